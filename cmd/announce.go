@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/apex/log"
+	"github.com/caarlos0/ctrlc"
+	"github.com/fatih/color"
+	"github.com/goreleaser/goreleaser/internal/middleware/errhandler"
+	"github.com/goreleaser/goreleaser/internal/middleware/logging"
+	"github.com/goreleaser/goreleaser/internal/middleware/skip"
+	"github.com/goreleaser/goreleaser/internal/pipe/announce"
+	"github.com/goreleaser/goreleaser/internal/pipe/effectiveconfig"
+	"github.com/goreleaser/goreleaser/internal/pipe/env"
+	"github.com/goreleaser/goreleaser/internal/pipe/metadata"
+	"github.com/goreleaser/goreleaser/internal/pipe/tmplcontext"
+	"github.com/goreleaser/goreleaser/internal/pipeline"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/spf13/cobra"
+)
+
+var errAnnounceSnapshot = errors.New("cannot announce a snapshot release")
+
+type announceCmd struct {
+	cmd  *cobra.Command
+	opts announceOpts
+}
+
+type announceOpts struct {
+	config  string
+	from    string
+	timeout time.Duration
+}
+
+func newAnnounceCmd() *announceCmd {
+	root := &announceCmd{}
+	cmd := &cobra.Command{
+		Use:   "announce",
+		Short: "Announces a previously finished release",
+		Long: `The ` + "`goreleaser announce`" + ` command runs only the announcers of a
+release that was already published, e.g. when a webhook failed.
+
+It uses the configuration and release information stored in the dist folder
+of the release, so nothing is built or published again.
+The environment variables used by the templates are also restored from
+there, so the messages are the same even if the environment changed.
+`,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		Args:          cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			start := time.Now()
+
+			log.Infof(color.New(color.Bold).Sprint("announcing..."))
+
+			if err := announceProject(root.opts); err != nil {
+				return wrapError(err, color.New(color.Bold).Sprintf("announcement failed after %0.2fs", time.Since(start).Seconds()))
+			}
+
+			log.Infof(color.New(color.Bold).Sprintf("announcement succeeded after %0.2fs", time.Since(start).Seconds()))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&root.opts.from, "from", "dist", "The dist folder of the release to announce")
+	cmd.Flags().StringVarP(&root.opts.config, "config", "f", "", "Load configuration from file (default: the effective configuration stored in the dist folder)")
+	cmd.Flags().DurationVar(&root.opts.timeout, "timeout", 30*time.Minute, "Timeout to the entire announce process")
+
+	root.cmd = cmd
+	return root
+}
+
+func announceProject(options announceOpts) error {
+	path := options.config
+	if path == "" {
+		path = filepath.Join(options.from, effectiveconfig.Filename)
+	}
+	cfg, err := config.Load(path)
+	if err != nil {
+		return err
+	}
+	md, err := metadata.Load(options.from)
+	if err != nil {
+		return fmt.Errorf("failed to load release metadata: %w", err)
+	}
+	if md.Snapshot {
+		return errAnnounceSnapshot
+	}
+	cfg.Dist = options.from
+
+	ctx, cancel := context.NewWithTimeout(cfg, options.timeout)
+	defer cancel()
+	md.Apply(ctx)
+	ctx.SkipTokenCheck = true
+
+	return ctrlc.Default.Run(ctx, func() error {
+		for _, pipe := range []pipeline.Piper{
+			env.Pipe{},                // load and validate environment variables
+			tmplcontext.RestorePipe{}, // restore the template context of the release
+			announce.Pipe{},           // announce releases
+		} {
+			if err := skip.Maybe(
+				pipe,
+				logging.Log(
+					pipe.String(),
+					errhandler.Handle(pipe.Run),
+					logging.DefaultInitialPadding,
+				),
+			)(ctx); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
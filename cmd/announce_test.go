@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/pipe/metadata"
+	"github.com/goreleaser/goreleaser/internal/pipe/tmplcontext"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+	yaml "gopkg.in/yaml.v2"
+)
+
+func setupAnnounceDist(tb testing.TB, endpoint string, snapshot bool) string {
+	tb.Helper()
+	dist := tb.TempDir()
+	require.NoError(tb, os.WriteFile(filepath.Join(dist, "config.yaml"), []byte(`project_name: foo
+announce:
+  webhook:
+    enabled: true
+    endpoint_url: `+endpoint+`
+    message_template: '{{ .ProjectName }} {{ .Tag }} {{ .ReleaseURL }}'
+    content_type: text/plain
+`), 0o644))
+
+	ctx := context.New(config.Project{ProjectName: "foo", Dist: dist})
+	ctx.Git.CurrentTag = "v1.0.0"
+	ctx.ReleaseURL = "https://example.com/releases/v1.0.0"
+	ctx.Snapshot = snapshot
+	require.NoError(tb, metadata.Pipe{}.Run(ctx))
+	return dist
+}
+
+func TestAnnounce(t *testing.T) {
+	var body string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		bts, _ := io.ReadAll(r.Body)
+		body = string(bts)
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(srv.Close)
+
+	cmd := newAnnounceCmd()
+	cmd.cmd.SetArgs([]string{"--from", setupAnnounceDist(t, srv.URL, false)})
+	require.NoError(t, cmd.cmd.Execute())
+	require.Equal(t, "foo v1.0.0 https://example.com/releases/v1.0.0", body)
+}
+
+func TestAnnounceTemplateContext(t *testing.T) {
+	var body string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		bts, _ := io.ReadAll(r.Body)
+		body = string(bts)
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(srv.Close)
+
+	dist := setupAnnounceDist(t, srv.URL, false)
+	cfg, err := config.Load(filepath.Join(dist, "config.yaml"))
+	require.NoError(t, err)
+	cfg.Announce.Webhook.MessageTemplate = "{{ .ProjectName }} {{ .Env.CHANNEL }}"
+	bts, err := yaml.Marshal(cfg)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dist, "config.yaml"), bts, 0o644))
+
+	cfg.Dist = dist
+	ctx := context.New(cfg)
+	ctx.Env["CHANNEL"] = "releases"
+	require.NoError(t, tmplcontext.Pipe{}.Run(ctx))
+
+	// the environment changed since the release.
+	t.Setenv("CHANNEL", "other")
+	cmd := newAnnounceCmd()
+	cmd.cmd.SetArgs([]string{"--from", dist})
+	require.NoError(t, cmd.cmd.Execute())
+	require.Equal(t, "foo releases", body)
+}
+
+func TestAnnounceFailed(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	t.Cleanup(srv.Close)
+
+	cmd := newAnnounceCmd()
+	cmd.cmd.SetArgs([]string{"--from", setupAnnounceDist(t, srv.URL, false)})
+	require.EqualError(t, cmd.cmd.Execute(), "webhook: failed to announce release: request failed with status 500 Internal Server Error")
+}
+
+func TestAnnounceSnapshot(t *testing.T) {
+	cmd := newAnnounceCmd()
+	cmd.cmd.SetArgs([]string{"--from", setupAnnounceDist(t, "http://localhost", true)})
+	require.EqualError(t, cmd.cmd.Execute(), errAnnounceSnapshot.Error())
+}
+
+func TestAnnounceNoMetadata(t *testing.T) {
+	dist := setupAnnounceDist(t, "http://localhost", false)
+	require.NoError(t, os.Remove(filepath.Join(dist, metadata.Filename)))
+
+	cmd := newAnnounceCmd()
+	cmd.cmd.SetArgs([]string{"--from", dist})
+	require.Error(t, cmd.cmd.Execute())
+}
+
+func TestAnnounceNoConfig(t *testing.T) {
+	cmd := newAnnounceCmd()
+	cmd.cmd.SetArgs([]string{"--from", t.TempDir()})
+	require.Error(t, cmd.cmd.Execute())
+}
@@ -3,16 +3,20 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"runtime"
+	"strings"
 	"time"
 
 	"github.com/apex/log"
 	"github.com/caarlos0/ctrlc"
 	"github.com/fatih/color"
-	"github.com/goreleaser/goreleaser/internal/middleware/errhandler"
-	"github.com/goreleaser/goreleaser/internal/middleware/logging"
-	"github.com/goreleaser/goreleaser/internal/middleware/skip"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/deprecate"
+	"github.com/goreleaser/goreleaser/internal/gio"
+	"github.com/goreleaser/goreleaser/internal/metrics"
 	"github.com/goreleaser/goreleaser/internal/pipeline"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
 	"github.com/goreleaser/goreleaser/pkg/config"
 	"github.com/goreleaser/goreleaser/pkg/context"
 	"github.com/spf13/cobra"
@@ -24,16 +28,18 @@ type buildCmd struct {
 }
 
 type buildOpts struct {
-	config        string
-	id            string
-	snapshot      bool
-	skipValidate  bool
-	skipPostHooks bool
-	rmDist        bool
-	deprecated    bool
-	parallelism   int
-	timeout       time.Duration
-	singleTarget  bool
+	config           string
+	id               string
+	snapshot         bool
+	skipValidate     bool
+	skipPostHooks    bool
+	rmDist           bool
+	deprecated       bool
+	failOnDeprecated bool
+	parallelism      int
+	timeout          time.Duration
+	singleTarget     bool
+	output           string
 }
 
 func newBuildCmd() *buildCmd {
@@ -57,6 +63,11 @@ the ` + "`--single-target`" + ` option, and specific build IDs using the
 When using ` + "`--single-target`" + `, the ` + "`GOOS`" + ` and
 ` + "`GOARCH`" + ` environment variables are used to determine the target,
 defaulting to the current's machine target if not set.
+
+The ` + "`--output`" + ` option copies the built binaries to the given path,
+which may be a template, e.g. ` + "`./bin/{{ .ProjectName }}`" + `.
+If it ends with a slash or is an existing directory, the binaries are copied
+into it instead.
 `,
 		SilenceUsage:  true,
 		SilenceErrors: true,
@@ -68,12 +79,10 @@ defaulting to the current's machine target if not set.
 
 			ctx, err := buildProject(root.opts)
 			if err != nil {
-				return wrapError(err, color.New(color.Bold).Sprintf("build failed after %0.2fs", time.Since(start).Seconds()))
+				return wrapErrorWithCode(err, exitCode(err), color.New(color.Bold).Sprintf("build failed after %0.2fs", time.Since(start).Seconds()))
 			}
 
-			if ctx.Deprecated {
-				log.Warn(color.New(color.Bold).Sprintf("your config is using deprecated properties, check logs above for details"))
-			}
+			deprecate.Report(ctx)
 
 			log.Infof(color.New(color.Bold).Sprintf("build succeeded after %0.2fs", time.Since(start).Seconds()))
 			return nil
@@ -89,6 +98,8 @@ defaulting to the current's machine target if not set.
 	cmd.Flags().DurationVar(&root.opts.timeout, "timeout", 30*time.Minute, "Timeout to the entire build process")
 	cmd.Flags().BoolVar(&root.opts.singleTarget, "single-target", false, "Builds only for current GOOS and GOARCH")
 	cmd.Flags().StringVar(&root.opts.id, "id", "", "Builds only the specified build id")
+	cmd.Flags().StringVarP(&root.opts.output, "output", "o", "", "Copy the binaries to this path, templates are allowed")
+	cmd.Flags().BoolVar(&root.opts.failOnDeprecated, "fail-on-deprecated", false, "Fails if the config uses deprecated options, before anything is built")
 	cmd.Flags().BoolVar(&root.opts.deprecated, "deprecated", false, "Force print the deprecation message - tests only")
 	_ = cmd.Flags().MarkHidden("deprecated")
 
@@ -107,19 +118,10 @@ func buildProject(options buildOpts) (*context.Context, error) {
 		return nil, err
 	}
 	return ctx, ctrlc.Default.Run(ctx, func() error {
-		for _, pipe := range pipeline.BuildCmdPipeline {
-			if err := skip.Maybe(
-				pipe,
-				logging.Log(
-					pipe.String(),
-					errhandler.Handle(pipe.Run),
-					logging.DefaultInitialPadding,
-				),
-			)(ctx); err != nil {
-				return err
-			}
+		if err := pipeline.Run(ctx, pipeline.BuildCmdPipeline, metrics.New(), options.failOnDeprecated); err != nil {
+			return err
 		}
-		return nil
+		return copyBinaries(ctx, options.output)
 	})
 }
 
@@ -150,6 +152,49 @@ func setupBuildContext(ctx *context.Context, options buildOpts) error {
 	return nil
 }
 
+// copyBinaries copies the built binaries to the given output path, if any.
+func copyBinaries(ctx *context.Context, output string) error {
+	if output == "" {
+		return nil
+	}
+	binaries := ctx.Artifacts.Filter(artifact.Or(
+		artifact.ByType(artifact.Binary),
+		artifact.ByType(artifact.UniversalBinary),
+	)).List()
+	if len(binaries) == 0 {
+		return fmt.Errorf("--output: no binaries were built")
+	}
+
+	targets := map[string]string{}
+	var order []string
+	for _, binary := range binaries {
+		target, err := tmpl.New(ctx).WithArtifact(binary, map[string]string{}).Apply(output)
+		if err != nil {
+			return fmt.Errorf("--output: %w", err)
+		}
+		if info, err := os.Stat(target); strings.HasSuffix(target, "/") || (err == nil && info.IsDir()) {
+			target = filepath.Join(target, filepath.Base(binary.Path))
+		}
+		if other, ok := targets[target]; ok {
+			return fmt.Errorf("--output: both %s and %s would be copied to %s, use --single-target, --id or a template to tell them apart", other, binary.Path, target)
+		}
+		targets[target] = binary.Path
+		order = append(order, target)
+	}
+
+	for _, target := range order {
+		path := targets[target]
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			return fmt.Errorf("--output: %w", err)
+		}
+		log.WithField("binary", path).WithField("output", target).Info("copying binary")
+		if err := gio.Copy(path, target); err != nil {
+			return fmt.Errorf("--output: %w", err)
+		}
+	}
+	return nil
+}
+
 func setupBuildSingleTarget(ctx *context.Context) {
 	goos := os.Getenv("GOOS")
 	if goos == "" {
@@ -2,9 +2,11 @@ package cmd
 
 import (
 	"os"
+	"path/filepath"
 	"runtime"
 	"testing"
 
+	"github.com/goreleaser/goreleaser/internal/artifact"
 	"github.com/goreleaser/goreleaser/pkg/config"
 	"github.com/goreleaser/goreleaser/pkg/context"
 	"github.com/stretchr/testify/require"
@@ -24,6 +26,72 @@ func TestBuildSingleTarget(t *testing.T) {
 	require.NoError(t, cmd.cmd.Execute())
 }
 
+func TestBuildSingleTargetWithOutput(t *testing.T) {
+	folder := setup(t)
+	cmd := newBuildCmd()
+	cmd.cmd.SetArgs([]string{"--snapshot", "--timeout=1m", "--parallelism=2", "--single-target", "--output", "./bin/{{ .ProjectName }}-{{ .Os }}"})
+	require.NoError(t, cmd.cmd.Execute())
+	require.FileExists(t, filepath.Join(folder, "bin", "fake-"+runtime.GOOS))
+}
+
+func TestBuildWithOutputDir(t *testing.T) {
+	folder := setup(t)
+	cmd := newBuildCmd()
+	cmd.cmd.SetArgs([]string{"--snapshot", "--timeout=1m", "--parallelism=2", "-o", "./bin/"})
+	require.NoError(t, cmd.cmd.Execute())
+	require.FileExists(t, filepath.Join(folder, "bin", "fake"))
+}
+
+func TestCopyBinaries(t *testing.T) {
+	folder := t.TempDir()
+	binary := filepath.Join(folder, "foo")
+	require.NoError(t, os.WriteFile(binary, []byte("foo"), 0o755))
+	newCtx := func() *context.Context {
+		ctx := context.New(config.Project{ProjectName: "proj"})
+		for _, goos := range []string{"linux", "darwin"} {
+			ctx.Artifacts.Add(&artifact.Artifact{
+				Name:   "foo",
+				Path:   binary,
+				Goos:   goos,
+				Goarch: "amd64",
+				Type:   artifact.Binary,
+			})
+		}
+		return ctx
+	}
+
+	t.Run("no output", func(t *testing.T) {
+		require.NoError(t, copyBinaries(newCtx(), ""))
+	})
+
+	t.Run("templated", func(t *testing.T) {
+		out := filepath.Join(folder, "out")
+		require.NoError(t, copyBinaries(newCtx(), out+"/{{ .ProjectName }}_{{ .Os }}"))
+		for _, goos := range []string{"linux", "darwin"} {
+			info, err := os.Stat(filepath.Join(out, "proj_"+goos))
+			require.NoError(t, err)
+			require.Equal(t, os.FileMode(0o755), info.Mode().Perm())
+		}
+	})
+
+	t.Run("colliding", func(t *testing.T) {
+		require.EqualError(
+			t,
+			copyBinaries(newCtx(), filepath.Join(folder, "bin")),
+			"--output: both "+binary+" and "+binary+" would be copied to "+filepath.Join(folder, "bin")+", use --single-target, --id or a template to tell them apart",
+		)
+	})
+
+	t.Run("invalid template", func(t *testing.T) {
+		require.Error(t, copyBinaries(newCtx(), "{{ .Nope }"))
+	})
+
+	t.Run("no binaries", func(t *testing.T) {
+		ctx := context.New(config.Project{})
+		require.EqualError(t, copyBinaries(ctx, "bin"), "--output: no binaries were built")
+	})
+}
+
 func TestBuildInvalidConfig(t *testing.T) {
 	setup(t)
 	createFile(t, "goreleaser.yml", "foo: bar")
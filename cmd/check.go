@@ -8,7 +8,12 @@ import (
 	"github.com/apex/log/handlers/cli"
 	"github.com/caarlos0/ctrlc"
 	"github.com/fatih/color"
+	"github.com/goreleaser/goreleaser/internal/configdiff"
+	"github.com/goreleaser/goreleaser/internal/deprecate"
+	"github.com/goreleaser/goreleaser/internal/pipe/collision"
 	"github.com/goreleaser/goreleaser/internal/pipe/defaults"
+	"github.com/goreleaser/goreleaser/internal/sarif"
+	"github.com/goreleaser/goreleaser/pkg/config"
 	"github.com/goreleaser/goreleaser/pkg/context"
 	"github.com/spf13/cobra"
 )
@@ -18,6 +23,8 @@ type checkCmd struct {
 	config     string
 	quiet      bool
 	deprecated bool
+	against    string
+	sarif      string
 }
 
 func newCheckCmd() *checkCmd {
@@ -34,38 +41,153 @@ func newCheckCmd() *checkCmd {
 				log.SetHandler(cli.New(io.Discard))
 			}
 
-			cfg, err := loadConfig(root.config)
-			if err != nil {
-				return err
+			report := &sarif.Report{
+				File:    configFile(root.config),
+				Version: cmd.Root().Version,
 			}
-			ctx := context.New(cfg)
-			ctx.Deprecated = root.deprecated
-
-			if err := ctrlc.Default.Run(ctx, func() error {
-				log.Info(color.New(color.Bold).Sprint("checking config:"))
-				return defaults.Pipe{}.Run(ctx)
-			}); err != nil {
-				log.WithError(err).Error(color.New(color.Bold).Sprintf("config is invalid"))
-				return fmt.Errorf("invalid config: %w", err)
+			err := root.check(report)
+			if root.sarif == "" {
+				return err
 			}
-
-			if ctx.Deprecated {
-				return wrapErrorWithCode(
-					fmt.Errorf("config is valid, but uses deprecated properties, check logs above for details"),
-					2,
-					"",
-				)
+			if werr := report.WriteFile(root.sarif); werr != nil {
+				if err != nil {
+					log.WithError(werr).Error("could not write sarif report")
+					return err
+				}
+				return fmt.Errorf("could not write sarif report: %w", werr)
 			}
-			log.Infof(color.New(color.Bold).Sprintf("config is valid"))
-			return nil
+			return err
 		},
 	}
 
 	cmd.Flags().StringVarP(&root.config, "config", "f", "", "Configuration file to check")
 	cmd.Flags().BoolVarP(&root.quiet, "quiet", "q", false, "Quiet mode: no output")
+	cmd.Flags().StringVar(&root.against, "against", "", "Baseline configuration file or URL to compare the configuration against")
+	cmd.Flags().StringVar(&root.sarif, "sarif", "", "Also write the findings as a SARIF report to the given file, - for stdout")
 	cmd.Flags().BoolVar(&root.deprecated, "deprecated", false, "Force print the deprecation message - tests only")
 	_ = cmd.Flags().MarkHidden("deprecated")
 
 	root.cmd = cmd
 	return root
 }
+
+// check checks the config, adding its findings to the given report.
+func (root *checkCmd) check(report *sarif.Report) error {
+	cfg, err := loadConfig(root.config)
+	if err != nil {
+		report.Add(sarif.Finding{
+			Rule:    sarif.InvalidConfig,
+			Level:   sarif.Error,
+			Message: err.Error(),
+			Line:    sarif.LineOf(err),
+		})
+		return err
+	}
+
+	// defaults change the config in place, so compare it against the
+	// baseline before they run.
+	var drift []configdiff.Change
+	if root.against != "" {
+		drift, err = diffAgainst(cfg, root.against)
+		if err != nil {
+			return err
+		}
+	}
+
+	ctx := context.New(cfg)
+	ctx.Deprecated = root.deprecated
+
+	err = ctrlc.Default.Run(ctx, func() error {
+		log.Info(color.New(color.Bold).Sprint("checking config:"))
+		if err := (defaults.Pipe{}).Run(ctx); err != nil {
+			report.Add(sarif.Finding{
+				Rule:    sarif.InvalidConfig,
+				Level:   sarif.Error,
+				Message: err.Error(),
+			})
+			return err
+		}
+		return checkCollisions(ctx, report)
+	})
+	for _, d := range ctx.Deprecations {
+		report.Add(sarif.Finding{
+			Rule:    sarif.DeprecatedProperty,
+			Level:   sarif.Warning,
+			Message: deprecate.Message(d),
+			Path:    deprecate.Name(d),
+		})
+	}
+	if err != nil {
+		log.WithError(err).Error(color.New(color.Bold).Sprintf("config is invalid"))
+		return fmt.Errorf("invalid config: %w", err)
+	}
+
+	if root.against != "" {
+		if err := reportDrift(drift, report); err != nil {
+			return err
+		}
+	}
+
+	if ctx.Deprecated {
+		return wrapErrorWithCode(
+			fmt.Errorf("config is valid, but uses deprecated properties, check logs above for details"),
+			2,
+			"",
+		)
+	}
+	log.Infof(color.New(color.Bold).Sprintf("config is valid"))
+	return nil
+}
+
+// checkCollisions fails if archives would be created with the same name.
+// Templates that can't be rendered outside of a release, e.g. because of
+// missing environment variables, are only warned about.
+func checkCollisions(ctx *context.Context, report *sarif.Report) error {
+	collisions, err := collision.Check(ctx)
+	if err != nil {
+		log.WithError(err).Warn("could not check artifact names for collisions")
+		return nil
+	}
+	for _, c := range collisions {
+		report.Add(sarif.Finding{
+			Rule:    sarif.ArchiveNameCollision,
+			Level:   sarif.Error,
+			Message: c.String(),
+			Path:    "archives",
+		})
+	}
+	return collision.Error(collisions)
+}
+
+// diffAgainst compares the given config against the baseline config at the
+// given path or URL.
+func diffAgainst(cfg config.Project, against string) ([]configdiff.Change, error) {
+	baseline, err := loadBaseline(against)
+	if err != nil {
+		return nil, fmt.Errorf("invalid baseline: %w", err)
+	}
+	return configdiff.Diff(baseline, cfg)
+}
+
+// reportDrift logs the sections that differ from the baseline.
+func reportDrift(changes []configdiff.Change, report *sarif.Report) error {
+	log.Info(color.New(color.Bold).Sprint("comparing against baseline:"))
+	for _, change := range changes {
+		log.WithField("section", change.Path).Warn(string(change.Kind))
+		report.Add(sarif.Finding{
+			Rule:    sarif.BaselineDrift,
+			Level:   sarif.Note,
+			Message: change.String(),
+			Path:    change.Path,
+		})
+	}
+	if len(changes) > 0 {
+		return wrapErrorWithCode(
+			fmt.Errorf("config is valid, but differs from the baseline in %d sections, check logs above for details", len(changes)),
+			3,
+			"",
+		)
+	}
+	log.Info("config matches the baseline")
+	return nil
+}
@@ -1,6 +1,10 @@
 package cmd
 
 import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -41,3 +45,95 @@ func TestCheckConfigDeprecated(t *testing.T) {
 	cmd.cmd.SetArgs([]string{"-f", "testdata/good.yml", "--deprecated"})
 	require.EqualError(t, cmd.cmd.Execute(), "config is valid, but uses deprecated properties, check logs above for details")
 }
+
+func TestCheckConfigAgainstItself(t *testing.T) {
+	cmd := newCheckCmd()
+	cmd.cmd.SetArgs([]string{"-f", "testdata/good.yml", "--against", "testdata/good.yml"})
+	require.NoError(t, cmd.cmd.Execute())
+}
+
+func TestCheckConfigAgainstBaseline(t *testing.T) {
+	cmd := newCheckCmd()
+	cmd.cmd.SetArgs([]string{"-f", "testdata/good.yml", "--against", "testdata/baseline.yml"})
+	err := cmd.cmd.Execute()
+	require.EqualError(t, err, "config is valid, but differs from the baseline in 4 sections, check logs above for details")
+	var eerr *exitError
+	require.ErrorAs(t, err, &eerr)
+	require.Equal(t, 3, eerr.code)
+}
+
+func TestCheckConfigAgainstBaselineURL(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, "testdata/good.yml")
+	}))
+	t.Cleanup(srv.Close)
+
+	cmd := newCheckCmd()
+	cmd.cmd.SetArgs([]string{"-f", "testdata/good.yml", "--against", srv.URL + "/good.yml"})
+	require.NoError(t, cmd.cmd.Execute())
+}
+
+func TestCheckConfigAgainstBaselineURLNotFound(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	t.Cleanup(srv.Close)
+
+	cmd := newCheckCmd()
+	cmd.cmd.SetArgs([]string{"-f", "testdata/good.yml", "--against", srv.URL + "/nope.yml"})
+	require.EqualError(t, cmd.cmd.Execute(), "invalid baseline: failed to download "+srv.URL+"/nope.yml: 404 Not Found")
+}
+
+func TestCheckConfigAgainstBaselineThatDoesNotExist(t *testing.T) {
+	cmd := newCheckCmd()
+	cmd.cmd.SetArgs([]string{"-f", "testdata/good.yml", "--against", "testdata/nope.yml"})
+	require.EqualError(t, cmd.cmd.Execute(), "invalid baseline: open testdata/nope.yml: no such file or directory")
+}
+
+func TestCheckConfigSARIF(t *testing.T) {
+	for name, tt := range map[string]struct {
+		args     []string
+		contains []string
+	}{
+		"valid": {
+			args:     []string{"-f", "testdata/good.yml"},
+			contains: []string{`"results": []`},
+		},
+		"unmarshal error": {
+			args:     []string{"-f", "testdata/unmarshal_error.yml"},
+			contains: []string{`"ruleId": "invalid-config"`, `"level": "error"`, `"uri": "testdata/unmarshal_error.yml"`, `"startLine": 1`},
+		},
+		"invalid": {
+			args:     []string{"-f", "testdata/invalid.yml"},
+			contains: []string{`"ruleId": "invalid-config"`, "found 2 builds with the ID 'a', please fix your config"},
+		},
+		"against baseline": {
+			args:     []string{"-f", "testdata/good.yml", "--against", "testdata/baseline.yml"},
+			contains: []string{`"ruleId": "baseline-drift"`, `"level": "note"`},
+		},
+	} {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "report.sarif")
+			cmd := newCheckCmd()
+			cmd.cmd.SetArgs(append(tt.args, "--sarif", path))
+			_ = cmd.cmd.Execute()
+			bts, err := os.ReadFile(path)
+			require.NoError(t, err)
+			require.Contains(t, string(bts), `"version": "2.1.0"`)
+			for _, s := range tt.contains {
+				require.Contains(t, string(bts), s)
+			}
+		})
+	}
+}
+
+func TestCheckConfigSARIFWriteError(t *testing.T) {
+	cmd := newCheckCmd()
+	cmd.cmd.SetArgs([]string{"-f", "testdata/good.yml", "--sarif", filepath.Join(t.TempDir(), "nope", "report.sarif")})
+	require.Error(t, cmd.cmd.Execute())
+}
+
+func TestCheckConfigSARIFWriteErrorKeepsCheckError(t *testing.T) {
+	cmd := newCheckCmd()
+	cmd.cmd.SetArgs([]string{"-f", "testdata/invalid.yml", "--sarif", filepath.Join(t.TempDir(), "nope", "report.sarif")})
+	require.EqualError(t, cmd.cmd.Execute(), "invalid config: found 2 builds with the ID 'a', please fix your config")
+}
@@ -1,22 +1,28 @@
 package cmd
 
 import (
+	"fmt"
+	"net/http"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/apex/log"
 	"github.com/goreleaser/goreleaser/pkg/config"
 )
 
+var configFiles = [4]string{
+	".goreleaser.yml",
+	".goreleaser.yaml",
+	"goreleaser.yml",
+	"goreleaser.yaml",
+}
+
 func loadConfig(path string) (config.Project, error) {
 	if path != "" {
 		return config.Load(path)
 	}
-	for _, f := range [4]string{
-		".goreleaser.yml",
-		".goreleaser.yaml",
-		"goreleaser.yml",
-		"goreleaser.yaml",
-	} {
+	for _, f := range configFiles {
 		proj, err := config.Load(f)
 		if err != nil && os.IsNotExist(err) {
 			continue
@@ -28,3 +34,35 @@ func loadConfig(path string) (config.Project, error) {
 	log.Warn("could not find a config file, using defaults...")
 	return config.Project{}, nil
 }
+
+// configFile returns the path of the config file loadConfig loads, or an
+// empty string if there is none.
+func configFile(path string) string {
+	if path != "" {
+		return path
+	}
+	for _, f := range configFiles {
+		if _, err := os.Stat(f); err == nil {
+			return f
+		}
+	}
+	return ""
+}
+
+// loadBaseline loads a baseline config from the given file or http(s) URL.
+func loadBaseline(path string) (config.Project, error) {
+	if !strings.HasPrefix(path, "http://") && !strings.HasPrefix(path, "https://") {
+		return config.Load(path)
+	}
+	log.WithField("url", path).Info("loading baseline config")
+	cli := &http.Client{Timeout: 30 * time.Second}
+	resp, err := cli.Get(path) // #nosec
+	if err != nil {
+		return config.Project{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return config.Project{}, fmt.Errorf("failed to download %s: %s", path, resp.Status)
+	}
+	return config.LoadReader(resp.Body)
+}
@@ -0,0 +1,16 @@
+package cmd
+
+import (
+	"errors"
+
+	"github.com/goreleaser/goreleaser/internal/deprecate"
+)
+
+// exitCode returns the exit code of a failed release or build: 2 if it
+// failed because of deprecated options, 1 otherwise.
+func exitCode(err error) int {
+	if errors.Is(err, deprecate.ErrDeprecated) {
+		return 2
+	}
+	return 1
+}
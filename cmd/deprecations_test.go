@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/deprecate"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExitCode(t *testing.T) {
+	require.Equal(t, 2, exitCode(fmt.Errorf("wrapped: %w", deprecate.ErrDeprecated)))
+	require.Equal(t, 1, exitCode(errors.New("other")))
+}
+
+func TestReleaseFailOnDeprecated(t *testing.T) {
+	setup(t)
+	cmd := newReleaseCmd()
+	cmd.cmd.SetArgs([]string{"--snapshot", "--timeout=1m", "--parallelism=2", "--deprecated", "--fail-on-deprecated"})
+	err := cmd.cmd.Execute()
+	require.EqualError(t, err, deprecate.ErrDeprecated.Error())
+
+	eerr := &exitError{}
+	require.True(t, errors.As(err, &eerr))
+	require.Equal(t, 2, eerr.code)
+}
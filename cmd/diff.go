@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/apex/log"
+	"github.com/fatih/color"
+	"github.com/goreleaser/goreleaser/internal/distdiff"
+	"github.com/spf13/cobra"
+)
+
+type diffCmd struct {
+	cmd  *cobra.Command
+	json bool
+}
+
+func newDiffCmd() *diffCmd {
+	root := &diffCmd{}
+	cmd := &cobra.Command{
+		Use:   "diff [dist-a] [dist-b]",
+		Short: "Compares the artifacts of two dist folders",
+		Long: `The ` + "`goreleaser diff`" + ` command compares two dist folders: the artifacts
+listed in their artifacts.json files, the sizes and checksums of the artifact
+files, and their release metadata.
+
+It is useful to validate a refactoring of the configuration, or to check if
+a release is reproducible.
+The command fails if the dist folders differ.
+`,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		Args:          cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			changes, err := distdiff.Dirs(args[0], args[1])
+			if err != nil {
+				return err
+			}
+			if root.json {
+				if changes == nil {
+					changes = []distdiff.Change{}
+				}
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				if err := enc.Encode(changes); err != nil {
+					return err
+				}
+			} else {
+				reportDistDiff(changes)
+			}
+			if len(changes) > 0 {
+				return wrapErrorWithCode(
+					fmt.Errorf("found %d differences between the dist folders, check logs above for details", len(changes)),
+					1,
+					"",
+				)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&root.json, "json", false, "Print the differences as JSON")
+
+	root.cmd = cmd
+	return root
+}
+
+// reportDistDiff logs the differences between the dist folders.
+func reportDistDiff(changes []distdiff.Change) {
+	log.Info(color.New(color.Bold).Sprint("comparing dist folders:"))
+	for _, change := range changes {
+		entry := log.WithField(string(change.Section), change.Name)
+		if change.Field != "" {
+			entry = entry.WithField("field", change.Field)
+		}
+		if change.Kind == distdiff.Changed {
+			entry = entry.WithField("old", change.Old).WithField("new", change.New)
+		}
+		entry.Warn(string(change.Kind))
+	}
+	if len(changes) == 0 {
+		log.Info("dist folders are identical")
+	}
+}
@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func setupDiffDist(tb testing.TB, content string) string {
+	tb.Helper()
+	dist := tb.TempDir()
+	require.NoError(tb, os.WriteFile(filepath.Join(dist, "artifacts.json"), []byte(`[{"name":"foo.txt","path":"dist/foo.txt","type":"Checksum"}]`), 0o644))
+	require.NoError(tb, os.WriteFile(filepath.Join(dist, "foo.txt"), []byte(content), 0o644))
+	return dist
+}
+
+func TestDiff(t *testing.T) {
+	cmd := newDiffCmd()
+	cmd.cmd.SetArgs([]string{setupDiffDist(t, "foo"), setupDiffDist(t, "foo")})
+	require.NoError(t, cmd.cmd.Execute())
+}
+
+func TestDiffDifferent(t *testing.T) {
+	for _, args := range [][]string{nil, {"--json"}} {
+		cmd := newDiffCmd()
+		cmd.cmd.SetArgs(append([]string{setupDiffDist(t, "foo"), setupDiffDist(t, "bar")}, args...))
+		require.EqualError(t, cmd.cmd.Execute(), "found 1 differences between the dist folders, check logs above for details")
+	}
+}
+
+func TestDiffInvalidArgs(t *testing.T) {
+	cmd := newDiffCmd()
+	cmd.cmd.SetArgs([]string{t.TempDir()})
+	require.Error(t, cmd.cmd.Execute())
+
+	cmd = newDiffCmd()
+	cmd.cmd.SetArgs([]string{t.TempDir(), t.TempDir()})
+	require.Error(t, cmd.cmd.Execute())
+}
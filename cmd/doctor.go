@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/apex/log"
+	"github.com/fatih/color"
+	"github.com/goreleaser/goreleaser/internal/doctor"
+	"github.com/goreleaser/goreleaser/internal/git"
+	"github.com/spf13/cobra"
+)
+
+type doctorCmd struct {
+	cmd *cobra.Command
+}
+
+func newDoctorCmd() *doctorCmd {
+	root := &doctorCmd{}
+	cmd := &cobra.Command{
+		Use:           "doctor",
+		Short:         "Analyzes the project and suggests improvements",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		Args:          cobra.NoArgs,
+	}
+	cmd.AddCommand(newDoctorConfigCmd().cmd)
+
+	root.cmd = cmd
+	return root
+}
+
+type doctorConfigCmd struct {
+	cmd    *cobra.Command
+	config string
+}
+
+func newDoctorConfigCmd() *doctorConfigCmd {
+	root := &doctorConfigCmd{}
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Suggests configuration sections the project could use",
+		Long: `Analyzes the current repository and its configuration, and suggests the
+configuration sections of features it could use, e.g. docker images when it
+has a Dockerfile, as ready to paste YAML.
+`,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		Args:          cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := loadConfig(root.config)
+			if err != nil {
+				return err
+			}
+			owner := "myuser"
+			if repo, err := git.ExtractRepoFromConfig(); err == nil {
+				owner = repo.Owner
+			}
+			suggestions, err := doctor.Suggest(".", cfg, owner)
+			if err != nil {
+				return fmt.Errorf("failed to analyze the repository: %w", err)
+			}
+			if len(suggestions) == 0 {
+				log.Info(color.New(color.Bold).Sprint("no suggestions, the config looks good"))
+				return nil
+			}
+			log.Infof(color.New(color.Bold).Sprintf("found %d suggestions", len(suggestions)))
+			return printSuggestions(cmd.OutOrStdout(), suggestions)
+		},
+	}
+
+	cmd.Flags().StringVarP(&root.config, "config", "f", "", "Configuration file to analyze")
+
+	root.cmd = cmd
+	return root
+}
+
+func printSuggestions(w io.Writer, suggestions []doctor.Suggestion) error {
+	for i, s := range suggestions {
+		if i > 0 {
+			if _, err := fmt.Fprintln(w); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintf(w, "# %s: %s\n# %s\n%s", s.Title, s.Reason, s.Docs, s.YAML); err != nil {
+			return err
+		}
+	}
+	return nil
+}
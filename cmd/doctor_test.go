@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDoctorConfig(t *testing.T) {
+	setup(t)
+	createFile(t, "Dockerfile", "FROM scratch\n")
+	cmd := newDoctorCmd().cmd
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"config"})
+	require.NoError(t, cmd.Execute())
+	require.Contains(t, out.String(), "# Docker images: the repository has a Dockerfile, but no images are built")
+	require.Contains(t, out.String(), `"ghcr.io/goreleaser/{{ .ProjectName }}:latest"`)
+}
+
+func TestDoctorConfigNoSuggestions(t *testing.T) {
+	setup(t)
+	cmd := newDoctorCmd().cmd
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"config"})
+	require.NoError(t, cmd.Execute())
+	require.Empty(t, out.String())
+}
+
+func TestDoctorConfigInvalidConfig(t *testing.T) {
+	setup(t)
+	createFile(t, "goreleaser.yml", "foo: bar")
+	cmd := newDoctorCmd().cmd
+	cmd.SetArgs([]string{"config", "-f", "goreleaser.yml"})
+	require.EqualError(t, cmd.Execute(), "yaml: unmarshal errors:\n  line 1: field foo not found in type config.Project")
+}
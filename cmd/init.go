@@ -1,29 +1,49 @@
 package cmd
 
 import (
+	"fmt"
+	"io"
+	"net/http"
 	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/apex/log"
 	"github.com/fatih/color"
+	"github.com/goreleaser/goreleaser/internal/git"
 	"github.com/goreleaser/goreleaser/internal/static"
 	"github.com/spf13/cobra"
 )
 
 type initCmd struct {
-	cmd    *cobra.Command
-	config string
+	cmd          *cobra.Command
+	config       string
+	template     string
+	templateRepo string
 }
 
 func newInitCmd() *initCmd {
 	root := &initCmd{}
 	cmd := &cobra.Command{
-		Use:           "init",
-		Aliases:       []string{"i"},
-		Short:         "Generates a .goreleaser.yaml file",
+		Use:     "init",
+		Aliases: []string{"i"},
+		Short:   "Generates a .goreleaser.yaml file",
+		Long: `Generates a .goreleaser.yaml file from a template.
+
+The built-in templates are ` + strings.Join(static.TemplateNames(), ", ") + `.
+Templates can also be a URL, or the name of a template in a git repository
+set with --template-repo, e.g. to share configurations across the projects of
+an organization.
+`,
 		SilenceUsage:  true,
 		SilenceErrors: true,
 		Args:          cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			content, err := loadTemplate(root.template, root.templateRepo)
+			if err != nil {
+				return err
+			}
+
 			conf, err := os.OpenFile(root.config, os.O_WRONLY|os.O_CREATE|os.O_TRUNC|os.O_EXCL, 0o644)
 			if err != nil {
 				return err
@@ -31,7 +51,7 @@ func newInitCmd() *initCmd {
 			defer conf.Close()
 
 			log.Infof(color.New(color.Bold).Sprintf("Generating %s file", root.config))
-			if _, err := conf.WriteString(static.ExampleConfig); err != nil {
+			if _, err := conf.WriteString(fillTemplate(content)); err != nil {
 				return err
 			}
 
@@ -50,7 +70,87 @@ func newInitCmd() *initCmd {
 	}
 
 	cmd.Flags().StringVarP(&root.config, "config", "f", ".goreleaser.yaml", "Load configuration from file")
+	cmd.Flags().StringVarP(&root.template, "template", "t", "default", "Template to generate the configuration from: "+strings.Join(static.TemplateNames(), ", ")+", or a URL")
+	cmd.Flags().StringVar(&root.templateRepo, "template-repo", os.Getenv("GORELEASER_TEMPLATE_REPO"), "Git repository to look the template up in first, as <template>.yaml (default: $GORELEASER_TEMPLATE_REPO)")
 
 	root.cmd = cmd
 	return root
 }
+
+// loadTemplate returns the content of the given template, looking it up in
+// the given git repository first, if any.
+func loadTemplate(name, repo string) (string, error) {
+	if strings.HasPrefix(name, "https://") || strings.HasPrefix(name, "http://") {
+		return downloadTemplate(name)
+	}
+	if repo != "" {
+		content, err := repoTemplate(repo, name)
+		if err == nil || !os.IsNotExist(err) {
+			return content, err
+		}
+		log.WithField("template", name).WithField("repo", repo).Debug("template not found in repository, using the built-in one")
+	}
+	content, ok := static.Templates[name]
+	if !ok {
+		return "", fmt.Errorf("unknown template %q, valid options are %v", name, static.TemplateNames())
+	}
+	return content, nil
+}
+
+func downloadTemplate(url string) (string, error) {
+	log.WithField("url", url).Info("downloading template")
+	resp, err := http.Get(url) // #nosec
+	if err != nil {
+		return "", fmt.Errorf("failed to download template: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to download template: %s", resp.Status)
+	}
+	bts, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to download template: %w", err)
+	}
+	return string(bts), nil
+}
+
+// repoTemplate returns the content of the <name>.yaml or <name>.yml file of
+// the given git repository.
+func repoTemplate(repo, name string) (string, error) {
+	dir, err := os.MkdirTemp("", "goreleaser-templates")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(dir)
+
+	log.WithField("repo", repo).Info("cloning template repository")
+	if _, err := git.Clean(git.Run("clone", "--depth=1", repo, dir)); err != nil {
+		return "", fmt.Errorf("failed to clone template repository: %w", err)
+	}
+	for _, ext := range []string{".yaml", ".yml"} {
+		bts, err := os.ReadFile(filepath.Join(dir, name+ext))
+		if err == nil {
+			return string(bts), nil
+		}
+		if !os.IsNotExist(err) {
+			return "", err
+		}
+	}
+	return "", os.ErrNotExist
+}
+
+// fillTemplate replaces the placeholders of the given template with the
+// owner and name of the current repository.
+func fillTemplate(content string) string {
+	owner, name := "myorg", "myproject"
+	if wd, err := os.Getwd(); err == nil {
+		name = filepath.Base(wd)
+	}
+	if repo, err := git.ExtractRepoFromConfig(); err == nil {
+		owner, name = repo.Owner, repo.Name
+	}
+	return strings.NewReplacer(
+		static.OwnerPlaceholder, owner,
+		static.ProjectPlaceholder, name,
+	).Replace(content)
+}
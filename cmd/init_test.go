@@ -1,10 +1,14 @@
 package cmd
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"testing"
 
+	"github.com/goreleaser/goreleaser/internal/static"
 	"github.com/stretchr/testify/require"
 )
 
@@ -54,6 +58,95 @@ func TestInitFileError(t *testing.T) {
 	require.EqualError(t, cmd.Execute(), "open "+path+": permission denied")
 }
 
+func TestInitTemplate(t *testing.T) {
+	folder := setupInitTest(t)
+	cmd := newInitCmd().cmd
+	cmd.SetArgs([]string{"-f", "foo.yaml", "--template", "cli"})
+	require.NoError(t, cmd.Execute())
+
+	bts, err := os.ReadFile(filepath.Join(folder, "foo.yaml"))
+	require.NoError(t, err)
+	require.Contains(t, string(bts), "owner: myorg")
+	require.Contains(t, string(bts), "system \"#{bin}/"+filepath.Base(folder)+" --help\"")
+	require.NotContains(t, string(bts), static.OwnerPlaceholder)
+	require.NotContains(t, string(bts), static.ProjectPlaceholder)
+}
+
+func TestInitUnknownTemplate(t *testing.T) {
+	folder := setupInitTest(t)
+	cmd := newInitCmd().cmd
+	cmd.SetArgs([]string{"-f", "foo.yaml", "--template", "nope"})
+	require.EqualError(t, cmd.Execute(), `unknown template "nope", valid options are [cli daemon default k8s-operator library]`)
+	require.NoFileExists(t, filepath.Join(folder, "foo.yaml"))
+}
+
+func TestInitTemplateURL(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/team.yaml" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte("project_name: __PROJECT__\n"))
+	}))
+	t.Cleanup(srv.Close)
+
+	folder := setupInitTest(t)
+	cmd := newInitCmd().cmd
+	cmd.SetArgs([]string{"-f", "foo.yaml", "--template", srv.URL + "/team.yaml"})
+	require.NoError(t, cmd.Execute())
+	bts, err := os.ReadFile(filepath.Join(folder, "foo.yaml"))
+	require.NoError(t, err)
+	require.Equal(t, "project_name: "+filepath.Base(folder)+"\n", string(bts))
+
+	cmd = newInitCmd().cmd
+	cmd.SetArgs([]string{"-f", "bar.yaml", "--template", srv.URL + "/nope.yaml"})
+	require.EqualError(t, cmd.Execute(), "failed to download template: 404 Not Found")
+}
+
+func TestInitTemplateRepo(t *testing.T) {
+	repo := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"config", "user.email", "test@goreleaser.com"},
+		{"config", "user.name", "test"},
+		{"config", "commit.gpgsign", "false"},
+	} {
+		out, err := exec.Command("git", append([]string{"-C", repo}, args...)...).CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "cli.yml"), []byte("project_name: team-cli\n"), 0o644))
+	for _, args := range [][]string{
+		{"add", "-A"},
+		{"commit", "-q", "-m", "templates"},
+	} {
+		out, err := exec.Command("git", append([]string{"-C", repo}, args...)...).CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	t.Setenv("GORELEASER_TEMPLATE_REPO", repo)
+
+	folder := setupInitTest(t)
+	cmd := newInitCmd().cmd
+	cmd.SetArgs([]string{"-f", "cli.yaml", "--template", "cli"})
+	require.NoError(t, cmd.Execute())
+	bts, err := os.ReadFile(filepath.Join(folder, "cli.yaml"))
+	require.NoError(t, err)
+	require.Equal(t, "project_name: team-cli\n", string(bts))
+
+	// templates missing from the repository fall back to the built-in ones.
+	cmd = newInitCmd().cmd
+	cmd.SetArgs([]string{"-f", "library.yaml", "--template", "library"})
+	require.NoError(t, cmd.Execute())
+	bts, err = os.ReadFile(filepath.Join(folder, "library.yaml"))
+	require.NoError(t, err)
+	require.Equal(t, static.LibraryConfig, string(bts))
+
+	cmd = newInitCmd().cmd
+	cmd.SetArgs([]string{"-f", "nope.yaml", "--template", "cli", "--template-repo", filepath.Join(repo, "nope")})
+	err = cmd.Execute()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to clone template repository")
+}
+
 func setupInitTest(tb testing.TB) string {
 	tb.Helper()
 
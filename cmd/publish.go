@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/apex/log"
+	"github.com/caarlos0/ctrlc"
+	"github.com/fatih/color"
+	"github.com/goreleaser/goreleaser/internal/middleware"
+	"github.com/goreleaser/goreleaser/internal/middleware/errhandler"
+	"github.com/goreleaser/goreleaser/internal/middleware/logging"
+	"github.com/goreleaser/goreleaser/internal/middleware/skip"
+	"github.com/goreleaser/goreleaser/internal/pipe/docker"
+	"github.com/goreleaser/goreleaser/internal/pipe/effectiveconfig"
+	"github.com/goreleaser/goreleaser/internal/pipe/env"
+	"github.com/goreleaser/goreleaser/internal/pipe/metadata"
+	"github.com/goreleaser/goreleaser/internal/pipe/tmplcontext"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/spf13/cobra"
+)
+
+var errPublishSnapshot = errors.New("cannot publish a snapshot release")
+
+type publishCmd struct {
+	cmd  *cobra.Command
+	opts publishOpts
+}
+
+type publishOpts struct {
+	config  string
+	from    string
+	timeout time.Duration
+}
+
+func newPublishCmd() *publishCmd {
+	root := &publishCmd{}
+	cmd := &cobra.Command{
+		Use:   "publish",
+		Short: "Publishes the docker images saved by a previous release",
+		Long: `The ` + "`goreleaser publish`" + ` command pushes the docker images and manifests of
+a release built with ` + "`dockers.save`" + ` enabled, e.g. with ` + "`--skip-publish`" + ` on a host
+without network access.
+
+It loads the images from the tarballs in the dist folder of the release, and
+uses the configuration and release information stored there, so the images
+are pushed with the same tags, and the manifests are created from the same
+images, even if the environment variables used by the templates changed.
+`,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		Args:          cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			start := time.Now()
+
+			log.Infof(color.New(color.Bold).Sprint("publishing..."))
+
+			if err := publishProject(root.opts); err != nil {
+				return wrapError(err, color.New(color.Bold).Sprintf("publishing failed after %0.2fs", time.Since(start).Seconds()))
+			}
+
+			log.Infof(color.New(color.Bold).Sprintf("publishing succeeded after %0.2fs", time.Since(start).Seconds()))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&root.opts.from, "from", "dist", "The dist folder of the release to publish")
+	cmd.Flags().StringVarP(&root.opts.config, "config", "f", "", "Load configuration from file (default: the effective configuration stored in the dist folder)")
+	cmd.Flags().DurationVar(&root.opts.timeout, "timeout", 30*time.Minute, "Timeout to the entire publish process")
+
+	root.cmd = cmd
+	return root
+}
+
+type publishStep struct {
+	pipe   fmt.Stringer
+	action middleware.Action
+}
+
+func publishProject(options publishOpts) error {
+	path := options.config
+	if path == "" {
+		path = filepath.Join(options.from, effectiveconfig.Filename)
+	}
+	cfg, err := config.Load(path)
+	if err != nil {
+		return err
+	}
+	md, err := metadata.Load(options.from)
+	if err != nil {
+		return fmt.Errorf("failed to load release metadata: %w", err)
+	}
+	if md.Snapshot {
+		return errPublishSnapshot
+	}
+	cfg.Dist = options.from
+
+	ctx, cancel := context.NewWithTimeout(cfg, options.timeout)
+	defer cancel()
+	md.Apply(ctx)
+	ctx.SkipTokenCheck = true
+
+	return ctrlc.Default.Run(ctx, func() error {
+		for _, step := range []publishStep{
+			{env.Pipe{}, env.Pipe{}.Run},                               // load and validate environment variables
+			{tmplcontext.RestorePipe{}, tmplcontext.RestorePipe{}.Run}, // restore the template context of the release
+			{docker.Pipe{}, docker.Pipe{}.Default},                     // set the docker defaults
+			{docker.ManifestPipe{}, docker.ManifestPipe{}.Default},     // set the docker manifest defaults
+			{docker.LoadPipe{}, docker.LoadPipe{}.Run},                 // load the saved docker images
+			{docker.Pipe{}, docker.Pipe{}.Publish},                     // push the docker images
+			{docker.ManifestPipe{}, docker.ManifestPipe{}.Publish},     // create and push the docker manifests
+		} {
+			if err := skip.Maybe(
+				step.pipe,
+				logging.Log(
+					step.pipe.String(),
+					errhandler.Handle(step.action),
+					logging.DefaultInitialPadding,
+				),
+			)(ctx); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/pipe/metadata"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+func setupPublishDist(tb testing.TB, snapshot bool) string {
+	tb.Helper()
+	dist := tb.TempDir()
+	require.NoError(tb, os.WriteFile(filepath.Join(dist, "config.yaml"), []byte(`project_name: foo
+dockers:
+  - image_templates: ['ghcr.io/foo/bar:{{ .Tag }}']
+    save: true
+`), 0o644))
+	require.NoError(tb, os.WriteFile(filepath.Join(dist, "ghcr.io_foo_bar_v1.0.0.docker.tar"), []byte("images"), 0o644))
+
+	ctx := context.New(config.Project{ProjectName: "foo", Dist: dist})
+	ctx.Git.CurrentTag = "v1.0.0"
+	ctx.Snapshot = snapshot
+	require.NoError(tb, metadata.Pipe{}.Run(ctx))
+	return dist
+}
+
+func TestPublish(t *testing.T) {
+	bin := t.TempDir()
+	calls := filepath.Join(bin, "docker.log")
+	require.NoError(t, os.WriteFile(filepath.Join(bin, "docker"), []byte("#!/bin/sh\necho \"$@\" >> "+calls+"\n"), 0o755))
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	dist := setupPublishDist(t, false)
+	cmd := newPublishCmd()
+	cmd.cmd.SetArgs([]string{"--from", dist})
+	require.NoError(t, cmd.cmd.Execute())
+
+	bts, err := os.ReadFile(calls)
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"load -i " + filepath.Join(dist, "ghcr.io_foo_bar_v1.0.0.docker.tar"),
+		"push ghcr.io/foo/bar:v1.0.0",
+	}, strings.Split(strings.TrimSpace(string(bts)), "\n"))
+}
+
+func TestPublishSnapshot(t *testing.T) {
+	cmd := newPublishCmd()
+	cmd.cmd.SetArgs([]string{"--from", setupPublishDist(t, true)})
+	require.EqualError(t, cmd.cmd.Execute(), errPublishSnapshot.Error())
+}
+
+func TestPublishMissingMetadata(t *testing.T) {
+	dist := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dist, "config.yaml"), []byte("project_name: foo\n"), 0o644))
+	cmd := newPublishCmd()
+	cmd.cmd.SetArgs([]string{"--from", dist})
+	err := cmd.cmd.Execute()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to load release metadata")
+}
@@ -7,9 +7,8 @@ import (
 	"github.com/apex/log"
 	"github.com/caarlos0/ctrlc"
 	"github.com/fatih/color"
-	"github.com/goreleaser/goreleaser/internal/middleware/errhandler"
-	"github.com/goreleaser/goreleaser/internal/middleware/logging"
-	"github.com/goreleaser/goreleaser/internal/middleware/skip"
+	"github.com/goreleaser/goreleaser/internal/deprecate"
+	"github.com/goreleaser/goreleaser/internal/metrics"
 	"github.com/goreleaser/goreleaser/internal/pipe/git"
 	"github.com/goreleaser/goreleaser/internal/pipeline"
 	"github.com/goreleaser/goreleaser/pkg/context"
@@ -31,6 +30,7 @@ type releaseOpts struct {
 	releaseFooterTmpl  string
 	autoSnapshot       bool
 	snapshot           bool
+	localRegistry      bool
 	skipPublish        bool
 	skipSign           bool
 	skipValidate       bool
@@ -38,6 +38,7 @@ type releaseOpts struct {
 	skipSBOMCataloging bool
 	rmDist             bool
 	deprecated         bool
+	failOnDeprecated   bool
 	parallelism        int
 	timeout            time.Duration
 }
@@ -59,12 +60,12 @@ func newReleaseCmd() *releaseCmd {
 
 			ctx, err := releaseProject(root.opts)
 			if err != nil {
-				return wrapError(err, color.New(color.Bold).Sprintf("release failed after %0.2fs", time.Since(start).Seconds()))
+				return wrapErrorWithCode(err, exitCode(err), color.New(color.Bold).Sprintf("release failed after %0.2fs", time.Since(start).Seconds()))
 			}
 
-			if ctx.Deprecated {
-				log.Warn(color.New(color.Bold).Sprintf("your config is using deprecated properties, check logs above for details"))
-			}
+			deprecate.Report(ctx)
+
+			reportUnchangedFiles(ctx)
 
 			log.Infof(color.New(color.Bold).Sprintf("release succeeded after %0.2fs", time.Since(start).Seconds()))
 			return nil
@@ -80,6 +81,7 @@ func newReleaseCmd() *releaseCmd {
 	cmd.Flags().StringVar(&root.opts.releaseFooterTmpl, "release-footer-tmpl", "", "Load custom release notes footer from a templated markdown file (overrides --release-footer)")
 	cmd.Flags().BoolVar(&root.opts.autoSnapshot, "auto-snapshot", false, "Automatically sets --snapshot if the repo is dirty")
 	cmd.Flags().BoolVar(&root.opts.snapshot, "snapshot", false, "Generate an unversioned snapshot release, skipping all validations and without publishing any artifacts (implies --skip-publish, --skip-announce and --skip-validate)")
+	cmd.Flags().BoolVar(&root.opts.localRegistry, "local-registry", false, "Push docker images and manifests to a temporary local registry (requires --snapshot)")
 	cmd.Flags().BoolVar(&root.opts.skipPublish, "skip-publish", false, "Skips publishing artifacts")
 	cmd.Flags().BoolVar(&root.opts.skipAnnounce, "skip-announce", false, "Skips announcing releases (implies --skip-validate)")
 	cmd.Flags().BoolVar(&root.opts.skipSign, "skip-sign", false, "Skips signing artifacts")
@@ -88,6 +90,7 @@ func newReleaseCmd() *releaseCmd {
 	cmd.Flags().BoolVar(&root.opts.rmDist, "rm-dist", false, "Removes the dist folder")
 	cmd.Flags().IntVarP(&root.opts.parallelism, "parallelism", "p", 0, "Amount tasks to run concurrently (default: number of CPUs)")
 	cmd.Flags().DurationVar(&root.opts.timeout, "timeout", 30*time.Minute, "Timeout to the entire release process")
+	cmd.Flags().BoolVar(&root.opts.failOnDeprecated, "fail-on-deprecated", false, "Fails if the config uses deprecated options, before anything is published")
 	cmd.Flags().BoolVar(&root.opts.deprecated, "deprecated", false, "Force print the deprecation message - tests only")
 	_ = cmd.Flags().MarkHidden("deprecated")
 
@@ -103,21 +106,24 @@ func releaseProject(options releaseOpts) (*context.Context, error) {
 	ctx, cancel := context.NewWithTimeout(cfg, options.timeout)
 	defer cancel()
 	setupReleaseContext(ctx, options)
-	return ctx, ctrlc.Default.Run(ctx, func() error {
-		for _, pipe := range pipeline.Pipeline {
-			if err := skip.Maybe(
-				pipe,
-				logging.Log(
-					pipe.String(),
-					errhandler.Handle(pipe.Run),
-					logging.DefaultInitialPadding,
-				),
-			)(ctx); err != nil {
-				return err
-			}
-		}
-		return nil
+	recorder := metrics.New()
+	err = ctrlc.Default.Run(ctx, func() error {
+		return pipeline.Run(ctx, pipeline.Pipeline, recorder, options.failOnDeprecated)
 	})
+	recorder.Push(ctx, err)
+	return ctx, err
+}
+
+// reportUnchangedFiles logs the files that were not committed to their
+// repositories because they were already up to date.
+func reportUnchangedFiles(ctx *context.Context) {
+	if len(ctx.UnchangedFiles) == 0 {
+		return
+	}
+	log.Infof(color.New(color.Bold).Sprintf("%d files were already up to date, skipped their commits:", len(ctx.UnchangedFiles)))
+	for _, f := range ctx.UnchangedFiles {
+		log.WithField("repository", f.Repo).Info(f.Path)
+	}
 }
 
 func setupReleaseContext(ctx *context.Context, options releaseOpts) *context.Context {
@@ -137,6 +143,7 @@ func setupReleaseContext(ctx *context.Context, options releaseOpts) *context.Con
 		log.Info("git repo is dirty and --auto-snapshot is set, implying --snapshot")
 		ctx.Snapshot = true
 	}
+	ctx.LocalRegistry.Enabled = options.localRegistry
 	ctx.SkipPublish = ctx.Snapshot || options.skipPublish
 	ctx.SkipAnnounce = ctx.Snapshot || options.skipPublish || options.skipAnnounce
 	ctx.SkipValidate = ctx.Snapshot || options.skipValidate
@@ -68,6 +68,16 @@ func TestReleaseFlags(t *testing.T) {
 		require.True(t, ctx.SkipAnnounce)
 	})
 
+	t.Run("local registry", func(t *testing.T) {
+		ctx := setup(releaseOpts{
+			snapshot:      true,
+			localRegistry: true,
+		})
+		require.True(t, ctx.LocalRegistry.Enabled)
+		require.Empty(t, ctx.LocalRegistry.Address)
+		require.True(t, ctx.SkipPublish)
+	})
+
 	t.Run("skips", func(t *testing.T) {
 		ctx := setup(releaseOpts{
 			skipPublish:  true,
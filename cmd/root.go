@@ -80,7 +80,12 @@ single .goreleaser.yaml file.
 	cmd.AddCommand(
 		newBuildCmd().cmd,
 		newReleaseCmd().cmd,
+		newAnnounceCmd().cmd,
+		newPublishCmd().cmd,
 		newCheckCmd().cmd,
+		newDoctorCmd().cmd,
+		newVerifyCmd().cmd,
+		newDiffCmd().cmd,
 		newInitCmd().cmd,
 		newDocsCmd().cmd,
 		newManCmd().cmd,
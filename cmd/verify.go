@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/apex/log"
+	"github.com/caarlos0/ctrlc"
+	"github.com/fatih/color"
+	"github.com/goreleaser/goreleaser/internal/client"
+	"github.com/goreleaser/goreleaser/internal/pipe/defaults"
+	"github.com/goreleaser/goreleaser/internal/pipe/effectiveconfig"
+	"github.com/goreleaser/goreleaser/internal/pipe/metadata"
+	"github.com/goreleaser/goreleaser/internal/pipe/semver"
+	"github.com/goreleaser/goreleaser/internal/pipeline"
+	"github.com/goreleaser/goreleaser/internal/verify"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/spf13/cobra"
+)
+
+type verifyCmd struct {
+	cmd  *cobra.Command
+	opts verifyOpts
+}
+
+type verifyOpts struct {
+	config  string
+	dist    string
+	key     string
+	timeout time.Duration
+}
+
+func newVerifyCmd() *verifyCmd {
+	root := &verifyCmd{}
+	cmd := &cobra.Command{
+		Use:   "verify [tag]",
+		Short: "Verifies the checksums and signatures of a release",
+		Long: `The ` + "`goreleaser verify`" + ` command checks the integrity of a release: the
+checksums of its files, and the signatures and certificates created by the
+signs configuration.
+
+Given a tag, it downloads the files of the published release.
+Otherwise, it verifies the release in the dist folder.
+
+A missing signature is a failure, unless the kind of the file it is for can't
+be told, e.g. the archives of a published release, in which case it is
+skipped.
+`,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		Args:          cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			start := time.Now()
+
+			log.Infof(color.New(color.Bold).Sprint("verifying..."))
+
+			var tag string
+			if len(args) > 0 {
+				tag = args[0]
+			}
+			if err := verifyRelease(root.opts, tag); err != nil {
+				return wrapError(err, color.New(color.Bold).Sprintf("verification failed after %0.2fs", time.Since(start).Seconds()))
+			}
+
+			log.Infof(color.New(color.Bold).Sprintf("verification succeeded after %0.2fs", time.Since(start).Seconds()))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&root.opts.dist, "dist", "dist", "The dist folder of the release to verify, if no tag is given")
+	cmd.Flags().StringVarP(&root.opts.config, "config", "f", "", "Load configuration from file (default: the effective configuration stored in the dist folder, or the project configuration if a tag is given)")
+	cmd.Flags().StringVar(&root.opts.key, "key", "", "Public key to verify cosign signatures with")
+	cmd.Flags().DurationVar(&root.opts.timeout, "timeout", 30*time.Minute, "Timeout to the entire verify process")
+
+	root.cmd = cmd
+	return root
+}
+
+func verifyRelease(options verifyOpts, tag string) error {
+	if tag == "" {
+		return verifyDist(options)
+	}
+	return verifyPublished(options, tag)
+}
+
+// verifyDist verifies the release built in the dist folder.
+func verifyDist(options verifyOpts) error {
+	path := options.config
+	if path == "" {
+		path = filepath.Join(options.dist, effectiveconfig.Filename)
+	}
+	cfg, err := config.Load(path)
+	if err != nil {
+		return err
+	}
+	md, err := metadata.Load(options.dist)
+	if err != nil {
+		return fmt.Errorf("failed to load release metadata: %w", err)
+	}
+	src, err := verify.Dir(options.dist)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.NewWithTimeout(cfg, options.timeout)
+	defer cancel()
+	md.Apply(ctx)
+
+	return ctrlc.Default.Run(ctx, func() error {
+		return verify.Run(ctx, src, options.key)
+	})
+}
+
+// verifyPublished downloads and verifies the release of the given tag.
+func verifyPublished(options verifyOpts, tag string) error {
+	cfg, err := loadConfig(options.config)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.NewWithTimeout(cfg, options.timeout)
+	defer cancel()
+	ctx.TokenType = tokenTypeOf(cfg)
+	ctx.Git.CurrentTag = tag
+	ctx.Version = strings.TrimPrefix(tag, "v")
+
+	return ctrlc.Default.Run(ctx, func() error {
+		for _, pipe := range []pipeline.Piper{
+			defaults.Pipe{}, // load default configs
+			semver.Pipe{},   // parse current tag to a semver
+		} {
+			if err := pipe.Run(ctx); err != nil {
+				return err
+			}
+		}
+		cli, err := client.New(ctx)
+		if err != nil {
+			return err
+		}
+		url, err := cli.ReleaseURLTemplate(ctx)
+		if err != nil {
+			return err
+		}
+		dir, err := os.MkdirTemp("", "goreleaserverify")
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(dir)
+		return verify.Run(ctx, verify.URL(url, dir), options.key)
+	})
+}
+
+// tokenTypeOf returns where the project is released to.
+func tokenTypeOf(cfg config.Project) context.TokenType {
+	switch {
+	case cfg.Release.GitLab.Name != "":
+		return context.TokenTypeGitLab
+	case cfg.Release.Gitea.Name != "":
+		return context.TokenTypeGitea
+	case cfg.Release.Bitbucket.Name != "":
+		return context.TokenTypeBitbucket
+	case cfg.Release.AzureDevOps.Name != "":
+		return context.TokenTypeAzureDevOps
+	default:
+		return context.TokenTypeGitHub
+	}
+}
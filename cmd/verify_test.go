@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/pipe/metadata"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+func setupVerifyDist(tb testing.TB) string {
+	tb.Helper()
+	dist := tb.TempDir()
+	require.NoError(tb, os.WriteFile(filepath.Join(dist, "config.yaml"), []byte(`project_name: foo
+checksum:
+  name_template: '{{ .ProjectName }}_{{ .Version }}_checksums.txt'
+  algorithm: sha256
+`), 0o644))
+	require.NoError(tb, os.WriteFile(filepath.Join(dist, "foo.txt"), []byte("foo"), 0o644))
+	require.NoError(tb, os.WriteFile(
+		filepath.Join(dist, "foo_1.0.0_checksums.txt"),
+		[]byte("2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae  foo.txt\n"),
+		0o644,
+	))
+
+	ctx := context.New(config.Project{ProjectName: "foo", Dist: dist})
+	ctx.Git.CurrentTag = "v1.0.0"
+	ctx.Version = "1.0.0"
+	require.NoError(tb, metadata.Pipe{}.Run(ctx))
+	return dist
+}
+
+func TestVerify(t *testing.T) {
+	cmd := newVerifyCmd()
+	cmd.cmd.SetArgs([]string{"--dist", setupVerifyDist(t)})
+	require.NoError(t, cmd.cmd.Execute())
+}
+
+func TestVerifyTampered(t *testing.T) {
+	dist := setupVerifyDist(t)
+	require.NoError(t, os.WriteFile(filepath.Join(dist, "foo.txt"), []byte("bar"), 0o644))
+
+	cmd := newVerifyCmd()
+	cmd.cmd.SetArgs([]string{"--dist", dist})
+	require.EqualError(t, cmd.cmd.Execute(), "1 verifications failed, check logs above for details")
+}
+
+func TestVerifyNoDist(t *testing.T) {
+	cmd := newVerifyCmd()
+	cmd.cmd.SetArgs([]string{"--dist", t.TempDir()})
+	require.Error(t, cmd.cmd.Execute())
+}
@@ -17,6 +17,8 @@ import (
 	"sync"
 
 	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/platforms"
+	"github.com/goreleaser/goreleaser/pkg/config"
 )
 
 // Type defines the type of an artifact.
@@ -67,12 +69,53 @@ const (
 	ScoopManifest
 	// SBOM is a Software Bill of Materials file.
 	SBOM
+	// UploadableArchivePart is a part of a split archive, or the manifest
+	// needed to join its parts back together.
+	UploadableArchivePart
+	// Provenance is a SLSA provenance attestation of the other artifacts.
+	Provenance
+	// Attestation is an in-toto attestation of another artifact.
+	Attestation
+	// DockerImageArchive is a tarball of Docker images, created with docker save.
+	DockerImageArchive
+	// GeneratedFile is a file rendered from a template by the files_generate
+	// pipe.
+	GeneratedFile
+	// Directory is a directory published as a whole, e.g. generated docs.
+	Directory
+	// AppImage is a linux AppImage, or the zsync file used to update it.
+	AppImage
+	// Nixpkg is an uploadable nix package expression.
+	Nixpkg
+	// TermuxBuildScript is a build.sh of a termux package.
+	TermuxBuildScript
+	// MSI is a windows installer.
+	MSI
+	// PublishableChocolatey is a chocolatey package yet to be published.
+	PublishableChocolatey
+	// WingetManifest is an uploadable winget manifest file.
+	WingetManifest
+	// DMG is a macOS disk image.
+	DMG
+	// MacPortsPortfile is an uploadable MacPorts Portfile.
+	MacPortsPortfile
+	// BrewBottle is a bottle of a brew formula.
+	BrewBottle
+	// PacmanPackage is an Arch Linux package, published to a pacman
+	// repository.
+	PacmanPackage
+
+	// lastType is not a type, it must stay at the end so all the types can
+	// be iterated.
+	lastType
 )
 
 func (t Type) String() string {
 	switch t {
 	case UploadableArchive:
 		return "Archive"
+	case UploadableArchivePart:
+		return "Archive Part"
 	case UploadableFile:
 		return "File"
 	case UploadableBinary, Binary, UniversalBinary:
@@ -83,6 +126,8 @@ func (t Type) String() string {
 		return "Docker Image"
 	case DockerManifest:
 		return "Docker Manifest"
+	case DockerImageArchive:
+		return "Docker Image Archive"
 	case PublishableSnapcraft, Snapcraft:
 		return "Snap"
 	case Checksum:
@@ -103,6 +148,34 @@ func (t Type) String() string {
 		return "Scoop Manifest"
 	case SBOM:
 		return "SBOM"
+	case Provenance:
+		return "Provenance"
+	case Attestation:
+		return "Attestation"
+	case GeneratedFile:
+		return "Generated File"
+	case Directory:
+		return "Directory"
+	case AppImage:
+		return "AppImage"
+	case Nixpkg:
+		return "Nixpkg"
+	case TermuxBuildScript:
+		return "Termux Build Script"
+	case MSI:
+		return "MSI"
+	case PublishableChocolatey:
+		return "Chocolatey"
+	case WingetManifest:
+		return "Winget Manifest"
+	case DMG:
+		return "DMG"
+	case MacPortsPortfile:
+		return "MacPorts Portfile"
+	case BrewBottle:
+		return "Brew Bottle"
+	case PacmanPackage:
+		return "Pacman Package"
 	case PkgBuild:
 		return "PKGBUILD"
 	case SrcInfo:
@@ -116,6 +189,18 @@ func (t Type) MarshalJSON() ([]byte, error) {
 	return []byte(fmt.Sprintf("%q", t)), nil
 }
 
+// ParseType returns the type with the given name, as written to the
+// artifacts.json file.
+// Names shared by several types, e.g. Binary, resolve to the uploadable one.
+func ParseType(name string) (Type, error) {
+	for t := UploadableArchive; t < lastType; t++ {
+		if t.String() == name {
+			return t, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid artifact type: %q", name)
+}
+
 const (
 	ExtraID        = "ID"
 	ExtraBinary    = "Binary"
@@ -126,6 +211,11 @@ const (
 	ExtraBinaries  = "Binaries"
 	ExtraRefresh   = "Refresh"
 	ExtraReplaces  = "Replaces"
+	ExtraUpload    = "Upload"
+	ExtraEncrypted = "Encrypted"
+
+	ExtraNotarizationID     = "NotarizationID"
+	ExtraNotarizationStatus = "NotarizationStatus"
 )
 
 // Extras represents the extra fields in an artifact.
@@ -160,6 +250,22 @@ func (a Artifact) String() string {
 	return a.Name
 }
 
+// Target returns the build target of the artifact, e.g. linux_arm_7, or an
+// empty string if it is not specific to a platform.
+func (a Artifact) Target() string {
+	if a.Goos == "" {
+		return ""
+	}
+	target := a.Goos + "_" + a.Goarch
+	if a.Goarm != "" {
+		return target + "_" + a.Goarm
+	}
+	if a.Gomips != "" {
+		return target + "_" + a.Gomips
+	}
+	return target
+}
+
 // ExtraOr returns the Extra field with the given key or the or value specified
 // if it is nil.
 func (a Artifact) ExtraOr(key string, or interface{}) interface{} {
@@ -320,6 +426,18 @@ func OnlyReplacingUnibins(a *Artifact) bool {
 	return a.ExtraOr(ExtraReplaces, true).(bool)
 }
 
+// OnlyUnencrypted removes encrypted archives, which package managers can't
+// extract.
+func OnlyUnencrypted(a *Artifact) bool {
+	return !a.ExtraOr(ExtraEncrypted, false).(bool)
+}
+
+// MarkedForUpload filters the artifacts that are only uploaded to the release
+// when asked to, like docker image tarballs.
+func MarkedForUpload(a *Artifact) bool {
+	return a.ExtraOr(ExtraUpload, false).(bool)
+}
+
 // ByGoos is a predefined filter that filters by the given goos.
 func ByGoos(s string) Filter {
 	return func(a *Artifact) bool {
@@ -348,6 +466,47 @@ func ByType(t Type) Filter {
 	}
 }
 
+// nolint: gochecknoglobals
+var typeNames = map[string][]Type{
+	"archive":        {UploadableArchive, UploadableArchivePart},
+	"binary":         {UploadableBinary},
+	"source":         {UploadableSourceArchive},
+	"package":        {LinuxPackage},
+	"appimage":       {AppImage},
+	"msi":            {MSI},
+	"dmg":            {DMG},
+	"sbom":           {SBOM},
+	"checksum":       {Checksum},
+	"signature":      {Signature},
+	"certificate":    {Certificate},
+	"file":           {UploadableFile},
+	"provenance":     {Provenance},
+	"attestation":    {Attestation},
+	"bottle":         {BrewBottle},
+	"docker_archive": {DockerImageArchive},
+	"directory":      {Directory},
+	"generated":      {GeneratedFile},
+	"pacman":         {PacmanPackage},
+	"chocolatey":     {PublishableChocolatey},
+}
+
+// ByTypeNames filters artifacts by the type names used in the configuration
+// file, e.g. archive, binary, source, package and sbom.
+// It errors if any of the given names is not a valid type name.
+func ByTypeNames(names ...string) (Filter, error) {
+	filters := make([]Filter, 0, len(names))
+	for _, name := range names {
+		types, ok := typeNames[name]
+		if !ok {
+			return nil, fmt.Errorf("invalid artifact type: %q", name)
+		}
+		for _, t := range types {
+			filters = append(filters, ByType(t))
+		}
+	}
+	return Or(filters...), nil
+}
+
 // ByFormats filters artifacts by a `Format` extra field.
 func ByFormats(formats ...string) Filter {
 	filters := make([]Filter, 0, len(formats))
@@ -375,6 +534,77 @@ func ByIDs(ids ...string) Filter {
 	return Or(filters...)
 }
 
+// ByPlatforms filters artifacts by the given targets or platform aliases.
+func ByPlatforms(defs []config.Platform, names ...string) Filter {
+	targets := platforms.Expand(defs, names)
+	return func(a *Artifact) bool {
+		return platforms.Matches(targets, a.Target())
+	}
+}
+
+// ByConfig filters artifacts matching all the given include criteria and
+// none of the exclude ones, as configured in the configuration file.
+// Empty criteria are ignored, and each criteria matches any of its values.
+// defs are the custom platforms the platform criteria can refer to.
+func ByConfig(include, exclude config.ArtifactFilters, defs []config.Platform) (Filter, error) {
+	filters := []Filter{}
+	if len(include.IDs) > 0 {
+		filters = append(filters, ByIDs(include.IDs...))
+	}
+	if len(include.Types) > 0 {
+		types, err := ByTypeNames(include.Types...)
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, types)
+	}
+	if len(include.Goos) > 0 {
+		filters = append(filters, byGoosList(include.Goos))
+	}
+	if len(include.Formats) > 0 {
+		filters = append(filters, ByFormats(include.Formats...))
+	}
+	if len(include.Platforms) > 0 {
+		filters = append(filters, ByPlatforms(defs, include.Platforms...))
+	}
+
+	excludes := []Filter{}
+	for _, id := range exclude.IDs {
+		id := id
+		excludes = append(excludes, func(a *Artifact) bool {
+			return a.ID() == id
+		})
+	}
+	if len(exclude.Types) > 0 {
+		types, err := ByTypeNames(exclude.Types...)
+		if err != nil {
+			return nil, fmt.Errorf("exclude: %w", err)
+		}
+		excludes = append(excludes, types)
+	}
+	if len(exclude.Goos) > 0 {
+		excludes = append(excludes, byGoosList(exclude.Goos))
+	}
+	if len(exclude.Formats) > 0 {
+		excludes = append(excludes, ByFormats(exclude.Formats...))
+	}
+	if len(exclude.Platforms) > 0 {
+		excludes = append(excludes, ByPlatforms(defs, exclude.Platforms...))
+	}
+	if len(excludes) > 0 {
+		filters = append(filters, Not(Or(excludes...)))
+	}
+	return And(filters...), nil
+}
+
+func byGoosList(goos []string) Filter {
+	filters := make([]Filter, 0, len(goos))
+	for _, s := range goos {
+		filters = append(filters, ByGoos(s))
+	}
+	return Or(filters...)
+}
+
 // Or performs an OR between all given filters.
 func Or(filters ...Filter) Filter {
 	return func(a *Artifact) bool {
@@ -399,6 +629,13 @@ func And(filters ...Filter) Filter {
 	}
 }
 
+// Not negates the given filter.
+func Not(filter Filter) Filter {
+	return func(a *Artifact) bool {
+		return !filter(a)
+	}
+}
+
 // Filter filters the artifact list, returning a new instance.
 // There are some pre-defined filters but anything of the Type Filter
 // is accepted.
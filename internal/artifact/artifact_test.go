@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"github.com/goreleaser/goreleaser/internal/golden"
+	"github.com/goreleaser/goreleaser/pkg/config"
 	"github.com/stretchr/testify/require"
 	"golang.org/x/sync/errgroup"
 )
@@ -106,6 +107,8 @@ func TestFilter(t *testing.T) {
 	require.Len(t, artifacts.Filter(OnlyReplacingUnibins).items, 6)
 	require.Len(t, artifacts.Filter(And(OnlyReplacingUnibins, ByGoos("darwin"))).items, 1)
 
+	require.Len(t, artifacts.Filter(OnlyUnencrypted).items, 7)
+
 	require.Len(t, artifacts.Filter(nil).items, 7)
 
 	require.Len(t, artifacts.Filter(
@@ -128,6 +131,12 @@ func TestFilter(t *testing.T) {
 	).List(), 2)
 }
 
+func TestOnlyUnencrypted(t *testing.T) {
+	require.True(t, OnlyUnencrypted(&Artifact{}))
+	require.True(t, OnlyUnencrypted(&Artifact{Extra: map[string]interface{}{ExtraEncrypted: false}}))
+	require.False(t, OnlyUnencrypted(&Artifact{Extra: map[string]interface{}{ExtraEncrypted: true}}))
+}
+
 func TestRemove(t *testing.T) {
 	data := []*Artifact{
 		{
@@ -364,6 +373,120 @@ func TestByFormats(t *testing.T) {
 	require.Len(t, artifacts.Filter(ByFormats("zip", "tar.gz")).items, 3)
 }
 
+func TestByTypeNames(t *testing.T) {
+	artifacts := New()
+	for _, a := range []*Artifact{
+		{Name: "archive", Type: UploadableArchive},
+		{Name: "binary", Type: UploadableBinary},
+		{Name: "deb", Type: LinuxPackage},
+		{Name: "rpm", Type: LinuxPackage},
+		{Name: "sbom", Type: SBOM},
+		{Name: "appimage", Type: AppImage},
+		{Name: "bottle", Type: BrewBottle},
+		{Name: "site", Type: Directory},
+	} {
+		artifacts.Add(a)
+	}
+
+	filter, err := ByTypeNames("package")
+	require.NoError(t, err)
+	require.Len(t, artifacts.Filter(filter).items, 2)
+
+	filter, err = ByTypeNames("appimage")
+	require.NoError(t, err)
+	require.Len(t, artifacts.Filter(filter).items, 1)
+
+	filter, err = ByTypeNames("archive", "sbom")
+	require.NoError(t, err)
+	require.Len(t, artifacts.Filter(filter).items, 2)
+
+	require.Len(t, artifacts.Filter(Not(filter)).items, 6)
+
+	filter, err = ByTypeNames("bottle", "directory")
+	require.NoError(t, err)
+	require.Len(t, artifacts.Filter(filter).items, 2)
+
+	_, err = ByTypeNames("archive", "nope")
+	require.EqualError(t, err, `invalid artifact type: "nope"`)
+}
+
+func TestByConfig(t *testing.T) {
+	artifacts := New()
+	for _, a := range []*Artifact{
+		{Name: "linux.tar.gz", Goos: "linux", Goarch: "amd64", Type: UploadableArchive, Extra: map[string]interface{}{ExtraID: "default", ExtraFormat: "tar.gz"}},
+		{Name: "windows.zip", Goos: "windows", Goarch: "amd64", Type: UploadableArchive, Extra: map[string]interface{}{ExtraID: "default", ExtraFormat: "zip"}},
+		{Name: "linux", Goos: "linux", Goarch: "amd64", Type: UploadableBinary, Extra: map[string]interface{}{ExtraID: "raw", ExtraFormat: "binary"}},
+		{Name: "foo.deb", Goos: "linux", Goarch: "amd64", Type: LinuxPackage, Extra: map[string]interface{}{ExtraID: "pkg", ExtraFormat: "deb"}},
+		{Name: "checksums.txt", Type: Checksum},
+	} {
+		artifacts.Add(a)
+	}
+
+	names := func(filter Filter) []string {
+		var result []string
+		for _, a := range artifacts.Filter(filter).List() {
+			result = append(result, a.Name)
+		}
+		return result
+	}
+
+	t.Run("empty", func(t *testing.T) {
+		filter, err := ByConfig(config.ArtifactFilters{}, config.ArtifactFilters{}, nil)
+		require.NoError(t, err)
+		require.Len(t, names(filter), 5)
+	})
+
+	t.Run("include", func(t *testing.T) {
+		filter, err := ByConfig(config.ArtifactFilters{
+			Types: []string{"archive", "binary"},
+			Goos:  []string{"linux"},
+		}, config.ArtifactFilters{}, nil)
+		require.NoError(t, err)
+		require.Equal(t, []string{"linux.tar.gz", "linux"}, names(filter))
+	})
+
+	t.Run("exclude", func(t *testing.T) {
+		filter, err := ByConfig(config.ArtifactFilters{}, config.ArtifactFilters{
+			IDs:     []string{"pkg"},
+			Formats: []string{"zip"},
+		}, nil)
+		require.NoError(t, err)
+		require.Equal(t, []string{"linux.tar.gz", "linux", "checksums.txt"}, names(filter))
+	})
+
+	t.Run("include and exclude", func(t *testing.T) {
+		filter, err := ByConfig(config.ArtifactFilters{
+			IDs: []string{"default"},
+		}, config.ArtifactFilters{
+			Goos: []string{"windows"},
+		}, nil)
+		require.NoError(t, err)
+		require.Equal(t, []string{"linux.tar.gz", "checksums.txt"}, names(filter))
+	})
+
+	t.Run("platforms", func(t *testing.T) {
+		filter, err := ByConfig(config.ArtifactFilters{
+			Platforms: []string{"desktop"},
+		}, config.ArtifactFilters{
+			Platforms: []string{"windows/amd64"},
+		}, []config.Platform{
+			{Name: "desktop", Targets: []string{"linux_amd64", "windows_amd64"}},
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{"linux.tar.gz", "linux", "foo.deb"}, names(filter))
+	})
+
+	t.Run("invalid include", func(t *testing.T) {
+		_, err := ByConfig(config.ArtifactFilters{Types: []string{"nope"}}, config.ArtifactFilters{}, nil)
+		require.EqualError(t, err, `invalid artifact type: "nope"`)
+	})
+
+	t.Run("invalid exclude", func(t *testing.T) {
+		_, err := ByConfig(config.ArtifactFilters{}, config.ArtifactFilters{Types: []string{"nope"}}, nil)
+		require.EqualError(t, err, `exclude: invalid artifact type: "nope"`)
+	})
+}
+
 func TestTypeToString(t *testing.T) {
 	for _, a := range []Type{
 		UploadableArchive,
@@ -386,8 +509,18 @@ func TestTypeToString(t *testing.T) {
 		KrewPluginManifest,
 		ScoopManifest,
 		SBOM,
+		UploadableArchivePart,
 		PkgBuild,
 		SrcInfo,
+		Nixpkg,
+		TermuxBuildScript,
+		MSI,
+		PublishableChocolatey,
+		WingetManifest,
+		DMG,
+		MacPortsPortfile,
+		BrewBottle,
+		PacmanPackage,
 	} {
 		t.Run(a.String(), func(t *testing.T) {
 			require.NotEqual(t, "unknown", a.String())
@@ -404,6 +537,34 @@ func TestTypeToString(t *testing.T) {
 	})
 }
 
+func TestParseType(t *testing.T) {
+	for name, expected := range map[string]Type{
+		"Archive":        UploadableArchive,
+		"Binary":         UploadableBinary,
+		"Checksum":       Checksum,
+		"Docker Image":   PublishableDockerImage,
+		"Pacman Package": PacmanPackage,
+	} {
+		t.Run(name, func(t *testing.T) {
+			typ, err := ParseType(name)
+			require.NoError(t, err)
+			require.Equal(t, expected, typ)
+		})
+	}
+	t.Run("unknown", func(t *testing.T) {
+		_, err := ParseType("unknown")
+		require.EqualError(t, err, `invalid artifact type: "unknown"`)
+	})
+	t.Run("all types", func(t *testing.T) {
+		for typ := UploadableArchive; typ < lastType; typ++ {
+			require.NotEqual(t, "unknown", typ.String())
+			parsed, err := ParseType(typ.String())
+			require.NoError(t, err)
+			require.Equal(t, typ.String(), parsed.String())
+		}
+	})
+}
+
 func TestPaths(t *testing.T) {
 	paths := []string{"a/b", "b/c", "d/e", "f/g"}
 	artifacts := New()
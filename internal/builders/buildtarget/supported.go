@@ -0,0 +1,210 @@
+package buildtarget
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/pkg/config"
+)
+
+// ErrUnsupportedTargets happens when some of the build targets are not
+// supported by the go binary being used.
+type ErrUnsupportedTargets struct {
+	id      string
+	targets map[string]string
+}
+
+func (e ErrUnsupportedTargets) Error() string {
+	var lines []string
+	for _, t := range sortedKeys(e.targets) {
+		lines = append(lines, fmt.Sprintf("  - %s: %s", t, e.targets[t]))
+	}
+	return fmt.Sprintf(
+		"build %q has targets that can't be built:\n%s\nupdate your go binary, remove them from the build matrix or set prune_unsupported_targets to skip them",
+		e.id,
+		strings.Join(lines, "\n"),
+	)
+}
+
+// Check verifies that the targets of the given build are supported by its
+// go binary, and warns about targets that need a go version newer than the
+// one declared in the go directive of go.mod.
+// Unsupported targets are either removed from the returned build or reported
+// as an error, depending on build.PruneUnsupportedTargets.
+func Check(build config.Build) (config.Build, error) {
+	dist, err := distList(build)
+	if err != nil {
+		log.WithError(err).Debug("could not list targets supported by the go binary, skipping target validation")
+		return build, nil
+	}
+	var version string
+	if bts, err := goVersion(build); err == nil {
+		if fields := strings.Fields(string(bts)); len(fields) > 2 {
+			version = fields[2]
+		}
+	}
+	unsupported := unsupportedTargets(build.Targets, dist, version)
+
+	var targets []string
+	for _, target := range build.Targets {
+		if _, ok := unsupported[target]; !ok {
+			targets = append(targets, target)
+		}
+	}
+
+	if len(unsupported) > 0 {
+		if !build.PruneUnsupportedTargets {
+			return build, ErrUnsupportedTargets{id: build.ID, targets: unsupported}
+		}
+		for _, target := range sortedKeys(unsupported) {
+			log.WithField("target", target).
+				WithField("reason", unsupported[target]).
+				Warn("skipping unsupported target")
+		}
+		build.Targets = targets
+	}
+
+	if required := goModVersion(build.Dir); required != "" {
+		for _, target := range targets {
+			min, ok := minGoVersion[osArch(target)]
+			if ok && versionLess(required, min) {
+				log.WithField("target", target).
+					WithField("go.mod", required).
+					Warnf("target requires go >= %s, consider updating the go directive of your go.mod", min)
+			}
+		}
+	}
+
+	return build, nil
+}
+
+// unsupportedTargets returns the targets not present in the given
+// `go tool dist list` output.
+// If the output can't be parsed, it assumes all targets are supported.
+func unsupportedTargets(targets []string, dist []byte, version string) map[string]string {
+	supported := map[string]bool{}
+	scanner := bufio.NewScanner(bytes.NewReader(dist))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.Count(line, "/") == 1 {
+			supported[line] = true
+		}
+	}
+
+	result := map[string]string{}
+	if len(supported) == 0 {
+		return result
+	}
+	if version == "" {
+		version = "the go binary being used"
+	}
+	for _, target := range targets {
+		if !supported[osArch(target)] {
+			result[target] = fmt.Sprintf("%s is not supported by %s", osArch(target), version)
+		}
+	}
+	return result
+}
+
+func distList(build config.Build) ([]byte, error) {
+	cmd := exec.Command(build.GoBinary, "tool", "dist", "list")
+	if fileInfo, err := os.Stat(build.Dir); err == nil && fileInfo.IsDir() {
+		cmd.Dir = build.Dir
+	}
+	bts, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("unable to list targets of go binary (%s): %w", build.GoBinary, err)
+	}
+	return bts, nil
+}
+
+// goModVersion returns the go directive of the go.mod file in the given dir,
+// or an empty string if there's none.
+func goModVersion(dir string) string {
+	bts, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+	if err != nil {
+		return ""
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(bts))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && fields[0] == "go" {
+			return fields[1]
+		}
+	}
+	return ""
+}
+
+// osArch converts a target like linux_arm_7 into linux/arm.
+func osArch(target string) string {
+	parts := strings.Split(target, "_")
+	if len(parts) < 2 {
+		return target
+	}
+	return parts[0] + "/" + parts[1]
+}
+
+// versionLess returns true if the go version a is older than b.
+// Versions that can't be parsed are never considered older.
+func versionLess(a, b string) bool {
+	av, ok := parseVersion(a)
+	if !ok {
+		return false
+	}
+	bv, ok := parseVersion(b)
+	if !ok {
+		return false
+	}
+	for i := range av {
+		if av[i] != bv[i] {
+			return av[i] < bv[i]
+		}
+	}
+	return false
+}
+
+func parseVersion(s string) ([2]int, bool) {
+	var result [2]int
+	parts := strings.Split(strings.TrimPrefix(s, "go"), ".")
+	if len(parts) < 2 {
+		return result, false
+	}
+	for i := range result {
+		n, err := strconv.Atoi(parts[i])
+		if err != nil {
+			return result, false
+		}
+		result[i] = n
+	}
+	return result, true
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// first go version supporting each of the more recent ports.
+// nolint: gochecknoglobals
+var minGoVersion = map[string]string{
+	"darwin/arm64":  "1.16",
+	"windows/arm64": "1.17",
+	"windows/arm":   "1.12",
+	"aix/ppc64":     "1.12",
+	"illumos/amd64": "1.14",
+	"freebsd/arm64": "1.14",
+	"linux/riscv64": "1.14",
+	"openbsd/arm64": "1.13",
+}
@@ -0,0 +1,108 @@
+package buildtarget
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnsupportedTargets(t *testing.T) {
+	dist := []byte("darwin/amd64\nlinux/386\nlinux/amd64\nlinux/arm\n")
+
+	t.Run("all supported", func(t *testing.T) {
+		require.Empty(t, unsupportedTargets([]string{
+			"darwin_amd64",
+			"linux_386",
+			"linux_arm_7",
+		}, dist, "go1.15"))
+	})
+
+	t.Run("some unsupported", func(t *testing.T) {
+		require.Equal(t, map[string]string{
+			"darwin_arm64":  "darwin/arm64 is not supported by go1.15",
+			"windows_arm64": "windows/arm64 is not supported by go1.15",
+		}, unsupportedTargets([]string{
+			"darwin_amd64",
+			"darwin_arm64",
+			"linux_arm_7",
+			"windows_arm64",
+		}, dist, "go1.15"))
+	})
+
+	t.Run("unknown go version", func(t *testing.T) {
+		require.Equal(t, map[string]string{
+			"darwin_arm64": "darwin/arm64 is not supported by the go binary being used",
+		}, unsupportedTargets([]string{"darwin_arm64"}, dist, ""))
+	})
+
+	t.Run("invalid dist list", func(t *testing.T) {
+		require.Empty(t, unsupportedTargets([]string{"darwin_arm64"}, []byte("go1.17\n"), "go1.17"))
+	})
+}
+
+func TestCheck(t *testing.T) {
+	build := config.Build{
+		ID:       "foo",
+		GoBinary: "go",
+		Dir:      ".",
+		Targets: []string{
+			"linux_amd64",
+			"linux_wasm",
+		},
+	}
+
+	t.Run("error", func(t *testing.T) {
+		_, err := Check(build)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), `build "foo" has targets that can't be built`)
+		require.Contains(t, err.Error(), "  - linux_wasm: linux/wasm is not supported by go")
+	})
+
+	t.Run("prune", func(t *testing.T) {
+		build := build
+		build.PruneUnsupportedTargets = true
+		result, err := Check(build)
+		require.NoError(t, err)
+		require.Equal(t, []string{"linux_amd64"}, result.Targets)
+	})
+
+	t.Run("invalid go binary", func(t *testing.T) {
+		build := build
+		build.GoBinary = "nope-go"
+		result, err := Check(build)
+		require.NoError(t, err)
+		require.Equal(t, build.Targets, result.Targets)
+	})
+}
+
+func TestGoModVersion(t *testing.T) {
+	dir := t.TempDir()
+	require.Empty(t, goModVersion(dir))
+	require.NoError(t, os.WriteFile(
+		filepath.Join(dir, "go.mod"),
+		[]byte("module foo\n\ngo 1.15\n\nrequire example.com/go v1.0.0\n"),
+		0o644,
+	))
+	require.Equal(t, "1.15", goModVersion(dir))
+}
+
+func TestVersionLess(t *testing.T) {
+	for _, tt := range []struct {
+		a, b string
+		less bool
+	}{
+		{"1.15", "1.16", true},
+		{"1.16", "1.16", false},
+		{"1.17", "1.16", false},
+		{"1.9", "1.16", true},
+		{"go1.21.3", "1.17", false},
+		{"devel", "1.17", false},
+	} {
+		t.Run(tt.a+"<"+tt.b, func(t *testing.T) {
+			require.Equal(t, tt.less, versionLess(tt.a, tt.b))
+		})
+	}
+}
@@ -66,7 +66,7 @@ func (*Builder) WithDefaults(build config.Build) (config.Build, error) {
 			return build, err
 		}
 	}
-	return build, nil
+	return buildtarget.Check(build)
 }
 
 // Build builds a golang build.
@@ -192,7 +192,7 @@ func processFlag(ctx *context.Context, a *artifact.Artifact, env []string, rawFl
 func run(ctx *context.Context, command, env []string, dir string) error {
 	/* #nosec */
 	cmd := exec.CommandContext(ctx, command[0], command[1:]...)
-	log := log.WithField("env", env).WithField("cmd", command)
+	log := log.FromContext(ctx).WithField("env", env).WithField("cmd", command)
 	cmd.Env = env
 	cmd.Dir = dir
 	log.Debug("running")
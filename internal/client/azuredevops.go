@@ -0,0 +1,568 @@
+package client
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/commitauthor"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+)
+
+const (
+	// DefaultAzureDevOpsAPIURL is the URL of the Azure DevOps Services API.
+	DefaultAzureDevOpsAPIURL = "https://dev.azure.com"
+	// DefaultAzureAuthorityHost is the URL of Microsoft Entra ID, used to
+	// exchange federated credentials for Azure DevOps tokens.
+	DefaultAzureAuthorityHost = "https://login.microsoftonline.com"
+
+	azureDevOpsAPIVersion = "7.1"
+	// azureDevOpsScope is the scope of the Azure DevOps resource in Entra ID.
+	azureDevOpsScope = "499b84ac-1321-427f-aa17-267ca6975798/.default"
+	// azureFederatedAudience is the audience Entra ID expects in federated
+	// tokens.
+	azureFederatedAudience = "api://AzureADTokenExchange"
+)
+
+// errAzureDevOpsNotFound is returned when the Azure DevOps API answers with a
+// 404.
+var errAzureDevOpsNotFound = errors.New("not found")
+
+var commitHashRe = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
+type azureDevOpsClient struct {
+	client *http.Client
+	api    string
+	auth   string
+	pat    string
+}
+
+// NewAzureDevOps returns an azure devops client implementation.
+//
+// The token is a personal access token. If it is empty, the federated
+// credential of the Entra ID application set in AZURE_CLIENT_ID and
+// AZURE_TENANT_ID is exchanged for an access token instead.
+func NewAzureDevOps(ctx *context.Context, token string) (Client, error) {
+	api, err := tmpl.New(ctx).Apply(ctx.Config.AzureDevOpsURLs.API)
+	if err != nil {
+		return nil, fmt.Errorf("templating Azure DevOps API URL: %w", err)
+	}
+	if _, err := url.ParseRequestURI(api); err != nil {
+		return nil, fmt.Errorf("invalid Azure DevOps API URL: %w", err)
+	}
+	transport := &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		TLSClientConfig: &tls.Config{
+			// nolint: gosec
+			InsecureSkipVerify: ctx.Config.AzureDevOpsURLs.SkipTLSVerify,
+		},
+	}
+	c := &azureDevOpsClient{
+		client: &http.Client{Transport: transport},
+		api:    strings.TrimSuffix(api, "/"),
+		pat:    token,
+	}
+	if token != "" {
+		c.auth = "Basic " + base64.StdEncoding.EncodeToString([]byte(":"+token))
+		return c, nil
+	}
+	accessToken, err := azureDevOpsAccessToken(ctx, c.client)
+	if err != nil {
+		return nil, err
+	}
+	c.auth = "Bearer " + accessToken
+	return c, nil
+}
+
+// CloseMilestone is not supported, as Azure Repos has no milestones.
+func (c *azureDevOpsClient) CloseMilestone(ctx *context.Context, repo Repo, title string) error {
+	return ErrNotImplemented
+}
+
+func (c *azureDevOpsClient) Changelog(ctx *context.Context, repo Repo, prev, current string) (string, error) {
+	var log []string
+	for {
+		query := url.Values{}
+		query.Add("searchCriteria.itemVersion.version", current)
+		query.Add("searchCriteria.itemVersion.versionType", azureDevOpsVersionType(current))
+		query.Add("searchCriteria.compareVersion.version", prev)
+		query.Add("searchCriteria.compareVersion.versionType", azureDevOpsVersionType(prev))
+		query.Add("searchCriteria.$top", "100")
+		query.Add("searchCriteria.$skip", fmt.Sprint(len(log)))
+		u, err := c.repoURL(repo, "commits", query)
+		if err != nil {
+			return "", err
+		}
+		var page struct {
+			Value []struct {
+				CommitID string `json:"commitId"`
+				Comment  string `json:"comment"`
+				Author   struct {
+					Name  string `json:"name"`
+					Email string `json:"email"`
+				} `json:"author"`
+			} `json:"value"`
+		}
+		if err := c.do(ctx, http.MethodGet, u, nil, &page); err != nil {
+			return "", err
+		}
+		for _, commit := range page.Value {
+			log = append(log, fmt.Sprintf(
+				"%s: %s (%s <%s>)",
+				shortHash(commit.CommitID),
+				strings.Split(commit.Comment, "\n")[0],
+				commit.Author.Name,
+				commit.Author.Email,
+			))
+		}
+		if len(page.Value) < 100 {
+			return strings.Join(log, "\n"), nil
+		}
+	}
+}
+
+// azureDevOpsVersionType returns whether the given version is a commit or a
+// tag.
+func azureDevOpsVersionType(version string) string {
+	if commitHashRe.MatchString(version) {
+		return "commit"
+	}
+	return "tag"
+}
+
+// GetDefaultBranch returns the default branch of the repository.
+func (c *azureDevOpsClient) GetDefaultBranch(ctx *context.Context, repo Repo) (string, error) {
+	u, err := c.repoURL(repo, "", nil)
+	if err != nil {
+		return "", err
+	}
+	var r struct {
+		DefaultBranch string `json:"defaultBranch"`
+	}
+	if err := c.do(ctx, http.MethodGet, u, nil, &r); err != nil {
+		log.FromContext(ctx).WithFields(log.Fields{
+			"projectID": repo.String(),
+			"err":       err.Error(),
+		}).Warn("error checking for default branch")
+		return "", err
+	}
+	return strings.TrimPrefix(r.DefaultBranch, "refs/heads/"), nil
+}
+
+// CreateFile creates a file in the repository at a given path
+// or updates the file if it exists.
+func (c *azureDevOpsClient) CreateFile(
+	ctx *context.Context,
+	commitAuthor config.CommitAuthor,
+	repo Repo,
+	content []byte,
+	path,
+	message string,
+) error {
+	branch := repo.Branch
+	if branch == "" {
+		var err error
+		branch, err = c.GetDefaultBranch(ctx, repo)
+		if err != nil {
+			return err
+		}
+	}
+	oldObjectID, err := c.ref(ctx, repo, "heads/"+branch)
+	if err != nil {
+		return err
+	}
+
+	query := url.Values{}
+	query.Add("path", path)
+	query.Add("versionDescriptor.version", branch)
+	query.Add("versionDescriptor.versionType", "branch")
+	query.Add("$format", "octetStream")
+	u, err := c.repoURL(repo, "items", query)
+	if err != nil {
+		return err
+	}
+	changeType := "edit"
+	var existing []byte
+	err = c.do(ctx, http.MethodGet, u, nil, &existing)
+	if errors.Is(err, errAzureDevOpsNotFound) {
+		changeType = "add"
+	} else if err != nil {
+		return err
+	} else if bytes.Equal(existing, content) {
+		skipUnchanged(ctx, repo, path)
+		return nil
+	}
+
+	committer := commitauthor.Committer(commitAuthor)
+	push := map[string]interface{}{
+		"refUpdates": []map[string]string{{
+			"name":        "refs/heads/" + branch,
+			"oldObjectId": oldObjectID,
+		}},
+		"commits": []map[string]interface{}{{
+			"comment": message,
+			"author": map[string]string{
+				"name":  commitAuthor.Name,
+				"email": commitAuthor.Email,
+			},
+			"committer": map[string]string{
+				"name":  committer.Name,
+				"email": committer.Email,
+			},
+			"changes": []map[string]interface{}{{
+				"changeType": changeType,
+				"item": map[string]string{
+					"path": "/" + strings.TrimPrefix(path, "/"),
+				},
+				"newContent": map[string]string{
+					"content":     base64.StdEncoding.EncodeToString(content),
+					"contentType": "base64encoded",
+				},
+			}},
+		}},
+	}
+	u, err = c.repoURL(repo, "pushes", nil)
+	if err != nil {
+		return err
+	}
+	return c.do(ctx, http.MethodPost, u, push, nil)
+}
+
+// CreateRelease creates the annotated tag of the release, if it doesn't exist
+// yet, with the release notes as its message.
+//
+// Azure Repos has no releases, so the tag is used as the release ID, and the
+// artifacts are published as an universal package to Azure Artifacts once
+// they are all uploaded.
+func (c *azureDevOpsClient) CreateRelease(ctx *context.Context, body string) (string, error) {
+	repo := azureDevOpsReleaseRepo(ctx)
+	tag := ctx.Git.CurrentTag
+	id, err := c.ref(ctx, repo, "tags/"+tag)
+	if err == nil && id != "" {
+		log.FromContext(ctx).WithField("tag", tag).Info("Azure DevOps tag already exists")
+		return tag, nil
+	}
+	if err != nil && !errors.Is(err, errAzureDevOpsNotFound) {
+		return "", err
+	}
+
+	u, err := c.repoURL(repo, "annotatedtags", nil)
+	if err != nil {
+		return "", err
+	}
+	// annotated tags require a message.
+	message := truncateReleaseBody(body)
+	if message == "" {
+		message = tag
+	}
+	if err := c.do(ctx, http.MethodPost, u, map[string]interface{}{
+		"name": tag,
+		"taggedObject": map[string]string{
+			"objectId": ctx.Git.FullCommit,
+		},
+		"message": message,
+	}, nil); err != nil {
+		return "", err
+	}
+	log.FromContext(ctx).WithField("tag", tag).Info("Azure DevOps tag created")
+	return tag, nil
+}
+
+// ReleaseURLTemplate is not supported, as the files of universal packages
+// can't be downloaded with plain URLs.
+func (c *azureDevOpsClient) ReleaseURLTemplate(ctx *context.Context) (string, error) {
+	return "", errors.New("Azure Artifacts universal packages have no download URLs, please set a url_template")
+}
+
+// Upload stages the file to be published in the universal package of the
+// release.
+func (c *azureDevOpsClient) Upload(
+	ctx *context.Context,
+	releaseID string,
+	artifact *artifact.Artifact,
+	file *os.File,
+) error {
+	dir := azureArtifactsDir(ctx, releaseID)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	dst, err := os.Create(filepath.Join(dir, artifact.Name))
+	if err != nil {
+		return err
+	}
+	defer dst.Close()
+	if _, err := io.Copy(dst, file); err != nil {
+		return err
+	}
+	return dst.Close()
+}
+
+// FinalizeRelease publishes the uploaded files as an universal package with
+// the az cli, as Azure Artifacts has no API to upload them.
+func (c *azureDevOpsClient) FinalizeRelease(ctx *context.Context, releaseID string) error {
+	dir := azureArtifactsDir(ctx, releaseID)
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		log.FromContext(ctx).Warn("no files to publish to Azure Artifacts")
+		return nil
+	}
+	repo := azureDevOpsReleaseRepo(ctx)
+	org, project, err := splitAzureDevOpsOwner(repo.Owner)
+	if err != nil {
+		return err
+	}
+	feed := ctx.Config.Release.AzureArtifacts
+	args := []string{
+		"artifacts", "universal", "publish",
+		"--organization", c.api + "/" + org,
+		"--scope", feed.Scope,
+		"--feed", feed.Feed,
+		"--name", feed.Package,
+		"--version", ctx.Version,
+		"--path", dir,
+	}
+	if feed.Scope == "project" {
+		args = append(args, "--project", project)
+	}
+	if feed.Description != "" {
+		description, err := tmpl.New(ctx).Apply(feed.Description)
+		if err != nil {
+			return fmt.Errorf("templating Azure Artifacts description: %w", err)
+		}
+		args = append(args, "--description", description)
+	}
+
+	/* #nosec */
+	cmd := exec.CommandContext(ctx, "az", args...)
+	cmd.Env = ctx.Env.Strings()
+	if c.pat != "" {
+		// otherwise, az uses the account it is logged in with.
+		cmd.Env = append(cmd.Env, "AZURE_DEVOPS_EXT_PAT="+c.pat)
+	}
+	log.FromContext(ctx).WithField("package", feed.Package).
+		WithField("feed", feed.Feed).
+		Info("publishing to Azure Artifacts")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to publish %s to Azure Artifacts: %w: %s", feed.Package, err, string(out))
+	}
+	return nil
+}
+
+// azureArtifactsDir returns the folder the files of the universal package of
+// the given release are staged in.
+func azureArtifactsDir(ctx *context.Context, releaseID string) string {
+	return filepath.Join(ctx.Config.Dist, "azure_artifacts", releaseID)
+}
+
+// azureDevOpsReleaseRepo returns the repository the project is released to.
+func azureDevOpsReleaseRepo(ctx *context.Context) Repo {
+	return Repo{
+		Owner: ctx.Config.Release.AzureDevOps.Owner,
+		Name:  ctx.Config.Release.AzureDevOps.Name,
+	}
+}
+
+// splitAzureDevOpsOwner splits the owner of an Azure DevOps repository into
+// its organization and project.
+func splitAzureDevOpsOwner(owner string) (string, string, error) {
+	parts := strings.Split(owner, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("invalid Azure DevOps owner %q, expected organization/project", owner)
+	}
+	return parts[0], parts[1], nil
+}
+
+// ref returns the object ID of the given ref, e.g. tags/v1.0.0.
+func (c *azureDevOpsClient) ref(ctx *context.Context, repo Repo, name string) (string, error) {
+	query := url.Values{}
+	query.Add("filter", name)
+	u, err := c.repoURL(repo, "refs", query)
+	if err != nil {
+		return "", err
+	}
+	var r struct {
+		Value []struct {
+			Name     string `json:"name"`
+			ObjectID string `json:"objectId"`
+		} `json:"value"`
+	}
+	if err := c.do(ctx, http.MethodGet, u, nil, &r); err != nil {
+		return "", err
+	}
+	// the filter matches prefixes, so refs/tags/v1.0.0-rc1 would also match.
+	for _, ref := range r.Value {
+		if ref.Name == "refs/"+name {
+			return ref.ObjectID, nil
+		}
+	}
+	return "", fmt.Errorf("ref %s: %w", name, errAzureDevOpsNotFound)
+}
+
+// repoURL returns the API URL of the given path of the repository.
+func (c *azureDevOpsClient) repoURL(repo Repo, path string, query url.Values) (string, error) {
+	org, project, err := splitAzureDevOpsOwner(repo.Owner)
+	if err != nil {
+		return "", err
+	}
+	u := c.api + "/" + url.PathEscape(org) + "/" + url.PathEscape(project) +
+		"/_apis/git/repositories/" + url.PathEscape(repo.Name)
+	if path != "" {
+		u += "/" + path
+	}
+	if query == nil {
+		query = url.Values{}
+	}
+	query.Set("api-version", azureDevOpsAPIVersion)
+	return u + "?" + query.Encode(), nil
+}
+
+// do sends the request, with the given body encoded as JSON, if not nil.
+// The JSON response is decoded into v, if not nil, or copied into v, if it
+// is a *[]byte.
+func (c *azureDevOpsClient) do(ctx *context.Context, method, u string, body, v interface{}) error {
+	var r io.Reader
+	if body != nil {
+		bts, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		r = bytes.NewReader(bts)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, r)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", c.auth)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	bts, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("%s %s: %w", method, req.URL.Path, errAzureDevOpsNotFound)
+	}
+	// Azure DevOps redirects unauthenticated requests to the sign in page.
+	if resp.StatusCode < 200 || resp.StatusCode > 299 || resp.StatusCode == http.StatusNonAuthoritativeInfo {
+		return fmt.Errorf("%s %s: %s: %s", method, req.URL.Path, resp.Status, strings.TrimSpace(string(bts)))
+	}
+	switch v := v.(type) {
+	case nil:
+		return nil
+	case *[]byte:
+		*v = bts
+		return nil
+	default:
+		return json.Unmarshal(bts, v)
+	}
+}
+
+// azureDevOpsAccessToken exchanges the federated credential of the CI for an
+// Azure DevOps access token of the Entra ID application set in
+// AZURE_CLIENT_ID and AZURE_TENANT_ID.
+func azureDevOpsAccessToken(ctx *context.Context, client *http.Client) (string, error) {
+	clientID := ctx.Env["AZURE_CLIENT_ID"]
+	tenantID := ctx.Env["AZURE_TENANT_ID"]
+	if clientID == "" || tenantID == "" {
+		return "", errors.New("azure devops: AZURE_CLIENT_ID and AZURE_TENANT_ID are required to use a federated credential")
+	}
+	assertion, err := azureFederatedToken(ctx, client)
+	if err != nil {
+		return "", err
+	}
+
+	authority := ctx.Env["AZURE_AUTHORITY_HOST"]
+	if authority == "" {
+		authority = DefaultAzureAuthorityHost
+	}
+	form := url.Values{}
+	form.Add("client_id", clientID)
+	form.Add("scope", azureDevOpsScope)
+	form.Add("grant_type", "client_credentials")
+	form.Add("client_assertion_type", "urn:ietf:params:oauth:client-assertion-type:jwt-bearer")
+	form.Add("client_assertion", assertion)
+	req, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodPost,
+		strings.TrimSuffix(authority, "/")+"/"+url.PathEscape(tenantID)+"/oauth2/v2.0/token",
+		strings.NewReader(form.Encode()),
+	)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	var result struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := doJSON(client, req, &result); err != nil {
+		return "", fmt.Errorf("azure devops: failed to exchange federated credential: %w", err)
+	}
+	return result.AccessToken, nil
+}
+
+// azureFederatedToken returns the federated token from the file in
+// AZURE_FEDERATED_TOKEN_FILE or, on GitHub Actions, from its OIDC provider.
+func azureFederatedToken(ctx *context.Context, client *http.Client) (string, error) {
+	if path := ctx.Env["AZURE_FEDERATED_TOKEN_FILE"]; path != "" {
+		bts, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("azure devops: failed to read federated token: %w", err)
+		}
+		return strings.TrimSpace(string(bts)), nil
+	}
+
+	requestURL := ctx.Env["ACTIONS_ID_TOKEN_REQUEST_URL"]
+	bearer := ctx.Env["ACTIONS_ID_TOKEN_REQUEST_TOKEN"]
+	if requestURL == "" || bearer == "" {
+		return "", errors.New("azure devops: no federated token available, set AZURE_FEDERATED_TOKEN_FILE or run on GitHub Actions with the id-token: write permission")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL+"&audience="+url.QueryEscape(azureFederatedAudience), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "bearer "+bearer)
+	var result struct {
+		Value string `json:"value"`
+	}
+	if err := doJSON(client, req, &result); err != nil {
+		return "", fmt.Errorf("azure devops: failed to get federated token: %w", err)
+	}
+	return result.Value, nil
+}
+
+// doJSON sends the request and decodes its JSON response into v.
+func doJSON(client *http.Client, req *http.Request, v interface{}) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	bts, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(bts)))
+	}
+	return json.Unmarshal(bts, v)
+}
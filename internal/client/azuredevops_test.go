@@ -0,0 +1,468 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+const azureDevOpsRepoPath = "/org/project/_apis/git/repositories/repo"
+
+func TestClientNewAzureDevOps(t *testing.T) {
+	ctx := &context.Context{
+		Config: config.Project{
+			AzureDevOpsURLs: config.AzureDevOpsURLs{
+				API: DefaultAzureDevOpsAPIURL,
+			},
+		},
+		TokenType: context.TokenTypeAzureDevOps,
+		Token:     "azuredevopstoken",
+	}
+	client, err := New(ctx)
+	require.NoError(t, err)
+	_, ok := client.(*azureDevOpsClient)
+	require.True(t, ok)
+	_, ok = client.(ReleaseFinalizer)
+	require.True(t, ok)
+}
+
+func TestClientNewAzureDevOpsInvalidURL(t *testing.T) {
+	ctx := &context.Context{
+		Config: config.Project{
+			AzureDevOpsURLs: config.AzureDevOpsURLs{
+				API: "://dev.azure.com",
+			},
+		},
+		TokenType: context.TokenTypeAzureDevOps,
+		Token:     "azuredevopstoken",
+	}
+	client, err := New(ctx)
+	require.Error(t, err)
+	require.Nil(t, client)
+}
+
+func TestAzureDevOpsPersonalAccessToken(t *testing.T) {
+	var auth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		require.Equal(t, "7.1", r.URL.Query().Get("api-version"))
+		fmt.Fprint(w, `{"defaultBranch":"refs/heads/main"}`)
+	}))
+	defer srv.Close()
+
+	ctx := newAzureDevOpsContext(t, srv.URL)
+	client, err := NewAzureDevOps(ctx, "token")
+	require.NoError(t, err)
+	branch, err := client.GetDefaultBranch(ctx, Repo{Owner: "org/project", Name: "repo"})
+	require.NoError(t, err)
+	require.Equal(t, "main", branch)
+	require.Equal(t, "Basic OnRva2Vu", auth)
+}
+
+func TestAzureDevOpsFederatedCredential(t *testing.T) {
+	newServer := func(t *testing.T, auth *string) *httptest.Server {
+		t.Helper()
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/actions/token":
+				require.Equal(t, "bearer actions-secret", r.Header.Get("Authorization"))
+				require.Equal(t, "api://AzureADTokenExchange", r.URL.Query().Get("audience"))
+				fmt.Fprint(w, `{"value":"github-oidc-token"}`)
+			case "/tenant/oauth2/v2.0/token":
+				require.NoError(t, r.ParseForm())
+				require.Equal(t, "client", r.PostForm.Get("client_id"))
+				require.Equal(t, "client_credentials", r.PostForm.Get("grant_type"))
+				require.Equal(t, "499b84ac-1321-427f-aa17-267ca6975798/.default", r.PostForm.Get("scope"))
+				require.Equal(t, "urn:ietf:params:oauth:client-assertion-type:jwt-bearer", r.PostForm.Get("client_assertion_type"))
+				fmt.Fprintf(w, `{"access_token":"entra-for-%s"}`, r.PostForm.Get("client_assertion"))
+			case azureDevOpsRepoPath:
+				*auth = r.Header.Get("Authorization")
+				fmt.Fprint(w, `{"defaultBranch":"refs/heads/main"}`)
+			default:
+				t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+			}
+		}))
+	}
+
+	for name, tt := range map[string]struct {
+		env  func(srv *httptest.Server) map[string]string
+		auth string
+	}{
+		"token file": {
+			env: func(srv *httptest.Server) map[string]string {
+				path := filepath.Join(t.TempDir(), "token")
+				require.NoError(t, os.WriteFile(path, []byte("file-oidc-token\n"), 0o600))
+				return map[string]string{"AZURE_FEDERATED_TOKEN_FILE": path}
+			},
+			auth: "Bearer entra-for-file-oidc-token",
+		},
+		"github actions": {
+			env: func(srv *httptest.Server) map[string]string {
+				return map[string]string{
+					"ACTIONS_ID_TOKEN_REQUEST_URL":   srv.URL + "/actions/token?api-version=2.0",
+					"ACTIONS_ID_TOKEN_REQUEST_TOKEN": "actions-secret",
+				}
+			},
+			auth: "Bearer entra-for-github-oidc-token",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var auth string
+			srv := newServer(t, &auth)
+			defer srv.Close()
+
+			ctx := newAzureDevOpsContext(t, srv.URL)
+			ctx.Env = context.Env{
+				"AZURE_CLIENT_ID":      "client",
+				"AZURE_TENANT_ID":      "tenant",
+				"AZURE_AUTHORITY_HOST": srv.URL,
+			}
+			for k, v := range tt.env(srv) {
+				ctx.Env[k] = v
+			}
+			client, err := NewAzureDevOps(ctx, "")
+			require.NoError(t, err)
+			_, err = client.GetDefaultBranch(ctx, Repo{Owner: "org/project", Name: "repo"})
+			require.NoError(t, err)
+			require.Equal(t, tt.auth, auth)
+		})
+	}
+
+	t.Run("no client", func(t *testing.T) {
+		ctx := newAzureDevOpsContext(t, DefaultAzureDevOpsAPIURL)
+		ctx.Env = context.Env{}
+		_, err := NewAzureDevOps(ctx, "")
+		require.EqualError(t, err, "azure devops: AZURE_CLIENT_ID and AZURE_TENANT_ID are required to use a federated credential")
+	})
+
+	t.Run("no federated token", func(t *testing.T) {
+		ctx := newAzureDevOpsContext(t, DefaultAzureDevOpsAPIURL)
+		ctx.Env = context.Env{
+			"AZURE_CLIENT_ID": "client",
+			"AZURE_TENANT_ID": "tenant",
+		}
+		_, err := NewAzureDevOps(ctx, "")
+		require.EqualError(t, err, "azure devops: no federated token available, set AZURE_FEDERATED_TOKEN_FILE or run on GitHub Actions with the id-token: write permission")
+	})
+
+	t.Run("exchange fails", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"error":"invalid_client"}`)
+		}))
+		defer srv.Close()
+		path := filepath.Join(t.TempDir(), "token")
+		require.NoError(t, os.WriteFile(path, []byte("oidc-token"), 0o600))
+
+		ctx := newAzureDevOpsContext(t, srv.URL)
+		ctx.Env = context.Env{
+			"AZURE_CLIENT_ID":            "client",
+			"AZURE_TENANT_ID":            "tenant",
+			"AZURE_AUTHORITY_HOST":       srv.URL,
+			"AZURE_FEDERATED_TOKEN_FILE": path,
+		}
+		_, err := NewAzureDevOps(ctx, "")
+		require.EqualError(t, err, `azure devops: failed to exchange federated credential: 401 Unauthorized: {"error":"invalid_client"}`)
+	})
+}
+
+func TestAzureDevOpsCreateRelease(t *testing.T) {
+	t.Run("new tag", func(t *testing.T) {
+		var created map[string]interface{}
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method + " " + r.URL.Path {
+			case "GET " + azureDevOpsRepoPath + "/refs":
+				require.Equal(t, "tags/v1.0.0", r.URL.Query().Get("filter"))
+				// the filter matches by prefix.
+				fmt.Fprint(w, `{"value":[{"name":"refs/tags/v1.0.0-rc1","objectId":"abc"}]}`)
+			case "POST " + azureDevOpsRepoPath + "/annotatedtags":
+				require.Equal(t, "application/json", r.Header.Get("Content-Type"))
+				require.NoError(t, json.NewDecoder(r.Body).Decode(&created))
+				w.WriteHeader(http.StatusCreated)
+				fmt.Fprint(w, `{}`)
+			default:
+				t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+			}
+		}))
+		defer srv.Close()
+
+		ctx := newAzureDevOpsContext(t, srv.URL)
+		client, err := NewAzureDevOps(ctx, "token")
+		require.NoError(t, err)
+		id, err := client.CreateRelease(ctx, "the changelog")
+		require.NoError(t, err)
+		require.Equal(t, "v1.0.0", id)
+		require.Equal(t, map[string]interface{}{
+			"name":         "v1.0.0",
+			"message":      "the changelog",
+			"taggedObject": map[string]interface{}{"objectId": "deadbeef"},
+		}, created)
+	})
+
+	t.Run("existing tag", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, http.MethodGet, r.Method)
+			fmt.Fprint(w, `{"value":[{"name":"refs/tags/v1.0.0","objectId":"abc"}]}`)
+		}))
+		defer srv.Close()
+
+		ctx := newAzureDevOpsContext(t, srv.URL)
+		client, err := NewAzureDevOps(ctx, "token")
+		require.NoError(t, err)
+		id, err := client.CreateRelease(ctx, "the changelog")
+		require.NoError(t, err)
+		require.Equal(t, "v1.0.0", id)
+	})
+
+	t.Run("invalid owner", func(t *testing.T) {
+		ctx := newAzureDevOpsContext(t, DefaultAzureDevOpsAPIURL)
+		ctx.Config.Release.AzureDevOps.Owner = "org"
+		client, err := NewAzureDevOps(ctx, "token")
+		require.NoError(t, err)
+		_, err = client.CreateRelease(ctx, "the changelog")
+		require.EqualError(t, err, `invalid Azure DevOps owner "org", expected organization/project`)
+	})
+}
+
+func TestAzureDevOpsCreateFile(t *testing.T) {
+	for name, tt := range map[string]struct {
+		existing   string
+		status     int
+		changeType string
+	}{
+		"new file":  {status: http.StatusNotFound, changeType: "add"},
+		"changed":   {existing: "old", status: http.StatusOK, changeType: "edit"},
+		"unchanged": {existing: "new", status: http.StatusOK},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var push map[string]interface{}
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.Method + " " + r.URL.Path {
+				case "GET " + azureDevOpsRepoPath:
+					fmt.Fprint(w, `{"defaultBranch":"refs/heads/main"}`)
+				case "GET " + azureDevOpsRepoPath + "/refs":
+					require.Equal(t, "heads/main", r.URL.Query().Get("filter"))
+					fmt.Fprint(w, `{"value":[{"name":"refs/heads/main","objectId":"cafebabe"}]}`)
+				case "GET " + azureDevOpsRepoPath + "/items":
+					require.Equal(t, "Formula/foo.rb", r.URL.Query().Get("path"))
+					require.Equal(t, "main", r.URL.Query().Get("versionDescriptor.version"))
+					w.WriteHeader(tt.status)
+					fmt.Fprint(w, tt.existing)
+				case "POST " + azureDevOpsRepoPath + "/pushes":
+					require.NoError(t, json.NewDecoder(r.Body).Decode(&push))
+					w.WriteHeader(http.StatusCreated)
+					fmt.Fprint(w, `{}`)
+				default:
+					t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+				}
+			}))
+			defer srv.Close()
+
+			ctx := newAzureDevOpsContext(t, srv.URL)
+			client, err := NewAzureDevOps(ctx, "token")
+			require.NoError(t, err)
+			author := config.CommitAuthor{
+				Name:      "goreleaserbot",
+				Email:     "bot@goreleaser.com",
+				Committer: config.Committer{Name: "ci", Email: "ci@goreleaser.com"},
+			}
+			repo := Repo{Owner: "org/project", Name: "repo"}
+			require.NoError(t, client.CreateFile(ctx, author, repo, []byte("new"), "Formula/foo.rb", "update foo"))
+
+			if tt.changeType == "" {
+				require.Nil(t, push)
+				require.Len(t, ctx.UnchangedFiles, 1)
+				return
+			}
+			require.Equal(t, map[string]interface{}{
+				"refUpdates": []interface{}{map[string]interface{}{
+					"name":        "refs/heads/main",
+					"oldObjectId": "cafebabe",
+				}},
+				"commits": []interface{}{map[string]interface{}{
+					"comment": "update foo",
+					"author": map[string]interface{}{
+						"name":  "goreleaserbot",
+						"email": "bot@goreleaser.com",
+					},
+					"committer": map[string]interface{}{
+						"name":  "ci",
+						"email": "ci@goreleaser.com",
+					},
+					"changes": []interface{}{map[string]interface{}{
+						"changeType": tt.changeType,
+						"item":       map[string]interface{}{"path": "/Formula/foo.rb"},
+						"newContent": map[string]interface{}{
+							"content":     "bmV3",
+							"contentType": "base64encoded",
+						},
+					}},
+				}},
+			}, push)
+		})
+	}
+}
+
+func TestAzureDevOpsChangelog(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, azureDevOpsRepoPath+"/commits", r.URL.Path)
+		query := r.URL.Query()
+		require.Equal(t, "v1.1.0", query.Get("searchCriteria.itemVersion.version"))
+		require.Equal(t, "tag", query.Get("searchCriteria.itemVersion.versionType"))
+		require.Equal(t, "0123456789abcdef0123456789abcdef01234567", query.Get("searchCriteria.compareVersion.version"))
+		require.Equal(t, "commit", query.Get("searchCriteria.compareVersion.versionType"))
+		fmt.Fprint(w, `{"value":[
+			{"commitId":"abcdef0123456789","comment":"second\n\nbody","author":{"name":"Bar","email":"bar@example.com"}},
+			{"commitId":"0123456789abcdef","comment":"first","author":{"name":"Foo","email":"foo@example.com"}}
+		]}`)
+	}))
+	defer srv.Close()
+
+	ctx := newAzureDevOpsContext(t, srv.URL)
+	client, err := NewAzureDevOps(ctx, "token")
+	require.NoError(t, err)
+	log, err := client.Changelog(ctx, Repo{Owner: "org/project", Name: "repo"}, "0123456789abcdef0123456789abcdef01234567", "v1.1.0")
+	require.NoError(t, err)
+	require.Equal(t, "abcdef0: second (Bar <bar@example.com>)\n0123456: first (Foo <foo@example.com>)", log)
+}
+
+func TestAzureDevOpsUploadAndFinalize(t *testing.T) {
+	for name, tt := range map[string]struct {
+		token  string
+		scope  string
+		args   string
+		patEnv string
+	}{
+		"project feed": {
+			token:  "token",
+			scope:  "project",
+			args:   "--scope project --feed releases --name foo --version 1.0.0 --path %s --project project --description foo 1.0.0",
+			patEnv: "token",
+		},
+		"organization feed with federated credential": {
+			scope: "organization",
+			args:  "--scope organization --feed releases --name foo --version 1.0.0 --path %s --description foo 1.0.0",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			calls := fakeAz(t, 0)
+			ctx := newAzureDevOpsContext(t, "https://dev.azure.com")
+			ctx.Config.Release.AzureArtifacts.Scope = tt.scope
+			client := &azureDevOpsClient{
+				client: http.DefaultClient,
+				api:    "https://dev.azure.com",
+				auth:   "Bearer entra",
+				pat:    tt.token,
+			}
+
+			path := filepath.Join(t.TempDir(), "bin.tar.gz")
+			require.NoError(t, os.WriteFile(path, []byte("archive"), 0o644))
+			file, err := os.Open(path)
+			require.NoError(t, err)
+			defer file.Close()
+			require.NoError(t, client.Upload(ctx, "v1.0.0", &artifact.Artifact{Name: "foo_1.0.0.tar.gz", Path: path}, file))
+
+			dir := filepath.Join(ctx.Config.Dist, "azure_artifacts", "v1.0.0")
+			bts, err := os.ReadFile(filepath.Join(dir, "foo_1.0.0.tar.gz"))
+			require.NoError(t, err)
+			require.Equal(t, "archive", string(bts))
+
+			require.NoError(t, client.FinalizeRelease(ctx, "v1.0.0"))
+			bts, err = os.ReadFile(calls)
+			require.NoError(t, err)
+			lines := strings.Split(strings.TrimSpace(string(bts)), "\n")
+			require.Equal(t, []string{
+				"artifacts universal publish --organization https://dev.azure.com/org " + fmt.Sprintf(tt.args, dir),
+				"pat=" + tt.patEnv,
+			}, lines)
+		})
+	}
+}
+
+func TestAzureDevOpsFinalizeFails(t *testing.T) {
+	fakeAz(t, 1)
+	ctx := newAzureDevOpsContext(t, "https://dev.azure.com")
+	client, err := NewAzureDevOps(ctx, "token")
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(filepath.Join(ctx.Config.Dist, "azure_artifacts", "v1.0.0"), 0o755))
+	err = client.(ReleaseFinalizer).FinalizeRelease(ctx, "v1.0.0")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to publish foo to Azure Artifacts")
+	require.Contains(t, err.Error(), "version already exists")
+}
+
+func TestAzureDevOpsFinalizeNothingUploaded(t *testing.T) {
+	calls := fakeAz(t, 0)
+	ctx := newAzureDevOpsContext(t, "https://dev.azure.com")
+	client, err := NewAzureDevOps(ctx, "token")
+	require.NoError(t, err)
+	require.NoError(t, client.(ReleaseFinalizer).FinalizeRelease(ctx, "v1.0.0"))
+	require.NoFileExists(t, calls)
+}
+
+func TestAzureDevOpsReleaseURLTemplate(t *testing.T) {
+	ctx := newAzureDevOpsContext(t, DefaultAzureDevOpsAPIURL)
+	client, err := NewAzureDevOps(ctx, "token")
+	require.NoError(t, err)
+	_, err = client.ReleaseURLTemplate(ctx)
+	require.EqualError(t, err, "Azure Artifacts universal packages have no download URLs, please set a url_template")
+}
+
+func TestAzureDevOpsCloseMilestone(t *testing.T) {
+	ctx := newAzureDevOpsContext(t, DefaultAzureDevOpsAPIURL)
+	client, err := NewAzureDevOps(ctx, "token")
+	require.NoError(t, err)
+	require.Equal(t, ErrNotImplemented, client.CloseMilestone(ctx, Repo{}, "v1.0.0"))
+}
+
+func newAzureDevOpsContext(tb testing.TB, api string) *context.Context {
+	ctx := context.New(config.Project{
+		AzureDevOpsURLs: config.AzureDevOpsURLs{
+			API: api,
+		},
+		Release: config.Release{
+			AzureDevOps: config.Repo{Owner: "org/project", Name: "repo"},
+			AzureArtifacts: config.AzureArtifacts{
+				Feed:        "releases",
+				Scope:       "project",
+				Package:     "foo",
+				Description: "{{ .ProjectName }} {{ .Version }}",
+			},
+		},
+	})
+	ctx.Config.ProjectName = "foo"
+	ctx.Config.Dist = tb.TempDir()
+	ctx.Git.CurrentTag = "v1.0.0"
+	ctx.Git.FullCommit = "deadbeef"
+	ctx.Version = "1.0.0"
+	return ctx
+}
+
+// fakeAz puts an az in the PATH that logs its arguments and the personal
+// access token it got, and exits with the given code.
+func fakeAz(tb testing.TB, code int) string {
+	tb.Helper()
+	dir := tb.TempDir()
+	calls := filepath.Join(dir, "calls.log")
+	script := fmt.Sprintf(`#!/bin/sh
+if [ %d -ne 0 ]; then
+	echo "version already exists"
+	exit %d
+fi
+echo "$@" >> %s
+echo "pat=$AZURE_DEVOPS_EXT_PAT" >> %s
+`, code, code, calls, calls)
+	require.NoError(tb, os.WriteFile(filepath.Join(dir, "az"), []byte(script), 0o755))
+	tb.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return calls
+}
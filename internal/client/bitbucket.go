@@ -0,0 +1,346 @@
+package client
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+)
+
+const (
+	// DefaultBitbucketAPIURL is the URL of the Bitbucket Cloud API.
+	DefaultBitbucketAPIURL = "https://api.bitbucket.org/2.0"
+	// DefaultBitbucketDownloadURL is the URL of Bitbucket Cloud.
+	DefaultBitbucketDownloadURL = "https://bitbucket.org"
+)
+
+// errBitbucketNotFound is returned when the Bitbucket API answers with a 404.
+var errBitbucketNotFound = errors.New("not found")
+
+type bitbucketClient struct {
+	client *http.Client
+	api    string
+	token  string
+}
+
+// NewBitbucket returns a bitbucket client implementation.
+//
+// The token is either a repository, project or workspace access token, or a
+// username:app_password pair.
+func NewBitbucket(ctx *context.Context, token string) (Client, error) {
+	api, err := tmpl.New(ctx).Apply(ctx.Config.BitbucketURLs.API)
+	if err != nil {
+		return nil, fmt.Errorf("templating Bitbucket API URL: %w", err)
+	}
+	if _, err := url.ParseRequestURI(api); err != nil {
+		return nil, fmt.Errorf("invalid Bitbucket API URL: %w", err)
+	}
+	transport := &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		TLSClientConfig: &tls.Config{
+			// nolint: gosec
+			InsecureSkipVerify: ctx.Config.BitbucketURLs.SkipTLSVerify,
+		},
+	}
+	return &bitbucketClient{
+		client: &http.Client{Transport: transport},
+		api:    strings.TrimSuffix(api, "/"),
+		token:  token,
+	}, nil
+}
+
+// CloseMilestone is not supported, as Bitbucket has no milestones.
+func (c *bitbucketClient) CloseMilestone(ctx *context.Context, repo Repo, title string) error {
+	return ErrNotImplemented
+}
+
+func (c *bitbucketClient) Changelog(ctx *context.Context, repo Repo, prev, current string) (string, error) {
+	query := url.Values{}
+	query.Add("include", current)
+	query.Add("exclude", prev)
+	next := c.repoURL(repo, "commits") + "?" + query.Encode()
+	var log []string
+	for next != "" {
+		var page struct {
+			Values []struct {
+				Hash    string `json:"hash"`
+				Message string `json:"message"`
+				Author  struct {
+					Raw string `json:"raw"`
+				} `json:"author"`
+			} `json:"values"`
+			Next string `json:"next"`
+		}
+		if err := c.do(ctx, http.MethodGet, next, nil, "", &page); err != nil {
+			return "", err
+		}
+		for _, commit := range page.Values {
+			log = append(log, fmt.Sprintf(
+				"%s: %s (%s)",
+				shortHash(commit.Hash),
+				strings.Split(commit.Message, "\n")[0],
+				commit.Author.Raw,
+			))
+		}
+		next = page.Next
+	}
+	return strings.Join(log, "\n"), nil
+}
+
+func shortHash(hash string) string {
+	if len(hash) > 7 {
+		return hash[:7]
+	}
+	return hash
+}
+
+// GetDefaultBranch returns the main branch of the repository.
+func (c *bitbucketClient) GetDefaultBranch(ctx *context.Context, repo Repo) (string, error) {
+	var r struct {
+		MainBranch struct {
+			Name string `json:"name"`
+		} `json:"mainbranch"`
+	}
+	if err := c.do(ctx, http.MethodGet, c.repoURL(repo, ""), nil, "", &r); err != nil {
+		log.FromContext(ctx).WithFields(log.Fields{
+			"projectID": repo.String(),
+			"err":       err.Error(),
+		}).Warn("error checking for default branch")
+		return "", err
+	}
+	return r.MainBranch.Name, nil
+}
+
+// CreateFile creates a file in the repository at a given path
+// or updates the file if it exists.
+func (c *bitbucketClient) CreateFile(
+	ctx *context.Context,
+	commitAuthor config.CommitAuthor,
+	repo Repo,
+	content []byte,
+	path,
+	message string,
+) error {
+	branch := repo.Branch
+	if branch == "" {
+		var err error
+		branch, err = c.GetDefaultBranch(ctx, repo)
+		if err != nil {
+			return err
+		}
+	}
+
+	existing, err := c.raw(ctx, c.repoURL(repo, "src/"+url.PathEscape(branch)+"/"+path))
+	if err != nil && !errors.Is(err, errBitbucketNotFound) {
+		return err
+	}
+	if err == nil && bytes.Equal(existing, content) {
+		skipUnchanged(ctx, repo, path)
+		return nil
+	}
+
+	body, contentType, err := multipartBody(map[string]string{
+		"message": message,
+		"branch":  branch,
+		"author":  fmt.Sprintf("%s <%s>", commitAuthor.Name, commitAuthor.Email),
+	}, path, path, bytes.NewReader(content))
+	if err != nil {
+		return err
+	}
+	return c.do(ctx, http.MethodPost, c.repoURL(repo, "src"), body, contentType, nil)
+}
+
+// CreateRelease creates the tag of the release, if it doesn't exist yet, with
+// the release notes as its message.
+//
+// Bitbucket has no releases, so the tag is used as the release ID, and the
+// artifacts are uploaded to the downloads of the repository.
+func (c *bitbucketClient) CreateRelease(ctx *context.Context, body string) (string, error) {
+	repo := bitbucketReleaseRepo(ctx)
+	tag := ctx.Git.CurrentTag
+	err := c.do(ctx, http.MethodGet, c.repoURL(repo, "refs/tags/"+url.PathEscape(tag)), nil, "", nil)
+	if err == nil {
+		log.FromContext(ctx).WithField("tag", tag).Info("Bitbucket tag already exists")
+		return tag, nil
+	}
+	if !errors.Is(err, errBitbucketNotFound) {
+		return "", err
+	}
+
+	payload := map[string]interface{}{
+		"name": tag,
+		"target": map[string]string{
+			"hash": ctx.Git.FullCommit,
+		},
+	}
+	if body != "" {
+		payload["message"] = truncateReleaseBody(body)
+	}
+	bts, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+	if err := c.do(ctx, http.MethodPost, c.repoURL(repo, "refs/tags"), bytes.NewReader(bts), "application/json", nil); err != nil {
+		return "", err
+	}
+	log.FromContext(ctx).WithField("tag", tag).Info("Bitbucket tag created")
+	return tag, nil
+}
+
+func (c *bitbucketClient) ReleaseURLTemplate(ctx *context.Context) (string, error) {
+	downloadURL, err := tmpl.New(ctx).Apply(ctx.Config.BitbucketURLs.Download)
+	if err != nil {
+		return "", fmt.Errorf("templating Bitbucket download URL: %w", err)
+	}
+
+	return fmt.Sprintf(
+		"%s/%s/%s/downloads/{{ .ArtifactName }}",
+		downloadURL,
+		ctx.Config.Release.Bitbucket.Owner,
+		ctx.Config.Release.Bitbucket.Name,
+	), nil
+}
+
+// ReleaseDownloads returns the download count of each file in the downloads
+// of the repository, as they are not tied to a tag.
+func (c *bitbucketClient) ReleaseDownloads(ctx *context.Context, tag string) (map[string]int, error) {
+	repo := bitbucketReleaseRepo(ctx)
+	result := map[string]int{}
+	next := c.repoURL(repo, "downloads")
+	for next != "" {
+		var page struct {
+			Values []struct {
+				Name      string `json:"name"`
+				Downloads int    `json:"downloads"`
+			} `json:"values"`
+			Next string `json:"next"`
+		}
+		if err := c.do(ctx, http.MethodGet, next, nil, "", &page); err != nil {
+			return nil, err
+		}
+		for _, download := range page.Values {
+			result[download.Name] = download.Downloads
+		}
+		next = page.Next
+	}
+	return result, nil
+}
+
+// Upload uploads a file to the downloads of the repository.
+func (c *bitbucketClient) Upload(
+	ctx *context.Context,
+	releaseID string,
+	artifact *artifact.Artifact,
+	file *os.File,
+) error {
+	repo := bitbucketReleaseRepo(ctx)
+	body, contentType, err := multipartBody(nil, "files", artifact.Name, file)
+	if err != nil {
+		return err
+	}
+	if err := c.do(ctx, http.MethodPost, c.repoURL(repo, "downloads"), body, contentType, nil); err != nil {
+		return RetriableError{err}
+	}
+	return nil
+}
+
+// bitbucketReleaseRepo returns the repository the project is released to.
+func bitbucketReleaseRepo(ctx *context.Context) Repo {
+	return Repo{
+		Owner: ctx.Config.Release.Bitbucket.Owner,
+		Name:  ctx.Config.Release.Bitbucket.Name,
+	}
+}
+
+// repoURL returns the API URL of the given path of the repository.
+func (c *bitbucketClient) repoURL(repo Repo, path string) string {
+	u := c.api + "/repositories/" + url.PathEscape(repo.Owner) + "/" + url.PathEscape(repo.Name)
+	if path != "" {
+		u += "/" + path
+	}
+	return u
+}
+
+// raw returns the raw body of the given URL.
+func (c *bitbucketClient) raw(ctx *context.Context, u string) ([]byte, error) {
+	var bts []byte
+	err := c.do(ctx, http.MethodGet, u, nil, "", &bts)
+	return bts, err
+}
+
+// do sends the request, decoding the JSON response into v, if not nil, or
+// copying it into v, if it is a *[]byte.
+func (c *bitbucketClient) do(ctx *context.Context, method, u string, body io.Reader, contentType string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
+	if err != nil {
+		return err
+	}
+	if parts := strings.SplitN(c.token, ":", 2); len(parts) == 2 {
+		req.SetBasicAuth(parts[0], parts[1])
+	} else {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	bts, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("%s %s: %w", method, req.URL.Path, errBitbucketNotFound)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%s %s: %s: %s", method, req.URL.Path, resp.Status, strings.TrimSpace(string(bts)))
+	}
+	switch v := v.(type) {
+	case nil:
+		return nil
+	case *[]byte:
+		*v = bts
+		return nil
+	default:
+		return json.Unmarshal(bts, v)
+	}
+}
+
+// multipartBody returns a multipart form with the given fields and file, and
+// its content type.
+func multipartBody(fields map[string]string, fileField, fileName string, file io.Reader) (io.Reader, string, error) {
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	for name, value := range fields {
+		if err := w.WriteField(name, value); err != nil {
+			return nil, "", err
+		}
+	}
+	part, err := w.CreateFormFile(fileField, fileName)
+	if err != nil {
+		return nil, "", err
+	}
+	if _, err := io.Copy(part, file); err != nil {
+		return nil, "", err
+	}
+	if err := w.Close(); err != nil {
+		return nil, "", err
+	}
+	return &buf, w.FormDataContentType(), nil
+}
@@ -0,0 +1,321 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientNewBitbucket(t *testing.T) {
+	ctx := &context.Context{
+		Config: config.Project{
+			BitbucketURLs: config.BitbucketURLs{
+				API: DefaultBitbucketAPIURL,
+			},
+		},
+		TokenType: context.TokenTypeBitbucket,
+		Token:     "bitbuckettoken",
+	}
+	client, err := New(ctx)
+	require.NoError(t, err)
+	_, ok := client.(*bitbucketClient)
+	require.True(t, ok)
+}
+
+func TestClientNewBitbucketInvalidURL(t *testing.T) {
+	ctx := &context.Context{
+		Config: config.Project{
+			BitbucketURLs: config.BitbucketURLs{
+				API: "://api.bitbucket.org/2.0",
+			},
+		},
+		TokenType: context.TokenTypeBitbucket,
+		Token:     "bitbuckettoken",
+	}
+	client, err := New(ctx)
+	require.Error(t, err)
+	require.Nil(t, client)
+}
+
+func TestBitbucketReleaseURLTemplate(t *testing.T) {
+	ctx := context.New(config.Project{
+		BitbucketURLs: config.BitbucketURLs{
+			API:      DefaultBitbucketAPIURL,
+			Download: "{{ .Env.BITBUCKET_URL }}",
+		},
+		Release: config.Release{
+			Bitbucket: config.Repo{Owner: "owner", Name: "name"},
+		},
+	})
+	ctx.Env["BITBUCKET_URL"] = "https://bitbucket.mycompany.com"
+	client, err := NewBitbucket(ctx, "token")
+	require.NoError(t, err)
+	tpl, err := client.ReleaseURLTemplate(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "https://bitbucket.mycompany.com/owner/name/downloads/{{ .ArtifactName }}", tpl)
+
+	ctx.Config.BitbucketURLs.Download = "{{ .Nope }"
+	_, err = client.ReleaseURLTemplate(ctx)
+	require.Error(t, err)
+}
+
+func TestBitbucketAuth(t *testing.T) {
+	for token, expected := range map[string]string{
+		"token":         "Bearer token",
+		"user:password": "Basic dXNlcjpwYXNzd29yZA==",
+	} {
+		t.Run(token, func(t *testing.T) {
+			var auth string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				auth = r.Header.Get("Authorization")
+				fmt.Fprint(w, `{"mainbranch":{"name":"main"}}`)
+			}))
+			defer srv.Close()
+
+			ctx := newBitbucketContext(srv.URL)
+			client, err := NewBitbucket(ctx, token)
+			require.NoError(t, err)
+			branch, err := client.GetDefaultBranch(ctx, Repo{Owner: "owner", Name: "name"})
+			require.NoError(t, err)
+			require.Equal(t, "main", branch)
+			require.Equal(t, expected, auth)
+		})
+	}
+}
+
+func TestBitbucketGetDefaultBranchErr(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, `{"error":{"message":"nope"}}`)
+	}))
+	defer srv.Close()
+
+	ctx := newBitbucketContext(srv.URL)
+	client, err := NewBitbucket(ctx, "token")
+	require.NoError(t, err)
+	_, err = client.GetDefaultBranch(ctx, Repo{Owner: "owner", Name: "name"})
+	require.EqualError(t, err, `GET /repositories/owner/name: 403 Forbidden: {"error":{"message":"nope"}}`)
+}
+
+func TestBitbucketCreateRelease(t *testing.T) {
+	t.Run("new tag", func(t *testing.T) {
+		var created map[string]interface{}
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method + " " + r.URL.Path {
+			case "GET /repositories/owner/name/refs/tags/v1.0.0":
+				w.WriteHeader(http.StatusNotFound)
+			case "POST /repositories/owner/name/refs/tags":
+				require.Equal(t, "application/json", r.Header.Get("Content-Type"))
+				require.NoError(t, json.NewDecoder(r.Body).Decode(&created))
+				w.WriteHeader(http.StatusCreated)
+			default:
+				t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+			}
+		}))
+		defer srv.Close()
+
+		ctx := newBitbucketContext(srv.URL)
+		client, err := NewBitbucket(ctx, "token")
+		require.NoError(t, err)
+		id, err := client.CreateRelease(ctx, "the changelog")
+		require.NoError(t, err)
+		require.Equal(t, "v1.0.0", id)
+		require.Equal(t, map[string]interface{}{
+			"name":    "v1.0.0",
+			"message": "the changelog",
+			"target":  map[string]interface{}{"hash": "deadbeef"},
+		}, created)
+	})
+
+	t.Run("existing tag", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, http.MethodGet, r.Method)
+			fmt.Fprint(w, `{"name":"v1.0.0"}`)
+		}))
+		defer srv.Close()
+
+		ctx := newBitbucketContext(srv.URL)
+		client, err := NewBitbucket(ctx, "token")
+		require.NoError(t, err)
+		id, err := client.CreateRelease(ctx, "the changelog")
+		require.NoError(t, err)
+		require.Equal(t, "v1.0.0", id)
+	})
+}
+
+func TestBitbucketUpload(t *testing.T) {
+	var name, content string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "POST /repositories/owner/name/downloads", r.Method+" "+r.URL.Path)
+		file, header, err := r.FormFile("files")
+		require.NoError(t, err)
+		bts, err := io.ReadAll(file)
+		require.NoError(t, err)
+		name, content = header.Filename, string(bts)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "bin.tar.gz")
+	require.NoError(t, os.WriteFile(path, []byte("archive"), 0o644))
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	ctx := newBitbucketContext(srv.URL)
+	client, err := NewBitbucket(ctx, "token")
+	require.NoError(t, err)
+	require.NoError(t, client.Upload(ctx, "v1.0.0", &artifact.Artifact{Name: "foo_1.0.0.tar.gz", Path: path}, file))
+	require.Equal(t, "foo_1.0.0.tar.gz", name)
+	require.Equal(t, "archive", content)
+}
+
+func TestBitbucketUploadFails(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "file.txt")
+	require.NoError(t, os.WriteFile(path, []byte("file"), 0o644))
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	ctx := newBitbucketContext(srv.URL)
+	client, err := NewBitbucket(ctx, "token")
+	require.NoError(t, err)
+	err = client.Upload(ctx, "v1.0.0", &artifact.Artifact{Name: "file.txt"}, file)
+	require.Error(t, err)
+	require.IsType(t, RetriableError{}, err)
+}
+
+func TestBitbucketCreateFile(t *testing.T) {
+	for name, tt := range map[string]struct {
+		existing   string
+		status     int
+		wantCommit bool
+	}{
+		"new file":  {status: http.StatusNotFound, wantCommit: true},
+		"changed":   {existing: "old", status: http.StatusOK, wantCommit: true},
+		"unchanged": {existing: "new", status: http.StatusOK},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var fields map[string]string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.Method + " " + r.URL.Path {
+				case "GET /repositories/owner/name":
+					fmt.Fprint(w, `{"mainbranch":{"name":"main"}}`)
+				case "GET /repositories/owner/name/src/main/Formula/foo.rb":
+					w.WriteHeader(tt.status)
+					fmt.Fprint(w, tt.existing)
+				case "POST /repositories/owner/name/src":
+					require.NoError(t, r.ParseMultipartForm(1<<20))
+					fields = map[string]string{}
+					for key, values := range r.MultipartForm.Value {
+						fields[key] = values[0]
+					}
+					file, _, err := r.FormFile("Formula/foo.rb")
+					require.NoError(t, err)
+					bts, err := io.ReadAll(file)
+					require.NoError(t, err)
+					fields["content"] = string(bts)
+					w.WriteHeader(http.StatusCreated)
+				default:
+					t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+				}
+			}))
+			defer srv.Close()
+
+			ctx := newBitbucketContext(srv.URL)
+			client, err := NewBitbucket(ctx, "token")
+			require.NoError(t, err)
+			author := config.CommitAuthor{Name: "goreleaserbot", Email: "bot@goreleaser.com"}
+			repo := Repo{Owner: "owner", Name: "name"}
+			require.NoError(t, client.CreateFile(ctx, author, repo, []byte("new"), "Formula/foo.rb", "update foo"))
+
+			if !tt.wantCommit {
+				require.Nil(t, fields)
+				require.Len(t, ctx.UnchangedFiles, 1)
+				return
+			}
+			require.Equal(t, map[string]string{
+				"message": "update foo",
+				"branch":  "main",
+				"author":  "goreleaserbot <bot@goreleaser.com>",
+				"content": "new",
+			}, fields)
+		})
+	}
+}
+
+func TestBitbucketChangelog(t *testing.T) {
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/repositories/owner/name/commits", r.URL.Path)
+		if r.URL.Query().Get("page") == "2" {
+			fmt.Fprint(w, `{"values":[{"hash":"0123456789abcdef","message":"first","author":{"raw":"Foo <foo@example.com>"}}]}`)
+			return
+		}
+		require.Equal(t, "v1.1.0", r.URL.Query().Get("include"))
+		require.Equal(t, "v1.0.0", r.URL.Query().Get("exclude"))
+		fmt.Fprintf(w, `{"values":[{"hash":"abcdef0123456789","message":"second\n\nbody","author":{"raw":"Bar <bar@example.com>"}}],"next":"%s/repositories/owner/name/commits?page=2"}`, srv.URL)
+	}))
+	defer srv.Close()
+
+	ctx := newBitbucketContext(srv.URL)
+	client, err := NewBitbucket(ctx, "token")
+	require.NoError(t, err)
+	log, err := client.Changelog(ctx, Repo{Owner: "owner", Name: "name"}, "v1.0.0", "v1.1.0")
+	require.NoError(t, err)
+	require.Equal(t, "abcdef0: second (Bar <bar@example.com>)\n0123456: first (Foo <foo@example.com>)", log)
+}
+
+func TestBitbucketReleaseDownloads(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/repositories/owner/name/downloads", r.URL.Path)
+		fmt.Fprint(w, `{"values":[{"name":"foo.tar.gz","downloads":10},{"name":"checksums.txt","downloads":2}]}`)
+	}))
+	defer srv.Close()
+
+	ctx := newBitbucketContext(srv.URL)
+	client, err := NewBitbucket(ctx, "token")
+	require.NoError(t, err)
+	counter, ok := client.(DownloadCounter)
+	require.True(t, ok)
+	downloads, err := counter.ReleaseDownloads(ctx, "v1.0.0")
+	require.NoError(t, err)
+	require.Equal(t, map[string]int{"foo.tar.gz": 10, "checksums.txt": 2}, downloads)
+}
+
+func TestBitbucketCloseMilestone(t *testing.T) {
+	ctx := newBitbucketContext(DefaultBitbucketAPIURL)
+	client, err := NewBitbucket(ctx, "token")
+	require.NoError(t, err)
+	require.Equal(t, ErrNotImplemented, client.CloseMilestone(ctx, Repo{}, "v1.0.0"))
+}
+
+func newBitbucketContext(api string) *context.Context {
+	ctx := context.New(config.Project{
+		BitbucketURLs: config.BitbucketURLs{
+			API:      api,
+			Download: DefaultBitbucketDownloadURL,
+		},
+		Release: config.Release{
+			Bitbucket: config.Repo{Owner: "owner", Name: "name"},
+		},
+	})
+	ctx.Git.CurrentTag = "v1.0.0"
+	ctx.Git.FullCommit = "deadbeef"
+	return ctx
+}
@@ -59,13 +59,63 @@ type GitHubClient interface {
 	GenerateReleaseNotes(ctx *context.Context, repo Repo, prev, current string) (string, error)
 }
 
+// DownloadCounter is implemented by the clients able to report how many times
+// the assets of a release were downloaded.
+type DownloadCounter interface {
+	// ReleaseDownloads returns the download count of each asset of the
+	// release with the given tag, keyed by the asset name.
+	ReleaseDownloads(ctx *context.Context, tag string) (map[string]int, error)
+}
+
+// Attester is implemented by the clients able to store the attestations of
+// the release assets.
+type Attester interface {
+	// CreateAttestation stores the given Sigstore bundle in the repository.
+	CreateAttestation(ctx *context.Context, repo Repo, bundle []byte) error
+}
+
+// DiscussionPoster is implemented by the clients able to post discussions in
+// a repository.
+type DiscussionPoster interface {
+	// CreateDiscussion creates a discussion in the category with the given
+	// name or slug, and returns its URL.
+	CreateDiscussion(ctx *context.Context, repo Repo, category, title, body string) (string, error)
+	// UpdateDiscussion replaces the title and body of the discussion with
+	// the given number.
+	UpdateDiscussion(ctx *context.Context, repo Repo, number int, title, body string) error
+}
+
+// PullRequestOpener is implemented by the clients able to open pull
+// requests.
+type PullRequestOpener interface {
+	// CreateBranch creates the branch of the given repository from its
+	// default branch, if it doesn't exist yet.
+	CreateBranch(ctx *context.Context, repo Repo) error
+	// OpenPullRequest opens a pull request from the branch of the head
+	// repository into the branch of the base repository, or its default
+	// branch, and returns its URL.
+	OpenPullRequest(ctx *context.Context, base, head Repo, title, body string) (string, error)
+}
+
+// ErrPullRequestNotSupported is returned when a pull request should be
+// opened with a client that isn't a PullRequestOpener.
+var ErrPullRequestNotSupported = fmt.Errorf("pull requests are only supported on GitHub")
+
+// ReleaseFinalizer is implemented by the clients that publish the assets of
+// a release all at once.
+type ReleaseFinalizer interface {
+	// FinalizeRelease is called once all the assets of the release with the
+	// given ID were uploaded.
+	FinalizeRelease(ctx *context.Context, releaseID string) error
+}
+
 // New creates a new client depending on the token type.
 func New(ctx *context.Context) (Client, error) {
 	return newWithToken(ctx, ctx.Token)
 }
 
 func newWithToken(ctx *context.Context, token string) (Client, error) {
-	log.WithField("type", ctx.TokenType).Debug("token type")
+	log.FromContext(ctx).WithField("type", ctx.TokenType).Debug("token type")
 	switch ctx.TokenType {
 	case context.TokenTypeGitHub:
 		return NewGitHub(ctx, token)
@@ -73,6 +123,10 @@ func newWithToken(ctx *context.Context, token string) (Client, error) {
 		return NewGitLab(ctx, token)
 	case context.TokenTypeGitea:
 		return NewGitea(ctx, token)
+	case context.TokenTypeBitbucket:
+		return NewBitbucket(ctx, token)
+	case context.TokenTypeAzureDevOps:
+		return NewAzureDevOps(ctx, token)
 	default:
 		return nil, fmt.Errorf("invalid client token type: %q", ctx.TokenType)
 	}
@@ -86,7 +140,7 @@ func NewIfToken(ctx *context.Context, cli Client, token string) (Client, error)
 	if err != nil {
 		return nil, err
 	}
-	log.Debug("using custom token")
+	log.FromContext(ctx).Debug("using custom token")
 	return newWithToken(ctx, token)
 }
 
@@ -12,6 +12,7 @@ import (
 	"code.gitea.io/sdk/gitea"
 	"github.com/apex/log"
 	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/commitauthor"
 	"github.com/goreleaser/goreleaser/internal/tmpl"
 	"github.com/goreleaser/goreleaser/pkg/config"
 	"github.com/goreleaser/goreleaser/pkg/context"
@@ -91,7 +92,7 @@ func (c *giteaClient) GetDefaultBranch(ctx *context.Context, repo Repo) (string,
 	projectID := repo.String()
 	p, res, err := c.client.GetRepo(repo.Owner, repo.Name)
 	if err != nil {
-		log.WithFields(log.Fields{
+		log.FromContext(ctx).WithFields(log.Fields{
 			"projectID":  projectID,
 			"statusCode": res.StatusCode,
 			"err":        err.Error(),
@@ -120,7 +121,7 @@ func (c *giteaClient) CreateFile(
 		branch, err = c.GetDefaultBranch(ctx, repo)
 		if err != nil {
 			// Fall back to 'master' 😭
-			log.WithFields(log.Fields{
+			log.FromContext(ctx).WithFields(log.Fields{
 				"fileName":        path,
 				"projectID":       repo.String(),
 				"requestedBranch": branch,
@@ -130,6 +131,7 @@ func (c *giteaClient) CreateFile(
 
 	}
 
+	committer := commitauthor.Committer(commitAuthor)
 	fileOptions := gitea.FileOptions{
 		Message:    message,
 		BranchName: branch,
@@ -138,8 +140,8 @@ func (c *giteaClient) CreateFile(
 			Email: commitAuthor.Email,
 		},
 		Committer: gitea.Identity{
-			Name:  commitAuthor.Name,
-			Email: commitAuthor.Email,
+			Name:  committer.Name,
+			Email: committer.Email,
 		},
 	}
 
@@ -156,6 +158,11 @@ func (c *giteaClient) CreateFile(
 		return err
 	}
 
+	if currentFile.Content != nil && sameBase64Content(*currentFile.Content, content) {
+		skipUnchanged(ctx, repo, path)
+		return nil
+	}
+
 	// update file
 	_, _, err = c.client.UpdateFile(repo.Owner, repo.Name, path, gitea.UpdateFileOptions{
 		FileOptions: fileOptions,
@@ -181,12 +188,12 @@ func (c *giteaClient) createRelease(ctx *context.Context, title, body string) (*
 	}
 	release, _, err := c.client.CreateRelease(owner, repoName, opts)
 	if err != nil {
-		log.WithFields(log.Fields{
+		log.FromContext(ctx).WithFields(log.Fields{
 			"err": err.Error(),
 		}).Debug("error creating Gitea release")
 		return nil, err
 	}
-	log.WithField("id", release.ID).Info("Gitea release created")
+	log.FromContext(ctx).WithField("id", release.ID).Info("Gitea release created")
 	return release, nil
 }
 
@@ -222,12 +229,12 @@ func (c *giteaClient) updateRelease(ctx *context.Context, title, body string, id
 
 	release, _, err := c.client.EditRelease(owner, repoName, id, opts)
 	if err != nil {
-		log.WithFields(log.Fields{
+		log.FromContext(ctx).WithFields(log.Fields{
 			"err": err.Error(),
 		}).Debug("error updating Gitea release")
 		return nil, err
 	}
-	log.WithField("id", release.ID).Info("Gitea release updated")
+	log.FromContext(ctx).WithField("id", release.ID).Info("Gitea release updated")
 	return release, nil
 }
 
@@ -283,6 +290,23 @@ func (c *giteaClient) ReleaseURLTemplate(ctx *context.Context) (string, error) {
 	), nil
 }
 
+// ReleaseDownloads returns the download count of each attachment of the given release.
+func (c *giteaClient) ReleaseDownloads(ctx *context.Context, tag string) (map[string]int, error) {
+	release, _, err := c.client.GetReleaseByTag(
+		ctx.Config.Release.Gitea.Owner,
+		ctx.Config.Release.Gitea.Name,
+		tag,
+	)
+	if err != nil {
+		return nil, err
+	}
+	result := map[string]int{}
+	for _, attachment := range release.Attachments {
+		result[attachment.Name] = int(attachment.DownloadCount)
+	}
+	return result, nil
+}
+
 // Upload uploads a file into a release repository.
 func (c *giteaClient) Upload(
 	ctx *context.Context,
@@ -2,17 +2,22 @@ package client
 
 import (
 	"crypto/tls"
+	"encoding/json"
 	"fmt"
+	"mime"
 	"net/http"
 	"net/url"
 	"os"
+	"path/filepath"
 	"reflect"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/apex/log"
 	"github.com/google/go-github/v41/github"
 	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/commitauthor"
 	"github.com/goreleaser/goreleaser/internal/tmpl"
 	"github.com/goreleaser/goreleaser/pkg/config"
 	"github.com/goreleaser/goreleaser/pkg/context"
@@ -94,7 +99,7 @@ func (c *githubClient) Changelog(ctx *context.Context, repo Repo, prev, current
 func (c *githubClient) GetDefaultBranch(ctx *context.Context, repo Repo) (string, error) {
 	p, res, err := c.client.Repositories.Get(ctx, repo.Owner, repo.Name)
 	if err != nil {
-		log.WithFields(log.Fields{
+		log.FromContext(ctx).WithFields(log.Fields{
 			"projectID":  repo.String(),
 			"statusCode": res.StatusCode,
 			"err":        err.Error(),
@@ -145,7 +150,7 @@ func (c *githubClient) CreateFile(
 		branch, err = c.GetDefaultBranch(ctx, repo)
 		if err != nil {
 			// Fall back to sdk default
-			log.WithFields(log.Fields{
+			log.FromContext(ctx).WithFields(log.Fields{
 				"fileName":        path,
 				"projectID":       repo.String(),
 				"requestedBranch": branch,
@@ -153,11 +158,16 @@ func (c *githubClient) CreateFile(
 			}).Warn("error checking for default branch, using master")
 		}
 	}
+	committer := commitauthor.Committer(commitAuthor)
 	options := &github.RepositoryContentFileOptions{
-		Committer: &github.CommitAuthor{
+		Author: &github.CommitAuthor{
 			Name:  github.String(commitAuthor.Name),
 			Email: github.String(commitAuthor.Email),
 		},
+		Committer: &github.CommitAuthor{
+			Name:  github.String(committer.Name),
+			Email: github.String(committer.Email),
+		},
 		Content: content,
 		Message: github.String(message),
 	}
@@ -179,6 +189,15 @@ func (c *githubClient) CreateFile(
 		return err
 	}
 
+	if res.StatusCode != 404 {
+		if existing, err := file.GetContent(); err == nil && existing == string(content) {
+			skipUnchanged(ctx, repo, path)
+			return nil
+		}
+	}
+	if commitAuthor.Signing.Enabled {
+		return c.createSignedFile(ctx, commitAuthor, repo, branch, content, path, message)
+	}
 	if res.StatusCode == 404 {
 		_, _, err = c.client.Repositories.CreateFile(
 			ctx,
@@ -200,6 +219,97 @@ func (c *githubClient) CreateFile(
 	return err
 }
 
+// createSignedFile commits the file with the git data API, which, unlike the
+// contents API, takes the signature of the commit.
+func (c *githubClient) createSignedFile(
+	ctx *context.Context,
+	commitAuthor config.CommitAuthor,
+	repo Repo,
+	branch string,
+	content []byte,
+	path,
+	message string,
+) error {
+	if branch == "" {
+		branch = "master"
+	}
+	ref, _, err := c.client.Git.GetRef(ctx, repo.Owner, repo.Name, "heads/"+branch)
+	if err != nil {
+		return err
+	}
+	parent, _, err := c.client.Git.GetCommit(ctx, repo.Owner, repo.Name, ref.GetObject().GetSHA())
+	if err != nil {
+		return err
+	}
+	tree, _, err := c.client.Git.CreateTree(ctx, repo.Owner, repo.Name, parent.GetTree().GetSHA(), []*github.TreeEntry{{
+		Path:    github.String(path),
+		Mode:    github.String("100644"),
+		Type:    github.String("blob"),
+		Content: github.String(string(content)),
+	}})
+	if err != nil {
+		return err
+	}
+
+	// the dates are part of the signed payload, so they must be the ones
+	// GitHub puts in the commit.
+	date := time.Now().UTC().Truncate(time.Second)
+	committer := commitauthor.Committer(commitAuthor)
+	commit := &github.Commit{
+		Message: github.String(message),
+		Tree:    &github.Tree{SHA: tree.SHA},
+		Parents: []*github.Commit{{SHA: parent.SHA}},
+		Author: &github.CommitAuthor{
+			Name:  github.String(commitAuthor.Name),
+			Email: github.String(commitAuthor.Email),
+			Date:  &date,
+		},
+		Committer: &github.CommitAuthor{
+			Name:  github.String(committer.Name),
+			Email: github.String(committer.Email),
+			Date:  &date,
+		},
+	}
+	signature, err := commitauthor.Sign(ctx, commitAuthor, commitPayload(commit))
+	if err != nil {
+		return err
+	}
+	commit.Verification = &github.SignatureVerification{Signature: github.String(signature)}
+	created, _, err := c.client.Git.CreateCommit(ctx, repo.Owner, repo.Name, commit)
+	if err != nil {
+		return err
+	}
+
+	ref.Object.SHA = created.SHA
+	_, _, err = c.client.Git.UpdateRef(ctx, repo.Owner, repo.Name, ref, false)
+	return err
+}
+
+// commitPayload returns the git commit object of the given commit, without
+// its signature, which is what gets signed.
+func commitPayload(commit *github.Commit) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "tree %s\n", commit.GetTree().GetSHA())
+	for _, parent := range commit.Parents {
+		fmt.Fprintf(&b, "parent %s\n", parent.GetSHA())
+	}
+	for _, person := range []struct {
+		role   string
+		author *github.CommitAuthor
+	}{{"author", commit.Author}, {"committer", commit.Committer}} {
+		fmt.Fprintf(
+			&b, "%s %s <%s> %d %s\n",
+			person.role,
+			person.author.GetName(),
+			person.author.GetEmail(),
+			person.author.GetDate().Unix(),
+			person.author.GetDate().Format("-0700"),
+		)
+	}
+	fmt.Fprintf(&b, "\n%s", commit.GetMessage())
+	return []byte(b.String())
+}
+
 func (c *githubClient) CreateRelease(ctx *context.Context, body string) (string, error) {
 	var release *github.RepositoryRelease
 	title, err := tmpl.New(ctx).Apply(ctx.Config.Release.NameTemplate)
@@ -244,7 +354,7 @@ func (c *githubClient) CreateRelease(ctx *context.Context, body string) (string,
 			data,
 		)
 	}
-	log.WithField("url", release.GetHTMLURL()).Info("release updated")
+	log.FromContext(ctx).WithField("url", release.GetHTMLURL()).Info("release updated")
 	githubReleaseID := strconv.FormatInt(release.GetID(), 10)
 	return githubReleaseID, err
 }
@@ -273,23 +383,324 @@ func (c *githubClient) Upload(
 	if err != nil {
 		return err
 	}
-	_, resp, err := c.client.Repositories.UploadReleaseAsset(
-		ctx,
+	sum, err := artifact.Checksum("sha256")
+	if err != nil {
+		return err
+	}
+	digest := "sha256:" + sum
+
+	asset, resp, err := c.uploadAsset(ctx, githubReleaseID, artifact.Name, file)
+	if err == nil {
+		return c.checkDigest(ctx, asset, digest)
+	}
+	if resp != nil && resp.StatusCode == 422 {
+		// a previous try might have uploaded it already.
+		existing, ferr := c.findAsset(ctx, githubReleaseID, artifact.Name)
+		if ferr == nil && existing != nil && existing.Digest == digest {
+			log.FromContext(ctx).WithField("name", artifact.Name).Info("identical asset already uploaded, skipping")
+			return nil
+		}
+		return err
+	}
+	return RetriableError{err}
+}
+
+// releaseAsset is a release asset along with its digest, which go-github
+// doesn't have yet.
+// Older GitHub Enterprise Server versions don't report digests at all.
+type releaseAsset struct {
+	ID     int64  `json:"id"`
+	Name   string `json:"name"`
+	Digest string `json:"digest"`
+}
+
+// uploadAsset uploads the file like UploadReleaseAsset does, but keeps the
+// asset digest from the response.
+func (c *githubClient) uploadAsset(ctx *context.Context, releaseID int64, name string, file *os.File) (*releaseAsset, *github.Response, error) {
+	stat, err := file.Stat()
+	if err != nil {
+		return nil, nil, err
+	}
+	if stat.IsDir() {
+		return nil, nil, fmt.Errorf("the asset to upload can't be a directory")
+	}
+	mediaType := mime.TypeByExtension(filepath.Ext(file.Name()))
+	if mediaType == "" {
+		mediaType = "application/octet-stream"
+	}
+	u := fmt.Sprintf(
+		"repos/%s/%s/releases/%d/assets?name=%s",
 		ctx.Config.Release.GitHub.Owner,
 		ctx.Config.Release.GitHub.Name,
-		githubReleaseID,
-		&github.UploadOptions{
-			Name: artifact.Name,
-		},
-		file,
+		releaseID,
+		url.QueryEscape(name),
 	)
+	req, err := c.client.NewUploadRequest(u, file, stat.Size(), mediaType)
+	if err != nil {
+		return nil, nil, err
+	}
+	asset := &releaseAsset{}
+	resp, err := c.client.Do(ctx, req, asset)
+	if err != nil {
+		return nil, resp, err
+	}
+	return asset, resp, nil
+}
+
+// checkDigest checks the digest GitHub computed for the uploaded asset
+// against the local one, deleting the asset if they don't match, so the
+// upload can be retried.
+func (c *githubClient) checkDigest(ctx *context.Context, asset *releaseAsset, digest string) error {
+	if asset.Digest == "" || asset.Digest == digest {
+		return nil
+	}
+	if _, err := c.client.Repositories.DeleteReleaseAsset(
+		ctx,
+		ctx.Config.Release.GitHub.Owner,
+		ctx.Config.Release.GitHub.Name,
+		asset.ID,
+	); err != nil {
+		return fmt.Errorf("failed to delete corrupted asset %s: %w", asset.Name, err)
+	}
+	return RetriableError{fmt.Errorf("uploaded asset %s has digest %s, expected %s", asset.Name, asset.Digest, digest)}
+}
+
+// findAsset returns the asset of the release with the given name, if any.
+func (c *githubClient) findAsset(ctx *context.Context, releaseID int64, name string) (*releaseAsset, error) {
+	for page := 1; page != 0; {
+		u := fmt.Sprintf(
+			"repos/%s/%s/releases/%d/assets?per_page=100&page=%d",
+			ctx.Config.Release.GitHub.Owner,
+			ctx.Config.Release.GitHub.Name,
+			releaseID,
+			page,
+		)
+		req, err := c.client.NewRequest(http.MethodGet, u, nil)
+		if err != nil {
+			return nil, err
+		}
+		var assets []*releaseAsset
+		resp, err := c.client.Do(ctx, req, &assets)
+		if err != nil {
+			return nil, err
+		}
+		for _, asset := range assets {
+			if asset.Name == name {
+				return asset, nil
+			}
+		}
+		page = resp.NextPage
+	}
+	return nil, nil
+}
+
+// CreateAttestation stores the given Sigstore bundle with the attestations
+// of the repository, which links it to the release assets it attests.
+func (c *githubClient) CreateAttestation(ctx *context.Context, repo Repo, bundle []byte) error {
+	u := fmt.Sprintf("repos/%s/%s/attestations", repo.Owner, repo.Name)
+	req, err := c.client.NewRequest(http.MethodPost, u, map[string]json.RawMessage{
+		"bundle": bundle,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = c.client.Do(ctx, req, nil)
+	return err
+}
+
+// CreateBranch creates the branch of the repository from the head of its
+// default branch.
+func (c *githubClient) CreateBranch(ctx *context.Context, repo Repo) error {
+	_, res, err := c.client.Git.GetRef(ctx, repo.Owner, repo.Name, "refs/heads/"+repo.Branch)
 	if err == nil {
 		return nil
 	}
-	if resp != nil && resp.StatusCode == 422 {
+	if res == nil || res.StatusCode != http.StatusNotFound {
 		return err
 	}
-	return RetriableError{err}
+	branch, err := c.GetDefaultBranch(ctx, repo)
+	if err != nil {
+		return err
+	}
+	ref, _, err := c.client.Git.GetRef(ctx, repo.Owner, repo.Name, "refs/heads/"+branch)
+	if err != nil {
+		return fmt.Errorf("failed to get branch %s of %s: %w", branch, repo, err)
+	}
+	_, _, err = c.client.Git.CreateRef(ctx, repo.Owner, repo.Name, &github.Reference{
+		Ref:    github.String("refs/heads/" + repo.Branch),
+		Object: &github.GitObject{SHA: ref.Object.SHA},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create branch %s in %s: %w", repo.Branch, repo, err)
+	}
+	return nil
+}
+
+// OpenPullRequest opens a pull request from the head repository branch into
+// the base repository.
+func (c *githubClient) OpenPullRequest(ctx *context.Context, base, head Repo, title, body string) (string, error) {
+	branch := base.Branch
+	if branch == "" {
+		var err error
+		branch, err = c.GetDefaultBranch(ctx, base)
+		if err != nil {
+			return "", err
+		}
+	}
+	pr, _, err := c.client.PullRequests.Create(ctx, base.Owner, base.Name, &github.NewPullRequest{
+		Title: github.String(title),
+		Head:  github.String(head.Owner + ":" + head.Branch),
+		Base:  github.String(branch),
+		Body:  github.String(body),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to open pull request in %s: %w", base, err)
+	}
+	return pr.GetHTMLURL(), nil
+}
+
+// CreateDiscussion creates a discussion in the given category of the
+// repository.
+func (c *githubClient) CreateDiscussion(ctx *context.Context, repo Repo, category, title, body string) (string, error) {
+	var repository struct {
+		Repository struct {
+			ID                   string `json:"id"`
+			DiscussionCategories struct {
+				Nodes []struct {
+					ID   string `json:"id"`
+					Name string `json:"name"`
+					Slug string `json:"slug"`
+				} `json:"nodes"`
+			} `json:"discussionCategories"`
+		} `json:"repository"`
+	}
+	if err := c.graphql(ctx, `query($owner: String!, $name: String!) {
+  repository(owner: $owner, name: $name) {
+    id
+    discussionCategories(first: 100) { nodes { id name slug } }
+  }
+}`, map[string]interface{}{
+		"owner": repo.Owner,
+		"name":  repo.Name,
+	}, &repository); err != nil {
+		return "", err
+	}
+
+	var categoryID string
+	for _, cat := range repository.Repository.DiscussionCategories.Nodes {
+		if strings.EqualFold(cat.Name, category) || strings.EqualFold(cat.Slug, category) {
+			categoryID = cat.ID
+			break
+		}
+	}
+	if categoryID == "" {
+		return "", fmt.Errorf("discussion category %q not found in %s", category, repo)
+	}
+
+	var created struct {
+		CreateDiscussion struct {
+			Discussion struct {
+				URL string `json:"url"`
+			} `json:"discussion"`
+		} `json:"createDiscussion"`
+	}
+	if err := c.graphql(ctx, `mutation($input: CreateDiscussionInput!) {
+  createDiscussion(input: $input) { discussion { url } }
+}`, map[string]interface{}{
+		"input": map[string]string{
+			"repositoryId": repository.Repository.ID,
+			"categoryId":   categoryID,
+			"title":        title,
+			"body":         body,
+		},
+	}, &created); err != nil {
+		return "", err
+	}
+	return created.CreateDiscussion.Discussion.URL, nil
+}
+
+// UpdateDiscussion replaces the title and body of the given discussion.
+func (c *githubClient) UpdateDiscussion(ctx *context.Context, repo Repo, number int, title, body string) error {
+	var discussion struct {
+		Repository struct {
+			Discussion struct {
+				ID string `json:"id"`
+			} `json:"discussion"`
+		} `json:"repository"`
+	}
+	if err := c.graphql(ctx, `query($owner: String!, $name: String!, $number: Int!) {
+  repository(owner: $owner, name: $name) { discussion(number: $number) { id } }
+}`, map[string]interface{}{
+		"owner":  repo.Owner,
+		"name":   repo.Name,
+		"number": number,
+	}, &discussion); err != nil {
+		return err
+	}
+
+	return c.graphql(ctx, `mutation($input: UpdateDiscussionInput!) {
+  updateDiscussion(input: $input) { discussion { id } }
+}`, map[string]interface{}{
+		"input": map[string]string{
+			"discussionId": discussion.Repository.Discussion.ID,
+			"title":        title,
+			"body":         body,
+		},
+	}, nil)
+}
+
+// graphql runs the given query against the GraphQL API, which lives next to
+// the REST one, e.g. api/graphql instead of api/v3 on GitHub Enterprise.
+func (c *githubClient) graphql(ctx *context.Context, query string, variables map[string]interface{}, data interface{}) error {
+	endpoint := "graphql"
+	if strings.HasSuffix(c.client.BaseURL.Path, "/v3/") {
+		endpoint = "../graphql"
+	}
+	req, err := c.client.NewRequest(http.MethodPost, endpoint, map[string]interface{}{
+		"query":     query,
+		"variables": variables,
+	})
+	if err != nil {
+		return err
+	}
+	var result struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if _, err := c.client.Do(ctx, req, &result); err != nil {
+		return err
+	}
+	if len(result.Errors) > 0 {
+		msgs := make([]string, 0, len(result.Errors))
+		for _, e := range result.Errors {
+			msgs = append(msgs, e.Message)
+		}
+		return fmt.Errorf("graphql: %s", strings.Join(msgs, "; "))
+	}
+	if data == nil {
+		return nil
+	}
+	return json.Unmarshal(result.Data, data)
+}
+
+// ReleaseDownloads returns the download count of each asset of the given release.
+func (c *githubClient) ReleaseDownloads(ctx *context.Context, tag string) (map[string]int, error) {
+	release, _, err := c.client.Repositories.GetReleaseByTag(
+		ctx,
+		ctx.Config.Release.GitHub.Owner,
+		ctx.Config.Release.GitHub.Name,
+		tag,
+	)
+	if err != nil {
+		return nil, err
+	}
+	result := map[string]int{}
+	for _, asset := range release.Assets {
+		result[asset.GetName()] = asset.GetDownloadCount()
+	}
+	return result, nil
 }
 
 // getMilestoneByTitle returns a milestone by title.
@@ -1,14 +1,19 @@
 package client
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"text/template"
 
+	"github.com/google/go-github/v41/github"
 	"github.com/goreleaser/goreleaser/internal/artifact"
 	"github.com/goreleaser/goreleaser/pkg/config"
 	"github.com/goreleaser/goreleaser/pkg/context"
@@ -127,6 +132,98 @@ func TestGitHubUploadReleaseIDNotInt(t *testing.T) {
 	)
 }
 
+func TestGitHubUploadDigest(t *testing.T) {
+	// sha256 of "foo"
+	const digest = "sha256:2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae"
+
+	setup := func(tb testing.TB, upload, assets string, uploadStatus int) (Client, *context.Context, *artifact.Artifact, *os.File, *[]string) {
+		tb.Helper()
+		var requests []string
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer r.Body.Close()
+			requests = append(requests, r.Method+" "+r.URL.Path)
+			switch {
+			case r.Method == http.MethodPost:
+				w.WriteHeader(uploadStatus)
+				fmt.Fprint(w, upload)
+			case r.Method == http.MethodDelete:
+				w.WriteHeader(http.StatusNoContent)
+			default:
+				fmt.Fprint(w, assets)
+			}
+		}))
+		tb.Cleanup(srv.Close)
+
+		ctx := context.New(config.Project{
+			GitHubURLs: config.GitHubURLs{
+				API:    srv.URL + "/",
+				Upload: srv.URL + "/",
+			},
+			Release: config.Release{
+				GitHub: config.Repo{Owner: "foo", Name: "bar"},
+			},
+		})
+		client, err := NewGitHub(ctx, "test-token")
+		require.NoError(tb, err)
+
+		path := filepath.Join(tb.TempDir(), "foo.txt")
+		require.NoError(tb, os.WriteFile(path, []byte("foo"), 0o644))
+		file, err := os.Open(path)
+		require.NoError(tb, err)
+		tb.Cleanup(func() { _ = file.Close() })
+		return client, ctx, &artifact.Artifact{Name: "foo.txt", Path: path}, file, &requests
+	}
+
+	t.Run("matching digest", func(t *testing.T) {
+		client, ctx, a, file, requests := setup(t, `{"id":1,"name":"foo.txt","digest":"`+digest+`"}`, "", http.StatusCreated)
+		require.NoError(t, client.Upload(ctx, "1", a, file))
+		require.Equal(t, []string{"POST /repos/foo/bar/releases/1/assets"}, *requests)
+	})
+
+	t.Run("no digest", func(t *testing.T) {
+		client, ctx, a, file, requests := setup(t, `{"id":1,"name":"foo.txt"}`, "", http.StatusCreated)
+		require.NoError(t, client.Upload(ctx, "1", a, file))
+		require.Equal(t, []string{"POST /repos/foo/bar/releases/1/assets"}, *requests)
+	})
+
+	t.Run("digest mismatch", func(t *testing.T) {
+		client, ctx, a, file, requests := setup(t, `{"id":1,"name":"foo.txt","digest":"sha256:nope"}`, "", http.StatusCreated)
+		err := client.Upload(ctx, "1", a, file)
+		require.ErrorAs(t, err, &RetriableError{})
+		require.EqualError(t, err, "uploaded asset foo.txt has digest sha256:nope, expected "+digest)
+		require.Equal(t, []string{
+			"POST /repos/foo/bar/releases/1/assets",
+			"DELETE /repos/foo/bar/releases/assets/1",
+		}, *requests)
+	})
+
+	t.Run("already uploaded", func(t *testing.T) {
+		client, ctx, a, file, requests := setup(
+			t,
+			`{"message":"Validation Failed","errors":[{"code":"already_exists"}]}`,
+			`[{"id":2,"name":"bar.txt"},{"id":1,"name":"foo.txt","digest":"`+digest+`"}]`,
+			http.StatusUnprocessableEntity,
+		)
+		require.NoError(t, client.Upload(ctx, "1", a, file))
+		require.Equal(t, []string{
+			"POST /repos/foo/bar/releases/1/assets",
+			"GET /repos/foo/bar/releases/1/assets",
+		}, *requests)
+	})
+
+	t.Run("already uploaded without digest", func(t *testing.T) {
+		client, ctx, a, file, _ := setup(
+			t,
+			`{"message":"Validation Failed","errors":[{"code":"already_exists"}]}`,
+			`[{"id":1,"name":"foo.txt"}]`,
+			http.StatusUnprocessableEntity,
+		)
+		err := client.Upload(ctx, "1", a, file)
+		require.Error(t, err)
+		require.False(t, errors.As(err, &RetriableError{}))
+	})
+}
+
 func TestGitHubReleaseURLTemplate(t *testing.T) {
 	tests := []struct {
 		name            string
@@ -323,3 +420,282 @@ func TestReleaseNotes(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, "**Full Changelog**: https://github.com/someone/something/compare/v1.0.0...v1.1.0", log)
 }
+
+func TestGitHubCreateFileUnchanged(t *testing.T) {
+	var updates int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+		switch r.Method {
+		case http.MethodGet:
+			// GitHub wraps the base64 content in lines.
+			fmt.Fprint(w, `{"type":"file","path":"Formula/foo.rb","sha":"abc","encoding":"base64","content":"Zm9v\nYmFy\n"}`)
+		case http.MethodPut:
+			updates++
+			fmt.Fprint(w, `{"content":{"sha":"def"}}`)
+		}
+	}))
+	defer srv.Close()
+
+	ctx := context.New(config.Project{
+		GitHubURLs: config.GitHubURLs{
+			API: srv.URL + "/",
+		},
+	})
+	client, err := NewGitHub(ctx, "test-token")
+	require.NoError(t, err)
+	repo := Repo{Owner: "someone", Name: "something", Branch: "main"}
+
+	require.NoError(t, client.CreateFile(ctx, config.CommitAuthor{}, repo, []byte("foobar"), "Formula/foo.rb", "update"))
+	require.Equal(t, 0, updates)
+	require.Equal(t, []context.UnchangedFile{{Repo: "someone/something", Path: "Formula/foo.rb"}}, ctx.UnchangedFiles)
+
+	require.NoError(t, client.CreateFile(ctx, config.CommitAuthor{}, repo, []byte("foobaz"), "Formula/foo.rb", "update"))
+	require.Equal(t, 1, updates)
+	require.Len(t, ctx.UnchangedFiles, 1)
+}
+
+func TestGitHubCreateFileCommitter(t *testing.T) {
+	var body github.RepositoryContentFileOptions
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+		switch r.Method {
+		case http.MethodGet:
+			w.WriteHeader(http.StatusNotFound)
+		case http.MethodPut:
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			fmt.Fprint(w, `{"content":{"sha":"def"}}`)
+		}
+	}))
+	defer srv.Close()
+
+	ctx := context.New(config.Project{
+		GitHubURLs: config.GitHubURLs{
+			API: srv.URL + "/",
+		},
+	})
+	client, err := NewGitHub(ctx, "test-token")
+	require.NoError(t, err)
+	repo := Repo{Owner: "someone", Name: "something", Branch: "main"}
+	author := config.CommitAuthor{
+		Name:      "foo",
+		Email:     "foo@bar",
+		Committer: config.Committer{Name: "bot", Email: "bot@bar"},
+	}
+
+	require.NoError(t, client.CreateFile(ctx, author, repo, []byte("foo"), "Formula/foo.rb", "update"))
+	require.Equal(t, "foo", body.Author.GetName())
+	require.Equal(t, "foo@bar", body.Author.GetEmail())
+	require.Equal(t, "bot", body.Committer.GetName())
+	require.Equal(t, "bot@bar", body.Committer.GetEmail())
+}
+
+func TestGitHubCreateFileSigned(t *testing.T) {
+	dir := t.TempDir()
+	gpg := filepath.Join(dir, "gpg")
+	require.NoError(t, os.WriteFile(gpg, []byte(`#!/bin/sh
+cat > `+filepath.Join(dir, "payload")+`
+echo "[GNUPG:] SIG_CREATED D 1 8 00 1 ABC" >&2
+echo "-----BEGIN PGP SIGNATURE-----"
+`), 0o755))
+
+	var commit map[string]interface{}
+	var ref map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+		switch r.Method + " " + r.URL.Path {
+		case "GET /repos/someone/something/contents/Formula/foo.rb":
+			w.WriteHeader(http.StatusNotFound)
+		case "GET /repos/someone/something/git/ref/heads/main":
+			fmt.Fprint(w, `{"ref":"refs/heads/main","object":{"sha":"parent"}}`)
+		case "GET /repos/someone/something/git/commits/parent":
+			fmt.Fprint(w, `{"sha":"parent","tree":{"sha":"basetree"}}`)
+		case "POST /repos/someone/something/git/trees":
+			fmt.Fprint(w, `{"sha":"newtree"}`)
+		case "POST /repos/someone/something/git/commits":
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&commit))
+			fmt.Fprint(w, `{"sha":"newcommit"}`)
+		case "PATCH /repos/someone/something/git/refs/heads/main":
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&ref))
+			fmt.Fprint(w, `{"ref":"refs/heads/main","object":{"sha":"newcommit"}}`)
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+
+	ctx := context.New(config.Project{
+		GitHubURLs: config.GitHubURLs{
+			API: srv.URL + "/",
+		},
+	})
+	client, err := NewGitHub(ctx, "test-token")
+	require.NoError(t, err)
+	repo := Repo{Owner: "someone", Name: "something", Branch: "main"}
+	author := config.CommitAuthor{
+		Name:    "foo",
+		Email:   "foo@bar",
+		Signing: config.CommitSigning{Enabled: true, Key: "ABC", Program: gpg},
+	}
+
+	require.NoError(t, client.CreateFile(ctx, author, repo, []byte("foo"), "Formula/foo.rb", "update"))
+	require.Equal(t, "-----BEGIN PGP SIGNATURE-----\n", commit["signature"])
+	require.Equal(t, "newtree", commit["tree"])
+	require.Equal(t, []interface{}{"parent"}, commit["parents"])
+	require.Equal(t, "newcommit", ref["sha"])
+	require.Equal(t, false, ref["force"])
+
+	payload, err := os.ReadFile(filepath.Join(dir, "payload"))
+	require.NoError(t, err)
+	lines := strings.Split(string(payload), "\n")
+	require.Equal(t, "tree newtree", lines[0])
+	require.Equal(t, "parent parent", lines[1])
+	require.Regexp(t, `^author foo <foo@bar> \d+ \+0000$`, lines[2])
+	require.Regexp(t, `^committer foo <foo@bar> \d+ \+0000$`, lines[3])
+	require.Equal(t, []string{"", "update"}, lines[4:])
+}
+
+func TestGitHubCreateAttestation(t *testing.T) {
+	var body map[string]json.RawMessage
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+		require.Equal(t, http.MethodPost, r.Method)
+		require.Equal(t, "/repos/someone/something/attestations", r.URL.Path)
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"id":1}`)
+	}))
+	defer srv.Close()
+
+	ctx := context.New(config.Project{
+		GitHubURLs: config.GitHubURLs{
+			API: srv.URL + "/",
+		},
+	})
+	client, err := NewGitHub(ctx, "test-token")
+	require.NoError(t, err)
+	repo := Repo{Owner: "someone", Name: "something"}
+	require.NoError(t, client.(Attester).CreateAttestation(ctx, repo, []byte(`{"mediaType":"application/vnd.dev.sigstore.bundle.v0.3+json"}`)))
+	require.JSONEq(t, `{"mediaType":"application/vnd.dev.sigstore.bundle.v0.3+json"}`, string(body["bundle"]))
+}
+
+func TestGitHubDiscussions(t *testing.T) {
+	var inputs []map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+		require.Equal(t, http.MethodPost, r.Method)
+		require.Equal(t, "/api/graphql", r.URL.Path)
+		var req struct {
+			Query     string                 `json:"query"`
+			Variables map[string]interface{} `json:"variables"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		switch {
+		case strings.Contains(req.Query, "discussionCategories"):
+			fmt.Fprint(w, `{"data":{"repository":{"id":"R_1","discussionCategories":{"nodes":[{"id":"C_1","name":"General","slug":"general"},{"id":"C_2","name":"Announcements","slug":"announcements"}]}}}}`)
+		case strings.Contains(req.Query, "createDiscussion"):
+			inputs = append(inputs, req.Variables["input"].(map[string]interface{}))
+			fmt.Fprint(w, `{"data":{"createDiscussion":{"discussion":{"url":"https://github.com/someone/something/discussions/2"}}}}`)
+		case strings.Contains(req.Query, "discussion(number"):
+			require.Equal(t, float64(1), req.Variables["number"])
+			fmt.Fprint(w, `{"data":{"repository":{"discussion":{"id":"D_1"}}}}`)
+		case strings.Contains(req.Query, "updateDiscussion"):
+			inputs = append(inputs, req.Variables["input"].(map[string]interface{}))
+			fmt.Fprint(w, `{"data":{"updateDiscussion":{"discussion":{"id":"D_1"}}}}`)
+		default:
+			t.Fatalf("unexpected query: %s", req.Query)
+		}
+	}))
+	defer srv.Close()
+
+	ctx := context.New(config.Project{
+		GitHubURLs: config.GitHubURLs{
+			API: srv.URL + "/api/v3/",
+		},
+	})
+	client, err := NewGitHub(ctx, "test-token")
+	require.NoError(t, err)
+	poster := client.(DiscussionPoster)
+	repo := Repo{Owner: "someone", Name: "something"}
+
+	url, err := poster.CreateDiscussion(ctx, repo, "announcements", "v1.0.0", "notes")
+	require.NoError(t, err)
+	require.Equal(t, "https://github.com/someone/something/discussions/2", url)
+	require.NoError(t, poster.UpdateDiscussion(ctx, repo, 1, "latest", "notes"))
+	require.Equal(t, []map[string]interface{}{
+		{"repositoryId": "R_1", "categoryId": "C_2", "title": "v1.0.0", "body": "notes"},
+		{"discussionId": "D_1", "title": "latest", "body": "notes"},
+	}, inputs)
+
+	_, err = poster.CreateDiscussion(ctx, repo, "Q&A", "v1.0.0", "notes")
+	require.EqualError(t, err, `discussion category "Q&A" not found in someone/something`)
+}
+
+func TestGitHubDiscussionsGraphQLErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/graphql", r.URL.Path)
+		fmt.Fprint(w, `{"data":null,"errors":[{"message":"Could not resolve to a Repository"},{"message":"nope"}]}`)
+	}))
+	defer srv.Close()
+
+	ctx := context.New(config.Project{
+		GitHubURLs: config.GitHubURLs{
+			API: srv.URL + "/",
+		},
+	})
+	client, err := NewGitHub(ctx, "test-token")
+	require.NoError(t, err)
+	_, err = client.(DiscussionPoster).CreateDiscussion(ctx, Repo{Owner: "someone", Name: "something"}, "general", "v1.0.0", "notes")
+	require.EqualError(t, err, "graphql: Could not resolve to a Repository; nope")
+}
+
+func TestGitHubPullRequests(t *testing.T) {
+	var created map[string]interface{}
+	var opened map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/someone/something/git/ref/heads/feature":
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"message":"Not Found"}`)
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/someone/something":
+			fmt.Fprint(w, `{"default_branch":"main"}`)
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/someone/something/git/ref/heads/main":
+			fmt.Fprint(w, `{"ref":"refs/heads/main","object":{"sha":"abc123"}}`)
+		case r.Method == http.MethodPost && r.URL.Path == "/repos/someone/something/git/refs":
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&created))
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{"ref":"refs/heads/feature","object":{"sha":"abc123"}}`)
+		case r.Method == http.MethodPost && r.URL.Path == "/repos/upstream/something/pulls":
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&opened))
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{"html_url":"https://github.com/upstream/something/pull/1"}`)
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+
+	ctx := context.New(config.Project{
+		GitHubURLs: config.GitHubURLs{
+			API: srv.URL + "/",
+		},
+	})
+	client, err := NewGitHub(ctx, "test-token")
+	require.NoError(t, err)
+	head := Repo{Owner: "someone", Name: "something", Branch: "feature"}
+	require.NoError(t, client.(PullRequestOpener).CreateBranch(ctx, head))
+	require.Equal(t, map[string]interface{}{
+		"ref": "refs/heads/feature",
+		"sha": "abc123",
+	}, created)
+
+	url, err := client.(PullRequestOpener).OpenPullRequest(ctx, Repo{Owner: "upstream", Name: "something", Branch: "master"}, head, "foo 1.0.0", "new version")
+	require.NoError(t, err)
+	require.Equal(t, "https://github.com/upstream/something/pull/1", url)
+	require.Equal(t, map[string]interface{}{
+		"title": "foo 1.0.0",
+		"head":  "someone:feature",
+		"base":  "master",
+		"body":  "new version",
+	}, opened)
+}
@@ -78,7 +78,7 @@ func (c *gitlabClient) GetDefaultBranch(ctx *context.Context, repo Repo) (string
 	projectID := repo.String()
 	p, res, err := c.client.Projects.GetProject(projectID, nil)
 	if err != nil {
-		log.WithFields(log.Fields{
+		log.FromContext(ctx).WithFields(log.Fields{
 			"projectID":  projectID,
 			"statusCode": res.StatusCode,
 			"err":        err.Error(),
@@ -143,7 +143,7 @@ func (c *gitlabClient) CreateFile(
 		branch, err = c.GetDefaultBranch(ctx, repo)
 		if err != nil {
 			// Fall back to 'master' 😭
-			log.WithFields(log.Fields{
+			log.FromContext(ctx).WithFields(log.Fields{
 				"fileName":        fileName,
 				"projectID":       repo.String(),
 				"requestedBranch": branch,
@@ -157,16 +157,16 @@ func (c *gitlabClient) CreateFile(
 	opts := &gitlab.GetFileOptions{Ref: &ref}
 	castedContent := string(content)
 
-	log.WithFields(log.Fields{
+	log.FromContext(ctx).WithFields(log.Fields{
 		"owner":  repo.Owner,
 		"name":   repo.Name,
 		"ref":    ref,
 		"branch": branch,
 	}).Debug("projectID at brew")
 
-	_, res, err := c.client.RepositoryFiles.GetFile(repo.String(), fileName, opts)
+	file, res, err := c.client.RepositoryFiles.GetFile(repo.String(), fileName, opts)
 	if err != nil && (res == nil || res.StatusCode != 404) {
-		log.WithFields(log.Fields{
+		log.FromContext(ctx).WithFields(log.Fields{
 			"fileName":   fileName,
 			"ref":        ref,
 			"projectID":  projectID,
@@ -176,14 +176,14 @@ func (c *gitlabClient) CreateFile(
 		return err
 	}
 
-	log.WithFields(log.Fields{
+	log.FromContext(ctx).WithFields(log.Fields{
 		"fileName":  fileName,
 		"branch":    branch,
 		"projectID": projectID,
 	}).Debug("found already existing brew formula file")
 
 	if res.StatusCode == 404 {
-		log.WithFields(log.Fields{
+		log.FromContext(ctx).WithFields(log.Fields{
 			"fileName":  fileName,
 			"ref":       ref,
 			"projectID": projectID,
@@ -197,7 +197,7 @@ func (c *gitlabClient) CreateFile(
 		}
 		fileInfo, res, err := c.client.RepositoryFiles.CreateFile(projectID, fileName, createOpts)
 		if err != nil {
-			log.WithFields(log.Fields{
+			log.FromContext(ctx).WithFields(log.Fields{
 				"fileName":   fileName,
 				"branch":     branch,
 				"projectID":  projectID,
@@ -207,7 +207,7 @@ func (c *gitlabClient) CreateFile(
 			return err
 		}
 
-		log.WithFields(log.Fields{
+		log.FromContext(ctx).WithFields(log.Fields{
 			"fileName":  fileName,
 			"branch":    branch,
 			"projectID": projectID,
@@ -216,7 +216,12 @@ func (c *gitlabClient) CreateFile(
 		return nil
 	}
 
-	log.WithFields(log.Fields{
+	if file != nil && file.Encoding == "base64" && sameBase64Content(file.Content, content) {
+		skipUnchanged(ctx, repo, path)
+		return nil
+	}
+
+	log.FromContext(ctx).WithFields(log.Fields{
 		"fileName":  fileName,
 		"ref":       ref,
 		"projectID": projectID,
@@ -231,7 +236,7 @@ func (c *gitlabClient) CreateFile(
 
 	updateFileInfo, res, err := c.client.RepositoryFiles.UpdateFile(projectID, fileName, updateOpts)
 	if err != nil {
-		log.WithFields(log.Fields{
+		log.FromContext(ctx).WithFields(log.Fields{
 			"fileName":   fileName,
 			"branch":     branch,
 			"projectID":  projectID,
@@ -241,7 +246,7 @@ func (c *gitlabClient) CreateFile(
 		return err
 	}
 
-	log.WithFields(log.Fields{
+	log.FromContext(ctx).WithFields(log.Fields{
 		"fileName":   fileName,
 		"branch":     branch,
 		"projectID":  projectID,
@@ -266,7 +271,7 @@ func (c *gitlabClient) CreateRelease(ctx *context.Context, body string) (release
 	if ctx.Config.Release.GitLab.Owner != "" {
 		projectID = ctx.Config.Release.GitLab.Owner + "/" + projectID
 	}
-	log.WithFields(log.Fields{
+	log.FromContext(ctx).WithFields(log.Fields{
 		"owner":     ctx.Config.Release.GitLab.Owner,
 		"name":      gitlabName,
 		"projectID": projectID,
@@ -280,7 +285,7 @@ func (c *gitlabClient) CreateRelease(ctx *context.Context, body string) (release
 	}
 
 	if resp.StatusCode == 403 || resp.StatusCode == 404 {
-		log.WithFields(log.Fields{
+		log.FromContext(ctx).WithFields(log.Fields{
 			"err": err.Error(),
 		}).Debug("get release")
 
@@ -288,7 +293,7 @@ func (c *gitlabClient) CreateRelease(ctx *context.Context, body string) (release
 		ref := ctx.Git.Commit
 		gitURL := ctx.Git.URL
 
-		log.WithFields(log.Fields{
+		log.FromContext(ctx).WithFields(log.Fields{
 			"name":        name,
 			"description": description,
 			"ref":         ref,
@@ -302,12 +307,12 @@ func (c *gitlabClient) CreateRelease(ctx *context.Context, body string) (release
 		})
 
 		if err != nil {
-			log.WithFields(log.Fields{
+			log.FromContext(ctx).WithFields(log.Fields{
 				"err": err.Error(),
 			}).Debug("error create release")
 			return "", err
 		}
-		log.WithField("name", release.Name).Info("release created")
+		log.FromContext(ctx).WithField("name", release.Name).Info("release created")
 	} else {
 		desc := body
 		if release != nil {
@@ -319,13 +324,13 @@ func (c *gitlabClient) CreateRelease(ctx *context.Context, body string) (release
 			Description: &desc,
 		})
 		if err != nil {
-			log.WithFields(log.Fields{
+			log.FromContext(ctx).WithFields(log.Fields{
 				"err": err.Error(),
 			}).Debug("error update release")
 			return "", err
 		}
 
-		log.WithField("name", release.Name).Info("release updated")
+		log.FromContext(ctx).WithField("name", release.Name).Info("release updated")
 	}
 
 	return tagName, err // gitlab references a tag in a repo by its name
@@ -380,7 +385,7 @@ func (c *gitlabClient) Upload(
 	var baseLinkURL string
 	var linkURL string
 	if ctx.Config.GitLabURLs.UsePackageRegistry {
-		log.WithField("file", file.Name()).Debug("uploading file as generic package")
+		log.FromContext(ctx).WithField("file", file.Name()).Debug("uploading file as generic package")
 		if _, _, err := c.client.GenericPackages.PublishPackageFile(
 			projectID,
 			ctx.Config.ProjectName,
@@ -403,7 +408,7 @@ func (c *gitlabClient) Upload(
 		}
 		linkURL = c.client.BaseURL().String() + baseLinkURL
 	} else {
-		log.WithField("file", file.Name()).Debug("uploading file as attachment")
+		log.FromContext(ctx).WithField("file", file.Name()).Debug("uploading file as attachment")
 		projectFile, _, err := c.client.Projects.UploadFile(
 			projectID,
 			file.Name(),
@@ -427,7 +432,7 @@ func (c *gitlabClient) Upload(
 		linkURL = gitlabBaseURL + "/" + projectDetails.PathWithNamespace + baseLinkURL
 	}
 
-	log.WithFields(log.Fields{
+	log.FromContext(ctx).WithFields(log.Fields{
 		"file": file.Name(),
 		"url":  baseLinkURL,
 	}).Debug("uploaded file")
@@ -446,7 +451,7 @@ func (c *gitlabClient) Upload(
 		return RetriableError{err}
 	}
 
-	log.WithFields(log.Fields{
+	log.FromContext(ctx).WithFields(log.Fields{
 		"id":  releaseLink.ID,
 		"url": releaseLink.DirectAssetURL,
 	}).Debug("created release link")
@@ -546,3 +546,35 @@ func TestCloseMileston(t *testing.T) {
 	err = client.CloseMilestone(ctx, repo, "never-will-exist")
 	require.Error(t, err)
 }
+
+func TestGitlabCreateFileUnchanged(t *testing.T) {
+	var updates int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+		switch r.Method {
+		case http.MethodGet:
+			fmt.Fprint(w, `{"file_path":"Formula/foo.rb","encoding":"base64","content":"Zm9vYmFy"}`)
+		case http.MethodPut:
+			updates++
+			fmt.Fprint(w, `{"file_path":"Formula/foo.rb","branch":"main"}`)
+		}
+	}))
+	defer srv.Close()
+
+	ctx := context.New(config.Project{
+		GitLabURLs: config.GitLabURLs{
+			API: srv.URL,
+		},
+	})
+	client, err := NewGitLab(ctx, "test-token")
+	require.NoError(t, err)
+	repo := Repo{Owner: "someone", Name: "something", Branch: "main"}
+
+	require.NoError(t, client.CreateFile(ctx, config.CommitAuthor{}, repo, []byte("foobar"), "Formula/foo.rb", "update"))
+	require.Equal(t, 0, updates)
+	require.Equal(t, []context.UnchangedFile{{Repo: "someone/something", Path: "Formula/foo.rb"}}, ctx.UnchangedFiles)
+
+	require.NoError(t, client.CreateFile(ctx, config.CommitAuthor{}, repo, []byte("foobaz"), "Formula/foo.rb", "update"))
+	require.Equal(t, 1, updates)
+	require.Len(t, ctx.UnchangedFiles, 1)
+}
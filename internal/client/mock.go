@@ -13,8 +13,13 @@ import (
 )
 
 var (
-	_ Client       = &Mock{}
-	_ GitHubClient = &Mock{}
+	_ Client            = &Mock{}
+	_ GitHubClient      = &Mock{}
+	_ DownloadCounter   = &Mock{}
+	_ Attester          = &Mock{}
+	_ DiscussionPoster  = &Mock{}
+	_ PullRequestOpener = &Mock{}
+	_ ReleaseFinalizer  = &Mock{}
 )
 
 func NewMock() *Mock {
@@ -25,10 +30,13 @@ type Mock struct {
 	CreatedFile          bool
 	Content              string
 	Path                 string
+	CreatedFiles         map[string]string
 	FailToCreateRelease  bool
 	FailToUpload         bool
 	CreatedRelease       bool
 	UploadedFile         bool
+	FailToFinalize       bool
+	FinalizedRelease     bool
 	UploadedFileNames    []string
 	UploadedFilePaths    map[string]string
 	FailFirstUpload      bool
@@ -37,6 +45,27 @@ type Mock struct {
 	FailToCloseMilestone bool
 	Changes              string
 	ReleaseNotes         string
+	Downloads            map[string]int
+	Attestations         [][]byte
+	Discussions          []MockDiscussion
+	UpdatedDiscussions   map[int]MockDiscussion
+	CreatedBranches      []string
+	PullRequests         []MockPullRequest
+}
+
+// MockDiscussion is a discussion created or updated with the mock client.
+type MockDiscussion struct {
+	Category string
+	Title    string
+	Body     string
+}
+
+// MockPullRequest is a pull request opened with the mock client.
+type MockPullRequest struct {
+	Base  Repo
+	Head  Repo
+	Title string
+	Body  string
 }
 
 func (c *Mock) Changelog(ctx *context.Context, repo Repo, prev, current string) (string, error) {
@@ -53,6 +82,51 @@ func (c *Mock) GenerateReleaseNotes(ctx *context.Context, repo Repo, prev, curre
 	return "", ErrNotImplemented
 }
 
+func (c *Mock) ReleaseDownloads(ctx *context.Context, tag string) (map[string]int, error) {
+	if c.Downloads != nil {
+		return c.Downloads, nil
+	}
+	return nil, ErrNotImplemented
+}
+
+func (c *Mock) CreateAttestation(ctx *context.Context, repo Repo, bundle []byte) error {
+	c.Lock.Lock()
+	defer c.Lock.Unlock()
+	c.Attestations = append(c.Attestations, bundle)
+	return nil
+}
+
+func (c *Mock) CreateDiscussion(ctx *context.Context, repo Repo, category, title, body string) (string, error) {
+	c.Lock.Lock()
+	defer c.Lock.Unlock()
+	c.Discussions = append(c.Discussions, MockDiscussion{Category: category, Title: title, Body: body})
+	return fmt.Sprintf("https://github.com/%s/discussions/%d", repo, len(c.Discussions)), nil
+}
+
+func (c *Mock) UpdateDiscussion(ctx *context.Context, repo Repo, number int, title, body string) error {
+	c.Lock.Lock()
+	defer c.Lock.Unlock()
+	if c.UpdatedDiscussions == nil {
+		c.UpdatedDiscussions = map[int]MockDiscussion{}
+	}
+	c.UpdatedDiscussions[number] = MockDiscussion{Title: title, Body: body}
+	return nil
+}
+
+func (c *Mock) CreateBranch(ctx *context.Context, repo Repo) error {
+	c.Lock.Lock()
+	defer c.Lock.Unlock()
+	c.CreatedBranches = append(c.CreatedBranches, repo.String()+"@"+repo.Branch)
+	return nil
+}
+
+func (c *Mock) OpenPullRequest(ctx *context.Context, base, head Repo, title, body string) (string, error) {
+	c.Lock.Lock()
+	defer c.Lock.Unlock()
+	c.PullRequests = append(c.PullRequests, MockPullRequest{Base: base, Head: head, Title: title, Body: body})
+	return fmt.Sprintf("https://github.com/%s/pull/%d", base, len(c.PullRequests)), nil
+}
+
 func (c *Mock) CloseMilestone(ctx *context.Context, repo Repo, title string) error {
 	if c.FailToCloseMilestone {
 		return errors.New("milestone failed")
@@ -75,14 +149,28 @@ func (c *Mock) CreateRelease(ctx *context.Context, body string) (string, error)
 	return "", nil
 }
 
+func (c *Mock) FinalizeRelease(ctx *context.Context, releaseID string) error {
+	if c.FailToFinalize {
+		return errors.New("finalize failed")
+	}
+	c.FinalizedRelease = true
+	return nil
+}
+
 func (c *Mock) ReleaseURLTemplate(ctx *context.Context) (string, error) {
 	return "https://dummyhost/download/{{ .Tag }}/{{ .ArtifactName }}", nil
 }
 
 func (c *Mock) CreateFile(ctx *context.Context, commitAuthor config.CommitAuthor, repo Repo, content []byte, path, msg string) error {
+	c.Lock.Lock()
+	defer c.Lock.Unlock()
 	c.CreatedFile = true
 	c.Content = string(content)
 	c.Path = path
+	if c.CreatedFiles == nil {
+		c.CreatedFiles = map[string]string{}
+	}
+	c.CreatedFiles[path] = string(content)
 	return nil
 }
 
@@ -0,0 +1,34 @@
+package client
+
+import (
+	"bytes"
+	"encoding/base64"
+	"strings"
+	"sync"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/pkg/context"
+)
+
+var unchangedLock sync.Mutex
+
+// sameBase64Content reports whether the given base64 encoded content, as
+// returned by the APIs, is the same as the given content.
+func sameBase64Content(encoded string, content []byte) bool {
+	existing, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(encoded, "\n", ""))
+	return err == nil && bytes.Equal(existing, content)
+}
+
+// skipUnchanged records that the given file already has the content that
+// would be committed, so no commit is made.
+func skipUnchanged(ctx *context.Context, repo Repo, path string) {
+	log.FromContext(ctx).WithField("repository", repo.String()).
+		WithField("path", path).
+		Info("file is up to date, skipping commit")
+	unchangedLock.Lock()
+	defer unchangedLock.Unlock()
+	ctx.UnchangedFiles = append(ctx.UnchangedFiles, context.UnchangedFile{
+		Repo: repo.String(),
+		Path: path,
+	})
+}
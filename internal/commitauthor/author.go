@@ -2,6 +2,8 @@
 package commitauthor
 
 import (
+	"errors"
+
 	"github.com/goreleaser/goreleaser/internal/tmpl"
 	"github.com/goreleaser/goreleaser/pkg/config"
 	"github.com/goreleaser/goreleaser/pkg/context"
@@ -22,6 +24,24 @@ func Get(ctx *context.Context, og config.CommitAuthor) (config.CommitAuthor, err
 		return author, err
 	}
 	author.Email, err = tmpl.New(ctx).Apply(og.Email)
+	if err != nil {
+		return author, err
+	}
+	author.Committer.Name, err = tmpl.New(ctx).Apply(og.Committer.Name)
+	if err != nil {
+		return author, err
+	}
+	author.Committer.Email, err = tmpl.New(ctx).Apply(og.Committer.Email)
+	if err != nil {
+		return author, err
+	}
+	author.Signing.Enabled = og.Signing.Enabled
+	author.Signing.Format = og.Signing.Format
+	author.Signing.Key, err = tmpl.New(ctx).Apply(og.Signing.Key)
+	if err != nil {
+		return author, err
+	}
+	author.Signing.Program, err = tmpl.New(ctx).Apply(og.Signing.Program)
 	return author, err
 }
 
@@ -33,5 +53,73 @@ func Default(og config.CommitAuthor) config.CommitAuthor {
 	if og.Email == "" {
 		og.Email = defaultEmail
 	}
+	if og.Signing.Enabled && og.Signing.Format == "" {
+		og.Signing.Format = "openpgp"
+	}
 	return og
 }
+
+// Committer returns the committer of the commits of the given author, the
+// fields that are not set are the ones of the author.
+func Committer(author config.CommitAuthor) config.Committer {
+	committer := author.Committer
+	if committer.Name == "" {
+		committer.Name = author.Name
+	}
+	if committer.Email == "" {
+		committer.Email = author.Email
+	}
+	return committer
+}
+
+// ErrSigningUnsupported happens when commit signing is enabled on a pipe that
+// commits through the API of a git provider which can't take signed commits.
+var ErrSigningUnsupported = errors.New("commit signing is only supported on GitHub or when pushing with git")
+
+// CheckSigning returns ErrSigningUnsupported if the given author has commit
+// signing enabled, but the commits are created through the API of a git
+// provider other than GitHub.
+func CheckSigning(ctx *context.Context, author config.CommitAuthor) error {
+	if !author.Signing.Enabled {
+		return nil
+	}
+	switch ctx.TokenType {
+	case "", context.TokenTypeGitHub:
+		return nil
+	default:
+		return ErrSigningUnsupported
+	}
+}
+
+// GitConfig returns the git config key/value pairs needed to commit as the
+// given author, including the commit signing settings.
+func GitConfig(author config.CommitAuthor) [][]string {
+	result := [][]string{
+		{"user.name", author.Name},
+		{"user.email", author.Email},
+	}
+	if author.Committer.Name != "" {
+		result = append(result, []string{"committer.name", author.Committer.Name})
+	}
+	if author.Committer.Email != "" {
+		result = append(result, []string{"committer.email", author.Committer.Email})
+	}
+	if !author.Signing.Enabled {
+		return append(result, []string{"commit.gpgSign", "false"})
+	}
+	result = append(result, []string{"commit.gpgSign", "true"})
+	if author.Signing.Key != "" {
+		result = append(result, []string{"user.signingKey", author.Signing.Key})
+	}
+	if author.Signing.Format != "" {
+		result = append(result, []string{"gpg.format", author.Signing.Format})
+	}
+	if author.Signing.Program != "" {
+		section := "gpg"
+		if author.Signing.Format != "" && author.Signing.Format != "openpgp" {
+			section = "gpg." + author.Signing.Format
+		}
+		result = append(result, []string{section + ".program", author.Signing.Program})
+	}
+	return result
+}
@@ -23,6 +23,80 @@ func TestGet(t *testing.T) {
 		}, author)
 	})
 
+	t.Run("signing", func(t *testing.T) {
+		author, err := Get(context.New(config.Project{
+			Env: []string{"KEY=ABCDEF", "GPG=/usr/bin/gpg2"},
+		}), config.CommitAuthor{
+			Name:  "foo",
+			Email: "foo@bar",
+			Signing: config.CommitSigning{
+				Enabled: true,
+				Key:     "{{.Env.KEY}}",
+				Program: "{{.Env.GPG}}",
+				Format:  "openpgp",
+			},
+		})
+		require.NoError(t, err)
+		require.Equal(t, config.CommitAuthor{
+			Name:  "foo",
+			Email: "foo@bar",
+			Signing: config.CommitSigning{
+				Enabled: true,
+				Key:     "ABCDEF",
+				Program: "/usr/bin/gpg2",
+				Format:  "openpgp",
+			},
+		}, author)
+	})
+
+	t.Run("committer", func(t *testing.T) {
+		author, err := Get(context.New(config.Project{
+			Env: []string{"NAME=bot", "MAIL=bot@bar"},
+		}), config.CommitAuthor{
+			Name:  "foo",
+			Email: "foo@bar",
+			Committer: config.Committer{
+				Name:  "{{.Env.NAME}}",
+				Email: "{{.Env.MAIL}}",
+			},
+		})
+		require.NoError(t, err)
+		require.Equal(t, config.CommitAuthor{
+			Name:  "foo",
+			Email: "foo@bar",
+			Committer: config.Committer{
+				Name:  "bot",
+				Email: "bot@bar",
+			},
+		}, author)
+	})
+
+	t.Run("invalid committer tmpl", func(t *testing.T) {
+		_, err := Get(
+			context.New(config.Project{}),
+			config.CommitAuthor{
+				Name:  "a",
+				Email: "a",
+				Committer: config.Committer{
+					Email: "{{.Env.NOPE}}",
+				},
+			})
+		require.Error(t, err)
+	})
+
+	t.Run("invalid signing key tmpl", func(t *testing.T) {
+		_, err := Get(
+			context.New(config.Project{}),
+			config.CommitAuthor{
+				Name:  "a",
+				Email: "a",
+				Signing: config.CommitSigning{
+					Key: "{{.Env.NOPE}}",
+				},
+			})
+		require.Error(t, err)
+	})
+
 	t.Run("invalid name tmpl", func(t *testing.T) {
 		_, err := Get(
 			context.New(config.Project{}),
@@ -70,3 +144,125 @@ func TestDefault(t *testing.T) {
 		})
 	})
 }
+
+func TestDefaultSigning(t *testing.T) {
+	require.Equal(t, Default(config.CommitAuthor{
+		Signing: config.CommitSigning{
+			Enabled: true,
+		},
+	}), config.CommitAuthor{
+		Name:  defaultName,
+		Email: defaultEmail,
+		Signing: config.CommitSigning{
+			Enabled: true,
+			Format:  "openpgp",
+		},
+	})
+}
+
+func TestCommitter(t *testing.T) {
+	t.Run("author", func(t *testing.T) {
+		require.Equal(t, config.Committer{
+			Name:  "a",
+			Email: "a@b",
+		}, Committer(config.CommitAuthor{
+			Name:  "a",
+			Email: "a@b",
+		}))
+	})
+
+	t.Run("committer", func(t *testing.T) {
+		require.Equal(t, config.Committer{
+			Name:  "bot",
+			Email: "a@b",
+		}, Committer(config.CommitAuthor{
+			Name:      "a",
+			Email:     "a@b",
+			Committer: config.Committer{Name: "bot"},
+		}))
+	})
+}
+
+func TestCheckSigning(t *testing.T) {
+	signed := config.CommitAuthor{Signing: config.CommitSigning{Enabled: true}}
+	ctx := context.New(config.Project{})
+	require.NoError(t, CheckSigning(ctx, config.CommitAuthor{Name: "a"}))
+	require.NoError(t, CheckSigning(ctx, signed))
+
+	ctx.TokenType = context.TokenTypeGitHub
+	require.NoError(t, CheckSigning(ctx, signed))
+
+	ctx.TokenType = context.TokenTypeGitLab
+	require.NoError(t, CheckSigning(ctx, config.CommitAuthor{Name: "a"}))
+	require.ErrorIs(t, CheckSigning(ctx, signed), ErrSigningUnsupported)
+}
+
+func TestGitConfig(t *testing.T) {
+	t.Run("no signing", func(t *testing.T) {
+		require.Equal(t, [][]string{
+			{"user.name", "a"},
+			{"user.email", "a@b"},
+			{"commit.gpgSign", "false"},
+		}, GitConfig(config.CommitAuthor{
+			Name:  "a",
+			Email: "a@b",
+		}))
+	})
+
+	t.Run("committer", func(t *testing.T) {
+		require.Equal(t, [][]string{
+			{"user.name", "a"},
+			{"user.email", "a@b"},
+			{"committer.name", "bot"},
+			{"committer.email", "bot@b"},
+			{"commit.gpgSign", "false"},
+		}, GitConfig(config.CommitAuthor{
+			Name:  "a",
+			Email: "a@b",
+			Committer: config.Committer{
+				Name:  "bot",
+				Email: "bot@b",
+			},
+		}))
+	})
+
+	t.Run("openpgp", func(t *testing.T) {
+		require.Equal(t, [][]string{
+			{"user.name", "a"},
+			{"user.email", "a@b"},
+			{"commit.gpgSign", "true"},
+			{"user.signingKey", "ABCDEF"},
+			{"gpg.format", "openpgp"},
+			{"gpg.program", "gpg2"},
+		}, GitConfig(config.CommitAuthor{
+			Name:  "a",
+			Email: "a@b",
+			Signing: config.CommitSigning{
+				Enabled: true,
+				Key:     "ABCDEF",
+				Program: "gpg2",
+				Format:  "openpgp",
+			},
+		}))
+	})
+
+	t.Run("ssh", func(t *testing.T) {
+		require.Equal(t, [][]string{
+			{"user.name", "a"},
+			{"user.email", "a@b"},
+			{"commit.gpgSign", "true"},
+			{"user.signingKey", "/home/a/.ssh/id_ed25519.pub"},
+			{"gpg.format", "ssh"},
+			{"gpg.ssh.program", "ssh-keygen"},
+		}, GitConfig(config.CommitAuthor{
+			Name:  "a",
+			Email: "a@b",
+			Signing: config.CommitSigning{
+				Enabled: true,
+				Key:     "/home/a/.ssh/id_ed25519.pub",
+				Program: "ssh-keygen",
+				Format:  "ssh",
+			},
+		}))
+	})
+}
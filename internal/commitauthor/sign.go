@@ -0,0 +1,104 @@
+package commitauthor
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+)
+
+// Sign signs the given commit payload like git does, with the signing
+// program and key of the given author, and returns the armored signature.
+// The payload is the commit object without its signature, as hashed by git.
+func Sign(ctx *context.Context, author config.CommitAuthor, payload []byte) (string, error) {
+	signing := author.Signing
+	switch signing.Format {
+	case "", "openpgp":
+		return signGPG(ctx, orDefault(signing.Program, "gpg"), signingKey(author), payload)
+	case "x509":
+		return signGPG(ctx, orDefault(signing.Program, "gpgsm"), signingKey(author), payload)
+	case "ssh":
+		return signSSH(ctx, orDefault(signing.Program, "ssh-keygen"), signing.Key, payload)
+	default:
+		return "", fmt.Errorf("invalid commit signing format: %q", signing.Format)
+	}
+}
+
+// signingKey returns the key to sign with, which, like in git, is the
+// committer identity if no key is set.
+func signingKey(author config.CommitAuthor) string {
+	if author.Signing.Key != "" {
+		return author.Signing.Key
+	}
+	committer := Committer(author)
+	return committer.Name + " <" + committer.Email + ">"
+}
+
+func signGPG(ctx *context.Context, program, key string, payload []byte) (string, error) {
+	var stdout, stderr bytes.Buffer
+	/* #nosec */
+	cmd := exec.CommandContext(ctx, program, "--status-fd=2", "-bsau", key)
+	cmd.Env = ctx.Env.Strings()
+	cmd.Stdin = bytes.NewReader(payload)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("failed to sign commit: %w: %s", err, stderr.String())
+	}
+	if !strings.Contains(stderr.String(), "[GNUPG:] SIG_CREATED ") {
+		return "", fmt.Errorf("failed to sign commit: %s", stderr.String())
+	}
+	return stdout.String(), nil
+}
+
+func signSSH(ctx *context.Context, program, key string, payload []byte) (string, error) {
+	if key == "" {
+		return "", errors.New("failed to sign commit: ssh signing requires a key")
+	}
+	dir, err := os.MkdirTemp("", "goreleaser-commit-sign")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(dir)
+
+	args := []string{"-Y", "sign", "-n", "git", "-f"}
+	// like git, a literal public key means the private key is in the agent.
+	if literal := strings.TrimPrefix(key, "key::"); literal != key || strings.HasPrefix(key, "ssh-") {
+		path := filepath.Join(dir, "key.pub")
+		if err := os.WriteFile(path, []byte(literal), 0o600); err != nil {
+			return "", err
+		}
+		args = append(args, path, "-U")
+	} else {
+		args = append(args, key)
+	}
+	buffer := filepath.Join(dir, "commit")
+	if err := os.WriteFile(buffer, payload, 0o600); err != nil {
+		return "", err
+	}
+
+	/* #nosec */
+	cmd := exec.CommandContext(ctx, program, append(args, buffer)...)
+	cmd.Env = ctx.Env.Strings()
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return "", fmt.Errorf("failed to sign commit: %w: %s", err, string(out))
+	}
+	signature, err := os.ReadFile(buffer + ".sig")
+	if err != nil {
+		return "", fmt.Errorf("failed to sign commit: %w", err)
+	}
+	return string(signature), nil
+}
+
+func orDefault(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
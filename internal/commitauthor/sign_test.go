@@ -0,0 +1,91 @@
+package commitauthor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeProgram writes a shell script that records its arguments and standard
+// input next to it, and returns its path.
+func fakeProgram(tb testing.TB, name, script string) string {
+	tb.Helper()
+	dir := tb.TempDir()
+	path := filepath.Join(dir, name)
+	require.NoError(tb, os.WriteFile(path, []byte("#!/bin/sh\necho \"$@\" > "+path+".args\n"+script), 0o755))
+	return path
+}
+
+func TestSign(t *testing.T) {
+	ctx := context.New(config.Project{})
+
+	t.Run("openpgp", func(t *testing.T) {
+		gpg := fakeProgram(t, "gpg", `cat > /dev/null
+echo "[GNUPG:] SIG_CREATED D 1 8 00 1 ABC" >&2
+echo "-----BEGIN PGP SIGNATURE-----"
+`)
+		signature, err := Sign(ctx, config.CommitAuthor{
+			Signing: config.CommitSigning{Enabled: true, Key: "ABC", Program: gpg},
+		}, []byte("tree abc\n"))
+		require.NoError(t, err)
+		require.Equal(t, "-----BEGIN PGP SIGNATURE-----\n", signature)
+		args, err := os.ReadFile(gpg + ".args")
+		require.NoError(t, err)
+		require.Equal(t, "--status-fd=2 -bsau ABC\n", string(args))
+	})
+
+	t.Run("openpgp committer key", func(t *testing.T) {
+		gpg := fakeProgram(t, "gpg", `echo "[GNUPG:] SIG_CREATED D 1 8 00 1 ABC" >&2
+`)
+		_, err := Sign(ctx, config.CommitAuthor{
+			Name:      "a",
+			Email:     "a@b",
+			Committer: config.Committer{Name: "bot", Email: "bot@b"},
+			Signing:   config.CommitSigning{Enabled: true, Program: gpg},
+		}, []byte("tree abc\n"))
+		require.NoError(t, err)
+		args, err := os.ReadFile(gpg + ".args")
+		require.NoError(t, err)
+		require.Equal(t, "--status-fd=2 -bsau bot <bot@b>\n", string(args))
+	})
+
+	t.Run("openpgp not signed", func(t *testing.T) {
+		gpg := fakeProgram(t, "gpg", "echo nope >&2\n")
+		_, err := Sign(ctx, config.CommitAuthor{
+			Signing: config.CommitSigning{Enabled: true, Key: "ABC", Program: gpg},
+		}, []byte("tree abc\n"))
+		require.EqualError(t, err, "failed to sign commit: nope\n")
+	})
+
+	t.Run("ssh", func(t *testing.T) {
+		keygen := fakeProgram(t, "ssh-keygen", `for last; do :; done
+echo "-----BEGIN SSH SIGNATURE-----" > "$last.sig"
+`)
+		signature, err := Sign(ctx, config.CommitAuthor{
+			Signing: config.CommitSigning{Enabled: true, Format: "ssh", Key: "/keys/id_ed25519", Program: keygen},
+		}, []byte("tree abc\n"))
+		require.NoError(t, err)
+		require.Equal(t, "-----BEGIN SSH SIGNATURE-----\n", signature)
+		args, err := os.ReadFile(keygen + ".args")
+		require.NoError(t, err)
+		require.Contains(t, string(args), "-Y sign -n git -f /keys/id_ed25519 ")
+	})
+
+	t.Run("ssh without key", func(t *testing.T) {
+		_, err := Sign(ctx, config.CommitAuthor{
+			Signing: config.CommitSigning{Enabled: true, Format: "ssh"},
+		}, []byte("tree abc\n"))
+		require.EqualError(t, err, "failed to sign commit: ssh signing requires a key")
+	})
+
+	t.Run("invalid format", func(t *testing.T) {
+		_, err := Sign(ctx, config.CommitAuthor{
+			Signing: config.CommitSigning{Enabled: true, Format: "nope"},
+		}, []byte("tree abc\n"))
+		require.EqualError(t, err, `invalid commit signing format: "nope"`)
+	})
+}
@@ -0,0 +1,154 @@
+// Package configdiff compares a project configuration against a baseline
+// configuration, reporting the sections that were added, removed or
+// overridden.
+package configdiff
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"gopkg.in/yaml.v2"
+)
+
+// Kind of a change between the baseline and the current configuration.
+type Kind string
+
+const (
+	// Added means the section is only in the current configuration.
+	Added Kind = "added"
+	// Removed means the section is only in the baseline configuration.
+	Removed Kind = "removed"
+	// Overridden means the section is in both configurations, with different
+	// values.
+	Overridden Kind = "overridden"
+)
+
+// Change is a single difference between the baseline and the current
+// configuration.
+type Change struct {
+	Kind Kind
+	// Path of the section, e.g. builds[0].goos.
+	Path string
+}
+
+func (c Change) String() string {
+	return fmt.Sprintf("%s: %s", c.Kind, c.Path)
+}
+
+// Diff returns the changes needed to go from the baseline configuration to
+// the current one, sorted by path.
+func Diff(baseline, current config.Project) ([]Change, error) {
+	base, err := toTree(baseline)
+	if err != nil {
+		return nil, fmt.Errorf("baseline: %w", err)
+	}
+	curr, err := toTree(current)
+	if err != nil {
+		return nil, err
+	}
+	var changes []Change
+	walk("", base, curr, &changes)
+	return changes, nil
+}
+
+// toTree converts the project into a generic yaml tree, so unset fields are
+// omitted and both configs are compared in the same normalized form.
+func toTree(project config.Project) (interface{}, error) {
+	bts, err := yaml.Marshal(project)
+	if err != nil {
+		return nil, err
+	}
+	var tree interface{}
+	return tree, yaml.Unmarshal(bts, &tree)
+}
+
+func walk(path string, base, curr interface{}, changes *[]Change) {
+	baseMap, baseIsMap := toMap(base)
+	currMap, currIsMap := toMap(curr)
+	if baseIsMap && currIsMap {
+		for _, key := range keys(baseMap, currMap) {
+			b, inBase := baseMap[key]
+			c, inCurr := currMap[key]
+			p := join(path, key)
+			switch {
+			case !inBase:
+				*changes = append(*changes, Change{Kind: Added, Path: p})
+			case !inCurr:
+				*changes = append(*changes, Change{Kind: Removed, Path: p})
+			default:
+				walk(p, b, c, changes)
+			}
+		}
+		return
+	}
+
+	// lists of sections, e.g. builds, are compared item by item, scalar lists
+	// such as goos are compared as a whole.
+	baseList, baseIsList := base.([]interface{})
+	currList, currIsList := curr.([]interface{})
+	if baseIsList && currIsList && hasSections(baseList) && hasSections(currList) {
+		for i := 0; i < len(baseList) || i < len(currList); i++ {
+			p := fmt.Sprintf("%s[%d]", path, i)
+			switch {
+			case i >= len(baseList):
+				*changes = append(*changes, Change{Kind: Added, Path: p})
+			case i >= len(currList):
+				*changes = append(*changes, Change{Kind: Removed, Path: p})
+			default:
+				walk(p, baseList[i], currList[i], changes)
+			}
+		}
+		return
+	}
+
+	if !reflect.DeepEqual(base, curr) {
+		*changes = append(*changes, Change{Kind: Overridden, Path: path})
+	}
+}
+
+func hasSections(list []interface{}) bool {
+	for _, item := range list {
+		if _, ok := item.(map[interface{}]interface{}); !ok {
+			return false
+		}
+	}
+	return len(list) > 0
+}
+
+// toMap converts a yaml mapping into a map keyed by strings, as keys such as
+// the ones in replacements might be decoded as numbers.
+func toMap(v interface{}) (map[string]interface{}, bool) {
+	m, ok := v.(map[interface{}]interface{})
+	if !ok {
+		return nil, false
+	}
+	result := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		result[fmt.Sprint(k)] = v
+	}
+	return result, true
+}
+
+func keys(maps ...map[string]interface{}) []string {
+	seen := map[string]bool{}
+	var result []string
+	for _, m := range maps {
+		for k := range m {
+			if !seen[k] {
+				seen[k] = true
+				result = append(result, k)
+			}
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+func join(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,65 @@
+package configdiff
+
+import (
+	"testing"
+
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffEqual(t *testing.T) {
+	project := config.Project{
+		Builds: []config.Build{{Goos: []string{"linux"}}},
+	}
+	changes, err := Diff(project, project)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+}
+
+func TestDiff(t *testing.T) {
+	baseline := config.Project{
+		Builds: []config.Build{
+			{Goos: []string{"linux", "darwin"}, Env: []string{"CGO_ENABLED=0"}},
+		},
+		Checksum: config.Checksum{NameTemplate: "checksums.txt"},
+		Snapshot: config.Snapshot{NameTemplate: "{{ .Tag }}-next"},
+		Archives: []config.Archive{{
+			Replacements: map[string]string{"386": "i386", "amd64": "x86_64"},
+		}},
+	}
+	current := config.Project{
+		Builds: []config.Build{
+			{Goos: []string{"linux"}, Env: []string{"CGO_ENABLED=0"}},
+			{ID: "other"},
+		},
+		Checksum: config.Checksum{NameTemplate: "checksums.txt", Algorithm: "sha512"},
+		Archives: []config.Archive{{
+			Replacements: map[string]string{"386": "i386", "amd64": "amd64"},
+		}},
+	}
+	changes, err := Diff(baseline, current)
+	require.NoError(t, err)
+	require.Equal(t, []Change{
+		{Kind: Overridden, Path: "archives[0].replacements.amd64"},
+		{Kind: Overridden, Path: "builds[0].goos"},
+		{Kind: Added, Path: "builds[1]"},
+		{Kind: Added, Path: "checksum.algorithm"},
+		{Kind: Removed, Path: "snapshot"},
+	}, changes)
+}
+
+func TestDiffRemovedSections(t *testing.T) {
+	baseline := config.Project{
+		Builds: []config.Build{{ID: "a"}, {ID: "b"}},
+	}
+	current := config.Project{
+		Builds: []config.Build{{ID: "a"}},
+	}
+	changes, err := Diff(baseline, current)
+	require.NoError(t, err)
+	require.Equal(t, []Change{{Kind: Removed, Path: "builds[1]"}}, changes)
+}
+
+func TestChangeString(t *testing.T) {
+	require.Equal(t, "added: builds[1]", Change{Kind: Added, Path: "builds[1]"}.String())
+}
@@ -24,14 +24,16 @@ func NewWriter(ctx *context.Context) io.Writer {
 
 type writer struct {
 	ctx  *context.Context
-	once sync.Once
+	lock sync.Mutex
 }
 
 func (w *writer) Write(p []byte) (n int, err error) {
-	w.once.Do(func() {
-		w.ctx.Deprecated = true
-	})
-	log.Warn(color.New(color.Bold, color.FgHiYellow).Sprintf("DEPRECATED: " + strings.TrimSuffix(string(p), "\n")))
+	msg := strings.TrimSuffix(string(p), "\n")
+	w.lock.Lock()
+	w.ctx.Deprecated = true
+	w.ctx.Deprecations = append(w.ctx.Deprecations, context.Deprecation{Message: msg})
+	w.lock.Unlock()
+	log.FromContext(w.ctx).Warn(color.New(color.Bold, color.FgHiYellow).Sprint("DEPRECATED: " + msg))
 	return len(p), nil
 }
 
@@ -40,29 +42,53 @@ func Notice(ctx *context.Context, property string) {
 	NoticeCustom(ctx, property, "`{{ .Property }}` should not be used anymore, check {{ .URL }} for more info")
 }
 
+// NoticeAt warns the user about the deprecation of the given property, used
+// at the given YAML path, e.g. dockers[0].use_buildx, suggesting the given
+// YAML snippet instead.
+func NoticeAt(ctx *context.Context, property, path, replacement string) {
+	notice(ctx, context.Deprecation{
+		Property:    property,
+		Path:        path,
+		Replacement: replacement,
+	}, "`{{ .Path }}` should not be used anymore{{ with .Replacement }}, use `{{ . }}` instead{{ end }}, check {{ .URL }} for more info")
+}
+
 // NoticeCustom warns the user about the deprecation of the given property.
 func NoticeCustom(ctx *context.Context, property, tmpl string) {
+	notice(ctx, context.Deprecation{Property: property}, tmpl)
+}
+
+func notice(ctx *context.Context, deprecation context.Deprecation, tmpl string) {
 	ctx.Deprecated = true
+	ctx.Deprecations = append(ctx.Deprecations, deprecation)
 	cli.Default.Padding += 3
 	defer func() {
 		cli.Default.Padding -= 3
 	}()
-	// replaces . and _ with -
-	url := baseURL + strings.NewReplacer(
-		".", "",
-		"_", "",
-	).Replace(property)
 	var out bytes.Buffer
 	if err := template.Must(template.New("deprecation").Parse("DEPRECATED: "+tmpl)).Execute(&out, templateData{
-		URL:      url,
-		Property: property,
+		URL:         URL(deprecation.Property),
+		Property:    deprecation.Property,
+		Path:        deprecation.Path,
+		Replacement: deprecation.Replacement,
 	}); err != nil {
 		panic(err) // this should never happen
 	}
-	log.Warn(color.New(color.Bold, color.FgHiYellow).Sprintf(out.String()))
+	log.FromContext(ctx).Warn(color.New(color.Bold, color.FgHiYellow).Sprint(out.String()))
+}
+
+// URL returns the link to the deprecation notice of the given property.
+func URL(property string) string {
+	// removes . and _
+	return baseURL + strings.NewReplacer(
+		".", "",
+		"_", "",
+	).Replace(property)
 }
 
 type templateData struct {
-	URL      string
-	Property string
+	URL         string
+	Property    string
+	Path        string
+	Replacement string
 }
@@ -24,6 +24,7 @@ func TestNotice(t *testing.T) {
 	Notice(ctx, "foo.bar.whatever")
 	log.Info("last")
 	require.True(t, ctx.Deprecated)
+	require.Equal(t, []context.Deprecation{{Property: "foo.bar.whatever"}}, ctx.Deprecations)
 
 	golden.RequireEqualTxt(t, w.Bytes())
 }
@@ -55,6 +56,31 @@ func TestWriter(t *testing.T) {
 	_, err := ww.Write([]byte("foo bar\n"))
 	require.NoError(t, err)
 	require.True(t, ctx.Deprecated)
+	require.Equal(t, []context.Deprecation{{Message: "foo bar"}}, ctx.Deprecations)
 
 	golden.RequireEqualTxt(t, w.Bytes())
 }
+
+func TestNoticeAt(t *testing.T) {
+	var w bytes.Buffer
+
+	color.NoColor = true
+	log.SetHandler(cli.New(&w))
+
+	log.Info("first")
+	ctx := context.New(config.Project{})
+	NoticeAt(ctx, "docker.use_buildx", "dockers[1].use_buildx", "use: buildx")
+	log.Info("last")
+	require.True(t, ctx.Deprecated)
+	require.Equal(t, []context.Deprecation{{
+		Property:    "docker.use_buildx",
+		Path:        "dockers[1].use_buildx",
+		Replacement: "use: buildx",
+	}}, ctx.Deprecations)
+
+	golden.RequireEqualTxt(t, w.Bytes())
+}
+
+func TestURL(t *testing.T) {
+	require.Equal(t, "https://goreleaser.com/deprecations#dockerusebuildx", URL("docker.use_buildx"))
+}
@@ -0,0 +1,69 @@
+package deprecate
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/apex/log"
+	"github.com/fatih/color"
+	"github.com/goreleaser/goreleaser/pkg/context"
+)
+
+// ErrDeprecated happens when the config uses deprecated options and
+// --fail-on-deprecated is set.
+var ErrDeprecated = errors.New("config uses deprecated options and --fail-on-deprecated is set, check logs above for details")
+
+// Check fails if the config used deprecated options so far and
+// failOnDeprecated is set.
+func Check(ctx *context.Context, failOnDeprecated bool) error {
+	if !failOnDeprecated || !ctx.Deprecated {
+		return nil
+	}
+	Report(ctx)
+	return ErrDeprecated
+}
+
+// Report logs every deprecated option the config used, with its path and
+// replacement, so they can be fixed before they are removed.
+// The report is only logged, nothing is sent anywhere.
+func Report(ctx *context.Context) {
+	if !ctx.Deprecated {
+		return
+	}
+	if len(ctx.Deprecations) == 0 {
+		log.FromContext(ctx).Warn(color.New(color.Bold).Sprintf("your config is using deprecated properties, check logs above for details"))
+		return
+	}
+	log.FromContext(ctx).Warn(color.New(color.Bold).Sprintf("deprecations and upcoming removals, deprecated options are removed ~6 months after their deprecation:"))
+	for _, d := range ctx.Deprecations {
+		entry := log.FromContext(ctx).WithField("property", Name(d))
+		if d.Replacement != "" {
+			entry = entry.WithField("replacement", d.Replacement)
+		}
+		if d.Property != "" {
+			entry = entry.WithField("details", URL(d.Property))
+		}
+		entry.Warn(Message(d))
+	}
+}
+
+// Name returns the YAML path of the given deprecation, or its property if
+// the path is unknown.
+func Name(d context.Deprecation) string {
+	if d.Path != "" {
+		return d.Path
+	}
+	return d.Property
+}
+
+// Message describes the given deprecation.
+func Message(d context.Deprecation) string {
+	if d.Property == "" {
+		return d.Message
+	}
+	msg := fmt.Sprintf("`%s` should not be used anymore", Name(d))
+	if d.Replacement != "" {
+		msg += fmt.Sprintf(", use `%s` instead", d.Replacement)
+	}
+	return msg
+}
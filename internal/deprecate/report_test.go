@@ -0,0 +1,33 @@
+package deprecate
+
+import (
+	"testing"
+
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessage(t *testing.T) {
+	require.Equal(t, "`docker.use_buildx` should not be used anymore", Message(context.Deprecation{
+		Property: "docker.use_buildx",
+	}))
+	require.Equal(t, "`dockers[0].use_buildx` should not be used anymore, use `use: buildx` instead", Message(context.Deprecation{
+		Property:    "docker.use_buildx",
+		Path:        "dockers[0].use_buildx",
+		Replacement: "use: buildx",
+	}))
+	require.Equal(t, "nfpm foo is deprecated", Message(context.Deprecation{
+		Message: "nfpm foo is deprecated",
+	}))
+}
+
+func TestCheck(t *testing.T) {
+	ctx := context.New(config.Project{})
+	require.NoError(t, Check(ctx, true))
+
+	ctx.Deprecated = true
+	ctx.Deprecations = []context.Deprecation{{Property: "docker.use_buildx", Path: "dockers[0].use_buildx"}}
+	require.NoError(t, Check(ctx, false))
+	require.ErrorIs(t, Check(ctx, true), ErrDeprecated)
+}
@@ -0,0 +1,307 @@
+// Package distdiff compares the dist folders of two releases: their
+// artifacts, the sizes and checksums of their files, and their metadata.
+package distdiff
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Kind of a difference between the two dist folders.
+type Kind string
+
+const (
+	// Added means the item is only in the second dist folder.
+	Added Kind = "added"
+	// Removed means the item is only in the first dist folder.
+	Removed Kind = "removed"
+	// Changed means the item is in both dist folders, with different values.
+	Changed Kind = "changed"
+)
+
+// Section of the dist folder a change is in.
+type Section string
+
+const (
+	// Artifacts are the ones listed in artifacts.json.
+	Artifacts Section = "artifact"
+	// Metadata is the release metadata in metadata.json.
+	Metadata Section = "metadata"
+)
+
+// Change is a single difference between the two dist folders.
+type Change struct {
+	Kind    Kind    `json:"kind"`
+	Section Section `json:"section"`
+	// Name of the artifact, e.g. "foo_linux_amd64.tar.gz (Archive)", or of
+	// the metadata field, e.g. "semver.major".
+	Name string `json:"name"`
+	// Field of the artifact that changed, e.g. "size".
+	Field string `json:"field,omitempty"`
+	Old   string `json:"old,omitempty"`
+	New   string `json:"new,omitempty"`
+}
+
+func (c Change) String() string {
+	name := string(c.Section) + " " + c.Name
+	if c.Field != "" {
+		name += " " + c.Field
+	}
+	if c.Kind == Changed {
+		return fmt.Sprintf("%s: %s: %s -> %s", c.Kind, name, c.Old, c.New)
+	}
+	return fmt.Sprintf("%s: %s", c.Kind, name)
+}
+
+// Dirs returns the differences between the dist folders a and b, artifacts
+// first, sorted by name.
+func Dirs(a, b string) ([]Change, error) {
+	before, err := load(a)
+	if err != nil {
+		return nil, err
+	}
+	after, err := load(b)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []Change
+	for _, key := range names(before.artifacts, after.artifacts) {
+		old, inBefore := before.artifacts[key]
+		curr, inAfter := after.artifacts[key]
+		switch {
+		case !inBefore:
+			changes = append(changes, Change{Kind: Added, Section: Artifacts, Name: key})
+		case !inAfter:
+			changes = append(changes, Change{Kind: Removed, Section: Artifacts, Name: key})
+		default:
+			for _, field := range keys(old, curr) {
+				if old[field] != curr[field] {
+					changes = append(changes, Change{
+						Kind:    Changed,
+						Section: Artifacts,
+						Name:    key,
+						Field:   field,
+						Old:     old[field],
+						New:     curr[field],
+					})
+				}
+			}
+		}
+	}
+	for _, key := range keys(before.metadata, after.metadata) {
+		old, inBefore := before.metadata[key]
+		curr, inAfter := after.metadata[key]
+		switch {
+		case !inBefore:
+			changes = append(changes, Change{Kind: Added, Section: Metadata, Name: key, New: curr})
+		case !inAfter:
+			changes = append(changes, Change{Kind: Removed, Section: Metadata, Name: key, Old: old})
+		case old != curr:
+			changes = append(changes, Change{Kind: Changed, Section: Metadata, Name: key, Old: old, New: curr})
+		}
+	}
+	return changes, nil
+}
+
+// dist is the comparable content of a dist folder.
+type dist struct {
+	// artifacts by name and type, with their fields.
+	artifacts map[string]map[string]string
+	// metadata fields, flattened.
+	metadata map[string]string
+}
+
+type artifact struct {
+	Name   string                 `json:"name"`
+	Path   string                 `json:"path"`
+	Goos   string                 `json:"goos"`
+	Goarch string                 `json:"goarch"`
+	Goarm  string                 `json:"goarm"`
+	Gomips string                 `json:"gomips"`
+	Type   string                 `json:"type"`
+	Extra  map[string]interface{} `json:"extra"`
+}
+
+func load(path string) (dist, error) {
+	result := dist{
+		artifacts: map[string]map[string]string{},
+		metadata:  map[string]string{},
+	}
+	if info, err := os.Stat(path); err != nil || !info.IsDir() {
+		return result, fmt.Errorf("%s is not a dist folder", path)
+	}
+
+	bts, err := os.ReadFile(filepath.Join(path, "artifacts.json"))
+	if err != nil {
+		return result, fmt.Errorf("%s is not a dist folder: %w", path, err)
+	}
+	var artifacts []artifact
+	if err := json.Unmarshal(bts, &artifacts); err != nil {
+		return result, fmt.Errorf("failed to parse %s: %w", filepath.Join(path, "artifacts.json"), err)
+	}
+	for _, a := range artifacts {
+		fields, err := fieldsOf(path, a)
+		if err != nil {
+			return result, err
+		}
+		result.artifacts[keyOf(a)] = fields
+	}
+
+	bts, err = os.ReadFile(filepath.Join(path, "metadata.json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return result, nil
+	}
+	if err != nil {
+		return result, err
+	}
+	var md interface{}
+	if err := json.Unmarshal(bts, &md); err != nil {
+		return result, fmt.Errorf("failed to parse %s: %w", filepath.Join(path, "metadata.json"), err)
+	}
+	flatten("", md, result.metadata)
+	return result, nil
+}
+
+// keyOf identifies an artifact in both dist folders, e.g.
+// "foo (Binary linux/arm/7)".
+func keyOf(a artifact) string {
+	platform := strings.Join(nonEmpty(a.Goos, a.Goarch, a.Goarm, a.Gomips), "/")
+	return fmt.Sprintf("%s (%s)", a.Name, strings.Join(nonEmpty(a.Type, platform), " "))
+}
+
+// fieldsOf returns the size and checksum of the artifact file, and its
+// scalar extra fields.
+// Nested extra fields hold other artifacts and paths, which differ between
+// dist folders anyway, so they are not compared.
+func fieldsOf(dist string, a artifact) (map[string]string, error) {
+	fields := map[string]string{}
+	for k, v := range a.Extra {
+		switch v.(type) {
+		case string, float64, bool:
+			fields["extra."+k] = fmt.Sprint(v)
+		}
+	}
+	if a.Path == "" {
+		return fields, nil
+	}
+	path := resolve(dist, a.Path)
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		fields["file"] = "missing"
+		return fields, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return fields, nil
+	}
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return nil, fmt.Errorf("failed to checksum %s: %w", path, err)
+	}
+	fields["size"] = fmt.Sprint(info.Size())
+	fields["sha256"] = hex.EncodeToString(h.Sum(nil))
+	return fields, nil
+}
+
+// resolve returns the path of an artifact file inside the given dist
+// folder.
+// Paths in artifacts.json start with the dist folder the release was built
+// in, which might have been renamed since, so the file is looked up inside
+// the given folder first.
+func resolve(dist, path string) string {
+	path = filepath.ToSlash(path)
+	if !filepath.IsAbs(path) {
+		parts := strings.SplitN(strings.TrimPrefix(path, "./"), "/", 2)
+		if len(parts) == 2 {
+			rebased := filepath.Join(dist, parts[1])
+			if _, err := os.Stat(rebased); err == nil {
+				return rebased
+			}
+		}
+	}
+	return filepath.FromSlash(path)
+}
+
+// flatten adds the leaves of the given json value to result, with keys like
+// "semver.major".
+func flatten(prefix string, v interface{}, result map[string]string) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, item := range v {
+			flatten(join(prefix, k), item, result)
+		}
+	case []interface{}:
+		for i, item := range v {
+			flatten(fmt.Sprintf("%s[%d]", prefix, i), item, result)
+		}
+	case nil:
+	default:
+		result[prefix] = fmt.Sprint(v)
+	}
+}
+
+func join(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
+
+func nonEmpty(values ...string) []string {
+	var result []string
+	for _, v := range values {
+		if v != "" {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+func names(maps ...map[string]map[string]string) []string {
+	var result []string
+	for _, m := range maps {
+		for k := range m {
+			result = append(result, k)
+		}
+	}
+	return unique(result)
+}
+
+func keys(maps ...map[string]string) []string {
+	var result []string
+	for _, m := range maps {
+		for k := range m {
+			result = append(result, k)
+		}
+	}
+	return unique(result)
+}
+
+func unique(values []string) []string {
+	seen := map[string]bool{}
+	var result []string
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			result = append(result, v)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
@@ -0,0 +1,98 @@
+package distdiff
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// writeDist writes a dist folder with the given artifacts.json and
+// metadata.json contents, and files.
+func writeDist(tb testing.TB, artifacts, metadata string, files map[string]string) string {
+	tb.Helper()
+	dist := tb.TempDir()
+	require.NoError(tb, os.WriteFile(filepath.Join(dist, "artifacts.json"), []byte(artifacts), 0o644))
+	if metadata != "" {
+		require.NoError(tb, os.WriteFile(filepath.Join(dist, "metadata.json"), []byte(metadata), 0o644))
+	}
+	for name, content := range files {
+		path := filepath.Join(dist, name)
+		require.NoError(tb, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(tb, os.WriteFile(path, []byte(content), 0o644))
+	}
+	return dist
+}
+
+const artifactsJSON = `[
+  {"name":"foo","path":"dist/foo_linux_amd64_v1/foo","goos":"linux","goarch":"amd64","type":"Binary","extra":{"ID":"foo","Builds":[]}},
+  {"name":"foo.tar.gz","path":"dist/foo.tar.gz","goos":"linux","goarch":"amd64","type":"Archive","extra":{"Format":"tar.gz"}}
+]`
+
+func TestDirsEqual(t *testing.T) {
+	files := map[string]string{"foo_linux_amd64_v1/foo": "bin", "foo.tar.gz": "archive"}
+	a := writeDist(t, artifactsJSON, `{"version":"1.0.0"}`, files)
+	b := writeDist(t, artifactsJSON, `{"version":"1.0.0"}`, files)
+	changes, err := Dirs(a, b)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+}
+
+func TestDirs(t *testing.T) {
+	a := writeDist(t, artifactsJSON, `{"version":"1.0.0","semver":{"major":1,"minor":0},"snapshot":false}`, map[string]string{
+		"foo_linux_amd64_v1/foo": "bin",
+		"foo.tar.gz":             "archive",
+	})
+	b := writeDist(t, `[
+  {"name":"foo","path":"dist/foo_linux_amd64_v1/foo","goos":"linux","goarch":"amd64","type":"Binary","extra":{"ID":"bar"}},
+  {"name":"foo.tar.gz","path":"dist/foo.tar.gz","goos":"linux","goarch":"amd64","type":"Archive","extra":{"Format":"tar.gz"}},
+  {"name":"foo.zip","path":"dist/foo.zip","goos":"linux","goarch":"amd64","type":"Archive"}
+]`, `{"version":"1.1.0","semver":{"major":1,"minor":1}}`, map[string]string{
+		"foo_linux_amd64_v1/foo": "bin",
+		"foo.tar.gz":             "bigger archive",
+		"foo.zip":                "zip",
+	})
+	changes, err := Dirs(a, b)
+	require.NoError(t, err)
+	require.Equal(t, []Change{
+		{Kind: Changed, Section: Artifacts, Name: "foo (Binary linux/amd64)", Field: "extra.ID", Old: "foo", New: "bar"},
+		{Kind: Changed, Section: Artifacts, Name: "foo.tar.gz (Archive linux/amd64)", Field: "sha256", Old: "0eb3e36bfb24dcd9bb1d1bece1531216b59539a8fde17ee80224af0653c92aa3", New: "b58121784171a365d9bba2b7423b34543c7550c0c566588b14cda60709d1d703"},
+		{Kind: Changed, Section: Artifacts, Name: "foo.tar.gz (Archive linux/amd64)", Field: "size", Old: "7", New: "14"},
+		{Kind: Added, Section: Artifacts, Name: "foo.zip (Archive linux/amd64)"},
+		{Kind: Changed, Section: Metadata, Name: "semver.minor", Old: "0", New: "1"},
+		{Kind: Removed, Section: Metadata, Name: "snapshot", Old: "false"},
+		{Kind: Changed, Section: Metadata, Name: "version", Old: "1.0.0", New: "1.1.0"},
+	}, changes)
+	require.Equal(t, "changed: metadata version: 1.0.0 -> 1.1.0", changes[6].String())
+	require.Equal(t, "added: artifact foo.zip (Archive linux/amd64)", changes[3].String())
+}
+
+func TestDirsMissingFile(t *testing.T) {
+	a := writeDist(t, artifactsJSON, "", map[string]string{
+		"foo_linux_amd64_v1/foo": "bin",
+		"foo.tar.gz":             "archive",
+	})
+	b := writeDist(t, artifactsJSON, "", map[string]string{
+		"foo_linux_amd64_v1/foo": "bin",
+	})
+	changes, err := Dirs(a, b)
+	require.NoError(t, err)
+	require.Len(t, changes, 3)
+	require.Equal(t, Change{Kind: Changed, Section: Artifacts, Name: "foo.tar.gz (Archive linux/amd64)", Field: "file", Old: "", New: "missing"}, changes[0])
+}
+
+func TestDirsNotADist(t *testing.T) {
+	a := writeDist(t, artifactsJSON, "", nil)
+	_, err := Dirs(a, t.TempDir())
+	require.Error(t, err)
+	_, err = Dirs(a, filepath.Join(t.TempDir(), "nope"))
+	require.Error(t, err)
+}
+
+func TestDirsInvalidArtifacts(t *testing.T) {
+	a := writeDist(t, artifactsJSON, "", nil)
+	b := writeDist(t, "{", "", nil)
+	_, err := Dirs(a, b)
+	require.Error(t, err)
+}
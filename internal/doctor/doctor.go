@@ -0,0 +1,259 @@
+// Package doctor analyzes a repository and its configuration, suggesting the
+// configuration sections of features it looks like it could use.
+package doctor
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/goreleaser/goreleaser/pkg/config"
+)
+
+// Suggestion is a configuration section the project could use.
+type Suggestion struct {
+	Title string
+	// Reason explains why the section is suggested.
+	Reason string
+	// Docs is the URL of the documentation of the section.
+	Docs string
+	// YAML is the configuration to be added.
+	YAML string
+}
+
+// Suggest returns the suggestions for the repository in the given directory
+// and its configuration. Owner is used in the suggested image names.
+func Suggest(dir string, cfg config.Project, owner string) ([]Suggestion, error) {
+	repo, err := scan(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []Suggestion
+	for _, check := range []func(repository, config.Project, string) (Suggestion, bool){
+		suggestDockers,
+		suggestCompletions,
+		suggestManpages,
+		suggestWindowsZip,
+	} {
+		if s, ok := check(repo, cfg, owner); ok {
+			result = append(result, s)
+		}
+	}
+	return result, nil
+}
+
+// repository holds what was found in the repository.
+type repository struct {
+	dockerfile string
+	cobra      bool
+	manpages   bool
+}
+
+// scan looks for the files and dependencies the checks need.
+func scan(dir string) (repository, error) {
+	var repo repository
+
+	for _, pattern := range []string{"Dockerfile", "Dockerfile.*", "*.Dockerfile"} {
+		matches, err := filepath.Glob(filepath.Join(dir, pattern))
+		if err != nil {
+			return repo, err
+		}
+		if len(matches) > 0 {
+			repo.dockerfile = filepath.Base(matches[0])
+			break
+		}
+	}
+
+	gomod, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+	if err != nil && !os.IsNotExist(err) {
+		return repo, err
+	}
+	repo.cobra = requires(gomod, "github.com/spf13/cobra")
+	repo.manpages = requires(gomod, "github.com/muesli/mango") ||
+		requires(gomod, "github.com/muesli/mango-cobra")
+	if repo.manpages || !repo.cobra {
+		return repo, nil
+	}
+
+	// cobra/doc is in the cobra module, so look for it in the imports.
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			switch d.Name() {
+			case ".git", "vendor", "testdata", "dist", "node_modules":
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(path, ".go") {
+			return nil
+		}
+		bts, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if bytes.Contains(bts, []byte(`"github.com/spf13/cobra/doc"`)) {
+			repo.manpages = true
+			return errFound
+		}
+		return nil
+	})
+	if errors.Is(err, errFound) {
+		err = nil
+	}
+	return repo, err
+}
+
+// errFound stops walking the repository once cobra/doc was found.
+var errFound = errors.New("found")
+
+// requires reports whether the given go.mod requires the given module, or
+// any module under it.
+func requires(gomod []byte, module string) bool {
+	s := bufio.NewScanner(bytes.NewReader(gomod))
+	for s.Scan() {
+		fields := strings.Fields(strings.TrimPrefix(strings.TrimSpace(s.Text()), "require"))
+		if len(fields) > 0 && (fields[0] == module || strings.HasPrefix(fields[0], module+"/")) {
+			return true
+		}
+	}
+	return false
+}
+
+func suggestDockers(repo repository, cfg config.Project, owner string) (Suggestion, bool) {
+	if repo.dockerfile == "" || len(cfg.Dockers) > 0 || len(cfg.Kos) > 0 {
+		return Suggestion{}, false
+	}
+	var dockerfile string
+	if repo.dockerfile != "Dockerfile" {
+		dockerfile = "\n    dockerfile: " + repo.dockerfile
+	}
+	return Suggestion{
+		Title:  "Docker images",
+		Reason: "the repository has a " + repo.dockerfile + ", but no images are built",
+		Docs:   "https://goreleaser.com/customization/docker/",
+		YAML: `dockers:
+  - image_templates:
+      - "ghcr.io/` + owner + `/{{ .ProjectName }}:{{ .Version }}"
+      - "ghcr.io/` + owner + `/{{ .ProjectName }}:latest"` + dockerfile + `
+`,
+	}, true
+}
+
+func suggestCompletions(repo repository, cfg config.Project, _ string) (Suggestion, bool) {
+	if !repo.cobra || mentions(cfg, "completion") {
+		return Suggestion{}, false
+	}
+	return Suggestion{
+		Title:  "Shell completions",
+		Reason: "the project uses cobra, which has a completion command, but no completions are shipped",
+		Docs:   "https://goreleaser.com/customization/archive/",
+		YAML: `before:
+  hooks:
+    - sh -c 'mkdir -p completions && for sh in bash zsh fish; do go run . completion "$sh" > "completions/{{ .ProjectName }}.$sh"; done'
+archives:
+  - files:
+      - README*
+      - LICENSE*
+      - completions/*
+`,
+	}, true
+}
+
+func suggestManpages(repo repository, cfg config.Project, _ string) (Suggestion, bool) {
+	if !repo.manpages || mentions(cfg, "man") {
+		return Suggestion{}, false
+	}
+	return Suggestion{
+		Title:  "Manpages",
+		Reason: "the project can generate manpages, but none are shipped; the hook assumes a `man` command that prints them",
+		Docs:   "https://goreleaser.com/customization/archive/",
+		YAML: `before:
+  hooks:
+    - sh -c 'mkdir -p manpages && go run . man | gzip -c -9 > manpages/{{ .ProjectName }}.1.gz'
+archives:
+  - files:
+      - README*
+      - LICENSE*
+      - manpages/*
+`,
+	}, true
+}
+
+func suggestWindowsZip(_ repository, cfg config.Project, _ string) (Suggestion, bool) {
+	if !buildsWindows(cfg) {
+		return Suggestion{}, false
+	}
+	archives := cfg.Archives
+	if len(archives) == 0 {
+		archives = []config.Archive{{}}
+	}
+	for _, archive := range archives {
+		if windowsFormat(archive) == "zip" || archive.Format == "binary" {
+			continue
+		}
+		return Suggestion{
+			Title:  "Zip archives for Windows",
+			Reason: "the project is built for Windows, where zip files are easier to open than tarballs",
+			Docs:   "https://goreleaser.com/customization/archive/",
+			YAML: `archives:
+  - format_overrides:
+      - goos: windows
+        format: zip
+`,
+		}, true
+	}
+	return Suggestion{}, false
+}
+
+// buildsWindows reports whether any of the builds targets Windows.
+func buildsWindows(cfg config.Project) bool {
+	for _, build := range cfg.Builds {
+		for _, goos := range build.Goos {
+			if goos == "windows" {
+				return true
+			}
+		}
+		for _, target := range build.Targets {
+			if strings.HasPrefix(target, "windows_") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// windowsFormat returns the format the given archive uses for Windows.
+func windowsFormat(archive config.Archive) string {
+	for _, override := range archive.FormatOverrides {
+		if override.Goos == "windows" {
+			return override.Format
+		}
+	}
+	return archive.Format
+}
+
+// mentions reports whether any before hook or archive file mentions the
+// given word.
+func mentions(cfg config.Project, word string) bool {
+	for _, hook := range cfg.Before.Hooks {
+		if strings.Contains(hook.Cmd, word) {
+			return true
+		}
+	}
+	for _, archive := range cfg.Archives {
+		for _, file := range archive.Files {
+			if strings.Contains(file.Source, word) {
+				return true
+			}
+		}
+	}
+	return false
+}
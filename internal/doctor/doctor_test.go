@@ -0,0 +1,166 @@
+package doctor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSuggestNothing(t *testing.T) {
+	suggestions, err := Suggest(t.TempDir(), config.Project{}, "foo")
+	require.NoError(t, err)
+	require.Empty(t, suggestions)
+}
+
+func TestSuggestDockers(t *testing.T) {
+	t.Run("dockerfile", func(t *testing.T) {
+		dir := t.TempDir()
+		writeFile(t, dir, "Dockerfile", "FROM scratch")
+		suggestions, err := Suggest(dir, config.Project{}, "foo")
+		require.NoError(t, err)
+		require.Len(t, suggestions, 1)
+		require.Equal(t, "Docker images", suggestions[0].Title)
+		require.Equal(t, `dockers:
+  - image_templates:
+      - "ghcr.io/foo/{{ .ProjectName }}:{{ .Version }}"
+      - "ghcr.io/foo/{{ .ProjectName }}:latest"
+`, suggestions[0].YAML)
+	})
+
+	t.Run("other dockerfile", func(t *testing.T) {
+		dir := t.TempDir()
+		writeFile(t, dir, "release.Dockerfile", "FROM scratch")
+		suggestions, err := Suggest(dir, config.Project{}, "foo")
+		require.NoError(t, err)
+		require.Len(t, suggestions, 1)
+		require.Contains(t, suggestions[0].YAML, "    dockerfile: release.Dockerfile\n")
+	})
+
+	t.Run("already configured", func(t *testing.T) {
+		dir := t.TempDir()
+		writeFile(t, dir, "Dockerfile", "FROM scratch")
+		suggestions, err := Suggest(dir, config.Project{
+			Dockers: []config.Docker{{}},
+		}, "foo")
+		require.NoError(t, err)
+		require.Empty(t, suggestions)
+	})
+}
+
+func TestSuggestCompletionsAndManpages(t *testing.T) {
+	gomod := `module foo
+
+go 1.17
+
+require (
+	github.com/spf13/cobra v1.4.0
+)
+`
+	t.Run("cobra", func(t *testing.T) {
+		dir := t.TempDir()
+		writeFile(t, dir, "go.mod", gomod)
+		suggestions, err := Suggest(dir, config.Project{}, "foo")
+		require.NoError(t, err)
+		require.Equal(t, []string{"Shell completions"}, titles(suggestions))
+	})
+
+	t.Run("cobra doc", func(t *testing.T) {
+		dir := t.TempDir()
+		writeFile(t, dir, "go.mod", gomod)
+		writeFile(t, dir, "cmd/man.go", "package cmd\n\nimport _ \"github.com/spf13/cobra/doc\"\n")
+		suggestions, err := Suggest(dir, config.Project{}, "foo")
+		require.NoError(t, err)
+		require.Equal(t, []string{"Shell completions", "Manpages"}, titles(suggestions))
+	})
+
+	t.Run("mango", func(t *testing.T) {
+		dir := t.TempDir()
+		writeFile(t, dir, "go.mod", gomod+"require github.com/muesli/mango-cobra v1.2.0\n")
+		suggestions, err := Suggest(dir, config.Project{}, "foo")
+		require.NoError(t, err)
+		require.Equal(t, []string{"Shell completions", "Manpages"}, titles(suggestions))
+	})
+
+	t.Run("already shipped", func(t *testing.T) {
+		dir := t.TempDir()
+		writeFile(t, dir, "go.mod", gomod+"require github.com/muesli/mango-cobra v1.2.0\n")
+		suggestions, err := Suggest(dir, config.Project{
+			Before: config.Before{
+				Hooks: config.Hooks{{Cmd: "./scripts/completions.sh"}},
+			},
+			Archives: []config.Archive{{
+				Files: []config.File{{Source: "manpages/*"}},
+			}},
+		}, "foo")
+		require.NoError(t, err)
+		require.Empty(t, suggestions)
+	})
+}
+
+func TestSuggestWindowsZip(t *testing.T) {
+	windows := []config.Build{{Goos: []string{"linux", "windows"}}}
+	for name, tc := range map[string]struct {
+		cfg      config.Project
+		expected []string
+	}{
+		"no windows": {
+			cfg: config.Project{
+				Builds: []config.Build{{Goos: []string{"linux"}}},
+			},
+		},
+		"default archive": {
+			cfg:      config.Project{Builds: windows},
+			expected: []string{"Zip archives for Windows"},
+		},
+		"windows target": {
+			cfg: config.Project{
+				Builds: []config.Build{{Targets: []string{"windows_amd64"}}},
+			},
+			expected: []string{"Zip archives for Windows"},
+		},
+		"override": {
+			cfg: config.Project{
+				Builds: windows,
+				Archives: []config.Archive{{
+					FormatOverrides: []config.FormatOverride{{Goos: "windows", Format: "zip"}},
+				}},
+			},
+		},
+		"zip": {
+			cfg: config.Project{
+				Builds:   windows,
+				Archives: []config.Archive{{Format: "zip"}},
+			},
+		},
+		"binary": {
+			cfg: config.Project{
+				Builds:   windows,
+				Archives: []config.Archive{{Format: "binary"}},
+			},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			suggestions, err := Suggest(t.TempDir(), tc.cfg, "foo")
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, titles(suggestions))
+		})
+	}
+}
+
+func titles(suggestions []Suggestion) []string {
+	var result []string
+	for _, s := range suggestions {
+		result = append(result, s.Title)
+	}
+	return result
+}
+
+func writeFile(tb testing.TB, dir, name, content string) {
+	tb.Helper()
+	path := filepath.Join(dir, name)
+	require.NoError(tb, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(tb, os.WriteFile(path, []byte(content), 0o644))
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/caarlos0/go-shellwords"
 	"github.com/goreleaser/goreleaser/internal/artifact"
 	"github.com/goreleaser/goreleaser/internal/extrafiles"
+	"github.com/goreleaser/goreleaser/internal/fallback"
 	"github.com/goreleaser/goreleaser/internal/gio"
 	"github.com/goreleaser/goreleaser/internal/logext"
 	"github.com/goreleaser/goreleaser/internal/pipe"
@@ -30,9 +31,20 @@ func Execute(ctx *context.Context, publishers []config.Publisher) error {
 		return pipe.ErrSkipPublishEnabled
 	}
 
+	targets := fallback.Targets{Kind: "publisher"}
 	for _, p := range publishers {
-		log.WithField("name", p.Name).Debug("executing custom publisher")
-		err := executePublisher(ctx, p)
+		targets.Names = append(targets.Names, p.Name)
+		targets.Fallbacks = append(targets.Fallbacks, p.Fallback)
+	}
+	if err := targets.Validate(); err != nil {
+		return err
+	}
+
+	for _, i := range targets.Primary() {
+		err := targets.Run(ctx, i, func(i int) error {
+			log.FromContext(ctx).WithField("name", publishers[i].Name).Debug("executing custom publisher")
+			return executePublisher(ctx, publishers[i])
+		})
 		if err != nil {
 			return err
 		}
@@ -42,7 +54,7 @@ func Execute(ctx *context.Context, publishers []config.Publisher) error {
 }
 
 func executePublisher(ctx *context.Context, publisher config.Publisher) error {
-	log.Debugf("filtering %d artifacts", len(ctx.Artifacts.List()))
+	log.FromContext(ctx).Debugf("filtering %d artifacts", len(ctx.Artifacts.List()))
 	artifacts := filterArtifacts(ctx.Artifacts, publisher)
 
 	extraFiles, err := extrafiles.Find(ctx, publisher.ExtraFiles)
@@ -58,7 +70,7 @@ func executePublisher(ctx *context.Context, publisher config.Publisher) error {
 		})
 	}
 
-	log.Debugf("will execute custom publisher with %d artifacts", len(artifacts))
+	log.FromContext(ctx).Debugf("will execute custom publisher with %d artifacts", len(artifacts))
 
 	g := semerrgroup.New(ctx.Parallelism)
 	for _, artifact := range artifacts {
@@ -69,14 +81,14 @@ func executePublisher(ctx *context.Context, publisher config.Publisher) error {
 				return err
 			}
 
-			return executeCommand(c, artifact)
+			return executeCommand(ctx, c, artifact)
 		})
 	}
 
 	return g.Wait()
 }
 
-func executeCommand(c *command, artifact *artifact.Artifact) error {
+func executeCommand(ctx *context.Context, c *command, artifact *artifact.Artifact) error {
 	log.WithField("args", c.Args).
 		WithField("env", c.Env).
 		WithField("artifact", artifact.Name).
@@ -117,8 +129,12 @@ func executeCommand(c *command, artifact *artifact.Artifact) error {
 func filterArtifacts(artifacts artifact.Artifacts, publisher config.Publisher) []*artifact.Artifact {
 	filters := []artifact.Filter{
 		artifact.ByType(artifact.UploadableArchive),
+		artifact.ByType(artifact.UploadableArchivePart),
 		artifact.ByType(artifact.UploadableFile),
 		artifact.ByType(artifact.LinuxPackage),
+		artifact.ByType(artifact.AppImage),
+		artifact.ByType(artifact.MSI),
+		artifact.ByType(artifact.DMG),
 		artifact.ByType(artifact.UploadableBinary),
 		artifact.ByType(artifact.DockerImage),
 		artifact.ByType(artifact.DockerManifest),
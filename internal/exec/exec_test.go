@@ -351,6 +351,53 @@ func TestExecute(t *testing.T) {
 			// stderr is sent to output via logger
 			fmt.Errorf(`publishing: %s failed: exit status 1: test error`, MockCmd),
 		},
+		{
+			"fallback",
+			[]config.Publisher{
+				{
+					Name:     "primary",
+					IDs:      []string{"debpkg"},
+					Fallback: "secondary",
+					Cmd:      MockCmd + " {{.ArtifactName}}",
+					Env: []string{
+						MarshalMockEnv(&MockData{
+							AnyOf: []MockCall{
+								{
+									ExpectedArgs: []string{"a.deb"},
+									ExpectedEnv:  osEnv(),
+									Stderr:       "test error",
+									ExitCode:     1,
+								},
+							},
+						}),
+					},
+				},
+				{
+					Name: "secondary",
+					IDs:  []string{"debpkg"},
+					Cmd:  MockCmd + " {{.ArtifactName}}",
+					Env: []string{
+						MarshalMockEnv(&MockData{
+							AnyOf: []MockCall{
+								{ExpectedArgs: []string{"a.deb"}, ExitCode: 0, ExpectedEnv: osEnv()},
+							},
+						}),
+					},
+				},
+			},
+			nil,
+		},
+		{
+			"invalid fallback",
+			[]config.Publisher{
+				{
+					Name:     "test",
+					Fallback: "nope",
+					Cmd:      MockCmd,
+				},
+			},
+			fmt.Errorf(`publisher: fallback "nope" of "test" does not exist`),
+		},
 	}
 
 	for i, tc := range testCases {
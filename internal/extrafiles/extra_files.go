@@ -22,7 +22,7 @@ func Find(ctx *context.Context, files []config.ExtraFile) (map[string]string, er
 			return result, fmt.Errorf("failed to apply template to glob %q: %w", extra.Glob, err)
 		}
 		if glob == "" {
-			log.Warn("ignoring empty glob")
+			log.FromContext(ctx).Warn("ignoring empty glob")
 			continue
 		}
 		files, err := fileglob.Glob(glob)
@@ -35,7 +35,7 @@ func Find(ctx *context.Context, files []config.ExtraFile) (map[string]string, er
 		for _, file := range files {
 			info, err := os.Stat(file)
 			if err == nil && info.IsDir() {
-				log.Debugf("ignoring directory %s", file)
+				log.FromContext(ctx).Debugf("ignoring directory %s", file)
 				continue
 			}
 			n, err := t.Apply(extra.NameTemplate)
@@ -47,7 +47,7 @@ func Find(ctx *context.Context, files []config.ExtraFile) (map[string]string, er
 				name = n
 			}
 			if old, ok := result[name]; ok {
-				log.Warnf("overriding %s with %s for name %s", old, file, name)
+				log.FromContext(ctx).Warnf("overriding %s with %s for name %s", old, file, name)
 			}
 			result[name] = file
 		}
@@ -0,0 +1,102 @@
+// Package fallback runs publish targets, switching to their configured
+// fallback targets when they fail.
+package fallback
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/pkg/context"
+)
+
+// nolint: gochecknoglobals
+var lock sync.Mutex
+
+// Targets are the publish targets of a kind, e.g. blobs, identified by their
+// names, along with the name of the fallback of each of them.
+type Targets struct {
+	Kind      string
+	Names     []string
+	Fallbacks []string
+}
+
+// Validate checks that all fallbacks are set on targets with a name and
+// reference another existing target, and that there are no cycles between
+// them.
+func (t Targets) Validate() error {
+	for i, fallback := range t.Fallbacks {
+		if fallback == "" {
+			continue
+		}
+		if t.Names[i] == "" {
+			return fmt.Errorf("%s: fallback %q is set on a %s without an id", t.Kind, fallback, t.Kind)
+		}
+		if t.index(fallback) == -1 {
+			return fmt.Errorf("%s: fallback %q of %q does not exist", t.Kind, fallback, t.Names[i])
+		}
+		seen := map[int]bool{i: true}
+		for j := t.index(fallback); j != -1; j = t.index(t.Fallbacks[j]) {
+			if seen[j] {
+				return fmt.Errorf("%s: fallback of %q leads to a cycle", t.Kind, t.Names[i])
+			}
+			seen[j] = true
+		}
+	}
+	return nil
+}
+
+// Primary returns the indexes of the targets that are not the fallback of
+// any other target, and thus should always be run.
+func (t Targets) Primary() []int {
+	fallbacks := map[string]bool{}
+	for _, fallback := range t.Fallbacks {
+		if fallback != "" {
+			fallbacks[fallback] = true
+		}
+	}
+	var result []int
+	for i, name := range t.Names {
+		if name == "" || !fallbacks[name] {
+			result = append(result, i)
+		}
+	}
+	return result
+}
+
+// Run runs the target at the given index, and its fallbacks, in order, until
+// one of them succeeds.
+// Each switch to a fallback is recorded in the context.
+func (t Targets) Run(ctx *context.Context, i int, run func(i int) error) error {
+	err := run(i)
+	for err != nil && t.Fallbacks[i] != "" {
+		next := t.index(t.Fallbacks[i])
+		log.FromContext(ctx).WithError(err).
+			WithField(t.Kind, t.Names[i]).
+			WithField("fallback", t.Names[next]).
+			Warn("publishing failed, using fallback")
+		lock.Lock()
+		ctx.PublishFallbacks = append(ctx.PublishFallbacks, context.PublishFallback{
+			Kind:     t.Kind,
+			Target:   t.Names[i],
+			Fallback: t.Names[next],
+			Err:      err.Error(),
+		})
+		lock.Unlock()
+		i = next
+		err = run(i)
+	}
+	return err
+}
+
+func (t Targets) index(name string) int {
+	if name == "" {
+		return -1
+	}
+	for i, n := range t.Names {
+		if n == name {
+			return i
+		}
+	}
+	return -1
+}
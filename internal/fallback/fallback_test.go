@@ -0,0 +1,92 @@
+package fallback
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	require.NoError(t, Targets{
+		Kind:      "blob",
+		Names:     []string{"a", "b", "c", ""},
+		Fallbacks: []string{"b", "c", "", ""},
+	}.Validate())
+
+	require.EqualError(t, Targets{
+		Kind:      "blob",
+		Names:     []string{"a", "b"},
+		Fallbacks: []string{"c", ""},
+	}.Validate(), `blob: fallback "c" of "a" does not exist`)
+
+	require.EqualError(t, Targets{
+		Kind:      "blob",
+		Names:     []string{"a", "b", "c"},
+		Fallbacks: []string{"b", "c", "b"},
+	}.Validate(), `blob: fallback of "a" leads to a cycle`)
+
+	require.EqualError(t, Targets{
+		Kind:      "blob",
+		Names:     []string{"a"},
+		Fallbacks: []string{"a"},
+	}.Validate(), `blob: fallback of "a" leads to a cycle`)
+
+	require.EqualError(t, Targets{
+		Kind:      "blob",
+		Names:     []string{"", "b"},
+		Fallbacks: []string{"b", ""},
+	}.Validate(), `blob: fallback "b" is set on a blob without an id`)
+}
+
+func TestPrimary(t *testing.T) {
+	require.Equal(t, []int{0, 3}, Targets{
+		Names:     []string{"a", "b", "c", ""},
+		Fallbacks: []string{"b", "c", "", ""},
+	}.Primary())
+}
+
+func TestRun(t *testing.T) {
+	targets := Targets{
+		Kind:      "blob",
+		Names:     []string{"a", "b", "c"},
+		Fallbacks: []string{"b", "c", ""},
+	}
+
+	t.Run("success", func(t *testing.T) {
+		ctx := context.New(config.Project{})
+		var ran []string
+		require.NoError(t, targets.Run(ctx, 0, func(i int) error {
+			ran = append(ran, targets.Names[i])
+			return nil
+		}))
+		require.Equal(t, []string{"a"}, ran)
+		require.Empty(t, ctx.PublishFallbacks)
+	})
+
+	t.Run("fallback", func(t *testing.T) {
+		ctx := context.New(config.Project{})
+		var ran []string
+		require.NoError(t, targets.Run(ctx, 0, func(i int) error {
+			ran = append(ran, targets.Names[i])
+			if i == 0 {
+				return fmt.Errorf("fake error")
+			}
+			return nil
+		}))
+		require.Equal(t, []string{"a", "b"}, ran)
+		require.Equal(t, []context.PublishFallback{
+			{Kind: "blob", Target: "a", Fallback: "b", Err: "fake error"},
+		}, ctx.PublishFallbacks)
+	})
+
+	t.Run("all fail", func(t *testing.T) {
+		ctx := context.New(config.Project{})
+		require.EqualError(t, targets.Run(ctx, 0, func(i int) error {
+			return fmt.Errorf("failed %s", targets.Names[i])
+		}), "failed c")
+		require.Len(t, ctx.PublishFallbacks, 2)
+	})
+}
@@ -0,0 +1,13 @@
+package gio
+
+import "os"
+
+// NormalizeMode returns 0755 if the given mode has any executable bit set,
+// and 0644 otherwise, so files get the same permissions regardless of the
+// umask they were created with.
+func NormalizeMode(mode os.FileMode) os.FileMode {
+	if mode&0o111 != 0 {
+		return 0o755
+	}
+	return 0o644
+}
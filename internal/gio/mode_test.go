@@ -0,0 +1,22 @@
+package gio
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeMode(t *testing.T) {
+	for mode, expected := range map[os.FileMode]os.FileMode{
+		0o600: 0o644,
+		0o664: 0o644,
+		0o644: 0o644,
+		0o700: 0o755,
+		0o775: 0o755,
+		0o744: 0o755,
+		0o610: 0o755,
+	} {
+		require.Equal(t, expected, NormalizeMode(mode), "%o", mode)
+	}
+}
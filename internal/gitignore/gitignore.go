@@ -0,0 +1,136 @@
+// Package gitignore matches paths against patterns with the same semantics as
+// the ones of a .gitignore file.
+package gitignore
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Matcher matches paths against a list of gitignore-style patterns.
+type Matcher struct {
+	patterns []pattern
+}
+
+type pattern struct {
+	re      *regexp.Regexp
+	negate  bool
+	dirOnly bool
+}
+
+// New compiles the given patterns, ignoring empty ones and comments.
+//
+// As in a .gitignore file, a pattern:
+//   - starting with `!` re-includes paths excluded by a previous pattern;
+//   - ending with `/` only matches directories;
+//   - with a `/` at the beginning or in the middle is relative to the root,
+//     otherwise it matches at any depth;
+//   - may use `*`, `?`, `[...]` and `**` to match any number of directories.
+func New(patterns ...string) (*Matcher, error) {
+	m := &Matcher{}
+	for _, s := range patterns {
+		s = strings.TrimSpace(s)
+		if s == "" || strings.HasPrefix(s, "#") {
+			continue
+		}
+		p := pattern{}
+		if strings.HasPrefix(s, "!") {
+			p.negate = true
+			s = s[1:]
+		}
+		if strings.HasSuffix(s, "/") {
+			p.dirOnly = true
+			s = strings.TrimRight(s, "/")
+		}
+		re, err := compile(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", s, err)
+		}
+		p.re = re
+		m.patterns = append(m.patterns, p)
+	}
+	return m, nil
+}
+
+// Match reports whether the given path is excluded, either by itself or
+// because one of its parent directories is.
+func (m *Matcher) Match(path string, isDir bool) bool {
+	path = strings.Trim(filepath.ToSlash(filepath.Clean(path)), "/")
+	parts := strings.Split(path, "/")
+	for i := 1; i < len(parts); i++ {
+		if m.match(strings.Join(parts[:i], "/"), true) {
+			return true
+		}
+	}
+	return m.match(path, isDir)
+}
+
+// match returns the result of the last pattern that matches the path.
+func (m *Matcher) match(path string, isDir bool) bool {
+	matched := false
+	for _, p := range m.patterns {
+		if p.dirOnly && !isDir {
+			continue
+		}
+		if p.re.MatchString(path) {
+			matched = !p.negate
+		}
+	}
+	return matched
+}
+
+// compile converts a gitignore pattern to a regular expression.
+func compile(s string) (*regexp.Regexp, error) {
+	var sb strings.Builder
+	if strings.Contains(s, "/") {
+		sb.WriteString("^")
+		s = strings.TrimPrefix(s, "/")
+	} else {
+		sb.WriteString("^(?:.*/)?")
+	}
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '*':
+			rest := s[i:]
+			atStart := i == 0 || s[i-1] == '/'
+			switch {
+			case atStart && strings.HasPrefix(rest, "**/"):
+				sb.WriteString("(?:.*/)?")
+				i += 2
+			case atStart && rest == "**":
+				sb.WriteString(".*")
+				i++
+			default:
+				sb.WriteString("[^/]*")
+				for i+1 < len(s) && s[i+1] == '*' {
+					i++
+				}
+			}
+		case '?':
+			sb.WriteString("[^/]")
+		case '[':
+			end := strings.IndexByte(s[i+1:], ']')
+			if end == -1 {
+				sb.WriteString(`\[`)
+				continue
+			}
+			class := s[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			sb.WriteString("[" + class + "]")
+			i += end + 1
+		case '\\':
+			if i+1 < len(s) {
+				i++
+			}
+			sb.WriteString(regexp.QuoteMeta(string(s[i])))
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	sb.WriteString("$")
+	return regexp.Compile(sb.String())
+}
@@ -0,0 +1,53 @@
+package gitignore
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatch(t *testing.T) {
+	for _, tt := range []struct {
+		patterns []string
+		path     string
+		isDir    bool
+		match    bool
+	}{
+		{[]string{"*.log"}, "foo.log", false, true},
+		{[]string{"*.log"}, "a/b/foo.log", false, true},
+		{[]string{"*.log"}, "foo.txt", false, false},
+		{[]string{"node_modules"}, "web/node_modules/react/index.js", false, true},
+		{[]string{"node_modules/"}, "node_modules", false, false},
+		{[]string{"node_modules/"}, "node_modules", true, true},
+		{[]string{"/testdata"}, "testdata/foo", false, true},
+		{[]string{"/testdata"}, "pkg/testdata/foo", false, false},
+		{[]string{"pkg/*.go"}, "pkg/foo.go", false, true},
+		{[]string{"pkg/*.go"}, "pkg/sub/foo.go", false, false},
+		{[]string{"pkg/**/*.go"}, "pkg/foo.go", false, true},
+		{[]string{"pkg/**/*.go"}, "pkg/a/b/foo.go", false, true},
+		{[]string{"**/testdata/**"}, "a/testdata/b/c", false, true},
+		{[]string{"**/testdata/**"}, "testdata", true, false},
+		{[]string{"docs/**"}, "docs/a/b.md", false, true},
+		{[]string{"fo?.txt"}, "foo.txt", false, true},
+		{[]string{"fo?.txt"}, "fo/.txt", false, false},
+		{[]string{"[!a]bc"}, "abc", false, false},
+		{[]string{"[!a]bc"}, "xbc", false, true},
+		{[]string{`\!important`}, "!important", false, true},
+		{[]string{"*.md", "!README.md"}, "README.md", false, false},
+		{[]string{"*.md", "!README.md"}, "CHANGELOG.md", false, true},
+		{[]string{"docs/", "!docs/README.md"}, "docs/README.md", false, true},
+		{[]string{"# comment", "", "  "}, "comment", false, false},
+		{[]string{"*.go"}, "./foo.go", false, true},
+	} {
+		t.Run(tt.path, func(t *testing.T) {
+			m, err := New(tt.patterns...)
+			require.NoError(t, err)
+			require.Equal(t, tt.match, m.Match(tt.path, tt.isDir), "%v", tt.patterns)
+		})
+	}
+}
+
+func TestInvalidPattern(t *testing.T) {
+	_, err := New("[z-a]")
+	require.Error(t, err)
+}
@@ -159,13 +159,17 @@ func Upload(ctx *context.Context, uploads []config.Upload, kind string, check Re
 			// TODO: should we add source archives here too?
 			filters = append(filters,
 				artifact.ByType(artifact.UploadableArchive),
+				artifact.ByType(artifact.UploadableArchivePart),
 				artifact.ByType(artifact.LinuxPackage),
+				artifact.ByType(artifact.AppImage),
+				artifact.ByType(artifact.MSI),
+				artifact.ByType(artifact.DMG),
 			)
 		case ModeBinary:
 			filters = append(filters, artifact.ByType(artifact.UploadableBinary))
 		default:
 			err := fmt.Errorf("%s: mode \"%s\" not supported", kind, v)
-			log.WithFields(log.Fields{
+			log.FromContext(ctx).WithFields(log.Fields{
 				kind:   upload.Name,
 				"mode": v,
 			}).Error(err.Error())
@@ -176,6 +180,11 @@ func Upload(ctx *context.Context, uploads []config.Upload, kind string, check Re
 		if len(upload.IDs) > 0 {
 			filter = artifact.And(filter, artifact.ByIDs(upload.IDs...))
 		}
+		selected, err := artifact.ByConfig(upload.Include, upload.Exclude, ctx.Config.Platforms)
+		if err != nil {
+			return fmt.Errorf("%s: %w", kind, err)
+		}
+		filter = artifact.And(filter, selected)
 		if err := uploadWithFilter(ctx, &upload, filter, kind, check); err != nil {
 			return err
 		}
@@ -186,7 +195,7 @@ func Upload(ctx *context.Context, uploads []config.Upload, kind string, check Re
 
 func uploadWithFilter(ctx *context.Context, upload *config.Upload, filter artifact.Filter, kind string, check ResponseChecker) error {
 	artifacts := ctx.Artifacts.Filter(filter).List()
-	log.Debugf("will upload %d artifacts", len(artifacts))
+	log.FromContext(ctx).Debugf("will upload %d artifacts", len(artifacts))
 	g := semerrgroup.New(ctx.Parallelism)
 	for _, artifact := range artifacts {
 		artifact := artifact
@@ -208,7 +217,7 @@ func uploadAsset(ctx *context.Context, upload *config.Upload, artifact *artifact
 	targetURL, err := resolveTargetTemplate(ctx, upload, artifact)
 	if err != nil {
 		msg := fmt.Sprintf("%s: error while building the target url", kind)
-		log.WithField("instance", upload.Name).WithError(err).Error(msg)
+		log.FromContext(ctx).WithField("instance", upload.Name).WithError(err).Error(msg)
 		return fmt.Errorf("%s: %w", msg, err)
 	}
 
@@ -227,7 +236,7 @@ func uploadAsset(ctx *context.Context, upload *config.Upload, artifact *artifact
 		}
 		targetURL += artifact.Name
 	}
-	log.Debugf("generated target url: %s", targetURL)
+	log.FromContext(ctx).Debugf("generated target url: %s", targetURL)
 
 	headers := map[string]string{}
 	if upload.CustomHeaders != nil {
@@ -235,7 +244,7 @@ func uploadAsset(ctx *context.Context, upload *config.Upload, artifact *artifact
 			resolvedValue, err := resolveHeaderTemplate(ctx, upload, artifact, value)
 			if err != nil {
 				msg := fmt.Sprintf("%s: failed to resolve custom_headers template", kind)
-				log.WithError(err).WithFields(log.Fields{
+				log.FromContext(ctx).WithError(err).WithFields(log.Fields{
 					"instance":     upload.Name,
 					"header_name":  name,
 					"header_value": value,
@@ -256,16 +265,16 @@ func uploadAsset(ctx *context.Context, upload *config.Upload, artifact *artifact
 	res, err := uploadAssetToServer(ctx, upload, targetURL, username, secret, headers, asset, check)
 	if err != nil {
 		msg := fmt.Sprintf("%s: upload failed", kind)
-		log.WithError(err).WithFields(log.Fields{
+		log.FromContext(ctx).WithError(err).WithFields(log.Fields{
 			"instance": upload.Name,
 		}).Error(msg)
 		return fmt.Errorf("%s: %w", msg, err)
 	}
 	if err := res.Body.Close(); err != nil {
-		log.WithError(err).Warn("failed to close response body")
+		log.FromContext(ctx).WithError(err).Warn("failed to close response body")
 	}
 
-	log.WithFields(log.Fields{
+	log.FromContext(ctx).WithFields(log.Fields{
 		"instance": upload.Name,
 		"mode":     upload.Mode,
 	}).Info("uploaded successful")
@@ -332,7 +341,7 @@ func executeHTTPRequest(ctx *context.Context, upload *config.Upload, req *h.Requ
 	if err != nil {
 		return nil, err
 	}
-	log.Debugf("executing request: %s %s (headers: %v)", req.Method, req.URL, req.Header)
+	log.FromContext(ctx).Debugf("executing request: %s %s (headers: %v)", req.Method, req.URL, req.Header)
 	resp, err := client.Do(req)
 	if err != nil {
 		// If we got an error, and the context has been canceled,
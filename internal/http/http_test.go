@@ -368,6 +368,45 @@ func TestUpload(t *testing.T) {
 				check{"/blah/2.1.0/a.tar", "u1", "x", content, map[string]string{}},
 			),
 		},
+		{
+			"archive_with_include_exclude", true, true, false, false,
+			func(s *httptest.Server) (*context.Context, config.Upload) {
+				return ctx, config.Upload{
+					Mode:         ModeArchive,
+					Name:         "a",
+					Target:       s.URL + "/{{.ProjectName}}/{{.Version}}/",
+					Username:     "u1",
+					TrustedCerts: cert(s),
+					Checksum:     true,
+					Include: config.ArtifactFilters{
+						Goos: []string{"linux"},
+					},
+					Exclude: config.ArtifactFilters{
+						Types: []string{"package"},
+					},
+				}
+			},
+			checks(
+				check{"/blah/2.1.0/a.tar", "u1", "x", content, map[string]string{}},
+				check{"/blah/2.1.0/a.sum", "u1", "x", content, map[string]string{}},
+			),
+		},
+		{
+			"archive_with_invalid_filter", true, true, true, true,
+			func(s *httptest.Server) (*context.Context, config.Upload) {
+				return ctx, config.Upload{
+					Mode:         ModeArchive,
+					Name:         "a",
+					Target:       s.URL + "/{{.ProjectName}}/{{.Version}}/",
+					Username:     "u1",
+					TrustedCerts: cert(s),
+					Exclude: config.ArtifactFilters{
+						Types: []string{"nope"},
+					},
+				}
+			},
+			checks(),
+		},
 		{
 			"binary", true, true, false, false,
 			func(s *httptest.Server) (*context.Context, config.Upload) {
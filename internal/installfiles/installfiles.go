@@ -0,0 +1,179 @@
+// Package installfiles resolves the shell completions and manpages of a
+// project, and where they should be installed by archives, linux packages and
+// homebrew formulas.
+package installfiles
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/goreleaser/fileglob"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/context"
+)
+
+// Kind of an install file.
+type Kind string
+
+const (
+	// Bash completion script.
+	Bash Kind = "bash"
+	// Zsh completion script.
+	Zsh Kind = "zsh"
+	// Fish completion script.
+	Fish Kind = "fish"
+	// Manpage is a man page, optionally gzipped.
+	Manpage Kind = "manpage"
+)
+
+const (
+	completionsDir = "completions"
+	manpagesDir    = "manpages"
+)
+
+// File is a completion script or manpage.
+type File struct {
+	Kind Kind
+	// Source is the path of the file on disk.
+	Source string
+	// Name is the name the file should be installed as, e.g. _myapp for a zsh
+	// completion script.
+	Name string
+	// Section of the manpage, e.g. 1.
+	Section string
+}
+
+// Find resolves the completions and manpages of the project.
+func Find(ctx *context.Context) ([]File, error) {
+	t := tmpl.New(ctx)
+	var result []File
+	for _, c := range []struct {
+		kind Kind
+		path string
+	}{
+		{Bash, ctx.Config.Completions.Bash},
+		{Zsh, ctx.Config.Completions.Zsh},
+		{Fish, ctx.Config.Completions.Fish},
+	} {
+		if c.path == "" {
+			continue
+		}
+		src, err := t.Apply(c.path)
+		if err != nil {
+			return nil, fmt.Errorf("completions: failed to apply template %s: %w", c.path, err)
+		}
+		result = append(result, File{
+			Kind:   c.kind,
+			Source: src,
+			Name:   completionName(c.kind, filepath.Base(src)),
+		})
+	}
+
+	for _, glob := range ctx.Config.Manpages {
+		pattern, err := t.Apply(glob)
+		if err != nil {
+			return nil, fmt.Errorf("manpages: failed to apply template %s: %w", glob, err)
+		}
+		matches, err := fileglob.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("manpages: globbing failed for pattern %s: %w", glob, err)
+		}
+		sort.Strings(matches)
+		for _, match := range matches {
+			name := filepath.Base(match)
+			section, err := manSection(name)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, File{
+				Kind:    Manpage,
+				Source:  match,
+				Name:    name,
+				Section: section,
+			})
+		}
+	}
+	return result, nil
+}
+
+// ArchivePath is the path of the file inside archives.
+func (f File) ArchivePath() string {
+	if f.Kind == Manpage {
+		return path.Join(manpagesDir, filepath.Base(f.Source))
+	}
+	return path.Join(completionsDir, filepath.Base(f.Source))
+}
+
+// PackagePath is the path the file is installed to by linux packages.
+func (f File) PackagePath() string {
+	switch f.Kind {
+	case Bash:
+		return path.Join("/usr/share/bash-completion/completions", f.Name)
+	case Zsh:
+		return path.Join("/usr/share/zsh/vendor-completions", f.Name)
+	case Fish:
+		return path.Join("/usr/share/fish/vendor_completions.d", f.Name)
+	default:
+		return path.Join("/usr/share/man", "man"+f.Section, f.Name)
+	}
+}
+
+// BrewInstall is the homebrew install statement of the file, installing it
+// from the archive.
+func (f File) BrewInstall() string {
+	switch f.Kind {
+	case Bash:
+		return fmt.Sprintf("bash_completion.install %q => %q", f.ArchivePath(), f.Name)
+	case Zsh:
+		return fmt.Sprintf("zsh_completion.install %q => %q", f.ArchivePath(), f.Name)
+	case Fish:
+		return fmt.Sprintf("fish_completion.install %q => %q", f.ArchivePath(), f.Name)
+	default:
+		return fmt.Sprintf("man%s.install %q", f.Section, f.ArchivePath())
+	}
+}
+
+// KegPath is the path of the file inside the keg of a homebrew bottle, which
+// is where the install statement of the formula would have put it.
+func (f File) KegPath() string {
+	switch f.Kind {
+	case Bash:
+		return path.Join("etc/bash_completion.d", f.Name)
+	case Zsh:
+		return path.Join("share/zsh/site-functions", f.Name)
+	case Fish:
+		return path.Join("share/fish/vendor_completions.d", f.Name)
+	default:
+		return path.Join("share/man", "man"+f.Section, f.Name)
+	}
+}
+
+// completionName is the name completion scripts are installed as, which is
+// what shells use to find the command they complete.
+func completionName(kind Kind, name string) string {
+	switch kind {
+	case Zsh:
+		name = strings.TrimSuffix(name, ".zsh")
+		if !strings.HasPrefix(name, "_") {
+			name = "_" + name
+		}
+		return name
+	case Fish:
+		return strings.TrimSuffix(name, ".fish") + ".fish"
+	default:
+		return strings.TrimSuffix(name, ".bash")
+	}
+}
+
+// manSection infers the section of a manpage from its name, e.g. 1 for
+// myapp.1.gz.
+func manSection(name string) (string, error) {
+	ext := strings.TrimPrefix(filepath.Ext(strings.TrimSuffix(name, ".gz")), ".")
+	if ext == "" || ext[0] < '1' || ext[0] > '9' {
+		return "", fmt.Errorf("manpages: could not infer the section of %s, its name should end with it, e.g. myapp.1 or myapp.1.gz", name)
+	}
+	return ext[:1], nil
+}
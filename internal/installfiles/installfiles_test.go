@@ -0,0 +1,107 @@
+package installfiles
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/testlib"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFind(t *testing.T) {
+	testlib.Mktmp(t)
+	require.NoError(t, os.Mkdir("manpages", 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join("manpages", "myapp.1.gz"), []byte("man"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join("manpages", "myapp-config.5"), []byte("man"), 0o644))
+
+	ctx := context.New(config.Project{
+		ProjectName: "myapp",
+		Completions: config.Completions{
+			Bash: "completions/{{ .ProjectName }}.bash",
+			Zsh:  "completions/{{ .ProjectName }}.zsh",
+			Fish: "completions/{{ .ProjectName }}",
+		},
+		Manpages: []string{"manpages/*"},
+	})
+	result, err := Find(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []File{
+		{Kind: Bash, Source: "completions/myapp.bash", Name: "myapp"},
+		{Kind: Zsh, Source: "completions/myapp.zsh", Name: "_myapp"},
+		{Kind: Fish, Source: "completions/myapp", Name: "myapp.fish"},
+		{Kind: Manpage, Source: "manpages/myapp-config.5", Name: "myapp-config.5", Section: "5"},
+		{Kind: Manpage, Source: "manpages/myapp.1.gz", Name: "myapp.1.gz", Section: "1"},
+	}, result)
+}
+
+func TestFindEmpty(t *testing.T) {
+	result, err := Find(context.New(config.Project{}))
+	require.NoError(t, err)
+	require.Empty(t, result)
+}
+
+func TestFindInvalidManpage(t *testing.T) {
+	testlib.Mktmp(t)
+	require.NoError(t, os.WriteFile("myapp.gz", []byte("man"), 0o644))
+
+	_, err := Find(context.New(config.Project{
+		Manpages: []string{"*.gz"},
+	}))
+	require.EqualError(t, err, "manpages: could not infer the section of myapp.gz, its name should end with it, e.g. myapp.1 or myapp.1.gz")
+}
+
+func TestFindInvalidTemplate(t *testing.T) {
+	_, err := Find(context.New(config.Project{
+		Completions: config.Completions{Bash: "{{ .Nope }"},
+	}))
+	require.Error(t, err)
+}
+
+func TestPaths(t *testing.T) {
+	for _, tt := range []struct {
+		file    File
+		archive string
+		pkg     string
+		brew    string
+		keg     string
+	}{
+		{
+			file:    File{Kind: Bash, Source: "dist/myapp.bash", Name: "myapp"},
+			archive: "completions/myapp.bash",
+			pkg:     "/usr/share/bash-completion/completions/myapp",
+			brew:    `bash_completion.install "completions/myapp.bash" => "myapp"`,
+			keg:     "etc/bash_completion.d/myapp",
+		},
+		{
+			file:    File{Kind: Zsh, Source: "dist/myapp.zsh", Name: "_myapp"},
+			archive: "completions/myapp.zsh",
+			pkg:     "/usr/share/zsh/vendor-completions/_myapp",
+			brew:    `zsh_completion.install "completions/myapp.zsh" => "_myapp"`,
+			keg:     "share/zsh/site-functions/_myapp",
+		},
+		{
+			file:    File{Kind: Fish, Source: "dist/myapp.fish", Name: "myapp.fish"},
+			archive: "completions/myapp.fish",
+			pkg:     "/usr/share/fish/vendor_completions.d/myapp.fish",
+			brew:    `fish_completion.install "completions/myapp.fish" => "myapp.fish"`,
+			keg:     "share/fish/vendor_completions.d/myapp.fish",
+		},
+		{
+			file:    File{Kind: Manpage, Source: "dist/myapp.1.gz", Name: "myapp.1.gz", Section: "1"},
+			archive: "manpages/myapp.1.gz",
+			pkg:     "/usr/share/man/man1/myapp.1.gz",
+			brew:    `man1.install "manpages/myapp.1.gz"`,
+			keg:     "share/man/man1/myapp.1.gz",
+		},
+	} {
+		t.Run(string(tt.file.Kind), func(t *testing.T) {
+			require.Equal(t, tt.archive, tt.file.ArchivePath())
+			require.Equal(t, tt.pkg, tt.file.PackagePath())
+			require.Equal(t, tt.brew, tt.file.BrewInstall())
+			require.Equal(t, tt.keg, tt.file.KegPath())
+		})
+	}
+}
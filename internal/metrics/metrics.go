@@ -0,0 +1,260 @@
+// Package metrics records metrics about a release run and pushes them to a
+// Prometheus Pushgateway.
+package metrics
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/middleware"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/context"
+)
+
+const defaultJob = "goreleaser"
+
+type pipeRun struct {
+	name     string
+	duration time.Duration
+	failed   bool
+}
+
+// Recorder records the duration and outcome of each pipe of a release.
+type Recorder struct {
+	start time.Time
+	pipes []pipeRun
+}
+
+// New creates a new recorder, starting the release clock.
+func New() *Recorder {
+	return &Recorder{start: time.Now()}
+}
+
+// Measure wraps the given action, recording its duration and whether it
+// failed under the given pipe name.
+func (r *Recorder) Measure(name string, next middleware.Action) middleware.Action {
+	return func(ctx *context.Context) error {
+		start := time.Now()
+		err := next(ctx)
+		r.record(name, time.Since(start), err != nil)
+		return err
+	}
+}
+
+// record adds a run of the given pipe, pipes sharing a name are reported as
+// a single series, with their durations added up.
+func (r *Recorder) record(name string, duration time.Duration, failed bool) {
+	for i := range r.pipes {
+		if r.pipes[i].name == name {
+			r.pipes[i].duration += duration
+			r.pipes[i].failed = r.pipes[i].failed || failed
+			return
+		}
+	}
+	r.pipes = append(r.pipes, pipeRun{
+		name:     name,
+		duration: duration,
+		failed:   failed,
+	})
+}
+
+// Push pushes the recorded metrics to the configured Pushgateway, if any.
+// The given error is the outcome of the whole release.
+// Failing to push only logs a warning, so it never fails the release.
+func (r *Recorder) Push(ctx *context.Context, releaseErr error) {
+	if ctx.Config.Metrics.Pushgateway == "" {
+		return
+	}
+	if err := r.push(ctx, releaseErr); err != nil {
+		log.FromContext(ctx).WithError(err).Warn("failed to push metrics")
+	}
+}
+
+func (r *Recorder) push(ctx *context.Context, releaseErr error) error {
+	u, err := r.url(ctx)
+	if err != nil {
+		return err
+	}
+	log.FromContext(ctx).WithField("url", u).Debug("pushing metrics")
+
+	req, err := http.NewRequest(http.MethodPut, u, bytes.NewReader(r.render(ctx, releaseErr)))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/plain; version=0.0.4")
+
+	cli := &http.Client{Timeout: 30 * time.Second}
+	resp, err := cli.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status pushing metrics: %s", resp.Status)
+	}
+	return nil
+}
+
+// url builds the pushgateway URL, grouping the metrics by job and the
+// configured labels.
+func (r *Recorder) url(ctx *context.Context) (string, error) {
+	cfg := ctx.Config.Metrics
+	t := tmpl.New(ctx)
+	base, err := t.Apply(cfg.Pushgateway)
+	if err != nil {
+		return "", fmt.Errorf("metrics: failed to template pushgateway: %w", err)
+	}
+	job := cfg.Job
+	if job == "" {
+		job = defaultJob
+	}
+	job, err = t.Apply(job)
+	if err != nil {
+		return "", fmt.Errorf("metrics: failed to template job: %w", err)
+	}
+
+	path := append([]string{strings.TrimSuffix(base, "/"), "metrics"}, label("job", job)...)
+	for _, k := range sortedKeys(cfg.Labels) {
+		v, err := t.Apply(cfg.Labels[k])
+		if err != nil {
+			return "", fmt.Errorf("metrics: failed to template label %s: %w", k, err)
+		}
+		path = append(path, label(k, v)...)
+	}
+	return strings.Join(path, "/"), nil
+}
+
+// label returns the path segments of the given grouping label.
+// Values the Pushgateway can't read from the path, the empty ones and the
+// ones containing slashes, are base64url encoded.
+func label(name, value string) []string {
+	switch {
+	case value == "":
+		return []string{url.PathEscape(name) + "@base64", "="}
+	case strings.Contains(value, "/"):
+		return []string{url.PathEscape(name) + "@base64", base64.RawURLEncoding.EncodeToString([]byte(value))}
+	default:
+		return []string{url.PathEscape(name), url.PathEscape(value)}
+	}
+}
+
+// render the metrics in the prometheus text exposition format.
+func (r *Recorder) render(ctx *context.Context, releaseErr error) []byte {
+	var b bytes.Buffer
+	gauge := func(name, help string) {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s gauge\n", name, help, name)
+	}
+
+	gauge("goreleaser_release_duration_seconds", "Duration of the release.")
+	fmt.Fprintf(&b, "goreleaser_release_duration_seconds %g\n", time.Since(r.start).Seconds())
+
+	gauge("goreleaser_release_success", "Whether the release succeeded.")
+	fmt.Fprintf(&b, "goreleaser_release_success %d\n", boolToInt(releaseErr == nil))
+
+	gauge("goreleaser_release_timestamp_seconds", "Time the release finished.")
+	fmt.Fprintf(&b, "goreleaser_release_timestamp_seconds %d\n", time.Now().Unix())
+
+	counts := map[string]int{}
+	var types []string
+	for _, a := range ctx.Artifacts.List() {
+		if counts[a.Type.String()] == 0 {
+			types = append(types, a.Type.String())
+		}
+		counts[a.Type.String()]++
+	}
+	sort.Strings(types)
+	gauge("goreleaser_artifacts", "Number of artifacts created, by type.")
+	for _, t := range types {
+		fmt.Fprintf(&b, "goreleaser_artifacts{type=%q} %d\n", t, counts[t])
+	}
+
+	gauge("goreleaser_uploaded_bytes", "Size of the artifacts uploaded to the release.")
+	fmt.Fprintf(&b, "goreleaser_uploaded_bytes %d\n", uploadedBytes(ctx))
+
+	gauge("goreleaser_pipe_duration_seconds", "Duration of each pipe.")
+	for _, p := range r.pipes {
+		fmt.Fprintf(&b, "goreleaser_pipe_duration_seconds{pipe=%q} %g\n", p.name, p.duration.Seconds())
+	}
+
+	gauge("goreleaser_pipe_failed", "Whether each pipe failed.")
+	for _, p := range r.pipes {
+		fmt.Fprintf(&b, "goreleaser_pipe_failed{pipe=%q} %d\n", p.name, boolToInt(p.failed))
+	}
+
+	gauge("goreleaser_publish_fallback", "Publish targets that failed and were replaced by their fallback.")
+	for _, f := range ctx.PublishFallbacks {
+		fmt.Fprintf(&b, "goreleaser_publish_fallback{kind=%q,target=%q,fallback=%q} 1\n", f.Kind, f.Target, f.Fallback)
+	}
+
+	gauge("goreleaser_docker_platform_build_duration_seconds", "Duration of the build of each platform of multi-platform docker images.")
+	for _, d := range ctx.DockerPlatformBuilds {
+		fmt.Fprintf(&b, "goreleaser_docker_platform_build_duration_seconds{image=%q,platform=%q} %g\n", d.Image, d.Platform, d.Duration.Seconds())
+	}
+
+	gauge("goreleaser_docker_platform_build_attempts", "Attempts needed to build each platform of multi-platform docker images.")
+	for _, d := range ctx.DockerPlatformBuilds {
+		fmt.Fprintf(&b, "goreleaser_docker_platform_build_attempts{image=%q,platform=%q} %d\n", d.Image, d.Platform, d.Attempts)
+	}
+
+	gauge("goreleaser_unchanged_files", "Files that were not committed because they were already up to date.")
+	fmt.Fprintf(&b, "goreleaser_unchanged_files %d\n", len(ctx.UnchangedFiles))
+	return b.Bytes()
+}
+
+// uploadedBytes sums the size of the artifacts that are uploaded to the
+// release.
+func uploadedBytes(ctx *context.Context) int64 {
+	if ctx.SkipPublish {
+		return 0
+	}
+	var total int64
+	for _, a := range ctx.Artifacts.Filter(artifact.Or(
+		artifact.ByType(artifact.UploadableArchive),
+		artifact.ByType(artifact.UploadableArchivePart),
+		artifact.ByType(artifact.UploadableBinary),
+		artifact.ByType(artifact.UploadableSourceArchive),
+		artifact.ByType(artifact.UploadableFile),
+		artifact.ByType(artifact.Checksum),
+		artifact.ByType(artifact.Signature),
+		artifact.ByType(artifact.Certificate),
+		artifact.ByType(artifact.LinuxPackage),
+		artifact.ByType(artifact.AppImage),
+		artifact.ByType(artifact.MSI),
+		artifact.ByType(artifact.DMG),
+		artifact.ByType(artifact.SBOM),
+		artifact.ByType(artifact.Provenance),
+		artifact.ByType(artifact.Attestation),
+	)).List() {
+		info, err := os.Stat(a.Path)
+		if err != nil {
+			continue
+		}
+		total += info.Size()
+	}
+	return total
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,162 @@
+package metrics
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPush(t *testing.T) {
+	var path, body, contentType string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPut, r.Method)
+		bts, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		path = r.URL.Path
+		body = string(bts)
+		contentType = r.Header.Get("Content-Type")
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(srv.Close)
+
+	archive := filepath.Join(t.TempDir(), "foo.tar.gz")
+	require.NoError(t, os.WriteFile(archive, []byte("fake archive"), 0o644))
+
+	ctx := context.New(config.Project{
+		ProjectName: "foo",
+		Metrics: config.Metrics{
+			Pushgateway: srv.URL + "/",
+			Labels: map[string]string{
+				"project": "{{ .ProjectName }}",
+				"team":    "platform",
+			},
+		},
+	})
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name: "foo.tar.gz",
+		Path: archive,
+		Type: artifact.UploadableArchive,
+	})
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name: "foo",
+		Path: "dist/foo",
+		Type: artifact.Binary,
+	})
+	ctx.PublishFallbacks = []context.PublishFallback{
+		{Kind: "blob", Target: "primary", Fallback: "secondary", Err: "fake"},
+	}
+	ctx.UnchangedFiles = []context.UnchangedFile{
+		{Repo: "foo/homebrew-tap", Path: "Formula/foo.rb"},
+	}
+	ctx.DockerPlatformBuilds = []context.DockerPlatformBuild{
+		{Image: "ghcr.io/foo/bar:v1.0.0", Platform: "linux/arm64", Duration: 90 * time.Second, Attempts: 2},
+	}
+
+	recorder := New()
+	require.NoError(t, recorder.Measure("first", func(ctx *context.Context) error {
+		return nil
+	})(ctx))
+	require.Error(t, recorder.Measure("second", func(ctx *context.Context) error {
+		return errors.New("fake")
+	})(ctx))
+	require.NoError(t, recorder.Measure("second", func(ctx *context.Context) error {
+		return nil
+	})(ctx))
+	recorder.Push(ctx, errors.New("fake"))
+
+	require.Equal(t, "/metrics/job/goreleaser/project/foo/team/platform", path)
+	require.Equal(t, "text/plain; version=0.0.4", contentType)
+	require.Contains(t, body, "# TYPE goreleaser_release_duration_seconds gauge\n")
+	require.Contains(t, body, "goreleaser_release_success 0\n")
+	require.Contains(t, body, `goreleaser_artifacts{type="Archive"} 1`+"\n")
+	require.Contains(t, body, `goreleaser_artifacts{type="Binary"} 1`+"\n")
+	require.Contains(t, body, "goreleaser_uploaded_bytes 12\n")
+	require.Contains(t, body, `goreleaser_pipe_duration_seconds{pipe="first"} `)
+	require.Contains(t, body, `goreleaser_pipe_duration_seconds{pipe="second"} `)
+	require.Contains(t, body, `goreleaser_pipe_failed{pipe="first"} 0`+"\n")
+	require.Contains(t, body, `goreleaser_pipe_failed{pipe="second"} 1`+"\n")
+	require.Equal(t, 1, strings.Count(body, `goreleaser_pipe_duration_seconds{pipe="second"} `))
+	require.Equal(t, 1, strings.Count(body, `goreleaser_pipe_failed{pipe="second"} `))
+	require.Contains(t, body, `goreleaser_publish_fallback{kind="blob",target="primary",fallback="secondary"} 1`+"\n")
+	require.Contains(t, body, "goreleaser_unchanged_files 1\n")
+	require.Contains(t, body, `goreleaser_docker_platform_build_duration_seconds{image="ghcr.io/foo/bar:v1.0.0",platform="linux/arm64"} 90`+"\n")
+	require.Contains(t, body, `goreleaser_docker_platform_build_attempts{image="ghcr.io/foo/bar:v1.0.0",platform="linux/arm64"} 2`+"\n")
+}
+
+func TestURL(t *testing.T) {
+	ctx := context.New(config.Project{
+		Metrics: config.Metrics{
+			Pushgateway: "http://localhost:9091",
+			Job:         "release",
+			Labels: map[string]string{
+				"branch":  "feat/metrics",
+				"empty":   "",
+				"project": "foo bar",
+			},
+		},
+	})
+	u, err := New().url(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "http://localhost:9091/metrics/job/release/branch@base64/ZmVhdC9tZXRyaWNz/empty@base64/=/project/foo%20bar", u)
+
+	ctx.Config.Metrics.Job = "org/release"
+	ctx.Config.Metrics.Labels = nil
+	u, err = New().url(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "http://localhost:9091/metrics/job@base64/b3JnL3JlbGVhc2U", u)
+}
+
+func TestPushDisabled(t *testing.T) {
+	New().Push(context.New(config.Project{}), nil)
+}
+
+func TestPushSkipPublish(t *testing.T) {
+	ctx := context.New(config.Project{})
+	ctx.SkipPublish = true
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name: "foo.tar.gz",
+		Path: "metrics.go",
+		Type: artifact.UploadableArchive,
+	})
+	require.Contains(t, string(New().render(ctx, nil)), "goreleaser_uploaded_bytes 0\n")
+}
+
+func TestPushErrors(t *testing.T) {
+	t.Run("bad status", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+		}))
+		t.Cleanup(srv.Close)
+		err := New().push(context.New(config.Project{
+			Metrics: config.Metrics{
+				Pushgateway: srv.URL,
+				Job:         "release",
+			},
+		}), nil)
+		require.EqualError(t, err, "unexpected status pushing metrics: 400 Bad Request")
+	})
+
+	for name, cfg := range map[string]config.Metrics{
+		"pushgateway": {Pushgateway: "{{ .Nope }"},
+		"job":         {Pushgateway: "http://localhost", Job: "{{ .Nope }"},
+		"label":       {Pushgateway: "http://localhost", Labels: map[string]string{"a": "{{ .Nope }"}},
+	} {
+		cfg := cfg
+		t.Run("invalid "+name+" template", func(t *testing.T) {
+			_, err := New().url(context.New(config.Project{Metrics: cfg}))
+			require.Error(t, err)
+			require.Contains(t, err.Error(), "metrics: failed to template "+name)
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/goreleaser/goreleaser/internal/pipe"
+	"github.com/goreleaser/goreleaser/pkg/config"
 	"github.com/goreleaser/goreleaser/pkg/context"
 	"github.com/stretchr/testify/require"
 )
@@ -13,18 +14,18 @@ func TestError(t *testing.T) {
 	t.Run("no errors", func(t *testing.T) {
 		require.NoError(t, Handle(func(ctx *context.Context) error {
 			return nil
-		})(nil))
+		})(context.New(config.Project{})))
 	})
 
 	t.Run("pipe skipped", func(t *testing.T) {
 		require.NoError(t, Handle(func(ctx *context.Context) error {
 			return pipe.ErrSkipValidateEnabled
-		})(nil))
+		})(context.New(config.Project{})))
 	})
 
 	t.Run("some err", func(t *testing.T) {
 		require.Error(t, Handle(func(ctx *context.Context) error {
 			return fmt.Errorf("pipe errored")
-		})(nil))
+		})(context.New(config.Project{})))
 	})
 }
@@ -23,13 +23,20 @@ const ExtraPadding = DefaultInitialPadding * 2
 // action logs in padding+default padding.
 // The default padding in the log library is 3.
 // The middleware always resets to the default padding.
+// Only the cli handler is padded, other handlers, like the ones of the loggers
+// set on the context by programs embedding goreleaser, are left untouched.
 func Log(title string, next middleware.Action, padding Padding) middleware.Action {
 	return func(ctx *context.Context) error {
+		logger := log.FromContext(ctx)
+		if l, ok := logger.(*log.Logger); !ok || l.Handler != cli.Default {
+			logger.Infof(color.New(color.Bold).Sprint(title))
+			return next(ctx)
+		}
 		defer func() {
 			cli.Default.Padding = int(DefaultInitialPadding)
 		}()
 		cli.Default.Padding = int(padding)
-		log.Infof(color.New(color.Bold).Sprint(title))
+		logger.Infof(color.New(color.Bold).Sprint(title))
 		cli.Default.Padding = int(padding + DefaultInitialPadding)
 		return next(ctx)
 	}
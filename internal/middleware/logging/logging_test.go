@@ -3,6 +3,7 @@ package logging
 import (
 	"testing"
 
+	"github.com/goreleaser/goreleaser/pkg/config"
 	"github.com/goreleaser/goreleaser/pkg/context"
 	"github.com/stretchr/testify/require"
 )
@@ -10,5 +11,5 @@ import (
 func TestLogging(t *testing.T) {
 	require.NoError(t, Log("foo", func(ctx *context.Context) error {
 		return nil
-	}, DefaultInitialPadding)(nil))
+	}, DefaultInitialPadding)(context.New(config.Project{})))
 }
@@ -22,7 +22,7 @@ func Maybe(skipper interface{}, next middleware.Action) middleware.Action {
 	if skipper, ok := skipper.(Skipper); ok {
 		return func(ctx *context.Context) error {
 			if skipper.Skip(ctx) {
-				log.Debugf("skipped %s", skipper.String())
+				log.FromContext(ctx).Debugf("skipped %s", skipper.String())
 				return nil
 			}
 			return next(ctx)
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/goreleaser/goreleaser/pkg/config"
 	"github.com/goreleaser/goreleaser/pkg/context"
 	"github.com/stretchr/testify/require"
 )
@@ -15,15 +16,15 @@ func TestSkip(t *testing.T) {
 	}
 
 	t.Run("not a skipper", func(t *testing.T) {
-		require.EqualError(t, Maybe(action, action)(nil), fakeErr.Error())
+		require.EqualError(t, Maybe(action, action)(context.New(config.Project{})), fakeErr.Error())
 	})
 
 	t.Run("skip", func(t *testing.T) {
-		require.NoError(t, Maybe(skipper{true}, action)(nil))
+		require.NoError(t, Maybe(skipper{true}, action)(context.New(config.Project{})))
 	})
 
 	t.Run("do not skip", func(t *testing.T) {
-		require.EqualError(t, Maybe(skipper{false}, action)(nil), fakeErr.Error())
+		require.EqualError(t, Maybe(skipper{false}, action)(context.New(config.Project{})), fakeErr.Error())
 	})
 }
 
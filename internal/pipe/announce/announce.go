@@ -9,6 +9,7 @@ import (
 	"github.com/goreleaser/goreleaser/internal/middleware/logging"
 	"github.com/goreleaser/goreleaser/internal/middleware/skip"
 	"github.com/goreleaser/goreleaser/internal/pipe/discord"
+	"github.com/goreleaser/goreleaser/internal/pipe/discussions"
 	"github.com/goreleaser/goreleaser/internal/pipe/linkedin"
 	"github.com/goreleaser/goreleaser/internal/pipe/mattermost"
 	"github.com/goreleaser/goreleaser/internal/pipe/reddit"
@@ -32,6 +33,7 @@ type Announcer interface {
 var announcers = []Announcer{
 	// XXX: keep asc sorting
 	discord.Pipe{},
+	discussions.Pipe{},
 	linkedin.Pipe{},
 	mattermost.Pipe{},
 	reddit.Pipe{},
@@ -57,10 +59,10 @@ func (Pipe) Skip(ctx *context.Context) bool {
 	}
 	skip, err := tmpl.New(ctx).Apply(ctx.Config.Announce.Skip)
 	if err != nil {
-		log.Error("invalid announce.skip template, will skip the announcing step")
+		log.FromContext(ctx).Error("invalid announce.skip template, will skip the announcing step")
 		return true
 	}
-	log.Debugf("announce.skip evaluated from %q to %q", ctx.Config.Announce.Skip, skip)
+	log.FromContext(ctx).Debugf("announce.skip evaluated from %q to %q", ctx.Config.Announce.Skip, skip)
 	return skip == "true"
 }
 
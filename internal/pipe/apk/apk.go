@@ -0,0 +1,213 @@
+// Package apk implements the Pipe interface, publishing the apk packages to
+// signed Alpine repositories.
+package apk
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/ids"
+	"github.com/goreleaser/goreleaser/internal/pipe"
+	"github.com/goreleaser/goreleaser/internal/pkgrepo"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+)
+
+// Pipe for Alpine repositories.
+type Pipe struct{}
+
+func (Pipe) String() string                 { return "apk repositories" }
+func (Pipe) Skip(ctx *context.Context) bool { return len(ctx.Config.APKRepositories) == 0 }
+
+// Default sets the pipe defaults.
+func (Pipe) Default(ctx *context.Context) error {
+	ids := ids.New("apk_repositories")
+	for i := range ctx.Config.APKRepositories {
+		repo := &ctx.Config.APKRepositories[i]
+		if repo.ID == "" {
+			repo.ID = "default"
+		}
+		if repo.Name == "" {
+			repo.Name = ctx.Config.ProjectName
+		}
+		if repo.Description == "" {
+			repo.Description = repo.Name
+		}
+		if repo.KeyFile == "" {
+			return fmt.Errorf("apk_repositories %s: key_file is required", repo.ID)
+		}
+		if repo.Blob.Bucket == "" {
+			return fmt.Errorf("apk_repositories %s: blob is required", repo.ID)
+		}
+		switch repo.Blob.Provider {
+		case "s3", "gs", "azblob":
+		default:
+			return fmt.Errorf("apk_repositories %s: invalid blob provider %q, valid options are s3, gs and azblob", repo.ID, repo.Blob.Provider)
+		}
+		ids.Inc(repo.ID)
+	}
+	return ids.Validate()
+}
+
+// Publish the repositories.
+func (Pipe) Publish(ctx *context.Context) error {
+	skips := pipe.SkipMemento{}
+	for _, repo := range ctx.Config.APKRepositories {
+		err := doPublish(ctx, repo)
+		if err != nil && pipe.IsSkip(err) {
+			skips.Remember(err)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return skips.Evaluate()
+}
+
+func doPublish(ctx *context.Context, cfg config.APKRepository) error {
+	if strings.TrimSpace(cfg.SkipUpload) == "true" {
+		return pipe.Skip("apk_repositories.skip_upload is set")
+	}
+	if strings.TrimSpace(cfg.SkipUpload) == "auto" && ctx.Semver.Prerelease != "" {
+		return pipe.Skip("prerelease detected with 'auto' upload, skipping apk repository publish")
+	}
+
+	filters := []artifact.Filter{
+		artifact.ByType(artifact.LinuxPackage),
+		artifact.ByFormats("apk"),
+	}
+	if len(cfg.IDs) > 0 {
+		filters = append(filters, artifact.ByIDs(cfg.IDs...))
+	}
+	apks := ctx.Artifacts.Filter(artifact.And(filters...)).List()
+	if len(apks) == 0 {
+		return pipe.Skip("no apk packages found")
+	}
+
+	keyFile, err := tmpl.New(ctx).Apply(cfg.KeyFile)
+	if err != nil {
+		return err
+	}
+	key, err := loadKey(keyFile)
+	if err != nil {
+		return err
+	}
+	pub, err := publicKey(key)
+	if err != nil {
+		return err
+	}
+	keyName, err := tmpl.New(ctx).Apply(cfg.KeyName)
+	if err != nil {
+		return err
+	}
+	if keyName == "" {
+		keyName = defaultKeyName(keyFile)
+	}
+
+	st, err := pkgrepo.Open(ctx, cfg.Blob, cfg.Folder)
+	if err != nil {
+		return err
+	}
+	defer st.Close()
+
+	log := log.FromContext(ctx).WithField("repository", cfg.ID)
+	entries := map[string][]entry{}
+	for _, apk := range apks {
+		info, err := readAPK(apk.Path)
+		if err != nil {
+			return err
+		}
+		// apk downloads the packages from <arch>/<name>-<version>.apk.
+		arch := info.field("arch")
+		location := path.Join(arch, info.field("pkgname")+"-"+info.field("pkgver")+".apk")
+		data, err := os.ReadFile(apk.Path)
+		if err != nil {
+			return err
+		}
+		log.WithField("package", apk.Name).Info("adding")
+		if err := st.Write(ctx, location, data); err != nil {
+			return fmt.Errorf("failed to write %s: %w", location, err)
+		}
+		entries[arch] = append(entries[arch], newEntry(info))
+	}
+
+	archs := make([]string, 0, len(entries))
+	for arch := range entries {
+		archs = append(archs, arch)
+	}
+	sort.Strings(archs)
+	for _, arch := range archs {
+		// the packages already in the index are kept, so older versions can
+		// still be installed.
+		idx, err := readIndex(ctx, st, arch)
+		if err != nil {
+			return err
+		}
+		for _, e := range entries[arch] {
+			idx.add(e)
+		}
+		bts, err := idx.render(cfg.Description, key, keyName)
+		if err != nil {
+			return err
+		}
+		location := path.Join(arch, indexName)
+		if err := st.Write(ctx, location, bts); err != nil {
+			return fmt.Errorf("failed to write %s: %w", location, err)
+		}
+	}
+
+	if err := st.Write(ctx, keyName, pub); err != nil {
+		return fmt.Errorf("failed to write %s: %w", keyName, err)
+	}
+	logInstructions(ctx, cfg, keyName)
+	return nil
+}
+
+// readIndex reads the APKINDEX of the given architecture, if it exists.
+func readIndex(ctx *context.Context, st *pkgrepo.Store, arch string) (*index, error) {
+	location := path.Join(arch, indexName)
+	bts, err := st.Read(ctx, location)
+	if errors.Is(err, os.ErrNotExist) {
+		return parseIndex(nil)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", location, err)
+	}
+	idx, err := parseIndex(bts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", location, err)
+	}
+	return idx, nil
+}
+
+// defaultKeyName returns the name of the public key of the given private
+// key file, following the abuild convention of <name>.rsa and
+// <name>.rsa.pub.
+func defaultKeyName(keyFile string) string {
+	name := filepath.Base(keyFile)
+	name = strings.TrimSuffix(name, ".pem")
+	name = strings.TrimSuffix(name, ".rsa")
+	return name + ".rsa.pub"
+}
+
+// logInstructions logs how to install the packages from the repository, if
+// its url is known.
+func logInstructions(ctx *context.Context, cfg config.APKRepository, keyName string) {
+	url := pkgrepo.InstallURL(ctx, cfg.URL)
+	if url == "" {
+		return
+	}
+	log.FromContext(ctx).Info("install with:")
+	log.FromContext(ctx).Infof("sudo wget -O /etc/apk/keys/%s %s/%s", keyName, url, keyName)
+	log.FromContext(ctx).Infof("echo %s | sudo tee -a /etc/apk/repositories", url)
+	log.FromContext(ctx).Info("sudo apk add " + cfg.Name)
+}
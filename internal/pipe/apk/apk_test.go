@@ -0,0 +1,227 @@
+package apk
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/pipe"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+	_ "gocloud.dev/blob/fileblob"
+)
+
+func TestDescription(t *testing.T) {
+	require.NotEmpty(t, Pipe{}.String())
+}
+
+func TestSkip(t *testing.T) {
+	require.True(t, Pipe{}.Skip(context.New(config.Project{})))
+	require.False(t, Pipe{}.Skip(context.New(config.Project{
+		APKRepositories: []config.APKRepository{{}},
+	})))
+}
+
+func TestDefault(t *testing.T) {
+	ctx := context.New(config.Project{
+		ProjectName: "foo",
+		APKRepositories: []config.APKRepository{{
+			KeyFile: "foo.rsa",
+			Blob:    config.RepositoryBlob{Provider: "s3", Bucket: "bar"},
+		}},
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.Equal(t, config.APKRepository{
+		ID:          "default",
+		Name:        "foo",
+		Description: "foo",
+		KeyFile:     "foo.rsa",
+		Blob:        config.RepositoryBlob{Provider: "s3", Bucket: "bar"},
+	}, ctx.Config.APKRepositories[0])
+}
+
+func TestDefaultErrors(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		repos []config.APKRepository
+		err   string
+	}{
+		{
+			name:  "no key",
+			repos: []config.APKRepository{{}},
+			err:   "apk_repositories default: key_file is required",
+		},
+		{
+			name:  "no blob",
+			repos: []config.APKRepository{{KeyFile: "foo.rsa"}},
+			err:   "apk_repositories default: blob is required",
+		},
+		{
+			name: "invalid provider",
+			repos: []config.APKRepository{{
+				KeyFile: "foo.rsa",
+				Blob:    config.RepositoryBlob{Bucket: "bar"},
+			}},
+			err: `apk_repositories default: invalid blob provider "", valid options are s3, gs and azblob`,
+		},
+		{
+			name: "duplicated ids",
+			repos: []config.APKRepository{
+				{KeyFile: "foo.rsa", Blob: config.RepositoryBlob{Provider: "s3", Bucket: "bar"}},
+				{KeyFile: "foo.rsa", Blob: config.RepositoryBlob{Provider: "gs", Bucket: "bar"}},
+			},
+			err: "found 2 apk_repositories with the ID 'default', please fix your config",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.New(config.Project{
+				ProjectName:     "foo",
+				APKRepositories: tt.repos,
+			})
+			require.EqualError(t, Pipe{}.Default(ctx), tt.err)
+		})
+	}
+}
+
+func TestDefaultKeyName(t *testing.T) {
+	require.Equal(t, "foo@example.com-1234.rsa.pub", defaultKeyName("/keys/foo@example.com-1234.rsa"))
+	require.Equal(t, "foo.rsa.pub", defaultKeyName("foo.pem"))
+	require.Equal(t, "foo.rsa.pub", defaultKeyName("foo"))
+}
+
+func TestPublish(t *testing.T) {
+	key := generateKey(t)
+	keyFile := filepath.Join(t.TempDir(), "foo@example.com.rsa")
+	writeKey(t, key, keyFile)
+	bucket := t.TempDir()
+	cfg := config.APKRepository{
+		KeyFile: keyFile,
+		URL:     "https://apk.example.com",
+		Blob:    config.RepositoryBlob{Provider: "s3", Bucket: bucket},
+		Folder:  "{{ .ProjectName }}",
+	}
+	ctx := newContext(t, cfg)
+	addAPK(t, ctx, "foo", "1.0.0", "amd64")
+	addAPK(t, ctx, "foo", "1.0.0", "arm64")
+	require.NoError(t, Pipe{}.Publish(ctx))
+
+	repo := filepath.Join(bucket, "foo")
+	for _, name := range []string{
+		"x86_64/foo-1.0.0.apk",
+		"x86_64/APKINDEX.tar.gz",
+		"aarch64/foo-1.0.0.apk",
+		"aarch64/APKINDEX.tar.gz",
+	} {
+		require.FileExists(t, filepath.Join(repo, name))
+	}
+	require.Len(t, readIndexFile(t, repo, "x86_64").entries, 1)
+
+	bts, err := os.ReadFile(filepath.Join(repo, "foo@example.com.rsa.pub"))
+	require.NoError(t, err)
+	pub, err := publicKey(key)
+	require.NoError(t, err)
+	require.Equal(t, string(pub), string(bts))
+
+	// a new release keeps the packages already in the repository.
+	ctx = newContext(t, cfg)
+	addAPK(t, ctx, "foo", "1.1.0", "amd64")
+	require.NoError(t, Pipe{}.Publish(ctx))
+
+	entries := readIndexFile(t, repo, "x86_64").sorted()
+	require.Len(t, entries, 2)
+	require.Equal(t, "1.1.0", entries[1].version)
+	require.Len(t, readIndexFile(t, repo, "aarch64").entries, 1)
+}
+
+func TestPublishSkip(t *testing.T) {
+	t.Run("skip upload", func(t *testing.T) {
+		ctx := newContext(t, config.APKRepository{
+			KeyFile:    "foo.rsa",
+			SkipUpload: "true",
+			Blob:       config.RepositoryBlob{Provider: "s3", Bucket: "foo"},
+		})
+		require.True(t, pipe.IsSkip(Pipe{}.Publish(ctx)))
+	})
+
+	t.Run("auto", func(t *testing.T) {
+		ctx := newContext(t, config.APKRepository{
+			KeyFile:    "foo.rsa",
+			SkipUpload: "auto",
+			Blob:       config.RepositoryBlob{Provider: "s3", Bucket: "foo"},
+		})
+		ctx.Semver.Prerelease = "beta1"
+		require.True(t, pipe.IsSkip(Pipe{}.Publish(ctx)))
+	})
+
+	t.Run("no apks", func(t *testing.T) {
+		ctx := newContext(t, config.APKRepository{
+			KeyFile: "foo.rsa",
+			Blob:    config.RepositoryBlob{Provider: "s3", Bucket: "foo"},
+		})
+		ctx.Artifacts.Add(&artifact.Artifact{
+			Name: "foo.deb",
+			Type: artifact.LinuxPackage,
+			Extra: map[string]interface{}{
+				artifact.ExtraFormat: "deb",
+			},
+		})
+		require.EqualError(t, Pipe{}.Publish(ctx), "no apk packages found")
+	})
+}
+
+func TestPublishInvalidKey(t *testing.T) {
+	bucket := t.TempDir()
+	ctx := newContext(t, config.APKRepository{
+		KeyFile: filepath.Join(t.TempDir(), "nope.rsa"),
+		Blob:    config.RepositoryBlob{Provider: "s3", Bucket: bucket},
+	})
+	addAPK(t, ctx, "foo", "1.0.0", "amd64")
+	err := Pipe{}.Publish(ctx)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to read key")
+	require.NoFileExists(t, filepath.Join(bucket, "x86_64/foo-1.0.0.apk"))
+}
+
+// newContext returns a context with the given repository, published to a
+// local folder with fileblob instead of a real bucket.
+func newContext(tb testing.TB, repo config.APKRepository) *context.Context {
+	tb.Helper()
+	ctx := context.New(config.Project{
+		ProjectName:     "foo",
+		Dist:            tb.TempDir(),
+		APKRepositories: []config.APKRepository{repo},
+	})
+	ctx.Git.CurrentTag = "v1.0.0"
+	ctx.Date = time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)
+	require.NoError(tb, Pipe{}.Default(ctx))
+	ctx.Config.APKRepositories[0].Blob.Provider = "file"
+	return ctx
+}
+
+func addAPK(tb testing.TB, ctx *context.Context, name, version, arch string) {
+	tb.Helper()
+	path := createAPK(tb, ctx.Config.Dist, name, version, arch)
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name:   filepath.Base(path),
+		Path:   path,
+		Goos:   "linux",
+		Goarch: arch,
+		Type:   artifact.LinuxPackage,
+		Extra: map[string]interface{}{
+			artifact.ExtraID:     "default",
+			artifact.ExtraFormat: "apk",
+		},
+	})
+}
+
+func readIndexFile(tb testing.TB, repo, arch string) *index {
+	tb.Helper()
+	bts, err := os.ReadFile(filepath.Join(repo, arch, indexName))
+	require.NoError(tb, err)
+	idx, err := parseIndex(bts)
+	require.NoError(tb, err)
+	return idx
+}
@@ -0,0 +1,249 @@
+package apk
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1" // nolint: gosec
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const indexName = "APKINDEX.tar.gz"
+
+// entry is a package in the APKINDEX.
+type entry struct {
+	name    string
+	version string
+	text    string
+}
+
+// index is the APKINDEX of one architecture of the repository.
+type index struct {
+	entries map[string]entry
+}
+
+func newEntry(info apkInfo) entry {
+	lines := []string{
+		"C:" + info.checksum,
+		"P:" + info.field("pkgname"),
+		"V:" + info.field("pkgver"),
+		"A:" + info.field("arch"),
+		"S:" + strconv.FormatInt(info.size, 10),
+		"I:" + info.field("size"),
+		"T:" + info.field("pkgdesc"),
+	}
+	for _, field := range []struct {
+		key, name string
+	}{
+		{"U", "url"},
+		{"L", "license"},
+		{"o", "origin"},
+		{"m", "maintainer"},
+		{"t", "builddate"},
+		{"c", "commit"},
+	} {
+		if value := info.field(field.name); value != "" {
+			lines = append(lines, field.key+":"+value)
+		}
+	}
+	for _, field := range []struct {
+		key, name string
+	}{
+		{"D", "depend"},
+		{"p", "provides"},
+		{"r", "replaces"},
+	} {
+		if values := info.fields[field.name]; len(values) > 0 {
+			lines = append(lines, field.key+":"+strings.Join(values, " "))
+		}
+	}
+	return entry{
+		name:    info.field("pkgname"),
+		version: info.field("pkgver"),
+		text:    strings.Join(lines, "\n"),
+	}
+}
+
+// parseIndex parses the given APKINDEX.tar.gz, or returns an empty index if
+// it is nil.
+func parseIndex(tgz []byte) (*index, error) {
+	idx := &index{entries: map[string]entry{}}
+	if tgz == nil {
+		return idx, nil
+	}
+	// the signature and the index are concatenated gzip streams, which the
+	// reader reads as a single tar.
+	gz, err := gzip.NewReader(bytes.NewReader(tgz))
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", indexName, err)
+	}
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("invalid %s: APKINDEX not found", indexName)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", indexName, err)
+		}
+		if hdr.Name != "APKINDEX" {
+			continue
+		}
+		bts, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", indexName, err)
+		}
+		for _, block := range strings.Split(string(bts), "\n\n") {
+			block = strings.TrimSpace(block)
+			if block == "" {
+				continue
+			}
+			e := entry{text: block}
+			for _, line := range strings.Split(block, "\n") {
+				if strings.HasPrefix(line, "P:") {
+					e.name = line[2:]
+				}
+				if strings.HasPrefix(line, "V:") {
+					e.version = line[2:]
+				}
+			}
+			idx.add(e)
+		}
+		return idx, nil
+	}
+}
+
+// add adds the given entry, replacing the same version of the package if it
+// is already there.
+func (idx *index) add(e entry) {
+	idx.entries[e.name+"-"+e.version] = e
+}
+
+func (idx *index) sorted() []entry {
+	result := make([]entry, 0, len(idx.entries))
+	for _, e := range idx.entries {
+		result = append(result, e)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].name != result[j].name {
+			return result[i].name < result[j].name
+		}
+		return result[i].version < result[j].version
+	})
+	return result
+}
+
+// render returns the APKINDEX.tar.gz of the index, signed with the given
+// key.
+func (idx *index) render(description string, key *rsa.PrivateKey, keyName string) ([]byte, error) {
+	var content strings.Builder
+	for _, e := range idx.sorted() {
+		content.WriteString(e.text)
+		content.WriteString("\n\n")
+	}
+	indexTgz, err := tgz(true, map[string][]byte{
+		"DESCRIPTION": []byte(description),
+		"APKINDEX":    []byte(content.String()),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// apk verifies a RSA signature of the SHA1 of the gzipped index.
+	digest := sha1.Sum(indexTgz) // nolint: gosec
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA1, digest[:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign %s: %w", indexName, err)
+	}
+	signatureTgz, err := tgz(false, map[string][]byte{
+		".SIGN.RSA." + keyName: signature,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return append(signatureTgz, indexTgz...), nil
+}
+
+// tgz returns a gzipped tar with the given files, sorted by name.
+// If terminate is false, the tar end of archive marker is not written, so it
+// can be concatenated with another tar.
+func tgz(terminate bool, files map[string][]byte) ([]byte, error) {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gw)
+	for _, name := range names {
+		if err := tw.WriteHeader(&tar.Header{
+			Name: name,
+			Mode: 0o644,
+			Size: int64(len(files[name])),
+		}); err != nil {
+			return nil, err
+		}
+		if _, err := tw.Write(files[name]); err != nil {
+			return nil, err
+		}
+	}
+	if err := tw.Flush(); err != nil {
+		return nil, err
+	}
+	if terminate {
+		if err := tw.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := gw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// loadKey reads the RSA private key from the given PEM file.
+func loadKey(path string) (*rsa.PrivateKey, error) {
+	bts, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key: %w", err)
+	}
+	block, _ := pem.Decode(bts)
+	if block == nil {
+		return nil, fmt.Errorf("failed to read key: %s is not a PEM file", path)
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key: %w", err)
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("failed to read key: %s is not a RSA key", path)
+	}
+	return rsaKey, nil
+}
+
+// publicKey returns the PEM encoded public key of the given key, as expected
+// in /etc/apk/keys.
+func publicKey(key *rsa.PrivateKey) ([]byte, error) {
+	bts, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: bts}), nil
+}
@@ -0,0 +1,166 @@
+package apk
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1" // nolint: gosec
+	"crypto/x509"
+	"encoding/pem"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewEntry(t *testing.T) {
+	require.Equal(t, entry{
+		name:    "foo",
+		version: "1.0.0",
+		text: `C:Q1abc=
+P:foo
+V:1.0.0
+A:x86_64
+S:1234
+I:42
+T:a foo
+U:https://example.com
+L:MIT
+m:Foo <foo@example.com>
+D:bash curl
+p:cmd:foo`,
+	}, newEntry(apkInfo{
+		checksum: "Q1abc=",
+		size:     1234,
+		fields: map[string][]string{
+			"pkgname":    {"foo"},
+			"pkgver":     {"1.0.0"},
+			"arch":       {"x86_64"},
+			"size":       {"42"},
+			"pkgdesc":    {"a foo"},
+			"url":        {"https://example.com"},
+			"license":    {"MIT"},
+			"maintainer": {"Foo <foo@example.com>"},
+			"depend":     {"bash", "curl"},
+			"provides":   {"cmd:foo"},
+		},
+	}))
+}
+
+func TestIndex(t *testing.T) {
+	key := generateKey(t)
+	idx, err := parseIndex(nil)
+	require.NoError(t, err)
+	idx.add(testEntry("foo", "1.0.0"))
+	idx.add(testEntry("bar", "1.0.0"))
+	idx.add(testEntry("foo", "1.1.0"))
+
+	bts, err := idx.render("my repo", key, "foo.rsa.pub")
+	require.NoError(t, err)
+
+	// the first stream is the signature of the second one.
+	r := bytes.NewReader(bts)
+	gz, err := gzip.NewReader(r)
+	require.NoError(t, err)
+	gz.Multistream(false)
+	tr := tar.NewReader(gz)
+	hdr, err := tr.Next()
+	require.NoError(t, err)
+	require.Equal(t, ".SIGN.RSA.foo.rsa.pub", hdr.Name)
+	signature, err := io.ReadAll(tr)
+	require.NoError(t, err)
+	_, err = io.Copy(io.Discard, gz)
+	require.NoError(t, err)
+	digest := sha1.Sum(bts[len(bts)-r.Len():]) // nolint: gosec
+	require.NoError(t, rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA1, digest[:], signature))
+
+	parsed, err := parseIndex(bts)
+	require.NoError(t, err)
+	require.Equal(t, idx.sorted(), parsed.sorted())
+	require.Equal(t, []entry{
+		testEntry("bar", "1.0.0"),
+		testEntry("foo", "1.0.0"),
+		testEntry("foo", "1.1.0"),
+	}, parsed.sorted())
+
+	// the same version replaces the previous one.
+	replaced := testEntry("foo", "1.1.0")
+	replaced.text += "\nD:bash"
+	parsed.add(replaced)
+	require.Len(t, parsed.sorted(), 3)
+	require.Equal(t, replaced, parsed.sorted()[2])
+}
+
+func TestParseIndexInvalid(t *testing.T) {
+	_, err := parseIndex([]byte("nope"))
+	require.Error(t, err)
+
+	bts, err := tgz(true, map[string][]byte{"DESCRIPTION": []byte("foo")})
+	require.NoError(t, err)
+	_, err = parseIndex(bts)
+	require.EqualError(t, err, "invalid APKINDEX.tar.gz: APKINDEX not found")
+}
+
+func TestLoadKey(t *testing.T) {
+	key := generateKey(t)
+	dir := t.TempDir()
+
+	pkcs1 := filepath.Join(dir, "pkcs1.rsa")
+	require.NoError(t, os.WriteFile(pkcs1, pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(key),
+	}), 0o600))
+	loaded, err := loadKey(pkcs1)
+	require.NoError(t, err)
+	require.True(t, key.Equal(loaded))
+
+	pkcs8 := filepath.Join(dir, "pkcs8.rsa")
+	writeKey(t, key, pkcs8)
+	loaded, err = loadKey(pkcs8)
+	require.NoError(t, err)
+	require.True(t, key.Equal(loaded))
+
+	invalid := filepath.Join(dir, "invalid.rsa")
+	require.NoError(t, os.WriteFile(invalid, []byte("nope"), 0o600))
+	_, err = loadKey(invalid)
+	require.EqualError(t, err, "failed to read key: "+invalid+" is not a PEM file")
+
+	_, err = loadKey(filepath.Join(dir, "nope.rsa"))
+	require.Error(t, err)
+
+	pub, err := publicKey(key)
+	require.NoError(t, err)
+	block, _ := pem.Decode(pub)
+	require.Equal(t, "PUBLIC KEY", block.Type)
+}
+
+func testEntry(name, version string) entry {
+	return entry{
+		name:    name,
+		version: version,
+		text:    "C:Q1abc=\nP:" + name + "\nV:" + version + "\nA:x86_64",
+	}
+}
+
+func generateKey(tb testing.TB) *rsa.PrivateKey {
+	tb.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(tb, err)
+	return key
+}
+
+// writeKey writes the given key to path, as PKCS8 like abuild-keygen does.
+func writeKey(tb testing.TB, key *rsa.PrivateKey, path string) {
+	tb.Helper()
+	bts, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(tb, err)
+	require.NoError(tb, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{
+		Type:  "PRIVATE KEY",
+		Bytes: bts,
+	}), 0o600))
+}
@@ -0,0 +1,119 @@
+package apk
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha1" // nolint: gosec
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// apkInfo is the metadata of an apk package.
+type apkInfo struct {
+	// fields of the .PKGINFO file, by key.
+	fields map[string][]string
+	// checksum of the control section, as used in the APKINDEX.
+	checksum string
+	size     int64
+}
+
+func (i apkInfo) field(key string) string {
+	if values := i.fields[key]; len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// readAPK reads the metadata of the given apk package.
+//
+// An apk package is the concatenation of gzipped tar streams: an optional
+// signature, the control section with the .PKGINFO file, and the data.
+func readAPK(apkPath string) (apkInfo, error) {
+	var info apkInfo
+	data, err := os.ReadFile(apkPath)
+	if err != nil {
+		return info, err
+	}
+	r := bytes.NewReader(data)
+	for r.Len() > 0 {
+		start := len(data) - r.Len()
+		// bytes.Reader is an io.ByteReader, so gzip won't read past the end
+		// of the current stream.
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return info, fmt.Errorf("%s is not an apk package", apkPath)
+		}
+		gz.Multistream(false)
+		pkginfo, err := findPKGINFO(gz)
+		if err != nil {
+			return info, fmt.Errorf("failed to read %s: %w", apkPath, err)
+		}
+		if _, err := io.Copy(io.Discard, gz); err != nil {
+			return info, fmt.Errorf("failed to read %s: %w", apkPath, err)
+		}
+		if pkginfo == nil {
+			continue
+		}
+		sum := sha1.Sum(data[start : len(data)-r.Len()]) // nolint: gosec
+		info.fields = parsePKGINFO(pkginfo)
+		info.checksum = "Q1" + base64.StdEncoding.EncodeToString(sum[:])
+		info.size = int64(len(data))
+		if info.field("pkgname") == "" || info.field("pkgver") == "" || info.field("arch") == "" {
+			return info, fmt.Errorf("failed to read %s: .PKGINFO is missing pkgname, pkgver or arch", apkPath)
+		}
+		return info, nil
+	}
+	return info, fmt.Errorf("failed to read %s: .PKGINFO not found", apkPath)
+}
+
+// findPKGINFO returns the content of the .PKGINFO file in the given tar
+// stream, or nil if it is not there.
+func findPKGINFO(r io.Reader) ([]byte, error) {
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		// the signature and control streams are not terminated, so they
+		// may end without the tar end of archive marker.
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if hdr.Name == ".PKGINFO" {
+			return io.ReadAll(tr)
+		}
+	}
+}
+
+// parsePKGINFO parses the `key = value` lines of a .PKGINFO file.
+// Indented lines continue the value of the previous line.
+func parsePKGINFO(bts []byte) map[string][]string {
+	fields := map[string][]string{}
+	var last string
+	scanner := bufio.NewScanner(bytes.NewReader(bts))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "#") || strings.TrimSpace(line) == "" {
+			continue
+		}
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && last != "" {
+			values := fields[last]
+			values[len(values)-1] += " " + strings.TrimSpace(line)
+			continue
+		}
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		last = strings.TrimSpace(parts[0])
+		fields[last] = append(fields[last], strings.TrimSpace(parts[1]))
+	}
+	return fields
+}
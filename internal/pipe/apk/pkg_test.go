@@ -0,0 +1,121 @@
+package apk
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/goreleaser/nfpm/v2"
+	nfpmapk "github.com/goreleaser/nfpm/v2/apk"
+	"github.com/goreleaser/nfpm/v2/files"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadAPK(t *testing.T) {
+	path := createAPK(t, t.TempDir(), "foo", "1.0.0", "amd64")
+	info, err := readAPK(path)
+	require.NoError(t, err)
+	require.Equal(t, "foo", info.field("pkgname"))
+	require.Equal(t, "1.0.0", info.field("pkgver"))
+	require.Equal(t, "x86_64", info.field("arch"))
+	require.Equal(t, "a foo with more lines", info.field("pkgdesc"))
+	require.Equal(t, []string{"bash"}, info.fields["depend"])
+	require.Regexp(t, "^Q1[A-Za-z0-9+/]{27}=$", info.checksum)
+
+	stat, err := os.Stat(path)
+	require.NoError(t, err)
+	require.Equal(t, stat.Size(), info.size)
+
+	// the checksum only depends on the control section.
+	again, err := readAPK(path)
+	require.NoError(t, err)
+	require.Equal(t, info.checksum, again.checksum)
+}
+
+func TestReadSignedAPK(t *testing.T) {
+	dir := t.TempDir()
+	keyFile := filepath.Join(dir, "foo.rsa")
+	// nfpm only reads PKCS1 keys.
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(generateKey(t)),
+	}), 0o600))
+	path := createAPK(t, dir, "foo", "1.0.0", "amd64")
+	unsigned, err := readAPK(path)
+	require.NoError(t, err)
+
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	info := testInfo(t, dir, "foo", "1.0.0", "amd64")
+	info.APK.Signature.KeyFile = keyFile
+	require.NoError(t, nfpmapk.Default.Package(info, f))
+	require.NoError(t, f.Close())
+
+	// the signature is skipped, so the checksum is the same.
+	signed, err := readAPK(path)
+	require.NoError(t, err)
+	require.Equal(t, unsigned.checksum, signed.checksum)
+	require.Greater(t, signed.size, unsigned.size)
+}
+
+func TestReadAPKInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "foo.apk")
+	require.NoError(t, os.WriteFile(path, []byte("not an apk"), 0o644))
+	_, err := readAPK(path)
+	require.EqualError(t, err, path+" is not an apk package")
+
+	require.NoError(t, os.WriteFile(path, nil, 0o644))
+	_, err = readAPK(path)
+	require.EqualError(t, err, "failed to read "+path+": .PKGINFO not found")
+
+	_, err = readAPK(filepath.Join(t.TempDir(), "nope.apk"))
+	require.Error(t, err)
+}
+
+func TestParsePKGINFO(t *testing.T) {
+	require.Equal(t, map[string][]string{
+		"pkgname": {"foo"},
+		"pkgdesc": {"a foo with more lines"},
+		"depend":  {"bash", "curl"},
+	}, parsePKGINFO([]byte(`# Generated by nfpm
+pkgname = foo
+pkgdesc = a foo
+  with more lines
+depend = bash
+depend = curl
+not a field
+`)))
+}
+
+func createAPK(tb testing.TB, dir, name, version, arch string) string {
+	tb.Helper()
+	bin := filepath.Join(dir, name)
+	require.NoError(tb, os.WriteFile(bin, []byte("#!/bin/sh\necho "+name+"\n"), 0o755))
+	path := filepath.Join(dir, name+"_"+version+"_"+arch+".apk")
+	f, err := os.Create(path)
+	require.NoError(tb, err)
+	defer f.Close()
+	require.NoError(tb, nfpmapk.Default.Package(testInfo(tb, dir, name, version, arch), f))
+	return path
+}
+
+func testInfo(tb testing.TB, dir, name, version, arch string) *nfpm.Info {
+	tb.Helper()
+	bin := filepath.Join(dir, name)
+	return nfpm.WithDefaults(&nfpm.Info{
+		Name:        name,
+		Arch:        arch,
+		Version:     version,
+		Maintainer:  "Foo <foo@example.com>",
+		Description: "a " + name + "\nwith more lines",
+		License:     "MIT",
+		Overridables: nfpm.Overridables{
+			Depends: []string{"bash"},
+			Contents: files.Contents{
+				{Source: bin, Destination: "/usr/bin/" + name},
+			},
+		},
+	})
+}
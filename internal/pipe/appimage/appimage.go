@@ -0,0 +1,229 @@
+// Package appimage implements the Pipe interface, packaging linux binaries as
+// AppImages.
+package appimage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/gio"
+	"github.com/goreleaser/goreleaser/internal/ids"
+	"github.com/goreleaser/goreleaser/internal/semerrgroup"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+)
+
+const (
+	defaultNameTemplate = "{{ .ProjectName }}_{{ .Version }}_{{ .Os }}_{{ .Arch }}{{ if .Arm }}v{{ .Arm }}{{ end }}"
+	appImageTool        = "appimagetool"
+)
+
+// ErrNoAppImageTool is returned when appimagetool cannot be found in $PATH.
+var ErrNoAppImageTool = errors.New("appimagetool not present in $PATH")
+
+// Pipe for AppImage packaging.
+type Pipe struct{}
+
+func (Pipe) String() string                 { return "appimages" }
+func (Pipe) Skip(ctx *context.Context) bool { return len(ctx.Config.AppImages) == 0 }
+
+// Default sets the pipe defaults.
+func (Pipe) Default(ctx *context.Context) error {
+	ids := ids.New("appimages")
+	for i := range ctx.Config.AppImages {
+		appimage := &ctx.Config.AppImages[i]
+		if appimage.ID == "" {
+			appimage.ID = "default"
+		}
+		if appimage.NameTemplate == "" {
+			appimage.NameTemplate = defaultNameTemplate
+		}
+		if appimage.Name == "" {
+			appimage.Name = ctx.Config.ProjectName
+		}
+		if len(appimage.Categories) == 0 {
+			appimage.Categories = []string{"Utility"}
+		}
+		if appimage.Icon == "" {
+			return fmt.Errorf("appimages %s: icon is required", appimage.ID)
+		}
+		ids.Inc(appimage.ID)
+	}
+	return ids.Validate()
+}
+
+// Run the pipe.
+func (Pipe) Run(ctx *context.Context) error {
+	if _, err := exec.LookPath(appImageTool); err != nil {
+		return ErrNoAppImageTool
+	}
+	g := semerrgroup.New(ctx.Parallelism)
+	for _, appimage := range ctx.Config.AppImages {
+		filters := []artifact.Filter{
+			artifact.ByGoos("linux"),
+			artifact.ByType(artifact.Binary),
+		}
+		if len(appimage.Builds) > 0 {
+			filters = append(filters, artifact.ByIDs(appimage.Builds...))
+		}
+		for platform, binaries := range ctx.Artifacts.Filter(artifact.And(filters...)).GroupByPlatform() {
+			arch, ok := archs[platform]
+			if !ok {
+				log.FromContext(ctx).WithField("platform", platform).Warn("ignored unsupported platform")
+				continue
+			}
+			appimage := appimage
+			binaries := binaries
+			g.Go(func() error {
+				return create(ctx, appimage, arch, binaries)
+			})
+		}
+	}
+	return g.Wait()
+}
+
+// archs maps the platforms to the architectures appimagetool knows.
+var archs = map[string]string{
+	"linuxamd64": "x86_64",
+	"linux386":   "i686",
+	"linuxarm64": "aarch64",
+	"linuxarm6":  "armhf",
+	"linuxarm7":  "armhf",
+}
+
+func create(ctx *context.Context, appimage config.AppImage, arch string, binaries []*artifact.Artifact) error {
+	name, err := tmpl.New(ctx).WithArtifact(binaries[0], nil).Apply(appimage.NameTemplate)
+	if err != nil {
+		return err
+	}
+	log := log.FromContext(ctx).WithField("appimage", name+".AppImage")
+
+	// AppDir is the directory appimagetool turns into the AppImage.
+	appDir := filepath.Join(ctx.Config.Dist, name+".AppDir")
+	if err := os.MkdirAll(filepath.Join(appDir, "usr", "bin"), 0o755); err != nil {
+		return err
+	}
+
+	binary := appimage.Binary
+	if binary == "" {
+		binary = binaries[0].Name
+	}
+	var found bool
+	for _, b := range binaries {
+		if b.Name == binary {
+			found = true
+		}
+		if err := gio.CopyWithMode(b.Path, filepath.Join(appDir, "usr", "bin", b.Name), 0o755); err != nil {
+			return fmt.Errorf("failed to copy binary: %w", err)
+		}
+	}
+	if !found {
+		return fmt.Errorf("appimages %s: binary %s was not built", appimage.ID, binary)
+	}
+	if err := os.Symlink(filepath.Join("usr", "bin", binary), filepath.Join(appDir, "AppRun")); err != nil {
+		return err
+	}
+
+	icon := appimage.Name + filepath.Ext(appimage.Icon)
+	if err := gio.Copy(appimage.Icon, filepath.Join(appDir, icon)); err != nil {
+		return fmt.Errorf("failed to copy icon: %w", err)
+	}
+	if err := os.Symlink(icon, filepath.Join(appDir, ".DirIcon")); err != nil {
+		return err
+	}
+
+	desktop := filepath.Join(appDir, appimage.Name+".desktop")
+	if err := os.WriteFile(desktop, []byte(desktopEntry(appimage, binary)), 0o644); err != nil { //nolint: gosec
+		return err
+	}
+
+	for _, file := range appimage.Files {
+		dst := file.Destination
+		if dst == "" {
+			dst = file.Source
+		}
+		dst = filepath.Join(appDir, dst)
+		if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+			return err
+		}
+		if file.Info.Mode != 0 {
+			err = gio.CopyWithMode(file.Source, dst, file.Info.Mode)
+		} else {
+			err = gio.Copy(file.Source, dst)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to copy %s: %w", file.Source, err)
+		}
+	}
+
+	updateInformation, err := tmpl.New(ctx).WithArtifact(binaries[0], nil).Apply(appimage.UpdateInformation)
+	if err != nil {
+		return err
+	}
+
+	filename := name + ".AppImage"
+	args := []string{}
+	if updateInformation != "" {
+		args = append(args, "--updateinformation", updateInformation)
+	}
+	args = append(args, name+".AppDir", filename)
+
+	log.Info("creating")
+	/* #nosec */
+	cmd := exec.CommandContext(ctx, appImageTool, args...)
+	// the zsync file is created in the working directory.
+	cmd.Dir = ctx.Config.Dist
+	cmd.Env = append(ctx.Env.Strings(), "ARCH="+arch)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to create appimage: %w: %s", err, string(out))
+	}
+
+	newArtifact := func(name, format string) *artifact.Artifact {
+		return &artifact.Artifact{
+			Type:   artifact.AppImage,
+			Name:   name,
+			Path:   filepath.Join(ctx.Config.Dist, name),
+			Goos:   binaries[0].Goos,
+			Goarch: binaries[0].Goarch,
+			Goarm:  binaries[0].Goarm,
+			Extra: map[string]interface{}{
+				artifact.ExtraID:     appimage.ID,
+				artifact.ExtraFormat: format,
+			},
+		}
+	}
+	ctx.Artifacts.Add(newArtifact(filename, "AppImage"))
+	if updateInformation == "" {
+		return nil
+	}
+	zsync := newArtifact(filename+".zsync", "zsync")
+	if _, err := os.Stat(zsync.Path); err != nil {
+		return fmt.Errorf("%s was not created, is zsyncmake installed? %w", zsync.Name, err)
+	}
+	ctx.Artifacts.Add(zsync)
+	return nil
+}
+
+// desktopEntry returns the desktop entry of the given AppImage, which runs
+// the given binary.
+func desktopEntry(appimage config.AppImage, binary string) string {
+	var sb strings.Builder
+	sb.WriteString("[Desktop Entry]\n")
+	sb.WriteString("Type=Application\n")
+	sb.WriteString("Name=" + appimage.Name + "\n")
+	sb.WriteString("Exec=" + binary + "\n")
+	sb.WriteString("Icon=" + appimage.Name + "\n")
+	sb.WriteString("Categories=" + strings.Join(appimage.Categories, ";") + ";\n")
+	if appimage.Comment != "" {
+		sb.WriteString("Comment=" + appimage.Comment + "\n")
+	}
+	sb.WriteString(fmt.Sprintf("Terminal=%t\n", appimage.Terminal))
+	return sb.String()
+}
@@ -0,0 +1,236 @@
+package appimage
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDescription(t *testing.T) {
+	require.NotEmpty(t, Pipe{}.String())
+}
+
+func TestSkip(t *testing.T) {
+	require.True(t, Pipe{}.Skip(context.New(config.Project{})))
+	require.False(t, Pipe{}.Skip(context.New(config.Project{
+		AppImages: []config.AppImage{{}},
+	})))
+}
+
+func TestDefault(t *testing.T) {
+	ctx := context.New(config.Project{
+		ProjectName: "foo",
+		AppImages:   []config.AppImage{{Icon: "icon.png"}},
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.Equal(t, config.AppImage{
+		ID:           "default",
+		NameTemplate: defaultNameTemplate,
+		Name:         "foo",
+		Icon:         "icon.png",
+		Categories:   []string{"Utility"},
+	}, ctx.Config.AppImages[0])
+}
+
+func TestDefaultNoIcon(t *testing.T) {
+	ctx := context.New(config.Project{
+		AppImages: []config.AppImage{{ID: "foo"}},
+	})
+	require.EqualError(t, Pipe{}.Default(ctx), "appimages foo: icon is required")
+}
+
+func TestDefaultDuplicateID(t *testing.T) {
+	ctx := context.New(config.Project{
+		AppImages: []config.AppImage{
+			{ID: "foo", Icon: "icon.png"},
+			{ID: "foo", Icon: "icon.png"},
+		},
+	})
+	require.EqualError(t, Pipe{}.Default(ctx), "found 2 appimages with the ID 'foo', please fix your config")
+}
+
+func TestRunNoAppImageTool(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+	ctx := context.New(config.Project{
+		AppImages: []config.AppImage{{}},
+	})
+	require.Equal(t, ErrNoAppImageTool, Pipe{}.Run(ctx))
+}
+
+func TestRun(t *testing.T) {
+	calls := fakeAppImageTool(t)
+	ctx := context.New(config.Project{
+		ProjectName: "foo",
+		Dist:        t.TempDir(),
+		AppImages: []config.AppImage{{
+			Builds:            []string{"foo"},
+			Icon:              "testdata/icon.svg",
+			Comment:           "a foo",
+			Terminal:          true,
+			UpdateInformation: "gh-releases-zsync|foo|bar|latest|foo_*_{{ .Arch }}.AppImage.zsync",
+			Files: []config.File{{
+				Source:      "testdata/icon.svg",
+				Destination: "usr/share/foo/icon.svg",
+			}},
+		}},
+	})
+	ctx.Version = "1.0.0"
+	require.NoError(t, Pipe{}.Default(ctx))
+	addBinaries(t, ctx)
+	require.NoError(t, Pipe{}.Run(ctx))
+
+	appimages := ctx.Artifacts.Filter(artifact.ByType(artifact.AppImage)).List()
+	var names []string
+	for _, a := range appimages {
+		names = append(names, a.Name)
+		require.FileExists(t, a.Path)
+		require.Equal(t, "default", a.ExtraOr(artifact.ExtraID, ""))
+	}
+	require.ElementsMatch(t, []string{
+		"foo_1.0.0_linux_amd64.AppImage",
+		"foo_1.0.0_linux_amd64.AppImage.zsync",
+		"foo_1.0.0_linux_arm64.AppImage",
+		"foo_1.0.0_linux_arm64.AppImage.zsync",
+	}, names)
+
+	appDir := filepath.Join(ctx.Config.Dist, "foo_1.0.0_linux_amd64.AppDir")
+	bts, err := os.ReadFile(filepath.Join(appDir, "foo.desktop"))
+	require.NoError(t, err)
+	require.Equal(t, `[Desktop Entry]
+Type=Application
+Name=foo
+Exec=foo
+Icon=foo
+Categories=Utility;
+Comment=a foo
+Terminal=true
+`, string(bts))
+	link, err := os.Readlink(filepath.Join(appDir, "AppRun"))
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join("usr", "bin", "foo"), link)
+	link, err = os.Readlink(filepath.Join(appDir, ".DirIcon"))
+	require.NoError(t, err)
+	require.Equal(t, "foo.svg", link)
+	require.FileExists(t, filepath.Join(appDir, "usr", "bin", "foo"))
+	require.FileExists(t, filepath.Join(appDir, "usr", "share", "foo", "icon.svg"))
+
+	bts, err = os.ReadFile(calls)
+	require.NoError(t, err)
+	require.Contains(t, strings.Split(strings.TrimSpace(string(bts)), "\n"),
+		"x86_64 --updateinformation gh-releases-zsync|foo|bar|latest|foo_*_amd64.AppImage.zsync foo_1.0.0_linux_amd64.AppDir foo_1.0.0_linux_amd64.AppImage")
+}
+
+func TestRunNoUpdateInformation(t *testing.T) {
+	fakeAppImageTool(t)
+	ctx := context.New(config.Project{
+		ProjectName: "foo",
+		Dist:        t.TempDir(),
+		AppImages:   []config.AppImage{{Icon: "testdata/icon.svg"}},
+	})
+	ctx.Version = "1.0.0"
+	require.NoError(t, Pipe{}.Default(ctx))
+	addBinaries(t, ctx)
+	require.NoError(t, Pipe{}.Run(ctx))
+	require.Len(t, ctx.Artifacts.Filter(artifact.ByType(artifact.AppImage)).List(), 2)
+	require.Empty(t, ctx.Artifacts.Filter(artifact.ByFormats("zsync")).List())
+}
+
+func TestRunBinaryNotBuilt(t *testing.T) {
+	fakeAppImageTool(t)
+	ctx := context.New(config.Project{
+		ProjectName: "foo",
+		Dist:        t.TempDir(),
+		AppImages:   []config.AppImage{{Icon: "testdata/icon.svg", Binary: "bar"}},
+	})
+	ctx.Version = "1.0.0"
+	require.NoError(t, Pipe{}.Default(ctx))
+	addBinaries(t, ctx)
+	require.EqualError(t, Pipe{}.Run(ctx), "appimages default: binary bar was not built")
+}
+
+func TestRunInvalidNameTemplate(t *testing.T) {
+	fakeAppImageTool(t)
+	ctx := context.New(config.Project{
+		ProjectName: "foo",
+		Dist:        t.TempDir(),
+		AppImages:   []config.AppImage{{Icon: "testdata/icon.svg"}},
+	})
+	ctx.Version = "1.0.0"
+	require.NoError(t, Pipe{}.Default(ctx))
+	addBinaries(t, ctx)
+	ctx.Config.AppImages[0].NameTemplate = "{{ .Foo }"
+	require.Error(t, Pipe{}.Run(ctx))
+}
+
+func TestRunAppImageToolFails(t *testing.T) {
+	dir := t.TempDir()
+	script := "#!/bin/sh\necho nope\nexit 1\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, appImageTool), []byte(script), 0o755))
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	ctx := context.New(config.Project{
+		ProjectName: "foo",
+		Dist:        t.TempDir(),
+		AppImages:   []config.AppImage{{Icon: "testdata/icon.svg"}},
+	})
+	ctx.Version = "1.0.0"
+	require.NoError(t, Pipe{}.Default(ctx))
+	addBinaries(t, ctx)
+	err := Pipe{}.Run(ctx)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to create appimage")
+	require.Contains(t, err.Error(), "nope")
+}
+
+// addBinaries adds the linux binaries AppImages are made of, and a windows
+// one, which is ignored.
+func addBinaries(tb testing.TB, ctx *context.Context) {
+	tb.Helper()
+	for _, goarch := range []string{"amd64", "arm64", "mips"} {
+		path := filepath.Join(ctx.Config.Dist, "foo_linux_"+goarch, "foo")
+		require.NoError(tb, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(tb, os.WriteFile(path, []byte("fake binary"), 0o755))
+		ctx.Artifacts.Add(&artifact.Artifact{
+			Name:   "foo",
+			Path:   path,
+			Goos:   "linux",
+			Goarch: goarch,
+			Type:   artifact.Binary,
+			Extra: map[string]interface{}{
+				artifact.ExtraID: "foo",
+			},
+		})
+	}
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name:   "foo.exe",
+		Path:   "nope",
+		Goos:   "windows",
+		Goarch: "amd64",
+		Type:   artifact.Binary,
+	})
+}
+
+// fakeAppImageTool puts an appimagetool in the PATH that logs its calls and
+// creates the AppImage, and the zsync file if update information is given.
+func fakeAppImageTool(tb testing.TB) string {
+	tb.Helper()
+	dir := tb.TempDir()
+	calls := filepath.Join(dir, "calls.log")
+	script := `#!/bin/sh
+echo "$ARCH $@" >> ` + calls + `
+if [ "$1" = "--updateinformation" ]; then
+	echo appimage > "$4"
+	echo zsync > "$4.zsync"
+else
+	echo appimage > "$2"
+fi
+`
+	require.NoError(tb, os.WriteFile(filepath.Join(dir, appImageTool), []byte(script), 0o755))
+	tb.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return calls
+}
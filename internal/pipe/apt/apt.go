@@ -0,0 +1,325 @@
+// Package apt implements the Pipe interface, publishing the deb packages to
+// signed APT repositories.
+package apt
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/commitauthor"
+	"github.com/goreleaser/goreleaser/internal/ids"
+	"github.com/goreleaser/goreleaser/internal/pipe"
+	"github.com/goreleaser/goreleaser/internal/pkgrepo"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+)
+
+const defaultCommitMessage = "Update {{ .ProjectName }} APT repository to {{ .Tag }}"
+
+// Pipe for APT repositories.
+type Pipe struct{}
+
+func (Pipe) String() string                 { return "apt repositories" }
+func (Pipe) Skip(ctx *context.Context) bool { return len(ctx.Config.APTRepositories) == 0 }
+
+// Default sets the pipe defaults.
+func (Pipe) Default(ctx *context.Context) error {
+	ids := ids.New("apt_repositories")
+	for i := range ctx.Config.APTRepositories {
+		repo := &ctx.Config.APTRepositories[i]
+		if repo.ID == "" {
+			repo.ID = "default"
+		}
+		if repo.Name == "" {
+			repo.Name = ctx.Config.ProjectName
+		}
+		if repo.Distribution == "" {
+			repo.Distribution = "stable"
+		}
+		if repo.Component == "" {
+			repo.Component = "main"
+		}
+		if repo.Origin == "" {
+			repo.Origin = repo.Name
+		}
+		if repo.Label == "" {
+			repo.Label = repo.Name
+		}
+
+		blob := repo.Blob.Bucket != ""
+		pages := repo.GitHubPages.Owner != "" || repo.GitHubPages.Name != ""
+		switch {
+		case blob && pages:
+			return fmt.Errorf("apt_repositories %s: blob and github_pages can't be used together", repo.ID)
+		case blob:
+			switch repo.Blob.Provider {
+			case "s3", "gs", "azblob":
+			default:
+				return fmt.Errorf("apt_repositories %s: invalid blob provider %q, valid options are s3, gs and azblob", repo.ID, repo.Blob.Provider)
+			}
+		case pages:
+			if repo.GitHubPages.Owner == "" || repo.GitHubPages.Name == "" {
+				return fmt.Errorf("apt_repositories %s: github_pages owner and name are required", repo.ID)
+			}
+			if repo.GitHubPages.Branch == "" {
+				repo.GitHubPages.Branch = "gh-pages"
+			}
+			repo.CommitAuthor = commitauthor.Default(repo.CommitAuthor)
+			if repo.CommitMessageTemplate == "" {
+				repo.CommitMessageTemplate = defaultCommitMessage
+			}
+		default:
+			return fmt.Errorf("apt_repositories %s: either blob or github_pages is required", repo.ID)
+		}
+		ids.Inc(repo.ID)
+	}
+	return ids.Validate()
+}
+
+// Publish the repositories.
+func (Pipe) Publish(ctx *context.Context) error {
+	skips := pipe.SkipMemento{}
+	for _, repo := range ctx.Config.APTRepositories {
+		err := doPublish(ctx, repo)
+		if err != nil && pipe.IsSkip(err) {
+			skips.Remember(err)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return skips.Evaluate()
+}
+
+func doPublish(ctx *context.Context, cfg config.APTRepository) error {
+	if strings.TrimSpace(cfg.SkipUpload) == "true" {
+		return pipe.Skip("apt_repositories.skip_upload is set")
+	}
+	if strings.TrimSpace(cfg.SkipUpload) == "auto" && ctx.Semver.Prerelease != "" {
+		return pipe.Skip("prerelease detected with 'auto' upload, skipping apt repository publish")
+	}
+
+	filters := []artifact.Filter{
+		artifact.ByType(artifact.LinuxPackage),
+		artifact.ByFormats("deb"),
+	}
+	if len(cfg.IDs) > 0 {
+		filters = append(filters, artifact.ByIDs(cfg.IDs...))
+	}
+	debs := ctx.Artifacts.Filter(artifact.And(filters...)).List()
+	if len(debs) == 0 {
+		return pipe.Skip("no deb packages found")
+	}
+
+	st, err := openStore(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer st.Close()
+
+	log := log.FromContext(ctx).WithField("repository", cfg.ID)
+	dist := path.Join("dists", cfg.Distribution)
+
+	// the packages already in the repository are kept, so the other
+	// architectures and versions can still be installed.
+	indexes := map[string][]paragraph{}
+	release, err := st.Read(ctx, path.Join(dist, "Release"))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to read the Release file: %w", err)
+	}
+	for _, p := range parseParagraphs(string(release)) {
+		for _, arch := range strings.Fields(p.get("Architectures")) {
+			bts, err := st.Read(ctx, packagesPath(cfg, arch))
+			if err != nil && !errors.Is(err, os.ErrNotExist) {
+				return fmt.Errorf("failed to read the %s index: %w", arch, err)
+			}
+			indexes[arch] = parseParagraphs(string(bts))
+		}
+	}
+
+	for _, deb := range debs {
+		control, err := readControl(deb.Path)
+		if err != nil {
+			return err
+		}
+		name := control.get("Package")
+		arch := control.get("Architecture")
+		if name == "" || arch == "" {
+			return fmt.Errorf("%s: control file has no Package or Architecture", deb.Name)
+		}
+		data, err := os.ReadFile(deb.Path)
+		if err != nil {
+			return err
+		}
+		filename := path.Join("pool", cfg.Component, poolPrefix(name), name, deb.Name)
+		log.WithField("package", deb.Name).Info("adding")
+		if err := st.Write(ctx, filename, data); err != nil {
+			return fmt.Errorf("failed to write %s: %w", filename, err)
+		}
+		h := hashesOf(data)
+		control.set("Filename", filename)
+		control.set("Size", fmt.Sprint(h.size))
+		control.set("MD5sum", h.md5)
+		control.set("SHA1", h.sha1)
+		control.set("SHA256", h.sha256)
+		indexes[arch] = mergePackages(indexes[arch], control)
+	}
+
+	info := releaseInfo{
+		Origin:       cfg.Origin,
+		Label:        cfg.Label,
+		Distribution: cfg.Distribution,
+		Component:    cfg.Component,
+		Description:  cfg.Description,
+		Date:         ctx.Date,
+		Files:        map[string]hashes{},
+	}
+	for arch, index := range indexes {
+		info.Architectures = append(info.Architectures, arch)
+		packages := []byte(renderPackages(index))
+		gz, err := gzipped(packages)
+		if err != nil {
+			return err
+		}
+		for name, data := range map[string][]byte{
+			packagesPath(cfg, arch):         packages,
+			packagesPath(cfg, arch) + ".gz": gz,
+		} {
+			if err := st.Write(ctx, name, data); err != nil {
+				return fmt.Errorf("failed to write %s: %w", name, err)
+			}
+			info.Files[strings.TrimPrefix(name, dist+"/")] = hashesOf(data)
+		}
+	}
+	sort.Strings(info.Architectures)
+
+	signed, err := sign(ctx, cfg, renderRelease(info))
+	if err != nil {
+		return err
+	}
+	for name, data := range signed {
+		if err := st.Write(ctx, name, data); err != nil {
+			return fmt.Errorf("failed to write %s: %w", name, err)
+		}
+	}
+
+	if err := st.Commit(ctx); err != nil {
+		return err
+	}
+	logInstructions(ctx, cfg)
+	return nil
+}
+
+// packagesPath returns the path of the Packages index of the given
+// architecture.
+func packagesPath(cfg config.APTRepository, arch string) string {
+	return path.Join("dists", cfg.Distribution, cfg.Component, "binary-"+arch, "Packages")
+}
+
+// poolPrefix returns the folder of the pool a package goes in, as debian
+// does: the first letter of its name, or the first 4 for libraries.
+func poolPrefix(name string) string {
+	if strings.HasPrefix(name, "lib") && len(name) > 3 {
+		return name[:4]
+	}
+	return name[:1]
+}
+
+// sign writes the given Release file to the dist folder, signs it with gpg
+// and returns the files to publish, by their path in the repository.
+func sign(ctx *context.Context, cfg config.APTRepository, release string) (map[string][]byte, error) {
+	key, err := tmpl.New(ctx).Apply(cfg.KeyID)
+	if err != nil {
+		return nil, err
+	}
+
+	dir := filepath.Join(ctx.Config.Dist, "apt", cfg.ID)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	releasePath := filepath.Join(dir, "Release")
+	if err := os.WriteFile(releasePath, []byte(release), 0o644); err != nil { //nolint: gosec
+		return nil, err
+	}
+
+	var user []string
+	if key != "" {
+		user = []string{"--local-user", key}
+	}
+	for _, args := range [][]string{
+		{"--armor", "--detach-sign", "--output", releasePath + ".gpg", releasePath},
+		{"--clearsign", "--output", filepath.Join(dir, "InRelease"), releasePath},
+	} {
+		if err := gpg(ctx, append(user, args...)...); err != nil {
+			return nil, err
+		}
+	}
+
+	dist := path.Join("dists", cfg.Distribution)
+	result := map[string][]byte{}
+	for name, file := range map[string]string{
+		path.Join(dist, "Release"):     releasePath,
+		path.Join(dist, "Release.gpg"): releasePath + ".gpg",
+		path.Join(dist, "InRelease"):   filepath.Join(dir, "InRelease"),
+	} {
+		bts, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		result[name] = bts
+	}
+
+	if key == "" {
+		return result, nil
+	}
+	// the public key, so users can add it to their keyrings.
+	pub := filepath.Join(dir, "key.gpg")
+	if err := gpg(ctx, "--armor", "--output", pub, "--export", key); err != nil {
+		return nil, err
+	}
+	bts, err := os.ReadFile(pub)
+	if err != nil {
+		return nil, err
+	}
+	result["key.gpg"] = bts
+	return result, nil
+}
+
+func gpg(ctx *context.Context, args ...string) error {
+	args = append([]string{"--batch", "--yes"}, args...)
+	/* #nosec */
+	cmd := exec.CommandContext(ctx, "gpg", args...)
+	cmd.Env = ctx.Env.Strings()
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to sign the repository: %w: %s", err, string(out))
+	}
+	return nil
+}
+
+// logInstructions logs how to install the packages from the repository, if
+// its url is known.
+func logInstructions(ctx *context.Context, cfg config.APTRepository) {
+	url := pkgrepo.InstallURL(ctx, cfg.URL)
+	if url == "" {
+		return
+	}
+	source := fmt.Sprintf("%s %s %s", url, cfg.Distribution, cfg.Component)
+	log.FromContext(ctx).Info("install with:")
+	if cfg.KeyID != "" {
+		keyring := "/etc/apt/keyrings/" + cfg.Name + ".asc"
+		log.FromContext(ctx).Infof("sudo curl -fsSL %s/key.gpg -o %s", url, keyring)
+		source = fmt.Sprintf("[signed-by=%s] %s", keyring, source)
+	}
+	log.FromContext(ctx).Infof(`echo "deb %s" | sudo tee /etc/apt/sources.list.d/%s.list`, source, cfg.Name)
+	log.FromContext(ctx).Info("sudo apt update && sudo apt install " + cfg.Name)
+}
@@ -0,0 +1,277 @@
+package apt
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/pipe"
+	"github.com/goreleaser/goreleaser/internal/testlib"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+	_ "gocloud.dev/blob/fileblob"
+)
+
+func TestDescription(t *testing.T) {
+	require.NotEmpty(t, Pipe{}.String())
+}
+
+func TestSkip(t *testing.T) {
+	require.True(t, Pipe{}.Skip(context.New(config.Project{})))
+	require.False(t, Pipe{}.Skip(context.New(config.Project{
+		APTRepositories: []config.APTRepository{{}},
+	})))
+}
+
+func TestDefault(t *testing.T) {
+	t.Run("blob", func(t *testing.T) {
+		ctx := context.New(config.Project{
+			ProjectName: "foo",
+			APTRepositories: []config.APTRepository{{
+				Blob: config.RepositoryBlob{Provider: "s3", Bucket: "bar"},
+			}},
+		})
+		require.NoError(t, Pipe{}.Default(ctx))
+		require.Equal(t, config.APTRepository{
+			ID:           "default",
+			Name:         "foo",
+			Distribution: "stable",
+			Component:    "main",
+			Origin:       "foo",
+			Label:        "foo",
+			Blob:         config.RepositoryBlob{Provider: "s3", Bucket: "bar"},
+		}, ctx.Config.APTRepositories[0])
+	})
+
+	t.Run("github pages", func(t *testing.T) {
+		ctx := context.New(config.Project{
+			ProjectName: "foo",
+			APTRepositories: []config.APTRepository{{
+				GitHubPages: config.RepoRef{Owner: "foo", Name: "bar"},
+			}},
+		})
+		require.NoError(t, Pipe{}.Default(ctx))
+		repo := ctx.Config.APTRepositories[0]
+		require.Equal(t, "gh-pages", repo.GitHubPages.Branch)
+		require.Equal(t, defaultCommitMessage, repo.CommitMessageTemplate)
+		require.NotEmpty(t, repo.CommitAuthor.Name)
+	})
+
+	for name, tt := range map[string]struct {
+		repos []config.APTRepository
+		err   string
+	}{
+		"no target": {
+			repos: []config.APTRepository{{}},
+			err:   "apt_repositories default: either blob or github_pages is required",
+		},
+		"both targets": {
+			repos: []config.APTRepository{{
+				Blob:        config.RepositoryBlob{Provider: "s3", Bucket: "bar"},
+				GitHubPages: config.RepoRef{Owner: "foo", Name: "bar"},
+			}},
+			err: "apt_repositories default: blob and github_pages can't be used together",
+		},
+		"invalid provider": {
+			repos: []config.APTRepository{{
+				Blob: config.RepositoryBlob{Bucket: "bar"},
+			}},
+			err: `apt_repositories default: invalid blob provider "", valid options are s3, gs and azblob`,
+		},
+		"missing repo name": {
+			repos: []config.APTRepository{{
+				GitHubPages: config.RepoRef{Owner: "foo"},
+			}},
+			err: "apt_repositories default: github_pages owner and name are required",
+		},
+		"duplicate ids": {
+			repos: []config.APTRepository{
+				{Blob: config.RepositoryBlob{Provider: "s3", Bucket: "bar"}},
+				{Blob: config.RepositoryBlob{Provider: "gs", Bucket: "bar"}},
+			},
+			err: "found 2 apt_repositories with the ID 'default', please fix your config",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.New(config.Project{
+				APTRepositories: tt.repos,
+			})
+			require.EqualError(t, Pipe{}.Default(ctx), tt.err)
+		})
+	}
+}
+
+func TestPublishBlob(t *testing.T) {
+	calls := testlib.FakeGPG(t)
+	bucket := t.TempDir()
+	cfg := config.APTRepository{
+		KeyID:       "ABC123",
+		Description: "the foo repository",
+		Blob:        config.RepositoryBlob{Provider: "s3", Bucket: bucket},
+		Folder:      "{{ .ProjectName }}",
+	}
+	ctx := newContext(t, cfg)
+	// fileblob is used instead of a real bucket.
+	ctx.Config.APTRepositories[0].Blob.Provider = "file"
+	addDeb(t, ctx, "foo", "1.0.0", "amd64")
+	addDeb(t, ctx, "foo", "1.0.0", "arm64")
+	require.NoError(t, Pipe{}.Publish(ctx))
+
+	repo := filepath.Join(bucket, "foo")
+	for _, name := range []string{
+		"pool/main/f/foo/foo_1.0.0_amd64.deb",
+		"pool/main/f/foo/foo_1.0.0_arm64.deb",
+		"dists/stable/main/binary-amd64/Packages.gz",
+		"dists/stable/main/binary-arm64/Packages.gz",
+		"dists/stable/Release.gpg",
+		"dists/stable/InRelease",
+		"key.gpg",
+	} {
+		require.FileExists(t, filepath.Join(repo, name))
+	}
+
+	packages := readParagraphs(t, filepath.Join(repo, "dists/stable/main/binary-amd64/Packages"))
+	require.Len(t, packages, 1)
+	require.Equal(t, "foo", packages[0].get("Package"))
+	require.Equal(t, "pool/main/f/foo/foo_1.0.0_amd64.deb", packages[0].get("Filename"))
+	require.Len(t, packages[0].get("SHA256"), 64)
+
+	release := readParagraphs(t, filepath.Join(repo, "dists/stable/Release"))
+	require.Equal(t, "amd64 arm64", release[0].get("Architectures"))
+	require.Equal(t, "the foo repository", release[0].get("Description"))
+	require.Contains(t, release[0].get("SHA256"), " main/binary-amd64/Packages.gz\n")
+
+	bts, err := os.ReadFile(calls)
+	require.NoError(t, err)
+	require.Contains(t, string(bts), "--batch --yes --local-user ABC123 --clearsign --output ")
+	require.Contains(t, string(bts), "--batch --yes --armor --output ")
+
+	// a new release keeps the packages already in the repository.
+	ctx = newContext(t, cfg)
+	ctx.Config.APTRepositories[0].Blob.Provider = "file"
+	addDeb(t, ctx, "foo", "1.1.0", "amd64")
+	require.NoError(t, Pipe{}.Publish(ctx))
+
+	packages = readParagraphs(t, filepath.Join(repo, "dists/stable/main/binary-amd64/Packages"))
+	require.Len(t, packages, 2)
+	require.Equal(t, "1.0.0", packages[0].get("Version"))
+	require.Equal(t, "1.1.0", packages[1].get("Version"))
+	require.Len(t, readParagraphs(t, filepath.Join(repo, "dists/stable/main/binary-arm64/Packages")), 1)
+	release = readParagraphs(t, filepath.Join(repo, "dists/stable/Release"))
+	require.Equal(t, "amd64 arm64", release[0].get("Architectures"))
+}
+
+func TestPublishGitHubPages(t *testing.T) {
+	testlib.FakeGPG(t)
+	remotes := t.TempDir()
+	remote := filepath.Join(remotes, "foo", "bar.git")
+	require.NoError(t, exec.Command("git", "init", "--bare", remote).Run())
+
+	cfg := config.APTRepository{
+		GitHubPages: config.RepoRef{Owner: "foo", Name: "bar"},
+		Folder:      "apt",
+	}
+	for _, version := range []string{"1.0.0", "1.1.0"} {
+		ctx := newContext(t, cfg)
+		ctx.Config.GitHubURLs.Download = "file://" + filepath.ToSlash(remotes)
+		ctx.Git.CurrentTag = "v" + version
+		addDeb(t, ctx, "foo", version, "amd64")
+		require.NoError(t, Pipe{}.Publish(ctx))
+	}
+
+	out, err := exec.Command("git", "--git-dir", remote, "log", "--format=%s", "gh-pages").CombinedOutput()
+	require.NoError(t, err, string(out))
+	require.Equal(t, "Update foo APT repository to v1.1.0\nUpdate foo APT repository to v1.0.0\n", string(out))
+
+	out, err = exec.Command("git", "--git-dir", remote, "show", "gh-pages:apt/dists/stable/main/binary-amd64/Packages").CombinedOutput()
+	require.NoError(t, err, string(out))
+	require.Len(t, parseParagraphs(string(out)), 2)
+}
+
+func TestPublishSkip(t *testing.T) {
+	t.Run("skip upload", func(t *testing.T) {
+		ctx := newContext(t, config.APTRepository{
+			SkipUpload: "true",
+			Blob:       config.RepositoryBlob{Provider: "s3", Bucket: "foo"},
+		})
+		require.True(t, pipe.IsSkip(Pipe{}.Publish(ctx)))
+	})
+
+	t.Run("auto", func(t *testing.T) {
+		ctx := newContext(t, config.APTRepository{
+			SkipUpload: "auto",
+			Blob:       config.RepositoryBlob{Provider: "s3", Bucket: "foo"},
+		})
+		ctx.Semver.Prerelease = "beta1"
+		require.True(t, pipe.IsSkip(Pipe{}.Publish(ctx)))
+	})
+
+	t.Run("no debs", func(t *testing.T) {
+		ctx := newContext(t, config.APTRepository{
+			Blob: config.RepositoryBlob{Provider: "s3", Bucket: "foo"},
+		})
+		ctx.Artifacts.Add(&artifact.Artifact{
+			Name: "foo.rpm",
+			Type: artifact.LinuxPackage,
+			Extra: map[string]interface{}{
+				artifact.ExtraFormat: "rpm",
+			},
+		})
+		require.EqualError(t, Pipe{}.Publish(ctx), "no deb packages found")
+	})
+}
+
+func TestPublishSignFails(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "gpg"), []byte("#!/bin/sh\necho no key\nexit 1\n"), 0o755))
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	ctx := newContext(t, config.APTRepository{
+		Blob: config.RepositoryBlob{Provider: "s3", Bucket: t.TempDir()},
+	})
+	ctx.Config.APTRepositories[0].Blob.Provider = "file"
+	addDeb(t, ctx, "foo", "1.0.0", "amd64")
+	err := Pipe{}.Publish(ctx)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to sign the repository")
+	require.Contains(t, err.Error(), "no key")
+}
+
+func newContext(tb testing.TB, repo config.APTRepository) *context.Context {
+	tb.Helper()
+	ctx := context.New(config.Project{
+		ProjectName:     "foo",
+		Dist:            tb.TempDir(),
+		APTRepositories: []config.APTRepository{repo},
+	})
+	ctx.Git.CurrentTag = "v1.0.0"
+	ctx.Date = time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)
+	require.NoError(tb, Pipe{}.Default(ctx))
+	return ctx
+}
+
+func addDeb(tb testing.TB, ctx *context.Context, name, version, arch string) {
+	tb.Helper()
+	path := createDeb(tb, ctx.Config.Dist, name, version, arch)
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name:   filepath.Base(path),
+		Path:   path,
+		Goos:   "linux",
+		Goarch: arch,
+		Type:   artifact.LinuxPackage,
+		Extra: map[string]interface{}{
+			artifact.ExtraID:     "default",
+			artifact.ExtraFormat: "deb",
+		},
+	})
+}
+
+func readParagraphs(tb testing.TB, path string) []paragraph {
+	tb.Helper()
+	bts, err := os.ReadFile(path)
+	require.NoError(tb, err)
+	return parseParagraphs(string(bts))
+}
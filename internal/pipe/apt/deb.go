@@ -0,0 +1,145 @@
+package apt
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/md5"  // #nosec
+	"crypto/sha1" // #nosec
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+)
+
+// readControl returns the fields of the control file of the given deb
+// package.
+func readControl(debPath string) (paragraph, error) {
+	f, err := os.Open(debPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	magic := make([]byte, 8)
+	if _, err := io.ReadFull(r, magic); err != nil || string(magic) != "!<arch>\n" {
+		return nil, fmt.Errorf("%s is not a deb package", debPath)
+	}
+
+	// deb packages are ar archives, with the control files in a tarball
+	// named control.tar with an optional compression extension.
+	header := make([]byte, 60)
+	for {
+		if _, err := io.ReadFull(r, header); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil, fmt.Errorf("%s has no control.tar", debPath)
+			}
+			return nil, fmt.Errorf("failed to read %s: %w", debPath, err)
+		}
+		name := strings.TrimSuffix(strings.TrimSpace(string(header[0:16])), "/")
+		size, err := strconv.ParseInt(strings.TrimSpace(string(header[48:58])), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: invalid ar header", debPath)
+		}
+		if !strings.HasPrefix(name, "control.tar") {
+			// entries are padded to an even size.
+			if _, err := io.CopyN(io.Discard, r, size+size%2); err != nil {
+				return nil, fmt.Errorf("failed to read %s: %w", debPath, err)
+			}
+			continue
+		}
+		control, err := decompress(io.LimitReader(r, size), strings.TrimPrefix(name, "control.tar"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", debPath, err)
+		}
+		return controlFromTar(control, debPath)
+	}
+}
+
+func decompress(r io.Reader, ext string) (io.Reader, error) {
+	switch ext {
+	case "":
+		return r, nil
+	case ".gz":
+		return gzip.NewReader(r)
+	case ".xz":
+		return xz.NewReader(r)
+	case ".zst":
+		return zstd.NewReader(r)
+	default:
+		return nil, fmt.Errorf("unsupported control.tar compression: %s", ext)
+	}
+}
+
+func controlFromTar(r io.Reader, debPath string) (paragraph, error) {
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("%s has no control file", debPath)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", debPath, err)
+		}
+		if path.Clean(hdr.Name) != "control" {
+			continue
+		}
+		bts, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", debPath, err)
+		}
+		paragraphs := parseParagraphs(string(bts))
+		if len(paragraphs) == 0 {
+			return nil, fmt.Errorf("%s has an empty control file", debPath)
+		}
+		return paragraphs[0], nil
+	}
+}
+
+// hashes of a file, as listed in the indexes.
+type hashes struct {
+	size   int64
+	md5    string
+	sha1   string
+	sha256 string
+}
+
+func hashesOf(data []byte) hashes {
+	// #nosec
+	md5sum := md5.Sum(data)
+	// #nosec
+	sha1sum := sha1.Sum(data)
+	sha256sum := sha256.Sum256(data)
+	return hashes{
+		size:   int64(len(data)),
+		md5:    hex.EncodeToString(md5sum[:]),
+		sha1:   hex.EncodeToString(sha1sum[:]),
+		sha256: hex.EncodeToString(sha256sum[:]),
+	}
+}
+
+// gzipped returns the given data compressed with gzip.
+func gzipped(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w, err := gzip.NewWriterLevel(&buf, gzip.BestCompression)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,52 @@
+package apt
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/goreleaser/nfpm/v2"
+	"github.com/goreleaser/nfpm/v2/deb"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadControl(t *testing.T) {
+	path := createDeb(t, t.TempDir(), "foo", "1.0.0", "amd64")
+	control, err := readControl(path)
+	require.NoError(t, err)
+	require.Equal(t, "foo", control.get("Package"))
+	require.Equal(t, "1.0.0", control.get("Version"))
+	require.Equal(t, "amd64", control.get("Architecture"))
+	require.Equal(t, "Foo <foo@example.com>", control.get("Maintainer"))
+}
+
+func TestReadControlInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "foo.deb")
+	require.NoError(t, os.WriteFile(path, []byte("not a deb"), 0o644))
+	_, err := readControl(path)
+	require.EqualError(t, err, path+" is not a deb package")
+
+	require.NoError(t, os.WriteFile(path, []byte("!<arch>\n"), 0o644))
+	_, err = readControl(path)
+	require.EqualError(t, err, path+" has no control.tar")
+
+	_, err = readControl(filepath.Join(t.TempDir(), "nope.deb"))
+	require.Error(t, err)
+}
+
+func createDeb(tb testing.TB, dir, name, version, arch string) string {
+	tb.Helper()
+	path := filepath.Join(dir, name+"_"+version+"_"+arch+".deb")
+	f, err := os.Create(path)
+	require.NoError(tb, err)
+	defer f.Close()
+	info := nfpm.WithDefaults(&nfpm.Info{
+		Name:        name,
+		Arch:        arch,
+		Version:     version,
+		Maintainer:  "Foo <foo@example.com>",
+		Description: "a " + name,
+	})
+	require.NoError(tb, deb.Default.Package(info, f))
+	return path
+}
@@ -0,0 +1,172 @@
+package apt
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// paragraph is a stanza of a debian control file, keeping the order of its
+// fields.
+type paragraph []field
+
+type field struct {
+	name  string
+	value string
+}
+
+// get returns the value of the given field, or an empty string.
+func (p paragraph) get(name string) string {
+	for _, f := range p {
+		if strings.EqualFold(f.name, name) {
+			return f.value
+		}
+	}
+	return ""
+}
+
+// set sets the value of the given field, adding it if needed.
+func (p *paragraph) set(name, value string) {
+	for i, f := range *p {
+		if strings.EqualFold(f.name, name) {
+			(*p)[i].value = value
+			return
+		}
+	}
+	*p = append(*p, field{name, value})
+}
+
+func (p paragraph) String() string {
+	var sb strings.Builder
+	for _, f := range p {
+		sb.WriteString(f.name + ":")
+		// multiline values have their continuation lines indented already.
+		if !strings.HasPrefix(f.value, "\n") {
+			sb.WriteString(" ")
+		}
+		sb.WriteString(f.value + "\n")
+	}
+	return sb.String()
+}
+
+// parseParagraphs parses the paragraphs of a control file, e.g. a Packages
+// index.
+func parseParagraphs(s string) []paragraph {
+	var result []paragraph
+	var current paragraph
+	for _, line := range strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n") {
+		switch {
+		case strings.TrimSpace(line) == "":
+			if len(current) > 0 {
+				result = append(result, current)
+				current = nil
+			}
+		case line[0] == ' ' || line[0] == '\t':
+			if len(current) > 0 {
+				current[len(current)-1].value += "\n" + line
+			}
+		default:
+			name, value, _ := cut(line, ":")
+			current = append(current, field{
+				name:  strings.TrimSpace(name),
+				value: strings.TrimSpace(value),
+			})
+		}
+	}
+	if len(current) > 0 {
+		result = append(result, current)
+	}
+	return result
+}
+
+// cut is strings.Cut, which needs Go 1.18.
+func cut(s, sep string) (string, string, bool) {
+	if i := strings.Index(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return s, "", false
+}
+
+// mergePackages adds the given packages to the index, replacing the ones with
+// the same name, version and architecture, and sorts it.
+func mergePackages(index []paragraph, added ...paragraph) []paragraph {
+	key := func(p paragraph) string {
+		return p.get("Package") + " " + p.get("Version") + " " + p.get("Architecture")
+	}
+	byKey := map[string]paragraph{}
+	for _, p := range index {
+		byKey[key(p)] = p
+	}
+	for _, p := range added {
+		byKey[key(p)] = p
+	}
+	result := make([]paragraph, 0, len(byKey))
+	for _, p := range byKey {
+		result = append(result, p)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return key(result[i]) < key(result[j])
+	})
+	return result
+}
+
+// renderPackages renders a Packages index.
+func renderPackages(index []paragraph) string {
+	parts := make([]string, 0, len(index))
+	for _, p := range index {
+		parts = append(parts, p.String())
+	}
+	return strings.Join(parts, "\n")
+}
+
+// releaseInfo holds the fields of a Release file.
+type releaseInfo struct {
+	Origin        string
+	Label         string
+	Distribution  string
+	Component     string
+	Description   string
+	Date          time.Time
+	Architectures []string
+	// Files are the hashes of the indexes, by their path relative to the
+	// distribution.
+	Files map[string]hashes
+}
+
+// renderRelease renders the Release file of a distribution.
+func renderRelease(info releaseInfo) string {
+	p := paragraph{}
+	p.set("Origin", info.Origin)
+	p.set("Label", info.Label)
+	p.set("Suite", info.Distribution)
+	p.set("Codename", info.Distribution)
+	p.set("Date", info.Date.UTC().Format(time.RFC1123))
+	p.set("Architectures", strings.Join(info.Architectures, " "))
+	p.set("Components", info.Component)
+	if info.Description != "" {
+		p.set("Description", info.Description)
+	}
+
+	names := make([]string, 0, len(info.Files))
+	for name := range info.Files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, sum := range []struct {
+		field string
+		hash  func(h hashes) string
+	}{
+		{"MD5Sum", func(h hashes) string { return h.md5 }},
+		{"SHA1", func(h hashes) string { return h.sha1 }},
+		{"SHA256", func(h hashes) string { return h.sha256 }},
+	} {
+		var sb strings.Builder
+		for _, name := range names {
+			h := info.Files[name]
+			sb.WriteString(fmt.Sprintf("\n %s %d %s", sum.hash(h), h.size, name))
+		}
+		p.set(sum.field, sb.String())
+	}
+	return p.String()
+}
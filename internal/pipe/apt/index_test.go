@@ -0,0 +1,102 @@
+package apt
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParagraphs(t *testing.T) {
+	content := `Package: foo
+Version: 1.0.0
+Architecture: amd64
+Description: a foo
+ that does foo things
+ .
+ and more
+
+Package: bar
+Version: 2.0.0
+Architecture: all
+`
+	paragraphs := parseParagraphs(content)
+	require.Len(t, paragraphs, 2)
+	require.Equal(t, "foo", paragraphs[0].get("Package"))
+	require.Equal(t, "1.0.0", paragraphs[0].get("version"))
+	require.Equal(t, "a foo\n that does foo things\n .\n and more", paragraphs[0].get("Description"))
+	require.Equal(t, "all", paragraphs[1].get("Architecture"))
+	require.Empty(t, paragraphs[1].get("Description"))
+	require.Equal(t, content, renderPackages(paragraphs))
+
+	paragraphs[1].set("Version", "2.0.1")
+	paragraphs[1].set("Size", "10")
+	require.Equal(t, "Package: bar\nVersion: 2.0.1\nArchitecture: all\nSize: 10\n", paragraphs[1].String())
+
+	require.Empty(t, parseParagraphs(""))
+}
+
+func TestMergePackages(t *testing.T) {
+	pkg := func(name, version, size string) paragraph {
+		return paragraph{
+			{"Package", name},
+			{"Version", version},
+			{"Architecture", "amd64"},
+			{"Size", size},
+		}
+	}
+	index := mergePackages(
+		[]paragraph{pkg("foo", "1.0.0", "1"), pkg("foo", "1.1.0", "1")},
+		pkg("foo", "1.1.0", "2"),
+		pkg("bar", "1.0.0", "3"),
+	)
+	require.Equal(t, []paragraph{
+		pkg("bar", "1.0.0", "3"),
+		pkg("foo", "1.0.0", "1"),
+		pkg("foo", "1.1.0", "2"),
+	}, index)
+}
+
+func TestRenderRelease(t *testing.T) {
+	release := renderRelease(releaseInfo{
+		Origin:        "foo",
+		Label:         "Foo",
+		Distribution:  "stable",
+		Component:     "main",
+		Description:   "the foo repository",
+		Date:          time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC),
+		Architectures: []string{"amd64", "arm64"},
+		Files: map[string]hashes{
+			"main/binary-arm64/Packages": {size: 20, md5: "md5b", sha1: "sha1b", sha256: "sha256b"},
+			"main/binary-amd64/Packages": {size: 10, md5: "md5a", sha1: "sha1a", sha256: "sha256a"},
+		},
+	})
+	require.Equal(t, `Origin: foo
+Label: Foo
+Suite: stable
+Codename: stable
+Date: Sun, 02 Jan 2022 03:04:05 UTC
+Architectures: amd64 arm64
+Components: main
+Description: the foo repository
+MD5Sum:
+ md5a 10 main/binary-amd64/Packages
+ md5b 20 main/binary-arm64/Packages
+SHA1:
+ sha1a 10 main/binary-amd64/Packages
+ sha1b 20 main/binary-arm64/Packages
+SHA256:
+ sha256a 10 main/binary-amd64/Packages
+ sha256b 20 main/binary-arm64/Packages
+`, release)
+
+	paragraphs := parseParagraphs(release)
+	require.Len(t, paragraphs, 1)
+	require.Equal(t, "amd64 arm64", paragraphs[0].get("Architectures"))
+}
+
+func TestPoolPrefix(t *testing.T) {
+	require.Equal(t, "f", poolPrefix("foo"))
+	require.Equal(t, "libf", poolPrefix("libfoo"))
+	require.Equal(t, "l", poolPrefix("lib"))
+}
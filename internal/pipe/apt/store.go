@@ -0,0 +1,159 @@
+package apt
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/commitauthor"
+	"github.com/goreleaser/goreleaser/internal/git"
+	"github.com/goreleaser/goreleaser/internal/pkgrepo"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+)
+
+// store is where the repository is published.
+type store interface {
+	// Read returns the content of the given file, or an error wrapping
+	// os.ErrNotExist if it does not exist yet.
+	Read(ctx *context.Context, name string) ([]byte, error)
+	Write(ctx *context.Context, name string, data []byte) error
+	// Commit publishes the written files.
+	Commit(ctx *context.Context) error
+	Close() error
+}
+
+// openStore opens the store of the given repository.
+func openStore(ctx *context.Context, cfg config.APTRepository) (store, error) {
+	if cfg.Blob.Bucket != "" {
+		st, err := pkgrepo.Open(ctx, cfg.Blob, cfg.Folder)
+		if err != nil {
+			return nil, err
+		}
+		return blobStore{st}, nil
+	}
+	folder, err := tmpl.New(ctx).Apply(cfg.Folder)
+	if err != nil {
+		return nil, err
+	}
+	return openGitStore(ctx, cfg, strings.Trim(folder, "/"))
+}
+
+// blobStore publishes the repository to a bucket, files are written right
+// away.
+type blobStore struct{ *pkgrepo.Store }
+
+func (blobStore) Commit(ctx *context.Context) error { return nil }
+
+// gitStore publishes the repository to a GitHub Pages branch.
+type gitStore struct {
+	dir    string
+	env    []string
+	folder string
+	branch string
+	msg    string
+}
+
+func openGitStore(ctx *context.Context, cfg config.APTRepository, folder string) (*gitStore, error) {
+	repo := cfg.GitHubPages
+	token, err := tmpl.New(ctx).Apply(repo.Token)
+	if err != nil {
+		return nil, err
+	}
+	if token == "" {
+		token = ctx.Token
+	}
+	remote, err := url.Parse(ctx.Config.GitHubURLs.Download)
+	if err != nil {
+		return nil, fmt.Errorf("invalid github download url: %w", err)
+	}
+	remote.Path = path.Join(remote.Path, repo.Owner, repo.Name+".git")
+	// the token is passed as a header, so it doesn't end up in the logs nor
+	// in the git config.
+	env := append(os.Environ(),
+		"GIT_CONFIG_COUNT=1",
+		"GIT_CONFIG_KEY_0=http.extraheader",
+		"GIT_CONFIG_VALUE_0=AUTHORIZATION: basic "+base64.StdEncoding.EncodeToString([]byte("x-access-token:"+token)),
+	)
+
+	msg, err := tmpl.New(ctx).Apply(cfg.CommitMessageTemplate)
+	if err != nil {
+		return nil, err
+	}
+	author, err := commitauthor.Get(ctx, cfg.CommitAuthor)
+	if err != nil {
+		return nil, err
+	}
+
+	dir := filepath.Join(ctx.Config.Dist, "apt", cfg.ID, "repo")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	cmds := [][]string{
+		{"init", "--quiet"},
+		{"remote", "add", "origin", remote.String()},
+	}
+	for _, kv := range commitauthor.GitConfig(author) {
+		cmds = append(cmds, append([]string{"config", "--local"}, kv...))
+	}
+	if err := runGitCmds(dir, env, cmds); err != nil {
+		return nil, fmt.Errorf("failed to setup local repository: %w", err)
+	}
+
+	log := log.FromContext(ctx).WithField("repo", repo.Owner+"/"+repo.Name).WithField("branch", repo.Branch)
+	if err := runGitCmds(dir, env, [][]string{
+		{"fetch", "--depth=1", "origin", repo.Branch},
+		{"checkout", "-b", repo.Branch, "FETCH_HEAD"},
+	}); err != nil {
+		log.WithError(err).Warn("could not fetch the branch, creating it")
+		if err := runGitCmds(dir, env, [][]string{{"checkout", "--orphan", repo.Branch}}); err != nil {
+			return nil, fmt.Errorf("failed to setup local repository: %w", err)
+		}
+	}
+	return &gitStore{dir: dir, env: env, folder: folder, branch: repo.Branch, msg: msg}, nil
+}
+
+func (s *gitStore) path(name string) string {
+	return filepath.Join(s.dir, filepath.FromSlash(s.folder), filepath.FromSlash(name))
+}
+
+func (s *gitStore) Read(ctx *context.Context, name string) ([]byte, error) {
+	return os.ReadFile(s.path(name))
+}
+
+func (s *gitStore) Write(ctx *context.Context, name string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(s.path(name)), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(s.path(name), data, 0o644) //nolint: gosec
+}
+
+func (s *gitStore) Commit(ctx *context.Context) error {
+	log.FromContext(ctx).WithField("branch", s.branch).Info("pushing")
+	if err := runGitCmds(s.dir, s.env, [][]string{
+		{"add", "-A", "."},
+		{"commit", "--quiet", "-m", s.msg},
+		{"push", "--quiet", "origin", "HEAD:" + s.branch},
+	}); err != nil {
+		return fmt.Errorf("failed to push the repository: %w", err)
+	}
+	return nil
+}
+
+func (s *gitStore) Close() error { return nil }
+
+func runGitCmds(cwd string, env []string, cmds [][]string) error {
+	for _, cmd := range cmds {
+		args := append([]string{"-C", cwd}, cmd...)
+		if _, err := git.Clean(git.RunWithEnv(env, args...)); err != nil {
+			return fmt.Errorf("%q failed: %w", strings.Join(cmd, " "), err)
+		}
+	}
+	return nil
+}
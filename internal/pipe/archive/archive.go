@@ -6,17 +6,23 @@ package archive
 import (
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"sort"
 	"strings"
 	"sync"
 
+	"filippo.io/age"
 	"github.com/apex/log"
 	"github.com/goreleaser/fileglob"
 	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/gio"
+	"github.com/goreleaser/goreleaser/internal/gitignore"
 	"github.com/goreleaser/goreleaser/internal/ids"
+	"github.com/goreleaser/goreleaser/internal/installfiles"
 	"github.com/goreleaser/goreleaser/internal/semerrgroup"
+	"github.com/goreleaser/goreleaser/internal/sharedlib"
 	"github.com/goreleaser/goreleaser/internal/tmpl"
 	"github.com/goreleaser/goreleaser/pkg/archive"
 	"github.com/goreleaser/goreleaser/pkg/config"
@@ -75,14 +81,100 @@ func (Pipe) Default(ctx *context.Context) error {
 		}
 		if len(archive.Builds) == 0 {
 			for _, build := range ctx.Config.Builds {
+				if build.Variant != nil {
+					continue
+				}
 				archive.Builds = append(archive.Builds, build.ID)
 			}
 		}
+		if archive.Encryption.Password != "" && len(archive.Encryption.Recipients) > 0 {
+			return fmt.Errorf("archive %s: %w", archive.ID, errPasswordAndRecipients)
+		}
+		if err := validateEncryption(*archive); err != nil {
+			return err
+		}
+		if err := validateReproducible(*archive); err != nil {
+			return err
+		}
+		switch archive.Symlinks {
+		case "":
+			archive.Symlinks = symlinksPreserve
+		case symlinksPreserve, symlinksFollow, symlinksReject:
+		default:
+			return fmt.Errorf("archive %s: invalid symlinks option %q, valid options are %s, %s and %s", archive.ID, archive.Symlinks, symlinksPreserve, symlinksFollow, symlinksReject)
+		}
+		if err := validateWrappers(*archive); err != nil {
+			return err
+		}
+		if archive.Split.Size != "" {
+			if _, err := parseSize(archive.Split.Size); err != nil {
+				return fmt.Errorf("archive %s: split: %w", archive.ID, err)
+			}
+		}
+		ids.Inc(archive.ID)
+	}
+	variants := variantArchives(ctx)
+	for _, archive := range variants {
 		ids.Inc(archive.ID)
 	}
+	ctx.Config.Archives = append(ctx.Config.Archives, variants...)
 	return ids.Validate()
 }
 
+// validateReproducible checks reproducible archives aren't created in formats
+// which can't be normalized.
+func validateReproducible(arch config.Archive) error {
+	if !arch.Reproducible {
+		return nil
+	}
+	formats := []string{arch.Format}
+	for _, override := range arch.FormatOverrides {
+		formats = append(formats, override.Format)
+	}
+	for _, format := range formats {
+		if format == "7z" || format == "squashfs" {
+			return fmt.Errorf("archive %s: reproducible can't be used with the %s format", arch.ID, format)
+		}
+	}
+	return nil
+}
+
+// variantArchives returns the separate archives of the build variants which
+// have them enabled, one for each archive of the build they are a variant of.
+func variantArchives(ctx *context.Context) []config.Archive {
+	var result []config.Archive
+	for _, archive := range ctx.Config.Archives {
+		for _, build := range ctx.Config.Builds {
+			if !contains(archive.Builds, build.ID) {
+				continue
+			}
+			for _, variant := range build.Variants {
+				if !variant.Archive.Enabled {
+					continue
+				}
+				va := archive
+				va.ID = archive.ID + "-" + variant.ID
+				va.Builds = []string{build.ID + "-" + variant.ID}
+				va.NameTemplate = variant.Archive.NameTemplate
+				if va.NameTemplate == "" {
+					va.NameTemplate = archive.NameTemplate + "_" + variant.ID
+				}
+				result = append(result, va)
+			}
+		}
+	}
+	return result
+}
+
+func contains(ss []string, s string) bool {
+	for _, v := range ss {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
 // Run the pipe.
 func (Pipe) Run(ctx *context.Context) error {
 	g := semerrgroup.New(ctx.Parallelism)
@@ -101,7 +193,7 @@ func (Pipe) Run(ctx *context.Context) error {
 			return fmt.Errorf("invalid archive: %d: %w", i, ErrArchiveDifferentBinaryCount)
 		}
 		for group, artifacts := range artifacts {
-			log.Debugf("group %s has %d binaries", group, len(artifacts))
+			log.FromContext(ctx).Debugf("group %s has %d binaries", group, len(artifacts))
 			artifacts := artifacts
 			g.Go(func() error {
 				if packageFormat(archive, artifacts[0].Goos) == "binary" {
@@ -125,15 +217,44 @@ func checkArtifacts(artifacts map[string][]*artifact.Artifact) error {
 	return ErrArchiveDifferentBinaryCount
 }
 
-func create(ctx *context.Context, arch config.Archive, binaries []*artifact.Artifact) error {
-	format := packageFormat(arch, binaries[0].Goos)
-	folder, err := tmpl.New(ctx).
-		WithArtifact(binaries[0], arch.Replacements).
+// archiveName returns the file name of the archive of the given binary's
+// platform, along with the password and recipients it is encrypted with.
+func archiveName(ctx *context.Context, arch config.Archive, binary *artifact.Artifact) (string, string, []age.Recipient, error) {
+	format := packageFormat(arch, binary.Goos)
+	template := tmpl.New(ctx).WithArtifact(binary, arch.Replacements)
+	folder, err := template.Apply(arch.NameTemplate)
+	if err != nil {
+		return "", "", nil, err
+	}
+	password, recipients, err := encryption(template, arch, format)
+	if err != nil {
+		return "", "", nil, fmt.Errorf("archive %s: %w", arch.ID, err)
+	}
+	name := folder + "." + format
+	if len(recipients) > 0 {
+		name += ageExtension
+	}
+	return name, password, recipients, nil
+}
+
+// unarchivedName returns the file name the given binary is released with
+// when it is not archived.
+func unarchivedName(ctx *context.Context, arch config.Archive, binary *artifact.Artifact) (string, error) {
+	name, err := tmpl.New(ctx).
+		WithArtifact(binary, arch.Replacements).
 		Apply(arch.NameTemplate)
+	if err != nil {
+		return "", err
+	}
+	return name + binary.ExtraOr(artifact.ExtraExt, "").(string), nil
+}
+
+func create(ctx *context.Context, arch config.Archive, binaries []*artifact.Artifact) error {
+	name, password, recipients, err := archiveName(ctx, arch, binaries[0])
 	if err != nil {
 		return err
 	}
-	archivePath := filepath.Join(ctx.Config.Dist, folder+"."+format)
+	archivePath := filepath.Join(ctx.Config.Dist, name)
 	lock.Lock()
 	if err := os.MkdirAll(filepath.Dir(archivePath), 0o755|os.ModeDir); err != nil {
 		lock.Unlock()
@@ -151,7 +272,7 @@ func create(ctx *context.Context, arch config.Archive, binaries []*artifact.Arti
 	lock.Unlock()
 	defer archiveFile.Close()
 
-	log := log.WithField("archive", archivePath)
+	log := log.FromContext(ctx).WithField("archive", archivePath)
 	log.Info("creating")
 
 	template := tmpl.New(ctx).
@@ -161,48 +282,249 @@ func create(ctx *context.Context, arch config.Archive, binaries []*artifact.Arti
 		return err
 	}
 
-	a := NewEnhancedArchive(archive.New(archiveFile), wrap)
+	var w io.Writer = archiveFile
+	var encrypted io.WriteCloser
+	if len(recipients) > 0 {
+		encrypted, err = age.Encrypt(archiveFile, recipients...)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt archive %s: %w", archivePath, err)
+		}
+		w = encrypted
+	}
+
+	a := NewEnhancedArchive(archive.NewWriter(w, strings.TrimSuffix(archivePath, ageExtension), archive.Options{
+		Compression:       arch.Compression,
+		Reproducible:      arch.Reproducible,
+		Password:          password,
+		PreserveHardlinks: arch.PreserveHardlinks,
+	}), wrap)
 	defer a.Close()
 
-	files, err := findFiles(template, arch.Files)
+	files, err := findFiles(ctx, template, arch.Files)
 	if err != nil {
 		return fmt.Errorf("failed to find files to archive: %w", err)
 	}
+	files, err = symlinks(arch, files)
+	if err != nil {
+		return err
+	}
+	installs, err := installfiles.Find(ctx)
+	if err != nil {
+		return err
+	}
+	for _, f := range installs {
+		files = append(files, config.File{
+			Source:      f.Source,
+			Destination: f.ArchivePath(),
+		})
+	}
+	if arch.Reproducible {
+		files, binaries = reproducible(ctx, files, binaries)
+	}
+	if arch.NormalizeModes {
+		files, err = normalizeModes(files)
+		if err != nil {
+			return err
+		}
+	}
 	for _, f := range files {
 		if err = a.Add(f); err != nil {
 			return fmt.Errorf("failed to add: '%s' -> '%s': %w", f.Source, f.Destination, err)
 		}
 	}
+	var libDir string
+	if arch.SharedLibrary.Enabled {
+		libDir, err = os.MkdirTemp("", "goreleaser-sharedlib")
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(libDir)
+	}
 	bins := []string{}
 	for _, binary := range binaries {
+		if arch.SharedLibrary.Enabled && sharedlib.IsShared(ctx, binary) {
+			libs, err := sharedlib.Files(ctx, arch.SharedLibrary, binary, "", libDir)
+			if err != nil {
+				return fmt.Errorf("archive %s: %w", arch.ID, err)
+			}
+			for _, lib := range libs {
+				if err := a.Add(config.File{
+					Source:      lib.Source,
+					Destination: lib.Destination,
+					Info:        binaryInfo(ctx, arch),
+				}); err != nil {
+					return fmt.Errorf("failed to add: '%s' -> '%s': %w", lib.Source, lib.Destination, err)
+				}
+			}
+			bins = append(bins, libs[0].Destination)
+			continue
+		}
+		name, err := binaryName(ctx, arch, binary)
+		if err != nil {
+			return err
+		}
 		if err := a.Add(config.File{
 			Source:      binary.Path,
-			Destination: binary.Name,
+			Destination: name,
+			Info:        binaryInfo(ctx, arch),
 		}); err != nil {
-			return fmt.Errorf("failed to add: '%s' -> '%s': %w", binary.Path, binary.Name, err)
+			return fmt.Errorf("failed to add: '%s' -> '%s': %w", binary.Path, name, err)
+		}
+		bins = append(bins, name)
+	}
+	if len(arch.Wrappers) > 0 {
+		dir, err := os.MkdirTemp("", "goreleaser-wrappers")
+		if err != nil {
+			return err
 		}
-		bins = append(bins, binary.Name)
+		defer os.RemoveAll(dir)
+		wrapped, err := wrappers(ctx, arch, binaries, bins, dir)
+		if err != nil {
+			return err
+		}
+		for _, f := range wrapped {
+			if err = a.Add(f); err != nil {
+				return fmt.Errorf("failed to add: '%s' -> '%s': %w", f.Source, f.Destination, err)
+			}
+		}
+	}
+	if err := a.Close(); err != nil {
+		return fmt.Errorf("failed to close archive %s: %w", archivePath, err)
+	}
+	if encrypted != nil {
+		if err := encrypted.Close(); err != nil {
+			return fmt.Errorf("failed to encrypt archive %s: %w", archivePath, err)
+		}
+	}
+	if err := archiveFile.Close(); err != nil {
+		return fmt.Errorf("failed to close archive %s: %w", archivePath, err)
+	}
+
+	extra := map[string]interface{}{
+		artifact.ExtraBuilds:    binaries,
+		artifact.ExtraID:        arch.ID,
+		artifact.ExtraFormat:    arch.Format,
+		artifact.ExtraWrappedIn: wrap,
+		artifact.ExtraBinaries:  bins,
+		artifact.ExtraReplaces:  binaries[0].Extra[artifact.ExtraReplaces],
+	}
+	if password != "" || len(recipients) > 0 {
+		extra[artifact.ExtraEncrypted] = true
+	}
+	if len(recipients) > 0 {
+		extra[artifact.ExtraFormat] = arch.Format + ageExtension
 	}
+
+	if arch.Split.Size != "" {
+		split, err := splitArchive(ctx, arch, name, archivePath, binaries[0], extra)
+		if err != nil || split {
+			return err
+		}
+	}
+
 	ctx.Artifacts.Add(&artifact.Artifact{
 		Type:   artifact.UploadableArchive,
-		Name:   folder + "." + format,
+		Name:   name,
 		Path:   archivePath,
 		Goos:   binaries[0].Goos,
 		Goarch: binaries[0].Goarch,
 		Goarm:  binaries[0].Goarm,
 		Gomips: binaries[0].Gomips,
-		Extra: map[string]interface{}{
-			artifact.ExtraBuilds:    binaries,
-			artifact.ExtraID:        arch.ID,
-			artifact.ExtraFormat:    arch.Format,
-			artifact.ExtraWrappedIn: wrap,
-			artifact.ExtraBinaries:  bins,
-			artifact.ExtraReplaces:  binaries[0].Extra[artifact.ExtraReplaces],
-		},
+		Extra:  extra,
 	})
 	return nil
 }
 
+// splitArchive splits the archive in parts if it is bigger than the
+// configured split size, adding the parts and their manifest to the
+// artifacts instead of the archive.
+// It returns whether the archive was split.
+func splitArchive(ctx *context.Context, arch config.Archive, name, archivePath string, binary *artifact.Artifact, extra map[string]interface{}) (bool, error) {
+	size, err := parseSize(arch.Split.Size)
+	if err != nil {
+		return false, fmt.Errorf("archive %s: split: %w", arch.ID, err)
+	}
+	info, err := os.Stat(archivePath)
+	if err != nil {
+		return false, err
+	}
+	if info.Size() <= size {
+		return false, nil
+	}
+
+	log.FromContext(ctx).WithField("archive", archivePath).
+		WithField("size", arch.Split.Size).
+		Info("splitting")
+	parts, manifest, err := split(archivePath, size)
+	if err != nil {
+		return false, fmt.Errorf("failed to split archive %s: %w", archivePath, err)
+	}
+	for _, path := range append(parts, manifest) {
+		ctx.Artifacts.Add(&artifact.Artifact{
+			Type:   artifact.UploadableArchivePart,
+			Name:   name + strings.TrimPrefix(path, archivePath),
+			Path:   path,
+			Goos:   binary.Goos,
+			Goarch: binary.Goarch,
+			Goarm:  binary.Goarm,
+			Gomips: binary.Gomips,
+			Extra:  extra,
+		})
+	}
+	return true, nil
+}
+
+// reproducible sets the modification time of all files to the commit date,
+// unless they already have one set, and sorts the binaries by name, so the
+// archive is the same regardless of the order they were built in.
+// The other files are already sorted by findFiles.
+func reproducible(ctx *context.Context, files []config.File, binaries []*artifact.Artifact) ([]config.File, []*artifact.Artifact) {
+	result := make([]config.File, 0, len(files))
+	for _, f := range files {
+		if f.Info.MTime.IsZero() {
+			f.Info.MTime = ctx.Git.CommitDate.UTC()
+		}
+		result = append(result, f)
+	}
+	sorted := make([]*artifact.Artifact, len(binaries))
+	copy(sorted, binaries)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Name < sorted[j].Name
+	})
+	return result, sorted
+}
+
+// normalizeModes sets the mode of the files without an explicit one to 0755
+// if they are executable, and to 0644 otherwise. Symlinks are left as is.
+func normalizeModes(files []config.File) ([]config.File, error) {
+	result := make([]config.File, 0, len(files))
+	for _, f := range files {
+		if f.Info.Mode == 0 {
+			info, err := os.Lstat(f.Source)
+			if err != nil {
+				return nil, fmt.Errorf("failed to normalize mode of %s: %w", f.Source, err)
+			}
+			if info.Mode()&os.ModeSymlink == 0 {
+				f.Info.Mode = gio.NormalizeMode(info.Mode())
+			}
+		}
+		result = append(result, f)
+	}
+	return result, nil
+}
+
+// binaryInfo is the file info of the binaries added to the given archive.
+func binaryInfo(ctx *context.Context, arch config.Archive) config.FileInfo {
+	info := arch.BuildsInfo
+	if arch.Reproducible && info.MTime.IsZero() {
+		info.MTime = ctx.Git.CommitDate.UTC()
+	}
+	if arch.NormalizeModes && info.Mode == 0 {
+		info.Mode = 0o755
+	}
+	return info
+}
+
 func wrapFolder(a config.Archive) string {
 	switch a.WrapInDirectory {
 	case "true":
@@ -216,14 +538,11 @@ func wrapFolder(a config.Archive) string {
 
 func skip(ctx *context.Context, archive config.Archive, binaries []*artifact.Artifact) error {
 	for _, binary := range binaries {
-		name, err := tmpl.New(ctx).
-			WithArtifact(binary, archive.Replacements).
-			Apply(archive.NameTemplate)
+		finalName, err := unarchivedName(ctx, archive, binary)
 		if err != nil {
 			return err
 		}
-		finalName := name + binary.ExtraOr(artifact.ExtraExt, "").(string)
-		log.WithField("binary", binary.Name).
+		log.FromContext(ctx).WithField("binary", binary.Name).
 			WithField("name", finalName).
 			Info("skip archiving")
 		ctx.Artifacts.Add(&artifact.Artifact{
@@ -246,7 +565,9 @@ func skip(ctx *context.Context, archive config.Archive, binaries []*artifact.Art
 	return nil
 }
 
-func findFiles(template *tmpl.Template, files []config.File) ([]config.File, error) {
+// findFiles resolves the given files in order. Files whose source starts with
+// `!` are gitignore-style patterns that exclude the files matched so far.
+func findFiles(ctx *context.Context, template *tmpl.Template, files []config.File) ([]config.File, error) {
 	var result []config.File
 	for _, f := range files {
 		replaced, err := template.Apply(f.Source)
@@ -254,6 +575,14 @@ func findFiles(template *tmpl.Template, files []config.File) ([]config.File, err
 			return result, fmt.Errorf("failed to apply template %s: %w", f.Source, err)
 		}
 
+		if strings.HasPrefix(replaced, "!") {
+			result, err = excludeFiles(ctx, result, replaced[1:])
+			if err != nil {
+				return result, err
+			}
+			continue
+		}
+
 		files, err := fileglob.Glob(replaced)
 		if err != nil {
 			return result, fmt.Errorf("globbing failed for pattern %s: %w", f.Source, err)
@@ -272,16 +601,33 @@ func findFiles(template *tmpl.Template, files []config.File) ([]config.File, err
 		return result[i].Destination < result[j].Destination
 	})
 
-	return unique(result), nil
+	return unique(ctx, result), nil
+}
+
+// excludeFiles removes the files matching the given gitignore-style pattern.
+func excludeFiles(ctx *context.Context, files []config.File, pattern string) ([]config.File, error) {
+	matcher, err := gitignore.New(pattern)
+	if err != nil {
+		return files, fmt.Errorf("invalid exclude pattern %s: %w", pattern, err)
+	}
+	var result []config.File
+	for _, f := range files {
+		if matcher.Match(f.Source, false) {
+			log.FromContext(ctx).WithField("file", f.Source).Debug("excluded from archive")
+			continue
+		}
+		result = append(result, f)
+	}
+	return result, nil
 }
 
 // remove duplicates
-func unique(in []config.File) []config.File {
+func unique(ctx *context.Context, in []config.File) []config.File {
 	var result []config.File
 	exist := map[string]string{}
 	for _, f := range in {
 		if current := exist[f.Destination]; current != "" {
-			log.Warnf(
+			log.FromContext(ctx).Warnf(
 				"file '%s' already exists in archive as '%s' - '%s' will be ignored",
 				f.Destination,
 				current,
@@ -11,6 +11,7 @@ import (
 	"testing"
 	"time"
 
+	"filippo.io/age"
 	"github.com/goreleaser/goreleaser/internal/artifact"
 	"github.com/goreleaser/goreleaser/internal/testlib"
 	"github.com/goreleaser/goreleaser/internal/tmpl"
@@ -652,6 +653,400 @@ func TestRunPipeWrap(t *testing.T) {
 	}
 }
 
+func TestRunPipeReproducible(t *testing.T) {
+	for _, format := range []string{"tar.gz", "tar.xz", "tar.zst", "zip"} {
+		format := format
+		t.Run(format, func(t *testing.T) {
+			folder := testlib.Mktmp(t)
+			require.NoError(t, os.WriteFile(filepath.Join(folder, "README.md"), []byte("readme"), 0o644))
+			require.NoError(t, os.Mkdir(filepath.Join(folder, "bin"), 0o755))
+			for _, name := range []string{"a", "b"} {
+				require.NoError(t, os.WriteFile(filepath.Join(folder, "bin", name), []byte(name), 0o755))
+			}
+			commitDate := time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+
+			run := func(order []string) []byte {
+				dist := filepath.Join(folder, "dist")
+				require.NoError(t, os.RemoveAll(dist))
+				require.NoError(t, os.Mkdir(dist, 0o755))
+				ctx := context.New(config.Project{
+					Dist: dist,
+					Archives: []config.Archive{
+						{
+							Builds:       []string{"default"},
+							NameTemplate: "foo",
+							Format:       format,
+							Reproducible: true,
+							Files: []config.File{
+								{Source: "README.*"},
+							},
+						},
+					},
+				})
+				ctx.Git.CurrentTag = "v0.0.1"
+				ctx.Git.CommitDate = commitDate
+				for _, name := range order {
+					ctx.Artifacts.Add(&artifact.Artifact{
+						Goos:   "linux",
+						Goarch: "amd64",
+						Name:   name,
+						Path:   filepath.Join(folder, "bin", name),
+						Type:   artifact.Binary,
+						Extra: map[string]interface{}{
+							artifact.ExtraBinary: name,
+							artifact.ExtraID:     "default",
+						},
+					})
+				}
+				require.NoError(t, Pipe{}.Run(ctx))
+				bts, err := os.ReadFile(filepath.Join(dist, "foo."+format))
+				require.NoError(t, err)
+				return bts
+			}
+
+			first := run([]string{"a", "b"})
+
+			// touch all files and build the binaries in another order
+			later := time.Now().Add(time.Hour)
+			for _, path := range []string{"README.md", "bin/a", "bin/b"} {
+				require.NoError(t, os.Chtimes(filepath.Join(folder, path), later, later))
+			}
+			second := run([]string{"b", "a"})
+
+			require.Equal(t, first, second)
+		})
+	}
+}
+
+func TestRunPipeReproducibleContents(t *testing.T) {
+	folder := testlib.Mktmp(t)
+	dist := filepath.Join(folder, "dist")
+	require.NoError(t, os.Mkdir(dist, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(folder, "bin"), []byte("bin"), 0o755))
+	commitDate := time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+	ctx := context.New(config.Project{
+		Dist: dist,
+		Archives: []config.Archive{
+			{
+				Builds:       []string{"default"},
+				NameTemplate: "foo",
+				Format:       "tar.gz",
+				Reproducible: true,
+				Files:        []config.File{},
+			},
+		},
+	})
+	ctx.Git.CurrentTag = "v0.0.1"
+	ctx.Git.CommitDate = commitDate
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Goos:   "linux",
+		Goarch: "amd64",
+		Name:   "bin",
+		Path:   filepath.Join(folder, "bin"),
+		Type:   artifact.Binary,
+		Extra: map[string]interface{}{
+			artifact.ExtraBinary: "bin",
+			artifact.ExtraID:     "default",
+		},
+	})
+	require.NoError(t, Pipe{}.Run(ctx))
+
+	f, err := os.Open(filepath.Join(dist, "foo.tar.gz"))
+	require.NoError(t, err)
+	defer func() { require.NoError(t, f.Close()) }()
+	gr, err := gzip.NewReader(f)
+	require.NoError(t, err)
+	defer func() { require.NoError(t, gr.Close()) }()
+	h, err := tar.NewReader(gr).Next()
+	require.NoError(t, err)
+	require.Equal(t, "bin", h.Name)
+	require.True(t, commitDate.Equal(h.ModTime))
+	require.Equal(t, 0, h.Uid)
+	require.Equal(t, 0, h.Gid)
+	require.Empty(t, h.Uname)
+	require.Empty(t, h.Gname)
+}
+
+func TestRunPipeNormalizeModes(t *testing.T) {
+	folder := testlib.Mktmp(t)
+	dist := filepath.Join(folder, "dist")
+	require.NoError(t, os.Mkdir(dist, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(folder, "bin"), []byte("bin"), 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(folder, "config.yaml"), []byte("config"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(folder, "install.sh"), []byte("script"), 0o770))
+	require.NoError(t, os.WriteFile(filepath.Join(folder, "secret.txt"), []byte("secret"), 0o600))
+	// umask may have changed the modes above.
+	for name, mode := range map[string]os.FileMode{"bin": 0o700, "config.yaml": 0o600, "install.sh": 0o770, "secret.txt": 0o600} {
+		require.NoError(t, os.Chmod(filepath.Join(folder, name), mode))
+	}
+	ctx := context.New(config.Project{
+		Dist: dist,
+		Archives: []config.Archive{
+			{
+				Builds:         []string{"default"},
+				NameTemplate:   "foo",
+				Format:         "tar.gz",
+				NormalizeModes: true,
+				BuildsInfo: config.FileInfo{
+					Owner: "root",
+					Group: "wheel",
+				},
+				Files: []config.File{
+					{Source: "config.yaml"},
+					{Source: "install.sh"},
+					{Source: "secret.txt", Info: config.FileInfo{Mode: 0o600}},
+				},
+			},
+		},
+	})
+	ctx.Git.CurrentTag = "v0.0.1"
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Goos:   "linux",
+		Goarch: "amd64",
+		Name:   "bin",
+		Path:   filepath.Join(folder, "bin"),
+		Type:   artifact.Binary,
+		Extra: map[string]interface{}{
+			artifact.ExtraBinary: "bin",
+			artifact.ExtraID:     "default",
+		},
+	})
+	require.NoError(t, Pipe{}.Run(ctx))
+
+	f, err := os.Open(filepath.Join(dist, "foo.tar.gz"))
+	require.NoError(t, err)
+	defer func() { require.NoError(t, f.Close()) }()
+	gr, err := gzip.NewReader(f)
+	require.NoError(t, err)
+	defer func() { require.NoError(t, gr.Close()) }()
+	r := tar.NewReader(gr)
+	modes := map[string]int64{}
+	for {
+		h, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		modes[h.Name] = h.Mode
+		if h.Name == "bin" {
+			require.Equal(t, "root", h.Uname)
+			require.Equal(t, "wheel", h.Gname)
+		}
+	}
+	require.Equal(t, map[string]int64{
+		"bin":         0o755,
+		"config.yaml": 0o644,
+		"install.sh":  0o755,
+		"secret.txt":  0o600,
+	}, modes)
+}
+
+func TestRunPipeSplit(t *testing.T) {
+	folder := testlib.Mktmp(t)
+	dist := filepath.Join(folder, "dist")
+	require.NoError(t, os.Mkdir(dist, 0o755))
+	bts := make([]byte, 4096)
+	for i := range bts {
+		bts[i] = byte(i * 7 % 251)
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(folder, "big"), bts, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(folder, "small"), []byte("small"), 0o755))
+
+	ctx := context.New(config.Project{
+		Dist: dist,
+		Archives: []config.Archive{
+			{
+				Builds:       []string{"default"},
+				NameTemplate: "{{ .Os }}",
+				Format:       "tar",
+				Split: config.ArchiveSplit{
+					Size: "2KiB",
+				},
+			},
+		},
+	})
+	ctx.Git.CurrentTag = "v0.0.1"
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Goos:   "linux",
+		Goarch: "amd64",
+		Name:   "big",
+		Path:   filepath.Join(folder, "big"),
+		Type:   artifact.Binary,
+		Extra: map[string]interface{}{
+			artifact.ExtraBinary: "big",
+			artifact.ExtraID:     "default",
+		},
+	})
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Goos:   "darwin",
+		Goarch: "amd64",
+		Name:   "small",
+		Path:   filepath.Join(folder, "small"),
+		Type:   artifact.Binary,
+		Extra: map[string]interface{}{
+			artifact.ExtraBinary: "small",
+			artifact.ExtraID:     "default",
+		},
+	})
+	require.NoError(t, Pipe{}.Run(ctx))
+
+	archives := ctx.Artifacts.Filter(artifact.ByType(artifact.UploadableArchive)).List()
+	require.Len(t, archives, 1)
+	require.Equal(t, "darwin.tar", archives[0].Name)
+
+	var names []string
+	for _, part := range ctx.Artifacts.Filter(artifact.ByType(artifact.UploadableArchivePart)).List() {
+		names = append(names, part.Name)
+		require.Equal(t, "linux", part.Goos)
+		require.Equal(t, "tar", part.Format())
+		require.FileExists(t, part.Path)
+	}
+	require.Equal(t, []string{"linux.tar.001", "linux.tar.002", "linux.tar.003", "linux.tar.parts.json"}, names)
+	require.NoFileExists(t, filepath.Join(dist, "linux.tar"))
+}
+
+func TestRunPipeEncrypted(t *testing.T) {
+	identity, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+
+	folder := testlib.Mktmp(t)
+	dist := filepath.Join(folder, "dist")
+	require.NoError(t, os.Mkdir(dist, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(folder, "bin"), []byte("bin"), 0o755))
+
+	ctx := context.New(config.Project{
+		Dist: dist,
+		Archives: []config.Archive{
+			{
+				Builds:       []string{"default"},
+				NameTemplate: "foo",
+				Format:       "tar.gz",
+				Encryption: config.ArchiveEncryption{
+					Recipients: []string{"{{ .Env.RECIPIENT }}"},
+				},
+			},
+		},
+	})
+	ctx.Env["RECIPIENT"] = identity.Recipient().String()
+	ctx.Git.CurrentTag = "v0.0.1"
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Goos:   "linux",
+		Goarch: "amd64",
+		Name:   "bin",
+		Path:   filepath.Join(folder, "bin"),
+		Type:   artifact.Binary,
+		Extra: map[string]interface{}{
+			artifact.ExtraBinary: "bin",
+			artifact.ExtraID:     "default",
+		},
+	})
+	require.NoError(t, Pipe{}.Run(ctx))
+
+	archives := ctx.Artifacts.Filter(artifact.ByType(artifact.UploadableArchive)).List()
+	require.Len(t, archives, 1)
+	require.Equal(t, "foo.tar.gz.age", archives[0].Name)
+	require.Equal(t, "tar.gz.age", archives[0].Format())
+	require.False(t, artifact.OnlyUnencrypted(archives[0]))
+
+	f, err := os.Open(filepath.Join(dist, "foo.tar.gz.age"))
+	require.NoError(t, err)
+	defer f.Close()
+	r, err := age.Decrypt(f, identity)
+	require.NoError(t, err)
+	gr, err := gzip.NewReader(r)
+	require.NoError(t, err)
+	h, err := tar.NewReader(gr).Next()
+	require.NoError(t, err)
+	require.Equal(t, "bin", h.Name)
+}
+
+func TestDefaultPasswordAndRecipients(t *testing.T) {
+	ctx := context.New(config.Project{
+		Archives: []config.Archive{
+			{
+				Encryption: config.ArchiveEncryption{
+					Password:   "secret",
+					Recipients: []string{"age1foo"},
+				},
+			},
+		},
+	})
+	require.EqualError(t, Pipe{}.Default(ctx), "archive default: encryption: password and recipients can't be used together")
+}
+
+func TestDefaultEncryptedBinary(t *testing.T) {
+	for name, archive := range map[string]config.Archive{
+		"format": {
+			Format:     "binary",
+			Encryption: config.ArchiveEncryption{Password: "secret"},
+		},
+		"override": {
+			Format:          "tar.gz",
+			FormatOverrides: []config.FormatOverride{{Goos: "windows", Format: "binary"}},
+			Encryption:      config.ArchiveEncryption{Recipients: []string{"age1foo"}},
+		},
+	} {
+		archive := archive
+		t.Run(name, func(t *testing.T) {
+			ctx := context.New(config.Project{Archives: []config.Archive{archive}})
+			require.EqualError(t, Pipe{}.Default(ctx), "archive default: encryption can't be used with the binary format")
+		})
+	}
+}
+
+func TestDefaultReproducibleUnsupportedFormat(t *testing.T) {
+	for name, tt := range map[string]struct {
+		archive config.Archive
+		format  string
+	}{
+		"7z": {
+			archive: config.Archive{Format: "7z", Reproducible: true},
+			format:  "7z",
+		},
+		"squashfs override": {
+			archive: config.Archive{
+				Format:          "tar.gz",
+				FormatOverrides: []config.FormatOverride{{Goos: "linux", Format: "squashfs"}},
+				Reproducible:    true,
+			},
+			format: "squashfs",
+		},
+	} {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			ctx := context.New(config.Project{Archives: []config.Archive{tt.archive}})
+			require.EqualError(t, Pipe{}.Default(ctx), "archive default: reproducible can't be used with the "+tt.format+" format")
+		})
+	}
+
+	t.Run("zip", func(t *testing.T) {
+		ctx := context.New(config.Project{Archives: []config.Archive{{Format: "zip", Reproducible: true}}})
+		require.NoError(t, Pipe{}.Default(ctx))
+	})
+}
+
+func TestDefaultInvalidSymlinks(t *testing.T) {
+	ctx := context.New(config.Project{
+		Archives: []config.Archive{
+			{Symlinks: "copy"},
+		},
+	})
+	require.EqualError(t, Pipe{}.Default(ctx), `archive default: invalid symlinks option "copy", valid options are preserve, follow and reject`)
+}
+
+func TestDefaultInvalidSplitSize(t *testing.T) {
+	ctx := context.New(config.Project{
+		Archives: []config.Archive{
+			{
+				Split: config.ArchiveSplit{
+					Size: "2 bananas",
+				},
+			},
+		},
+	})
+	require.EqualError(t, Pipe{}.Default(ctx), `archive default: split: invalid size "2 bananas": unknown unit "bananas"`)
+}
+
 func TestDefault(t *testing.T) {
 	ctx := &context.Context{
 		Config: config.Project{
@@ -664,6 +1059,43 @@ func TestDefault(t *testing.T) {
 	require.NotEmpty(t, ctx.Config.Archives[0].Files)
 }
 
+func TestDefaultVariants(t *testing.T) {
+	ctx := context.New(config.Project{
+		Builds: []config.Build{
+			{
+				ID: "foo",
+				Variants: []config.BuildVariant{
+					{ID: "race", Archive: config.BuildVariantArchive{Enabled: true}},
+					{ID: "debug", Archive: config.BuildVariantArchive{Enabled: true, NameTemplate: "{{ .ProjectName }}_debug"}},
+					{ID: "noarchive"},
+				},
+			},
+			{ID: "foo-race", Variant: &config.BuildVariant{ID: "race"}},
+			{ID: "foo-debug", Variant: &config.BuildVariant{ID: "debug"}},
+			{ID: "foo-noarchive", Variant: &config.BuildVariant{ID: "noarchive"}},
+			{ID: "bar"},
+		},
+		Archives: []config.Archive{
+			{},
+			{ID: "bar", Builds: []string{"bar"}},
+		},
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.Len(t, ctx.Config.Archives, 4)
+	require.Equal(t, []string{"foo", "bar"}, ctx.Config.Archives[0].Builds)
+
+	race := ctx.Config.Archives[2]
+	require.Equal(t, "default-race", race.ID)
+	require.Equal(t, []string{"foo-race"}, race.Builds)
+	require.Equal(t, defaultNameTemplate+"_race", race.NameTemplate)
+	require.Equal(t, "tar.gz", race.Format)
+
+	debug := ctx.Config.Archives[3]
+	require.Equal(t, "default-debug", debug.ID)
+	require.Equal(t, []string{"foo-debug"}, debug.Builds)
+	require.Equal(t, "{{ .ProjectName }}_debug", debug.NameTemplate)
+}
+
 func TestDefaultSet(t *testing.T) {
 	ctx := &context.Context{
 		Config: config.Project{
@@ -925,10 +1357,11 @@ func TestSeveralArchivesWithTheSameID(t *testing.T) {
 
 func TestFindFiles(t *testing.T) {
 	now := time.Now().Truncate(time.Second)
-	tmpl := tmpl.New(context.New(config.Project{}))
+	ctx := context.New(config.Project{})
+	tmpl := tmpl.New(ctx)
 
 	t.Run("single file", func(t *testing.T) {
-		result, err := findFiles(tmpl, []config.File{
+		result, err := findFiles(ctx, tmpl, []config.File{
 			{
 				Source:      "./testdata/**/d.txt",
 				Destination: "var/foobar/d.txt",
@@ -945,7 +1378,7 @@ func TestFindFiles(t *testing.T) {
 	})
 
 	t.Run("match multiple files within tree without destination", func(t *testing.T) {
-		result, err := findFiles(tmpl, []config.File{{Source: "./testdata/a"}})
+		result, err := findFiles(ctx, tmpl, []config.File{{Source: "./testdata/a"}})
 
 		require.NoError(t, err)
 		require.Equal(t, []config.File{
@@ -956,7 +1389,7 @@ func TestFindFiles(t *testing.T) {
 	})
 
 	t.Run("match multiple files within tree specific destination", func(t *testing.T) {
-		result, err := findFiles(tmpl, []config.File{
+		result, err := findFiles(ctx, tmpl, []config.File{
 			{
 				Source:      "./testdata/a",
 				Destination: "usr/local/test",
@@ -1005,7 +1438,7 @@ func TestFindFiles(t *testing.T) {
 	})
 
 	t.Run("match multiple files within tree specific destination stripping parents", func(t *testing.T) {
-		result, err := findFiles(tmpl, []config.File{
+		result, err := findFiles(ctx, tmpl, []config.File{
 			{
 				Source:      "./testdata/a",
 				Destination: "usr/local/test",
@@ -1043,6 +1476,41 @@ func TestFindFiles(t *testing.T) {
 			},
 		}, result)
 	})
+
+	t.Run("exclude nested files", func(t *testing.T) {
+		result, err := findFiles(ctx, tmpl, []config.File{
+			{Source: "./testdata/a"},
+			{Source: "!c/"},
+		})
+
+		require.NoError(t, err)
+		require.Equal(t, []config.File{
+			{Source: "testdata/a/a.txt", Destination: "testdata/a/a.txt"},
+			{Source: "testdata/a/b/a.txt", Destination: "testdata/a/b/a.txt"},
+		}, result)
+	})
+
+	t.Run("exclude and include again", func(t *testing.T) {
+		result, err := findFiles(ctx, tmpl, []config.File{
+			{Source: "./testdata/a"},
+			{Source: "!*.txt"},
+			{Source: "./testdata/**/d.txt"},
+		})
+
+		require.NoError(t, err)
+		require.Equal(t, []config.File{
+			{Source: "testdata/a/b/c/d.txt", Destination: "testdata/a/b/c/d.txt"},
+		}, result)
+	})
+
+	t.Run("invalid exclude pattern", func(t *testing.T) {
+		_, err := findFiles(ctx, tmpl, []config.File{
+			{Source: "./testdata/a"},
+			{Source: "![z-a]"},
+		})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid exclude pattern [z-a]")
+	})
 }
 
 func TestArchive_globbing(t *testing.T) {
@@ -1127,3 +1595,234 @@ func TestArchive_globbing(t *testing.T) {
 		})
 	})
 }
+
+func TestRunPipeBinaryNameAndWrappers(t *testing.T) {
+	folder := testlib.Mktmp(t)
+	dist := filepath.Join(folder, "dist")
+	require.NoError(t, os.Mkdir(dist, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(folder, "mybin_1.0.0"), []byte("bin"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(folder, "mybin_1.0.0.exe"), []byte("bin"), 0o755))
+
+	ctx := context.New(config.Project{
+		Dist: dist,
+		Archives: []config.Archive{
+			{
+				Builds:             []string{"default"},
+				NameTemplate:       "foo_{{ .Os }}",
+				Format:             "zip",
+				BinaryNameTemplate: `bin/{{ trimsuffix .Binary (print "_" .Version) }}-real`,
+				Wrappers: []config.ArchiveWrapper{
+					{
+						NameTemplate: "mybin",
+						Env:          []string{"MYBIN_OS={{ .Os }}"},
+					},
+				},
+			},
+		},
+	})
+	ctx.Git.CurrentTag = "v1.0.0"
+	ctx.Version = "1.0.0"
+	for _, goos := range []string{"linux", "windows"} {
+		ext := ""
+		if goos == "windows" {
+			ext = ".exe"
+		}
+		ctx.Artifacts.Add(&artifact.Artifact{
+			Goos:   goos,
+			Goarch: "amd64",
+			Name:   "mybin_1.0.0" + ext,
+			Path:   filepath.Join(folder, "mybin_1.0.0"+ext),
+			Type:   artifact.Binary,
+			Extra: map[string]interface{}{
+				artifact.ExtraBinary: "mybin_1.0.0",
+				artifact.ExtraExt:    ext,
+				artifact.ExtraID:     "default",
+			},
+		})
+	}
+	require.NoError(t, Pipe{}.Run(ctx))
+
+	require.ElementsMatch(t, []string{"bin/mybin-real", "mybin"}, zipFiles(t, filepath.Join(dist, "foo_linux.zip")))
+	require.ElementsMatch(t, []string{"bin/mybin-real.exe", "mybin.bat"}, zipFiles(t, filepath.Join(dist, "foo_windows.zip")))
+
+	archives := ctx.Artifacts.Filter(artifact.And(
+		artifact.ByType(artifact.UploadableArchive),
+		artifact.ByGoos("linux"),
+	)).List()
+	require.Len(t, archives, 1)
+	require.Equal(t, []string{"bin/mybin-real"}, archives[0].ExtraOr(artifact.ExtraBinaries, nil))
+
+	f, err := os.Open(filepath.Join(dist, "foo_linux.zip"))
+	require.NoError(t, err)
+	defer f.Close()
+	info, err := f.Stat()
+	require.NoError(t, err)
+	r, err := zip.NewReader(f, info.Size())
+	require.NoError(t, err)
+	for _, zf := range r.File {
+		if zf.Name != "mybin" {
+			continue
+		}
+		require.Equal(t, "-rwxr-xr-x", zf.Mode().String())
+		rc, err := zf.Open()
+		require.NoError(t, err)
+		bts, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		require.NoError(t, rc.Close())
+		require.Equal(t, "#!/bin/sh\nexport MYBIN_OS='linux'\nexec \"$(dirname \"$0\")/bin/mybin-real\" \"$@\"\n", string(bts))
+	}
+}
+
+func TestRunPipeWrapperInvalidBinary(t *testing.T) {
+	folder := testlib.Mktmp(t)
+	dist := filepath.Join(folder, "dist")
+	require.NoError(t, os.Mkdir(dist, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(folder, "bin"), []byte("bin"), 0o755))
+
+	ctx := context.New(config.Project{
+		Dist: dist,
+		Archives: []config.Archive{
+			{
+				ID:           "default",
+				Builds:       []string{"default"},
+				NameTemplate: "foo",
+				Format:       "tar.gz",
+				Wrappers: []config.ArchiveWrapper{
+					{NameTemplate: "run", Binary: "nope"},
+				},
+			},
+		},
+	})
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Goos:   "linux",
+		Goarch: "amd64",
+		Name:   "bin",
+		Path:   filepath.Join(folder, "bin"),
+		Type:   artifact.Binary,
+		Extra: map[string]interface{}{
+			artifact.ExtraBinary: "bin",
+			artifact.ExtraID:     "default",
+		},
+	})
+	require.EqualError(t, Pipe{}.Run(ctx), `archive default: wrapper 0: binary "nope" not found in the archive`)
+}
+
+func TestDefaultInvalidWrappers(t *testing.T) {
+	for name, tt := range map[string]struct {
+		wrapper config.ArchiveWrapper
+		err     string
+	}{
+		"no name": {
+			wrapper: config.ArchiveWrapper{Type: "sh"},
+			err:     "archive default: wrapper 0: name_template is required",
+		},
+		"invalid type": {
+			wrapper: config.ArchiveWrapper{NameTemplate: "run", Type: "ps1"},
+			err:     `archive default: wrapper 0: invalid type "ps1", valid types are sh and bat`,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.New(config.Project{
+				Archives: []config.Archive{
+					{Wrappers: []config.ArchiveWrapper{tt.wrapper}},
+				},
+			})
+			require.EqualError(t, Pipe{}.Default(ctx), tt.err)
+		})
+	}
+}
+
+func TestRunPipeCompletionsAndManpages(t *testing.T) {
+	folder := testlib.Mktmp(t)
+	dist := filepath.Join(folder, "dist")
+	require.NoError(t, os.Mkdir(dist, 0o755))
+	require.NoError(t, os.Mkdir("completions", 0o755))
+	require.NoError(t, os.Mkdir("manpages", 0o755))
+	require.NoError(t, os.WriteFile("bin", []byte("bin"), 0o755))
+	require.NoError(t, os.WriteFile("completions/bin.bash", []byte("complete"), 0o644))
+	require.NoError(t, os.WriteFile("manpages/bin.1.gz", []byte("man"), 0o644))
+
+	ctx := context.New(config.Project{
+		Dist: dist,
+		Completions: config.Completions{
+			Bash: "completions/bin.bash",
+		},
+		Manpages: []string{"manpages/*"},
+		Archives: []config.Archive{
+			{
+				Builds:          []string{"default"},
+				NameTemplate:    "foo",
+				WrapInDirectory: "foo",
+				Format:          "tar.gz",
+			},
+		},
+	})
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Goos:   "linux",
+		Goarch: "amd64",
+		Name:   "bin",
+		Path:   filepath.Join(folder, "bin"),
+		Type:   artifact.Binary,
+		Extra: map[string]interface{}{
+			artifact.ExtraBinary: "bin",
+			artifact.ExtraID:     "default",
+		},
+	})
+	require.NoError(t, Pipe{}.Run(ctx))
+	require.ElementsMatch(t, []string{
+		"foo/bin",
+		"foo/completions/bin.bash",
+		"foo/manpages/bin.1.gz",
+	}, tarFiles(t, filepath.Join(dist, "foo.tar.gz")))
+}
+
+func TestRunPipeSharedLibrary(t *testing.T) {
+	folder := testlib.Mktmp(t)
+	dist := filepath.Join(folder, "dist")
+	require.NoError(t, os.Mkdir(dist, 0o755))
+	require.NoError(t, os.WriteFile("libfoo", []byte("lib"), 0o755))
+	require.NoError(t, os.WriteFile("libfoo.h", []byte("header"), 0o644))
+
+	ctx := context.New(config.Project{
+		Dist: dist,
+		Builds: []config.Build{
+			{ID: "default", Flags: []string{"-buildmode=c-shared"}},
+		},
+		Archives: []config.Archive{
+			{
+				Builds:       []string{"default"},
+				NameTemplate: "foo",
+				Format:       "tar.gz",
+				SharedLibrary: config.SharedLibrary{
+					Enabled: true,
+				},
+			},
+		},
+	})
+	ctx.Git.CurrentTag = "v1.2.3"
+	ctx.Version = "1.2.3"
+	ctx.Semver = context.Semver{Major: 1, Minor: 2, Patch: 3}
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Goos:   "linux",
+		Goarch: "amd64",
+		Name:   "libfoo",
+		Path:   filepath.Join(folder, "libfoo"),
+		Type:   artifact.Binary,
+		Extra: map[string]interface{}{
+			artifact.ExtraBinary: "libfoo",
+			artifact.ExtraID:     "default",
+		},
+	})
+	require.NoError(t, Pipe{}.Run(ctx))
+	require.ElementsMatch(t, []string{
+		"lib/libfoo.so.1.2.3",
+		"lib/libfoo.so.1",
+		"lib/libfoo.so",
+		"include/libfoo.h",
+		"lib/pkgconfig/foo.pc",
+	}, tarFiles(t, filepath.Join(dist, "foo.tar.gz")))
+
+	archives := ctx.Artifacts.Filter(artifact.ByType(artifact.UploadableArchive)).List()
+	require.Len(t, archives, 1)
+	require.Equal(t, []string{"lib/libfoo.so.1.2.3"}, archives[0].ExtraOr(artifact.ExtraBinaries, nil))
+}
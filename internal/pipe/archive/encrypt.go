@@ -0,0 +1,94 @@
+package archive
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"filippo.io/age"
+	"filippo.io/age/agessh"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/config"
+)
+
+const ageExtension = ".age"
+
+var (
+	errPasswordAndRecipients = errors.New("encryption: password and recipients can't be used together")
+	errNoPasswordOrRecipient = errors.New("encryption: the password and recipients are all empty, the archive would not be encrypted")
+)
+
+// validateEncryption checks that encrypted archives are actual archives, as
+// binaries are released as is.
+func validateEncryption(arch config.Archive) error {
+	if arch.Encryption.Password == "" && len(arch.Encryption.Recipients) == 0 {
+		return nil
+	}
+	binary := arch.Format == "binary"
+	for _, override := range arch.FormatOverrides {
+		binary = binary || override.Format == "binary"
+	}
+	if binary {
+		return fmt.Errorf("archive %s: encryption can't be used with the binary format", arch.ID)
+	}
+	return nil
+}
+
+// encryption evaluates the encryption settings of an archive in the given
+// format.
+// It returns the password zip and 7z archives should be protected with, and
+// the age recipients the other formats should be encrypted to.
+// Encryption that is configured but renders to an empty password and no
+// recipients is an error, instead of a plain archive.
+func encryption(t *tmpl.Template, arch config.Archive, format string) (string, []age.Recipient, error) {
+	cfg := arch.Encryption
+	if cfg.Password != "" && len(cfg.Recipients) > 0 {
+		return "", nil, errPasswordAndRecipients
+	}
+
+	password, err := t.Apply(cfg.Password)
+	if err != nil {
+		return "", nil, fmt.Errorf("encryption: failed to template password: %w", err)
+	}
+	var recipients []age.Recipient
+	for _, s := range cfg.Recipients {
+		s, err := t.Apply(s)
+		if err != nil {
+			return "", nil, fmt.Errorf("encryption: failed to template recipient: %w", err)
+		}
+		if strings.TrimSpace(s) == "" {
+			continue
+		}
+		r, err := parseRecipient(strings.TrimSpace(s))
+		if err != nil {
+			return "", nil, fmt.Errorf("encryption: invalid recipient %q: %w", s, err)
+		}
+		recipients = append(recipients, r)
+	}
+
+	if password == "" && len(recipients) == 0 {
+		if cfg.Password != "" || len(cfg.Recipients) > 0 {
+			return "", nil, errNoPasswordOrRecipient
+		}
+		return "", nil, nil
+	}
+	if password == "" {
+		return password, recipients, nil
+	}
+	if format == "zip" || format == "7z" {
+		return password, recipients, nil
+	}
+	r, err := age.NewScryptRecipient(password)
+	if err != nil {
+		return "", nil, fmt.Errorf("encryption: %w", err)
+	}
+	return "", []age.Recipient{r}, nil
+}
+
+// parseRecipient parses either an age or a ssh public key.
+func parseRecipient(s string) (age.Recipient, error) {
+	if strings.HasPrefix(s, "age1") {
+		return age.ParseX25519Recipient(s)
+	}
+	return agessh.ParseRecipient(s)
+}
@@ -0,0 +1,114 @@
+package archive
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"strings"
+	"testing"
+
+	"filippo.io/age"
+	"filippo.io/age/agessh"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+)
+
+func TestEncryption(t *testing.T) {
+	identity, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	sshPub, err := ssh.NewPublicKey(pub)
+	require.NoError(t, err)
+	sshRecipient := strings.TrimSpace(string(ssh.MarshalAuthorizedKey(sshPub)))
+
+	ctx := context.New(config.Project{})
+	ctx.Env = map[string]string{
+		"PASSWORD":  "secret",
+		"RECIPIENT": identity.Recipient().String(),
+	}
+	template := tmpl.New(ctx)
+
+	t.Run("none", func(t *testing.T) {
+		password, recipients, err := encryption(template, config.Archive{}, "tar.gz")
+		require.NoError(t, err)
+		require.Empty(t, password)
+		require.Empty(t, recipients)
+	})
+
+	t.Run("password zip", func(t *testing.T) {
+		password, recipients, err := encryption(template, config.Archive{
+			Encryption: config.ArchiveEncryption{Password: "{{ .Env.PASSWORD }}"},
+		}, "zip")
+		require.NoError(t, err)
+		require.Equal(t, "secret", password)
+		require.Empty(t, recipients)
+	})
+
+	t.Run("password tar.gz", func(t *testing.T) {
+		password, recipients, err := encryption(template, config.Archive{
+			Encryption: config.ArchiveEncryption{Password: "{{ .Env.PASSWORD }}"},
+		}, "tar.gz")
+		require.NoError(t, err)
+		require.Empty(t, password)
+		require.Len(t, recipients, 1)
+		require.IsType(t, &age.ScryptRecipient{}, recipients[0])
+	})
+
+	t.Run("recipients", func(t *testing.T) {
+		password, recipients, err := encryption(template, config.Archive{
+			Encryption: config.ArchiveEncryption{Recipients: []string{
+				"{{ .Env.RECIPIENT }}",
+				sshRecipient,
+				`{{ index .Env "NOPE" }}`,
+			}},
+		}, "zip")
+		require.NoError(t, err)
+		require.Empty(t, password)
+		require.Len(t, recipients, 2)
+		require.IsType(t, &age.X25519Recipient{}, recipients[0])
+		require.IsType(t, &agessh.Ed25519Recipient{}, recipients[1])
+	})
+
+	t.Run("invalid recipient", func(t *testing.T) {
+		_, _, err := encryption(template, config.Archive{
+			Encryption: config.ArchiveEncryption{Recipients: []string{"age1nope"}},
+		}, "tar.gz")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), `encryption: invalid recipient "age1nope"`)
+	})
+
+	t.Run("invalid template", func(t *testing.T) {
+		_, _, err := encryption(template, config.Archive{
+			Encryption: config.ArchiveEncryption{Password: "{{ .Nope }"},
+		}, "tar.gz")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "encryption: failed to template password")
+	})
+
+	t.Run("password and recipients", func(t *testing.T) {
+		_, _, err := encryption(template, config.Archive{
+			Encryption: config.ArchiveEncryption{
+				Password:   "secret",
+				Recipients: []string{identity.Recipient().String()},
+			},
+		}, "tar.gz")
+		require.EqualError(t, err, errPasswordAndRecipients.Error())
+	})
+
+	t.Run("empty password", func(t *testing.T) {
+		_, _, err := encryption(template, config.Archive{
+			Encryption: config.ArchiveEncryption{Password: `{{ index .Env "NOPE" }}`},
+		}, "zip")
+		require.EqualError(t, err, errNoPasswordOrRecipient.Error())
+	})
+
+	t.Run("empty recipients", func(t *testing.T) {
+		_, _, err := encryption(template, config.Archive{
+			Encryption: config.ArchiveEncryption{Recipients: []string{`{{ index .Env "NOPE" }}`, " "}},
+		}, "tar.gz")
+		require.EqualError(t, err, errNoPasswordOrRecipient.Error())
+	})
+}
@@ -0,0 +1,65 @@
+package archive
+
+import (
+	"fmt"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/pkg/context"
+)
+
+// Filename is the name of a file an archive creates for a platform.
+type Filename struct {
+	Archive  string
+	Platform string
+	Name     string
+}
+
+// Filenames returns the names of the files the archives, including the
+// archives of build variants, would create for the given binaries, without
+// creating them. Archives which may be split also have the name of the
+// manifest of their parts, the parts being named after it.
+func Filenames(ctx *context.Context, binaries []*artifact.Artifact) ([]Filename, error) {
+	all := artifact.New()
+	for _, binary := range binaries {
+		all.Add(binary)
+	}
+	var result []Filename
+	for _, arch := range ctx.Config.Archives {
+		groups := all.Filter(artifact.ByIDs(arch.Builds...)).GroupByPlatform()
+		for _, group := range groups {
+			platform := group[0].Target()
+			if packageFormat(arch, group[0].Goos) == "binary" {
+				for _, binary := range group {
+					name, err := unarchivedName(ctx, arch, binary)
+					if err != nil {
+						return nil, fmt.Errorf("archive %s: %w", arch.ID, err)
+					}
+					result = append(result, Filename{
+						Archive:  arch.ID,
+						Platform: platform,
+						Name:     name,
+					})
+				}
+				continue
+			}
+
+			name, _, _, err := archiveName(ctx, arch, group[0])
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, Filename{
+				Archive:  arch.ID,
+				Platform: platform,
+				Name:     name,
+			})
+			if arch.Split.Size != "" {
+				result = append(result, Filename{
+					Archive:  arch.ID,
+					Platform: platform,
+					Name:     name + manifestSuffix,
+				})
+			}
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,45 @@
+package archive
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/goreleaser/goreleaser/pkg/config"
+)
+
+const (
+	symlinksPreserve = "preserve"
+	symlinksFollow   = "follow"
+	symlinksReject   = "reject"
+)
+
+// symlinks applies the symlinks option of the archive to the given files,
+// either keeping the symlinks as is, replacing them by the files they point
+// to, or failing if there are any.
+func symlinks(arch config.Archive, files []config.File) ([]config.File, error) {
+	if arch.Symlinks == "" || arch.Symlinks == symlinksPreserve {
+		return files, nil
+	}
+	result := make([]config.File, 0, len(files))
+	for _, f := range files {
+		info, err := os.Lstat(f.Source)
+		if err != nil {
+			return nil, err
+		}
+		if info.Mode()&os.ModeSymlink == 0 {
+			result = append(result, f)
+			continue
+		}
+		if arch.Symlinks == symlinksReject {
+			return nil, fmt.Errorf("archive %s: %s is a symlink, which is not allowed when symlinks is set to %s", arch.ID, f.Source, symlinksReject)
+		}
+		target, err := filepath.EvalSymlinks(f.Source)
+		if err != nil {
+			return nil, fmt.Errorf("archive %s: failed to follow symlink %s: %w", arch.ID, f.Source, err)
+		}
+		f.Source = target
+		result = append(result, f)
+	}
+	return result, nil
+}
@@ -0,0 +1,50 @@
+package archive
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSymlinks(t *testing.T) {
+	folder := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(folder, "libfoo.so.1.2.3"), []byte("lib"), 0o644))
+	require.NoError(t, os.Symlink("libfoo.so.1.2.3", filepath.Join(folder, "libfoo.so.1")))
+	files := []config.File{
+		{Source: filepath.Join(folder, "libfoo.so.1"), Destination: "lib/libfoo.so.1"},
+		{Source: filepath.Join(folder, "libfoo.so.1.2.3"), Destination: "lib/libfoo.so.1.2.3"},
+	}
+
+	t.Run("preserve", func(t *testing.T) {
+		result, err := symlinks(config.Archive{Symlinks: "preserve"}, files)
+		require.NoError(t, err)
+		require.Equal(t, files, result)
+	})
+
+	t.Run("follow", func(t *testing.T) {
+		result, err := symlinks(config.Archive{Symlinks: "follow"}, files)
+		require.NoError(t, err)
+		target, err := filepath.EvalSymlinks(filepath.Join(folder, "libfoo.so.1.2.3"))
+		require.NoError(t, err)
+		require.Equal(t, []config.File{
+			{Source: target, Destination: "lib/libfoo.so.1"},
+			files[1],
+		}, result)
+	})
+
+	t.Run("follow broken", func(t *testing.T) {
+		broken := filepath.Join(folder, "broken")
+		require.NoError(t, os.Symlink("nope", broken))
+		_, err := symlinks(config.Archive{ID: "foo", Symlinks: "follow"}, []config.File{{Source: broken}})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "archive foo: failed to follow symlink "+broken)
+	})
+
+	t.Run("reject", func(t *testing.T) {
+		_, err := symlinks(config.Archive{ID: "foo", Symlinks: "reject"}, files)
+		require.EqualError(t, err, "archive foo: "+files[0].Source+" is a symlink, which is not allowed when symlinks is set to reject")
+	})
+}
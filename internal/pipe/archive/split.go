@@ -0,0 +1,143 @@
+package archive
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// manifest describes how to join the parts of a split archive back into the
+// original archive.
+type manifest struct {
+	Name   string         `json:"name"`
+	Size   int64          `json:"size"`
+	SHA256 string         `json:"sha256"`
+	Parts  []manifestPart `json:"parts"`
+}
+
+type manifestPart struct {
+	Name   string `json:"name"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// nolint: gochecknoglobals
+var sizeUnits = map[string]int64{
+	"":    1,
+	"b":   1,
+	"k":   1000,
+	"kb":  1000,
+	"kib": 1 << 10,
+	"m":   1000 * 1000,
+	"mb":  1000 * 1000,
+	"mib": 1 << 20,
+	"g":   1000 * 1000 * 1000,
+	"gb":  1000 * 1000 * 1000,
+	"gib": 1 << 30,
+}
+
+// parseSize parses sizes like 1900MB, 1.5GiB or 1024.
+func parseSize(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	i := strings.IndexFunc(s, func(r rune) bool {
+		return !unicode.IsDigit(r) && r != '.'
+	})
+	if i == -1 {
+		i = len(s)
+	}
+	number, unit := s[:i], strings.ToLower(strings.TrimSpace(s[i:]))
+	mult, ok := sizeUnits[unit]
+	if !ok {
+		return 0, fmt.Errorf("invalid size %q: unknown unit %q", s, unit)
+	}
+	n, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	size := int64(n * float64(mult))
+	if size <= 0 {
+		return 0, fmt.Errorf("invalid size %q: must be greater than zero", s)
+	}
+	return size, nil
+}
+
+// manifestSuffix is appended to the name of a split archive to name the
+// manifest of its parts.
+const manifestSuffix = ".parts.json"
+
+// split splits the file at the given path in parts of at most size bytes,
+// named path.001, path.002 and so on, and writes a manifest describing them
+// to path.parts.json.
+// It returns the paths of the parts and of the manifest.
+// The original file is removed.
+func split(path string, size int64) ([]string, string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, "", err
+	}
+	count := (info.Size() + size - 1) / size
+	width := len(strconv.FormatInt(count, 10))
+	if width < 3 {
+		width = 3
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, "", err
+	}
+	defer f.Close()
+
+	whole := sha256.New()
+	result := manifest{
+		Name: info.Name(),
+		Size: info.Size(),
+	}
+	var parts []string
+	for i := int64(1); i <= count; i++ {
+		part := fmt.Sprintf("%s.%0*d", path, width, i)
+		written, sum, err := writePart(part, io.TeeReader(io.LimitReader(f, size), whole))
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to write part %s: %w", part, err)
+		}
+		parts = append(parts, part)
+		result.Parts = append(result.Parts, manifestPart{
+			Name:   info.Name() + strings.TrimPrefix(part, path),
+			Size:   written,
+			SHA256: sum,
+		})
+	}
+	result.SHA256 = hex.EncodeToString(whole.Sum(nil))
+
+	bts, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return nil, "", err
+	}
+	manifestPath := path + manifestSuffix
+	if err := os.WriteFile(manifestPath, append(bts, '\n'), 0o644); err != nil {
+		return nil, "", fmt.Errorf("failed to write manifest: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return nil, "", err
+	}
+	return parts, manifestPath, os.Remove(path)
+}
+
+func writePart(path string, r io.Reader) (int64, string, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return 0, "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	written, err := io.Copy(io.MultiWriter(f, h), r)
+	if err != nil {
+		return 0, "", err
+	}
+	return written, hex.EncodeToString(h.Sum(nil)), f.Close()
+}
@@ -0,0 +1,73 @@
+package archive
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSize(t *testing.T) {
+	for s, expected := range map[string]int64{
+		"1024":    1024,
+		"10b":     10,
+		"1KB":     1000,
+		"1KiB":    1024,
+		"1900MB":  1900 * 1000 * 1000,
+		"1.5GiB":  3 << 29,
+		" 2 gb  ": 2 * 1000 * 1000 * 1000,
+	} {
+		t.Run(s, func(t *testing.T) {
+			size, err := parseSize(s)
+			require.NoError(t, err)
+			require.Equal(t, expected, size)
+		})
+	}
+
+	for s, expected := range map[string]string{
+		"":     `invalid size ""`,
+		"GB":   `invalid size "GB"`,
+		"10TB": `invalid size "10TB": unknown unit "tb"`,
+		"0":    `invalid size "0": must be greater than zero`,
+	} {
+		t.Run(s, func(t *testing.T) {
+			_, err := parseSize(s)
+			require.EqualError(t, err, expected)
+		})
+	}
+}
+
+func TestSplit(t *testing.T) {
+	folder := t.TempDir()
+	path := filepath.Join(folder, "foo.tar.gz")
+	content := bytes.Repeat([]byte("0123456789"), 25)
+	require.NoError(t, os.WriteFile(path, content, 0o644))
+
+	parts, manifestPath, err := split(path, 100)
+	require.NoError(t, err)
+	require.Equal(t, []string{path + ".001", path + ".002", path + ".003"}, parts)
+	require.Equal(t, path+".parts.json", manifestPath)
+	require.NoFileExists(t, path)
+
+	var joined []byte
+	for _, part := range parts {
+		bts, err := os.ReadFile(part)
+		require.NoError(t, err)
+		joined = append(joined, bts...)
+	}
+	require.Equal(t, content, joined)
+
+	bts, err := os.ReadFile(manifestPath)
+	require.NoError(t, err)
+	var m manifest
+	require.NoError(t, json.Unmarshal(bts, &m))
+	require.Equal(t, "foo.tar.gz", m.Name)
+	require.Equal(t, int64(250), m.Size)
+	require.Equal(t, "ed5c630369e01156ad2c32acd25c52ad6fe44227e59072f36f07de4a9fff72c7", m.SHA256)
+	require.Len(t, m.Parts, 3)
+	require.Equal(t, "foo.tar.gz.003", m.Parts[2].Name)
+	require.Equal(t, int64(50), m.Parts[2].Size)
+}
@@ -0,0 +1,190 @@
+package archive
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+)
+
+const (
+	wrapperSh  = "sh"
+	wrapperBat = "bat"
+)
+
+// validateWrappers checks the wrappers of the given archive.
+func validateWrappers(arch config.Archive) error {
+	for i, w := range arch.Wrappers {
+		if w.NameTemplate == "" {
+			return fmt.Errorf("archive %s: wrapper %d: name_template is required", arch.ID, i)
+		}
+		switch w.Type {
+		case "", wrapperSh, wrapperBat:
+		default:
+			return fmt.Errorf("archive %s: wrapper %d: invalid type %q, valid types are %s and %s", arch.ID, i, w.Type, wrapperSh, wrapperBat)
+		}
+	}
+	return nil
+}
+
+// binaryName returns the name of the given binary inside the archive,
+// applying the binary name template, if any.
+// The binary extension, e.g. .exe, is added if the template does not end
+// with it already.
+func binaryName(ctx *context.Context, arch config.Archive, binary *artifact.Artifact) (string, error) {
+	if arch.BinaryNameTemplate == "" {
+		return binary.Name, nil
+	}
+	name, err := tmpl.New(ctx).
+		WithArtifact(binary, arch.Replacements).
+		Apply(arch.BinaryNameTemplate)
+	if err != nil {
+		return "", fmt.Errorf("archive %s: failed to apply binary name template: %w", arch.ID, err)
+	}
+	if ext := binary.ExtraOr(artifact.ExtraExt, "").(string); !strings.HasSuffix(name, ext) {
+		name += ext
+	}
+	return name, nil
+}
+
+// wrappers writes the wrapper scripts of the given archive into dir,
+// returning the files to add to the archive.
+// names are the names of each binary inside the archive.
+func wrappers(ctx *context.Context, arch config.Archive, binaries []*artifact.Artifact, names []string, dir string) ([]config.File, error) {
+	var result []config.File
+	for i, w := range arch.Wrappers {
+		bin, err := wrappedBinary(w, binaries)
+		if err != nil {
+			return nil, fmt.Errorf("archive %s: wrapper %d: %w", arch.ID, i, err)
+		}
+		template := tmpl.New(ctx).WithArtifact(binaries[bin], arch.Replacements)
+		name, err := template.Apply(w.NameTemplate)
+		if err != nil {
+			return nil, fmt.Errorf("archive %s: wrapper %d: failed to apply name template: %w", arch.ID, i, err)
+		}
+		var env, args []string
+		for _, e := range w.Env {
+			v, err := template.Apply(e)
+			if err != nil {
+				return nil, fmt.Errorf("archive %s: wrapper %s: failed to apply env template: %w", arch.ID, name, err)
+			}
+			env = append(env, v)
+		}
+		for _, a := range w.Args {
+			v, err := template.Apply(a)
+			if err != nil {
+				return nil, fmt.Errorf("archive %s: wrapper %s: failed to apply args template: %w", arch.ID, name, err)
+			}
+			args = append(args, v)
+		}
+
+		kind := w.Type
+		if kind == "" {
+			kind = wrapperSh
+			if binaries[bin].Goos == "windows" {
+				kind = wrapperBat
+			}
+		}
+		var content string
+		switch kind {
+		case wrapperBat:
+			if !strings.HasSuffix(name, ".bat") && !strings.HasSuffix(name, ".cmd") {
+				name += ".bat"
+			}
+			content = batWrapper(relativePath(name, names[bin]), env, args)
+		default:
+			content = shWrapper(relativePath(name, names[bin]), env, args)
+		}
+
+		src := filepath.Join(dir, strconv.Itoa(i))
+		if err := os.WriteFile(src, []byte(content), 0o755); err != nil { //nolint: gosec
+			return nil, fmt.Errorf("archive %s: failed to write wrapper %s: %w", arch.ID, name, err)
+		}
+		info := binaryInfo(ctx, arch)
+		info.Mode = 0o755
+		result = append(result, config.File{
+			Source:      src,
+			Destination: name,
+			Info:        info,
+		})
+	}
+	return result, nil
+}
+
+// wrappedBinary returns the index of the binary the given wrapper runs.
+func wrappedBinary(w config.ArchiveWrapper, binaries []*artifact.Artifact) (int, error) {
+	if w.Binary == "" {
+		if len(binaries) != 1 {
+			return 0, fmt.Errorf("binary is required when the archive has more than one binary")
+		}
+		return 0, nil
+	}
+	for i, binary := range binaries {
+		if binary.ExtraOr(artifact.ExtraBinary, "").(string) == w.Binary {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("binary %q not found in the archive", w.Binary)
+}
+
+// relativePath returns the path of the binary relative to the wrapper, as
+// both are inside the archive.
+func relativePath(wrapper, binary string) string {
+	dir := path.Dir(wrapper)
+	if dir == "." {
+		return binary
+	}
+	rel, err := filepath.Rel(filepath.FromSlash(dir), filepath.FromSlash(binary))
+	if err != nil {
+		return binary
+	}
+	return filepath.ToSlash(rel)
+}
+
+func shWrapper(binary string, env, args []string) string {
+	var b strings.Builder
+	b.WriteString("#!/bin/sh\n")
+	for _, e := range env {
+		k, v := splitEnv(e)
+		fmt.Fprintf(&b, "export %s=%s\n", k, shQuote(v))
+	}
+	fmt.Fprintf(&b, `exec "$(dirname "$0")/%s"`, binary)
+	for _, a := range args {
+		b.WriteString(" " + shQuote(a))
+	}
+	b.WriteString(" \"$@\"\n")
+	return b.String()
+}
+
+func batWrapper(binary string, env, args []string) string {
+	var b strings.Builder
+	b.WriteString("@echo off\r\n")
+	for _, e := range env {
+		fmt.Fprintf(&b, "set \"%s\"\r\n", e)
+	}
+	fmt.Fprintf(&b, `"%%~dp0%s"`, strings.ReplaceAll(binary, "/", `\`))
+	for _, a := range args {
+		fmt.Fprintf(&b, " \"%s\"", a)
+	}
+	b.WriteString(" %*\r\n")
+	return b.String()
+}
+
+func splitEnv(e string) (string, string) {
+	parts := strings.SplitN(e, "=", 2)
+	if len(parts) == 1 {
+		return parts[0], ""
+	}
+	return parts[0], parts[1]
+}
+
+func shQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'"'"'`) + "'"
+}
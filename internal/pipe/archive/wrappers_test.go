@@ -0,0 +1,53 @@
+package archive
+
+import (
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShWrapper(t *testing.T) {
+	require.Equal(
+		t,
+		"#!/bin/sh\nexport FOO='it'\"'\"'s'\nexec \"$(dirname \"$0\")/bin/foo\" '--config' 'a b' \"$@\"\n",
+		shWrapper("bin/foo", []string{"FOO=it's"}, []string{"--config", "a b"}),
+	)
+}
+
+func TestBatWrapper(t *testing.T) {
+	require.Equal(
+		t,
+		"@echo off\r\nset \"FOO=bar\"\r\n\"%~dp0bin\\foo.exe\" \"--config\" %*\r\n",
+		batWrapper("bin/foo.exe", []string{"FOO=bar"}, []string{"--config"}),
+	)
+}
+
+func TestRelativePath(t *testing.T) {
+	require.Equal(t, "foo", relativePath("run", "foo"))
+	require.Equal(t, "bin/foo", relativePath("run", "bin/foo"))
+	require.Equal(t, "foo", relativePath("bin/run", "bin/foo"))
+	require.Equal(t, "../libexec/foo", relativePath("bin/run", "libexec/foo"))
+}
+
+func TestWrappedBinary(t *testing.T) {
+	binaries := []*artifact.Artifact{
+		{Extra: map[string]interface{}{artifact.ExtraBinary: "foo"}},
+		{Extra: map[string]interface{}{artifact.ExtraBinary: "bar"}},
+	}
+
+	i, err := wrappedBinary(config.ArchiveWrapper{Binary: "bar"}, binaries)
+	require.NoError(t, err)
+	require.Equal(t, 1, i)
+
+	i, err = wrappedBinary(config.ArchiveWrapper{}, binaries[:1])
+	require.NoError(t, err)
+	require.Equal(t, 0, i)
+
+	_, err = wrappedBinary(config.ArchiveWrapper{}, binaries)
+	require.EqualError(t, err, "binary is required when the archive has more than one binary")
+
+	_, err = wrappedBinary(config.ArchiveWrapper{Binary: "nope"}, binaries)
+	require.EqualError(t, err, `binary "nope" not found in the archive`)
+}
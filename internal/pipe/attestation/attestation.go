@@ -0,0 +1,318 @@
+// Package attestation provides a pipe that creates in-toto attestations of
+// artifacts and docker images, with arbitrary predicates, like the results
+// of vulnerability scans or tests.
+package attestation
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/gio"
+	"github.com/goreleaser/goreleaser/internal/ids"
+	"github.com/goreleaser/goreleaser/internal/logext"
+	"github.com/goreleaser/goreleaser/internal/semerrgroup"
+	"github.com/goreleaser/goreleaser/internal/shell"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+)
+
+const statementType = "https://in-toto.io/Statement/v1"
+
+// Pipe that creates in-toto attestations.
+// The attestations of files are created when it runs, so they can be signed
+// and uploaded as any other artifact, while the ones of docker images are
+// pushed to their registries with cosign once the images are published.
+type Pipe struct{}
+
+func (Pipe) String() string { return "creating attestations" }
+func (Pipe) Skip(ctx *context.Context) bool {
+	return len(ctx.Config.Attestations) == 0
+}
+
+// Default sets the pipe defaults.
+func (Pipe) Default(ctx *context.Context) error {
+	ids := ids.New("attestations")
+	for i := range ctx.Config.Attestations {
+		cfg := &ctx.Config.Attestations[i]
+		if cfg.ID == "" {
+			cfg.ID = "default"
+		}
+		if cfg.Artifacts == "" {
+			cfg.Artifacts = "archive"
+		}
+		if _, err := filterFor(cfg.Artifacts); err != nil {
+			return fmt.Errorf("attestation %s: %w", cfg.ID, err)
+		}
+		if cfg.PredicateType == "" {
+			return fmt.Errorf("attestation %s: predicate_type is required", cfg.ID)
+		}
+		if (cfg.Predicate == "") == (cfg.Cmd == "") {
+			return fmt.Errorf("attestation %s: either predicate or cmd is required", cfg.ID)
+		}
+		if cfg.NameTemplate == "" {
+			cfg.NameTemplate = "{{ .ArtifactName }}." + cfg.ID + ".intoto.json"
+		}
+		ids.Inc(cfg.ID)
+	}
+	return ids.Validate()
+}
+
+// Run creates the attestations of the files.
+func (Pipe) Run(ctx *context.Context) error {
+	g := semerrgroup.New(ctx.Parallelism)
+	for _, cfg := range ctx.Config.Attestations {
+		if isImage(cfg.Artifacts) {
+			continue
+		}
+		cfg := cfg
+		for _, a := range artifactsFor(ctx, cfg) {
+			a := a
+			g.Go(func() error {
+				if err := attestFile(ctx, cfg, a); err != nil {
+					return fmt.Errorf("attestation %s: %s: %w", cfg.ID, a.Name, err)
+				}
+				return nil
+			})
+		}
+	}
+	return g.Wait()
+}
+
+// Publish creates and pushes the attestations of the docker images.
+func (Pipe) Publish(ctx *context.Context) error {
+	g := semerrgroup.New(ctx.Parallelism)
+	for _, cfg := range ctx.Config.Attestations {
+		if !isImage(cfg.Artifacts) {
+			continue
+		}
+		cfg := cfg
+		for _, a := range artifactsFor(ctx, cfg) {
+			a := a
+			g.Go(func() error {
+				if err := attestImage(ctx, cfg, a); err != nil {
+					return fmt.Errorf("attestation %s: %s: %w", cfg.ID, a.Name, err)
+				}
+				return nil
+			})
+		}
+	}
+	return g.Wait()
+}
+
+func isImage(artifacts string) bool {
+	return artifacts == "image" || artifacts == "manifest"
+}
+
+func filterFor(artifacts string) (artifact.Filter, error) {
+	switch artifacts {
+	case "archive":
+		return artifact.Or(
+			artifact.ByType(artifact.UploadableArchive),
+			artifact.ByType(artifact.UploadableArchivePart),
+		), nil
+	case "binary":
+		return artifact.ByType(artifact.UploadableBinary), nil
+	case "package":
+		return artifact.ByType(artifact.LinuxPackage), nil
+	case "source":
+		return artifact.ByType(artifact.UploadableSourceArchive), nil
+	case "sbom":
+		return artifact.ByType(artifact.SBOM), nil
+	case "all":
+		return artifact.Or(
+			artifact.ByType(artifact.UploadableArchive),
+			artifact.ByType(artifact.UploadableArchivePart),
+			artifact.ByType(artifact.UploadableBinary),
+			artifact.ByType(artifact.UploadableSourceArchive),
+			artifact.ByType(artifact.LinuxPackage),
+			artifact.ByType(artifact.SBOM),
+		), nil
+	case "image":
+		return artifact.ByType(artifact.DockerImage), nil
+	case "manifest":
+		return artifact.ByType(artifact.DockerManifest), nil
+	default:
+		return nil, fmt.Errorf("invalid list of artifacts to attest: %s", artifacts)
+	}
+}
+
+func artifactsFor(ctx *context.Context, cfg config.Attestation) []*artifact.Artifact {
+	filter, _ := filterFor(cfg.Artifacts) // validated in Default
+	if len(cfg.IDs) > 0 {
+		filter = artifact.And(filter, artifact.ByIDs(cfg.IDs...))
+	}
+	return ctx.Artifacts.Filter(filter).List()
+}
+
+type statement struct {
+	Type          string          `json:"_type"`
+	Subject       []subject       `json:"subject"`
+	PredicateType string          `json:"predicateType"`
+	Predicate     json.RawMessage `json:"predicate"`
+}
+
+type subject struct {
+	Name   string            `json:"name"`
+	Digest map[string]string `json:"digest"`
+}
+
+func attestFile(ctx *context.Context, cfg config.Attestation, a *artifact.Artifact) error {
+	t := tmpl.New(ctx).WithArtifact(a, nil)
+	predicateType, err := t.Apply(cfg.PredicateType)
+	if err != nil {
+		return err
+	}
+	predicate, err := predicateFor(ctx, cfg, a)
+	if err != nil {
+		return err
+	}
+	sum, err := a.Checksum("sha256")
+	if err != nil {
+		return err
+	}
+	bts, err := json.MarshalIndent(statement{
+		Type: statementType,
+		Subject: []subject{{
+			Name:   a.Name,
+			Digest: map[string]string{"sha256": sum},
+		}},
+		PredicateType: predicateType,
+		Predicate:     predicate,
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	name, err := t.Apply(cfg.NameTemplate)
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(ctx.Config.Dist, name)
+	log.FromContext(ctx).WithField("file", path).Info("writing")
+	if err := os.WriteFile(path, bts, 0o644); err != nil {
+		return err
+	}
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Type: artifact.Attestation,
+		Name: name,
+		Path: path,
+		Extra: map[string]interface{}{
+			artifact.ExtraID: cfg.ID,
+		},
+	})
+	return nil
+}
+
+func attestImage(ctx *context.Context, cfg config.Attestation, a *artifact.Artifact) error {
+	t := tmpl.New(ctx).WithArtifact(a, nil)
+	predicateType, err := t.Apply(cfg.PredicateType)
+	if err != nil {
+		return err
+	}
+	key, err := t.Apply(cfg.Key)
+	if err != nil {
+		return err
+	}
+	predicate, err := predicateFor(ctx, cfg, a)
+	if err != nil {
+		return err
+	}
+	f, err := os.CreateTemp("", "goreleaser-predicate-*.json")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(predicate); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	cmd := cosignCommand(predicateType, f.Name(), key, cfg.Referrers, a.Name)
+	log.FromContext(ctx).WithField("image", a.Name).Info("attesting")
+	return shell.Run(ctx, "", cmd, ctx.Env.Strings())
+}
+
+// cosignCommand returns the cosign command that signs and pushes the
+// attestation of the given image.
+func cosignCommand(predicateType, predicate, key string, referrers bool, image string) []string {
+	cmd := []string{"cosign", "attest", "--yes", "--type", predicateType, "--predicate", predicate}
+	if key != "" {
+		cmd = append(cmd, "--key", key)
+	}
+	if referrers {
+		cmd = append(cmd, "--registry-referrers-mode", "oci-1-1")
+	}
+	return append(cmd, image)
+}
+
+// predicateFor returns the predicate of the given artifact, either read from
+// the predicate file or from the output of the command, which must be JSON.
+func predicateFor(ctx *context.Context, cfg config.Attestation, a *artifact.Artifact) (json.RawMessage, error) {
+	t := tmpl.New(ctx).WithArtifact(a, nil)
+	var bts []byte
+	if cfg.Predicate != "" {
+		path, err := t.Apply(cfg.Predicate)
+		if err != nil {
+			return nil, err
+		}
+		bts, err = os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		out, err := runPredicateCmd(ctx, cfg, t, a)
+		if err != nil {
+			return nil, err
+		}
+		bts = out
+	}
+	if !json.Valid(bts) {
+		return nil, errors.New("predicate is not valid JSON")
+	}
+	return bytes.TrimSpace(bts), nil
+}
+
+func runPredicateCmd(ctx *context.Context, cfg config.Attestation, t *tmpl.Template, a *artifact.Artifact) ([]byte, error) {
+	args := make([]string, 0, len(cfg.Args))
+	for _, arg := range cfg.Args {
+		arg, err := t.Apply(arg)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+	}
+	env := ctx.Env.Strings()
+	for _, e := range cfg.Env {
+		e, err := t.Apply(e)
+		if err != nil {
+			return nil, err
+		}
+		env = append(env, e)
+	}
+
+	fields := log.Fields{"cmd": cfg.Cmd, "artifact": a.Name}
+	var stdout, stderr bytes.Buffer
+	// #nosec
+	cmd := exec.CommandContext(ctx, cfg.Cmd, args...)
+	cmd.Env = env
+	cmd.Stdout = &stdout
+	cmd.Stderr = io.MultiWriter(logext.NewWriter(fields, logext.Error), gio.Safe(&stderr))
+
+	log.FromContext(ctx).WithFields(fields).Debug("running")
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("%s failed: %w: %s", cfg.Cmd, err, stderr.String())
+	}
+	return stdout.Bytes(), nil
+}
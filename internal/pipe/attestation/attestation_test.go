@@ -0,0 +1,277 @@
+package attestation
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDescription(t *testing.T) {
+	require.NotEmpty(t, Pipe{}.String())
+}
+
+func TestSkip(t *testing.T) {
+	require.True(t, Pipe{}.Skip(context.New(config.Project{})))
+	require.False(t, Pipe{}.Skip(context.New(config.Project{
+		Attestations: []config.Attestation{{}},
+	})))
+}
+
+func TestDefault(t *testing.T) {
+	ctx := context.New(config.Project{
+		Attestations: []config.Attestation{
+			{PredicateType: "https://cyclonedx.org/vex", Predicate: "vex.json"},
+			{ID: "tests", PredicateType: "https://example.com/tests", Cmd: "cat", Artifacts: "binary"},
+		},
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.Equal(t, "default", ctx.Config.Attestations[0].ID)
+	require.Equal(t, "archive", ctx.Config.Attestations[0].Artifacts)
+	require.Equal(t, "{{ .ArtifactName }}.default.intoto.json", ctx.Config.Attestations[0].NameTemplate)
+	require.Equal(t, "{{ .ArtifactName }}.tests.intoto.json", ctx.Config.Attestations[1].NameTemplate)
+}
+
+func TestDefaultErrors(t *testing.T) {
+	for name, tt := range map[string]struct {
+		cfgs []config.Attestation
+		err  string
+	}{
+		"invalid artifacts": {
+			cfgs: []config.Attestation{{Artifacts: "nope", PredicateType: "a", Predicate: "a.json"}},
+			err:  "attestation default: invalid list of artifacts to attest: nope",
+		},
+		"no predicate type": {
+			cfgs: []config.Attestation{{Predicate: "a.json"}},
+			err:  "attestation default: predicate_type is required",
+		},
+		"no predicate": {
+			cfgs: []config.Attestation{{PredicateType: "a"}},
+			err:  "attestation default: either predicate or cmd is required",
+		},
+		"predicate and cmd": {
+			cfgs: []config.Attestation{{PredicateType: "a", Predicate: "a.json", Cmd: "cat"}},
+			err:  "attestation default: either predicate or cmd is required",
+		},
+		"duplicated ids": {
+			cfgs: []config.Attestation{
+				{PredicateType: "a", Predicate: "a.json"},
+				{PredicateType: "a", Predicate: "a.json"},
+			},
+			err: "found 2 attestations with the ID 'default', please fix your config",
+		},
+	} {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			ctx := context.New(config.Project{Attestations: tt.cfgs})
+			require.EqualError(t, Pipe{}.Default(ctx), tt.err)
+		})
+	}
+}
+
+// addArtifacts adds an archive and a linux package to attest.
+func addArtifacts(t *testing.T, ctx *context.Context) {
+	t.Helper()
+	for _, a := range []*artifact.Artifact{
+		{Name: "foo.tar.gz", Type: artifact.UploadableArchive, Goos: "linux", Goarch: "amd64"},
+		{Name: "foo.deb", Type: artifact.LinuxPackage, Goos: "linux", Goarch: "amd64"},
+	} {
+		a.Path = filepath.Join(ctx.Config.Dist, a.Name)
+		a.Extra = map[string]interface{}{artifact.ExtraID: "foo"}
+		require.NoError(t, os.WriteFile(a.Path, []byte(a.Name), 0o644))
+		ctx.Artifacts.Add(a)
+	}
+}
+
+func sha(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func readStatement(t *testing.T, a *artifact.Artifact) statement {
+	t.Helper()
+	bts, err := os.ReadFile(a.Path)
+	require.NoError(t, err)
+	var st statement
+	require.NoError(t, json.Unmarshal(bts, &st))
+	return st
+}
+
+func TestRunPredicateFile(t *testing.T) {
+	predicate := filepath.Join(t.TempDir(), "linux.json")
+	require.NoError(t, os.WriteFile(predicate, []byte(`{"results": []}`), 0o644))
+	ctx := context.New(config.Project{
+		Dist: t.TempDir(),
+		Attestations: []config.Attestation{{
+			ID:            "vex",
+			PredicateType: "https://openvex.dev/ns",
+			Predicate:     filepath.Join(filepath.Dir(predicate), "{{ .Os }}.json"),
+		}},
+	})
+	addArtifacts(t, ctx)
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.NoError(t, Pipe{}.Run(ctx))
+
+	attestations := ctx.Artifacts.Filter(artifact.ByType(artifact.Attestation)).List()
+	require.Len(t, attestations, 1)
+	require.Equal(t, "foo.tar.gz.vex.intoto.json", attestations[0].Name)
+	require.Equal(t, "vex", attestations[0].ID())
+
+	st := readStatement(t, attestations[0])
+	require.Equal(t, "https://in-toto.io/Statement/v1", st.Type)
+	require.Equal(t, "https://openvex.dev/ns", st.PredicateType)
+	require.Equal(t, []subject{{
+		Name:   "foo.tar.gz",
+		Digest: map[string]string{"sha256": sha("foo.tar.gz")},
+	}}, st.Subject)
+	require.JSONEq(t, `{"results": []}`, string(st.Predicate))
+}
+
+func TestRunPredicateCmd(t *testing.T) {
+	ctx := context.New(config.Project{
+		Dist: t.TempDir(),
+		Attestations: []config.Attestation{{
+			Artifacts:     "package",
+			PredicateType: "https://example.com/tests",
+			Cmd:           "echo",
+			Args:          []string{`{"artifact": "{{ .ArtifactName }}"}`},
+		}},
+	})
+	addArtifacts(t, ctx)
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.NoError(t, Pipe{}.Run(ctx))
+
+	attestations := ctx.Artifacts.Filter(artifact.ByType(artifact.Attestation)).List()
+	require.Len(t, attestations, 1)
+	require.Equal(t, "foo.deb.default.intoto.json", attestations[0].Name)
+	st := readStatement(t, attestations[0])
+	require.JSONEq(t, `{"artifact": "foo.deb"}`, string(st.Predicate))
+}
+
+func TestRunErrors(t *testing.T) {
+	t.Run("invalid json", func(t *testing.T) {
+		ctx := context.New(config.Project{
+			Dist: t.TempDir(),
+			Attestations: []config.Attestation{{
+				PredicateType: "a",
+				Cmd:           "echo",
+				Args:          []string{"nope"},
+			}},
+		})
+		addArtifacts(t, ctx)
+		require.NoError(t, Pipe{}.Default(ctx))
+		require.EqualError(t, Pipe{}.Run(ctx), "attestation default: foo.tar.gz: predicate is not valid JSON")
+	})
+
+	t.Run("missing predicate", func(t *testing.T) {
+		ctx := context.New(config.Project{
+			Dist: t.TempDir(),
+			Attestations: []config.Attestation{{
+				PredicateType: "a",
+				Predicate:     "nope.json",
+			}},
+		})
+		addArtifacts(t, ctx)
+		require.NoError(t, Pipe{}.Default(ctx))
+		require.Error(t, Pipe{}.Run(ctx))
+	})
+
+	t.Run("failing cmd", func(t *testing.T) {
+		ctx := context.New(config.Project{
+			Dist: t.TempDir(),
+			Attestations: []config.Attestation{{
+				PredicateType: "a",
+				Cmd:           "false",
+			}},
+		})
+		addArtifacts(t, ctx)
+		require.NoError(t, Pipe{}.Default(ctx))
+		err := Pipe{}.Run(ctx)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "false failed")
+	})
+
+	t.Run("invalid name template", func(t *testing.T) {
+		ctx := context.New(config.Project{
+			Dist: t.TempDir(),
+			Attestations: []config.Attestation{{
+				PredicateType: "a",
+				Cmd:           "echo",
+				Args:          []string{"{}"},
+				NameTemplate:  "{{ .Nope }",
+			}},
+		})
+		addArtifacts(t, ctx)
+		require.NoError(t, Pipe{}.Default(ctx))
+		require.Error(t, Pipe{}.Run(ctx))
+	})
+}
+
+func TestCosignCommand(t *testing.T) {
+	require.Equal(t, []string{
+		"cosign", "attest", "--yes",
+		"--type", "https://example.com/tests",
+		"--predicate", "predicate.json",
+		"ghcr.io/foo/bar:v1",
+	}, cosignCommand("https://example.com/tests", "predicate.json", "", false, "ghcr.io/foo/bar:v1"))
+
+	require.Equal(t, []string{
+		"cosign", "attest", "--yes",
+		"--type", "https://example.com/tests",
+		"--predicate", "predicate.json",
+		"--key", "cosign.key",
+		"--registry-referrers-mode", "oci-1-1",
+		"ghcr.io/foo/bar:v1",
+	}, cosignCommand("https://example.com/tests", "predicate.json", "cosign.key", true, "ghcr.io/foo/bar:v1"))
+}
+
+func TestPublishImages(t *testing.T) {
+	// a fake cosign that records its arguments and the predicate.
+	bin := t.TempDir()
+	out := filepath.Join(t.TempDir(), "out")
+	require.NoError(t, os.WriteFile(filepath.Join(bin, "cosign"), []byte(`#!/bin/sh
+echo "$@" >> `+out+`
+while [ "$1" != "--predicate" ]; do shift; done
+cat "$2" >> `+out+`
+`), 0o755))
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	ctx := context.New(config.Project{
+		Dist: t.TempDir(),
+		Attestations: []config.Attestation{{
+			Artifacts:     "image",
+			PredicateType: "https://example.com/scan",
+			Cmd:           "echo",
+			Args:          []string{`{"image": "{{ .ArtifactName }}"}`},
+			Referrers:     true,
+		}},
+	})
+	addArtifacts(t, ctx)
+	require.NoError(t, Pipe{}.Default(ctx))
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name:  "ghcr.io/foo/bar:v1",
+		Type:  artifact.DockerImage,
+		Extra: map[string]interface{}{},
+	})
+
+	// images are only attested when published.
+	require.NoError(t, Pipe{}.Run(ctx))
+	require.NoFileExists(t, out)
+
+	require.NoError(t, Pipe{}.Publish(ctx))
+	bts, err := os.ReadFile(out)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(bts)), "\n")
+	require.Len(t, lines, 2)
+	require.True(t, strings.HasPrefix(lines[0], "attest --yes --type https://example.com/scan --predicate "))
+	require.True(t, strings.HasSuffix(lines[0], " --registry-referrers-mode oci-1-1 ghcr.io/foo/bar:v1"))
+	require.JSONEq(t, `{"image": "ghcr.io/foo/bar:v1"}`, lines[1])
+}
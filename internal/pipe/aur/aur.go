@@ -112,6 +112,7 @@ func doRun(ctx *context.Context, pkgbuild config.AUR, cl client.Client) error {
 			artifact.ByType(artifact.UploadableArchive),
 			artifact.ByType(artifact.UploadableBinary),
 		),
+		artifact.OnlyUnencrypted,
 	}
 	if len(pkgbuild.IDs) > 0 {
 		filters = append(filters, artifact.ByIDs(pkgbuild.IDs...))
@@ -139,7 +140,7 @@ func doRun(ctx *context.Context, pkgbuild config.AUR, cl client.Client) error {
 				break
 			}
 		}
-		log.Warnf("guessing package to be %q", pkg)
+		log.FromContext(ctx).Warnf("guessing package to be %q", pkg)
 	}
 	pkgbuild.Package = pkg
 
@@ -169,7 +170,7 @@ func doRun(ctx *context.Context, pkgbuild config.AUR, cl client.Client) error {
 		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
 			return fmt.Errorf("failed to write %s: %w", info.kind, err)
 		}
-		log.WithField("file", path).Info("writing")
+		log.FromContext(ctx).WithField("file", path).Info("writing")
 		if err := os.WriteFile(path, []byte(pkgContent), 0o644); err != nil { //nolint: gosec
 			return fmt.Errorf("failed to write %s: %w", info.kind, err)
 		}
@@ -407,13 +408,13 @@ func doPublish(ctx *context.Context, pkgs []*artifact.Artifact) error {
 		return fmt.Errorf("failed to setup local AUR repo: %w", err)
 	}
 
-	if err := runGitCmds(cwd, env, [][]string{
-		// setup auth et al
-		{"config", "--local", "user.name", author.Name},
-		{"config", "--local", "user.email", author.Email},
-		{"config", "--local", "commit.gpgSign", "false"},
-		{"config", "--local", "init.defaultBranch", "master"},
-	}); err != nil {
+	// setup auth et al
+	cmds := [][]string{}
+	for _, kv := range commitauthor.GitConfig(author) {
+		cmds = append(cmds, append([]string{"config", "--local"}, kv...))
+	}
+	cmds = append(cmds, []string{"config", "--local", "init.defaultBranch", "master"})
+	if err := runGitCmds(cwd, env, cmds); err != nil {
 		return fmt.Errorf("failed to setup local AUR repo: %w", err)
 	}
 
@@ -428,7 +429,7 @@ func doPublish(ctx *context.Context, pkgs []*artifact.Artifact) error {
 		}
 	}
 
-	log.WithField("repo", url).WithField("name", cfg.Name).Info("pushing")
+	log.FromContext(ctx).WithField("repo", url).WithField("name", cfg.Name).Info("pushing")
 	if err := runGitCmds(cwd, env, [][]string{
 		{"add", "-A", "."},
 		{"commit", "-m", msg},
@@ -0,0 +1,200 @@
+// Package authenticode provides a pipe that signs Windows binaries and
+// installers with Authenticode.
+package authenticode
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/ids"
+	"github.com/goreleaser/goreleaser/internal/semerrgroup"
+	"github.com/goreleaser/goreleaser/internal/shell"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+)
+
+// Pipe that signs Windows binaries and installers with Authenticode.
+type Pipe struct{}
+
+func (Pipe) String() string { return "signing windows binaries" }
+func (Pipe) Skip(ctx *context.Context) bool {
+	return ctx.SkipSign || len(ctx.Config.Authenticode) == 0
+}
+
+// Default sets the pipe defaults.
+func (Pipe) Default(ctx *context.Context) error {
+	ids := ids.New("authenticode")
+	for i := range ctx.Config.Authenticode {
+		cfg := &ctx.Config.Authenticode[i]
+		if cfg.ID == "" {
+			cfg.ID = "default"
+		}
+		if cfg.Cmd == "" {
+			cfg.Cmd = "osslsigncode"
+		}
+		if cfg.Hash == "" {
+			cfg.Hash = "sha256"
+		}
+		if cfg.Certificate == "" {
+			return fmt.Errorf("authenticode %s: certificate is required", cfg.ID)
+		}
+		if cfg.PKCS11Module != "" && cfg.Key == "" {
+			return fmt.Errorf("authenticode %s: key is required when using a pkcs11 module", cfg.ID)
+		}
+		ids.Inc(cfg.ID)
+	}
+	return ids.Validate()
+}
+
+// Run the pipe.
+func (Pipe) Run(ctx *context.Context) error {
+	for _, cfg := range ctx.Config.Authenticode {
+		paths, err := pathsToSign(ctx, cfg)
+		if err != nil {
+			return fmt.Errorf("authenticode %s: %w", cfg.ID, err)
+		}
+		if len(paths) == 0 {
+			log.FromContext(ctx).WithField("id", cfg.ID).Warn("no windows binaries found")
+			continue
+		}
+		g := semerrgroup.New(ctx.Parallelism)
+		for _, path := range paths {
+			path := path
+			g.Go(func() error {
+				if err := sign(ctx, cfg, path); err != nil {
+					return fmt.Errorf("authenticode %s: failed to sign %s: %w", cfg.ID, path, err)
+				}
+				return nil
+			})
+		}
+		if err := g.Wait(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pathsToSign returns the paths of the windows binaries and of the extra
+// files matching the configuration.
+func pathsToSign(ctx *context.Context, cfg config.Authenticode) ([]string, error) {
+	filters := []artifact.Filter{
+		artifact.ByType(artifact.Binary),
+		artifact.ByGoos("windows"),
+	}
+	if len(cfg.IDs) > 0 {
+		filters = append(filters, artifact.ByIDs(cfg.IDs...))
+	}
+	var paths []string
+	for _, binary := range ctx.Artifacts.Filter(artifact.And(filters...)).List() {
+		paths = append(paths, binary.Path)
+	}
+	for _, glob := range cfg.Files {
+		glob, err := tmpl.New(ctx).Apply(glob)
+		if err != nil {
+			return nil, err
+		}
+		matches, err := filepath.Glob(glob)
+		if err != nil {
+			return nil, fmt.Errorf("invalid files glob %q: %w", glob, err)
+		}
+		paths = append(paths, matches...)
+	}
+	return paths, nil
+}
+
+func sign(ctx *context.Context, cfg config.Authenticode, path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	out := path + ".signed"
+	cmd, cleanup, err := signCommand(ctx, cfg, path, out)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	defer os.Remove(out)
+
+	log.FromContext(ctx).WithField("file", filepath.Base(path)).Info("signing")
+	if err := shell.Run(ctx, "", cmd, ctx.Env.Strings()); err != nil {
+		return err
+	}
+	// files can't be signed in place, so the signed file replaces the
+	// original one, keeping its permissions.
+	if err := os.Chmod(out, info.Mode()); err != nil {
+		return err
+	}
+	return os.Rename(out, path)
+}
+
+// signCommand returns the osslsigncode command that signs the file in the
+// given path, writing the signed file to out.
+// The returned cleanup function must be called once the command ran.
+func signCommand(ctx *context.Context, cfg config.Authenticode, path, out string) ([]string, func(), error) {
+	cleanup := func() {}
+	t := tmpl.New(ctx)
+	// cfg is a copy, so its fields can be templated in place.
+	for _, s := range []*string{
+		&cfg.Certificate,
+		&cfg.Key,
+		&cfg.Password,
+		&cfg.PKCS11Engine,
+		&cfg.PKCS11Module,
+		&cfg.Description,
+		&cfg.URL,
+		&cfg.TimestampURL,
+	} {
+		v, err := t.Apply(*s)
+		if err != nil {
+			return nil, cleanup, err
+		}
+		*s = v
+	}
+
+	cmd := []string{cfg.Cmd, "sign"}
+	if cfg.Key == "" {
+		cmd = append(cmd, "-pkcs12", cfg.Certificate)
+	} else {
+		cmd = append(cmd, "-certs", cfg.Certificate)
+		if cfg.PKCS11Engine != "" {
+			cmd = append(cmd, "-pkcs11engine", cfg.PKCS11Engine)
+		}
+		if cfg.PKCS11Module != "" {
+			cmd = append(cmd, "-pkcs11module", cfg.PKCS11Module)
+		}
+		cmd = append(cmd, "-key", cfg.Key)
+	}
+	if cfg.Password != "" {
+		// the password is passed in a file so it doesn't show up in the
+		// process list.
+		f, err := os.CreateTemp("", "goreleaser-authenticode")
+		if err != nil {
+			return nil, cleanup, err
+		}
+		cleanup = func() { _ = os.Remove(f.Name()) }
+		if _, err := io.WriteString(f, cfg.Password); err != nil {
+			_ = f.Close()
+			return nil, cleanup, err
+		}
+		if err := f.Close(); err != nil {
+			return nil, cleanup, err
+		}
+		cmd = append(cmd, "-readpass", f.Name())
+	}
+	cmd = append(cmd, "-h", cfg.Hash)
+	if cfg.Description != "" {
+		cmd = append(cmd, "-n", cfg.Description)
+	}
+	if cfg.URL != "" {
+		cmd = append(cmd, "-i", cfg.URL)
+	}
+	if cfg.TimestampURL != "" {
+		cmd = append(cmd, "-ts", cfg.TimestampURL)
+	}
+	return append(cmd, "-in", path, "-out", out), cleanup, nil
+}
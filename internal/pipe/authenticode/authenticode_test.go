@@ -0,0 +1,208 @@
+package authenticode
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDescription(t *testing.T) {
+	require.NotEmpty(t, Pipe{}.String())
+}
+
+func TestSkip(t *testing.T) {
+	t.Run("skip", func(t *testing.T) {
+		require.True(t, Pipe{}.Skip(context.New(config.Project{})))
+	})
+
+	t.Run("skip sign", func(t *testing.T) {
+		ctx := context.New(config.Project{
+			Authenticode: []config.Authenticode{{}},
+		})
+		ctx.SkipSign = true
+		require.True(t, Pipe{}.Skip(ctx))
+	})
+
+	t.Run("dont skip", func(t *testing.T) {
+		ctx := context.New(config.Project{
+			Authenticode: []config.Authenticode{{}},
+		})
+		require.False(t, Pipe{}.Skip(ctx))
+	})
+}
+
+func TestDefault(t *testing.T) {
+	ctx := context.New(config.Project{
+		Authenticode: []config.Authenticode{{Certificate: "cert.p12"}},
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	cfg := ctx.Config.Authenticode[0]
+	require.Equal(t, "default", cfg.ID)
+	require.Equal(t, "osslsigncode", cfg.Cmd)
+	require.Equal(t, "sha256", cfg.Hash)
+}
+
+func TestDefaultErrors(t *testing.T) {
+	for name, tt := range map[string]struct {
+		cfgs []config.Authenticode
+		err  string
+	}{
+		"no certificate": {
+			cfgs: []config.Authenticode{{}},
+			err:  "authenticode default: certificate is required",
+		},
+		"pkcs11 without key": {
+			cfgs: []config.Authenticode{{Certificate: "cert.pem", PKCS11Module: "module.so"}},
+			err:  "authenticode default: key is required when using a pkcs11 module",
+		},
+		"duplicated ids": {
+			cfgs: []config.Authenticode{{Certificate: "cert.p12"}, {Certificate: "cert.p12"}},
+			err:  "found 2 authenticode with the ID 'default', please fix your config",
+		},
+	} {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			ctx := context.New(config.Project{Authenticode: tt.cfgs})
+			require.EqualError(t, Pipe{}.Default(ctx), tt.err)
+		})
+	}
+}
+
+func TestSignCommand(t *testing.T) {
+	ctx := context.New(config.Project{ProjectName: "foo"})
+	ctx.Env["P12_PASSWORD"] = "secret"
+
+	t.Run("pkcs12", func(t *testing.T) {
+		cmd, cleanup, err := signCommand(ctx, config.Authenticode{
+			Cmd:          "osslsigncode",
+			Certificate:  "cert.p12",
+			Password:     "{{ .Env.P12_PASSWORD }}",
+			Hash:         "sha256",
+			Description:  "{{ .ProjectName }}",
+			URL:          "https://example.com",
+			TimestampURL: "http://timestamp.example.com",
+		}, "foo.exe", "foo.exe.signed")
+		require.NoError(t, err)
+		require.Len(t, cmd, 18)
+		require.Equal(t, []string{"osslsigncode", "sign", "-pkcs12", "cert.p12", "-readpass"}, cmd[:5])
+		require.Equal(t, []string{
+			"-h", "sha256",
+			"-n", "foo",
+			"-i", "https://example.com",
+			"-ts", "http://timestamp.example.com",
+			"-in", "foo.exe",
+			"-out", "foo.exe.signed",
+		}, cmd[6:])
+		bts, err := os.ReadFile(cmd[5])
+		require.NoError(t, err)
+		require.Equal(t, "secret", string(bts))
+
+		cleanup()
+		require.NoFileExists(t, cmd[5])
+	})
+
+	t.Run("pkcs11", func(t *testing.T) {
+		cmd, cleanup, err := signCommand(ctx, config.Authenticode{
+			Cmd:          "osslsigncode",
+			Certificate:  "cert.pem",
+			Key:          "pkcs11:object=release",
+			PKCS11Engine: "/usr/lib/engines/pkcs11.so",
+			PKCS11Module: "/usr/lib/kms-pkcs11.so",
+			Hash:         "sha256",
+		}, "foo.exe", "foo.exe.signed")
+		require.NoError(t, err)
+		defer cleanup()
+		require.Equal(t, []string{
+			"osslsigncode", "sign",
+			"-certs", "cert.pem",
+			"-pkcs11engine", "/usr/lib/engines/pkcs11.so",
+			"-pkcs11module", "/usr/lib/kms-pkcs11.so",
+			"-key", "pkcs11:object=release",
+			"-h", "sha256",
+			"-in", "foo.exe",
+			"-out", "foo.exe.signed",
+		}, cmd)
+	})
+
+	t.Run("invalid template", func(t *testing.T) {
+		_, cleanup, err := signCommand(ctx, config.Authenticode{Certificate: "{{ .Nope }"}, "foo.exe", "foo.exe.signed")
+		defer cleanup()
+		require.Error(t, err)
+	})
+}
+
+// fakeSigner writes a script that mimics osslsigncode, prefixing the signed
+// file with "signed".
+func fakeSigner(tb testing.TB) string {
+	tb.Helper()
+	path := filepath.Join(tb.TempDir(), "fakesign")
+	require.NoError(tb, os.WriteFile(path, []byte(`#!/bin/sh
+while [ "$1" != "-in" ]; do shift; done
+{ printf 'signed\n'; while IFS= read -r l; do printf '%s\n' "$l"; done < "$2"; } > "$4"
+`), 0o755))
+	return path
+}
+
+func TestRun(t *testing.T) {
+	folder := t.TempDir()
+	exe := filepath.Join(folder, "foo.exe")
+	require.NoError(t, os.WriteFile(exe, []byte("exe\n"), 0o755))
+	linux := filepath.Join(folder, "foo")
+	require.NoError(t, os.WriteFile(linux, []byte("elf\n"), 0o755))
+	msi := filepath.Join(folder, "foo.msi")
+	require.NoError(t, os.WriteFile(msi, []byte("msi\n"), 0o644))
+
+	ctx := context.New(config.Project{
+		Authenticode: []config.Authenticode{{
+			Cmd:         fakeSigner(t),
+			Certificate: "cert.p12",
+			Files:       []string{filepath.Join(folder, "*.msi")},
+		}},
+	})
+	for _, a := range []*artifact.Artifact{
+		{Name: "foo.exe", Path: exe, Goos: "windows", Type: artifact.Binary},
+		{Name: "foo", Path: linux, Goos: "linux", Type: artifact.Binary},
+	} {
+		ctx.Artifacts.Add(a)
+	}
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.NoError(t, Pipe{}.Run(ctx))
+
+	for path, content := range map[string]string{
+		exe:   "signed\nexe\n",
+		msi:   "signed\nmsi\n",
+		linux: "elf\n",
+	} {
+		bts, err := os.ReadFile(path)
+		require.NoError(t, err)
+		require.Equal(t, content, string(bts), path)
+	}
+	info, err := os.Stat(exe)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0o755), info.Mode())
+	require.NoFileExists(t, exe+".signed")
+}
+
+func TestRunFailure(t *testing.T) {
+	exe := filepath.Join(t.TempDir(), "foo.exe")
+	require.NoError(t, os.WriteFile(exe, []byte("exe"), 0o755))
+
+	ctx := context.New(config.Project{
+		Authenticode: []config.Authenticode{{
+			Cmd:         "false",
+			Certificate: "cert.p12",
+		}},
+	})
+	ctx.Artifacts.Add(&artifact.Artifact{Name: "foo.exe", Path: exe, Goos: "windows", Type: artifact.Binary})
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.Error(t, Pipe{}.Run(ctx))
+
+	bts, err := os.ReadFile(exe)
+	require.NoError(t, err)
+	require.Equal(t, "exe", string(bts))
+}
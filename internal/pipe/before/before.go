@@ -11,6 +11,7 @@ import (
 	"github.com/caarlos0/go-shellwords"
 	"github.com/goreleaser/goreleaser/internal/gio"
 	"github.com/goreleaser/goreleaser/internal/logext"
+	"github.com/goreleaser/goreleaser/internal/shell"
 	"github.com/goreleaser/goreleaser/internal/tmpl"
 	"github.com/goreleaser/goreleaser/pkg/context"
 )
@@ -25,7 +26,8 @@ func (Pipe) Skip(ctx *context.Context) bool { return len(ctx.Config.Before.Hooks
 func (Pipe) Run(ctx *context.Context) error {
 	tmpl := tmpl.New(ctx)
 	/* #nosec */
-	for _, step := range ctx.Config.Before.Hooks {
+	for _, hook := range ctx.Config.Before.Hooks {
+		step := hook.Cmd
 		s, err := tmpl.Apply(step)
 		if err != nil {
 			return err
@@ -34,17 +36,44 @@ func (Pipe) Run(ctx *context.Context) error {
 		if err != nil {
 			return err
 		}
+		dir, err := tmpl.Apply(hook.Dir)
+		if err != nil {
+			return err
+		}
+		var env []string
+		for _, rawEnv := range hook.Env {
+			e, err := tmpl.Apply(rawEnv)
+			if err != nil {
+				return err
+			}
+			env = append(env, e)
+		}
+		if hook.Image != "" {
+			image, err := tmpl.Apply(hook.Image)
+			if err != nil {
+				return err
+			}
+			args, err = shell.Container(ctx, hook.Use, image, dir, env, args)
+			if err != nil {
+				return err
+			}
+			dir = ""
+		}
 
 		cmd := exec.Command(args[0], args[1:]...)
-		cmd.Env = ctx.Env.Strings()
+		cmd.Env = append(ctx.Env.Strings(), env...)
+		cmd.Dir = dir
 
 		var b bytes.Buffer
 		w := gio.Safe(&b)
 		fields := log.Fields{"hook": step}
+		if hook.Image != "" {
+			fields["image"] = hook.Image
+		}
 		cmd.Stderr = io.MultiWriter(logext.NewWriter(fields, logext.Error), w)
 		cmd.Stdout = io.MultiWriter(logext.NewWriter(fields, logext.Info), w)
 
-		log.WithFields(fields).Info("running")
+		log.FromContext(ctx).WithFields(fields).Info("running")
 		if err := cmd.Run(); err != nil {
 			return fmt.Errorf("hook failed: %s: %w; output: %s", step, err, b.String())
 		}
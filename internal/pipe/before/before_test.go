@@ -22,12 +22,12 @@ func TestDescription(t *testing.T) {
 }
 
 func TestRunPipe(t *testing.T) {
-	for _, tc := range [][]string{
+	for _, tc := range []config.Hooks{
 		nil,
 		{},
-		{"go version"},
-		{"go version", "go list"},
-		{`bash -c "go version; echo \"lala spaces and such\""`},
+		{{Cmd: "go version"}},
+		{{Cmd: "go version"}, {Cmd: "go list"}},
+		{{Cmd: `bash -c "go version; echo \"lala spaces and such\""`}},
 	} {
 		ctx := context.New(
 			config.Project{
@@ -44,7 +44,7 @@ func TestRunPipeInvalidCommand(t *testing.T) {
 	ctx := context.New(
 		config.Project{
 			Before: config.Before{
-				Hooks: []string{`bash -c "echo \"unterminated command\"`},
+				Hooks: config.Hooks{{Cmd: `bash -c "echo \"unterminated command\"`}},
 			},
 		},
 	)
@@ -52,9 +52,9 @@ func TestRunPipeInvalidCommand(t *testing.T) {
 }
 
 func TestRunPipeFail(t *testing.T) {
-	for err, tc := range map[string][]string{
-		"hook failed: go tool foobar: exit status 2; output: go tool: no such tool \"foobar\"\n": {"go tool foobar"},
-		"hook failed: sh ./testdata/foo.sh: exit status 1; output: lalala\n":                     {"sh ./testdata/foo.sh"},
+	for err, tc := range map[string]config.Hooks{
+		"hook failed: go tool foobar: exit status 2; output: go tool: no such tool \"foobar\"\n": {{Cmd: "go tool foobar"}},
+		"hook failed: sh ./testdata/foo.sh: exit status 1; output: lalala\n":                     {{Cmd: "sh ./testdata/foo.sh"}},
 	} {
 		ctx := context.New(
 			config.Project{
@@ -75,18 +75,48 @@ func TestRunWithEnv(t *testing.T) {
 				"TEST_FILE=" + f,
 			},
 			Before: config.Before{
-				Hooks: []string{"touch {{ .Env.TEST_FILE }}"},
+				Hooks: config.Hooks{{Cmd: "touch {{ .Env.TEST_FILE }}"}},
 			},
 		},
 	)))
 	require.FileExists(t, f)
 }
 
+func TestRunWithDirAndHookEnv(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, Pipe{}.Run(context.New(
+		config.Project{
+			Before: config.Before{
+				Hooks: config.Hooks{{
+					Cmd: "sh -c \"touch $FILE_NAME\"",
+					Dir: dir,
+					Env: []string{"FILE_NAME=testfile"},
+				}},
+			},
+		},
+	)))
+	require.FileExists(t, filepath.Join(dir, "testfile"))
+}
+
+func TestRunInvalidContainerRuntime(t *testing.T) {
+	require.EqualError(t, Pipe{}.Run(context.New(
+		config.Project{
+			Before: config.Before{
+				Hooks: config.Hooks{{
+					Cmd:   "npm run build-docs",
+					Image: "node:16",
+					Use:   "nope",
+				}},
+			},
+		},
+	)), `invalid container runtime "nope", valid options are docker and podman`)
+}
+
 func TestInvalidTemplate(t *testing.T) {
 	require.EqualError(t, Pipe{}.Run(context.New(
 		config.Project{
 			Before: config.Before{
-				Hooks: []string{"touch {{ .fasdsd }"},
+				Hooks: config.Hooks{{Cmd: "touch {{ .fasdsd }"}},
 			},
 		},
 	)), `template: tmpl:1: unexpected "}" in operand`)
@@ -100,7 +130,7 @@ func TestSkip(t *testing.T) {
 	t.Run("dont skip", func(t *testing.T) {
 		ctx := context.New(config.Project{
 			Before: config.Before{
-				Hooks: []string{""},
+				Hooks: config.Hooks{{}},
 			},
 		})
 		require.False(t, Pipe{}.Skip(ctx))
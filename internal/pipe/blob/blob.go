@@ -4,6 +4,9 @@ package blob
 import (
 	"fmt"
 
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/fallback"
+	"github.com/goreleaser/goreleaser/internal/ids"
 	"github.com/goreleaser/goreleaser/internal/semerrgroup"
 	"github.com/goreleaser/goreleaser/pkg/context"
 )
@@ -17,8 +20,12 @@ func (Pipe) Skip(ctx *context.Context) bool { return len(ctx.Config.Blobs) == 0
 
 // Default sets the pipe defaults.
 func (Pipe) Default(ctx *context.Context) error {
+	ids := ids.New("blobs")
 	for i := range ctx.Config.Blobs {
 		blob := &ctx.Config.Blobs[i]
+		if blob.ID != "" {
+			ids.Inc(blob.ID)
+		}
 
 		if blob.Bucket == "" || blob.Provider == "" {
 			return fmt.Errorf("bucket or provider cannot be empty")
@@ -26,18 +33,42 @@ func (Pipe) Default(ctx *context.Context) error {
 		if blob.Folder == "" {
 			blob.Folder = "{{ .ProjectName }}/{{ .Tag }}"
 		}
+		if _, err := artifact.ByConfig(blob.Include, blob.Exclude, ctx.Config.Platforms); err != nil {
+			return fmt.Errorf("blob: %w", err)
+		}
+		if err := validateCredentials(*blob); err != nil {
+			return err
+		}
+		if err := validateSSE(*blob); err != nil {
+			return err
+		}
 	}
-	return nil
+	if err := ids.Validate(); err != nil {
+		return err
+	}
+	return targets(ctx).Validate()
 }
 
 // Publish to specified blob bucket url.
 func (Pipe) Publish(ctx *context.Context) error {
+	targets := targets(ctx)
 	g := semerrgroup.New(ctx.Parallelism)
-	for _, conf := range ctx.Config.Blobs {
-		conf := conf
+	for _, i := range targets.Primary() {
+		i := i
 		g.Go(func() error {
-			return doUpload(ctx, conf)
+			return targets.Run(ctx, i, func(i int) error {
+				return doUpload(ctx, ctx.Config.Blobs[i])
+			})
 		})
 	}
 	return g.Wait()
 }
+
+func targets(ctx *context.Context) fallback.Targets {
+	targets := fallback.Targets{Kind: "blob"}
+	for _, blob := range ctx.Config.Blobs {
+		targets.Names = append(targets.Names, blob.ID)
+		targets.Fallbacks = append(targets.Fallbacks, blob.Fallback)
+	}
+	return targets
+}
@@ -66,6 +66,10 @@ func TestMinioUpload(t *testing.T) {
 	require.NoError(t, os.WriteFile(debpath, []byte("fake\ndeb"), 0o744))
 	require.NoError(t, os.WriteFile(sigpath, []byte("fake\nsig"), 0o744))
 	require.NoError(t, os.WriteFile(certpath, []byte("fake\ncert"), 0o744))
+	sitepath := filepath.Join(folder, "site")
+	require.NoError(t, os.MkdirAll(filepath.Join(sitepath, "css"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(sitepath, "index.html"), []byte("fake\nhtml"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(sitepath, "css", "style.css"), []byte("fake\ncss"), 0o644))
 	ctx := context.New(config.Project{
 		Dist:        folder,
 		ProjectName: "testupload",
@@ -130,12 +134,22 @@ func TestMinioUpload(t *testing.T) {
 			artifact.ExtraID: "bar",
 		},
 	})
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Type: artifact.Directory,
+		Name: "docs",
+		Path: sitepath,
+		Extra: map[string]interface{}{
+			artifact.ExtraID: "foo",
+		},
+	})
 
 	setupBucket(t, name)
 	require.NoError(t, Pipe{}.Default(ctx))
 	require.NoError(t, Pipe{}.Publish(ctx))
 
 	require.Subset(t, getFiles(t, ctx, ctx.Config.Blobs[0]), []string{
+		"testupload/v1.0.0/docs/index.html",
+		"testupload/v1.0.0/docs/css/style.css",
 		"testupload/v1.0.0/bin.deb",
 		"testupload/v1.0.0/bin.tar.gz",
 		"testupload/v1.0.0/checksum.txt",
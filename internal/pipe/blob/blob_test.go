@@ -1,7 +1,9 @@
 package blob
 
 import (
-	"fmt"
+	"errors"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/goreleaser/goreleaser/pkg/config"
@@ -27,7 +29,7 @@ func TestErrors(t *testing.T) {
 		"other":                        "failed to write to bucket: other",
 	} {
 		t.Run(k, func(t *testing.T) {
-			require.EqualError(t, handleError(fmt.Errorf(k), "someurl"), v)
+			require.EqualError(t, handleError(errors.New(k), "someurl"), v)
 		})
 	}
 }
@@ -52,6 +54,116 @@ func TestDefaultsNoBucket(t *testing.T) {
 	require.EqualError(t, Pipe{}.Default(ctx), errorString)
 }
 
+func TestDefaultsInvalidFilters(t *testing.T) {
+	ctx := context.New(config.Project{
+		Blobs: []config.Blob{
+			{
+				Bucket:   "goreleaser-bucket",
+				Provider: "s3",
+				Include: config.ArtifactFilters{
+					Types: []string{"nope"},
+				},
+			},
+		},
+	})
+	require.EqualError(t, Pipe{}.Default(ctx), `blob: invalid artifact type: "nope"`)
+}
+
+func TestDefaultsInvalidFallback(t *testing.T) {
+	ctx := context.New(config.Project{
+		Blobs: []config.Blob{
+			{
+				ID:       "primary",
+				Bucket:   "goreleaser-bucket",
+				Provider: "s3",
+				Fallback: "secondary",
+			},
+		},
+	})
+	require.EqualError(t, Pipe{}.Default(ctx), `blob: fallback "secondary" of "primary" does not exist`)
+}
+
+func TestDefaultsFallbackWithoutID(t *testing.T) {
+	ctx := context.New(config.Project{
+		Blobs: []config.Blob{
+			{
+				Bucket:   "goreleaser-bucket",
+				Provider: "s3",
+				Fallback: "secondary",
+			},
+			{
+				ID:       "secondary",
+				Bucket:   "goreleaser-bucket-2",
+				Provider: "s3",
+			},
+		},
+	})
+	require.EqualError(t, Pipe{}.Default(ctx), `blob: fallback "secondary" is set on a blob without an id`)
+}
+
+func TestDefaultsDuplicatedID(t *testing.T) {
+	ctx := context.New(config.Project{
+		Blobs: []config.Blob{
+			{
+				ID:       "primary",
+				Bucket:   "goreleaser-bucket",
+				Provider: "s3",
+			},
+			{
+				ID:       "primary",
+				Bucket:   "goreleaser-bucket-2",
+				Provider: "s3",
+			},
+			{
+				Bucket:   "goreleaser-bucket-3",
+				Provider: "s3",
+			},
+			{
+				Bucket:   "goreleaser-bucket-4",
+				Provider: "s3",
+			},
+		},
+	})
+	require.EqualError(t, Pipe{}.Default(ctx), "found 2 blobs with the ID 'primary', please fix your config")
+}
+
+func TestDefaultsInvalidCredentials(t *testing.T) {
+	for name, tt := range map[string]struct {
+		blob config.Blob
+		err  string
+	}{
+		"wrong provider": {
+			blob: config.Blob{
+				Provider:    "gs",
+				Credentials: config.BlobCredentials{AccessKeyID: "foo", SecretAccessKey: "bar"},
+			},
+			err: "blob: credentials don't match the gs provider",
+		},
+		"missing secret": {
+			blob: config.Blob{
+				Provider:    "s3",
+				Credentials: config.BlobCredentials{AccessKeyID: "foo"},
+			},
+			err: "blob: access_key_id and secret_access_key are required",
+		},
+		"missing azure key": {
+			blob: config.Blob{
+				Provider:    "azblob",
+				Credentials: config.BlobCredentials{AzureAccount: "foo"},
+			},
+			err: "blob: azure_account and azure_key are required",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			tt.blob.Bucket = "goreleaser-bucket"
+			ctx := context.New(config.Project{
+				Blobs: []config.Blob{tt.blob},
+			})
+			require.EqualError(t, Pipe{}.Default(ctx), tt.err)
+		})
+	}
+}
+
 func TestDefaultsNoProvider(t *testing.T) {
 	errorString := "bucket or provider cannot be empty"
 	ctx := context.New(config.Project{
@@ -183,3 +295,18 @@ func TestSkip(t *testing.T) {
 		require.False(t, Pipe{}.Skip(ctx))
 	})
 }
+
+func TestDirFiles(t *testing.T) {
+	folder := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(folder, "a", "b"), 0o755))
+	require.NoError(t, os.MkdirAll(filepath.Join(folder, "empty"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(folder, "index.html"), []byte("html"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(folder, "a", "b", "c.css"), []byte("css"), 0o644))
+
+	files, err := dirFiles(folder)
+	require.NoError(t, err)
+	require.Equal(t, []string{"a/b/c.css", "index.html"}, files)
+
+	_, err = dirFiles(filepath.Join(folder, "nope"))
+	require.Error(t, err)
+}
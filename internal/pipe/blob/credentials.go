@@ -0,0 +1,122 @@
+package blob
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/container"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"gocloud.dev/blob"
+	"gocloud.dev/blob/azureblob"
+	"gocloud.dev/blob/gcsblob"
+	"gocloud.dev/blob/s3blob"
+	"gocloud.dev/gcp"
+	"golang.org/x/oauth2/google"
+)
+
+const googleScope = "https://www.googleapis.com/auth/cloud-platform"
+
+// hasCredentials tells whether the blob overrides the credentials from the
+// environment.
+func hasCredentials(creds config.BlobCredentials) bool {
+	return creds != config.BlobCredentials{}
+}
+
+// validateCredentials checks that the credentials match the blob provider.
+func validateCredentials(conf config.Blob) error {
+	creds := conf.Credentials
+	s3 := creds.AccessKeyID != "" || creds.SecretAccessKey != "" || creds.SessionToken != ""
+	gs := creds.GoogleCredentialsFile != ""
+	azure := creds.AzureAccount != "" || creds.AzureKey != ""
+	switch {
+	case s3 && conf.Provider != "s3",
+		gs && conf.Provider != "gs",
+		azure && conf.Provider != "azblob":
+		return fmt.Errorf("blob: credentials don't match the %s provider", conf.Provider)
+	case s3 && (creds.AccessKeyID == "" || creds.SecretAccessKey == ""):
+		return fmt.Errorf("blob: access_key_id and secret_access_key are required")
+	case azure && (creds.AzureAccount == "" || creds.AzureKey == ""):
+		return fmt.Errorf("blob: azure_account and azure_key are required")
+	}
+	return nil
+}
+
+// openBucketWithCredentials opens the bucket using the credentials of the
+// given blob config.
+func openBucketWithCredentials(ctx *context.Context, conf config.Blob) (*blob.Bucket, error) {
+	t := tmpl.New(ctx)
+	var creds config.BlobCredentials
+	for _, f := range []struct {
+		in  string
+		out *string
+	}{
+		{conf.Bucket, &conf.Bucket},
+		{conf.Credentials.AccessKeyID, &creds.AccessKeyID},
+		{conf.Credentials.SecretAccessKey, &creds.SecretAccessKey},
+		{conf.Credentials.SessionToken, &creds.SessionToken},
+		{conf.Credentials.GoogleCredentialsFile, &creds.GoogleCredentialsFile},
+		{conf.Credentials.AzureAccount, &creds.AzureAccount},
+		{conf.Credentials.AzureKey, &creds.AzureKey},
+	} {
+		v, err := t.Apply(f.in)
+		if err != nil {
+			return nil, fmt.Errorf("blob: failed to apply template to credentials: %w", err)
+		}
+		*f.out = v
+	}
+
+	switch conf.Provider {
+	case "s3":
+		cfg := &aws.Config{
+			Credentials: credentials.NewStaticCredentials(creds.AccessKeyID, creds.SecretAccessKey, creds.SessionToken),
+		}
+		if conf.Region != "" {
+			cfg.Region = aws.String(conf.Region)
+		}
+		if conf.Endpoint != "" {
+			cfg.Endpoint = aws.String(conf.Endpoint)
+			cfg.S3ForcePathStyle = aws.Bool(true)
+		}
+		if conf.DisableSSL {
+			cfg.DisableSSL = aws.Bool(true)
+		}
+		sess, err := session.NewSession(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return s3blob.OpenBucket(ctx, sess, conf.Bucket, nil)
+	case "gs":
+		bts, err := os.ReadFile(creds.GoogleCredentialsFile)
+		if err != nil {
+			return nil, fmt.Errorf("blob: failed to read google credentials: %w", err)
+		}
+		gcreds, err := google.CredentialsFromJSON(ctx, bts, googleScope)
+		if err != nil {
+			return nil, fmt.Errorf("blob: invalid google credentials: %w", err)
+		}
+		client, err := gcp.NewHTTPClient(gcp.DefaultTransport(), gcp.CredentialsTokenSource(gcreds))
+		if err != nil {
+			return nil, err
+		}
+		return gcsblob.OpenBucket(ctx, client, conf.Bucket, nil)
+	case "azblob":
+		credential, err := azblob.NewSharedKeyCredential(creds.AzureAccount, creds.AzureKey)
+		if err != nil {
+			return nil, fmt.Errorf("blob: invalid azure credentials: %w", err)
+		}
+		url := fmt.Sprintf("https://%s.blob.core.windows.net/%s", creds.AzureAccount, conf.Bucket)
+		client, err := container.NewClientWithSharedKeyCredential(url, credential, nil)
+		if err != nil {
+			return nil, fmt.Errorf("blob: invalid azure credentials: %w", err)
+		}
+		return azureblob.OpenBucket(ctx, client, nil)
+	default:
+		return nil, fmt.Errorf("blob: credentials are not supported by the %s provider", conf.Provider)
+	}
+}
@@ -0,0 +1,154 @@
+package blob
+
+import (
+	"errors"
+	"fmt"
+
+	"cloud.google.com/go/storage"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"gocloud.dev/blob"
+)
+
+// Server side encryption types.
+const (
+	sseS3  = "sse-s3"
+	sseKMS = "sse-kms"
+	cmek   = "cmek"
+)
+
+// validateSSE checks that the server side encryption matches the blob
+// provider.
+func validateSSE(conf config.Blob) error {
+	sse := conf.ServerSideEncryption
+	switch {
+	case sse == config.BlobSSE{}:
+		return nil
+	case sse.Type == "":
+		return fmt.Errorf("blob: server_side_encryption.type is required")
+	case sse.Type != sseS3 && sse.Type != sseKMS && sse.Type != cmek:
+		return fmt.Errorf("blob: invalid server_side_encryption.type %q, valid options are %s, %s and %s", sse.Type, sseS3, sseKMS, cmek)
+	case (sse.Type == sseS3 || sse.Type == sseKMS) && conf.Provider != "s3",
+		sse.Type == cmek && conf.Provider != "gs":
+		return fmt.Errorf("blob: server side encryption %s can't be used with the %s provider", sse.Type, conf.Provider)
+	case sse.Type == sseS3 && sse.KMSKeyID != "":
+		return fmt.Errorf("blob: kms_key_id can't be used with %s", sseS3)
+	case sse.Type == cmek && sse.KMSKeyID == "":
+		return fmt.Errorf("blob: kms_key_id is required with %s", cmek)
+	}
+	return nil
+}
+
+// sseKeyID returns the KMS key ID of the server side encryption, with
+// templates applied.
+func sseKeyID(ctx *context.Context, sse config.BlobSSE) (string, error) {
+	key, err := tmpl.New(ctx).Apply(sse.KMSKeyID)
+	if err != nil {
+		return "", fmt.Errorf("blob: failed to apply template to kms_key_id: %w", err)
+	}
+	return key, nil
+}
+
+// beforeWrite returns the hook that asks the provider to encrypt the
+// uploaded files, if server side encryption is set.
+func beforeWrite(sse config.BlobSSE, key string) func(asFunc func(interface{}) bool) error {
+	if sse.Type == "" {
+		return nil
+	}
+	return func(asFunc func(interface{}) bool) error {
+		switch sse.Type {
+		case sseS3, sseKMS:
+			var input *s3manager.UploadInput
+			if !asFunc(&input) {
+				return fmt.Errorf("blob: failed to set server side encryption")
+			}
+			if sse.Type == sseS3 {
+				input.ServerSideEncryption = aws.String(s3.ServerSideEncryptionAes256)
+				return nil
+			}
+			input.ServerSideEncryption = aws.String(s3.ServerSideEncryptionAwsKms)
+			if key != "" {
+				input.SSEKMSKeyId = aws.String(key)
+			}
+		case cmek:
+			var w *storage.Writer
+			if !asFunc(&w) {
+				return fmt.Errorf("blob: failed to set server side encryption")
+			}
+			w.KMSKeyName = key
+		}
+		return nil
+	}
+}
+
+// verifySSE checks that the bucket encrypts the files uploaded to it by
+// default, as the server side encryption requires.
+func verifySSE(ctx *context.Context, bucket *blob.Bucket, name string, sse config.BlobSSE, key string) error {
+	switch sse.Type {
+	case sseS3, sseKMS:
+		var client *s3.S3
+		if !bucket.As(&client) {
+			return fmt.Errorf("blob: failed to verify the encryption of bucket %s", name)
+		}
+		out, err := client.GetBucketEncryptionWithContext(ctx, &s3.GetBucketEncryptionInput{
+			Bucket: aws.String(name),
+		})
+		var aerr awserr.Error
+		if errors.As(err, &aerr) && aerr.Code() == "ServerSideEncryptionConfigurationNotFoundError" {
+			return checkS3Encryption(name, nil, sse, key)
+		}
+		if err != nil {
+			return fmt.Errorf("blob: failed to verify the encryption of bucket %s: %w", name, err)
+		}
+		return checkS3Encryption(name, out.ServerSideEncryptionConfiguration, sse, key)
+	case cmek:
+		var client *storage.Client
+		if !bucket.As(&client) {
+			return fmt.Errorf("blob: failed to verify the encryption of bucket %s", name)
+		}
+		attrs, err := client.Bucket(name).Attrs(ctx)
+		if err != nil {
+			return fmt.Errorf("blob: failed to verify the encryption of bucket %s: %w", name, err)
+		}
+		return checkGCSEncryption(name, attrs.Encryption, key)
+	}
+	return nil
+}
+
+// checkS3Encryption checks that the default encryption of an s3 bucket
+// matches the server side encryption.
+func checkS3Encryption(name string, conf *s3.ServerSideEncryptionConfiguration, sse config.BlobSSE, key string) error {
+	algorithm := s3.ServerSideEncryptionAes256
+	if sse.Type == sseKMS {
+		algorithm = s3.ServerSideEncryptionAwsKms
+	}
+	if conf != nil {
+		for _, rule := range conf.Rules {
+			def := rule.ApplyServerSideEncryptionByDefault
+			if def == nil || aws.StringValue(def.SSEAlgorithm) != algorithm {
+				continue
+			}
+			if key == "" || aws.StringValue(def.KMSMasterKeyID) == key {
+				return nil
+			}
+		}
+	}
+	if key != "" {
+		return fmt.Errorf("blob: bucket %s does not enforce %s encryption with key %s", name, sse.Type, key)
+	}
+	return fmt.Errorf("blob: bucket %s does not enforce %s encryption", name, sse.Type)
+}
+
+// checkGCSEncryption checks that the default key of a gcs bucket is the
+// given one.
+func checkGCSEncryption(name string, enc *storage.BucketEncryption, key string) error {
+	if enc == nil || enc.DefaultKMSKeyName != key {
+		return fmt.Errorf("blob: bucket %s does not enforce %s encryption with key %s", name, cmek, key)
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+package blob
+
+import (
+	"testing"
+
+	"cloud.google.com/go/storage"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultsInvalidSSE(t *testing.T) {
+	for name, tt := range map[string]struct {
+		provider string
+		sse      config.BlobSSE
+		err      string
+	}{
+		"missing type": {
+			provider: "s3",
+			sse:      config.BlobSSE{KMSKeyID: "foo"},
+			err:      "blob: server_side_encryption.type is required",
+		},
+		"invalid type": {
+			provider: "s3",
+			sse:      config.BlobSSE{Type: "foo"},
+			err:      `blob: invalid server_side_encryption.type "foo", valid options are sse-s3, sse-kms and cmek`,
+		},
+		"s3 on gs": {
+			provider: "gs",
+			sse:      config.BlobSSE{Type: "sse-kms"},
+			err:      "blob: server side encryption sse-kms can't be used with the gs provider",
+		},
+		"cmek on s3": {
+			provider: "s3",
+			sse:      config.BlobSSE{Type: "cmek", KMSKeyID: "foo"},
+			err:      "blob: server side encryption cmek can't be used with the s3 provider",
+		},
+		"azure": {
+			provider: "azblob",
+			sse:      config.BlobSSE{Type: "sse-s3"},
+			err:      "blob: server side encryption sse-s3 can't be used with the azblob provider",
+		},
+		"sse-s3 with key": {
+			provider: "s3",
+			sse:      config.BlobSSE{Type: "sse-s3", KMSKeyID: "foo"},
+			err:      "blob: kms_key_id can't be used with sse-s3",
+		},
+		"cmek without key": {
+			provider: "gs",
+			sse:      config.BlobSSE{Type: "cmek"},
+			err:      "blob: kms_key_id is required with cmek",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.New(config.Project{
+				Blobs: []config.Blob{{
+					Bucket:               "goreleaser-bucket",
+					Provider:             tt.provider,
+					ServerSideEncryption: tt.sse,
+				}},
+			})
+			require.EqualError(t, Pipe{}.Default(ctx), tt.err)
+		})
+	}
+}
+
+func TestDefaultsSSE(t *testing.T) {
+	ctx := context.New(config.Project{
+		Blobs: []config.Blob{
+			{Bucket: "a", Provider: "s3", ServerSideEncryption: config.BlobSSE{Type: "sse-s3"}},
+			{Bucket: "b", Provider: "s3", ServerSideEncryption: config.BlobSSE{Type: "sse-kms"}},
+			{Bucket: "c", Provider: "s3", ServerSideEncryption: config.BlobSSE{Type: "sse-kms", KMSKeyID: "foo"}},
+			{Bucket: "d", Provider: "gs", ServerSideEncryption: config.BlobSSE{Type: "cmek", KMSKeyID: "foo"}},
+		},
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+}
+
+func TestSSEKeyID(t *testing.T) {
+	ctx := context.New(config.Project{})
+	ctx.Env["KMS_KEY"] = "arn:aws:kms:us-east-1:123:key/abc"
+	key, err := sseKeyID(ctx, config.BlobSSE{KMSKeyID: "{{ .Env.KMS_KEY }}"})
+	require.NoError(t, err)
+	require.Equal(t, "arn:aws:kms:us-east-1:123:key/abc", key)
+
+	_, err = sseKeyID(ctx, config.BlobSSE{KMSKeyID: "{{ .Env.NOPE }}"})
+	require.Error(t, err)
+}
+
+func TestBeforeWrite(t *testing.T) {
+	t.Run("none", func(t *testing.T) {
+		require.Nil(t, beforeWrite(config.BlobSSE{}, ""))
+	})
+
+	s3AsFunc := func(input *s3manager.UploadInput) func(interface{}) bool {
+		return func(i interface{}) bool {
+			p, ok := i.(**s3manager.UploadInput)
+			if ok {
+				*p = input
+			}
+			return ok
+		}
+	}
+
+	t.Run("sse-s3", func(t *testing.T) {
+		input := &s3manager.UploadInput{}
+		require.NoError(t, beforeWrite(config.BlobSSE{Type: "sse-s3"}, "")(s3AsFunc(input)))
+		require.Equal(t, "AES256", aws.StringValue(input.ServerSideEncryption))
+		require.Nil(t, input.SSEKMSKeyId)
+	})
+
+	t.Run("sse-kms", func(t *testing.T) {
+		input := &s3manager.UploadInput{}
+		require.NoError(t, beforeWrite(config.BlobSSE{Type: "sse-kms"}, "")(s3AsFunc(input)))
+		require.Equal(t, "aws:kms", aws.StringValue(input.ServerSideEncryption))
+		require.Nil(t, input.SSEKMSKeyId)
+	})
+
+	t.Run("sse-kms with key", func(t *testing.T) {
+		input := &s3manager.UploadInput{}
+		require.NoError(t, beforeWrite(config.BlobSSE{Type: "sse-kms"}, "foo")(s3AsFunc(input)))
+		require.Equal(t, "aws:kms", aws.StringValue(input.ServerSideEncryption))
+		require.Equal(t, "foo", aws.StringValue(input.SSEKMSKeyId))
+	})
+
+	t.Run("cmek", func(t *testing.T) {
+		w := &storage.Writer{}
+		require.NoError(t, beforeWrite(config.BlobSSE{Type: "cmek"}, "foo")(func(i interface{}) bool {
+			p, ok := i.(**storage.Writer)
+			if ok {
+				*p = w
+			}
+			return ok
+		}))
+		require.Equal(t, "foo", w.KMSKeyName)
+	})
+
+	t.Run("unsupported driver", func(t *testing.T) {
+		err := beforeWrite(config.BlobSSE{Type: "sse-s3"}, "")(func(interface{}) bool { return false })
+		require.EqualError(t, err, "blob: failed to set server side encryption")
+	})
+}
+
+func TestCheckS3Encryption(t *testing.T) {
+	rule := func(algorithm, key string) *s3.ServerSideEncryptionConfiguration {
+		def := &s3.ServerSideEncryptionByDefault{SSEAlgorithm: aws.String(algorithm)}
+		if key != "" {
+			def.KMSMasterKeyID = aws.String(key)
+		}
+		return &s3.ServerSideEncryptionConfiguration{
+			Rules: []*s3.ServerSideEncryptionRule{{ApplyServerSideEncryptionByDefault: def}},
+		}
+	}
+	sseS3 := config.BlobSSE{Type: "sse-s3"}
+	sseKMS := config.BlobSSE{Type: "sse-kms"}
+
+	require.NoError(t, checkS3Encryption("foo", rule("AES256", ""), sseS3, ""))
+	require.NoError(t, checkS3Encryption("foo", rule("aws:kms", ""), sseKMS, ""))
+	require.NoError(t, checkS3Encryption("foo", rule("aws:kms", "key"), sseKMS, ""))
+	require.NoError(t, checkS3Encryption("foo", rule("aws:kms", "key"), sseKMS, "key"))
+
+	require.EqualError(t, checkS3Encryption("foo", nil, sseS3, ""), "blob: bucket foo does not enforce sse-s3 encryption")
+	require.EqualError(t, checkS3Encryption("foo", rule("AES256", ""), sseKMS, ""), "blob: bucket foo does not enforce sse-kms encryption")
+	require.EqualError(t, checkS3Encryption("foo", rule("aws:kms", "other"), sseKMS, "key"), "blob: bucket foo does not enforce sse-kms encryption with key key")
+}
+
+func TestCheckGCSEncryption(t *testing.T) {
+	require.NoError(t, checkGCSEncryption("foo", &storage.BucketEncryption{DefaultKMSKeyName: "key"}, "key"))
+	require.EqualError(t, checkGCSEncryption("foo", nil, "key"), "blob: bucket foo does not enforce cmek encryption with key key")
+	require.EqualError(t, checkGCSEncryption("foo", &storage.BucketEncryption{DefaultKMSKeyName: "other"}, "key"), "blob: bucket foo does not enforce cmek encryption with key key")
+}
@@ -3,14 +3,15 @@ package blob
 import (
 	"fmt"
 	"io"
-	"net/url"
 	"os"
 	"path"
+	"path/filepath"
 	"strings"
 
 	"github.com/apex/log"
 	"github.com/goreleaser/goreleaser/internal/artifact"
 	"github.com/goreleaser/goreleaser/internal/extrafiles"
+	"github.com/goreleaser/goreleaser/internal/pkgrepo"
 	"github.com/goreleaser/goreleaser/internal/semerrgroup"
 	"github.com/goreleaser/goreleaser/internal/tmpl"
 	"github.com/goreleaser/goreleaser/pkg/config"
@@ -30,34 +31,13 @@ import (
 )
 
 func urlFor(ctx *context.Context, conf config.Blob) (string, error) {
-	bucket, err := tmpl.New(ctx).Apply(conf.Bucket)
-	if err != nil {
-		return "", err
-	}
-
-	bucketURL := fmt.Sprintf("%s://%s", conf.Provider, bucket)
-
-	if conf.Provider != "s3" {
-		return bucketURL, nil
-	}
-
-	query := url.Values{}
-	if conf.Endpoint != "" {
-		query.Add("endpoint", conf.Endpoint)
-		query.Add("s3ForcePathStyle", "true")
-	}
-	if conf.Region != "" {
-		query.Add("region", conf.Region)
-	}
-	if conf.DisableSSL {
-		query.Add("disableSSL", "true")
-	}
-
-	if len(query) > 0 {
-		bucketURL = bucketURL + "?" + query.Encode()
-	}
-
-	return bucketURL, nil
+	return pkgrepo.BlobURL(ctx, config.RepositoryBlob{
+		Provider:   conf.Provider,
+		Bucket:     conf.Bucket,
+		Region:     conf.Region,
+		Endpoint:   conf.Endpoint,
+		DisableSSL: conf.DisableSSL,
+	})
 }
 
 // Takes goreleaser context(which includes artificats) and bucketURL for
@@ -77,19 +57,35 @@ func doUpload(ctx *context.Context, conf config.Blob) error {
 
 	filter := artifact.Or(
 		artifact.ByType(artifact.UploadableArchive),
+		artifact.ByType(artifact.UploadableArchivePart),
 		artifact.ByType(artifact.UploadableBinary),
 		artifact.ByType(artifact.UploadableSourceArchive),
 		artifact.ByType(artifact.Checksum),
 		artifact.ByType(artifact.Signature),
 		artifact.ByType(artifact.Certificate),
 		artifact.ByType(artifact.LinuxPackage),
+		artifact.ByType(artifact.AppImage),
+		artifact.ByType(artifact.MSI),
+		artifact.ByType(artifact.DMG),
 		artifact.ByType(artifact.SBOM),
+		artifact.ByType(artifact.Provenance),
+		artifact.ByType(artifact.Attestation),
 	)
 	if len(conf.IDs) > 0 {
 		filter = artifact.And(filter, artifact.ByIDs(conf.IDs...))
 	}
+	selected, err := artifact.ByConfig(conf.Include, conf.Exclude, ctx.Config.Platforms)
+	if err != nil {
+		return fmt.Errorf("blob: %w", err)
+	}
+	filter = artifact.And(filter, selected)
+
+	dirFilter := artifact.And(artifact.ByType(artifact.Directory), selected)
+	if len(conf.IDs) > 0 {
+		dirFilter = artifact.And(dirFilter, artifact.ByIDs(conf.IDs...))
+	}
 
-	up := &productionUploader{}
+	up := &productionUploader{conf: conf}
 	if err := up.Open(ctx, bucketURL); err != nil {
 		return handleError(err, bucketURL)
 	}
@@ -107,6 +103,20 @@ func doUpload(ctx *context.Context, conf config.Blob) error {
 		})
 	}
 
+	for _, dir := range ctx.Artifacts.Filter(dirFilter).List() {
+		files, err := dirFiles(dir.Path)
+		if err != nil {
+			return err
+		}
+		for _, file := range files {
+			dataFile := filepath.Join(dir.Path, filepath.FromSlash(file))
+			uploadFile := path.Join(folder, dir.Name, file)
+			g.Go(func() error {
+				return uploadData(ctx, conf, up, dataFile, uploadFile, bucketURL)
+			})
+		}
+	}
+
 	files, err := extrafiles.Find(ctx, conf.ExtraFiles)
 	if err != nil {
 		return err
@@ -126,6 +136,30 @@ func doUpload(ctx *context.Context, conf config.Blob) error {
 	return g.Wait()
 }
 
+// dirFiles returns the slash separated paths of all the files inside the
+// given directory, relative to it.
+func dirFiles(dir string) ([]string, error) {
+	var files []string
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		files = append(files, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files of %s: %w", dir, err)
+	}
+	return files, nil
+}
+
 func uploadData(ctx *context.Context, conf config.Blob, up uploader, dataFile, uploadFile, bucketURL string) error {
 	data, err := getData(ctx, conf, dataFile)
 	if err != nil {
@@ -199,7 +233,9 @@ type uploader interface {
 
 // productionUploader actually do upload to.
 type productionUploader struct {
+	conf   config.Blob
 	bucket *blob.Bucket
+	sseKey string
 }
 
 func (u *productionUploader) Close() error {
@@ -210,23 +246,45 @@ func (u *productionUploader) Close() error {
 }
 
 func (u *productionUploader) Open(ctx *context.Context, bucket string) error {
-	log.WithFields(log.Fields{
+	log.FromContext(ctx).WithFields(log.Fields{
 		"bucket": bucket,
 	}).Debug("uploading")
 
-	conn, err := blob.OpenBucket(ctx, bucket)
+	var conn *blob.Bucket
+	var err error
+	if hasCredentials(u.conf.Credentials) {
+		conn, err = openBucketWithCredentials(ctx, u.conf)
+	} else {
+		conn, err = blob.OpenBucket(ctx, bucket)
+	}
 	if err != nil {
 		return err
 	}
 	u.bucket = conn
-	return nil
+
+	sse := u.conf.ServerSideEncryption
+	if sse.Type == "" {
+		return nil
+	}
+	if u.sseKey, err = sseKeyID(ctx, sse); err != nil {
+		return err
+	}
+	if !sse.Verify {
+		return nil
+	}
+	name, err := tmpl.New(ctx).Apply(u.conf.Bucket)
+	if err != nil {
+		return err
+	}
+	return verifySSE(ctx, conn, name, sse, u.sseKey)
 }
 
 func (u *productionUploader) Upload(ctx *context.Context, filepath string, data []byte) error {
-	log.WithField("path", filepath).Info("uploading")
+	log.FromContext(ctx).WithField("path", filepath).Info("uploading")
 
 	opts := &blob.WriterOptions{
 		ContentDisposition: "attachment; filename=" + path.Base(filepath),
+		BeforeWrite:        beforeWrite(u.conf.ServerSideEncryption, u.sseKey),
 	}
 	w, err := u.bucket.NewWriter(ctx, filepath, opts)
 	if err != nil {
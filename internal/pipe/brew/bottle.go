@@ -0,0 +1,170 @@
+package brew
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/gio"
+	"github.com/goreleaser/goreleaser/internal/installfiles"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/archive/tar"
+	"github.com/goreleaser/goreleaser/pkg/archive/targz"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+)
+
+// bottleTagsFor returns the homebrew bottle tags the given archive can be
+// poured on, or none if homebrew has no bottles for its platform.
+func bottleTagsFor(cfg config.Homebrew, art *artifact.Artifact) []string {
+	var tags []string
+	switch art.Goos + art.Goarch {
+	case "darwinamd64":
+		tags = append(tags, cfg.Bottles.MacOSVersions...)
+	case "darwinarm64":
+		for _, version := range cfg.Bottles.MacOSVersions {
+			tags = append(tags, "arm64_"+version)
+		}
+	case "darwinall":
+		for _, version := range cfg.Bottles.MacOSVersions {
+			tags = append(tags, "arm64_"+version, version)
+		}
+	case "linuxamd64":
+		tags = append(tags, "x86_64_linux")
+	case "linuxarm64":
+		tags = append(tags, "arm64_linux")
+	}
+	return tags
+}
+
+// bottleFilesFor returns the files of the keg poured from the bottle of the
+// given archive.
+func bottleFilesFor(art *artifact.Artifact, extra []installfiles.File) []config.File {
+	var files []config.File
+	switch art.Type {
+	case artifact.UploadableBinary:
+		files = append(files, config.File{
+			Source:      art.Path,
+			Destination: path.Join("bin", art.ExtraOr(artifact.ExtraBinary, art.Name).(string)),
+			Info:        config.FileInfo{Mode: 0o755},
+		})
+	case artifact.UploadableArchive:
+		for _, binary := range art.ExtraOr(artifact.ExtraBuilds, []*artifact.Artifact{}).([]*artifact.Artifact) {
+			files = append(files, config.File{
+				Source:      binary.Path,
+				Destination: path.Join("bin", binary.Name),
+				Info:        config.FileInfo{Mode: 0o755},
+			})
+		}
+		for _, f := range extra {
+			files = append(files, config.File{
+				Source:      f.Source,
+				Destination: f.KegPath(),
+				Info:        config.FileInfo{Mode: 0o644},
+			})
+		}
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Destination < files[j].Destination
+	})
+	return files
+}
+
+// makeBottles creates the bottles of the formula, one for each bottle tag of
+// each archive, and adds them to the artifacts, so they are released along
+// with the archives.
+// It returns the bottles and the URL they are downloaded from.
+func makeBottles(ctx *context.Context, cfg config.Homebrew, archives []*artifact.Artifact, extra []installfiles.File) ([]bottle, string, error) {
+	var bottles []bottle
+	var rootURL string
+	for _, art := range archives {
+		tags := bottleTagsFor(cfg, art)
+		if len(tags) == 0 {
+			log.FromContext(ctx).WithField("archive", art.Name).Warn("homebrew has no bottles for this platform, skipping")
+			continue
+		}
+
+		var sum, first string
+		for _, tag := range tags {
+			name := fmt.Sprintf("%s--%s.%s.bottle.tar.gz", cfg.Name, ctx.Version, tag)
+			bottlePath := filepath.Join(ctx.Config.Dist, name)
+			if first == "" {
+				log.FromContext(ctx).WithField("bottle", bottlePath).Info("creating")
+				if err := makeBottle(ctx, cfg, bottlePath, bottleFilesFor(art, extra)); err != nil {
+					return nil, "", fmt.Errorf("failed to create bottle: %w", err)
+				}
+				first = bottlePath
+			} else if err := gio.Copy(first, bottlePath); err != nil {
+				return nil, "", fmt.Errorf("failed to create bottle: %w", err)
+			}
+
+			bottleArt := &artifact.Artifact{
+				Type:   artifact.BrewBottle,
+				Name:   name,
+				Path:   bottlePath,
+				Goos:   art.Goos,
+				Goarch: art.Goarch,
+				Extra: map[string]interface{}{
+					artifact.ExtraID: art.ID(),
+				},
+			}
+			if sum == "" {
+				var err error
+				if sum, err = bottleArt.Checksum("sha256"); err != nil {
+					return nil, "", err
+				}
+				if rootURL, err = bottleRootURL(ctx, cfg, bottleArt); err != nil {
+					return nil, "", err
+				}
+			}
+			ctx.Artifacts.Add(bottleArt)
+			bottles = append(bottles, bottle{Tag: tag, SHA256: sum})
+		}
+	}
+	sort.SliceStable(bottles, func(i, j int) bool {
+		return bottles[i].Tag < bottles[j].Tag
+	})
+	return bottles, rootURL, nil
+}
+
+// makeBottle creates a bottle with the given files, inside the keg of the
+// formula version.
+func makeBottle(ctx *context.Context, cfg config.Homebrew, bottlePath string, files []config.File) error {
+	f, err := os.Create(bottlePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	archive := targz.NewWithOptions(f, tar.Options{Reproducible: true})
+	keg := path.Join(cfg.Name, ctx.Version)
+	for _, file := range files {
+		file.Destination = path.Join(keg, file.Destination)
+		file.Info.MTime = ctx.Date
+		if err := archive.Add(file); err != nil {
+			return err
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// bottleRootURL returns the URL the bottles are downloaded from, which
+// defaults to where the given bottle is released.
+func bottleRootURL(ctx *context.Context, cfg config.Homebrew, bottle *artifact.Artifact) (string, error) {
+	if cfg.Bottles.RootURL != "" {
+		url, err := tmpl.New(ctx).Apply(cfg.Bottles.RootURL)
+		return strings.TrimSuffix(url, "/"), err
+	}
+	url, err := tmpl.New(ctx).WithArtifact(bottle, map[string]string{}).Apply(cfg.URLTemplate)
+	if err != nil {
+		return "", err
+	}
+	return url[:strings.LastIndex(url, "/")], nil
+}
@@ -16,6 +16,7 @@ import (
 	"github.com/goreleaser/goreleaser/internal/artifact"
 	"github.com/goreleaser/goreleaser/internal/client"
 	"github.com/goreleaser/goreleaser/internal/commitauthor"
+	"github.com/goreleaser/goreleaser/internal/installfiles"
 	"github.com/goreleaser/goreleaser/internal/pipe"
 	"github.com/goreleaser/goreleaser/internal/tmpl"
 	"github.com/goreleaser/goreleaser/pkg/config"
@@ -51,9 +52,15 @@ func (Pipe) Default(ctx *context.Context) error {
 		if brew.Name == "" {
 			brew.Name = ctx.Config.ProjectName
 		}
+		if err := commitauthor.CheckSigning(ctx, brew.CommitAuthor); err != nil {
+			return fmt.Errorf("brew %s: %w", brew.Name, err)
+		}
 		if brew.Goarm == "" {
 			brew.Goarm = "6"
 		}
+		if brew.Bottles.Enabled && len(brew.Bottles.MacOSVersions) == 0 {
+			brew.Bottles.MacOSVersions = []string{"ventura", "monterey", "big_sur"}
+		}
 	}
 
 	return nil
@@ -123,7 +130,7 @@ func doPublish(ctx *context.Context, formula *artifact.Artifact, cl client.Clien
 	repo := client.RepoFromRef(brew.Tap)
 
 	gpath := buildFormulaPath(brew.Folder, formula.Name)
-	log.WithField("formula", gpath).
+	log.FromContext(ctx).WithField("formula", gpath).
 		WithField("repo", repo.String()).
 		Info("pushing")
 
@@ -173,6 +180,7 @@ func doRun(ctx *context.Context, brew config.Homebrew, cl client.Client) error {
 			artifact.ByType(artifact.UploadableBinary),
 		),
 		artifact.OnlyReplacingUnibins,
+		artifact.OnlyUnencrypted,
 	}
 	if len(brew.IDs) > 0 {
 		filters = append(filters, artifact.ByIDs(brew.IDs...))
@@ -214,7 +222,7 @@ func doRun(ctx *context.Context, brew config.Homebrew, cl client.Client) error {
 
 	filename := brew.Name + ".rb"
 	path := filepath.Join(ctx.Config.Dist, filename)
-	log.WithField("formula", path).Info("writing")
+	log.FromContext(ctx).WithField("formula", path).Info("writing")
 	if err := os.WriteFile(path, []byte(content), 0o644); err != nil { //nolint: gosec
 		return fmt.Errorf("failed to write brew formula: %w", err)
 	}
@@ -281,7 +289,7 @@ func doBuildFormula(ctx *context.Context, data templateData) (string, error) {
 	return out.String(), nil
 }
 
-func installs(cfg config.Homebrew, art *artifact.Artifact) []string {
+func installs(ctx *context.Context, cfg config.Homebrew, art *artifact.Artifact, extra []installfiles.File) []string {
 	if cfg.Install != "" {
 		return split(cfg.Install)
 	}
@@ -296,11 +304,14 @@ func installs(cfg config.Homebrew, art *artifact.Artifact) []string {
 		for _, bin := range art.ExtraOr(artifact.ExtraBinaries, []string{}).([]string) {
 			install[fmt.Sprintf("bin.install %q", bin)] = true
 		}
+		for _, f := range extra {
+			install[f.BrewInstall()] = true
+		}
 	}
 
 	result := keys(install)
 	sort.Strings(result)
-	log.Warnf("guessing install to be %q", strings.Join(result, ", "))
+	log.FromContext(ctx).Warnf("guessing install to be %q", strings.Join(result, ", "))
 	return result
 }
 
@@ -320,7 +331,6 @@ func dataFor(ctx *context.Context, cfg config.Homebrew, cl client.Client, artifa
 		Version:       ctx.Version,
 		License:       cfg.License,
 		Caveats:       split(cfg.Caveats),
-		Dependencies:  cfg.Dependencies,
 		Conflicts:     cfg.Conflicts,
 		Plist:         cfg.Plist,
 		PostInstall:   cfg.PostInstall,
@@ -329,6 +339,42 @@ func dataFor(ctx *context.Context, cfg config.Homebrew, cl client.Client, artifa
 		CustomBlock:   split(cfg.CustomBlock),
 	}
 
+	tests, err := testsFor(ctx, cfg)
+	if err != nil {
+		return result, err
+	}
+	result.Tests = append(result.Tests, tests...)
+
+	if result.Service, err = serviceFor(ctx, cfg); err != nil {
+		return result, err
+	}
+
+	for _, dep := range cfg.Dependencies {
+		switch dep.OS {
+		case "":
+			result.Dependencies = append(result.Dependencies, dep)
+		case "mac":
+			result.MacOSDependencies = append(result.MacOSDependencies, dep)
+		case "linux":
+			result.LinuxDependencies = append(result.LinuxDependencies, dep)
+		default:
+			return result, fmt.Errorf("invalid os for brew dependency %s: %s", dep.Name, dep.OS)
+		}
+	}
+
+	extra, err := installfiles.Find(ctx)
+	if err != nil {
+		return result, err
+	}
+
+	if cfg.URLTemplate == "" {
+		url, err := cl.ReleaseURLTemplate(ctx)
+		if err != nil {
+			return result, err
+		}
+		cfg.URLTemplate = url
+	}
+
 	counts := map[string]int{}
 	for _, art := range artifacts {
 		sum, err := art.Checksum("sha256")
@@ -336,14 +382,6 @@ func dataFor(ctx *context.Context, cfg config.Homebrew, cl client.Client, artifa
 			return result, err
 		}
 
-		if cfg.URLTemplate == "" {
-			url, err := cl.ReleaseURLTemplate(ctx)
-			if err != nil {
-				return result, err
-			}
-			cfg.URLTemplate = url
-		}
-
 		url, err := tmpl.New(ctx).WithArtifact(art, map[string]string{}).Apply(cfg.URLTemplate)
 		if err != nil {
 			return result, err
@@ -355,7 +393,7 @@ func dataFor(ctx *context.Context, cfg config.Homebrew, cl client.Client, artifa
 			OS:               art.Goos,
 			Arch:             art.Goarch,
 			DownloadStrategy: cfg.DownloadStrategy,
-			Install:          installs(cfg, art),
+			Install:          installs(ctx, cfg, art, extra),
 		}
 
 		counts[pkg.OS+pkg.Arch]++
@@ -374,11 +412,55 @@ func dataFor(ctx *context.Context, cfg config.Homebrew, cl client.Client, artifa
 		}
 	}
 
+	if cfg.Bottles.Enabled {
+		result.Bottles, result.BottleRootURL, err = makeBottles(ctx, cfg, artifacts, extra)
+		if err != nil {
+			return result, err
+		}
+	}
+
 	sort.Slice(result.LinuxPackages, lessFnFor(result.LinuxPackages))
 	sort.Slice(result.MacOSPackages, lessFnFor(result.MacOSPackages))
 	return result, nil
 }
 
+// testsFor returns the lines of the test block running the configured test
+// commands, asserting their output if one is set.
+func testsFor(ctx *context.Context, cfg config.Homebrew) ([]string, error) {
+	var tests []string
+	for _, test := range cfg.Tests {
+		command, err := tmpl.New(ctx).Apply(test.Command)
+		if err != nil {
+			return nil, err
+		}
+		if test.Output == "" {
+			tests = append(tests, fmt.Sprintf("system %q", command))
+			continue
+		}
+		output, err := tmpl.New(ctx).Apply(test.Output)
+		if err != nil {
+			return nil, err
+		}
+		tests = append(tests, fmt.Sprintf("assert_match %q, shell_output(%q)", output, command))
+	}
+	return tests, nil
+}
+
+// serviceFor returns the service of the formula with its run arguments
+// templated.
+func serviceFor(ctx *context.Context, cfg config.Homebrew) (config.HomebrewService, error) {
+	service := cfg.Service
+	service.Run = make([]string, 0, len(cfg.Service.Run))
+	for _, arg := range cfg.Service.Run {
+		arg, err := tmpl.New(ctx).Apply(arg)
+		if err != nil {
+			return service, err
+		}
+		service.Run = append(service.Run, arg)
+	}
+	return service, nil
+}
+
 func lessFnFor(list []releasePackage) func(i, j int) bool {
 	return func(i, j int) bool { return list[i].OS > list[j].OS && list[i].Arch > list[j].Arch }
 }
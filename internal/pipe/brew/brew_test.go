@@ -1,14 +1,21 @@
 package brew
 
 import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/goreleaser/goreleaser/internal/artifact"
 	"github.com/goreleaser/goreleaser/internal/client"
+	"github.com/goreleaser/goreleaser/internal/commitauthor"
 	"github.com/goreleaser/goreleaser/internal/golden"
+	"github.com/goreleaser/goreleaser/internal/installfiles"
 	"github.com/goreleaser/goreleaser/internal/testlib"
 	"github.com/goreleaser/goreleaser/pkg/config"
 	"github.com/goreleaser/goreleaser/pkg/context"
@@ -621,6 +628,35 @@ func TestRunPipeNoBuilds(t *testing.T) {
 	require.False(t, client.CreatedFile)
 }
 
+func TestRunPipeEncryptedArchive(t *testing.T) {
+	ctx := context.New(config.Project{
+		Brews: []config.Homebrew{
+			{
+				Tap: config.RepoRef{
+					Owner: "test",
+					Name:  "test",
+				},
+			},
+		},
+	})
+	ctx.TokenType = context.TokenTypeGitHub
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name:   "bin.tar.gz",
+		Path:   "doesnt-matter",
+		Goos:   "darwin",
+		Goarch: "amd64",
+		Type:   artifact.UploadableArchive,
+		Extra: map[string]interface{}{
+			artifact.ExtraID:        "foo",
+			artifact.ExtraFormat:    "tar.gz",
+			artifact.ExtraEncrypted: true,
+		},
+	})
+	client := client.NewMock()
+	require.Equal(t, ErrNoArchivesFound, runAll(ctx, client))
+	require.False(t, client.CreatedFile)
+}
+
 func TestRunPipeMultipleArchivesSameOsBuild(t *testing.T) {
 	ctx := context.New(
 		config.Project{
@@ -932,6 +968,24 @@ func TestDefault(t *testing.T) {
 	require.NotEmpty(t, ctx.Config.Brews[0].CommitMessageTemplate)
 }
 
+func TestDefaultSigning(t *testing.T) {
+	ctx := context.New(config.Project{
+		Brews: []config.Homebrew{{
+			Name: "foo",
+			CommitAuthor: config.CommitAuthor{
+				Signing: config.CommitSigning{Enabled: true},
+			},
+		}},
+	})
+	ctx.TokenType = context.TokenTypeGitHub
+	require.NoError(t, Pipe{}.Default(ctx))
+
+	ctx.TokenType = context.TokenTypeGitLab
+	err := Pipe{}.Default(ctx)
+	require.ErrorIs(t, err, commitauthor.ErrSigningUnsupported)
+	require.EqualError(t, err, "brew foo: commit signing is only supported on GitHub or when pushing with git")
+}
+
 func TestGHFolder(t *testing.T) {
 	require.Equal(t, "bar.rb", buildFormulaPath("", "bar.rb"))
 	require.Equal(t, "fooo/bar.rb", buildFormulaPath("fooo", "bar.rb"))
@@ -962,13 +1016,16 @@ func TestRunSkipNoName(t *testing.T) {
 }
 
 func TestInstalls(t *testing.T) {
+	ctx := context.New(config.Project{})
 	t.Run("provided", func(t *testing.T) {
 		require.Equal(t, []string{
 			`bin.install "foo"`,
 			`bin.install "bar"`,
 		}, installs(
+			ctx,
 			config.Homebrew{Install: "bin.install \"foo\"\nbin.install \"bar\""},
 			&artifact.Artifact{},
+			nil,
 		))
 	})
 
@@ -977,6 +1034,7 @@ func TestInstalls(t *testing.T) {
 			`bin.install "bar"`,
 			`bin.install "foo"`,
 		}, installs(
+			ctx,
 			config.Homebrew{},
 			&artifact.Artifact{
 				Type: artifact.UploadableArchive,
@@ -984,6 +1042,30 @@ func TestInstalls(t *testing.T) {
 					artifact.ExtraBinaries: []string{"foo", "bar"},
 				},
 			},
+			nil,
+		))
+	})
+
+	t.Run("from archives with completions and manpages", func(t *testing.T) {
+		require.Equal(t, []string{
+			`bash_completion.install "completions/foo.bash" => "foo"`,
+			`bin.install "foo"`,
+			`man1.install "manpages/foo.1.gz"`,
+			`zsh_completion.install "completions/foo.zsh" => "_foo"`,
+		}, installs(
+			ctx,
+			config.Homebrew{},
+			&artifact.Artifact{
+				Type: artifact.UploadableArchive,
+				Extra: map[string]interface{}{
+					artifact.ExtraBinaries: []string{"foo"},
+				},
+			},
+			[]installfiles.File{
+				{Kind: installfiles.Bash, Source: "dist/completions/foo.bash", Name: "foo"},
+				{Kind: installfiles.Zsh, Source: "dist/completions/foo.zsh", Name: "_foo"},
+				{Kind: installfiles.Manpage, Source: "dist/manpages/foo.1.gz", Name: "foo.1.gz", Section: "1"},
+			},
 		))
 	})
 
@@ -991,6 +1073,7 @@ func TestInstalls(t *testing.T) {
 		require.Equal(t, []string{
 			`bin.install "foo_macos" => "foo"`,
 		}, installs(
+			ctx,
 			config.Homebrew{},
 			&artifact.Artifact{
 				Name: "foo_macos",
@@ -999,6 +1082,7 @@ func TestInstalls(t *testing.T) {
 					artifact.ExtraBinary: "foo",
 				},
 			},
+			nil,
 		))
 	})
 }
@@ -1138,3 +1222,189 @@ func TestRunPipeUniversalBinaryNotReplacing(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, client.Content, string(distBts))
 }
+
+func TestFullFormulaeOSDependencies(t *testing.T) {
+	data := defaultTemplateData
+	data.Dependencies = []config.HomebrewDependency{{Name: "git"}}
+	data.MacOSDependencies = []config.HomebrewDependency{{Name: "zsh", Type: "optional", OS: "mac"}}
+	data.LinuxDependencies = []config.HomebrewDependency{{Name: "bash", OS: "linux"}}
+	formulae, err := doBuildFormula(context.New(config.Project{
+		ProjectName: "foo",
+	}), data)
+	require.NoError(t, err)
+
+	golden.RequireEqualRb(t, []byte(formulae))
+}
+
+func TestDataForInvalidDependencyOS(t *testing.T) {
+	_, err := dataFor(context.New(config.Project{}), config.Homebrew{
+		Dependencies: []config.HomebrewDependency{{Name: "zsh", OS: "windows"}},
+	}, client.NewMock(), nil)
+	require.EqualError(t, err, "invalid os for brew dependency zsh: windows")
+}
+
+func TestFullFormulaeService(t *testing.T) {
+	data := defaultTemplateData
+	data.Tests = []string{`system "#{bin}/foo -h"`}
+	data.Service = config.HomebrewService{
+		Run:          []string{"foo", "serve", "--port=8080"},
+		KeepAlive:    true,
+		LogPath:      "log/foo.log",
+		ErrorLogPath: "log/foo.error.log",
+	}
+	formulae, err := doBuildFormula(context.New(config.Project{
+		ProjectName: "foo",
+	}), data)
+	require.NoError(t, err)
+
+	golden.RequireEqualRb(t, []byte(formulae))
+}
+
+func TestDataForTestsAndService(t *testing.T) {
+	ctx := context.New(config.Project{ProjectName: "foo"})
+	ctx.Version = "1.2.3"
+	data, err := dataFor(ctx, config.Homebrew{
+		Test: `system "true"`,
+		Tests: []config.HomebrewTest{
+			{Command: "#{bin}/{{ .ProjectName }} -h"},
+			{Command: `#{bin}/foo version --short`, Output: "{{ .Version }}"},
+		},
+		Service: config.HomebrewService{
+			Run: []string{"{{ .ProjectName }}", "serve"},
+		},
+	}, client.NewMock(), nil)
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		`system "true"`,
+		`system "#{bin}/foo -h"`,
+		`assert_match "1.2.3", shell_output("#{bin}/foo version --short")`,
+	}, data.Tests)
+	require.Equal(t, []string{"foo", "serve"}, data.Service.Run)
+
+	for name, cfg := range map[string]config.Homebrew{
+		"test command": {Tests: []config.HomebrewTest{{Command: "{{ .Nope }"}}},
+		"test output":  {Tests: []config.HomebrewTest{{Command: "foo", Output: "{{ .Nope }"}}},
+		"service run":  {Service: config.HomebrewService{Run: []string{"{{ .Nope }"}}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := dataFor(ctx, cfg, client.NewMock(), nil)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestDefaultBottles(t *testing.T) {
+	ctx := context.New(config.Project{
+		Brews: []config.Homebrew{
+			{},
+			{Bottles: config.HomebrewBottles{Enabled: true}},
+		},
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.Empty(t, ctx.Config.Brews[0].Bottles.MacOSVersions)
+	require.Equal(t, []string{"ventura", "monterey", "big_sur"}, ctx.Config.Brews[1].Bottles.MacOSVersions)
+}
+
+func TestRunPipeBottles(t *testing.T) {
+	for name, rootURL := range map[string]string{
+		"default":  "",
+		"root_url": "https://example.com/bottles/{{ .Tag }}/",
+	} {
+		t.Run(name, func(t *testing.T) {
+			folder := t.TempDir()
+			ctx := context.New(config.Project{
+				Dist:        folder,
+				ProjectName: "foo",
+				Brews: []config.Homebrew{{
+					Name: "foo",
+					Tap: config.RepoRef{
+						Owner: "foo",
+						Name:  "homebrew-tap",
+					},
+					Bottles: config.HomebrewBottles{
+						Enabled:       true,
+						RootURL:       rootURL,
+						MacOSVersions: []string{"ventura", "monterey"},
+					},
+				}},
+			})
+			ctx.Git.CurrentTag = "v1.0.1"
+			ctx.Version = "1.0.1"
+			ctx.Date = time.Date(2022, 3, 4, 5, 6, 7, 0, time.UTC)
+			require.NoError(t, Pipe{}.Default(ctx))
+
+			for _, platform := range []struct{ goos, goarch, goarm string }{
+				{"darwin", "amd64", ""},
+				{"darwin", "arm64", ""},
+				{"linux", "amd64", ""},
+				{"linux", "arm", "6"},
+			} {
+				base := "foo_" + platform.goos + "_" + platform.goarch + platform.goarm
+				binary := filepath.Join(folder, base, "foo")
+				require.NoError(t, os.MkdirAll(filepath.Dir(binary), 0o755))
+				require.NoError(t, os.WriteFile(binary, []byte(base), 0o755))
+				archive := filepath.Join(folder, base+".tar.gz")
+				require.NoError(t, os.WriteFile(archive, []byte(base), 0o644))
+				ctx.Artifacts.Add(&artifact.Artifact{
+					Name:   base + ".tar.gz",
+					Path:   archive,
+					Goos:   platform.goos,
+					Goarch: platform.goarch,
+					Goarm:  platform.goarm,
+					Type:   artifact.UploadableArchive,
+					Extra: map[string]interface{}{
+						artifact.ExtraID:       "foo",
+						artifact.ExtraFormat:   "tar.gz",
+						artifact.ExtraBinaries: []string{"foo"},
+						artifact.ExtraBuilds: []*artifact.Artifact{{
+							Name: "foo",
+							Path: binary,
+						}},
+					},
+				})
+			}
+
+			cli := client.NewMock()
+			require.NoError(t, runAll(ctx, cli))
+			require.NoError(t, publishAll(ctx, cli))
+			golden.RequireEqualRb(t, []byte(cli.Content))
+
+			bottles := ctx.Artifacts.Filter(artifact.ByType(artifact.BrewBottle)).List()
+			names := make([]string, 0, len(bottles))
+			for _, bottle := range bottles {
+				names = append(names, bottle.Name)
+				require.Equal(t, "foo", bottle.ID())
+			}
+			require.ElementsMatch(t, []string{
+				"foo--1.0.1.ventura.bottle.tar.gz",
+				"foo--1.0.1.monterey.bottle.tar.gz",
+				"foo--1.0.1.arm64_ventura.bottle.tar.gz",
+				"foo--1.0.1.arm64_monterey.bottle.tar.gz",
+				"foo--1.0.1.x86_64_linux.bottle.tar.gz",
+			}, names)
+
+			require.Equal(t, []string{"foo/1.0.1/bin/foo"}, targzFiles(t, filepath.Join(folder, "foo--1.0.1.arm64_ventura.bottle.tar.gz")))
+		})
+	}
+}
+
+func targzFiles(tb testing.TB, path string) []string {
+	tb.Helper()
+	f, err := os.Open(path)
+	require.NoError(tb, err)
+	defer f.Close()
+	gr, err := gzip.NewReader(f)
+	require.NoError(tb, err)
+	defer gr.Close()
+	var result []string
+	r := tar.NewReader(gr)
+	for {
+		h, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(tb, err)
+		result = append(result, h.Name)
+	}
+	return result
+}
@@ -3,21 +3,26 @@ package brew
 import "github.com/goreleaser/goreleaser/pkg/config"
 
 type templateData struct {
-	Name          string
-	Desc          string
-	Homepage      string
-	Version       string
-	License       string
-	Caveats       []string
-	Plist         string
-	PostInstall   string
-	Dependencies  []config.HomebrewDependency
-	Conflicts     []string
-	Tests         []string
-	CustomRequire string
-	CustomBlock   []string
-	LinuxPackages []releasePackage
-	MacOSPackages []releasePackage
+	Name              string
+	Desc              string
+	Homepage          string
+	Version           string
+	License           string
+	Caveats           []string
+	Plist             string
+	PostInstall       string
+	Dependencies      []config.HomebrewDependency
+	MacOSDependencies []config.HomebrewDependency
+	LinuxDependencies []config.HomebrewDependency
+	Conflicts         []string
+	Tests             []string
+	Service           config.HomebrewService
+	CustomRequire     string
+	CustomBlock       []string
+	LinuxPackages     []releasePackage
+	MacOSPackages     []releasePackage
+	BottleRootURL     string
+	Bottles           []bottle
 }
 
 type releasePackage struct {
@@ -29,6 +34,11 @@ type releasePackage struct {
 	Install          []string
 }
 
+type bottle struct {
+	Tag    string
+	SHA256 string
+}
+
 const formulaTemplate = `# typed: false
 # frozen_string_literal: true
 
@@ -51,6 +61,16 @@ class {{ .Name }} < Formula
   {{- end }}
   {{- printf "\n" }}
 
+  {{- with .Bottles }}
+  bottle do
+    root_url "{{ $.BottleRootURL }}"
+    {{- range $index, $element := . }}
+    sha256 cellar: :any_skip_relocation, {{ .Tag }}: "{{ .SHA256 }}"
+    {{- end }}
+  end
+  {{- printf "\n" }}
+  {{- end }}
+
   {{- if .MacOSPackages }}
   on_macos do
   {{- range $element := .MacOSPackages }}
@@ -66,11 +86,11 @@ class {{ .Name }} < Formula
     end
     {{- else }}
     {{- if eq $element.Arch "amd64" }}
-    if Hardware::CPU.intel?
+    on_intel do
     {{- end }}
     {{- if eq $element.Arch "arm64" }}
-    if Hardware::CPU.arm?
-    {{- end}}
+    on_arm do
+    {{- end }}
       url "{{ $element.DownloadURL }}"
       {{- if .DownloadStrategy }}, :using => {{ .DownloadStrategy }}{{- end }}
       sha256 "{{ $element.SHA256 }}"
@@ -92,22 +112,26 @@ class {{ .Name }} < Formula
   on_linux do
   {{- range $element := .LinuxPackages }}
     {{- if eq $element.Arch "amd64" }}
-    if Hardware::CPU.intel?
+    on_intel do
+      if Hardware::CPU.is_64_bit?
     {{- end }}
     {{- if eq $element.Arch "arm" }}
-    if Hardware::CPU.arm? && !Hardware::CPU.is_64_bit?
+    on_arm do
+      if !Hardware::CPU.is_64_bit?
     {{- end }}
     {{- if eq $element.Arch "arm64" }}
-    if Hardware::CPU.arm? && Hardware::CPU.is_64_bit?
+    on_arm do
+      if Hardware::CPU.is_64_bit?
     {{- end }}
-      url "{{ $element.DownloadURL }}"
-      {{- if .DownloadStrategy }}, :using => {{ .DownloadStrategy }}{{- end }}
-      sha256 "{{ $element.SHA256 }}"
-
-      def install
-        {{- range $index, $element := .Install }}
-        {{ . -}}
-        {{- end }}
+        url "{{ $element.DownloadURL }}"
+        {{- if .DownloadStrategy }}, :using => {{ .DownloadStrategy }}{{- end }}
+        sha256 "{{ $element.SHA256 }}"
+
+        def install
+          {{- range $index, $element := .Install }}
+          {{ . -}}
+          {{- end }}
+        end
       end
     end
   {{- end }}
@@ -127,6 +151,26 @@ class {{ .Name }} < Formula
   {{- end }}
   {{- end -}}
 
+  {{- with .MacOSDependencies }}
+
+  on_macos do
+    {{- range $index, $element := . }}
+    depends_on "{{ .Name }}"
+    {{- if .Type }} => :{{ .Type }}{{- end }}
+    {{- end }}
+  end
+  {{- end -}}
+
+  {{- with .LinuxDependencies }}
+
+  on_linux do
+    {{- range $index, $element := . }}
+    depends_on "{{ .Name }}"
+    {{- if .Type }} => :{{ .Type }}{{- end }}
+    {{- end }}
+  end
+  {{- end -}}
+
   {{- with .Conflicts }}
   {{ range $index, $element := . }}
   conflicts_with "{{ . }}"
@@ -160,6 +204,22 @@ class {{ .Name }} < Formula
   end
   {{- end -}}
 
+  {{- with .Service.Run }}
+
+  service do
+    run [opt_bin/{{ printf "%q" (index . 0) }}{{ range $index, $element := . }}{{ if $index }}, {{ printf "%q" . }}{{ end }}{{ end }}]
+    {{- if $.Service.KeepAlive }}
+    keep_alive true
+    {{- end }}
+    {{- with $.Service.LogPath }}
+    log_path var/{{ printf "%q" . }}
+    {{- end }}
+    {{- with $.Service.ErrorLogPath }}
+    error_log_path var/{{ printf "%q" . }}
+    {{- end }}
+  end
+  {{- end -}}
+
   {{- if .Tests }}
 
   test do
@@ -10,7 +10,9 @@ import (
 
 	"github.com/apex/log"
 	"github.com/caarlos0/go-shellwords"
+	"github.com/goreleaser/goreleaser/internal/artifact"
 	"github.com/goreleaser/goreleaser/internal/ids"
+	"github.com/goreleaser/goreleaser/internal/platforms"
 	"github.com/goreleaser/goreleaser/internal/semerrgroup"
 	"github.com/goreleaser/goreleaser/internal/shell"
 	"github.com/goreleaser/goreleaser/internal/tmpl"
@@ -33,10 +35,10 @@ func (Pipe) String() string {
 func (Pipe) Run(ctx *context.Context) error {
 	for _, build := range ctx.Config.Builds {
 		if build.Skip {
-			log.WithField("id", build.ID).Info("skip is set")
+			log.FromContext(ctx).WithField("id", build.ID).Info("skip is set")
 			continue
 		}
-		log.WithField("build", build).Debug("building")
+		log.FromContext(ctx).WithField("build", build).Debug("building")
 		if err := runPipeOnBuild(ctx, build); err != nil {
 			return err
 		}
@@ -46,6 +48,9 @@ func (Pipe) Run(ctx *context.Context) error {
 
 // Default sets the pipe defaults.
 func (Pipe) Default(ctx *context.Context) error {
+	if err := platforms.Validate(ctx.Config.Platforms); err != nil {
+		return err
+	}
 	ids := ids.New("builds")
 	for i, build := range ctx.Config.Builds {
 		build, err := buildWithDefaults(ctx, build)
@@ -53,7 +58,6 @@ func (Pipe) Default(ctx *context.Context) error {
 			return err
 		}
 		ctx.Config.Builds[i] = build
-		ids.Inc(ctx.Config.Builds[i].ID)
 	}
 	if len(ctx.Config.Builds) == 0 {
 		build, err := buildWithDefaults(ctx, ctx.Config.SingleBuild)
@@ -62,9 +66,58 @@ func (Pipe) Default(ctx *context.Context) error {
 		}
 		ctx.Config.Builds = []config.Build{build}
 	}
+	builds := ctx.Config.Builds
+	for _, build := range builds {
+		variants, err := buildVariants(build)
+		if err != nil {
+			return err
+		}
+		ctx.Config.Builds = append(ctx.Config.Builds, variants...)
+	}
+	for _, build := range ctx.Config.Builds {
+		ids.Inc(build.ID)
+	}
 	return ids.Validate()
 }
 
+// buildVariants returns the builds of the variants of the given build, which
+// are built like it, but with their own ID, binary name and flags.
+func buildVariants(build config.Build) ([]config.Build, error) {
+	var builds []config.Build
+	for i := range build.Variants {
+		variant := build.Variants[i]
+		if variant.ID == "" {
+			return nil, fmt.Errorf("build %s: variant %d: id is required", build.ID, i)
+		}
+		vb := build
+		vb.ID = build.ID + "-" + variant.ID
+		vb.Variants = nil
+		vb.Variant = &variant
+		vb.Binary = variant.Binary
+		if vb.Binary == "" {
+			vb.Binary = build.Binary + "-" + variant.ID
+		}
+		vb.Env = append([]string{}, build.Env...)
+		for _, env := range variant.Env {
+			vb.Env = append(vb.Env, os.ExpandEnv(env))
+		}
+		if len(variant.Flags) > 0 {
+			vb.Flags = variant.Flags
+		}
+		if len(variant.Tags) > 0 {
+			vb.Tags = variant.Tags
+		}
+		if len(variant.Ldflags) > 0 {
+			vb.Ldflags = variant.Ldflags
+		}
+		if len(variant.Gcflags) > 0 {
+			vb.Gcflags = variant.Gcflags
+		}
+		builds = append(builds, vb)
+	}
+	return builds, nil
+}
+
 func buildWithDefaults(ctx *context.Context, build config.Build) (config.Build, error) {
 	if build.Builder == "" {
 		build.Builder = "go"
@@ -78,9 +131,44 @@ func buildWithDefaults(ctx *context.Context, build config.Build) (config.Build,
 	for k, v := range build.Env {
 		build.Env[k] = os.ExpandEnv(v)
 	}
+	if len(build.Targets) > 0 {
+		build.Targets = platforms.ExpandVariants(platforms.Expand(ctx.Config.Platforms, build.Targets), build.Goarm)
+	}
 	return builders.For(build.Builder).WithDefaults(build)
 }
 
+// Plan returns the binaries the builds would produce for all their targets,
+// without building them.
+func Plan(ctx *context.Context) ([]*artifact.Artifact, error) {
+	var result []*artifact.Artifact
+	for _, build := range ctx.Config.Builds {
+		if build.Skip {
+			continue
+		}
+		for _, target := range build.Targets {
+			opts, err := buildOptionsForTarget(ctx, build, target)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, &artifact.Artifact{
+				Type:   artifact.Binary,
+				Name:   opts.Name,
+				Path:   opts.Path,
+				Goos:   opts.Goos,
+				Goarch: opts.Goarch,
+				Goarm:  opts.Goarm,
+				Gomips: opts.Gomips,
+				Extra: map[string]interface{}{
+					artifact.ExtraBinary: strings.TrimSuffix(opts.Name, opts.Ext),
+					artifact.ExtraExt:    opts.Ext,
+					artifact.ExtraID:     build.ID,
+				},
+			})
+		}
+	}
+	return result, nil
+}
+
 func runPipeOnBuild(ctx *context.Context, build config.Build) error {
 	g := semerrgroup.New(ctx.Parallelism)
 	for _, target := range build.Targets {
@@ -91,6 +179,7 @@ func runPipeOnBuild(ctx *context.Context, build config.Build) error {
 			if err != nil {
 				return err
 			}
+			log.FromContext(ctx).WithField("binary", opts.Path).Info("building")
 
 			if err := runHook(ctx, *opts, build.Env, build.Hooks.Pre); err != nil {
 				return fmt.Errorf("pre hook failed: %w", err)
@@ -141,12 +230,25 @@ func runHook(ctx *context.Context, opts builders.Options, buildEnv []string, hoo
 			return err
 		}
 
-		log.WithField("hook", sh).Info("running hook")
+		log.FromContext(ctx).WithField("hook", sh).Info("running hook")
 		cmd, err := shellwords.Parse(sh)
 		if err != nil {
 			return err
 		}
 
+		if hook.Image != "" {
+			// only the env not coming from ctx.Env is set in the container.
+			image, err := tmpl.New(ctx).WithBuildOptions(opts).Apply(hook.Image)
+			if err != nil {
+				return err
+			}
+			cmd, err = shell.Container(ctx, hook.Use, image, dir, env[len(ctx.Env):], cmd)
+			if err != nil {
+				return err
+			}
+			dir = ""
+		}
+
 		if err := shell.Run(ctx, dir, cmd, env); err != nil {
 			return err
 		}
@@ -204,8 +306,6 @@ func buildOptionsForTarget(ctx *context.Context, build config.Build, target stri
 	}
 	buildOpts.Path = path
 	buildOpts.Name = name
-
-	log.WithField("binary", buildOpts.Path).Info("building")
 	return &buildOpts, nil
 }
 
@@ -249,6 +249,54 @@ func TestDefaultExpandEnv(t *testing.T) {
 	require.Equal(t, "XFOO=bar_FOOBAR", env)
 }
 
+func TestDefaultPlatforms(t *testing.T) {
+	ctx := context.New(config.Project{
+		Platforms: []config.Platform{
+			{Name: "raspberry-pi", Targets: []string{"linux/arm64", "linux/arm/7"}},
+		},
+		Builds: []config.Build{
+			{
+				ID:      "foo",
+				Binary:  "foo",
+				Targets: []string{"raspberry-pi", "linux_amd64"},
+			},
+		},
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.Equal(t, []string{"linux_arm64", "linux_arm_7", "linux_amd64"}, ctx.Config.Builds[0].Targets)
+}
+
+func TestDefaultArmVariants(t *testing.T) {
+	ctx := context.New(config.Project{
+		Platforms: []config.Platform{
+			{Name: "arm", Targets: []string{"linux/arm/*"}},
+		},
+		Builds: []config.Build{
+			{
+				ID:      "foo",
+				Binary:  "foo",
+				Targets: []string{"linux/arm/*", "linux_amd64"},
+			},
+			{
+				ID:      "bar",
+				Binary:  "bar",
+				Targets: []string{"arm", "freebsd/arm/*"},
+				Goarm:   []string{"5", "6", "7"},
+			},
+		},
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.Equal(t, []string{"linux_arm_6", "linux_arm_7", "linux_amd64"}, ctx.Config.Builds[0].Targets)
+	require.Equal(t, []string{"linux_arm_5", "linux_arm_6", "linux_arm_7", "freebsd_arm_5", "freebsd_arm_6", "freebsd_arm_7"}, ctx.Config.Builds[1].Targets)
+}
+
+func TestDefaultInvalidPlatforms(t *testing.T) {
+	ctx := context.New(config.Project{
+		Platforms: []config.Platform{{Name: "raspberry-pi"}},
+	})
+	require.EqualError(t, Pipe{}.Default(ctx), "platform raspberry-pi: targets are required")
+}
+
 func TestDefaultEmptyBuild(t *testing.T) {
 	ctx := &context.Context{
 		Config: config.Project{
@@ -309,6 +357,85 @@ func TestSeveralBuildsWithTheSameID(t *testing.T) {
 	require.EqualError(t, Pipe{}.Default(ctx), "found 2 builds with the ID 'a', please fix your config")
 }
 
+func TestDefaultVariants(t *testing.T) {
+	ctx := &context.Context{
+		Config: config.Project{
+			ProjectName: "foo",
+			Builds: []config.Build{
+				{
+					Env:     []string{"CGO_ENABLED=0"},
+					Ldflags: []string{"-s -w"},
+					Flags:   []string{"-trimpath"},
+					Variants: []config.BuildVariant{
+						{
+							ID:    "race",
+							Env:   []string{"CGO_ENABLED=1"},
+							Flags: []string{"-race"},
+						},
+						{
+							ID:      "debug",
+							Binary:  "foo-dbg",
+							Ldflags: []string{"-X main.debug=true"},
+						},
+					},
+				},
+			},
+		},
+	}
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.Len(t, ctx.Config.Builds, 3)
+
+	race := ctx.Config.Builds[1]
+	require.Equal(t, "foo-race", race.ID)
+	require.Equal(t, "foo-race", race.Binary)
+	require.Equal(t, []string{"CGO_ENABLED=0", "CGO_ENABLED=1"}, race.Env)
+	require.Equal(t, config.FlagArray{"-race"}, race.Flags)
+	require.Equal(t, config.StringArray{"-s -w"}, race.Ldflags)
+	require.Equal(t, ctx.Config.Builds[0].Targets, race.Targets)
+	require.Equal(t, "race", race.Variant.ID)
+	require.Empty(t, race.Variants)
+
+	debug := ctx.Config.Builds[2]
+	require.Equal(t, "foo-debug", debug.ID)
+	require.Equal(t, "foo-dbg", debug.Binary)
+	require.Equal(t, config.FlagArray{"-trimpath"}, debug.Flags)
+	require.Equal(t, config.StringArray{"-X main.debug=true"}, debug.Ldflags)
+
+	require.Nil(t, ctx.Config.Builds[0].Variant)
+	require.Equal(t, []string{"CGO_ENABLED=0"}, ctx.Config.Builds[0].Env)
+}
+
+func TestDefaultVariantWithoutID(t *testing.T) {
+	ctx := &context.Context{
+		Config: config.Project{
+			Builds: []config.Build{
+				{
+					ID:       "foo",
+					Variants: []config.BuildVariant{{Flags: []string{"-race"}}},
+				},
+			},
+		},
+	}
+	require.EqualError(t, Pipe{}.Default(ctx), "build foo: variant 0: id is required")
+}
+
+func TestDefaultVariantDuplicatedID(t *testing.T) {
+	ctx := &context.Context{
+		Config: config.Project{
+			Builds: []config.Build{
+				{
+					ID:       "foo",
+					Variants: []config.BuildVariant{{ID: "race"}},
+				},
+				{
+					ID: "foo-race",
+				},
+			},
+		},
+	}
+	require.EqualError(t, Pipe{}.Default(ctx), "found 2 builds with the ID 'foo-race', please fix your config")
+}
+
 func TestDefaultPartialBuilds(t *testing.T) {
 	ctx := &context.Context{
 		Config: config.Project{
@@ -410,6 +537,38 @@ func TestSkipBuild(t *testing.T) {
 	require.Len(t, ctx.Artifacts.List(), 0)
 }
 
+func TestPlan(t *testing.T) {
+	ctx := context.New(config.Project{
+		Dist: "dist",
+		Builds: []config.Build{
+			{
+				ID:      "foo",
+				Binary:  "foo_{{ .Os }}",
+				Targets: []string{"linux_arm_6", "windows_amd64"},
+			},
+			{
+				ID:      "skipped",
+				Skip:    true,
+				Targets: []string{"linux_amd64"},
+			},
+		},
+	})
+	binaries, err := Plan(ctx)
+	require.NoError(t, err)
+	require.Len(t, binaries, 2)
+
+	require.Equal(t, "foo_linux", binaries[0].Name)
+	require.Equal(t, "arm", binaries[0].Goarch)
+	require.Equal(t, "6", binaries[0].Goarm)
+	require.Equal(t, "foo_linux", binaries[0].Extra[artifact.ExtraBinary])
+	require.Equal(t, "foo", binaries[0].Extra[artifact.ExtraID])
+
+	require.Equal(t, "foo_windows.exe", binaries[1].Name)
+	require.Equal(t, "foo_windows", binaries[1].Extra[artifact.ExtraBinary])
+	require.Equal(t, ".exe", binaries[1].Extra[artifact.ExtraExt])
+	require.Empty(t, ctx.Artifacts.List())
+}
+
 func TestExtWindows(t *testing.T) {
 	require.Equal(t, ".exe", extFor("windows_amd64", config.FlagArray{}))
 	require.Equal(t, ".exe", extFor("windows_386", config.FlagArray{}))
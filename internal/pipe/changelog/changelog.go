@@ -14,6 +14,7 @@ import (
 	"github.com/goreleaser/goreleaser/internal/client"
 	"github.com/goreleaser/goreleaser/internal/git"
 	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/internal/vcs"
 	"github.com/goreleaser/goreleaser/pkg/context"
 )
 
@@ -25,14 +26,17 @@ const li = "* "
 type useChangelog string
 
 func (u useChangelog) formatable() bool {
-	return u != "github-native"
+	return u != useGitHubNative && u != useTag
 }
 
 const (
 	useGit          = "git"
 	useGitHub       = "github"
 	useGitLab       = "gitlab"
+	useBitbucket    = "bitbucket"
+	useAzureDevOps  = "azure-devops"
 	useGitHubNative = "github-native"
+	useTag          = "tag"
 )
 
 // Pipe for checksums.
@@ -43,6 +47,8 @@ func (Pipe) Skip(ctx *context.Context) bool { return ctx.Config.Changelog.Skip |
 
 // Run the pipe.
 func (Pipe) Run(ctx *context.Context) error {
+	loadPreviousDownloads(ctx)
+
 	notes, err := loadContent(ctx, ctx.ReleaseNotesFile, ctx.ReleaseNotesTmpl)
 	if err != nil {
 		return err
@@ -67,12 +73,7 @@ func (Pipe) Run(ctx *context.Context) error {
 		return err
 	}
 
-	entries, err := buildChangelog(ctx)
-	if err != nil {
-		return err
-	}
-
-	changes, err := formatChangelog(ctx, entries)
+	changes, err := changelogContent(ctx)
 	if err != nil {
 		return err
 	}
@@ -91,32 +92,101 @@ func (Pipe) Run(ctx *context.Context) error {
 	}
 
 	path := filepath.Join(ctx.Config.Dist, "CHANGELOG.md")
-	log.WithField("changelog", path).Info("writing")
+	log.FromContext(ctx).WithField("changelog", path).Info("writing")
 	return os.WriteFile(path, []byte(ctx.ReleaseNotes), 0o644) //nolint: gosec
 }
 
-func formatChangelog(ctx *context.Context, entries []string) (string, error) {
-	newLine := "\n"
+// changelogContent returns the formatted changelog, divided by the tags
+// between the previous and the current one if asked to.
+func changelogContent(ctx *context.Context) (string, error) {
+	if ctx.Config.Changelog.DivideByTag {
+		if !useChangelog(ctx.Config.Changelog.Use).formatable() {
+			log.FromContext(ctx).Warnf("changelog.divide_by_tag can't be used with changelog.use: %s, ignoring it", ctx.Config.Changelog.Use)
+		} else {
+			sections, err := buildDividedChangelog(ctx)
+			if err != nil {
+				return "", err
+			}
+			if len(sections) > 1 {
+				return formatDividedChangelog(ctx, sections)
+			}
+		}
+	}
+
+	if ctx.Config.Changelog.Use == useTag {
+		return tagMessage(ctx)
+	}
+
+	entries, err := buildChangelog(ctx)
+	if err != nil {
+		return "", err
+	}
+	return formatChangelog(ctx, entries)
+}
+
+// tagMessage returns the message of the current tag, verbatim.
+func tagMessage(ctx *context.Context) (string, error) {
+	if ctx.Git.TagSubject == "" {
+		return "", fmt.Errorf("changelog.use is %q, but tag %s has no message", useTag, ctx.Git.CurrentTag)
+	}
+	if ctx.Git.TagBody == "" {
+		return ctx.Git.TagSubject, nil
+	}
+	return ctx.Git.TagSubject + "\n\n" + ctx.Git.TagBody, nil
+}
+
+func lineBreak(ctx *context.Context) string {
 	if ctx.TokenType == context.TokenTypeGitLab || ctx.TokenType == context.TokenTypeGitea {
 		// We need two or more whitespace to let markdown interpret
 		// it as newline. See https://docs.gitlab.com/ee/user/markdown.html#newlines for details
-		log.Debug("is gitlab or gitea changelog")
-		newLine = "   \n"
+		log.FromContext(ctx).Debug("is gitlab or gitea changelog")
+		return "   \n"
 	}
+	return "\n"
+}
 
+func formatChangelog(ctx *context.Context, entries []string) (string, error) {
+	newLine := lineBreak(ctx)
 	if !useChangelog(ctx.Config.Changelog.Use).formatable() {
 		return strings.Join(entries, newLine), nil
 	}
 
+	items, err := formatEntries(ctx, entries, "###")
+	if err != nil {
+		return "", err
+	}
+	return strings.Join(append([]string{"## Changelog"}, items...), newLine), nil
+}
+
+// formatDividedChangelog formats the changelog with a section for each tag.
+func formatDividedChangelog(ctx *context.Context, sections []tagSection) (string, error) {
 	result := []string{"## Changelog"}
+	for _, section := range sections {
+		items, err := formatEntries(ctx, section.entries, "####")
+		if err != nil {
+			return "", err
+		}
+		if len(items) == 0 {
+			continue
+		}
+		result = append(result, "### "+section.tag)
+		result = append(result, items...)
+	}
+	return strings.Join(result, lineBreak(ctx)), nil
+}
+
+// formatEntries returns the given entries as list items, grouped under
+// headings of the given level if there are groups.
+func formatEntries(ctx *context.Context, entries []string, heading string) ([]string, error) {
 	if len(ctx.Config.Changelog.Groups) == 0 {
-		log.Debug("not grouping entries")
-		return strings.Join(append(result, filterAndPrefixItems(entries)...), newLine), nil
+		log.FromContext(ctx).Debug("not grouping entries")
+		return filterAndPrefixItems(entries), nil
 	}
 
-	log.Debug("grouping entries")
+	log.FromContext(ctx).Debug("grouping entries")
 	groups := ctx.Config.Changelog.Groups
 
+	var result []string
 	sort.Slice(groups, func(i, j int) bool { return groups[i].Order < groups[j].Order })
 	for _, group := range groups {
 		items := make([]string, 0)
@@ -128,7 +198,7 @@ func formatChangelog(ctx *context.Context, entries []string) (string, error) {
 		} else {
 			regex, err := regexp.Compile(group.Regexp)
 			if err != nil {
-				return "", fmt.Errorf("failed to group into %q: %w", group.Title, err)
+				return nil, fmt.Errorf("failed to group into %q: %w", group.Title, err)
 			}
 			for i, entry := range entries {
 				match := regex.MatchString(entry)
@@ -140,12 +210,12 @@ func formatChangelog(ctx *context.Context, entries []string) (string, error) {
 			}
 		}
 		if len(items) > 0 {
-			result = append(result, fmt.Sprintf("### %s", group.Title))
+			result = append(result, fmt.Sprintf("%s %s", heading, group.Title))
 			result = append(result, items...)
 		}
 	}
 
-	return strings.Join(result, newLine), nil
+	return result, nil
 }
 
 func filterAndPrefixItems(ss []string) []string {
@@ -158,12 +228,12 @@ func filterAndPrefixItems(ss []string) []string {
 	return r
 }
 
-func loadFromFile(file string) (string, error) {
+func loadFromFile(ctx *context.Context, file string) (string, error) {
 	bts, err := os.ReadFile(file)
 	if err != nil {
 		return "", err
 	}
-	log.WithField("file", file).Debugf("read %d bytes", len(bts))
+	log.FromContext(ctx).WithField("file", file).Debugf("read %d bytes", len(bts))
 	return string(bts), nil
 }
 
@@ -184,6 +254,51 @@ func buildChangelog(ctx *context.Context) ([]string, error) {
 	if err != nil {
 		return nil, err
 	}
+	return parseEntries(ctx, log)
+}
+
+// tagSection is the part of a changelog about a single tag.
+type tagSection struct {
+	tag     string
+	entries []string
+}
+
+// buildDividedChangelog returns the changelog of each of the tags between
+// the previous and the current one, newest first.
+func buildDividedChangelog(ctx *context.Context) ([]tagSection, error) {
+	prev, err := previousRef(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tags, err := intermediateTags(prev, ctx.Git.CurrentTag)
+	if err != nil {
+		return nil, err
+	}
+	bounds := append(append([]string{ctx.Git.CurrentTag}, tags...), prev)
+	sections := make([]tagSection, 0, len(bounds)-1)
+	for i := 0; i < len(bounds)-1; i++ {
+		log, err := doGetChangelog(ctx, bounds[i+1], bounds[i])
+		if err != nil {
+			return nil, err
+		}
+		entries, err := parseEntries(ctx, log)
+		if err != nil {
+			return nil, err
+		}
+		sections = append(sections, tagSection{tag: bounds[i], entries: entries})
+	}
+	return sections, nil
+}
+
+// intermediateTags returns the tags that are in the history of the current
+// tag but not in the one of prev, newest first.
+func intermediateTags(prev, current string) ([]string, error) {
+	return vcs.Detect().TagsBetween(prev, current)
+}
+
+// parseEntries splits the given log into entries, filtered and sorted as
+// configured.
+func parseEntries(ctx *context.Context, log string) ([]string, error) {
 	entries := strings.Split(log, "\n")
 	if lastLine := entries[len(entries)-1]; strings.TrimSpace(lastLine) == "" {
 		entries = entries[0 : len(entries)-1]
@@ -191,7 +306,7 @@ func buildChangelog(ctx *context.Context) ([]string, error) {
 	if !useChangelog(ctx.Config.Changelog.Use).formatable() {
 		return entries, nil
 	}
-	entries, err = filterEntries(ctx, entries)
+	entries, err := filterEntries(ctx, entries)
 	if err != nil {
 		return entries, err
 	}
@@ -241,18 +356,22 @@ func extractCommitInfo(line string) string {
 }
 
 func getChangelog(ctx *context.Context, tag string) (string, error) {
-	prev := ctx.Git.PreviousTag
-	if prev == "" {
-		// get first commit
-		result, err := git.Clean(git.Run("rev-list", "--max-parents=0", "HEAD"))
-		if err != nil {
-			return "", err
-		}
-		prev = result
+	prev, err := previousRef(ctx)
+	if err != nil {
+		return "", err
 	}
 	return doGetChangelog(ctx, prev, tag)
 }
 
+// previousRef returns the previous tag, or the first commit if there is none.
+func previousRef(ctx *context.Context) (string, error) {
+	if ctx.Git.PreviousTag != "" {
+		return ctx.Git.PreviousTag, nil
+	}
+	// get first commit
+	return vcs.Detect().FirstCommit()
+}
+
 func doGetChangelog(ctx *context.Context, prev, tag string) (string, error) {
 	l, err := getChangeloger(ctx)
 	if err != nil {
@@ -270,6 +389,10 @@ func getChangeloger(ctx *context.Context) (changeloger, error) {
 	case useGitHub:
 		fallthrough
 	case useGitLab:
+		fallthrough
+	case useBitbucket:
+		fallthrough
+	case useAzureDevOps:
 		return newSCMChangeloger(ctx)
 	case useGitHubNative:
 		return newGithubChangeloger(ctx)
@@ -301,6 +424,16 @@ func newSCMChangeloger(ctx *context.Context) (changeloger, error) {
 	if err != nil {
 		return nil, err
 	}
+	if ctx.Config.Changelog.Use == useAzureDevOps {
+		// the owner in the remote URL is not the organization/project one.
+		return &scmChangeloger{
+			client: cli,
+			repo: client.Repo{
+				Owner: ctx.Config.Release.AzureDevOps.Owner,
+				Name:  ctx.Config.Release.AzureDevOps.Name,
+			},
+		}, nil
+	}
 	repo, err := git.ExtractRepoFromConfig()
 	if err != nil {
 		return nil, err
@@ -316,8 +449,8 @@ func newSCMChangeloger(ctx *context.Context) (changeloger, error) {
 
 func loadContent(ctx *context.Context, fileName, tmplName string) (string, error) {
 	if tmplName != "" {
-		log.Debugf("loading template %s", tmplName)
-		content, err := loadFromFile(tmplName)
+		log.FromContext(ctx).Debugf("loading template %s", tmplName)
+		content, err := loadFromFile(ctx, tmplName)
 		if err != nil {
 			return "", err
 		}
@@ -325,8 +458,8 @@ func loadContent(ctx *context.Context, fileName, tmplName string) (string, error
 	}
 
 	if fileName != "" {
-		log.Debugf("loading file %s", fileName)
-		return loadFromFile(fileName)
+		log.FromContext(ctx).Debugf("loading file %s", fileName)
+		return loadFromFile(ctx, fileName)
 	}
 
 	return "", nil
@@ -338,16 +471,8 @@ type changeloger interface {
 
 type gitChangeloger struct{}
 
-var validSHA1 = regexp.MustCompile(`^[a-fA-F0-9]{40}$`)
-
 func (g gitChangeloger) Log(_ *context.Context, prev, current string) (string, error) {
-	args := []string{"log", "--pretty=oneline", "--abbrev-commit", "--no-decorate", "--no-color"}
-	if validSHA1.MatchString(prev) {
-		args = append(args, prev, current)
-	} else {
-		args = append(args, fmt.Sprintf("tags/%s..tags/%s", prev, current))
-	}
-	return git.Run(args...)
+	return vcs.Detect().Log(prev, current)
 }
 
 type scmChangeloger struct {
@@ -519,6 +519,41 @@ func TestGetChangeloger(t *testing.T) {
 		require.IsType(t, c, &scmChangeloger{})
 	})
 
+	t.Run(useBitbucket, func(t *testing.T) {
+		ctx := context.New(config.Project{
+			Changelog: config.Changelog{
+				Use: useBitbucket,
+			},
+			BitbucketURLs: config.BitbucketURLs{
+				API: client.DefaultBitbucketAPIURL,
+			},
+		})
+		ctx.TokenType = context.TokenTypeBitbucket
+		c, err := getChangeloger(ctx)
+		require.NoError(t, err)
+		require.IsType(t, c, &scmChangeloger{})
+	})
+
+	t.Run(useAzureDevOps, func(t *testing.T) {
+		ctx := context.New(config.Project{
+			Changelog: config.Changelog{
+				Use: useAzureDevOps,
+			},
+			AzureDevOpsURLs: config.AzureDevOpsURLs{
+				API: client.DefaultAzureDevOpsAPIURL,
+			},
+			Release: config.Release{
+				AzureDevOps: config.Repo{Owner: "org/project", Name: "repo"},
+			},
+		})
+		ctx.TokenType = context.TokenTypeAzureDevOps
+		ctx.Token = "pat"
+		c, err := getChangeloger(ctx)
+		require.NoError(t, err)
+		require.IsType(t, c, &scmChangeloger{})
+		require.Equal(t, client.Repo{Owner: "org/project", Name: "repo"}, c.(*scmChangeloger).repo)
+	})
+
 	t.Run("invalid", func(t *testing.T) {
 		c, err := getChangeloger(context.New(config.Project{
 			Changelog: config.Changelog{
@@ -602,6 +637,135 @@ func TestGroup(t *testing.T) {
 	require.Contains(t, ctx.ReleaseNotes, "### Others")
 }
 
+func TestChangelogDivideByTag(t *testing.T) {
+	folder := testlib.Mktmp(t)
+	testlib.GitInit(t)
+	testlib.GitCommit(t, "first")
+	testlib.GitTag(t, "v0.1.0")
+	testlib.GitCommit(t, "feat: added feature 1")
+	testlib.GitTag(t, "v0.1.1")
+	testlib.GitCommit(t, "fix: fixed bug 2")
+	testlib.GitCommit(t, "docs: whatever")
+	testlib.GitTag(t, "v0.1.2")
+	testlib.GitCommit(t, "feat: added feature 3")
+	testlib.GitTag(t, "v0.2.0")
+	ctx := context.New(config.Project{
+		Dist: folder,
+		Changelog: config.Changelog{
+			DivideByTag: true,
+			Filters: config.Filters{
+				Exclude: []string{"^docs:"},
+			},
+		},
+	})
+	ctx.Git.PreviousTag = "v0.1.0"
+	ctx.Git.CurrentTag = "v0.2.0"
+	require.NoError(t, Pipe{}.Run(ctx))
+
+	lines := strings.Split(strings.TrimSpace(ctx.ReleaseNotes), "\n")
+	require.Len(t, lines, 7)
+	require.Equal(t, "## Changelog", lines[0])
+	require.Equal(t, "### v0.2.0", lines[1])
+	require.True(t, strings.HasSuffix(lines[2], " feat: added feature 3"), lines[2])
+	require.Equal(t, "### v0.1.2", lines[3])
+	require.True(t, strings.HasSuffix(lines[4], " fix: fixed bug 2"), lines[4])
+	require.Equal(t, "### v0.1.1", lines[5])
+	require.True(t, strings.HasSuffix(lines[6], " feat: added feature 1"), lines[6])
+}
+
+func TestChangelogDivideByTagGroups(t *testing.T) {
+	folder := testlib.Mktmp(t)
+	testlib.GitInit(t)
+	testlib.GitCommit(t, "first")
+	testlib.GitTag(t, "v0.1.0")
+	testlib.GitCommit(t, "feat: added feature 1")
+	testlib.GitCommit(t, "fix: fixed bug 1")
+	testlib.GitTag(t, "v0.1.1")
+	testlib.GitCommit(t, "docs: whatever")
+	testlib.GitTag(t, "v0.1.2")
+	testlib.GitCommit(t, "fix: fixed bug 2")
+	testlib.GitTag(t, "v0.2.0")
+	ctx := context.New(config.Project{
+		Dist: folder,
+		Changelog: config.Changelog{
+			DivideByTag: true,
+			Filters: config.Filters{
+				Exclude: []string{"^docs:"},
+			},
+			Groups: []config.ChangeLogGroup{
+				{Title: "Features", Regexp: "feat:", Order: 0},
+				{Title: "Bug fixes", Regexp: "fix:", Order: 1},
+			},
+		},
+	})
+	ctx.Git.PreviousTag = "v0.1.0"
+	ctx.Git.CurrentTag = "v0.2.0"
+	require.NoError(t, Pipe{}.Run(ctx))
+
+	var headings []string
+	for _, line := range strings.Split(ctx.ReleaseNotes, "\n") {
+		if strings.HasPrefix(line, "#") {
+			headings = append(headings, line)
+		}
+	}
+	// v0.1.2 only has filtered out entries, so it has no section.
+	require.Equal(t, []string{
+		"## Changelog",
+		"### v0.2.0",
+		"#### Bug fixes",
+		"### v0.1.1",
+		"#### Features",
+		"#### Bug fixes",
+	}, headings)
+}
+
+func TestChangelogDivideByTagWithoutIntermediateTags(t *testing.T) {
+	folder := testlib.Mktmp(t)
+	testlib.GitInit(t)
+	testlib.GitCommit(t, "first")
+	testlib.GitTag(t, "v0.1.0")
+	testlib.GitCommit(t, "feat: added feature 1")
+	testlib.GitTag(t, "v0.2.0")
+	ctx := context.New(config.Project{
+		Dist: folder,
+		Changelog: config.Changelog{
+			DivideByTag: true,
+		},
+	})
+	ctx.Git.PreviousTag = "v0.1.0"
+	ctx.Git.CurrentTag = "v0.2.0"
+	require.NoError(t, Pipe{}.Run(ctx))
+	require.Contains(t, ctx.ReleaseNotes, "## Changelog")
+	require.Contains(t, ctx.ReleaseNotes, "feat: added feature 1")
+	require.NotContains(t, ctx.ReleaseNotes, "### v0.2.0")
+}
+
+func TestChangelogTagMessage(t *testing.T) {
+	ctx := context.New(config.Project{
+		Dist: t.TempDir(),
+		Changelog: config.Changelog{
+			Use: useTag,
+			Groups: []config.ChangeLogGroup{
+				{Title: "Features", Regexp: "^feat"},
+			},
+		},
+	})
+	ctx.Git.CurrentTag = "v1.0.0"
+	ctx.Git.TagSubject = "First stable release"
+	ctx.Git.TagBody = "It's been a long way.\n\n- feat: *everything*"
+	require.NoError(t, Pipe{}.Run(ctx))
+	require.Equal(t, "First stable release\n\nIt's been a long way.\n\n- feat: *everything*\n", ctx.ReleaseNotes)
+
+	ctx.ReleaseNotes = ""
+	ctx.Git.TagBody = ""
+	require.NoError(t, Pipe{}.Run(ctx))
+	require.Equal(t, "First stable release\n", ctx.ReleaseNotes)
+
+	ctx.ReleaseNotes = ""
+	ctx.Git.TagSubject = ""
+	require.EqualError(t, Pipe{}.Run(ctx), `changelog.use is "tag", but tag v1.0.0 has no message`)
+}
+
 func TestGroupBadRegex(t *testing.T) {
 	folder := testlib.Mktmp(t)
 	testlib.GitInit(t)
@@ -0,0 +1,41 @@
+package changelog
+
+import (
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/client"
+	"github.com/goreleaser/goreleaser/pkg/context"
+)
+
+// loadPreviousDownloads loads the download counts of the previous release
+// into the context, so they can be used in the release notes, header, footer
+// and announce templates.
+// Failing to do so only warns, as the stats are not essential to the release.
+func loadPreviousDownloads(ctx *context.Context) {
+	if !ctx.Config.Changelog.DownloadStats || ctx.Git.PreviousTag == "" {
+		return
+	}
+	cli, err := client.New(ctx)
+	if err != nil {
+		log.FromContext(ctx).WithError(err).Warn("could not get previous release download stats")
+		return
+	}
+	if err := previousDownloads(ctx, cli); err != nil {
+		log.FromContext(ctx).WithError(err).Warn("could not get previous release download stats")
+	}
+}
+
+func previousDownloads(ctx *context.Context, cli client.Client) error {
+	counter, ok := cli.(client.DownloadCounter)
+	if !ok {
+		return client.ErrNotImplemented
+	}
+	downloads, err := counter.ReleaseDownloads(ctx, ctx.Git.PreviousTag)
+	if err != nil {
+		return err
+	}
+	ctx.PreviousDownloads = downloads
+	log.FromContext(ctx).WithField("tag", ctx.Git.PreviousTag).
+		WithField("downloads", ctx.PreviousDownloads.Total()).
+		Debug("loaded previous release download stats")
+	return nil
+}
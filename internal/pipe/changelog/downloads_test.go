@@ -0,0 +1,52 @@
+package changelog
+
+import (
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/client"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreviousDownloads(t *testing.T) {
+	ctx := context.New(config.Project{})
+	ctx.Git.PreviousTag = "v1.0.0"
+	require.NoError(t, previousDownloads(ctx, &client.Mock{
+		Downloads: map[string]int{
+			"foo_linux_amd64.tar.gz":  10,
+			"foo_darwin_arm64.tar.gz": 5,
+		},
+	}))
+	require.Equal(t, context.ReleaseDownloads{
+		"foo_linux_amd64.tar.gz":  10,
+		"foo_darwin_arm64.tar.gz": 5,
+	}, ctx.PreviousDownloads)
+	require.Equal(t, 15, ctx.PreviousDownloads.Total())
+}
+
+func TestPreviousDownloadsNotImplemented(t *testing.T) {
+	ctx := context.New(config.Project{})
+	ctx.Git.PreviousTag = "v1.0.0"
+	require.ErrorIs(t, previousDownloads(ctx, &client.Mock{}), client.ErrNotImplemented)
+	require.Empty(t, ctx.PreviousDownloads)
+}
+
+func TestLoadPreviousDownloadsDisabled(t *testing.T) {
+	ctx := context.New(config.Project{})
+	ctx.Git.PreviousTag = "v1.0.0"
+	loadPreviousDownloads(ctx)
+	require.Empty(t, ctx.PreviousDownloads)
+}
+
+func TestLoadPreviousDownloadsInvalidClient(t *testing.T) {
+	ctx := context.New(config.Project{
+		Changelog: config.Changelog{
+			DownloadStats: true,
+		},
+	})
+	ctx.TokenType = "nope"
+	ctx.Git.PreviousTag = "v1.0.0"
+	loadPreviousDownloads(ctx)
+	require.Empty(t, ctx.PreviousDownloads)
+}
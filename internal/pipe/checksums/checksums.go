@@ -7,7 +7,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"sort"
+	"slices"
 	"strings"
 	"sync"
 
@@ -16,6 +16,7 @@ import (
 	"github.com/goreleaser/goreleaser/internal/extrafiles"
 	"github.com/goreleaser/goreleaser/internal/semerrgroup"
 	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/config"
 	"github.com/goreleaser/goreleaser/pkg/context"
 )
 
@@ -38,11 +39,29 @@ func (Pipe) Default(ctx *context.Context) error {
 	if ctx.Config.Checksum.Algorithm == "" {
 		ctx.Config.Checksum.Algorithm = "sha256"
 	}
-	return nil
+	if ctx.Config.Checksum.Format == "" {
+		ctx.Config.Checksum.Format = formatGNU
+	}
+	if err := validateFormat(ctx.Config.Checksum.Format); err != nil {
+		return err
+	}
+	if ctx.Config.Checksum.DisableCombined && !ctx.Config.Checksum.Split {
+		return errors.New("checksum: disable_combined requires split to be enabled")
+	}
+	_, err := buildFilter(ctx)
+	return err
 }
 
 // Run the pipe.
 func (Pipe) Run(ctx *context.Context) error {
+	if ctx.Config.Checksum.Split {
+		if err := split(ctx); err != nil {
+			return err
+		}
+	}
+	if ctx.Config.Checksum.DisableCombined {
+		return nil
+	}
 	filename, err := tmpl.New(ctx).Apply(ctx.Config.Checksum.NameTemplate)
 	if err != nil {
 		return err
@@ -60,7 +79,7 @@ func (Pipe) Run(ctx *context.Context) error {
 		Name: filename,
 		Extra: map[string]interface{}{
 			artifact.ExtraRefresh: func() error {
-				log.WithField("file", filename).Info("refreshing checksums")
+				log.FromContext(ctx).WithField("file", filename).Info("refreshing checksums")
 				return refresh(ctx, filepath)
 			},
 		},
@@ -68,25 +87,67 @@ func (Pipe) Run(ctx *context.Context) error {
 	return nil
 }
 
-func refresh(ctx *context.Context, filepath string) error {
-	lock.Lock()
-	defer lock.Unlock()
-	filter := artifact.Or(
-		artifact.ByType(artifact.UploadableArchive),
-		artifact.ByType(artifact.UploadableBinary),
-		artifact.ByType(artifact.UploadableSourceArchive),
-		artifact.ByType(artifact.LinuxPackage),
-		artifact.ByType(artifact.SBOM),
-	)
-	if len(ctx.Config.Checksum.IDs) > 0 {
-		filter = artifact.And(filter, artifact.ByIDs(ctx.Config.Checksum.IDs...))
+// split writes a checksum file for each artifact, named after it, e.g.
+// foo.tar.gz.sha256.
+func split(ctx *context.Context) error {
+	artifactList, err := checksummable(ctx)
+	if err != nil {
+		if errors.Is(err, errNoArtifacts) {
+			return nil
+		}
+		return err
+	}
+
+	g := semerrgroup.New(ctx.Parallelism)
+	for _, a := range artifactList {
+		a := a
+		g.Go(func() error {
+			filename := a.Name + Extension(ctx.Config.Checksum.Algorithm, ctx.Config.Checksum.Format)
+			path := filepath.Join(ctx.Config.Dist, filename)
+			if err := refreshOne(ctx, a, path); err != nil {
+				return err
+			}
+			ctx.Artifacts.Add(&artifact.Artifact{
+				Type: artifact.Checksum,
+				Path: path,
+				Name: filename,
+				Extra: map[string]interface{}{
+					artifact.ExtraRefresh: func() error {
+						log.FromContext(ctx).WithField("file", filename).Debug("refreshing checksum")
+						return refreshOne(ctx, a, path)
+					},
+				},
+			})
+			return nil
+		})
+	}
+	return g.Wait()
+}
+
+func refreshOne(ctx *context.Context, a *artifact.Artifact, path string) error {
+	sum, err := checksums(ctx, ctx.Config.Checksum.Algorithm, a)
+	if err != nil {
+		return err
+	}
+	content, err := render(ctx.Config.Checksum.Format, []entry{sum})
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(content), 0o644)
+}
+
+// checksummable returns the artifacts and extra files to checksum.
+func checksummable(ctx *context.Context) ([]*artifact.Artifact, error) {
+	filter, err := buildFilter(ctx)
+	if err != nil {
+		return nil, err
 	}
 
 	artifactList := ctx.Artifacts.Filter(filter).List()
 
 	extraFiles, err := extrafiles.Find(ctx, ctx.Config.Checksum.ExtraFiles)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	for name, path := range extraFiles {
@@ -98,20 +159,30 @@ func refresh(ctx *context.Context, filepath string) error {
 	}
 
 	if len(artifactList) == 0 {
-		return errNoArtifacts
+		return nil, errNoArtifacts
+	}
+	return artifactList, nil
+}
+
+func refresh(ctx *context.Context, filepath string) error {
+	lock.Lock()
+	defer lock.Unlock()
+	artifactList, err := checksummable(ctx)
+	if err != nil {
+		return err
 	}
 
 	g := semerrgroup.New(ctx.Parallelism)
-	sumLines := make([]string, len(artifactList))
+	sums := make([]entry, len(artifactList))
 	for i, artifact := range artifactList {
 		i := i
 		artifact := artifact
 		g.Go(func() error {
-			sumLine, err := checksums(ctx.Config.Checksum.Algorithm, artifact)
+			sum, err := checksums(ctx, ctx.Config.Checksum.Algorithm, artifact)
 			if err != nil {
 				return err
 			}
-			sumLines[i] = sumLine
+			sums[i] = sum
 			return nil
 		})
 	}
@@ -121,6 +192,11 @@ func refresh(ctx *context.Context, filepath string) error {
 		return err
 	}
 
+	content, err := render(ctx.Config.Checksum.Format, sums)
+	if err != nil {
+		return err
+	}
+
 	file, err := os.OpenFile(
 		filepath,
 		os.O_APPEND|os.O_WRONLY|os.O_CREATE|os.O_TRUNC,
@@ -131,17 +207,55 @@ func refresh(ctx *context.Context, filepath string) error {
 	}
 	defer file.Close()
 
-	// sort to ensure the signature is deterministic downstream
-	sort.Strings(sumLines)
-	_, err = file.WriteString(strings.Join(sumLines, ""))
+	_, err = file.WriteString(content)
 	return err
 }
 
-func checksums(algorithm string, artifact *artifact.Artifact) (string, error) {
-	log.WithField("file", artifact.Name).Debug("checksumming")
+// checksummedTypes are the names of the types of artifacts that can be
+// included in the checksums file.
+var checksummedTypes = []string{"archive", "binary", "source", "package", "appimage", "msi", "dmg", "sbom"}
+
+func buildFilter(ctx *context.Context) (artifact.Filter, error) {
+	cfg := ctx.Config.Checksum
+	selected, err := artifact.ByConfig(config.ArtifactFilters{
+		IDs:   cfg.IDs,
+		Types: cfg.Types,
+	}, cfg.Exclude, ctx.Config.Platforms)
+	if err != nil {
+		return nil, fmt.Errorf("checksum: %w", err)
+	}
+	for _, name := range cfg.Types {
+		if !slices.Contains(checksummedTypes, name) {
+			return nil, fmt.Errorf(
+				"checksum: artifacts of type %q can't be checksummed, valid types are: %s",
+				name, strings.Join(checksummedTypes, ", "),
+			)
+		}
+	}
+	filter, err := artifact.ByTypeNames(checksummedTypes...)
+	if err != nil {
+		return nil, err
+	}
+	if ctx.Config.Source.SkipChecksum {
+		filter = artifact.And(filter, artifact.Not(artifact.ByType(artifact.UploadableSourceArchive)))
+	}
+	return artifact.And(filter, selected), nil
+}
+
+func checksums(ctx *context.Context, algorithm string, artifact *artifact.Artifact) (entry, error) {
+	log.FromContext(ctx).WithField("file", artifact.Name).Debug("checksumming")
 	sha, err := artifact.Checksum(algorithm)
 	if err != nil {
-		return "", err
+		return entry{}, err
+	}
+	info, err := os.Stat(artifact.Path)
+	if err != nil {
+		return entry{}, err
 	}
-	return fmt.Sprintf("%v  %v\n", sha, artifact.Name), nil
+	return entry{
+		Algorithm: algorithm,
+		File:      artifact.Name,
+		Checksum:  sha,
+		Size:      info.Size(),
+	}, nil
 }
@@ -24,8 +24,10 @@ func TestPipe(t *testing.T) {
 	const sum = "61d034473102d7dac305902770471fd50f4c5b26f6831a56dd90b5184b3c30fc  "
 
 	tests := map[string]struct {
-		ids  []string
-		want string
+		ids     []string
+		types   []string
+		exclude config.ArtifactFilters
+		want    string
 	}{
 		"default": {
 			want: strings.Join([]string{
@@ -44,6 +46,31 @@ func TestPipe(t *testing.T) {
 				sum + archive,
 			}, "\n") + "\n",
 		},
+		"select types": {
+			types: []string{"archive", "package"},
+			want: strings.Join([]string{
+				sum + linuxPackage,
+				sum + archive,
+			}, "\n") + "\n",
+		},
+		"exclude types": {
+			exclude: config.ArtifactFilters{
+				Types: []string{"package"},
+			},
+			want: strings.Join([]string{
+				sum + binary,
+				sum + archive,
+			}, "\n") + "\n",
+		},
+		"exclude ids": {
+			exclude: config.ArtifactFilters{
+				IDs: []string{"id-1"},
+			},
+			want: strings.Join([]string{
+				sum + linuxPackage,
+				sum + archive,
+			}, "\n") + "\n",
+		},
 	}
 	for name, tt := range tests {
 		t.Run(name, func(t *testing.T) {
@@ -58,6 +85,8 @@ func TestPipe(t *testing.T) {
 						NameTemplate: "{{ .ProjectName }}_{{ .Env.FOO }}_checksums.txt",
 						Algorithm:    "sha256",
 						IDs:          tt.ids,
+						Types:        tt.types,
+						Exclude:      tt.exclude,
 					},
 				},
 			)
@@ -101,6 +130,47 @@ func TestPipe(t *testing.T) {
 	}
 }
 
+func TestPipeSource(t *testing.T) {
+	for name, skip := range map[string]bool{
+		"included": false,
+		"skipped":  true,
+	} {
+		t.Run(name, func(t *testing.T) {
+			folder := t.TempDir()
+			file := filepath.Join(folder, "binary")
+			require.NoError(t, os.WriteFile(file, []byte("some string"), 0o644))
+			ctx := context.New(config.Project{
+				Dist:        folder,
+				ProjectName: "binary",
+				Source:      config.Source{SkipChecksum: skip},
+				Checksum: config.Checksum{
+					NameTemplate: "checksums.txt",
+					Algorithm:    "sha256",
+				},
+			})
+			ctx.Artifacts.Add(&artifact.Artifact{
+				Name: "binary.tar.gz",
+				Path: file,
+				Type: artifact.UploadableArchive,
+			})
+			ctx.Artifacts.Add(&artifact.Artifact{
+				Name: "source.tar.gz",
+				Path: file,
+				Type: artifact.UploadableSourceArchive,
+			})
+			require.NoError(t, Pipe{}.Run(ctx))
+			bts, err := os.ReadFile(filepath.Join(folder, "checksums.txt"))
+			require.NoError(t, err)
+			require.Contains(t, string(bts), "binary.tar.gz")
+			if skip {
+				require.NotContains(t, string(bts), "source.tar.gz")
+				return
+			}
+			require.Contains(t, string(bts), "source.tar.gz")
+		})
+	}
+}
+
 func TestRefreshModifying(t *testing.T) {
 	const binary = "binary"
 	folder := t.TempDir()
@@ -243,6 +313,34 @@ func TestDefault(t *testing.T) {
 		ctx.Config.Checksum.NameTemplate,
 	)
 	require.Equal(t, "sha256", ctx.Config.Checksum.Algorithm)
+	require.Equal(t, "gnu", ctx.Config.Checksum.Format)
+}
+
+func TestDefaultInvalidTypes(t *testing.T) {
+	ctx := context.New(config.Project{
+		Checksum: config.Checksum{
+			Types: []string{"nope"},
+		},
+	})
+	require.EqualError(t, Pipe{}.Default(ctx), `checksum: invalid artifact type: "nope"`)
+
+	ctx = context.New(config.Project{
+		Checksum: config.Checksum{
+			Exclude: config.ArtifactFilters{
+				Types: []string{"nope"},
+			},
+		},
+	})
+	require.EqualError(t, Pipe{}.Default(ctx), `checksum: exclude: invalid artifact type: "nope"`)
+}
+
+func TestDefaultTypeNotChecksummed(t *testing.T) {
+	ctx := context.New(config.Project{
+		Checksum: config.Checksum{
+			Types: []string{"archive", "signature"},
+		},
+	})
+	require.EqualError(t, Pipe{}.Default(ctx), `checksum: artifacts of type "signature" can't be checksummed, valid types are: archive, binary, source, package, appimage, msi, dmg, sbom`)
 }
 
 func TestDefaultSet(t *testing.T) {
@@ -388,3 +486,122 @@ func TestSkip(t *testing.T) {
 }
 
 // TODO: add tests for LinuxPackage and UploadableSourceArchive
+
+func TestPipeSplit(t *testing.T) {
+	const sum = "61d034473102d7dac305902770471fd50f4c5b26f6831a56dd90b5184b3c30fc  "
+	for name, disableCombined := range map[string]bool{
+		"with combined":    false,
+		"without combined": true,
+	} {
+		t.Run(name, func(t *testing.T) {
+			folder := t.TempDir()
+			file := filepath.Join(folder, "binary")
+			require.NoError(t, os.WriteFile(file, []byte("some string"), 0o644))
+			ctx := context.New(config.Project{
+				Dist:        folder,
+				ProjectName: "binary",
+				Checksum: config.Checksum{
+					NameTemplate:    "checksums.txt",
+					Algorithm:       "sha256",
+					Split:           true,
+					DisableCombined: disableCombined,
+				},
+			})
+			ctx.Git.CurrentTag = "1.2.3"
+			for _, name := range []string{"binary", "binary.tar.gz"} {
+				ctx.Artifacts.Add(&artifact.Artifact{
+					Name: name,
+					Path: file,
+					Type: artifact.UploadableArchive,
+				})
+			}
+			require.NoError(t, Pipe{}.Run(ctx))
+
+			var names []string
+			for _, check := range ctx.Artifacts.Filter(artifact.ByType(artifact.Checksum)).List() {
+				names = append(names, check.Name)
+			}
+			expected := []string{"binary.sha256", "binary.tar.gz.sha256"}
+			if !disableCombined {
+				expected = append(expected, "checksums.txt")
+			}
+			require.ElementsMatch(t, expected, names)
+
+			bts, err := os.ReadFile(filepath.Join(folder, "binary.tar.gz.sha256"))
+			require.NoError(t, err)
+			require.Equal(t, sum+"binary.tar.gz\n", string(bts))
+			_, err = os.Stat(filepath.Join(folder, "checksums.txt"))
+			require.Equal(t, disableCombined, os.IsNotExist(err))
+		})
+	}
+}
+
+func TestRefreshSplit(t *testing.T) {
+	folder := t.TempDir()
+	file := filepath.Join(folder, "binary")
+	require.NoError(t, os.WriteFile(file, []byte("some string"), 0o644))
+	ctx := context.New(config.Project{
+		Dist: folder,
+		Checksum: config.Checksum{
+			Algorithm:       "sha256",
+			Split:           true,
+			DisableCombined: true,
+		},
+	})
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name: "binary",
+		Path: file,
+		Type: artifact.UploadableBinary,
+	})
+	require.NoError(t, Pipe{}.Run(ctx))
+	checks := ctx.Artifacts.Filter(artifact.ByType(artifact.Checksum)).List()
+	require.Len(t, checks, 1)
+	previous, err := os.ReadFile(checks[0].Path)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(file, []byte("some other string"), 0o644))
+	require.NoError(t, checks[0].Refresh())
+	current, err := os.ReadFile(checks[0].Path)
+	require.NoError(t, err)
+	require.NotEqual(t, string(previous), string(current))
+}
+
+func TestDefaultInvalidFormat(t *testing.T) {
+	ctx := context.New(config.Project{
+		Checksum: config.Checksum{
+			Format: "nope",
+		},
+	})
+	require.EqualError(t, Pipe{}.Default(ctx), `checksum: invalid format "nope", valid formats are gnu, bsd and json`)
+}
+
+func TestPipeBSDFormat(t *testing.T) {
+	folder := t.TempDir()
+	file := filepath.Join(folder, "binary")
+	require.NoError(t, os.WriteFile(file, []byte("some string"), 0o644))
+	ctx := context.New(config.Project{
+		Dist: folder,
+		Checksum: config.Checksum{
+			NameTemplate: "checksums.txt",
+			Format:       "bsd",
+		},
+	})
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name: "binary",
+		Path: file,
+		Type: artifact.UploadableBinary,
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.NoError(t, Pipe{}.Run(ctx))
+	bts, err := os.ReadFile(filepath.Join(folder, "checksums.txt"))
+	require.NoError(t, err)
+	require.Equal(t, "SHA256 (binary) = 61d034473102d7dac305902770471fd50f4c5b26f6831a56dd90b5184b3c30fc\n", string(bts))
+}
+
+func TestDefaultDisableCombinedWithoutSplit(t *testing.T) {
+	ctx := context.New(config.Project{
+		Checksum: config.Checksum{
+			DisableCombined: true,
+		},
+	})
+	require.EqualError(t, Pipe{}.Default(ctx), "checksum: disable_combined requires split to be enabled")
+}
@@ -0,0 +1,114 @@
+package checksums
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	formatGNU  = "gnu"
+	formatBSD  = "bsd"
+	formatJSON = "json"
+)
+
+// entry is the checksum of a single file.
+type entry struct {
+	Algorithm string `json:"algorithm"`
+	File      string `json:"file"`
+	Checksum  string `json:"checksum"`
+	Size      int64  `json:"size"`
+}
+
+func validateFormat(format string) error {
+	switch format {
+	case formatGNU, formatBSD, formatJSON:
+		return nil
+	default:
+		return fmt.Errorf("checksum: invalid format %q, valid formats are %s, %s and %s", format, formatGNU, formatBSD, formatJSON)
+	}
+}
+
+// render renders the given entries in the given format, sorted to ensure the
+// signature is deterministic downstream.
+func render(format string, entries []entry) (string, error) {
+	switch format {
+	case formatJSON:
+		sorted := make([]entry, len(entries))
+		copy(sorted, entries)
+		sort.Slice(sorted, func(i, j int) bool {
+			return sorted[i].File < sorted[j].File
+		})
+		bts, err := json.MarshalIndent(sorted, "", "  ")
+		if err != nil {
+			return "", err
+		}
+		return string(bts) + "\n", nil
+	case formatBSD:
+		lines := make([]string, 0, len(entries))
+		for _, e := range entries {
+			lines = append(lines, fmt.Sprintf("%s (%s) = %s\n", strings.ToUpper(e.Algorithm), e.File, e.Checksum))
+		}
+		sort.Strings(lines)
+		return strings.Join(lines, ""), nil
+	default:
+		lines := make([]string, 0, len(entries))
+		for _, e := range entries {
+			lines = append(lines, fmt.Sprintf("%v  %v\n", e.Checksum, e.File))
+		}
+		sort.Strings(lines)
+		return strings.Join(lines, ""), nil
+	}
+}
+
+// Extension is the extension of split checksum files, e.g. .sha256.
+func Extension(algorithm, format string) string {
+	if format == formatJSON {
+		return "." + algorithm + ".json"
+	}
+	return "." + algorithm
+}
+
+// Parse parses checksum files written in the given format, returning the
+// checksum of each file by its name.
+func Parse(format string, content []byte) (map[string]string, error) {
+	result := map[string]string{}
+	if format == formatJSON {
+		var entries []entry
+		if err := json.Unmarshal(content, &entries); err != nil {
+			return nil, fmt.Errorf("checksum: invalid json checksum file: %w", err)
+		}
+		for _, e := range entries {
+			result[e.File] = e.Checksum
+		}
+		return result, nil
+	}
+	for _, line := range strings.Split(string(content), "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		file, sum, ok := parseLine(format, line)
+		if !ok {
+			return nil, fmt.Errorf("checksum: invalid %s checksum line: %q", format, line)
+		}
+		result[file] = sum
+	}
+	return result, nil
+}
+
+func parseLine(format, line string) (file, sum string, ok bool) {
+	if format == formatBSD {
+		start := strings.Index(line, " (")
+		end := strings.LastIndex(line, ") = ")
+		if start == -1 || end < start {
+			return "", "", false
+		}
+		return line[start+2 : end], line[end+4:], true
+	}
+	parts := strings.SplitN(line, "  ", 2)
+	if len(parts) != 2 {
+		return "", "", false
+	}
+	return parts[1], parts[0], true
+}
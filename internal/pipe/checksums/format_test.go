@@ -0,0 +1,77 @@
+package checksums
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRender(t *testing.T) {
+	entries := []entry{
+		{Algorithm: "sha256", File: "foo.tar.gz", Checksum: "bbb", Size: 10},
+		{Algorithm: "sha256", File: "bar.tar.gz", Checksum: "aaa", Size: 20},
+	}
+	for format, expected := range map[string]string{
+		"":        "aaa  bar.tar.gz\nbbb  foo.tar.gz\n",
+		formatGNU: "aaa  bar.tar.gz\nbbb  foo.tar.gz\n",
+		formatBSD: "SHA256 (bar.tar.gz) = aaa\nSHA256 (foo.tar.gz) = bbb\n",
+		formatJSON: `[
+  {
+    "algorithm": "sha256",
+    "file": "bar.tar.gz",
+    "checksum": "aaa",
+    "size": 20
+  },
+  {
+    "algorithm": "sha256",
+    "file": "foo.tar.gz",
+    "checksum": "bbb",
+    "size": 10
+  }
+]
+`,
+	} {
+		t.Run(format, func(t *testing.T) {
+			out, err := render(format, entries)
+			require.NoError(t, err)
+			require.Equal(t, expected, out)
+		})
+	}
+}
+
+func TestExtension(t *testing.T) {
+	require.Equal(t, ".sha256", Extension("sha256", formatGNU))
+	require.Equal(t, ".sha512", Extension("sha512", formatBSD))
+	require.Equal(t, ".sha256.json", Extension("sha256", formatJSON))
+}
+
+func TestParse(t *testing.T) {
+	entries := []entry{
+		{Algorithm: "sha256", File: "foo.tar.gz", Checksum: "bbb", Size: 10},
+		{Algorithm: "sha256", File: "bar baz.tar.gz", Checksum: "aaa", Size: 20},
+	}
+	for _, format := range []string{formatGNU, formatBSD, formatJSON} {
+		format := format
+		t.Run(format, func(t *testing.T) {
+			out, err := render(format, entries)
+			require.NoError(t, err)
+			sums, err := Parse(format, []byte(out))
+			require.NoError(t, err)
+			require.Equal(t, map[string]string{
+				"foo.tar.gz":     "bbb",
+				"bar baz.tar.gz": "aaa",
+			}, sums)
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for format, content := range map[string]string{
+		formatGNU:  "aaa foo.tar.gz\n",
+		formatBSD:  "aaa  foo.tar.gz\n",
+		formatJSON: "aaa  foo.tar.gz\n",
+	} {
+		_, err := Parse(format, []byte(content))
+		require.Error(t, err, format)
+	}
+}
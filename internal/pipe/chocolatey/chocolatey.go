@@ -0,0 +1,368 @@
+// Package chocolatey implements the Pipe, packing a chocolatey package that
+// installs the windows archives of the release, and pushing it to a
+// chocolatey repository.
+package chocolatey
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"text/template"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/client"
+	"github.com/goreleaser/goreleaser/internal/pipe"
+	"github.com/goreleaser/goreleaser/internal/semerrgroup"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+)
+
+const (
+	chocoConfigExtra  = "ChocolateyConfig"
+	defaultSourceRepo = "https://push.chocolatey.org/"
+	apiKeyEnv         = "GORELEASER_CHOCOLATEY_API_KEY"
+)
+
+var (
+	// ErrNoChoco happens when choco cannot be found in $PATH.
+	ErrNoChoco = errors.New("choco not present in $PATH")
+
+	// ErrNoWindowsArchives happens when no windows zip archives are found.
+	ErrNoWindowsArchives = errors.New("chocolatey requires windows zip archives")
+
+	// ErrMultipleArchivesSameArch happens when the config yields multiple
+	// archives for the same architecture.
+	ErrMultipleArchivesSameArch = errors.New("one chocolatey package can handle only one archive per architecture. Consider using ids in the chocolateys section")
+)
+
+// Pipe for chocolatey packages.
+type Pipe struct{}
+
+func (Pipe) String() string                 { return "chocolatey packages" }
+func (Pipe) Skip(ctx *context.Context) bool { return len(ctx.Config.Chocolateys) == 0 }
+
+// Default sets the pipe defaults.
+func (Pipe) Default(ctx *context.Context) error {
+	for i := range ctx.Config.Chocolateys {
+		choco := &ctx.Config.Chocolateys[i]
+		if choco.Name == "" {
+			choco.Name = ctx.Config.ProjectName
+		}
+		if choco.Title == "" {
+			choco.Title = ctx.Config.ProjectName
+		}
+		if choco.SourceRepo == "" {
+			choco.SourceRepo = defaultSourceRepo
+		}
+		if choco.APIKey == "" {
+			choco.APIKey = "{{ .Env.CHOCOLATEY_API_KEY }}"
+		}
+	}
+	return nil
+}
+
+// Run packs the chocolatey packages.
+func (Pipe) Run(ctx *context.Context) error {
+	if _, err := exec.LookPath("choco"); err != nil {
+		return ErrNoChoco
+	}
+	cli, err := client.New(ctx)
+	if err != nil {
+		return err
+	}
+	for _, choco := range ctx.Config.Chocolateys {
+		if err := doRun(ctx, choco, cli); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Publish pushes the chocolatey packages.
+func (Pipe) Publish(ctx *context.Context) error {
+	if ctx.SkipPublish {
+		return pipe.ErrSkipPublishEnabled
+	}
+	skips := pipe.SkipMemento{}
+	g := semerrgroup.New(ctx.Parallelism)
+	for _, nupkg := range ctx.Artifacts.Filter(artifact.ByType(artifact.PublishableChocolatey)).List() {
+		choco := nupkg.Extra[chocoConfigExtra].(config.Chocolatey)
+		if choco.SkipPublish {
+			skips.Remember(pipe.Skip("chocolateys.skip_publish is set"))
+			continue
+		}
+		nupkg := nupkg
+		g.Go(func() error {
+			return push(ctx, choco, nupkg)
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return err
+	}
+	return skips.Evaluate()
+}
+
+func doRun(ctx *context.Context, choco config.Chocolatey, cl client.Client) error {
+	filters := []artifact.Filter{
+		artifact.ByGoos("windows"),
+		artifact.ByType(artifact.UploadableArchive),
+		artifact.ByFormats("zip"),
+		artifact.OnlyUnencrypted,
+	}
+	if len(choco.IDs) > 0 {
+		filters = append(filters, artifact.ByIDs(choco.IDs...))
+	}
+	archives := ctx.Artifacts.Filter(artifact.And(filters...)).List()
+	if len(archives) == 0 {
+		return ErrNoWindowsArchives
+	}
+
+	t := tmpl.New(ctx)
+	for _, field := range []*string{
+		&choco.Name,
+		&choco.Title,
+		&choco.Owners,
+		&choco.Authors,
+		&choco.Copyright,
+		&choco.Summary,
+		&choco.Description,
+		&choco.ReleaseNotes,
+		&choco.Tags,
+	} {
+		applied, err := t.Apply(*field)
+		if err != nil {
+			return err
+		}
+		*field = applied
+	}
+
+	install, err := installScript(ctx, choco, cl, archives)
+	if err != nil {
+		return err
+	}
+
+	// the package is built from this folder, as choco packs the files
+	// relative to the nuspec.
+	dir := filepath.Join(ctx.Config.Dist, "chocolatey", choco.Name+"."+ctx.Version)
+	if err := os.MkdirAll(filepath.Join(dir, "tools"), 0o755); err != nil {
+		return err
+	}
+	nuspec := filepath.Join(dir, choco.Name+".nuspec")
+	log.FromContext(ctx).WithField("nuspec", nuspec).Info("writing")
+	if err := os.WriteFile(nuspec, buildNuspec(ctx, choco), 0o644); err != nil { //nolint: gosec
+		return fmt.Errorf("failed to write nuspec: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "tools", "chocolateyinstall.ps1"), []byte(install), 0o644); err != nil { //nolint: gosec
+		return fmt.Errorf("failed to write install script: %w", err)
+	}
+
+	/* #nosec */
+	cmd := exec.CommandContext(ctx, "choco", "pack", nuspec, "--out", ctx.Config.Dist)
+	cmd.Env = ctx.Env.Strings()
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to pack chocolatey package: %w: %s", err, string(out))
+	}
+
+	filename := choco.Name + "." + ctx.Version + ".nupkg"
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name: filename,
+		Path: filepath.Join(ctx.Config.Dist, filename),
+		Type: artifact.PublishableChocolatey,
+		Extra: map[string]interface{}{
+			chocoConfigExtra: choco,
+		},
+	})
+	return nil
+}
+
+func push(ctx *context.Context, choco config.Chocolatey, nupkg *artifact.Artifact) error {
+	log := log.FromContext(ctx).WithField("package", nupkg.Name).WithField("source", choco.SourceRepo)
+	// only templated here, so the key doesn't end up in the artifacts list.
+	key, err := tmpl.New(ctx).Apply(choco.APIKey)
+	if err != nil {
+		return err
+	}
+	if key == "" {
+		return fmt.Errorf("failed to push %s: chocolateys.api_key is empty", nupkg.Name)
+	}
+	log.Info("pushing")
+	if out, err := pushCommand(ctx, choco.SourceRepo, nupkg.Path, key).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to push %s: %w: %s", nupkg.Name, err, string(out))
+	}
+	return nil
+}
+
+// pushCommand returns the command pushing the given package. The API key is
+// passed through the environment and only expanded by the shell, so it is
+// never part of the arguments goreleaser runs.
+func pushCommand(ctx *context.Context, source, path, key string) *exec.Cmd {
+	var cmd *exec.Cmd
+	/* #nosec */
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", "choco", "push", "--source", source, "--api-key", "%"+apiKeyEnv+"%", path)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", `choco push --source "$1" --api-key "$`+apiKeyEnv+`" "$2"`, "choco", source, path)
+	}
+	cmd.Env = append(ctx.Env.Strings(), apiKeyEnv+"="+key)
+	return cmd
+}
+
+// nuspec is the manifest of a chocolatey package.
+// more info: https://docs.chocolatey.org/en-us/create/create-packages#nuspec
+type nuspec struct {
+	XMLName  xml.Name `xml:"package"`
+	Xmlns    string   `xml:"xmlns,attr"`
+	Metadata metadata `xml:"metadata"`
+	Files    []file   `xml:"files>file"`
+}
+
+type metadata struct {
+	ID                       string       `xml:"id"`
+	Version                  string       `xml:"version"`
+	PackageSourceURL         string       `xml:"packageSourceUrl,omitempty"`
+	Owners                   string       `xml:"owners,omitempty"`
+	Title                    string       `xml:"title,omitempty"`
+	Authors                  string       `xml:"authors"`
+	ProjectURL               string       `xml:"projectUrl,omitempty"`
+	IconURL                  string       `xml:"iconUrl,omitempty"`
+	Copyright                string       `xml:"copyright,omitempty"`
+	LicenseURL               string       `xml:"licenseUrl,omitempty"`
+	RequireLicenseAcceptance bool         `xml:"requireLicenseAcceptance"`
+	ProjectSourceURL         string       `xml:"projectSourceUrl,omitempty"`
+	DocsURL                  string       `xml:"docsUrl,omitempty"`
+	BugTrackerURL            string       `xml:"bugTrackerUrl,omitempty"`
+	Tags                     string       `xml:"tags,omitempty"`
+	Summary                  string       `xml:"summary,omitempty"`
+	Description              string       `xml:"description"`
+	ReleaseNotes             string       `xml:"releaseNotes,omitempty"`
+	Dependencies             []dependency `xml:"dependencies>dependency,omitempty"`
+}
+
+type dependency struct {
+	ID      string `xml:"id,attr"`
+	Version string `xml:"version,attr,omitempty"`
+}
+
+type file struct {
+	Source string `xml:"src,attr"`
+	Target string `xml:"target,attr"`
+}
+
+func buildNuspec(ctx *context.Context, choco config.Chocolatey) []byte {
+	spec := nuspec{
+		Xmlns: "http://schemas.microsoft.com/packaging/2015/06/nuspec.xsd",
+		Metadata: metadata{
+			ID:                       choco.Name,
+			Version:                  ctx.Version,
+			PackageSourceURL:         choco.PackageSourceURL,
+			Owners:                   choco.Owners,
+			Title:                    choco.Title,
+			Authors:                  choco.Authors,
+			ProjectURL:               choco.ProjectURL,
+			IconURL:                  choco.IconURL,
+			Copyright:                choco.Copyright,
+			LicenseURL:               choco.LicenseURL,
+			RequireLicenseAcceptance: choco.RequireLicenseAcceptance,
+			ProjectSourceURL:         choco.ProjectSourceURL,
+			DocsURL:                  choco.DocsURL,
+			BugTrackerURL:            choco.BugTrackerURL,
+			Tags:                     choco.Tags,
+			Summary:                  choco.Summary,
+			Description:              choco.Description,
+			ReleaseNotes:             choco.ReleaseNotes,
+		},
+		Files: []file{{Source: `tools\**`, Target: "tools"}},
+	}
+	for _, dep := range choco.Dependencies {
+		spec.Metadata.Dependencies = append(spec.Metadata.Dependencies, dependency{
+			ID:      dep.ID,
+			Version: dep.Version,
+		})
+	}
+	// can't fail, as it only has strings and bools.
+	bts, _ := xml.MarshalIndent(spec, "", "  ")
+	return append([]byte(xml.Header), append(bts, '\n')...)
+}
+
+// archive is the archive of an architecture the install script downloads.
+type archive struct {
+	URL      string
+	Checksum string
+}
+
+type installData struct {
+	Archive32 *archive
+	Archive64 *archive
+}
+
+func installScript(ctx *context.Context, choco config.Chocolatey, cl client.Client, archives []*artifact.Artifact) (string, error) {
+	if choco.URLTemplate == "" {
+		url, err := cl.ReleaseURLTemplate(ctx)
+		if err != nil {
+			return "", err
+		}
+		choco.URLTemplate = url
+	}
+
+	var data installData
+	for _, a := range archives {
+		var target **archive
+		switch a.Goarch {
+		case "386":
+			target = &data.Archive32
+		case "amd64":
+			target = &data.Archive64
+		default:
+			continue
+		}
+		if *target != nil {
+			return "", ErrMultipleArchivesSameArch
+		}
+		sum, err := a.Checksum("sha256")
+		if err != nil {
+			return "", err
+		}
+		url, err := tmpl.New(ctx).WithArtifact(a, map[string]string{}).Apply(choco.URLTemplate)
+		if err != nil {
+			return "", err
+		}
+		*target = &archive{URL: url, Checksum: sum}
+	}
+	if data.Archive32 == nil && data.Archive64 == nil {
+		return "", ErrNoWindowsArchives
+	}
+
+	var out bytes.Buffer
+	if err := installTemplate.Execute(&out, data); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
+
+var installTemplate = template.Must(template.New("chocolateyinstall.ps1").Parse(`$ErrorActionPreference = 'Stop'
+$toolsDir = "$(Split-Path -Parent $MyInvocation.MyCommand.Definition)"
+
+$packageArgs = @{
+  packageName    = $env:ChocolateyPackageName
+  unzipLocation  = $toolsDir
+  {{- with .Archive32 }}
+  url            = '{{ .URL }}'
+  checksum       = '{{ .Checksum }}'
+  checksumType   = 'sha256'
+  {{- end }}
+  {{- with .Archive64 }}
+  url64bit       = '{{ .URL }}'
+  checksum64     = '{{ .Checksum }}'
+  checksumType64 = 'sha256'
+  {{- end }}
+}
+
+Install-ChocolateyZipPackage @packageArgs
+`))
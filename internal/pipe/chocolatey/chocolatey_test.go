@@ -0,0 +1,275 @@
+package chocolatey
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/client"
+	"github.com/goreleaser/goreleaser/internal/golden"
+	"github.com/goreleaser/goreleaser/internal/testlib"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDescription(t *testing.T) {
+	require.NotEmpty(t, Pipe{}.String())
+}
+
+func TestSkip(t *testing.T) {
+	require.True(t, Pipe{}.Skip(context.New(config.Project{})))
+	require.False(t, Pipe{}.Skip(context.New(config.Project{
+		Chocolateys: []config.Chocolatey{{}},
+	})))
+}
+
+func TestDefault(t *testing.T) {
+	ctx := context.New(config.Project{
+		ProjectName: "myproject",
+		Chocolateys: []config.Chocolatey{{}},
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.Equal(t, config.Chocolatey{
+		Name:       "myproject",
+		Title:      "myproject",
+		SourceRepo: "https://push.chocolatey.org/",
+		APIKey:     "{{ .Env.CHOCOLATEY_API_KEY }}",
+	}, ctx.Config.Chocolateys[0])
+}
+
+func TestRunNoChoco(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+	ctx := context.New(config.Project{
+		Chocolateys: []config.Chocolatey{{}},
+	})
+	require.Equal(t, ErrNoChoco, Pipe{}.Run(ctx))
+}
+
+func TestRunPipe(t *testing.T) {
+	calls := fakeChoco(t)
+	ctx := context.New(config.Project{
+		ProjectName: "foo",
+		Dist:        t.TempDir(),
+		Chocolateys: []config.Chocolatey{{
+			IDs:              []string{"default"},
+			Owners:           "caarlos0",
+			Authors:          "caarlos0",
+			ProjectURL:       "https://goreleaser.com",
+			LicenseURL:       "https://github.com/goreleaser/goreleaser/blob/main/LICENSE.md",
+			Tags:             "foo bar",
+			Summary:          "Foo & bar",
+			Description:      "{{ .ProjectName }} installer package.",
+			ReleaseNotes:     "https://github.com/goreleaser/goreleaser/releases/tag/{{ .Tag }}",
+			PackageSourceURL: "https://github.com/goreleaser/goreleaser",
+			Dependencies: []config.ChocolateyDependency{
+				{ID: "nfpm", Version: "2.15.0"},
+			},
+		}},
+	})
+	ctx.Version = "1.0.0"
+	ctx.Git = context.GitInfo{CurrentTag: "v1.0.0"}
+	require.NoError(t, Pipe{}.Default(ctx))
+	addArchives(t, ctx)
+	require.NoError(t, doRun(ctx, ctx.Config.Chocolateys[0], client.NewMock()))
+
+	dir := filepath.Join(ctx.Config.Dist, "chocolatey", "foo.1.0.0")
+	golden.RequireEqualExt(t, golden.RequireReadFile(t, filepath.Join(dir, "foo.nuspec")), ".nuspec")
+	golden.RequireEqualExt(t, golden.RequireReadFile(t, filepath.Join(dir, "tools", "chocolateyinstall.ps1")), ".ps1")
+
+	nupkgs := ctx.Artifacts.Filter(artifact.ByType(artifact.PublishableChocolatey)).List()
+	require.Len(t, nupkgs, 1)
+	require.Equal(t, "foo.1.0.0.nupkg", nupkgs[0].Name)
+	require.FileExists(t, nupkgs[0].Path)
+
+	bts, err := os.ReadFile(calls)
+	require.NoError(t, err)
+	require.Equal(t, "pack "+filepath.Join(dir, "foo.nuspec")+" --out "+ctx.Config.Dist, strings.TrimSpace(string(bts)))
+}
+
+func TestRunPipeOnlyOneArch(t *testing.T) {
+	fakeChoco(t)
+	ctx := context.New(config.Project{
+		ProjectName: "foo",
+		Dist:        t.TempDir(),
+		Chocolateys: []config.Chocolatey{{IDs: []string{"amd64-only"}}},
+	})
+	ctx.Version = "1.0.0"
+	ctx.Git = context.GitInfo{CurrentTag: "v1.0.0"}
+	require.NoError(t, Pipe{}.Default(ctx))
+	addArchives(t, ctx)
+	require.NoError(t, doRun(ctx, ctx.Config.Chocolateys[0], client.NewMock()))
+	bts := golden.RequireReadFile(t, filepath.Join(ctx.Config.Dist, "chocolatey", "foo.1.0.0", "tools", "chocolateyinstall.ps1"))
+	require.Contains(t, string(bts), "url64bit")
+	require.NotContains(t, string(bts), "url            =")
+}
+
+func TestRunPipeErrors(t *testing.T) {
+	for name, tt := range map[string]struct {
+		choco config.Chocolatey
+		err   string
+	}{
+		"no archives": {
+			choco: config.Chocolatey{IDs: []string{"nope"}},
+			err:   ErrNoWindowsArchives.Error(),
+		},
+		"multiple archives": {
+			choco: config.Chocolatey{IDs: []string{"default", "amd64-only"}},
+			err:   ErrMultipleArchivesSameArch.Error(),
+		},
+		"invalid template": {
+			choco: config.Chocolatey{IDs: []string{"default"}, Description: "{{ .Nope }"},
+			err:   `template: tmpl:1: unexpected "}" in operand`,
+		},
+		"invalid url template": {
+			choco: config.Chocolatey{IDs: []string{"default"}, URLTemplate: "{{ .Nope }"},
+			err:   `template: tmpl:1: unexpected "}" in operand`,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			fakeChoco(t)
+			ctx := context.New(config.Project{
+				ProjectName: "foo",
+				Dist:        t.TempDir(),
+				Chocolateys: []config.Chocolatey{tt.choco},
+			})
+			ctx.Version = "1.0.0"
+			ctx.Git = context.GitInfo{CurrentTag: "v1.0.0"}
+			require.NoError(t, Pipe{}.Default(ctx))
+			addArchives(t, ctx)
+			require.EqualError(t, doRun(ctx, ctx.Config.Chocolateys[0], client.NewMock()), tt.err)
+		})
+	}
+}
+
+func TestRunPipePackFails(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "choco"), []byte("#!/bin/sh\necho nope\nexit 1\n"), 0o755))
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	ctx := context.New(config.Project{
+		ProjectName: "foo",
+		Dist:        t.TempDir(),
+		Chocolateys: []config.Chocolatey{{IDs: []string{"default"}}},
+	})
+	ctx.Version = "1.0.0"
+	ctx.Git = context.GitInfo{CurrentTag: "v1.0.0"}
+	require.NoError(t, Pipe{}.Default(ctx))
+	addArchives(t, ctx)
+	err := doRun(ctx, ctx.Config.Chocolateys[0], client.NewMock())
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to pack chocolatey package")
+	require.Contains(t, err.Error(), "nope")
+}
+
+func TestPublish(t *testing.T) {
+	calls := fakeChoco(t)
+	ctx := context.New(config.Project{
+		ProjectName: "foo",
+		Dist:        t.TempDir(),
+		Chocolateys: []config.Chocolatey{{IDs: []string{"default"}}},
+	})
+	ctx.Version = "1.0.0"
+	ctx.Git = context.GitInfo{CurrentTag: "v1.0.0"}
+	require.NoError(t, Pipe{}.Default(ctx))
+	addArchives(t, ctx)
+	ctx.Env["CHOCOLATEY_API_KEY"] = "secret"
+	require.NoError(t, doRun(ctx, ctx.Config.Chocolateys[0], client.NewMock()))
+	require.NoError(t, os.Remove(calls))
+	require.NoError(t, Pipe{}.Publish(ctx))
+
+	bts, err := os.ReadFile(calls)
+	require.NoError(t, err)
+	require.Equal(t, "push --source https://push.chocolatey.org/ --api-key secret "+filepath.Join(ctx.Config.Dist, "foo.1.0.0.nupkg"), strings.TrimSpace(string(bts)))
+}
+
+func TestPushCommand(t *testing.T) {
+	ctx := context.New(config.Project{})
+	cmd := pushCommand(ctx, defaultSourceRepo, "foo.1.0.0.nupkg", "secret")
+	for _, arg := range cmd.Args {
+		require.NotContains(t, arg, "secret")
+	}
+	require.Contains(t, cmd.Env, apiKeyEnv+"=secret")
+}
+
+func TestPublishNoAPIKey(t *testing.T) {
+	fakeChoco(t)
+	ctx := context.New(config.Project{
+		ProjectName: "foo",
+		Dist:        t.TempDir(),
+		Chocolateys: []config.Chocolatey{{IDs: []string{"default"}}},
+	})
+	ctx.Version = "1.0.0"
+	ctx.Git = context.GitInfo{CurrentTag: "v1.0.0"}
+	require.NoError(t, Pipe{}.Default(ctx))
+	addArchives(t, ctx)
+	ctx.Env["CHOCOLATEY_API_KEY"] = ""
+	require.NoError(t, doRun(ctx, ctx.Config.Chocolateys[0], client.NewMock()))
+	require.EqualError(t, Pipe{}.Publish(ctx), "failed to push foo.1.0.0.nupkg: chocolateys.api_key is empty")
+}
+
+func TestPublishSkip(t *testing.T) {
+	fakeChoco(t)
+	ctx := context.New(config.Project{
+		ProjectName: "foo",
+		Dist:        t.TempDir(),
+		Chocolateys: []config.Chocolatey{{IDs: []string{"default"}, SkipPublish: true}},
+	})
+	ctx.Version = "1.0.0"
+	ctx.Git = context.GitInfo{CurrentTag: "v1.0.0"}
+	require.NoError(t, Pipe{}.Default(ctx))
+	addArchives(t, ctx)
+	require.NoError(t, doRun(ctx, ctx.Config.Chocolateys[0], client.NewMock()))
+	testlib.AssertSkipped(t, Pipe{}.Publish(ctx))
+
+	ctx.SkipPublish = true
+	testlib.AssertSkipped(t, Pipe{}.Publish(ctx))
+}
+
+// addArchives adds the windows archives of the default build, and an amd64
+// only one with another ID.
+func addArchives(tb testing.TB, ctx *context.Context) {
+	tb.Helper()
+	for _, a := range []struct {
+		id, goarch, format string
+	}{
+		{"default", "amd64", "zip"},
+		{"default", "386", "zip"},
+		{"default", "arm64", "zip"},
+		{"default", "amd64", "tar.gz"},
+		{"amd64-only", "amd64", "zip"},
+	} {
+		name := "foo_" + a.id + "_windows_" + a.goarch + "." + a.format
+		path := filepath.Join(ctx.Config.Dist, name)
+		require.NoError(tb, os.WriteFile(path, []byte(name), 0o644))
+		ctx.Artifacts.Add(&artifact.Artifact{
+			Name:   name,
+			Path:   path,
+			Goos:   "windows",
+			Goarch: a.goarch,
+			Type:   artifact.UploadableArchive,
+			Extra: map[string]interface{}{
+				artifact.ExtraID:     a.id,
+				artifact.ExtraFormat: a.format,
+			},
+		})
+	}
+}
+
+// fakeChoco puts a choco in the PATH that logs its calls, and creates the
+// package when packing.
+func fakeChoco(tb testing.TB) string {
+	tb.Helper()
+	dir := tb.TempDir()
+	calls := filepath.Join(dir, "calls.log")
+	script := `#!/bin/sh
+echo "$@" >> ` + calls + `
+if [ "$1" = "pack" ]; then
+	echo nupkg > "$4/$(basename "$2" .nuspec).1.0.0.nupkg"
+fi
+`
+	require.NoError(tb, os.WriteFile(filepath.Join(dir, "choco"), []byte(script), 0o755))
+	tb.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return calls
+}
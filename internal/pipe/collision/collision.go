@@ -0,0 +1,88 @@
+// Package collision provides a pipe that checks that no two artifacts would
+// be created with the same name, before anything is built.
+package collision
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/goreleaser/goreleaser/internal/pipe/archive"
+	"github.com/goreleaser/goreleaser/internal/pipe/build"
+	"github.com/goreleaser/goreleaser/pkg/context"
+)
+
+// Pipe that checks for artifact name collisions.
+type Pipe struct{}
+
+func (Pipe) String() string { return "checking artifact names" }
+
+// Run the pipe.
+func (Pipe) Run(ctx *context.Context) error {
+	collisions, err := Check(ctx)
+	if err != nil {
+		return err
+	}
+	return Error(collisions)
+}
+
+// Collision is a file name used by more than one archive and platform.
+type Collision struct {
+	Name  string
+	Users []archive.Filename
+}
+
+func (c Collision) String() string {
+	users := make([]string, 0, len(c.Users))
+	for _, u := range c.Users {
+		users = append(users, fmt.Sprintf("%s (%s)", u.Archive, u.Platform))
+	}
+	return fmt.Sprintf("%s is used by %s", c.Name, strings.Join(users, ", "))
+}
+
+// Check renders the name templates of the archives for all the targets of
+// their builds, and returns the names that more than one of them resolve to.
+func Check(ctx *context.Context) ([]Collision, error) {
+	binaries, err := build.Plan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	names, err := archive.Filenames(ctx, binaries)
+	if err != nil {
+		return nil, err
+	}
+
+	byName := map[string][]archive.Filename{}
+	for _, name := range names {
+		byName[name.Name] = append(byName[name.Name], name)
+	}
+	var result []Collision
+	for name, users := range byName {
+		if len(users) < 2 {
+			continue
+		}
+		sort.Slice(users, func(i, j int) bool {
+			if users[i].Archive != users[j].Archive {
+				return users[i].Archive < users[j].Archive
+			}
+			return users[i].Platform < users[j].Platform
+		})
+		result = append(result, Collision{Name: name, Users: users})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result, nil
+}
+
+// Error returns an error describing the given collisions, if any.
+func Error(collisions []Collision) error {
+	if len(collisions) == 0 {
+		return nil
+	}
+	lines := make([]string, 0, len(collisions))
+	for _, c := range collisions {
+		lines = append(lines, c.String())
+	}
+	return fmt.Errorf("found %d archive name collisions, check your name templates:\n%s", len(collisions), strings.Join(lines, "\n"))
+}
@@ -0,0 +1,144 @@
+package collision
+
+import (
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/pipe/archive"
+	"github.com/goreleaser/goreleaser/internal/pipe/build"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDescription(t *testing.T) {
+	require.NotEmpty(t, Pipe{}.String())
+}
+
+func newContext(archives ...config.Archive) *context.Context {
+	ctx := context.New(config.Project{
+		ProjectName: "foo",
+		Builds: []config.Build{{
+			ID:      "foo",
+			Binary:  "foo",
+			Targets: []string{"linux_amd64", "linux_arm64", "windows_amd64"},
+		}},
+		Archives: archives,
+	})
+	ctx.Version = "1.0.0"
+	return ctx
+}
+
+func TestNoCollisions(t *testing.T) {
+	ctx := newContext(config.Archive{
+		ID:           "default",
+		Builds:       []string{"foo"},
+		Format:       "tar.gz",
+		NameTemplate: "{{ .ProjectName }}_{{ .Os }}_{{ .Arch }}",
+	})
+	collisions, err := Check(ctx)
+	require.NoError(t, err)
+	require.Empty(t, collisions)
+	require.NoError(t, Pipe{}.Run(ctx))
+}
+
+func TestCollisionsBetweenPlatforms(t *testing.T) {
+	ctx := newContext(config.Archive{
+		ID:           "default",
+		Builds:       []string{"foo"},
+		Format:       "tar.gz",
+		NameTemplate: "{{ .ProjectName }}_{{ .Os }}",
+	})
+	collisions, err := Check(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []Collision{{
+		Name: "foo_linux.tar.gz",
+		Users: []archive.Filename{
+			{Archive: "default", Platform: "linux_amd64", Name: "foo_linux.tar.gz"},
+			{Archive: "default", Platform: "linux_arm64", Name: "foo_linux.tar.gz"},
+		},
+	}}, collisions)
+	require.EqualError(t, Pipe{}.Run(ctx), "found 1 archive name collisions, check your name templates:\n"+
+		"foo_linux.tar.gz is used by default (linux_amd64), default (linux_arm64)")
+}
+
+func TestCollisionsBetweenArchives(t *testing.T) {
+	ctx := newContext(
+		config.Archive{
+			ID:           "a",
+			Builds:       []string{"foo"},
+			Format:       "zip",
+			NameTemplate: "{{ .ProjectName }}_{{ .Os }}_{{ .Arch }}",
+		},
+		config.Archive{
+			ID:           "b",
+			Builds:       []string{"foo"},
+			Format:       "zip",
+			NameTemplate: "{{ .ProjectName }}_{{ .Os }}_{{ .Arch }}",
+		},
+	)
+	collisions, err := Check(ctx)
+	require.NoError(t, err)
+	require.Len(t, collisions, 3)
+	require.Equal(t, "foo_linux_amd64.zip is used by a (linux_amd64), b (linux_amd64)", collisions[0].String())
+}
+
+func TestBinaryFormat(t *testing.T) {
+	ctx := newContext(config.Archive{
+		ID:           "default",
+		Builds:       []string{"foo"},
+		Format:       "binary",
+		NameTemplate: "{{ .Binary }}_{{ .Arch }}",
+	})
+	collisions, err := Check(ctx)
+	require.NoError(t, err)
+	// foo_amd64 and foo_amd64.exe don't collide.
+	require.Empty(t, collisions)
+}
+
+func TestInvalidTemplate(t *testing.T) {
+	ctx := newContext(config.Archive{
+		ID:           "default",
+		Builds:       []string{"foo"},
+		Format:       "tar.gz",
+		NameTemplate: "{{ .Nope }",
+	})
+	_, err := Check(ctx)
+	require.Error(t, err)
+	require.Error(t, Pipe{}.Run(ctx))
+}
+
+func TestSplitArchives(t *testing.T) {
+	ctx := newContext(config.Archive{
+		ID:           "default",
+		Builds:       []string{"foo"},
+		Format:       "tar.gz",
+		NameTemplate: "{{ .ProjectName }}_{{ .Os }}",
+		Split:        config.ArchiveSplit{Size: "1GB"},
+	})
+	collisions, err := Check(ctx)
+	require.NoError(t, err)
+	require.Len(t, collisions, 2)
+	require.Equal(t, "foo_linux.tar.gz", collisions[0].Name)
+	require.Equal(t, "foo_linux.tar.gz.parts.json", collisions[1].Name)
+}
+
+func TestVariantArchives(t *testing.T) {
+	ctx := newContext(config.Archive{
+		Builds:       []string{"foo"},
+		Format:       "tar.gz",
+		NameTemplate: "{{ .ProjectName }}_{{ .Os }}_{{ .Arch }}",
+	})
+	ctx.Config.Builds[0].Variants = []config.BuildVariant{{
+		ID: "debug",
+		Archive: config.BuildVariantArchive{
+			Enabled:      true,
+			NameTemplate: "{{ .ProjectName }}_{{ .Os }}_{{ .Arch }}",
+		},
+	}}
+	require.NoError(t, build.Pipe{}.Default(ctx))
+	require.NoError(t, archive.Pipe{}.Default(ctx))
+	collisions, err := Check(ctx)
+	require.NoError(t, err)
+	require.Len(t, collisions, 3)
+	require.Equal(t, "foo_linux_amd64.tar.gz is used by default (linux_amd64), default-debug (linux_amd64)", collisions[0].String())
+}
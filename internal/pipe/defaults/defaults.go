@@ -37,6 +37,15 @@ func (Pipe) Run(ctx *context.Context) error {
 
 		ctx.Config.GiteaURLs.Download = strings.ReplaceAll(apiURL, "/api/v1", "")
 	}
+	if ctx.Config.BitbucketURLs.API == "" {
+		ctx.Config.BitbucketURLs.API = client.DefaultBitbucketAPIURL
+	}
+	if ctx.Config.BitbucketURLs.Download == "" {
+		ctx.Config.BitbucketURLs.Download = client.DefaultBitbucketDownloadURL
+	}
+	if ctx.Config.AzureDevOpsURLs.API == "" {
+		ctx.Config.AzureDevOpsURLs.API = client.DefaultAzureDevOpsAPIURL
+	}
 	for _, defaulter := range defaults.Defaulters {
 		if err := errhandler.Handle(defaulter.Default)(ctx); err != nil {
 			return err
@@ -112,6 +112,14 @@ func TestFillPartial(t *testing.T) {
 	}
 	require.NoError(t, Pipe{}.Run(ctx))
 	require.Equal(t, "https://gitea.com", ctx.Config.GiteaURLs.Download)
+
+	ctx = &context.Context{
+		TokenType: context.TokenTypeBitbucket,
+	}
+	require.NoError(t, Pipe{}.Run(ctx))
+	require.Equal(t, "https://api.bitbucket.org/2.0", ctx.Config.BitbucketURLs.API)
+	require.Equal(t, "https://bitbucket.org", ctx.Config.BitbucketURLs.Download)
+	require.Equal(t, "https://dev.azure.com", ctx.Config.AzureDevOpsURLs.API)
 }
 
 func TestGiteaTemplateDownloadURL(t *testing.T) {
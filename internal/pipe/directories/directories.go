@@ -0,0 +1,83 @@
+// Package directories provides a pipe that adds whole directories, e.g.
+// generated docs or dashboards, as artifacts, so they can be published along
+// with the other artifacts.
+package directories
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/ids"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+)
+
+// Pipe that adds directory artifacts.
+type Pipe struct{}
+
+func (Pipe) String() string                 { return "directories" }
+func (Pipe) Skip(ctx *context.Context) bool { return len(ctx.Config.Directories) == 0 }
+
+// Default sets the pipe defaults.
+func (Pipe) Default(ctx *context.Context) error {
+	ids := ids.New("directories")
+	for i := range ctx.Config.Directories {
+		dir := &ctx.Config.Directories[i]
+		if dir.ID == "" {
+			dir.ID = "default"
+		}
+		if dir.Path == "" {
+			return fmt.Errorf("directories %s: path is required", dir.ID)
+		}
+		ids.Inc(dir.ID)
+	}
+	return ids.Validate()
+}
+
+// Run the pipe.
+func (Pipe) Run(ctx *context.Context) error {
+	for _, dir := range ctx.Config.Directories {
+		if err := add(ctx, dir); err != nil {
+			return fmt.Errorf("directories %s: %w", dir.ID, err)
+		}
+	}
+	return nil
+}
+
+func add(ctx *context.Context, dir config.Directory) error {
+	t := tmpl.New(ctx)
+	path, err := t.Apply(dir.Path)
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", path)
+	}
+
+	name := filepath.Base(filepath.Clean(path))
+	if dir.NameTemplate != "" {
+		name, err = t.Apply(dir.NameTemplate)
+		if err != nil {
+			return err
+		}
+	}
+
+	log.FromContext(ctx).WithField("path", path).WithField("name", name).Info("adding directory")
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Type: artifact.Directory,
+		Name: name,
+		Path: path,
+		Extra: map[string]interface{}{
+			artifact.ExtraID: dir.ID,
+		},
+	})
+	return nil
+}
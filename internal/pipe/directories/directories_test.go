@@ -0,0 +1,109 @@
+package directories
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDescription(t *testing.T) {
+	require.NotEmpty(t, Pipe{}.String())
+}
+
+func TestSkip(t *testing.T) {
+	require.True(t, Pipe{}.Skip(context.New(config.Project{})))
+	require.False(t, Pipe{}.Skip(context.New(config.Project{
+		Directories: []config.Directory{{}},
+	})))
+}
+
+func TestDefault(t *testing.T) {
+	ctx := context.New(config.Project{
+		Directories: []config.Directory{
+			{Path: "site"},
+			{ID: "dashboards", Path: "dashboards"},
+		},
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.Equal(t, []config.Directory{
+		{ID: "default", Path: "site"},
+		{ID: "dashboards", Path: "dashboards"},
+	}, ctx.Config.Directories)
+}
+
+func TestDefaultErrors(t *testing.T) {
+	t.Run("no path", func(t *testing.T) {
+		require.EqualError(t, Pipe{}.Default(context.New(config.Project{
+			Directories: []config.Directory{{}},
+		})), "directories default: path is required")
+	})
+
+	t.Run("duplicated ids", func(t *testing.T) {
+		require.EqualError(t, Pipe{}.Default(context.New(config.Project{
+			Directories: []config.Directory{{Path: "a"}, {Path: "b"}},
+		})), "found 2 directories with the ID 'default', please fix your config")
+	})
+}
+
+func TestRun(t *testing.T) {
+	folder := t.TempDir()
+	site := filepath.Join(folder, "site")
+	require.NoError(t, os.MkdirAll(filepath.Join(site, "css"), 0o755))
+
+	ctx := context.New(config.Project{
+		ProjectName: "proj",
+		Directories: []config.Directory{
+			{ID: "site", Path: site + "/"},
+			{ID: "docs", Path: site, NameTemplate: "{{ .ProjectName }}_docs_{{ .Version }}"},
+		},
+	})
+	ctx.Version = "1.2.3"
+	require.NoError(t, Pipe{}.Run(ctx))
+
+	dirs := ctx.Artifacts.Filter(artifact.ByType(artifact.Directory)).List()
+	require.Len(t, dirs, 2)
+	require.Equal(t, "site", dirs[0].Name)
+	require.Equal(t, site+"/", dirs[0].Path)
+	require.Equal(t, "site", dirs[0].ID())
+	require.Equal(t, "proj_docs_1.2.3", dirs[1].Name)
+	require.Equal(t, "docs", dirs[1].ID())
+}
+
+func TestRunErrors(t *testing.T) {
+	folder := t.TempDir()
+	file := filepath.Join(folder, "file")
+	require.NoError(t, os.WriteFile(file, []byte("foo"), 0o644))
+
+	for name, tt := range map[string]struct {
+		dir config.Directory
+		err string
+	}{
+		"not found": {
+			dir: config.Directory{ID: "foo", Path: filepath.Join(folder, "nope")},
+			err: "directories foo: stat " + filepath.Join(folder, "nope") + ": no such file or directory",
+		},
+		"not a directory": {
+			dir: config.Directory{ID: "foo", Path: file},
+			err: "directories foo: " + file + " is not a directory",
+		},
+		"invalid path template": {
+			dir: config.Directory{ID: "foo", Path: "{{ .Nope }"},
+			err: `directories foo: template: tmpl:1: unexpected "}" in operand`,
+		},
+		"invalid name template": {
+			dir: config.Directory{ID: "foo", Path: folder, NameTemplate: "{{ .Nope }"},
+			err: `directories foo: template: tmpl:1: unexpected "}" in operand`,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			require.EqualError(t, Pipe{}.Run(context.New(config.Project{
+				Directories: []config.Directory{tt.dir},
+			})), tt.err)
+		})
+	}
+}
@@ -56,7 +56,7 @@ func (p Pipe) Announce(ctx *context.Context) error {
 		return fmt.Errorf("announce: failed to announce to discord: %w", err)
 	}
 
-	log.Infof("posting: '%s'", msg)
+	log.FromContext(ctx).Infof("posting: '%s'", msg)
 
 	webhook, err := disgohook.NewWebhookClientByToken(nil, nil, fmt.Sprintf("%s/%s", cfg.WebhookID, cfg.WebhookToken))
 	if err != nil {
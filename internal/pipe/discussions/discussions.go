@@ -0,0 +1,106 @@
+// Package discussions announces releases as GitHub discussions.
+package discussions
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/client"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/context"
+)
+
+const (
+	defaultTitleTemplate       = `{{ .ProjectName }} {{ .Tag }}`
+	defaultMessageTemplate     = "{{ .ReleaseNotes }}\n\n---\n\nRelease: {{ .ReleaseURL }}"
+	defaultPinnedTitleTemplate = `Latest release: {{ .ProjectName }} {{ .Tag }}`
+)
+
+// Pipe that announces releases as GitHub discussions.
+type Pipe struct{}
+
+func (Pipe) String() string { return "github discussions" }
+func (Pipe) Skip(ctx *context.Context) bool {
+	return !ctx.Config.Announce.GitHubDiscussions.Enabled
+}
+
+// Default sets the pipe defaults.
+func (Pipe) Default(ctx *context.Context) error {
+	cfg := &ctx.Config.Announce.GitHubDiscussions
+	if cfg.TitleTemplate == "" {
+		cfg.TitleTemplate = defaultTitleTemplate
+	}
+	if cfg.MessageTemplate == "" {
+		cfg.MessageTemplate = defaultMessageTemplate
+	}
+	if cfg.PinnedTitleTemplate == "" {
+		cfg.PinnedTitleTemplate = defaultPinnedTitleTemplate
+	}
+	if cfg.Repository.Name == "" {
+		cfg.Repository.Owner = ctx.Config.Release.GitHub.Owner
+		cfg.Repository.Name = ctx.Config.Release.GitHub.Name
+	}
+	return nil
+}
+
+// Announce posts the release notes as a new discussion, and updates the
+// pinned discussion if any.
+func (Pipe) Announce(ctx *context.Context) error {
+	cli, err := client.New(ctx)
+	if err != nil {
+		return fmt.Errorf("announce: failed to announce to github discussions: %w", err)
+	}
+	cli, err = client.NewIfToken(ctx, cli, ctx.Config.Announce.GitHubDiscussions.Repository.Token)
+	if err != nil {
+		return fmt.Errorf("announce: failed to announce to github discussions: %w", err)
+	}
+	if err := doAnnounce(ctx, cli); err != nil {
+		return fmt.Errorf("announce: failed to announce to github discussions: %w", err)
+	}
+	return nil
+}
+
+func doAnnounce(ctx *context.Context, cli client.Client) error {
+	cfg := ctx.Config.Announce.GitHubDiscussions
+	poster, ok := cli.(client.DiscussionPoster)
+	if !ok {
+		return errors.New("discussions are only supported on GitHub")
+	}
+	if cfg.Category == "" {
+		return errors.New("category is required")
+	}
+
+	t := tmpl.New(ctx)
+	title, err := t.Apply(cfg.TitleTemplate)
+	if err != nil {
+		return err
+	}
+	msg, err := t.Apply(cfg.MessageTemplate)
+	if err != nil {
+		return err
+	}
+
+	repo := client.Repo{
+		Owner: cfg.Repository.Owner,
+		Name:  cfg.Repository.Name,
+	}
+	log.FromContext(ctx).WithField("repo", repo.String()).
+		WithField("category", cfg.Category).
+		Infof("posting: '%s'", title)
+	url, err := poster.CreateDiscussion(ctx, repo, cfg.Category, title, msg)
+	if err != nil {
+		return err
+	}
+	log.FromContext(ctx).WithField("url", url).Info("discussion created")
+
+	if cfg.PinnedDiscussion == 0 {
+		return nil
+	}
+	pinnedTitle, err := t.Apply(cfg.PinnedTitleTemplate)
+	if err != nil {
+		return err
+	}
+	log.FromContext(ctx).WithField("discussion", cfg.PinnedDiscussion).Info("updating pinned discussion")
+	return poster.UpdateDiscussion(ctx, repo, cfg.PinnedDiscussion, pinnedTitle, msg)
+}
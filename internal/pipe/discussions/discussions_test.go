@@ -0,0 +1,132 @@
+package discussions
+
+import (
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/client"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStringer(t *testing.T) {
+	require.Equal(t, "github discussions", Pipe{}.String())
+}
+
+func TestSkip(t *testing.T) {
+	require.True(t, Pipe{}.Skip(context.New(config.Project{})))
+	require.False(t, Pipe{}.Skip(context.New(config.Project{
+		Announce: config.Announce{
+			GitHubDiscussions: config.GitHubDiscussions{Enabled: true},
+		},
+	})))
+}
+
+func TestDefault(t *testing.T) {
+	ctx := context.New(config.Project{
+		Release: config.Release{
+			GitHub: config.Repo{Owner: "foo", Name: "bar"},
+		},
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	cfg := ctx.Config.Announce.GitHubDiscussions
+	require.Equal(t, defaultTitleTemplate, cfg.TitleTemplate)
+	require.Equal(t, defaultMessageTemplate, cfg.MessageTemplate)
+	require.Equal(t, defaultPinnedTitleTemplate, cfg.PinnedTitleTemplate)
+	require.Equal(t, config.RepoRef{Owner: "foo", Name: "bar"}, cfg.Repository)
+}
+
+func TestDefaultRepository(t *testing.T) {
+	ctx := context.New(config.Project{
+		Release: config.Release{
+			GitHub: config.Repo{Owner: "foo", Name: "bar"},
+		},
+		Announce: config.Announce{
+			GitHubDiscussions: config.GitHubDiscussions{
+				Repository: config.RepoRef{Owner: "foo", Name: "community"},
+			},
+		},
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.Equal(t, config.RepoRef{Owner: "foo", Name: "community"}, ctx.Config.Announce.GitHubDiscussions.Repository)
+}
+
+func testContext(tb testing.TB, cfg config.GitHubDiscussions) *context.Context {
+	tb.Helper()
+	cfg.Enabled = true
+	ctx := context.New(config.Project{
+		ProjectName: "foo",
+		Release: config.Release{
+			GitHub: config.Repo{Owner: "foo", Name: "bar"},
+		},
+		Announce: config.Announce{
+			GitHubDiscussions: cfg,
+		},
+	})
+	ctx.Git.CurrentTag = "v1.0.0"
+	ctx.ReleaseURL = "https://github.com/foo/bar/releases/tag/v1.0.0"
+	ctx.ReleaseNotes = "## Changelog\n\n- abc: fix"
+	require.NoError(tb, Pipe{}.Default(ctx))
+	return ctx
+}
+
+func TestAnnounce(t *testing.T) {
+	ctx := testContext(t, config.GitHubDiscussions{Category: "Announcements"})
+	cli := client.NewMock()
+	require.NoError(t, doAnnounce(ctx, cli))
+	require.Equal(t, []client.MockDiscussion{{
+		Category: "Announcements",
+		Title:    "foo v1.0.0",
+		Body:     "## Changelog\n\n- abc: fix\n\n---\n\nRelease: https://github.com/foo/bar/releases/tag/v1.0.0",
+	}}, cli.Discussions)
+	require.Empty(t, cli.UpdatedDiscussions)
+}
+
+func TestAnnouncePinned(t *testing.T) {
+	ctx := testContext(t, config.GitHubDiscussions{
+		Category:         "Announcements",
+		MessageTemplate:  "{{ .Tag }} is out",
+		PinnedDiscussion: 42,
+	})
+	cli := client.NewMock()
+	require.NoError(t, doAnnounce(ctx, cli))
+	require.Len(t, cli.Discussions, 1)
+	require.Equal(t, map[int]client.MockDiscussion{
+		42: {Title: "Latest release: foo v1.0.0", Body: "v1.0.0 is out"},
+	}, cli.UpdatedDiscussions)
+}
+
+func TestAnnounceErrors(t *testing.T) {
+	for name, tt := range map[string]struct {
+		cfg config.GitHubDiscussions
+		err string
+	}{
+		"no category": {
+			cfg: config.GitHubDiscussions{},
+			err: "category is required",
+		},
+		"invalid title": {
+			cfg: config.GitHubDiscussions{Category: "a", TitleTemplate: "{{ .Foo }"},
+			err: `template: tmpl:1: unexpected "}" in operand`,
+		},
+		"invalid message": {
+			cfg: config.GitHubDiscussions{Category: "a", MessageTemplate: "{{ .Foo }"},
+			err: `template: tmpl:1: unexpected "}" in operand`,
+		},
+		"invalid pinned title": {
+			cfg: config.GitHubDiscussions{Category: "a", PinnedDiscussion: 1, PinnedTitleTemplate: "{{ .Foo }"},
+			err: `template: tmpl:1: unexpected "}" in operand`,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			ctx := testContext(t, tt.cfg)
+			require.EqualError(t, doAnnounce(ctx, client.NewMock()), tt.err)
+		})
+	}
+}
+
+func TestAnnounceNotGitHub(t *testing.T) {
+	ctx := testContext(t, config.GitHubDiscussions{Category: "a"})
+	cli := struct{ client.Client }{client.NewMock()}
+	require.EqualError(t, doAnnounce(ctx, cli), "discussions are only supported on GitHub")
+}
@@ -21,11 +21,11 @@ func (Pipe) String() string {
 func (Pipe) Run(ctx *context.Context) (err error) {
 	_, err = os.Stat(ctx.Config.Dist)
 	if os.IsNotExist(err) {
-		log.Debugf("%s doesn't exist, creating empty folder", ctx.Config.Dist)
+		log.FromContext(ctx).Debugf("%s doesn't exist, creating empty folder", ctx.Config.Dist)
 		return mkdir(ctx)
 	}
 	if ctx.RmDist {
-		log.Info("--rm-dist is set, cleaning it up")
+		log.FromContext(ctx).Info("--rm-dist is set, cleaning it up")
 		err = os.RemoveAll(ctx.Config.Dist)
 		if err == nil {
 			err = mkdir(ctx)
@@ -37,13 +37,13 @@ func (Pipe) Run(ctx *context.Context) (err error) {
 		return
 	}
 	if len(files) != 0 {
-		log.Debugf("there are %d files on %s", len(files), ctx.Config.Dist)
+		log.FromContext(ctx).Debugf("there are %d files on %s", len(files), ctx.Config.Dist)
 		return fmt.Errorf(
 			"%s is not empty, remove it before running goreleaser or use the --rm-dist flag",
 			ctx.Config.Dist,
 		)
 	}
-	log.Debugf("%s is empty", ctx.Config.Dist)
+	log.FromContext(ctx).Debugf("%s is empty", ctx.Config.Dist)
 	return mkdir(ctx)
 }
 
@@ -0,0 +1,244 @@
+// Package dmg implements the Pipe interface, packaging macOS binaries, or
+// app bundles, as disk images.
+package dmg
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/gio"
+	"github.com/goreleaser/goreleaser/internal/ids"
+	"github.com/goreleaser/goreleaser/internal/semerrgroup"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+)
+
+const defaultNameTemplate = "{{ .ProjectName }}_{{ .Version }}_{{ .Os }}_{{ .Arch }}"
+
+// ErrNoDMGTool is returned when neither hdiutil, genisoimage nor mkisofs can
+// be found in $PATH.
+var ErrNoDMGTool = errors.New("hdiutil, genisoimage or mkisofs not present in $PATH")
+
+// Pipe for DMG packaging.
+type Pipe struct{}
+
+func (Pipe) String() string                 { return "dmgs" }
+func (Pipe) Skip(ctx *context.Context) bool { return len(ctx.Config.DMGs) == 0 }
+
+// Default sets the pipe defaults.
+func (Pipe) Default(ctx *context.Context) error {
+	ids := ids.New("dmgs")
+	for i := range ctx.Config.DMGs {
+		dmg := &ctx.Config.DMGs[i]
+		if dmg.ID == "" {
+			dmg.ID = "default"
+		}
+		if dmg.NameTemplate == "" {
+			dmg.NameTemplate = defaultNameTemplate
+		}
+		if dmg.VolumeName == "" {
+			dmg.VolumeName = "{{ .ProjectName }}"
+		}
+		ids.Inc(dmg.ID)
+	}
+	return ids.Validate()
+}
+
+// Run the pipe.
+func (Pipe) Run(ctx *context.Context) error {
+	tool, err := lookupTool()
+	if err != nil {
+		return err
+	}
+	g := semerrgroup.New(ctx.Parallelism)
+	for _, dmg := range ctx.Config.DMGs {
+		filters := []artifact.Filter{
+			artifact.ByGoos("darwin"),
+			artifact.Or(
+				artifact.ByType(artifact.Binary),
+				artifact.ByType(artifact.UniversalBinary),
+			),
+		}
+		if len(dmg.Builds) > 0 {
+			filters = append(filters, artifact.ByIDs(dmg.Builds...))
+		}
+		for _, binaries := range ctx.Artifacts.Filter(artifact.And(filters...)).GroupByPlatform() {
+			dmg := dmg
+			binaries := binaries
+			g.Go(func() error {
+				return create(ctx, dmg, tool, binaries)
+			})
+		}
+	}
+	return g.Wait()
+}
+
+// lookupTool returns the tool creating disk images found in $PATH, preferring
+// hdiutil, which is only available on macOS.
+func lookupTool() (string, error) {
+	for _, tool := range []string{"hdiutil", "genisoimage", "mkisofs"} {
+		if _, err := exec.LookPath(tool); err == nil {
+			return tool, nil
+		}
+	}
+	return "", ErrNoDMGTool
+}
+
+func create(ctx *context.Context, dmg config.DMG, tool string, binaries []*artifact.Artifact) error {
+	t := tmpl.New(ctx).WithArtifact(binaries[0], nil)
+	name, err := t.Apply(dmg.NameTemplate)
+	if err != nil {
+		return err
+	}
+	for _, field := range []*string{&dmg.VolumeName, &dmg.App, &dmg.Background} {
+		applied, err := t.Apply(*field)
+		if err != nil {
+			return err
+		}
+		*field = applied
+	}
+	log := log.FromContext(ctx).WithField("dmg", name+".dmg")
+
+	// the staging folder holds the contents of the disk image.
+	staging := filepath.Join(ctx.Config.Dist, name+".dmgroot")
+	if err := os.MkdirAll(staging, 0o755); err != nil {
+		return err
+	}
+	if dmg.App != "" {
+		if err := gio.Copy(dmg.App, filepath.Join(staging, filepath.Base(dmg.App))); err != nil {
+			return fmt.Errorf("failed to copy app: %w", err)
+		}
+	} else {
+		for _, b := range binaries {
+			if err := gio.CopyWithMode(b.Path, filepath.Join(staging, b.Name), 0o755); err != nil {
+				return fmt.Errorf("failed to copy binary: %w", err)
+			}
+		}
+	}
+	if !dmg.SkipApplicationsLink {
+		if err := os.Symlink("/Applications", filepath.Join(staging, "Applications")); err != nil {
+			return err
+		}
+	}
+	var background string
+	if dmg.Background != "" {
+		background = filepath.Base(dmg.Background)
+		if err := os.MkdirAll(filepath.Join(staging, ".background"), 0o755); err != nil {
+			return err
+		}
+		if err := gio.Copy(dmg.Background, filepath.Join(staging, ".background", background)); err != nil {
+			return fmt.Errorf("failed to copy background: %w", err)
+		}
+	}
+	for _, file := range dmg.Files {
+		dst := file.Destination
+		if dst == "" {
+			dst = file.Source
+		}
+		dst = filepath.Join(staging, dst)
+		if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+			return err
+		}
+		if file.Info.Mode != 0 {
+			err = gio.CopyWithMode(file.Source, dst, file.Info.Mode)
+		} else {
+			err = gio.Copy(file.Source, dst)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to copy %s: %w", file.Source, err)
+		}
+	}
+
+	filename := name + ".dmg"
+	path := filepath.Join(ctx.Config.Dist, filename)
+	log.Info("creating")
+	if tool == "hdiutil" {
+		err = hdiutil(ctx, dmg.VolumeName, background, staging, path)
+	} else {
+		if background != "" {
+			log.Warn("the background is only set up when creating dmgs on macOS")
+		}
+		err = run(ctx, tool, "-V", dmg.VolumeName, "-D", "-R", "-apple", "-no-pad", "-o", path, staging)
+	}
+	if err != nil {
+		return err
+	}
+
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Type:   artifact.DMG,
+		Name:   filename,
+		Path:   path,
+		Goos:   binaries[0].Goos,
+		Goarch: binaries[0].Goarch,
+		Extra: map[string]interface{}{
+			artifact.ExtraID:     dmg.ID,
+			artifact.ExtraFormat: "dmg",
+		},
+	})
+	return nil
+}
+
+// hdiutil creates the disk image with hdiutil.
+// If there is a background, the image is first created writable, so Finder
+// can set the background and icon view of its window up.
+func hdiutil(ctx *context.Context, volume, background, staging, path string) error {
+	if background == "" {
+		return run(ctx, "hdiutil", "create", "-volname", volume, "-srcfolder", staging, "-ov", "-format", "UDZO", path)
+	}
+
+	rw := strings.TrimSuffix(path, ".dmg") + ".rw.dmg"
+	defer os.Remove(rw)
+	if err := run(ctx, "hdiutil", "create", "-volname", volume, "-srcfolder", staging, "-ov", "-format", "UDRW", rw); err != nil {
+		return err
+	}
+	mountpoint := strings.TrimSuffix(path, ".dmg") + ".mnt"
+	if err := run(ctx, "hdiutil", "attach", "-readwrite", "-noverify", "-noautoopen", "-mountpoint", mountpoint, rw); err != nil {
+		return err
+	}
+	err := run(ctx, "osascript", "-e", finderScript(volume, background))
+	if detachErr := run(ctx, "hdiutil", "detach", mountpoint); err == nil {
+		err = detachErr
+	}
+	if err != nil {
+		return err
+	}
+	return run(ctx, "hdiutil", "convert", rw, "-ov", "-format", "UDZO", "-o", path)
+}
+
+// finderScript returns the AppleScript setting the window of the given volume
+// up with the given background.
+func finderScript(volume, background string) string {
+	quote := strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+	return `tell application "Finder"
+	tell disk "` + quote.Replace(volume) + `"
+		open
+		set current view of container window to icon view
+		set toolbar visible of container window to false
+		set statusbar visible of container window to false
+		set bounds of container window to {100, 100, 640, 480}
+		set viewOptions to icon view options of container window
+		set arrangement of viewOptions to not arranged
+		set icon size of viewOptions to 96
+		set background picture of viewOptions to file ".background:` + quote.Replace(background) + `"
+		close
+	end tell
+end tell
+`
+}
+
+func run(ctx *context.Context, name string, args ...string) error {
+	/* #nosec */
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Env = ctx.Env.Strings()
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to create dmg: %s: %w: %s", name, err, string(out))
+	}
+	return nil
+}
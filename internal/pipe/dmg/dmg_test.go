@@ -0,0 +1,299 @@
+package dmg
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDescription(t *testing.T) {
+	require.NotEmpty(t, Pipe{}.String())
+}
+
+func TestSkip(t *testing.T) {
+	require.True(t, Pipe{}.Skip(context.New(config.Project{})))
+	require.False(t, Pipe{}.Skip(context.New(config.Project{
+		DMGs: []config.DMG{{}},
+	})))
+}
+
+func TestDefault(t *testing.T) {
+	ctx := context.New(config.Project{
+		DMGs: []config.DMG{{}},
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.Equal(t, config.DMG{
+		ID:           "default",
+		NameTemplate: defaultNameTemplate,
+		VolumeName:   "{{ .ProjectName }}",
+	}, ctx.Config.DMGs[0])
+}
+
+func TestDefaultDuplicateID(t *testing.T) {
+	ctx := context.New(config.Project{
+		DMGs: []config.DMG{{ID: "foo"}, {ID: "foo"}},
+	})
+	require.EqualError(t, Pipe{}.Default(ctx), "found 2 dmgs with the ID 'foo', please fix your config")
+}
+
+func TestRunNoTool(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+	ctx := context.New(config.Project{
+		DMGs: []config.DMG{{}},
+	})
+	require.Equal(t, ErrNoDMGTool, Pipe{}.Run(ctx))
+}
+
+func TestRunGenisoimage(t *testing.T) {
+	calls := fakeTools(t, "genisoimage")
+	ctx := context.New(config.Project{
+		ProjectName: "foo",
+		Dist:        t.TempDir(),
+		DMGs: []config.DMG{{
+			Builds:     []string{"foo"},
+			VolumeName: "{{ .ProjectName }} {{ .Version }}",
+			Background: "testdata/background.png",
+			Files: []config.File{{
+				Source:      "testdata/README.txt",
+				Destination: "docs/README.txt",
+			}},
+		}},
+	})
+	ctx.Version = "1.0.0"
+	require.NoError(t, Pipe{}.Default(ctx))
+	addBinaries(t, ctx)
+	require.NoError(t, Pipe{}.Run(ctx))
+
+	dmgs := ctx.Artifacts.Filter(artifact.ByType(artifact.DMG)).List()
+	var names []string
+	for _, a := range dmgs {
+		names = append(names, a.Name)
+		require.FileExists(t, a.Path)
+		require.Equal(t, "default", a.ExtraOr(artifact.ExtraID, ""))
+		require.Equal(t, "dmg", a.Format())
+	}
+	require.ElementsMatch(t, []string{
+		"foo_1.0.0_darwin_amd64.dmg",
+		"foo_1.0.0_darwin_arm64.dmg",
+	}, names)
+
+	staging := filepath.Join(ctx.Config.Dist, "foo_1.0.0_darwin_amd64.dmgroot")
+	require.FileExists(t, filepath.Join(staging, "foo"))
+	require.FileExists(t, filepath.Join(staging, ".background", "background.png"))
+	require.FileExists(t, filepath.Join(staging, "docs", "README.txt"))
+	link, err := os.Readlink(filepath.Join(staging, "Applications"))
+	require.NoError(t, err)
+	require.Equal(t, "/Applications", link)
+
+	bts, err := os.ReadFile(calls)
+	require.NoError(t, err)
+	require.Contains(t, strings.Split(strings.TrimSpace(string(bts)), "\n"),
+		"genisoimage -V foo 1.0.0 -D -R -apple -no-pad -o "+filepath.Join(ctx.Config.Dist, "foo_1.0.0_darwin_amd64.dmg")+" "+staging)
+}
+
+func TestRunHdiutil(t *testing.T) {
+	calls := fakeTools(t, "hdiutil", "osascript")
+	ctx := context.New(config.Project{
+		ProjectName: "foo",
+		Dist:        t.TempDir(),
+		DMGs: []config.DMG{{
+			Builds:               []string{"foo"},
+			SkipApplicationsLink: true,
+		}},
+	})
+	ctx.Version = "1.0.0"
+	require.NoError(t, Pipe{}.Default(ctx))
+	addBinaries(t, ctx)
+	require.NoError(t, Pipe{}.Run(ctx))
+	require.Len(t, ctx.Artifacts.Filter(artifact.ByType(artifact.DMG)).List(), 2)
+
+	staging := filepath.Join(ctx.Config.Dist, "foo_1.0.0_darwin_arm64.dmgroot")
+	require.NoFileExists(t, filepath.Join(staging, "Applications"))
+
+	bts, err := os.ReadFile(calls)
+	require.NoError(t, err)
+	require.Contains(t, strings.Split(strings.TrimSpace(string(bts)), "\n"),
+		"hdiutil create -volname foo -srcfolder "+staging+" -ov -format UDZO "+filepath.Join(ctx.Config.Dist, "foo_1.0.0_darwin_arm64.dmg"))
+	require.NotContains(t, string(bts), "osascript")
+}
+
+func TestRunHdiutilBackground(t *testing.T) {
+	calls := fakeTools(t, "hdiutil", "osascript")
+	ctx := context.New(config.Project{
+		ProjectName: "foo",
+		Dist:        t.TempDir(),
+		DMGs: []config.DMG{{
+			Builds:     []string{"foo"},
+			Background: "testdata/background.png",
+		}},
+	})
+	ctx.Version = "1.0.0"
+	require.NoError(t, Pipe{}.Default(ctx))
+	addBinaries(t, ctx)
+	require.NoError(t, Pipe{}.Run(ctx))
+
+	bts, err := os.ReadFile(calls)
+	require.NoError(t, err)
+	var cmds []string
+	for _, line := range strings.Split(string(bts), "\n") {
+		if strings.HasPrefix(line, "hdiutil") && strings.Contains(line, "arm64") {
+			cmds = append(cmds, strings.Fields(line)[1])
+		}
+	}
+	require.Equal(t, []string{"create", "attach", "detach", "convert"}, cmds)
+	require.Contains(t, string(bts), `set background picture of viewOptions to file ".background:background.png"`)
+	require.NoFileExists(t, filepath.Join(ctx.Config.Dist, "foo_1.0.0_darwin_arm64.rw.dmg"))
+}
+
+func TestRunApp(t *testing.T) {
+	fakeTools(t, "genisoimage")
+	app := filepath.Join(t.TempDir(), "Foo.app")
+	require.NoError(t, os.MkdirAll(filepath.Join(app, "Contents", "MacOS"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(app, "Contents", "MacOS", "foo"), []byte("fake"), 0o755))
+	ctx := context.New(config.Project{
+		ProjectName: "foo",
+		Dist:        t.TempDir(),
+		DMGs: []config.DMG{{
+			Builds: []string{"foo"},
+			App:    app,
+		}},
+	})
+	ctx.Version = "1.0.0"
+	require.NoError(t, Pipe{}.Default(ctx))
+	addBinaries(t, ctx)
+	require.NoError(t, Pipe{}.Run(ctx))
+	staging := filepath.Join(ctx.Config.Dist, "foo_1.0.0_darwin_amd64.dmgroot")
+	require.FileExists(t, filepath.Join(staging, "Foo.app", "Contents", "MacOS", "foo"))
+	require.NoFileExists(t, filepath.Join(staging, "foo"))
+}
+
+func TestRunUniversalBinary(t *testing.T) {
+	fakeTools(t, "genisoimage")
+	ctx := context.New(config.Project{
+		ProjectName: "foo",
+		Dist:        t.TempDir(),
+		DMGs:        []config.DMG{{Builds: []string{"universal"}}},
+	})
+	ctx.Version = "1.0.0"
+	require.NoError(t, Pipe{}.Default(ctx))
+	addBinaries(t, ctx)
+	require.NoError(t, Pipe{}.Run(ctx))
+	dmgs := ctx.Artifacts.Filter(artifact.ByType(artifact.DMG)).List()
+	require.Len(t, dmgs, 1)
+	require.Equal(t, "foo_1.0.0_darwin_all.dmg", dmgs[0].Name)
+}
+
+func TestRunErrors(t *testing.T) {
+	for name, dmg := range map[string]config.DMG{
+		"name_template": {NameTemplate: "{{ .Foo }"},
+		"volume_name":   {VolumeName: "{{ .Foo }"},
+		"background":    {Background: "/does/not/exist"},
+		"app":           {App: "/does/not/exist"},
+		"files":         {Files: []config.File{{Source: "/does/not/exist"}}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			fakeTools(t, "genisoimage")
+			dmg.Builds = []string{"foo"}
+			ctx := context.New(config.Project{
+				ProjectName: "foo",
+				Dist:        t.TempDir(),
+				DMGs:        []config.DMG{dmg},
+			})
+			ctx.Version = "1.0.0"
+			require.NoError(t, Pipe{}.Default(ctx))
+			addBinaries(t, ctx)
+			require.Error(t, Pipe{}.Run(ctx))
+		})
+	}
+}
+
+func TestRunToolFails(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "genisoimage"), []byte("#!/bin/sh\necho nope\nexit 1\n"), 0o755))
+	t.Setenv("PATH", dir)
+	ctx := context.New(config.Project{
+		ProjectName: "foo",
+		Dist:        t.TempDir(),
+		DMGs:        []config.DMG{{Builds: []string{"foo"}}},
+	})
+	ctx.Version = "1.0.0"
+	require.NoError(t, Pipe{}.Default(ctx))
+	addBinaries(t, ctx)
+	err := Pipe{}.Run(ctx)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to create dmg")
+	require.Contains(t, err.Error(), "nope")
+}
+
+// addBinaries adds the darwin binaries dmgs are made of, a universal one, and
+// a linux one, which is ignored.
+func addBinaries(tb testing.TB, ctx *context.Context) {
+	tb.Helper()
+	dist := ctx.Config.Dist
+	for _, goarch := range []string{"amd64", "arm64"} {
+		path := filepath.Join(dist, "foo_darwin_"+goarch, "foo")
+		require.NoError(tb, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(tb, os.WriteFile(path, []byte("fake binary"), 0o755))
+		ctx.Artifacts.Add(&artifact.Artifact{
+			Name:   "foo",
+			Path:   path,
+			Goos:   "darwin",
+			Goarch: goarch,
+			Type:   artifact.Binary,
+			Extra: map[string]interface{}{
+				artifact.ExtraID: "foo",
+			},
+		})
+	}
+	path := filepath.Join(dist, "foo_darwin_all", "foo")
+	require.NoError(tb, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(tb, os.WriteFile(path, []byte("fake binary"), 0o755))
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name:   "foo",
+		Path:   path,
+		Goos:   "darwin",
+		Goarch: "all",
+		Type:   artifact.UniversalBinary,
+		Extra: map[string]interface{}{
+			artifact.ExtraID: "universal",
+		},
+	})
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name:   "foo",
+		Path:   "nope",
+		Goos:   "linux",
+		Goarch: "amd64",
+		Type:   artifact.Binary,
+		Extra: map[string]interface{}{
+			artifact.ExtraID: "foo",
+		},
+	})
+}
+
+// fakeTools puts the given tools in the PATH, and only them, logging their
+// calls and creating the disk images given as arguments, like the real tools
+// do.
+func fakeTools(tb testing.TB, names ...string) string {
+	tb.Helper()
+	dir := tb.TempDir()
+	calls := filepath.Join(dir, "calls.log")
+	for _, name := range names {
+		script := `#!/bin/sh
+echo "` + name + ` $*" >> ` + calls + `
+for arg; do
+	case "$arg" in
+		*.dmg) echo dmg > "$arg" ;;
+	esac
+done
+`
+		require.NoError(tb, os.WriteFile(filepath.Join(dir, name), []byte(script), 0o755))
+	}
+	tb.Setenv("PATH", dir)
+	return calls
+}
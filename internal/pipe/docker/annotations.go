@@ -0,0 +1,32 @@
+package docker
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/context"
+)
+
+// annotationFlags returns the buildx --annotation flags of the given
+// annotations, for each of the given levels (e.g. index or manifest).
+// Keys are sorted so the command is the same on every run.
+func annotationFlags(ctx *context.Context, annotations map[string]string, levels ...string) ([]string, error) {
+	keys := make([]string, 0, len(annotations))
+	for k := range annotations {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var flags []string
+	for _, level := range levels {
+		for _, k := range keys {
+			value, err := tmpl.New(ctx).Apply(annotations[k])
+			if err != nil {
+				return nil, fmt.Errorf("failed to process annotation '%s': %w", k, err)
+			}
+			flags = append(flags, "--annotation="+level+":"+k+"="+value)
+		}
+	}
+	return flags, nil
+}
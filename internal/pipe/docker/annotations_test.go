@@ -0,0 +1,128 @@
+package docker
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+var testAnnotations = map[string]string{
+	"org.opencontainers.image.version":  "{{ .Version }}",
+	"org.opencontainers.image.revision": "{{ .FullCommit }}",
+}
+
+func TestAnnotationFlags(t *testing.T) {
+	ctx := context.New(config.Project{})
+	ctx.Version = "1.0.0"
+	ctx.Git.FullCommit = "abc"
+	flags, err := annotationFlags(ctx, testAnnotations, "index", "manifest")
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"--annotation=index:org.opencontainers.image.revision=abc",
+		"--annotation=index:org.opencontainers.image.version=1.0.0",
+		"--annotation=manifest:org.opencontainers.image.revision=abc",
+		"--annotation=manifest:org.opencontainers.image.version=1.0.0",
+	}, flags)
+
+	flags, err = annotationFlags(ctx, nil, "index")
+	require.NoError(t, err)
+	require.Empty(t, flags)
+
+	_, err = annotationFlags(ctx, map[string]string{"foo": "{{ .Nope }"}, "index")
+	require.Error(t, err)
+}
+
+func TestMultiPlatformAnnotations(t *testing.T) {
+	calls := fakeDocker(t)
+	dist := t.TempDir()
+	ctx := context.New(config.Project{
+		Dist: dist,
+		Dockers: []config.Docker{{
+			Use:            useBuildx,
+			Dockerfile:     "testdata/Dockerfile.dummy",
+			ImageTemplates: []string{"ghcr.io/foo/bar:{{ .Tag }}"},
+			Platforms:      []string{"linux/amd64"},
+			Annotations:    testAnnotations,
+		}},
+	})
+	ctx.Git.CurrentTag = "v1.0.0"
+	ctx.Git.FullCommit = "abc"
+	ctx.Version = "1.0.0"
+	bin := filepath.Join(dist, "bar")
+	require.NoError(t, os.WriteFile(bin, []byte("bar"), 0o755))
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name:   "bar",
+		Path:   bin,
+		Goos:   "linux",
+		Goarch: "amd64",
+		Type:   artifact.Binary,
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.NoError(t, Pipe{}.Run(ctx))
+	require.NoError(t, Pipe{}.Publish(ctx))
+
+	manifest := "--annotation=manifest:org.opencontainers.image.revision=abc" +
+		" --annotation=manifest:org.opencontainers.image.version=1.0.0"
+	index := "--annotation=index:org.opencontainers.image.revision=abc" +
+		" --annotation=index:org.opencontainers.image.version=1.0.0"
+	root := ctx.Artifacts.Filter(artifact.ByType(artifact.PublishableDockerImage)).List()[0].Extra[dockerContextExtra].(string)
+	require.Equal(t, []string{
+		"buildx build . --platform=linux/amd64 -t ghcr.io/foo/bar:v1.0.0 " + manifest,
+		pushCall(root, "linux/amd64", "ghcr.io/foo/bar") + manifest,
+		"buildx imagetools create --tag ghcr.io/foo/bar:v1.0.0 " + index + " ghcr.io/foo/bar@sha256:linux-amd64",
+	}, dockerCalls(t, calls))
+}
+
+func TestManifestAnnotations(t *testing.T) {
+	calls := fakeDocker(t)
+	ctx := context.New(config.Project{
+		DockerManifests: []config.DockerManifest{{
+			ID:             "foo",
+			NameTemplate:   "ghcr.io/foo/bar:{{ .Tag }}",
+			ImageTemplates: []string{"ghcr.io/foo/bar:{{ .Tag }}-amd64", "ghcr.io/foo/bar:{{ .Tag }}-arm64"},
+			CreateFlags:    []string{"--prefer-index=false"},
+			Use:            useBuildx,
+			Annotations:    testAnnotations,
+		}},
+	})
+	ctx.Git.CurrentTag = "v1.0.0"
+	ctx.Git.FullCommit = "abc"
+	ctx.Version = "1.0.0"
+	require.NoError(t, ManifestPipe{}.Default(ctx))
+	require.NoError(t, ManifestPipe{}.Publish(ctx))
+
+	require.Equal(t, []string{
+		"buildx imagetools create --tag ghcr.io/foo/bar:v1.0.0 --prefer-index=false" +
+			" --annotation=index:org.opencontainers.image.revision=abc" +
+			" --annotation=index:org.opencontainers.image.version=1.0.0" +
+			" ghcr.io/foo/bar:v1.0.0-amd64 ghcr.io/foo/bar:v1.0.0-arm64",
+	}, dockerCalls(t, calls))
+	manifests := ctx.Artifacts.Filter(artifact.ByType(artifact.DockerManifest)).List()
+	require.Len(t, manifests, 1)
+	require.Equal(t, "ghcr.io/foo/bar:v1.0.0", manifests[0].Name)
+}
+
+func TestAnnotationsErrors(t *testing.T) {
+	t.Run("docker without platforms", func(t *testing.T) {
+		ctx := context.New(config.Project{
+			Dockers: []config.Docker{{
+				Use:         useBuildx,
+				Annotations: testAnnotations,
+			}},
+		})
+		require.EqualError(t, Pipe{}.Default(ctx), "docker: annotations can only be used with platforms, as they are lost when images are loaded into the docker daemon")
+	})
+	t.Run("manifest without buildx", func(t *testing.T) {
+		ctx := context.New(config.Project{
+			DockerManifests: []config.DockerManifest{{
+				Annotations: testAnnotations,
+			}},
+		})
+		require.EqualError(t, ManifestPipe{}.Default(ctx), "docker manifest: annotations can only be used with use: buildx")
+	})
+}
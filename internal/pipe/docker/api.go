@@ -60,9 +60,26 @@ func runCommand(ctx *context.Context, dir, binary string, args ...string) error
 	cmd.Stderr = io.MultiWriter(logext.NewWriter(fields, logext.Error), w)
 	cmd.Stdout = io.MultiWriter(logext.NewWriter(fields, logext.Info), w)
 
-	log.WithFields(fields).WithField("args", args[1:]).Debug("running")
+	log.FromContext(ctx).WithFields(fields).WithField("args", args[1:]).Debug("running")
 	if err := cmd.Run(); err != nil {
 		return fmt.Errorf("%w: %s", err, b.String())
 	}
 	return nil
 }
+
+// commandOutput runs the given command and returns its trimmed output.
+func commandOutput(ctx *context.Context, dir, binary string, args ...string) (string, error) {
+	/* #nosec */
+	cmd := exec.CommandContext(ctx, binary, args...)
+	cmd.Dir = dir
+	cmd.Env = ctx.Env.Strings()
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	log.FromContext(ctx).WithField("cmd", append([]string{binary}, args...)).Debug("running")
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", err, stderr.String())
+	}
+	return string(bytes.TrimSpace(out)), nil
+}
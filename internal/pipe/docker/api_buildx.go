@@ -0,0 +1,32 @@
+package docker
+
+import (
+	"fmt"
+
+	"github.com/goreleaser/goreleaser/pkg/context"
+)
+
+func init() {
+	registerManifester(useBuildx, buildxManifester{})
+}
+
+// buildxManifester creates manifest lists with buildx imagetools, which,
+// unlike docker manifest, can set annotations on them.
+type buildxManifester struct{}
+
+// Create creates and pushes the manifest list, as imagetools works directly
+// on the registry.
+func (m buildxManifester) Create(ctx *context.Context, manifest string, images, flags []string) error {
+	args := []string{"buildx", "imagetools", "create", "--tag", manifest}
+	args = append(args, flags...)
+	args = append(args, images...)
+	if err := runCommand(ctx, ".", "docker", args...); err != nil {
+		return fmt.Errorf("failed to create %s: %w", manifest, err)
+	}
+	return nil
+}
+
+// Push does nothing, as the manifest list was already pushed by Create.
+func (m buildxManifester) Push(ctx *context.Context, manifest string, flags []string) error {
+	return nil
+}
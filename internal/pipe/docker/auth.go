@@ -0,0 +1,202 @@
+package docker
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	homedir "github.com/mitchellh/go-homedir"
+)
+
+const dockerHub = "https://index.docker.io/v1/"
+
+type dockerConfigFile struct {
+	Auths map[string]dockerAuth `json:"auths"`
+}
+
+type dockerAuth struct {
+	Auth          string `json:"auth,omitempty"`
+	IdentityToken string `json:"identitytoken,omitempty"`
+}
+
+// withAuth returns a context to run the docker commands of the given images
+// with, logged in with the credentials of the configured registries and the
+// given ones instead of the ones from the user docker config.
+// It does so by writing a docker config holding only those credentials to a
+// temporary directory, and pointing DOCKER_CONFIG to it, so different entries
+// can use different credentials at the same time.
+// If there are no credentials, the given context is returned as is.
+// The returned function removes the temporary directory.
+func withAuth(ctx *context.Context, auth config.RegistryAuth, images []string) (*context.Context, func(), error) {
+	hasAuth := auth.Username != "" || auth.Password != ""
+	if !hasAuth && len(ctx.Config.Registries) == 0 {
+		return ctx, func() {}, nil
+	}
+
+	cfg := dockerConfigFile{Auths: map[string]dockerAuth{}}
+	if err := addRegistries(ctx, cfg.Auths); err != nil {
+		return nil, nil, err
+	}
+	if hasAuth {
+		if err := addAuth(ctx, cfg.Auths, auth, images); err != nil {
+			return nil, nil, err
+		}
+	}
+	bts, err := json.Marshal(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	dir, err := os.MkdirTemp("", "goreleaserdockerconfig")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create docker config dir: %w", err)
+	}
+	cleanup := func() { _ = os.RemoveAll(dir) }
+	if err := os.WriteFile(filepath.Join(dir, "config.json"), bts, 0o600); err != nil {
+		cleanup()
+		return nil, nil, fmt.Errorf("failed to write docker config: %w", err)
+	}
+	linkPlugins(ctx, dir)
+
+	// only the env is changed, the copy is only used to run commands.
+	authCtx := *ctx
+	authCtx.Env = ctx.Env.Copy()
+	authCtx.Env["DOCKER_CONFIG"] = dir
+	return &authCtx, cleanup, nil
+}
+
+// addAuth adds the given credentials for its registry or, if not set, for
+// the registries of the given images.
+func addAuth(ctx *context.Context, auths map[string]dockerAuth, auth config.RegistryAuth, images []string) error {
+	t := tmpl.New(ctx)
+	username, err := t.Apply(auth.Username)
+	if err != nil {
+		return fmt.Errorf("failed to apply template to auth username: %w", err)
+	}
+	password, err := t.Apply(auth.Password)
+	if err != nil {
+		return fmt.Errorf("failed to apply template to auth password: %w", err)
+	}
+	registries := []string{auth.Registry}
+	if auth.Registry == "" {
+		registries = registriesOf(images)
+	}
+	for _, registry := range registries {
+		auths[registry] = dockerAuth{
+			Auth: base64.StdEncoding.EncodeToString([]byte(username + ":" + password)),
+		}
+	}
+	return nil
+}
+
+// addRegistries adds the credentials of all the configured registries.
+func addRegistries(ctx *context.Context, auths map[string]dockerAuth) error {
+	t := tmpl.New(ctx)
+	for _, registry := range ctx.Config.Registries {
+		var address, username, password, token string
+		for _, field := range []struct {
+			name   string
+			in     string
+			target *string
+		}{
+			{"address", registry.Address, &address},
+			{"username", registry.Username, &username},
+			{"password", registry.Password, &password},
+			{"token", registry.Token, &token},
+		} {
+			result, err := t.Apply(field.in)
+			if err != nil {
+				return fmt.Errorf("registry %s: failed to apply template to %s: %w", registry.Address, field.name, err)
+			}
+			*field.target = result
+		}
+		if token != "" {
+			auths[normalizeRegistry(address)] = dockerAuth{IdentityToken: token}
+			continue
+		}
+		auths[normalizeRegistry(address)] = dockerAuth{
+			Auth: base64.StdEncoding.EncodeToString([]byte(username + ":" + password)),
+		}
+	}
+	return nil
+}
+
+// normalizeRegistry returns the key docker uses for the credentials of the
+// given registry address.
+func normalizeRegistry(address string) string {
+	address = strings.TrimPrefix(address, "https://")
+	address = strings.TrimPrefix(address, "http://")
+	address = strings.TrimSuffix(address, "/")
+	switch address {
+	case "docker.io", "index.docker.io", "registry-1.docker.io", "index.docker.io/v1":
+		return dockerHub
+	}
+	return address
+}
+
+// validateRegistries checks the configured registries.
+func validateRegistries(ctx *context.Context) error {
+	for _, registry := range ctx.Config.Registries {
+		if registry.Address == "" {
+			return fmt.Errorf("registries: address is required")
+		}
+		if registry.Token != "" && (registry.Username != "" || registry.Password != "") {
+			return fmt.Errorf("registries: %s: token can't be used with username and password", registry.Address)
+		}
+		if registry.Token == "" && registry.Username == "" {
+			return fmt.Errorf("registries: %s: either username and password or token are required", registry.Address)
+		}
+	}
+	return nil
+}
+
+// linkPlugins links the cli plugins, e.g. buildx, and the buildx state of the
+// user docker config into the given directory, so they keep working.
+func linkPlugins(ctx *context.Context, dir string) {
+	original := ctx.Env["DOCKER_CONFIG"]
+	if original == "" {
+		home, err := homedir.Dir()
+		if err != nil {
+			return
+		}
+		original = filepath.Join(home, ".docker")
+	}
+	for _, name := range []string{"cli-plugins", "buildx"} {
+		if _, err := os.Stat(filepath.Join(original, name)); err == nil {
+			_ = os.Symlink(filepath.Join(original, name), filepath.Join(dir, name))
+		}
+	}
+}
+
+// registriesOf returns the registries of the given images, e.g. ghcr.io for
+// ghcr.io/goreleaser/goreleaser.
+func registriesOf(images []string) []string {
+	seen := map[string]bool{}
+	var result []string
+	for _, image := range images {
+		registry := registryOf(image)
+		if !seen[registry] {
+			seen[registry] = true
+			result = append(result, registry)
+		}
+	}
+	return result
+}
+
+func registryOf(image string) string {
+	i := strings.IndexRune(image, '/')
+	if i == -1 {
+		return dockerHub
+	}
+	host := image[:i]
+	if host != "localhost" && !strings.ContainsAny(host, ".:") {
+		return dockerHub
+	}
+	return host
+}
@@ -0,0 +1,151 @@
+package docker
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistryOf(t *testing.T) {
+	for image, registry := range map[string]string{
+		"goreleaser":                     dockerHub,
+		"goreleaser/goreleaser:latest":   dockerHub,
+		"ghcr.io/goreleaser/goreleaser":  "ghcr.io",
+		"localhost:5000/goreleaser/test": "localhost:5000",
+		"localhost/goreleaser":           "localhost",
+	} {
+		t.Run(image, func(t *testing.T) {
+			require.Equal(t, registry, registryOf(image))
+		})
+	}
+}
+
+func TestWithAuthNoCredentials(t *testing.T) {
+	ctx := context.New(config.Project{})
+	authCtx, cleanup, err := withAuth(ctx, config.RegistryAuth{}, []string{"ghcr.io/foo/bar"})
+	require.NoError(t, err)
+	defer cleanup()
+	require.Same(t, ctx, authCtx)
+}
+
+func TestWithAuth(t *testing.T) {
+	ctx := context.New(config.Project{})
+	ctx.Env["DOCKER_CONFIG"] = t.TempDir()
+	ctx.Env["REGISTRY_PASSWORD"] = "secret"
+	authCtx, cleanup, err := withAuth(ctx, config.RegistryAuth{
+		Username: "user",
+		Password: "{{ .Env.REGISTRY_PASSWORD }}",
+	}, []string{"ghcr.io/foo/bar:v1", "ghcr.io/foo/bar:latest", "foo/bar"})
+	require.NoError(t, err)
+
+	dir := authCtx.Env["DOCKER_CONFIG"]
+	require.NotEqual(t, ctx.Env["DOCKER_CONFIG"], dir)
+	bts, err := os.ReadFile(filepath.Join(dir, "config.json"))
+	require.NoError(t, err)
+	var cfg dockerConfigFile
+	require.NoError(t, json.Unmarshal(bts, &cfg))
+	require.Equal(t, map[string]dockerAuth{
+		"ghcr.io": {Auth: "dXNlcjpzZWNyZXQ="},
+		dockerHub: {Auth: "dXNlcjpzZWNyZXQ="},
+	}, cfg.Auths)
+
+	cleanup()
+	require.NoDirExists(t, dir)
+}
+
+func TestWithAuthRegistry(t *testing.T) {
+	ctx := context.New(config.Project{})
+	ctx.Env["DOCKER_CONFIG"] = t.TempDir()
+	authCtx, cleanup, err := withAuth(ctx, config.RegistryAuth{
+		Registry: "registry.example.com",
+		Username: "user",
+		Password: "pass",
+	}, []string{"ghcr.io/foo/bar"})
+	require.NoError(t, err)
+	defer cleanup()
+
+	bts, err := os.ReadFile(filepath.Join(authCtx.Env["DOCKER_CONFIG"], "config.json"))
+	require.NoError(t, err)
+	require.JSONEq(t, `{"auths":{"registry.example.com":{"auth":"dXNlcjpwYXNz"}}}`, string(bts))
+}
+
+func TestWithAuthInvalidTemplate(t *testing.T) {
+	ctx := context.New(config.Project{})
+	_, _, err := withAuth(ctx, config.RegistryAuth{
+		Username: "user",
+		Password: "{{ .Nope }",
+	}, []string{"foo/bar"})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to apply template to auth password")
+}
+
+func TestWithAuthRegistries(t *testing.T) {
+	ctx := context.New(config.Project{
+		Registries: []config.Registry{
+			{Address: "ghcr.io", Username: "user", Password: "{{ .Env.GHCR_TOKEN }}"},
+			{Address: "docker.io", Username: "user", Password: "pass"},
+			{Address: "https://myregistry.azurecr.io/", Token: "{{ .Env.ACR_TOKEN }}"},
+		},
+	})
+	ctx.Env["DOCKER_CONFIG"] = t.TempDir()
+	ctx.Env["GHCR_TOKEN"] = "secret"
+	ctx.Env["ACR_TOKEN"] = "token"
+	authCtx, cleanup, err := withAuth(ctx, config.RegistryAuth{
+		Registry: "ghcr.io",
+		Username: "other",
+		Password: "pass",
+	}, []string{"ghcr.io/foo/bar"})
+	require.NoError(t, err)
+	defer cleanup()
+
+	bts, err := os.ReadFile(filepath.Join(authCtx.Env["DOCKER_CONFIG"], "config.json"))
+	require.NoError(t, err)
+	var cfg dockerConfigFile
+	require.NoError(t, json.Unmarshal(bts, &cfg))
+	require.Equal(t, map[string]dockerAuth{
+		"ghcr.io":               {Auth: "b3RoZXI6cGFzcw=="},
+		dockerHub:               {Auth: "dXNlcjpwYXNz"},
+		"myregistry.azurecr.io": {IdentityToken: "token"},
+	}, cfg.Auths)
+}
+
+func TestWithAuthRegistriesInvalidTemplate(t *testing.T) {
+	ctx := context.New(config.Project{
+		Registries: []config.Registry{
+			{Address: "ghcr.io", Username: "user", Password: "{{ .Nope }"},
+		},
+	})
+	_, _, err := withAuth(ctx, config.RegistryAuth{}, []string{"ghcr.io/foo/bar"})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "registry ghcr.io: failed to apply template to password")
+}
+
+func TestValidateRegistries(t *testing.T) {
+	for name, tt := range map[string]struct {
+		registry config.Registry
+		err      string
+	}{
+		"valid":          {config.Registry{Address: "ghcr.io", Username: "user", Password: "pass"}, ""},
+		"valid token":    {config.Registry{Address: "ghcr.io", Token: "token"}, ""},
+		"no address":     {config.Registry{Username: "user", Password: "pass"}, "registries: address is required"},
+		"no credentials": {config.Registry{Address: "ghcr.io"}, "registries: ghcr.io: either username and password or token are required"},
+		"both":           {config.Registry{Address: "ghcr.io", Username: "user", Token: "token"}, "registries: ghcr.io: token can't be used with username and password"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.New(config.Project{
+				Registries: []config.Registry{tt.registry},
+			})
+			err := validateRegistries(ctx)
+			if tt.err == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.EqualError(t, err, tt.err)
+		})
+	}
+}
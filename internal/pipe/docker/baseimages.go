@@ -0,0 +1,191 @@
+package docker
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+)
+
+var baseImagesLock sync.Mutex
+
+// validateBaseImages checks the base images config of the given docker.
+func validateBaseImages(docker config.Docker) error {
+	verify := docker.BaseImages.Verify
+	if !docker.BaseImages.Pin && verify == (config.CosignVerify{}) {
+		return nil
+	}
+	if docker.Use == useBuildPacks {
+		return fmt.Errorf("docker: base_images can't be used with use: %s", useBuildPacks)
+	}
+	if verify == (config.CosignVerify{}) {
+		return nil
+	}
+	if verify.Key != "" && (verify.CertificateIdentity != "" || verify.CertificateOIDCIssuer != "") {
+		return fmt.Errorf("docker: base_images.verify: key can't be used with certificate_identity and certificate_oidc_issuer")
+	}
+	if verify.Key == "" && (verify.CertificateIdentity == "" || verify.CertificateOIDCIssuer == "") {
+		return fmt.Errorf("docker: base_images.verify: either key or certificate_identity and certificate_oidc_issuer are required")
+	}
+	return nil
+}
+
+// pinBaseImages resolves the base images of the given Dockerfile to their
+// digests, verifies their signatures if configured, and rewrites the
+// Dockerfile to use the pinned images.
+// Verifying implies pinning, so the verified image is the one being built.
+// It returns the pinned images.
+func pinBaseImages(ctx *context.Context, docker config.Docker, dockerfile string) ([]context.BaseImage, error) {
+	verify := docker.BaseImages.Verify
+	if !docker.BaseImages.Pin && verify == (config.CosignVerify{}) {
+		return nil, nil
+	}
+	verifyArgs, err := cosignVerifyArgs(ctx, verify)
+	if err != nil {
+		return nil, err
+	}
+
+	bts, err := os.ReadFile(dockerfile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read dockerfile: %w", err)
+	}
+	var pinned []context.BaseImage
+	var lines []string
+	digests := map[string]string{}
+	stages := map[string]bool{}
+	scanner := bufio.NewScanner(strings.NewReader(string(bts)))
+	for scanner.Scan() {
+		line := scanner.Text()
+		image, stage := parseFrom(line)
+		if image != "" && pinnable(image, stages) {
+			digest, ok := digests[image]
+			if !ok {
+				digest, err = resolveDigest(ctx, image)
+				if err != nil {
+					return nil, err
+				}
+				if verifyArgs != nil {
+					if err := verifyImage(ctx, image+"@"+digest, verifyArgs); err != nil {
+						return nil, err
+					}
+				}
+				digests[image] = digest
+				pinned = append(pinned, context.BaseImage{Image: image, Digest: digest})
+			}
+			line = strings.Replace(line, image, image+"@"+digest, 1)
+		}
+		if stage != "" {
+			stages[stage] = true
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read dockerfile: %w", err)
+	}
+	if err := os.WriteFile(dockerfile, []byte(strings.Join(lines, "\n")+"\n"), 0o644); err != nil {
+		return nil, fmt.Errorf("failed to write dockerfile: %w", err)
+	}
+	return pinned, nil
+}
+
+// parseFrom returns the image and stage name of a FROM instruction, if the
+// given line is one.
+func parseFrom(line string) (string, string) {
+	fields := strings.Fields(line)
+	if len(fields) < 2 || !strings.EqualFold(fields[0], "FROM") {
+		return "", ""
+	}
+	fields = fields[1:]
+	for len(fields) > 0 && strings.HasPrefix(fields[0], "--") {
+		fields = fields[1:]
+	}
+	if len(fields) == 0 {
+		return "", ""
+	}
+	image := fields[0]
+	if len(fields) == 3 && strings.EqualFold(fields[1], "AS") {
+		return image, strings.ToLower(fields[2])
+	}
+	return image, ""
+}
+
+// pinnable returns true if the given image is a remote image that is not
+// pinned yet, i.e. not scratch, a previous stage, an image with build args
+// or an image with a digest.
+func pinnable(image string, stages map[string]bool) bool {
+	return image != "scratch" &&
+		!stages[strings.ToLower(image)] &&
+		!strings.Contains(image, "$") &&
+		!strings.Contains(image, "@")
+}
+
+func resolveDigest(ctx *context.Context, image string) (string, error) {
+	log.FromContext(ctx).WithField("image", image).Info("resolving base image digest")
+	digest, err := commandOutput(ctx, "", "docker", "buildx", "imagetools", "inspect", "--format", "{{ .Manifest.Digest }}", image)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve digest of base image %s: %w", image, err)
+	}
+	if !strings.HasPrefix(digest, "sha256:") {
+		return "", fmt.Errorf("failed to resolve digest of base image %s: unexpected digest: %q", image, digest)
+	}
+	return digest, nil
+}
+
+func cosignVerifyArgs(ctx *context.Context, verify config.CosignVerify) ([]string, error) {
+	if verify == (config.CosignVerify{}) {
+		return nil, nil
+	}
+	t := tmpl.New(ctx)
+	if verify.Key != "" {
+		key, err := t.Apply(verify.Key)
+		if err != nil {
+			return nil, fmt.Errorf("docker: base_images.verify: failed to apply template to key: %w", err)
+		}
+		return []string{"--key", key}, nil
+	}
+	identity, err := t.Apply(verify.CertificateIdentity)
+	if err != nil {
+		return nil, fmt.Errorf("docker: base_images.verify: failed to apply template to certificate_identity: %w", err)
+	}
+	issuer, err := t.Apply(verify.CertificateOIDCIssuer)
+	if err != nil {
+		return nil, fmt.Errorf("docker: base_images.verify: failed to apply template to certificate_oidc_issuer: %w", err)
+	}
+	return []string{"--certificate-identity", identity, "--certificate-oidc-issuer", issuer}, nil
+}
+
+func verifyImage(ctx *context.Context, image string, args []string) error {
+	log.FromContext(ctx).WithField("image", image).Info("verifying base image signature")
+	args = append(append([]string{"verify"}, args...), image)
+	if err := runCommand(ctx, "", "cosign", args...); err != nil {
+		return fmt.Errorf("failed to verify signature of base image %s: %w", image, err)
+	}
+	return nil
+}
+
+// recordBaseImages adds the given pinned images to the context, so they end
+// up in the release metadata.
+func recordBaseImages(ctx *context.Context, images []context.BaseImage) {
+	baseImagesLock.Lock()
+	defer baseImagesLock.Unlock()
+	for _, image := range images {
+		if !hasBaseImage(ctx.BaseImages, image.Image) {
+			ctx.BaseImages = append(ctx.BaseImages, image)
+		}
+	}
+}
+
+func hasBaseImage(images []context.BaseImage, image string) bool {
+	for _, base := range images {
+		if base.Image == image {
+			return true
+		}
+	}
+	return false
+}
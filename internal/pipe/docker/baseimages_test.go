@@ -0,0 +1,180 @@
+package docker
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+const fakeDigest = "sha256:e2e16842c9b54d985bf1ef9242a313f36b856181f188de21313820e177002501"
+
+// fakeBaseImageTools puts fake docker and cosign binaries in the PATH, the
+// docker one resolving all images to fakeDigest, and returns the path of
+// the file the calls are logged to.
+func fakeBaseImageTools(tb testing.TB, cosignExit int) string {
+	tb.Helper()
+	dir := tb.TempDir()
+	calls := filepath.Join(dir, "calls.log")
+	docker := `#!/bin/sh
+echo docker "$@" >> ` + calls + `
+echo ` + fakeDigest + `
+`
+	cosign := `#!/bin/sh
+echo cosign "$@" >> ` + calls + `
+exit ` + strconv.Itoa(cosignExit) + `
+`
+	require.NoError(tb, os.WriteFile(filepath.Join(dir, "docker"), []byte(docker), 0o755))
+	require.NoError(tb, os.WriteFile(filepath.Join(dir, "cosign"), []byte(cosign), 0o755))
+	tb.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return calls
+}
+
+func writeDockerfile(tb testing.TB, content string) string {
+	tb.Helper()
+	path := filepath.Join(tb.TempDir(), "Dockerfile")
+	require.NoError(tb, os.WriteFile(path, []byte(content), 0o644))
+	return path
+}
+
+func TestPinBaseImages(t *testing.T) {
+	calls := fakeBaseImageTools(t, 0)
+	dockerfile := writeDockerfile(t, `ARG BASE=alpine
+FROM --platform=$BUILDPLATFORM golang:1.19 AS build
+RUN go build ./...
+FROM build as test
+FROM $BASE
+FROM scratch
+FROM alpine:3.16@sha256:abc
+FROM golang:1.19
+from gcr.io/distroless/static:nonroot
+COPY --from=build /app /app
+`)
+	ctx := context.New(config.Project{})
+	pinned, err := pinBaseImages(ctx, config.Docker{
+		BaseImages: config.DockerBaseImages{Pin: true},
+	}, dockerfile)
+	require.NoError(t, err)
+	require.Equal(t, []context.BaseImage{
+		{Image: "golang:1.19", Digest: fakeDigest},
+		{Image: "gcr.io/distroless/static:nonroot", Digest: fakeDigest},
+	}, pinned)
+
+	bts, err := os.ReadFile(dockerfile)
+	require.NoError(t, err)
+	require.Equal(t, `ARG BASE=alpine
+FROM --platform=$BUILDPLATFORM golang:1.19@`+fakeDigest+` AS build
+RUN go build ./...
+FROM build as test
+FROM $BASE
+FROM scratch
+FROM alpine:3.16@sha256:abc
+FROM golang:1.19@`+fakeDigest+`
+from gcr.io/distroless/static:nonroot@`+fakeDigest+`
+COPY --from=build /app /app
+`, string(bts))
+
+	require.Equal(t, []string{
+		"docker buildx imagetools inspect --format {{ .Manifest.Digest }} golang:1.19",
+		"docker buildx imagetools inspect --format {{ .Manifest.Digest }} gcr.io/distroless/static:nonroot",
+	}, dockerCalls(t, calls))
+}
+
+func TestPinBaseImagesDisabled(t *testing.T) {
+	dockerfile := writeDockerfile(t, "FROM alpine\n")
+	pinned, err := pinBaseImages(context.New(config.Project{}), config.Docker{}, dockerfile)
+	require.NoError(t, err)
+	require.Empty(t, pinned)
+	bts, err := os.ReadFile(dockerfile)
+	require.NoError(t, err)
+	require.Equal(t, "FROM alpine\n", string(bts))
+}
+
+func TestPinBaseImagesVerify(t *testing.T) {
+	t.Run("key", func(t *testing.T) {
+		calls := fakeBaseImageTools(t, 0)
+		ctx := context.New(config.Project{})
+		ctx.Env["COSIGN_KEY"] = "cosign.pub"
+		_, err := pinBaseImages(ctx, config.Docker{
+			BaseImages: config.DockerBaseImages{
+				Verify: config.CosignVerify{Key: "{{ .Env.COSIGN_KEY }}"},
+			},
+		}, writeDockerfile(t, "FROM alpine\n"))
+		require.NoError(t, err)
+		require.Equal(t, []string{
+			"docker buildx imagetools inspect --format {{ .Manifest.Digest }} alpine",
+			"cosign verify --key cosign.pub alpine@" + fakeDigest,
+		}, dockerCalls(t, calls))
+	})
+
+	t.Run("keyless", func(t *testing.T) {
+		calls := fakeBaseImageTools(t, 0)
+		_, err := pinBaseImages(context.New(config.Project{}), config.Docker{
+			BaseImages: config.DockerBaseImages{
+				Verify: config.CosignVerify{
+					CertificateIdentity:   "https://github.com/foo/bar/.github/workflows/release.yml@refs/heads/main",
+					CertificateOIDCIssuer: "https://token.actions.githubusercontent.com",
+				},
+			},
+		}, writeDockerfile(t, "FROM alpine\n"))
+		require.NoError(t, err)
+		require.Equal(t, "cosign verify --certificate-identity https://github.com/foo/bar/.github/workflows/release.yml@refs/heads/main --certificate-oidc-issuer https://token.actions.githubusercontent.com alpine@"+fakeDigest, dockerCalls(t, calls)[1])
+	})
+
+	t.Run("fails", func(t *testing.T) {
+		fakeBaseImageTools(t, 1)
+		_, err := pinBaseImages(context.New(config.Project{}), config.Docker{
+			BaseImages: config.DockerBaseImages{
+				Verify: config.CosignVerify{Key: "cosign.pub"},
+			},
+		}, writeDockerfile(t, "FROM alpine\n"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to verify signature of base image alpine@"+fakeDigest)
+	})
+}
+
+func TestRecordBaseImages(t *testing.T) {
+	ctx := context.New(config.Project{})
+	recordBaseImages(ctx, []context.BaseImage{{Image: "alpine", Digest: fakeDigest}})
+	recordBaseImages(ctx, []context.BaseImage{{Image: "alpine", Digest: fakeDigest}, {Image: "golang", Digest: fakeDigest}})
+	require.Equal(t, []context.BaseImage{
+		{Image: "alpine", Digest: fakeDigest},
+		{Image: "golang", Digest: fakeDigest},
+	}, ctx.BaseImages)
+}
+
+func TestValidateBaseImages(t *testing.T) {
+	for name, tt := range map[string]struct {
+		docker config.Docker
+		err    string
+	}{
+		"disabled": {config.Docker{}, ""},
+		"pin":      {config.Docker{BaseImages: config.DockerBaseImages{Pin: true}}, ""},
+		"key":      {config.Docker{BaseImages: config.DockerBaseImages{Verify: config.CosignVerify{Key: "cosign.pub"}}}, ""},
+		"buildpacks": {
+			config.Docker{Use: useBuildPacks, BaseImages: config.DockerBaseImages{Pin: true}},
+			"docker: base_images can't be used with use: buildpacks",
+		},
+		"key and identity": {
+			config.Docker{BaseImages: config.DockerBaseImages{Verify: config.CosignVerify{Key: "cosign.pub", CertificateIdentity: "foo"}}},
+			"docker: base_images.verify: key can't be used with certificate_identity and certificate_oidc_issuer",
+		},
+		"identity without issuer": {
+			config.Docker{BaseImages: config.DockerBaseImages{Verify: config.CosignVerify{CertificateIdentity: "foo"}}},
+			"docker: base_images.verify: either key or certificate_identity and certificate_oidc_issuer are required",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := validateBaseImages(tt.docker)
+			if tt.err == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.EqualError(t, err, tt.err)
+		})
+	}
+}
@@ -35,6 +35,9 @@ func (Pipe) Skip(ctx *context.Context) bool { return len(ctx.Config.Dockers) ==
 
 // Default sets the pipe defaults.
 func (Pipe) Default(ctx *context.Context) error {
+	if err := validateRegistries(ctx); err != nil {
+		return err
+	}
 	ids := ids.New("dockers")
 	for i := range ctx.Config.Dockers {
 		docker := &ctx.Config.Dockers[i]
@@ -42,17 +45,24 @@ func (Pipe) Default(ctx *context.Context) error {
 		if docker.ID != "" {
 			ids.Inc(docker.ID)
 		}
-		if docker.Goos == "" {
-			docker.Goos = "linux"
-		}
-		if docker.Goarch == "" {
-			docker.Goarch = "amd64"
+		// multi-platform images are built for all their platforms at once.
+		if len(docker.Platforms) == 0 {
+			if docker.Goos == "" {
+				docker.Goos = "linux"
+			}
+			if docker.Goarch == "" {
+				docker.Goarch = "amd64"
+			}
+			if docker.Goarch == "arm" && docker.Goarm == "" {
+				docker.Goarm = "6"
+			}
 		}
 		if docker.Dockerfile == "" {
 			docker.Dockerfile = "Dockerfile"
 		}
+		setRetryDefaults(&docker.Retry)
 		if docker.Buildx {
-			deprecate.Notice(ctx, "docker.use_buildx")
+			deprecate.NoticeAt(ctx, "docker.use_buildx", fmt.Sprintf("dockers[%d].use_buildx", i), "use: buildx")
 			if docker.Use == "" {
 				docker.Use = useBuildx
 			}
@@ -63,6 +73,31 @@ func (Pipe) Default(ctx *context.Context) error {
 		if err := validateImager(docker.Use); err != nil {
 			return err
 		}
+		if len(docker.Platforms) > 0 {
+			if err := validatePlatforms(*docker); err != nil {
+				return err
+			}
+		}
+		if len(docker.Annotations) > 0 && len(docker.Platforms) == 0 {
+			return fmt.Errorf("docker: annotations can only be used with platforms, as they are lost when images are loaded into the docker daemon")
+		}
+		if err := validateBaseImages(*docker); err != nil {
+			return err
+		}
+		if docker.Use == useBuildPacks && (len(docker.Secrets) > 0 || len(docker.SSH) > 0) {
+			return fmt.Errorf("docker: secrets and ssh can't be used with use: %s", useBuildPacks)
+		}
+		if docker.Save && docker.PromoteFrom != "" {
+			return fmt.Errorf("docker: save can't be used with promote_from, as promoted images are not built")
+		}
+		if docker.Save {
+			if docker.SaveFormat == "" {
+				docker.SaveFormat = saveFormatDocker
+			}
+			if err := validateSaveFormat(docker.SaveFormat); err != nil {
+				return err
+			}
+		}
 		for _, f := range docker.Files {
 			if f == "." || strings.HasPrefix(f, ctx.Config.Dist) {
 				return fmt.Errorf("invalid docker.files: can't be . or inside dist folder: %s", f)
@@ -103,21 +138,27 @@ func (Pipe) Run(ctx *context.Context) error {
 	for _, docker := range ctx.Config.Dockers {
 		docker := docker
 		g.Go(func() error {
-			log.WithField("docker", docker).Debug("looking for artifacts matching")
+			if docker.PromoteFrom != "" {
+				return promote(ctx, docker)
+			}
+			log.FromContext(ctx).WithField("docker", docker).Debug("looking for artifacts matching")
 			filters := []artifact.Filter{
 				artifact.ByGoos(docker.Goos),
 				artifact.ByGoarch(docker.Goarch),
 				artifact.ByGoarm(docker.Goarm),
-				artifact.Or(
-					artifact.ByType(artifact.Binary),
-					artifact.ByType(artifact.LinuxPackage),
-				),
 			}
+			if len(docker.Platforms) > 0 {
+				filters = []artifact.Filter{platformsFilter(docker.Platforms)}
+			}
+			filters = append(filters, artifact.Or(
+				artifact.ByType(artifact.Binary),
+				artifact.ByType(artifact.LinuxPackage),
+			))
 			if len(docker.IDs) > 0 {
 				filters = append(filters, artifact.ByIDs(docker.IDs...))
 			}
 			artifacts := ctx.Artifacts.Filter(artifact.And(filters...))
-			log.WithField("artifacts", artifacts.Paths()).Debug("found artifacts")
+			log.FromContext(ctx).WithField("artifacts", artifacts.Paths()).Debug("found artifacts")
 			return process(ctx, docker, artifacts.List())
 		})
 	}
@@ -139,7 +180,7 @@ func process(ctx *context.Context, docker config.Docker, artifacts []*artifact.A
 		return pipe.Skip("no image templates found")
 	}
 
-	log := log.WithField("image", images[0])
+	log := log.FromContext(ctx).WithField("image", images[0])
 	log.Debug("tempdir: " + tmp)
 
 	if docker.Use != useBuildPacks {
@@ -160,7 +201,14 @@ func process(ctx *context.Context, docker config.Docker, artifacts []*artifact.A
 		}
 	}
 	for _, art := range artifacts {
-		if err := gio.Copy(art.Path, filepath.Join(tmp, filepath.Base(art.Path))); err != nil {
+		dst := filepath.Join(tmp, filepath.Base(art.Path))
+		if len(docker.Platforms) > 0 {
+			dst = filepath.Join(tmp, platformDir(art), filepath.Base(art.Path))
+			if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+				return fmt.Errorf("failed to copy artifact: %w", err)
+			}
+		}
+		if err := gio.Copy(art.Path, dst); err != nil {
 			return fmt.Errorf("failed to copy artifact: %w", err)
 		}
 	}
@@ -169,23 +217,95 @@ func process(ctx *context.Context, docker config.Docker, artifacts []*artifact.A
 	if err != nil {
 		return err
 	}
+	secrets, err := secretFlags(ctx, docker)
+	if err != nil {
+		return err
+	}
+	buildFlags = append(buildFlags, secrets...)
+	var indexFlags []string
+	if len(docker.Platforms) > 0 {
+		buildFlags = append(buildFlags, multiPlatformFlags(docker)...)
+		annotations, err := annotationFlags(ctx, docker.Annotations, "manifest")
+		if err != nil {
+			return err
+		}
+		buildFlags = append(buildFlags, annotations...)
+		// the index is only created when the manifest list is assembled.
+		indexFlags, err = annotationFlags(ctx, docker.Annotations, "index")
+		if err != nil {
+			return err
+		}
+	}
+	if docker.Use == useBuildx && len(docker.Platforms) == 0 && !hasPlatformFlag(buildFlags) {
+		buildFlags = append(buildFlags, "--platform="+platform(docker))
+	}
+
+	authCtx, cleanup, err := withAuth(ctx, docker.Auth, images)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	if docker.Use != useBuildPacks {
+		pinned, err := pinBaseImages(authCtx, docker, filepath.Join(tmp, "Dockerfile"))
+		if err != nil {
+			return err
+		}
+		recordBaseImages(ctx, pinned)
+	}
 
 	log.Info("building docker image")
-	if err := imagers[docker.Use].Build(ctx, tmp, images, buildFlags); err != nil {
+	if len(docker.Platforms) > 0 {
+		builds, err := buildMultiPlatform(authCtx, docker, tmp, images, buildFlags)
+		if err != nil {
+			return err
+		}
+		recordPlatformBuilds(ctx, builds)
+	} else if err := imagers[docker.Use].Build(authCtx, tmp, images, buildFlags); err != nil {
 		return err
 	}
 
+	if docker.Save {
+		if err := save(ctx, docker, images); err != nil {
+			return err
+		}
+	}
+
+	if err := skipPush(ctx, docker); err != nil {
+		return err
+	}
+	var extra map[string]interface{}
+	if len(docker.Platforms) > 0 {
+		extra = map[string]interface{}{
+			dockerContextExtra:    tmp,
+			dockerBuildFlagsExtra: buildFlags,
+			dockerIndexFlagsExtra: indexFlags,
+		}
+	}
+	addPublishable(ctx, docker, images, extra)
+	return nil
+}
+
+// skipPush returns a skip error if the images of the given config should not
+// be pushed.
+func skipPush(ctx *context.Context, docker config.Docker) error {
 	if strings.TrimSpace(docker.SkipPush) == "true" {
 		return pipe.Skip("docker.skip_push is set")
 	}
-	if ctx.SkipPublish {
+	if ctx.SkipPublish && ctx.LocalRegistry.Address == "" {
 		return pipe.ErrSkipPublishEnabled
 	}
 	if strings.TrimSpace(docker.SkipPush) == "auto" && ctx.Semver.Prerelease != "" {
 		return pipe.Skip("prerelease detected with 'auto' push, skipping docker publish")
 	}
+	return nil
+}
+
+// addPublishable adds the given images as docker images to be pushed, with
+// the given extra fields.
+func addPublishable(ctx *context.Context, docker config.Docker, images []string, extra map[string]interface{}) {
 	for _, img := range images {
-		ctx.Artifacts.Add(&artifact.Artifact{
+		art := &artifact.Artifact{
 			Type:   artifact.PublishableDockerImage,
 			Name:   img,
 			Path:   img,
@@ -195,9 +315,12 @@ func process(ctx *context.Context, docker config.Docker, artifacts []*artifact.A
 			Extra: map[string]interface{}{
 				dockerConfigExtra: docker,
 			},
-		})
+		}
+		for k, v := range extra {
+			art.Extra[k] = v
+		}
+		ctx.Artifacts.Add(art)
 	}
-	return nil
 }
 
 func processImageTemplates(ctx *context.Context, docker config.Docker) ([]string, error) {
@@ -212,7 +335,7 @@ func processImageTemplates(ctx *context.Context, docker config.Docker) ([]string
 			continue
 		}
 
-		images = append(images, image)
+		images = append(images, withLocalRegistry(ctx, image))
 	}
 
 	return images, nil
@@ -231,10 +354,45 @@ func processBuildFlagTemplates(ctx *context.Context, docker config.Docker) ([]st
 	return buildFlags, nil
 }
 
+func hasPlatformFlag(flags []string) bool {
+	for _, flag := range flags {
+		if flag == "--platform" || strings.HasPrefix(flag, "--platform=") {
+			return true
+		}
+	}
+	return false
+}
+
+// platform returns the docker platform of the images of the given config,
+// e.g. linux/arm/v7.
+func platform(docker config.Docker) string {
+	result := docker.Goos + "/" + docker.Goarch
+	if docker.Goarch == "arm" && docker.Goarm != "" {
+		result += "/v" + docker.Goarm
+	}
+	return result
+}
+
 func dockerPush(ctx *context.Context, image *artifact.Artifact) error {
-	log.WithField("image", image.Name).Info("pushing")
+	log.FromContext(ctx).WithField("image", image.Name).Info("pushing")
 	docker := image.Extra[dockerConfigExtra].(config.Docker)
-	if err := imagers[docker.Use].Push(ctx, image.Name, docker.PushFlags); err != nil {
+	authCtx, cleanup, err := withAuth(ctx, docker.Auth, []string{image.Name})
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	_, promoted := image.Extra[promoteFromExtra]
+	if len(docker.Platforms) > 0 && !promoted {
+		// platforms are retried on their own.
+		if err := pushMultiPlatform(authCtx, image); err != nil {
+			return err
+		}
+	} else if err := withRetry(ctx, docker.Retry, "push "+image.Name, func() error {
+		if promoted {
+			return pushPromoted(authCtx, image)
+		}
+		return imagers[docker.Use].Push(authCtx, image.Name, docker.PushFlags)
+	}); err != nil {
 		return err
 	}
 	art := &artifact.Artifact{
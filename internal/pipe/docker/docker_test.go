@@ -1229,6 +1229,68 @@ func TestNoDockerWithoutImageName(t *testing.T) {
 	})))
 }
 
+func TestDefaultGoarm(t *testing.T) {
+	ctx := context.New(config.Project{
+		Dockers: []config.Docker{
+			{Goarch: "arm"},
+			{Goarch: "arm", Goarm: "7"},
+		},
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.Equal(t, "6", ctx.Config.Dockers[0].Goarm)
+	require.Equal(t, "7", ctx.Config.Dockers[1].Goarm)
+}
+
+func TestPlatform(t *testing.T) {
+	for expected, docker := range map[string]config.Docker{
+		"linux/amd64":  {Goos: "linux", Goarch: "amd64"},
+		"linux/arm64":  {Goos: "linux", Goarch: "arm64"},
+		"linux/arm/v6": {Goos: "linux", Goarch: "arm", Goarm: "6"},
+		"linux/arm/v7": {Goos: "linux", Goarch: "arm", Goarm: "7"},
+	} {
+		require.Equal(t, expected, platform(docker))
+	}
+}
+
+func TestHasPlatformFlag(t *testing.T) {
+	require.True(t, hasPlatformFlag([]string{"--label=foo", "--platform=linux/arm64"}))
+	require.True(t, hasPlatformFlag([]string{"--platform", "linux/arm64"}))
+	require.False(t, hasPlatformFlag([]string{"--label=platform"}))
+	require.False(t, hasPlatformFlag(nil))
+}
+
+func TestBuildxPlatform(t *testing.T) {
+	calls := fakeDocker(t)
+	ctx := context.New(config.Project{
+		Dist: t.TempDir(),
+		Dockers: []config.Docker{
+			{
+				Goarch:         "arm",
+				Goarm:          "7",
+				Use:            useBuildx,
+				Dockerfile:     "testdata/Dockerfile.dummy",
+				ImageTemplates: []string{"foo:arm7"},
+				SkipPush:       "true",
+			},
+			{
+				Goarch:             "arm64",
+				Use:                useBuildx,
+				Dockerfile:         "testdata/Dockerfile.dummy",
+				ImageTemplates:     []string{"foo:arm64"},
+				BuildFlagTemplates: []string{"--platform=linux/arm64/v8"},
+				SkipPush:           "true",
+			},
+		},
+	})
+	ctx.Parallelism = 1
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.True(t, pipe.IsSkip(Pipe{}.Run(ctx)))
+	require.ElementsMatch(t, []string{
+		"buildx build . --load -t foo:arm7 --platform=linux/arm/v7",
+		"buildx build . --load -t foo:arm64 --platform=linux/arm64/v8",
+	}, dockerCalls(t, calls))
+}
+
 func TestDefault(t *testing.T) {
 	ctx := &context.Context{
 		Config: config.Project{
@@ -1257,6 +1319,7 @@ func TestDefault(t *testing.T) {
 	require.Equal(t, useDocker, docker.Use)
 	docker = ctx.Config.Dockers[1]
 	require.Equal(t, useBuildx, docker.Use)
+	require.Empty(t, docker.Goarm)
 
 	require.NoError(t, ManifestPipe{}.Default(ctx))
 	require.Len(t, ctx.Config.DockerManifests, 2)
@@ -0,0 +1,20 @@
+package docker
+
+import (
+	"strings"
+
+	"github.com/goreleaser/goreleaser/pkg/context"
+)
+
+// withLocalRegistry returns the given image in the local registry, if one is
+// being used, e.g. localhost:5000/goreleaser/goreleaser:latest for
+// ghcr.io/goreleaser/goreleaser:latest.
+func withLocalRegistry(ctx *context.Context, image string) string {
+	if ctx.LocalRegistry.Address == "" {
+		return image
+	}
+	if registry := registryOf(image); registry != dockerHub {
+		image = strings.TrimPrefix(image, registry+"/")
+	}
+	return ctx.LocalRegistry.Address + "/" + image
+}
@@ -0,0 +1,39 @@
+package docker
+
+import (
+	"testing"
+
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithLocalRegistry(t *testing.T) {
+	t.Run("disabled", func(t *testing.T) {
+		ctx := context.New(config.Project{})
+		require.Equal(t, "ghcr.io/foo/bar:v1", withLocalRegistry(ctx, "ghcr.io/foo/bar:v1"))
+	})
+
+	ctx := context.New(config.Project{})
+	ctx.LocalRegistry.Address = "localhost:5000"
+	for image, expected := range map[string]string{
+		"ghcr.io/foo/bar:v1":             "localhost:5000/foo/bar:v1",
+		"docker.io/foo/bar:v1":           "localhost:5000/foo/bar:v1",
+		"foo/bar:v1":                     "localhost:5000/foo/bar:v1",
+		"bar:v1":                         "localhost:5000/bar:v1",
+		"registry.local:8080/foo/bar:v1": "localhost:5000/foo/bar:v1",
+	} {
+		t.Run(image, func(t *testing.T) {
+			require.Equal(t, expected, withLocalRegistry(ctx, image))
+		})
+	}
+}
+
+func TestSkipPushLocalRegistry(t *testing.T) {
+	ctx := context.New(config.Project{})
+	ctx.SkipPublish = true
+	require.Error(t, skipPush(ctx, config.Docker{}))
+	ctx.LocalRegistry.Address = "localhost:5000"
+	require.NoError(t, skipPush(ctx, config.Docker{}))
+	require.Error(t, skipPush(ctx, config.Docker{SkipPush: "true"}))
+}
@@ -33,9 +33,13 @@ func (ManifestPipe) Default(ctx *context.Context) error {
 		if manifest.Use == "" {
 			manifest.Use = useDocker
 		}
+		setRetryDefaults(&manifest.Retry)
 		if err := validateManifester(manifest.Use); err != nil {
 			return err
 		}
+		if len(manifest.Annotations) > 0 && manifest.Use != useBuildx {
+			return fmt.Errorf("docker manifest: annotations can only be used with use: %s", useBuildx)
+		}
 	}
 	return ids.Validate()
 }
@@ -64,10 +68,30 @@ func (ManifestPipe) Publish(ctx *context.Context) error {
 				return err
 			}
 
+			annotations, err := annotationFlags(ctx, manifest.Annotations, "index")
+			if err != nil {
+				return err
+			}
+			flags := append(append([]string{}, manifest.CreateFlags...), annotations...)
+			pushFlags := manifest.PushFlags
+			if ctx.LocalRegistry.Address != "" && manifest.Use == useDocker {
+				// the local registry is served over plain http.
+				flags = append(flags, "--insecure")
+				pushFlags = append(append([]string{}, pushFlags...), "--insecure")
+			}
+
 			manifester := manifesters[manifest.Use]
 
-			log.WithField("manifest", name).WithField("images", images).Info("creating")
-			if err := manifester.Create(ctx, name, images, manifest.CreateFlags); err != nil {
+			authCtx, cleanup, err := withAuth(ctx, manifest.Auth, append([]string{name}, images...))
+			if err != nil {
+				return err
+			}
+			defer cleanup()
+
+			log.FromContext(ctx).WithField("manifest", name).WithField("images", images).Info("creating")
+			if err := withRetry(ctx, manifest.Retry, "create "+name, func() error {
+				return manifester.Create(authCtx, name, images, flags)
+			}); err != nil {
 				return err
 			}
 			art := &artifact.Artifact{
@@ -81,8 +105,10 @@ func (ManifestPipe) Publish(ctx *context.Context) error {
 			}
 			ctx.Artifacts.Add(art)
 
-			log.WithField("manifest", name).Info("pushing")
-			return manifester.Push(ctx, name, manifest.PushFlags)
+			log.FromContext(ctx).WithField("manifest", name).Info("pushing")
+			return withRetry(ctx, manifest.Retry, "push "+name, func() error {
+				return manifester.Push(authCtx, name, pushFlags)
+			})
 		})
 	}
 	return g.Wait()
@@ -110,7 +136,7 @@ func manifestName(ctx *context.Context, manifest config.DockerManifest) (string,
 	if strings.TrimSpace(name) == "" {
 		return name, pipe.Skip("manifest name is empty")
 	}
-	return name, nil
+	return withLocalRegistry(ctx, name), nil
 }
 
 func manifestImages(ctx *context.Context, manifest config.DockerManifest) ([]string, error) {
@@ -120,7 +146,7 @@ func manifestImages(ctx *context.Context, manifest config.DockerManifest) ([]str
 		if err != nil {
 			return []string{}, err
 		}
-		imgs = append(imgs, str)
+		imgs = append(imgs, withLocalRegistry(ctx, str))
 	}
 	if strings.TrimSpace(strings.Join(manifest.ImageTemplates, "")) == "" {
 		return imgs, pipe.Skip("manifest has no images")
@@ -0,0 +1,222 @@
+package docker
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/semerrgroup"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+)
+
+const (
+	dockerContextExtra    = "DockerContext"
+	dockerBuildFlagsExtra = "DockerBuildFlags"
+	dockerIndexFlagsExtra = "DockerIndexFlags"
+)
+
+var platformBuildsLock sync.Mutex
+
+// validatePlatforms checks the platforms of a multi-platform docker config.
+func validatePlatforms(docker config.Docker) error {
+	if docker.Use != useBuildx {
+		return fmt.Errorf("docker: platforms can only be used with use: %s", useBuildx)
+	}
+	if docker.Save {
+		return fmt.Errorf("docker: save can't be used with platforms, as multi-platform images can't be loaded into the docker daemon")
+	}
+	for _, p := range docker.Platforms {
+		if _, _, _, err := parsePlatform(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parsePlatform returns the goos, goarch and goarm of the given docker
+// platform, e.g. linux/arm/v7.
+func parsePlatform(platform string) (goos, goarch, goarm string, err error) {
+	parts := strings.Split(platform, "/")
+	switch {
+	case len(parts) == 2:
+		return parts[0], parts[1], "", nil
+	case len(parts) == 3 && parts[1] == "arm" && strings.HasPrefix(parts[2], "v"):
+		return parts[0], parts[1], strings.TrimPrefix(parts[2], "v"), nil
+	case len(parts) == 3 && parts[1] == "arm64" && parts[2] == "v8":
+		return parts[0], parts[1], "", nil
+	default:
+		return "", "", "", fmt.Errorf("docker: invalid platform: %s", platform)
+	}
+}
+
+// platformsFilter filters the artifacts of any of the given platforms.
+func platformsFilter(platforms []string) artifact.Filter {
+	filters := make([]artifact.Filter, 0, len(platforms))
+	for _, p := range platforms {
+		goos, goarch, goarm, _ := parsePlatform(p)
+		if goarch == "arm" && goarm == "" {
+			goarm = "6"
+		}
+		filters = append(filters, artifact.And(
+			artifact.ByGoos(goos),
+			artifact.ByGoarch(goarch),
+			artifact.ByGoarm(goarm),
+		))
+	}
+	return artifact.Or(filters...)
+}
+
+// platformDir returns the folder the given artifact is copied to in the
+// build context of a multi-platform image, e.g. linux/arm/v7, so the
+// Dockerfile can copy it from $TARGETPLATFORM.
+func platformDir(a *artifact.Artifact) string {
+	dir := filepath.Join(a.Goos, a.Goarch)
+	if a.Goarm != "" {
+		dir = filepath.Join(dir, "v"+a.Goarm)
+	}
+	return dir
+}
+
+// multiPlatformFlags returns the buildx flags of a multi-platform docker
+// config, shared by the builds of all its platforms.
+func multiPlatformFlags(docker config.Docker) []string {
+	var flags []string
+	if docker.Provenance != "" {
+		flags = append(flags, "--provenance="+docker.Provenance)
+	}
+	if docker.SBOM != "" {
+		flags = append(flags, "--sbom="+docker.SBOM)
+	}
+	return flags
+}
+
+// buildMultiPlatform builds the images of each platform on its own, so a
+// platform that fails transiently, e.g. because QEMU crashed, is retried
+// without building the other ones again.
+// As multi-platform images can't be loaded into the docker daemon, they are
+// kept in the build cache until they are pushed.
+func buildMultiPlatform(ctx *context.Context, docker config.Docker, root string, images, flags []string) ([]context.DockerPlatformBuild, error) {
+	builds := make([]context.DockerPlatformBuild, len(docker.Platforms))
+	g := semerrgroup.New(ctx.Parallelism)
+	for i, platform := range docker.Platforms {
+		i, platform := i, platform
+		g.Go(func() error {
+			args := []string{"buildx", "build", ".", "--platform=" + platform}
+			for _, image := range images {
+				args = append(args, "-t", image)
+			}
+			args = append(args, flags...)
+			var attempts int
+			start := time.Now()
+			if err := withRetry(ctx, docker.Retry, "build "+images[0]+" for "+platform, func() error {
+				attempts++
+				return runCommand(ctx, root, "docker", args...)
+			}); err != nil {
+				return fmt.Errorf("failed to build %s for %s: %w", images[0], platform, err)
+			}
+			builds[i] = context.DockerPlatformBuild{
+				Image:    images[0],
+				Platform: platform,
+				Duration: time.Since(start),
+				Attempts: attempts,
+			}
+			log.FromContext(ctx).WithField("image", images[0]).
+				WithField("platform", platform).
+				WithField("duration", builds[i].Duration.Round(time.Millisecond)).
+				WithField("attempts", attempts).
+				Info("built platform")
+			return nil
+		})
+	}
+	return builds, g.Wait()
+}
+
+// recordPlatformBuilds adds the given platform builds to the context, so
+// they end up in the release metrics.
+func recordPlatformBuilds(ctx *context.Context, builds []context.DockerPlatformBuild) {
+	platformBuildsLock.Lock()
+	defer platformBuildsLock.Unlock()
+	ctx.DockerPlatformBuilds = append(ctx.DockerPlatformBuilds, builds...)
+}
+
+// pushMultiPlatform pushes the given multi-platform image: the image of each
+// platform is pushed by digest, building it again from the build cache, and
+// the manifest list is then assembled from those digests.
+// Each step is retried on its own, so a platform failing transiently doesn't
+// push the other ones again.
+func pushMultiPlatform(ctx *context.Context, image *artifact.Artifact) error {
+	docker := image.Extra[dockerConfigExtra].(config.Docker)
+	root, _ := image.Extra[dockerContextExtra].(string)
+	flags, _ := image.Extra[dockerBuildFlagsExtra].([]string)
+	indexFlags, _ := image.Extra[dockerIndexFlagsExtra].([]string)
+	repo := imageRepo(image.Name)
+
+	sources := make([]string, 0, len(docker.Platforms))
+	for _, platform := range docker.Platforms {
+		metadata := filepath.Join(root, "metadata-"+strings.ReplaceAll(platform, "/", "-")+".json")
+		args := append([]string{
+			"buildx", "build", ".",
+			"--platform=" + platform,
+			"--output=type=image,name=" + repo + ",push-by-digest=true,name-canonical=true,push=true",
+			"--metadata-file=" + metadata,
+		}, flags...)
+		var digest string
+		if err := withRetry(ctx, docker.Retry, "push "+image.Name+" for "+platform, func() error {
+			if err := runCommand(ctx, root, "docker", args...); err != nil {
+				return err
+			}
+			var err error
+			digest, err = readDigest(metadata)
+			return err
+		}); err != nil {
+			return fmt.Errorf("failed to push %s for %s: %w", image.Name, platform, err)
+		}
+		sources = append(sources, repo+"@"+digest)
+	}
+
+	args := append([]string{"buildx", "imagetools", "create", "--tag", image.Name}, indexFlags...)
+	args = append(args, sources...)
+	if err := withRetry(ctx, docker.Retry, "create manifest list "+image.Name, func() error {
+		return runCommand(ctx, root, "docker", args...)
+	}); err != nil {
+		return fmt.Errorf("failed to push %s: %w", image.Name, err)
+	}
+	return nil
+}
+
+// readDigest reads the digest of the pushed image from the given buildx
+// metadata file.
+func readDigest(path string) (string, error) {
+	bts, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read build metadata: %w", err)
+	}
+	var metadata struct {
+		Digest string `json:"containerimage.digest"`
+	}
+	if err := json.Unmarshal(bts, &metadata); err != nil {
+		return "", fmt.Errorf("failed to read build metadata: %w", err)
+	}
+	if metadata.Digest == "" {
+		return "", fmt.Errorf("build metadata has no digest: %s", path)
+	}
+	return metadata.Digest, nil
+}
+
+// imageRepo returns the given image name without its tag or digest.
+func imageRepo(name string) string {
+	if i := strings.LastIndex(name, "@"); i >= 0 {
+		name = name[:i]
+	}
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		name = name[:i]
+	}
+	return name
+}
@@ -0,0 +1,236 @@
+package docker
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePlatform(t *testing.T) {
+	for platform, expected := range map[string][3]string{
+		"linux/amd64":    {"linux", "amd64", ""},
+		"linux/arm64":    {"linux", "arm64", ""},
+		"linux/arm64/v8": {"linux", "arm64", ""},
+		"linux/arm/v7":   {"linux", "arm", "7"},
+		"windows/amd64":  {"windows", "amd64", ""},
+	} {
+		goos, goarch, goarm, err := parsePlatform(platform)
+		require.NoError(t, err)
+		require.Equal(t, expected, [3]string{goos, goarch, goarm}, platform)
+	}
+	for _, platform := range []string{"linux", "linux/arm/7", "linux/amd64/v2", "linux/arm/v7/foo"} {
+		_, _, _, err := parsePlatform(platform)
+		require.EqualError(t, err, "docker: invalid platform: "+platform)
+	}
+}
+
+func TestDefaultPlatforms(t *testing.T) {
+	ctx := context.New(config.Project{
+		Dockers: []config.Docker{{
+			Use:       useBuildx,
+			Platforms: []string{"linux/amd64", "linux/arm64"},
+		}},
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.Empty(t, ctx.Config.Dockers[0].Goos)
+	require.Empty(t, ctx.Config.Dockers[0].Goarch)
+}
+
+func TestDefaultPlatformsErrors(t *testing.T) {
+	for expected, docker := range map[string]config.Docker{
+		"docker: platforms can only be used with use: buildx": {
+			Platforms: []string{"linux/amd64"},
+		},
+		"docker: save can't be used with platforms, as multi-platform images can't be loaded into the docker daemon": {
+			Use:       useBuildx,
+			Platforms: []string{"linux/amd64"},
+			Save:      true,
+		},
+		"docker: invalid platform: linux": {
+			Use:       useBuildx,
+			Platforms: []string{"linux"},
+		},
+	} {
+		ctx := context.New(config.Project{Dockers: []config.Docker{docker}})
+		require.EqualError(t, Pipe{}.Default(ctx), expected)
+	}
+}
+
+func TestMultiPlatform(t *testing.T) {
+	log := fakeDocker(t)
+	dist := t.TempDir()
+	ctx := context.New(config.Project{
+		Dist: dist,
+		Dockers: []config.Docker{{
+			Use:                useBuildx,
+			Dockerfile:         "testdata/Dockerfile.dummy",
+			ImageTemplates:     []string{"ghcr.io/foo/bar:{{ .Tag }}", "ghcr.io/foo/bar:latest"},
+			BuildFlagTemplates: []string{"--label=version={{ .Version }}"},
+			Platforms:          []string{"linux/amd64", "linux/arm64", "linux/arm/v7"},
+			Provenance:         "mode=max",
+			SBOM:               "true",
+		}},
+	})
+	ctx.Git.CurrentTag = "v1.0.0"
+	ctx.Version = "1.0.0"
+	for _, a := range []*artifact.Artifact{
+		{Name: "bar", Goos: "linux", Goarch: "amd64"},
+		{Name: "bar", Goos: "linux", Goarch: "arm64"},
+		{Name: "bar", Goos: "linux", Goarch: "arm", Goarm: "6"},
+		{Name: "bar", Goos: "linux", Goarch: "arm", Goarm: "7"},
+		{Name: "bar", Goos: "darwin", Goarch: "arm64"},
+	} {
+		dir := filepath.Join(dist, a.Goos+a.Goarch+a.Goarm)
+		require.NoError(t, os.MkdirAll(dir, 0o755))
+		a.Path = filepath.Join(dir, a.Name)
+		a.Type = artifact.Binary
+		require.NoError(t, os.WriteFile(a.Path, []byte(a.Goos+a.Goarch+a.Goarm), 0o755))
+		ctx.Artifacts.Add(a)
+	}
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.NoError(t, Pipe{}.Run(ctx))
+
+	images := ctx.Artifacts.Filter(artifact.ByType(artifact.PublishableDockerImage)).List()
+	require.Len(t, images, 2)
+	root := images[0].Extra[dockerContextExtra].(string)
+	for path, content := range map[string]string{
+		"linux/amd64/bar":  "linuxamd64",
+		"linux/arm64/bar":  "linuxarm64",
+		"linux/arm/v7/bar": "linuxarm7",
+	} {
+		bts, err := os.ReadFile(filepath.Join(root, path))
+		require.NoError(t, err)
+		require.Equal(t, content, string(bts))
+	}
+	require.NoFileExists(t, filepath.Join(root, "linux/arm/v6/bar"))
+	require.NoFileExists(t, filepath.Join(root, "darwin/arm64/bar"))
+
+	require.NoError(t, Pipe{}.Publish(ctx))
+	flags := "--label=version=1.0.0 --provenance=mode=max --sbom=true"
+	tags := "-t ghcr.io/foo/bar:v1.0.0 -t ghcr.io/foo/bar:latest "
+	sources := "ghcr.io/foo/bar@sha256:linux-amd64 ghcr.io/foo/bar@sha256:linux-arm64 ghcr.io/foo/bar@sha256:linux-arm-v7"
+	calls := dockerCalls(t, log)
+	// platforms are built concurrently.
+	sort.Strings(calls[:3])
+	require.Equal(t, []string{
+		"buildx build . --platform=linux/amd64 " + tags + flags,
+		"buildx build . --platform=linux/arm/v7 " + tags + flags,
+		"buildx build . --platform=linux/arm64 " + tags + flags,
+		pushCall(root, "linux/amd64", "ghcr.io/foo/bar") + flags,
+		pushCall(root, "linux/arm64", "ghcr.io/foo/bar") + flags,
+		pushCall(root, "linux/arm/v7", "ghcr.io/foo/bar") + flags,
+		"buildx imagetools create --tag ghcr.io/foo/bar:v1.0.0 " + sources,
+		pushCall(root, "linux/amd64", "ghcr.io/foo/bar") + flags,
+		pushCall(root, "linux/arm64", "ghcr.io/foo/bar") + flags,
+		pushCall(root, "linux/arm/v7", "ghcr.io/foo/bar") + flags,
+		"buildx imagetools create --tag ghcr.io/foo/bar:latest " + sources,
+	}, calls)
+	require.Len(t, ctx.Artifacts.Filter(artifact.ByType(artifact.DockerImage)).List(), 2)
+
+	require.Len(t, ctx.DockerPlatformBuilds, 3)
+	for _, build := range ctx.DockerPlatformBuilds {
+		require.Equal(t, "ghcr.io/foo/bar:v1.0.0", build.Image)
+		require.Equal(t, 1, build.Attempts)
+		require.NotZero(t, build.Duration)
+	}
+}
+
+func TestMultiPlatformFlakyPlatform(t *testing.T) {
+	log := fakeDocker(t)
+	t.Setenv("FAKE_DOCKER_FLAKY_PLATFORM", "linux/arm64")
+	dist := t.TempDir()
+	ctx := context.New(config.Project{
+		Dist: dist,
+		Dockers: []config.Docker{{
+			Use:            useBuildx,
+			Dockerfile:     "testdata/Dockerfile.dummy",
+			ImageTemplates: []string{"ghcr.io/foo/bar:latest"},
+			Platforms:      []string{"linux/amd64", "linux/arm64"},
+			Retry:          config.Retry{Delay: time.Millisecond},
+		}},
+	})
+	for _, goarch := range []string{"amd64", "arm64"} {
+		path := filepath.Join(dist, goarch, "bar")
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(goarch), 0o755))
+		ctx.Artifacts.Add(&artifact.Artifact{
+			Name:   "bar",
+			Path:   path,
+			Goos:   "linux",
+			Goarch: goarch,
+			Type:   artifact.Binary,
+		})
+	}
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.NoError(t, Pipe{}.Run(ctx))
+
+	calls := dockerCalls(t, log)
+	sort.Strings(calls)
+	require.Equal(t, []string{
+		"buildx build . --platform=linux/amd64 -t ghcr.io/foo/bar:latest",
+		"buildx build . --platform=linux/arm64 -t ghcr.io/foo/bar:latest",
+		"buildx build . --platform=linux/arm64 -t ghcr.io/foo/bar:latest",
+	}, calls)
+
+	builds := map[string]int{}
+	for _, build := range ctx.DockerPlatformBuilds {
+		builds[build.Platform] = build.Attempts
+	}
+	require.Equal(t, map[string]int{"linux/amd64": 1, "linux/arm64": 2}, builds)
+}
+
+func TestMultiPlatformBuildError(t *testing.T) {
+	dir := t.TempDir()
+	script := "#!/bin/sh\necho 'denied: requested access to the resource is denied' >&2\nexit 1\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "docker"), []byte(script), 0o755))
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	ctx := context.New(config.Project{})
+	docker := config.Docker{Platforms: []string{"linux/amd64"}, Retry: config.Retry{Attempts: 3}}
+	_, err := buildMultiPlatform(ctx, docker, t.TempDir(), []string{"ghcr.io/foo/bar:latest"}, nil)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to build ghcr.io/foo/bar:latest for linux/amd64")
+}
+
+func TestImageRepo(t *testing.T) {
+	for name, expected := range map[string]string{
+		"ghcr.io/foo/bar:v1.0.0":        "ghcr.io/foo/bar",
+		"ghcr.io/foo/bar":               "ghcr.io/foo/bar",
+		"localhost:5000/foo/bar:latest": "localhost:5000/foo/bar",
+		"localhost:5000/foo/bar":        "localhost:5000/foo/bar",
+		"ghcr.io/foo/bar@sha256:abc":    "ghcr.io/foo/bar",
+		"ghcr.io/foo/bar:v1@sha256:abc": "ghcr.io/foo/bar",
+	} {
+		require.Equal(t, expected, imageRepo(name), name)
+	}
+}
+
+func TestReadDigest(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "metadata.json")
+	_, err := readDigest(path)
+	require.Error(t, err)
+
+	require.NoError(t, os.WriteFile(path, []byte(`{}`), 0o644))
+	_, err = readDigest(path)
+	require.EqualError(t, err, "build metadata has no digest: "+path)
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"containerimage.digest": "sha256:abc"}`), 0o644))
+	digest, err := readDigest(path)
+	require.NoError(t, err)
+	require.Equal(t, "sha256:abc", digest)
+}
+
+// pushCall returns the arguments of the buildx call pushing the given
+// platform of a multi-platform image by digest.
+func pushCall(root, platform, repo string) string {
+	return "buildx build . --platform=" + platform +
+		" --output=type=image,name=" + repo + ",push-by-digest=true,name-canonical=true,push=true" +
+		" --metadata-file=" + filepath.Join(root, "metadata-"+strings.ReplaceAll(platform, "/", "-")+".json") + " "
+}
@@ -0,0 +1,65 @@
+package docker
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/pipe"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+)
+
+const promoteFromExtra = "PromoteFrom"
+
+// promote adds the images of the given config to be published as new tags of
+// an image pushed before, e.g. by a previous build, instead of building them.
+func promote(ctx *context.Context, docker config.Docker) error {
+	images, err := processImageTemplates(ctx, docker)
+	if err != nil {
+		return err
+	}
+	if len(images) == 0 {
+		return pipe.Skip("no image templates found")
+	}
+	src, err := tmpl.New(ctx).Apply(docker.PromoteFrom)
+	if err != nil {
+		return fmt.Errorf("failed to execute promote_from template '%s': %w", docker.PromoteFrom, err)
+	}
+	if src == "" {
+		return errors.New("docker: promote_from evaluated to an empty image")
+	}
+	if err := skipPush(ctx, docker); err != nil {
+		return err
+	}
+	addPublishable(ctx, docker, images, map[string]interface{}{
+		promoteFromExtra: src,
+	})
+	return nil
+}
+
+// pushPromoted tags the source image of the given image with its name in the
+// registry.
+// With buildx, the tag is created in the registry, keeping the manifest list
+// of multi-platform images and their digests; otherwise the source image is
+// pulled, tagged and pushed.
+func pushPromoted(ctx *context.Context, image *artifact.Artifact) error {
+	src := image.Extra[promoteFromExtra].(string)
+	docker := image.Extra[dockerConfigExtra].(config.Docker)
+	log.FromContext(ctx).WithField("image", image.Name).WithField("from", src).Info("promoting")
+	if docker.Use == useBuildx {
+		if err := runCommand(ctx, ".", "docker", "buildx", "imagetools", "create", "--tag", image.Name, src); err != nil {
+			return fmt.Errorf("failed to promote %s to %s: %w", src, image.Name, err)
+		}
+		return nil
+	}
+	if err := runCommand(ctx, ".", "docker", "pull", src); err != nil {
+		return fmt.Errorf("failed to promote %s to %s: %w", src, image.Name, err)
+	}
+	if err := runCommand(ctx, ".", "docker", "tag", src, image.Name); err != nil {
+		return fmt.Errorf("failed to promote %s to %s: %w", src, image.Name, err)
+	}
+	return dockerImager{}.Push(ctx, image.Name, docker.PushFlags)
+}
@@ -0,0 +1,94 @@
+package docker
+
+import (
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/pipe"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+func promoteContext(tb testing.TB, use string) *context.Context {
+	tb.Helper()
+	ctx := context.New(config.Project{
+		Dist: tb.TempDir(),
+		Dockers: []config.Docker{{
+			ID:             "foo",
+			ImageTemplates: []string{"ghcr.io/foo/bar:{{ .Tag }}", "ghcr.io/foo/bar:latest"},
+			PromoteFrom:    "ghcr.io/foo/bar:sha-{{ .ShortCommit }}",
+			Use:            use,
+		}},
+	})
+	ctx.Git.CurrentTag = "v1.0.0"
+	ctx.Git.ShortCommit = "abc1234"
+	require.NoError(tb, Pipe{}.Default(ctx))
+	return ctx
+}
+
+func TestPromote(t *testing.T) {
+	calls := fakeDocker(t)
+	ctx := promoteContext(t, useDocker)
+	require.NoError(t, Pipe{}.Run(ctx))
+	require.NoFileExists(t, calls)
+
+	images := ctx.Artifacts.Filter(artifact.ByType(artifact.PublishableDockerImage)).List()
+	require.Len(t, images, 2)
+	for _, img := range images {
+		require.Equal(t, "ghcr.io/foo/bar:sha-abc1234", img.Extra[promoteFromExtra])
+	}
+
+	require.NoError(t, Pipe{}.Publish(ctx))
+	require.Equal(t, []string{
+		"pull ghcr.io/foo/bar:sha-abc1234",
+		"tag ghcr.io/foo/bar:sha-abc1234 ghcr.io/foo/bar:v1.0.0",
+		"push ghcr.io/foo/bar:v1.0.0",
+		"pull ghcr.io/foo/bar:sha-abc1234",
+		"tag ghcr.io/foo/bar:sha-abc1234 ghcr.io/foo/bar:latest",
+		"push ghcr.io/foo/bar:latest",
+	}, dockerCalls(t, calls))
+	require.Len(t, ctx.Artifacts.Filter(artifact.ByType(artifact.DockerImage)).List(), 2)
+}
+
+func TestPromoteBuildx(t *testing.T) {
+	calls := fakeDocker(t)
+	ctx := promoteContext(t, useBuildx)
+	require.NoError(t, Pipe{}.Run(ctx))
+	require.NoError(t, Pipe{}.Publish(ctx))
+	require.Equal(t, []string{
+		"buildx imagetools create --tag ghcr.io/foo/bar:v1.0.0 ghcr.io/foo/bar:sha-abc1234",
+		"buildx imagetools create --tag ghcr.io/foo/bar:latest ghcr.io/foo/bar:sha-abc1234",
+	}, dockerCalls(t, calls))
+}
+
+func TestPromoteSkipPublish(t *testing.T) {
+	ctx := promoteContext(t, useDocker)
+	ctx.SkipPublish = true
+	require.True(t, pipe.IsSkip(Pipe{}.Run(ctx)))
+	require.Empty(t, ctx.Artifacts.List())
+}
+
+func TestPromoteErrors(t *testing.T) {
+	t.Run("invalid template", func(t *testing.T) {
+		ctx := promoteContext(t, useDocker)
+		ctx.Config.Dockers[0].PromoteFrom = "{{ .Nope }"
+		require.Error(t, Pipe{}.Run(ctx))
+	})
+	t.Run("empty", func(t *testing.T) {
+		ctx := promoteContext(t, useDocker)
+		ctx.Config.Dockers[0].PromoteFrom = "{{ .Env.NOPE }}"
+		ctx.Env["NOPE"] = ""
+		require.EqualError(t, Pipe{}.Run(ctx), "docker: promote_from evaluated to an empty image")
+	})
+	t.Run("save", func(t *testing.T) {
+		ctx := context.New(config.Project{
+			Dockers: []config.Docker{{
+				ImageTemplates: []string{"foo"},
+				PromoteFrom:    "bar",
+				Save:           true,
+			}},
+		})
+		require.EqualError(t, Pipe{}.Default(ctx), "docker: save can't be used with promote_from, as promoted images are not built")
+	})
+}
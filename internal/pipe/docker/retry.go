@@ -0,0 +1,147 @@
+package docker
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+)
+
+const (
+	defaultRetryAttempts = 10
+	defaultRetryDelay    = 10 * time.Second
+	defaultRetryMaxDelay = 5 * time.Minute
+)
+
+// transientErrors are the messages of registry errors worth retrying.
+var transientErrors = []string{
+	"500 internal server error",
+	"502 bad gateway",
+	"503 service unavailable",
+	"504 gateway timeout",
+	"connection reset by peer",
+	"connection refused",
+	"i/o timeout",
+	"tls handshake timeout",
+	"unexpected eof",
+}
+
+// emulationErrors are the messages of builds crashing under QEMU emulation,
+// which are usually flakes.
+var emulationErrors = []string{
+	"qemu: uncaught target signal",
+	"segmentation fault",
+	"exit code: 139",
+}
+
+// rateLimitErrors are the messages of registries rate limiting us.
+var rateLimitErrors = []string{
+	"429 too many requests",
+	"toomanyrequests",
+	"rate limit",
+}
+
+var retryAfterRe = regexp.MustCompile(`(?i)retry[- ]after:?\s*(\d+)`)
+
+func setRetryDefaults(retry *config.Retry) {
+	if retry.Attempts == 0 {
+		retry.Attempts = defaultRetryAttempts
+	}
+	if retry.Delay == 0 {
+		retry.Delay = defaultRetryDelay
+	}
+	if retry.MaxDelay == 0 {
+		retry.MaxDelay = defaultRetryMaxDelay
+	}
+}
+
+// withRetry runs fn until it succeeds, fails with an error that is not
+// transient, or runs out of attempts, waiting longer after each attempt.
+// Attempts wait for as long as the registry asked, up to the maximum delay.
+func withRetry(ctx *context.Context, retry config.Retry, what string, fn func() error) error {
+	var err error
+	for try := 1; ; try++ {
+		err = fn()
+		if err == nil {
+			return nil
+		}
+		transient, rateLimited, retryAfter := classify(err)
+		if !transient || try >= int(retry.Attempts) {
+			break
+		}
+		delay := retryDelay(retry, try, retryAfter)
+		log.FromContext(ctx).WithField("try", try).
+			WithField("delay", delay).
+			WithField("rate_limited", rateLimited).
+			WithError(err).
+			Warnf("failed to %s, will retry", what)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+	return err
+}
+
+// retryDelay returns how long to wait before the given retry: the delay the
+// registry asked for, capped at the maximum delay, or the exponential backoff
+// if it didn't say.
+func retryDelay(retry config.Retry, try int, retryAfter time.Duration) time.Duration {
+	if retryAfter <= 0 {
+		return backoff(retry, try)
+	}
+	if retryAfter > retry.MaxDelay {
+		return retry.MaxDelay
+	}
+	return retryAfter
+}
+
+// backoff returns the exponential delay before the given retry.
+func backoff(retry config.Retry, try int) time.Duration {
+	delay := retry.Delay
+	for i := 1; i < try; i++ {
+		delay *= 2
+		if delay >= retry.MaxDelay {
+			return retry.MaxDelay
+		}
+	}
+	if delay > retry.MaxDelay {
+		return retry.MaxDelay
+	}
+	return delay
+}
+
+// classify returns whether the given error is transient, whether it is
+// because of a rate limit, and how long the registry asked to wait, if it
+// did.
+func classify(err error) (transient, rateLimited bool, retryAfter time.Duration) {
+	msg := strings.ToLower(err.Error())
+	for _, s := range rateLimitErrors {
+		if strings.Contains(msg, s) {
+			rateLimited = true
+		}
+	}
+	if m := retryAfterRe.FindStringSubmatch(msg); m != nil {
+		seconds, _ := strconv.Atoi(m[1])
+		retryAfter = time.Duration(seconds) * time.Second
+	}
+	if rateLimited {
+		return true, true, retryAfter
+	}
+	for _, s := range transientErrors {
+		if strings.Contains(msg, s) {
+			return true, false, retryAfter
+		}
+	}
+	for _, s := range emulationErrors {
+		if strings.Contains(msg, s) {
+			return true, false, retryAfter
+		}
+	}
+	return false, false, 0
+}
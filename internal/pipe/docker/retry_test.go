@@ -0,0 +1,170 @@
+package docker
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+var fastRetry = config.Retry{
+	Attempts: 3,
+	Delay:    time.Millisecond,
+	MaxDelay: 2 * time.Millisecond,
+}
+
+func TestClassify(t *testing.T) {
+	for msg, expected := range map[string]struct {
+		transient, rateLimited bool
+		retryAfter             time.Duration
+	}{
+		"received unexpected HTTP status: 503 Service Unavailable":                          {true, false, 0},
+		"Get https://ghcr.io/v2/: net/http: TLS handshake timeout":                          {true, false, 0},
+		"read tcp 1.2.3.4:443: read: connection reset by peer":                              {true, false, 0},
+		"toomanyrequests: You have reached your pull rate limit":                            {true, true, 0},
+		"unexpected status: 429 Too Many Requests, Retry-After: 30":                         {true, true, 30 * time.Second},
+		"qemu: uncaught target signal 11 (Segmentation fault) - core dumped":                {true, false, 0},
+		"process \"/bin/sh -c apk add curl\" did not complete successfully: exit code: 139": {true, false, 0},
+		"denied: requested access to the resource is denied":                                {false, false, 0},
+		"unauthorized: authentication required":                                             {false, false, 0},
+		"manifest unknown: manifest tagged by \"latest\" is not found":                      {false, false, 0},
+		"name unknown: repository name not known to registry, retry after 5":                {false, false, 0},
+	} {
+		transient, rateLimited, retryAfter := classify(errors.New(msg))
+		require.Equal(t, expected.transient, transient, msg)
+		require.Equal(t, expected.rateLimited, rateLimited, msg)
+		require.Equal(t, expected.retryAfter, retryAfter, msg)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	retry := config.Retry{Delay: time.Second, MaxDelay: 10 * time.Second}
+	for try, expected := range map[int]time.Duration{
+		1: time.Second,
+		2: 2 * time.Second,
+		3: 4 * time.Second,
+		4: 8 * time.Second,
+		5: 10 * time.Second,
+		9: 10 * time.Second,
+	} {
+		require.Equal(t, expected, backoff(retry, try), try)
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	retry := config.Retry{Delay: time.Second, MaxDelay: 10 * time.Second}
+	require.Equal(t, time.Second, retryDelay(retry, 1, 0))
+	require.Equal(t, 4*time.Second, retryDelay(retry, 3, 0))
+	require.Equal(t, 5*time.Second, retryDelay(retry, 1, 5*time.Second))
+	require.Equal(t, 10*time.Second, retryDelay(retry, 1, time.Hour))
+}
+
+func TestWithRetry(t *testing.T) {
+	ctx := context.New(config.Project{})
+
+	t.Run("transient", func(t *testing.T) {
+		tries := 0
+		require.NoError(t, withRetry(ctx, fastRetry, "push", func() error {
+			tries++
+			if tries < 3 {
+				return errors.New("502 Bad Gateway")
+			}
+			return nil
+		}))
+		require.Equal(t, 3, tries)
+	})
+
+	t.Run("rate limited", func(t *testing.T) {
+		tries := 0
+		require.NoError(t, withRetry(ctx, fastRetry, "push", func() error {
+			tries++
+			if tries < 2 {
+				return errors.New("toomanyrequests: slow down")
+			}
+			return nil
+		}))
+		require.Equal(t, 2, tries)
+	})
+
+	t.Run("out of attempts", func(t *testing.T) {
+		tries := 0
+		require.EqualError(t, withRetry(ctx, fastRetry, "push", func() error {
+			tries++
+			return errors.New("503 Service Unavailable")
+		}), "503 Service Unavailable")
+		require.Equal(t, 3, tries)
+	})
+
+	t.Run("not transient", func(t *testing.T) {
+		tries := 0
+		require.EqualError(t, withRetry(ctx, fastRetry, "push", func() error {
+			tries++
+			return errors.New("denied")
+		}), "denied")
+		require.Equal(t, 1, tries)
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		ctx, cancel := context.NewWithTimeout(config.Project{}, time.Millisecond)
+		defer cancel()
+		retry := config.Retry{Attempts: 3, Delay: time.Hour, MaxDelay: time.Hour}
+		require.ErrorIs(t, withRetry(ctx, retry, "push", func() error {
+			return errors.New("503 Service Unavailable")
+		}), ctx.Err())
+	})
+}
+
+func TestDefaultRetry(t *testing.T) {
+	ctx := context.New(config.Project{
+		Dockers:         []config.Docker{{}},
+		DockerManifests: []config.DockerManifest{{Retry: config.Retry{Attempts: 1}}},
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.NoError(t, ManifestPipe{}.Default(ctx))
+	require.Equal(t, config.Retry{
+		Attempts: defaultRetryAttempts,
+		Delay:    defaultRetryDelay,
+		MaxDelay: defaultRetryMaxDelay,
+	}, ctx.Config.Dockers[0].Retry)
+	require.Equal(t, config.Retry{
+		Attempts: 1,
+		Delay:    defaultRetryDelay,
+		MaxDelay: defaultRetryMaxDelay,
+	}, ctx.Config.DockerManifests[0].Retry)
+}
+
+func TestPushRetry(t *testing.T) {
+	dir := t.TempDir()
+	calls := filepath.Join(dir, "docker.log")
+	script := `#!/bin/sh
+echo "$@" >> ` + calls + `
+if [ "$1" = "push" ] && [ ! -f ` + filepath.Join(dir, "pushed") + ` ]; then
+	touch ` + filepath.Join(dir, "pushed") + `
+	echo "received unexpected HTTP status: 503 Service Unavailable" >&2
+	exit 1
+fi
+`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "docker"), []byte(script), 0o755))
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	ctx := context.New(config.Project{})
+	docker := config.Docker{Use: useDocker, Retry: fastRetry}
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Type:  artifact.PublishableDockerImage,
+		Name:  "ghcr.io/foo/bar:latest",
+		Path:  "ghcr.io/foo/bar:latest",
+		Extra: map[string]interface{}{dockerConfigExtra: docker},
+	})
+	require.NoError(t, Pipe{}.Publish(ctx))
+	require.Equal(t, []string{
+		"push ghcr.io/foo/bar:latest",
+		"push ghcr.io/foo/bar:latest",
+	}, dockerCalls(t, calls))
+	require.Len(t, ctx.Artifacts.Filter(artifact.ByType(artifact.DockerImage)).List(), 1)
+}
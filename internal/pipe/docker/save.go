@@ -0,0 +1,182 @@
+package docker
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/semerrgroup"
+	"github.com/goreleaser/goreleaser/pkg/archive/tar"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+)
+
+const (
+	imagesExtra = "Images"
+
+	saveFormatDocker = "docker"
+	saveFormatOCI    = "oci"
+)
+
+// LoadPipe loads the docker images saved in the dist folder by a previous
+// release, so they can be pushed from another host, e.g. with
+// goreleaser publish.
+type LoadPipe struct{}
+
+func (LoadPipe) String() string { return "loading docker images" }
+
+func (LoadPipe) Skip(ctx *context.Context) bool {
+	for _, docker := range ctx.Config.Dockers {
+		if docker.Save {
+			return false
+		}
+	}
+	return true
+}
+
+// Run the pipe.
+func (LoadPipe) Run(ctx *context.Context) error {
+	g := semerrgroup.NewSkipAware(semerrgroup.New(ctx.Parallelism))
+	for _, docker := range ctx.Config.Dockers {
+		if !docker.Save {
+			continue
+		}
+		docker := docker
+		g.Go(func() error {
+			images, err := processImageTemplates(ctx, docker)
+			if err != nil || len(images) == 0 {
+				return err
+			}
+			if err := skipPush(ctx, docker); err != nil {
+				return err
+			}
+			if err := load(ctx, docker, images); err != nil {
+				return err
+			}
+			addPublishable(ctx, docker, images, nil)
+			return nil
+		})
+	}
+	return g.Wait()
+}
+
+// save exports the given images to a tarball in the dist folder.
+// The tarball keeps all the tags of the images, so they can be pushed as they
+// are once loaded back.
+func save(ctx *context.Context, docker config.Docker, images []string) error {
+	name := archiveName(images[0], docker.SaveFormat)
+	path := filepath.Join(ctx.Config.Dist, name)
+	log.FromContext(ctx).WithField("file", path).Info("saving docker images")
+	var err error
+	if docker.SaveFormat == saveFormatOCI {
+		err = saveOCI(ctx, path, images)
+	} else {
+		err = runCommand(ctx, ".", "docker", append([]string{"save", "-o", path}, images...)...)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save %s: %w", images[0], err)
+	}
+	art := &artifact.Artifact{
+		Type:   artifact.DockerImageArchive,
+		Name:   name,
+		Path:   path,
+		Goarch: docker.Goarch,
+		Goos:   docker.Goos,
+		Goarm:  docker.Goarm,
+		Extra: map[string]interface{}{
+			imagesExtra:          images,
+			artifact.ExtraFormat: docker.SaveFormat,
+			artifact.ExtraUpload: docker.SaveUpload,
+		},
+	}
+	if docker.ID != "" {
+		art.Extra[artifact.ExtraID] = docker.ID
+	}
+	ctx.Artifacts.Add(art)
+	return nil
+}
+
+// saveOCI exports the given images to an OCI image layout with skopeo, each
+// image named after its reference, and archives it as a tarball.
+func saveOCI(ctx *context.Context, path string, images []string) error {
+	dir, err := os.MkdirTemp("", "goreleaser-oci-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	for _, image := range images {
+		if err := runCommand(ctx, ".", "skopeo", "copy", "docker-daemon:"+image, "oci:"+dir+":"+image); err != nil {
+			return err
+		}
+	}
+	return tarDir(dir, path)
+}
+
+// tarDir archives the contents of the given directory.
+func tarDir(dir, path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	a := tar.NewWithOptions(f, tar.Options{Reproducible: true})
+	if err := filepath.Walk(dir, func(src string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		dst, err := filepath.Rel(dir, src)
+		if err != nil {
+			return err
+		}
+		return a.Add(config.File{
+			Source:      src,
+			Destination: filepath.ToSlash(dst),
+		})
+	}); err != nil {
+		_ = a.Close()
+		return err
+	}
+	if err := a.Close(); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// load imports the tarball of the given images from the dist folder.
+func load(ctx *context.Context, docker config.Docker, images []string) error {
+	path := filepath.Join(ctx.Config.Dist, archiveName(images[0], docker.SaveFormat))
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("failed to load %s: %w", images[0], err)
+	}
+	log.FromContext(ctx).WithField("file", path).Info("loading docker images")
+	if docker.SaveFormat != saveFormatOCI {
+		if err := runCommand(ctx, ".", "docker", "load", "-i", path); err != nil {
+			return fmt.Errorf("failed to load %s: %w", images[0], err)
+		}
+		return nil
+	}
+	for _, image := range images {
+		if err := runCommand(ctx, ".", "skopeo", "copy", "oci-archive:"+path+":"+image, "docker-daemon:"+image); err != nil {
+			return fmt.Errorf("failed to load %s: %w", image, err)
+		}
+	}
+	return nil
+}
+
+// archiveName returns the name of the tarball of the images saved along with
+// the given image.
+func archiveName(image, format string) string {
+	return strings.NewReplacer("/", "_", ":", "_", "@", "_").Replace(image) + "." + format + ".tar"
+}
+
+func validateSaveFormat(format string) error {
+	switch format {
+	case saveFormatDocker, saveFormatOCI:
+		return nil
+	default:
+		return fmt.Errorf("docker: invalid save_format: %s, valid options are [%s %s]", format, saveFormatDocker, saveFormatOCI)
+	}
+}
@@ -0,0 +1,225 @@
+package docker
+
+import (
+	"archive/tar"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/pipe"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeDocker puts a fake docker command in the PATH, which records its
+// arguments in the returned file and creates the tarballs it is asked to save.
+func fakeDocker(tb testing.TB) string {
+	tb.Helper()
+	dir := tb.TempDir()
+	calls := filepath.Join(dir, "docker.log")
+	script := `#!/bin/sh
+echo "$@" >> ` + calls + `
+if [ "$1" = "save" ]; then
+	echo images > "$3"
+fi
+for arg in "$@"; do
+	case "$arg" in
+	--platform=*) platform="${arg#--platform=}" ;;
+	--metadata-file=*) metadata="${arg#--metadata-file=}" ;;
+	esac
+done
+if [ -n "$FAKE_DOCKER_FLAKY_PLATFORM" ] && [ "$platform" = "$FAKE_DOCKER_FLAKY_PLATFORM" ] && [ ! -f ` + calls + `.flaked ]; then
+	touch ` + calls + `.flaked
+	echo "qemu: uncaught target signal 11 (Segmentation fault) - core dumped" >&2
+	exit 1
+fi
+if [ -n "$metadata" ]; then
+	echo "{\"containerimage.digest\": \"sha256:$(echo "$platform" | tr / -)\"}" > "$metadata"
+fi
+`
+	require.NoError(tb, os.WriteFile(filepath.Join(dir, "docker"), []byte(script), 0o755))
+	tb.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return calls
+}
+
+func dockerCalls(tb testing.TB, path string) []string {
+	tb.Helper()
+	bts, err := os.ReadFile(path)
+	require.NoError(tb, err)
+	return strings.Split(strings.TrimSpace(string(bts)), "\n")
+}
+
+func saveContext(tb testing.TB, dist string) *context.Context {
+	tb.Helper()
+	ctx := context.New(config.Project{
+		Dist: dist,
+		Dockers: []config.Docker{{
+			ID:             "foo",
+			Dockerfile:     "testdata/Dockerfile.dummy",
+			ImageTemplates: []string{"ghcr.io/foo/bar:{{ .Tag }}", "ghcr.io/foo/bar:latest"},
+			Save:           true,
+		}},
+		DockerManifests: []config.DockerManifest{{
+			NameTemplate:   "ghcr.io/foo/bar:{{ .Tag }}-multi",
+			ImageTemplates: []string{"ghcr.io/foo/bar:{{ .Tag }}"},
+		}},
+	})
+	ctx.Git.CurrentTag = "v1.0.0"
+	require.NoError(tb, Pipe{}.Default(ctx))
+	require.NoError(tb, ManifestPipe{}.Default(ctx))
+	return ctx
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	calls := fakeDocker(t)
+	dist := t.TempDir()
+
+	ctx := saveContext(t, dist)
+	ctx.SkipPublish = true
+	require.True(t, pipe.IsSkip(Pipe{}.Run(ctx)))
+	require.Empty(t, ctx.Artifacts.Filter(artifact.ByType(artifact.PublishableDockerImage)).List())
+
+	archives := ctx.Artifacts.Filter(artifact.ByType(artifact.DockerImageArchive)).List()
+	require.Len(t, archives, 1)
+	archive := archives[0]
+	require.Equal(t, "ghcr.io_foo_bar_v1.0.0.docker.tar", archive.Name)
+	require.Equal(t, filepath.Join(dist, archive.Name), archive.Path)
+	require.Equal(t, "foo", archive.ID())
+	require.Equal(t, []string{"ghcr.io/foo/bar:v1.0.0", "ghcr.io/foo/bar:latest"}, archive.Extra[imagesExtra])
+	require.FileExists(t, archive.Path)
+	require.Equal(t, []string{
+		"build . -t ghcr.io/foo/bar:v1.0.0 -t ghcr.io/foo/bar:latest",
+		"save -o " + archive.Path + " ghcr.io/foo/bar:v1.0.0 ghcr.io/foo/bar:latest",
+	}, dockerCalls(t, calls))
+
+	require.NoError(t, os.Remove(calls))
+	ctx = saveContext(t, dist)
+	require.False(t, LoadPipe{}.Skip(ctx))
+	require.NoError(t, LoadPipe{}.Run(ctx))
+	require.NoError(t, Pipe{}.Publish(ctx))
+	require.NoError(t, ManifestPipe{}.Publish(ctx))
+	require.Equal(t, []string{
+		"load -i " + archive.Path,
+		"push ghcr.io/foo/bar:v1.0.0",
+		"push ghcr.io/foo/bar:latest",
+		"manifest rm ghcr.io/foo/bar:v1.0.0-multi",
+		"manifest create ghcr.io/foo/bar:v1.0.0-multi ghcr.io/foo/bar:v1.0.0",
+		"manifest push ghcr.io/foo/bar:v1.0.0-multi",
+	}, dockerCalls(t, calls))
+
+	var images []string
+	for _, a := range ctx.Artifacts.Filter(artifact.ByType(artifact.DockerImage)).List() {
+		images = append(images, a.Name)
+	}
+	require.Equal(t, []string{"ghcr.io/foo/bar:v1.0.0", "ghcr.io/foo/bar:latest"}, images)
+}
+
+func TestLoadMissingArchive(t *testing.T) {
+	fakeDocker(t)
+	ctx := saveContext(t, t.TempDir())
+	err := LoadPipe{}.Run(ctx)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to load ghcr.io/foo/bar:v1.0.0")
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestLoadSkipPush(t *testing.T) {
+	calls := fakeDocker(t)
+	ctx := saveContext(t, t.TempDir())
+	ctx.Config.Dockers[0].SkipPush = "true"
+	require.True(t, pipe.IsSkip(LoadPipe{}.Run(ctx)))
+	require.NoFileExists(t, calls)
+}
+
+func TestLoadSkip(t *testing.T) {
+	require.True(t, LoadPipe{}.Skip(context.New(config.Project{})))
+	require.True(t, LoadPipe{}.Skip(context.New(config.Project{
+		Dockers: []config.Docker{{ImageTemplates: []string{"foo"}}},
+	})))
+}
+
+// fakeSkopeo puts a skopeo script in the PATH that logs its arguments and
+// writes an index.json to the OCI layouts it copies to.
+func fakeSkopeo(tb testing.TB) string {
+	tb.Helper()
+	dir := tb.TempDir()
+	calls := filepath.Join(dir, "skopeo.log")
+	script := `#!/bin/sh
+echo "$@" >> ` + calls + `
+case "$3" in
+oci:*)
+	layout="${3#oci:}"
+	layout="${layout%%:*}"
+	echo '{"schemaVersion":2}' > "$layout/index.json"
+	;;
+esac
+`
+	require.NoError(tb, os.WriteFile(filepath.Join(dir, "skopeo"), []byte(script), 0o755))
+	tb.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return calls
+}
+
+func TestSaveAndLoadOCI(t *testing.T) {
+	dockerLog := fakeDocker(t)
+	skopeoLog := fakeSkopeo(t)
+	dist := t.TempDir()
+
+	ctx := saveContext(t, dist)
+	ctx.Config.Dockers[0].SaveFormat = "oci"
+	ctx.Config.Dockers[0].SaveUpload = true
+	ctx.SkipPublish = true
+	require.True(t, pipe.IsSkip(Pipe{}.Run(ctx)))
+
+	archives := ctx.Artifacts.Filter(artifact.ByType(artifact.DockerImageArchive)).List()
+	require.Len(t, archives, 1)
+	archive := archives[0]
+	require.Equal(t, "ghcr.io_foo_bar_v1.0.0.oci.tar", archive.Name)
+	require.Equal(t, "oci", archive.Format())
+	require.True(t, artifact.MarkedForUpload(archive))
+	require.Equal(t, []string{"build . -t ghcr.io/foo/bar:v1.0.0 -t ghcr.io/foo/bar:latest"}, dockerCalls(t, dockerLog))
+	calls := dockerCalls(t, skopeoLog)
+	require.Len(t, calls, 2)
+	require.True(t, strings.HasPrefix(calls[0], "copy docker-daemon:ghcr.io/foo/bar:v1.0.0 oci:"), calls[0])
+	require.True(t, strings.HasSuffix(calls[1], ":ghcr.io/foo/bar:latest"), calls[1])
+
+	f, err := os.Open(archive.Path)
+	require.NoError(t, err)
+	defer f.Close()
+	hdr, err := tar.NewReader(f).Next()
+	require.NoError(t, err)
+	require.Equal(t, "index.json", hdr.Name)
+
+	require.NoError(t, os.Remove(dockerLog))
+	require.NoError(t, os.Remove(skopeoLog))
+	ctx = saveContext(t, dist)
+	ctx.Config.Dockers[0].SaveFormat = "oci"
+	require.NoError(t, LoadPipe{}.Run(ctx))
+	require.Equal(t, []string{
+		"copy oci-archive:" + archive.Path + ":ghcr.io/foo/bar:v1.0.0 docker-daemon:ghcr.io/foo/bar:v1.0.0",
+		"copy oci-archive:" + archive.Path + ":ghcr.io/foo/bar:latest docker-daemon:ghcr.io/foo/bar:latest",
+	}, dockerCalls(t, skopeoLog))
+	require.NoFileExists(t, dockerLog)
+}
+
+func TestDefaultSaveFormat(t *testing.T) {
+	ctx := saveContext(t, t.TempDir())
+	require.Equal(t, "docker", ctx.Config.Dockers[0].SaveFormat)
+
+	ctx = context.New(config.Project{
+		Dockers: []config.Docker{{
+			ImageTemplates: []string{"foo"},
+			Save:           true,
+			SaveFormat:     "zip",
+		}},
+	})
+	require.EqualError(t, Pipe{}.Default(ctx), "docker: invalid save_format: zip, valid options are [docker oci]")
+}
+
+func TestArchiveName(t *testing.T) {
+	require.Equal(t, "ghcr.io_foo_bar_v1.docker.tar", archiveName("ghcr.io/foo/bar:v1", "docker"))
+	require.Equal(t, "foo_sha256_abc.docker.tar", archiveName("foo@sha256:abc", "docker"))
+	require.Equal(t, "ghcr.io_foo_bar_v1.oci.tar", archiveName("ghcr.io/foo/bar:v1", "oci"))
+}
@@ -0,0 +1,84 @@
+package docker
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+)
+
+// secretFlags returns the --secret and --ssh flags of the given config.
+//
+// The images are built from a temporary folder, so relative paths of secret
+// files, ssh sockets and keys are made absolute. Only references to the
+// secrets end up in the command line, never their values.
+func secretFlags(ctx *context.Context, docker config.Docker) ([]string, error) {
+	var flags []string
+	for _, secret := range docker.Secrets {
+		value, err := tmpl.New(ctx).Apply(secret)
+		if err != nil {
+			return nil, fmt.Errorf("failed to process secret '%s': %w", secret, err)
+		}
+		if value == "" {
+			continue
+		}
+		value, err = absSecretPaths(value)
+		if err != nil {
+			return nil, err
+		}
+		flags = append(flags, "--secret="+value)
+	}
+	for _, ssh := range docker.SSH {
+		value, err := tmpl.New(ctx).Apply(ssh)
+		if err != nil {
+			return nil, fmt.Errorf("failed to process ssh '%s': %w", ssh, err)
+		}
+		if value == "" {
+			continue
+		}
+		value, err = absSSHPaths(value)
+		if err != nil {
+			return nil, err
+		}
+		flags = append(flags, "--ssh="+value)
+	}
+	return flags, nil
+}
+
+// absSecretPaths makes the src of a secret, as in id=foo,src=path, absolute.
+func absSecretPaths(secret string) (string, error) {
+	fields := strings.Split(secret, ",")
+	for i, field := range fields {
+		kv := strings.SplitN(field, "=", 2)
+		if len(kv) != 2 || (kv[0] != "src" && kv[0] != "source") {
+			continue
+		}
+		abs, err := filepath.Abs(kv[1])
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve secret '%s': %w", secret, err)
+		}
+		fields[i] = kv[0] + "=" + abs
+	}
+	return strings.Join(fields, ","), nil
+}
+
+// absSSHPaths makes the socket or keys of an ssh agent, as in
+// id=path[,path], absolute.
+func absSSHPaths(ssh string) (string, error) {
+	kv := strings.SplitN(ssh, "=", 2)
+	if len(kv) != 2 {
+		return ssh, nil
+	}
+	parts := strings.Split(kv[1], ",")
+	for i, path := range parts {
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve ssh '%s': %w", ssh, err)
+		}
+		parts[i] = abs
+	}
+	return kv[0] + "=" + strings.Join(parts, ","), nil
+}
@@ -0,0 +1,79 @@
+package docker
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecretFlags(t *testing.T) {
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+
+	ctx := context.New(config.Project{})
+	ctx.Env["NPMRC"] = ".npmrc"
+	ctx.Env["SSH_AUTH_SOCK"] = "/run/ssh.sock"
+	ctx.Env["EMPTY"] = ""
+	flags, err := secretFlags(ctx, config.Docker{
+		Secrets: []string{
+			"id=npmrc,src={{ .Env.NPMRC }}",
+			"id=key,source=/etc/key,type=file",
+			"id=token,env=GITHUB_TOKEN",
+			"{{ .Env.EMPTY }}",
+		},
+		SSH: []string{
+			"default",
+			"default={{ .Env.SSH_AUTH_SOCK }}",
+			"github=keys/id_rsa,/etc/id_ed25519",
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"--secret=id=npmrc,src=" + filepath.Join(wd, ".npmrc"),
+		"--secret=id=key,source=/etc/key,type=file",
+		"--secret=id=token,env=GITHUB_TOKEN",
+		"--ssh=default",
+		"--ssh=default=/run/ssh.sock",
+		"--ssh=github=" + filepath.Join(wd, "keys/id_rsa") + ",/etc/id_ed25519",
+	}, flags)
+}
+
+func TestSecretFlagsInvalidTemplate(t *testing.T) {
+	ctx := context.New(config.Project{})
+	_, err := secretFlags(ctx, config.Docker{Secrets: []string{"{{ .Nope }"}})
+	require.Error(t, err)
+	_, err = secretFlags(ctx, config.Docker{SSH: []string{"{{ .Nope }"}})
+	require.Error(t, err)
+}
+
+func TestRunWithSecrets(t *testing.T) {
+	calls := fakeDocker(t)
+	ctx := context.New(config.Project{
+		Dist: t.TempDir(),
+		Dockers: []config.Docker{{
+			Dockerfile:     "testdata/Dockerfile.dummy",
+			ImageTemplates: []string{"ghcr.io/foo/bar:latest"},
+			Secrets:        []string{"id=token,env=GITHUB_TOKEN"},
+			SSH:            []string{"default"},
+		}},
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.NoError(t, Pipe{}.Run(ctx))
+	require.Equal(t, []string{
+		"build . -t ghcr.io/foo/bar:latest --secret=id=token,env=GITHUB_TOKEN --ssh=default",
+	}, dockerCalls(t, calls))
+}
+
+func TestDefaultSecretsBuildpacks(t *testing.T) {
+	ctx := context.New(config.Project{
+		Dockers: []config.Docker{{
+			Use: useBuildPacks,
+			SSH: []string{"default"},
+		}},
+	})
+	require.EqualError(t, Pipe{}.Default(ctx), "docker: secrets and ssh can't be used with use: buildpacks")
+}
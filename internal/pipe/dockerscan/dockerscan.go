@@ -0,0 +1,215 @@
+// Package dockerscan provides a pipe that scans the docker images for
+// vulnerabilities before they are pushed, failing the release if any of them
+// is too severe.
+package dockerscan
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/ids"
+	"github.com/goreleaser/goreleaser/internal/semerrgroup"
+	"github.com/goreleaser/goreleaser/internal/shell"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+)
+
+const (
+	scannerTrivy = "trivy"
+	scannerGrype = "grype"
+
+	defaultSeverity = "high"
+)
+
+// severities, from the least to the most severe.
+var severities = []string{"negligible", "low", "medium", "high", "critical"}
+
+// Pipe that scans docker images.
+type Pipe struct{}
+
+func (Pipe) String() string                 { return "scanning docker images" }
+func (Pipe) Skip(ctx *context.Context) bool { return len(ctx.Config.DockerScans) == 0 }
+
+// Default sets the pipe defaults.
+func (Pipe) Default(ctx *context.Context) error {
+	ids := ids.New("docker_scans")
+	for i := range ctx.Config.DockerScans {
+		cfg := &ctx.Config.DockerScans[i]
+		if cfg.ID == "" {
+			cfg.ID = "default"
+		}
+		if cfg.Scanner == "" {
+			cfg.Scanner = scannerTrivy
+		}
+		if cfg.Scanner != scannerTrivy && cfg.Scanner != scannerGrype {
+			return fmt.Errorf("docker_scans %s: invalid scanner: %s, valid options are [%s %s]", cfg.ID, cfg.Scanner, scannerTrivy, scannerGrype)
+		}
+		if cfg.Severity == "" {
+			cfg.Severity = defaultSeverity
+		}
+		cfg.Severity = strings.ToLower(cfg.Severity)
+		if rank(cfg.Severity) < rank("low") {
+			return fmt.Errorf("docker_scans %s: invalid severity: %s, valid options are [low medium high critical]", cfg.ID, cfg.Severity)
+		}
+		ids.Inc(cfg.ID)
+	}
+	return ids.Validate()
+}
+
+// Run scans the images, and fails if any of them has vulnerabilities as
+// severe as the configured severity, or more.
+func (Pipe) Run(ctx *context.Context) error {
+	var lock sync.Mutex
+	var failures []string
+	g := semerrgroup.New(ctx.Parallelism)
+	for _, cfg := range ctx.Config.DockerScans {
+		cfg := cfg
+		filter := artifact.ByType(artifact.PublishableDockerImage)
+		if len(cfg.IDs) > 0 {
+			filter = artifact.And(filter, artifact.ByIDs(cfg.IDs...))
+		}
+		for _, img := range ctx.Artifacts.Filter(filter).List() {
+			img := img
+			g.Go(func() error {
+				found, err := scan(ctx, cfg, img)
+				if err != nil {
+					return fmt.Errorf("docker_scans %s: %s: %w", cfg.ID, img.Name, err)
+				}
+				if found == 0 {
+					return nil
+				}
+				lock.Lock()
+				defer lock.Unlock()
+				failures = append(failures, fmt.Sprintf("%s: %d vulnerabilities of severity %s or higher", img.Name, found, cfg.Severity))
+				return nil
+			})
+		}
+	}
+	if err := g.Wait(); err != nil {
+		return err
+	}
+	if len(failures) > 0 {
+		sort.Strings(failures)
+		return fmt.Errorf("vulnerable docker images found:\n%s", strings.Join(failures, "\n"))
+	}
+	return nil
+}
+
+// scan scans the given image, writing the report to the dist folder, and
+// returns how many vulnerabilities are at least as severe as the configured
+// severity.
+func scan(ctx *context.Context, cfg config.DockerScan, img *artifact.Artifact) (int, error) {
+	name := reportName(img.Name, cfg.Scanner)
+	path := filepath.Join(ctx.Config.Dist, name)
+	log.FromContext(ctx).WithField("image", img.Name).WithField("scanner", cfg.Scanner).Info("scanning")
+	if err := shell.Run(ctx, "", scanCommand(cfg, img.Name, path), ctx.Env.Strings()); err != nil {
+		return 0, err
+	}
+
+	bts, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	found, err := parseReport(cfg.Scanner, bts)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s report: %w", cfg.Scanner, err)
+	}
+	if cfg.Release {
+		ctx.Artifacts.Add(&artifact.Artifact{
+			Type: artifact.UploadableFile,
+			Name: name,
+			Path: path,
+			Extra: map[string]interface{}{
+				artifact.ExtraID: cfg.ID,
+			},
+		})
+	}
+
+	count := 0
+	for severity, n := range found {
+		if rank(severity) >= rank(cfg.Severity) {
+			count += n
+		}
+	}
+	return count, nil
+}
+
+// scanCommand returns the command that scans the given image, writing a JSON
+// report to the given path.
+func scanCommand(cfg config.DockerScan, image, report string) []string {
+	var cmd []string
+	if cfg.Scanner == scannerGrype {
+		cmd = []string{"grype", image, "--quiet", "--output", "json", "--file", report}
+	} else {
+		cmd = []string{"trivy", "image", "--quiet", "--format", "json", "--output", report}
+	}
+	cmd = append(cmd, cfg.Args...)
+	if cfg.Scanner == scannerTrivy {
+		cmd = append(cmd, image)
+	}
+	return cmd
+}
+
+type trivyReport struct {
+	Results []struct {
+		Vulnerabilities []struct {
+			Severity string `json:"Severity"`
+		} `json:"Vulnerabilities"`
+	} `json:"Results"`
+}
+
+type grypeReport struct {
+	Matches []struct {
+		Vulnerability struct {
+			Severity string `json:"severity"`
+		} `json:"vulnerability"`
+	} `json:"matches"`
+}
+
+// parseReport returns how many vulnerabilities of each severity are in the
+// given report.
+func parseReport(scanner string, bts []byte) (map[string]int, error) {
+	found := map[string]int{}
+	if scanner == scannerGrype {
+		var report grypeReport
+		if err := json.Unmarshal(bts, &report); err != nil {
+			return nil, err
+		}
+		for _, m := range report.Matches {
+			found[strings.ToLower(m.Vulnerability.Severity)]++
+		}
+		return found, nil
+	}
+	var report trivyReport
+	if err := json.Unmarshal(bts, &report); err != nil {
+		return nil, err
+	}
+	for _, r := range report.Results {
+		for _, v := range r.Vulnerabilities {
+			found[strings.ToLower(v.Severity)]++
+		}
+	}
+	return found, nil
+}
+
+// rank returns how severe the given severity is, or -1 if it is unknown.
+func rank(severity string) int {
+	for i, s := range severities {
+		if s == severity {
+			return i
+		}
+	}
+	return -1
+}
+
+// reportName returns the name of the report of the given image.
+func reportName(image, scanner string) string {
+	return strings.NewReplacer("/", "_", ":", "_", "@", "_").Replace(image) + "." + scanner + ".json"
+}
@@ -0,0 +1,203 @@
+package dockerscan
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	vulnerableTrivyReport = `{"Results":[{"Vulnerabilities":[{"Severity":"LOW"},{"Severity":"HIGH"}]},{"Vulnerabilities":[{"Severity":"CRITICAL"}]}]}`
+	vulnerableGrypeReport = `{"matches":[{"vulnerability":{"severity":"Medium"}},{"vulnerability":{"severity":"Negligible"}}]}`
+)
+
+// fakeScanner puts a trivy or grype script in the PATH that logs its
+// arguments and writes the given report to the path it was asked to.
+func fakeScanner(tb testing.TB, scanner, report string) string {
+	tb.Helper()
+	dir := tb.TempDir()
+	calls := filepath.Join(dir, scanner+".log")
+	flag := "--output"
+	if scanner == scannerGrype {
+		flag = "--file"
+	}
+	script := `#!/bin/sh
+echo "$@" >> ` + calls + `
+while [ $# -gt 0 ]; do
+	if [ "$1" = "` + flag + `" ]; then
+		echo '` + report + `' > "$2"
+	fi
+	shift
+done
+`
+	require.NoError(tb, os.WriteFile(filepath.Join(dir, scanner), []byte(script), 0o755))
+	tb.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return calls
+}
+
+func calls(tb testing.TB, path string) []string {
+	tb.Helper()
+	bts, err := os.ReadFile(path)
+	require.NoError(tb, err)
+	return strings.Split(strings.TrimSpace(string(bts)), "\n")
+}
+
+// addImages adds the docker images of the bar and baz IDs.
+func addImages(ctx *context.Context) {
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Type:  artifact.PublishableDockerImage,
+		Name:  "ghcr.io/foo/bar:v1.0.0",
+		Extra: map[string]interface{}{artifact.ExtraID: "bar"},
+	})
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Type:  artifact.PublishableDockerImage,
+		Name:  "ghcr.io/foo/baz:v1.0.0",
+		Extra: map[string]interface{}{artifact.ExtraID: "baz"},
+	})
+}
+
+func TestDescription(t *testing.T) {
+	require.NotEmpty(t, Pipe{}.String())
+}
+
+func TestSkip(t *testing.T) {
+	require.True(t, Pipe{}.Skip(context.New(config.Project{})))
+	require.False(t, Pipe{}.Skip(context.New(config.Project{
+		DockerScans: []config.DockerScan{{}},
+	})))
+}
+
+func TestDefault(t *testing.T) {
+	ctx := context.New(config.Project{
+		DockerScans: []config.DockerScan{{}, {ID: "other", Scanner: "grype", Severity: "CRITICAL"}},
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.Equal(t, []config.DockerScan{
+		{ID: "default", Scanner: "trivy", Severity: "high"},
+		{ID: "other", Scanner: "grype", Severity: "critical"},
+	}, ctx.Config.DockerScans)
+}
+
+func TestDefaultInvalid(t *testing.T) {
+	for name, tt := range map[string]struct {
+		scans []config.DockerScan
+		err   string
+	}{
+		"scanner": {
+			scans: []config.DockerScan{{Scanner: "clair"}},
+			err:   "docker_scans default: invalid scanner: clair, valid options are [trivy grype]",
+		},
+		"severity": {
+			scans: []config.DockerScan{{Severity: "negligible"}},
+			err:   "docker_scans default: invalid severity: negligible, valid options are [low medium high critical]",
+		},
+		"duplicated ids": {
+			scans: []config.DockerScan{{}, {}},
+			err:   "found 2 docker_scans with the ID 'default', please fix your config",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.New(config.Project{DockerScans: tt.scans})
+			require.EqualError(t, Pipe{}.Default(ctx), tt.err)
+		})
+	}
+}
+
+func TestRunTrivyPass(t *testing.T) {
+	log := fakeScanner(t, scannerTrivy, `{"Results":[{"Vulnerabilities":[{"Severity":"LOW"}]}]}`)
+	ctx := context.New(config.Project{
+		Dist:        t.TempDir(),
+		DockerScans: []config.DockerScan{{Args: []string{"--ignore-unfixed"}}},
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	addImages(ctx)
+	require.NoError(t, Pipe{}.Run(ctx))
+
+	got := calls(t, log)
+	require.Len(t, got, 2)
+	require.Contains(t, got, "image --quiet --format json --output "+
+		filepath.Join(ctx.Config.Dist, "ghcr.io_foo_bar_v1.0.0.trivy.json")+
+		" --ignore-unfixed ghcr.io/foo/bar:v1.0.0")
+	require.FileExists(t, filepath.Join(ctx.Config.Dist, "ghcr.io_foo_baz_v1.0.0.trivy.json"))
+	require.Empty(t, ctx.Artifacts.Filter(artifact.ByType(artifact.UploadableFile)).List())
+}
+
+func TestRunTrivyFail(t *testing.T) {
+	fakeScanner(t, scannerTrivy, vulnerableTrivyReport)
+	ctx := context.New(config.Project{
+		Dist:        t.TempDir(),
+		DockerScans: []config.DockerScan{{}},
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	addImages(ctx)
+	require.EqualError(t, Pipe{}.Run(ctx), "vulnerable docker images found:\n"+
+		"ghcr.io/foo/bar:v1.0.0: 2 vulnerabilities of severity high or higher\n"+
+		"ghcr.io/foo/baz:v1.0.0: 2 vulnerabilities of severity high or higher")
+}
+
+func TestRunGrype(t *testing.T) {
+	log := fakeScanner(t, scannerGrype, vulnerableGrypeReport)
+	ctx := context.New(config.Project{
+		Dist:        t.TempDir(),
+		DockerScans: []config.DockerScan{{Scanner: "grype", IDs: []string{"bar"}, Severity: "medium"}},
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	addImages(ctx)
+	require.EqualError(t, Pipe{}.Run(ctx), "vulnerable docker images found:\n"+
+		"ghcr.io/foo/bar:v1.0.0: 1 vulnerabilities of severity medium or higher")
+	require.Equal(t, []string{
+		"ghcr.io/foo/bar:v1.0.0 --quiet --output json --file " +
+			filepath.Join(ctx.Config.Dist, "ghcr.io_foo_bar_v1.0.0.grype.json"),
+	}, calls(t, log))
+}
+
+func TestRunRelease(t *testing.T) {
+	fakeScanner(t, scannerTrivy, `{"Results":[]}`)
+	ctx := context.New(config.Project{
+		Dist:        t.TempDir(),
+		DockerScans: []config.DockerScan{{ID: "scan", IDs: []string{"baz"}, Release: true}},
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	addImages(ctx)
+	require.NoError(t, Pipe{}.Run(ctx))
+
+	reports := ctx.Artifacts.Filter(artifact.ByType(artifact.UploadableFile)).List()
+	require.Len(t, reports, 1)
+	require.Equal(t, "ghcr.io_foo_baz_v1.0.0.trivy.json", reports[0].Name)
+	require.Equal(t, filepath.Join(ctx.Config.Dist, reports[0].Name), reports[0].Path)
+	require.Equal(t, "scan", reports[0].ExtraOr(artifact.ExtraID, ""))
+}
+
+func TestRunInvalidReport(t *testing.T) {
+	fakeScanner(t, scannerTrivy, `not json`)
+	ctx := context.New(config.Project{
+		Dist:        t.TempDir(),
+		DockerScans: []config.DockerScan{{IDs: []string{"bar"}}},
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	addImages(ctx)
+	err := Pipe{}.Run(ctx)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "docker_scans default: ghcr.io/foo/bar:v1.0.0: invalid trivy report")
+}
+
+func TestRunScannerFails(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "trivy"), []byte("#!/bin/sh\nexit 1\n"), 0o755))
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	ctx := context.New(config.Project{
+		Dist:        t.TempDir(),
+		DockerScans: []config.DockerScan{{IDs: []string{"bar"}}},
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	addImages(ctx)
+	err := Pipe{}.Run(ctx)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "docker_scans default: ghcr.io/foo/bar:v1.0.0")
+}
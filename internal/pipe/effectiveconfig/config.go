@@ -9,6 +9,9 @@ import (
 	yaml "gopkg.in/yaml.v2"
 )
 
+// Filename of the effective config file inside the dist folder.
+const Filename = "config.yaml"
+
 // Pipe that writes the effective config file to dist.
 type Pipe struct{}
 
@@ -18,11 +21,11 @@ func (Pipe) String() string {
 
 // Run the pipe.
 func (Pipe) Run(ctx *context.Context) (err error) {
-	path := filepath.Join(ctx.Config.Dist, "config.yaml")
+	path := filepath.Join(ctx.Config.Dist, Filename)
 	bts, err := yaml.Marshal(ctx.Config)
 	if err != nil {
 		return err
 	}
-	log.WithField("config", path).Info("writing")
+	log.FromContext(ctx).WithField("config", path).Info("writing")
 	return os.WriteFile(path, bts, 0o644) //nolint: gosec
 }
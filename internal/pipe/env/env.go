@@ -15,8 +15,8 @@ import (
 	homedir "github.com/mitchellh/go-homedir"
 )
 
-// ErrMissingToken indicates an error when GITHUB_TOKEN, GITLAB_TOKEN and GITEA_TOKEN are all missing in the environment.
-var ErrMissingToken = errors.New("missing GITHUB_TOKEN, GITLAB_TOKEN and GITEA_TOKEN")
+// ErrMissingToken indicates an error when GITHUB_TOKEN, GITLAB_TOKEN, GITEA_TOKEN, BITBUCKET_TOKEN and AZURE_DEVOPS_TOKEN are all missing in the environment.
+var ErrMissingToken = errors.New("missing GITHUB_TOKEN, GITLAB_TOKEN, GITEA_TOKEN, BITBUCKET_TOKEN and AZURE_DEVOPS_TOKEN")
 
 // ErrMultipleTokens indicates that multiple tokens are defined. ATM only one of them if allowed.
 // See https://github.com/goreleaser/goreleaser/pull/809
@@ -46,6 +46,12 @@ func setDefaultTokenFiles(ctx *context.Context) {
 	if env.GiteaToken == "" {
 		env.GiteaToken = "~/.config/goreleaser/gitea_token"
 	}
+	if env.BitbucketToken == "" {
+		env.BitbucketToken = "~/.config/goreleaser/bitbucket_token"
+	}
+	if env.AzureDevOpsToken == "" {
+		env.AzureDevOpsToken = "~/.config/goreleaser/azure_devops_token"
+	}
 }
 
 // Run the pipe.
@@ -67,6 +73,9 @@ func (Pipe) Run(ctx *context.Context) error {
 	githubToken, githubTokenErr := loadEnv("GITHUB_TOKEN", ctx.Config.EnvFiles.GitHubToken)
 	gitlabToken, gitlabTokenErr := loadEnv("GITLAB_TOKEN", ctx.Config.EnvFiles.GitLabToken)
 	giteaToken, giteaTokenErr := loadEnv("GITEA_TOKEN", ctx.Config.EnvFiles.GiteaToken)
+	bitbucketToken, bitbucketTokenErr := loadEnv("BITBUCKET_TOKEN", ctx.Config.EnvFiles.BitbucketToken)
+	azureDevOpsToken, azureDevOpsTokenErr := loadEnv("AZURE_DEVOPS_TOKEN", ctx.Config.EnvFiles.AzureDevOpsToken)
+	azureDevOpsOIDC := azureDevOpsToken == "" && usesAzureDevOpsOIDC(ctx)
 
 	var tokens []string
 	if githubToken != "" {
@@ -78,31 +87,53 @@ func (Pipe) Run(ctx *context.Context) error {
 	if giteaToken != "" {
 		tokens = append(tokens, "GITEA_TOKEN")
 	}
+	if bitbucketToken != "" {
+		tokens = append(tokens, "BITBUCKET_TOKEN")
+	}
+	if azureDevOpsToken != "" {
+		tokens = append(tokens, "AZURE_DEVOPS_TOKEN")
+	}
+	if azureDevOpsOIDC {
+		tokens = append(tokens, "AZURE_CLIENT_ID")
+	}
 	if len(tokens) > 1 {
 		return ErrMultipleTokens{tokens}
 	}
 
-	noTokens := githubToken == "" && gitlabToken == "" && giteaToken == ""
-	noTokenErrs := githubTokenErr == nil && gitlabTokenErr == nil && giteaTokenErr == nil
+	noTokens := githubToken == "" && gitlabToken == "" && giteaToken == "" && bitbucketToken == "" && azureDevOpsToken == "" && !azureDevOpsOIDC
+	noTokenErrs := githubTokenErr == nil && gitlabTokenErr == nil && giteaTokenErr == nil && bitbucketTokenErr == nil && azureDevOpsTokenErr == nil
 
-	if err := checkErrors(ctx, noTokens, noTokenErrs, gitlabTokenErr, githubTokenErr, giteaTokenErr); err != nil {
+	if err := checkErrors(ctx, noTokens, noTokenErrs, gitlabTokenErr, githubTokenErr, giteaTokenErr, bitbucketTokenErr, azureDevOpsTokenErr); err != nil {
 		return err
 	}
 
 	if gitlabToken != "" {
-		log.Debug("token type: gitlab")
+		log.FromContext(ctx).Debug("token type: gitlab")
 		ctx.TokenType = context.TokenTypeGitLab
 		ctx.Token = gitlabToken
 	}
 
 	if giteaToken != "" {
-		log.Debug("token type: gitea")
+		log.FromContext(ctx).Debug("token type: gitea")
 		ctx.TokenType = context.TokenTypeGitea
 		ctx.Token = giteaToken
 	}
 
+	if bitbucketToken != "" {
+		log.FromContext(ctx).Debug("token type: bitbucket")
+		ctx.TokenType = context.TokenTypeBitbucket
+		ctx.Token = bitbucketToken
+	}
+
+	if azureDevOpsToken != "" || azureDevOpsOIDC {
+		log.FromContext(ctx).Debug("token type: azure devops")
+		ctx.TokenType = context.TokenTypeAzureDevOps
+		// empty when using a federated credential, exchanged by the client.
+		ctx.Token = azureDevOpsToken
+	}
+
 	if githubToken != "" {
-		log.Debug("token type: github")
+		log.FromContext(ctx).Debug("token type: github")
 		ctx.Token = githubToken
 	}
 
@@ -113,7 +144,7 @@ func (Pipe) Run(ctx *context.Context) error {
 	return nil
 }
 
-func checkErrors(ctx *context.Context, noTokens, noTokenErrs bool, gitlabTokenErr, githubTokenErr, giteaTokenErr error) error {
+func checkErrors(ctx *context.Context, noTokens, noTokenErrs bool, gitlabTokenErr, githubTokenErr, giteaTokenErr, bitbucketTokenErr, azureDevOpsTokenErr error) error {
 	if ctx.SkipTokenCheck || ctx.SkipPublish || ctx.Config.Release.Disable {
 		return nil
 	}
@@ -133,9 +164,25 @@ func checkErrors(ctx *context.Context, noTokens, noTokenErrs bool, gitlabTokenEr
 	if giteaTokenErr != nil {
 		return fmt.Errorf("failed to load gitea token: %w", giteaTokenErr)
 	}
+
+	if bitbucketTokenErr != nil {
+		return fmt.Errorf("failed to load bitbucket token: %w", bitbucketTokenErr)
+	}
+
+	if azureDevOpsTokenErr != nil {
+		return fmt.Errorf("failed to load azure devops token: %w", azureDevOpsTokenErr)
+	}
 	return nil
 }
 
+// usesAzureDevOpsOIDC tells whether the release goes to Azure DevOps with the
+// federated credential of an Entra ID application instead of a token.
+func usesAzureDevOpsOIDC(ctx *context.Context) bool {
+	return ctx.Config.Release.AzureDevOps.Name != "" &&
+		ctx.Env["AZURE_CLIENT_ID"] != "" &&
+		ctx.Env["AZURE_TENANT_ID"] != ""
+}
+
 func loadEnv(env, path string) (string, error) {
 	val := os.Getenv(env)
 	if val != "" {
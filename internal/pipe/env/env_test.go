@@ -21,6 +21,8 @@ func TestSetDefaultTokenFiles(t *testing.T) {
 		require.Equal(t, "~/.config/goreleaser/github_token", ctx.Config.EnvFiles.GitHubToken)
 		require.Equal(t, "~/.config/goreleaser/gitlab_token", ctx.Config.EnvFiles.GitLabToken)
 		require.Equal(t, "~/.config/goreleaser/gitea_token", ctx.Config.EnvFiles.GiteaToken)
+		require.Equal(t, "~/.config/goreleaser/bitbucket_token", ctx.Config.EnvFiles.BitbucketToken)
+		require.Equal(t, "~/.config/goreleaser/azure_devops_token", ctx.Config.EnvFiles.AzureDevOpsToken)
 	})
 	t.Run("custom config config", func(t *testing.T) {
 		cfg := "what"
@@ -102,6 +104,64 @@ func TestValidGiteaEnv(t *testing.T) {
 	require.NoError(t, os.Unsetenv("GITEA_TOKEN"))
 }
 
+func TestValidBitbucketEnv(t *testing.T) {
+	require.NoError(t, os.Setenv("BITBUCKET_TOKEN", "user:password"))
+	ctx := &context.Context{
+		Config: config.Project{},
+	}
+	require.NoError(t, Pipe{}.Run(ctx))
+	require.Equal(t, "user:password", ctx.Token)
+	require.Equal(t, context.TokenTypeBitbucket, ctx.TokenType)
+	// so the tests do not depend on each other
+	require.NoError(t, os.Unsetenv("BITBUCKET_TOKEN"))
+}
+
+func TestValidAzureDevOpsEnv(t *testing.T) {
+	require.NoError(t, os.Setenv("AZURE_DEVOPS_TOKEN", "pat"))
+	ctx := &context.Context{
+		Config: config.Project{},
+	}
+	require.NoError(t, Pipe{}.Run(ctx))
+	require.Equal(t, "pat", ctx.Token)
+	require.Equal(t, context.TokenTypeAzureDevOps, ctx.TokenType)
+	// so the tests do not depend on each other
+	require.NoError(t, os.Unsetenv("AZURE_DEVOPS_TOKEN"))
+}
+
+func TestAzureDevOpsFederatedCredential(t *testing.T) {
+	t.Run("release to azure devops", func(t *testing.T) {
+		ctx := context.New(config.Project{
+			Release: config.Release{
+				AzureDevOps: config.Repo{Owner: "org/project", Name: "repo"},
+			},
+		})
+		ctx.Env["AZURE_CLIENT_ID"] = "client"
+		ctx.Env["AZURE_TENANT_ID"] = "tenant"
+		require.NoError(t, Pipe{}.Run(ctx))
+		require.Empty(t, ctx.Token)
+		require.Equal(t, context.TokenTypeAzureDevOps, ctx.TokenType)
+	})
+
+	t.Run("release elsewhere", func(t *testing.T) {
+		ctx := context.New(config.Project{})
+		ctx.Env["AZURE_CLIENT_ID"] = "client"
+		ctx.Env["AZURE_TENANT_ID"] = "tenant"
+		require.EqualError(t, Pipe{}.Run(ctx), ErrMissingToken.Error())
+	})
+
+	t.Run("with another token", func(t *testing.T) {
+		t.Setenv("GITHUB_TOKEN", "asdf")
+		ctx := context.New(config.Project{
+			Release: config.Release{
+				AzureDevOps: config.Repo{Owner: "org/project", Name: "repo"},
+			},
+		})
+		ctx.Env["AZURE_CLIENT_ID"] = "client"
+		ctx.Env["AZURE_TENANT_ID"] = "tenant"
+		require.EqualError(t, Pipe{}.Run(ctx), ErrMultipleTokens{[]string{"GITHUB_TOKEN", "AZURE_CLIENT_ID"}}.Error())
+	})
+}
+
 func TestInvalidEnv(t *testing.T) {
 	require.NoError(t, os.Unsetenv("GITHUB_TOKEN"))
 	require.NoError(t, os.Unsetenv("GITLAB_TOKEN"))
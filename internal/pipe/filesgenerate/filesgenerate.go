@@ -0,0 +1,129 @@
+// Package filesgenerate provides a pipe that renders templates to files, like
+// version headers or install manifests, so they can be archived and released
+// along with the binaries.
+package filesgenerate
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/ids"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+)
+
+const defaultMode = 0o644
+
+// Pipe that generates files from templates.
+type Pipe struct{}
+
+func (Pipe) String() string                 { return "generating files" }
+func (Pipe) Skip(ctx *context.Context) bool { return len(ctx.Config.FilesGenerate) == 0 }
+
+// Default sets the pipe defaults.
+func (Pipe) Default(ctx *context.Context) error {
+	ids := ids.New("files_generate")
+	for i := range ctx.Config.FilesGenerate {
+		cfg := &ctx.Config.FilesGenerate[i]
+		if cfg.ID == "" {
+			cfg.ID = "default"
+		}
+		if (cfg.Src == "") == (cfg.Contents == "") {
+			return fmt.Errorf("files_generate %s: either src or contents is required", cfg.ID)
+		}
+		if cfg.Dst == "" {
+			if cfg.Src == "" {
+				return fmt.Errorf("files_generate %s: dst is required", cfg.ID)
+			}
+			cfg.Dst = defaultDst(cfg.Src)
+		}
+		if cfg.Mode == 0 {
+			cfg.Mode = defaultMode
+		}
+		ids.Inc(cfg.ID)
+	}
+	return ids.Validate()
+}
+
+// defaultDst returns the name of the file rendered from the given template,
+// without its .tpl or .tmpl extension.
+func defaultDst(src string) string {
+	name := filepath.Base(src)
+	for _, ext := range []string{".tpl", ".tmpl"} {
+		name = strings.TrimSuffix(name, ext)
+	}
+	return name
+}
+
+// Run the pipe.
+func (Pipe) Run(ctx *context.Context) error {
+	for _, cfg := range ctx.Config.FilesGenerate {
+		if err := generate(ctx, cfg); err != nil {
+			return fmt.Errorf("files_generate %s: %w", cfg.ID, err)
+		}
+	}
+	return nil
+}
+
+func generate(ctx *context.Context, cfg config.FileGenerate) error {
+	t := tmpl.New(ctx)
+	contents := cfg.Contents
+	if cfg.Src != "" {
+		src, err := t.Apply(cfg.Src)
+		if err != nil {
+			return err
+		}
+		bts, err := os.ReadFile(src)
+		if err != nil {
+			return err
+		}
+		contents = string(bts)
+	}
+	out, err := t.Apply(contents)
+	if err != nil {
+		return err
+	}
+	dst, err := t.Apply(cfg.Dst)
+	if err != nil {
+		return err
+	}
+	if dst == "" {
+		return errors.New("dst evaluated to an empty path")
+	}
+
+	path := dst
+	if !cfg.Repo {
+		path = filepath.Join(ctx.Config.Dist, dst)
+	}
+	log.FromContext(ctx).WithField("file", path).Info("generating")
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, []byte(out), cfg.Mode); err != nil {
+		return err
+	}
+	// WriteFile only sets the mode of new files.
+	if err := os.Chmod(path, cfg.Mode); err != nil {
+		return err
+	}
+
+	typ := artifact.GeneratedFile
+	if cfg.Release {
+		typ = artifact.UploadableFile
+	}
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Type: typ,
+		Name: filepath.Base(path),
+		Path: path,
+		Extra: map[string]interface{}{
+			artifact.ExtraID: cfg.ID,
+		},
+	})
+	return nil
+}
@@ -0,0 +1,156 @@
+package filesgenerate
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDescription(t *testing.T) {
+	require.NotEmpty(t, Pipe{}.String())
+}
+
+func TestSkip(t *testing.T) {
+	require.True(t, Pipe{}.Skip(context.New(config.Project{})))
+	require.False(t, Pipe{}.Skip(context.New(config.Project{
+		FilesGenerate: []config.FileGenerate{{}},
+	})))
+}
+
+func TestDefault(t *testing.T) {
+	ctx := context.New(config.Project{
+		FilesGenerate: []config.FileGenerate{
+			{Src: "deploy/k8s.yaml.tpl"},
+			{ID: "version", Contents: "{{ .Version }}", Dst: "VERSION"},
+		},
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.Equal(t, []config.FileGenerate{
+		{ID: "default", Src: "deploy/k8s.yaml.tpl", Dst: "k8s.yaml", Mode: 0o644},
+		{ID: "version", Contents: "{{ .Version }}", Dst: "VERSION", Mode: 0o644},
+	}, ctx.Config.FilesGenerate)
+}
+
+func TestDefaultErrors(t *testing.T) {
+	for name, tt := range map[string]struct {
+		cfg config.FileGenerate
+		err string
+	}{
+		"no src nor contents": {
+			cfg: config.FileGenerate{Dst: "foo"},
+			err: "files_generate default: either src or contents is required",
+		},
+		"src and contents": {
+			cfg: config.FileGenerate{Src: "foo", Contents: "bar"},
+			err: "files_generate default: either src or contents is required",
+		},
+		"no dst": {
+			cfg: config.FileGenerate{Contents: "bar"},
+			err: "files_generate default: dst is required",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			require.EqualError(t, Pipe{}.Default(context.New(config.Project{
+				FilesGenerate: []config.FileGenerate{tt.cfg},
+			})), tt.err)
+		})
+	}
+}
+
+func TestDefaultDuplicatedIDs(t *testing.T) {
+	require.Error(t, Pipe{}.Default(context.New(config.Project{
+		FilesGenerate: []config.FileGenerate{
+			{Contents: "a", Dst: "a"},
+			{Contents: "b", Dst: "b"},
+		},
+	})))
+}
+
+func TestRun(t *testing.T) {
+	dist := t.TempDir()
+	repo := t.TempDir()
+	src := filepath.Join(t.TempDir(), "install.sh.tpl")
+	require.NoError(t, os.WriteFile(src, []byte("#!/bin/sh\necho {{ .ProjectName }} {{ .Version }}\n"), 0o644))
+
+	ctx := context.New(config.Project{
+		ProjectName: "foo",
+		Dist:        dist,
+		FilesGenerate: []config.FileGenerate{
+			{ID: "install", Src: src, Mode: 0o755, Release: true},
+			{ID: "header", Contents: "#define VERSION \"{{ .Version }}\"\n", Dst: filepath.Join(repo, "include", "version.h"), Repo: true},
+			{ID: "manifest", Contents: "image: foo:{{ .Tag }}\n", Dst: "deploy/{{ .ProjectName }}.yaml"},
+		},
+	})
+	ctx.Git.CurrentTag = "v1.2.3"
+	ctx.Version = "1.2.3"
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.NoError(t, Pipe{}.Run(ctx))
+
+	install := filepath.Join(dist, "install.sh")
+	bts, err := os.ReadFile(install)
+	require.NoError(t, err)
+	require.Equal(t, "#!/bin/sh\necho foo 1.2.3\n", string(bts))
+	info, err := os.Stat(install)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0o755), info.Mode().Perm())
+
+	header := filepath.Join(repo, "include", "version.h")
+	bts, err = os.ReadFile(header)
+	require.NoError(t, err)
+	require.Equal(t, "#define VERSION \"1.2.3\"\n", string(bts))
+
+	manifest := filepath.Join(dist, "deploy", "foo.yaml")
+	bts, err = os.ReadFile(manifest)
+	require.NoError(t, err)
+	require.Equal(t, "image: foo:v1.2.3\n", string(bts))
+
+	uploadable := ctx.Artifacts.Filter(artifact.ByType(artifact.UploadableFile)).List()
+	require.Len(t, uploadable, 1)
+	require.Equal(t, "install.sh", uploadable[0].Name)
+	require.Equal(t, install, uploadable[0].Path)
+	require.Equal(t, "install", uploadable[0].ID())
+
+	generated := ctx.Artifacts.Filter(artifact.ByType(artifact.GeneratedFile)).List()
+	require.Len(t, generated, 2)
+	paths := []string{generated[0].Path, generated[1].Path}
+	require.ElementsMatch(t, []string{header, manifest}, paths)
+}
+
+func TestRunErrors(t *testing.T) {
+	for name, tt := range map[string]struct {
+		cfg config.FileGenerate
+		err string
+	}{
+		"missing src": {
+			cfg: config.FileGenerate{Src: "testdata/nope.tpl", Dst: "nope", Mode: 0o644},
+			err: "files_generate default: open testdata/nope.tpl: no such file or directory",
+		},
+		"invalid contents": {
+			cfg: config.FileGenerate{Contents: "{{ .Nope }", Dst: "nope", Mode: 0o644},
+			err: `files_generate default: template: tmpl:1: unexpected "}" in operand`,
+		},
+		"invalid dst": {
+			cfg: config.FileGenerate{Contents: "foo", Dst: "{{ .Nope }", Mode: 0o644},
+			err: `files_generate default: template: tmpl:1: unexpected "}" in operand`,
+		},
+		"empty dst": {
+			cfg: config.FileGenerate{Contents: "foo", Dst: "{{ .Env.NOPE }}", Mode: 0o644},
+			err: "files_generate default: dst evaluated to an empty path",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			tt.cfg.ID = "default"
+			ctx := context.New(config.Project{
+				Dist:          t.TempDir(),
+				FilesGenerate: []config.FileGenerate{tt.cfg},
+			})
+			ctx.Env["NOPE"] = ""
+			require.EqualError(t, Pipe{}.Run(ctx), tt.err)
+		})
+	}
+}
@@ -38,6 +38,9 @@ func TestSingleCommit(t *testing.T) {
 	require.Equal(t, "v0.0.1", ctx.Git.Summary)
 	require.Equal(t, "commit1", ctx.Git.TagSubject)
 	require.Equal(t, "commit1", ctx.Git.TagContents)
+	require.Empty(t, ctx.Git.TagBody)
+	require.Empty(t, ctx.Git.TagTagger)
+	require.Equal(t, ctx.Git.CommitDate, ctx.Git.TagDate)
 }
 
 func TestAnnotatedTags(t *testing.T) {
@@ -53,6 +56,10 @@ func TestAnnotatedTags(t *testing.T) {
 	require.Equal(t, "v0.0.1", ctx.Git.CurrentTag)
 	require.Equal(t, "first version", ctx.Git.TagSubject)
 	require.Equal(t, "first version\n\nlalalla\nlalal\nlah", ctx.Git.TagContents)
+	require.Equal(t, "lalalla\nlalal\nlah", ctx.Git.TagBody)
+	require.Contains(t, ctx.Git.TagTagger, "GoReleaser")
+	require.Contains(t, ctx.Git.TagTagger, "test@goreleaser.github.com")
+	require.False(t, ctx.Git.TagDate.IsZero())
 	require.Equal(t, "v0.0.1", ctx.Git.Summary)
 }
 
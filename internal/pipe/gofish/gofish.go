@@ -45,6 +45,9 @@ func (Pipe) Default(ctx *context.Context) error {
 		if goFish.Name == "" {
 			goFish.Name = ctx.Config.ProjectName
 		}
+		if err := commitauthor.CheckSigning(ctx, goFish.CommitAuthor); err != nil {
+			return fmt.Errorf("rig %s: %w", goFish.Name, err)
+		}
 		if goFish.Goarm == "" {
 			goFish.Goarm = "6"
 		}
@@ -97,6 +100,7 @@ func doRun(ctx *context.Context, goFish config.GoFish, cl client.Client) error {
 			artifact.ByType(artifact.UploadableBinary),
 		),
 		artifact.OnlyReplacingUnibins,
+		artifact.OnlyUnencrypted,
 	}
 	if len(goFish.IDs) > 0 {
 		filters = append(filters, artifact.ByIDs(goFish.IDs...))
@@ -120,7 +124,7 @@ func doRun(ctx *context.Context, goFish config.GoFish, cl client.Client) error {
 
 	filename := goFish.Name + ".lua"
 	luaPath := filepath.Join(ctx.Config.Dist, filename)
-	log.WithField("food", luaPath).Info("writing")
+	log.FromContext(ctx).WithField("food", luaPath).Info("writing")
 	if err := os.WriteFile(luaPath, []byte(content), 0o644); err != nil { //nolint: gosec
 		return fmt.Errorf("failed to write gofish food: %w", err)
 	}
@@ -284,7 +288,7 @@ func doPublish(ctx *context.Context, food *artifact.Artifact, cl client.Client)
 	repo := client.RepoFromRef(rig.Rig)
 
 	gpath := buildFoodPath(foodFolder, food.Name)
-	log.WithField("food", gpath).
+	log.FromContext(ctx).WithField("food", gpath).
 		WithField("repo", repo.String()).
 		Info("pushing")
 
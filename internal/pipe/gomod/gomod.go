@@ -24,11 +24,23 @@ func (Pipe) Default(ctx *context.Context) error {
 	if ctx.Config.GoMod.GoBinary == "" {
 		ctx.Config.GoMod.GoBinary = "go"
 	}
-	return nil
+	if ctx.Config.GoMod.VersionCheck == "" {
+		ctx.Config.GoMod.VersionCheck = versionCheckError
+	}
+	switch ctx.Config.GoMod.VersionCheck {
+	case versionCheckError, versionCheckWarn, versionCheckSkip:
+		return nil
+	default:
+		return fmt.Errorf("invalid gomod.version_check: %q", ctx.Config.GoMod.VersionCheck)
+	}
 }
 
 // Run the pipe.
 func (Pipe) Run(ctx *context.Context) error {
+	if err := checkGoVersion(ctx); err != nil {
+		return err
+	}
+
 	out, err := exec.CommandContext(ctx, ctx.Config.GoMod.GoBinary, "list", "-m").CombinedOutput()
 	result := strings.TrimSpace(string(out))
 	if result == go115NotAGoModuleError || result == go116NotAGoModuleError {
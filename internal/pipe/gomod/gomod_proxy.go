@@ -87,7 +87,7 @@ func proxyBuild(ctx *context.Context, build *config.Build) error {
 	mainPackage := path.Join(ctx.ModulePath, build.Main)
 	if strings.HasSuffix(build.Main, ".go") {
 		pkg := path.Dir(build.Main)
-		log.Warnf("guessing package of '%s' to be '%s', if this is incorrect, setup 'build.%s.main' to be the correct package", build.Main, pkg, build.ID)
+		log.FromContext(ctx).Warnf("guessing package of '%s' to be '%s', if this is incorrect, setup 'build.%s.main' to be the correct package", build.Main, pkg, build.ID)
 		mainPackage = path.Join(ctx.ModulePath, pkg)
 	}
 	template := tmpl.New(ctx).WithExtraFields(tmpl.Fields{
@@ -95,7 +95,7 @@ func proxyBuild(ctx *context.Context, build *config.Build) error {
 		"BuildID": build.ID,
 	})
 
-	log.Infof("proxying %s@%s to build %s", ctx.ModulePath, ctx.Git.CurrentTag, mainPackage)
+	log.FromContext(ctx).Infof("proxying %s@%s to build %s", ctx.ModulePath, ctx.Git.CurrentTag, mainPackage)
 
 	mod, err := template.Apply(goModTpl)
 	if err != nil {
@@ -109,7 +109,7 @@ func proxyBuild(ctx *context.Context, build *config.Build) error {
 
 	dir := filepath.Join(ctx.Config.Dist, "proxy", build.ID)
 
-	log.Debugf("creating needed files")
+	log.FromContext(ctx).Debugf("creating needed files")
 
 	if err := os.MkdirAll(dir, 0o755); err != nil {
 		return newErrProxy(err)
@@ -127,7 +127,7 @@ func proxyBuild(ctx *context.Context, build *config.Build) error {
 		return newErrProxy(err)
 	}
 
-	log.Debugf("tidying")
+	log.FromContext(ctx).Debugf("tidying")
 	cmd := exec.CommandContext(ctx, ctx.Config.GoMod.GoBinary, "mod", "tidy")
 	cmd.Dir = dir
 	cmd.Env = append(ctx.Config.GoMod.Env, os.Environ()...)
@@ -37,6 +37,79 @@ func TestRunCommandError(t *testing.T) {
 	require.Empty(t, ctx.ModulePath)
 }
 
+func TestRunGoVersionTooOld(t *testing.T) {
+	t.Setenv("GOTOOLCHAIN", "local")
+	dir := testlib.Mktmp(t)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module foo\n\ngo 1.999\n"), 0o666))
+
+	t.Run("error", func(t *testing.T) {
+		ctx := context.New(config.Project{})
+		require.NoError(t, Pipe{}.Default(ctx))
+		err := Pipe{}.Run(ctx)
+		require.Error(t, err)
+		require.ErrorAs(t, err, &ErrGoVersion{})
+		require.Contains(t, err.Error(), "go.mod requires go >= 1.999")
+	})
+
+	t.Run("skip", func(t *testing.T) {
+		ctx := context.New(config.Project{
+			GoMod: config.GoMod{
+				VersionCheck: "skip",
+			},
+		})
+		require.NoError(t, Pipe{}.Default(ctx))
+		require.NoError(t, checkGoVersion(ctx))
+	})
+
+	t.Run("warn", func(t *testing.T) {
+		ctx := context.New(config.Project{
+			GoMod: config.GoMod{
+				VersionCheck: "warn",
+			},
+		})
+		require.NoError(t, Pipe{}.Default(ctx))
+		require.NoError(t, checkGoVersion(ctx))
+	})
+}
+
+func TestDefaultInvalidVersionCheck(t *testing.T) {
+	ctx := context.New(config.Project{
+		GoMod: config.GoMod{
+			VersionCheck: "nope",
+		},
+	})
+	require.EqualError(t, Pipe{}.Default(ctx), `invalid gomod.version_check: "nope"`)
+}
+
+func TestParseGoMod(t *testing.T) {
+	goVersion, toolchain := parseGoMod([]byte("module foo\n\ngo 1.21\n\ntoolchain go1.21.3\n\nrequire (\n\tgo.foo/bar v1.0.0\n)\n"))
+	require.Equal(t, "1.21", goVersion)
+	require.Equal(t, "1.21.3", toolchain)
+}
+
+func TestOlderThan(t *testing.T) {
+	for current, required := range map[string]string{
+		"go1.16":     "1.17",
+		"go1.17.2":   "1.17.3",
+		"go1.21rc1":  "1.22",
+		"go1.9":      "1.10",
+		"1.20.14":    "1.21.0",
+		"go1.17.13 ": "1.18",
+	} {
+		require.True(t, olderThan(current, required), current+" < "+required)
+	}
+	for current, required := range map[string]string{
+		"go1.17":                   "1.17",
+		"go1.18":                   "1.17",
+		"go1.21.1":                 "1.21",
+		"go1.10":                   "1.9",
+		"devel go1.22-2fc63e2eb6f": "1.21",
+		"go1.16":                   "invalid",
+	} {
+		require.False(t, olderThan(current, required), current+" >= "+required)
+	}
+}
+
 func TestDescription(t *testing.T) {
 	require.NotEmpty(t, Pipe{}.String())
 }
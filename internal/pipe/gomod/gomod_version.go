@@ -0,0 +1,141 @@
+package gomod
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/pkg/context"
+)
+
+const (
+	versionCheckError = "error"
+	versionCheckWarn  = "warn"
+	versionCheckSkip  = "skip"
+)
+
+// ErrGoVersion happens when the Go binary being used is older than the
+// version required by the go.mod file.
+type ErrGoVersion struct {
+	required string
+	current  string
+}
+
+func (e ErrGoVersion) Error() string {
+	return fmt.Sprintf(
+		"go.mod requires go >= %s, but the go binary being used is %s: update your go binary or set gomod.gobinary accordingly",
+		e.required,
+		e.current,
+	)
+}
+
+// checkGoVersion compares the go and toolchain directives of the go.mod file
+// in the current directory against the version of the go binary in use.
+func checkGoVersion(ctx *context.Context) error {
+	if ctx.Config.GoMod.VersionCheck == versionCheckSkip {
+		return nil
+	}
+
+	bts, err := os.ReadFile("go.mod")
+	if err != nil {
+		log.FromContext(ctx).WithError(err).Debug("could not read go.mod, skipping go version check")
+		return nil
+	}
+	required, toolchain := parseGoMod(bts)
+	if required == "" {
+		return nil
+	}
+
+	out, err := exec.CommandContext(ctx, ctx.Config.GoMod.GoBinary, "env", "GOVERSION").Output()
+	if err != nil {
+		log.FromContext(ctx).WithError(err).Debug("could not get go version, skipping go version check")
+		return nil
+	}
+	current := strings.TrimSpace(string(out))
+	log.FromContext(ctx).WithField("go", current).
+		WithField("required", required).
+		WithField("toolchain", toolchain).
+		Debug("checking go version")
+
+	if olderThan(current, required) {
+		err := ErrGoVersion{required: required, current: current}
+		if ctx.Config.GoMod.VersionCheck == versionCheckWarn {
+			log.FromContext(ctx).Warn(err.Error())
+			return nil
+		}
+		return err
+	}
+
+	if toolchain != "" && olderThan(current, toolchain) {
+		log.FromContext(ctx).Warnf("go.mod suggests the %s toolchain, but the go binary being used is %s", toolchain, current)
+	}
+	return nil
+}
+
+// parseGoMod returns the values of the go and toolchain directives of the
+// given go.mod contents.
+func parseGoMod(bts []byte) (goVersion, toolchain string) {
+	scanner := bufio.NewScanner(bytes.NewReader(bts))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		switch fields[0] {
+		case "go":
+			goVersion = fields[1]
+		case "toolchain":
+			toolchain = strings.TrimPrefix(fields[1], "go")
+		}
+	}
+	return
+}
+
+// olderThan returns true if the current version is older than the required
+// one. Versions that can't be parsed (e.g. devel builds) are never considered
+// older.
+func olderThan(current, required string) bool {
+	cv, ok := parseGoVersion(current)
+	if !ok {
+		return false
+	}
+	rv, ok := parseGoVersion(required)
+	if !ok {
+		return false
+	}
+	for i := range cv {
+		if cv[i] != rv[i] {
+			return cv[i] < rv[i]
+		}
+	}
+	return false
+}
+
+// parseGoVersion parses versions like go1.17, 1.21.3 and go1.22rc1 into their
+// major, minor and patch numbers.
+func parseGoVersion(s string) ([3]int, bool) {
+	var result [3]int
+	s = strings.TrimPrefix(strings.TrimSpace(s), "go")
+	if idx := strings.IndexFunc(s, func(r rune) bool {
+		return r != '.' && (r < '0' || r > '9')
+	}); idx >= 0 {
+		s = s[:idx]
+	}
+	parts := strings.Split(s, ".")
+	if len(parts) < 2 || len(parts) > 3 {
+		return result, false
+	}
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			return result, false
+		}
+		result[i] = n
+	}
+	return result, true
+}
@@ -0,0 +1,313 @@
+// Package ko provides a pipe that builds container images of Go binaries with
+// ko, without a Dockerfile or a docker daemon, and pushes them straight to
+// their registries.
+package ko
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/gio"
+	"github.com/goreleaser/goreleaser/internal/ids"
+	"github.com/goreleaser/goreleaser/internal/logext"
+	"github.com/goreleaser/goreleaser/internal/semerrgroup"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	defaultBaseImage = "cgr.dev/chainguard/static"
+	defaultPlatform  = "linux/amd64"
+	defaultTag       = "latest"
+	defaultSBOM      = "spdx"
+
+	digestExtra = "Digest"
+)
+
+// Pipe that builds and publishes images with ko.
+type Pipe struct{}
+
+func (Pipe) String() string                 { return "ko" }
+func (Pipe) Skip(ctx *context.Context) bool { return len(ctx.Config.Kos) == 0 }
+
+// Default sets the pipe defaults.
+func (Pipe) Default(ctx *context.Context) error {
+	ids := ids.New("kos")
+	for i := range ctx.Config.Kos {
+		ko := &ctx.Config.Kos[i]
+		if ko.ID == "" {
+			ko.ID = ctx.Config.ProjectName
+		}
+		if err := setBuildDefaults(ctx, ko); err != nil {
+			return fmt.Errorf("ko %s: %w", ko.ID, err)
+		}
+		if ko.Main == "" {
+			ko.Main = "."
+		}
+		if ko.WorkingDir == "" {
+			ko.WorkingDir = "."
+		}
+		if ko.BaseImage == "" {
+			ko.BaseImage = defaultBaseImage
+		}
+		if ko.Repository == "" {
+			ko.Repository = ctx.Env["KO_DOCKER_REPO"]
+		}
+		if ko.Repository == "" {
+			return fmt.Errorf("ko %s: repository is required", ko.ID)
+		}
+		if len(ko.Platforms) == 0 {
+			ko.Platforms = []string{defaultPlatform}
+		}
+		if len(ko.Tags) == 0 {
+			ko.Tags = []string{defaultTag}
+		}
+		if ko.SBOM == "" {
+			ko.SBOM = defaultSBOM
+		}
+		switch ko.SBOM {
+		case "spdx", "none":
+		default:
+			return fmt.Errorf("ko %s: invalid sbom: %s, valid options are spdx and none", ko.ID, ko.SBOM)
+		}
+		if countTrue(ko.Bare, ko.PreserveImportPaths, ko.BaseImportPaths) > 1 {
+			return fmt.Errorf("ko %s: bare, preserve_import_paths and base_import_paths are mutually exclusive", ko.ID)
+		}
+		ids.Inc(ko.ID)
+	}
+	return ids.Validate()
+}
+
+// setBuildDefaults uses the settings of the build the image is about for the
+// ones the ko config doesn't set, so the binary in the image is built the same
+// way as the released ones.
+func setBuildDefaults(ctx *context.Context, ko *config.Ko) error {
+	if ko.Build == "" {
+		if len(ctx.Config.Builds) == 0 {
+			return nil
+		}
+		ko.Build = ctx.Config.Builds[0].ID
+	}
+	for _, build := range ctx.Config.Builds {
+		if build.ID != ko.Build {
+			continue
+		}
+		if ko.Main == "" {
+			ko.Main = build.Main
+		}
+		if ko.WorkingDir == "" {
+			ko.WorkingDir = build.Dir
+		}
+		if len(ko.Ldflags) == 0 {
+			ko.Ldflags = build.Ldflags
+		}
+		if len(ko.Flags) == 0 {
+			ko.Flags = build.Flags
+		}
+		if len(ko.Env) == 0 {
+			ko.Env = build.Env
+		}
+		return nil
+	}
+	return fmt.Errorf("build %s not found", ko.Build)
+}
+
+func countTrue(bs ...bool) int {
+	n := 0
+	for _, b := range bs {
+		if b {
+			n++
+		}
+	}
+	return n
+}
+
+// Publish builds and pushes the images.
+func (Pipe) Publish(ctx *context.Context) error {
+	g := semerrgroup.New(ctx.Parallelism)
+	for _, ko := range ctx.Config.Kos {
+		ko := ko
+		g.Go(func() error {
+			if err := doBuild(ctx, ko); err != nil {
+				return fmt.Errorf("ko %s: %w", ko.ID, err)
+			}
+			return nil
+		})
+	}
+	return g.Wait()
+}
+
+// koConfig is the subset of the .ko.yaml configuration used to build an
+// image.
+type koConfig struct {
+	DefaultBaseImage string    `yaml:"defaultBaseImage"`
+	Builds           []koBuild `yaml:"builds"`
+}
+
+type koBuild struct {
+	ID      string   `yaml:"id"`
+	Main    string   `yaml:"main"`
+	Env     []string `yaml:"env,omitempty"`
+	Flags   []string `yaml:"flags,omitempty"`
+	Ldflags []string `yaml:"ldflags,omitempty"`
+}
+
+func doBuild(ctx *context.Context, ko config.Ko) error {
+	t := tmpl.New(ctx)
+	repository, err := t.Apply(ko.Repository)
+	if err != nil {
+		return err
+	}
+	baseImage, err := t.Apply(ko.BaseImage)
+	if err != nil {
+		return err
+	}
+	tags, err := applyAll(t, ko.Tags)
+	if err != nil {
+		return err
+	}
+	ldflags, err := applyAll(t, ko.Ldflags)
+	if err != nil {
+		return err
+	}
+	flags, err := applyAll(t, ko.Flags)
+	if err != nil {
+		return err
+	}
+	env, err := applyAll(t, ko.Env)
+	if err != nil {
+		return err
+	}
+
+	dir, err := filepath.Abs(filepath.Join(ctx.Config.Dist, "ko", ko.ID))
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	bts, err := yaml.Marshal(koConfig{
+		DefaultBaseImage: baseImage,
+		Builds: []koBuild{{
+			ID:      ko.ID,
+			Main:    ko.Main,
+			Env:     env,
+			Flags:   flags,
+			Ldflags: ldflags,
+		}},
+	})
+	if err != nil {
+		return err
+	}
+	configPath := filepath.Join(dir, ".ko.yaml")
+	if err := os.WriteFile(configPath, bts, 0o644); err != nil {
+		return err
+	}
+	refsPath := filepath.Join(dir, "image-refs.txt")
+
+	log.FromContext(ctx).WithField("repository", repository).Info("building and pushing")
+	if err := runKo(ctx, ko.WorkingDir, []string{
+		"KO_DOCKER_REPO=" + repository,
+		"KO_CONFIG_PATH=" + configPath,
+	}, koArgs(ko, tags, refsPath)...); err != nil {
+		return err
+	}
+
+	refs, err := os.ReadFile(refsPath)
+	if err != nil {
+		return err
+	}
+	ref := strings.TrimSpace(string(refs))
+	if ref == "" {
+		return errors.New("ko did not push any image")
+	}
+	name, digest := splitRef(ref)
+	for _, tag := range tags {
+		ctx.Artifacts.Add(&artifact.Artifact{
+			Type: artifact.DockerImage,
+			Name: name + ":" + tag,
+			Path: name + ":" + tag,
+			Extra: map[string]interface{}{
+				artifact.ExtraID: ko.ID,
+				digestExtra:      digest,
+			},
+		})
+	}
+	return nil
+}
+
+// koArgs returns the arguments of the ko build command.
+func koArgs(ko config.Ko, tags []string, refsPath string) []string {
+	args := []string{
+		"build", ko.Main,
+		"--platform=" + strings.Join(ko.Platforms, ","),
+		"--tags=" + strings.Join(tags, ","),
+		"--sbom=" + ko.SBOM,
+		"--image-refs=" + refsPath,
+	}
+	switch {
+	case ko.Bare:
+		args = append(args, "--bare")
+	case ko.PreserveImportPaths:
+		args = append(args, "--preserve-import-paths")
+	case ko.BaseImportPaths:
+		args = append(args, "--base-import-paths")
+	}
+	return args
+}
+
+// splitRef splits a reference like repo:tag@sha256:abc into the image name,
+// without a tag, and its digest.
+func splitRef(ref string) (string, string) {
+	name, digest := ref, ""
+	if i := strings.Index(ref, "@"); i >= 0 {
+		name, digest = ref[:i], ref[i+1:]
+	}
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		name = name[:i]
+	}
+	return name, digest
+}
+
+func applyAll(t *tmpl.Template, ss []string) ([]string, error) {
+	result := make([]string, 0, len(ss))
+	for _, s := range ss {
+		s, err := t.Apply(s)
+		if err != nil {
+			return nil, err
+		}
+		if s == "" {
+			continue
+		}
+		result = append(result, s)
+	}
+	return result, nil
+}
+
+func runKo(ctx *context.Context, dir string, env []string, args ...string) error {
+	fields := log.Fields{"cmd": "ko", "cwd": dir}
+	var b bytes.Buffer
+	w := gio.Safe(&b)
+	/* #nosec */
+	cmd := exec.CommandContext(ctx, "ko", args...)
+	cmd.Dir = dir
+	cmd.Env = append(ctx.Env.Strings(), env...)
+	cmd.Stderr = io.MultiWriter(logext.NewWriter(fields, logext.Error), w)
+	cmd.Stdout = io.MultiWriter(logext.NewWriter(fields, logext.Info), w)
+
+	log.FromContext(ctx).WithFields(fields).WithField("args", args).Debug("running")
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to build image: %w: %s", err, b.String())
+	}
+	return nil
+}
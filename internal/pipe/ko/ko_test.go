@@ -0,0 +1,216 @@
+package ko
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeKo puts a ko script in the PATH that logs its arguments, environment
+// and config, and writes the given reference to the image refs file.
+func fakeKo(tb testing.TB, ref string) string {
+	tb.Helper()
+	dir := tb.TempDir()
+	calls := filepath.Join(dir, "ko.log")
+	script := `#!/bin/sh
+echo "$@" >> ` + calls + `
+echo "repo=$KO_DOCKER_REPO" >> ` + calls + `
+cat "$KO_CONFIG_PATH" >> ` + calls + `
+for arg in "$@"; do
+	case "$arg" in
+	--image-refs=*) echo "` + ref + `" > "${arg#--image-refs=}" ;;
+	esac
+done
+`
+	require.NoError(tb, os.WriteFile(filepath.Join(dir, "ko"), []byte(script), 0o755))
+	tb.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return calls
+}
+
+func TestDescription(t *testing.T) {
+	require.NotEmpty(t, Pipe{}.String())
+}
+
+func TestSkip(t *testing.T) {
+	require.True(t, Pipe{}.Skip(context.New(config.Project{})))
+	require.False(t, Pipe{}.Skip(context.New(config.Project{
+		Kos: []config.Ko{{}},
+	})))
+}
+
+func TestDefault(t *testing.T) {
+	ctx := context.New(config.Project{
+		ProjectName: "foo",
+		Builds: []config.Build{{
+			ID:      "foo",
+			Main:    "./cmd/foo",
+			Dir:     "app",
+			Ldflags: []string{"-s -w -X main.version={{.Version}}"},
+			Env:     []string{"CGO_ENABLED=0"},
+		}},
+		Kos: []config.Ko{{
+			Repository: "ghcr.io/foo/bar",
+		}},
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.Equal(t, config.Ko{
+		ID:         "foo",
+		Build:      "foo",
+		Main:       "./cmd/foo",
+		WorkingDir: "app",
+		BaseImage:  defaultBaseImage,
+		Repository: "ghcr.io/foo/bar",
+		Platforms:  []string{"linux/amd64"},
+		Tags:       []string{"latest"},
+		SBOM:       "spdx",
+		Ldflags:    []string{"-s -w -X main.version={{.Version}}"},
+		Env:        []string{"CGO_ENABLED=0"},
+	}, ctx.Config.Kos[0])
+}
+
+func TestDefaultRepositoryFromEnv(t *testing.T) {
+	ctx := context.New(config.Project{
+		Kos: []config.Ko{{ID: "foo"}},
+	})
+	ctx.Env["KO_DOCKER_REPO"] = "ghcr.io/foo"
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.Equal(t, "ghcr.io/foo", ctx.Config.Kos[0].Repository)
+	require.Equal(t, ".", ctx.Config.Kos[0].Main)
+	require.Equal(t, ".", ctx.Config.Kos[0].WorkingDir)
+}
+
+func TestDefaultErrors(t *testing.T) {
+	for name, tt := range map[string]struct {
+		ko  config.Ko
+		err string
+	}{
+		"no repository": {
+			ko:  config.Ko{ID: "foo"},
+			err: "ko foo: repository is required",
+		},
+		"build not found": {
+			ko:  config.Ko{ID: "foo", Build: "nope", Repository: "foo"},
+			err: "ko foo: build nope not found",
+		},
+		"invalid sbom": {
+			ko:  config.Ko{ID: "foo", Repository: "foo", SBOM: "cyclonedx"},
+			err: "ko foo: invalid sbom: cyclonedx, valid options are spdx and none",
+		},
+		"naming": {
+			ko:  config.Ko{ID: "foo", Repository: "foo", Bare: true, BaseImportPaths: true},
+			err: "ko foo: bare, preserve_import_paths and base_import_paths are mutually exclusive",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			require.EqualError(t, Pipe{}.Default(context.New(config.Project{
+				Kos: []config.Ko{tt.ko},
+			})), tt.err)
+		})
+	}
+}
+
+func TestDefaultDuplicatedIDs(t *testing.T) {
+	require.Error(t, Pipe{}.Default(context.New(config.Project{
+		Kos: []config.Ko{
+			{ID: "foo", Repository: "foo"},
+			{ID: "foo", Repository: "foo"},
+		},
+	})))
+}
+
+func TestPublish(t *testing.T) {
+	calls := fakeKo(t, "ghcr.io/foo/bar:v1.0.0@sha256:abc")
+	ctx := context.New(config.Project{
+		Dist: t.TempDir(),
+		Kos: []config.Ko{{
+			ID:         "foo",
+			Main:       "./cmd/foo",
+			WorkingDir: ".",
+			BaseImage:  "alpine",
+			Repository: "ghcr.io/foo/{{ .ProjectName }}",
+			Platforms:  []string{"linux/amd64", "linux/arm64"},
+			Tags:       []string{"{{ .Tag }}", "latest", "{{ if .IsSnapshot }}snapshot{{ end }}"},
+			SBOM:       "none",
+			Ldflags:    []string{"-X main.version={{ .Version }}"},
+			Bare:       true,
+		}},
+	})
+	ctx.Config.ProjectName = "bar"
+	ctx.Git.CurrentTag = "v1.0.0"
+	ctx.Version = "1.0.0"
+	require.NoError(t, Pipe{}.Publish(ctx))
+
+	bts, err := os.ReadFile(calls)
+	require.NoError(t, err)
+	log := string(bts)
+	refs, err := filepath.Abs(filepath.Join(ctx.Config.Dist, "ko", "foo", "image-refs.txt"))
+	require.NoError(t, err)
+	require.Contains(t, log, "build ./cmd/foo --platform=linux/amd64,linux/arm64 --tags=v1.0.0,latest --sbom=none --image-refs="+refs+" --bare\n")
+	require.Contains(t, log, "repo=ghcr.io/foo/bar\n")
+	require.Contains(t, log, "defaultBaseImage: alpine\n")
+	require.Contains(t, log, "- -X main.version=1.0.0\n")
+
+	images := ctx.Artifacts.Filter(artifact.ByType(artifact.DockerImage)).List()
+	require.Len(t, images, 2)
+	names := make([]string, 0, len(images))
+	for _, img := range images {
+		names = append(names, img.Name)
+		require.Equal(t, "foo", img.ExtraOr(artifact.ExtraID, ""))
+		require.Equal(t, "sha256:abc", img.ExtraOr(digestExtra, ""))
+	}
+	require.ElementsMatch(t, []string{"ghcr.io/foo/bar:v1.0.0", "ghcr.io/foo/bar:latest"}, names)
+}
+
+func TestPublishFails(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "ko"), []byte("#!/bin/sh\necho nope >&2\nexit 1\n"), 0o755))
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	err := Pipe{}.Publish(context.New(config.Project{
+		Dist: t.TempDir(),
+		Kos: []config.Ko{{
+			ID:         "foo",
+			Main:       ".",
+			WorkingDir: ".",
+			Repository: "foo",
+			Platforms:  []string{"linux/amd64"},
+			Tags:       []string{"latest"},
+			SBOM:       "spdx",
+		}},
+	}))
+	require.Error(t, err)
+	require.True(t, strings.HasPrefix(err.Error(), "ko foo: failed to build image"), err.Error())
+	require.Contains(t, err.Error(), "nope")
+}
+
+func TestPublishInvalidTemplate(t *testing.T) {
+	err := Pipe{}.Publish(context.New(config.Project{
+		Dist: t.TempDir(),
+		Kos: []config.Ko{{
+			ID:         "foo",
+			Repository: "{{ .Nope }",
+		}},
+	}))
+	require.Error(t, err)
+}
+
+func TestSplitRef(t *testing.T) {
+	for ref, expected := range map[string][2]string{
+		"ghcr.io/foo/bar@sha256:abc":       {"ghcr.io/foo/bar", "sha256:abc"},
+		"ghcr.io/foo/bar:v1@sha256:abc":    {"ghcr.io/foo/bar", "sha256:abc"},
+		"localhost:5000/foo:v1@sha256:abc": {"localhost:5000/foo", "sha256:abc"},
+		"localhost:5000/foo":               {"localhost:5000/foo", ""},
+		"localhost:5000/foo/bar:latest":    {"localhost:5000/foo/bar", ""},
+	} {
+		t.Run(ref, func(t *testing.T) {
+			name, digest := splitRef(ref)
+			require.Equal(t, expected[0], name)
+			require.Equal(t, expected[1], digest)
+		})
+	}
+}
@@ -50,6 +50,9 @@ func (Pipe) Default(ctx *context.Context) error {
 		if krew.Name == "" {
 			krew.Name = ctx.Config.ProjectName
 		}
+		if err := commitauthor.CheckSigning(ctx, krew.CommitAuthor); err != nil {
+			return fmt.Errorf("krew %s: %w", krew.Name, err)
+		}
 	}
 
 	return nil
@@ -78,11 +81,17 @@ func doRun(ctx *context.Context, krew config.Krew, cl client.Client) error {
 	if krew.Name == "" {
 		return pipe.Skip("krew: manifest name is not set")
 	}
-	if krew.Description == "" {
-		return fmt.Errorf("krew: manifest description is not set")
-	}
-	if krew.ShortDescription == "" {
-		return fmt.Errorf("krew: manifest short description is not set")
+	plugins := pluginsFor(krew)
+	for _, plugin := range plugins {
+		if plugin.Name == "" {
+			return fmt.Errorf("krew: plugin name is not set")
+		}
+		if plugin.Description == "" {
+			return fmt.Errorf("krew: manifest description is not set")
+		}
+		if plugin.ShortDescription == "" {
+			return fmt.Errorf("krew: manifest short description is not set")
+		}
 	}
 
 	filters := []artifact.Filter{
@@ -102,6 +111,7 @@ func doRun(ctx *context.Context, krew config.Krew, cl client.Client) error {
 		),
 		artifact.ByType(artifact.UploadableArchive),
 		artifact.OnlyReplacingUnibins,
+		artifact.OnlyUnencrypted,
 	}
 	if len(krew.IDs) > 0 {
 		filters = append(filters, artifact.ByIDs(krew.IDs...))
@@ -112,19 +122,80 @@ func doRun(ctx *context.Context, krew config.Krew, cl client.Client) error {
 		return ErrNoArchivesFound
 	}
 
+	for _, plugin := range plugins {
+		if err := doRunPlugin(ctx, krew, plugin, cl, archives); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pluginsFor returns the plugins to create manifests for: the ones listed in
+// the config, defaulting to its fields, or the one the config describes.
+func pluginsFor(krew config.Krew) []config.KrewPlugin {
+	if len(krew.Plugins) == 0 {
+		return []config.KrewPlugin{{
+			Name:             krew.Name,
+			ShortDescription: krew.ShortDescription,
+			Description:      krew.Description,
+			Homepage:         krew.Homepage,
+			Caveats:          krew.Caveats,
+			Files:            krew.Files,
+		}}
+	}
+
+	plugins := make([]config.KrewPlugin, 0, len(krew.Plugins))
+	for _, plugin := range krew.Plugins {
+		if plugin.ShortDescription == "" {
+			plugin.ShortDescription = krew.ShortDescription
+		}
+		if plugin.Description == "" {
+			plugin.Description = krew.Description
+		}
+		if plugin.Homepage == "" {
+			plugin.Homepage = krew.Homepage
+		}
+		if plugin.Caveats == "" {
+			plugin.Caveats = krew.Caveats
+		}
+		if len(plugin.Files) == 0 {
+			plugin.Files = krew.Files
+		}
+		plugins = append(plugins, plugin)
+	}
+	return plugins
+}
+
+func doRunPlugin(ctx *context.Context, krew config.Krew, plugin config.KrewPlugin, cl client.Client, archives []*artifact.Artifact) error {
+	krew.Name = plugin.Name
+	krew.ShortDescription = plugin.ShortDescription
+	krew.Description = plugin.Description
+	krew.Homepage = plugin.Homepage
+	krew.Caveats = plugin.Caveats
+	krew.Files = plugin.Files
+	krew.Plugins = nil
+
 	krew, err := templateFields(ctx, krew)
 	if err != nil {
 		return err
 	}
+	binary, err := tmpl.New(ctx).Apply(plugin.Binary)
+	if err != nil {
+		return err
+	}
 
-	content, err := buildmanifest(ctx, krew, cl, archives)
+	data, err := manifestFor(ctx, krew, binary, cl, archives)
+	if err != nil {
+		return err
+	}
+	content, err := doBuildManifest(data)
 	if err != nil {
 		return err
 	}
 
 	filename := krew.Name + ".yaml"
 	yamlPath := filepath.Join(ctx.Config.Dist, filename)
-	log.WithField("manifest", yamlPath).Info("writing")
+	log.FromContext(ctx).WithField("manifest", yamlPath).Info("writing")
 	if err := os.WriteFile(yamlPath, []byte(content), 0o644); err != nil { //nolint: gosec
 		return fmt.Errorf("failed to write krew manifest: %w", err)
 	}
@@ -171,14 +242,6 @@ func templateFields(ctx *context.Context, krew config.Krew) (config.Krew, error)
 	return krew, nil
 }
 
-func buildmanifest(ctx *context.Context, krew config.Krew, client client.Client, artifacts []*artifact.Artifact) (string, error) {
-	data, err := manifestFor(ctx, krew, client, artifacts)
-	if err != nil {
-		return "", err
-	}
-	return doBuildManifest(data)
-}
-
 func doBuildManifest(data Manifest) (string, error) {
 	out, err := yaml.Marshal(data)
 	if err != nil {
@@ -187,7 +250,7 @@ func doBuildManifest(data Manifest) (string, error) {
 	return string(out), nil
 }
 
-func manifestFor(ctx *context.Context, cfg config.Krew, cl client.Client, artifacts []*artifact.Artifact) (Manifest, error) {
+func manifestFor(ctx *context.Context, cfg config.Krew, binary string, cl client.Client, artifacts []*artifact.Artifact) (Manifest, error) {
 	result := Manifest{
 		APIVersion: apiVersion,
 		Kind:       kind,
@@ -221,18 +284,25 @@ func manifestFor(ctx *context.Context, cfg config.Krew, cl client.Client, artifa
 			return result, err
 		}
 
+		bin, err := binaryFor(art, binary)
+		if err != nil {
+			return result, err
+		}
+
+		files, err := filesFor(ctx, cfg, art)
+		if err != nil {
+			return result, err
+		}
+
 		goarch := []string{art.Goarch}
 		if art.Goarch == "all" {
 			goarch = []string{"amd64", "arm64"}
 		}
 
 		for _, arch := range goarch {
-			bins := art.ExtraOr(artifact.ExtraBinaries, []string{}).([]string)
-			if len(bins) != 1 {
-				return result, fmt.Errorf("krew: only one binary per archive allowed, got %d on %q", len(bins), art.Name)
-			}
 			result.Spec.Platforms = append(result.Spec.Platforms, Platform{
-				Bin:    bins[0],
+				Bin:    bin,
+				Files:  files,
 				URI:    url,
 				Sha256: sum,
 				Selector: Selector{
@@ -252,6 +322,46 @@ func manifestFor(ctx *context.Context, cfg config.Krew, cl client.Client, artifa
 	return result, nil
 }
 
+// binaryFor returns the binary of the archive the plugin runs, which is the
+// given one, or the only binary of the archive if none is given.
+func binaryFor(art *artifact.Artifact, binary string) (string, error) {
+	bins := art.ExtraOr(artifact.ExtraBinaries, []string{}).([]string)
+	if binary == "" {
+		if len(bins) != 1 {
+			return "", fmt.Errorf("krew: only one binary per archive allowed, got %d on %q", len(bins), art.Name)
+		}
+		return bins[0], nil
+	}
+	for _, bin := range bins {
+		if bin == binary || bin == binary+".exe" {
+			return bin, nil
+		}
+	}
+	return "", fmt.Errorf("krew: binary %q not found in %q", binary, art.Name)
+}
+
+// filesFor returns the files of the archive installed with the plugin, with
+// their paths templated for the archive, so they can differ per platform.
+func filesFor(ctx *context.Context, cfg config.Krew, art *artifact.Artifact) ([]File, error) {
+	files := make([]File, 0, len(cfg.Files))
+	t := tmpl.New(ctx).WithArtifact(art, map[string]string{})
+	for _, file := range cfg.Files {
+		from, err := t.Apply(file.From)
+		if err != nil {
+			return nil, err
+		}
+		to, err := t.Apply(file.To)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, File{From: from, To: to})
+	}
+	if len(files) == 0 {
+		return nil, nil
+	}
+	return files, nil
+}
+
 // Publish krew manifest.
 func (Pipe) Publish(ctx *context.Context) error {
 	cli, err := client.New(ctx)
@@ -295,7 +405,7 @@ func doPublish(ctx *context.Context, manifest *artifact.Artifact, cl client.Clie
 	repo := client.RepoFromRef(cfg.Index)
 
 	gpath := buildManifestPath(manifestsFolder, manifest.Name)
-	log.WithField("manifest", gpath).
+	log.FromContext(ctx).WithField("manifest", gpath).
 		WithField("repo", repo.String()).
 		Info("pushing")
 
@@ -314,6 +424,14 @@ func doPublish(ctx *context.Context, manifest *artifact.Artifact, cl client.Clie
 		return err
 	}
 
+	var data Manifest
+	if err := yaml.Unmarshal(content, &data); err != nil {
+		return fmt.Errorf("krew: failed to unmarshal yaml: %w", err)
+	}
+	if err := validate(data); err != nil {
+		return err
+	}
+
 	return cl.CreateFile(ctx, author, repo, content, gpath, msg)
 }
 
@@ -341,8 +459,14 @@ type Selector struct {
 	MatchLabels MatchLabels `yaml:"matchLabels,omitempty"`
 }
 
+type File struct {
+	From string `yaml:"from,omitempty"`
+	To   string `yaml:"to,omitempty"`
+}
+
 type Platform struct {
 	Bin      string   `yaml:"bin,omitempty"`
+	Files    []File   `yaml:"files,omitempty"`
 	URI      string   `yaml:"uri,omitempty"`
 	Sha256   string   `yaml:"sha256,omitempty"`
 	Selector Selector `yaml:"selector,omitempty"`
@@ -861,6 +861,243 @@ func TestRunMultipleBinaries(t *testing.T) {
 	require.EqualError(t, runAll(ctx, client), `krew: only one binary per archive allowed, got 2 on "bin.tar.gz"`)
 }
 
+func TestRunPipeMultiplePlugins(t *testing.T) {
+	folder := t.TempDir()
+	ctx := context.New(config.Project{
+		Dist:        folder,
+		ProjectName: "foo",
+		Krews: []config.Krew{
+			{
+				Name:             "foo",
+				Description:      "Some desc",
+				ShortDescription: "Short desc",
+				Homepage:         "https://goreleaser.com",
+				Index: config.RepoRef{
+					Owner: "test",
+					Name:  "test",
+				},
+				Files: []config.KrewFile{
+					{From: "kubectl-*", To: "."},
+					{From: "LICENSE", To: "."},
+				},
+				Plugins: []config.KrewPlugin{
+					{
+						Name:    "foo",
+						Binary:  "kubectl-foo",
+						Caveats: "Run kubectl foo --help",
+						Files: []config.KrewFile{
+							{From: `kubectl-foo{{ if eq .Os "windows" }}.exe{{ end }}`, To: "."},
+						},
+					},
+					{
+						Name:             "{{ .ProjectName }}-bar",
+						Binary:           "kubectl-bar",
+						ShortDescription: "Short bar desc",
+					},
+				},
+			},
+		},
+	})
+	ctx.Git = context.GitInfo{CurrentTag: "v1.0.1"}
+	ctx.Version = "1.0.1"
+	for _, goos := range []string{"linux", "windows"} {
+		ext := ""
+		if goos == "windows" {
+			ext = ".exe"
+		}
+		path := filepath.Join(folder, "bin_"+goos+".tar.gz")
+		require.NoError(t, os.WriteFile(path, []byte(goos), 0o644))
+		ctx.Artifacts.Add(&artifact.Artifact{
+			Name:   "bin_" + goos + ".tar.gz",
+			Path:   path,
+			Goos:   goos,
+			Goarch: "amd64",
+			Type:   artifact.UploadableArchive,
+			Extra: map[string]interface{}{
+				artifact.ExtraID:       "foo",
+				artifact.ExtraFormat:   "tar.gz",
+				artifact.ExtraBinaries: []string{"kubectl-foo" + ext, "kubectl-bar" + ext},
+			},
+		})
+	}
+
+	cli := client.NewMock()
+	require.NoError(t, runAll(ctx, cli))
+	require.NoError(t, publishAll(ctx, cli))
+	require.Len(t, cli.CreatedFiles, 2)
+	for _, name := range []string{"foo", "foo-bar"} {
+		t.Run(name, func(t *testing.T) {
+			content := cli.CreatedFiles["plugins/"+name+".yaml"]
+			golden.RequireEqualNakedYaml(t, []byte(content))
+			requireValidManifest(t)
+		})
+	}
+}
+
+func TestRunPipePluginErrors(t *testing.T) {
+	newCtx := func(t *testing.T, plugin config.KrewPlugin) *context.Context {
+		t.Helper()
+		folder := t.TempDir()
+		ctx := context.New(config.Project{
+			Dist:        folder,
+			ProjectName: "foo",
+			Krews: []config.Krew{{
+				Name:             "foo",
+				Description:      "Some desc",
+				ShortDescription: "Short desc",
+				Plugins:          []config.KrewPlugin{plugin},
+			}},
+		})
+		ctx.Git = context.GitInfo{CurrentTag: "v1.0.1"}
+		ctx.Version = "1.0.1"
+		path := filepath.Join(folder, "bin.tar.gz")
+		require.NoError(t, os.WriteFile(path, []byte("bin"), 0o644))
+		ctx.Artifacts.Add(&artifact.Artifact{
+			Name:   "bin.tar.gz",
+			Path:   path,
+			Goos:   "linux",
+			Goarch: "amd64",
+			Type:   artifact.UploadableArchive,
+			Extra: map[string]interface{}{
+				artifact.ExtraID:       "foo",
+				artifact.ExtraFormat:   "tar.gz",
+				artifact.ExtraBinaries: []string{"kubectl-foo"},
+			},
+		})
+		return ctx
+	}
+
+	t.Run("no name", func(t *testing.T) {
+		ctx := newCtx(t, config.KrewPlugin{Binary: "kubectl-foo"})
+		require.EqualError(t, runAll(ctx, client.NewMock()), "krew: plugin name is not set")
+	})
+
+	t.Run("binary not found", func(t *testing.T) {
+		ctx := newCtx(t, config.KrewPlugin{Name: "foo", Binary: "kubectl-bar"})
+		require.EqualError(t, runAll(ctx, client.NewMock()), `krew: binary "kubectl-bar" not found in "bin.tar.gz"`)
+	})
+
+	for name, plugin := range map[string]config.KrewPlugin{
+		"name":      {Name: "{{ .Nope }"},
+		"binary":    {Name: "foo", Binary: "{{ .Nope }"},
+		"file from": {Name: "foo", Files: []config.KrewFile{{From: "{{ .Nope }", To: "."}}},
+		"file to":   {Name: "foo", Files: []config.KrewFile{{From: "foo", To: "{{ .Nope }"}}},
+	} {
+		t.Run("invalid template "+name, func(t *testing.T) {
+			ctx := newCtx(t, plugin)
+			require.Error(t, runAll(ctx, client.NewMock()))
+		})
+	}
+}
+
+func TestPublishInvalidManifest(t *testing.T) {
+	folder := t.TempDir()
+	ctx := context.New(config.Project{
+		Dist:        folder,
+		ProjectName: "foo",
+		Krews: []config.Krew{{
+			Name:             "foo",
+			Description:      "Some desc",
+			ShortDescription: "Short desc",
+			Index:            config.RepoRef{Owner: "test", Name: "test"},
+		}},
+	})
+	ctx.Git = context.GitInfo{CurrentTag: "v1.0.1"}
+	ctx.Version = "1.0"
+	path := filepath.Join(folder, "bin.tar.gz")
+	require.NoError(t, os.WriteFile(path, []byte("bin"), 0o644))
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name:   "bin.tar.gz",
+		Path:   path,
+		Goos:   "linux",
+		Goarch: "amd64",
+		Type:   artifact.UploadableArchive,
+		Extra: map[string]interface{}{
+			artifact.ExtraID:       "foo",
+			artifact.ExtraFormat:   "tar.gz",
+			artifact.ExtraBinaries: []string{"foo"},
+		},
+	})
+
+	cli := client.NewMock()
+	require.NoError(t, runAll(ctx, cli))
+	require.Error(t, publishAll(ctx, cli))
+	require.False(t, cli.CreatedFile)
+}
+
+func TestValidate(t *testing.T) {
+	require.NoError(t, validate(createTemplateValidData()))
+
+	for name, tt := range map[string]struct {
+		prepare func(m *Manifest)
+		err     string
+	}{
+		"name": {
+			prepare: func(m *Manifest) { m.Metadata.Name = "foo/bar" },
+			err:     `krew: invalid manifest for "foo/bar": name must match ^[\w-]+$`,
+		},
+		"api version": {
+			prepare: func(m *Manifest) { m.APIVersion = "v1" },
+			err:     `krew: invalid manifest for "Test": apiVersion must be krew.googlecontainertools.github.com/v1alpha2`,
+		},
+		"kind": {
+			prepare: func(m *Manifest) { m.Kind = "Pod" },
+			err:     `krew: invalid manifest for "Test": kind must be Plugin`,
+		},
+		"short description": {
+			prepare: func(m *Manifest) { m.Spec.ShortDescription = "" },
+			err:     `krew: invalid manifest for "Test": shortDescription is required`,
+		},
+		"version without v": {
+			prepare: func(m *Manifest) { m.Spec.Version = "0.1.3" },
+			err:     `krew: invalid manifest for "Test": version "0.1.3" must start with v`,
+		},
+		"version": {
+			prepare: func(m *Manifest) { m.Spec.Version = "v0.1" },
+			err:     `krew: invalid manifest for "Test": version "v0.1" is not a valid semver: Invalid Semantic Version`,
+		},
+		"no platforms": {
+			prepare: func(m *Manifest) { m.Spec.Platforms = nil },
+			err:     `krew: invalid manifest for "Test": at least one platform is required`,
+		},
+		"uri": {
+			prepare: func(m *Manifest) { m.Spec.Platforms[0].URI = "" },
+			err:     `krew: invalid manifest for "Test": platform darwin/amd64: uri is required`,
+		},
+		"sha256": {
+			prepare: func(m *Manifest) { m.Spec.Platforms[1].Sha256 = "nope" },
+			err:     `krew: invalid manifest for "Test": platform darwin/arm64: sha256 "nope" must match ^[a-f0-9]{64}$`,
+		},
+		"bin": {
+			prepare: func(m *Manifest) { m.Spec.Platforms[0].Bin = "" },
+			err:     `krew: invalid manifest for "Test": platform darwin/amd64: bin is required`,
+		},
+		"files": {
+			prepare: func(m *Manifest) { m.Spec.Platforms[0].Files = []File{{From: "foo"}} },
+			err:     `krew: invalid manifest for "Test": platform darwin/amd64: files must have both from and to`,
+		},
+		"selector": {
+			prepare: func(m *Manifest) { m.Spec.Platforms[0].Selector = Selector{} },
+			err:     `krew: invalid manifest for "Test": platform /: selector is required`,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			data := createTemplateValidData()
+			tt.prepare(&data)
+			require.EqualError(t, validate(data), tt.err)
+		})
+	}
+}
+
+// createTemplateValidData returns the template data with valid checksums.
+func createTemplateValidData() Manifest {
+	data := createTemplateData()
+	for i := range data.Spec.Platforms {
+		data.Spec.Platforms[i].Sha256 = "1633f61598ab0791e213135923624eb342196b3494909c91899bcd0560f84c67"
+	}
+	return data
+}
+
 func TestDefault(t *testing.T) {
 	testlib.Mktmp(t)
 
@@ -0,0 +1,76 @@
+package krew
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+var (
+	pluginNameRegexp = regexp.MustCompile(`^[\w-]+$`)
+	sha256Regexp     = regexp.MustCompile(`^[a-f0-9]{64}$`)
+)
+
+// validate checks the manifest follows the krew manifest schema, as the
+// krew index validates it, so invalid manifests are never committed.
+func validate(manifest Manifest) error {
+	if err := doValidate(manifest); err != nil {
+		return fmt.Errorf("krew: invalid manifest for %q: %w", manifest.Metadata.Name, err)
+	}
+	return nil
+}
+
+func doValidate(manifest Manifest) error {
+	if !pluginNameRegexp.MatchString(manifest.Metadata.Name) {
+		return fmt.Errorf("name must match %s", pluginNameRegexp)
+	}
+	if manifest.APIVersion != apiVersion {
+		return fmt.Errorf("apiVersion must be %s", apiVersion)
+	}
+	if manifest.Kind != kind {
+		return fmt.Errorf("kind must be %s", kind)
+	}
+	if manifest.Spec.ShortDescription == "" {
+		return errors.New("shortDescription is required")
+	}
+	if !strings.HasPrefix(manifest.Spec.Version, "v") {
+		return fmt.Errorf("version %q must start with v", manifest.Spec.Version)
+	}
+	if _, err := semver.StrictNewVersion(strings.TrimPrefix(manifest.Spec.Version, "v")); err != nil {
+		return fmt.Errorf("version %q is not a valid semver: %w", manifest.Spec.Version, err)
+	}
+	if len(manifest.Spec.Platforms) == 0 {
+		return errors.New("at least one platform is required")
+	}
+	for _, platform := range manifest.Spec.Platforms {
+		if err := validatePlatform(platform); err != nil {
+			labels := platform.Selector.MatchLabels
+			return fmt.Errorf("platform %s/%s: %w", labels.Os, labels.Arch, err)
+		}
+	}
+	return nil
+}
+
+func validatePlatform(platform Platform) error {
+	if platform.URI == "" {
+		return errors.New("uri is required")
+	}
+	if !sha256Regexp.MatchString(platform.Sha256) {
+		return fmt.Errorf("sha256 %q must match %s", platform.Sha256, sha256Regexp)
+	}
+	if platform.Bin == "" {
+		return errors.New("bin is required")
+	}
+	for _, file := range platform.Files {
+		if file.From == "" || file.To == "" {
+			return errors.New("files must have both from and to")
+		}
+	}
+	if platform.Selector.MatchLabels.Os == "" && platform.Selector.MatchLabels.Arch == "" {
+		return errors.New("selector is required")
+	}
+	return nil
+}
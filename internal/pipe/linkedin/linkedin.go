@@ -52,7 +52,7 @@ func (Pipe) Announce(ctx *context.Context) error {
 		return fmt.Errorf("failed to announce to linkedin: %w", err)
 	}
 
-	log.Infof("The text post is available at: %s\n", url)
+	log.FromContext(ctx).Infof("The text post is available at: %s\n", url)
 
 	return nil
 }
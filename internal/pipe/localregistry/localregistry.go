@@ -0,0 +1,90 @@
+// Package localregistry provides the pipes that start a temporary local
+// registry and push the docker images and manifests of a snapshot to it, so
+// they can be tested without the credentials of their real registries.
+package localregistry
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/pipe"
+	"github.com/goreleaser/goreleaser/internal/pipe/docker"
+	"github.com/goreleaser/goreleaser/pkg/context"
+)
+
+// Image of the registry that is started.
+const Image = "registry:2"
+
+// Pipe that starts the local registry.
+type Pipe struct{}
+
+func (Pipe) String() string { return "starting local registry" }
+func (Pipe) Skip(ctx *context.Context) bool {
+	return !ctx.LocalRegistry.Enabled || (len(ctx.Config.Dockers) == 0 && len(ctx.Config.DockerManifests) == 0)
+}
+
+// Run the pipe.
+func (Pipe) Run(ctx *context.Context) error {
+	if !ctx.Snapshot {
+		return errors.New("--local-registry can only be used with --snapshot")
+	}
+	id, err := run(ctx, "run", "--detach", "--rm", "--publish", "127.0.0.1::5000", Image)
+	if err != nil {
+		return fmt.Errorf("failed to start local registry: %w", err)
+	}
+	port, err := run(ctx, "port", id, "5000/tcp")
+	if err != nil {
+		return fmt.Errorf("failed to get the port of the local registry: %w", err)
+	}
+	// the output is like 127.0.0.1:49153, possibly followed by other addresses.
+	port = strings.Split(port, "\n")[0]
+	port = port[strings.LastIndex(port, ":")+1:]
+	ctx.LocalRegistry.Address = "localhost:" + port
+	log.FromContext(ctx).WithField("address", ctx.LocalRegistry.Address).
+		WithField("container", id).
+		Info("local registry started, remove it with 'docker rm -f <container>' when you are done")
+	return nil
+}
+
+// PublishPipe pushes the docker images and manifests to the local registry.
+type PublishPipe struct{}
+
+func (PublishPipe) String() string                 { return "pushing to local registry" }
+func (PublishPipe) Skip(ctx *context.Context) bool { return ctx.LocalRegistry.Address == "" }
+
+// Run the pipe.
+func (PublishPipe) Run(ctx *context.Context) error {
+	if err := (docker.Pipe{}).Publish(ctx); err != nil {
+		return err
+	}
+	if len(ctx.Config.DockerManifests) > 0 {
+		if err := (docker.ManifestPipe{}).Publish(ctx); err != nil && !pipe.IsSkip(err) {
+			return err
+		}
+	}
+	for _, a := range ctx.Artifacts.Filter(artifact.Or(
+		artifact.ByType(artifact.DockerImage),
+		artifact.ByType(artifact.DockerManifest),
+	)).List() {
+		log.FromContext(ctx).WithField("type", a.Type.String()).Info(a.Name)
+	}
+	return nil
+}
+
+func run(ctx *context.Context, args ...string) (string, error) {
+	/* #nosec */
+	cmd := exec.CommandContext(ctx, "docker", args...)
+	cmd.Env = ctx.Env.Strings()
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", err, stderr.String())
+	}
+	return strings.TrimSpace(string(out)), nil
+}
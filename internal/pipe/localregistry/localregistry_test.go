@@ -0,0 +1,98 @@
+package localregistry
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+func TestString(t *testing.T) {
+	require.NotEmpty(t, Pipe{}.String())
+	require.NotEmpty(t, PublishPipe{}.String())
+}
+
+func TestSkip(t *testing.T) {
+	t.Run("disabled", func(t *testing.T) {
+		ctx := context.New(config.Project{
+			Dockers: []config.Docker{{}},
+		})
+		require.True(t, Pipe{}.Skip(ctx))
+		require.True(t, PublishPipe{}.Skip(ctx))
+	})
+
+	t.Run("no dockers", func(t *testing.T) {
+		ctx := context.New(config.Project{})
+		ctx.LocalRegistry.Enabled = true
+		require.True(t, Pipe{}.Skip(ctx))
+	})
+
+	t.Run("dont skip", func(t *testing.T) {
+		ctx := context.New(config.Project{
+			DockerManifests: []config.DockerManifest{{}},
+		})
+		ctx.LocalRegistry.Enabled = true
+		require.False(t, Pipe{}.Skip(ctx))
+		ctx.LocalRegistry.Address = "localhost:5000"
+		require.False(t, PublishPipe{}.Skip(ctx))
+	})
+}
+
+func TestRunNotSnapshot(t *testing.T) {
+	ctx := context.New(config.Project{})
+	ctx.LocalRegistry.Enabled = true
+	require.EqualError(t, Pipe{}.Run(ctx), "--local-registry can only be used with --snapshot")
+}
+
+func TestRun(t *testing.T) {
+	calls := fakeDocker(t, `
+if [ "$1" = "run" ]; then
+	echo abc123
+fi
+if [ "$1" = "port" ]; then
+	echo 127.0.0.1:49153
+	echo "[::1]:49154"
+fi
+`)
+	ctx := context.New(config.Project{})
+	ctx.Snapshot = true
+	ctx.LocalRegistry.Enabled = true
+	require.NoError(t, Pipe{}.Run(ctx))
+	require.Equal(t, "localhost:49153", ctx.LocalRegistry.Address)
+
+	bts, err := os.ReadFile(calls)
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"run --detach --rm --publish 127.0.0.1::5000 registry:2",
+		"port abc123 5000/tcp",
+	}, strings.Split(strings.TrimSpace(string(bts)), "\n"))
+}
+
+func TestRunFail(t *testing.T) {
+	fakeDocker(t, `
+echo something went wrong >&2
+exit 1
+`)
+	ctx := context.New(config.Project{})
+	ctx.Snapshot = true
+	ctx.LocalRegistry.Enabled = true
+	err := Pipe{}.Run(ctx)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to start local registry")
+	require.Contains(t, err.Error(), "something went wrong")
+	require.Empty(t, ctx.LocalRegistry.Address)
+}
+
+func fakeDocker(tb testing.TB, body string) string {
+	tb.Helper()
+	dir := tb.TempDir()
+	calls := filepath.Join(dir, "docker.log")
+	script := "#!/bin/sh\necho \"$@\" >> " + calls + "\n" + body
+	require.NoError(tb, os.WriteFile(filepath.Join(dir, "docker"), []byte(script), 0o755))
+	tb.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return calls
+}
@@ -0,0 +1,381 @@
+// Package macports implements the Pipe, generating the Portfile of a MacPorts
+// port from the darwin archives of the release, and pushing it to a ports
+// repository, optionally opening a pull request.
+package macports
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/client"
+	"github.com/goreleaser/goreleaser/internal/commitauthor"
+	"github.com/goreleaser/goreleaser/internal/pipe"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+)
+
+const macportsConfigExtra = "MacPortsConfig"
+
+var (
+	// ErrNoArchivesFound happens when no darwin archives are found.
+	ErrNoArchivesFound = errors.New("no darwin archives found")
+
+	// ErrMultipleArchivesSameArch happens when the config yields multiple
+	// archives for the same architecture.
+	ErrMultipleArchivesSameArch = errors.New("one port can handle only one archive per architecture. Consider using ids in the macports section")
+)
+
+// arches maps go architectures to the macports ones.
+var arches = map[string][]string{
+	"amd64": {"x86_64"},
+	"arm64": {"arm64"},
+	"all":   {"x86_64", "arm64"},
+}
+
+// Pipe for macports ports.
+type Pipe struct{}
+
+func (Pipe) String() string                 { return "macports ports" }
+func (Pipe) Skip(ctx *context.Context) bool { return len(ctx.Config.MacPorts) == 0 }
+
+func (Pipe) Default(ctx *context.Context) error {
+	for i := range ctx.Config.MacPorts {
+		port := &ctx.Config.MacPorts[i]
+
+		port.CommitAuthor = commitauthor.Default(port.CommitAuthor)
+		if port.CommitMessageTemplate == "" {
+			port.CommitMessageTemplate = "{{ .ProjectName }}: update to {{ .Version }}"
+		}
+		if port.Name == "" {
+			port.Name = ctx.Config.ProjectName
+		}
+		if err := commitauthor.CheckSigning(ctx, port.CommitAuthor); err != nil {
+			return fmt.Errorf("macports %s: %w", port.Name, err)
+		}
+		if len(port.Categories) == 0 {
+			port.Categories = []string{"sysutils"}
+		}
+		if len(port.Maintainers) == 0 {
+			port.Maintainers = []string{"nomaintainer"}
+		}
+		if port.PullRequest.Enabled && port.Repository.Branch == "" {
+			port.Repository.Branch = "{{ .ProjectName }}-{{ .Version }}"
+		}
+	}
+	return nil
+}
+
+func (Pipe) Run(ctx *context.Context) error {
+	cli, err := client.New(ctx)
+	if err != nil {
+		return err
+	}
+	return runAll(ctx, cli)
+}
+
+// Publish the macports Portfiles.
+func (Pipe) Publish(ctx *context.Context) error {
+	cli, err := client.New(ctx)
+	if err != nil {
+		return err
+	}
+	return publishAll(ctx, cli)
+}
+
+func runAll(ctx *context.Context, cli client.Client) error {
+	for _, port := range ctx.Config.MacPorts {
+		if err := doRun(ctx, port, cli); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func publishAll(ctx *context.Context, cli client.Client) error {
+	skips := pipe.SkipMemento{}
+	for _, portfile := range ctx.Artifacts.Filter(artifact.ByType(artifact.MacPortsPortfile)).List() {
+		err := doPublish(ctx, portfile, cli)
+		if err != nil && pipe.IsSkip(err) {
+			skips.Remember(err)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return skips.Evaluate()
+}
+
+func doRun(ctx *context.Context, port config.MacPorts, cl client.Client) error {
+	if port.Repository.Name == "" {
+		return pipe.Skip("macports.repository.name is not set")
+	}
+
+	filters := []artifact.Filter{
+		artifact.ByGoos("darwin"),
+		artifact.ByType(artifact.UploadableArchive),
+		artifact.ByFormats("zip", "tar.gz"),
+		artifact.OnlyReplacingUnibins,
+		artifact.OnlyUnencrypted,
+	}
+	if len(port.IDs) > 0 {
+		filters = append(filters, artifact.ByIDs(port.IDs...))
+	}
+	archives := ctx.Artifacts.Filter(artifact.And(filters...)).List()
+	if len(archives) == 0 {
+		return ErrNoArchivesFound
+	}
+
+	t := tmpl.New(ctx)
+	for _, field := range []*string{
+		&port.Name,
+		&port.Path,
+		&port.Repository.Owner,
+		&port.Repository.Name,
+		&port.Repository.Branch,
+		&port.PullRequest.Base.Owner,
+		&port.PullRequest.Base.Name,
+		&port.PullRequest.Base.Branch,
+		&port.SkipUpload,
+	} {
+		applied, err := t.Apply(*field)
+		if err != nil {
+			return err
+		}
+		*field = applied
+	}
+	if port.Path == "" {
+		port.Path = path.Join(port.Categories[0], port.Name, "Portfile")
+	}
+
+	content, err := portfile(ctx, port, cl, archives)
+	if err != nil {
+		return err
+	}
+
+	filename := port.Name + ".Portfile"
+	portfilePath := filepath.Join(ctx.Config.Dist, filename)
+	log.FromContext(ctx).WithField("portfile", portfilePath).Info("writing")
+	if err := os.WriteFile(portfilePath, []byte(content), 0o644); err != nil { //nolint: gosec
+		return fmt.Errorf("failed to write macports portfile: %w", err)
+	}
+
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name: filename,
+		Path: portfilePath,
+		Type: artifact.MacPortsPortfile,
+		Extra: map[string]interface{}{
+			macportsConfigExtra: port,
+		},
+	})
+	return nil
+}
+
+func doPublish(ctx *context.Context, portfile *artifact.Artifact, cl client.Client) error {
+	port := portfile.Extra[macportsConfigExtra].(config.MacPorts)
+	var err error
+	cl, err = client.NewIfToken(ctx, cl, port.Repository.Token)
+	if err != nil {
+		return err
+	}
+
+	if strings.TrimSpace(port.SkipUpload) == "true" {
+		return pipe.Skip("macports.skip_upload is set")
+	}
+
+	if strings.TrimSpace(port.SkipUpload) == "auto" && ctx.Semver.Prerelease != "" {
+		return pipe.Skip("prerelease detected with 'auto' upload, skipping macports publish")
+	}
+
+	repo := client.RepoFromRef(port.Repository)
+	var opener client.PullRequestOpener
+	if port.PullRequest.Enabled {
+		var ok bool
+		opener, ok = cl.(client.PullRequestOpener)
+		if !ok {
+			return fmt.Errorf("macports.pull_request: %w", client.ErrPullRequestNotSupported)
+		}
+		if err := opener.CreateBranch(ctx, repo); err != nil {
+			return err
+		}
+	}
+
+	log.FromContext(ctx).WithField("portfile", port.Path).
+		WithField("repo", repo.String()).
+		Info("pushing")
+
+	msg, err := tmpl.New(ctx).Apply(port.CommitMessageTemplate)
+	if err != nil {
+		return err
+	}
+
+	author, err := commitauthor.Get(ctx, port.CommitAuthor)
+	if err != nil {
+		return err
+	}
+
+	content, err := os.ReadFile(portfile.Path)
+	if err != nil {
+		return err
+	}
+
+	if err := cl.CreateFile(ctx, author, repo, content, port.Path, msg); err != nil {
+		return err
+	}
+
+	if opener == nil {
+		return nil
+	}
+	base := client.RepoFromRef(port.PullRequest.Base)
+	if base.Name == "" {
+		base = client.Repo{Owner: repo.Owner, Name: repo.Name}
+	}
+	url, err := opener.OpenPullRequest(ctx, base, repo, msg, "Automated with [GoReleaser](https://goreleaser.com).")
+	if err != nil {
+		return err
+	}
+	log.FromContext(ctx).WithField("url", url).Info("opened pull request")
+	return nil
+}
+
+func portfile(ctx *context.Context, port config.MacPorts, cl client.Client, archives []*artifact.Artifact) (string, error) {
+	data, err := dataFor(ctx, port, cl, archives)
+	if err != nil {
+		return "", err
+	}
+	t, err := template.New(data.Name).Funcs(template.FuncMap{
+		"join": strings.Join,
+	}).Parse(portfileTemplate)
+	if err != nil {
+		return "", err
+	}
+	var out bytes.Buffer
+	if err := t.Execute(&out, data); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
+
+func dataFor(ctx *context.Context, cfg config.MacPorts, cl client.Client, archives []*artifact.Artifact) (templateData, error) {
+	t := tmpl.New(ctx)
+	result := templateData{
+		Name:        cfg.Name,
+		Version:     ctx.Version,
+		License:     cfg.License,
+		Categories:  cfg.Categories,
+		Maintainers: cfg.Maintainers,
+	}
+	for _, dep := range cfg.Dependencies {
+		if !strings.Contains(dep, ":") {
+			dep = "port:" + dep
+		}
+		result.Dependencies = append(result.Dependencies, dep)
+	}
+	if destroot := strings.TrimSpace(cfg.Destroot); destroot != "" {
+		for _, line := range strings.Split(destroot, "\n") {
+			result.Destroot = append(result.Destroot, strings.TrimRight(line, " \t"))
+		}
+	}
+	for _, field := range []struct {
+		in  string
+		out *string
+	}{
+		{cfg.Description, &result.Description},
+		{cfg.LongDescription, &result.LongDescription},
+		{cfg.Homepage, &result.Homepage},
+	} {
+		applied, err := t.Apply(field.in)
+		if err != nil {
+			return result, err
+		}
+		*field.out = applied
+	}
+	result.Description = quote(result.Description)
+	if result.LongDescription != "" {
+		result.LongDescription = quote(result.LongDescription)
+	}
+
+	if cfg.URLTemplate == "" {
+		url, err := cl.ReleaseURLTemplate(ctx)
+		if err != nil {
+			return result, err
+		}
+		cfg.URLTemplate = url
+	}
+
+	seen := map[string]bool{}
+	for _, archive := range archives {
+		archs := arches[archive.Goarch]
+		for _, arch := range archs {
+			if seen[arch] {
+				return result, ErrMultipleArchivesSameArch
+			}
+			seen[arch] = true
+		}
+		if len(archs) == 0 {
+			continue
+		}
+		sum, err := archive.Checksum("sha256")
+		if err != nil {
+			return result, err
+		}
+		info, err := os.Stat(archive.Path)
+		if err != nil {
+			return result, err
+		}
+		url, err := t.WithArtifact(archive, map[string]string{}).Apply(cfg.URLTemplate)
+		if err != nil {
+			return result, err
+		}
+		idx := strings.LastIndex(url, "/")
+		result.Platforms = append(result.Platforms, platform{
+			Arch:        archs[0],
+			MasterSites: url[:idx+1],
+			Distfile:    url[idx+1:],
+			Wrap:        archive.ExtraOr(artifact.ExtraWrappedIn, "").(string),
+			Zip:         archive.Format() == "zip",
+			SHA256:      sum,
+			Size:        info.Size(),
+		})
+		if len(result.Binaries) == 0 {
+			for _, binary := range archive.ExtraOr(artifact.ExtraBuilds, []*artifact.Artifact{}).([]*artifact.Artifact) {
+				result.Binaries = append(result.Binaries, binary.Name)
+			}
+		}
+	}
+	if len(result.Platforms) == 0 {
+		return result, ErrNoArchivesFound
+	}
+	sort.Slice(result.Platforms, func(i, j int) bool {
+		return result.Platforms[i].Arch < result.Platforms[j].Arch
+	})
+	for arch := range seen {
+		result.Archs = append(result.Archs, arch)
+	}
+	sort.Strings(result.Archs)
+	result.Conditional = len(result.Platforms) > 1
+	return result, nil
+}
+
+// quote quotes the given string to be used as a single word in a Portfile,
+// which is Tcl.
+func quote(s string) string {
+	return `"` + strings.NewReplacer(
+		`\`, `\\`,
+		`"`, `\"`,
+		"$", `\$`,
+		"[", `\[`,
+		"]", `\]`,
+		"\n", " ",
+	).Replace(strings.TrimSpace(s)) + `"`
+}
@@ -0,0 +1,329 @@
+package macports
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/client"
+	"github.com/goreleaser/goreleaser/internal/golden"
+	"github.com/goreleaser/goreleaser/internal/testlib"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDescription(t *testing.T) {
+	require.NotEmpty(t, Pipe{}.String())
+}
+
+func TestSkip(t *testing.T) {
+	require.True(t, Pipe{}.Skip(context.New(config.Project{})))
+	require.False(t, Pipe{}.Skip(context.New(config.Project{
+		MacPorts: []config.MacPorts{{}},
+	})))
+}
+
+func TestDefault(t *testing.T) {
+	ctx := context.New(config.Project{
+		ProjectName: "myproject",
+		MacPorts: []config.MacPorts{
+			{},
+			{PullRequest: config.PullRequest{Enabled: true}},
+		},
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	port := ctx.Config.MacPorts[0]
+	require.Equal(t, "myproject", port.Name)
+	require.Equal(t, []string{"sysutils"}, port.Categories)
+	require.Equal(t, []string{"nomaintainer"}, port.Maintainers)
+	require.Empty(t, port.Repository.Branch)
+	require.NotEmpty(t, port.CommitAuthor.Name)
+	require.NotEmpty(t, port.CommitAuthor.Email)
+	require.NotEmpty(t, port.CommitMessageTemplate)
+	require.Equal(t, "{{ .ProjectName }}-{{ .Version }}", ctx.Config.MacPorts[1].Repository.Branch)
+}
+
+func TestRunPipe(t *testing.T) {
+	for name, tt := range map[string]struct {
+		prepare func(ctx *context.Context)
+		path    string
+	}{
+		"default": {
+			prepare: func(ctx *context.Context) {},
+			path:    "sysutils/default/Portfile",
+		},
+		"custom": {
+			prepare: func(ctx *context.Context) {
+				port := &ctx.Config.MacPorts[0]
+				port.Path = "ports/{{ .ProjectName }}/Portfile"
+				port.URLTemplate = "https://example.com/{{ .Tag }}/{{ .ArtifactName }}"
+				port.Categories = []string{"devel", "sysutils"}
+				port.Maintainers = []string{"@foo", "openmaintainer"}
+				port.Description = `A "macports" port costing ${{ .Env.PRICE }} [now]`
+				port.LongDescription = "A longer description\nof {{ .ProjectName }}."
+				port.Dependencies = []string{"git", "bin:ssh:openssh"}
+				port.Destroot = `
+xinstall -m 0755 ${worksrcpath}/foo ${destroot}${prefix}/bin/
+
+xinstall -d ${destroot}${prefix}/share/doc/${name}
+`
+			},
+			path: "ports/custom/Portfile",
+		},
+		"universal": {
+			prepare: func(ctx *context.Context) {
+				ctx.Config.MacPorts[0].IDs = []string{"universal"}
+				addArchive(t, ctx, &artifact.Artifact{
+					Goos:   "darwin",
+					Goarch: "all",
+					Extra: map[string]interface{}{
+						artifact.ExtraID:       "universal",
+						artifact.ExtraReplaces: true,
+					},
+				})
+			},
+			path: "sysutils/universal/Portfile",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			folder := t.TempDir()
+			ctx := context.New(config.Project{
+				Dist:        folder,
+				ProjectName: name,
+				Env:         []string{"PRICE=0"},
+				MacPorts: []config.MacPorts{{
+					Name:        name,
+					IDs:         []string{"foo"},
+					Description: "A foo port",
+					Homepage:    "https://goreleaser.com",
+					License:     "MIT",
+					Repository: config.RepoRef{
+						Owner: "foo",
+						Name:  "macports-ports",
+					},
+				}},
+			})
+			ctx.Git.CurrentTag = "v1.2.1"
+			ctx.Version = "1.2.1"
+			ctx.Env = map[string]string{"PRICE": "0"}
+			require.NoError(t, Pipe{}.Default(ctx))
+			addArchives(t, ctx)
+			tt.prepare(ctx)
+
+			cli := client.NewMock()
+			require.NoError(t, runAll(ctx, cli))
+			require.NoError(t, publishAll(ctx, cli))
+			require.True(t, cli.CreatedFile)
+			require.Equal(t, tt.path, cli.Path)
+			require.Empty(t, cli.PullRequests)
+			golden.RequireEqualExt(t, []byte(cli.Content), ".Portfile")
+
+			bts, err := os.ReadFile(filepath.Join(folder, name+".Portfile"))
+			require.NoError(t, err)
+			require.Equal(t, cli.Content, string(bts))
+		})
+	}
+}
+
+func TestRunPipePullRequest(t *testing.T) {
+	folder := t.TempDir()
+	ctx := context.New(config.Project{
+		Dist:        folder,
+		ProjectName: "foo",
+		MacPorts: []config.MacPorts{{
+			IDs: []string{"foo"},
+			Repository: config.RepoRef{
+				Owner: "me",
+				Name:  "macports-ports",
+			},
+			PullRequest: config.PullRequest{
+				Enabled: true,
+				Base: config.RepoRef{
+					Owner:  "macports",
+					Name:   "macports-ports",
+					Branch: "master",
+				},
+			},
+		}},
+	})
+	ctx.Git.CurrentTag = "v1.2.1"
+	ctx.Version = "1.2.1"
+	require.NoError(t, Pipe{}.Default(ctx))
+	addArchives(t, ctx)
+
+	cli := client.NewMock()
+	require.NoError(t, runAll(ctx, cli))
+	require.NoError(t, publishAll(ctx, cli))
+	require.Equal(t, []string{"me/macports-ports@foo-1.2.1"}, cli.CreatedBranches)
+	require.Equal(t, []client.MockPullRequest{{
+		Base:  client.Repo{Owner: "macports", Name: "macports-ports", Branch: "master"},
+		Head:  client.Repo{Owner: "me", Name: "macports-ports", Branch: "foo-1.2.1"},
+		Title: "foo: update to 1.2.1",
+		Body:  "Automated with [GoReleaser](https://goreleaser.com).",
+	}}, cli.PullRequests)
+}
+
+func TestRunPipePullRequestNotSupported(t *testing.T) {
+	folder := t.TempDir()
+	ctx := context.New(config.Project{
+		Dist:        folder,
+		ProjectName: "foo",
+		MacPorts: []config.MacPorts{{
+			IDs:         []string{"foo"},
+			Repository:  config.RepoRef{Owner: "me", Name: "macports-ports"},
+			PullRequest: config.PullRequest{Enabled: true},
+		}},
+	})
+	ctx.Git.CurrentTag = "v1.2.1"
+	ctx.Version = "1.2.1"
+	require.NoError(t, Pipe{}.Default(ctx))
+	addArchives(t, ctx)
+
+	// only the Client methods of the mock, which can't open pull requests.
+	cli := struct{ client.Client }{client.NewMock()}
+	require.NoError(t, runAll(ctx, cli))
+	err := publishAll(ctx, cli)
+	require.ErrorIs(t, err, client.ErrPullRequestNotSupported)
+	require.EqualError(t, err, "macports.pull_request: pull requests are only supported on GitHub")
+}
+
+func TestRunPipeErrors(t *testing.T) {
+	newCtx := func(t *testing.T) *context.Context {
+		t.Helper()
+		ctx := context.New(config.Project{
+			Dist:        t.TempDir(),
+			ProjectName: "foo",
+			MacPorts: []config.MacPorts{{
+				Repository: config.RepoRef{Owner: "foo", Name: "macports-ports"},
+			}},
+		})
+		ctx.Git.CurrentTag = "v1.2.1"
+		require.NoError(t, Pipe{}.Default(ctx))
+		return ctx
+	}
+
+	t.Run("no archives", func(t *testing.T) {
+		ctx := newCtx(t)
+		require.Equal(t, ErrNoArchivesFound, runAll(ctx, client.NewMock()))
+	})
+
+	t.Run("same arch", func(t *testing.T) {
+		ctx := newCtx(t)
+		addArchives(t, ctx)
+		addArchive(t, ctx, &artifact.Artifact{
+			Goos:   "darwin",
+			Goarch: "all",
+			Extra: map[string]interface{}{
+				artifact.ExtraID:     "bar",
+				artifact.ExtraFormat: "zip",
+			},
+		})
+		require.Equal(t, ErrMultipleArchivesSameArch, runAll(ctx, client.NewMock()))
+	})
+
+	t.Run("invalid templates", func(t *testing.T) {
+		for name, prepare := range map[string]func(port *config.MacPorts){
+			"name":             func(port *config.MacPorts) { port.Name = "{{ .Nope }" },
+			"path":             func(port *config.MacPorts) { port.Path = "{{ .Nope }" },
+			"branch":           func(port *config.MacPorts) { port.Repository.Branch = "{{ .Nope }" },
+			"description":      func(port *config.MacPorts) { port.Description = "{{ .Nope }" },
+			"long description": func(port *config.MacPorts) { port.LongDescription = "{{ .Nope }" },
+			"homepage":         func(port *config.MacPorts) { port.Homepage = "{{ .Nope }" },
+			"url template":     func(port *config.MacPorts) { port.URLTemplate = "{{ .Nope }" },
+		} {
+			t.Run(name, func(t *testing.T) {
+				ctx := newCtx(t)
+				addArchives(t, ctx)
+				prepare(&ctx.Config.MacPorts[0])
+				require.Error(t, runAll(ctx, client.NewMock()))
+			})
+		}
+	})
+
+	t.Run("invalid commit template", func(t *testing.T) {
+		ctx := newCtx(t)
+		addArchives(t, ctx)
+		ctx.Config.MacPorts[0].CommitMessageTemplate = "{{ .Nope }"
+		require.NoError(t, runAll(ctx, client.NewMock()))
+		require.Error(t, publishAll(ctx, client.NewMock()))
+	})
+}
+
+func TestRunPipeNoUpload(t *testing.T) {
+	ctx := context.New(config.Project{
+		Dist:        t.TempDir(),
+		ProjectName: "foo",
+		MacPorts: []config.MacPorts{{
+			Repository: config.RepoRef{Owner: "foo", Name: "macports-ports"},
+		}},
+	})
+	ctx.Git.CurrentTag = "v1.2.1"
+	require.NoError(t, Pipe{}.Default(ctx))
+	addArchives(t, ctx)
+	cli := client.NewMock()
+
+	assertNoPublish := func(t *testing.T) {
+		t.Helper()
+		require.NoError(t, runAll(ctx, cli))
+		testlib.AssertSkipped(t, publishAll(ctx, cli))
+		require.False(t, cli.CreatedFile)
+	}
+	t.Run("skip upload true", func(t *testing.T) {
+		ctx.Config.MacPorts[0].SkipUpload = "true"
+		ctx.Semver.Prerelease = ""
+		assertNoPublish(t)
+	})
+	t.Run("skip upload auto", func(t *testing.T) {
+		ctx.Config.MacPorts[0].SkipUpload = "auto"
+		ctx.Semver.Prerelease = "beta1"
+		assertNoPublish(t)
+	})
+}
+
+func TestRunSkipNoName(t *testing.T) {
+	ctx := context.New(config.Project{
+		MacPorts: []config.MacPorts{{}},
+	})
+	testlib.AssertSkipped(t, runAll(ctx, client.NewMock()))
+}
+
+func TestQuote(t *testing.T) {
+	require.Equal(t, `"a \"quoted\" \\ costing \${price} \[now\] on two lines"`, quote("a \"quoted\" \\ costing ${price} [now]\non two lines\n"))
+}
+
+// addArchives adds the darwin archives of a release, and other archives that
+// should be ignored.
+func addArchives(tb testing.TB, ctx *context.Context) {
+	tb.Helper()
+	for _, art := range []*artifact.Artifact{
+		{Goos: "darwin", Goarch: "amd64"},
+		{Goos: "darwin", Goarch: "arm64", Extra: map[string]interface{}{artifact.ExtraFormat: "zip"}},
+		{Goos: "darwin", Goarch: "amd64", Extra: map[string]interface{}{artifact.ExtraFormat: "tar.xz"}},
+		{Goos: "linux", Goarch: "amd64"},
+	} {
+		addArchive(tb, ctx, art)
+	}
+}
+
+func addArchive(tb testing.TB, ctx *context.Context, art *artifact.Artifact) {
+	tb.Helper()
+	if art.Extra == nil {
+		art.Extra = map[string]interface{}{}
+	}
+	if _, ok := art.Extra[artifact.ExtraFormat]; !ok {
+		art.Extra[artifact.ExtraFormat] = "tar.gz"
+	}
+	if _, ok := art.Extra[artifact.ExtraID]; !ok {
+		art.Extra[artifact.ExtraID] = "foo"
+	}
+	base := "foo_" + art.Goos + "_" + art.Goarch
+	art.Extra[artifact.ExtraWrappedIn] = base
+	art.Extra[artifact.ExtraBuilds] = []*artifact.Artifact{{Name: "foo"}, {Name: "bar"}}
+	art.Name = base + "." + art.Extra[artifact.ExtraFormat].(string)
+	art.Path = filepath.Join(ctx.Config.Dist, art.Name)
+	art.Type = artifact.UploadableArchive
+	require.NoError(tb, os.WriteFile(art.Path, []byte(art.Name), 0o644))
+	ctx.Artifacts.Add(art)
+}
@@ -0,0 +1,93 @@
+package macports
+
+type templateData struct {
+	Name            string
+	Version         string
+	License         string
+	Description     string
+	LongDescription string
+	Homepage        string
+	Categories      []string
+	Maintainers     []string
+	Dependencies    []string
+	Archs           []string
+	Conditional     bool
+	Platforms       []platform
+	Binaries        []string
+	Destroot        []string
+}
+
+type platform struct {
+	Arch        string
+	MasterSites string
+	Distfile    string
+	Wrap        string
+	Zip         bool
+	SHA256      string
+	Size        int64
+}
+
+// portfileTemplate is the Portfile of the port, which installs the binaries
+// of the darwin archive of the release matching the architecture being built.
+const portfileTemplate = `# -*- coding: utf-8; mode: tcl; tab-width: 4; indent-tabs-mode: nil; c-basic-offset: 4 -*- vim:fenc=utf-8:ft=tcl:et:sw=4:ts=4:sts=4
+# This file was generated by GoReleaser. DO NOT EDIT.
+
+PortSystem          1.0
+
+name                {{ .Name }}
+version             {{ .Version }}
+revision            0
+categories          {{ join .Categories " " }}
+{{- with .License }}
+license             {{ . }}
+{{- end }}
+maintainers         {{ join .Maintainers " " }}
+platforms           darwin
+supported_archs     {{ join .Archs " " }}
+installs_libs       no
+
+description         {{ .Description }}
+long_description    {{ with .LongDescription }}{{ . }}{{ else }}{*}${description}{{ end }}
+{{- with .Homepage }}
+homepage            {{ . }}
+{{- end }}
+{{- with .Dependencies }}
+
+depends_run{{ range . }} \
+                    {{ . }}{{ end }}
+{{- end }}
+{{ $pad := "" }}{{ if .Conditional }}{{ $pad = "    " }}{{ end }}
+{{- range $i, $p := .Platforms }}
+{{- if $.Conditional }}
+{{ if eq $i 0 }}if{{ else }}} elseif{{ end }} {${configure.build_arch} eq "{{ $p.Arch }}"} {
+{{- end }}
+{{ $pad }}master_sites        {{ $p.MasterSites }}
+{{ $pad }}distfiles           {{ $p.Distfile }}
+{{- if $p.Zip }}
+{{ $pad }}use_zip             yes
+{{- end }}
+{{- if $p.Wrap }}
+{{ $pad }}worksrcdir          {{ $p.Wrap }}
+{{- else }}
+{{ $pad }}extract.mkdir       yes
+{{- end }}
+{{ $pad }}checksums           sha256  {{ $p.SHA256 }} \
+{{ $pad }}                    size    {{ $p.Size }}
+{{- end }}
+{{- if .Conditional }}
+}
+{{- end }}
+
+use_configure       no
+build {}
+
+destroot {
+{{- range .Destroot }}
+{{ with . }}    {{ . }}{{ end }}
+{{- else }}
+{{- range .Binaries }}
+    xinstall -m 0755 ${worksrcpath}/{{ . }} ${destroot}${prefix}/bin/
+{{- end }}
+{{- end }}
+}
+`
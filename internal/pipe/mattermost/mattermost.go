@@ -64,7 +64,7 @@ func (Pipe) Announce(ctx *context.Context) error {
 		return fmt.Errorf("announce: failed to announce to mattermost: %w", err)
 	}
 
-	log.Infof("posting: %q", msg)
+	log.FromContext(ctx).Infof("posting: %q", msg)
 
 	wm := &incomingWebhookRequest{
 		Username:    ctx.Config.Announce.Mattermost.Username,
@@ -0,0 +1,155 @@
+// Package metadata provides the pipe implementation that creates a
+// metadata.json file in the dist folder, holding the information about the
+// release needed to run the announcers again later.
+package metadata
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/pkg/context"
+)
+
+// Filename of the metadata file inside the dist folder.
+const Filename = "metadata.json"
+
+// Metadata of a release.
+type Metadata struct {
+	ProjectName  string      `json:"project_name"`
+	ModulePath   string      `json:"module_path,omitempty"`
+	Version      string      `json:"version"`
+	Tag          string      `json:"tag"`
+	PreviousTag  string      `json:"previous_tag,omitempty"`
+	Branch       string      `json:"branch,omitempty"`
+	Commit       string      `json:"commit"`
+	ShortCommit  string      `json:"short_commit"`
+	FullCommit   string      `json:"full_commit"`
+	CommitDate   time.Time   `json:"commit_date"`
+	GitURL       string      `json:"git_url"`
+	Summary      string      `json:"summary,omitempty"`
+	TagSubject   string      `json:"tag_subject,omitempty"`
+	TagContents  string      `json:"tag_contents,omitempty"`
+	TagBody      string      `json:"tag_body,omitempty"`
+	TagTagger    string      `json:"tag_tagger,omitempty"`
+	TagDate      time.Time   `json:"tag_date"`
+	Date         time.Time   `json:"date"`
+	ReleaseURL   string      `json:"release_url,omitempty"`
+	ReleaseNotes string      `json:"release_notes,omitempty"`
+	Snapshot     bool        `json:"snapshot"`
+	Semver       Semver      `json:"semver"`
+	BaseImages   []BaseImage `json:"base_images,omitempty"`
+}
+
+// Semver is the parsed version of the release.
+type Semver struct {
+	Major      uint64 `json:"major"`
+	Minor      uint64 `json:"minor"`
+	Patch      uint64 `json:"patch"`
+	RawVersion string `json:"raw_version"`
+	Prerelease string `json:"prerelease,omitempty"`
+}
+
+// BaseImage is a base image of the docker images of the release, and the
+// digest it was pinned to.
+type BaseImage struct {
+	Image  string `json:"image"`
+	Digest string `json:"digest"`
+}
+
+// Pipe implementation.
+type Pipe struct{}
+
+func (Pipe) String() string                 { return "storing release metadata" }
+func (Pipe) Skip(ctx *context.Context) bool { return false }
+
+// Run the pipe.
+func (Pipe) Run(ctx *context.Context) error {
+	bts, err := json.Marshal(fromContext(ctx))
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(ctx.Config.Dist, Filename)
+	log.Log.WithField("file", path).Info("writing")
+	return os.WriteFile(path, bts, 0o644)
+}
+
+// Load the metadata from the given dist folder.
+func Load(dist string) (Metadata, error) {
+	var md Metadata
+	bts, err := os.ReadFile(filepath.Join(dist, Filename))
+	if err != nil {
+		return md, err
+	}
+	if err := json.Unmarshal(bts, &md); err != nil {
+		return md, fmt.Errorf("failed to parse %s: %w", Filename, err)
+	}
+	return md, nil
+}
+
+// Apply sets the metadata back into the given context.
+func (md Metadata) Apply(ctx *context.Context) {
+	ctx.Config.ProjectName = md.ProjectName
+	ctx.ModulePath = md.ModulePath
+	ctx.Version = md.Version
+	ctx.Git = context.GitInfo{
+		Branch:      md.Branch,
+		CurrentTag:  md.Tag,
+		PreviousTag: md.PreviousTag,
+		Commit:      md.Commit,
+		ShortCommit: md.ShortCommit,
+		FullCommit:  md.FullCommit,
+		CommitDate:  md.CommitDate,
+		URL:         md.GitURL,
+		Summary:     md.Summary,
+		TagSubject:  md.TagSubject,
+		TagContents: md.TagContents,
+		TagBody:     md.TagBody,
+		TagTagger:   md.TagTagger,
+		TagDate:     md.TagDate,
+	}
+	ctx.Date = md.Date
+	ctx.ReleaseURL = md.ReleaseURL
+	ctx.ReleaseNotes = md.ReleaseNotes
+	ctx.Snapshot = md.Snapshot
+	ctx.Semver = context.Semver(md.Semver)
+	ctx.BaseImages = nil
+	for _, image := range md.BaseImages {
+		ctx.BaseImages = append(ctx.BaseImages, context.BaseImage(image))
+	}
+}
+
+func fromContext(ctx *context.Context) Metadata {
+	var baseImages []BaseImage
+	for _, image := range ctx.BaseImages {
+		baseImages = append(baseImages, BaseImage(image))
+	}
+	return Metadata{
+		ProjectName:  ctx.Config.ProjectName,
+		ModulePath:   ctx.ModulePath,
+		Version:      ctx.Version,
+		Tag:          ctx.Git.CurrentTag,
+		PreviousTag:  ctx.Git.PreviousTag,
+		Branch:       ctx.Git.Branch,
+		Commit:       ctx.Git.Commit,
+		ShortCommit:  ctx.Git.ShortCommit,
+		FullCommit:   ctx.Git.FullCommit,
+		CommitDate:   ctx.Git.CommitDate,
+		GitURL:       ctx.Git.URL,
+		Summary:      ctx.Git.Summary,
+		TagSubject:   ctx.Git.TagSubject,
+		TagContents:  ctx.Git.TagContents,
+		TagBody:      ctx.Git.TagBody,
+		TagTagger:    ctx.Git.TagTagger,
+		TagDate:      ctx.Git.TagDate,
+		Date:         ctx.Date,
+		ReleaseURL:   ctx.ReleaseURL,
+		ReleaseNotes: ctx.ReleaseNotes,
+		Snapshot:     ctx.Snapshot,
+		Semver:       Semver(ctx.Semver),
+		BaseImages:   baseImages,
+	}
+}
@@ -0,0 +1,86 @@
+package metadata
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+func TestString(t *testing.T) {
+	require.NotEmpty(t, Pipe{}.String())
+}
+
+func TestRunAndLoad(t *testing.T) {
+	tmp := t.TempDir()
+	ctx := context.New(config.Project{
+		ProjectName: "foo",
+		Dist:        tmp,
+	})
+	ctx.Version = "1.2.3-beta"
+	ctx.Git = context.GitInfo{
+		CurrentTag:  "v1.2.3-beta",
+		PreviousTag: "v1.2.2",
+		Commit:      "a1b2c3",
+		ShortCommit: "a1b2",
+		FullCommit:  "a1b2c3d4",
+		CommitDate:  time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC),
+		URL:         "https://github.com/goreleaser/foo.git",
+		TagSubject:  "first beta",
+		TagContents: "first beta\n\nwith a body",
+		TagBody:     "with a body",
+		TagTagger:   "Foo <foo@example.com>",
+		TagDate:     time.Date(2022, 1, 2, 4, 4, 5, 0, time.UTC),
+	}
+	ctx.Date = time.Date(2022, 1, 3, 3, 4, 5, 0, time.UTC)
+	ctx.ReleaseURL = "https://github.com/goreleaser/foo/releases/tag/v1.2.3-beta"
+	ctx.ReleaseNotes = "## Changelog\n\n* foo"
+	ctx.Semver = context.Semver{
+		Major:      1,
+		Minor:      2,
+		Patch:      3,
+		RawVersion: "1.2.3-beta",
+		Prerelease: "beta",
+	}
+	ctx.BaseImages = []context.BaseImage{
+		{Image: "alpine:3.16", Digest: "sha256:a1b2c3"},
+	}
+
+	require.NoError(t, Pipe{}.Run(ctx))
+
+	info, err := os.Stat(filepath.Join(tmp, Filename))
+	require.NoError(t, err)
+	require.Equal(t, "-rw-r--r--", info.Mode().String())
+
+	md, err := Load(tmp)
+	require.NoError(t, err)
+
+	loaded := context.New(config.Project{Dist: tmp})
+	md.Apply(loaded)
+	require.Equal(t, "foo", loaded.Config.ProjectName)
+	require.Equal(t, ctx.Version, loaded.Version)
+	require.Equal(t, ctx.Git, loaded.Git)
+	require.Equal(t, ctx.Date, loaded.Date)
+	require.Equal(t, ctx.ReleaseURL, loaded.ReleaseURL)
+	require.Equal(t, ctx.ReleaseNotes, loaded.ReleaseNotes)
+	require.Equal(t, ctx.Semver, loaded.Semver)
+	require.Equal(t, ctx.BaseImages, loaded.BaseImages)
+	require.False(t, loaded.Snapshot)
+}
+
+func TestLoadMissing(t *testing.T) {
+	_, err := Load(t.TempDir())
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestLoadInvalid(t *testing.T) {
+	tmp := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tmp, Filename), []byte("nope"), 0o644))
+	_, err := Load(tmp)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to parse metadata.json")
+}
@@ -68,7 +68,7 @@ func doPublish(ctx *context.Context, vcsClient client.Client) error {
 			Owner: milestone.Repo.Owner,
 		}
 
-		log.WithField("milestone", name).
+		log.FromContext(ctx).WithField("milestone", name).
 			WithField("repo", repo.String()).
 			Info("closing milestone")
 
@@ -79,7 +79,7 @@ func doPublish(ctx *context.Context, vcsClient client.Client) error {
 				return err
 			}
 
-			log.WithField("milestone", name).
+			log.FromContext(ctx).WithField("milestone", name).
 				WithField("repo", repo.String()).
 				Warnf("error closing milestone: %s", err)
 		}
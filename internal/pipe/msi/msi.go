@@ -0,0 +1,313 @@
+// Package msi implements the Pipe interface, packaging windows binaries as
+// MSI installers with the WiX toolset.
+package msi
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/google/uuid"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/ids"
+	"github.com/goreleaser/goreleaser/internal/semerrgroup"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+)
+
+const defaultNameTemplate = "{{ .ProjectName }}_{{ .Version }}_{{ .Os }}_{{ .Arch }}"
+
+// ErrNoWix is returned when neither candle and light, nor wixl, can be found
+// in $PATH.
+var ErrNoWix = errors.New("wix toolset not present in $PATH: candle and light, or wixl, are required")
+
+// Pipe for MSI packaging.
+type Pipe struct{}
+
+func (Pipe) String() string                 { return "msi installers" }
+func (Pipe) Skip(ctx *context.Context) bool { return len(ctx.Config.MSIs) == 0 }
+
+// Default sets the pipe defaults.
+func (Pipe) Default(ctx *context.Context) error {
+	ids := ids.New("msis")
+	for i := range ctx.Config.MSIs {
+		msi := &ctx.Config.MSIs[i]
+		if msi.ID == "" {
+			msi.ID = "default"
+		}
+		if msi.NameTemplate == "" {
+			msi.NameTemplate = defaultNameTemplate
+		}
+		if msi.Name == "" {
+			msi.Name = ctx.Config.ProjectName
+		}
+		if msi.Manufacturer == "" {
+			msi.Manufacturer = msi.Name
+		}
+		if msi.UpgradeCode == "" {
+			// derived from the project name and ID so it is the same across
+			// releases, which is what allows upgrades.
+			msi.UpgradeCode = upgradeCode(ctx.Config.ProjectName + "/" + msi.ID)
+		}
+		ids.Inc(msi.ID)
+	}
+	return ids.Validate()
+}
+
+// Run the pipe.
+func (Pipe) Run(ctx *context.Context) error {
+	build, err := lookupWix()
+	if err != nil {
+		return err
+	}
+	g := semerrgroup.New(ctx.Parallelism)
+	for _, msi := range ctx.Config.MSIs {
+		filters := []artifact.Filter{
+			artifact.ByGoos("windows"),
+			artifact.ByType(artifact.Binary),
+		}
+		if len(msi.Builds) > 0 {
+			filters = append(filters, artifact.ByIDs(msi.Builds...))
+		}
+		for platform, binaries := range ctx.Artifacts.Filter(artifact.And(filters...)).GroupByPlatform() {
+			arch, ok := archs[platform]
+			if !ok {
+				log.FromContext(ctx).WithField("platform", platform).Warn("ignored unsupported platform")
+				continue
+			}
+			msi := msi
+			binaries := binaries
+			g.Go(func() error {
+				return create(ctx, msi, arch, binaries, build)
+			})
+		}
+	}
+	return g.Wait()
+}
+
+// arch is a windows architecture as WiX knows it.
+type arch struct {
+	Name         string
+	ProgramFiles string
+	Win64        bool
+}
+
+// archs maps the platforms to the architectures WiX knows.
+var archs = map[string]arch{
+	"windowsamd64": {Name: "x64", ProgramFiles: "ProgramFiles64Folder", Win64: true},
+	"windows386":   {Name: "x86", ProgramFiles: "ProgramFilesFolder"},
+	"windowsarm64": {Name: "arm64", ProgramFiles: "ProgramFiles64Folder", Win64: true},
+}
+
+// buildFunc builds the MSI at the given path from the given WiX source.
+type buildFunc func(ctx *context.Context, arch, wxs, msi string) error
+
+// lookupWix returns the function building MSIs with the WiX toolset found in
+// $PATH, preferring candle and light over wixl.
+func lookupWix() (buildFunc, error) {
+	_, candleErr := exec.LookPath("candle")
+	_, lightErr := exec.LookPath("light")
+	if candleErr == nil && lightErr == nil {
+		return candleLight, nil
+	}
+	if _, err := exec.LookPath("wixl"); err == nil {
+		return wixl, nil
+	}
+	return nil, ErrNoWix
+}
+
+func candleLight(ctx *context.Context, arch, wxs, msi string) error {
+	obj := strings.TrimSuffix(wxs, filepath.Ext(wxs)) + ".wixobj"
+	if err := run(ctx, "candle", "-nologo", "-arch", arch, "-out", obj, wxs); err != nil {
+		return err
+	}
+	return run(ctx, "light", "-nologo", "-out", msi, obj)
+}
+
+func wixl(ctx *context.Context, arch, wxs, msi string) error {
+	return run(ctx, "wixl", "-a", arch, "-o", msi, wxs)
+}
+
+func run(ctx *context.Context, name string, args ...string) error {
+	/* #nosec */
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Env = ctx.Env.Strings()
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to create msi: %s: %w: %s", name, err, string(out))
+	}
+	return nil
+}
+
+// binary is a file installed by the MSI.
+type binary struct {
+	ID     string
+	Name   string
+	Source string
+}
+
+func create(ctx *context.Context, msi config.MSI, arch arch, binaries []*artifact.Artifact, build buildFunc) error {
+	t := tmpl.New(ctx).WithArtifact(binaries[0], nil)
+	name, err := t.Apply(msi.NameTemplate)
+	if err != nil {
+		return err
+	}
+	log := log.FromContext(ctx).WithField("msi", name+".msi")
+
+	for _, field := range []*string{&msi.Name, &msi.Manufacturer, &msi.Description, &msi.License} {
+		applied, err := t.Apply(*field)
+		if err != nil {
+			return err
+		}
+		*field = applied
+	}
+
+	var files []binary
+	for i, b := range binaries {
+		src, err := filepath.Abs(b.Path)
+		if err != nil {
+			return err
+		}
+		files = append(files, binary{
+			ID:     fmt.Sprintf("Binary%d", i),
+			Name:   escape(b.Name),
+			Source: escape(src),
+		})
+	}
+	var license binary
+	if msi.License != "" {
+		src, err := filepath.Abs(msi.License)
+		if err != nil {
+			return err
+		}
+		if _, err := os.Stat(src); err != nil {
+			return fmt.Errorf("msis %s: license not found: %w", msi.ID, err)
+		}
+		license = binary{
+			ID:     "License",
+			Name:   escape(filepath.Base(src)),
+			Source: escape(src),
+		}
+	}
+
+	source := defaultWXS
+	if msi.WXS != "" {
+		bts, err := os.ReadFile(msi.WXS)
+		if err != nil {
+			return fmt.Errorf("msis %s: failed to read wxs template: %w", msi.ID, err)
+		}
+		source = string(bts)
+	}
+	content, err := t.WithExtraFields(tmpl.Fields{
+		"Name":         escape(msi.Name),
+		"Manufacturer": escape(msi.Manufacturer),
+		"Description":  escape(msi.Description),
+		"MsiVersion":   fmt.Sprintf("%d.%d.%d", ctx.Semver.Major, ctx.Semver.Minor, ctx.Semver.Patch),
+		"UpgradeCode":  msi.UpgradeCode,
+		"MsiArch":      arch.Name,
+		"ProgramFiles": arch.ProgramFiles,
+		"Win64":        yesNo(arch.Win64),
+		"Binaries":     files,
+		"License":      license,
+		"Path":         !msi.SkipPath,
+		"PathGuid":     upgradeCode(msi.UpgradeCode + "/" + arch.Name + "/path"),
+	}).Apply(source)
+	if err != nil {
+		return fmt.Errorf("msis %s: failed to apply wxs template: %w", msi.ID, err)
+	}
+
+	wxs, err := filepath.Abs(filepath.Join(ctx.Config.Dist, name+".wxs"))
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(wxs, []byte(content), 0o644); err != nil { //nolint: gosec
+		return err
+	}
+
+	filename := name + ".msi"
+	path := filepath.Join(ctx.Config.Dist, filename)
+	log.Info("creating")
+	if err := build(ctx, arch.Name, wxs, path); err != nil {
+		return err
+	}
+
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Type:   artifact.MSI,
+		Name:   filename,
+		Path:   path,
+		Goos:   binaries[0].Goos,
+		Goarch: binaries[0].Goarch,
+		Extra: map[string]interface{}{
+			artifact.ExtraID:     msi.ID,
+			artifact.ExtraFormat: "msi",
+		},
+	})
+	return nil
+}
+
+// upgradeCode returns a GUID derived from the given string, as WiX expects it.
+func upgradeCode(s string) string {
+	return strings.ToUpper(uuid.NewSHA1(uuid.NameSpaceOID, []byte(s)).String())
+}
+
+// escape escapes the given string to be used in a XML attribute.
+func escape(s string) string {
+	var b bytes.Buffer
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
+
+const defaultWXS = `<?xml version="1.0" encoding="utf-8"?>
+<Wix xmlns="http://schemas.microsoft.com/wix/2006/wi">
+  <Product Id="*" Name="{{ .Name }}" Version="{{ .MsiVersion }}" Manufacturer="{{ .Manufacturer }}" UpgradeCode="{{ .UpgradeCode }}" Language="1033">
+    <Package InstallerVersion="500" Compressed="yes" InstallScope="perMachine" Platform="{{ .MsiArch }}"{{ with .Description }} Description="{{ . }}"{{ end }} />
+    <MajorUpgrade DowngradeErrorMessage="A newer version of {{ .Name }} is already installed." />
+    <Media Id="1" Cabinet="product.cab" EmbedCab="yes" />
+    <Directory Id="TARGETDIR" Name="SourceDir">
+      <Directory Id="{{ .ProgramFiles }}">
+        <Directory Id="INSTALLDIR" Name="{{ .Name }}">
+          {{- range .Binaries }}
+          <Component Id="{{ .ID }}" Guid="*" Win64="{{ $.Win64 }}">
+            <File Id="{{ .ID }}" Name="{{ .Name }}" Source="{{ .Source }}" KeyPath="yes" />
+          </Component>
+          {{- end }}
+          {{- with .License.ID }}
+          <Component Id="License" Guid="*" Win64="{{ $.Win64 }}">
+            <File Id="License" Name="{{ $.License.Name }}" Source="{{ $.License.Source }}" KeyPath="yes" />
+          </Component>
+          {{- end }}
+          {{- if .Path }}
+          <Component Id="Path" Guid="{{ .PathGuid }}" Win64="{{ .Win64 }}" KeyPath="yes">
+            <Environment Id="Path" Name="PATH" Value="[INSTALLDIR]" Permanent="no" Part="last" Action="set" System="yes" />
+          </Component>
+          {{- end }}
+        </Directory>
+      </Directory>
+    </Directory>
+    <Feature Id="Complete" Level="1">
+      {{- range .Binaries }}
+      <ComponentRef Id="{{ .ID }}" />
+      {{- end }}
+      {{- with .License.ID }}
+      <ComponentRef Id="License" />
+      {{- end }}
+      {{- if .Path }}
+      <ComponentRef Id="Path" />
+      {{- end }}
+    </Feature>
+  </Product>
+</Wix>
+`
@@ -0,0 +1,248 @@
+package msi
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDescription(t *testing.T) {
+	require.NotEmpty(t, Pipe{}.String())
+}
+
+func TestSkip(t *testing.T) {
+	require.True(t, Pipe{}.Skip(context.New(config.Project{})))
+	require.False(t, Pipe{}.Skip(context.New(config.Project{
+		MSIs: []config.MSI{{}},
+	})))
+}
+
+func TestDefault(t *testing.T) {
+	ctx := context.New(config.Project{
+		ProjectName: "foo",
+		MSIs:        []config.MSI{{}, {ID: "bar", UpgradeCode: "{CODE}"}},
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.Equal(t, config.MSI{
+		ID:           "default",
+		NameTemplate: defaultNameTemplate,
+		Name:         "foo",
+		Manufacturer: "foo",
+		UpgradeCode:  upgradeCode("foo/default"),
+	}, ctx.Config.MSIs[0])
+	require.Equal(t, "{CODE}", ctx.Config.MSIs[1].UpgradeCode)
+}
+
+func TestDefaultDuplicateID(t *testing.T) {
+	ctx := context.New(config.Project{
+		MSIs: []config.MSI{{ID: "foo"}, {ID: "foo"}},
+	})
+	require.EqualError(t, Pipe{}.Default(ctx), "found 2 msis with the ID 'foo', please fix your config")
+}
+
+func TestUpgradeCode(t *testing.T) {
+	require.Equal(t, upgradeCode("foo/default"), upgradeCode("foo/default"))
+	require.NotEqual(t, upgradeCode("foo/default"), upgradeCode("bar/default"))
+	require.Regexp(t, `^[0-9A-F]{8}-[0-9A-F]{4}-[0-9A-F]{4}-[0-9A-F]{4}-[0-9A-F]{12}$`, upgradeCode("foo"))
+}
+
+func TestRunNoWix(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+	ctx := context.New(config.Project{
+		MSIs: []config.MSI{{}},
+	})
+	require.Equal(t, ErrNoWix, Pipe{}.Run(ctx))
+}
+
+func TestRun(t *testing.T) {
+	calls := fakeTool(t, "wixl", `echo msi > "$4"`)
+	ctx := context.New(config.Project{
+		ProjectName: "foo",
+		Dist:        t.TempDir(),
+		MSIs: []config.MSI{{
+			Builds:       []string{"foo"},
+			Manufacturer: "Foo & Co",
+			Description:  "foo {{ .Version }}",
+			License:      "testdata/LICENSE.txt",
+		}},
+	})
+	ctx.Version = "1.2.3"
+	ctx.Semver = context.Semver{Major: 1, Minor: 2, Patch: 3}
+	require.NoError(t, Pipe{}.Default(ctx))
+	addBinaries(t, ctx)
+	require.NoError(t, Pipe{}.Run(ctx))
+
+	msis := ctx.Artifacts.Filter(artifact.ByType(artifact.MSI)).List()
+	var names []string
+	for _, a := range msis {
+		names = append(names, a.Name)
+		require.FileExists(t, a.Path)
+		require.Equal(t, "default", a.ExtraOr(artifact.ExtraID, ""))
+		require.Equal(t, "msi", a.Format())
+	}
+	require.ElementsMatch(t, []string{
+		"foo_1.2.3_windows_amd64.msi",
+		"foo_1.2.3_windows_386.msi",
+	}, names)
+
+	bts, err := os.ReadFile(filepath.Join(ctx.Config.Dist, "foo_1.2.3_windows_amd64.wxs"))
+	require.NoError(t, err)
+	wxs := string(bts)
+	require.Contains(t, wxs, `Name="foo" Version="1.2.3" Manufacturer="Foo &amp; Co" UpgradeCode="`+ctx.Config.MSIs[0].UpgradeCode+`"`)
+	require.Contains(t, wxs, `Platform="x64" Description="foo 1.2.3"`)
+	require.Contains(t, wxs, `<Directory Id="ProgramFiles64Folder">`)
+	require.Contains(t, wxs, `<File Id="Binary0" Name="foo.exe" Source="`+filepath.Join(ctx.Config.Dist, "foo_windows_amd64", "foo.exe")+`" KeyPath="yes" />`)
+	require.Contains(t, wxs, `<File Id="License" Name="LICENSE.txt"`)
+	require.Contains(t, wxs, `<Environment Id="Path" Name="PATH" Value="[INSTALLDIR]"`)
+
+	bts, err = os.ReadFile(calls)
+	require.NoError(t, err)
+	dist, err := filepath.Abs(ctx.Config.Dist)
+	require.NoError(t, err)
+	require.Contains(t, strings.Split(strings.TrimSpace(string(bts)), "\n"),
+		"-a x86 -o "+filepath.Join(ctx.Config.Dist, "foo_1.2.3_windows_386.msi")+" "+filepath.Join(dist, "foo_1.2.3_windows_386.wxs"))
+}
+
+func TestRunSkipPath(t *testing.T) {
+	fakeTool(t, "wixl", `echo msi > "$4"`)
+	ctx := context.New(config.Project{
+		ProjectName: "foo",
+		Dist:        t.TempDir(),
+		MSIs:        []config.MSI{{SkipPath: true}},
+	})
+	ctx.Version = "1.2.3"
+	ctx.Semver = context.Semver{Major: 1, Minor: 2, Patch: 3}
+	require.NoError(t, Pipe{}.Default(ctx))
+	addBinaries(t, ctx)
+	require.NoError(t, Pipe{}.Run(ctx))
+	bts, err := os.ReadFile(filepath.Join(ctx.Config.Dist, "foo_1.2.3_windows_386.wxs"))
+	require.NoError(t, err)
+	require.Contains(t, string(bts), `<Directory Id="ProgramFilesFolder">`)
+	require.NotContains(t, string(bts), "Environment")
+	require.NotContains(t, string(bts), "License")
+}
+
+func TestRunCustomWXS(t *testing.T) {
+	fakeTool(t, "wixl", `echo msi > "$4"`)
+	wxs := filepath.Join(t.TempDir(), "foo.wxs")
+	require.NoError(t, os.WriteFile(wxs, []byte("{{ .Name }} {{ .MsiArch }} {{ .Version }}"), 0o644))
+	ctx := context.New(config.Project{
+		ProjectName: "foo",
+		Dist:        t.TempDir(),
+		MSIs:        []config.MSI{{WXS: wxs}},
+	})
+	ctx.Version = "1.2.3"
+	ctx.Semver = context.Semver{Major: 1, Minor: 2, Patch: 3}
+	require.NoError(t, Pipe{}.Default(ctx))
+	addBinaries(t, ctx)
+	require.NoError(t, Pipe{}.Run(ctx))
+	bts, err := os.ReadFile(filepath.Join(ctx.Config.Dist, "foo_1.2.3_windows_amd64.wxs"))
+	require.NoError(t, err)
+	require.Equal(t, "foo x64 1.2.3", string(bts))
+}
+
+func TestRunCandleLight(t *testing.T) {
+	calls := fakeTool(t, "candle", `echo wixobj > "$6"`)
+	fakeTool(t, "light", `echo msi > "$3"`)
+	fakeTool(t, "wixl", "exit 1")
+	ctx := context.New(config.Project{
+		ProjectName: "foo",
+		Dist:        t.TempDir(),
+		MSIs:        []config.MSI{{}},
+	})
+	ctx.Version = "1.2.3"
+	ctx.Semver = context.Semver{Major: 1, Minor: 2, Patch: 3}
+	require.NoError(t, Pipe{}.Default(ctx))
+	addBinaries(t, ctx)
+	require.NoError(t, Pipe{}.Run(ctx))
+	require.Len(t, ctx.Artifacts.Filter(artifact.ByType(artifact.MSI)).List(), 2)
+	bts, err := os.ReadFile(calls)
+	require.NoError(t, err)
+	require.Contains(t, string(bts), "-nologo -arch x64 -out ")
+}
+
+func TestRunInvalidTemplates(t *testing.T) {
+	for name, msi := range map[string]config.MSI{
+		"name_template": {NameTemplate: "{{ .Foo }"},
+		"description":   {Description: "{{ .Foo }"},
+		"wxs":           {WXS: "/does/not/exist"},
+		"license":       {License: "/does/not/exist"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			fakeTool(t, "wixl", `echo msi > "$4"`)
+			ctx := context.New(config.Project{
+				ProjectName: "foo",
+				Dist:        t.TempDir(),
+				MSIs:        []config.MSI{msi},
+			})
+			ctx.Version = "1.2.3"
+			ctx.Semver = context.Semver{Major: 1, Minor: 2, Patch: 3}
+			require.NoError(t, Pipe{}.Default(ctx))
+			addBinaries(t, ctx)
+			require.Error(t, Pipe{}.Run(ctx))
+		})
+	}
+}
+
+func TestRunWixFails(t *testing.T) {
+	fakeTool(t, "wixl", "echo nope\nexit 1")
+	ctx := context.New(config.Project{
+		ProjectName: "foo",
+		Dist:        t.TempDir(),
+		MSIs:        []config.MSI{{}},
+	})
+	ctx.Version = "1.2.3"
+	ctx.Semver = context.Semver{Major: 1, Minor: 2, Patch: 3}
+	require.NoError(t, Pipe{}.Default(ctx))
+	addBinaries(t, ctx)
+	err := Pipe{}.Run(ctx)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to create msi")
+	require.Contains(t, err.Error(), "nope")
+}
+
+// addBinaries adds the windows binaries installers are made of, and a linux
+// one, which is ignored.
+func addBinaries(tb testing.TB, ctx *context.Context) {
+	tb.Helper()
+	for _, goarch := range []string{"amd64", "386", "mips"} {
+		path := filepath.Join(ctx.Config.Dist, "foo_windows_"+goarch, "foo.exe")
+		require.NoError(tb, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(tb, os.WriteFile(path, []byte("fake binary"), 0o755))
+		ctx.Artifacts.Add(&artifact.Artifact{
+			Name:   "foo.exe",
+			Path:   path,
+			Goos:   "windows",
+			Goarch: goarch,
+			Type:   artifact.Binary,
+			Extra: map[string]interface{}{
+				artifact.ExtraID: "foo",
+			},
+		})
+	}
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name:   "foo",
+		Path:   "nope",
+		Goos:   "linux",
+		Goarch: "amd64",
+		Type:   artifact.Binary,
+	})
+}
+
+// fakeTool puts a tool with the given name in the PATH that logs its calls
+// and runs the given script.
+func fakeTool(tb testing.TB, name, script string) string {
+	tb.Helper()
+	dir := tb.TempDir()
+	calls := filepath.Join(dir, "calls.log")
+	script = "#!/bin/sh\necho \"$@\" >> " + calls + "\n" + script + "\n"
+	require.NoError(tb, os.WriteFile(filepath.Join(dir, name), []byte(script), 0o755))
+	tb.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return calls
+}
@@ -0,0 +1,84 @@
+package nfpm
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/chglog"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+)
+
+var changelogCommit = regexp.MustCompile(`^[a-fA-F0-9]{7,40}$`)
+
+// writeChangelog writes the release changelog in the format nFPM embeds in
+// deb and rpm packages, returning its path.
+// It returns an empty path if the changelog is disabled or there are no
+// release notes, e.g. on snapshots.
+func writeChangelog(ctx *context.Context, fpm config.NFPM) (string, error) {
+	if !fpm.Changelog.Enabled {
+		return "", nil
+	}
+	changes := changelogChanges(ctx.ReleaseNotes)
+	if len(changes) == 0 {
+		log.FromContext(ctx).WithField("id", fpm.ID).Debug("no release notes, not embedding the changelog")
+		return "", nil
+	}
+
+	date := ctx.Git.CommitDate
+	if date.IsZero() {
+		date = ctx.Date
+	}
+	entries := chglog.ChangeLogEntries{
+		{
+			ChangeLogOverridables: chglog.ChangeLogOverridables{
+				Deb: &chglog.ChangelogDeb{
+					Urgency:       fpm.Changelog.Urgency,
+					Distributions: fpm.Changelog.Distributions,
+				},
+			},
+			Semver:   ctx.Version,
+			Date:     date.UTC(),
+			Packager: fpm.Maintainer,
+			Changes:  changes,
+		},
+	}
+
+	dir := filepath.Join(ctx.Config.Dist, "changelog")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, fpm.ID+".yml")
+	if err := entries.Save(path); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// changelogChanges returns the list items of the given release notes, with
+// their commit hashes if they start with one.
+func changelogChanges(notes string) chglog.ChangeLogChanges {
+	var changes chglog.ChangeLogChanges
+	for _, line := range strings.Split(notes, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "* ") && !strings.HasPrefix(line, "- ") {
+			continue
+		}
+		note := strings.TrimSpace(line[2:])
+		if note == "" {
+			continue
+		}
+		var commit string
+		if parts := strings.SplitN(note, " ", 2); len(parts) == 2 && changelogCommit.MatchString(parts[0]) {
+			commit, note = parts[0], parts[1]
+		}
+		changes = append(changes, &chglog.ChangeLogChange{
+			Commit: commit,
+			Note:   note,
+		})
+	}
+	return changes
+}
@@ -0,0 +1,125 @@
+package nfpm
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/goreleaser/chglog"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+const testReleaseNotes = `## Changelog
+### New Features
+* 5c9a0d4 feat: foo
+* 0b2fe6a6aa1e2b6fc1d8b7c8f3e4c4a1c2d3e4f5 feat: bar
+
+### Others
+* fix: no commit
+
+**Full Changelog**: https://github.com/foo/bar/compare/v0.9.0...v1.0.0
+`
+
+func TestChangelogChanges(t *testing.T) {
+	require.Equal(t, chglog.ChangeLogChanges{
+		{Commit: "5c9a0d4", Note: "feat: foo"},
+		{Commit: "0b2fe6a6aa1e2b6fc1d8b7c8f3e4c4a1c2d3e4f5", Note: "feat: bar"},
+		{Note: "fix: no commit"},
+	}, changelogChanges(testReleaseNotes))
+	require.Empty(t, changelogChanges(""))
+	require.Empty(t, changelogChanges("no list items here\n"))
+}
+
+func TestWriteChangelog(t *testing.T) {
+	ctx := context.New(config.Project{Dist: t.TempDir()})
+	ctx.Version = "1.0.0"
+	ctx.ReleaseNotes = testReleaseNotes
+	ctx.Git.CommitDate = time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)
+	fpm := config.NFPM{
+		ID:         "someid",
+		Maintainer: "me <me@me>",
+		Changelog: config.NFPMChangelog{
+			Enabled:       true,
+			Urgency:       "high",
+			Distributions: []string{"stable"},
+		},
+	}
+
+	path, err := writeChangelog(ctx, fpm)
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(ctx.Config.Dist, "changelog", "someid.yml"), path)
+
+	entries, err := chglog.Parse(path)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, "1.0.0", entries[0].Semver)
+	require.Equal(t, "me <me@me>", entries[0].Packager)
+	require.Equal(t, ctx.Git.CommitDate, entries[0].Date)
+	require.Equal(t, "high", entries[0].Deb.Urgency)
+	require.Equal(t, []string{"stable"}, entries[0].Deb.Distributions)
+	require.Len(t, entries[0].Changes, 3)
+}
+
+func TestWriteChangelogDisabledOrEmpty(t *testing.T) {
+	ctx := context.New(config.Project{Dist: t.TempDir()})
+	ctx.ReleaseNotes = testReleaseNotes
+
+	path, err := writeChangelog(ctx, config.NFPM{ID: "someid"})
+	require.NoError(t, err)
+	require.Empty(t, path)
+
+	ctx.ReleaseNotes = ""
+	path, err = writeChangelog(ctx, config.NFPM{
+		ID:        "someid",
+		Changelog: config.NFPMChangelog{Enabled: true},
+	})
+	require.NoError(t, err)
+	require.Empty(t, path)
+	require.NoDirExists(t, filepath.Join(ctx.Config.Dist, "changelog"))
+}
+
+func TestRunPipeChangelog(t *testing.T) {
+	folder := t.TempDir()
+	dist := filepath.Join(folder, "dist")
+	require.NoError(t, os.Mkdir(dist, 0o755))
+	binPath := filepath.Join(dist, "mybin")
+	require.NoError(t, os.WriteFile(binPath, []byte("binary"), 0o755))
+	ctx := context.New(config.Project{
+		ProjectName: "mybin",
+		Dist:        dist,
+		NFPMs: []config.NFPM{
+			{
+				ID:          "someid",
+				Builds:      []string{"default"},
+				Formats:     []string{"deb", "rpm"},
+				Description: "Some description",
+				Maintainer:  "me <me@me>",
+				Changelog: config.NFPMChangelog{
+					Enabled: true,
+				},
+			},
+		},
+	})
+	ctx.Version = "1.0.0"
+	ctx.Git = context.GitInfo{CurrentTag: "v1.0.0"}
+	ctx.ReleaseNotes = testReleaseNotes
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name:   "mybin",
+		Path:   binPath,
+		Goarch: "amd64",
+		Goos:   "linux",
+		Type:   artifact.Binary,
+		Extra: map[string]interface{}{
+			artifact.ExtraID: "default",
+		},
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.Equal(t, []string{"stable"}, ctx.Config.NFPMs[0].Changelog.Distributions)
+	require.NoError(t, Pipe{}.Run(ctx))
+	require.FileExists(t, filepath.Join(dist, "changelog", "someid.yml"))
+	require.Len(t, ctx.Artifacts.Filter(artifact.ByType(artifact.LinuxPackage)).List(), 2)
+}
@@ -0,0 +1,363 @@
+package nfpm
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/nfpm/v2"
+	"github.com/goreleaser/nfpm/v2/files"
+)
+
+const ipkFormat = "ipk"
+
+// ipkArches maps go architectures to the OpenWrt package architectures of
+// their most common targets.
+// Other targets can be set with the ipk.arch option.
+var ipkArches = map[string]string{
+	"386":      "i386_pentium4",
+	"amd64":    "x86_64",
+	"arm5":     "arm_arm926ej-s",
+	"arm6":     "arm_arm1176jzf-s_vfp",
+	"arm7":     "arm_cortex-a7_neon-vfpv4",
+	"arm64":    "aarch64_generic",
+	"mips":     "mips_24kc",
+	"mipsle":   "mipsel_24kc",
+	"mips64":   "mips64_octeonplus",
+	"mips64le": "mips64el_mips64r2",
+}
+
+// ipk creates OpenWrt packages, which nFPM doesn't support yet.
+// An ipk is a gzipped tarball with the debian-binary, data.tar.gz and
+// control.tar.gz files, read by opkg.
+type ipk struct {
+	cfg config.NFPMIPK
+}
+
+func (p ipk) arch(info *nfpm.Info) string {
+	if p.cfg.Arch != "" {
+		return p.cfg.Arch
+	}
+	arch := strings.TrimSuffix(strings.TrimSuffix(info.Arch, "hardfloat"), "softfloat")
+	if a, ok := ipkArches[arch]; ok {
+		return a
+	}
+	return arch
+}
+
+// ConventionalFileName returns the name opkg-build would give the package.
+func (p ipk) ConventionalFileName(info *nfpm.Info) string {
+	return fmt.Sprintf("%s_%s_%s.ipk", info.Name, ipkVersion(info), p.arch(info))
+}
+
+// Package writes the ipk of the given info to w.
+func (p ipk) Package(info *nfpm.Info, w io.Writer) error {
+	data, size, err := ipkData(info)
+	if err != nil {
+		return err
+	}
+	control, err := p.controlTarball(info, size)
+	if err != nil {
+		return err
+	}
+
+	gw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gw)
+	for _, f := range []struct {
+		name string
+		body []byte
+	}{
+		{"./debian-binary", []byte("2.0\n")},
+		{"./data.tar.gz", data},
+		{"./control.tar.gz", control},
+	} {
+		if err := writeTarFile(tw, f.name, f.body, 0o644); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gw.Close()
+}
+
+// ipkVersion returns the package version, without the epoch.
+func ipkVersion(info *nfpm.Info) string {
+	version := info.Version
+	if info.Prerelease != "" {
+		version += "~" + info.Prerelease
+	}
+	if info.VersionMetadata != "" {
+		version += "+" + info.VersionMetadata
+	}
+	if info.Release != "" {
+		version += "-" + info.Release
+	}
+	return version
+}
+
+// control returns the control file of the package.
+func (p ipk) control(info *nfpm.Info, size int64) []byte {
+	var b bytes.Buffer
+	field := func(name, value string) {
+		if value != "" {
+			fmt.Fprintf(&b, "%s: %s\n", name, value)
+		}
+	}
+	list := func(name string, values []string) {
+		field(name, strings.Join(values, ", "))
+	}
+
+	version := ipkVersion(info)
+	if info.Epoch != "" {
+		version = info.Epoch + ":" + version
+	}
+	section := info.Section
+	if section == "" {
+		section = "utils"
+	}
+	priority := info.Priority
+	if priority == "" {
+		priority = "optional"
+	}
+
+	field("Package", info.Name)
+	field("Version", version)
+	list("Depends", info.Depends)
+	list("Pre-Depends", p.cfg.Predepends)
+	list("Provides", info.Provides)
+	list("Recommends", info.Recommends)
+	list("Suggests", info.Suggests)
+	list("Conflicts", info.Conflicts)
+	list("Replaces", info.Replaces)
+	field("Section", section)
+	field("Priority", priority)
+	field("Maintainer", info.Maintainer)
+	field("License", info.License)
+	field("Homepage", info.Homepage)
+	field("Architecture", p.arch(info))
+	field("Installed-Size", fmt.Sprint(size))
+	field("ABIVersion", p.cfg.ABIVersion)
+	if p.cfg.Essential {
+		field("Essential", "yes")
+	}
+	if p.cfg.AutoInstalled {
+		field("Auto-Installed", "yes")
+	}
+	list("Tags", p.cfg.Tags)
+	keys := make([]string, 0, len(p.cfg.Fields))
+	for k := range p.cfg.Fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		field(k, p.cfg.Fields[k])
+	}
+	description := strings.TrimSpace(info.Description)
+	field("Description", strings.ReplaceAll(description, "\n", "\n "))
+	return b.Bytes()
+}
+
+func (p ipk) controlTarball(info *nfpm.Info, size int64) ([]byte, error) {
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gw)
+
+	if err := writeTarFile(tw, "./control", p.control(info, size), 0o644); err != nil {
+		return nil, err
+	}
+
+	var conffiles []string
+	for _, f := range ipkContents(info) {
+		switch f.Type {
+		case "config", "config|noreplace":
+			conffiles = append(conffiles, f.Destination)
+		}
+	}
+	if len(conffiles) > 0 {
+		body := []byte(strings.Join(conffiles, "\n") + "\n")
+		if err := writeTarFile(tw, "./conffiles", body, 0o644); err != nil {
+			return nil, err
+		}
+	}
+
+	for name, path := range map[string]string{
+		"./preinst":  info.Scripts.PreInstall,
+		"./postinst": info.Scripts.PostInstall,
+		"./prerm":    info.Scripts.PreRemove,
+		"./postrm":   info.Scripts.PostRemove,
+	} {
+		if path == "" {
+			continue
+		}
+		body, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read ipk script: %w", err)
+		}
+		if err := writeTarFile(tw, name, body, 0o755); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// ipkData returns the data tarball of the package, and the size of its
+// files.
+func ipkData(info *nfpm.Info) ([]byte, int64, error) {
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gw)
+
+	var size int64
+	created := map[string]bool{}
+	for _, f := range ipkContents(info) {
+		if err := ipkTree(tw, f.Destination, created); err != nil {
+			return nil, 0, err
+		}
+		name := ipkPath(f.Destination)
+		switch f.Type {
+		case "ghost":
+			continue
+		case "dir":
+			if created[name+"/"] {
+				continue
+			}
+			created[name+"/"] = true
+			if err := tw.WriteHeader(&tar.Header{
+				Name:     name + "/",
+				Mode:     int64(f.FileInfo.Mode.Perm()),
+				Typeflag: tar.TypeDir,
+				ModTime:  f.FileInfo.MTime,
+				Uname:    f.FileInfo.Owner,
+				Gname:    f.FileInfo.Group,
+			}); err != nil {
+				return nil, 0, err
+			}
+		case "symlink":
+			if err := tw.WriteHeader(&tar.Header{
+				Name:     name,
+				Linkname: f.Source,
+				Typeflag: tar.TypeSymlink,
+				ModTime:  f.FileInfo.MTime,
+			}); err != nil {
+				return nil, 0, err
+			}
+		default:
+			n, err := ipkFile(tw, name, f)
+			if err != nil {
+				return nil, 0, err
+			}
+			size += n
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, 0, err
+	}
+	if err := gw.Close(); err != nil {
+		return nil, 0, err
+	}
+	return buf.Bytes(), size, nil
+}
+
+// ipkContents returns the contents that should go in an ipk.
+func ipkContents(info *nfpm.Info) files.Contents {
+	var result files.Contents
+	for _, f := range info.Contents {
+		if f.Packager != "" && f.Packager != ipkFormat {
+			continue
+		}
+		result = append(result, f)
+	}
+	return result
+}
+
+func ipkFile(tw *tar.Writer, name string, f *files.Content) (int64, error) {
+	src, err := os.Open(f.Source)
+	if err != nil {
+		return 0, fmt.Errorf("could not add file to the ipk: %w", err)
+	}
+	defer src.Close()
+	stat, err := src.Stat()
+	if err != nil {
+		return 0, fmt.Errorf("could not add file to the ipk: %w", err)
+	}
+	mode := stat.Mode().Perm()
+	if f.FileInfo.Mode != 0 {
+		mode = f.FileInfo.Mode.Perm()
+	}
+	if err := tw.WriteHeader(&tar.Header{
+		Name:     name,
+		Size:     stat.Size(),
+		Mode:     int64(mode),
+		Typeflag: tar.TypeReg,
+		ModTime:  f.FileInfo.MTime,
+		Uname:    f.FileInfo.Owner,
+		Gname:    f.FileInfo.Group,
+	}); err != nil {
+		return 0, err
+	}
+	return io.Copy(tw, src)
+}
+
+// ipkTree creates the parent directories of dst which were not created yet.
+func ipkTree(tw *tar.Writer, dst string, created map[string]bool) error {
+	var parents []string
+	for dir := filepath.Dir(filepath.Join("/", dst)); dir != "/"; dir = filepath.Dir(dir) {
+		parents = append([]string{ipkPath(dir) + "/"}, parents...)
+	}
+	for _, dir := range parents {
+		if created[dir] {
+			continue
+		}
+		created[dir] = true
+		if err := tw.WriteHeader(&tar.Header{
+			Name:     dir,
+			Mode:     0o755,
+			Typeflag: tar.TypeDir,
+			ModTime:  time.Now(),
+			Uname:    "root",
+			Gname:    "root",
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ipkPath returns the given destination relative to the package root, as
+// "./usr/bin/foo".
+func ipkPath(dst string) string {
+	return "." + files.ToNixPath(filepath.Join("/", dst))
+}
+
+func writeTarFile(tw *tar.Writer, name string, body []byte, mode int64) error {
+	if err := tw.WriteHeader(&tar.Header{
+		Name:     name,
+		Size:     int64(len(body)),
+		Mode:     mode,
+		Typeflag: tar.TypeReg,
+		ModTime:  time.Now(),
+		Uname:    "root",
+		Gname:    "root",
+	}); err != nil {
+		return err
+	}
+	_, err := tw.Write(body)
+	return err
+}
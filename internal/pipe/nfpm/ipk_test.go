@@ -0,0 +1,221 @@
+package nfpm
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/goreleaser/nfpm/v2"
+	"github.com/goreleaser/nfpm/v2/files"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIPKConventionalFileName(t *testing.T) {
+	for arch, expected := range map[string]string{
+		"amd64":           "x86_64",
+		"386":             "i386_pentium4",
+		"arm64":           "aarch64_generic",
+		"arm6":            "arm_arm1176jzf-s_vfp",
+		"arm7":            "arm_cortex-a7_neon-vfpv4",
+		"mipssoftfloat":   "mips_24kc",
+		"mipslehardfloat": "mipsel_24kc",
+		"riscv64":         "riscv64",
+	} {
+		t.Run(arch, func(t *testing.T) {
+			info := &nfpm.Info{Name: "foo", Version: "1.2.3", Release: "1", Arch: arch}
+			require.Equal(t, "foo_1.2.3-1_"+expected+".ipk", ipk{}.ConventionalFileName(info))
+		})
+	}
+
+	t.Run("custom arch", func(t *testing.T) {
+		info := &nfpm.Info{Name: "foo", Version: "1.2.3", Prerelease: "rc1", Arch: "mipssoftfloat"}
+		require.Equal(t, "foo_1.2.3~rc1_mips_4kec.ipk", ipk{cfg: config.NFPMIPK{Arch: "mips_4kec"}}.ConventionalFileName(info))
+	})
+}
+
+func TestIPKPackage(t *testing.T) {
+	folder := t.TempDir()
+	bin := filepath.Join(folder, "foo")
+	require.NoError(t, os.WriteFile(bin, []byte("binary"), 0o755))
+	script := filepath.Join(folder, "postinst.sh")
+	require.NoError(t, os.WriteFile(script, []byte("#!/bin/sh\n"), 0o755))
+
+	info := &nfpm.Info{
+		Name:        "foo",
+		Arch:        "arm7",
+		Version:     "1.2.3",
+		Epoch:       "2",
+		Maintainer:  "me@me",
+		License:     "MIT",
+		Homepage:    "https://goreleaser.com",
+		Description: "Some description\nin two lines",
+		Overridables: nfpm.Overridables{
+			Depends:   []string{"libc", "libubox"},
+			Conflicts: []string{"bar"},
+			Contents: files.Contents{
+				{Source: bin, Destination: "/usr/bin/foo"},
+				{Source: "./testdata/testfile.txt", Destination: "/etc/foo.conf", Type: "config"},
+				{Source: "./testdata/testfile.txt", Destination: "/etc/foo.rpm", Packager: "rpm"},
+				{Source: "/usr/bin/foo", Destination: "/usr/sbin/foo", Type: "symlink"},
+				{Destination: "/var/lib/foo", Type: "dir"},
+			},
+			Scripts: nfpm.Scripts{PostInstall: script},
+		},
+	}
+	require.NoError(t, nfpm.Validate(info))
+
+	var out bytes.Buffer
+	require.NoError(t, ipk{cfg: config.NFPMIPK{
+		ABIVersion: "1",
+		Essential:  true,
+		Predepends: []string{"busybox"},
+		Tags:       []string{"net", "tools"},
+		Fields:     map[string]string{"SourceName": "foo", "Bugs": "https://example.com"},
+	}}.Package(info, &out))
+
+	outer := readTarGz(t, out.Bytes())
+	require.Equal(t, []string{"./debian-binary", "./data.tar.gz", "./control.tar.gz"}, outer.names)
+	require.Equal(t, "2.0\n", string(outer.files["./debian-binary"]))
+
+	control := readTarGz(t, outer.files["./control.tar.gz"])
+	require.ElementsMatch(t, []string{"./control", "./conffiles", "./postinst"}, control.names)
+	require.Equal(t, "/etc/foo.conf\n", string(control.files["./conffiles"]))
+	require.Equal(t, int64(0o755), control.modes["./postinst"])
+	require.Equal(t, `Package: foo
+Version: 2:1.2.3
+Depends: libc, libubox
+Pre-Depends: busybox
+Conflicts: bar
+Section: utils
+Priority: optional
+Maintainer: me@me
+License: MIT
+Homepage: https://goreleaser.com
+Architecture: arm_cortex-a7_neon-vfpv4
+Installed-Size: 25
+ABIVersion: 1
+Essential: yes
+Tags: net, tools
+Bugs: https://example.com
+SourceName: foo
+Description: Some description
+ in two lines
+`, string(control.files["./control"]))
+
+	data := readTarGz(t, outer.files["./data.tar.gz"])
+	require.Equal(t, []string{
+		"./etc/",
+		"./etc/foo.conf",
+		"./usr/",
+		"./usr/bin/",
+		"./usr/bin/foo",
+		"./usr/sbin/",
+		"./usr/sbin/foo",
+		"./var/",
+		"./var/lib/",
+		"./var/lib/foo/",
+	}, data.names)
+	require.Equal(t, "binary", string(data.files["./usr/bin/foo"]))
+	require.Equal(t, int64(0o755), data.modes["./usr/bin/foo"])
+	require.Equal(t, "/usr/bin/foo", data.links["./usr/sbin/foo"])
+}
+
+func TestRunPipeIPK(t *testing.T) {
+	folder := t.TempDir()
+	dist := filepath.Join(folder, "dist")
+	require.NoError(t, os.Mkdir(dist, 0o755))
+	binPath := filepath.Join(dist, "mybin")
+	require.NoError(t, os.WriteFile(binPath, []byte("binary"), 0o755))
+	ctx := context.New(config.Project{
+		ProjectName: "mybin",
+		Dist:        dist,
+		NFPMs: []config.NFPM{
+			{
+				ID:          "someid",
+				Builds:      []string{"default"},
+				Formats:     []string{"ipk"},
+				Description: "Some description",
+				Maintainer:  "me@me",
+				NFPMOverridables: config.NFPMOverridables{
+					FileNameTemplate: "{{ .ConventionalFileName }}",
+					IPK: config.NFPMIPK{
+						ABIVersion: "1",
+					},
+				},
+			},
+		},
+	})
+	ctx.Version = "1.0.0"
+	ctx.Git = context.GitInfo{CurrentTag: "v1.0.0"}
+	for _, goarch := range []string{"mips", "arm"} {
+		art := &artifact.Artifact{
+			Name:   "mybin",
+			Path:   binPath,
+			Goarch: goarch,
+			Goos:   "linux",
+			Type:   artifact.Binary,
+			Extra: map[string]interface{}{
+				artifact.ExtraID: "default",
+			},
+		}
+		if goarch == "mips" {
+			art.Gomips = "softfloat"
+		} else {
+			art.Goarm = "7"
+		}
+		ctx.Artifacts.Add(art)
+	}
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.NoError(t, Pipe{}.Run(ctx))
+	packages := ctx.Artifacts.Filter(artifact.ByType(artifact.LinuxPackage)).List()
+	var names []string
+	for _, pkg := range packages {
+		require.Equal(t, "ipk", pkg.Format())
+		require.FileExists(t, pkg.Path)
+		names = append(names, pkg.Name)
+	}
+	require.ElementsMatch(t, []string{
+		"mybin_1.0.0_mips_24kc.ipk",
+		"mybin_1.0.0_arm_cortex-a7_neon-vfpv4.ipk",
+	}, names)
+}
+
+type tarContents struct {
+	names []string
+	files map[string][]byte
+	modes map[string]int64
+	links map[string]string
+}
+
+func readTarGz(t *testing.T, b []byte) tarContents {
+	t.Helper()
+	result := tarContents{
+		files: map[string][]byte{},
+		modes: map[string]int64{},
+		links: map[string]string{},
+	}
+	gr, err := gzip.NewReader(bytes.NewReader(b))
+	require.NoError(t, err)
+	tr := tar.NewReader(gr)
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		result.names = append(result.names, h.Name)
+		result.modes[h.Name] = h.Mode
+		result.links[h.Name] = h.Linkname
+		body, err := io.ReadAll(tr)
+		require.NoError(t, err)
+		result.files[h.Name] = body
+	}
+	return result
+}
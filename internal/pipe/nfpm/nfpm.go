@@ -10,9 +10,12 @@ import (
 	"github.com/apex/log"
 	"github.com/goreleaser/goreleaser/internal/artifact"
 	"github.com/goreleaser/goreleaser/internal/deprecate"
+	"github.com/goreleaser/goreleaser/internal/gio"
 	"github.com/goreleaser/goreleaser/internal/ids"
+	"github.com/goreleaser/goreleaser/internal/installfiles"
 	"github.com/goreleaser/goreleaser/internal/pipe"
 	"github.com/goreleaser/goreleaser/internal/semerrgroup"
+	"github.com/goreleaser/goreleaser/internal/sharedlib"
 	"github.com/goreleaser/goreleaser/internal/tmpl"
 	"github.com/goreleaser/goreleaser/pkg/config"
 	"github.com/goreleaser/goreleaser/pkg/context"
@@ -54,8 +57,19 @@ func (Pipe) Default(ctx *context.Context) error {
 		if fpm.FileNameTemplate == "" {
 			fpm.FileNameTemplate = defaultNameTemplate
 		}
+		if fpm.Changelog.Enabled && len(fpm.Changelog.Distributions) == 0 {
+			fpm.Changelog.Distributions = []string{"stable"}
+		}
+		for j := range fpm.Systemd {
+			if fpm.Systemd[j].Name == "" {
+				fpm.Systemd[j].Name = fpm.PackageName
+			}
+		}
 		if len(fpm.Builds) == 0 { // TODO: change this to empty by default and deal with it in the filtering code
 			for _, b := range ctx.Config.Builds {
+				if b.Variant != nil {
+					continue
+				}
 				fpm.Builds = append(fpm.Builds, b.ID)
 			}
 		}
@@ -86,16 +100,24 @@ func doRun(ctx *context.Context, fpm config.NFPM) error {
 		artifact.ByGoos("linux"),
 		artifact.ByIDs(fpm.Builds...),
 	)).GroupByPlatform()
-	if len(linuxBinaries) == 0 {
-		return fmt.Errorf("no linux binaries found for builds %v", fpm.Builds)
+	changelog, err := writeChangelog(ctx, fpm)
+	if err != nil {
+		return fmt.Errorf("nfpm %s: failed to write changelog: %w", fpm.ID, err)
 	}
 	g := semerrgroup.New(ctx.Parallelism)
 	for _, format := range fpm.Formats {
-		for _, artifacts := range linuxBinaries {
+		binaries := linuxBinaries
+		if format == termuxFormat {
+			binaries = termuxBinaries(ctx, fpm)
+		}
+		if len(binaries) == 0 {
+			return fmt.Errorf("no linux binaries found for builds %v", fpm.Builds)
+		}
+		for _, artifacts := range binaries {
 			format := format
 			artifacts := artifacts
 			g.Go(func() error {
-				return create(ctx, fpm, format, artifacts)
+				return create(ctx, fpm, format, artifacts, changelog)
 			})
 		}
 	}
@@ -117,7 +139,7 @@ func mergeOverrides(fpm config.NFPM, format string) (*config.NFPMOverridables, e
 	return &overridden, nil
 }
 
-func create(ctx *context.Context, fpm config.NFPM, format string, binaries []*artifact.Artifact) error {
+func create(ctx *context.Context, fpm config.NFPM, format string, binaries []*artifact.Artifact, changelog string) error {
 	arch := binaries[0].Goarch + binaries[0].Goarm + binaries[0].Gomips
 
 	overridden, err := mergeOverrides(fpm, format)
@@ -181,21 +203,62 @@ func create(ctx *context.Context, fpm config.NFPM, format string, binaries []*ar
 		})
 	}
 
-	log := log.WithField("package", fpm.PackageName).WithField("format", format).WithField("arch", arch)
+	log := log.FromContext(ctx).WithField("package", fpm.PackageName).WithField("format", format).WithField("arch", arch)
 
 	// FPM meta package should not contain binaries at all
 	if !fpm.Meta {
 		for _, binary := range binaries {
+			if fpm.SharedLibrary.Enabled && sharedlib.IsShared(ctx, binary) {
+				libs, err := sharedLibrary(ctx, fpm, format, binary)
+				if err != nil {
+					return err
+				}
+				contents = append(contents, libs...)
+				continue
+			}
 			src := binary.Path
 			dst := filepath.Join(binDir, binary.Name)
 			log.WithField("src", src).WithField("dst", dst).Debug("adding binary to package")
-			contents = append(contents, &files.Content{
+			content := &files.Content{
 				Source:      filepath.ToSlash(src),
 				Destination: filepath.ToSlash(dst),
+			}
+			if fpm.NormalizeModes {
+				content.FileInfo = &files.ContentFileInfo{Mode: 0o755}
+			}
+			contents = append(contents, content)
+		}
+
+		extra, err := installfiles.Find(ctx)
+		if err != nil {
+			return err
+		}
+		for _, f := range extra {
+			log.WithField("src", f.Source).WithField("dst", f.PackagePath()).Debug("adding file to package")
+			contents = append(contents, &files.Content{
+				Source:      filepath.ToSlash(f.Source),
+				Destination: f.PackagePath(),
 			})
 		}
 	}
 
+	units, scripts, err := systemdUnits(ctx, fpm, overridden, format, binDir, binaries)
+	if err != nil {
+		return err
+	}
+	contents = append(contents, units...)
+
+	if fpm.NormalizeModes {
+		contents, err = normalizeModes(contents)
+		if err != nil {
+			return fmt.Errorf("nfpm %s: %w", fpm.ID, err)
+		}
+	}
+
+	if format == termuxFormat {
+		contents = termuxContents(contents)
+	}
+
 	log.WithField("files", destinations(contents)).Debug("all archive files")
 
 	info := &nfpm.Info{
@@ -214,6 +277,7 @@ func create(ctx *context.Context, fpm config.NFPM, format string, binaries []*ar
 		Vendor:          fpm.Vendor,
 		Homepage:        homepage,
 		License:         fpm.License,
+		Changelog:       changelog,
 		Overridables: nfpm.Overridables{
 			Conflicts:    overridden.Conflicts,
 			Depends:      overridden.Dependencies,
@@ -223,10 +287,10 @@ func create(ctx *context.Context, fpm config.NFPM, format string, binaries []*ar
 			EmptyFolders: overridden.EmptyFolders,
 			Contents:     contents,
 			Scripts: nfpm.Scripts{
-				PreInstall:  overridden.Scripts.PreInstall,
-				PostInstall: overridden.Scripts.PostInstall,
-				PreRemove:   overridden.Scripts.PreRemove,
-				PostRemove:  overridden.Scripts.PostRemove,
+				PreInstall:  scripts.PreInstall,
+				PostInstall: scripts.PostInstall,
+				PreRemove:   scripts.PreRemove,
+				PostRemove:  scripts.PostRemove,
 			},
 			Deb: nfpm.Deb{
 				Scripts: nfpm.DebScripts{
@@ -281,6 +345,10 @@ func create(ctx *context.Context, fpm config.NFPM, format string, binaries []*ar
 		},
 	}
 
+	if format == termuxFormat {
+		info.Deb.Arch = termuxArch(binaries[0])
+	}
+
 	if ctx.SkipSign {
 		info.APK.Signature = nfpm.APKSignature{}
 		info.RPM.Signature = nfpm.RPMSignature{}
@@ -291,7 +359,7 @@ func create(ctx *context.Context, fpm config.NFPM, format string, binaries []*ar
 		return fmt.Errorf("invalid nfpm config: %w", err)
 	}
 
-	packager, err := nfpm.Get(format)
+	packager, err := packagerFor(format, overridden)
 	if err != nil {
 		return err
 	}
@@ -303,7 +371,9 @@ func create(ctx *context.Context, fpm config.NFPM, format string, binaries []*ar
 	if err != nil {
 		return err
 	}
-	if !strings.HasSuffix(name, "."+format) {
+	// termux debs might be named as debs, e.g. with the conventional file
+	// name.
+	if !strings.HasSuffix(name, "."+format) && !(format == termuxFormat && strings.HasSuffix(name, ".deb")) {
 		name = name + "." + format
 	}
 
@@ -337,6 +407,18 @@ func create(ctx *context.Context, fpm config.NFPM, format string, binaries []*ar
 	return nil
 }
 
+// packagerFor returns the packager for the given format.
+// Formats nFPM doesn't support are created by GoReleaser itself.
+func packagerFor(format string, overridden *config.NFPMOverridables) (nfpm.Packager, error) {
+	switch format {
+	case ipkFormat:
+		return ipk{cfg: overridden.IPK}, nil
+	case termuxFormat:
+		return nfpm.Get("deb")
+	}
+	return nfpm.Get(format)
+}
+
 func destinations(contents files.Contents) []string {
 	result := make([]string, 0, len(contents))
 	for _, f := range contents {
@@ -363,3 +445,87 @@ func getPassphraseFromEnv(ctx *context.Context, packager string, nfpmID string)
 
 	return passphrase
 }
+
+// sharedLibrary returns the contents to install the given c-shared binary as a
+// shared library.
+// The symlinks and pkg-config file are written next to the binary, as the
+// package is only created later on.
+func sharedLibrary(ctx *context.Context, fpm config.NFPM, format string, binary *artifact.Artifact) (files.Contents, error) {
+	dir := filepath.Join(ctx.Config.Dist, "sharedlib", fpm.ID+"_"+format+"_"+binary.Goarch+binary.Goarm+binary.Gomips)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	prefix, err := tmpl.New(ctx).WithArtifact(binary, nil).Apply(fpm.SharedLibrary.Prefix)
+	if err != nil {
+		return nil, fmt.Errorf("nfpm %s: failed to apply shared library prefix template: %w", fpm.ID, err)
+	}
+	if prefix == "" {
+		prefix = "/usr"
+	}
+	libs, err := sharedlib.Files(ctx, fpm.SharedLibrary, binary, prefix, dir)
+	if err != nil {
+		return nil, fmt.Errorf("nfpm %s: %w", fpm.ID, err)
+	}
+	var result files.Contents
+	for _, lib := range libs {
+		if lib.Link != "" {
+			result = append(result, &files.Content{
+				Source:      lib.Link,
+				Destination: lib.Destination,
+				Type:        "symlink",
+			})
+			continue
+		}
+		result = append(result, &files.Content{
+			Source:      filepath.ToSlash(lib.Source),
+			Destination: lib.Destination,
+		})
+	}
+	return result, nil
+}
+
+// normalizeModes expands the regular files without an explicit mode, and sets
+// their mode to 0755 if they are executable, and to 0644 otherwise.
+// Each file gets its own file info, as nfpm shares it between all the files
+// matched by a glob.
+func normalizeModes(contents files.Contents) (files.Contents, error) {
+	var result files.Contents
+	for _, content := range contents {
+		switch content.Type {
+		case "", "config", "config|noreplace":
+		default:
+			result = append(result, content)
+			continue
+		}
+		if content.FileInfo != nil && content.FileInfo.Mode != 0 {
+			result = append(result, content)
+			continue
+		}
+
+		var orig files.ContentFileInfo
+		if content.FileInfo != nil {
+			orig = *content.FileInfo
+		}
+		expanded, err := files.ExpandContentGlobs(files.Contents{{
+			Source:      content.Source,
+			Destination: content.Destination,
+			Type:        content.Type,
+			Packager:    content.Packager,
+			FileInfo:    &files.ContentFileInfo{},
+		}}, false)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range expanded {
+			stat, err := os.Stat(f.Source)
+			if err != nil {
+				return nil, fmt.Errorf("failed to normalize mode of %s: %w", f.Source, err)
+			}
+			info := orig
+			info.Mode = gio.NormalizeMode(stat.Mode())
+			f.FileInfo = &info
+			result = append(result, f)
+		}
+	}
+	return result, nil
+}
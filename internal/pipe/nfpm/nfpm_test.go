@@ -1223,3 +1223,184 @@ func sources(contents files.Contents) []string {
 	}
 	return result
 }
+
+func TestRunPipeCompletionsAndManpages(t *testing.T) {
+	folder := t.TempDir()
+	dist := filepath.Join(folder, "dist")
+	require.NoError(t, os.Mkdir(dist, 0o755))
+	binPath := filepath.Join(dist, "mybin")
+	require.NoError(t, os.WriteFile(binPath, []byte("bin"), 0o755))
+	ctx := context.New(config.Project{
+		ProjectName: "mybin",
+		Dist:        dist,
+		Completions: config.Completions{
+			Bash: "testdata/testfile.txt",
+			Zsh:  "testdata/testfile.txt",
+		},
+		NFPMs: []config.NFPM{
+			{
+				ID:          "someid",
+				Bindir:      "/usr/bin",
+				Builds:      []string{"default"},
+				Formats:     []string{"deb"},
+				Description: "Some description",
+				Maintainer:  "me@me",
+				NFPMOverridables: config.NFPMOverridables{
+					PackageName: "foo",
+				},
+			},
+		},
+	})
+	ctx.Version = "1.0.0"
+	ctx.Git = context.GitInfo{CurrentTag: "v1.0.0"}
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name:   "mybin",
+		Path:   binPath,
+		Goarch: "amd64",
+		Goos:   "linux",
+		Type:   artifact.Binary,
+		Extra: map[string]interface{}{
+			artifact.ExtraID: "default",
+		},
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.NoError(t, Pipe{}.Run(ctx))
+	packages := ctx.Artifacts.Filter(artifact.ByType(artifact.LinuxPackage)).List()
+	require.Len(t, packages, 1)
+	require.ElementsMatch(t, []string{
+		"/usr/bin/mybin",
+		"/usr/share/bash-completion/completions/testfile.txt",
+		"/usr/share/zsh/vendor-completions/_testfile.txt",
+	}, destinations(packages[0].ExtraOr(extraFiles, files.Contents{}).(files.Contents)))
+}
+
+func TestRunPipeSharedLibrary(t *testing.T) {
+	folder := t.TempDir()
+	dist := filepath.Join(folder, "dist")
+	require.NoError(t, os.Mkdir(dist, 0o755))
+	libPath := filepath.Join(dist, "libfoo")
+	require.NoError(t, os.WriteFile(libPath, []byte("lib"), 0o755))
+	require.NoError(t, os.WriteFile(libPath+".h", []byte("header"), 0o644))
+	ctx := context.New(config.Project{
+		ProjectName: "foo",
+		Dist:        dist,
+		Builds: []config.Build{
+			{ID: "default", Flags: []string{"-buildmode=c-shared"}},
+		},
+		NFPMs: []config.NFPM{
+			{
+				ID:          "someid",
+				Builds:      []string{"default"},
+				Formats:     []string{"deb", "rpm"},
+				Description: "Some description",
+				Maintainer:  "me@me",
+				NFPMOverridables: config.NFPMOverridables{
+					PackageName: "libfoo",
+				},
+				SharedLibrary: config.SharedLibrary{
+					Enabled: true,
+				},
+			},
+		},
+	})
+	ctx.Version = "1.2.3"
+	ctx.Git = context.GitInfo{CurrentTag: "v1.2.3"}
+	ctx.Semver = context.Semver{Major: 1, Minor: 2, Patch: 3}
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name:   "libfoo",
+		Path:   libPath,
+		Goarch: "amd64",
+		Goos:   "linux",
+		Type:   artifact.Binary,
+		Extra: map[string]interface{}{
+			artifact.ExtraID:     "default",
+			artifact.ExtraBinary: "libfoo",
+		},
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.NoError(t, Pipe{}.Run(ctx))
+	packages := ctx.Artifacts.Filter(artifact.ByType(artifact.LinuxPackage)).List()
+	require.Len(t, packages, 2)
+	for _, pkg := range packages {
+		contents := pkg.ExtraOr(extraFiles, files.Contents{}).(files.Contents)
+		require.ElementsMatch(t, []string{
+			"/usr/lib/libfoo.so.1.2.3",
+			"/usr/lib/libfoo.so.1",
+			"/usr/lib/libfoo.so",
+			"/usr/include/libfoo.h",
+			"/usr/lib/pkgconfig/foo.pc",
+		}, destinations(contents))
+		for _, c := range contents {
+			if c.Destination == "/usr/lib/libfoo.so" {
+				require.Equal(t, "symlink", c.Type)
+				require.Equal(t, "libfoo.so.1", c.Source)
+			}
+		}
+	}
+}
+
+func TestNormalizeModes(t *testing.T) {
+	dir := t.TempDir()
+	for name, mode := range map[string]os.FileMode{
+		"conf/a.conf": 0o600,
+		"conf/b.conf": 0o664,
+		"scripts/run": 0o700,
+		"secret":      0o600,
+	} {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(name), mode))
+		require.NoError(t, os.Chmod(path, mode))
+	}
+
+	result, err := normalizeModes(files.Contents{
+		{
+			Source:      filepath.Join(dir, "conf/*.conf"),
+			Destination: "/etc/foo",
+			Type:        "config",
+			FileInfo:    &files.ContentFileInfo{Owner: "foo"},
+		},
+		{
+			Source:      filepath.Join(dir, "scripts/run"),
+			Destination: "/usr/libexec/foo/run",
+		},
+		{
+			Source:      filepath.Join(dir, "secret"),
+			Destination: "/etc/foo/secret",
+			FileInfo:    &files.ContentFileInfo{Mode: 0o600},
+		},
+		{
+			Source:      "/usr/libexec/foo/run",
+			Destination: "/usr/bin/foo-run",
+			Type:        "symlink",
+		},
+	})
+	require.NoError(t, err)
+
+	modes := map[string]os.FileMode{}
+	for _, c := range result {
+		if c.FileInfo == nil {
+			modes[c.Destination] = 0
+			continue
+		}
+		modes[c.Destination] = c.FileInfo.Mode
+		if c.Type == "config" {
+			require.Equal(t, "foo", c.FileInfo.Owner)
+		}
+	}
+	require.Equal(t, map[string]os.FileMode{
+		"/etc/foo/a.conf":      0o644,
+		"/etc/foo/b.conf":      0o644,
+		"/usr/libexec/foo/run": 0o755,
+		"/etc/foo/secret":      0o600,
+		"/usr/bin/foo-run":     0,
+	}, modes)
+}
+
+func TestNormalizeModesNoMatch(t *testing.T) {
+	_, err := normalizeModes(files.Contents{{
+		Source:      "testdata/nope/*",
+		Destination: "/etc/nope",
+	}})
+	require.Error(t, err)
+}
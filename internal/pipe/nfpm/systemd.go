@@ -0,0 +1,209 @@
+package nfpm
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/goreleaser/nfpm/v2/files"
+)
+
+// systemdUnitDirs maps the formats that can install systemd units to the
+// folder they are installed to.
+var systemdUnitDirs = map[string]string{
+	"deb": "/lib/systemd/system",
+	"rpm": "/usr/lib/systemd/system",
+	"apk": "/usr/lib/systemd/system",
+}
+
+// systemdRemoving maps the formats that can install systemd units to the
+// condition telling the preremove script runs because the package is being
+// removed, rather than upgraded.
+var systemdRemoving = map[string]string{
+	"deb": `[ "$1" = "remove" ]`,
+	"rpm": `[ "$1" = "0" ]`,
+	"apk": `true`,
+}
+
+const defaultSystemdUnit = `[Unit]
+Description={{ .Description }}
+After=network-online.target
+Wants=network-online.target
+
+[Service]
+ExecStart={{ .ExecStart }}
+{{- with .User }}
+User={{ . }}
+{{- end }}
+Restart=on-failure
+
+[Install]
+WantedBy=multi-user.target
+`
+
+// systemdUnits returns the contents installing the systemd units of the given
+// nfpm config, and the scripts enabling and disabling them.
+// The units and scripts are written to the dist folder, as the package is only
+// created later on.
+// Formats that can't install systemd units are left untouched.
+func systemdUnits(ctx *context.Context, fpm config.NFPM, overridden *config.NFPMOverridables, format, binDir string, binaries []*artifact.Artifact) (files.Contents, config.NFPMScripts, error) {
+	scripts := overridden.Scripts
+	unitDir, ok := systemdUnitDirs[format]
+	if len(fpm.Systemd) == 0 || !ok {
+		return nil, scripts, nil
+	}
+
+	binary := binaries[0]
+	dir := filepath.Join(ctx.Config.Dist, "systemd", fpm.ID+"_"+format+"_"+binary.Goarch+binary.Goarm+binary.Gomips)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, scripts, err
+	}
+
+	var contents files.Contents
+	var units []config.NFPMSystemd
+	for _, cfg := range fpm.Systemd {
+		t := tmpl.New(ctx).
+			WithArtifact(binary, overridden.Replacements).
+			WithExtraFields(tmpl.Fields{
+				"Release":     fpm.Release,
+				"Epoch":       fpm.Epoch,
+				"PackageName": fpm.PackageName,
+			})
+		for _, field := range []*string{&cfg.Name, &cfg.Description, &cfg.ExecStart, &cfg.User} {
+			applied, err := t.Apply(*field)
+			if err != nil {
+				return nil, scripts, fmt.Errorf("nfpm %s: failed to apply systemd template: %w", fpm.ID, err)
+			}
+			*field = applied
+		}
+		if path.Ext(cfg.Name) == "" {
+			cfg.Name += ".service"
+		}
+		if cfg.Description == "" {
+			cfg.Description = strings.TrimSpace(strings.Split(strings.TrimSpace(fpm.Description), "\n")[0])
+		}
+		if cfg.ExecStart == "" {
+			cfg.ExecStart = path.Join(filepath.ToSlash(binDir), binary.Name)
+		}
+
+		unit := defaultSystemdUnit
+		if cfg.Template != "" {
+			bts, err := os.ReadFile(cfg.Template)
+			if err != nil {
+				return nil, scripts, fmt.Errorf("nfpm %s: failed to read systemd unit template: %w", fpm.ID, err)
+			}
+			unit = string(bts)
+		}
+		content, err := t.WithExtraFields(tmpl.Fields{
+			"UnitName":    cfg.Name,
+			"Description": cfg.Description,
+			"ExecStart":   cfg.ExecStart,
+			"User":        cfg.User,
+		}).Apply(unit)
+		if err != nil {
+			return nil, scripts, fmt.Errorf("nfpm %s: failed to apply systemd unit template: %w", fpm.ID, err)
+		}
+
+		src := filepath.Join(dir, cfg.Name)
+		if err := os.WriteFile(src, []byte(content), 0o644); err != nil { //nolint: gosec
+			return nil, scripts, err
+		}
+		contents = append(contents, &files.Content{
+			Source:      filepath.ToSlash(src),
+			Destination: path.Join(unitDir, cfg.Name),
+			FileInfo:    &files.ContentFileInfo{Mode: 0o644},
+		})
+		units = append(units, cfg)
+	}
+
+	postinstall, err := withScript(systemdPostInstall(units), scripts.PostInstall, true)
+	if err != nil {
+		return nil, scripts, fmt.Errorf("nfpm %s: %w", fpm.ID, err)
+	}
+	preremove, err := withScript(systemdPreRemove(format, units), scripts.PreRemove, false)
+	if err != nil {
+		return nil, scripts, fmt.Errorf("nfpm %s: %w", fpm.ID, err)
+	}
+	scripts.PostInstall = filepath.Join(dir, "postinstall.sh")
+	scripts.PreRemove = filepath.Join(dir, "preremove.sh")
+	for name, content := range map[string]string{
+		scripts.PostInstall: postinstall,
+		scripts.PreRemove:   preremove,
+	} {
+		if err := os.WriteFile(name, []byte(content), 0o755); err != nil { //nolint: gosec
+			return nil, scripts, err
+		}
+	}
+	return contents, scripts, nil
+}
+
+// systemdPostInstall reloads systemd after the units are installed, enabling
+// and starting them as configured.
+func systemdPostInstall(units []config.NFPMSystemd) string {
+	var sb strings.Builder
+	sb.WriteString("# reload systemd, enabling and starting the units of the package\n")
+	sb.WriteString("if command -v systemctl >/dev/null 2>&1; then\n")
+	sb.WriteString("\tsystemctl daemon-reload >/dev/null 2>&1 || true\n")
+	for _, unit := range units {
+		if !unit.SkipEnable {
+			fmt.Fprintf(&sb, "\tsystemctl enable %s >/dev/null 2>&1 || true\n", unit.Name)
+		}
+		if unit.Start {
+			fmt.Fprintf(&sb, "\tsystemctl restart %s >/dev/null 2>&1 || true\n", unit.Name)
+		}
+	}
+	sb.WriteString("fi\n")
+	return sb.String()
+}
+
+// systemdPreRemove stops and disables the units when the package is removed,
+// but not when it is upgraded.
+func systemdPreRemove(format string, units []config.NFPMSystemd) string {
+	var sb strings.Builder
+	sb.WriteString("# stop and disable the units of the package when it is removed\n")
+	fmt.Fprintf(&sb, "if %s && command -v systemctl >/dev/null 2>&1; then\n", systemdRemoving[format])
+	for _, unit := range units {
+		fmt.Fprintf(&sb, "\tsystemctl disable --now %s >/dev/null 2>&1 || true\n", unit.Name)
+	}
+	sb.WriteString("fi\n")
+	return sb.String()
+}
+
+// withScript combines the generated snippet with the script at the given path,
+// if any, keeping its shebang.
+// The script runs before the snippet if snippetLast is true, in a subshell so
+// an exit doesn't skip the snippet, and after it otherwise.
+func withScript(snippet, script string, snippetLast bool) (string, error) {
+	shebang := "#!/bin/sh\n"
+	if script == "" {
+		return shebang + snippet, nil
+	}
+	bts, err := os.ReadFile(script)
+	if err != nil {
+		return "", fmt.Errorf("failed to read script: %w", err)
+	}
+	content := string(bts)
+	if strings.HasPrefix(content, "#!") {
+		i := strings.Index(content, "\n")
+		if i < 0 {
+			i = len(content) - 1
+		}
+		shebang, content = content[:i+1], content[i+1:]
+		if !strings.HasSuffix(shebang, "\n") {
+			shebang += "\n"
+		}
+	}
+	if !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	if snippetLast {
+		return shebang + "(\n" + content + ") || exit $?\n\n" + snippet, nil
+	}
+	return shebang + snippet + "\n" + content, nil
+}
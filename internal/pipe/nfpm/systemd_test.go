@@ -0,0 +1,182 @@
+package nfpm
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/goreleaser/nfpm/v2/files"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunPipeSystemd(t *testing.T) {
+	folder := t.TempDir()
+	dist := filepath.Join(folder, "dist")
+	require.NoError(t, os.Mkdir(dist, 0o755))
+	binPath := filepath.Join(dist, "mybin")
+	require.NoError(t, os.WriteFile(binPath, []byte("binary"), 0o755))
+	tmplPath := filepath.Join(folder, "worker.service.tpl")
+	require.NoError(t, os.WriteFile(tmplPath, []byte("[Service]\nExecStart={{ .ExecStart }} worker --version {{ .Version }}\n"), 0o644))
+	postinstall := filepath.Join(folder, "postinstall.sh")
+	require.NoError(t, os.WriteFile(postinstall, []byte("#!/bin/bash\nuseradd mybin\n"), 0o755))
+
+	ctx := context.New(config.Project{
+		ProjectName: "mybin",
+		Dist:        dist,
+		NFPMs: []config.NFPM{
+			{
+				ID:          "someid",
+				Builds:      []string{"default"},
+				Formats:     []string{"deb", "rpm"},
+				Description: "Some description\nwith details",
+				Maintainer:  "me@me",
+				Systemd: []config.NFPMSystemd{
+					{
+						User:  "{{ .ProjectName }}",
+						Start: true,
+					},
+					{
+						Name:       "mybin-worker",
+						Template:   tmplPath,
+						ExecStart:  "/usr/bin/mybin",
+						SkipEnable: true,
+					},
+				},
+				NFPMOverridables: config.NFPMOverridables{
+					Scripts: config.NFPMScripts{
+						PostInstall: postinstall,
+					},
+				},
+			},
+		},
+	})
+	ctx.Version = "1.0.0"
+	ctx.Git = context.GitInfo{CurrentTag: "v1.0.0"}
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name:   "mybin",
+		Path:   binPath,
+		Goarch: "amd64",
+		Goos:   "linux",
+		Type:   artifact.Binary,
+		Extra: map[string]interface{}{
+			artifact.ExtraID: "default",
+		},
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.NoError(t, Pipe{}.Run(ctx))
+
+	packages := ctx.Artifacts.Filter(artifact.ByType(artifact.LinuxPackage)).List()
+	require.Len(t, packages, 2)
+	for _, pkg := range packages {
+		unitDir := "/lib/systemd/system"
+		removing := `[ "$1" = "remove" ]`
+		if pkg.Format() == "rpm" {
+			unitDir = "/usr/lib/systemd/system"
+			removing = `[ "$1" = "0" ]`
+		}
+		contents := pkg.Extra[extraFiles].(files.Contents)
+		require.Contains(t, destinations(contents), unitDir+"/mybin.service")
+		require.Contains(t, destinations(contents), unitDir+"/mybin-worker.service")
+
+		dir := filepath.Join(dist, "systemd", "someid_"+pkg.Format()+"_amd64")
+		unit, err := os.ReadFile(filepath.Join(dir, "mybin.service"))
+		require.NoError(t, err)
+		require.Equal(t, `[Unit]
+Description=Some description
+After=network-online.target
+Wants=network-online.target
+
+[Service]
+ExecStart=/usr/local/bin/mybin
+User=mybin
+Restart=on-failure
+
+[Install]
+WantedBy=multi-user.target
+`, string(unit))
+
+		unit, err = os.ReadFile(filepath.Join(dir, "mybin-worker.service"))
+		require.NoError(t, err)
+		require.Equal(t, "[Service]\nExecStart=/usr/bin/mybin worker --version 1.0.0\n", string(unit))
+
+		script, err := os.ReadFile(filepath.Join(dir, "postinstall.sh"))
+		require.NoError(t, err)
+		require.Equal(t, `#!/bin/bash
+(
+useradd mybin
+) || exit $?
+
+# reload systemd, enabling and starting the units of the package
+if command -v systemctl >/dev/null 2>&1; then
+	systemctl daemon-reload >/dev/null 2>&1 || true
+	systemctl enable mybin.service >/dev/null 2>&1 || true
+	systemctl restart mybin.service >/dev/null 2>&1 || true
+fi
+`, string(script))
+
+		script, err = os.ReadFile(filepath.Join(dir, "preremove.sh"))
+		require.NoError(t, err)
+		require.Equal(t, `#!/bin/sh
+# stop and disable the units of the package when it is removed
+if `+removing+` && command -v systemctl >/dev/null 2>&1; then
+	systemctl disable --now mybin.service >/dev/null 2>&1 || true
+	systemctl disable --now mybin-worker.service >/dev/null 2>&1 || true
+fi
+`, string(script))
+	}
+}
+
+func TestRunPipeSystemdUnsupportedFormat(t *testing.T) {
+	ctx := context.New(config.Project{Dist: t.TempDir()})
+	fpm := config.NFPM{
+		ID:      "someid",
+		Systemd: []config.NFPMSystemd{{Name: "foo"}},
+	}
+	overridden := &config.NFPMOverridables{
+		Scripts: config.NFPMScripts{PostInstall: "foo.sh"},
+	}
+	contents, scripts, err := systemdUnits(ctx, fpm, overridden, "ipk", "/usr/bin", []*artifact.Artifact{{Name: "foo"}})
+	require.NoError(t, err)
+	require.Empty(t, contents)
+	require.Equal(t, overridden.Scripts, scripts)
+}
+
+func TestRunPipeSystemdInvalidTemplate(t *testing.T) {
+	for name, cfg := range map[string]config.NFPMSystemd{
+		"name":     {Name: "{{ .Nope }"},
+		"template": {Template: "/does/not/exist"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.New(config.Project{Dist: t.TempDir()})
+			fpm := config.NFPM{
+				ID:      "someid",
+				Systemd: []config.NFPMSystemd{cfg},
+			}
+			_, _, err := systemdUnits(ctx, fpm, &config.NFPMOverridables{}, "deb", "/usr/bin", []*artifact.Artifact{{Name: "foo"}})
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestWithScript(t *testing.T) {
+	script := filepath.Join(t.TempDir(), "script.sh")
+	require.NoError(t, os.WriteFile(script, []byte("echo hi"), 0o755))
+
+	out, err := withScript("snippet\n", "", true)
+	require.NoError(t, err)
+	require.Equal(t, "#!/bin/sh\nsnippet\n", out)
+
+	out, err = withScript("snippet\n", script, true)
+	require.NoError(t, err)
+	require.Equal(t, "#!/bin/sh\n(\necho hi\n) || exit $?\n\nsnippet\n", out)
+
+	out, err = withScript("snippet\n", script, false)
+	require.NoError(t, err)
+	require.Equal(t, "#!/bin/sh\nsnippet\n\necho hi\n", out)
+
+	_, err = withScript("snippet\n", script+".nope", false)
+	require.Error(t, err)
+}
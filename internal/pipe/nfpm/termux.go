@@ -0,0 +1,93 @@
+package nfpm
+
+import (
+	"path"
+	"strings"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/goreleaser/nfpm/v2/files"
+)
+
+const (
+	termuxFormat = "termux.deb"
+
+	// termuxPrefix is where Termux installs packages on Android, as it can't
+	// write to /usr.
+	termuxPrefix = "/data/data/com.termux/files/usr"
+)
+
+// termuxArches maps go architectures to the Termux ones.
+var termuxArches = map[string]string{
+	"386":   "i686",
+	"amd64": "x86_64",
+	"arm64": "aarch64",
+	"arm5":  "arm",
+	"arm6":  "arm",
+	"arm7":  "arm",
+}
+
+// termuxArch returns the Termux architecture of the given binary, or an empty
+// string if Termux doesn't run on it.
+func termuxArch(a *artifact.Artifact) string {
+	return termuxArches[a.Goarch+a.Goarm]
+}
+
+// termuxBinaries returns the binaries to package as Termux debs, grouped by
+// platform.
+// Android binaries are preferred, falling back to the linux ones, which run
+// on Termux as well if they are static.
+func termuxBinaries(ctx *context.Context, fpm config.NFPM) map[string][]*artifact.Artifact {
+	for _, goos := range []string{"android", "linux"} {
+		binaries := ctx.Artifacts.Filter(artifact.And(
+			artifact.ByType(artifact.Binary),
+			artifact.ByGoos(goos),
+			artifact.ByIDs(fpm.Builds...),
+			func(a *artifact.Artifact) bool { return termuxArch(a) != "" },
+		)).GroupByPlatform()
+		if len(binaries) > 0 {
+			return binaries
+		}
+	}
+	return nil
+}
+
+// termuxPath moves the given destination under the Termux prefix, e.g.
+// /usr/local/bin/foo is installed at $PREFIX/bin/foo and /etc/foo.conf at
+// $PREFIX/etc/foo.conf.
+func termuxPath(dst string) string {
+	if dst == termuxPrefix || strings.HasPrefix(dst, termuxPrefix+"/") {
+		return dst
+	}
+	dst = path.Join("/", dst)
+	for _, prefix := range []string{"/usr/local", "/usr"} {
+		if dst == prefix || strings.HasPrefix(dst, prefix+"/") {
+			dst = strings.TrimPrefix(dst, prefix)
+			break
+		}
+	}
+	return path.Join(termuxPrefix, dst)
+}
+
+// termuxContents returns the given contents installed under the Termux
+// prefix.
+// Symlinks to absolute paths are moved as well.
+// Termux packages are created by the deb packager, so contents are filtered
+// by packager here.
+func termuxContents(contents files.Contents) files.Contents {
+	result := make(files.Contents, 0, len(contents))
+	for _, c := range contents {
+		if c.Packager != "" && c.Packager != termuxFormat {
+			continue
+		}
+		moved := *c
+		moved.Packager = ""
+		moved.Destination = termuxPath(c.Destination)
+		if c.Type == "symlink" && path.IsAbs(c.Source) {
+			moved.Source = termuxPath(c.Source)
+		}
+		result = append(result, &moved)
+	}
+	return result
+}
@@ -0,0 +1,121 @@
+package nfpm
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/goreleaser/nfpm/v2/files"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTermuxPath(t *testing.T) {
+	for dst, expected := range map[string]string{
+		"/usr/local/bin/foo":           "/data/data/com.termux/files/usr/bin/foo",
+		"/usr/bin/foo":                 "/data/data/com.termux/files/usr/bin/foo",
+		"/usr/share/man/man1/foo.1.gz": "/data/data/com.termux/files/usr/share/man/man1/foo.1.gz",
+		"/etc/foo.conf":                "/data/data/com.termux/files/usr/etc/foo.conf",
+		"var/lib/foo":                  "/data/data/com.termux/files/usr/var/lib/foo",
+		"/usrfoo":                      "/data/data/com.termux/files/usr/usrfoo",
+		"/data/data/com.termux/files/usr/bin/foo": "/data/data/com.termux/files/usr/bin/foo",
+	} {
+		require.Equal(t, expected, termuxPath(dst), dst)
+	}
+}
+
+func TestTermuxContents(t *testing.T) {
+	contents := termuxContents(files.Contents{
+		{Source: "foo", Destination: "/usr/bin/foo"},
+		{Source: "/usr/bin/foo", Destination: "/usr/bin/bar", Type: "symlink"},
+		{Source: "foo", Destination: "foo", Type: "symlink"},
+		{Source: "deb.conf", Destination: "/etc/deb.conf", Packager: "deb"},
+		{Source: "termux.conf", Destination: "/etc/termux.conf", Packager: "termux.deb"},
+	})
+	require.Equal(t, files.Contents{
+		{Source: "foo", Destination: "/data/data/com.termux/files/usr/bin/foo"},
+		{Source: "/data/data/com.termux/files/usr/bin/foo", Destination: "/data/data/com.termux/files/usr/bin/bar", Type: "symlink"},
+		{Source: "foo", Destination: "/data/data/com.termux/files/usr/foo", Type: "symlink"},
+		{Source: "termux.conf", Destination: "/data/data/com.termux/files/usr/etc/termux.conf"},
+	}, contents)
+}
+
+func TestRunPipeTermux(t *testing.T) {
+	for name, goos := range map[string]string{
+		"android": "android",
+		"linux":   "linux",
+	} {
+		t.Run(name, func(t *testing.T) {
+			folder := t.TempDir()
+			dist := filepath.Join(folder, "dist")
+			require.NoError(t, os.Mkdir(dist, 0o755))
+			binPath := filepath.Join(dist, "mybin")
+			require.NoError(t, os.WriteFile(binPath, []byte("binary"), 0o755))
+			ctx := context.New(config.Project{
+				ProjectName: "mybin",
+				Dist:        dist,
+				NFPMs: []config.NFPM{
+					{
+						ID:          "someid",
+						Builds:      []string{"default"},
+						Formats:     []string{"termux.deb"},
+						Description: "Some description",
+						Maintainer:  "me@me",
+						NFPMOverridables: config.NFPMOverridables{
+							FileNameTemplate: "{{ .ConventionalFileName }}",
+							Contents: []*files.Content{
+								{
+									Source:      "./testdata/testfile.txt",
+									Destination: "/etc/mybin.conf",
+									Type:        "config",
+								},
+							},
+						},
+					},
+				},
+			})
+			ctx.Version = "1.0.0"
+			ctx.Git = context.GitInfo{CurrentTag: "v1.0.0"}
+			for _, platform := range []struct{ goos, goarch, goarm string }{
+				{goos, "arm64", ""},
+				{goos, "arm", "7"},
+				{goos, "mips", ""},
+				{"linux", "amd64", ""},
+			} {
+				ctx.Artifacts.Add(&artifact.Artifact{
+					Name:   "mybin",
+					Path:   binPath,
+					Goos:   platform.goos,
+					Goarch: platform.goarch,
+					Goarm:  platform.goarm,
+					Type:   artifact.Binary,
+					Extra: map[string]interface{}{
+						artifact.ExtraID: "default",
+					},
+				})
+			}
+			require.NoError(t, Pipe{}.Default(ctx))
+			require.NoError(t, Pipe{}.Run(ctx))
+			packages := ctx.Artifacts.Filter(artifact.ByType(artifact.LinuxPackage)).List()
+			var names []string
+			for _, pkg := range packages {
+				require.Equal(t, "termux.deb", pkg.Format())
+				require.ElementsMatch(t, []string{
+					"/data/data/com.termux/files/usr/bin/mybin",
+					"/data/data/com.termux/files/usr/etc/mybin.conf",
+				}, destinations(pkg.ExtraOr(extraFiles, files.Contents{}).(files.Contents)))
+				names = append(names, pkg.Name)
+			}
+			expected := []string{
+				"mybin_1.0.0_aarch64.deb",
+				"mybin_1.0.0_arm.deb",
+			}
+			if goos == "linux" {
+				expected = append(expected, "mybin_1.0.0_x86_64.deb")
+			}
+			require.ElementsMatch(t, expected, names)
+		})
+	}
+}
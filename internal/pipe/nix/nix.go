@@ -0,0 +1,394 @@
+// Package nix implements the Pipe, generating a nix package expression and
+// pushing it to a configured repository, e.g. a NUR or a flake.
+package nix
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/client"
+	"github.com/goreleaser/goreleaser/internal/commitauthor"
+	"github.com/goreleaser/goreleaser/internal/pipe"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+)
+
+const nixConfigExtra = "NixConfig"
+
+var (
+	// ErrNoArchivesFound happens when 0 archives are found.
+	ErrNoArchivesFound = errors.New("no linux/macos archives found")
+
+	// ErrMultipleArchivesSamePlatform happens when the config yields multiple
+	// archives for the same nix system.
+	ErrMultipleArchivesSamePlatform = errors.New("one nix package can handle only one archive of an OS/Arch combination. Consider using ids in the nix section")
+)
+
+// Pipe for nix package expressions.
+type Pipe struct{}
+
+func (Pipe) String() string                 { return "nix packages" }
+func (Pipe) Skip(ctx *context.Context) bool { return len(ctx.Config.Nix) == 0 }
+
+func (Pipe) Default(ctx *context.Context) error {
+	for i := range ctx.Config.Nix {
+		nix := &ctx.Config.Nix[i]
+
+		nix.CommitAuthor = commitauthor.Default(nix.CommitAuthor)
+		if nix.CommitMessageTemplate == "" {
+			nix.CommitMessageTemplate = "{{ .ProjectName }}: {{ .PreviousTag }} -> {{ .Tag }}"
+		}
+		if nix.Name == "" {
+			nix.Name = ctx.Config.ProjectName
+		}
+		if err := commitauthor.CheckSigning(ctx, nix.CommitAuthor); err != nil {
+			return fmt.Errorf("nix %s: %w", nix.Name, err)
+		}
+		if nix.Goarm == "" {
+			nix.Goarm = "6"
+		}
+	}
+
+	return nil
+}
+
+func (Pipe) Run(ctx *context.Context) error {
+	cli, err := client.New(ctx)
+	if err != nil {
+		return err
+	}
+
+	return runAll(ctx, cli)
+}
+
+// Publish the nix package expressions.
+func (Pipe) Publish(ctx *context.Context) error {
+	cli, err := client.New(ctx)
+	if err != nil {
+		return err
+	}
+	return publishAll(ctx, cli)
+}
+
+func runAll(ctx *context.Context, cli client.Client) error {
+	for _, nix := range ctx.Config.Nix {
+		err := doRun(ctx, nix, cli)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func publishAll(ctx *context.Context, cli client.Client) error {
+	skips := pipe.SkipMemento{}
+	for _, pkg := range ctx.Artifacts.Filter(artifact.ByType(artifact.Nixpkg)).List() {
+		err := doPublish(ctx, pkg, cli)
+		if err != nil && pipe.IsSkip(err) {
+			skips.Remember(err)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return skips.Evaluate()
+}
+
+func doRun(ctx *context.Context, nix config.Nix, cl client.Client) error {
+	if nix.Repository.Name == "" {
+		return pipe.Skip("nix.repository.name is not set")
+	}
+
+	filters := []artifact.Filter{
+		artifact.Or(
+			artifact.ByGoos("darwin"),
+			artifact.ByGoos("linux"),
+		),
+		artifact.Or(
+			artifact.ByGoarch("amd64"),
+			artifact.ByGoarch("arm64"),
+			artifact.ByGoarch("386"),
+			artifact.ByGoarch("all"),
+			artifact.And(
+				artifact.ByGoarch("arm"),
+				artifact.ByGoarm(nix.Goarm),
+			),
+		),
+		artifact.ByFormats("zip", "tar.gz", "tgz"),
+		artifact.ByType(artifact.UploadableArchive),
+		artifact.OnlyReplacingUnibins,
+		artifact.OnlyUnencrypted,
+	}
+	if len(nix.IDs) > 0 {
+		filters = append(filters, artifact.ByIDs(nix.IDs...))
+	}
+
+	archives := ctx.Artifacts.Filter(artifact.And(filters...)).List()
+	if len(archives) == 0 {
+		return ErrNoArchivesFound
+	}
+
+	t := tmpl.New(ctx)
+	for _, field := range []*string{
+		&nix.Name,
+		&nix.Path,
+		&nix.Repository.Owner,
+		&nix.Repository.Name,
+		&nix.SkipUpload,
+	} {
+		applied, err := t.Apply(*field)
+		if err != nil {
+			return err
+		}
+		*field = applied
+	}
+	if nix.Path == "" {
+		nix.Path = path.Join("pkgs", nix.Name, "default.nix")
+	}
+
+	content, err := buildPkg(ctx, nix, cl, archives)
+	if err != nil {
+		return err
+	}
+
+	filename := nix.Name + ".nix"
+	nixPath := filepath.Join(ctx.Config.Dist, filename)
+	log.FromContext(ctx).WithField("nixpkg", nixPath).Info("writing")
+	if err := os.WriteFile(nixPath, []byte(content), 0o644); err != nil { //nolint: gosec
+		return fmt.Errorf("failed to write nix package: %w", err)
+	}
+
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name: filename,
+		Path: nixPath,
+		Type: artifact.Nixpkg,
+		Extra: map[string]interface{}{
+			nixConfigExtra: nix,
+		},
+	})
+
+	return nil
+}
+
+func doPublish(ctx *context.Context, pkg *artifact.Artifact, cl client.Client) error {
+	nix := pkg.Extra[nixConfigExtra].(config.Nix)
+	var err error
+	cl, err = client.NewIfToken(ctx, cl, nix.Repository.Token)
+	if err != nil {
+		return err
+	}
+
+	if strings.TrimSpace(nix.SkipUpload) == "true" {
+		return pipe.Skip("nix.skip_upload is set")
+	}
+
+	if strings.TrimSpace(nix.SkipUpload) == "auto" && ctx.Semver.Prerelease != "" {
+		return pipe.Skip("prerelease detected with 'auto' upload, skipping nix publish")
+	}
+
+	repo := client.RepoFromRef(nix.Repository)
+	log.FromContext(ctx).WithField("nixpkg", nix.Path).
+		WithField("repo", repo.String()).
+		Info("pushing")
+
+	msg, err := tmpl.New(ctx).Apply(nix.CommitMessageTemplate)
+	if err != nil {
+		return err
+	}
+
+	author, err := commitauthor.Get(ctx, nix.CommitAuthor)
+	if err != nil {
+		return err
+	}
+
+	content, err := os.ReadFile(pkg.Path)
+	if err != nil {
+		return err
+	}
+
+	return cl.CreateFile(ctx, author, repo, content, nix.Path, msg)
+}
+
+func buildPkg(ctx *context.Context, nix config.Nix, cl client.Client, archives []*artifact.Artifact) (string, error) {
+	data, err := dataFor(ctx, nix, cl, archives)
+	if err != nil {
+		return "", err
+	}
+	return doBuildPkg(data)
+}
+
+func doBuildPkg(data templateData) (string, error) {
+	t, err := template.New(data.Name).Parse(pkgTemplate)
+	if err != nil {
+		return "", err
+	}
+	var out bytes.Buffer
+	if err := t.Execute(&out, data); err != nil {
+		return "", err
+	}
+	content := out.String()
+	out.Reset()
+
+	// Sanitize the template output and get rid of trailing whitespace.
+	s := bufio.NewScanner(strings.NewReader(content))
+	for s.Scan() {
+		_, _ = out.WriteString(strings.TrimRight(s.Text(), " "))
+		_ = out.WriteByte('\n')
+	}
+	if err := s.Err(); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
+
+func dataFor(ctx *context.Context, cfg config.Nix, cl client.Client, archives []*artifact.Artifact) (templateData, error) {
+	t := tmpl.New(ctx)
+	result := templateData{
+		Name:    cfg.Name,
+		Version: ctx.Version,
+		License: cfg.License,
+	}
+	for _, field := range []struct {
+		in  string
+		out *string
+	}{
+		{cfg.Description, &result.Description},
+		{cfg.Homepage, &result.Homepage},
+	} {
+		applied, err := t.Apply(field.in)
+		if err != nil {
+			return result, err
+		}
+		*field.out = escape(applied)
+	}
+
+	if cfg.URLTemplate == "" {
+		url, err := cl.ReleaseURLTemplate(ctx)
+		if err != nil {
+			return result, err
+		}
+		cfg.URLTemplate = url
+	}
+
+	binaries := map[string]bool{}
+	systems := map[string]bool{}
+	for _, art := range archives {
+		sum, err := art.Checksum("sha256")
+		if err != nil {
+			return result, err
+		}
+		url, err := t.WithArtifact(art, map[string]string{}).Apply(cfg.URLTemplate)
+		if err != nil {
+			return result, err
+		}
+		wrappedIn := art.ExtraOr(artifact.ExtraWrappedIn, "").(string)
+		if wrappedIn != "" {
+			result.Wrapped = true
+		}
+		if art.ExtraOr(artifact.ExtraFormat, "").(string) == "zip" {
+			result.Unzip = true
+		}
+		for _, bin := range art.ExtraOr(artifact.ExtraBinaries, []string{}).([]string) {
+			binaries[bin] = true
+		}
+		for _, system := range systemsFor(art) {
+			if systems[system] {
+				return result, ErrMultipleArchivesSamePlatform
+			}
+			systems[system] = true
+			result.Platforms = append(result.Platforms, platform{
+				System:    system,
+				URL:       url,
+				SHA256:    sum,
+				WrappedIn: wrappedIn,
+			})
+		}
+	}
+	sort.Slice(result.Platforms, func(i, j int) bool {
+		return result.Platforms[i].System < result.Platforms[j].System
+	})
+
+	install, err := t.Apply(cfg.Install)
+	if err != nil {
+		return result, err
+	}
+	if install == "" {
+		install = defaultInstall(binaries)
+	}
+	result.Install = split(install)
+
+	postInstall, err := t.Apply(cfg.PostInstall)
+	if err != nil {
+		return result, err
+	}
+	result.PostInstall = split(postInstall)
+
+	return result, nil
+}
+
+// systemsFor returns the nix systems the given archive can be installed on.
+func systemsFor(art *artifact.Artifact) []string {
+	var arch string
+	switch art.Goarch {
+	case "all":
+		return []string{"aarch64-" + art.Goos, "x86_64-" + art.Goos}
+	case "amd64":
+		arch = "x86_64"
+	case "arm64":
+		arch = "aarch64"
+	case "386":
+		arch = "i686"
+	case "arm":
+		arch = "armv" + art.Goarm + "l"
+	}
+	return []string{arch + "-" + art.Goos}
+}
+
+// defaultInstall copies the binaries in the archives to $out/bin.
+func defaultInstall(binaries map[string]bool) string {
+	names := make([]string, 0, len(binaries))
+	for bin := range binaries {
+		names = append(names, bin)
+	}
+	sort.Strings(names)
+	lines := []string{"mkdir -p $out/bin"}
+	for _, bin := range names {
+		lines = append(lines, fmt.Sprintf("cp -vr ./%s $out/bin/%s", bin, bin))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// split splits the given script in lines, keeping their indentation.
+func split(s string) []string {
+	s = strings.Trim(s, "\n")
+	if strings.TrimSpace(s) == "" {
+		return nil
+	}
+	var result []string
+	for _, line := range strings.Split(s, "\n") {
+		result = append(result, strings.TrimRight(line, " \t"))
+	}
+	return result
+}
+
+// escape escapes the given string to be used inside a nix double quoted
+// string.
+func escape(s string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		`"`, `\"`,
+		"${", `\${`,
+	).Replace(s)
+}
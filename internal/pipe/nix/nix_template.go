@@ -0,0 +1,93 @@
+package nix
+
+const pkgTemplate = `# This file was generated by GoReleaser. DO NOT EDIT.
+# vim: set ft=nix ts=2 sw=2 sts=2 et sta
+{ lib, stdenvNoCC, fetchurl{{ if .Unzip }}, unzip{{ end }} }:
+let
+  inherit (stdenvNoCC.hostPlatform) system;
+  urlMap = {
+    {{- range .Platforms }}
+    {{ .System }} = "{{ .URL }}";
+    {{- end }}
+  };
+  shaMap = {
+    {{- range .Platforms }}
+    {{ .System }} = "{{ .SHA256 }}";
+    {{- end }}
+  };
+  {{- if .Wrapped }}
+  sourceRootMap = {
+    {{- range .Platforms }}
+    {{ .System }} = "{{ with .WrappedIn }}{{ . }}{{ else }}.{{ end }}";
+    {{- end }}
+  };
+  {{- end }}
+in
+stdenvNoCC.mkDerivation {
+  pname = "{{ .Name }}";
+  version = "{{ .Version }}";
+  src = fetchurl {
+    url = urlMap.${system};
+    sha256 = shaMap.${system};
+  };
+
+  sourceRoot = {{ if .Wrapped }}sourceRootMap.${system}{{ else }}"."{{ end }};
+  {{- if .Unzip }}
+
+  nativeBuildInputs = [ unzip ];
+  {{- end }}
+
+  installPhase = ''
+    {{- range .Install }}
+    {{ . }}
+    {{- end }}
+  '';
+  {{- with .PostInstall }}
+
+  postInstall = ''
+    {{- range . }}
+    {{ . }}
+    {{- end }}
+  '';
+  {{- end }}
+
+  meta = {
+    {{- with .Description }}
+    description = "{{ . }}";
+    {{- end }}
+    {{- with .Homepage }}
+    homepage = "{{ . }}";
+    {{- end }}
+    {{- with .License }}
+    license = lib.licenses.{{ . }};
+    {{- end }}
+    sourceProvenance = [ lib.sourceTypes.binaryNativeCode ];
+    platforms = [
+      {{- range .Platforms }}
+      "{{ .System }}"
+      {{- end }}
+    ];
+  };
+}
+`
+
+type templateData struct {
+	Name        string
+	Version     string
+	Description string
+	Homepage    string
+	License     string
+	Install     []string
+	PostInstall []string
+	Platforms   []platform
+	Wrapped     bool
+	Unzip       bool
+}
+
+// platform is a nix system and the archive to install on it.
+type platform struct {
+	System    string
+	URL       string
+	SHA256    string
+	WrappedIn string
+}
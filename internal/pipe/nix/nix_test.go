@@ -0,0 +1,271 @@
+package nix
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/client"
+	"github.com/goreleaser/goreleaser/internal/golden"
+	"github.com/goreleaser/goreleaser/internal/testlib"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDescription(t *testing.T) {
+	require.NotEmpty(t, Pipe{}.String())
+}
+
+func TestSkip(t *testing.T) {
+	require.True(t, Pipe{}.Skip(context.New(config.Project{})))
+	require.False(t, Pipe{}.Skip(context.New(config.Project{
+		Nix: []config.Nix{{}},
+	})))
+}
+
+func TestDefault(t *testing.T) {
+	ctx := context.New(config.Project{
+		ProjectName: "myproject",
+		Nix:         []config.Nix{{}},
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	nix := ctx.Config.Nix[0]
+	require.Equal(t, "myproject", nix.Name)
+	require.Equal(t, "6", nix.Goarm)
+	require.NotEmpty(t, nix.CommitAuthor.Name)
+	require.NotEmpty(t, nix.CommitAuthor.Email)
+	require.NotEmpty(t, nix.CommitMessageTemplate)
+}
+
+func TestRunPipe(t *testing.T) {
+	for name, tt := range map[string]struct {
+		prepare func(ctx *context.Context)
+		path    string
+	}{
+		"default": {
+			prepare: func(ctx *context.Context) {},
+			path:    "pkgs/default/default.nix",
+		},
+		"custom": {
+			prepare: func(ctx *context.Context) {
+				nix := &ctx.Config.Nix[0]
+				nix.Path = "pkgs/{{ .ProjectName }}.nix"
+				nix.URLTemplate = "https://example.com/{{ .Tag }}/{{ .ArtifactName }}"
+				nix.Description = `A "nix" package costing ${{ .Env.PRICE }}`
+				nix.License = "asl20"
+				nix.Install = `
+mkdir -p $out/bin
+cp foo $out/bin/foo
+if [ -f bar ]; then
+  cp bar $out/bin/bar
+fi
+`
+				nix.PostInstall = "installShellCompletion ./completions/*"
+			},
+			path: "pkgs/custom.nix",
+		},
+		"wrapped": {
+			prepare: func(ctx *context.Context) {
+				for _, art := range ctx.Artifacts.List() {
+					art.Extra[artifact.ExtraWrappedIn] = "foo_" + art.Goos
+					if art.Goos == "darwin" {
+						art.Extra[artifact.ExtraFormat] = "zip"
+					}
+				}
+			},
+			path: "pkgs/wrapped/default.nix",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			folder := t.TempDir()
+			ctx := context.New(config.Project{
+				Dist:        folder,
+				ProjectName: name,
+				Env:         []string{"PRICE=0"},
+				Nix: []config.Nix{{
+					Name:        name,
+					IDs:         []string{"foo"},
+					Description: "A foo package",
+					Homepage:    "https://goreleaser.com",
+					License:     "mit",
+					Repository: config.RepoRef{
+						Owner: "foo",
+						Name:  "nur",
+					},
+				}},
+			})
+			ctx.Git.CurrentTag = "v1.2.1"
+			ctx.Version = "1.2.1"
+			ctx.Env = map[string]string{"PRICE": "0"}
+			require.NoError(t, Pipe{}.Default(ctx))
+			addArchives(t, ctx, folder)
+			tt.prepare(ctx)
+
+			cli := client.NewMock()
+			require.NoError(t, runAll(ctx, cli))
+			require.NoError(t, publishAll(ctx, cli))
+			require.True(t, cli.CreatedFile)
+			require.Equal(t, tt.path, cli.Path)
+			golden.RequireEqualExt(t, []byte(cli.Content), ".nix")
+
+			bts, err := os.ReadFile(filepath.Join(folder, name+".nix"))
+			require.NoError(t, err)
+			require.Equal(t, cli.Content, string(bts))
+		})
+	}
+}
+
+func TestRunPipeErrors(t *testing.T) {
+	newCtx := func(t *testing.T) *context.Context {
+		t.Helper()
+		folder := t.TempDir()
+		ctx := context.New(config.Project{
+			Dist:        folder,
+			ProjectName: "foo",
+			Nix: []config.Nix{{
+				Repository: config.RepoRef{Owner: "foo", Name: "nur"},
+			}},
+		})
+		ctx.Git.CurrentTag = "v1.2.1"
+		require.NoError(t, Pipe{}.Default(ctx))
+		return ctx
+	}
+
+	t.Run("no archives", func(t *testing.T) {
+		ctx := newCtx(t)
+		require.Equal(t, ErrNoArchivesFound, runAll(ctx, client.NewMock()))
+	})
+
+	t.Run("same platform", func(t *testing.T) {
+		ctx := newCtx(t)
+		addArchives(t, ctx, ctx.Config.Dist)
+		path := filepath.Join(ctx.Config.Dist, "other.tar.gz")
+		require.NoError(t, os.WriteFile(path, []byte("other"), 0o644))
+		ctx.Artifacts.Add(&artifact.Artifact{
+			Name:   "other.tar.gz",
+			Path:   path,
+			Goos:   "linux",
+			Goarch: "amd64",
+			Type:   artifact.UploadableArchive,
+			Extra: map[string]interface{}{
+				artifact.ExtraID:     "bar",
+				artifact.ExtraFormat: "tar.gz",
+			},
+		})
+		require.Equal(t, ErrMultipleArchivesSamePlatform, runAll(ctx, client.NewMock()))
+	})
+
+	t.Run("invalid templates", func(t *testing.T) {
+		for name, prepare := range map[string]func(nix *config.Nix){
+			"name":         func(nix *config.Nix) { nix.Name = "{{ .Nope }" },
+			"path":         func(nix *config.Nix) { nix.Path = "{{ .Nope }" },
+			"description":  func(nix *config.Nix) { nix.Description = "{{ .Nope }" },
+			"url template": func(nix *config.Nix) { nix.URLTemplate = "{{ .Nope }" },
+			"install":      func(nix *config.Nix) { nix.Install = "{{ .Nope }" },
+			"post install": func(nix *config.Nix) { nix.PostInstall = "{{ .Nope }" },
+		} {
+			t.Run(name, func(t *testing.T) {
+				ctx := newCtx(t)
+				addArchives(t, ctx, ctx.Config.Dist)
+				prepare(&ctx.Config.Nix[0])
+				require.Error(t, runAll(ctx, client.NewMock()))
+			})
+		}
+	})
+
+	t.Run("invalid commit template", func(t *testing.T) {
+		ctx := newCtx(t)
+		addArchives(t, ctx, ctx.Config.Dist)
+		ctx.Config.Nix[0].CommitMessageTemplate = "{{ .Nope }"
+		require.NoError(t, runAll(ctx, client.NewMock()))
+		require.Error(t, publishAll(ctx, client.NewMock()))
+	})
+}
+
+func TestRunPipeNoUpload(t *testing.T) {
+	folder := t.TempDir()
+	ctx := context.New(config.Project{
+		Dist:        folder,
+		ProjectName: "foo",
+		Nix: []config.Nix{{
+			Repository: config.RepoRef{Owner: "foo", Name: "nur"},
+		}},
+	})
+	ctx.Git.CurrentTag = "v1.2.1"
+	require.NoError(t, Pipe{}.Default(ctx))
+	addArchives(t, ctx, folder)
+	cli := client.NewMock()
+
+	assertNoPublish := func(t *testing.T) {
+		t.Helper()
+		require.NoError(t, runAll(ctx, cli))
+		testlib.AssertSkipped(t, publishAll(ctx, cli))
+		require.False(t, cli.CreatedFile)
+	}
+	t.Run("skip upload true", func(t *testing.T) {
+		ctx.Config.Nix[0].SkipUpload = "true"
+		ctx.Semver.Prerelease = ""
+		assertNoPublish(t)
+	})
+	t.Run("skip upload auto", func(t *testing.T) {
+		ctx.Config.Nix[0].SkipUpload = "auto"
+		ctx.Semver.Prerelease = "beta1"
+		assertNoPublish(t)
+	})
+}
+
+func TestRunSkipNoName(t *testing.T) {
+	ctx := context.New(config.Project{
+		Nix: []config.Nix{{}},
+	})
+	testlib.AssertSkipped(t, runAll(ctx, client.NewMock()))
+}
+
+func TestSystemsFor(t *testing.T) {
+	for expected, art := range map[string]artifact.Artifact{
+		"x86_64-linux":   {Goos: "linux", Goarch: "amd64"},
+		"aarch64-linux":  {Goos: "linux", Goarch: "arm64"},
+		"i686-linux":     {Goos: "linux", Goarch: "386"},
+		"armv6l-linux":   {Goos: "linux", Goarch: "arm", Goarm: "6"},
+		"armv7l-linux":   {Goos: "linux", Goarch: "arm", Goarm: "7"},
+		"aarch64-darwin": {Goos: "darwin", Goarch: "arm64"},
+	} {
+		art := art
+		require.Equal(t, []string{expected}, systemsFor(&art))
+	}
+	require.Equal(t, []string{"aarch64-darwin", "x86_64-darwin"}, systemsFor(&artifact.Artifact{
+		Goos:   "darwin",
+		Goarch: "all",
+	}))
+}
+
+func TestEscape(t *testing.T) {
+	require.Equal(t, `a \"quoted\" \\ costing \${price}`, escape(`a "quoted" \ costing ${price}`))
+}
+
+// addArchives adds the archives of a release with binaries for linux and
+// macOS, and an extra one that should be ignored.
+func addArchives(tb testing.TB, ctx *context.Context, folder string) {
+	tb.Helper()
+	for _, art := range []*artifact.Artifact{
+		{Goos: "linux", Goarch: "amd64"},
+		{Goos: "linux", Goarch: "arm64"},
+		{Goos: "linux", Goarch: "arm", Goarm: "6"},
+		{Goos: "linux", Goarch: "arm", Goarm: "7"},
+		{Goos: "darwin", Goarch: "all"},
+		{Goos: "windows", Goarch: "amd64"},
+	} {
+		art.Name = "foo_" + art.Goos + "_" + art.Goarch + art.Goarm + ".tar.gz"
+		art.Path = filepath.Join(folder, art.Name)
+		art.Type = artifact.UploadableArchive
+		art.Extra = map[string]interface{}{
+			artifact.ExtraID:       "foo",
+			artifact.ExtraFormat:   "tar.gz",
+			artifact.ExtraBinaries: []string{"foo", "bar"},
+		}
+		require.NoError(tb, os.WriteFile(art.Path, []byte(art.Name), 0o644))
+		ctx.Artifacts.Add(art)
+	}
+}
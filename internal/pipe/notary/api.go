@@ -0,0 +1,184 @@
+package notary
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/goreleaser/goreleaser/pkg/context"
+)
+
+// nolint: gochecknoglobals
+var (
+	// notaryURL is the URL of Apple's Notary API, the one notarytool uses.
+	notaryURL = "https://appstoreconnect.apple.com/notary/v2"
+	// s3Endpoint overrides the endpoint the files are uploaded to, for tests.
+	s3Endpoint = ""
+	// pollInterval is how often the status of a submission is checked.
+	pollInterval = 30 * time.Second
+)
+
+const (
+	statusInProgress = "In Progress"
+	statusAccepted   = "Accepted"
+)
+
+// client talks to the Notary API, authenticating with an App Store Connect
+// API key.
+type client struct {
+	issuerID string
+	keyID    string
+	key      interface{}
+}
+
+func newClient(issuerID, keyID string, key []byte) (*client, error) {
+	pk, err := jwt.ParseECPrivateKeyFromPEM(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid notarization key: %w", err)
+	}
+	return &client{issuerID: issuerID, keyID: keyID, key: pk}, nil
+}
+
+func (c *client) token() (string, error) {
+	now := time.Now()
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"iss": c.issuerID,
+		"iat": now.Unix(),
+		"exp": now.Add(15 * time.Minute).Unix(),
+		"aud": "appstoreconnect-v1",
+	})
+	token.Header["kid"] = c.keyID
+	return token.SignedString(c.key)
+}
+
+type submissionResponse struct {
+	Data struct {
+		ID         string `json:"id"`
+		Attributes struct {
+			Status          string `json:"status"`
+			AccessKeyID     string `json:"awsAccessKeyId"`
+			SecretAccessKey string `json:"awsSecretAccessKey"`
+			SessionToken    string `json:"awsSessionToken"`
+			Bucket          string `json:"bucket"`
+			Object          string `json:"object"`
+			DeveloperLogURL string `json:"developerLogUrl"`
+		} `json:"attributes"`
+	} `json:"data"`
+}
+
+// submit uploads the given zip file to be notarized, returning the ID of
+// the submission.
+func (c *client) submit(ctx *context.Context, name, path string) (string, error) {
+	bts, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(bts)
+	body, err := json.Marshal(map[string]string{
+		"submissionName": name,
+		"sha256":         hex.EncodeToString(sum[:]),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	var resp submissionResponse
+	if err := c.do(ctx, http.MethodPost, "/submissions", body, &resp); err != nil {
+		return "", fmt.Errorf("failed to create submission: %w", err)
+	}
+	attrs := resp.Data.Attributes
+
+	cfg := aws.NewConfig().
+		WithRegion("us-west-2").
+		WithCredentials(credentials.NewStaticCredentials(attrs.AccessKeyID, attrs.SecretAccessKey, attrs.SessionToken))
+	if s3Endpoint != "" {
+		cfg = cfg.WithEndpoint(s3Endpoint).WithS3ForcePathStyle(true)
+	}
+	sess, err := session.NewSession(cfg)
+	if err != nil {
+		return "", err
+	}
+	if _, err := s3manager.NewUploader(sess).UploadWithContext(ctx, &s3manager.UploadInput{
+		Bucket: aws.String(attrs.Bucket),
+		Key:    aws.String(attrs.Object),
+		Body:   bytes.NewReader(bts),
+	}); err != nil {
+		return "", fmt.Errorf("failed to upload submission: %w", err)
+	}
+	return resp.Data.ID, nil
+}
+
+// wait waits for the given submission to be processed, returning its final
+// status.
+func (c *client) wait(ctx *context.Context, id string, timeout time.Duration) (string, error) {
+	deadline := time.After(timeout)
+	for {
+		var resp submissionResponse
+		if err := c.do(ctx, http.MethodGet, "/submissions/"+id, nil, &resp); err != nil {
+			return "", fmt.Errorf("failed to get submission status: %w", err)
+		}
+		status := resp.Data.Attributes.Status
+		if status != statusInProgress {
+			return status, nil
+		}
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-deadline:
+			return "", fmt.Errorf("submission %s still in progress after %s", id, timeout)
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+// logURL returns the URL of the developer log of the given submission.
+func (c *client) logURL(ctx *context.Context, id string) (string, error) {
+	var resp submissionResponse
+	if err := c.do(ctx, http.MethodGet, "/submissions/"+id+"/logs", nil, &resp); err != nil {
+		return "", err
+	}
+	return resp.Data.Attributes.DeveloperLogURL, nil
+}
+
+func (c *client) do(ctx *context.Context, method, path string, body []byte, v interface{}) error {
+	token, err := c.token()
+	if err != nil {
+		return err
+	}
+	var r io.Reader
+	if body != nil {
+		r = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, notaryURL+path, r)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	bts, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return errors.New(resp.Status + ": " + strings.TrimSpace(string(bts)))
+	}
+	return json.Unmarshal(bts, v)
+}
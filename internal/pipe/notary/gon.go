@@ -0,0 +1,83 @@
+package notary
+
+import (
+	"fmt"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/hashicorp/hcl/v2/hclsimple"
+)
+
+// gonConfig is the configuration file of gon, either in HCL or JSON.
+// more info: https://github.com/mitchellh/gon#configuration-file
+type gonConfig struct {
+	Source   []string      `hcl:"source,optional"`
+	BundleID string        `hcl:"bundle_id,optional"`
+	AppleID  *gonAppleID   `hcl:"apple_id,block"`
+	Sign     *gonSign      `hcl:"sign,block"`
+	Notarize []gonNotarize `hcl:"notarize,block"`
+	Zip      *gonZip       `hcl:"zip,block"`
+	DMG      *gonDMG       `hcl:"dmg,block"`
+}
+
+type gonAppleID struct {
+	Username string `hcl:"username,optional"`
+	Password string `hcl:"password,optional"`
+	Provider string `hcl:"provider,optional"`
+}
+
+type gonSign struct {
+	ApplicationIdentity string `hcl:"application_identity"`
+	EntitlementsFile    string `hcl:"entitlements_file,optional"`
+}
+
+type gonNotarize struct {
+	Path     string `hcl:"path"`
+	BundleID string `hcl:"bundle_id"`
+	Staple   bool   `hcl:"staple,optional"`
+}
+
+type gonZip struct {
+	OutputPath string `hcl:"output_path"`
+}
+
+type gonDMG struct {
+	OutputPath string `hcl:"output_path"`
+	VolumeName string `hcl:"volume_name"`
+}
+
+// loadGon fills the settings of the given configuration that are not set
+// from its gon configuration file.
+// Gon notarizes with an Apple ID, which the notary API doesn't take, so the
+// App Store Connect API key still has to be set in the configuration.
+// The files to sign are the binaries of the build, so the source, zip and
+// dmg settings are ignored.
+func loadGon(ctx *context.Context, cfg *config.MacOSSignNotarize) error {
+	var gon gonConfig
+	if err := hclsimple.DecodeFile(cfg.Gon, nil, &gon); err != nil {
+		return fmt.Errorf("notarize %s: failed to load gon config: %w", cfg.ID, err)
+	}
+
+	if gon.Sign != nil && cfg.Sign.Identity == "" && cfg.Sign.Certificate == "" {
+		cfg.Sign.Identity = gon.Sign.ApplicationIdentity
+	}
+	if gon.Sign != nil && cfg.Sign.Entitlements == "" {
+		cfg.Sign.Entitlements = gon.Sign.EntitlementsFile
+	}
+	if gon.AppleID != nil || len(gon.Notarize) > 0 {
+		// gon always waits for the notarization to finish.
+		if !cfg.Notarize.Enabled {
+			cfg.Notarize.Enabled = true
+			cfg.Notarize.Wait = true
+		}
+		if cfg.Notarize.IssuerID == "" || cfg.Notarize.KeyID == "" || cfg.Notarize.Key == "" {
+			return fmt.Errorf("notarize %s: gon notarizes with an apple id, which can't be used here, set notarize.issuer_id, key_id and key to an App Store Connect API key instead", cfg.ID)
+		}
+	}
+	if len(gon.Source) > 0 || gon.Zip != nil || gon.DMG != nil {
+		log.FromContext(ctx).WithField("id", cfg.ID).
+			Warn("gon source, zip and dmg are ignored, the binaries of the build are signed and notarized")
+	}
+	return nil
+}
@@ -0,0 +1,278 @@
+// Package notary provides a pipe that codesigns and notarizes macOS
+// binaries.
+package notary
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/ids"
+	"github.com/goreleaser/goreleaser/internal/semerrgroup"
+	"github.com/goreleaser/goreleaser/internal/shell"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+)
+
+// Pipe that codesigns and notarizes macOS binaries.
+type Pipe struct{}
+
+func (Pipe) String() string { return "signing and notarizing macOS binaries" }
+func (Pipe) Skip(ctx *context.Context) bool {
+	return ctx.SkipSign || len(ctx.Config.Notarize.MacOS) == 0
+}
+
+// Default sets the pipe defaults.
+func (Pipe) Default(ctx *context.Context) error {
+	ids := ids.New("notarize.macos")
+	for i := range ctx.Config.Notarize.MacOS {
+		cfg := &ctx.Config.Notarize.MacOS[i]
+		if cfg.ID == "" {
+			cfg.ID = "default"
+		}
+		if cfg.Gon != "" {
+			if err := loadGon(ctx, cfg); err != nil {
+				return err
+			}
+		}
+		if (cfg.Sign.Identity == "") == (cfg.Sign.Certificate == "") {
+			return fmt.Errorf("notarize %s: sign requires either identity or certificate", cfg.ID)
+		}
+		if cfg.Notarize.Enabled {
+			if cfg.Notarize.IssuerID == "" || cfg.Notarize.KeyID == "" || cfg.Notarize.Key == "" {
+				return fmt.Errorf("notarize %s: notarize requires issuer_id, key_id and key", cfg.ID)
+			}
+			if cfg.Notarize.Timeout == 0 {
+				cfg.Notarize.Timeout = 10 * time.Minute
+			}
+		}
+		ids.Inc(cfg.ID)
+	}
+	return ids.Validate()
+}
+
+// Run the pipe.
+func (Pipe) Run(ctx *context.Context) error {
+	for _, cfg := range ctx.Config.Notarize.MacOS {
+		filters := []artifact.Filter{
+			artifact.Or(
+				artifact.ByType(artifact.Binary),
+				artifact.ByType(artifact.UniversalBinary),
+			),
+			artifact.ByGoos("darwin"),
+		}
+		if len(cfg.IDs) > 0 {
+			filters = append(filters, artifact.ByIDs(cfg.IDs...))
+		}
+		binaries := ctx.Artifacts.Filter(artifact.And(filters...)).List()
+		if len(binaries) == 0 {
+			log.FromContext(ctx).WithField("id", cfg.ID).Warn("no macOS binaries found")
+			continue
+		}
+		if err := signAndNotarize(ctx, cfg, binaries); err != nil {
+			return fmt.Errorf("notarize %s: %w", cfg.ID, err)
+		}
+	}
+	return nil
+}
+
+func signAndNotarize(ctx *context.Context, cfg config.MacOSSignNotarize, binaries []*artifact.Artifact) error {
+	var cli *client
+	if cfg.Notarize.Enabled && ctx.Snapshot {
+		log.FromContext(ctx).WithField("id", cfg.ID).Warn("skipping notarization of snapshot builds")
+	}
+	if cfg.Notarize.Enabled && !ctx.Snapshot {
+		key, err := notarizationKey(ctx, cfg.Notarize.Key)
+		if err != nil {
+			return err
+		}
+		issuerID, err := tmpl.New(ctx).Apply(cfg.Notarize.IssuerID)
+		if err != nil {
+			return err
+		}
+		keyID, err := tmpl.New(ctx).Apply(cfg.Notarize.KeyID)
+		if err != nil {
+			return err
+		}
+		cli, err = newClient(issuerID, keyID, key)
+		if err != nil {
+			return err
+		}
+	}
+
+	g := semerrgroup.New(ctx.Parallelism)
+	for _, binary := range binaries {
+		binary := binary
+		g.Go(func() error {
+			if err := codesign(ctx, cfg.Sign, binary); err != nil {
+				return fmt.Errorf("failed to sign %s: %w", binary.Name, err)
+			}
+			if cli == nil {
+				return nil
+			}
+			if err := notarize(ctx, cli, cfg.Notarize, binary); err != nil {
+				return fmt.Errorf("failed to notarize %s: %w", binary.Name, err)
+			}
+			return nil
+		})
+	}
+	return g.Wait()
+}
+
+// notarizationKey returns the App Store Connect API key, given either its
+// contents or the path to it.
+func notarizationKey(ctx *context.Context, s string) ([]byte, error) {
+	key, err := tmpl.New(ctx).Apply(s)
+	if err != nil {
+		return nil, err
+	}
+	if strings.HasPrefix(strings.TrimSpace(key), "-----BEGIN") {
+		return []byte(key), nil
+	}
+	return os.ReadFile(key)
+}
+
+func codesign(ctx *context.Context, cfg config.MacOSSign, binary *artifact.Artifact) error {
+	cmd, cleanup, err := codesignCommand(ctx, cfg, binary.Path)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	log.FromContext(ctx).WithField("binary", binary.Name).Info("signing")
+	return shell.Run(ctx, "", cmd, ctx.Env.Strings())
+}
+
+// codesignCommand returns the command that signs the binary with the given
+// path: codesign with an identity of the keychain, or rcodesign with a
+// certificate file, which also works outside macOS.
+// The returned cleanup function must be called once the command ran.
+func codesignCommand(ctx *context.Context, cfg config.MacOSSign, path string) ([]string, func(), error) {
+	cleanup := func() {}
+	t := tmpl.New(ctx)
+	entitlements, err := t.Apply(cfg.Entitlements)
+	if err != nil {
+		return nil, cleanup, err
+	}
+
+	if cfg.Identity != "" {
+		identity, err := t.Apply(cfg.Identity)
+		if err != nil {
+			return nil, cleanup, err
+		}
+		cmd := []string{"codesign", "--force", "--timestamp", "--options", "runtime", "--sign", identity}
+		if entitlements != "" {
+			cmd = append(cmd, "--entitlements", entitlements)
+		}
+		return append(cmd, path), cleanup, nil
+	}
+
+	certificate, err := t.Apply(cfg.Certificate)
+	if err != nil {
+		return nil, cleanup, err
+	}
+	password, err := t.Apply(cfg.Password)
+	if err != nil {
+		return nil, cleanup, err
+	}
+	// the password is passed in a file so it doesn't show up in the
+	// process list.
+	f, err := os.CreateTemp("", "goreleaser-codesign")
+	if err != nil {
+		return nil, cleanup, err
+	}
+	cleanup = func() { _ = os.Remove(f.Name()) }
+	if _, err := io.WriteString(f, password); err != nil {
+		_ = f.Close()
+		return nil, cleanup, err
+	}
+	if err := f.Close(); err != nil {
+		return nil, cleanup, err
+	}
+	cmd := []string{"rcodesign", "sign", "--p12-file", certificate, "--p12-password-file", f.Name(), "--code-signature-flags", "runtime"}
+	if entitlements != "" {
+		cmd = append(cmd, "--entitlements-xml-path", entitlements)
+	}
+	return append(cmd, path), cleanup, nil
+}
+
+func notarize(ctx *context.Context, cli *client, cfg config.MacOSNotarize, binary *artifact.Artifact) error {
+	path, err := zipBinary(binary)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(path)
+
+	name := filepath.Base(path)
+	log.FromContext(ctx).WithField("binary", binary.Name).Info("submitting for notarization")
+	id, err := cli.submit(ctx, name, path)
+	if err != nil {
+		return err
+	}
+	binary.Extra[artifact.ExtraNotarizationID] = id
+	if !cfg.Wait {
+		log.FromContext(ctx).WithField("binary", binary.Name).WithField("submission", id).Info("submitted for notarization")
+		return nil
+	}
+
+	log.FromContext(ctx).WithField("binary", binary.Name).WithField("submission", id).Info("waiting for notarization")
+	status, err := cli.wait(ctx, id, cfg.Timeout)
+	if err != nil {
+		return err
+	}
+	binary.Extra[artifact.ExtraNotarizationStatus] = status
+	if status != statusAccepted {
+		url, err := cli.logURL(ctx, id)
+		if err != nil {
+			return fmt.Errorf("submission %s: %s", id, status)
+		}
+		return fmt.Errorf("submission %s: %s, see %s for details", id, status, url)
+	}
+	log.FromContext(ctx).WithField("binary", binary.Name).WithField("submission", id).Info("notarized")
+	return nil
+}
+
+// zipBinary puts the given binary in a zip file, as only zip files, disk
+// images and packages can be notarized.
+func zipBinary(binary *artifact.Artifact) (string, error) {
+	f, err := os.CreateTemp("", binary.Name+"-*.zip")
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	src, err := os.Open(binary.Path)
+	if err != nil {
+		return "", err
+	}
+	defer src.Close()
+	info, err := src.Stat()
+	if err != nil {
+		return "", err
+	}
+	header, err := zip.FileInfoHeader(info)
+	if err != nil {
+		return "", err
+	}
+	header.Name = filepath.Base(binary.Path)
+	header.Method = zip.Deflate
+
+	w := zip.NewWriter(f)
+	dst, err := w.CreateHeader(header)
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		return "", err
+	}
+	if err := w.Close(); err != nil {
+		return "", err
+	}
+	return f.Name(), f.Close()
+}
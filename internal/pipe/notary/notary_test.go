@@ -0,0 +1,401 @@
+package notary
+
+import (
+	"archive/zip"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDescription(t *testing.T) {
+	require.NotEmpty(t, Pipe{}.String())
+}
+
+func TestSkip(t *testing.T) {
+	t.Run("skip", func(t *testing.T) {
+		require.True(t, Pipe{}.Skip(context.New(config.Project{})))
+	})
+
+	t.Run("skip sign", func(t *testing.T) {
+		ctx := context.New(config.Project{
+			Notarize: config.Notarize{MacOS: []config.MacOSSignNotarize{{}}},
+		})
+		ctx.SkipSign = true
+		require.True(t, Pipe{}.Skip(ctx))
+	})
+
+	t.Run("dont skip", func(t *testing.T) {
+		ctx := context.New(config.Project{
+			Notarize: config.Notarize{MacOS: []config.MacOSSignNotarize{{}}},
+		})
+		require.False(t, Pipe{}.Skip(ctx))
+	})
+}
+
+func TestDefault(t *testing.T) {
+	ctx := context.New(config.Project{
+		Notarize: config.Notarize{MacOS: []config.MacOSSignNotarize{{
+			Sign: config.MacOSSign{Identity: "Developer ID Application: Foo"},
+			Notarize: config.MacOSNotarize{
+				Enabled:  true,
+				IssuerID: "issuer",
+				KeyID:    "key",
+				Key:      "key.p8",
+			},
+		}}},
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	cfg := ctx.Config.Notarize.MacOS[0]
+	require.Equal(t, "default", cfg.ID)
+	require.Equal(t, 10*time.Minute, cfg.Notarize.Timeout)
+}
+
+func TestDefaultErrors(t *testing.T) {
+	for name, tt := range map[string]struct {
+		cfg config.MacOSSignNotarize
+		err string
+	}{
+		"no identity nor certificate": {
+			cfg: config.MacOSSignNotarize{},
+			err: "notarize default: sign requires either identity or certificate",
+		},
+		"identity and certificate": {
+			cfg: config.MacOSSignNotarize{Sign: config.MacOSSign{Identity: "foo", Certificate: "foo.p12"}},
+			err: "notarize default: sign requires either identity or certificate",
+		},
+		"notarize without key": {
+			cfg: config.MacOSSignNotarize{
+				Sign:     config.MacOSSign{Identity: "foo"},
+				Notarize: config.MacOSNotarize{Enabled: true, IssuerID: "issuer", KeyID: "key"},
+			},
+			err: "notarize default: notarize requires issuer_id, key_id and key",
+		},
+	} {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			ctx := context.New(config.Project{
+				Notarize: config.Notarize{MacOS: []config.MacOSSignNotarize{tt.cfg}},
+			})
+			require.EqualError(t, Pipe{}.Default(ctx), tt.err)
+		})
+	}
+}
+
+func TestDefaultGon(t *testing.T) {
+	notarize := config.MacOSNotarize{IssuerID: "issuer", KeyID: "key", Key: "key.p8"}
+
+	t.Run("hcl", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "gon.hcl")
+		require.NoError(t, os.WriteFile(path, []byte(`
+source = ["./dist/foo_darwin_amd64/foo"]
+bundle_id = "com.example.foo"
+
+apple_id {
+  username = "foo@example.com"
+  password = "@env:AC_PASSWORD"
+}
+
+sign {
+  application_identity = "Developer ID Application: Foo"
+  entitlements_file = "entitlements.plist"
+}
+`), 0o644))
+		ctx := context.New(config.Project{
+			Notarize: config.Notarize{MacOS: []config.MacOSSignNotarize{{
+				Gon:      path,
+				Notarize: notarize,
+			}}},
+		})
+		require.NoError(t, Pipe{}.Default(ctx))
+		cfg := ctx.Config.Notarize.MacOS[0]
+		require.Equal(t, "Developer ID Application: Foo", cfg.Sign.Identity)
+		require.Equal(t, "entitlements.plist", cfg.Sign.Entitlements)
+		require.True(t, cfg.Notarize.Enabled)
+		require.True(t, cfg.Notarize.Wait)
+	})
+
+	t.Run("json", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "gon.json")
+		require.NoError(t, os.WriteFile(path, []byte(`{
+  "sign": {"application_identity": "Developer ID Application: Foo"}
+}`), 0o644))
+		ctx := context.New(config.Project{
+			Notarize: config.Notarize{MacOS: []config.MacOSSignNotarize{{
+				Gon:  path,
+				Sign: config.MacOSSign{Entitlements: "mine.plist"},
+			}}},
+		})
+		require.NoError(t, Pipe{}.Default(ctx))
+		cfg := ctx.Config.Notarize.MacOS[0]
+		require.Equal(t, "Developer ID Application: Foo", cfg.Sign.Identity)
+		require.Equal(t, "mine.plist", cfg.Sign.Entitlements)
+		require.False(t, cfg.Notarize.Enabled)
+	})
+
+	t.Run("apple id without api key", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "gon.hcl")
+		require.NoError(t, os.WriteFile(path, []byte(`
+apple_id {
+  username = "foo@example.com"
+}
+
+sign {
+  application_identity = "Developer ID Application: Foo"
+}
+`), 0o644))
+		ctx := context.New(config.Project{
+			Notarize: config.Notarize{MacOS: []config.MacOSSignNotarize{{Gon: path}}},
+		})
+		require.EqualError(t, Pipe{}.Default(ctx), "notarize default: gon notarizes with an apple id, which can't be used here, set notarize.issuer_id, key_id and key to an App Store Connect API key instead")
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		ctx := context.New(config.Project{
+			Notarize: config.Notarize{MacOS: []config.MacOSSignNotarize{{
+				Gon: filepath.Join(t.TempDir(), "gon.hcl"),
+			}}},
+		})
+		err := Pipe{}.Default(ctx)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "notarize default: failed to load gon config")
+	})
+}
+
+func TestCodesignCommand(t *testing.T) {
+	ctx := context.New(config.Project{})
+	ctx.Env["P12_PASSWORD"] = "secret"
+
+	t.Run("identity", func(t *testing.T) {
+		cmd, cleanup, err := codesignCommand(ctx, config.MacOSSign{
+			Identity:     "Developer ID Application: Foo",
+			Entitlements: "entitlements.plist",
+		}, "dist/foo")
+		require.NoError(t, err)
+		defer cleanup()
+		require.Equal(t, []string{
+			"codesign", "--force", "--timestamp", "--options", "runtime",
+			"--sign", "Developer ID Application: Foo",
+			"--entitlements", "entitlements.plist",
+			"dist/foo",
+		}, cmd)
+	})
+
+	t.Run("certificate", func(t *testing.T) {
+		cmd, cleanup, err := codesignCommand(ctx, config.MacOSSign{
+			Certificate: "cert.p12",
+			Password:    "{{ .Env.P12_PASSWORD }}",
+		}, "dist/foo")
+		require.NoError(t, err)
+		require.Len(t, cmd, 9)
+		require.Equal(t, []string{"rcodesign", "sign", "--p12-file", "cert.p12", "--p12-password-file"}, cmd[:5])
+		require.Equal(t, []string{"--code-signature-flags", "runtime", "dist/foo"}, cmd[6:9])
+		bts, err := os.ReadFile(cmd[5])
+		require.NoError(t, err)
+		require.Equal(t, "secret", string(bts))
+
+		cleanup()
+		require.NoFileExists(t, cmd[5])
+	})
+
+	t.Run("invalid template", func(t *testing.T) {
+		_, cleanup, err := codesignCommand(ctx, config.MacOSSign{Identity: "{{ .Nope }"}, "dist/foo")
+		defer cleanup()
+		require.Error(t, err)
+	})
+}
+
+func p8Key(tb testing.TB) (*ecdsa.PrivateKey, string) {
+	tb.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(tb, err)
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(tb, err)
+	return key, string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+}
+
+type fakeNotary struct {
+	statuses  []string
+	submitted map[string]string
+	uploaded  []byte
+}
+
+// setup starts fake Notary API and S3 servers.
+func (n *fakeNotary) setup(tb testing.TB, key *ecdsa.PrivateKey) {
+	tb.Helper()
+	s3 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(tb, http.MethodPut, r.Method)
+		require.Equal(tb, "/notary-bucket/uploads/foo.zip", r.URL.Path)
+		bts, err := io.ReadAll(r.Body)
+		require.NoError(tb, err)
+		n.uploaded = bts
+	}))
+	tb.Cleanup(s3.Close)
+
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, err := jwt.Parse(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "), func(t *jwt.Token) (interface{}, error) {
+			require.Equal(tb, "key-id", t.Header["kid"])
+			return &key.PublicKey, nil
+		})
+		require.NoError(tb, err)
+		require.Equal(tb, "issuer-id", token.Claims.(jwt.MapClaims)["iss"])
+
+		var resp submissionResponse
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/submissions":
+			require.NoError(tb, json.NewDecoder(r.Body).Decode(&n.submitted))
+			resp.Data.ID = "submission-id"
+			resp.Data.Attributes.AccessKeyID = "access"
+			resp.Data.Attributes.SecretAccessKey = "secret"
+			resp.Data.Attributes.SessionToken = "session"
+			resp.Data.Attributes.Bucket = "notary-bucket"
+			resp.Data.Attributes.Object = "uploads/foo.zip"
+		case r.URL.Path == "/submissions/submission-id":
+			resp.Data.Attributes.Status = n.statuses[0]
+			n.statuses = n.statuses[1:]
+		case r.URL.Path == "/submissions/submission-id/logs":
+			resp.Data.Attributes.DeveloperLogURL = "https://example.com/log.json"
+		default:
+			http.NotFound(w, r)
+			return
+		}
+		require.NoError(tb, json.NewEncoder(w).Encode(resp))
+	}))
+	tb.Cleanup(api.Close)
+
+	prevURL, prevEndpoint, prevInterval := notaryURL, s3Endpoint, pollInterval
+	notaryURL, s3Endpoint, pollInterval = api.URL, s3.URL, time.Millisecond
+	tb.Cleanup(func() {
+		notaryURL, s3Endpoint, pollInterval = prevURL, prevEndpoint, prevInterval
+	})
+}
+
+func setupBinary(tb testing.TB) *artifact.Artifact {
+	tb.Helper()
+	path := filepath.Join(tb.TempDir(), "foo")
+	require.NoError(tb, os.WriteFile(path, []byte("binary"), 0o755))
+	return &artifact.Artifact{
+		Name:  "foo",
+		Path:  path,
+		Goos:  "darwin",
+		Type:  artifact.Binary,
+		Extra: map[string]interface{}{},
+	}
+}
+
+func TestNotarize(t *testing.T) {
+	key, p8 := p8Key(t)
+	n := &fakeNotary{statuses: []string{statusInProgress, statusAccepted}}
+	n.setup(t, key)
+
+	keyFile := filepath.Join(t.TempDir(), "key.p8")
+	require.NoError(t, os.WriteFile(keyFile, []byte(p8), 0o600))
+	ctx := context.New(config.Project{})
+	bts, err := notarizationKey(ctx, keyFile)
+	require.NoError(t, err)
+	cli, err := newClient("issuer-id", "key-id", bts)
+	require.NoError(t, err)
+
+	binary := setupBinary(t)
+	require.NoError(t, notarize(ctx, cli, config.MacOSNotarize{Wait: true, Timeout: time.Minute}, binary))
+	require.Equal(t, "submission-id", binary.Extra[artifact.ExtraNotarizationID])
+	require.Equal(t, statusAccepted, binary.Extra[artifact.ExtraNotarizationStatus])
+	require.Empty(t, n.statuses)
+	require.True(t, strings.HasPrefix(n.submitted["submissionName"], "foo-"))
+	require.Len(t, n.submitted["sha256"], 64)
+
+	zr, err := zip.NewReader(strings.NewReader(string(n.uploaded)), int64(len(n.uploaded)))
+	require.NoError(t, err)
+	require.Len(t, zr.File, 1)
+	require.Equal(t, "foo", zr.File[0].Name)
+}
+
+func TestSignAndNotarizeSnapshot(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell scripts are not executable on windows")
+	}
+	bin := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(bin, "codesign"), []byte("#!/bin/sh\n"), 0o755))
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	ctx := context.New(config.Project{})
+	ctx.Snapshot = true
+	binary := setupBinary(t)
+	require.NoError(t, signAndNotarize(ctx, config.MacOSSignNotarize{
+		Sign: config.MacOSSign{Identity: "Developer ID Application: Foo"},
+		Notarize: config.MacOSNotarize{
+			Enabled:  true,
+			IssuerID: "issuer",
+			KeyID:    "key",
+			Key:      filepath.Join(t.TempDir(), "nope.p8"),
+		},
+	}, []*artifact.Artifact{binary}))
+	require.NotContains(t, binary.Extra, artifact.ExtraNotarizationID)
+}
+
+func TestNotarizeNoWait(t *testing.T) {
+	key, p8 := p8Key(t)
+	n := &fakeNotary{}
+	n.setup(t, key)
+
+	ctx := context.New(config.Project{})
+	cli, err := newClient("issuer-id", "key-id", []byte(p8))
+	require.NoError(t, err)
+
+	binary := setupBinary(t)
+	require.NoError(t, notarize(ctx, cli, config.MacOSNotarize{}, binary))
+	require.Equal(t, "submission-id", binary.Extra[artifact.ExtraNotarizationID])
+	require.NotContains(t, binary.Extra, artifact.ExtraNotarizationStatus)
+	require.NotEmpty(t, n.uploaded)
+}
+
+func TestNotarizeInvalid(t *testing.T) {
+	key, p8 := p8Key(t)
+	n := &fakeNotary{statuses: []string{"Invalid"}}
+	n.setup(t, key)
+
+	ctx := context.New(config.Project{})
+	cli, err := newClient("issuer-id", "key-id", []byte(p8))
+	require.NoError(t, err)
+
+	binary := setupBinary(t)
+	err = notarize(ctx, cli, config.MacOSNotarize{Wait: true, Timeout: time.Minute}, binary)
+	require.EqualError(t, err, "submission submission-id: Invalid, see https://example.com/log.json for details")
+	require.Equal(t, "Invalid", binary.Extra[artifact.ExtraNotarizationStatus])
+}
+
+func TestNotarizeTimeout(t *testing.T) {
+	key, p8 := p8Key(t)
+	n := &fakeNotary{statuses: []string{statusInProgress, statusInProgress, statusInProgress}}
+	n.setup(t, key)
+	pollInterval = time.Second
+
+	ctx := context.New(config.Project{})
+	cli, err := newClient("issuer-id", "key-id", []byte(p8))
+	require.NoError(t, err)
+
+	err = notarize(ctx, cli, config.MacOSNotarize{Wait: true, Timeout: time.Millisecond}, setupBinary(t))
+	require.EqualError(t, err, "submission submission-id still in progress after 1ms")
+}
+
+func TestNewClientInvalidKey(t *testing.T) {
+	_, err := newClient("issuer-id", "key-id", []byte("nope"))
+	require.Error(t, err)
+}
@@ -0,0 +1,196 @@
+package pacman
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/md5" // nolint: gosec
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// entry is a package in the repository database.
+type entry struct {
+	name string
+	// dir is the folder of the package in the database, <name>-<version>.
+	dir   string
+	desc  []byte
+	files []byte
+}
+
+// database is the repository database of one architecture, the equivalent
+// of what repo-add maintains.
+type database struct {
+	// entries by package name, as the database holds a single version of
+	// each package.
+	entries map[string]entry
+}
+
+// newEntry returns the database entry of the given package, released as
+// filename, with its detached signature.
+func newEntry(info pkgInfo, filename string, data, sig []byte) entry {
+	md5sum := md5.Sum(data) // nolint: gosec
+	sha256sum := sha256.Sum256(data)
+	var desc bytes.Buffer
+	for _, section := range []struct {
+		name   string
+		values []string
+	}{
+		{"FILENAME", []string{filename}},
+		{"NAME", info.fields["pkgname"]},
+		{"BASE", info.fields["pkgbase"]},
+		{"VERSION", info.fields["pkgver"]},
+		{"DESC", info.fields["pkgdesc"]},
+		{"CSIZE", []string{strconv.Itoa(len(data))}},
+		{"ISIZE", info.fields["size"]},
+		{"MD5SUM", []string{hex.EncodeToString(md5sum[:])}},
+		{"SHA256SUM", []string{hex.EncodeToString(sha256sum[:])}},
+		{"PGPSIG", []string{base64.StdEncoding.EncodeToString(sig)}},
+		{"URL", info.fields["url"]},
+		{"LICENSE", info.fields["license"]},
+		{"ARCH", info.fields["arch"]},
+		{"BUILDDATE", info.fields["builddate"]},
+		{"PACKAGER", info.fields["packager"]},
+		{"CONFLICTS", info.fields["conflict"]},
+		{"PROVIDES", info.fields["provides"]},
+		{"DEPENDS", info.fields["depend"]},
+		{"OPTDEPENDS", info.fields["optdepend"]},
+	} {
+		writeSection(&desc, section.name, section.values)
+	}
+	var files bytes.Buffer
+	writeSection(&files, "FILES", info.files)
+	return entry{
+		name:  info.field("pkgname"),
+		dir:   info.field("pkgname") + "-" + info.field("pkgver"),
+		desc:  desc.Bytes(),
+		files: files.Bytes(),
+	}
+}
+
+// writeSection writes a %NAME% section of a desc or files entry, unless it
+// has no values.
+func writeSection(w *bytes.Buffer, name string, values []string) {
+	if len(values) == 0 || (len(values) == 1 && values[0] == "") {
+		return
+	}
+	fmt.Fprintf(w, "%%%s%%\n%s\n\n", name, strings.Join(values, "\n"))
+}
+
+// parseDatabase parses a gzipped files database, which has both the desc
+// and the files entries of the packages.
+// A nil database is an empty one.
+func parseDatabase(bts []byte) (*database, error) {
+	db := &database{entries: map[string]entry{}}
+	if bts == nil {
+		return db, nil
+	}
+	gz, err := gzip.NewReader(bytes.NewReader(bts))
+	if err != nil {
+		return nil, err
+	}
+	byDir := map[string]*entry{}
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		dir, name := path.Split(hdr.Name)
+		dir = strings.TrimSuffix(dir, "/")
+		e, ok := byDir[dir]
+		if !ok {
+			e = &entry{dir: dir}
+			byDir[dir] = e
+		}
+		content, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, err
+		}
+		switch name {
+		case "desc":
+			e.desc = content
+			e.name = descField(content, "NAME")
+		case "files":
+			e.files = content
+		}
+	}
+	for _, e := range byDir {
+		if e.name == "" {
+			return nil, fmt.Errorf("entry %s has no name", e.dir)
+		}
+		db.entries[e.name] = *e
+	}
+	return db, nil
+}
+
+// descField returns the first value of the given section of a desc entry.
+func descField(desc []byte, name string) string {
+	lines := strings.Split(string(desc), "\n")
+	for i, line := range lines {
+		if line == "%"+name+"%" && i+1 < len(lines) {
+			return lines[i+1]
+		}
+	}
+	return ""
+}
+
+// add adds the given package, replacing any other version of it.
+func (db *database) add(e entry) {
+	db.entries[e.name] = e
+}
+
+// render returns the gzipped database, with the files entries if withFiles
+// is set.
+func (db *database) render(withFiles bool, mtime time.Time) ([]byte, error) {
+	names := make([]string, 0, len(db.entries))
+	for name := range db.entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, name := range names {
+		e := db.entries[name]
+		if err := tw.WriteHeader(&tar.Header{
+			Typeflag: tar.TypeDir,
+			Name:     e.dir + "/",
+			Mode:     0o755,
+			ModTime:  mtime,
+		}); err != nil {
+			return nil, err
+		}
+		if err := writeEntry(tw, e.dir+"/desc", 0o644, e.desc, mtime); err != nil {
+			return nil, err
+		}
+		if !withFiles || e.files == nil {
+			continue
+		}
+		if err := writeEntry(tw, e.dir+"/files", 0o644, e.files, mtime); err != nil {
+			return nil, err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
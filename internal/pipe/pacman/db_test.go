@@ -0,0 +1,110 @@
+package pacman
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewEntry(t *testing.T) {
+	e := newEntry(testInfo("foo", "1.0.0-1"), "foo-1.0.0-1-x86_64.pkg.tar.zst", []byte("package"), []byte("signed"))
+	require.Equal(t, "foo", e.name)
+	require.Equal(t, "foo-1.0.0-1", e.dir)
+	require.Equal(t, `%FILENAME%
+foo-1.0.0-1-x86_64.pkg.tar.zst
+
+%NAME%
+foo
+
+%BASE%
+foo
+
+%VERSION%
+1.0.0-1
+
+%DESC%
+a foo
+
+%CSIZE%
+7
+
+%ISIZE%
+11
+
+%MD5SUM%
+efe90a8e604a7c840e88d03a67f6b7d8
+
+%SHA256SUM%
+bc4a71180870f7945155fbb02f4b0a2e3faa2a62d6d31b7039013055ed19869a
+
+%PGPSIG%
+c2lnbmVk
+
+%ARCH%
+x86_64
+
+%BUILDDATE%
+1641092645
+
+%PACKAGER%
+Unknown Packager
+
+%DEPENDS%
+glibc
+bash
+
+`, string(e.desc))
+	require.Equal(t, "%FILES%\nusr/\nusr/bin/\nusr/bin/foo\n\n", string(e.files))
+}
+
+func TestDatabase(t *testing.T) {
+	db, err := parseDatabase(nil)
+	require.NoError(t, err)
+	require.Empty(t, db.entries)
+
+	db.add(newEntry(testInfo("foo", "1.0.0-1"), "foo.pkg.tar.zst", nil, nil))
+	db.add(newEntry(testInfo("bar", "1.0.0-1"), "bar.pkg.tar.zst", nil, nil))
+	db.add(newEntry(testInfo("foo", "1.1.0-1"), "foo.pkg.tar.zst", nil, nil))
+
+	mtime := time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)
+	files, err := db.render(true, mtime)
+	require.NoError(t, err)
+	again, err := db.render(true, mtime)
+	require.NoError(t, err)
+	require.Equal(t, files, again, "rendering should be reproducible")
+
+	parsed, err := parseDatabase(files)
+	require.NoError(t, err)
+	require.Equal(t, db, parsed)
+
+	bts, err := db.render(false, mtime)
+	require.NoError(t, err)
+	parsed, err = parseDatabase(bts)
+	require.NoError(t, err)
+	require.Len(t, parsed.entries, 2)
+	require.Equal(t, "foo-1.1.0-1", parsed.entries["foo"].dir)
+	require.Nil(t, parsed.entries["foo"].files)
+}
+
+func TestParseDatabaseInvalid(t *testing.T) {
+	_, err := parseDatabase([]byte("not a database"))
+	require.Error(t, err)
+}
+
+func testInfo(name, version string) pkgInfo {
+	return pkgInfo{
+		fields: map[string][]string{
+			"pkgname":   {name},
+			"pkgbase":   {name},
+			"pkgver":    {version},
+			"pkgdesc":   {"a " + name},
+			"size":      {"11"},
+			"arch":      {"x86_64"},
+			"builddate": {"1641092645"},
+			"packager":  {"Unknown Packager"},
+			"depend":    {"glibc", "bash"},
+		},
+		files: []string{"usr/", "usr/bin/", "usr/bin/" + name},
+	}
+}
@@ -0,0 +1,387 @@
+// Package pacman implements the Pipe interface, packaging the linux binaries
+// as pacman packages and publishing them to signed Arch Linux repositories.
+package pacman
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/ids"
+	"github.com/goreleaser/goreleaser/internal/installfiles"
+	"github.com/goreleaser/goreleaser/internal/pipe"
+	"github.com/goreleaser/goreleaser/internal/pkgrepo"
+	"github.com/goreleaser/goreleaser/internal/semerrgroup"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+)
+
+// Pipe for pacman repositories.
+type Pipe struct{}
+
+func (Pipe) String() string                 { return "pacman repositories" }
+func (Pipe) Skip(ctx *context.Context) bool { return len(ctx.Config.PacmanRepositories) == 0 }
+
+// Default sets the pipe defaults.
+func (Pipe) Default(ctx *context.Context) error {
+	ids := ids.New("pacman_repositories")
+	for i := range ctx.Config.PacmanRepositories {
+		repo := &ctx.Config.PacmanRepositories[i]
+		if repo.ID == "" {
+			repo.ID = "default"
+		}
+		if repo.Name == "" {
+			repo.Name = ctx.Config.ProjectName
+		}
+		if repo.Description == "" {
+			repo.Description = repo.Name
+		}
+		if repo.Rel == "" {
+			repo.Rel = "1"
+		}
+		if repo.Packager == "" {
+			repo.Packager = "Unknown Packager"
+		}
+		if repo.Blob.Bucket == "" {
+			return fmt.Errorf("pacman_repositories %s: blob is required", repo.ID)
+		}
+		switch repo.Blob.Provider {
+		case "s3", "gs", "azblob":
+		default:
+			return fmt.Errorf("pacman_repositories %s: invalid blob provider %q, valid options are s3, gs and azblob", repo.ID, repo.Blob.Provider)
+		}
+		ids.Inc(repo.ID)
+	}
+	return ids.Validate()
+}
+
+// archs maps the platforms to the architectures pacman knows.
+var archs = map[string]string{
+	"linuxamd64": "x86_64",
+	"linux386":   "i686",
+	"linuxarm64": "aarch64",
+	"linuxarm6":  "armv6h",
+	"linuxarm7":  "armv7h",
+}
+
+// Run the pipe, creating the packages.
+func (Pipe) Run(ctx *context.Context) error {
+	extra, err := installfiles.Find(ctx)
+	if err != nil {
+		return err
+	}
+	g := semerrgroup.New(ctx.Parallelism)
+	for _, repo := range ctx.Config.PacmanRepositories {
+		filters := []artifact.Filter{
+			artifact.ByGoos("linux"),
+			artifact.ByType(artifact.Binary),
+		}
+		if len(repo.Builds) > 0 {
+			filters = append(filters, artifact.ByIDs(repo.Builds...))
+		}
+		for platform, binaries := range ctx.Artifacts.Filter(artifact.And(filters...)).GroupByPlatform() {
+			arch, ok := archs[platform]
+			if !ok {
+				log.FromContext(ctx).WithField("platform", platform).Warn("ignored unsupported platform")
+				continue
+			}
+			repo := repo
+			binaries := binaries
+			g.Go(func() error {
+				return create(ctx, repo, arch, binaries, extra)
+			})
+		}
+	}
+	return g.Wait()
+}
+
+func create(ctx *context.Context, cfg config.PacmanRepository, arch string, binaries []*artifact.Artifact, extra []installfiles.File) error {
+	var files []pkgFile
+	for _, binary := range binaries {
+		files = append(files, pkgFile{
+			source:      binary.Path,
+			destination: path.Join("usr/bin", binary.Name),
+			mode:        0o755,
+		})
+	}
+	for _, f := range extra {
+		files = append(files, pkgFile{
+			source:      f.Source,
+			destination: strings.TrimPrefix(f.PackagePath(), "/"),
+			mode:        0o644,
+		})
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].destination < files[j].destination
+	})
+	var size int64
+	for _, f := range files {
+		stat, err := os.Stat(f.source)
+		if err != nil {
+			return err
+		}
+		size += stat.Size()
+	}
+
+	// pacman versions can't have dashes, as they separate the release.
+	version := strings.ReplaceAll(ctx.Version, "-", "_") + "-" + cfg.Rel
+	filename := fmt.Sprintf("%s-%s-%s.pkg.tar.zst", cfg.Name, version, arch)
+	dir := filepath.Join(ctx.Config.Dist, "pacman", cfg.ID)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	pkgPath := filepath.Join(dir, filename)
+	log.FromContext(ctx).WithField("package", filename).Info("creating")
+	pkginfo := pkginfoFor(ctx, cfg, version, arch, size)
+	if err := makePackage(pkgPath, pkginfo, files, ctx.Date); err != nil {
+		return fmt.Errorf("failed to create pacman package: %w", err)
+	}
+
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Type:   artifact.PacmanPackage,
+		Name:   filename,
+		Path:   pkgPath,
+		Goos:   binaries[0].Goos,
+		Goarch: binaries[0].Goarch,
+		Goarm:  binaries[0].Goarm,
+		Extra: map[string]interface{}{
+			artifact.ExtraID: cfg.ID,
+		},
+	})
+	return nil
+}
+
+// pkginfoFor returns the .PKGINFO file of the package.
+func pkginfoFor(ctx *context.Context, cfg config.PacmanRepository, version, arch string, size int64) []byte {
+	lines := []string{
+		"# Generated by GoReleaser",
+		"pkgname = " + cfg.Name,
+		"pkgbase = " + cfg.Name,
+		"pkgver = " + version,
+		"pkgdesc = " + cfg.Description,
+	}
+	if cfg.Homepage != "" {
+		lines = append(lines, "url = "+cfg.Homepage)
+	}
+	lines = append(lines,
+		"builddate = "+strconv.FormatInt(ctx.Date.Unix(), 10),
+		"packager = "+cfg.Packager,
+		"size = "+strconv.FormatInt(size, 10),
+		"arch = "+arch,
+	)
+	if cfg.License != "" {
+		lines = append(lines, "license = "+cfg.License)
+	}
+	for _, field := range []struct {
+		key    string
+		values []string
+	}{
+		{"conflict", cfg.Conflicts},
+		{"provides", cfg.Provides},
+		{"depend", cfg.Depends},
+		{"optdepend", cfg.OptDepends},
+	} {
+		for _, value := range field.values {
+			lines = append(lines, field.key+" = "+value)
+		}
+	}
+	return []byte(strings.Join(lines, "\n") + "\n")
+}
+
+// Publish the repositories.
+func (Pipe) Publish(ctx *context.Context) error {
+	skips := pipe.SkipMemento{}
+	for _, repo := range ctx.Config.PacmanRepositories {
+		err := doPublish(ctx, repo)
+		if err != nil && pipe.IsSkip(err) {
+			skips.Remember(err)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return skips.Evaluate()
+}
+
+func doPublish(ctx *context.Context, cfg config.PacmanRepository) error {
+	if strings.TrimSpace(cfg.SkipUpload) == "true" {
+		return pipe.Skip("pacman_repositories.skip_upload is set")
+	}
+	if strings.TrimSpace(cfg.SkipUpload) == "auto" && ctx.Semver.Prerelease != "" {
+		return pipe.Skip("prerelease detected with 'auto' upload, skipping pacman repository publish")
+	}
+
+	pkgs := ctx.Artifacts.Filter(artifact.And(
+		artifact.ByType(artifact.PacmanPackage),
+		artifact.ByIDs(cfg.ID),
+	)).List()
+	if len(pkgs) == 0 {
+		return pipe.Skip("no pacman packages found")
+	}
+
+	key, err := tmpl.New(ctx).Apply(cfg.KeyID)
+	if err != nil {
+		return err
+	}
+
+	st, err := pkgrepo.Open(ctx, cfg.Blob, cfg.Folder)
+	if err != nil {
+		return err
+	}
+	defer st.Close()
+
+	log := log.FromContext(ctx).WithField("repository", cfg.ID)
+	entries := map[string][]entry{}
+	for _, pkg := range pkgs {
+		info, err := readPackage(pkg.Path)
+		if err != nil {
+			return err
+		}
+		data, err := os.ReadFile(pkg.Path)
+		if err != nil {
+			return err
+		}
+		sig, err := sign(ctx, key, pkg.Path)
+		if err != nil {
+			return err
+		}
+		// pacman downloads the packages from the folder of their
+		// architecture, next to its database.
+		arch := info.field("arch")
+		location := path.Join(arch, pkg.Name)
+		log.WithField("package", pkg.Name).Info("adding")
+		if err := st.Write(ctx, location, data); err != nil {
+			return fmt.Errorf("failed to write %s: %w", location, err)
+		}
+		if err := st.Write(ctx, location+".sig", sig); err != nil {
+			return fmt.Errorf("failed to write %s.sig: %w", location, err)
+		}
+		entries[arch] = append(entries[arch], newEntry(info, pkg.Name, data, sig))
+	}
+
+	archs := make([]string, 0, len(entries))
+	for arch := range entries {
+		archs = append(archs, arch)
+	}
+	sort.Strings(archs)
+	for _, arch := range archs {
+		// the other packages in the database are kept, so a repository can
+		// hold several of them.
+		db, err := readDatabase(ctx, st, cfg, arch)
+		if err != nil {
+			return err
+		}
+		for _, e := range entries[arch] {
+			db.add(e)
+		}
+		if err := writeDatabase(ctx, st, cfg, key, arch, db); err != nil {
+			return err
+		}
+	}
+	logInstructions(ctx, cfg, key)
+	return nil
+}
+
+// readDatabase reads the files database of the given architecture, if it
+// exists.
+func readDatabase(ctx *context.Context, st *pkgrepo.Store, cfg config.PacmanRepository, arch string) (*database, error) {
+	location := path.Join(arch, cfg.Name+".files.tar.gz")
+	bts, err := st.Read(ctx, location)
+	if errors.Is(err, os.ErrNotExist) {
+		return parseDatabase(nil)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", location, err)
+	}
+	db, err := parseDatabase(bts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", location, err)
+	}
+	return db, nil
+}
+
+// writeDatabase signs and writes the databases of the given architecture.
+//
+// Like repo-add, both the database and the files database are written, with
+// and without their .tar.gz extension, as pacman looks for <name>.db and
+// <name>.files.
+func writeDatabase(ctx *context.Context, st *pkgrepo.Store, cfg config.PacmanRepository, key, arch string, db *database) error {
+	dir := filepath.Join(ctx.Config.Dist, "pacman", cfg.ID, arch)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	for _, kind := range []string{"files", "db"} {
+		bts, err := db.render(kind == "files", ctx.Date)
+		if err != nil {
+			return err
+		}
+		name := cfg.Name + "." + kind
+		dbPath := filepath.Join(dir, name+".tar.gz")
+		if err := os.WriteFile(dbPath, bts, 0o644); err != nil { //nolint: gosec
+			return err
+		}
+		sig, err := sign(ctx, key, dbPath)
+		if err != nil {
+			return err
+		}
+		// the signatures are written first, so the databases never have an
+		// outdated one.
+		for _, file := range []struct {
+			name string
+			data []byte
+		}{
+			{name + ".tar.gz.sig", sig},
+			{name + ".sig", sig},
+			{name + ".tar.gz", bts},
+			{name, bts},
+		} {
+			location := path.Join(arch, file.name)
+			if err := st.Write(ctx, location, file.data); err != nil {
+				return fmt.Errorf("failed to write %s: %w", location, err)
+			}
+		}
+	}
+	return nil
+}
+
+// sign signs the given file with gpg and returns its detached binary
+// signature, which is also written next to it.
+func sign(ctx *context.Context, key, file string) ([]byte, error) {
+	args := []string{"--batch", "--yes"}
+	if key != "" {
+		args = append(args, "--local-user", key)
+	}
+	args = append(args, "--detach-sign", "--output", file+".sig", file)
+	/* #nosec */
+	cmd := exec.CommandContext(ctx, "gpg", args...)
+	cmd.Env = ctx.Env.Strings()
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("failed to sign %s: %w: %s", filepath.Base(file), err, string(out))
+	}
+	return os.ReadFile(file + ".sig")
+}
+
+// logInstructions logs how to install the packages from the repository, if
+// its url is known.
+func logInstructions(ctx *context.Context, cfg config.PacmanRepository, key string) {
+	url := pkgrepo.InstallURL(ctx, cfg.URL)
+	if url == "" {
+		return
+	}
+	log.FromContext(ctx).Info("install with:")
+	if key != "" {
+		log.FromContext(ctx).Infof("sudo pacman-key --recv-keys %s && sudo pacman-key --lsign-key %s", key, key)
+	}
+	log.FromContext(ctx).Infof("printf '[%s]\\nServer = %s/$arch\\n' | sudo tee -a /etc/pacman.conf", cfg.Name, url)
+	log.FromContext(ctx).Info("sudo pacman -Sy " + cfg.Name)
+}
@@ -0,0 +1,284 @@
+package pacman
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/pipe"
+	"github.com/goreleaser/goreleaser/internal/testlib"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+	_ "gocloud.dev/blob/fileblob"
+)
+
+func TestDescription(t *testing.T) {
+	require.NotEmpty(t, Pipe{}.String())
+}
+
+func TestSkip(t *testing.T) {
+	require.True(t, Pipe{}.Skip(context.New(config.Project{})))
+	require.False(t, Pipe{}.Skip(context.New(config.Project{
+		PacmanRepositories: []config.PacmanRepository{{}},
+	})))
+}
+
+func TestDefault(t *testing.T) {
+	ctx := context.New(config.Project{
+		ProjectName: "foo",
+		PacmanRepositories: []config.PacmanRepository{{
+			Blob: config.RepositoryBlob{Provider: "s3", Bucket: "bar"},
+		}},
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.Equal(t, config.PacmanRepository{
+		ID:          "default",
+		Name:        "foo",
+		Description: "foo",
+		Rel:         "1",
+		Packager:    "Unknown Packager",
+		Blob:        config.RepositoryBlob{Provider: "s3", Bucket: "bar"},
+	}, ctx.Config.PacmanRepositories[0])
+}
+
+func TestDefaultErrors(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		repos []config.PacmanRepository
+		err   string
+	}{
+		{
+			name:  "no blob",
+			repos: []config.PacmanRepository{{}},
+			err:   "pacman_repositories default: blob is required",
+		},
+		{
+			name: "invalid provider",
+			repos: []config.PacmanRepository{{
+				Blob: config.RepositoryBlob{Bucket: "bar"},
+			}},
+			err: `pacman_repositories default: invalid blob provider "", valid options are s3, gs and azblob`,
+		},
+		{
+			name: "duplicated ids",
+			repos: []config.PacmanRepository{
+				{Blob: config.RepositoryBlob{Provider: "s3", Bucket: "bar"}},
+				{Blob: config.RepositoryBlob{Provider: "gs", Bucket: "bar"}},
+			},
+			err: "found 2 pacman_repositories with the ID 'default', please fix your config",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.New(config.Project{
+				ProjectName:        "foo",
+				PacmanRepositories: tt.repos,
+			})
+			require.EqualError(t, Pipe{}.Default(ctx), tt.err)
+		})
+	}
+}
+
+func TestRun(t *testing.T) {
+	ctx := newContext(t, config.PacmanRepository{
+		Builds:      []string{"default"},
+		Description: "a foo",
+		Homepage:    "https://example.com",
+		License:     "MIT",
+		Depends:     []string{"glibc"},
+		Blob:        config.RepositoryBlob{Provider: "s3", Bucket: "bar"},
+	})
+	ctx.Git.CurrentTag = "v1.0.0-rc1"
+	ctx.Version = "1.0.0-rc1"
+	ctx.Config.Completions.Bash = "testdata/foo.bash"
+	addBinary(t, ctx, "default", "amd64", "")
+	addBinary(t, ctx, "default", "arm", "7")
+	addBinary(t, ctx, "other", "arm64", "")
+	addBinary(t, ctx, "default", "mips", "")
+	require.NoError(t, Pipe{}.Run(ctx))
+
+	pkgs := ctx.Artifacts.Filter(artifact.ByType(artifact.PacmanPackage)).List()
+	require.Len(t, pkgs, 2)
+	names := []string{pkgs[0].Name, pkgs[1].Name}
+	require.ElementsMatch(t, []string{
+		"foo-1.0.0_rc1-1-x86_64.pkg.tar.zst",
+		"foo-1.0.0_rc1-1-armv7h.pkg.tar.zst",
+	}, names)
+
+	for _, pkg := range pkgs {
+		require.Equal(t, "default", pkg.ID())
+		info, err := readPackage(pkg.Path)
+		require.NoError(t, err)
+		require.Equal(t, "foo", info.field("pkgname"))
+		require.Equal(t, "1.0.0_rc1-1", info.field("pkgver"))
+		require.Equal(t, "a foo", info.field("pkgdesc"))
+		require.Equal(t, "https://example.com", info.field("url"))
+		require.Equal(t, "MIT", info.field("license"))
+		require.Equal(t, "Unknown Packager", info.field("packager"))
+		require.Equal(t, "1641092645", info.field("builddate"))
+		require.Equal(t, []string{"glibc"}, info.fields["depend"])
+		require.Equal(t, []string{
+			"usr/",
+			"usr/bin/",
+			"usr/bin/foo",
+			"usr/share/",
+			"usr/share/bash-completion/",
+			"usr/share/bash-completion/completions/",
+			"usr/share/bash-completion/completions/foo",
+		}, info.files)
+	}
+}
+
+func TestPublish(t *testing.T) {
+	calls := testlib.FakeGPG(t)
+	bucket := t.TempDir()
+	cfg := config.PacmanRepository{
+		KeyID:  "ABC123",
+		URL:    "https://arch.example.com/{{ .ProjectName }}",
+		Blob:   config.RepositoryBlob{Provider: "s3", Bucket: bucket},
+		Folder: "{{ .ProjectName }}",
+	}
+	ctx := newContext(t, cfg)
+	addBinary(t, ctx, "default", "amd64", "")
+	addBinary(t, ctx, "default", "arm64", "")
+	require.NoError(t, Pipe{}.Run(ctx))
+	require.NoError(t, Pipe{}.Publish(ctx))
+
+	repo := filepath.Join(bucket, "foo")
+	for _, name := range []string{
+		"x86_64/foo-1.0.0-1-x86_64.pkg.tar.zst",
+		"x86_64/foo-1.0.0-1-x86_64.pkg.tar.zst.sig",
+		"x86_64/foo.db",
+		"x86_64/foo.db.sig",
+		"x86_64/foo.db.tar.gz",
+		"x86_64/foo.db.tar.gz.sig",
+		"x86_64/foo.files",
+		"x86_64/foo.files.sig",
+		"x86_64/foo.files.tar.gz",
+		"x86_64/foo.files.tar.gz.sig",
+		"aarch64/foo-1.0.0-1-aarch64.pkg.tar.zst",
+		"aarch64/foo.db",
+	} {
+		require.FileExists(t, filepath.Join(repo, name))
+	}
+
+	bts, err := os.ReadFile(calls)
+	require.NoError(t, err)
+	require.Contains(t, string(bts), "--batch --yes --local-user ABC123 --detach-sign --output ")
+
+	db := readDB(t, filepath.Join(repo, "x86_64/foo.db"))
+	require.Len(t, db.entries, 1)
+	require.Nil(t, db.entries["foo"].files)
+	require.Equal(t, "foo-1.0.0-1", db.entries["foo"].dir)
+
+	// a new release replaces the package in the database, keeping the
+	// others.
+	ctx = newContext(t, cfg)
+	ctx.Config.PacmanRepositories[0].Name = "bar"
+	addBinary(t, ctx, "default", "amd64", "")
+	require.NoError(t, Pipe{}.Run(ctx))
+	require.NoError(t, Pipe{}.Publish(ctx))
+	ctx = newContext(t, cfg)
+	ctx.Git.CurrentTag = "v1.1.0"
+	ctx.Version = "1.1.0"
+	addBinary(t, ctx, "default", "amd64", "")
+	require.NoError(t, Pipe{}.Run(ctx))
+	require.NoError(t, Pipe{}.Publish(ctx))
+
+	db = readDB(t, filepath.Join(repo, "x86_64/foo.files"))
+	require.Len(t, db.entries, 1)
+	require.Equal(t, "foo-1.1.0-1", db.entries["foo"].dir)
+	require.Contains(t, string(db.entries["foo"].files), "usr/bin/foo\n")
+	require.Len(t, readDB(t, filepath.Join(repo, "x86_64/bar.db")).entries, 1)
+	require.FileExists(t, filepath.Join(repo, "x86_64/foo-1.0.0-1-x86_64.pkg.tar.zst"))
+}
+
+func TestPublishSkip(t *testing.T) {
+	t.Run("skip upload", func(t *testing.T) {
+		ctx := newContext(t, config.PacmanRepository{
+			SkipUpload: "true",
+			Blob:       config.RepositoryBlob{Provider: "s3", Bucket: "foo"},
+		})
+		require.True(t, pipe.IsSkip(Pipe{}.Publish(ctx)))
+	})
+
+	t.Run("auto", func(t *testing.T) {
+		ctx := newContext(t, config.PacmanRepository{
+			SkipUpload: "auto",
+			Blob:       config.RepositoryBlob{Provider: "s3", Bucket: "foo"},
+		})
+		ctx.Semver.Prerelease = "beta1"
+		require.True(t, pipe.IsSkip(Pipe{}.Publish(ctx)))
+	})
+
+	t.Run("no packages", func(t *testing.T) {
+		ctx := newContext(t, config.PacmanRepository{
+			Blob: config.RepositoryBlob{Provider: "s3", Bucket: "foo"},
+		})
+		require.EqualError(t, Pipe{}.Publish(ctx), "no pacman packages found")
+	})
+}
+
+func TestPublishSignFails(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "gpg"), []byte("#!/bin/sh\necho no key\nexit 1\n"), 0o755))
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	bucket := t.TempDir()
+	ctx := newContext(t, config.PacmanRepository{
+		Blob: config.RepositoryBlob{Provider: "s3", Bucket: bucket},
+	})
+	addBinary(t, ctx, "default", "amd64", "")
+	require.NoError(t, Pipe{}.Run(ctx))
+	err := Pipe{}.Publish(ctx)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to sign foo-1.0.0-1-x86_64.pkg.tar.zst")
+	require.Contains(t, err.Error(), "no key")
+	require.NoFileExists(t, filepath.Join(bucket, "x86_64/foo.db"))
+}
+
+// newContext returns a context with the given repository, published to a
+// local folder with fileblob instead of a real bucket.
+func newContext(tb testing.TB, repo config.PacmanRepository) *context.Context {
+	tb.Helper()
+	ctx := context.New(config.Project{
+		ProjectName:        "foo",
+		Dist:               tb.TempDir(),
+		PacmanRepositories: []config.PacmanRepository{repo},
+	})
+	ctx.Git.CurrentTag = "v1.0.0"
+	ctx.Version = "1.0.0"
+	ctx.Date = time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)
+	require.NoError(tb, Pipe{}.Default(ctx))
+	ctx.Config.PacmanRepositories[0].Blob.Provider = "file"
+	return ctx
+}
+
+func addBinary(tb testing.TB, ctx *context.Context, id, goarch, goarm string) {
+	tb.Helper()
+	dir := filepath.Join(ctx.Config.Dist, id+"_"+goarch+goarm)
+	require.NoError(tb, os.MkdirAll(dir, 0o755))
+	path := filepath.Join(dir, "foo")
+	require.NoError(tb, os.WriteFile(path, []byte("fake binary"), 0o755))
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name:   "foo",
+		Path:   path,
+		Goos:   "linux",
+		Goarch: goarch,
+		Goarm:  goarm,
+		Type:   artifact.Binary,
+		Extra: map[string]interface{}{
+			artifact.ExtraID: id,
+		},
+	})
+}
+
+func readDB(tb testing.TB, path string) *database {
+	tb.Helper()
+	bts, err := os.ReadFile(path)
+	require.NoError(tb, err)
+	db, err := parseDatabase(bts)
+	require.NoError(tb, err)
+	return db
+}
@@ -0,0 +1,188 @@
+package pacman
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+const pkginfoName = ".PKGINFO"
+
+// pkgFile is a file installed by a package.
+type pkgFile struct {
+	// source is the path of the file on disk.
+	source string
+	// destination is the path of the file in the package, relative to the
+	// root folder.
+	destination string
+	mode        int64
+}
+
+// pkgInfo is the metadata of a pacman package.
+type pkgInfo struct {
+	// fields of the .PKGINFO file, by key.
+	fields map[string][]string
+	// files installed by the package, the folders ending with a slash.
+	files []string
+}
+
+func (i pkgInfo) field(key string) string {
+	if values := i.fields[key]; len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// makePackage creates a pacman package, a zstd compressed tar with the
+// .PKGINFO file followed by the files it installs.
+func makePackage(pkgPath string, pkginfo []byte, files []pkgFile, mtime time.Time) error {
+	f, err := os.Create(pkgPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	zw, err := zstd.NewWriter(f)
+	if err != nil {
+		return err
+	}
+	tw := tar.NewWriter(zw)
+
+	if err := writeEntry(tw, pkginfoName, 0o644, pkginfo, mtime); err != nil {
+		return err
+	}
+	for _, dir := range foldersOf(files) {
+		if err := tw.WriteHeader(&tar.Header{
+			Typeflag: tar.TypeDir,
+			Name:     dir,
+			Mode:     0o755,
+			ModTime:  mtime,
+			Uname:    "root",
+			Gname:    "root",
+		}); err != nil {
+			return err
+		}
+	}
+	for _, file := range files {
+		bts, err := os.ReadFile(file.source)
+		if err != nil {
+			return err
+		}
+		if err := writeEntry(tw, file.destination, file.mode, bts, mtime); err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+func writeEntry(tw *tar.Writer, name string, mode int64, bts []byte, mtime time.Time) error {
+	if err := tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Mode:     mode,
+		Size:     int64(len(bts)),
+		ModTime:  mtime,
+		Uname:    "root",
+		Gname:    "root",
+	}); err != nil {
+		return err
+	}
+	_, err := tw.Write(bts)
+	return err
+}
+
+// foldersOf returns the folders holding the given files, sorted, each
+// ending with a slash.
+func foldersOf(files []pkgFile) []string {
+	seen := map[string]bool{}
+	for _, file := range files {
+		for dir := path.Dir(file.destination); dir != "." && dir != "/"; dir = path.Dir(dir) {
+			seen[dir+"/"] = true
+		}
+	}
+	dirs := make([]string, 0, len(seen))
+	for dir := range seen {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+	return dirs
+}
+
+// readPackage reads the metadata and the list of files of the given pacman
+// package.
+func readPackage(pkgPath string) (pkgInfo, error) {
+	var info pkgInfo
+	f, err := os.Open(pkgPath)
+	if err != nil {
+		return info, err
+	}
+	defer f.Close()
+	zr, err := zstd.NewReader(f)
+	if err != nil {
+		return info, fmt.Errorf("%s is not a pacman package", pkgPath)
+	}
+	defer zr.Close()
+
+	tr := tar.NewReader(zr)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return info, fmt.Errorf("failed to read %s: %w", pkgPath, err)
+		}
+		if hdr.Name == pkginfoName {
+			bts, err := io.ReadAll(tr)
+			if err != nil {
+				return info, fmt.Errorf("failed to read %s: %w", pkgPath, err)
+			}
+			info.fields = parsePKGINFO(bts)
+			continue
+		}
+		// the other metadata files, e.g. .MTREE, are not installed.
+		if strings.HasPrefix(hdr.Name, ".") {
+			continue
+		}
+		info.files = append(info.files, hdr.Name)
+	}
+	if info.field("pkgname") == "" || info.field("pkgver") == "" || info.field("arch") == "" {
+		return info, fmt.Errorf("failed to read %s: .PKGINFO is missing pkgname, pkgver or arch", pkgPath)
+	}
+	sort.Strings(info.files)
+	return info, nil
+}
+
+// parsePKGINFO parses the `key = value` lines of a .PKGINFO file.
+func parsePKGINFO(bts []byte) map[string][]string {
+	fields := map[string][]string{}
+	scanner := bufio.NewScanner(bytes.NewReader(bts))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		key := strings.TrimSpace(parts[0])
+		fields[key] = append(fields[key], strings.TrimSpace(parts[1]))
+	}
+	return fields
+}
@@ -0,0 +1,53 @@
+package pacman
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadPackage(t *testing.T) {
+	pkgPath := filepath.Join(t.TempDir(), "foo.pkg.tar.zst")
+	pkginfo := []byte("# comment\npkgname = foo\npkgver = 1.0.0-1\narch = x86_64\ndepend = glibc\ndepend = bash\n")
+	require.NoError(t, makePackage(pkgPath, pkginfo, []pkgFile{
+		{source: "testdata/foo.bash", destination: "usr/share/bash-completion/completions/foo", mode: 0o644},
+		{source: "testdata/foo.bash", destination: "usr/bin/foo", mode: 0o755},
+	}, time.Now()))
+
+	info, err := readPackage(pkgPath)
+	require.NoError(t, err)
+	require.Equal(t, map[string][]string{
+		"pkgname": {"foo"},
+		"pkgver":  {"1.0.0-1"},
+		"arch":    {"x86_64"},
+		"depend":  {"glibc", "bash"},
+	}, info.fields)
+	require.Equal(t, []string{
+		"usr/",
+		"usr/bin/",
+		"usr/bin/foo",
+		"usr/share/",
+		"usr/share/bash-completion/",
+		"usr/share/bash-completion/completions/",
+		"usr/share/bash-completion/completions/foo",
+	}, info.files)
+}
+
+func TestReadPackageErrors(t *testing.T) {
+	t.Run("not a package", func(t *testing.T) {
+		pkgPath := filepath.Join(t.TempDir(), "foo.pkg.tar.zst")
+		require.NoError(t, os.WriteFile(pkgPath, []byte("nope"), 0o644))
+		_, err := readPackage(pkgPath)
+		require.Error(t, err)
+	})
+
+	t.Run("no pkginfo fields", func(t *testing.T) {
+		pkgPath := filepath.Join(t.TempDir(), "foo.pkg.tar.zst")
+		require.NoError(t, makePackage(pkgPath, []byte("pkgname = foo\n"), nil, time.Now()))
+		_, err := readPackage(pkgPath)
+		require.EqualError(t, err, "failed to read "+pkgPath+": .PKGINFO is missing pkgname, pkgver or arch")
+	})
+}
@@ -0,0 +1 @@
+complete -F _foo foo
@@ -24,6 +24,10 @@ func (Pipe) Default(ctx *context.Context) error {
 			ctx.Config.ProjectName = ctx.Config.Release.GitLab.Name
 		case ctx.Config.Release.Gitea.Name != "":
 			ctx.Config.ProjectName = ctx.Config.Release.Gitea.Name
+		case ctx.Config.Release.Bitbucket.Name != "":
+			ctx.Config.ProjectName = ctx.Config.Release.Bitbucket.Name
+		case ctx.Config.Release.AzureDevOps.Name != "":
+			ctx.Config.ProjectName = ctx.Config.Release.AzureDevOps.Name
 		default:
 			return fmt.Errorf("couldn't guess project_name, please add it to your config")
 		}
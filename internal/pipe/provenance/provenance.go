@@ -0,0 +1,236 @@
+// Package provenance provides a pipe that creates a SLSA provenance
+// attestation of the artifacts.
+package provenance
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/context"
+)
+
+const (
+	statementType  = "https://in-toto.io/Statement/v1"
+	predicateType  = "https://slsa.dev/provenance/v1"
+	buildType      = "https://goreleaser.com/provenance/v1"
+	defaultBuilder = "https://goreleaser.com"
+)
+
+// Pipe that creates a SLSA provenance attestation of the artifacts.
+type Pipe struct{}
+
+func (Pipe) String() string { return "creating provenance attestation" }
+func (Pipe) Skip(ctx *context.Context) bool {
+	return !ctx.Config.Provenance.Enabled
+}
+
+// Default sets the pipe defaults.
+func (Pipe) Default(ctx *context.Context) error {
+	cfg := &ctx.Config.Provenance
+	if cfg.NameTemplate == "" {
+		cfg.NameTemplate = "{{ .ProjectName }}_{{ .Version }}.intoto.json"
+	}
+	if cfg.BuilderID == "" {
+		cfg.BuilderID = defaultBuilder
+	}
+	return nil
+}
+
+// Run the pipe.
+func (Pipe) Run(ctx *context.Context) error {
+	cfg := ctx.Config.Provenance
+	filter := artifact.Or(
+		artifact.ByType(artifact.UploadableArchive),
+		artifact.ByType(artifact.UploadableArchivePart),
+		artifact.ByType(artifact.UploadableBinary),
+		artifact.ByType(artifact.UploadableSourceArchive),
+		artifact.ByType(artifact.LinuxPackage),
+		artifact.ByType(artifact.AppImage),
+		artifact.ByType(artifact.MSI),
+		artifact.ByType(artifact.DMG),
+		artifact.ByType(artifact.SBOM),
+	)
+	if ctx.Config.Source.SkipProvenance {
+		filter = artifact.And(filter, artifact.Not(artifact.ByType(artifact.UploadableSourceArchive)))
+	}
+	if len(cfg.IDs) > 0 {
+		filter = artifact.And(filter, artifact.ByIDs(cfg.IDs...))
+	}
+	artifacts := ctx.Artifacts.Filter(filter).List()
+	if len(artifacts) == 0 {
+		log.FromContext(ctx).Warn("no artifacts to attest")
+		return nil
+	}
+
+	t := tmpl.New(ctx)
+	name, err := t.Apply(cfg.NameTemplate)
+	if err != nil {
+		return fmt.Errorf("provenance: %w", err)
+	}
+	builderID, err := t.Apply(cfg.BuilderID)
+	if err != nil {
+		return fmt.Errorf("provenance: %w", err)
+	}
+
+	st, err := newStatement(ctx, builderID, artifacts)
+	if err != nil {
+		return fmt.Errorf("provenance: %w", err)
+	}
+	bts, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return fmt.Errorf("provenance: %w", err)
+	}
+	path := filepath.Join(ctx.Config.Dist, name)
+	log.FromContext(ctx).WithField("file", path).Info("writing")
+	if err := os.WriteFile(path, bts, 0o644); err != nil {
+		return fmt.Errorf("provenance: %w", err)
+	}
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Type: artifact.Provenance,
+		Name: name,
+		Path: path,
+	})
+	return nil
+}
+
+type statement struct {
+	Type          string    `json:"_type"`
+	Subject       []subject `json:"subject"`
+	PredicateType string    `json:"predicateType"`
+	Predicate     predicate `json:"predicate"`
+}
+
+type subject struct {
+	Name   string            `json:"name"`
+	Digest map[string]string `json:"digest"`
+}
+
+type predicate struct {
+	BuildDefinition buildDefinition `json:"buildDefinition"`
+	RunDetails      runDetails      `json:"runDetails"`
+}
+
+type buildDefinition struct {
+	BuildType            string                 `json:"buildType"`
+	ExternalParameters   map[string]interface{} `json:"externalParameters"`
+	ResolvedDependencies []resourceDescriptor   `json:"resolvedDependencies,omitempty"`
+}
+
+type resourceDescriptor struct {
+	URI    string            `json:"uri"`
+	Digest map[string]string `json:"digest"`
+}
+
+type runDetails struct {
+	Builder  builder  `json:"builder"`
+	Metadata metadata `json:"metadata"`
+}
+
+type builder struct {
+	ID string `json:"id"`
+}
+
+type metadata struct {
+	StartedOn  time.Time `json:"startedOn"`
+	FinishedOn time.Time `json:"finishedOn"`
+}
+
+func newStatement(ctx *context.Context, builderID string, artifacts []*artifact.Artifact) (statement, error) {
+	subjects := make([]subject, 0, len(artifacts))
+	for _, a := range artifacts {
+		sum, err := a.Checksum("sha256")
+		if err != nil {
+			return statement{}, err
+		}
+		subjects = append(subjects, subject{
+			Name:   a.Name,
+			Digest: map[string]string{"sha256": sum},
+		})
+	}
+	sort.Slice(subjects, func(i, j int) bool {
+		return subjects[i].Name < subjects[j].Name
+	})
+
+	pred, err := newPredicate(ctx, builderID)
+	if err != nil {
+		return statement{}, err
+	}
+
+	return statement{
+		Type:          statementType,
+		Subject:       subjects,
+		PredicateType: predicateType,
+		Predicate:     pred,
+	}, nil
+}
+
+// Predicate returns the SLSA provenance predicate of the current release,
+// built by the given builder, and its type.
+func Predicate(ctx *context.Context, builderID string) (string, interface{}, error) {
+	pred, err := newPredicate(ctx, builderID)
+	return predicateType, pred, err
+}
+
+func newPredicate(ctx *context.Context, builderID string) (predicate, error) {
+	materials, err := resolvedDependencies(ctx)
+	if err != nil {
+		return predicate{}, err
+	}
+	return predicate{
+		BuildDefinition: buildDefinition{
+			BuildType: buildType,
+			ExternalParameters: map[string]interface{}{
+				"tag":      ctx.Git.CurrentTag,
+				"version":  ctx.Version,
+				"snapshot": ctx.Snapshot,
+			},
+			ResolvedDependencies: materials,
+		},
+		RunDetails: runDetails{
+			Builder: builder{ID: builderID},
+			Metadata: metadata{
+				StartedOn:  ctx.Date.UTC(),
+				FinishedOn: time.Now().UTC(),
+			},
+		},
+	}, nil
+}
+
+// resolvedDependencies returns the materials the artifacts were built
+// from: the git commit and, if any, the go.sum file.
+func resolvedDependencies(ctx *context.Context) ([]resourceDescriptor, error) {
+	var result []resourceDescriptor
+	if ctx.Git.FullCommit != "" {
+		uri := "git+" + ctx.Git.URL
+		if ctx.Git.CurrentTag != "" {
+			uri += "@refs/tags/" + ctx.Git.CurrentTag
+		}
+		result = append(result, resourceDescriptor{
+			URI:    uri,
+			Digest: map[string]string{"gitCommit": ctx.Git.FullCommit},
+		})
+	}
+
+	bts, err := os.ReadFile("go.sum")
+	if errors.Is(err, os.ErrNotExist) {
+		return result, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(bts)
+	return append(result, resourceDescriptor{
+		URI:    "go.sum",
+		Digest: map[string]string{"sha256": hex.EncodeToString(sum[:])},
+	}), nil
+}
@@ -0,0 +1,233 @@
+package provenance
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/testlib"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDescription(t *testing.T) {
+	require.NotEmpty(t, Pipe{}.String())
+}
+
+func TestSkip(t *testing.T) {
+	require.True(t, Pipe{}.Skip(context.New(config.Project{})))
+	require.False(t, Pipe{}.Skip(context.New(config.Project{
+		Provenance: config.Provenance{Enabled: true},
+	})))
+}
+
+func TestDefault(t *testing.T) {
+	ctx := context.New(config.Project{})
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.Equal(t, config.Provenance{
+		NameTemplate: "{{ .ProjectName }}_{{ .Version }}.intoto.json",
+		BuilderID:    "https://goreleaser.com",
+	}, ctx.Config.Provenance)
+}
+
+func sha(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+// addArtifacts adds an archive and a linux package to attest, and a checksums
+// file, which is not attested.
+func addArtifacts(t *testing.T, ctx *context.Context) {
+	t.Helper()
+	for name, typ := range map[string]artifact.Type{
+		"foo_linux_amd64.tar.gz": artifact.UploadableArchive,
+		"foo_1.0.0_amd64.deb":    artifact.LinuxPackage,
+		"checksums.txt":          artifact.Checksum,
+	} {
+		path := filepath.Join(ctx.Config.Dist, name)
+		require.NoError(t, os.WriteFile(path, []byte(name), 0o644))
+		ctx.Artifacts.Add(&artifact.Artifact{
+			Name:  name,
+			Path:  path,
+			Type:  typ,
+			Extra: map[string]interface{}{artifact.ExtraID: name},
+		})
+	}
+}
+
+func readStatement(t *testing.T, ctx *context.Context) statement {
+	t.Helper()
+	provenances := ctx.Artifacts.Filter(artifact.ByType(artifact.Provenance)).List()
+	require.Len(t, provenances, 1)
+	require.Equal(t, "foo_1.0.0.intoto.json", provenances[0].Name)
+	bts, err := os.ReadFile(provenances[0].Path)
+	require.NoError(t, err)
+	var st statement
+	require.NoError(t, json.Unmarshal(bts, &st))
+	return st
+}
+
+func TestRun(t *testing.T) {
+	testlib.Mktmp(t)
+	ctx := context.New(config.Project{
+		ProjectName: "foo",
+		Dist:        t.TempDir(),
+		Provenance: config.Provenance{
+			Enabled:   true,
+			BuilderID: "https://github.com/{{ .Env.REPO }}/actions",
+		},
+	})
+	ctx.Version = "1.0.0"
+	ctx.Date = time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)
+	ctx.Git = context.GitInfo{
+		CurrentTag: "v1.0.0",
+		FullCommit: "a1b2c3d4e5f6a1b2c3d4e5f6a1b2c3d4e5f6a1b2",
+		URL:        "https://github.com/goreleaser/foo.git",
+	}
+	addArtifacts(t, ctx)
+	require.NoError(t, Pipe{}.Default(ctx))
+	ctx.Env["REPO"] = "goreleaser/foo"
+	require.NoError(t, os.WriteFile("go.sum", []byte("gosum"), 0o644))
+	require.NoError(t, Pipe{}.Run(ctx))
+
+	st := readStatement(t, ctx)
+	require.Equal(t, "https://in-toto.io/Statement/v1", st.Type)
+	require.Equal(t, "https://slsa.dev/provenance/v1", st.PredicateType)
+	require.Equal(t, []subject{
+		{Name: "foo_1.0.0_amd64.deb", Digest: map[string]string{"sha256": sha("foo_1.0.0_amd64.deb")}},
+		{Name: "foo_linux_amd64.tar.gz", Digest: map[string]string{"sha256": sha("foo_linux_amd64.tar.gz")}},
+	}, st.Subject)
+	require.Equal(t, []resourceDescriptor{
+		{
+			URI:    "git+https://github.com/goreleaser/foo.git@refs/tags/v1.0.0",
+			Digest: map[string]string{"gitCommit": "a1b2c3d4e5f6a1b2c3d4e5f6a1b2c3d4e5f6a1b2"},
+		},
+		{
+			URI:    "go.sum",
+			Digest: map[string]string{"sha256": sha("gosum")},
+		},
+	}, st.Predicate.BuildDefinition.ResolvedDependencies)
+	require.Equal(t, "v1.0.0", st.Predicate.BuildDefinition.ExternalParameters["tag"])
+	require.Equal(t, "https://github.com/goreleaser/foo/actions", st.Predicate.RunDetails.Builder.ID)
+	require.Equal(t, ctx.Date, st.Predicate.RunDetails.Metadata.StartedOn)
+}
+
+func TestRunFilterByIDs(t *testing.T) {
+	testlib.Mktmp(t)
+	ctx := context.New(config.Project{
+		ProjectName: "foo",
+		Dist:        t.TempDir(),
+		Provenance: config.Provenance{
+			Enabled: true,
+			IDs:     []string{"foo_linux_amd64.tar.gz"},
+		},
+	})
+	ctx.Version = "1.0.0"
+	ctx.Date = time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)
+	ctx.Git = context.GitInfo{
+		CurrentTag: "v1.0.0",
+		FullCommit: "a1b2c3d4e5f6a1b2c3d4e5f6a1b2c3d4e5f6a1b2",
+		URL:        "https://github.com/goreleaser/foo.git",
+	}
+	addArtifacts(t, ctx)
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.NoError(t, Pipe{}.Run(ctx))
+
+	st := readStatement(t, ctx)
+	require.Len(t, st.Subject, 1)
+	require.Equal(t, "foo_linux_amd64.tar.gz", st.Subject[0].Name)
+	// no go.sum in the current directory.
+	require.Len(t, st.Predicate.BuildDefinition.ResolvedDependencies, 1)
+}
+
+func TestRunSource(t *testing.T) {
+	for name, skip := range map[string]bool{
+		"included": false,
+		"skipped":  true,
+	} {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.New(config.Project{
+				ProjectName: "foo",
+				Dist:        t.TempDir(),
+				Provenance:  config.Provenance{Enabled: true},
+			})
+			ctx.Version = "1.0.0"
+			ctx.Date = time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)
+			ctx.Git = context.GitInfo{
+				CurrentTag: "v1.0.0",
+				FullCommit: "a1b2c3d4e5f6a1b2c3d4e5f6a1b2c3d4e5f6a1b2",
+				URL:        "https://github.com/goreleaser/foo.git",
+			}
+			addArtifacts(t, ctx)
+			require.NoError(t, Pipe{}.Default(ctx))
+			ctx.Config.Source.SkipProvenance = skip
+			path := filepath.Join(ctx.Config.Dist, "foo-1.0.0.tar.gz")
+			require.NoError(t, os.WriteFile(path, []byte("source"), 0o644))
+			ctx.Artifacts.Add(&artifact.Artifact{
+				Name: "foo-1.0.0.tar.gz",
+				Path: path,
+				Type: artifact.UploadableSourceArchive,
+			})
+			require.NoError(t, Pipe{}.Run(ctx))
+
+			var names []string
+			for _, s := range readStatement(t, ctx).Subject {
+				names = append(names, s.Name)
+			}
+			if skip {
+				require.NotContains(t, names, "foo-1.0.0.tar.gz")
+				return
+			}
+			require.Contains(t, names, "foo-1.0.0.tar.gz")
+		})
+	}
+}
+
+func TestRunNoArtifacts(t *testing.T) {
+	ctx := context.New(config.Project{
+		ProjectName: "foo",
+		Dist:        t.TempDir(),
+		Provenance: config.Provenance{
+			Enabled: true,
+			IDs:     []string{"nope"},
+		},
+	})
+	ctx.Version = "1.0.0"
+	ctx.Date = time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)
+	ctx.Git = context.GitInfo{
+		CurrentTag: "v1.0.0",
+		FullCommit: "a1b2c3d4e5f6a1b2c3d4e5f6a1b2c3d4e5f6a1b2",
+		URL:        "https://github.com/goreleaser/foo.git",
+	}
+	addArtifacts(t, ctx)
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.NoError(t, Pipe{}.Run(ctx))
+	require.Empty(t, ctx.Artifacts.Filter(artifact.ByType(artifact.Provenance)).List())
+}
+
+func TestRunInvalidTemplate(t *testing.T) {
+	ctx := context.New(config.Project{
+		ProjectName: "foo",
+		Dist:        t.TempDir(),
+		Provenance: config.Provenance{
+			Enabled:      true,
+			NameTemplate: "{{ .Nope }",
+		},
+	})
+	ctx.Version = "1.0.0"
+	ctx.Date = time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)
+	ctx.Git = context.GitInfo{
+		CurrentTag: "v1.0.0",
+		FullCommit: "a1b2c3d4e5f6a1b2c3d4e5f6a1b2c3d4e5f6a1b2",
+		URL:        "https://github.com/goreleaser/foo.git",
+	}
+	addArtifacts(t, ctx)
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.Error(t, Pipe{}.Run(ctx))
+}
@@ -7,20 +7,32 @@ import (
 	"github.com/goreleaser/goreleaser/internal/middleware/errhandler"
 	"github.com/goreleaser/goreleaser/internal/middleware/logging"
 	"github.com/goreleaser/goreleaser/internal/middleware/skip"
+	"github.com/goreleaser/goreleaser/internal/pipe/apk"
+	"github.com/goreleaser/goreleaser/internal/pipe/apt"
 	"github.com/goreleaser/goreleaser/internal/pipe/artifactory"
+	"github.com/goreleaser/goreleaser/internal/pipe/attestation"
 	"github.com/goreleaser/goreleaser/internal/pipe/aur"
 	"github.com/goreleaser/goreleaser/internal/pipe/blob"
 	"github.com/goreleaser/goreleaser/internal/pipe/brew"
+	"github.com/goreleaser/goreleaser/internal/pipe/chocolatey"
 	"github.com/goreleaser/goreleaser/internal/pipe/custompublishers"
 	"github.com/goreleaser/goreleaser/internal/pipe/docker"
 	"github.com/goreleaser/goreleaser/internal/pipe/gofish"
+	"github.com/goreleaser/goreleaser/internal/pipe/ko"
 	"github.com/goreleaser/goreleaser/internal/pipe/krew"
+	"github.com/goreleaser/goreleaser/internal/pipe/macports"
 	"github.com/goreleaser/goreleaser/internal/pipe/milestone"
+	"github.com/goreleaser/goreleaser/internal/pipe/nix"
+	"github.com/goreleaser/goreleaser/internal/pipe/pacman"
 	"github.com/goreleaser/goreleaser/internal/pipe/release"
+	"github.com/goreleaser/goreleaser/internal/pipe/sbom"
 	"github.com/goreleaser/goreleaser/internal/pipe/scoop"
 	"github.com/goreleaser/goreleaser/internal/pipe/sign"
 	"github.com/goreleaser/goreleaser/internal/pipe/snapcraft"
+	"github.com/goreleaser/goreleaser/internal/pipe/termux"
 	"github.com/goreleaser/goreleaser/internal/pipe/upload"
+	"github.com/goreleaser/goreleaser/internal/pipe/winget"
+	"github.com/goreleaser/goreleaser/internal/pipe/yum"
 	"github.com/goreleaser/goreleaser/pkg/context"
 )
 
@@ -40,16 +52,28 @@ var publishers = []Publisher{
 	artifactory.Pipe{},
 	docker.Pipe{},
 	docker.ManifestPipe{},
+	ko.Pipe{},
 	sign.DockerPipe{},
+	sbom.Pipe{},
+	attestation.Pipe{},
 	snapcraft.Pipe{},
+	apt.Pipe{},
+	yum.Pipe{},
+	apk.Pipe{},
+	pacman.Pipe{},
 	// This should be one of the last steps
 	release.Pipe{},
 	// brew et al use the release URL, so, they should be last
 	brew.Pipe{},
 	aur.Pipe{},
 	gofish.Pipe{},
+	nix.Pipe{},
+	termux.Pipe{},
+	winget.Pipe{},
+	macports.Pipe{},
 	krew.Pipe{},
 	scoop.Pipe{},
+	chocolatey.Pipe{},
 	milestone.Pipe{},
 }
 
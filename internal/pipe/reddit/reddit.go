@@ -70,7 +70,7 @@ func (Pipe) Announce(ctx *context.Context) error {
 		return fmt.Errorf("announce: failed to announce to reddit: %w", err)
 	}
 
-	log.Infof("announce: The text post is available at: %s\n", post.URL)
+	log.FromContext(ctx).Infof("announce: The text post is available at: %s\n", post.URL)
 
 	return nil
 }
@@ -0,0 +1,116 @@
+package release
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/client"
+	"github.com/goreleaser/goreleaser/internal/pipe/provenance"
+	"github.com/goreleaser/goreleaser/internal/semerrgroup"
+	"github.com/goreleaser/goreleaser/internal/shell"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/context"
+)
+
+const defaultBuilderID = "https://goreleaser.com"
+
+// attestable filters the uploaded artifacts that get a provenance
+// attestation: signatures and attestations are about other artifacts, so
+// they are left out, and so are directories, which are uploaded as tarballs.
+var attestable = artifact.Not(artifact.Or(
+	artifact.ByType(artifact.Directory),
+	artifact.ByType(artifact.Signature),
+	artifact.ByType(artifact.Certificate),
+	artifact.ByType(artifact.Provenance),
+	artifact.ByType(artifact.Attestation),
+))
+
+// attest creates a SLSA provenance attestation of each of the given
+// artifacts, signed with cosign, and stores them in the repository.
+func attest(ctx *context.Context, cli client.Client, artifacts []*artifact.Artifact) error {
+	attester, ok := cli.(client.Attester)
+	if !ok {
+		return errors.New("release.attestations are only supported on GitHub")
+	}
+
+	builderID, err := attestationsBuilderID(ctx)
+	if err != nil {
+		return fmt.Errorf("release attestations: %w", err)
+	}
+	predicateType, predicate, err := provenance.Predicate(ctx, builderID)
+	if err != nil {
+		return fmt.Errorf("release attestations: %w", err)
+	}
+	bts, err := json.Marshal(predicate)
+	if err != nil {
+		return fmt.Errorf("release attestations: %w", err)
+	}
+	dir := filepath.Join(ctx.Config.Dist, "attestations")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("release attestations: %w", err)
+	}
+	predicatePath := filepath.Join(dir, "predicate.json")
+	if err := os.WriteFile(predicatePath, bts, 0o644); err != nil {
+		return fmt.Errorf("release attestations: %w", err)
+	}
+
+	repo := client.Repo{
+		Owner: ctx.Config.Release.GitHub.Owner,
+		Name:  ctx.Config.Release.GitHub.Name,
+	}
+	g := semerrgroup.New(ctx.Parallelism)
+	for _, a := range artifacts {
+		a := a
+		g.Go(func() error {
+			bundlePath := filepath.Join(dir, a.Name+".sigstore.json")
+			log.FromContext(ctx).WithField("artifact", a.Name).Info("attesting")
+			if err := shell.Run(ctx, "", attestCommand(predicateType, predicatePath, bundlePath, a.Path), ctx.Env.Strings()); err != nil {
+				return fmt.Errorf("failed to attest %s: %w", a.Name, err)
+			}
+			bundle, err := os.ReadFile(bundlePath)
+			if err != nil {
+				return fmt.Errorf("failed to attest %s: %w", a.Name, err)
+			}
+			if err := attester.CreateAttestation(ctx, repo, bundle); err != nil {
+				return fmt.Errorf("failed to store the attestation of %s: %w", a.Name, err)
+			}
+			return nil
+		})
+	}
+	return g.Wait()
+}
+
+// attestationsBuilderID returns the builder of the attestations: the
+// workflow running the release on GitHub Actions, or the provenance builder
+// otherwise.
+func attestationsBuilderID(ctx *context.Context) (string, error) {
+	if ref := ctx.Env["GITHUB_WORKFLOW_REF"]; ref != "" {
+		server := ctx.Env["GITHUB_SERVER_URL"]
+		if server == "" {
+			server = "https://github.com"
+		}
+		return server + "/" + ref, nil
+	}
+	if ctx.Config.Provenance.BuilderID == "" {
+		return defaultBuilderID, nil
+	}
+	return tmpl.New(ctx).Apply(ctx.Config.Provenance.BuilderID)
+}
+
+// attestCommand returns the cosign command that signs the attestation of the
+// given file, writing it as a Sigstore bundle.
+func attestCommand(predicateType, predicate, bundle, path string) []string {
+	return []string{
+		"cosign", "attest-blob", "--yes",
+		"--new-bundle-format",
+		"--type", predicateType,
+		"--predicate", predicate,
+		"--bundle", bundle,
+		path,
+	}
+}
@@ -0,0 +1,153 @@
+package release
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/client"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeCosign puts a cosign in the PATH which writes the arguments it got as
+// the bundle.
+func fakeCosign(tb testing.TB, exitCode int) {
+	tb.Helper()
+	dir := tb.TempDir()
+	script := `#!/bin/sh
+set -e
+while [ $# -gt 1 ]; do
+	if [ "$1" = "--bundle" ]; then
+		bundle="$2"
+	fi
+	args="$args\"$1\","
+	shift
+done
+printf '{"args":[%s"%s"]}' "$args" "$1" > "$bundle"
+exit ` + strconv.Itoa(exitCode) + `
+`
+	require.NoError(tb, os.WriteFile(filepath.Join(dir, "cosign"), []byte(script), 0o755))
+	tb.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+func attestationsContext(tb testing.TB) *context.Context {
+	tb.Helper()
+	dist := tb.TempDir()
+	ctx := context.New(config.Project{
+		Dist: dist,
+		Release: config.Release{
+			GitHub:       config.Repo{Owner: "test", Name: "test"},
+			Attestations: true,
+		},
+	})
+	ctx.Git = context.GitInfo{CurrentTag: "v1.0.0"}
+	ctx.Env = context.Env{}
+	for name, typ := range map[string]artifact.Type{
+		"bin.tar.gz":     artifact.UploadableArchive,
+		"bin.tar.gz.sig": artifact.Signature,
+		"checksums.txt":  artifact.Checksum,
+	} {
+		path := filepath.Join(dist, name)
+		require.NoError(tb, os.WriteFile(path, []byte(name), 0o644))
+		ctx.Artifacts.Add(&artifact.Artifact{Type: typ, Name: name, Path: path})
+	}
+	return ctx
+}
+
+func TestAttestations(t *testing.T) {
+	fakeCosign(t, 0)
+	ctx := attestationsContext(t)
+	cli := client.NewMock()
+	require.NoError(t, doPublish(ctx, cli))
+	require.Len(t, cli.UploadedFileNames, 3)
+	require.Len(t, cli.Attestations, 2)
+
+	var files []string
+	for _, bundle := range cli.Attestations {
+		var got struct {
+			Args []string `json:"args"`
+		}
+		require.NoError(t, json.Unmarshal(bundle, &got))
+		require.Equal(t, []string{
+			"attest-blob", "--yes", "--new-bundle-format",
+			"--type", "https://slsa.dev/provenance/v1",
+			"--predicate", filepath.Join(ctx.Config.Dist, "attestations", "predicate.json"),
+		}, got.Args[:7])
+		files = append(files, filepath.Base(got.Args[len(got.Args)-1]))
+	}
+	sort.Strings(files)
+	require.Equal(t, []string{"bin.tar.gz", "checksums.txt"}, files)
+
+	bts, err := os.ReadFile(filepath.Join(ctx.Config.Dist, "attestations", "predicate.json"))
+	require.NoError(t, err)
+	require.Contains(t, string(bts), `"id":"https://goreleaser.com"`)
+}
+
+func TestAttestationsDisabled(t *testing.T) {
+	ctx := attestationsContext(t)
+	ctx.Config.Release.Attestations = false
+	cli := client.NewMock()
+	require.NoError(t, doPublish(ctx, cli))
+	require.Empty(t, cli.Attestations)
+}
+
+func TestAttestationsCosignFails(t *testing.T) {
+	fakeCosign(t, 1)
+	ctx := attestationsContext(t)
+	cli := client.NewMock()
+	require.Error(t, doPublish(ctx, cli))
+	require.Empty(t, cli.Attestations)
+}
+
+func TestAttestationsUnsupportedClient(t *testing.T) {
+	ctx := attestationsContext(t)
+	cli := struct{ client.Client }{client.NewMock()}
+	require.EqualError(t, doPublish(ctx, cli), "release.attestations are only supported on GitHub")
+}
+
+func TestAttestationsBuilderID(t *testing.T) {
+	t.Run("github actions", func(t *testing.T) {
+		ctx := context.New(config.Project{})
+		ctx.Env = context.Env{
+			"GITHUB_SERVER_URL":   "https://github.example.com",
+			"GITHUB_WORKFLOW_REF": "foo/bar/.github/workflows/release.yml@refs/tags/v1.0.0",
+		}
+		id, err := attestationsBuilderID(ctx)
+		require.NoError(t, err)
+		require.Equal(t, "https://github.example.com/foo/bar/.github/workflows/release.yml@refs/tags/v1.0.0", id)
+	})
+
+	t.Run("provenance builder", func(t *testing.T) {
+		ctx := context.New(config.Project{
+			ProjectName: "foo",
+			Provenance:  config.Provenance{BuilderID: "https://example.com/{{ .ProjectName }}"},
+		})
+		ctx.Env = context.Env{}
+		id, err := attestationsBuilderID(ctx)
+		require.NoError(t, err)
+		require.Equal(t, "https://example.com/foo", id)
+	})
+
+	t.Run("default", func(t *testing.T) {
+		ctx := context.New(config.Project{})
+		ctx.Env = context.Env{}
+		id, err := attestationsBuilderID(ctx)
+		require.NoError(t, err)
+		require.Equal(t, defaultBuilderID, id)
+	})
+
+	t.Run("invalid template", func(t *testing.T) {
+		ctx := context.New(config.Project{
+			Provenance: config.Provenance{BuilderID: "{{ .Nope }"},
+		})
+		ctx.Env = context.Env{}
+		_, err := attestationsBuilderID(ctx)
+		require.Error(t, err)
+	})
+}
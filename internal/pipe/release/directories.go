@@ -0,0 +1,66 @@
+package release
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/pkg/archive"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+)
+
+// tarDirectories creates a tar.gz in the dist folder of each of the given
+// directory artifacts, as releases can only hold files, and returns
+// artifacts to upload them.
+func tarDirectories(ctx *context.Context, dirs []*artifact.Artifact) ([]*artifact.Artifact, error) {
+	var result []*artifact.Artifact
+	for _, dir := range dirs {
+		name := dir.Name + ".tar.gz"
+		path := filepath.Join(ctx.Config.Dist, name)
+		log.FromContext(ctx).WithField("directory", dir.Path).WithField("archive", path).Info("archiving directory")
+		if err := tarDirectory(dir.Path, dir.Name, path); err != nil {
+			return nil, fmt.Errorf("failed to archive directory %s: %w", dir.Path, err)
+		}
+		result = append(result, &artifact.Artifact{
+			Type:  artifact.UploadableFile,
+			Name:  name,
+			Path:  path,
+			Extra: dir.Extra,
+		})
+	}
+	return result, nil
+}
+
+// tarDirectory writes the contents of dir to a tar.gz at the given path,
+// inside a folder named prefix.
+func tarDirectory(dir, prefix, path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	archive := archive.NewWithOptions(file, archive.Options{Reproducible: true})
+	if err := filepath.Walk(dir, func(src string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, src)
+		if err != nil {
+			return err
+		}
+		return archive.Add(config.File{
+			Source:      src,
+			Destination: filepath.ToSlash(filepath.Join(prefix, rel)),
+		})
+	}); err != nil {
+		archive.Close()
+		return err
+	}
+	if err := archive.Close(); err != nil {
+		return err
+	}
+	return file.Close()
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/apex/log"
@@ -12,6 +13,7 @@ import (
 	"github.com/goreleaser/goreleaser/internal/extrafiles"
 	"github.com/goreleaser/goreleaser/internal/git"
 	"github.com/goreleaser/goreleaser/internal/semerrgroup"
+	"github.com/goreleaser/goreleaser/pkg/config"
 	"github.com/goreleaser/goreleaser/pkg/context"
 )
 
@@ -37,10 +39,20 @@ func (Pipe) Default(ctx *context.Context) error {
 	if ctx.Config.Release.Gitea.String() != "" {
 		numOfReleases++
 	}
+	if ctx.Config.Release.Bitbucket.String() != "" {
+		numOfReleases++
+	}
+	if ctx.Config.Release.AzureDevOps.String() != "" {
+		numOfReleases++
+	}
 	if numOfReleases > 1 {
 		return ErrMultipleReleases
 	}
 
+	if _, err := artifact.ByConfig(ctx.Config.Release.Include, ctx.Config.Release.Exclude, ctx.Config.Platforms); err != nil {
+		return fmt.Errorf("release: %w", err)
+	}
+
 	if ctx.Config.Release.NameTemplate == "" {
 		ctx.Config.Release.NameTemplate = "{{.Tag}}"
 	}
@@ -76,6 +88,32 @@ func (Pipe) Default(ctx *context.Context) error {
 			ctx.Config.Release.Gitea.Name,
 			ctx.Git.CurrentTag,
 		)
+	case context.TokenTypeBitbucket:
+		if ctx.Config.Release.Bitbucket.Name == "" {
+			repo, err := git.ExtractRepoFromConfig()
+			if err != nil {
+				return err
+			}
+			ctx.Config.Release.Bitbucket = repo
+		}
+		// bitbucket has no releases, the artifacts are in the downloads.
+		ctx.ReleaseURL = fmt.Sprintf(
+			"%s/%s/%s/downloads/",
+			ctx.Config.BitbucketURLs.Download,
+			ctx.Config.Release.Bitbucket.Owner,
+			ctx.Config.Release.Bitbucket.Name,
+		)
+	case context.TokenTypeAzureDevOps:
+		if ctx.Config.Release.AzureDevOps.Name == "" {
+			repo, err := git.ExtractRepoFromConfig()
+			if err != nil {
+				return err
+			}
+			ctx.Config.Release.AzureDevOps = azureDevOpsRepo(repo)
+		}
+		if err := setAzureArtifactsDefaults(ctx); err != nil {
+			return err
+		}
 	default:
 		// We keep github as default for now
 		if ctx.Config.Release.GitHub.Name == "" {
@@ -100,12 +138,59 @@ func (Pipe) Default(ctx *context.Context) error {
 		if ctx.Semver.Prerelease != "" {
 			ctx.PreRelease = true
 		}
-		log.Debugf("pre-release was detected for tag %s: %v", ctx.Git.CurrentTag, ctx.PreRelease)
+		log.FromContext(ctx).Debugf("pre-release was detected for tag %s: %v", ctx.Git.CurrentTag, ctx.PreRelease)
 	case "true":
 		ctx.PreRelease = true
 	}
-	log.Debugf("pre-release for tag %s set to %v", ctx.Git.CurrentTag, ctx.PreRelease)
+	log.FromContext(ctx).Debugf("pre-release for tag %s set to %v", ctx.Git.CurrentTag, ctx.PreRelease)
+
+	return nil
+}
+
+// azureDevOpsRepo returns the organization/project owner and name of the
+// repository extracted from an Azure Repos remote URL, e.g.
+// https://dev.azure.com/org/project/_git/repo or
+// git@ssh.dev.azure.com:v3/org/project/repo.
+func azureDevOpsRepo(repo config.Repo) config.Repo {
+	repo.Owner = strings.TrimSuffix(strings.TrimPrefix(repo.Owner, "v3/"), "/_git")
+	return repo
+}
+
+// setAzureArtifactsDefaults sets the defaults of the feed the artifacts are
+// published to, which has no releases, so the release URL is the page of the
+// universal package version.
+func setAzureArtifactsDefaults(ctx *context.Context) error {
+	feed := &ctx.Config.Release.AzureArtifacts
+	if feed.Feed == "" {
+		return errors.New("release: azure_artifacts.feed is required when releasing to Azure DevOps")
+	}
+	if feed.Scope == "" {
+		feed.Scope = "project"
+	}
+	if feed.Scope != "project" && feed.Scope != "organization" {
+		return fmt.Errorf("release: invalid azure_artifacts.scope %q, valid options are project and organization", feed.Scope)
+	}
+	if feed.Package == "" {
+		feed.Package = ctx.Config.ProjectName
+		if feed.Package == "" {
+			feed.Package = ctx.Config.Release.AzureDevOps.Name
+		}
+		// universal package names must be lowercase.
+		feed.Package = strings.ToLower(feed.Package)
+	}
 
+	owner := ctx.Config.Release.AzureDevOps.Owner
+	if feed.Scope == "organization" {
+		owner = strings.Split(owner, "/")[0]
+	}
+	ctx.ReleaseURL = fmt.Sprintf(
+		"%s/%s/_artifacts/feed/%s/UPack/%s/%s",
+		strings.TrimSuffix(ctx.Config.AzureDevOpsURLs.API, "/"),
+		owner,
+		feed.Feed,
+		feed.Package,
+		ctx.Version,
+	)
 	return nil
 }
 
@@ -119,7 +204,7 @@ func (Pipe) Publish(ctx *context.Context) error {
 }
 
 func doPublish(ctx *context.Context, client client.Client) error {
-	log.WithField("tag", ctx.Git.CurrentTag).
+	log.FromContext(ctx).WithField("tag", ctx.Git.CurrentTag).
 		WithField("repo", ctx.Config.Release.GitHub.String()).
 		Info("creating or updating release")
 	body, err := describeBody(ctx)
@@ -149,29 +234,76 @@ func doPublish(ctx *context.Context, client client.Client) error {
 
 	filters := artifact.Or(
 		artifact.ByType(artifact.UploadableArchive),
+		artifact.ByType(artifact.UploadableArchivePart),
 		artifact.ByType(artifact.UploadableBinary),
 		artifact.ByType(artifact.UploadableSourceArchive),
 		artifact.ByType(artifact.Checksum),
 		artifact.ByType(artifact.Signature),
 		artifact.ByType(artifact.Certificate),
 		artifact.ByType(artifact.LinuxPackage),
+		artifact.ByType(artifact.AppImage),
+		artifact.ByType(artifact.MSI),
+		artifact.ByType(artifact.DMG),
+		artifact.ByType(artifact.BrewBottle),
 		artifact.ByType(artifact.SBOM),
+		artifact.ByType(artifact.Provenance),
+		artifact.ByType(artifact.Attestation),
+		artifact.ByType(artifact.UploadableFile),
+		artifact.ByType(artifact.Directory),
+		artifact.And(
+			artifact.ByType(artifact.DockerImageArchive),
+			artifact.MarkedForUpload,
+		),
 	)
 
 	if len(ctx.Config.Release.IDs) > 0 {
-		filters = artifact.And(filters, artifact.ByIDs(ctx.Config.Release.IDs...))
+		// extra files have no id, they are always part of the release.
+		filters = artifact.And(filters, artifact.Or(
+			artifact.ByIDs(ctx.Config.Release.IDs...),
+			artifact.ByType(artifact.UploadableFile),
+		))
 	}
 
-	filters = artifact.Or(filters, artifact.ByType(artifact.UploadableFile))
+	selected, err := artifact.ByConfig(ctx.Config.Release.Include, ctx.Config.Release.Exclude, ctx.Config.Platforms)
+	if err != nil {
+		return fmt.Errorf("release: %w", err)
+	}
+	filters = artifact.And(filters, selected)
+
+	isDir := artifact.ByType(artifact.Directory)
+	dirs, err := tarDirectories(ctx, ctx.Artifacts.Filter(artifact.And(filters, isDir)).List())
+	if err != nil {
+		return err
+	}
 
 	g := semerrgroup.New(ctx.Parallelism)
-	for _, artifact := range ctx.Artifacts.Filter(filters).List() {
+	for _, artifact := range append(ctx.Artifacts.Filter(artifact.And(filters, artifact.Not(isDir))).List(), dirs...) {
 		artifact := artifact
 		g.Go(func() error {
 			return upload(ctx, client, releaseID, artifact)
 		})
 	}
-	return g.Wait()
+	if err := g.Wait(); err != nil {
+		return err
+	}
+	if err := finalize(ctx, client, releaseID); err != nil {
+		return err
+	}
+
+	if !ctx.Config.Release.Attestations {
+		return nil
+	}
+	return attest(ctx, client, ctx.Artifacts.Filter(artifact.And(filters, attestable)).List())
+}
+
+// finalize lets the clients that publish the assets all at once do so, once
+// they were all uploaded.
+func finalize(ctx *context.Context, cli client.Client, releaseID string) error {
+	finalizer, ok := cli.(client.ReleaseFinalizer)
+	if !ok {
+		return nil
+	}
+	return finalizer.FinalizeRelease(ctx, releaseID)
 }
 
 func upload(ctx *context.Context, cli client.Client, releaseID string, artifact *artifact.Artifact) error {
@@ -183,9 +315,9 @@ func upload(ctx *context.Context, cli client.Client, releaseID string, artifact
 			return err
 		}
 		defer file.Close()
-		log.WithField("file", file.Name()).WithField("name", artifact.Name).Info("uploading to release")
+		log.FromContext(ctx).WithField("file", file.Name()).WithField("name", artifact.Name).Info("uploading to release")
 		if err := cli.Upload(ctx, releaseID, artifact, file); err != nil {
-			log.WithField("try", try).
+			log.FromContext(ctx).WithField("try", try).
 				WithField("artifact", artifact.Name).
 				WithError(err).
 				Warnf("failed to upload artifact, will retry")
@@ -1,6 +1,9 @@
 package release
 
 import (
+	"archive/tar"
+	"compress/gzip"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -200,6 +203,216 @@ func TestRunPipeWithIDsThenFilters(t *testing.T) {
 	require.NotContains(t, client.UploadedFileNames, "filtered.tar.gz")
 }
 
+func TestRunPipeDockerImageArchives(t *testing.T) {
+	folder := t.TempDir()
+	ctx := context.New(config.Project{
+		Dist: folder,
+		Release: config.Release{
+			GitHub: config.Repo{
+				Owner: "test",
+				Name:  "test",
+			},
+		},
+	})
+	ctx.Git = context.GitInfo{CurrentTag: "v1.0.0"}
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Type: artifact.DockerImageArchive,
+		Name: "foo.oci.tar",
+		Path: createTmpFile(t, folder, "foo.oci.tar"),
+		Extra: map[string]interface{}{
+			artifact.ExtraUpload: true,
+		},
+	})
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Type: artifact.DockerImageArchive,
+		Name: "bar.docker.tar",
+		Path: createTmpFile(t, folder, "bar.docker.tar"),
+		Extra: map[string]interface{}{
+			artifact.ExtraUpload: false,
+		},
+	})
+	client := &client.Mock{}
+	require.NoError(t, doPublish(ctx, client))
+	require.Equal(t, []string{"foo.oci.tar"}, client.UploadedFileNames)
+}
+
+func TestRunPipeDirectories(t *testing.T) {
+	folder := t.TempDir()
+	site := filepath.Join(folder, "site")
+	require.NoError(t, os.MkdirAll(filepath.Join(site, "css"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(site, "index.html"), []byte("<html></html>"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(site, "css", "style.css"), []byte("body {}"), 0o644))
+
+	ctx := context.New(config.Project{
+		Dist: folder,
+		Release: config.Release{
+			GitHub: config.Repo{
+				Owner: "test",
+				Name:  "test",
+			},
+			IDs: []string{"site"},
+		},
+	})
+	ctx.Git = context.GitInfo{CurrentTag: "v1.0.0"}
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Type: artifact.Directory,
+		Name: "site",
+		Path: site,
+		Extra: map[string]interface{}{
+			artifact.ExtraID: "site",
+		},
+	})
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Type: artifact.Directory,
+		Name: "filtered",
+		Path: site,
+		Extra: map[string]interface{}{
+			artifact.ExtraID: "filtered",
+		},
+	})
+	client := &client.Mock{}
+	require.NoError(t, doPublish(ctx, client))
+	require.Equal(t, []string{"site.tar.gz"}, client.UploadedFileNames)
+	require.ElementsMatch(t, []string{
+		"site/",
+		"site/css/",
+		"site/css/style.css",
+		"site/index.html",
+	}, tarGzFiles(t, filepath.Join(folder, "site.tar.gz")))
+	require.NoFileExists(t, filepath.Join(folder, "filtered.tar.gz"))
+}
+
+func tarGzFiles(tb testing.TB, path string) []string {
+	tb.Helper()
+	f, err := os.Open(path)
+	require.NoError(tb, err)
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	require.NoError(tb, err)
+	var files []string
+	r := tar.NewReader(gz)
+	for {
+		header, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(tb, err)
+		name := header.Name
+		if header.Typeflag == tar.TypeDir {
+			name += "/"
+		}
+		files = append(files, name)
+	}
+	return files
+}
+
+func TestRunPipeWithIncludeExcludeFilters(t *testing.T) {
+	folder := t.TempDir()
+	for _, name := range []string{"linux.tar.gz", "windows.zip", "bin.deb", "checksums.txt", "notes.txt"} {
+		f, err := os.Create(filepath.Join(folder, name))
+		require.NoError(t, err)
+		require.NoError(t, f.Close())
+	}
+
+	ctx := context.New(config.Project{
+		Dist: folder,
+		Release: config.Release{
+			GitHub: config.Repo{
+				Owner: "test",
+				Name:  "test",
+			},
+			Include: config.ArtifactFilters{
+				Types: []string{"archive", "checksum"},
+			},
+			Exclude: config.ArtifactFilters{
+				Goos: []string{"windows"},
+			},
+		},
+	})
+	ctx.Git = context.GitInfo{CurrentTag: "v1.0.0"}
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Type: artifact.UploadableArchive,
+		Name: "linux.tar.gz",
+		Path: filepath.Join(folder, "linux.tar.gz"),
+		Goos: "linux",
+	})
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Type: artifact.UploadableArchive,
+		Name: "windows.zip",
+		Path: filepath.Join(folder, "windows.zip"),
+		Goos: "windows",
+	})
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Type: artifact.LinuxPackage,
+		Name: "bin.deb",
+		Path: filepath.Join(folder, "bin.deb"),
+		Goos: "linux",
+	})
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Type: artifact.Checksum,
+		Name: "checksums.txt",
+		Path: filepath.Join(folder, "checksums.txt"),
+	})
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Type: artifact.UploadableFile,
+		Name: "notes.txt",
+		Path: filepath.Join(folder, "notes.txt"),
+	})
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Type: artifact.Directory,
+		Name: "site",
+		Path: folder,
+	})
+	client := &client.Mock{}
+	require.NoError(t, doPublish(ctx, client))
+	require.ElementsMatch(t, []string{"linux.tar.gz", "checksums.txt"}, client.UploadedFileNames)
+}
+
+func TestRunPipeExcludeTypes(t *testing.T) {
+	folder := t.TempDir()
+	for _, name := range []string{"linux.tar.gz", "notes.txt", "multiple.intoto.jsonl"} {
+		require.NoError(t, os.WriteFile(filepath.Join(folder, name), nil, 0o644))
+	}
+
+	ctx := context.New(config.Project{
+		Dist: folder,
+		Release: config.Release{
+			GitHub: config.Repo{
+				Owner: "test",
+				Name:  "test",
+			},
+			Exclude: config.ArtifactFilters{
+				Types: []string{"file", "directory", "provenance"},
+			},
+		},
+	})
+	ctx.Git = context.GitInfo{CurrentTag: "v1.0.0"}
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Type: artifact.UploadableArchive,
+		Name: "linux.tar.gz",
+		Path: filepath.Join(folder, "linux.tar.gz"),
+	})
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Type: artifact.UploadableFile,
+		Name: "notes.txt",
+		Path: filepath.Join(folder, "notes.txt"),
+	})
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Type: artifact.Provenance,
+		Name: "multiple.intoto.jsonl",
+		Path: filepath.Join(folder, "multiple.intoto.jsonl"),
+	})
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Type: artifact.Directory,
+		Name: "site",
+		Path: folder,
+	})
+	client := &client.Mock{}
+	require.NoError(t, doPublish(ctx, client))
+	require.Equal(t, []string{"linux.tar.gz"}, client.UploadedFileNames)
+	require.NoFileExists(t, filepath.Join(folder, "site.tar.gz"))
+}
+
 func TestRunPipeReleaseCreationFailed(t *testing.T) {
 	config := config.Project{
 		Release: config.Release{
@@ -266,6 +479,43 @@ func TestRunPipeUploadFailure(t *testing.T) {
 	require.EqualError(t, doPublish(ctx, client), "failed to upload bin.tar.gz after 1 tries: upload failed")
 	require.True(t, client.CreatedRelease)
 	require.False(t, client.UploadedFile)
+	require.False(t, client.FinalizedRelease)
+}
+
+func TestRunPipeFinalize(t *testing.T) {
+	folder := t.TempDir()
+	tarfile, err := os.Create(filepath.Join(folder, "bin.tar.gz"))
+	require.NoError(t, err)
+	require.NoError(t, tarfile.Close())
+	ctx := context.New(config.Project{
+		Release: config.Release{
+			GitHub: config.Repo{
+				Owner: "test",
+				Name:  "test",
+			},
+		},
+	})
+	ctx.Git = context.GitInfo{CurrentTag: "v1.0.0"}
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Type: artifact.UploadableArchive,
+		Name: "bin.tar.gz",
+		Path: tarfile.Name(),
+	})
+
+	t.Run("finalized", func(t *testing.T) {
+		client := &client.Mock{}
+		require.NoError(t, doPublish(ctx, client))
+		require.True(t, client.UploadedFile)
+		require.True(t, client.FinalizedRelease)
+	})
+
+	t.Run("fails", func(t *testing.T) {
+		client := &client.Mock{
+			FailToFinalize: true,
+		}
+		require.EqualError(t, doPublish(ctx, client), "finalize failed")
+		require.True(t, client.UploadedFile)
+	})
 }
 
 func TestRunPipeExtraFileNotFound(t *testing.T) {
@@ -384,6 +634,96 @@ func TestDefaultWithGitea(t *testing.T) {
 	require.Equal(t, "https://git.honk.com/giteaowner/gitearepo/releases/tag/v1.0.0", ctx.ReleaseURL)
 }
 
+func TestDefaultWithBitbucket(t *testing.T) {
+	testlib.Mktmp(t)
+	testlib.GitInit(t)
+	testlib.GitRemoteAdd(t, "git@bitbucket.org:bitbucketowner/bitbucketrepo.git")
+
+	ctx := context.New(config.Project{})
+	ctx.TokenType = context.TokenTypeBitbucket
+	ctx.Config.BitbucketURLs.Download = "https://bitbucket.org"
+	ctx.Git.CurrentTag = "v1.0.0"
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.Equal(t, "bitbucketrepo", ctx.Config.Release.Bitbucket.Name)
+	require.Equal(t, "bitbucketowner", ctx.Config.Release.Bitbucket.Owner)
+	require.Equal(t, "https://bitbucket.org/bitbucketowner/bitbucketrepo/downloads/", ctx.ReleaseURL)
+}
+
+func TestDefaultWithAzureDevOps(t *testing.T) {
+	for remote, expected := range map[string]config.Repo{
+		"https://dev.azure.com/org/project/_git/repo":          {Owner: "org/project", Name: "repo"},
+		"https://org@dev.azure.com/org/project/_git/repo":      {Owner: "org/project", Name: "repo"},
+		"git@ssh.dev.azure.com:v3/org/project/repo":            {Owner: "org/project", Name: "repo"},
+		"git@ssh.dev.azure.com:v3/org/project%20name/repo.git": {Owner: "org/project name", Name: "repo"},
+	} {
+		t.Run(remote, func(t *testing.T) {
+			testlib.Mktmp(t)
+			testlib.GitInit(t)
+			testlib.GitRemoteAdd(t, remote)
+
+			ctx := context.New(config.Project{
+				ProjectName: "Foo",
+				Release: config.Release{
+					AzureArtifacts: config.AzureArtifacts{Feed: "releases"},
+				},
+			})
+			ctx.TokenType = context.TokenTypeAzureDevOps
+			ctx.Config.AzureDevOpsURLs.API = "https://dev.azure.com"
+			ctx.Git.CurrentTag = "v1.0.0"
+			ctx.Version = "1.0.0"
+			require.NoError(t, Pipe{}.Default(ctx))
+			require.Equal(t, expected, ctx.Config.Release.AzureDevOps)
+			require.Equal(t, config.AzureArtifacts{
+				Feed:    "releases",
+				Scope:   "project",
+				Package: "foo",
+			}, ctx.Config.Release.AzureArtifacts)
+			require.Equal(t, "https://dev.azure.com/"+expected.Owner+"/_artifacts/feed/releases/UPack/foo/1.0.0", ctx.ReleaseURL)
+		})
+	}
+}
+
+func TestDefaultWithAzureDevOpsOrganizationFeed(t *testing.T) {
+	ctx := context.New(config.Project{
+		Release: config.Release{
+			AzureDevOps:    config.Repo{Owner: "org/project", Name: "Repo"},
+			AzureArtifacts: config.AzureArtifacts{Feed: "releases", Scope: "organization"},
+		},
+	})
+	ctx.TokenType = context.TokenTypeAzureDevOps
+	ctx.Config.AzureDevOpsURLs.API = "https://dev.azure.com/"
+	ctx.Version = "1.0.0"
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.Equal(t, "repo", ctx.Config.Release.AzureArtifacts.Package)
+	require.Equal(t, "https://dev.azure.com/org/_artifacts/feed/releases/UPack/repo/1.0.0", ctx.ReleaseURL)
+}
+
+func TestDefaultWithAzureDevOpsErrors(t *testing.T) {
+	for name, tt := range map[string]struct {
+		feed config.AzureArtifacts
+		err  string
+	}{
+		"no feed": {
+			err: "release: azure_artifacts.feed is required when releasing to Azure DevOps",
+		},
+		"invalid scope": {
+			feed: config.AzureArtifacts{Feed: "releases", Scope: "collection"},
+			err:  `release: invalid azure_artifacts.scope "collection", valid options are project and organization`,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.New(config.Project{
+				Release: config.Release{
+					AzureDevOps:    config.Repo{Owner: "org/project", Name: "repo"},
+					AzureArtifacts: tt.feed,
+				},
+			})
+			ctx.TokenType = context.TokenTypeAzureDevOps
+			require.EqualError(t, Pipe{}.Default(ctx), tt.err)
+		})
+	}
+}
+
 func TestDefaultPreRelease(t *testing.T) {
 	testlib.Mktmp(t)
 	testlib.GitInit(t)
@@ -576,6 +916,22 @@ func TestDefaultMultipleReleasesDefined(t *testing.T) {
 	require.EqualError(t, Pipe{}.Default(ctx), ErrMultipleReleases.Error())
 }
 
+func TestDefaultInvalidFilters(t *testing.T) {
+	testlib.Mktmp(t)
+	testlib.GitInit(t)
+	testlib.GitRemoteAdd(t, "git@github.com:goreleaser/goreleaser.git")
+
+	ctx := context.New(config.Project{
+		Release: config.Release{
+			Exclude: config.ArtifactFilters{
+				Types: []string{"nope"},
+			},
+		},
+	})
+	ctx.TokenType = context.TokenTypeGitHub
+	require.EqualError(t, Pipe{}.Default(ctx), `release: exclude: invalid artifact type: "nope"`)
+}
+
 func TestSkip(t *testing.T) {
 	t.Run("skip", func(t *testing.T) {
 		ctx := context.New(config.Project{
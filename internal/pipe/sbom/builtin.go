@@ -0,0 +1,380 @@
+package sbom
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+)
+
+// Formats of the builtin SBOM generator.
+const (
+	formatCycloneDX = "cyclonedx-json"
+	formatSPDX      = "spdx-json"
+)
+
+// goModPath is the go.mod the builtin SBOMs are generated from.
+var goModPath = "go.mod"
+
+// module is a go module in the module graph.
+type module struct {
+	Path     string
+	Version  string
+	Indirect bool
+}
+
+func (m module) purl() string {
+	if m.Version == "" {
+		return "pkg:golang/" + m.Path
+	}
+	return "pkg:golang/" + m.Path + "@" + m.Version
+}
+
+// generateArtifact generates the SBOM of the given artifact from the go
+// module graph, without running any external command.
+func generateArtifact(ctx *context.Context, cfg config.SBOM, a *artifact.Artifact) ([]*artifact.Artifact, error) {
+	env := ctx.Env.Copy()
+	env["artifact"] = a.Name
+	env["artifactID"] = a.ID()
+	name, err := tmpl.New(ctx).WithEnv(env).WithArtifact(a, nil).Apply(expand(cfg.Documents[0], env))
+	if err != nil {
+		return nil, fmt.Errorf("cataloging artifacts failed: %s: invalid template: %w", cfg.Documents[0], err)
+	}
+
+	main, modules, err := readGoMod(goModPath)
+	if err != nil {
+		return nil, fmt.Errorf("cataloging artifacts failed: %w", err)
+	}
+	main.Version = ctx.Git.CurrentTag
+
+	digest, err := a.Checksum("sha256")
+	if err != nil {
+		return nil, fmt.Errorf("cataloging artifacts failed: %w", err)
+	}
+
+	var doc interface{}
+	switch cfg.Format {
+	case formatSPDX:
+		doc = spdxDocument(ctx, a, digest, main, modules)
+	default:
+		doc = cycloneDXDocument(ctx, a, digest, main, modules)
+	}
+	bts, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("cataloging artifacts failed: %w", err)
+	}
+
+	path := filepath.Join(ctx.Config.Dist, name)
+	log.FromContext(ctx).WithField("artifact", a.Path).WithField("sbom", name).Info("generating")
+	if err := os.WriteFile(path, bts, 0o644); err != nil {
+		return nil, fmt.Errorf("cataloging artifacts failed: %w", err)
+	}
+	return []*artifact.Artifact{{
+		Type: artifact.SBOM,
+		Name: name,
+		Path: path,
+		Extra: map[string]interface{}{
+			artifact.ExtraID: cfg.ID,
+		},
+	}}, nil
+}
+
+// readGoMod returns the main module and the required modules of the given
+// go.mod file, with the replace directives applied.
+// Since go 1.17, go.mod lists every module needed to build the main module.
+func readGoMod(path string) (module, []module, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return module{}, nil, fmt.Errorf("builtin SBOMs need a go.mod: %w", err)
+	}
+	defer f.Close()
+
+	var main module
+	var modules []module
+	replaces := map[string]module{}
+	var block string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		comment := ""
+		if i := strings.Index(line, "//"); i >= 0 {
+			line, comment = strings.TrimSpace(line[:i]), line[i:]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if block == "" {
+			if len(fields) == 2 && fields[1] == "(" {
+				block = fields[0]
+				continue
+			}
+		} else if fields[0] == ")" {
+			block = ""
+			continue
+		}
+
+		directive := block
+		if directive == "" {
+			directive, fields = fields[0], fields[1:]
+		}
+		switch directive {
+		case "module":
+			if len(fields) > 0 {
+				main.Path = unquote(fields[0])
+			}
+		case "require":
+			if len(fields) == 2 {
+				modules = append(modules, module{
+					Path:     unquote(fields[0]),
+					Version:  fields[1],
+					Indirect: strings.Contains(comment, "indirect"),
+				})
+			}
+		case "replace":
+			// old [version] => new [version], local replacements have no
+			// version and are kept as they are.
+			arrow := indexOf(fields, "=>")
+			if arrow < 0 || len(fields)-arrow != 3 {
+				continue
+			}
+			replaces[unquote(fields[0])] = module{Path: unquote(fields[arrow+1]), Version: fields[arrow+2]}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return module{}, nil, err
+	}
+	if main.Path == "" {
+		return module{}, nil, fmt.Errorf("%s has no module directive", path)
+	}
+
+	for i, m := range modules {
+		if r, ok := replaces[m.Path]; ok {
+			r.Indirect = m.Indirect
+			modules[i] = r
+		}
+	}
+	return main, modules, nil
+}
+
+func unquote(s string) string {
+	if u, err := strconv.Unquote(s); err == nil {
+		return u
+	}
+	return s
+}
+
+func indexOf(ss []string, s string) int {
+	for i, e := range ss {
+		if e == s {
+			return i
+		}
+	}
+	return -1
+}
+
+// uuid returns a version 5 like UUID derived from the given strings, so the
+// same artifact always gets the same document identifier.
+func uuid(parts ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\n")))
+	b := sum[:16]
+	b[6] = (b[6] & 0x0f) | 0x50
+	b[8] = (b[8] & 0x3f) | 0x80
+	h := hex.EncodeToString(b)
+	return h[0:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:32]
+}
+
+func timestamp(ctx *context.Context) string {
+	return ctx.Date.UTC().Format(time.RFC3339)
+}
+
+type cycloneDXBOM struct {
+	BOMFormat    string                `json:"bomFormat"`
+	SpecVersion  string                `json:"specVersion"`
+	SerialNumber string                `json:"serialNumber"`
+	Version      int                   `json:"version"`
+	Metadata     cycloneDXMetadata     `json:"metadata"`
+	Components   []cycloneDXComponent  `json:"components"`
+	Dependencies []cycloneDXDependency `json:"dependencies"`
+}
+
+type cycloneDXMetadata struct {
+	Timestamp string             `json:"timestamp"`
+	Tools     []cycloneDXTool    `json:"tools"`
+	Component cycloneDXComponent `json:"component"`
+}
+
+type cycloneDXTool struct {
+	Vendor string `json:"vendor"`
+	Name   string `json:"name"`
+}
+
+type cycloneDXComponent struct {
+	BOMRef  string          `json:"bom-ref"`
+	Type    string          `json:"type"`
+	Name    string          `json:"name"`
+	Version string          `json:"version,omitempty"`
+	Scope   string          `json:"scope,omitempty"`
+	Hashes  []cycloneDXHash `json:"hashes,omitempty"`
+	PURL    string          `json:"purl,omitempty"`
+}
+
+type cycloneDXHash struct {
+	Alg     string `json:"alg"`
+	Content string `json:"content"`
+}
+
+type cycloneDXDependency struct {
+	Ref       string   `json:"ref"`
+	DependsOn []string `json:"dependsOn,omitempty"`
+}
+
+func cycloneDXDocument(ctx *context.Context, a *artifact.Artifact, digest string, main module, modules []module) cycloneDXBOM {
+	typ := "file"
+	if a.Type == artifact.Binary || a.Type == artifact.UploadableBinary {
+		typ = "application"
+	}
+	bom := cycloneDXBOM{
+		BOMFormat:    "CycloneDX",
+		SpecVersion:  "1.4",
+		SerialNumber: "urn:uuid:" + uuid(a.Name, digest, formatCycloneDX),
+		Version:      1,
+		Metadata: cycloneDXMetadata{
+			Timestamp: timestamp(ctx),
+			Tools:     []cycloneDXTool{{Vendor: "goreleaser", Name: "goreleaser"}},
+			Component: cycloneDXComponent{
+				BOMRef:  main.purl(),
+				Type:    typ,
+				Name:    a.Name,
+				Version: ctx.Version,
+				Hashes:  []cycloneDXHash{{Alg: "SHA-256", Content: digest}},
+				PURL:    main.purl(),
+			},
+		},
+		Components:   []cycloneDXComponent{},
+		Dependencies: []cycloneDXDependency{},
+	}
+	root := cycloneDXDependency{Ref: main.purl()}
+	for _, m := range modules {
+		bom.Components = append(bom.Components, cycloneDXComponent{
+			BOMRef:  m.purl(),
+			Type:    "library",
+			Name:    m.Path,
+			Version: m.Version,
+			Scope:   "required",
+			PURL:    m.purl(),
+		})
+		if !m.Indirect {
+			root.DependsOn = append(root.DependsOn, m.purl())
+		}
+	}
+	bom.Dependencies = append(bom.Dependencies, root)
+	return bom
+}
+
+type spdxDoc struct {
+	SPDXVersion       string             `json:"spdxVersion"`
+	DataLicense       string             `json:"dataLicense"`
+	SPDXID            string             `json:"SPDXID"`
+	Name              string             `json:"name"`
+	DocumentNamespace string             `json:"documentNamespace"`
+	CreationInfo      spdxCreationInfo   `json:"creationInfo"`
+	Packages          []spdxPackage      `json:"packages"`
+	Relationships     []spdxRelationship `json:"relationships"`
+}
+
+type spdxCreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+type spdxPackage struct {
+	Name             string            `json:"name"`
+	SPDXID           string            `json:"SPDXID"`
+	VersionInfo      string            `json:"versionInfo,omitempty"`
+	DownloadLocation string            `json:"downloadLocation"`
+	FilesAnalyzed    bool              `json:"filesAnalyzed"`
+	Checksums        []spdxChecksum    `json:"checksums,omitempty"`
+	LicenseConcluded string            `json:"licenseConcluded"`
+	LicenseDeclared  string            `json:"licenseDeclared"`
+	CopyrightText    string            `json:"copyrightText"`
+	ExternalRefs     []spdxExternalRef `json:"externalRefs,omitempty"`
+}
+
+type spdxChecksum struct {
+	Algorithm     string `json:"algorithm"`
+	ChecksumValue string `json:"checksumValue"`
+}
+
+type spdxExternalRef struct {
+	ReferenceCategory string `json:"referenceCategory"`
+	ReferenceType     string `json:"referenceType"`
+	ReferenceLocator  string `json:"referenceLocator"`
+}
+
+type spdxRelationship struct {
+	SPDXElementID      string `json:"spdxElementId"`
+	RelationshipType   string `json:"relationshipType"`
+	RelatedSPDXElement string `json:"relatedSpdxElement"`
+}
+
+func spdxPackageOf(id, name, version, purl string) spdxPackage {
+	return spdxPackage{
+		Name:             name,
+		SPDXID:           id,
+		VersionInfo:      version,
+		DownloadLocation: "NOASSERTION",
+		LicenseConcluded: "NOASSERTION",
+		LicenseDeclared:  "NOASSERTION",
+		CopyrightText:    "NOASSERTION",
+		ExternalRefs: []spdxExternalRef{{
+			ReferenceCategory: "PACKAGE_MANAGER",
+			ReferenceType:     "purl",
+			ReferenceLocator:  purl,
+		}},
+	}
+}
+
+func spdxDocument(ctx *context.Context, a *artifact.Artifact, digest string, main module, modules []module) spdxDoc {
+	root := spdxPackageOf("SPDXRef-Package-0", a.Name, ctx.Version, main.purl())
+	root.Checksums = []spdxChecksum{{Algorithm: "SHA256", ChecksumValue: digest}}
+	doc := spdxDoc{
+		SPDXVersion:       "SPDX-2.2",
+		DataLicense:       "CC0-1.0",
+		SPDXID:            "SPDXRef-DOCUMENT",
+		Name:              a.Name,
+		DocumentNamespace: "https://goreleaser.com/spdx/" + a.Name + "-" + uuid(a.Name, digest, formatSPDX),
+		CreationInfo: spdxCreationInfo{
+			Created:  timestamp(ctx),
+			Creators: []string{"Tool: goreleaser"},
+		},
+		Packages: []spdxPackage{root},
+		Relationships: []spdxRelationship{{
+			SPDXElementID:      "SPDXRef-DOCUMENT",
+			RelationshipType:   "DESCRIBES",
+			RelatedSPDXElement: root.SPDXID,
+		}},
+	}
+	for i, m := range modules {
+		pkg := spdxPackageOf(fmt.Sprintf("SPDXRef-Package-%d", i+1), m.Path, m.Version, m.purl())
+		doc.Packages = append(doc.Packages, pkg)
+		doc.Relationships = append(doc.Relationships, spdxRelationship{
+			SPDXElementID:      root.SPDXID,
+			RelationshipType:   "DEPENDS_ON",
+			RelatedSPDXElement: pkg.SPDXID,
+		})
+	}
+	return doc
+}
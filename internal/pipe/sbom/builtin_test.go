@@ -0,0 +1,218 @@
+package sbom
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+const goMod = `module github.com/goreleaser/fake // the module
+
+go 1.17
+
+require (
+	github.com/apex/log v1.9.0
+	github.com/fatih/color v1.13.0 // indirect
+	"github.com/quoted/mod" v0.1.0
+)
+
+require gopkg.in/yaml.v2 v2.4.0
+
+replace github.com/apex/log => github.com/fork/log v1.9.1
+
+replace (
+	github.com/fatih/color v1.13.0 => ../color
+)
+`
+
+func setupGoMod(tb testing.TB, content string) {
+	tb.Helper()
+	path := filepath.Join(tb.TempDir(), "go.mod")
+	require.NoError(tb, os.WriteFile(path, []byte(content), 0o644))
+	prev := goModPath
+	goModPath = path
+	tb.Cleanup(func() {
+		goModPath = prev
+	})
+}
+
+func TestReadGoMod(t *testing.T) {
+	setupGoMod(t, goMod)
+	main, modules, err := readGoMod(goModPath)
+	require.NoError(t, err)
+	require.Equal(t, module{Path: "github.com/goreleaser/fake"}, main)
+	require.Equal(t, []module{
+		{Path: "github.com/fork/log", Version: "v1.9.1"},
+		{Path: "github.com/fatih/color", Version: "v1.13.0", Indirect: true},
+		{Path: "github.com/quoted/mod", Version: "v0.1.0"},
+		{Path: "gopkg.in/yaml.v2", Version: "v2.4.0"},
+	}, modules)
+}
+
+func TestReadGoModErrors(t *testing.T) {
+	t.Run("missing", func(t *testing.T) {
+		_, _, err := readGoMod(filepath.Join(t.TempDir(), "go.mod"))
+		require.Error(t, err)
+	})
+	t.Run("no module", func(t *testing.T) {
+		setupGoMod(t, "go 1.17\n")
+		_, _, err := readGoMod(goModPath)
+		require.EqualError(t, err, goModPath+" has no module directive")
+	})
+}
+
+func TestBuiltinDefault(t *testing.T) {
+	ctx := context.New(config.Project{
+		SBOMs: []config.SBOM{{Builtin: true}},
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	cfg := ctx.Config.SBOMs[0]
+	require.Empty(t, cfg.Cmd)
+	require.Empty(t, cfg.Args)
+	require.Empty(t, cfg.Env)
+	require.Equal(t, formatCycloneDX, cfg.Format)
+	require.Equal(t, "archive", cfg.Artifacts)
+	require.Equal(t, []string{"{{ .ArtifactName }}.sbom"}, cfg.Documents)
+}
+
+func TestBuiltinDefaultErrors(t *testing.T) {
+	for name, tt := range map[string]struct {
+		cfg config.SBOM
+		err string
+	}{
+		"format": {
+			cfg: config.SBOM{Builtin: true, Format: "xml"},
+			err: `invalid builtin SBOM format: "xml"`,
+		},
+		"any": {
+			cfg: config.SBOM{Builtin: true, Artifacts: "any"},
+			err: `builtin SBOMs can't catalog artifacts="any", only binary, archive and source are supported`,
+		},
+		"package": {
+			cfg: config.SBOM{Builtin: true, Artifacts: "package"},
+			err: `builtin SBOMs can't catalog artifacts="package", only binary, archive and source are supported`,
+		},
+	} {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			ctx := context.New(config.Project{SBOMs: []config.SBOM{tt.cfg}})
+			require.EqualError(t, Pipe{}.Default(ctx), tt.err)
+		})
+	}
+}
+
+func builtinContext(tb testing.TB, cfg config.SBOM) *context.Context {
+	tb.Helper()
+	setupGoMod(tb, goMod)
+	ctx := context.New(config.Project{
+		Dist:   tb.TempDir(),
+		SBOMs:  []config.SBOM{cfg},
+		Env:    []string{},
+		Builds: []config.Build{{ID: "foo"}},
+	})
+	ctx.Version = "1.2.3"
+	ctx.Git.CurrentTag = "v1.2.3"
+	require.NoError(tb, Pipe{}.Default(ctx))
+
+	for _, a := range []*artifact.Artifact{
+		{Name: "foo_linux_amd64", Type: artifact.UploadableBinary, Goos: "linux", Goarch: "amd64", Extra: map[string]interface{}{artifact.ExtraID: "foo", "Binary": "foo"}},
+		{Name: "foo.tar.gz", Type: artifact.UploadableSourceArchive, Extra: map[string]interface{}{artifact.ExtraID: "foo"}},
+	} {
+		a.Path = filepath.Join(ctx.Config.Dist, a.Name)
+		require.NoError(tb, os.WriteFile(a.Path, []byte(a.Name), 0o644))
+		ctx.Artifacts.Add(a)
+	}
+	return ctx
+}
+
+func TestBuiltinCycloneDX(t *testing.T) {
+	ctx := builtinContext(t, config.SBOM{Builtin: true, Artifacts: "binary"})
+	require.NoError(t, Pipe{}.Run(ctx))
+
+	sboms := ctx.Artifacts.Filter(artifact.ByType(artifact.SBOM)).List()
+	require.Len(t, sboms, 1)
+	require.Equal(t, "foo_1.2.3_linux_amd64.sbom", sboms[0].Name)
+
+	bts, err := os.ReadFile(sboms[0].Path)
+	require.NoError(t, err)
+	var bom cycloneDXBOM
+	require.NoError(t, json.Unmarshal(bts, &bom))
+	require.Equal(t, "CycloneDX", bom.BOMFormat)
+	require.Equal(t, "1.4", bom.SpecVersion)
+	require.Regexp(t, `^urn:uuid:[0-9a-f]{8}-[0-9a-f]{4}-5[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`, bom.SerialNumber)
+	require.Equal(t, "application", bom.Metadata.Component.Type)
+	require.Equal(t, "foo_linux_amd64", bom.Metadata.Component.Name)
+	require.Equal(t, "pkg:golang/github.com/goreleaser/fake@v1.2.3", bom.Metadata.Component.PURL)
+	binary := ctx.Artifacts.Filter(artifact.ByType(artifact.UploadableBinary)).List()[0]
+	digest, err := binary.Checksum("sha256")
+	require.NoError(t, err)
+	require.Equal(t, []cycloneDXHash{{Alg: "SHA-256", Content: digest}}, bom.Metadata.Component.Hashes)
+	require.Len(t, bom.Components, 4)
+	require.Equal(t, cycloneDXComponent{
+		BOMRef:  "pkg:golang/gopkg.in/yaml.v2@v2.4.0",
+		Type:    "library",
+		Name:    "gopkg.in/yaml.v2",
+		Version: "v2.4.0",
+		Scope:   "required",
+		PURL:    "pkg:golang/gopkg.in/yaml.v2@v2.4.0",
+	}, bom.Components[3])
+	require.Equal(t, []cycloneDXDependency{{
+		Ref: "pkg:golang/github.com/goreleaser/fake@v1.2.3",
+		DependsOn: []string{
+			"pkg:golang/github.com/fork/log@v1.9.1",
+			"pkg:golang/github.com/quoted/mod@v0.1.0",
+			"pkg:golang/gopkg.in/yaml.v2@v2.4.0",
+		},
+	}}, bom.Dependencies)
+
+	// the same artifact always gets the same serial number.
+	require.NoError(t, Pipe{}.Run(ctx))
+	bts2, err := os.ReadFile(sboms[0].Path)
+	require.NoError(t, err)
+	require.Equal(t, string(bts), string(bts2))
+}
+
+func TestBuiltinSPDX(t *testing.T) {
+	ctx := builtinContext(t, config.SBOM{Builtin: true, Artifacts: "source", Format: formatSPDX, Documents: []string{"${artifact}.spdx.json"}})
+	require.NoError(t, Pipe{}.Run(ctx))
+
+	sboms := ctx.Artifacts.Filter(artifact.ByType(artifact.SBOM)).List()
+	require.Len(t, sboms, 1)
+	require.Equal(t, "foo.tar.gz.spdx.json", sboms[0].Name)
+
+	bts, err := os.ReadFile(sboms[0].Path)
+	require.NoError(t, err)
+	var doc spdxDoc
+	require.NoError(t, json.Unmarshal(bts, &doc))
+	require.Equal(t, "SPDX-2.2", doc.SPDXVersion)
+	require.Equal(t, "foo.tar.gz", doc.Name)
+	require.Len(t, doc.Packages, 5)
+	require.Equal(t, "SPDXRef-Package-0", doc.Packages[0].SPDXID)
+	require.Equal(t, "SHA256", doc.Packages[0].Checksums[0].Algorithm)
+	require.Equal(t, "github.com/fork/log", doc.Packages[1].Name)
+	require.Equal(t, "pkg:golang/github.com/fork/log@v1.9.1", doc.Packages[1].ExternalRefs[0].ReferenceLocator)
+	require.Len(t, doc.Relationships, 5)
+	require.Equal(t, spdxRelationship{
+		SPDXElementID:      "SPDXRef-DOCUMENT",
+		RelationshipType:   "DESCRIBES",
+		RelatedSPDXElement: "SPDXRef-Package-0",
+	}, doc.Relationships[0])
+	require.Equal(t, "DEPENDS_ON", doc.Relationships[4].RelationshipType)
+}
+
+func TestBuiltinNoGoMod(t *testing.T) {
+	ctx := builtinContext(t, config.SBOM{Builtin: true, Artifacts: "binary"})
+	goModPath = filepath.Join(t.TempDir(), "go.mod")
+	require.Error(t, Pipe{}.Run(ctx))
+}
+
+func TestBuiltinInvalidTemplate(t *testing.T) {
+	ctx := builtinContext(t, config.SBOM{Builtin: true, Artifacts: "binary", Documents: []string{"{{ .Nope }"}})
+	require.Error(t, Pipe{}.Run(ctx))
+}
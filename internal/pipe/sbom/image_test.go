@@ -0,0 +1,169 @@
+package sbom
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeTools puts fake syft and cosign commands in the PATH: syft writes a
+// SPDX document, and cosign records its arguments in the returned file.
+func fakeTools(tb testing.TB) string {
+	tb.Helper()
+	dir := tb.TempDir()
+	calls := filepath.Join(dir, "cosign.log")
+	syft := `#!/bin/sh
+echo '{"spdxVersion":"SPDX-2.2","name":"'"$1"'"}' > "$3"
+`
+	cosign := `#!/bin/sh
+echo "$@" >> ` + calls + `
+`
+	require.NoError(tb, os.WriteFile(filepath.Join(dir, "syft"), []byte(syft), 0o755))
+	require.NoError(tb, os.WriteFile(filepath.Join(dir, "cosign"), []byte(cosign), 0o755))
+	tb.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return calls
+}
+
+func imageContext(tb testing.TB, cfg config.SBOM) *context.Context {
+	tb.Helper()
+	ctx := context.New(config.Project{
+		Dist:  tb.TempDir(),
+		SBOMs: []config.SBOM{cfg},
+	})
+	require.NoError(tb, Pipe{}.Default(ctx))
+	for _, name := range []string{"ghcr.io/foo/bar:v1.0.0", "ghcr.io/foo/bar:latest"} {
+		ctx.Artifacts.Add(&artifact.Artifact{
+			Name:  name,
+			Path:  name,
+			Type:  artifact.DockerImage,
+			Extra: map[string]interface{}{artifact.ExtraID: "bar"},
+		})
+	}
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name:  "ghcr.io/foo/bar:v1.0.0",
+		Path:  "ghcr.io/foo/bar:v1.0.0",
+		Type:  artifact.DockerManifest,
+		Extra: map[string]interface{}{artifact.ExtraID: "manifest"},
+	})
+	return ctx
+}
+
+func TestImageDefault(t *testing.T) {
+	ctx := imageContext(t, config.SBOM{Artifacts: "image"})
+	cfg := ctx.Config.SBOMs[0]
+	require.Equal(t, "syft", cfg.Cmd)
+	require.Empty(t, cfg.Env)
+	require.Len(t, cfg.Documents, 1)
+}
+
+func TestImageDefaultErrors(t *testing.T) {
+	for name, tt := range map[string]struct {
+		cfg config.SBOM
+		err string
+	}{
+		"attach files": {
+			cfg: config.SBOM{Artifacts: "archive", Attach: "cosign"},
+			err: "sbom default: attach is only supported when artifacts is image or manifest",
+		},
+		"invalid attach": {
+			cfg: config.SBOM{Artifacts: "image", Attach: "oras"},
+			err: `sbom default: invalid attach: "oras"`,
+		},
+		"builtin": {
+			cfg: config.SBOM{Artifacts: "image", Builtin: true},
+			err: `builtin SBOMs can't catalog artifacts="image", only binary, archive and source are supported`,
+		},
+	} {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			ctx := context.New(config.Project{SBOMs: []config.SBOM{tt.cfg}})
+			require.EqualError(t, Pipe{}.Default(ctx), tt.err)
+		})
+	}
+}
+
+func TestImageRunSkipsImages(t *testing.T) {
+	fakeTools(t)
+	ctx := imageContext(t, config.SBOM{Artifacts: "image"})
+	require.NoError(t, Pipe{}.Run(ctx))
+	require.Empty(t, ctx.Artifacts.Filter(artifact.ByType(artifact.SBOM)).List())
+}
+
+func TestImagePublish(t *testing.T) {
+	for name, tt := range map[string]struct {
+		cfg   config.SBOM
+		names []string
+		calls []string
+	}{
+		"image": {
+			cfg:   config.SBOM{Artifacts: "image"},
+			names: []string{"ghcr.io_foo_bar_latest.sbom", "ghcr.io_foo_bar_v1.0.0.sbom"},
+		},
+		"cosign": {
+			cfg:   config.SBOM{Artifacts: "image", Attach: "cosign", IDs: []string{"bar"}},
+			names: []string{"ghcr.io_foo_bar_latest.sbom", "ghcr.io_foo_bar_v1.0.0.sbom"},
+			calls: []string{
+				"attach sbom --sbom {{dist}}/ghcr.io_foo_bar_latest.sbom --type spdx ghcr.io/foo/bar:latest",
+				"attach sbom --sbom {{dist}}/ghcr.io_foo_bar_v1.0.0.sbom --type spdx ghcr.io/foo/bar:v1.0.0",
+			},
+		},
+		"referrers": {
+			cfg:   config.SBOM{Artifacts: "manifest", Attach: "referrers", Documents: []string{"manifest.spdx.json"}},
+			names: []string{"manifest.spdx.json"},
+			calls: []string{
+				"attach sbom --sbom {{dist}}/manifest.spdx.json --type spdx --registry-referrers-mode oci-1-1 ghcr.io/foo/bar:v1.0.0",
+			},
+		},
+	} {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			calls := fakeTools(t)
+			ctx := imageContext(t, tt.cfg)
+			require.NoError(t, Pipe{}.Publish(ctx))
+
+			var names []string
+			for _, a := range ctx.Artifacts.Filter(artifact.ByType(artifact.SBOM)).List() {
+				names = append(names, a.Name)
+				require.FileExists(t, a.Path)
+				require.Equal(t, "default", a.ID())
+			}
+			require.ElementsMatch(t, tt.names, names)
+
+			bts, err := os.ReadFile(calls)
+			if len(tt.calls) == 0 {
+				require.True(t, os.IsNotExist(err))
+				return
+			}
+			require.NoError(t, err)
+			dist, err := filepath.Abs(ctx.Config.Dist)
+			require.NoError(t, err)
+			var expected []string
+			for _, call := range tt.calls {
+				expected = append(expected, strings.ReplaceAll(call, "{{dist}}", dist))
+			}
+			require.ElementsMatch(t, expected, strings.Split(strings.TrimSpace(string(bts)), "\n"))
+		})
+	}
+}
+
+func TestSBOMType(t *testing.T) {
+	dir := t.TempDir()
+	for content, typ := range map[string]string{
+		`{"spdxVersion":"SPDX-2.3"}`:                        "spdx",
+		"SPDXVersion: SPDX-2.3\n":                           "spdx",
+		`{"bomFormat":"CycloneDX"}`:                         "cyclonedx",
+		`<bom xmlns="http://cyclonedx.org/schema/bom/1.4">`: "cyclonedx",
+		`{"artifacts":[]}`:                                  "syft",
+	} {
+		path := filepath.Join(dir, "sbom")
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+		require.Equal(t, typ, sbomType(path), content)
+	}
+	require.Equal(t, "syft", sbomType(filepath.Join(dir, "nope")))
+}
@@ -0,0 +1,315 @@
+package sbom
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+)
+
+// document is a SBOM document decoded as generic JSON, so the fields
+// goreleaser doesn't know about are kept when merging.
+type document = map[string]interface{}
+
+// merge merges the given SBOMs into a single document, describing the whole
+// release, and adds it to the artifacts.
+// Components found in several SBOMs, like the dependencies shared by all the
+// binaries of a project, are only listed once.
+func merge(ctx *context.Context, cfg config.SBOM, sboms []*artifact.Artifact) error {
+	if len(sboms) == 0 {
+		return nil
+	}
+	name, err := tmpl.New(ctx).Apply(cfg.MergedDocument)
+	if err != nil {
+		return fmt.Errorf("merging sboms failed: %s: invalid template: %w", cfg.MergedDocument, err)
+	}
+
+	sort.Slice(sboms, func(i, j int) bool {
+		return sboms[i].Name < sboms[j].Name
+	})
+	var docs []document
+	var typ string
+	for _, sbom := range sboms {
+		bts, err := os.ReadFile(sbom.Path)
+		if err != nil {
+			return fmt.Errorf("merging sboms failed: %w", err)
+		}
+		var doc document
+		if err := json.Unmarshal(bts, &doc); err != nil {
+			return fmt.Errorf("merging sboms failed: %s is not a JSON document: %w", sbom.Name, err)
+		}
+		t := sbomType(sbom.Path)
+		if t == "syft" {
+			return fmt.Errorf("merging sboms failed: %s is neither a SPDX nor a CycloneDX document", sbom.Name)
+		}
+		if typ != "" && t != typ {
+			return fmt.Errorf("merging sboms failed: can't merge %s and %s documents", typ, t)
+		}
+		typ = t
+		docs = append(docs, doc)
+	}
+
+	var merged document
+	if typ == "spdx" {
+		merged = mergeSPDX(ctx, name, docs)
+	} else {
+		merged = mergeCycloneDX(ctx, name, docs)
+	}
+	bts, err := json.MarshalIndent(merged, "", "  ")
+	if err != nil {
+		return fmt.Errorf("merging sboms failed: %w", err)
+	}
+	path := filepath.Join(ctx.Config.Dist, name)
+	log.FromContext(ctx).WithField("sboms", len(sboms)).WithField("sbom", name).Info("merging")
+	if err := os.WriteFile(path, bts, 0o644); err != nil {
+		return fmt.Errorf("merging sboms failed: %w", err)
+	}
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Type: artifact.SBOM,
+		Name: name,
+		Path: path,
+		Extra: map[string]interface{}{
+			artifact.ExtraID: cfg.ID,
+		},
+	})
+	return nil
+}
+
+// mergeSPDX merges SPDX documents.
+// Packages and files are identified by their package URL, or name and
+// version, and checksums, and get new SPDX identifiers, as the ones of
+// different documents can collide.
+func mergeSPDX(ctx *context.Context, name string, docs []document) document {
+	merged := document{
+		"spdxVersion":       docs[0]["spdxVersion"],
+		"dataLicense":       "CC0-1.0",
+		"SPDXID":            "SPDXRef-DOCUMENT",
+		"name":              name,
+		"documentNamespace": "https://goreleaser.com/spdx/" + name + "-" + uuid(append([]string{name}, namespaces(docs, "documentNamespace")...)...),
+		"creationInfo": document{
+			"created":  timestamp(ctx),
+			"creators": []string{"Tool: goreleaser"},
+		},
+	}
+
+	ids := map[string]string{}
+	for _, field := range []string{"packages", "files"} {
+		prefix := map[string]string{"packages": "SPDXRef-Package", "files": "SPDXRef-File"}[field]
+		var elements []interface{}
+		for i, doc := range docs {
+			for _, e := range list(doc[field]) {
+				element, ok := e.(document)
+				if !ok {
+					continue
+				}
+				key := spdxKey(field, element)
+				id := fmt.Sprintf("%d/%v", i, element["SPDXID"])
+				if kept, ok := ids[key]; ok {
+					ids[id] = kept
+					continue
+				}
+				newID := fmt.Sprintf("%s-%d", prefix, len(elements))
+				ids[key] = newID
+				ids[id] = newID
+				element["SPDXID"] = newID
+				elements = append(elements, element)
+			}
+		}
+		if len(elements) > 0 {
+			merged[field] = elements
+		}
+	}
+
+	remap := func(doc int, id interface{}) interface{} {
+		if newID, ok := ids[fmt.Sprintf("%d/%v", doc, id)]; ok {
+			return newID
+		}
+		return id
+	}
+	var describes, relationships []interface{}
+	describesSeen, relationshipsSeen := map[string]bool{}, map[string]bool{}
+	for i, doc := range docs {
+		for _, id := range list(doc["documentDescribes"]) {
+			describes = appendUnique(describesSeen, describes, remap(i, id))
+		}
+		for _, r := range list(doc["relationships"]) {
+			relationship, ok := r.(document)
+			if !ok {
+				continue
+			}
+			relationship["spdxElementId"] = remap(i, relationship["spdxElementId"])
+			relationship["relatedSpdxElement"] = remap(i, relationship["relatedSpdxElement"])
+			relationships = appendUnique(relationshipsSeen, relationships, relationship)
+		}
+	}
+	if len(describes) > 0 {
+		merged["documentDescribes"] = describes
+	}
+	merged["relationships"] = relationships
+	return merged
+}
+
+func spdxKey(field string, element document) string {
+	var checksums []string
+	for _, c := range list(element["checksums"]) {
+		if checksum, ok := c.(document); ok {
+			checksums = append(checksums, fmt.Sprint(checksum["checksumValue"]))
+		}
+	}
+	sort.Strings(checksums)
+	id := fmt.Sprintf("%v@%v", element["name"], element["versionInfo"])
+	if field == "files" {
+		id = fmt.Sprint(element["fileName"])
+	}
+	for _, r := range list(element["externalRefs"]) {
+		if ref, ok := r.(document); ok && ref["referenceType"] == "purl" {
+			id = fmt.Sprint(ref["referenceLocator"])
+		}
+	}
+	return field + ":" + id + "#" + strings.Join(checksums, ",")
+}
+
+// mergeCycloneDX merges CycloneDX documents.
+// The components the documents are about become components of the merged
+// document, which is about the project itself.
+// Components are identified by their package URL, or name and version, and
+// hashes, and get new references if theirs collide with another component.
+func mergeCycloneDX(ctx *context.Context, name string, docs []document) document {
+	project := ctx.Config.ProjectName + "@" + ctx.Version
+	merged := document{
+		"bomFormat":    "CycloneDX",
+		"specVersion":  docs[0]["specVersion"],
+		"serialNumber": "urn:uuid:" + uuid(append([]string{name}, namespaces(docs, "serialNumber")...)...),
+		"version":      1,
+		"metadata": document{
+			"timestamp": timestamp(ctx),
+			"tools":     []cycloneDXTool{{Vendor: "goreleaser", Name: "goreleaser"}},
+			"component": document{
+				"bom-ref": project,
+				"type":    "application",
+				"name":    ctx.Config.ProjectName,
+				"version": ctx.Version,
+			},
+		},
+	}
+
+	refs := map[string]string{}
+	keys := map[string]string{}
+	used := map[string]bool{}
+	var components, roots []interface{}
+	add := func(doc int, component document) string {
+		key := cycloneDXKey(component)
+		ref := fmt.Sprint(component["bom-ref"])
+		if kept, ok := keys[key]; ok {
+			refs[fmt.Sprintf("%d/%s", doc, ref)] = kept
+			return kept
+		}
+		newRef := ref
+		for n := 1; used[newRef]; n++ {
+			newRef = fmt.Sprintf("%s-%d", ref, n)
+		}
+		used[newRef] = true
+		refs[fmt.Sprintf("%d/%s", doc, ref)] = newRef
+		keys[key] = newRef
+		component["bom-ref"] = newRef
+		components = append(components, component)
+		return newRef
+	}
+	seen := map[string]bool{}
+	for i, doc := range docs {
+		if metadata, ok := doc["metadata"].(document); ok {
+			if component, ok := metadata["component"].(document); ok {
+				roots = appendUnique(seen, roots, add(i, component))
+			}
+		}
+		for _, c := range list(doc["components"]) {
+			if component, ok := c.(document); ok {
+				add(i, component)
+			}
+		}
+	}
+	merged["components"] = components
+
+	dependencies := []interface{}{document{"ref": project, "dependsOn": roots}}
+	index := map[string]document{}
+	dependsOnSeen := map[string]map[string]bool{}
+	for i, doc := range docs {
+		for _, d := range list(doc["dependencies"]) {
+			dependency, ok := d.(document)
+			if !ok {
+				continue
+			}
+			ref := refs[fmt.Sprintf("%d/%v", i, dependency["ref"])]
+			if ref == "" {
+				continue
+			}
+			existing, ok := index[ref]
+			if !ok {
+				existing = document{"ref": ref}
+				index[ref] = existing
+				dependsOnSeen[ref] = map[string]bool{}
+				dependencies = append(dependencies, existing)
+			}
+			dependsOn, _ := existing["dependsOn"].([]interface{})
+			for _, on := range list(dependency["dependsOn"]) {
+				if newRef := refs[fmt.Sprintf("%d/%v", i, on)]; newRef != "" {
+					dependsOn = appendUnique(dependsOnSeen[ref], dependsOn, newRef)
+				}
+			}
+			if len(dependsOn) > 0 {
+				existing["dependsOn"] = dependsOn
+			}
+		}
+	}
+	merged["dependencies"] = dependencies
+	return merged
+}
+
+func cycloneDXKey(component document) string {
+	var hashes []string
+	for _, h := range list(component["hashes"]) {
+		if hash, ok := h.(document); ok {
+			hashes = append(hashes, fmt.Sprint(hash["content"]))
+		}
+	}
+	sort.Strings(hashes)
+	id := fmt.Sprintf("%v@%v", component["name"], component["version"])
+	if purl, ok := component["purl"].(string); ok && purl != "" {
+		id = purl
+	}
+	return id + "#" + strings.Join(hashes, ",")
+}
+
+// namespaces returns the given field of each document, to derive the
+// identifier of the merged document from them.
+func namespaces(docs []document, field string) []string {
+	result := make([]string, 0, len(docs))
+	for _, doc := range docs {
+		result = append(result, fmt.Sprint(doc[field]))
+	}
+	return result
+}
+
+func list(v interface{}) []interface{} {
+	l, _ := v.([]interface{})
+	return l
+}
+
+// appendUnique appends v to the given list unless it was already seen.
+func appendUnique(seen map[string]bool, l []interface{}, v interface{}) []interface{} {
+	bts, _ := json.Marshal(v)
+	key := string(bts)
+	if seen[key] {
+		return l
+	}
+	seen[key] = true
+	return append(l, v)
+}
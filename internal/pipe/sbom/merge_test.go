@@ -0,0 +1,168 @@
+package sbom
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+func mergeContext(tb testing.TB, format string) *context.Context {
+	tb.Helper()
+	ctx := builtinContext(tb, config.SBOM{
+		Builtin:        true,
+		Artifacts:      "binary",
+		Format:         format,
+		MergedDocument: "{{ .ProjectName }}_{{ .Version }}.sbom.json",
+	})
+	ctx.Config.ProjectName = "foo"
+	a := &artifact.Artifact{
+		Name:   "foo_darwin_arm64",
+		Path:   filepath.Join(ctx.Config.Dist, "foo_darwin_arm64"),
+		Type:   artifact.UploadableBinary,
+		Goos:   "darwin",
+		Goarch: "arm64",
+		Extra:  map[string]interface{}{artifact.ExtraID: "foo", "Binary": "foo"},
+	}
+	require.NoError(tb, os.WriteFile(a.Path, []byte(a.Name), 0o644))
+	ctx.Artifacts.Add(a)
+	return ctx
+}
+
+func mergedDocument(tb testing.TB, ctx *context.Context, v interface{}) {
+	tb.Helper()
+	sboms := ctx.Artifacts.Filter(artifact.ByType(artifact.SBOM)).List()
+	require.Len(tb, sboms, 3)
+	merged := sboms[2]
+	require.Equal(tb, "foo_1.2.3.sbom.json", merged.Name)
+	require.Equal(tb, "default", merged.ID())
+	bts, err := os.ReadFile(merged.Path)
+	require.NoError(tb, err)
+	require.NoError(tb, json.Unmarshal(bts, v))
+}
+
+func TestMergeCycloneDX(t *testing.T) {
+	ctx := mergeContext(t, formatCycloneDX)
+	require.NoError(t, Pipe{}.Run(ctx))
+
+	var bom cycloneDXBOM
+	mergedDocument(t, ctx, &bom)
+	require.Equal(t, "CycloneDX", bom.BOMFormat)
+	require.Equal(t, "1.4", bom.SpecVersion)
+	require.Equal(t, "foo", bom.Metadata.Component.Name)
+	require.Equal(t, "foo@1.2.3", bom.Metadata.Component.BOMRef)
+
+	// both binaries, and their 4 shared dependencies once.
+	require.Len(t, bom.Components, 6)
+	require.Equal(t, "foo_darwin_arm64", bom.Components[0].Name)
+	require.Equal(t, "pkg:golang/github.com/goreleaser/fake@v1.2.3", bom.Components[0].BOMRef)
+	require.Equal(t, "foo_linux_amd64", bom.Components[5].Name)
+	require.Equal(t, "pkg:golang/github.com/goreleaser/fake@v1.2.3-1", bom.Components[5].BOMRef)
+
+	require.Equal(t, []cycloneDXDependency{
+		{
+			Ref: "foo@1.2.3",
+			DependsOn: []string{
+				"pkg:golang/github.com/goreleaser/fake@v1.2.3",
+				"pkg:golang/github.com/goreleaser/fake@v1.2.3-1",
+			},
+		},
+		{
+			Ref: "pkg:golang/github.com/goreleaser/fake@v1.2.3",
+			DependsOn: []string{
+				"pkg:golang/github.com/fork/log@v1.9.1",
+				"pkg:golang/github.com/quoted/mod@v0.1.0",
+				"pkg:golang/gopkg.in/yaml.v2@v2.4.0",
+			},
+		},
+		{
+			Ref: "pkg:golang/github.com/goreleaser/fake@v1.2.3-1",
+			DependsOn: []string{
+				"pkg:golang/github.com/fork/log@v1.9.1",
+				"pkg:golang/github.com/quoted/mod@v0.1.0",
+				"pkg:golang/gopkg.in/yaml.v2@v2.4.0",
+			},
+		},
+	}, bom.Dependencies)
+}
+
+func TestMergeSPDX(t *testing.T) {
+	ctx := mergeContext(t, formatSPDX)
+	require.NoError(t, Pipe{}.Run(ctx))
+
+	var doc spdxDoc
+	mergedDocument(t, ctx, &doc)
+	require.Equal(t, "SPDX-2.2", doc.SPDXVersion)
+	require.Equal(t, "foo_1.2.3.sbom.json", doc.Name)
+
+	// both binaries, and their 4 shared dependencies once.
+	require.Len(t, doc.Packages, 6)
+	var names []string
+	for i, pkg := range doc.Packages {
+		require.Equal(t, fmt.Sprintf("SPDXRef-Package-%d", i), pkg.SPDXID)
+		names = append(names, pkg.Name)
+	}
+	require.Equal(t, []string{
+		"foo_darwin_arm64",
+		"github.com/fork/log",
+		"github.com/fatih/color",
+		"github.com/quoted/mod",
+		"gopkg.in/yaml.v2",
+		"foo_linux_amd64",
+	}, names)
+
+	require.Len(t, doc.Relationships, 10)
+	require.Equal(t, spdxRelationship{
+		SPDXElementID:      "SPDXRef-DOCUMENT",
+		RelationshipType:   "DESCRIBES",
+		RelatedSPDXElement: "SPDXRef-Package-5",
+	}, doc.Relationships[5])
+	require.Equal(t, spdxRelationship{
+		SPDXElementID:      "SPDXRef-Package-5",
+		RelationshipType:   "DEPENDS_ON",
+		RelatedSPDXElement: "SPDXRef-Package-1",
+	}, doc.Relationships[6])
+}
+
+func TestMergeErrors(t *testing.T) {
+	t.Run("invalid template", func(t *testing.T) {
+		ctx := mergeContext(t, formatCycloneDX)
+		ctx.Config.SBOMs[0].MergedDocument = "{{ .Nope }"
+		require.Error(t, Pipe{}.Run(ctx))
+	})
+	t.Run("not json", func(t *testing.T) {
+		ctx := context.New(config.Project{Dist: t.TempDir()})
+		path := filepath.Join(ctx.Config.Dist, "foo.sbom")
+		require.NoError(t, os.WriteFile(path, []byte("SPDXVersion: SPDX-2.2"), 0o644))
+		err := merge(ctx, config.SBOM{MergedDocument: "merged.json"}, []*artifact.Artifact{{Name: "foo.sbom", Path: path}})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "merging sboms failed: foo.sbom is not a JSON document")
+	})
+	t.Run("syft", func(t *testing.T) {
+		ctx := context.New(config.Project{Dist: t.TempDir()})
+		path := filepath.Join(ctx.Config.Dist, "foo.sbom")
+		require.NoError(t, os.WriteFile(path, []byte(`{"artifacts":[]}`), 0o644))
+		err := merge(ctx, config.SBOM{MergedDocument: "merged.json"}, []*artifact.Artifact{{Name: "foo.sbom", Path: path}})
+		require.EqualError(t, err, "merging sboms failed: foo.sbom is neither a SPDX nor a CycloneDX document")
+	})
+	t.Run("mixed", func(t *testing.T) {
+		ctx := context.New(config.Project{Dist: t.TempDir()})
+		var sboms []*artifact.Artifact
+		for name, content := range map[string]string{
+			"a.sbom": `{"spdxVersion":"SPDX-2.2"}`,
+			"b.sbom": `{"bomFormat":"CycloneDX"}`,
+		} {
+			path := filepath.Join(ctx.Config.Dist, name)
+			require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+			sboms = append(sboms, &artifact.Artifact{Name: name, Path: path})
+		}
+		err := merge(ctx, config.SBOM{MergedDocument: "merged.json"}, sboms)
+		require.EqualError(t, err, "merging sboms failed: can't merge spdx and cyclonedx documents")
+	})
+}
@@ -2,6 +2,7 @@ package sbom
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
@@ -15,6 +16,7 @@ import (
 	"github.com/goreleaser/goreleaser/internal/ids"
 	"github.com/goreleaser/goreleaser/internal/logext"
 	"github.com/goreleaser/goreleaser/internal/semerrgroup"
+	"github.com/goreleaser/goreleaser/internal/shell"
 	"github.com/goreleaser/goreleaser/internal/tmpl"
 	"github.com/goreleaser/goreleaser/pkg/config"
 	"github.com/goreleaser/goreleaser/pkg/context"
@@ -24,6 +26,9 @@ import (
 var passthroughEnvVars = []string{"HOME", "USER", "USERPROFILE", "TMPDIR", "TMP", "TEMP", "PATH"}
 
 // Pipe that catalogs common artifacts as an SBOM.
+// Files are cataloged when it runs, so their SBOMs can be signed and
+// uploaded as any other artifact, while docker images are cataloged once
+// they are published, so the SBOMs can be attached to them.
 type Pipe struct{}
 
 func (Pipe) String() string { return "cataloging artifacts" }
@@ -36,7 +41,7 @@ func (Pipe) Default(ctx *context.Context) error {
 	ids := ids.New("sboms")
 	for i := range ctx.Config.SBOMs {
 		cfg := &ctx.Config.SBOMs[i]
-		if cfg.Cmd == "" {
+		if cfg.Cmd == "" && !cfg.Builtin {
 			cfg.Cmd = "syft"
 		}
 		if cfg.Artifacts == "" {
@@ -48,6 +53,8 @@ func (Pipe) Default(ctx *context.Context) error {
 				cfg.Documents = []string{"{{ .Binary }}_{{ .Version }}_{{ .Os }}_{{ .Arch }}.sbom"}
 			case "any":
 				cfg.Documents = []string{}
+			case "image", "manifest":
+				cfg.Documents = []string{`{{ replace (replace (replace .ArtifactName "/" "_") ":" "_") "@" "_" }}.sbom`}
 			default:
 				cfg.Documents = []string{"{{ .ArtifactName }}.sbom"}
 			}
@@ -65,6 +72,21 @@ func (Pipe) Default(ctx *context.Context) error {
 		if cfg.ID == "" {
 			cfg.ID = "default"
 		}
+		if cfg.Builtin {
+			if err := defaultBuiltin(cfg); err != nil {
+				return err
+			}
+		}
+
+		switch cfg.Attach {
+		case "":
+		case "cosign", "referrers":
+			if !isImage(cfg.Artifacts) {
+				return fmt.Errorf("sbom %s: attach is only supported when artifacts is image or manifest", cfg.ID)
+			}
+		default:
+			return fmt.Errorf("sbom %s: invalid attach: %q", cfg.ID, cfg.Attach)
+		}
 
 		if cfg.Artifacts != "any" && len(cfg.Documents) > 1 {
 			return fmt.Errorf("multiple SBOM outputs when artifacts=%q is unsupported", cfg.Artifacts)
@@ -75,15 +97,51 @@ func (Pipe) Default(ctx *context.Context) error {
 	return ids.Validate()
 }
 
-// Run executes the Pipe.
+func defaultBuiltin(cfg *config.SBOM) error {
+	if cfg.Format == "" {
+		cfg.Format = formatCycloneDX
+	}
+	switch cfg.Format {
+	case formatCycloneDX, formatSPDX:
+	default:
+		return fmt.Errorf("invalid builtin SBOM format: %q", cfg.Format)
+	}
+	switch cfg.Artifacts {
+	case "binary", "archive", "source":
+		return nil
+	default:
+		return fmt.Errorf("builtin SBOMs can't catalog artifacts=%q, only binary, archive and source are supported", cfg.Artifacts)
+	}
+}
+
+// Run catalogs the files.
 func (Pipe) Run(ctx *context.Context) error {
 	g := semerrgroup.New(ctx.Parallelism)
 	for _, cfg := range ctx.Config.SBOMs {
+		if isImage(cfg.Artifacts) {
+			continue
+		}
+		g.Go(catalogTask(ctx, cfg))
+	}
+	return g.Wait()
+}
+
+// Publish catalogs the published docker images.
+func (Pipe) Publish(ctx *context.Context) error {
+	g := semerrgroup.New(ctx.Parallelism)
+	for _, cfg := range ctx.Config.SBOMs {
+		if !isImage(cfg.Artifacts) {
+			continue
+		}
 		g.Go(catalogTask(ctx, cfg))
 	}
 	return g.Wait()
 }
 
+func isImage(artifacts string) bool {
+	return artifacts == "image" || artifacts == "manifest"
+}
+
 func catalogTask(ctx *context.Context, cfg config.SBOM) func() error {
 	return func() error {
 		var filters []artifact.Filter
@@ -91,14 +149,25 @@ func catalogTask(ctx *context.Context, cfg config.SBOM) func() error {
 		case "source":
 			filters = append(filters, artifact.ByType(artifact.UploadableSourceArchive))
 			if len(cfg.IDs) > 0 {
-				log.Warn("when artifacts is `source`, `ids` has no effect. ignoring")
+				log.FromContext(ctx).Warn("when artifacts is `source`, `ids` has no effect. ignoring")
 			}
 		case "archive":
+			if ctx.Config.Source.SBOM {
+				filters = append(filters, artifact.Or(
+					artifact.ByType(artifact.UploadableArchive),
+					artifact.ByType(artifact.UploadableSourceArchive),
+				))
+				break
+			}
 			filters = append(filters, artifact.ByType(artifact.UploadableArchive))
 		case "binary":
 			filters = append(filters, artifact.ByType(artifact.UploadableBinary))
 		case "package":
 			filters = append(filters, artifact.ByType(artifact.LinuxPackage))
+		case "image":
+			filters = append(filters, artifact.ByType(artifact.DockerImage))
+		case "manifest":
+			filters = append(filters, artifact.ByType(artifact.DockerManifest))
 		case "any":
 			newArtifacts, err := catalogArtifact(ctx, cfg, nil)
 			if err != nil {
@@ -116,17 +185,35 @@ func catalogTask(ctx *context.Context, cfg config.SBOM) func() error {
 			filters = append(filters, artifact.ByIDs(cfg.IDs...))
 		}
 		artifacts := ctx.Artifacts.Filter(artifact.And(filters...)).List()
-		return catalog(ctx, cfg, artifacts)
+		if err := catalog(ctx, cfg, artifacts); err != nil {
+			return err
+		}
+		if cfg.MergedDocument == "" {
+			return nil
+		}
+		return merge(ctx, cfg, ctx.Artifacts.Filter(artifact.And(
+			artifact.ByType(artifact.SBOM),
+			artifact.ByIDs(cfg.ID),
+		)).List())
 	}
 }
 
 func catalog(ctx *context.Context, cfg config.SBOM, artifacts []*artifact.Artifact) error {
+	catalogFn := catalogArtifact
+	if cfg.Builtin {
+		catalogFn = generateArtifact
+	}
 	for _, a := range artifacts {
-		newArtifacts, err := catalogArtifact(ctx, cfg, a)
+		newArtifacts, err := catalogFn(ctx, cfg, a)
 		if err != nil {
 			return err
 		}
 		for _, newArtifact := range newArtifacts {
+			if cfg.Attach != "" {
+				if err := attach(ctx, cfg, a, newArtifact); err != nil {
+					return err
+				}
+			}
 			ctx.Artifacts.Add(newArtifact)
 		}
 	}
@@ -156,7 +243,13 @@ func catalogArtifact(ctx *context.Context, cfg config.SBOM, a *artifact.Artifact
 	artifactDisplayName := "(any)"
 	templater := tmpl.New(ctx).WithEnv(env)
 
-	if a != nil {
+	if a != nil && isImage(cfg.Artifacts) {
+		env["artifact"] = a.Name
+		env["artifactID"] = a.ID()
+
+		templater = templater.WithArtifact(a, nil)
+		artifactDisplayName = a.Name
+	} else if a != nil {
 		procPath, err := subprocessDistPath(ctx.Config.Dist, a.Path)
 		if err != nil {
 			return nil, fmt.Errorf("cataloging artifacts failed: cannot determine artifact path for %q: %w", a.Path, err)
@@ -231,7 +324,7 @@ func catalogArtifact(ctx *context.Context, cfg config.SBOM, a *artifact.Artifact
 	cmd.Stderr = io.MultiWriter(logext.NewWriter(fields, logext.Error), w)
 	cmd.Stdout = io.MultiWriter(logext.NewWriter(fields, logext.Info), w)
 
-	log.WithFields(fields).Info("cataloging")
+	log.FromContext(ctx).WithFields(fields).Info("cataloging")
 	if err := cmd.Run(); err != nil {
 		return nil, fmt.Errorf("cataloging artifacts: %s failed: %w: %s", cfg.Cmd, err, b.String())
 	}
@@ -271,6 +364,47 @@ func catalogArtifact(ctx *context.Context, cfg config.SBOM, a *artifact.Artifact
 	return artifacts, nil
 }
 
+// attach attaches the given SBOM to the given docker image with cosign.
+func attach(ctx *context.Context, cfg config.SBOM, image, sbom *artifact.Artifact) error {
+	log.FromContext(ctx).WithField("image", image.Name).WithField("sbom", sbom.Name).Info("attaching")
+	if err := shell.Run(ctx, "", attachCommand(cfg.Attach, sbom.Path, sbomType(sbom.Path), image.Name), ctx.Env.Strings()); err != nil {
+		return fmt.Errorf("attaching sbom %s to %s: %w", sbom.Name, image.Name, err)
+	}
+	return nil
+}
+
+// attachCommand returns the cosign command that attaches the given SBOM to
+// the given image, using the OCI referrers API if mode is referrers.
+func attachCommand(mode, path, typ, image string) []string {
+	cmd := []string{"cosign", "attach", "sbom", "--sbom", path, "--type", typ}
+	if mode == "referrers" {
+		cmd = append(cmd, "--registry-referrers-mode", "oci-1-1")
+	}
+	return append(cmd, image)
+}
+
+// sbomType returns the cosign type of the given SBOM document: spdx,
+// cyclonedx, or syft if it is neither.
+func sbomType(path string) string {
+	bts, err := os.ReadFile(path)
+	if err != nil {
+		return "syft"
+	}
+	var doc struct {
+		SPDXVersion string `json:"spdxVersion"`
+		BOMFormat   string `json:"bomFormat"`
+	}
+	_ = json.Unmarshal(bts, &doc)
+	switch {
+	case doc.SPDXVersion != "" || bytes.HasPrefix(bts, []byte("SPDXVersion:")):
+		return "spdx"
+	case doc.BOMFormat == "CycloneDX" || bytes.Contains(bts, []byte("cyclonedx.org/schema/bom")):
+		return "cyclonedx"
+	default:
+		return "syft"
+	}
+}
+
 func expand(s string, env map[string]string) string {
 	return os.Expand(s, func(key string) string {
 		return env[key]
@@ -264,6 +264,19 @@ func TestSBOMCatalogArtifacts(t *testing.T) {
 			sbomPaths: []string{"artifact1.sbom", "artifact2.sbom"},
 			sbomNames: []string{"artifact1.sbom", "artifact2.sbom"},
 		},
+		{
+			desc: "catalog archives and source archives",
+			ctx: context.New(
+				config.Project{
+					Source: config.Source{SBOM: true},
+					SBOMs: []config.SBOM{
+						{Artifacts: "archive"},
+					},
+				},
+			),
+			sbomPaths: []string{"artifact1.sbom", "artifact2.sbom", "artifact5.tar.gz.sbom"},
+			sbomNames: []string{"artifact1.sbom", "artifact2.sbom", "artifact5.tar.gz.sbom"},
+		},
 		{
 			desc: "catalog linux packages",
 			ctx: context.New(
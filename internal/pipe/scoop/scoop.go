@@ -21,16 +21,24 @@ import (
 	"github.com/goreleaser/goreleaser/pkg/context"
 )
 
-// ErrNoWindows when there is no build for windows (goos doesn't contain windows).
-var ErrNoWindows = errors.New("scoop requires a windows build")
+var (
+	// ErrNoWindows when there is no build for windows (goos doesn't contain windows).
+	ErrNoWindows = errors.New("scoop requires a windows build")
+
+	// ErrNoCheckverURL happens when the manifest should autoupdate without a
+	// checkver url, and it can't be guessed from the release.
+	ErrNoCheckverURL = errors.New("scoop.autoupdate.url is required when not releasing to GitHub")
+)
 
 const scoopConfigExtra = "ScoopConfig"
 
 // Pipe that builds and publishes scoop manifests.
 type Pipe struct{}
 
-func (Pipe) String() string                 { return "scoop manifests" }
-func (Pipe) Skip(ctx *context.Context) bool { return ctx.Config.Scoop.Bucket.Name == "" }
+func (Pipe) String() string { return "scoop manifests" }
+func (Pipe) Skip(ctx *context.Context) bool {
+	return ctx.Config.Scoop.Bucket.Name == "" && len(ctx.Config.Scoop.Buckets) == 0
+}
 
 // Run creates the scoop manifest locally.
 func (Pipe) Run(ctx *context.Context) error {
@@ -56,9 +64,21 @@ func (Pipe) Default(ctx *context.Context) error {
 		ctx.Config.Scoop.Name = ctx.Config.ProjectName
 	}
 	ctx.Config.Scoop.CommitAuthor = commitauthor.Default(ctx.Config.Scoop.CommitAuthor)
+	if err := commitauthor.CheckSigning(ctx, ctx.Config.Scoop.CommitAuthor); err != nil {
+		return fmt.Errorf("scoop %s: %w", ctx.Config.Scoop.Name, err)
+	}
 	if ctx.Config.Scoop.CommitMessageTemplate == "" {
 		ctx.Config.Scoop.CommitMessageTemplate = "Scoop update for {{ .ProjectName }} version {{ .Tag }}"
 	}
+	if ctx.Config.Scoop.PullRequest.Enabled && ctx.Config.Scoop.Bucket.Branch == "" {
+		ctx.Config.Scoop.Bucket.Branch = "{{ .ProjectName }}-{{ .Version }}"
+	}
+	for i := range ctx.Config.Scoop.Buckets {
+		bucket := &ctx.Config.Scoop.Buckets[i]
+		if bucket.PullRequest.Enabled && bucket.Repository.Branch == "" {
+			bucket.Repository.Branch = "{{ .ProjectName }}-{{ .Version }}"
+		}
+	}
 	return nil
 }
 
@@ -74,6 +94,7 @@ func doRun(ctx *context.Context, cl client.Client) error {
 		artifact.And(
 			artifact.ByGoos("windows"),
 			artifact.ByType(artifact.UploadableArchive),
+			artifact.OnlyUnencrypted,
 		),
 	).List()
 	if len(archives) == 0 {
@@ -92,7 +113,7 @@ func doRun(ctx *context.Context, cl client.Client) error {
 	}
 
 	path := filepath.Join(ctx.Config.Dist, filename)
-	log.WithField("manifest", path).Info("writing")
+	log.FromContext(ctx).WithField("manifest", path).Info("writing")
 	if err := os.WriteFile(path, content.Bytes(), 0o644); err != nil {
 		return fmt.Errorf("failed to write scoop manifest: %w", err)
 	}
@@ -117,12 +138,6 @@ func doPublish(ctx *context.Context, cl client.Client) error {
 	manifest := manifests[0]
 	scoop := manifest.Extra[scoopConfigExtra].(config.Scoop)
 
-	var err error
-	cl, err = client.NewIfToken(ctx, cl, scoop.Bucket.Token)
-	if err != nil {
-		return err
-	}
-
 	if strings.TrimSpace(scoop.SkipUpload) == "true" {
 		return pipe.Skip("scoop.skip_upload is true")
 	}
@@ -151,15 +166,78 @@ func doPublish(ctx *context.Context, cl client.Client) error {
 		return err
 	}
 
-	repo := client.RepoFromRef(scoop.Bucket)
-	return cl.CreateFile(
-		ctx,
-		author,
-		repo,
-		content,
-		path.Join(scoop.Folder, manifest.Name),
-		commitMessage,
-	)
+	for _, bucket := range bucketsFor(scoop) {
+		if err := pushToBucket(ctx, cl, bucket, author, content, manifest.Name, commitMessage); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// bucketsFor returns all the buckets the manifest is pushed to.
+func bucketsFor(scoop config.Scoop) []config.ScoopBucket {
+	var buckets []config.ScoopBucket
+	if scoop.Bucket.Name != "" {
+		buckets = append(buckets, config.ScoopBucket{
+			Repository:  scoop.Bucket,
+			Folder:      scoop.Folder,
+			PullRequest: scoop.PullRequest,
+		})
+	}
+	return append(buckets, scoop.Buckets...)
+}
+
+func pushToBucket(ctx *context.Context, cl client.Client, bucket config.ScoopBucket, author config.CommitAuthor, content []byte, name, msg string) error {
+	cl, err := client.NewIfToken(ctx, cl, bucket.Repository.Token)
+	if err != nil {
+		return err
+	}
+
+	t := tmpl.New(ctx)
+	for _, field := range []*string{
+		&bucket.Repository.Branch,
+		&bucket.PullRequest.Base.Owner,
+		&bucket.PullRequest.Base.Name,
+		&bucket.PullRequest.Base.Branch,
+	} {
+		applied, err := t.Apply(*field)
+		if err != nil {
+			return err
+		}
+		*field = applied
+	}
+
+	repo := client.RepoFromRef(bucket.Repository)
+	var opener client.PullRequestOpener
+	if bucket.PullRequest.Enabled {
+		var ok bool
+		opener, ok = cl.(client.PullRequestOpener)
+		if !ok {
+			return fmt.Errorf("scoop.pull_request: %w", client.ErrPullRequestNotSupported)
+		}
+		if err := opener.CreateBranch(ctx, repo); err != nil {
+			return err
+		}
+	}
+
+	log.FromContext(ctx).WithField("bucket", repo.String()).Info("pushing")
+	if err := cl.CreateFile(ctx, author, repo, content, path.Join(bucket.Folder, name), msg); err != nil {
+		return err
+	}
+
+	if opener == nil {
+		return nil
+	}
+	base := client.RepoFromRef(bucket.PullRequest.Base)
+	if base.Name == "" {
+		base = client.Repo{Owner: repo.Owner, Name: repo.Name}
+	}
+	url, err := opener.OpenPullRequest(ctx, base, repo, msg, "Automated with [GoReleaser](https://goreleaser.com).")
+	if err != nil {
+		return err
+	}
+	log.FromContext(ctx).WithField("url", url).Info("opened pull request")
+	return nil
 }
 
 // Manifest represents a scoop.sh App Manifest.
@@ -173,6 +251,32 @@ type Manifest struct {
 	Persist      []string            `json:"persist,omitempty"`      // Persist data between updates
 	PreInstall   []string            `json:"pre_install,omitempty"`  // An array of strings, of the commands to be executed before an application is installed.
 	PostInstall  []string            `json:"post_install,omitempty"` // An array of strings, of the commands to be executed after an application is installed.
+	Checkver     *Checkver           `json:"checkver,omitempty"`     // How the bucket checks for new versions of the app.
+	Autoupdate   *Autoupdate         `json:"autoupdate,omitempty"`   // How the bucket updates the manifest to a new version of the app.
+}
+
+// Checkver represents where and how new versions of the app are looked up.
+type Checkver struct {
+	GitHub string `json:"github,omitempty"` // GitHub repository whose latest release is the latest version
+	URL    string `json:"url,omitempty"`    // page the latest version is looked up in
+	Regex  string `json:"regex,omitempty"`  // regex matching the latest version in the page
+}
+
+// Autoupdate represents the resources of new versions of the app, with
+// `$version` in place of the version.
+type Autoupdate struct {
+	Architecture map[string]AutoupdateResource `json:"architecture"`
+}
+
+// AutoupdateResource represents the archive of a new version for an architecture.
+type AutoupdateResource struct {
+	URL  string          `json:"url"`            // URL to the archive
+	Hash *AutoupdateHash `json:"hash,omitempty"` // where to find the archive checksum
+}
+
+// AutoupdateHash represents the file the checksum of an archive is found in.
+type AutoupdateHash struct {
+	URL string `json:"url"` // URL to the checksums file
 }
 
 // Resource represents a combination of a url and a binary name for an architecture.
@@ -193,12 +297,16 @@ func doBuildManifest(manifest Manifest) (bytes.Buffer, error) {
 }
 
 func dataFor(ctx *context.Context, cl client.Client, artifacts []*artifact.Artifact) (Manifest, error) {
+	description, err := tmpl.New(ctx).Apply(ctx.Config.Scoop.Description)
+	if err != nil {
+		return Manifest{}, err
+	}
 	manifest := Manifest{
 		Version:      ctx.Version,
 		Architecture: map[string]Resource{},
 		Homepage:     ctx.Config.Scoop.Homepage,
 		License:      ctx.Config.Scoop.License,
-		Description:  ctx.Config.Scoop.Description,
+		Description:  description,
 		Persist:      ctx.Config.Scoop.Persist,
 		PreInstall:   ctx.Config.Scoop.PreInstall,
 		PostInstall:  ctx.Config.Scoop.PostInstall,
@@ -239,7 +347,7 @@ func dataFor(ctx *context.Context, cl client.Client, artifacts []*artifact.Artif
 			return manifest, err
 		}
 
-		log.WithFields(log.Fields{
+		log.FromContext(ctx).WithFields(log.Fields{
 			"artifactExtras":   artifact.Extra,
 			"fromURLTemplate":  ctx.Config.Scoop.URLTemplate,
 			"templatedBrewURL": url,
@@ -253,9 +361,76 @@ func dataFor(ctx *context.Context, cl client.Client, artifacts []*artifact.Artif
 		}
 	}
 
+	if ctx.Config.Scoop.Autoupdate.Enabled {
+		if err := setAutoupdate(ctx, &manifest); err != nil {
+			return manifest, err
+		}
+	}
+
 	return manifest, nil
 }
 
+// setAutoupdate sets the checkver and autoupdate sections of the manifest,
+// using the URLs of the current version with `$version` in place of the
+// version.
+func setAutoupdate(ctx *context.Context, manifest *Manifest) error {
+	autoupdate := ctx.Config.Scoop.Autoupdate
+	switch {
+	case autoupdate.URL != "":
+		url, err := tmpl.New(ctx).Apply(autoupdate.URL)
+		if err != nil {
+			return err
+		}
+		manifest.Checkver = &Checkver{URL: url, Regex: autoupdate.Regex}
+	case ctx.TokenType == context.TokenTypeGitHub:
+		download, err := tmpl.New(ctx).Apply(ctx.Config.GitHubURLs.Download)
+		if err != nil {
+			return err
+		}
+		manifest.Checkver = &Checkver{GitHub: fmt.Sprintf(
+			"%s/%s/%s",
+			strings.TrimSuffix(download, "/"),
+			ctx.Config.Release.GitHub.Owner,
+			ctx.Config.Release.GitHub.Name,
+		)}
+	default:
+		return ErrNoCheckverURL
+	}
+
+	checksums, err := checksumsName(ctx)
+	if err != nil {
+		return err
+	}
+
+	manifest.Autoupdate = &Autoupdate{Architecture: map[string]AutoupdateResource{}}
+	for arch, resource := range manifest.Architecture {
+		res := AutoupdateResource{URL: withVersionVar(ctx, resource.URL)}
+		if checksums != "" {
+			res.Hash = &AutoupdateHash{URL: withVersionVar(ctx, resource.URL[:strings.LastIndex(resource.URL, "/")+1]+checksums)}
+		}
+		manifest.Autoupdate.Architecture[arch] = res
+	}
+	return nil
+}
+
+// checksumsName returns the name of the checksums file released along with
+// the archives, or none if there is no such file scoop can read the checksums
+// from, in which case scoop computes them itself.
+func checksumsName(ctx *context.Context) (string, error) {
+	checksum := ctx.Config.Checksum
+	if checksum.Disable || (checksum.Split && checksum.DisableCombined) || checksum.Format == "json" {
+		return "", nil
+	}
+	return tmpl.New(ctx).Apply(checksum.NameTemplate)
+}
+
+func withVersionVar(ctx *context.Context, s string) string {
+	if ctx.Version == "" {
+		return s
+	}
+	return strings.ReplaceAll(s, ctx.Version, "$version")
+}
+
 func binaries(a *artifact.Artifact) []string {
 	// nolint: prealloc
 	var bins []string
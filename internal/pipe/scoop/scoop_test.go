@@ -53,6 +53,28 @@ func TestDefault(t *testing.T) {
 	require.NotEmpty(t, ctx.Config.Scoop.CommitAuthor.Name)
 	require.NotEmpty(t, ctx.Config.Scoop.CommitAuthor.Email)
 	require.NotEmpty(t, ctx.Config.Scoop.CommitMessageTemplate)
+	require.Empty(t, ctx.Config.Scoop.Bucket.Branch)
+}
+
+func TestDefaultPullRequestBranches(t *testing.T) {
+	ctx := context.New(config.Project{
+		Scoop: config.Scoop{
+			PullRequest: config.PullRequest{Enabled: true},
+			Buckets: []config.ScoopBucket{
+				{},
+				{PullRequest: config.PullRequest{Enabled: true}},
+				{
+					Repository:  config.RepoRef{Branch: "main"},
+					PullRequest: config.PullRequest{Enabled: true},
+				},
+			},
+		},
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.Equal(t, "{{ .ProjectName }}-{{ .Version }}", ctx.Config.Scoop.Bucket.Branch)
+	require.Empty(t, ctx.Config.Scoop.Buckets[0].Repository.Branch)
+	require.Equal(t, "{{ .ProjectName }}-{{ .Version }}", ctx.Config.Scoop.Buckets[1].Repository.Branch)
+	require.Equal(t, "main", ctx.Config.Scoop.Buckets[2].Repository.Branch)
 }
 
 func Test_doRun(t *testing.T) {
@@ -876,6 +898,23 @@ func Test_buildManifest(t *testing.T) {
 	}
 }
 
+func TestDataForTemplatedDescription(t *testing.T) {
+	ctx := context.New(config.Project{
+		Scoop: config.Scoop{
+			Description: "{{ .TagSubject }}",
+			URLTemplate: "https://example.com/{{ .ArtifactName }}",
+		},
+	})
+	ctx.Git.TagSubject = "A run pipe test formula"
+	mf, err := dataFor(ctx, nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, "A run pipe test formula", mf.Description)
+
+	ctx.Config.Scoop.Description = "{{ .Nope }"
+	_, err = dataFor(ctx, nil, nil)
+	require.Error(t, err)
+}
+
 func getScoopPipeSkipCtx(folder string) (*context.Context, string) {
 	ctx := &context.Context{
 		Git: context.GitInfo{
@@ -939,6 +978,129 @@ func TestRunPipeScoopWithSkipUpload(t *testing.T) {
 	require.NoError(t, err, "file should exist: "+distFile)
 }
 
+func TestRunPipeMultipleBuckets(t *testing.T) {
+	folder := t.TempDir()
+	ctx, path := getScoopPipeSkipCtx(folder)
+	ctx.Config.Scoop.Folder = "bucket"
+	ctx.Config.Scoop.Buckets = []config.ScoopBucket{
+		{
+			Repository: config.RepoRef{Owner: "me", Name: "Extras"},
+			Folder:     "bucket",
+			PullRequest: config.PullRequest{
+				Enabled: true,
+				Base:    config.RepoRef{Owner: "ScoopInstaller", Name: "Extras", Branch: "master"},
+			},
+		},
+		{
+			Repository: config.RepoRef{Owner: "me", Name: "other-bucket"},
+		},
+	}
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.NoError(t, os.WriteFile(path, []byte("fake"), 0o644))
+
+	cli := client.NewMock()
+	require.NoError(t, doRun(ctx, cli))
+	require.NoError(t, doPublish(ctx, cli))
+
+	content, err := os.ReadFile(filepath.Join(folder, "run-pipe.json"))
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{
+		"bucket/run-pipe.json": string(content),
+		"run-pipe.json":        string(content),
+	}, cli.CreatedFiles)
+	require.Equal(t, []string{"me/Extras@run-pipe-1.0.1"}, cli.CreatedBranches)
+	require.Equal(t, []client.MockPullRequest{{
+		Base:  client.Repo{Owner: "ScoopInstaller", Name: "Extras", Branch: "master"},
+		Head:  client.Repo{Owner: "me", Name: "Extras", Branch: "run-pipe-1.0.1"},
+		Title: "Scoop update for run-pipe version v1.0.1",
+		Body:  "Automated with [GoReleaser](https://goreleaser.com).",
+	}}, cli.PullRequests)
+}
+
+func TestRunPipePullRequestNotSupported(t *testing.T) {
+	folder := t.TempDir()
+	ctx, path := getScoopPipeSkipCtx(folder)
+	ctx.Config.Scoop.PullRequest = config.PullRequest{Enabled: true}
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.NoError(t, os.WriteFile(path, []byte("fake"), 0o644))
+
+	// only the Client methods of the mock, which can't open pull requests.
+	cli := struct{ client.Client }{client.NewMock()}
+	require.NoError(t, doRun(ctx, cli))
+	err := doPublish(ctx, cli)
+	require.ErrorIs(t, err, client.ErrPullRequestNotSupported)
+	require.EqualError(t, err, "scoop.pull_request: pull requests are only supported on GitHub")
+}
+
+func TestAutoupdate(t *testing.T) {
+	folder := t.TempDir()
+	file := filepath.Join(folder, "archive")
+	require.NoError(t, os.WriteFile(file, []byte("lorem ipsum"), 0o644))
+	archives := []*artifact.Artifact{
+		{Name: "foo_1.0.1_windows_amd64.tar.gz", Goos: "windows", Goarch: "amd64", Path: file},
+		{Name: "foo_1.0.1_windows_386.tar.gz", Goos: "windows", Goarch: "386", Path: file},
+	}
+	newCtx := func(tokenType context.TokenType) *context.Context {
+		ctx := context.New(config.Project{
+			ProjectName: "foo",
+			Release: config.Release{
+				GitHub: config.Repo{Owner: "test", Name: "foo"},
+			},
+			GitHubURLs: config.GitHubURLs{Download: "https://github.com/"},
+			Checksum:   config.Checksum{NameTemplate: "{{ .ProjectName }}_{{ .Version }}_checksums.txt"},
+			Scoop: config.Scoop{
+				Autoupdate: config.ScoopAutoupdate{Enabled: true},
+			},
+		})
+		ctx.TokenType = tokenType
+		ctx.Git.CurrentTag = "v1.0.1"
+		ctx.Version = "1.0.1"
+		return ctx
+	}
+
+	for name, prepare := range map[string]func(ctx *context.Context){
+		"github": func(ctx *context.Context) {},
+		"url": func(ctx *context.Context) {
+			ctx.Config.Scoop.Autoupdate.URL = "https://example.com/{{ .ProjectName }}/latest"
+			ctx.Config.Scoop.Autoupdate.Regex = `foo_([\d.]+)_windows`
+		},
+		"no checksums": func(ctx *context.Context) {
+			ctx.Config.Checksum.Disable = true
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			ctx := newCtx(context.TokenTypeGitHub)
+			prepare(ctx)
+			mf, err := dataFor(ctx, client.NewMock(), archives)
+			require.NoError(t, err)
+
+			out, err := doBuildManifest(mf)
+			require.NoError(t, err)
+			golden.RequireEqualJSON(t, out.Bytes())
+		})
+	}
+
+	t.Run("no checkver url", func(t *testing.T) {
+		_, err := dataFor(newCtx(context.TokenTypeGitLab), client.NewMock(), archives)
+		require.Equal(t, ErrNoCheckverURL, err)
+	})
+
+	t.Run("invalid templates", func(t *testing.T) {
+		for name, prepare := range map[string]func(ctx *context.Context){
+			"url":       func(ctx *context.Context) { ctx.Config.Scoop.Autoupdate.URL = "{{ .Nope }" },
+			"download":  func(ctx *context.Context) { ctx.Config.GitHubURLs.Download = "{{ .Nope }" },
+			"checksums": func(ctx *context.Context) { ctx.Config.Checksum.NameTemplate = "{{ .Nope }" },
+		} {
+			t.Run(name, func(t *testing.T) {
+				ctx := newCtx(context.TokenTypeGitHub)
+				prepare(ctx)
+				_, err := dataFor(ctx, client.NewMock(), archives)
+				require.Error(t, err)
+			})
+		}
+	})
+}
+
 func TestWrapInDirectory(t *testing.T) {
 	folder := t.TempDir()
 	file := filepath.Join(folder, "archive")
@@ -1025,4 +1187,15 @@ func TestSkip(t *testing.T) {
 		})
 		require.False(t, Pipe{}.Skip(ctx))
 	})
+
+	t.Run("dont skip buckets only", func(t *testing.T) {
+		ctx := context.New(config.Project{
+			Scoop: config.Scoop{
+				Buckets: []config.ScoopBucket{{
+					Repository: config.RepoRef{Name: "a"},
+				}},
+			},
+		})
+		require.False(t, Pipe{}.Skip(ctx))
+	})
 }
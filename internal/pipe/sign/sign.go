@@ -16,6 +16,7 @@ import (
 	"github.com/goreleaser/goreleaser/internal/logext"
 	"github.com/goreleaser/goreleaser/internal/pipe"
 	"github.com/goreleaser/goreleaser/internal/semerrgroup"
+	"github.com/goreleaser/goreleaser/internal/sigstore"
 	"github.com/goreleaser/goreleaser/internal/tmpl"
 	"github.com/goreleaser/goreleaser/pkg/config"
 	"github.com/goreleaser/goreleaser/pkg/context"
@@ -32,17 +33,47 @@ func (Pipe) Default(ctx *context.Context) error {
 	ids := ids.New("signs")
 	for i := range ctx.Config.Signs {
 		cfg := &ctx.Config.Signs[i]
-		if cfg.Cmd == "" {
-			cfg.Cmd = "gpg"
-		}
 		if cfg.Signature == "" {
 			cfg.Signature = "${artifact}.sig"
+			if cfg.GPG.Enabled && cfg.GPG.Armor {
+				cfg.Signature = "${artifact}.asc"
+			}
 		}
-		if len(cfg.Args) == 0 {
-			cfg.Args = []string{"--output", "$signature", "--detach-sig", "$artifact"}
+		switch {
+		case cfg.Sigstore.Enabled && cfg.GPG.Enabled:
+			return fmt.Errorf("sign %s: sigstore and gpg can't be enabled at the same time", cfg.ID)
+		case cfg.GPG.Enabled:
+			if (cfg.GPG.Key == "") == (cfg.GPG.KeyFile == "") {
+				return fmt.Errorf("sign %s: gpg requires either key or key_file", cfg.ID)
+			}
+		case cfg.Sigstore.Enabled:
+			if cfg.Certificate == "" {
+				cfg.Certificate = "${artifact}.pem"
+			}
+			if cfg.Sigstore.FulcioURL == "" {
+				cfg.Sigstore.FulcioURL = sigstore.DefaultFulcioURL
+			}
+			if cfg.Sigstore.RekorURL == "" {
+				cfg.Sigstore.RekorURL = sigstore.DefaultRekorURL
+			}
+			// the public trusted root can only verify the public instance.
+			if cfg.Sigstore.TrustedRoot == "" &&
+				(cfg.Sigstore.FulcioURL != sigstore.DefaultFulcioURL || cfg.Sigstore.RekorURL != sigstore.DefaultRekorURL) {
+				return fmt.Errorf("sign %s: sigstore requires trusted_root when using another fulcio or rekor instance", cfg.ID)
+			}
+		default:
+			if cfg.Cmd == "" {
+				cfg.Cmd = "gpg"
+			}
+			if len(cfg.Args) == 0 {
+				cfg.Args = []string{"--output", "$signature", "--detach-sig", "$artifact"}
+			}
 		}
 		if cfg.Artifacts == "" {
 			cfg.Artifacts = "none"
+			if ctx.Config.Source.Sign {
+				cfg.Artifacts = "source"
+			}
 		}
 		if cfg.ID == "" {
 			cfg.ID = "default"
@@ -58,45 +89,11 @@ func (Pipe) Run(ctx *context.Context) error {
 	for i := range ctx.Config.Signs {
 		cfg := ctx.Config.Signs[i]
 		g.Go(func() error {
-			var filters []artifact.Filter
-			switch cfg.Artifacts {
-			case "checksum":
-				filters = append(filters, artifact.ByType(artifact.Checksum))
-				if len(cfg.IDs) > 0 {
-					log.Warn("when artifacts is `checksum`, `ids` has no effect. ignoring")
-				}
-			case "source":
-				filters = append(filters, artifact.ByType(artifact.UploadableSourceArchive))
-				if len(cfg.IDs) > 0 {
-					log.Warn("when artifacts is `source`, `ids` has no effect. ignoring")
-				}
-			case "all":
-				filters = append(filters, artifact.Or(
-					artifact.ByType(artifact.UploadableArchive),
-					artifact.ByType(artifact.UploadableBinary),
-					artifact.ByType(artifact.UploadableSourceArchive),
-					artifact.ByType(artifact.Checksum),
-					artifact.ByType(artifact.LinuxPackage),
-					artifact.ByType(artifact.SBOM),
-				))
-			case "archive":
-				filters = append(filters, artifact.ByType(artifact.UploadableArchive))
-			case "binary":
-				filters = append(filters, artifact.ByType(artifact.UploadableBinary))
-			case "sbom":
-				filters = append(filters, artifact.ByType(artifact.SBOM))
-			case "package":
-				filters = append(filters, artifact.ByType(artifact.LinuxPackage))
-			case "none": // TODO(caarlos0): this is not very useful, lets remove it.
-				return pipe.ErrSkipSignEnabled
-			default:
-				return fmt.Errorf("invalid list of artifacts to sign: %s", cfg.Artifacts)
+			filter, err := Filter(ctx, cfg)
+			if err != nil {
+				return err
 			}
-
-			if len(cfg.IDs) > 0 {
-				filters = append(filters, artifact.ByIDs(cfg.IDs...))
-			}
-			return sign(ctx, cfg, ctx.Artifacts.Filter(artifact.And(filters...)).List())
+			return sign(ctx, cfg, ctx.Artifacts.Filter(filter).List())
 		})
 	}
 	if err := g.Wait(); err != nil {
@@ -110,12 +107,94 @@ func (Pipe) Run(ctx *context.Context) error {
 		})
 }
 
+// Filter returns the filter of the artifacts the given config signs.
+func Filter(ctx *context.Context, cfg config.Sign) (artifact.Filter, error) {
+	var filters []artifact.Filter
+	switch cfg.Artifacts {
+	case "checksum":
+		filters = append(filters, artifact.ByType(artifact.Checksum))
+		if len(cfg.IDs) > 0 {
+			log.FromContext(ctx).Warn("when artifacts is `checksum`, `ids` has no effect. ignoring")
+		}
+	case "source":
+		filters = append(filters, artifact.ByType(artifact.UploadableSourceArchive))
+		if len(cfg.IDs) > 0 {
+			log.FromContext(ctx).Warn("when artifacts is `source`, `ids` has no effect. ignoring")
+		}
+	case "all":
+		filters = append(filters, artifact.Or(
+			artifact.ByType(artifact.UploadableArchive),
+			artifact.ByType(artifact.UploadableArchivePart),
+			artifact.ByType(artifact.UploadableBinary),
+			artifact.ByType(artifact.UploadableSourceArchive),
+			artifact.ByType(artifact.Checksum),
+			artifact.ByType(artifact.LinuxPackage),
+			artifact.ByType(artifact.AppImage),
+			artifact.ByType(artifact.MSI),
+			artifact.ByType(artifact.DMG),
+			artifact.ByType(artifact.SBOM),
+			artifact.ByType(artifact.Provenance),
+			artifact.ByType(artifact.Attestation),
+		))
+	case "archive":
+		filters = append(filters, artifact.Or(
+			artifact.ByType(artifact.UploadableArchive),
+			artifact.ByType(artifact.UploadableArchivePart),
+		))
+	case "binary":
+		filters = append(filters, artifact.ByType(artifact.UploadableBinary))
+	case "sbom":
+		filters = append(filters, artifact.ByType(artifact.SBOM))
+	case "package":
+		filters = append(filters, artifact.ByType(artifact.LinuxPackage))
+	case "attestation":
+		filters = append(filters, artifact.ByType(artifact.Attestation))
+	case "msi":
+		filters = append(filters, artifact.ByType(artifact.MSI))
+	case "dmg":
+		filters = append(filters, artifact.ByType(artifact.DMG))
+	case "none": // TODO(caarlos0): this is not very useful, lets remove it.
+		return nil, pipe.ErrSkipSignEnabled
+	default:
+		return nil, fmt.Errorf("invalid list of artifacts to sign: %s", cfg.Artifacts)
+	}
+	if signsSource(ctx, cfg) {
+		filters[0] = artifact.Or(filters[0], artifact.ByType(artifact.UploadableSourceArchive))
+	}
+
+	if len(cfg.IDs) > 0 {
+		filters = append(filters, artifact.ByIDs(cfg.IDs...))
+	}
+	return artifact.And(filters...), nil
+}
+
+// signsSource returns true if the source archive should be signed along with
+// the artifacts of the given config, which would not include it otherwise.
+func signsSource(ctx *context.Context, cfg config.Sign) bool {
+	if !ctx.Config.Source.Sign {
+		return false
+	}
+	switch cfg.Artifacts {
+	case "archive", "binary", "package", "sbom", "attestation":
+		return true
+	default:
+		return false
+	}
+}
+
 func sign(ctx *context.Context, cfg config.Sign, artifacts []*artifact.Artifact) error {
+	if len(artifacts) == 0 {
+		return nil
+	}
+	signer, err := newSigner(ctx, cfg)
+	if err != nil {
+		return fmt.Errorf("sign failed: %w", err)
+	}
 	for _, a := range artifacts {
 		if err := a.Refresh(); err != nil {
 			return err
 		}
-		artifacts, err := signone(ctx, cfg, a)
+		artifacts, err := signone(ctx, cfg, a, signer)
 		if err != nil {
 			return err
 		}
@@ -149,7 +228,7 @@ func tmplPath(ctx *context.Context, env map[string]string, s string) (string, er
 	return relativeToDist(ctx.Config.Dist, result)
 }
 
-func signone(ctx *context.Context, cfg config.Sign, art *artifact.Artifact) ([]*artifact.Artifact, error) {
+func signone(ctx *context.Context, cfg config.Sign, art *artifact.Artifact, signer signer) ([]*artifact.Artifact, error) {
 	env := ctx.Env.Copy()
 	env["artifactName"] = art.Name // shouldn't be used
 	env["artifact"] = art.Path
@@ -176,12 +255,54 @@ func signone(ctx *context.Context, cfg config.Sign, art *artifact.Artifact) ([]*
 	}
 	env["certificate"] = cert
 
+	if signer != nil {
+		if err := signer.sign(ctx, art, name, cert); err != nil {
+			return nil, fmt.Errorf("sign failed: %s: %w", art.Name, err)
+		}
+	} else if err := signCmd(ctx, cfg, art, env); err != nil {
+		return nil, err
+	}
+
+	var result []*artifact.Artifact
+
+	// re-execute template results, using artifact desc as artifact so they eval to the actual needed file desc.
+	env["artifact"] = art.Name
+	name, _ = tmpl.New(ctx).WithEnv(env).Apply(expand(cfg.Signature, env))   // could never error as it passed the previous check
+	cert, _ = tmpl.New(ctx).WithEnv(env).Apply(expand(cfg.Certificate, env)) // could never error as it passed the previous check
+
+	if cfg.Signature != "" {
+		result = append(result, &artifact.Artifact{
+			Type: artifact.Signature,
+			Name: name,
+			Path: env["signature"],
+			Extra: map[string]interface{}{
+				artifact.ExtraID: cfg.ID,
+			},
+		})
+	}
+
+	if cert != "" {
+		result = append(result, &artifact.Artifact{
+			Type: artifact.Certificate,
+			Name: cert,
+			Path: env["certificate"],
+			Extra: map[string]interface{}{
+				artifact.ExtraID: cfg.ID,
+			},
+		})
+	}
+
+	return result, nil
+}
+
+// signCmd signs the artifact running the sign command.
+func signCmd(ctx *context.Context, cfg config.Sign, art *artifact.Artifact, env context.Env) error {
 	// nolint:prealloc
 	var args []string
 	for _, a := range cfg.Args {
 		arg, err := tmpl.New(ctx).WithEnv(env).Apply(expand(a, env))
 		if err != nil {
-			return nil, fmt.Errorf("sign failed: %s: %w", art.Name, err)
+			return fmt.Errorf("sign failed: %s: %w", art.Name, err)
 		}
 		args = append(args, arg)
 	}
@@ -190,13 +311,13 @@ func signone(ctx *context.Context, cfg config.Sign, art *artifact.Artifact) ([]*
 	if cfg.Stdin != nil {
 		s, err := tmpl.New(ctx).WithEnv(env).Apply(expand(*cfg.Stdin, env))
 		if err != nil {
-			return nil, err
+			return err
 		}
 		stdin = strings.NewReader(s)
 	} else if cfg.StdinFile != "" {
 		f, err := os.Open(cfg.StdinFile)
 		if err != nil {
-			return nil, fmt.Errorf("sign failed: cannot open file %s: %w", cfg.StdinFile, err)
+			return fmt.Errorf("sign failed: cannot open file %s: %w", cfg.StdinFile, err)
 		}
 		defer f.Close()
 
@@ -204,10 +325,10 @@ func signone(ctx *context.Context, cfg config.Sign, art *artifact.Artifact) ([]*
 	}
 
 	fields := log.Fields{"cmd": cfg.Cmd, "artifact": art.Name}
-	if name != "" {
+	if name := env["signature"]; name != "" {
 		fields["signature"] = name
 	}
-	if cert != "" {
+	if cert := env["certificate"]; cert != "" {
 		fields["certificate"] = cert
 	}
 
@@ -224,41 +345,39 @@ func signone(ctx *context.Context, cfg config.Sign, art *artifact.Artifact) ([]*
 		cmd.Stdin = stdin
 	}
 	cmd.Env = env.Strings()
-	log.WithFields(fields).Info("signing")
+	log.FromContext(ctx).WithFields(fields).Info("signing")
 	if err := cmd.Run(); err != nil {
-		return nil, fmt.Errorf("sign: %s failed: %w: %s", cfg.Cmd, err, b.String())
+		return fmt.Errorf("sign: %s failed: %w: %s", cfg.Cmd, err, b.String())
 	}
 
-	var result []*artifact.Artifact
+	return nil
+}
 
-	// re-execute template results, using artifact desc as artifact so they eval to the actual needed file desc.
-	env["artifact"] = art.Name
-	name, _ = tmpl.New(ctx).WithEnv(env).Apply(expand(cfg.Signature, env))   // could never error as it passed the previous check
-	cert, _ = tmpl.New(ctx).WithEnv(env).Apply(expand(cfg.Certificate, env)) // could never error as it passed the previous check
+// Names returns the names of the signature and certificate the given sign
+// config creates for the artifact with the given name, as they are released.
+// Either can be empty if the config doesn't create it.
+func Names(ctx *context.Context, cfg config.Sign, name string) (string, string, error) {
+	env := ctx.Env.Copy()
+	env["artifactName"] = name
+	env["artifact"] = name
 
-	if cfg.Signature != "" {
-		result = append(result, &artifact.Artifact{
-			Type: artifact.Signature,
-			Name: name,
-			Path: env["signature"],
-			Extra: map[string]interface{}{
-				artifact.ExtraID: cfg.ID,
-			},
-		})
+	tmplEnv, err := templateEnvS(ctx, cfg.Env)
+	if err != nil {
+		return "", "", err
 	}
-
-	if cert != "" {
-		result = append(result, &artifact.Artifact{
-			Type: artifact.Certificate,
-			Name: cert,
-			Path: env["certificate"],
-			Extra: map[string]interface{}{
-				artifact.ExtraID: cfg.ID,
-			},
-		})
+	for k, v := range context.ToEnv(tmplEnv) {
+		env[k] = v
 	}
 
-	return result, nil
+	signature, err := tmpl.New(ctx).WithEnv(env).Apply(expand(cfg.Signature, env))
+	if err != nil {
+		return "", "", err
+	}
+	cert, err := tmpl.New(ctx).WithEnv(env).Apply(expand(cfg.Certificate, env))
+	if err != nil {
+		return "", "", err
+	}
+	return signature, cert, nil
 }
 
 func expand(s string, env map[string]string) string {
@@ -63,6 +63,129 @@ func TestSignDefault(t *testing.T) {
 	require.Equal(t, ctx.Config.Signs[0].Artifacts, "none")
 }
 
+func TestSignDefaultSource(t *testing.T) {
+	ctx := &context.Context{
+		Config: config.Project{
+			Source: config.Source{Sign: true},
+			Signs:  []config.Sign{{ID: "a"}, {ID: "b", Artifacts: "none"}},
+		},
+	}
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.Equal(t, "source", ctx.Config.Signs[0].Artifacts)
+	require.Equal(t, "none", ctx.Config.Signs[1].Artifacts)
+}
+
+func TestSignDefaultSigstore(t *testing.T) {
+	ctx := &context.Context{
+		Config: config.Project{
+			Signs: []config.Sign{{
+				Sigstore: config.Sigstore{Enabled: true},
+			}},
+		},
+	}
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.Empty(t, ctx.Config.Signs[0].Cmd)
+	require.Empty(t, ctx.Config.Signs[0].Args)
+	require.Equal(t, "${artifact}.sig", ctx.Config.Signs[0].Signature)
+	require.Equal(t, "${artifact}.pem", ctx.Config.Signs[0].Certificate)
+	require.Equal(t, "https://fulcio.sigstore.dev", ctx.Config.Signs[0].Sigstore.FulcioURL)
+	require.Equal(t, "https://rekor.sigstore.dev", ctx.Config.Signs[0].Sigstore.RekorURL)
+}
+
+func TestSignDefaultGPG(t *testing.T) {
+	t.Run("armor", func(t *testing.T) {
+		ctx := &context.Context{
+			Config: config.Project{
+				Signs: []config.Sign{{
+					GPG: config.GPG{Enabled: true, Key: "key", Armor: true},
+				}},
+			},
+		}
+		require.NoError(t, Pipe{}.Default(ctx))
+		require.Empty(t, ctx.Config.Signs[0].Cmd)
+		require.Empty(t, ctx.Config.Signs[0].Args)
+		require.Equal(t, "${artifact}.asc", ctx.Config.Signs[0].Signature)
+	})
+
+	t.Run("no key", func(t *testing.T) {
+		ctx := &context.Context{
+			Config: config.Project{
+				Signs: []config.Sign{{
+					ID:  "default",
+					GPG: config.GPG{Enabled: true},
+				}},
+			},
+		}
+		require.EqualError(t, Pipe{}.Default(ctx), "sign default: gpg requires either key or key_file")
+	})
+
+	t.Run("key and key file", func(t *testing.T) {
+		ctx := &context.Context{
+			Config: config.Project{
+				Signs: []config.Sign{{
+					ID:  "default",
+					GPG: config.GPG{Enabled: true, Key: "key", KeyFile: "key.asc"},
+				}},
+			},
+		}
+		require.EqualError(t, Pipe{}.Default(ctx), "sign default: gpg requires either key or key_file")
+	})
+
+	t.Run("with sigstore", func(t *testing.T) {
+		ctx := &context.Context{
+			Config: config.Project{
+				Signs: []config.Sign{{
+					ID:       "default",
+					GPG:      config.GPG{Enabled: true, Key: "key"},
+					Sigstore: config.Sigstore{Enabled: true},
+				}},
+			},
+		}
+		require.EqualError(t, Pipe{}.Default(ctx), "sign default: sigstore and gpg can't be enabled at the same time")
+	})
+}
+
+func TestSignDefaultSigstoreCustomInstance(t *testing.T) {
+	ctx := &context.Context{
+		Config: config.Project{
+			Signs: []config.Sign{{
+				ID:       "default",
+				Sigstore: config.Sigstore{Enabled: true, FulcioURL: "https://fulcio.example.com"},
+			}},
+		},
+	}
+	require.EqualError(t, Pipe{}.Default(ctx), "sign default: sigstore requires trusted_root when using another fulcio or rekor instance")
+
+	ctx.Config.Signs[0].Sigstore.TrustedRoot = "trusted_root.json"
+	require.NoError(t, Pipe{}.Default(ctx))
+}
+
+func TestSignSigstoreInvalidTrustedRoot(t *testing.T) {
+	folder := t.TempDir()
+	file := filepath.Join(folder, "checksums.txt")
+	require.NoError(t, os.WriteFile(file, []byte("foo"), 0o644))
+	root := filepath.Join(folder, "trusted_root.json")
+	require.NoError(t, os.WriteFile(root, []byte("{}"), 0o644))
+	ctx := context.New(config.Project{})
+	ctx.Config.Signs = []config.Sign{{
+		Artifacts: "checksum",
+		Signature: "${artifact}.sig",
+		Sigstore: config.Sigstore{
+			Enabled:       true,
+			IdentityToken: "a-token",
+			TrustedRoot:   root,
+		},
+	}}
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name: "checksums.txt",
+		Path: file,
+		Type: artifact.Checksum,
+	})
+	err := Pipe{}.Run(ctx)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "sign failed: sigstore: invalid trusted root "+root)
+}
+
 func TestSignDisabled(t *testing.T) {
 	ctx := context.New(config.Project{})
 	ctx.Config.Signs = []config.Sign{
@@ -332,6 +455,21 @@ func TestSignArtifacts(t *testing.T) {
 			signaturePaths: []string{"artifact5.tar.gz.sig"},
 			signatureNames: []string{"artifact5.tar.gz.sig"},
 		},
+		{
+			desc: "sign archives and source",
+			ctx: context.New(
+				config.Project{
+					Source: config.Source{Sign: true},
+					Signs: []config.Sign{
+						{
+							Artifacts: "archive",
+						},
+					},
+				},
+			),
+			signaturePaths: []string{"artifact1.sig", "artifact2.sig", "artifact5.tar.gz.sig"},
+			signatureNames: []string{"artifact1.sig", "artifact2.sig", "artifact5.tar.gz.sig"},
+		},
 		{
 			desc: "sign only source filter by id",
 			ctx: context.New(
@@ -783,3 +921,18 @@ func TestSkip(t *testing.T) {
 		require.False(t, Pipe{}.Skip(ctx))
 	})
 }
+
+func TestNames(t *testing.T) {
+	ctx := context.New(config.Project{ProjectName: "foo"})
+	sig, cert, err := Names(ctx, config.Sign{
+		Signature:   "${artifact}_{{ .ProjectName }}.sig",
+		Certificate: "${artifactName}.${EXT}",
+		Env:         []string{"EXT=pem"},
+	}, "bar.tar.gz")
+	require.NoError(t, err)
+	require.Equal(t, "bar.tar.gz_foo.sig", sig)
+	require.Equal(t, "bar.tar.gz.pem", cert)
+
+	_, _, err = Names(ctx, config.Sign{Signature: "{{ .Foo }"}, "bar.tar.gz")
+	require.Error(t, err)
+}
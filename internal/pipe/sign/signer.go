@@ -0,0 +1,155 @@
+package sign
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/sigstore"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+)
+
+// signer signs artifacts in-process, instead of running the sign command.
+type signer interface {
+	// sign signs the artifact, writing the signature and certificate, if
+	// any, to the given paths.
+	sign(ctx *context.Context, art *artifact.Artifact, signature, cert string) error
+}
+
+// newSigner returns the in-process signer of the given config, or nil if it
+// uses the sign command.
+func newSigner(ctx *context.Context, cfg config.Sign) (signer, error) {
+	switch {
+	case cfg.Sigstore.Enabled:
+		return newKeylessSigner(ctx, cfg.Sigstore)
+	case cfg.GPG.Enabled:
+		return newGPGSigner(ctx, cfg.GPG)
+	default:
+		return nil, nil
+	}
+}
+
+// keylessSigner signs with sigstore.
+// The ephemeral key is certified once and used for all artifacts.
+type keylessSigner struct {
+	signer *sigstore.Signer
+}
+
+func newKeylessSigner(ctx *context.Context, conf config.Sigstore) (signer, error) {
+	token, err := tmpl.New(ctx).Apply(conf.IdentityToken)
+	if err != nil {
+		return nil, err
+	}
+	s, err := sigstore.NewSigner(ctx, conf.FulcioURL, conf.RekorURL, token, conf.TrustedRoot)
+	if err != nil {
+		return nil, err
+	}
+	return keylessSigner{signer: s}, nil
+}
+
+func (s keylessSigner) sign(ctx *context.Context, art *artifact.Artifact, signature, cert string) error {
+	fields := log.Fields{"artifact": art.Name, "signature": signature}
+	if cert != "" {
+		fields["certificate"] = cert
+	}
+	log.FromContext(ctx).WithFields(fields).Info("signing with sigstore")
+	sig, pem, err := s.signer.Sign(ctx, art.Path)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(signature, sig, 0o644); err != nil { //nolint: gosec
+		return err
+	}
+	if cert == "" {
+		return nil
+	}
+	return os.WriteFile(cert, pem, 0o644) //nolint: gosec
+}
+
+// gpgSigner signs with an armored gpg private key, like gpg --detach-sig
+// does.
+type gpgSigner struct {
+	entity *openpgp.Entity
+	armor  bool
+}
+
+func newGPGSigner(ctx *context.Context, conf config.GPG) (signer, error) {
+	t := tmpl.New(ctx)
+	key, err := t.Apply(conf.Key)
+	if err != nil {
+		return nil, fmt.Errorf("gpg: failed to apply template to key: %w", err)
+	}
+	if conf.KeyFile != "" {
+		path, err := t.Apply(conf.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("gpg: failed to apply template to key_file: %w", err)
+		}
+		bts, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("gpg: failed to read key: %w", err)
+		}
+		key = string(bts)
+	}
+	passphrase, err := t.Apply(conf.Passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("gpg: failed to apply template to passphrase: %w", err)
+	}
+
+	entities, err := openpgp.ReadArmoredKeyRing(strings.NewReader(key))
+	if err != nil {
+		return nil, fmt.Errorf("gpg: invalid key: %w", err)
+	}
+	for _, entity := range entities {
+		if entity.PrivateKey == nil {
+			continue
+		}
+		if err := decrypt(entity, []byte(passphrase)); err != nil {
+			return nil, err
+		}
+		return gpgSigner{entity: entity, armor: conf.Armor}, nil
+	}
+	return nil, errors.New("gpg: key has no private key")
+}
+
+// decrypt decrypts the private key and subkeys of the given entity.
+func decrypt(entity *openpgp.Entity, passphrase []byte) error {
+	if entity.PrivateKey.Encrypted {
+		if err := entity.PrivateKey.Decrypt(passphrase); err != nil {
+			return fmt.Errorf("gpg: failed to decrypt key: %w", err)
+		}
+	}
+	for _, sub := range entity.Subkeys {
+		if sub.PrivateKey != nil && sub.PrivateKey.Encrypted {
+			if err := sub.PrivateKey.Decrypt(passphrase); err != nil {
+				return fmt.Errorf("gpg: failed to decrypt subkey: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+func (s gpgSigner) sign(ctx *context.Context, art *artifact.Artifact, signature, _ string) error {
+	log.FromContext(ctx).WithField("artifact", art.Name).WithField("signature", signature).Info("signing with gpg")
+	f, err := os.Open(art.Path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	detachSign := openpgp.DetachSign
+	if s.armor {
+		detachSign = openpgp.ArmoredDetachSign
+	}
+	var b bytes.Buffer
+	if err := detachSign(&b, s.entity, f, nil); err != nil {
+		return fmt.Errorf("gpg: %w", err)
+	}
+	return os.WriteFile(signature, b.Bytes(), 0o644) //nolint: gosec
+}
@@ -0,0 +1,122 @@
+package sign
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+// gpgKey creates a new key, returning it and its armored private key,
+// encrypted with the passphrase if not empty.
+func gpgKey(tb testing.TB, passphrase string) (*openpgp.Entity, string) {
+	tb.Helper()
+	entity, err := openpgp.NewEntity("goreleaser", "", "test@goreleaser.com", nil)
+	require.NoError(tb, err)
+	if passphrase != "" {
+		require.NoError(tb, entity.PrivateKey.Encrypt([]byte(passphrase)))
+		for _, sub := range entity.Subkeys {
+			require.NoError(tb, sub.PrivateKey.Encrypt([]byte(passphrase)))
+		}
+	}
+
+	var b bytes.Buffer
+	w, err := armor.Encode(&b, openpgp.PrivateKeyType, nil)
+	require.NoError(tb, err)
+	require.NoError(tb, entity.SerializePrivateWithoutSigning(w, nil))
+	require.NoError(tb, w.Close())
+	return entity, b.String()
+}
+
+func TestSignGPG(t *testing.T) {
+	entity, key := gpgKey(t, "")
+	keyFile := filepath.Join(t.TempDir(), "key.asc")
+	require.NoError(t, os.WriteFile(keyFile, []byte(key), 0o600))
+	_, encrypted := gpgKey(t, "secret")
+
+	for name, conf := range map[string]config.GPG{
+		"key":       {Enabled: true, Key: "{{ .Env.GPG_KEY }}"},
+		"key file":  {Enabled: true, KeyFile: keyFile},
+		"armor":     {Enabled: true, Key: key, Armor: true},
+		"encrypted": {Enabled: true, Key: encrypted, Passphrase: "{{ .Env.GPG_PASSPHRASE }}"},
+	} {
+		conf := conf
+		t.Run(name, func(t *testing.T) {
+			folder := t.TempDir()
+			file := filepath.Join(folder, "checksums.txt")
+			require.NoError(t, os.WriteFile(file, []byte("foo"), 0o644))
+
+			ctx := context.New(config.Project{})
+			ctx.Config.Dist = folder
+			ctx.Env["GPG_KEY"] = key
+			ctx.Env["GPG_PASSPHRASE"] = "secret"
+			ctx.Config.Signs = []config.Sign{{
+				Artifacts: "checksum",
+				GPG:       conf,
+			}}
+			ctx.Artifacts.Add(&artifact.Artifact{
+				Name: "checksums.txt",
+				Path: file,
+				Type: artifact.Checksum,
+			})
+			require.NoError(t, Pipe{}.Default(ctx))
+			require.NoError(t, Pipe{}.Run(ctx))
+
+			sigs := ctx.Artifacts.Filter(artifact.ByType(artifact.Signature)).List()
+			require.Len(t, sigs, 1)
+			ext := ".sig"
+			if conf.Armor {
+				ext = ".asc"
+			}
+			require.Equal(t, "checksums.txt"+ext, sigs[0].Name)
+
+			sig, err := os.Open(sigs[0].Path)
+			require.NoError(t, err)
+			defer sig.Close()
+
+			keyring := openpgp.EntityList{entity}
+			if conf.Passphrase != "" {
+				keyring, err = openpgp.ReadArmoredKeyRing(bytes.NewBufferString(encrypted))
+				require.NoError(t, err)
+			}
+			check := openpgp.CheckDetachedSignature
+			if conf.Armor {
+				check = openpgp.CheckArmoredDetachedSignature
+			}
+			_, err = check(keyring, bytes.NewBufferString("foo"), sig, nil)
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestNewGPGSignerErrors(t *testing.T) {
+	_, encrypted := gpgKey(t, "secret")
+	ctx := context.New(config.Project{})
+
+	t.Run("invalid key", func(t *testing.T) {
+		_, err := newGPGSigner(ctx, config.GPG{Key: "nope"})
+		require.Error(t, err)
+	})
+
+	t.Run("missing key file", func(t *testing.T) {
+		_, err := newGPGSigner(ctx, config.GPG{KeyFile: filepath.Join(t.TempDir(), "nope.asc")})
+		require.ErrorIs(t, err, os.ErrNotExist)
+	})
+
+	t.Run("wrong passphrase", func(t *testing.T) {
+		_, err := newGPGSigner(ctx, config.GPG{Key: encrypted, Passphrase: "nope"})
+		require.Error(t, err)
+	})
+
+	t.Run("invalid template", func(t *testing.T) {
+		_, err := newGPGSigner(ctx, config.GPG{Key: "{{ .Nope }"})
+		require.Error(t, err)
+	})
+}
@@ -45,7 +45,7 @@ func (Pipe) Announce(ctx *context.Context) error {
 		return fmt.Errorf("announce: failed to announce to slack: %w", err)
 	}
 
-	log.Infof("posting: '%s'", msg)
+	log.FromContext(ctx).Infof("posting: '%s'", msg)
 
 	wm := &slack.WebhookMessage{
 		Username:  ctx.Config.Announce.Slack.Username,
@@ -83,7 +83,7 @@ func (Pipe) Announce(ctx *context.Context) error {
 		return fmt.Errorf("announce: failed to announce to SMTP: %w", err)
 	}
 
-	log.Infof("announce: The mail has been send from %s to %s\n", ctx.Config.Announce.SMTP.From, receivers)
+	log.FromContext(ctx).Infof("announce: The mail has been send from %s to %s\n", ctx.Config.Announce.SMTP.From, receivers)
 
 	return nil
 }
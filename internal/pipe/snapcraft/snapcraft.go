@@ -93,6 +93,9 @@ func (Pipe) Default(ctx *context.Context) error {
 		}
 		if len(snap.Builds) == 0 {
 			for _, b := range ctx.Config.Builds {
+				if b.Variant != nil {
+					continue
+				}
 				snap.Builds = append(snap.Builds, b.ID)
 			}
 		}
@@ -137,7 +140,7 @@ func doRun(ctx *context.Context, snap config.Snapcraft) error {
 	).GroupByPlatform() {
 		arch := linuxArch(platform)
 		if !isValidArch(arch) {
-			log.WithField("arch", arch).Warn("ignored unsupported arch")
+			log.FromContext(ctx).WithField("arch", arch).Warn("ignored unsupported arch")
 			continue
 		}
 		binaries := binaries
@@ -175,7 +178,7 @@ func (Pipe) Publish(ctx *context.Context) error {
 }
 
 func create(ctx *context.Context, snap config.Snapcraft, arch string, binaries []*artifact.Artifact) error {
-	log := log.WithField("arch", arch)
+	log := log.FromContext(ctx).WithField("arch", arch)
 	folder, err := tmpl.New(ctx).
 		WithArtifact(binaries[0], snap.Replacements).
 		Apply(snap.NameTemplate)
@@ -353,7 +356,7 @@ const (
 )
 
 func push(ctx *context.Context, snap *artifact.Artifact) error {
-	log := log.WithField("snap", snap.Name)
+	log := log.FromContext(ctx).WithField("snap", snap.Name)
 	releases := snap.Extra[releasesExtra].([]string)
 	/* #nosec */
 	cmd := exec.CommandContext(ctx, "snapcraft", "upload", "--release="+strings.Join(releases, ","), snap.Path)
@@ -32,6 +32,6 @@ func (Pipe) Run(ctx *context.Context) error {
 		return fmt.Errorf("empty snapshot name")
 	}
 	ctx.Version = name
-	log.WithField("version", ctx.Version).Infof("building snapshot...")
+	log.FromContext(ctx).WithField("version", ctx.Version).Infof("building snapshot...")
 	return nil
 }
@@ -2,11 +2,14 @@
 package sourcearchive
 
 import (
+	"fmt"
 	"path/filepath"
+	"strings"
 
 	"github.com/apex/log"
 	"github.com/goreleaser/goreleaser/internal/artifact"
 	"github.com/goreleaser/goreleaser/internal/git"
+	"github.com/goreleaser/goreleaser/internal/gitignore"
 	"github.com/goreleaser/goreleaser/internal/tmpl"
 	"github.com/goreleaser/goreleaser/pkg/context"
 )
@@ -30,7 +33,7 @@ func (Pipe) Run(ctx *context.Context) (err error) {
 	}
 	filename := name + "." + ctx.Config.Source.Format
 	path := filepath.Join(ctx.Config.Dist, filename)
-	log.WithField("file", filename).Info("creating source archive")
+	log.FromContext(ctx).WithField("file", filename).Info("creating source archive")
 	args := []string{
 		"archive",
 		"-o", path,
@@ -43,8 +46,20 @@ func (Pipe) Run(ctx *context.Context) (err error) {
 		args = append(args, "--prefix", prefix)
 	}
 	args = append(args, ctx.Git.FullCommit)
+	if len(ctx.Config.Source.Excludes) > 0 {
+		excludes, err := excludedPaths(ctx.Git.FullCommit, ctx.Config.Source.Excludes)
+		if err != nil {
+			return err
+		}
+		if len(excludes) > 0 {
+			args = append(args, "--", ".")
+			for _, path := range excludes {
+				args = append(args, ":(top,exclude,literal)"+path)
+			}
+		}
+	}
 	out, err := git.Clean(git.Run(args...))
-	log.Debug(out)
+	log.FromContext(ctx).Debug(out)
 	ctx.Artifacts.Add(&artifact.Artifact{
 		Type: artifact.UploadableSourceArchive,
 		Name: filename,
@@ -56,6 +71,42 @@ func (Pipe) Run(ctx *context.Context) (err error) {
 	return err
 }
 
+// excludedPaths returns the files and directories of the given commit that
+// match the given gitignore-style patterns. Files inside an excluded
+// directory are not listed, as excluding the directory is enough.
+func excludedPaths(commit string, patterns []string) ([]string, error) {
+	matcher, err := gitignore.New(patterns...)
+	if err != nil {
+		return nil, fmt.Errorf("source archive: %w", err)
+	}
+	out, err := git.Run("ls-tree", "-r", "-t", "-z", commit)
+	if err != nil {
+		return nil, fmt.Errorf("source archive: failed to list files: %w", err)
+	}
+	var result []string
+	var excludedDir string
+	for _, entry := range strings.Split(out, "\x00") {
+		// entries look like "<mode> <type> <object>\t<path>"
+		parts := strings.SplitN(entry, "\t", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		path := parts[1]
+		if excludedDir != "" && strings.HasPrefix(path, excludedDir+"/") {
+			continue
+		}
+		isDir := strings.Contains(parts[0], " tree ")
+		if !matcher.Match(path, isDir) {
+			continue
+		}
+		if isDir {
+			excludedDir = path
+		}
+		result = append(result, path)
+	}
+	return result, nil
+}
+
 // Default sets the pipe defaults.
 func (Pipe) Default(ctx *context.Context) error {
 	archive := &ctx.Config.Source
@@ -1,6 +1,9 @@
 package sourcearchive
 
 import (
+	"archive/tar"
+	"errors"
+	"io"
 	"os"
 	"path/filepath"
 	"testing"
@@ -93,3 +96,89 @@ func TestSkip(t *testing.T) {
 		require.False(t, Pipe{}.Skip(ctx))
 	})
 }
+
+func TestArchiveExcludes(t *testing.T) {
+	testlib.Mktmp(t)
+	require.NoError(t, os.Mkdir("dist", 0o744))
+
+	testlib.GitInit(t)
+	for _, path := range []string{
+		"main.go",
+		"README.md",
+		"docs/README.md",
+		"docs/index.md",
+		"web/node_modules/react/index.js",
+		"web/app.js",
+		"pkg/testdata/fixture.txt",
+	} {
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte("fake"), 0o644))
+	}
+	testlib.GitAdd(t)
+	testlib.GitCommit(t, "feat: first")
+
+	ctx := context.New(config.Project{
+		ProjectName: "foo",
+		Dist:        "dist",
+		Source: config.Source{
+			Format:  "tar",
+			Enabled: true,
+			Excludes: []string{
+				"node_modules/",
+				"testdata",
+				"*.md",
+				"!/docs/README.md",
+			},
+		},
+	})
+	ctx.Git.FullCommit = "HEAD"
+	ctx.Version = "1.0.0"
+
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.NoError(t, Pipe{}.Run(ctx))
+
+	require.ElementsMatch(t, []string{
+		"main.go",
+		"docs/",
+		"docs/README.md",
+		"web/",
+		"web/app.js",
+	}, tarFiles(t, "dist/foo-1.0.0.tar"))
+}
+
+func TestArchiveInvalidExclude(t *testing.T) {
+	testlib.Mktmp(t)
+	testlib.GitInit(t)
+	ctx := context.New(config.Project{
+		Source: config.Source{
+			Enabled:  true,
+			Excludes: []string{"[z-a]"},
+		},
+	})
+	ctx.Git.FullCommit = "HEAD"
+	require.NoError(t, Pipe{}.Default(ctx))
+	err := Pipe{}.Run(ctx)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "source archive: invalid pattern")
+}
+
+func tarFiles(tb testing.TB, path string) []string {
+	tb.Helper()
+	f, err := os.Open(path)
+	require.NoError(tb, err)
+	defer f.Close()
+	var result []string
+	r := tar.NewReader(f)
+	for {
+		h, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(tb, err)
+		if h.Typeflag == tar.TypeXGlobalHeader {
+			continue
+		}
+		result = append(result, h.Name)
+	}
+	return result
+}
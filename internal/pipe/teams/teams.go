@@ -58,7 +58,7 @@ func (p Pipe) Announce(ctx *context.Context) error {
 		return fmt.Errorf("announce: failed to announce to teams: %w", err)
 	}
 
-	log.Infof("posting: '%s'", msg)
+	log.FromContext(ctx).Infof("posting: '%s'", msg)
 
 	client := goteamsnotify.NewClient()
 	msgCard := goteamsnotify.NewMessageCard()
@@ -39,7 +39,7 @@ func (Pipe) Announce(ctx *context.Context) error {
 		return fmt.Errorf("announce: failed to announce to telegram: %w", err)
 	}
 
-	log.Infof("posting: '%s'", msg)
+	log.FromContext(ctx).Infof("posting: '%s'", msg)
 	bot, err := api.NewBotAPI(cfg.ConsumerToken)
 	if err != nil {
 		return fmt.Errorf("announce: failed to announce to telegram: %w", err)
@@ -50,6 +50,6 @@ func (Pipe) Announce(ctx *context.Context) error {
 	if err != nil {
 		return fmt.Errorf("announce: failed to announce to telegram: %w", err)
 	}
-	log.Debug("message sent")
+	log.FromContext(ctx).Debug("message sent")
 	return nil
 }
@@ -0,0 +1,56 @@
+package termux
+
+type templateData struct {
+	Name         string
+	Description  string
+	Homepage     string
+	License      string
+	Maintainer   string
+	Version      string
+	Dependencies []string
+	Platforms    []platform
+}
+
+type platform struct {
+	Arch   string
+	URL    string
+	SHA256 string
+}
+
+// buildTemplate is the build.sh of the termux package, which installs the
+// termux deb of the release matching the architecture being built.
+const buildTemplate = `# This file was generated by GoReleaser. DO NOT EDIT.
+TERMUX_PKG_HOMEPAGE="{{ .Homepage }}"
+TERMUX_PKG_DESCRIPTION="{{ .Description }}"
+TERMUX_PKG_LICENSE="{{ .License }}"
+TERMUX_PKG_MAINTAINER="{{ .Maintainer }}"
+TERMUX_PKG_VERSION="{{ .Version }}"
+{{- with .Dependencies }}
+TERMUX_PKG_DEPENDS="{{ join . ", " }}"
+{{- end }}
+TERMUX_PKG_SKIP_SRC_EXTRACT=true
+TERMUX_PKG_BUILD_IN_SRC=true
+
+termux_step_get_source() {
+	local url sha256
+	case "$TERMUX_ARCH" in
+	{{- range .Platforms }}
+		{{ .Arch }})
+			url="{{ .URL }}"
+			sha256="{{ .SHA256 }}"
+			;;
+	{{- end }}
+		*)
+			termux_error_exit "{{ .Name }} is not available for $TERMUX_ARCH"
+			;;
+	esac
+	mkdir -p "$TERMUX_PKG_SRCDIR"
+	termux_download "$url" "$TERMUX_PKG_CACHEDIR/{{ .Name }}_${TERMUX_ARCH}.deb" "$sha256"
+}
+
+termux_step_make_install() {
+	cd "$TERMUX_PKG_SRCDIR"
+	ar x "$TERMUX_PKG_CACHEDIR/{{ .Name }}_${TERMUX_ARCH}.deb" data.tar.gz
+	tar -xzf data.tar.gz -C /
+}
+`
@@ -0,0 +1,332 @@
+// Package termux implements the Pipe, generating the build.sh of a termux
+// package from the termux debs of the release, and pushing it to a termux
+// packages repository, optionally opening a pull request.
+package termux
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/client"
+	"github.com/goreleaser/goreleaser/internal/commitauthor"
+	"github.com/goreleaser/goreleaser/internal/pipe"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+)
+
+const termuxConfigExtra = "TermuxConfig"
+
+var (
+	// ErrNoPackagesFound happens when no termux debs are found.
+	ErrNoPackagesFound = errors.New("no termux.deb packages found")
+
+	// ErrMultiplePackagesSameArch happens when the config yields multiple
+	// termux debs for the same architecture.
+	ErrMultiplePackagesSameArch = errors.New("one termux package can handle only one deb per architecture. Consider using ids in the termux section")
+)
+
+// arches maps go architectures to the termux ones.
+var arches = map[string]string{
+	"386":   "i686",
+	"amd64": "x86_64",
+	"arm64": "aarch64",
+	"arm5":  "arm",
+	"arm6":  "arm",
+	"arm7":  "arm",
+}
+
+// Pipe for termux packages.
+type Pipe struct{}
+
+func (Pipe) String() string                 { return "termux packages" }
+func (Pipe) Skip(ctx *context.Context) bool { return len(ctx.Config.Termux) == 0 }
+
+func (Pipe) Default(ctx *context.Context) error {
+	for i := range ctx.Config.Termux {
+		termux := &ctx.Config.Termux[i]
+
+		termux.CommitAuthor = commitauthor.Default(termux.CommitAuthor)
+		if termux.CommitMessageTemplate == "" {
+			termux.CommitMessageTemplate = "{{ .ProjectName }}: update to {{ .Version }}"
+		}
+		if termux.Name == "" {
+			termux.Name = ctx.Config.ProjectName
+		}
+		if err := commitauthor.CheckSigning(ctx, termux.CommitAuthor); err != nil {
+			return fmt.Errorf("termux %s: %w", termux.Name, err)
+		}
+		if termux.PullRequest.Enabled && termux.Repository.Branch == "" {
+			termux.Repository.Branch = "{{ .ProjectName }}-{{ .Version }}"
+		}
+	}
+	return nil
+}
+
+func (Pipe) Run(ctx *context.Context) error {
+	cli, err := client.New(ctx)
+	if err != nil {
+		return err
+	}
+	return runAll(ctx, cli)
+}
+
+// Publish the termux build scripts.
+func (Pipe) Publish(ctx *context.Context) error {
+	cli, err := client.New(ctx)
+	if err != nil {
+		return err
+	}
+	return publishAll(ctx, cli)
+}
+
+func runAll(ctx *context.Context, cli client.Client) error {
+	for _, termux := range ctx.Config.Termux {
+		if err := doRun(ctx, termux, cli); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func publishAll(ctx *context.Context, cli client.Client) error {
+	skips := pipe.SkipMemento{}
+	for _, script := range ctx.Artifacts.Filter(artifact.ByType(artifact.TermuxBuildScript)).List() {
+		err := doPublish(ctx, script, cli)
+		if err != nil && pipe.IsSkip(err) {
+			skips.Remember(err)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return skips.Evaluate()
+}
+
+func doRun(ctx *context.Context, termux config.Termux, cl client.Client) error {
+	if termux.Repository.Name == "" {
+		return pipe.Skip("termux.repository.name is not set")
+	}
+
+	filters := []artifact.Filter{
+		artifact.ByType(artifact.LinuxPackage),
+		artifact.ByFormats("termux.deb"),
+	}
+	if len(termux.IDs) > 0 {
+		filters = append(filters, artifact.ByIDs(termux.IDs...))
+	}
+	debs := ctx.Artifacts.Filter(artifact.And(filters...)).List()
+	if len(debs) == 0 {
+		return ErrNoPackagesFound
+	}
+
+	t := tmpl.New(ctx)
+	for _, field := range []*string{
+		&termux.Name,
+		&termux.Path,
+		&termux.Repository.Owner,
+		&termux.Repository.Name,
+		&termux.Repository.Branch,
+		&termux.PullRequest.Base.Owner,
+		&termux.PullRequest.Base.Name,
+		&termux.PullRequest.Base.Branch,
+		&termux.SkipUpload,
+	} {
+		applied, err := t.Apply(*field)
+		if err != nil {
+			return err
+		}
+		*field = applied
+	}
+	if termux.Path == "" {
+		termux.Path = path.Join("packages", termux.Name, "build.sh")
+	}
+
+	content, err := buildScript(ctx, termux, cl, debs)
+	if err != nil {
+		return err
+	}
+
+	filename := termux.Name + ".termux.sh"
+	scriptPath := filepath.Join(ctx.Config.Dist, filename)
+	log.FromContext(ctx).WithField("build.sh", scriptPath).Info("writing")
+	if err := os.WriteFile(scriptPath, []byte(content), 0o644); err != nil { //nolint: gosec
+		return fmt.Errorf("failed to write termux build script: %w", err)
+	}
+
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name: filename,
+		Path: scriptPath,
+		Type: artifact.TermuxBuildScript,
+		Extra: map[string]interface{}{
+			termuxConfigExtra: termux,
+		},
+	})
+	return nil
+}
+
+func doPublish(ctx *context.Context, script *artifact.Artifact, cl client.Client) error {
+	termux := script.Extra[termuxConfigExtra].(config.Termux)
+	var err error
+	cl, err = client.NewIfToken(ctx, cl, termux.Repository.Token)
+	if err != nil {
+		return err
+	}
+
+	if strings.TrimSpace(termux.SkipUpload) == "true" {
+		return pipe.Skip("termux.skip_upload is set")
+	}
+
+	if strings.TrimSpace(termux.SkipUpload) == "auto" && ctx.Semver.Prerelease != "" {
+		return pipe.Skip("prerelease detected with 'auto' upload, skipping termux publish")
+	}
+
+	repo := client.RepoFromRef(termux.Repository)
+	var opener client.PullRequestOpener
+	if termux.PullRequest.Enabled {
+		var ok bool
+		opener, ok = cl.(client.PullRequestOpener)
+		if !ok {
+			return fmt.Errorf("termux.pull_request: %w", client.ErrPullRequestNotSupported)
+		}
+		if err := opener.CreateBranch(ctx, repo); err != nil {
+			return err
+		}
+	}
+
+	log.FromContext(ctx).WithField("build.sh", termux.Path).
+		WithField("repo", repo.String()).
+		Info("pushing")
+
+	msg, err := tmpl.New(ctx).Apply(termux.CommitMessageTemplate)
+	if err != nil {
+		return err
+	}
+
+	author, err := commitauthor.Get(ctx, termux.CommitAuthor)
+	if err != nil {
+		return err
+	}
+
+	content, err := os.ReadFile(script.Path)
+	if err != nil {
+		return err
+	}
+
+	if err := cl.CreateFile(ctx, author, repo, content, termux.Path, msg); err != nil {
+		return err
+	}
+
+	if opener == nil {
+		return nil
+	}
+	base := client.RepoFromRef(termux.PullRequest.Base)
+	if base.Name == "" {
+		base = client.Repo{Owner: repo.Owner, Name: repo.Name}
+	}
+	url, err := opener.OpenPullRequest(ctx, base, repo, msg, "Automated with [GoReleaser](https://goreleaser.com).")
+	if err != nil {
+		return err
+	}
+	log.FromContext(ctx).WithField("url", url).Info("opened pull request")
+	return nil
+}
+
+func buildScript(ctx *context.Context, termux config.Termux, cl client.Client, debs []*artifact.Artifact) (string, error) {
+	data, err := dataFor(ctx, termux, cl, debs)
+	if err != nil {
+		return "", err
+	}
+	t, err := template.New(data.Name).Funcs(template.FuncMap{
+		"join": strings.Join,
+	}).Parse(buildTemplate)
+	if err != nil {
+		return "", err
+	}
+	var out bytes.Buffer
+	if err := t.Execute(&out, data); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
+
+func dataFor(ctx *context.Context, cfg config.Termux, cl client.Client, debs []*artifact.Artifact) (templateData, error) {
+	t := tmpl.New(ctx)
+	result := templateData{
+		Name:         cfg.Name,
+		Version:      ctx.Version,
+		License:      escape(cfg.License),
+		Dependencies: cfg.Dependencies,
+	}
+	for _, field := range []struct {
+		in  string
+		out *string
+	}{
+		{cfg.Description, &result.Description},
+		{cfg.Homepage, &result.Homepage},
+		{cfg.Maintainer, &result.Maintainer},
+	} {
+		applied, err := t.Apply(field.in)
+		if err != nil {
+			return result, err
+		}
+		*field.out = escape(applied)
+	}
+
+	if cfg.URLTemplate == "" {
+		url, err := cl.ReleaseURLTemplate(ctx)
+		if err != nil {
+			return result, err
+		}
+		cfg.URLTemplate = url
+	}
+
+	seen := map[string]bool{}
+	for _, deb := range debs {
+		arch := arches[deb.Goarch+deb.Goarm]
+		if arch == "" {
+			continue
+		}
+		if seen[arch] {
+			return result, ErrMultiplePackagesSameArch
+		}
+		seen[arch] = true
+		sum, err := deb.Checksum("sha256")
+		if err != nil {
+			return result, err
+		}
+		url, err := t.WithArtifact(deb, map[string]string{}).Apply(cfg.URLTemplate)
+		if err != nil {
+			return result, err
+		}
+		result.Platforms = append(result.Platforms, platform{
+			Arch:   arch,
+			URL:    url,
+			SHA256: sum,
+		})
+	}
+	sort.Slice(result.Platforms, func(i, j int) bool {
+		return result.Platforms[i].Arch < result.Platforms[j].Arch
+	})
+	return result, nil
+}
+
+// escape escapes the given string to be used inside double quotes in bash.
+func escape(s string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		`"`, `\"`,
+		"$", `\$`,
+		"`", "\\`",
+	).Replace(strings.TrimSpace(s))
+}
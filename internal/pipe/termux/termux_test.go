@@ -0,0 +1,300 @@
+package termux
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/client"
+	"github.com/goreleaser/goreleaser/internal/golden"
+	"github.com/goreleaser/goreleaser/internal/testlib"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDescription(t *testing.T) {
+	require.NotEmpty(t, Pipe{}.String())
+}
+
+func TestSkip(t *testing.T) {
+	require.True(t, Pipe{}.Skip(context.New(config.Project{})))
+	require.False(t, Pipe{}.Skip(context.New(config.Project{
+		Termux: []config.Termux{{}},
+	})))
+}
+
+func TestDefault(t *testing.T) {
+	ctx := context.New(config.Project{
+		ProjectName: "myproject",
+		Termux: []config.Termux{
+			{},
+			{PullRequest: config.PullRequest{Enabled: true}},
+		},
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	termux := ctx.Config.Termux[0]
+	require.Equal(t, "myproject", termux.Name)
+	require.Empty(t, termux.Repository.Branch)
+	require.NotEmpty(t, termux.CommitAuthor.Name)
+	require.NotEmpty(t, termux.CommitAuthor.Email)
+	require.NotEmpty(t, termux.CommitMessageTemplate)
+	require.Equal(t, "{{ .ProjectName }}-{{ .Version }}", ctx.Config.Termux[1].Repository.Branch)
+}
+
+func TestRunPipe(t *testing.T) {
+	for name, tt := range map[string]struct {
+		prepare func(ctx *context.Context)
+		path    string
+	}{
+		"default": {
+			prepare: func(ctx *context.Context) {},
+			path:    "packages/default/build.sh",
+		},
+		"custom": {
+			prepare: func(ctx *context.Context) {
+				termux := &ctx.Config.Termux[0]
+				termux.Path = "tur/{{ .ProjectName }}/build.sh"
+				termux.URLTemplate = "https://example.com/{{ .Tag }}/{{ .ArtifactName }}"
+				termux.Description = `A "termux" package costing ${{ .Env.PRICE }}`
+				termux.Maintainer = "Foo <foo@example.com>"
+				termux.Dependencies = []string{"git", "openssh"}
+			},
+			path: "tur/custom/build.sh",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			folder := t.TempDir()
+			ctx := context.New(config.Project{
+				Dist:        folder,
+				ProjectName: name,
+				Env:         []string{"PRICE=0"},
+				Termux: []config.Termux{{
+					Name:        name,
+					IDs:         []string{"foo"},
+					Description: "A foo package",
+					Homepage:    "https://goreleaser.com",
+					License:     "MIT",
+					Repository: config.RepoRef{
+						Owner: "foo",
+						Name:  "termux-packages",
+					},
+				}},
+			})
+			ctx.Git.CurrentTag = "v1.2.1"
+			ctx.Version = "1.2.1"
+			ctx.Env = map[string]string{"PRICE": "0"}
+			require.NoError(t, Pipe{}.Default(ctx))
+			addPackages(t, ctx, folder)
+			tt.prepare(ctx)
+
+			cli := client.NewMock()
+			require.NoError(t, runAll(ctx, cli))
+			require.NoError(t, publishAll(ctx, cli))
+			require.True(t, cli.CreatedFile)
+			require.Equal(t, tt.path, cli.Path)
+			require.Empty(t, cli.PullRequests)
+			golden.RequireEqualExt(t, []byte(cli.Content), ".sh")
+
+			bts, err := os.ReadFile(filepath.Join(folder, name+".termux.sh"))
+			require.NoError(t, err)
+			require.Equal(t, cli.Content, string(bts))
+		})
+	}
+}
+
+func TestRunPipePullRequest(t *testing.T) {
+	folder := t.TempDir()
+	ctx := context.New(config.Project{
+		Dist:        folder,
+		ProjectName: "foo",
+		Termux: []config.Termux{{
+			IDs: []string{"foo"},
+			Repository: config.RepoRef{
+				Owner: "me",
+				Name:  "termux-packages",
+			},
+			PullRequest: config.PullRequest{
+				Enabled: true,
+				Base: config.RepoRef{
+					Owner:  "termux",
+					Name:   "termux-packages",
+					Branch: "master",
+				},
+			},
+		}},
+	})
+	ctx.Git.CurrentTag = "v1.2.1"
+	ctx.Version = "1.2.1"
+	require.NoError(t, Pipe{}.Default(ctx))
+	addPackages(t, ctx, folder)
+
+	cli := client.NewMock()
+	require.NoError(t, runAll(ctx, cli))
+	require.NoError(t, publishAll(ctx, cli))
+	require.Equal(t, []string{"me/termux-packages@foo-1.2.1"}, cli.CreatedBranches)
+	require.Equal(t, []client.MockPullRequest{{
+		Base:  client.Repo{Owner: "termux", Name: "termux-packages", Branch: "master"},
+		Head:  client.Repo{Owner: "me", Name: "termux-packages", Branch: "foo-1.2.1"},
+		Title: "foo: update to 1.2.1",
+		Body:  "Automated with [GoReleaser](https://goreleaser.com).",
+	}}, cli.PullRequests)
+}
+
+func TestRunPipePullRequestNotSupported(t *testing.T) {
+	folder := t.TempDir()
+	ctx := context.New(config.Project{
+		Dist:        folder,
+		ProjectName: "foo",
+		Termux: []config.Termux{{
+			IDs:         []string{"foo"},
+			Repository:  config.RepoRef{Owner: "me", Name: "termux-packages"},
+			PullRequest: config.PullRequest{Enabled: true},
+		}},
+	})
+	ctx.Git.CurrentTag = "v1.2.1"
+	ctx.Version = "1.2.1"
+	require.NoError(t, Pipe{}.Default(ctx))
+	addPackages(t, ctx, folder)
+
+	// only the Client methods of the mock, which can't open pull requests.
+	cli := struct{ client.Client }{client.NewMock()}
+	require.NoError(t, runAll(ctx, cli))
+	err := publishAll(ctx, cli)
+	require.ErrorIs(t, err, client.ErrPullRequestNotSupported)
+	require.EqualError(t, err, "termux.pull_request: pull requests are only supported on GitHub")
+}
+
+func TestRunPipeErrors(t *testing.T) {
+	newCtx := func(t *testing.T) *context.Context {
+		t.Helper()
+		folder := t.TempDir()
+		ctx := context.New(config.Project{
+			Dist:        folder,
+			ProjectName: "foo",
+			Termux: []config.Termux{{
+				Repository: config.RepoRef{Owner: "foo", Name: "termux-packages"},
+			}},
+		})
+		ctx.Git.CurrentTag = "v1.2.1"
+		require.NoError(t, Pipe{}.Default(ctx))
+		return ctx
+	}
+
+	t.Run("no packages", func(t *testing.T) {
+		ctx := newCtx(t)
+		require.Equal(t, ErrNoPackagesFound, runAll(ctx, client.NewMock()))
+	})
+
+	t.Run("same arch", func(t *testing.T) {
+		ctx := newCtx(t)
+		addPackages(t, ctx, ctx.Config.Dist)
+		path := filepath.Join(ctx.Config.Dist, "other.deb")
+		require.NoError(t, os.WriteFile(path, []byte("other"), 0o644))
+		ctx.Artifacts.Add(&artifact.Artifact{
+			Name:   "other.deb",
+			Path:   path,
+			Goos:   "android",
+			Goarch: "arm",
+			Goarm:  "6",
+			Type:   artifact.LinuxPackage,
+			Extra: map[string]interface{}{
+				artifact.ExtraID:     "bar",
+				artifact.ExtraFormat: "termux.deb",
+			},
+		})
+		require.Equal(t, ErrMultiplePackagesSameArch, runAll(ctx, client.NewMock()))
+	})
+
+	t.Run("invalid templates", func(t *testing.T) {
+		for name, prepare := range map[string]func(termux *config.Termux){
+			"name":         func(termux *config.Termux) { termux.Name = "{{ .Nope }" },
+			"path":         func(termux *config.Termux) { termux.Path = "{{ .Nope }" },
+			"branch":       func(termux *config.Termux) { termux.Repository.Branch = "{{ .Nope }" },
+			"description":  func(termux *config.Termux) { termux.Description = "{{ .Nope }" },
+			"maintainer":   func(termux *config.Termux) { termux.Maintainer = "{{ .Nope }" },
+			"url template": func(termux *config.Termux) { termux.URLTemplate = "{{ .Nope }" },
+		} {
+			t.Run(name, func(t *testing.T) {
+				ctx := newCtx(t)
+				addPackages(t, ctx, ctx.Config.Dist)
+				prepare(&ctx.Config.Termux[0])
+				require.Error(t, runAll(ctx, client.NewMock()))
+			})
+		}
+	})
+
+	t.Run("invalid commit template", func(t *testing.T) {
+		ctx := newCtx(t)
+		addPackages(t, ctx, ctx.Config.Dist)
+		ctx.Config.Termux[0].CommitMessageTemplate = "{{ .Nope }"
+		require.NoError(t, runAll(ctx, client.NewMock()))
+		require.Error(t, publishAll(ctx, client.NewMock()))
+	})
+}
+
+func TestRunPipeNoUpload(t *testing.T) {
+	folder := t.TempDir()
+	ctx := context.New(config.Project{
+		Dist:        folder,
+		ProjectName: "foo",
+		Termux: []config.Termux{{
+			Repository: config.RepoRef{Owner: "foo", Name: "termux-packages"},
+		}},
+	})
+	ctx.Git.CurrentTag = "v1.2.1"
+	require.NoError(t, Pipe{}.Default(ctx))
+	addPackages(t, ctx, folder)
+	cli := client.NewMock()
+
+	assertNoPublish := func(t *testing.T) {
+		t.Helper()
+		require.NoError(t, runAll(ctx, cli))
+		testlib.AssertSkipped(t, publishAll(ctx, cli))
+		require.False(t, cli.CreatedFile)
+	}
+	t.Run("skip upload true", func(t *testing.T) {
+		ctx.Config.Termux[0].SkipUpload = "true"
+		ctx.Semver.Prerelease = ""
+		assertNoPublish(t)
+	})
+	t.Run("skip upload auto", func(t *testing.T) {
+		ctx.Config.Termux[0].SkipUpload = "auto"
+		ctx.Semver.Prerelease = "beta1"
+		assertNoPublish(t)
+	})
+}
+
+func TestRunSkipNoName(t *testing.T) {
+	ctx := context.New(config.Project{
+		Termux: []config.Termux{{}},
+	})
+	testlib.AssertSkipped(t, runAll(ctx, client.NewMock()))
+}
+
+func TestEscape(t *testing.T) {
+	require.Equal(t, "a \\\"quoted\\\" \\\\ costing \\${price} \\`now\\`", escape("a \"quoted\" \\ costing ${price} `now`"))
+}
+
+// addPackages adds the termux debs of a release, and other packages that
+// should be ignored.
+func addPackages(tb testing.TB, ctx *context.Context, folder string) {
+	tb.Helper()
+	for _, art := range []*artifact.Artifact{
+		{Goos: "android", Goarch: "arm64"},
+		{Goos: "android", Goarch: "arm", Goarm: "7"},
+		{Goos: "android", Goarch: "amd64"},
+		{Goos: "linux", Goarch: "amd64", Extra: map[string]interface{}{artifact.ExtraFormat: "deb"}},
+	} {
+		if art.Extra == nil {
+			art.Extra = map[string]interface{}{artifact.ExtraFormat: "termux.deb"}
+		}
+		art.Extra[artifact.ExtraID] = "foo"
+		art.Name = "foo_" + art.Goos + "_" + art.Goarch + art.Goarm + "." + art.Extra[artifact.ExtraFormat].(string)
+		art.Path = filepath.Join(folder, art.Name)
+		art.Type = artifact.LinuxPackage
+		require.NoError(tb, os.WriteFile(art.Path, []byte(art.Name), 0o644))
+		ctx.Artifacts.Add(art)
+	}
+}
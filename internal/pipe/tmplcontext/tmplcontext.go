@@ -0,0 +1,163 @@
+// Package tmplcontext provides the pipes that snapshot the template context
+// of a release into the dist folder at the end of the build phase, and
+// restore it when the release is published or announced again later, so
+// templates render the same even if the environment changed in between.
+package tmplcontext
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/pipe"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Filename of the template context snapshot inside the dist folder.
+const Filename = "template_context.json"
+
+var (
+	// envRefRe matches the environment variables used by templates, either
+	// as {{ .Env.FOO }} or {{ index .Env "FOO" }}.
+	envRefRe = regexp.MustCompile(`\.Env\.([A-Za-z_][A-Za-z0-9_]*)|index\s+\.Env\s+\\?"([A-Za-z_][A-Za-z0-9_]*)\\?"`)
+
+	// secretRe matches the names of environment variables that likely hold
+	// secrets, which are never written to disk.
+	secretRe = regexp.MustCompile(`(?i)token|secret|passw|passphrase|private|credential|auth|(^|_)key($|_)`)
+)
+
+// Snapshot of the template context of a release.
+type Snapshot struct {
+	// Env has the environment variables used by the templates of the config.
+	Env map[string]string `json:"env"`
+	// Redacted has the environment variables used by the templates of the
+	// config that look like secrets, so they are read from the environment
+	// again instead.
+	Redacted          []string       `json:"redacted,omitempty"`
+	PreviousDownloads map[string]int `json:"previous_downloads,omitempty"`
+}
+
+// Pipe that snapshots the template context.
+type Pipe struct{}
+
+func (Pipe) String() string                 { return "storing template context" }
+func (Pipe) Skip(ctx *context.Context) bool { return false }
+
+// Run the pipe.
+func (Pipe) Run(ctx *context.Context) error {
+	snapshot, err := fromContext(ctx)
+	if err != nil {
+		return err
+	}
+	bts, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(ctx.Config.Dist, Filename)
+	log.FromContext(ctx).WithField("file", path).Info("writing")
+	return os.WriteFile(path, bts, 0o644)
+}
+
+// RestorePipe restores the template context snapshotted by Pipe.
+// It must run after the environment is loaded, so the snapshotted values
+// take precedence.
+type RestorePipe struct{}
+
+func (RestorePipe) String() string                 { return "restoring template context" }
+func (RestorePipe) Skip(ctx *context.Context) bool { return false }
+
+// Run the pipe.
+func (RestorePipe) Run(ctx *context.Context) error {
+	snapshot, err := Load(ctx.Config.Dist)
+	if errors.Is(err, os.ErrNotExist) {
+		return pipe.Skip("no template context found")
+	}
+	if err != nil {
+		return err
+	}
+	snapshot.Apply(ctx)
+	return nil
+}
+
+// Load the snapshot from the given dist folder.
+func Load(dist string) (Snapshot, error) {
+	var snapshot Snapshot
+	bts, err := os.ReadFile(filepath.Join(dist, Filename))
+	if err != nil {
+		return snapshot, err
+	}
+	if err := json.Unmarshal(bts, &snapshot); err != nil {
+		return snapshot, fmt.Errorf("failed to parse %s: %w", Filename, err)
+	}
+	return snapshot, nil
+}
+
+// Apply sets the snapshot back into the given context.
+func (s Snapshot) Apply(ctx *context.Context) {
+	for k, v := range s.Env {
+		if current, ok := ctx.Env[k]; ok && current != v {
+			log.FromContext(ctx).WithField("env", k).Debug("using the value of the previous run")
+		}
+		ctx.Env[k] = v
+	}
+	for _, k := range s.Redacted {
+		if _, ok := ctx.Env[k]; !ok {
+			log.FromContext(ctx).WithField("env", k).Warn("not stored as it looks like a secret, and not set either")
+		}
+	}
+	if s.PreviousDownloads != nil {
+		ctx.PreviousDownloads = context.ReleaseDownloads(s.PreviousDownloads)
+	}
+}
+
+func fromContext(ctx *context.Context) (Snapshot, error) {
+	keys, err := envRefs(ctx)
+	if err != nil {
+		return Snapshot{}, err
+	}
+	snapshot := Snapshot{
+		Env:               map[string]string{},
+		PreviousDownloads: map[string]int(ctx.PreviousDownloads),
+	}
+	for _, k := range keys {
+		v, ok := ctx.Env[k]
+		if !ok {
+			continue
+		}
+		if secretRe.MatchString(k) {
+			snapshot.Redacted = append(snapshot.Redacted, k)
+			continue
+		}
+		snapshot.Env[k] = v
+	}
+	return snapshot, nil
+}
+
+// envRefs returns the sorted environment variables used by the templates of
+// the config.
+func envRefs(ctx *context.Context) ([]string, error) {
+	bts, err := yaml.Marshal(ctx.Config)
+	if err != nil {
+		return nil, err
+	}
+	seen := map[string]bool{}
+	var keys []string
+	for _, m := range envRefRe.FindAllStringSubmatch(string(bts), -1) {
+		k := m[1]
+		if k == "" {
+			k = m[2]
+		}
+		if !seen[k] {
+			seen[k] = true
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
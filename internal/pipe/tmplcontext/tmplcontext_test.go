@@ -0,0 +1,93 @@
+package tmplcontext
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/pipe"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+func TestString(t *testing.T) {
+	require.NotEmpty(t, Pipe{}.String())
+	require.NotEmpty(t, RestorePipe{}.String())
+}
+
+func testConfig(dist string) config.Project {
+	return config.Project{
+		Dist: dist,
+		Announce: config.Announce{
+			Webhook: config.Webhook{
+				MessageTemplate: `{{ .Env.CHANNEL }} {{ index .Env "BUILD_ID" }} {{ .Env.SLACK_TOKEN }}`,
+			},
+		},
+	}
+}
+
+func TestRunAndRestore(t *testing.T) {
+	dist := t.TempDir()
+	ctx := context.New(testConfig(dist))
+	ctx.Env = context.Env{
+		"CHANNEL":     "releases",
+		"BUILD_ID":    "42",
+		"SLACK_TOKEN": "s3cr3t",
+		"UNUSED":      "foo",
+	}
+	ctx.PreviousDownloads = context.ReleaseDownloads{"foo.tar.gz": 10}
+	require.NoError(t, Pipe{}.Run(ctx))
+
+	bts, err := os.ReadFile(filepath.Join(dist, Filename))
+	require.NoError(t, err)
+	require.NotContains(t, string(bts), "s3cr3t")
+	require.NotContains(t, string(bts), "UNUSED")
+
+	snapshot, err := Load(dist)
+	require.NoError(t, err)
+	require.Equal(t, Snapshot{
+		Env:               map[string]string{"BUILD_ID": "42", "CHANNEL": "releases"},
+		Redacted:          []string{"SLACK_TOKEN"},
+		PreviousDownloads: map[string]int{"foo.tar.gz": 10},
+	}, snapshot)
+
+	// the environment changed between the runs.
+	rerun := context.New(testConfig(dist))
+	rerun.Env = context.Env{
+		"CHANNEL":     "other",
+		"BUILD_ID":    "43",
+		"SLACK_TOKEN": "n3w",
+	}
+	rerun.PreviousDownloads = context.ReleaseDownloads{"foo.tar.gz": 20}
+	require.NoError(t, RestorePipe{}.Run(rerun))
+	out, err := tmpl.New(rerun).Apply(rerun.Config.Announce.Webhook.MessageTemplate)
+	require.NoError(t, err)
+	require.Equal(t, "releases 42 n3w", out)
+	require.Equal(t, 10, rerun.PreviousDownloads.Total())
+}
+
+func TestRestoreNoSnapshot(t *testing.T) {
+	ctx := context.New(config.Project{Dist: t.TempDir()})
+	require.True(t, pipe.IsSkip(RestorePipe{}.Run(ctx)))
+}
+
+func TestRestoreInvalidSnapshot(t *testing.T) {
+	dist := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dist, Filename), []byte("{"), 0o644))
+	ctx := context.New(config.Project{Dist: dist})
+	require.Error(t, RestorePipe{}.Run(ctx))
+}
+
+func TestSecretRe(t *testing.T) {
+	for _, k := range []string{
+		"GITHUB_TOKEN", "AWS_SECRET_ACCESS_KEY", "DOCKER_PASSWORD", "GPG_PASSPHRASE",
+		"SSH_PRIVATE_KEY", "NPM_AUTH", "KEY", "SIGNING_KEY", "GOOGLE_APPLICATION_CREDENTIALS",
+	} {
+		require.True(t, secretRe.MatchString(k), k)
+	}
+	for _, k := range []string{"CHANNEL", "BUILD_ID", "GOVERSION", "MONKEY", "KEYBOARD"} {
+		require.False(t, secretRe.MatchString(k), k)
+	}
+}
@@ -43,7 +43,7 @@ func (Pipe) Announce(ctx *context.Context) error {
 		return fmt.Errorf("announce: failed to announce to twitter: %w", err)
 	}
 
-	log.Infof("posting: '%s'", msg)
+	log.FromContext(ctx).Infof("posting: '%s'", msg)
 	config := oauth1.NewConfig(cfg.ConsumerKey, cfg.ConsumerSecret)
 	token := oauth1.NewToken(cfg.AccessToken, cfg.AccessSecret)
 	client := twitter.NewClient(config.Client(oauth1.NoContext, token))
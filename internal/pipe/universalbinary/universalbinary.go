@@ -97,12 +97,25 @@ func runHook(ctx *context.Context, hooks config.Hooks) error {
 			return err
 		}
 
-		log.WithField("hook", sh).Info("running hook")
+		log.FromContext(ctx).WithField("hook", sh).Info("running hook")
 		cmd, err := shellwords.Parse(sh)
 		if err != nil {
 			return err
 		}
 
+		if hook.Image != "" {
+			// only the env not coming from ctx.Env is set in the container.
+			image, err := tmpl.New(ctx).Apply(hook.Image)
+			if err != nil {
+				return err
+			}
+			cmd, err = shell.Container(ctx, hook.Use, image, dir, envs[len(ctx.Env):], cmd)
+			if err != nil {
+				return err
+			}
+			dir = ""
+		}
+
 		if err := shell.Run(ctx, dir, cmd, envs); err != nil {
 			return err
 		}
@@ -141,7 +154,7 @@ func makeUniversalBinary(ctx *context.Context, unibin config.UniversalBinary) er
 		return pipe.Skip(fmt.Sprintf("no darwin binaries found with id %q", unibin.ID))
 	}
 
-	log.WithField("binary", path).Infof("creating from %d binaries", len(binaries))
+	log.FromContext(ctx).WithField("binary", path).Infof("creating from %d binaries", len(binaries))
 
 	var inputs []input
 	offset := int64(align)
@@ -71,7 +71,7 @@ func (p Pipe) Announce(ctx *context.Context) error {
 		return fmt.Errorf("announce: failed to announce to webhook: %s", err)
 	}
 
-	log.Infof("posting: '%s'", msg)
+	log.FromContext(ctx).Infof("posting: '%s'", msg)
 	customTransport := http.DefaultTransport.(*http.Transport).Clone()
 
 	customTransport.TLSClientConfig = &tls.Config{
@@ -90,15 +90,15 @@ func (p Pipe) Announce(ctx *context.Context) error {
 	req.Header.Add(UserAgentHeaderKey, UserAgentHeaderValue)
 
 	if cfg.BasicAuthHeader != "" {
-		log.Debugf("set basic auth header")
+		log.FromContext(ctx).Debugf("set basic auth header")
 		req.Header.Add(AuthorizationHeaderKey, cfg.BasicAuthHeader)
 	} else if cfg.BearerTokenHeader != "" {
-		log.Debugf("set bearer token header")
+		log.FromContext(ctx).Debugf("set bearer token header")
 		req.Header.Add(AuthorizationHeaderKey, cfg.BearerTokenHeader)
 	}
 
 	for key, value := range ctx.Config.Announce.Webhook.Headers {
-		log.Debugf("Header Key %s / Value %s", key, value)
+		log.FromContext(ctx).Debugf("Header Key %s / Value %s", key, value)
 		req.Header.Add(key, value)
 	}
 	resp, err := client.Do(req)
@@ -109,9 +109,9 @@ func (p Pipe) Announce(ctx *context.Context) error {
 
 	switch resp.StatusCode {
 	case http.StatusOK, http.StatusCreated, http.StatusAccepted, http.StatusNoContent:
-		log.Infof("Post OK: '%v'", resp.StatusCode)
+		log.FromContext(ctx).Infof("Post OK: '%v'", resp.StatusCode)
 		body, _ := io.ReadAll(resp.Body)
-		log.Infof("Response : %v\n", string(body))
+		log.FromContext(ctx).Infof("Response : %v\n", string(body))
 		return nil
 	default:
 		return fmt.Errorf("request failed with status %v", resp.Status)
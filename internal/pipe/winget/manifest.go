@@ -0,0 +1,111 @@
+package winget
+
+import (
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+)
+
+// The manifests below follow the multi-file format winget-pkgs expects.
+// more info: https://github.com/microsoft/winget-cli/tree/master/schemas/JSON/manifests/v1.4.0
+
+type versionManifest struct {
+	PackageIdentifier string `yaml:"PackageIdentifier"`
+	PackageVersion    string `yaml:"PackageVersion"`
+	DefaultLocale     string `yaml:"DefaultLocale"`
+	ManifestType      string `yaml:"ManifestType"`
+	ManifestVersion   string `yaml:"ManifestVersion"`
+}
+
+type installerManifest struct {
+	PackageIdentifier string      `yaml:"PackageIdentifier"`
+	PackageVersion    string      `yaml:"PackageVersion"`
+	InstallerLocale   string      `yaml:"InstallerLocale"`
+	InstallerType     string      `yaml:"InstallerType"`
+	ReleaseDate       string      `yaml:"ReleaseDate,omitempty"`
+	Installers        []installer `yaml:"Installers"`
+	ManifestType      string      `yaml:"ManifestType"`
+	ManifestVersion   string      `yaml:"ManifestVersion"`
+}
+
+type installer struct {
+	Architecture         string                `yaml:"Architecture"`
+	NestedInstallerType  string                `yaml:"NestedInstallerType"`
+	NestedInstallerFiles []nestedInstallerFile `yaml:"NestedInstallerFiles"`
+	InstallerURL         string                `yaml:"InstallerUrl"`
+	InstallerSha256      string                `yaml:"InstallerSha256"`
+	UpgradeBehavior      string                `yaml:"UpgradeBehavior"`
+}
+
+type nestedInstallerFile struct {
+	RelativeFilePath     string `yaml:"RelativeFilePath"`
+	PortableCommandAlias string `yaml:"PortableCommandAlias"`
+}
+
+type localeManifest struct {
+	PackageIdentifier string   `yaml:"PackageIdentifier"`
+	PackageVersion    string   `yaml:"PackageVersion"`
+	PackageLocale     string   `yaml:"PackageLocale"`
+	Publisher         string   `yaml:"Publisher"`
+	PublisherURL      string   `yaml:"PublisherUrl,omitempty"`
+	Author            string   `yaml:"Author,omitempty"`
+	PackageName       string   `yaml:"PackageName"`
+	PackageURL        string   `yaml:"PackageUrl,omitempty"`
+	License           string   `yaml:"License"`
+	LicenseURL        string   `yaml:"LicenseUrl,omitempty"`
+	Copyright         string   `yaml:"Copyright,omitempty"`
+	ShortDescription  string   `yaml:"ShortDescription"`
+	Description       string   `yaml:"Description,omitempty"`
+	Moniker           string   `yaml:"Moniker"`
+	Tags              []string `yaml:"Tags,omitempty"`
+	ReleaseNotes      string   `yaml:"ReleaseNotes,omitempty"`
+	ReleaseNotesURL   string   `yaml:"ReleaseNotesUrl,omitempty"`
+	ManifestType      string   `yaml:"ManifestType"`
+	ManifestVersion   string   `yaml:"ManifestVersion"`
+}
+
+func versionManifestFor(ctx *context.Context, winget config.Winget) versionManifest {
+	return versionManifest{
+		PackageIdentifier: winget.PackageIdentifier,
+		PackageVersion:    ctx.Version,
+		DefaultLocale:     defaultLocale,
+		ManifestType:      "version",
+		ManifestVersion:   manifestVersion,
+	}
+}
+
+func installerManifestFor(ctx *context.Context, winget config.Winget, installers []installer) installerManifest {
+	return installerManifest{
+		PackageIdentifier: winget.PackageIdentifier,
+		PackageVersion:    ctx.Version,
+		InstallerLocale:   defaultLocale,
+		InstallerType:     "zip",
+		ReleaseDate:       ctx.Date.Format("2006-01-02"),
+		Installers:        installers,
+		ManifestType:      "installer",
+		ManifestVersion:   manifestVersion,
+	}
+}
+
+func localeManifestFor(ctx *context.Context, winget config.Winget) localeManifest {
+	return localeManifest{
+		PackageIdentifier: winget.PackageIdentifier,
+		PackageVersion:    ctx.Version,
+		PackageLocale:     defaultLocale,
+		Publisher:         winget.Publisher,
+		PublisherURL:      winget.PublisherURL,
+		Author:            winget.Author,
+		PackageName:       winget.Name,
+		PackageURL:        winget.Homepage,
+		License:           winget.License,
+		LicenseURL:        winget.LicenseURL,
+		Copyright:         winget.Copyright,
+		ShortDescription:  winget.ShortDescription,
+		Description:       winget.Description,
+		Moniker:           winget.Name,
+		Tags:              winget.Tags,
+		ReleaseNotes:      winget.ReleaseNotes,
+		ReleaseNotesURL:   winget.ReleaseNotesURL,
+		ManifestType:      "defaultLocale",
+		ManifestVersion:   manifestVersion,
+	}
+}
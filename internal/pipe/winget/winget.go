@@ -0,0 +1,377 @@
+// Package winget implements the Pipe, generating the winget manifests of the
+// windows archives of the release, and pushing them to a winget manifests
+// repository, optionally opening a pull request.
+package winget
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/client"
+	"github.com/goreleaser/goreleaser/internal/commitauthor"
+	"github.com/goreleaser/goreleaser/internal/pipe"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	wingetConfigExtra = "WingetConfig"
+	manifestVersion   = "1.4.0"
+	defaultLocale     = "en-US"
+)
+
+var (
+	// ErrNoWindowsArchives happens when no windows zip archives are found.
+	ErrNoWindowsArchives = errors.New("winget requires windows zip archives")
+
+	// ErrMultipleArchivesSameArch happens when the config yields multiple
+	// archives for the same architecture.
+	ErrMultipleArchivesSameArch = errors.New("one winget manifest can handle only one archive per architecture. Consider using ids in the winget section")
+
+	errNoPublisher        = errors.New("winget.publisher is required")
+	errNoLicense          = errors.New("winget.license is required")
+	errNoShortDescription = errors.New("winget.short_description is required")
+)
+
+// arches maps go architectures to the winget ones.
+var arches = map[string]string{
+	"386":   "x86",
+	"amd64": "x64",
+	"arm64": "arm64",
+}
+
+// Pipe for winget manifests.
+type Pipe struct{}
+
+func (Pipe) String() string                 { return "winget manifests" }
+func (Pipe) Skip(ctx *context.Context) bool { return len(ctx.Config.Winget) == 0 }
+
+func (Pipe) Default(ctx *context.Context) error {
+	for i := range ctx.Config.Winget {
+		winget := &ctx.Config.Winget[i]
+
+		winget.CommitAuthor = commitauthor.Default(winget.CommitAuthor)
+		if winget.CommitMessageTemplate == "" {
+			winget.CommitMessageTemplate = "New version: {{ .PackageIdentifier }} {{ .Version }}"
+		}
+		if winget.Name == "" {
+			winget.Name = ctx.Config.ProjectName
+		}
+		if err := commitauthor.CheckSigning(ctx, winget.CommitAuthor); err != nil {
+			return fmt.Errorf("winget %s: %w", winget.Name, err)
+		}
+		if winget.PackageIdentifier == "" {
+			winget.PackageIdentifier = `{{ replace .Publisher " " "" }}.{{ replace .Name " " "" }}`
+		}
+		if winget.PullRequest.Enabled && winget.Repository.Branch == "" {
+			winget.Repository.Branch = "{{ .PackageIdentifier }}-{{ .Version }}"
+		}
+	}
+	return nil
+}
+
+func (Pipe) Run(ctx *context.Context) error {
+	cli, err := client.New(ctx)
+	if err != nil {
+		return err
+	}
+	return runAll(ctx, cli)
+}
+
+// Publish the winget manifests.
+func (Pipe) Publish(ctx *context.Context) error {
+	cli, err := client.New(ctx)
+	if err != nil {
+		return err
+	}
+	return publishAll(ctx, cli)
+}
+
+func runAll(ctx *context.Context, cli client.Client) error {
+	for _, winget := range ctx.Config.Winget {
+		if err := doRun(ctx, winget, cli); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func publishAll(ctx *context.Context, cli client.Client) error {
+	skips := pipe.SkipMemento{}
+	manifests := ctx.Artifacts.Filter(artifact.ByType(artifact.WingetManifest)).GroupByID()
+	ids := make([]string, 0, len(manifests))
+	for id := range manifests {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		err := doPublish(ctx, manifests[id], cli)
+		if err != nil && pipe.IsSkip(err) {
+			skips.Remember(err)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return skips.Evaluate()
+}
+
+func doRun(ctx *context.Context, winget config.Winget, cl client.Client) error {
+	if winget.Repository.Name == "" {
+		return pipe.Skip("winget.repository.name is not set")
+	}
+
+	filters := []artifact.Filter{
+		artifact.ByGoos("windows"),
+		artifact.ByType(artifact.UploadableArchive),
+		artifact.ByFormats("zip"),
+		artifact.OnlyUnencrypted,
+	}
+	if len(winget.IDs) > 0 {
+		filters = append(filters, artifact.ByIDs(winget.IDs...))
+	}
+	archives := ctx.Artifacts.Filter(artifact.And(filters...)).List()
+	if len(archives) == 0 {
+		return ErrNoWindowsArchives
+	}
+
+	t := tmpl.New(ctx)
+	for _, field := range []*string{
+		&winget.Name,
+		&winget.Publisher,
+		&winget.Author,
+		&winget.Copyright,
+		&winget.License,
+		&winget.ShortDescription,
+		&winget.Description,
+		&winget.ReleaseNotes,
+		&winget.ReleaseNotesURL,
+		&winget.Repository.Owner,
+		&winget.Repository.Name,
+		&winget.PullRequest.Base.Owner,
+		&winget.PullRequest.Base.Name,
+		&winget.PullRequest.Base.Branch,
+		&winget.SkipUpload,
+	} {
+		applied, err := t.Apply(*field)
+		if err != nil {
+			return err
+		}
+		*field = applied
+	}
+	switch {
+	case winget.Publisher == "":
+		return errNoPublisher
+	case winget.License == "":
+		return errNoLicense
+	case winget.ShortDescription == "":
+		return errNoShortDescription
+	}
+
+	t = t.WithExtraFields(tmpl.Fields{
+		"Name":      winget.Name,
+		"Publisher": winget.Publisher,
+	})
+	id, err := t.Apply(winget.PackageIdentifier)
+	if err != nil {
+		return err
+	}
+	winget.PackageIdentifier = id
+	t = t.WithExtraFields(tmpl.Fields{
+		"PackageIdentifier": id,
+	})
+	for _, field := range []*string{&winget.Path, &winget.Repository.Branch} {
+		applied, err := t.Apply(*field)
+		if err != nil {
+			return err
+		}
+		*field = applied
+	}
+	if winget.Path == "" {
+		winget.Path = path.Join(
+			"manifests",
+			strings.ToLower(id[:1]),
+			strings.ReplaceAll(id, ".", "/"),
+			ctx.Version,
+		)
+	}
+
+	installers, err := installersFor(ctx, winget, cl, archives)
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Join(ctx.Config.Dist, "winget", filepath.FromSlash(winget.Path))
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	for _, m := range []struct {
+		name     string
+		manifest interface{}
+	}{
+		{id + ".yaml", versionManifestFor(ctx, winget)},
+		{id + ".installer.yaml", installerManifestFor(ctx, winget, installers)},
+		{id + ".locale." + defaultLocale + ".yaml", localeManifestFor(ctx, winget)},
+	} {
+		content, err := marshal(m.manifest)
+		if err != nil {
+			return err
+		}
+		manifestPath := filepath.Join(dir, m.name)
+		log.FromContext(ctx).WithField("manifest", manifestPath).Info("writing")
+		if err := os.WriteFile(manifestPath, content, 0o644); err != nil { //nolint: gosec
+			return fmt.Errorf("failed to write winget manifest: %w", err)
+		}
+		ctx.Artifacts.Add(&artifact.Artifact{
+			Name: m.name,
+			Path: manifestPath,
+			Type: artifact.WingetManifest,
+			Extra: map[string]interface{}{
+				artifact.ExtraID:  id,
+				wingetConfigExtra: winget,
+			},
+		})
+	}
+	return nil
+}
+
+func doPublish(ctx *context.Context, manifests []*artifact.Artifact, cl client.Client) error {
+	winget := manifests[0].Extra[wingetConfigExtra].(config.Winget)
+	var err error
+	cl, err = client.NewIfToken(ctx, cl, winget.Repository.Token)
+	if err != nil {
+		return err
+	}
+
+	if strings.TrimSpace(winget.SkipUpload) == "true" {
+		return pipe.Skip("winget.skip_upload is set")
+	}
+
+	if strings.TrimSpace(winget.SkipUpload) == "auto" && ctx.Semver.Prerelease != "" {
+		return pipe.Skip("prerelease detected with 'auto' upload, skipping winget publish")
+	}
+
+	repo := client.RepoFromRef(winget.Repository)
+	var opener client.PullRequestOpener
+	if winget.PullRequest.Enabled {
+		var ok bool
+		opener, ok = cl.(client.PullRequestOpener)
+		if !ok {
+			return fmt.Errorf("winget.pull_request: %w", client.ErrPullRequestNotSupported)
+		}
+		if err := opener.CreateBranch(ctx, repo); err != nil {
+			return err
+		}
+	}
+
+	msg, err := tmpl.New(ctx).WithExtraFields(tmpl.Fields{
+		"PackageIdentifier": winget.PackageIdentifier,
+	}).Apply(winget.CommitMessageTemplate)
+	if err != nil {
+		return err
+	}
+
+	author, err := commitauthor.Get(ctx, winget.CommitAuthor)
+	if err != nil {
+		return err
+	}
+
+	for _, manifest := range manifests {
+		content, err := os.ReadFile(manifest.Path)
+		if err != nil {
+			return err
+		}
+		manifestPath := path.Join(winget.Path, manifest.Name)
+		log.FromContext(ctx).WithField("manifest", manifestPath).
+			WithField("repo", repo.String()).
+			Info("pushing")
+		if err := cl.CreateFile(ctx, author, repo, content, manifestPath, msg); err != nil {
+			return err
+		}
+	}
+
+	if opener == nil {
+		return nil
+	}
+	base := client.RepoFromRef(winget.PullRequest.Base)
+	if base.Name == "" {
+		base = client.Repo{Owner: repo.Owner, Name: repo.Name}
+	}
+	url, err := opener.OpenPullRequest(ctx, base, repo, msg, "Automated with [GoReleaser](https://goreleaser.com).")
+	if err != nil {
+		return err
+	}
+	log.FromContext(ctx).WithField("url", url).Info("opened pull request")
+	return nil
+}
+
+func installersFor(ctx *context.Context, winget config.Winget, cl client.Client, archives []*artifact.Artifact) ([]installer, error) {
+	if winget.URLTemplate == "" {
+		url, err := cl.ReleaseURLTemplate(ctx)
+		if err != nil {
+			return nil, err
+		}
+		winget.URLTemplate = url
+	}
+
+	var installers []installer
+	seen := map[string]bool{}
+	for _, archive := range archives {
+		arch := arches[archive.Goarch]
+		if arch == "" {
+			continue
+		}
+		if seen[arch] {
+			return nil, ErrMultipleArchivesSameArch
+		}
+		seen[arch] = true
+		sum, err := archive.Checksum("sha256")
+		if err != nil {
+			return nil, err
+		}
+		url, err := tmpl.New(ctx).WithArtifact(archive, map[string]string{}).Apply(winget.URLTemplate)
+		if err != nil {
+			return nil, err
+		}
+		inst := installer{
+			Architecture:        arch,
+			NestedInstallerType: "portable",
+			InstallerURL:        url,
+			InstallerSha256:     strings.ToUpper(sum),
+			UpgradeBehavior:     "uninstallPrevious",
+		}
+		wrap := archive.ExtraOr(artifact.ExtraWrappedIn, "").(string)
+		for _, binary := range archive.ExtraOr(artifact.ExtraBuilds, []*artifact.Artifact{}).([]*artifact.Artifact) {
+			inst.NestedInstallerFiles = append(inst.NestedInstallerFiles, nestedInstallerFile{
+				RelativeFilePath:     path.Join(wrap, binary.Name),
+				PortableCommandAlias: strings.TrimSuffix(binary.Name, ".exe"),
+			})
+		}
+		installers = append(installers, inst)
+	}
+	if len(installers) == 0 {
+		return nil, ErrNoWindowsArchives
+	}
+	sort.Slice(installers, func(i, j int) bool {
+		return installers[i].Architecture < installers[j].Architecture
+	})
+	return installers, nil
+}
+
+func marshal(manifest interface{}) ([]byte, error) {
+	bts, err := yaml.Marshal(manifest)
+	if err != nil {
+		return nil, fmt.Errorf("winget: failed to marshal yaml: %w", err)
+	}
+	return append([]byte("# This file was generated by GoReleaser. DO NOT EDIT.\n"), bts...), nil
+}
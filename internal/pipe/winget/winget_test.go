@@ -0,0 +1,311 @@
+package winget
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/client"
+	"github.com/goreleaser/goreleaser/internal/golden"
+	"github.com/goreleaser/goreleaser/internal/testlib"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDescription(t *testing.T) {
+	require.NotEmpty(t, Pipe{}.String())
+}
+
+func TestSkip(t *testing.T) {
+	require.True(t, Pipe{}.Skip(context.New(config.Project{})))
+	require.False(t, Pipe{}.Skip(context.New(config.Project{
+		Winget: []config.Winget{{}},
+	})))
+}
+
+func TestDefault(t *testing.T) {
+	ctx := context.New(config.Project{
+		ProjectName: "myproject",
+		Winget: []config.Winget{
+			{},
+			{PullRequest: config.PullRequest{Enabled: true}},
+		},
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	winget := ctx.Config.Winget[0]
+	require.Equal(t, "myproject", winget.Name)
+	require.Equal(t, `{{ replace .Publisher " " "" }}.{{ replace .Name " " "" }}`, winget.PackageIdentifier)
+	require.Empty(t, winget.Repository.Branch)
+	require.NotEmpty(t, winget.CommitAuthor.Name)
+	require.NotEmpty(t, winget.CommitAuthor.Email)
+	require.NotEmpty(t, winget.CommitMessageTemplate)
+	require.Equal(t, "{{ .PackageIdentifier }}-{{ .Version }}", ctx.Config.Winget[1].Repository.Branch)
+}
+
+func TestRunPipe(t *testing.T) {
+	ctx := context.New(config.Project{
+		Dist:        t.TempDir(),
+		ProjectName: "foo",
+		Winget: []config.Winget{{
+			IDs:              []string{"foo"},
+			Publisher:        "Foo Inc",
+			License:          "MIT",
+			ShortDescription: "A foo package",
+			Repository:       config.RepoRef{Owner: "foo", Name: "winget-pkgs"},
+			Homepage:         "https://goreleaser.com",
+			Author:           "Carlos",
+			Copyright:        "Copyright (c) {{ .ProjectName }}",
+			LicenseURL:       "https://github.com/foo/bar/blob/main/LICENSE",
+			Description:      "A longer description\nof foo.",
+			ReleaseNotesURL:  "https://github.com/foo/bar/releases/tag/{{ .Tag }}",
+			Tags:             []string{"cli", "foo"},
+		}},
+	})
+	ctx.Git.CurrentTag = "v1.2.1"
+	ctx.Version = "1.2.1"
+	ctx.Date = time.Date(2022, 3, 4, 5, 6, 7, 0, time.UTC)
+	require.NoError(t, Pipe{}.Default(ctx))
+	addArchives(t, ctx)
+	cli := client.NewMock()
+	require.NoError(t, runAll(ctx, cli))
+	require.NoError(t, publishAll(ctx, cli))
+
+	dir := "manifests/f/FooInc/foo/1.2.1"
+	require.Len(t, cli.CreatedFiles, 3)
+	for _, name := range []string{
+		"FooInc.foo.yaml",
+		"FooInc.foo.installer.yaml",
+		"FooInc.foo.locale.en-US.yaml",
+	} {
+		t.Run(name, func(t *testing.T) {
+			content, ok := cli.CreatedFiles[dir+"/"+name]
+			require.True(t, ok)
+			golden.RequireEqualYaml(t, []byte(content))
+
+			bts, err := os.ReadFile(filepath.Join(ctx.Config.Dist, "winget", filepath.FromSlash(dir), name))
+			require.NoError(t, err)
+			require.Equal(t, content, string(bts))
+		})
+	}
+	require.Empty(t, cli.PullRequests)
+}
+
+func TestRunPipePullRequest(t *testing.T) {
+	ctx := context.New(config.Project{
+		Dist:        t.TempDir(),
+		ProjectName: "foo",
+		Winget: []config.Winget{{
+			IDs:               []string{"foo"},
+			Publisher:         "Foo Inc",
+			License:           "MIT",
+			ShortDescription:  "A foo package",
+			PackageIdentifier: "Foo.Bar",
+			Path:              "manifests/{{ .PackageIdentifier }}/{{ .Version }}",
+			Repository: config.RepoRef{
+				Owner: "me",
+				Name:  "winget-pkgs",
+			},
+			PullRequest: config.PullRequest{
+				Enabled: true,
+				Base: config.RepoRef{
+					Owner:  "microsoft",
+					Name:   "winget-pkgs",
+					Branch: "master",
+				},
+			},
+		}},
+	})
+	ctx.Git.CurrentTag = "v1.2.1"
+	ctx.Version = "1.2.1"
+	ctx.Date = time.Date(2022, 3, 4, 5, 6, 7, 0, time.UTC)
+	require.NoError(t, Pipe{}.Default(ctx))
+	addArchives(t, ctx)
+	cli := client.NewMock()
+	require.NoError(t, runAll(ctx, cli))
+	require.NoError(t, publishAll(ctx, cli))
+	require.Contains(t, cli.CreatedFiles, "manifests/Foo.Bar/1.2.1/Foo.Bar.installer.yaml")
+	require.Equal(t, []string{"me/winget-pkgs@Foo.Bar-1.2.1"}, cli.CreatedBranches)
+	require.Equal(t, []client.MockPullRequest{{
+		Base:  client.Repo{Owner: "microsoft", Name: "winget-pkgs", Branch: "master"},
+		Head:  client.Repo{Owner: "me", Name: "winget-pkgs", Branch: "Foo.Bar-1.2.1"},
+		Title: "New version: Foo.Bar 1.2.1",
+		Body:  "Automated with [GoReleaser](https://goreleaser.com).",
+	}}, cli.PullRequests)
+}
+
+func TestRunPipePullRequestNotSupported(t *testing.T) {
+	ctx := context.New(config.Project{
+		Dist:        t.TempDir(),
+		ProjectName: "foo",
+		Winget: []config.Winget{{
+			IDs:               []string{"foo"},
+			Publisher:         "Foo Inc",
+			License:           "MIT",
+			ShortDescription:  "A foo package",
+			PackageIdentifier: "Foo.Bar",
+			Repository:        config.RepoRef{Owner: "me", Name: "winget-pkgs"},
+			PullRequest:       config.PullRequest{Enabled: true},
+		}},
+	})
+	ctx.Git.CurrentTag = "v1.2.1"
+	ctx.Version = "1.2.1"
+	ctx.Date = time.Date(2022, 3, 4, 5, 6, 7, 0, time.UTC)
+	require.NoError(t, Pipe{}.Default(ctx))
+	addArchives(t, ctx)
+	// only the Client methods of the mock, which can't open pull requests.
+	cli := struct{ client.Client }{client.NewMock()}
+	require.NoError(t, runAll(ctx, cli))
+	err := publishAll(ctx, cli)
+	require.ErrorIs(t, err, client.ErrPullRequestNotSupported)
+	require.EqualError(t, err, "winget.pull_request: pull requests are only supported on GitHub")
+}
+
+func TestRunPipeErrors(t *testing.T) {
+	for name, tt := range map[string]struct {
+		prepare func(winget *config.Winget)
+		err     string
+	}{
+		"no archives": {
+			prepare: func(winget *config.Winget) { winget.IDs = []string{"nope"} },
+			err:     ErrNoWindowsArchives.Error(),
+		},
+		"multiple archives": {
+			prepare: func(winget *config.Winget) { winget.IDs = []string{"foo", "bar"} },
+			err:     ErrMultipleArchivesSameArch.Error(),
+		},
+		"no publisher": {
+			prepare: func(winget *config.Winget) { winget.Publisher = "" },
+			err:     errNoPublisher.Error(),
+		},
+		"no license": {
+			prepare: func(winget *config.Winget) { winget.License = "" },
+			err:     errNoLicense.Error(),
+		},
+		"no short description": {
+			prepare: func(winget *config.Winget) { winget.ShortDescription = "" },
+			err:     errNoShortDescription.Error(),
+		},
+		"invalid template": {
+			prepare: func(winget *config.Winget) { winget.Description = "{{ .Nope }" },
+			err:     `template: tmpl:1: unexpected "}" in operand`,
+		},
+		"invalid package identifier": {
+			prepare: func(winget *config.Winget) { winget.PackageIdentifier = "{{ .Nope }" },
+			err:     `template: tmpl:1: unexpected "}" in operand`,
+		},
+		"invalid url template": {
+			prepare: func(winget *config.Winget) { winget.URLTemplate = "{{ .Nope }" },
+			err:     `template: tmpl:1: unexpected "}" in operand`,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.New(config.Project{
+				Dist:        t.TempDir(),
+				ProjectName: "foo",
+				Winget: []config.Winget{{
+					IDs:              []string{"foo"},
+					Publisher:        "Foo Inc",
+					License:          "MIT",
+					ShortDescription: "A foo package",
+					Repository:       config.RepoRef{Owner: "foo", Name: "winget-pkgs"},
+				}},
+			})
+			ctx.Git.CurrentTag = "v1.2.1"
+			ctx.Version = "1.2.1"
+			ctx.Date = time.Date(2022, 3, 4, 5, 6, 7, 0, time.UTC)
+			require.NoError(t, Pipe{}.Default(ctx))
+			addArchives(t, ctx)
+			tt.prepare(&ctx.Config.Winget[0])
+			require.EqualError(t, runAll(ctx, client.NewMock()), tt.err)
+		})
+	}
+}
+
+func TestRunPipeNoRepository(t *testing.T) {
+	ctx := context.New(config.Project{
+		Dist:        t.TempDir(),
+		ProjectName: "foo",
+		Winget: []config.Winget{{
+			IDs:              []string{"foo"},
+			Publisher:        "Foo Inc",
+			License:          "MIT",
+			ShortDescription: "A foo package",
+			Repository:       config.RepoRef{Owner: "foo", Name: "winget-pkgs"},
+		}},
+	})
+	ctx.Git.CurrentTag = "v1.2.1"
+	ctx.Version = "1.2.1"
+	ctx.Date = time.Date(2022, 3, 4, 5, 6, 7, 0, time.UTC)
+	require.NoError(t, Pipe{}.Default(ctx))
+	addArchives(t, ctx)
+	ctx.Config.Winget[0].Repository = config.RepoRef{}
+	testlib.AssertSkipped(t, runAll(ctx, client.NewMock()))
+}
+
+func TestPublishSkipUpload(t *testing.T) {
+	for _, skip := range []string{"true", "auto"} {
+		t.Run(skip, func(t *testing.T) {
+			ctx := context.New(config.Project{
+				Dist:        t.TempDir(),
+				ProjectName: "foo",
+				Winget: []config.Winget{{
+					IDs:              []string{"foo"},
+					Publisher:        "Foo Inc",
+					License:          "MIT",
+					ShortDescription: "A foo package",
+					Repository:       config.RepoRef{Owner: "foo", Name: "winget-pkgs"},
+					SkipUpload:       skip,
+				}},
+			})
+			ctx.Git.CurrentTag = "v1.2.1"
+			ctx.Version = "1.2.1"
+			ctx.Date = time.Date(2022, 3, 4, 5, 6, 7, 0, time.UTC)
+			require.NoError(t, Pipe{}.Default(ctx))
+			addArchives(t, ctx)
+			ctx.Semver.Prerelease = "beta"
+			cli := client.NewMock()
+			require.NoError(t, runAll(ctx, cli))
+			testlib.AssertSkipped(t, publishAll(ctx, cli))
+			require.False(t, cli.CreatedFile)
+		})
+	}
+}
+
+// addArchives adds the windows archives of the foo build, wrapping foo.exe and
+// bar.exe, and one of the bar build.
+func addArchives(tb testing.TB, ctx *context.Context) {
+	tb.Helper()
+	for _, a := range []struct {
+		id, goarch, format string
+	}{
+		{"foo", "amd64", "zip"},
+		{"foo", "386", "zip"},
+		{"foo", "arm64", "zip"},
+		{"foo", "amd64", "tar.gz"},
+		{"foo", "mips", "zip"},
+		{"bar", "amd64", "zip"},
+	} {
+		name := a.id + "_windows_" + a.goarch + "." + a.format
+		path := filepath.Join(ctx.Config.Dist, name)
+		require.NoError(tb, os.WriteFile(path, []byte(name), 0o644))
+		ctx.Artifacts.Add(&artifact.Artifact{
+			Name:   name,
+			Path:   path,
+			Goos:   "windows",
+			Goarch: a.goarch,
+			Type:   artifact.UploadableArchive,
+			Extra: map[string]interface{}{
+				artifact.ExtraID:        a.id,
+				artifact.ExtraFormat:    a.format,
+				artifact.ExtraWrappedIn: "foo_" + a.goarch,
+				artifact.ExtraBuilds: []*artifact.Artifact{
+					{Name: "foo.exe"},
+					{Name: "bar.exe"},
+				},
+			},
+		})
+	}
+}
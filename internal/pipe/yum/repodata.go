@@ -0,0 +1,548 @@
+package yum
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	nsCommon    = "http://linux.duke.edu/metadata/common"
+	nsRPM       = "http://linux.duke.edu/metadata/rpm"
+	nsFilelists = "http://linux.duke.edu/metadata/filelists"
+	nsOther     = "http://linux.duke.edu/metadata/other"
+	nsRepo      = "http://linux.duke.edu/metadata/repo"
+)
+
+type xmlVersion struct {
+	Epoch string `xml:"epoch,attr"`
+	Ver   string `xml:"ver,attr"`
+	Rel   string `xml:"rel,attr"`
+}
+
+type xmlChecksum struct {
+	Type  string `xml:"type,attr"`
+	PkgID string `xml:"pkgid,attr,omitempty"`
+	Value string `xml:",chardata"`
+}
+
+type xmlLocation struct {
+	Href string `xml:"href,attr"`
+}
+
+type xmlFile struct {
+	Type string `xml:"type,attr,omitempty"`
+	Path string `xml:",chardata"`
+}
+
+type depEntry struct {
+	Name  string `xml:"name,attr"`
+	Flags string `xml:"flags,attr,omitempty"`
+	Epoch string `xml:"epoch,attr,omitempty"`
+	Ver   string `xml:"ver,attr,omitempty"`
+	Rel   string `xml:"rel,attr,omitempty"`
+}
+
+type depList struct {
+	Entries []depEntry `xml:"rpm:entry"`
+}
+
+// primaryPackage is a package in primary.xml.
+// Its format elements are prefixed with rpm:, which encoding/xml can write
+// but not read back, so the packages already in a repository are kept as
+// they are instead.
+type primaryPackage struct {
+	XMLName     xml.Name    `xml:"package"`
+	Type        string      `xml:"type,attr"`
+	Name        string      `xml:"name"`
+	Arch        string      `xml:"arch"`
+	Version     xmlVersion  `xml:"version"`
+	Checksum    xmlChecksum `xml:"checksum"`
+	Summary     string      `xml:"summary"`
+	Description string      `xml:"description"`
+	Packager    string      `xml:"packager"`
+	URL         string      `xml:"url"`
+	Time        struct {
+		File  int64 `xml:"file,attr"`
+		Build int64 `xml:"build,attr"`
+	} `xml:"time"`
+	Size struct {
+		Package   int64 `xml:"package,attr"`
+		Installed int64 `xml:"installed,attr"`
+		Archive   int64 `xml:"archive,attr"`
+	} `xml:"size"`
+	Location xmlLocation `xml:"location"`
+	Format   struct {
+		License     string `xml:"rpm:license"`
+		Vendor      string `xml:"rpm:vendor"`
+		Group       string `xml:"rpm:group"`
+		BuildHost   string `xml:"rpm:buildhost"`
+		SourceRPM   string `xml:"rpm:sourcerpm"`
+		HeaderRange struct {
+			Start int64 `xml:"start,attr"`
+			End   int64 `xml:"end,attr"`
+		} `xml:"rpm:header-range"`
+		Provides  *depList  `xml:"rpm:provides"`
+		Requires  *depList  `xml:"rpm:requires"`
+		Conflicts *depList  `xml:"rpm:conflicts"`
+		Obsoletes *depList  `xml:"rpm:obsoletes"`
+		Files     []xmlFile `xml:"file"`
+	} `xml:"format"`
+}
+
+// rawPrimary is primary.xml, keeping the content of each package as is.
+type rawPrimary struct {
+	Packages []struct {
+		Name     string      `xml:"name"`
+		Arch     string      `xml:"arch"`
+		Version  xmlVersion  `xml:"version"`
+		Checksum xmlChecksum `xml:"checksum"`
+		Inner    string      `xml:",innerxml"`
+	} `xml:"package"`
+}
+
+type filelistsPackage struct {
+	XMLName xml.Name   `xml:"package"`
+	PkgID   string     `xml:"pkgid,attr"`
+	Name    string     `xml:"name,attr"`
+	Arch    string     `xml:"arch,attr"`
+	Version xmlVersion `xml:"version"`
+	Files   []xmlFile  `xml:"file"`
+}
+
+type filelists struct {
+	Packages []filelistsPackage `xml:"package"`
+}
+
+type changelog struct {
+	Author string `xml:"author,attr"`
+	Date   int64  `xml:"date,attr"`
+	Text   string `xml:",chardata"`
+}
+
+type otherPackage struct {
+	XMLName    xml.Name    `xml:"package"`
+	PkgID      string      `xml:"pkgid,attr"`
+	Name       string      `xml:"name,attr"`
+	Arch       string      `xml:"arch,attr"`
+	Version    xmlVersion  `xml:"version"`
+	Changelogs []changelog `xml:"changelog"`
+}
+
+type otherdata struct {
+	Packages []otherPackage `xml:"package"`
+}
+
+type repomdData struct {
+	Type         string      `xml:"type,attr"`
+	Checksum     xmlChecksum `xml:"checksum"`
+	OpenChecksum xmlChecksum `xml:"open-checksum"`
+	Location     xmlLocation `xml:"location"`
+	Timestamp    int64       `xml:"timestamp"`
+	Size         int64       `xml:"size"`
+	OpenSize     int64       `xml:"open-size"`
+}
+
+type repomd struct {
+	XMLName  xml.Name     `xml:"repomd"`
+	Xmlns    string       `xml:"xmlns,attr"`
+	XmlnsRPM string       `xml:"xmlns:rpm,attr"`
+	Revision int64        `xml:"revision"`
+	Data     []repomdData `xml:"data"`
+}
+
+// location returns the location of the given metadata type, if any.
+func (r repomd) location(typ string) string {
+	for _, d := range r.Data {
+		if d.Type == typ {
+			return d.Location.Href
+		}
+	}
+	return ""
+}
+
+// pkg is a package of the repository, with its entry in each of the
+// metadata files.
+type pkg struct {
+	id        string
+	name      string
+	arch      string
+	version   xmlVersion
+	primary   string
+	filelists filelistsPackage
+	other     otherPackage
+}
+
+// repository is the metadata of a repository, with its packages by id.
+type repository struct {
+	packages map[string]*pkg
+}
+
+// parseRepository parses the given primary, filelists and other metadata.
+func parseRepository(primaryXML, filelistsXML, otherXML []byte) (*repository, error) {
+	repo := &repository{packages: map[string]*pkg{}}
+	if len(primaryXML) == 0 {
+		return repo, nil
+	}
+	var primary rawPrimary
+	if err := xml.Unmarshal(primaryXML, &primary); err != nil {
+		return nil, fmt.Errorf("failed to parse primary.xml: %w", err)
+	}
+	for _, p := range primary.Packages {
+		id := p.Checksum.Value
+		repo.packages[id] = &pkg{
+			id:      id,
+			name:    p.Name,
+			arch:    p.Arch,
+			version: p.Version,
+			primary: `<package type="rpm">` + p.Inner + `</package>`,
+			filelists: filelistsPackage{
+				PkgID: id, Name: p.Name, Arch: p.Arch, Version: p.Version,
+			},
+			other: otherPackage{
+				PkgID: id, Name: p.Name, Arch: p.Arch, Version: p.Version,
+			},
+		}
+	}
+
+	var files filelists
+	if len(filelistsXML) > 0 {
+		if err := xml.Unmarshal(filelistsXML, &files); err != nil {
+			return nil, fmt.Errorf("failed to parse filelists.xml: %w", err)
+		}
+	}
+	for _, f := range files.Packages {
+		if p, ok := repo.packages[f.PkgID]; ok {
+			p.filelists = f
+		}
+	}
+
+	var other otherdata
+	if len(otherXML) > 0 {
+		if err := xml.Unmarshal(otherXML, &other); err != nil {
+			return nil, fmt.Errorf("failed to parse other.xml: %w", err)
+		}
+	}
+	for _, o := range other.Packages {
+		if p, ok := repo.packages[o.PkgID]; ok {
+			p.other = o
+		}
+	}
+	return repo, nil
+}
+
+// add adds the given package, replacing the package with the same name,
+// architecture and version, if any.
+func (r *repository) add(p *pkg) {
+	for id, existing := range r.packages {
+		if existing.name == p.name && existing.arch == p.arch && existing.version == p.version {
+			delete(r.packages, id)
+		}
+	}
+	r.packages[p.id] = p
+}
+
+// sorted returns the packages sorted by name, architecture and version.
+func (r *repository) sorted() []*pkg {
+	result := make([]*pkg, 0, len(r.packages))
+	for _, p := range r.packages {
+		result = append(result, p)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if a.name != b.name {
+			return a.name < b.name
+		}
+		if a.arch != b.arch {
+			return a.arch < b.arch
+		}
+		if a.version != b.version {
+			return a.version.Epoch+":"+a.version.Ver+"-"+a.version.Rel < b.version.Epoch+":"+b.version.Ver+"-"+b.version.Rel
+		}
+		return a.id < b.id
+	})
+	return result
+}
+
+// render returns the metadata files of the repository, by their path,
+// including repodata/repomd.xml, which references the other ones.
+func (r *repository) render(date time.Time) (map[string][]byte, error) {
+	packages := r.sorted()
+	var primary, files, other bytes.Buffer
+	fmt.Fprintf(&primary, "%s<metadata xmlns=%q xmlns:rpm=%q packages=\"%d\">\n", xml.Header, nsCommon, nsRPM, len(packages))
+	fmt.Fprintf(&files, "%s<filelists xmlns=%q packages=\"%d\">\n", xml.Header, nsFilelists, len(packages))
+	fmt.Fprintf(&other, "%s<otherdata xmlns=%q packages=\"%d\">\n", xml.Header, nsOther, len(packages))
+	for _, p := range packages {
+		primary.WriteString(p.primary + "\n")
+		for _, v := range []struct {
+			w *bytes.Buffer
+			v interface{}
+		}{{&files, p.filelists}, {&other, p.other}} {
+			bts, err := xml.Marshal(v.v)
+			if err != nil {
+				return nil, err
+			}
+			v.w.Write(append(bts, '\n'))
+		}
+	}
+	primary.WriteString("</metadata>\n")
+	files.WriteString("</filelists>\n")
+	other.WriteString("</otherdata>\n")
+
+	result := map[string][]byte{}
+	md := repomd{Xmlns: nsRepo, XmlnsRPM: nsRPM, Revision: date.Unix()}
+	for _, data := range []struct {
+		typ     string
+		content []byte
+	}{
+		{"primary", primary.Bytes()},
+		{"filelists", files.Bytes()},
+		{"other", other.Bytes()},
+	} {
+		gz, err := gzipped(data.content)
+		if err != nil {
+			return nil, err
+		}
+		sum := sha256sum(gz)
+		// the files are named after their checksum, so clients never get
+		// a repomd.xml and metadata files that don't match from a cache.
+		name := path.Join("repodata", sum+"-"+data.typ+".xml.gz")
+		result[name] = gz
+		md.Data = append(md.Data, repomdData{
+			Type:         data.typ,
+			Checksum:     xmlChecksum{Type: "sha256", Value: sum},
+			OpenChecksum: xmlChecksum{Type: "sha256", Value: sha256sum(data.content)},
+			Location:     xmlLocation{Href: name},
+			Timestamp:    date.Unix(),
+			Size:         int64(len(gz)),
+			OpenSize:     int64(len(data.content)),
+		})
+	}
+	bts, err := xml.MarshalIndent(md, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	result["repodata/repomd.xml"] = append([]byte(xml.Header), append(bts, '\n')...)
+	return result, nil
+}
+
+// newPackage returns the repository package of the given rpm, published
+// at the given location.
+func newPackage(info rpmInfo, location string, data []byte, mtime time.Time) (*pkg, error) {
+	h := info.main
+	id := sha256sum(data)
+	version := xmlVersion{
+		Epoch: fmt.Sprint(h.firstInt(tagEpoch)),
+		Ver:   h.first(tagVersion),
+		Rel:   h.first(tagRelease),
+	}
+	files := packageFiles(h)
+
+	primary := primaryPackage{
+		Type:        "rpm",
+		Name:        h.first(tagName),
+		Arch:        h.first(tagArch),
+		Version:     version,
+		Checksum:    xmlChecksum{Type: "sha256", PkgID: "YES", Value: id},
+		Summary:     h.first(tagSummary),
+		Description: h.first(tagDescription),
+		Packager:    h.first(tagPackager),
+		URL:         h.first(tagURL),
+		Location:    xmlLocation{Href: location},
+	}
+	if primary.Name == "" || primary.Arch == "" {
+		return nil, fmt.Errorf("%s has no name or arch", location)
+	}
+	primary.Time.File = mtime.Unix()
+	primary.Time.Build = h.firstInt(tagBuildTime)
+	primary.Size.Package = int64(len(data))
+	primary.Size.Installed = h.firstInt(tagSize)
+	primary.Size.Archive = info.sig.firstInt(sigTagPayloadSize)
+	if primary.Size.Archive == 0 {
+		primary.Size.Archive = h.firstInt(tagArchiveSize)
+	}
+	f := &primary.Format
+	f.License = h.first(tagLicense)
+	f.Vendor = h.first(tagVendor)
+	f.Group = h.first(tagGroup)
+	f.BuildHost = h.first(tagBuildHost)
+	f.SourceRPM = h.first(tagSourceRPM)
+	f.HeaderRange.Start = info.headerStart
+	f.HeaderRange.End = info.headerEnd
+	f.Provides = deps(h, tagProvideName, tagProvideFlags, tagProvideVersion)
+	f.Requires = deps(h, tagRequireName, tagRequireFlags, tagRequireVersion)
+	f.Conflicts = deps(h, tagConflictName, tagConflictFlags, tagConflictVersion)
+	f.Obsoletes = deps(h, tagObsoleteName, tagObsoleteFlags, tagObsoleteVersion)
+	// primary.xml only lists the files dependencies usually refer to, the
+	// other ones are in filelists.xml.
+	for _, file := range files {
+		if isPrimaryFile(file.Path) {
+			f.Files = append(f.Files, file)
+		}
+	}
+	bts, err := xml.MarshalIndent(primary, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	var changelogs []changelog
+	times := h.ints(tagChangelogTime)
+	names := h.strings(tagChangelogName)
+	texts := h.strings(tagChangelogText)
+	for i := range times {
+		if i >= len(names) || i >= len(texts) {
+			break
+		}
+		changelogs = append(changelogs, changelog{Author: names[i], Date: times[i], Text: texts[i]})
+	}
+
+	return &pkg{
+		id:      id,
+		name:    primary.Name,
+		arch:    primary.Arch,
+		version: version,
+		primary: string(bts),
+		filelists: filelistsPackage{
+			PkgID:   id,
+			Name:    primary.Name,
+			Arch:    primary.Arch,
+			Version: version,
+			Files:   files,
+		},
+		other: otherPackage{
+			PkgID:      id,
+			Name:       primary.Name,
+			Arch:       primary.Arch,
+			Version:    version,
+			Changelogs: changelogs,
+		},
+	}, nil
+}
+
+// packageFiles returns the files of the package of the given header.
+func packageFiles(h header) []xmlFile {
+	const (
+		modeType  = 0o170000
+		modeDir   = 0o040000
+		flagGhost = 1 << 6
+	)
+	dirs := h.strings(tagDirNames)
+	indexes := h.ints(tagDirIndexes)
+	modes := h.ints(tagFileModes)
+	flags := h.ints(tagFileFlags)
+	var files []xmlFile
+	for i, base := range h.strings(tagBaseNames) {
+		if i >= len(indexes) || int(indexes[i]) >= len(dirs) {
+			break
+		}
+		file := xmlFile{Path: dirs[indexes[i]] + base}
+		switch {
+		case i < len(modes) && modes[i]&modeType == modeDir:
+			file.Type = "dir"
+		case i < len(flags) && flags[i]&flagGhost != 0:
+			file.Type = "ghost"
+		}
+		files = append(files, file)
+	}
+	return files
+}
+
+// isPrimaryFile returns whether the given file is listed in primary.xml, as
+// createrepo does.
+func isPrimaryFile(file string) bool {
+	return strings.Contains(file, "bin/") ||
+		strings.HasPrefix(file, "/etc/") ||
+		file == "/usr/lib/sendmail"
+}
+
+// deps returns the dependencies of the given tags, skipping the rpmlib
+// ones, which are only meaningful to rpm itself.
+func deps(h header, nameTag, flagsTag, versionTag uint32) *depList {
+	names := h.strings(nameTag)
+	flags := h.ints(flagsTag)
+	versions := h.strings(versionTag)
+	list := &depList{}
+	for i, name := range names {
+		if strings.HasPrefix(name, "rpmlib(") {
+			continue
+		}
+		dep := depEntry{Name: name}
+		if i < len(versions) && versions[i] != "" && i < len(flags) {
+			dep.Flags = depFlags(flags[i])
+			dep.Epoch, dep.Ver, dep.Rel = parseEVR(versions[i])
+		}
+		list.Entries = append(list.Entries, dep)
+	}
+	if len(list.Entries) == 0 {
+		return nil
+	}
+	return list
+}
+
+// depFlags returns the comparison of the given rpm dependency flags.
+func depFlags(flags int64) string {
+	const (
+		less    = 1 << 1
+		greater = 1 << 2
+		equal   = 1 << 3
+	)
+	switch flags & (less | greater | equal) {
+	case less:
+		return "LT"
+	case greater:
+		return "GT"
+	case equal:
+		return "EQ"
+	case less | equal:
+		return "LE"
+	case greater | equal:
+		return "GE"
+	}
+	return ""
+}
+
+// parseEVR splits the given [epoch:]version[-release].
+func parseEVR(evr string) (epoch, version, release string) {
+	epoch = "0"
+	if i := strings.Index(evr, ":"); i >= 0 {
+		epoch, evr = evr[:i], evr[i+1:]
+	}
+	if i := strings.LastIndex(evr, "-"); i >= 0 {
+		return epoch, evr[:i], evr[i+1:]
+	}
+	return epoch, evr, ""
+}
+
+func sha256sum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func gzipped(data []byte) ([]byte, error) {
+	var b bytes.Buffer
+	w := gzip.NewWriter(&b)
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+func gunzipped(data []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
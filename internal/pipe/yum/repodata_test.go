@@ -0,0 +1,130 @@
+package yum
+
+import (
+	"encoding/xml"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+var testDate = time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)
+
+func testPackage(tb testing.TB, name, version, arch string) *pkg {
+	tb.Helper()
+	path := createRPM(tb, tb.TempDir(), name, version, arch)
+	info, err := readRPM(path)
+	require.NoError(tb, err)
+	data, err := os.ReadFile(path)
+	require.NoError(tb, err)
+	p, err := newPackage(info, "Packages/"+name+".rpm", data, testDate)
+	require.NoError(tb, err)
+	return p
+}
+
+func TestNewPackage(t *testing.T) {
+	p := testPackage(t, "foo", "1.0.0", "amd64")
+	require.Equal(t, "foo", p.name)
+	require.Equal(t, "x86_64", p.arch)
+	require.Equal(t, xmlVersion{Epoch: "0", Ver: "1.0.0", Rel: "1"}, p.version)
+	require.Len(t, p.id, 64)
+	for _, s := range []string{
+		`<package type="rpm">`,
+		`<checksum type="sha256" pkgid="YES">` + p.id + `</checksum>`,
+		`<location href="Packages/foo.rpm"></location>`,
+		`<rpm:license>MIT</rpm:license>`,
+		`<rpm:entry name="bash" flags="GE" epoch="0" ver="4.0"></rpm:entry>`,
+		`<file>/usr/bin/foo</file>`,
+		`<time file="` + "1641092645" + `"`,
+	} {
+		require.Contains(t, p.primary, s)
+	}
+	require.NotContains(t, p.primary, "rpmlib(")
+	require.Equal(t, []xmlFile{{Path: "/usr/bin/foo"}}, p.filelists.Files)
+}
+
+func TestRenderAndParse(t *testing.T) {
+	repo, err := parseRepository(nil, nil, nil)
+	require.NoError(t, err)
+	repo.add(testPackage(t, "foo", "1.0.0", "amd64"))
+	repo.add(testPackage(t, "foo", "1.0.0", "arm64"))
+
+	files, err := repo.render(testDate)
+	require.NoError(t, err)
+	require.Len(t, files, 4)
+
+	var md repomd
+	require.NoError(t, xml.Unmarshal(files["repodata/repomd.xml"], &md))
+	require.Equal(t, int64(1641092645), md.Revision)
+	require.Len(t, md.Data, 3)
+	metadata := map[string][]byte{}
+	for _, d := range md.Data {
+		gz, ok := files[d.Location.Href]
+		require.True(t, ok, d.Location.Href)
+		require.Equal(t, sha256sum(gz), d.Checksum.Value)
+		require.True(t, strings.HasPrefix(d.Location.Href, "repodata/"+d.Checksum.Value+"-"))
+		bts, err := gunzipped(gz)
+		require.NoError(t, err)
+		require.Equal(t, sha256sum(bts), d.OpenChecksum.Value)
+		require.Equal(t, int64(len(bts)), d.OpenSize)
+		metadata[d.Type] = bts
+	}
+	require.Contains(t, string(metadata["primary"]), `xmlns:rpm="http://linux.duke.edu/metadata/rpm" packages="2"`)
+
+	// the parsed repository renders the same, and a new version is added
+	// next to the other ones.
+	parsed, err := parseRepository(metadata["primary"], metadata["filelists"], metadata["other"])
+	require.NoError(t, err)
+	again, err := parsed.render(testDate)
+	require.NoError(t, err)
+	require.Equal(t, files, again)
+
+	parsed.add(testPackage(t, "foo", "1.1.0", "amd64"))
+	sorted := parsed.sorted()
+	require.Len(t, sorted, 3)
+	require.Equal(t, "aarch64", sorted[0].arch)
+	require.Equal(t, "1.0.0", sorted[1].version.Ver)
+	require.Equal(t, "1.1.0", sorted[2].version.Ver)
+	require.Equal(t, []xmlFile{{Path: "/usr/bin/foo"}}, sorted[0].filelists.Files)
+}
+
+func TestAddReplacesSameVersion(t *testing.T) {
+	repo, err := parseRepository(nil, nil, nil)
+	require.NoError(t, err)
+	repo.add(&pkg{id: "a", name: "foo", arch: "x86_64", version: xmlVersion{"0", "1.0.0", "1"}})
+	repo.add(&pkg{id: "b", name: "foo", arch: "x86_64", version: xmlVersion{"0", "1.0.0", "1"}})
+	require.Len(t, repo.packages, 1)
+	require.Contains(t, repo.packages, "b")
+}
+
+func TestParseRepositoryInvalid(t *testing.T) {
+	_, err := parseRepository([]byte("<metadata"), nil, nil)
+	require.Error(t, err)
+}
+
+func TestDepFlags(t *testing.T) {
+	for flags, expected := range map[int64]string{
+		2:        "LT",
+		4:        "GT",
+		8:        "EQ",
+		10:       "LE",
+		12:       "GE",
+		0:        "",
+		12 | 256: "GE",
+	} {
+		require.Equal(t, expected, depFlags(flags), flags)
+	}
+}
+
+func TestParseEVR(t *testing.T) {
+	for evr, expected := range map[string][3]string{
+		"1.0.0":       {"0", "1.0.0", ""},
+		"1.0.0-1":     {"0", "1.0.0", "1"},
+		"2:1.0.0-1.1": {"2", "1.0.0", "1.1"},
+	} {
+		epoch, version, release := parseEVR(evr)
+		require.Equal(t, expected, [3]string{epoch, version, release}, evr)
+	}
+}
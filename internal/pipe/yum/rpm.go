@@ -0,0 +1,238 @@
+package yum
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+)
+
+// rpm header tags used to build the repository metadata.
+const (
+	tagName            = 1000
+	tagVersion         = 1001
+	tagRelease         = 1002
+	tagEpoch           = 1003
+	tagSummary         = 1004
+	tagDescription     = 1005
+	tagBuildTime       = 1006
+	tagBuildHost       = 1007
+	tagSize            = 1009
+	tagVendor          = 1011
+	tagLicense         = 1014
+	tagPackager        = 1015
+	tagGroup           = 1016
+	tagURL             = 1020
+	tagArch            = 1022
+	tagFileModes       = 1030
+	tagFileFlags       = 1037
+	tagSourceRPM       = 1044
+	tagArchiveSize     = 1046
+	tagProvideName     = 1047
+	tagRequireFlags    = 1048
+	tagRequireName     = 1049
+	tagRequireVersion  = 1050
+	tagConflictFlags   = 1053
+	tagConflictName    = 1054
+	tagConflictVersion = 1055
+	tagChangelogTime   = 1080
+	tagChangelogName   = 1081
+	tagChangelogText   = 1082
+	tagObsoleteName    = 1090
+	tagProvideFlags    = 1112
+	tagProvideVersion  = 1113
+	tagObsoleteFlags   = 1114
+	tagObsoleteVersion = 1115
+	tagDirIndexes      = 1116
+	tagBaseNames       = 1117
+	tagDirNames        = 1118
+
+	// sigTagPayloadSize is the size of the uncompressed payload, in the
+	// signature header.
+	sigTagPayloadSize = 1007
+)
+
+// rpm header entry types.
+const (
+	typeInt16       = 3
+	typeInt32       = 4
+	typeInt64       = 5
+	typeString      = 6
+	typeStringArray = 8
+	typeI18NString  = 9
+)
+
+const leadSize = 96
+
+var (
+	leadMagic   = []byte{0xed, 0xab, 0xee, 0xdb}
+	headerMagic = []byte{0x8e, 0xad, 0xe8, 0x01}
+)
+
+type entry struct {
+	typ    uint32
+	offset uint32
+	count  uint32
+}
+
+// header is a parsed rpm header, with its entries by tag.
+type header struct {
+	entries map[uint32]entry
+	data    []byte
+}
+
+// rpmInfo has the headers of an rpm package, and where its main header
+// starts and ends in the file.
+type rpmInfo struct {
+	sig         header
+	main        header
+	headerStart int64
+	headerEnd   int64
+}
+
+// readRPM reads the signature and main headers of the given rpm package.
+func readRPM(rpmPath string) (rpmInfo, error) {
+	var info rpmInfo
+	f, err := os.Open(rpmPath)
+	if err != nil {
+		return info, err
+	}
+	defer f.Close()
+
+	lead := make([]byte, leadSize)
+	if _, err := io.ReadFull(f, lead); err != nil || !bytes.Equal(lead[:4], leadMagic) {
+		return info, fmt.Errorf("%s is not an rpm package", rpmPath)
+	}
+	sig, sigSize, err := readHeader(f)
+	if err != nil {
+		return info, fmt.Errorf("failed to read %s: %w", rpmPath, err)
+	}
+	// the signature header is padded to 8 bytes.
+	if pad := (8 - sigSize%8) % 8; pad > 0 {
+		if _, err := io.CopyN(io.Discard, f, pad); err != nil {
+			return info, fmt.Errorf("failed to read %s: %w", rpmPath, err)
+		}
+	}
+	main, mainSize, err := readHeader(f)
+	if err != nil {
+		return info, fmt.Errorf("failed to read %s: %w", rpmPath, err)
+	}
+	info.sig = sig
+	info.main = main
+	info.headerStart = leadSize + sigSize + (8-sigSize%8)%8
+	info.headerEnd = info.headerStart + mainSize
+	return info, nil
+}
+
+// readHeader reads a header structure, returning it and its size.
+func readHeader(r io.Reader) (header, int64, error) {
+	h := header{entries: map[uint32]entry{}}
+	intro := make([]byte, 16)
+	if _, err := io.ReadFull(r, intro); err != nil {
+		return h, 0, err
+	}
+	if !bytes.Equal(intro[:4], headerMagic) {
+		return h, 0, fmt.Errorf("invalid rpm header")
+	}
+	count := binary.BigEndian.Uint32(intro[8:12])
+	size := binary.BigEndian.Uint32(intro[12:16])
+	index := make([]byte, 16*int64(count))
+	if _, err := io.ReadFull(r, index); err != nil {
+		return h, 0, err
+	}
+	for i := uint32(0); i < count; i++ {
+		e := index[16*i : 16*(i+1)]
+		h.entries[binary.BigEndian.Uint32(e[0:4])] = entry{
+			typ:    binary.BigEndian.Uint32(e[4:8]),
+			offset: binary.BigEndian.Uint32(e[8:12]),
+			count:  binary.BigEndian.Uint32(e[12:16]),
+		}
+	}
+	h.data = make([]byte, size)
+	if _, err := io.ReadFull(r, h.data); err != nil {
+		return h, 0, err
+	}
+	return h, 16 + 16*int64(count) + int64(size), nil
+}
+
+// strings returns the strings of the given tag, or nil if it is not set.
+func (h header) strings(tag uint32) []string {
+	e, ok := h.entries[tag]
+	if !ok || int(e.offset) > len(h.data) {
+		return nil
+	}
+	switch e.typ {
+	case typeString, typeStringArray, typeI18NString:
+	default:
+		return nil
+	}
+	n := int(e.count)
+	if e.typ == typeString {
+		n = 1
+	}
+	result := make([]string, 0, n)
+	data := h.data[e.offset:]
+	for i := 0; i < n; i++ {
+		end := bytes.IndexByte(data, 0)
+		if end < 0 {
+			break
+		}
+		result = append(result, string(data[:end]))
+		data = data[end+1:]
+	}
+	return result
+}
+
+// first returns the first string of the given tag, which is the
+// untranslated one for i18n strings.
+func (h header) first(tag uint32) string {
+	if s := h.strings(tag); len(s) > 0 {
+		return s[0]
+	}
+	return ""
+}
+
+// ints returns the integers of the given tag, or nil if it is not set.
+func (h header) ints(tag uint32) []int64 {
+	e, ok := h.entries[tag]
+	if !ok {
+		return nil
+	}
+	var size int
+	switch e.typ {
+	case typeInt16:
+		size = 2
+	case typeInt32:
+		size = 4
+	case typeInt64:
+		size = 8
+	default:
+		return nil
+	}
+	if int(e.offset)+size*int(e.count) > len(h.data) {
+		return nil
+	}
+	result := make([]int64, 0, e.count)
+	for i := 0; i < int(e.count); i++ {
+		b := h.data[int(e.offset)+size*i:]
+		switch size {
+		case 2:
+			result = append(result, int64(binary.BigEndian.Uint16(b)))
+		case 4:
+			result = append(result, int64(binary.BigEndian.Uint32(b)))
+		default:
+			result = append(result, int64(binary.BigEndian.Uint64(b)))
+		}
+	}
+	return result
+}
+
+// firstInt returns the first integer of the given tag, or 0 if it is not
+// set.
+func (h header) firstInt(tag uint32) int64 {
+	if i := h.ints(tag); len(i) > 0 {
+		return i[0]
+	}
+	return 0
+}
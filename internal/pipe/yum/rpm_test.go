@@ -0,0 +1,71 @@
+package yum
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/goreleaser/nfpm/v2"
+	"github.com/goreleaser/nfpm/v2/files"
+	"github.com/goreleaser/nfpm/v2/rpm"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadRPM(t *testing.T) {
+	path := createRPM(t, t.TempDir(), "foo", "1.0.0", "amd64")
+	info, err := readRPM(path)
+	require.NoError(t, err)
+	require.Equal(t, "foo", info.main.first(tagName))
+	require.Equal(t, "1.0.0", info.main.first(tagVersion))
+	require.Equal(t, "1", info.main.first(tagRelease))
+	require.Equal(t, "x86_64", info.main.first(tagArch))
+	require.Equal(t, "a foo", info.main.first(tagSummary))
+	require.Equal(t, []string{"bash"}, info.main.strings(tagRequireName))
+	require.Equal(t, []string{"4.0"}, info.main.strings(tagRequireVersion))
+	require.Equal(t, []string{"foo"}, info.main.strings(tagBaseNames))
+	require.Equal(t, []string{"/usr/bin/"}, info.main.strings(tagDirNames))
+	require.Greater(t, info.headerStart, int64(leadSize))
+	require.Greater(t, info.headerEnd, info.headerStart)
+}
+
+func TestReadRPMInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "foo.rpm")
+	require.NoError(t, os.WriteFile(path, []byte("not an rpm"), 0o644))
+	_, err := readRPM(path)
+	require.EqualError(t, err, path+" is not an rpm package")
+
+	lead := make([]byte, leadSize+16)
+	copy(lead, leadMagic)
+	require.NoError(t, os.WriteFile(path, lead, 0o644))
+	_, err = readRPM(path)
+	require.EqualError(t, err, "failed to read "+path+": invalid rpm header")
+
+	_, err = readRPM(filepath.Join(t.TempDir(), "nope.rpm"))
+	require.Error(t, err)
+}
+
+func createRPM(tb testing.TB, dir, name, version, arch string) string {
+	tb.Helper()
+	bin := filepath.Join(dir, name)
+	require.NoError(tb, os.WriteFile(bin, []byte("#!/bin/sh\necho "+name+"\n"), 0o755))
+	path := filepath.Join(dir, name+"-"+version+"."+arch+".rpm")
+	f, err := os.Create(path)
+	require.NoError(tb, err)
+	defer f.Close()
+	info := nfpm.WithDefaults(&nfpm.Info{
+		Name:        name,
+		Arch:        arch,
+		Version:     version,
+		Maintainer:  "Foo <foo@example.com>",
+		Description: "a " + name,
+		License:     "MIT",
+		Overridables: nfpm.Overridables{
+			Depends: []string{"bash >= 4.0"},
+			Contents: files.Contents{
+				{Source: bin, Destination: "/usr/bin/" + name},
+			},
+		},
+	})
+	require.NoError(tb, rpm.Default.Package(info, f))
+	return path
+}
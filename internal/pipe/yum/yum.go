@@ -0,0 +1,295 @@
+// Package yum implements the Pipe interface, publishing the rpm packages to
+// signed YUM/DNF repositories.
+package yum
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/ids"
+	"github.com/goreleaser/goreleaser/internal/pipe"
+	"github.com/goreleaser/goreleaser/internal/pkgrepo"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+)
+
+const repomdPath = "repodata/repomd.xml"
+
+// Pipe for YUM repositories.
+type Pipe struct{}
+
+func (Pipe) String() string                 { return "yum repositories" }
+func (Pipe) Skip(ctx *context.Context) bool { return len(ctx.Config.YUMRepositories) == 0 }
+
+// Default sets the pipe defaults.
+func (Pipe) Default(ctx *context.Context) error {
+	ids := ids.New("yum_repositories")
+	for i := range ctx.Config.YUMRepositories {
+		repo := &ctx.Config.YUMRepositories[i]
+		if repo.ID == "" {
+			repo.ID = "default"
+		}
+		if repo.Name == "" {
+			repo.Name = ctx.Config.ProjectName
+		}
+		if repo.Description == "" {
+			repo.Description = repo.Name
+		}
+		if repo.Blob.Bucket == "" {
+			return fmt.Errorf("yum_repositories %s: blob is required", repo.ID)
+		}
+		switch repo.Blob.Provider {
+		case "s3", "gs", "azblob":
+		default:
+			return fmt.Errorf("yum_repositories %s: invalid blob provider %q, valid options are s3, gs and azblob", repo.ID, repo.Blob.Provider)
+		}
+		ids.Inc(repo.ID)
+	}
+	return ids.Validate()
+}
+
+// Publish the repositories.
+func (Pipe) Publish(ctx *context.Context) error {
+	skips := pipe.SkipMemento{}
+	for _, repo := range ctx.Config.YUMRepositories {
+		err := doPublish(ctx, repo)
+		if err != nil && pipe.IsSkip(err) {
+			skips.Remember(err)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return skips.Evaluate()
+}
+
+func doPublish(ctx *context.Context, cfg config.YUMRepository) error {
+	if strings.TrimSpace(cfg.SkipUpload) == "true" {
+		return pipe.Skip("yum_repositories.skip_upload is set")
+	}
+	if strings.TrimSpace(cfg.SkipUpload) == "auto" && ctx.Semver.Prerelease != "" {
+		return pipe.Skip("prerelease detected with 'auto' upload, skipping yum repository publish")
+	}
+
+	filters := []artifact.Filter{
+		artifact.ByType(artifact.LinuxPackage),
+		artifact.ByFormats("rpm"),
+	}
+	if len(cfg.IDs) > 0 {
+		filters = append(filters, artifact.ByIDs(cfg.IDs...))
+	}
+	rpms := ctx.Artifacts.Filter(artifact.And(filters...)).List()
+	if len(rpms) == 0 {
+		return pipe.Skip("no rpm packages found")
+	}
+
+	st, err := pkgrepo.Open(ctx, cfg.Blob, cfg.Folder)
+	if err != nil {
+		return err
+	}
+	defer st.Close()
+
+	// the packages already in the repository are kept, so the other
+	// architectures and versions can still be installed.
+	repo, err := readRepository(ctx, st)
+	if err != nil {
+		return err
+	}
+
+	log := log.FromContext(ctx).WithField("repository", cfg.ID)
+	for _, rpm := range rpms {
+		info, err := readRPM(rpm.Path)
+		if err != nil {
+			return err
+		}
+		data, err := os.ReadFile(rpm.Path)
+		if err != nil {
+			return err
+		}
+		stat, err := os.Stat(rpm.Path)
+		if err != nil {
+			return err
+		}
+		location := path.Join("Packages", rpm.Name)
+		p, err := newPackage(info, location, data, stat.ModTime())
+		if err != nil {
+			return err
+		}
+		log.WithField("package", rpm.Name).Info("adding")
+		if err := st.Write(ctx, location, data); err != nil {
+			return fmt.Errorf("failed to write %s: %w", location, err)
+		}
+		repo.add(p)
+	}
+
+	files, err := repo.render(ctx.Date)
+	if err != nil {
+		return err
+	}
+	repomd := files[repomdPath]
+	delete(files, repomdPath)
+	if err := writeAll(ctx, st, files); err != nil {
+		return err
+	}
+
+	// repomd.xml is written last, so it never references metadata that
+	// wasn't written yet.
+	signed, err := sign(ctx, cfg, repomd)
+	if err != nil {
+		return err
+	}
+	if url, err := tmpl.New(ctx).Apply(cfg.URL); err == nil && url != "" {
+		signed[cfg.Name+".repo"] = []byte(repoFile(cfg, strings.TrimSuffix(url, "/")))
+	}
+	if err := writeAll(ctx, st, signed); err != nil {
+		return err
+	}
+	logInstructions(ctx, cfg)
+	return nil
+}
+
+// readRepository reads the metadata of the repository, if it exists.
+func readRepository(ctx *context.Context, st *pkgrepo.Store) (*repository, error) {
+	bts, err := st.Read(ctx, repomdPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return parseRepository(nil, nil, nil)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", repomdPath, err)
+	}
+	var md repomd
+	if err := xml.Unmarshal(bts, &md); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", repomdPath, err)
+	}
+	var metadata [3][]byte
+	for i, typ := range []string{"primary", "filelists", "other"} {
+		location := md.location(typ)
+		if location == "" {
+			continue
+		}
+		gz, err := st.Read(ctx, location)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", location, err)
+		}
+		if metadata[i], err = gunzipped(gz); err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", location, err)
+		}
+	}
+	return parseRepository(metadata[0], metadata[1], metadata[2])
+}
+
+// writeAll writes the given files, sorted by path.
+func writeAll(ctx *context.Context, st *pkgrepo.Store, files map[string][]byte) error {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := st.Write(ctx, name, files[name]); err != nil {
+			return fmt.Errorf("failed to write %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// sign writes the given repomd.xml to the dist folder, signs it with gpg
+// and returns the files to publish, by their path in the repository.
+func sign(ctx *context.Context, cfg config.YUMRepository, repomd []byte) (map[string][]byte, error) {
+	key, err := tmpl.New(ctx).Apply(cfg.KeyID)
+	if err != nil {
+		return nil, err
+	}
+
+	dir := filepath.Join(ctx.Config.Dist, "yum", cfg.ID)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	repomdFile := filepath.Join(dir, "repomd.xml")
+	if err := os.WriteFile(repomdFile, repomd, 0o644); err != nil { //nolint: gosec
+		return nil, err
+	}
+
+	var user []string
+	if key != "" {
+		user = []string{"--local-user", key}
+	}
+	args := append(user, "--armor", "--detach-sign", "--output", repomdFile+".asc", repomdFile)
+	if err := gpg(ctx, args...); err != nil {
+		return nil, err
+	}
+	files := map[string]string{
+		repomdPath:          repomdFile,
+		repomdPath + ".asc": repomdFile + ".asc",
+	}
+	if key != "" {
+		// the public key, so users can import it.
+		if err := gpg(ctx, "--armor", "--output", repomdFile+".key", "--export", key); err != nil {
+			return nil, err
+		}
+		files[repomdPath+".key"] = repomdFile + ".key"
+	}
+
+	result := map[string][]byte{}
+	for name, file := range files {
+		bts, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		result[name] = bts
+	}
+	return result, nil
+}
+
+func gpg(ctx *context.Context, args ...string) error {
+	args = append([]string{"--batch", "--yes"}, args...)
+	/* #nosec */
+	cmd := exec.CommandContext(ctx, "gpg", args...)
+	cmd.Env = ctx.Env.Strings()
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to sign the repository: %w: %s", err, string(out))
+	}
+	return nil
+}
+
+// repoFile returns the .repo file of the repository, served from the given
+// url, so users can add it to /etc/yum.repos.d.
+func repoFile(cfg config.YUMRepository, url string) string {
+	lines := []string{
+		"[" + cfg.Name + "]",
+		"name=" + cfg.Description,
+		"baseurl=" + url,
+		"enabled=1",
+		"gpgcheck=0",
+	}
+	if cfg.KeyID != "" {
+		lines = append(lines,
+			"repo_gpgcheck=1",
+			"gpgkey="+url+"/"+repomdPath+".key",
+		)
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// logInstructions logs how to install the packages from the repository, if
+// its url is known.
+func logInstructions(ctx *context.Context, cfg config.YUMRepository) {
+	url := pkgrepo.InstallURL(ctx, cfg.URL)
+	if url == "" {
+		return
+	}
+	log.FromContext(ctx).Info("install with:")
+	log.FromContext(ctx).Infof("sudo curl -fsSL %s/%s.repo -o /etc/yum.repos.d/%s.repo", url, cfg.Name, cfg.Name)
+	log.FromContext(ctx).Info("sudo dnf install " + cfg.Name)
+}
@@ -0,0 +1,229 @@
+package yum
+
+import (
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/pipe"
+	"github.com/goreleaser/goreleaser/internal/testlib"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+	_ "gocloud.dev/blob/fileblob"
+)
+
+func TestDescription(t *testing.T) {
+	require.NotEmpty(t, Pipe{}.String())
+}
+
+func TestSkip(t *testing.T) {
+	require.True(t, Pipe{}.Skip(context.New(config.Project{})))
+	require.False(t, Pipe{}.Skip(context.New(config.Project{
+		YUMRepositories: []config.YUMRepository{{}},
+	})))
+}
+
+func TestDefault(t *testing.T) {
+	ctx := context.New(config.Project{
+		ProjectName: "foo",
+		YUMRepositories: []config.YUMRepository{{
+			Blob: config.RepositoryBlob{Provider: "s3", Bucket: "bar"},
+		}},
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.Equal(t, config.YUMRepository{
+		ID:          "default",
+		Name:        "foo",
+		Description: "foo",
+		Blob:        config.RepositoryBlob{Provider: "s3", Bucket: "bar"},
+	}, ctx.Config.YUMRepositories[0])
+}
+
+func TestDefaultErrors(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		repos []config.YUMRepository
+		err   string
+	}{
+		{
+			name:  "no blob",
+			repos: []config.YUMRepository{{}},
+			err:   "yum_repositories default: blob is required",
+		},
+		{
+			name: "invalid provider",
+			repos: []config.YUMRepository{{
+				Blob: config.RepositoryBlob{Bucket: "bar"},
+			}},
+			err: `yum_repositories default: invalid blob provider "", valid options are s3, gs and azblob`,
+		},
+		{
+			name: "duplicated ids",
+			repos: []config.YUMRepository{
+				{Blob: config.RepositoryBlob{Provider: "s3", Bucket: "bar"}},
+				{Blob: config.RepositoryBlob{Provider: "gs", Bucket: "bar"}},
+			},
+			err: "found 2 yum_repositories with the ID 'default', please fix your config",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.New(config.Project{
+				ProjectName:     "foo",
+				YUMRepositories: tt.repos,
+			})
+			require.EqualError(t, Pipe{}.Default(ctx), tt.err)
+		})
+	}
+}
+
+func TestPublish(t *testing.T) {
+	calls := testlib.FakeGPG(t)
+	bucket := t.TempDir()
+	cfg := config.YUMRepository{
+		KeyID:  "ABC123",
+		URL:    "https://rpm.example.com/{{ .ProjectName }}",
+		Blob:   config.RepositoryBlob{Provider: "s3", Bucket: bucket},
+		Folder: "{{ .ProjectName }}",
+	}
+	ctx := newContext(t, cfg)
+	addRPM(t, ctx, "foo", "1.0.0", "amd64")
+	addRPM(t, ctx, "foo", "1.0.0", "arm64")
+	require.NoError(t, Pipe{}.Publish(ctx))
+
+	repo := filepath.Join(bucket, "foo")
+	for _, name := range []string{
+		"Packages/foo-1.0.0.amd64.rpm",
+		"Packages/foo-1.0.0.arm64.rpm",
+		"repodata/repomd.xml",
+		"repodata/repomd.xml.asc",
+		"repodata/repomd.xml.key",
+	} {
+		require.FileExists(t, filepath.Join(repo, name))
+	}
+	require.Len(t, readPrimary(t, repo).Packages, 2)
+
+	bts, err := os.ReadFile(filepath.Join(repo, "foo.repo"))
+	require.NoError(t, err)
+	require.Equal(t, `[foo]
+name=foo
+baseurl=https://rpm.example.com/foo
+enabled=1
+gpgcheck=0
+repo_gpgcheck=1
+gpgkey=https://rpm.example.com/foo/repodata/repomd.xml.key
+`, string(bts))
+
+	bts, err = os.ReadFile(calls)
+	require.NoError(t, err)
+	require.Contains(t, string(bts), "--batch --yes --local-user ABC123 --armor --detach-sign --output ")
+	require.Contains(t, string(bts), "--batch --yes --armor --output ")
+
+	// a new release keeps the packages already in the repository.
+	ctx = newContext(t, cfg)
+	addRPM(t, ctx, "foo", "1.1.0", "amd64")
+	require.NoError(t, Pipe{}.Publish(ctx))
+
+	primary := readPrimary(t, repo)
+	require.Len(t, primary.Packages, 3)
+	require.Equal(t, "1.1.0", primary.Packages[2].Version.Ver)
+}
+
+func TestPublishSkip(t *testing.T) {
+	t.Run("skip upload", func(t *testing.T) {
+		ctx := newContext(t, config.YUMRepository{
+			SkipUpload: "true",
+			Blob:       config.RepositoryBlob{Provider: "s3", Bucket: "foo"},
+		})
+		require.True(t, pipe.IsSkip(Pipe{}.Publish(ctx)))
+	})
+
+	t.Run("auto", func(t *testing.T) {
+		ctx := newContext(t, config.YUMRepository{
+			SkipUpload: "auto",
+			Blob:       config.RepositoryBlob{Provider: "s3", Bucket: "foo"},
+		})
+		ctx.Semver.Prerelease = "beta1"
+		require.True(t, pipe.IsSkip(Pipe{}.Publish(ctx)))
+	})
+
+	t.Run("no rpms", func(t *testing.T) {
+		ctx := newContext(t, config.YUMRepository{
+			Blob: config.RepositoryBlob{Provider: "s3", Bucket: "foo"},
+		})
+		ctx.Artifacts.Add(&artifact.Artifact{
+			Name: "foo.deb",
+			Type: artifact.LinuxPackage,
+			Extra: map[string]interface{}{
+				artifact.ExtraFormat: "deb",
+			},
+		})
+		require.EqualError(t, Pipe{}.Publish(ctx), "no rpm packages found")
+	})
+}
+
+func TestPublishSignFails(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "gpg"), []byte("#!/bin/sh\necho no key\nexit 1\n"), 0o755))
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	bucket := t.TempDir()
+	ctx := newContext(t, config.YUMRepository{
+		Blob: config.RepositoryBlob{Provider: "s3", Bucket: bucket},
+	})
+	addRPM(t, ctx, "foo", "1.0.0", "amd64")
+	err := Pipe{}.Publish(ctx)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to sign the repository")
+	require.Contains(t, err.Error(), "no key")
+	require.NoFileExists(t, filepath.Join(bucket, "repodata/repomd.xml"))
+}
+
+// newContext returns a context with the given repository, published to a
+// local folder with fileblob instead of a real bucket.
+func newContext(tb testing.TB, repo config.YUMRepository) *context.Context {
+	tb.Helper()
+	ctx := context.New(config.Project{
+		ProjectName:     "foo",
+		Dist:            tb.TempDir(),
+		YUMRepositories: []config.YUMRepository{repo},
+	})
+	ctx.Git.CurrentTag = "v1.0.0"
+	ctx.Date = time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)
+	require.NoError(tb, Pipe{}.Default(ctx))
+	ctx.Config.YUMRepositories[0].Blob.Provider = "file"
+	return ctx
+}
+
+func addRPM(tb testing.TB, ctx *context.Context, name, version, arch string) {
+	tb.Helper()
+	path := createRPM(tb, ctx.Config.Dist, name, version, arch)
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name:   filepath.Base(path),
+		Path:   path,
+		Goos:   "linux",
+		Goarch: arch,
+		Type:   artifact.LinuxPackage,
+		Extra: map[string]interface{}{
+			artifact.ExtraID:     "default",
+			artifact.ExtraFormat: "rpm",
+		},
+	})
+}
+
+func readPrimary(tb testing.TB, repo string) rawPrimary {
+	tb.Helper()
+	bts, err := os.ReadFile(filepath.Join(repo, "repodata/repomd.xml"))
+	require.NoError(tb, err)
+	var md repomd
+	require.NoError(tb, xml.Unmarshal(bts, &md))
+	gz, err := os.ReadFile(filepath.Join(repo, md.location("primary")))
+	require.NoError(tb, err)
+	bts, err = gunzipped(gz)
+	require.NoError(tb, err)
+	var primary rawPrimary
+	require.NoError(tb, xml.Unmarshal(bts, &primary))
+	return primary
+}
@@ -5,25 +5,42 @@ import (
 	"fmt"
 
 	"github.com/goreleaser/goreleaser/internal/pipe/announce"
+	"github.com/goreleaser/goreleaser/internal/pipe/appimage"
 	"github.com/goreleaser/goreleaser/internal/pipe/archive"
 	"github.com/goreleaser/goreleaser/internal/pipe/artifacts"
+	"github.com/goreleaser/goreleaser/internal/pipe/attestation"
 	"github.com/goreleaser/goreleaser/internal/pipe/aur"
+	"github.com/goreleaser/goreleaser/internal/pipe/authenticode"
 	"github.com/goreleaser/goreleaser/internal/pipe/before"
 	"github.com/goreleaser/goreleaser/internal/pipe/brew"
 	"github.com/goreleaser/goreleaser/internal/pipe/build"
 	"github.com/goreleaser/goreleaser/internal/pipe/changelog"
 	"github.com/goreleaser/goreleaser/internal/pipe/checksums"
+	"github.com/goreleaser/goreleaser/internal/pipe/chocolatey"
+	"github.com/goreleaser/goreleaser/internal/pipe/collision"
 	"github.com/goreleaser/goreleaser/internal/pipe/defaults"
+	"github.com/goreleaser/goreleaser/internal/pipe/directories"
 	"github.com/goreleaser/goreleaser/internal/pipe/dist"
+	"github.com/goreleaser/goreleaser/internal/pipe/dmg"
 	"github.com/goreleaser/goreleaser/internal/pipe/docker"
+	"github.com/goreleaser/goreleaser/internal/pipe/dockerscan"
 	"github.com/goreleaser/goreleaser/internal/pipe/effectiveconfig"
 	"github.com/goreleaser/goreleaser/internal/pipe/env"
+	"github.com/goreleaser/goreleaser/internal/pipe/filesgenerate"
 	"github.com/goreleaser/goreleaser/internal/pipe/git"
 	"github.com/goreleaser/goreleaser/internal/pipe/gofish"
 	"github.com/goreleaser/goreleaser/internal/pipe/gomod"
 	"github.com/goreleaser/goreleaser/internal/pipe/krew"
+	"github.com/goreleaser/goreleaser/internal/pipe/localregistry"
+	"github.com/goreleaser/goreleaser/internal/pipe/macports"
+	"github.com/goreleaser/goreleaser/internal/pipe/metadata"
+	"github.com/goreleaser/goreleaser/internal/pipe/msi"
 	"github.com/goreleaser/goreleaser/internal/pipe/nfpm"
+	"github.com/goreleaser/goreleaser/internal/pipe/nix"
+	"github.com/goreleaser/goreleaser/internal/pipe/notary"
+	"github.com/goreleaser/goreleaser/internal/pipe/pacman"
 	"github.com/goreleaser/goreleaser/internal/pipe/prebuild"
+	"github.com/goreleaser/goreleaser/internal/pipe/provenance"
 	"github.com/goreleaser/goreleaser/internal/pipe/publish"
 	"github.com/goreleaser/goreleaser/internal/pipe/sbom"
 	"github.com/goreleaser/goreleaser/internal/pipe/scoop"
@@ -32,7 +49,10 @@ import (
 	"github.com/goreleaser/goreleaser/internal/pipe/snapcraft"
 	"github.com/goreleaser/goreleaser/internal/pipe/snapshot"
 	"github.com/goreleaser/goreleaser/internal/pipe/sourcearchive"
+	"github.com/goreleaser/goreleaser/internal/pipe/termux"
+	"github.com/goreleaser/goreleaser/internal/pipe/tmplcontext"
 	"github.com/goreleaser/goreleaser/internal/pipe/universalbinary"
+	"github.com/goreleaser/goreleaser/internal/pipe/winget"
 	"github.com/goreleaser/goreleaser/pkg/context"
 )
 
@@ -59,6 +79,7 @@ var BuildPipeline = []Piper{
 	gomod.ProxyPipe{},      // proxy gomod if needed
 	effectiveconfig.Pipe{}, // writes the actual config (with defaults et al set) to dist
 	changelog.Pipe{},       // builds the release changelog
+	collision.Pipe{},       // check for artifact name collisions
 	build.Pipe{},           // build
 	universalbinary.Pipe{}, // universal binary handling
 }
@@ -71,20 +92,40 @@ var BuildCmdPipeline = append(BuildPipeline, artifacts.Pipe{})
 // nolint: gochecknoglobals
 var Pipeline = append(
 	BuildPipeline,
-	archive.Pipe{},       // archive in tar.gz, zip or binary (which does no archiving at all)
-	sourcearchive.Pipe{}, // archive the source code using git-archive
-	nfpm.Pipe{},          // archive via fpm (deb, rpm) using "native" go impl
-	snapcraft.Pipe{},     // archive via snapcraft (snap)
-	aur.Pipe{},           // create arch linux aur pkgbuild
-	brew.Pipe{},          // create brew tap
-	gofish.Pipe{},        // create gofish rig
-	krew.Pipe{},          // krew plugins
-	scoop.Pipe{},         // create scoop buckets
-	sbom.Pipe{},          // create SBOMs of artifacts
-	checksums.Pipe{},     // checksums of the files
-	sign.Pipe{},          // sign artifacts
-	docker.Pipe{},        // create and push docker images
-	artifacts.Pipe{},     // creates an artifacts.json in the dist folder
-	publish.Pipe{},       // publishes artifacts
-	announce.Pipe{},      // announce releases
+	notary.Pipe{},               // codesign and notarize macOS binaries
+	authenticode.Pipe{},         // authenticode sign windows binaries
+	filesgenerate.Pipe{},        // generate files from templates
+	directories.Pipe{},          // add directories to publish as a whole
+	archive.Pipe{},              // archive in tar.gz, tar.zst, tar.lz4, zip, 7z, squashfs or binary (which does no archiving at all)
+	sourcearchive.Pipe{},        // archive the source code using git-archive
+	nfpm.Pipe{},                 // archive via fpm (deb, rpm) using "native" go impl
+	snapcraft.Pipe{},            // archive via snapcraft (snap)
+	appimage.Pipe{},             // package linux binaries as appimages
+	msi.Pipe{},                  // package windows binaries as msi installers
+	dmg.Pipe{},                  // package macos binaries as dmgs
+	aur.Pipe{},                  // create arch linux aur pkgbuild
+	pacman.Pipe{},               // package linux binaries as arch linux pacman packages
+	brew.Pipe{},                 // create brew tap
+	gofish.Pipe{},               // create gofish rig
+	nix.Pipe{},                  // create nix package expressions
+	termux.Pipe{},               // create termux build scripts
+	winget.Pipe{},               // create winget manifests
+	macports.Pipe{},             // create macports portfiles
+	krew.Pipe{},                 // krew plugins
+	scoop.Pipe{},                // create scoop buckets
+	chocolatey.Pipe{},           // pack chocolatey packages
+	sbom.Pipe{},                 // create SBOMs of artifacts
+	checksums.Pipe{},            // checksums of the files
+	provenance.Pipe{},           // SLSA provenance of the artifacts
+	attestation.Pipe{},          // in-toto attestations of the artifacts
+	sign.Pipe{},                 // sign artifacts
+	localregistry.Pipe{},        // start a local registry to push snapshot images to
+	docker.Pipe{},               // create and push docker images
+	localregistry.PublishPipe{}, // push snapshot images and manifests to the local registry
+	dockerscan.Pipe{},           // scan docker images for vulnerabilities
+	artifacts.Pipe{},            // creates an artifacts.json in the dist folder
+	tmplcontext.Pipe{},          // creates a template_context.json in the dist folder, so re-runs render the same templates
+	publish.Pipe{},              // publishes artifacts
+	metadata.Pipe{},             // creates a metadata.json in the dist folder, so announce can be re-run later
+	announce.Pipe{},             // announce releases
 )
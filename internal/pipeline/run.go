@@ -0,0 +1,37 @@
+package pipeline
+
+import (
+	"github.com/goreleaser/goreleaser/internal/deprecate"
+	"github.com/goreleaser/goreleaser/internal/metrics"
+	"github.com/goreleaser/goreleaser/internal/middleware/errhandler"
+	"github.com/goreleaser/goreleaser/internal/middleware/logging"
+	"github.com/goreleaser/goreleaser/internal/middleware/skip"
+	"github.com/goreleaser/goreleaser/pkg/context"
+)
+
+// Run runs the given pipes in order, the same way for goreleaser release,
+// goreleaser build and programs embedding goreleaser: each pipe is skipped
+// if it should be, logged, and measured by the given recorder.
+// The run stops when the context is done, or after a pipe that used
+// deprecated options if failOnDeprecated is set.
+func Run(ctx *context.Context, pipes []Piper, recorder *metrics.Recorder, failOnDeprecated bool) error {
+	for _, pipe := range pipes {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := skip.Maybe(
+			pipe,
+			logging.Log(
+				pipe.String(),
+				recorder.Measure(pipe.String(), errhandler.Handle(pipe.Run)),
+				logging.DefaultInitialPadding,
+			),
+		)(ctx); err != nil {
+			return err
+		}
+		if err := deprecate.Check(ctx, failOnDeprecated); err != nil {
+			return err
+		}
+	}
+	return nil
+}
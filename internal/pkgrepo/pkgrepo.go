@@ -0,0 +1,103 @@
+// Package pkgrepo contains the helpers shared by the pipes publishing package
+// repositories, like apt, yum, apk and pacman ones.
+package pkgrepo
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"gocloud.dev/blob"
+	"gocloud.dev/gcerrors"
+
+	// Import the blob packages we want to be able to open.
+	_ "gocloud.dev/blob/azureblob"
+	_ "gocloud.dev/blob/gcsblob"
+	_ "gocloud.dev/blob/s3blob"
+)
+
+// Store is the bucket a repository is published to.
+type Store struct {
+	bucket *blob.Bucket
+	folder string
+}
+
+// Open opens the given bucket, the files are read from and written to the
+// given folder, which is a template.
+func Open(ctx *context.Context, conf config.RepositoryBlob, folder string) (*Store, error) {
+	folder, err := tmpl.New(ctx).Apply(folder)
+	if err != nil {
+		return nil, err
+	}
+	bucketURL, err := BlobURL(ctx, conf)
+	if err != nil {
+		return nil, err
+	}
+	bucket, err := blob.OpenBucket(ctx, bucketURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open bucket %s: %w", bucketURL, err)
+	}
+	return &Store{bucket: bucket, folder: strings.Trim(folder, "/")}, nil
+}
+
+// BlobURL returns the gocloud URL of the given bucket.
+func BlobURL(ctx *context.Context, conf config.RepositoryBlob) (string, error) {
+	bucket, err := tmpl.New(ctx).Apply(conf.Bucket)
+	if err != nil {
+		return "", err
+	}
+	bucketURL := fmt.Sprintf("%s://%s", conf.Provider, bucket)
+	if conf.Provider != "s3" {
+		return bucketURL, nil
+	}
+	query := url.Values{}
+	if conf.Endpoint != "" {
+		query.Add("endpoint", conf.Endpoint)
+		query.Add("s3ForcePathStyle", "true")
+	}
+	if conf.Region != "" {
+		query.Add("region", conf.Region)
+	}
+	if conf.DisableSSL {
+		query.Add("disableSSL", "true")
+	}
+	if len(query) > 0 {
+		bucketURL = bucketURL + "?" + query.Encode()
+	}
+	return bucketURL, nil
+}
+
+// Read returns the content of the given file, or an error wrapping
+// os.ErrNotExist if it does not exist yet.
+func (s *Store) Read(ctx *context.Context, name string) ([]byte, error) {
+	bts, err := s.bucket.ReadAll(ctx, path.Join(s.folder, name))
+	if gcerrors.Code(err) == gcerrors.NotFound {
+		return nil, fmt.Errorf("%s: %w", name, os.ErrNotExist)
+	}
+	return bts, err
+}
+
+// Write writes the given file.
+func (s *Store) Write(ctx *context.Context, name string, data []byte) error {
+	log.FromContext(ctx).WithField("path", path.Join(s.folder, name)).Debug("uploading")
+	return s.bucket.WriteAll(ctx, path.Join(s.folder, name), data, nil)
+}
+
+// Close closes the bucket.
+func (s *Store) Close() error { return s.bucket.Close() }
+
+// InstallURL returns the url the repository is served from, without the
+// trailing slash, or an empty string if it is not known.
+func InstallURL(ctx *context.Context, repoURL string) string {
+	url, err := tmpl.New(ctx).Apply(repoURL)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSuffix(url, "/")
+}
@@ -0,0 +1,86 @@
+package pkgrepo
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+
+	_ "gocloud.dev/blob/fileblob"
+)
+
+func TestBlobURL(t *testing.T) {
+	ctx := context.New(config.Project{ProjectName: "foo"})
+	for name, tt := range map[string]struct {
+		conf config.RepositoryBlob
+		url  string
+	}{
+		"s3 with opts": {
+			conf: config.RepositoryBlob{
+				Provider:   "s3",
+				Bucket:     "{{ .ProjectName }}",
+				Region:     "us-west-1",
+				Endpoint:   "s3.foobar.com",
+				DisableSSL: true,
+			},
+			url: "s3://foo?disableSSL=true&endpoint=s3.foobar.com&region=us-west-1&s3ForcePathStyle=true",
+		},
+		"s3 no opts": {
+			conf: config.RepositoryBlob{Provider: "s3", Bucket: "foo"},
+			url:  "s3://foo",
+		},
+		"gs ignores opts": {
+			conf: config.RepositoryBlob{Provider: "gs", Bucket: "foo", Region: "us-west-1"},
+			url:  "gs://foo",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			url, err := BlobURL(ctx, tt.conf)
+			require.NoError(t, err)
+			require.Equal(t, tt.url, url)
+		})
+	}
+
+	t.Run("invalid template", func(t *testing.T) {
+		_, err := BlobURL(ctx, config.RepositoryBlob{Provider: "s3", Bucket: "{{ .Nope }"})
+		require.Error(t, err)
+	})
+}
+
+func TestStore(t *testing.T) {
+	bucket := t.TempDir()
+	ctx := context.New(config.Project{ProjectName: "foo"})
+	st, err := Open(ctx, config.RepositoryBlob{
+		Provider: "file",
+		Bucket:   filepath.ToSlash(bucket),
+	}, "/{{ .ProjectName }}/")
+	require.NoError(t, err)
+	defer st.Close()
+
+	_, err = st.Read(ctx, "index")
+	require.ErrorIs(t, err, os.ErrNotExist)
+
+	require.NoError(t, st.Write(ctx, "index", []byte("packages")))
+	bts, err := st.Read(ctx, "index")
+	require.NoError(t, err)
+	require.Equal(t, "packages", string(bts))
+	require.FileExists(t, filepath.Join(bucket, "foo", "index"))
+}
+
+func TestOpenInvalidFolder(t *testing.T) {
+	_, err := Open(context.New(config.Project{}), config.RepositoryBlob{
+		Provider: "file",
+		Bucket:   filepath.ToSlash(t.TempDir()),
+	}, "{{ .Nope }")
+	require.Error(t, err)
+}
+
+func TestInstallURL(t *testing.T) {
+	ctx := context.New(config.Project{ProjectName: "foo"})
+	require.Equal(t, "https://example.com/foo", InstallURL(ctx, "https://example.com/{{ .ProjectName }}/"))
+	require.Empty(t, InstallURL(ctx, ""))
+	require.Empty(t, InstallURL(ctx, "{{ .Nope }"))
+}
@@ -0,0 +1,214 @@
+// Package platforms resolves the custom platform aliases of a project, e.g.
+// raspberry-pi for linux_arm64 and linux_arm_7, the goarm wildcard of targets,
+// e.g. linux_arm_*, and the human friendly names of targets.
+package platforms
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/goreleaser/goreleaser/pkg/config"
+)
+
+// Validate checks the platform definitions.
+func Validate(platforms []config.Platform) error {
+	seen := map[string]bool{}
+	for i, p := range platforms {
+		if p.Name == "" {
+			return fmt.Errorf("platform %d: name is required", i)
+		}
+		if strings.ContainsAny(p.Name, "_/") {
+			return fmt.Errorf("platform %s: name can't contain _ or /, as it would be mistaken for a target", p.Name)
+		}
+		if seen[p.Name] {
+			return fmt.Errorf("platform %s: defined more than once", p.Name)
+		}
+		seen[p.Name] = true
+		if len(p.Targets) == 0 {
+			return fmt.Errorf("platform %s: targets are required", p.Name)
+		}
+	}
+	return nil
+}
+
+// Expand replaces the platform aliases in the given targets by their
+// targets, removing duplicates.
+// Targets can be written as linux/arm/7 as well, they are normalized to
+// linux_arm_7.
+func Expand(platforms []config.Platform, targets []string) []string {
+	seen := map[string]bool{}
+	var result []string
+	add := func(target string) {
+		target = normalize(target)
+		if !seen[target] {
+			seen[target] = true
+			result = append(result, target)
+		}
+	}
+	for _, target := range targets {
+		if p, ok := find(platforms, target); ok {
+			for _, t := range p.Targets {
+				add(t)
+			}
+			continue
+		}
+		add(target)
+	}
+	return result
+}
+
+// defaultGoarm are the arm variants the goarm wildcard expands to if the
+// build doesn't set any: armv6 runs on all raspberry pis, armv7 is faster on
+// most other boards.
+var defaultGoarm = []string{"6", "7"}
+
+// ExpandVariants replaces the goarm wildcard in the given targets, e.g.
+// linux_arm_*, by a target for each of the given goarm, or for armv6 and
+// armv7 if there are none, removing duplicates.
+func ExpandVariants(targets, goarm []string) []string {
+	if len(goarm) == 0 {
+		goarm = defaultGoarm
+	}
+	seen := map[string]bool{}
+	var result []string
+	add := func(target string) {
+		if !seen[target] {
+			seen[target] = true
+			result = append(result, target)
+		}
+	}
+	for _, target := range targets {
+		target = normalize(target)
+		if prefix := strings.TrimSuffix(target, "_*"); prefix != target && strings.HasSuffix(prefix, "_arm") {
+			for _, arm := range goarm {
+				add(prefix + "_" + arm)
+			}
+			continue
+		}
+		add(target)
+	}
+	return result
+}
+
+// Of returns the name of the first platform alias the given target belongs
+// to, or an empty string if none.
+func Of(platforms []config.Platform, target string) string {
+	for _, p := range platforms {
+		if Matches(p.Targets, target) {
+			return p.Name
+		}
+	}
+	return ""
+}
+
+// Matches tells whether the given target is one of the given targets.
+// Targets without goarm or gomips match all of them, e.g. linux_arm matches
+// linux_arm_6 and linux_arm_7.
+func Matches(targets []string, target string) bool {
+	for _, t := range targets {
+		t = normalize(t)
+		if target == t || strings.HasPrefix(target, t+"_") {
+			return true
+		}
+	}
+	return false
+}
+
+func find(platforms []config.Platform, name string) (config.Platform, bool) {
+	for _, p := range platforms {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return config.Platform{}, false
+}
+
+func normalize(target string) string {
+	return strings.ReplaceAll(target, "/", "_")
+}
+
+// Name returns the human friendly name of the given target, e.g.
+// macOS (Apple Silicon) for darwin_arm64, or Linux ARMv7 for linux_arm_7.
+// names overrides the defaults, by target, by goos_goarch, or by goos alone.
+func Name(names map[string]string, target string) string {
+	target = normalize(target)
+	if target == "" {
+		return ""
+	}
+	parts := strings.SplitN(target, "_", 3)
+	candidates := []string{target}
+	if len(parts) == 3 {
+		candidates = append(candidates, parts[0]+"_"+parts[1])
+	}
+	for _, table := range []map[string]string{names, defaultNames} {
+		for _, c := range candidates {
+			if name, ok := table[c]; ok {
+				return name
+			}
+		}
+	}
+
+	osName := lookup(parts[0], names, osNames)
+	if len(parts) == 1 {
+		return osName
+	}
+	arch := lookup(parts[1], archNames)
+	if len(parts) == 3 {
+		if parts[1] == "arm" {
+			arch = "ARMv" + parts[2]
+		} else {
+			arch += " (" + parts[2] + ")"
+		}
+	}
+	return osName + " " + arch
+}
+
+func lookup(key string, tables ...map[string]string) string {
+	for _, table := range tables {
+		if v, ok := table[key]; ok {
+			return v
+		}
+	}
+	return key
+}
+
+// defaultNames are the names of the targets that can't be composed from the
+// names of their goos and goarch.
+var defaultNames = map[string]string{
+	"darwin_amd64": "macOS (Intel)",
+	"darwin_arm64": "macOS (Apple Silicon)",
+	"darwin_all":   "macOS (Universal)",
+}
+
+var osNames = map[string]string{
+	"aix":       "AIX",
+	"android":   "Android",
+	"darwin":    "macOS",
+	"dragonfly": "DragonFly BSD",
+	"freebsd":   "FreeBSD",
+	"illumos":   "illumos",
+	"ios":       "iOS",
+	"js":        "JavaScript",
+	"linux":     "Linux",
+	"netbsd":    "NetBSD",
+	"openbsd":   "OpenBSD",
+	"plan9":     "Plan 9",
+	"solaris":   "Solaris",
+	"windows":   "Windows",
+}
+
+var archNames = map[string]string{
+	"386":      "32-bit",
+	"amd64":    "64-bit",
+	"arm":      "ARM",
+	"arm64":    "ARM64",
+	"mips":     "MIPS",
+	"mipsle":   "MIPS LE",
+	"mips64":   "MIPS64",
+	"mips64le": "MIPS64 LE",
+	"ppc64":    "PowerPC 64",
+	"ppc64le":  "PowerPC 64 LE",
+	"riscv64":  "RISC-V 64-bit",
+	"s390x":    "IBM Z",
+	"wasm":     "WebAssembly",
+}
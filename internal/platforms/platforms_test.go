@@ -0,0 +1,110 @@
+package platforms
+
+import (
+	"testing"
+
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/stretchr/testify/require"
+)
+
+var defs = []config.Platform{
+	{Name: "raspberry-pi", Targets: []string{"linux/arm64", "linux/arm/7"}},
+	{Name: "desktop", Targets: []string{"linux_amd64", "darwin_amd64", "windows_amd64"}},
+	{Name: "arm", Targets: []string{"linux_arm"}},
+}
+
+func TestValidate(t *testing.T) {
+	require.NoError(t, Validate(defs))
+	require.NoError(t, Validate(nil))
+	for expected, platforms := range map[string][]config.Platform{
+		"platform 0: name is required": {{Targets: []string{"linux_amd64"}}},
+		"platform linux_amd64: name can't contain _ or /, as it would be mistaken for a target": {{Name: "linux_amd64", Targets: []string{"linux_amd64"}}},
+		"platform pi: defined more than once": {
+			{Name: "pi", Targets: []string{"linux_arm64"}},
+			{Name: "pi", Targets: []string{"linux_arm_7"}},
+		},
+		"platform pi: targets are required": {{Name: "pi"}},
+	} {
+		t.Run(expected, func(t *testing.T) {
+			require.EqualError(t, Validate(platforms), expected)
+		})
+	}
+}
+
+func TestExpand(t *testing.T) {
+	require.Equal(t, []string{
+		"linux_arm64",
+		"linux_arm_7",
+		"linux_amd64",
+		"darwin_amd64",
+		"windows_amd64",
+		"freebsd_amd64",
+	}, Expand(defs, []string{"raspberry-pi", "desktop", "linux_amd64", "freebsd/amd64"}))
+	require.Empty(t, Expand(defs, nil))
+}
+
+func TestOf(t *testing.T) {
+	require.Equal(t, "raspberry-pi", Of(defs, "linux_arm_7"))
+	require.Equal(t, "arm", Of(defs, "linux_arm_6"))
+	require.Equal(t, "desktop", Of(defs, "darwin_amd64"))
+	require.Equal(t, "", Of(defs, "darwin_arm64"))
+	require.Equal(t, "", Of(defs, ""))
+}
+
+func TestMatches(t *testing.T) {
+	require.True(t, Matches([]string{"linux_arm"}, "linux_arm_7"))
+	require.True(t, Matches([]string{"linux/arm/7"}, "linux_arm_7"))
+	require.False(t, Matches([]string{"linux_arm"}, "linux_arm64"))
+	require.False(t, Matches(nil, "linux_arm64"))
+}
+
+func TestName(t *testing.T) {
+	for target, name := range map[string]string{
+		"":                      "",
+		"darwin_arm64":          "macOS (Apple Silicon)",
+		"darwin/amd64":          "macOS (Intel)",
+		"darwin_all":            "macOS (Universal)",
+		"windows_amd64":         "Windows 64-bit",
+		"windows_386":           "Windows 32-bit",
+		"linux_arm_7":           "Linux ARMv7",
+		"linux_mips_softfloat":  "Linux MIPS (softfloat)",
+		"freebsd_arm64":         "FreeBSD ARM64",
+		"linux":                 "Linux",
+		"hurd_amd64":            "hurd 64-bit",
+		"linux_loong64":         "Linux loong64",
+		"windows_arm64":         "Windows ARM64",
+		"linux_ppc64le":         "Linux PowerPC 64 LE",
+		"js_wasm":               "JavaScript WebAssembly",
+		"netbsd_arm_6":          "NetBSD ARMv6",
+		"darwin_amd64_whatever": "macOS (Intel)",
+	} {
+		require.Equal(t, name, Name(nil, target), target)
+	}
+}
+
+func TestNameOverrides(t *testing.T) {
+	names := map[string]string{
+		"darwin":       "Mac",
+		"linux_arm_7":  "Raspberry Pi",
+		"linux_amd64":  "Linux x86_64",
+		"darwin_arm64": "Mac M1",
+	}
+	require.Equal(t, "Mac M1", Name(names, "darwin_arm64"))
+	require.Equal(t, "macOS (Intel)", Name(names, "darwin_amd64"))
+	require.Equal(t, "Mac PowerPC 64", Name(names, "darwin_ppc64"))
+	require.Equal(t, "Raspberry Pi", Name(names, "linux_arm_7"))
+	require.Equal(t, "Linux ARMv6", Name(names, "linux_arm_6"))
+	require.Equal(t, "Linux x86_64", Name(names, "linux_amd64"))
+}
+
+func TestExpandVariants(t *testing.T) {
+	require.Equal(t, []string{
+		"linux_arm_6",
+		"linux_arm_7",
+		"linux_amd64",
+		"freebsd_arm_6",
+		"freebsd_arm_7",
+	}, ExpandVariants([]string{"linux/arm/*", "linux_amd64", "linux_arm_7", "freebsd_arm_*"}, nil))
+	require.Equal(t, []string{"linux_arm_5", "linux_arm_7"}, ExpandVariants([]string{"linux/arm/*"}, []string{"5", "7"}))
+	require.Equal(t, []string{"linux_arm64_*"}, ExpandVariants([]string{"linux/arm64/*"}, nil))
+}
@@ -0,0 +1,56 @@
+package sarif
+
+import (
+	"regexp"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+var segmentRe = regexp.MustCompile(`([^.\[\]]+)|\[(\d+)\]`)
+
+// Locate returns the line of the given property path, e.g. builds[0].goos,
+// in the given yaml content.
+// If the property is not in the content, the line of its closest parent is
+// returned instead, or 0 if none of them are.
+// Properties of lists without an index are looked up in every item, which
+// allows to locate properties like dockers.use_buildx.
+func Locate(content []byte, path string) int {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil || len(doc.Content) == 0 {
+		return 0
+	}
+	return locate(doc.Content[0], segmentRe.FindAllStringSubmatch(path, -1), 0)
+}
+
+func locate(node *yaml.Node, segments [][]string, line int) int {
+	if len(segments) == 0 {
+		return line
+	}
+	key, index := segments[0][1], segments[0][2]
+	switch node.Kind {
+	case yaml.MappingNode:
+		if index != "" {
+			return line
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == key {
+				return locate(node.Content[i+1], segments[1:], node.Content[i].Line)
+			}
+		}
+	case yaml.SequenceNode:
+		if index != "" {
+			i, _ := strconv.Atoi(index)
+			if i < len(node.Content) {
+				return locate(node.Content[i], segments[1:], node.Content[i].Line)
+			}
+			return line
+		}
+		for _, item := range node.Content {
+			if found := locate(item, segments, 0); found != 0 {
+				return found
+			}
+		}
+	}
+	return line
+}
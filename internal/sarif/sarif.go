@@ -0,0 +1,229 @@
+// Package sarif reports configuration findings in the SARIF 2.1.0 format, so
+// code scanning tools can annotate the configuration file with them.
+package sarif
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+)
+
+const (
+	schema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	version = "2.1.0"
+)
+
+// Level of a finding.
+type Level string
+
+const (
+	// Error means the release will fail.
+	Error Level = "error"
+	// Warning means the release works, but might break in the future.
+	Warning Level = "warning"
+	// Note is purely informational.
+	Note Level = "note"
+)
+
+// Rule is a kind of finding.
+type Rule struct {
+	ID          string
+	Description string
+	HelpURI     string
+}
+
+// Rules reported by goreleaser.
+var (
+	InvalidConfig = Rule{
+		ID:          "invalid-config",
+		Description: "The configuration is invalid",
+		HelpURI:     "https://goreleaser.com/customization/",
+	}
+	DeprecatedProperty = Rule{
+		ID:          "deprecated-property",
+		Description: "The configuration uses deprecated properties",
+		HelpURI:     "https://goreleaser.com/deprecations/",
+	}
+	ArchiveNameCollision = Rule{
+		ID:          "archive-name-collision",
+		Description: "Archives would be created with the same name",
+		HelpURI:     "https://goreleaser.com/customization/archive/",
+	}
+	BaselineDrift = Rule{
+		ID:          "baseline-drift",
+		Description: "The configuration differs from the baseline",
+		HelpURI:     "https://goreleaser.com/cmd/goreleaser_check/",
+	}
+)
+
+// Finding is a single problem found in the configuration.
+type Finding struct {
+	Rule    Rule
+	Level   Level
+	Message string
+	// Path of the property the finding is about, e.g. builds[0].goos.
+	// It is used to find the line of the finding if Line is not set.
+	Path string
+	// Line of the finding in the configuration file, if known.
+	Line int
+}
+
+// Report is a list of findings about a configuration file.
+type Report struct {
+	// File is the path of the configuration file.
+	File string
+	// Version of goreleaser.
+	Version  string
+	Findings []Finding
+}
+
+// Add adds the given finding to the report.
+func (r *Report) Add(f Finding) {
+	r.Findings = append(r.Findings, f)
+}
+
+// WriteFile writes the report to the given path, or to stdout if the path
+// is -.
+func (r *Report) WriteFile(path string) error {
+	if path == "-" {
+		return r.Write(os.Stdout)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := r.Write(f); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Write writes the report as SARIF to the given writer.
+func (r *Report) Write(w io.Writer) error {
+	// the config file might not exist, in which case findings are reported
+	// without a line.
+	content, _ := os.ReadFile(r.File)
+
+	driver := driverJSON{
+		Name:           "goreleaser",
+		InformationURI: "https://goreleaser.com",
+		Version:        r.Version,
+		Rules:          []ruleJSON{},
+	}
+	seen := map[string]bool{}
+	results := []resultJSON{}
+	for _, f := range r.Findings {
+		if !seen[f.Rule.ID] {
+			seen[f.Rule.ID] = true
+			driver.Rules = append(driver.Rules, ruleJSON{
+				ID:               f.Rule.ID,
+				ShortDescription: textJSON{Text: f.Rule.Description},
+				HelpURI:          f.Rule.HelpURI,
+			})
+		}
+		line := f.Line
+		if line == 0 && f.Path != "" {
+			line = Locate(content, f.Path)
+		}
+		if line == 0 {
+			line = 1
+		}
+		result := resultJSON{
+			RuleID:  f.Rule.ID,
+			Level:   f.Level,
+			Message: textJSON{Text: f.Message},
+		}
+		if r.File != "" {
+			result.Locations = []locationJSON{{
+				PhysicalLocation: physicalLocationJSON{
+					ArtifactLocation: artifactLocationJSON{URI: r.File},
+					Region:           regionJSON{StartLine: line},
+				},
+			}}
+		}
+		results = append(results, result)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(logJSON{
+		Schema:  schema,
+		Version: version,
+		Runs: []runJSON{{
+			Tool:    toolJSON{Driver: driver},
+			Results: results,
+		}},
+	})
+}
+
+var lineRe = regexp.MustCompile(`line (\d+)`)
+
+// LineOf returns the line of the first yaml error in the given error, or 0 if
+// there is none.
+func LineOf(err error) int {
+	match := lineRe.FindStringSubmatch(err.Error())
+	if match == nil {
+		return 0
+	}
+	line, _ := strconv.Atoi(match[1])
+	return line
+}
+
+type logJSON struct {
+	Schema  string    `json:"$schema"`
+	Version string    `json:"version"`
+	Runs    []runJSON `json:"runs"`
+}
+
+type runJSON struct {
+	Tool    toolJSON     `json:"tool"`
+	Results []resultJSON `json:"results"`
+}
+
+type toolJSON struct {
+	Driver driverJSON `json:"driver"`
+}
+
+type driverJSON struct {
+	Name           string     `json:"name"`
+	InformationURI string     `json:"informationUri"`
+	Version        string     `json:"version,omitempty"`
+	Rules          []ruleJSON `json:"rules"`
+}
+
+type ruleJSON struct {
+	ID               string   `json:"id"`
+	ShortDescription textJSON `json:"shortDescription"`
+	HelpURI          string   `json:"helpUri,omitempty"`
+}
+
+type textJSON struct {
+	Text string `json:"text"`
+}
+
+type resultJSON struct {
+	RuleID    string         `json:"ruleId"`
+	Level     Level          `json:"level"`
+	Message   textJSON       `json:"message"`
+	Locations []locationJSON `json:"locations,omitempty"`
+}
+
+type locationJSON struct {
+	PhysicalLocation physicalLocationJSON `json:"physicalLocation"`
+}
+
+type physicalLocationJSON struct {
+	ArtifactLocation artifactLocationJSON `json:"artifactLocation"`
+	Region           regionJSON           `json:"region"`
+}
+
+type artifactLocationJSON struct {
+	URI string `json:"uri"`
+}
+
+type regionJSON struct {
+	StartLine int `json:"startLine"`
+}
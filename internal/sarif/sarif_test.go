@@ -0,0 +1,106 @@
+package sarif
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const config = `project_name: foo
+builds:
+  - id: a
+    goos:
+      - linux
+  - id: b
+dockers:
+  - image_templates:
+      - foo
+  - use_buildx: true
+`
+
+func TestLocate(t *testing.T) {
+	for path, line := range map[string]int{
+		"project_name":       1,
+		"builds":             2,
+		"builds[0]":          3,
+		"builds[0].goos":     4,
+		"builds[1].id":       6,
+		"builds[5]":          2,
+		"builds[1].goos":     6,
+		"dockers.use_buildx": 10,
+		"archives":           0,
+		"project_name[0]":    1,
+	} {
+		require.Equal(t, line, Locate([]byte(config), path), path)
+	}
+}
+
+func TestLocateInvalidYAML(t *testing.T) {
+	require.Equal(t, 0, Locate([]byte("foo: [bar"), "foo"))
+	require.Equal(t, 0, Locate(nil, "foo"))
+}
+
+func TestLineOf(t *testing.T) {
+	require.Equal(t, 12, LineOf(errors.New("yaml: line 12: did not find expected key")))
+	require.Equal(t, 0, LineOf(errors.New("nope")))
+}
+
+func TestWrite(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "goreleaser.yml")
+	require.NoError(t, os.WriteFile(file, []byte(config), 0o644))
+
+	report := &Report{File: file, Version: "1.2.3"}
+	report.Add(Finding{Rule: DeprecatedProperty, Level: Warning, Message: "deprecated", Path: "dockers.use_buildx"})
+	report.Add(Finding{Rule: InvalidConfig, Level: Error, Message: "invalid", Line: 3})
+	report.Add(Finding{Rule: InvalidConfig, Level: Error, Message: "invalid again"})
+
+	var out bytes.Buffer
+	require.NoError(t, report.Write(&out))
+
+	var log logJSON
+	require.NoError(t, json.Unmarshal(out.Bytes(), &log))
+	require.Equal(t, "2.1.0", log.Version)
+	require.Len(t, log.Runs, 1)
+	run := log.Runs[0]
+	require.Equal(t, "goreleaser", run.Tool.Driver.Name)
+	require.Equal(t, "1.2.3", run.Tool.Driver.Version)
+	require.Equal(t, []ruleJSON{
+		{ID: "deprecated-property", ShortDescription: textJSON{Text: DeprecatedProperty.Description}, HelpURI: DeprecatedProperty.HelpURI},
+		{ID: "invalid-config", ShortDescription: textJSON{Text: InvalidConfig.Description}, HelpURI: InvalidConfig.HelpURI},
+	}, run.Tool.Driver.Rules)
+
+	require.Len(t, run.Results, 3)
+	for i, line := range []int{10, 3, 1} {
+		result := run.Results[i]
+		require.Len(t, result.Locations, 1)
+		require.Equal(t, file, result.Locations[0].PhysicalLocation.ArtifactLocation.URI)
+		require.Equal(t, line, result.Locations[0].PhysicalLocation.Region.StartLine)
+	}
+	require.Equal(t, Warning, run.Results[0].Level)
+	require.Equal(t, "deprecated", run.Results[0].Message.Text)
+}
+
+func TestWriteEmpty(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, (&Report{}).Write(&out))
+	require.Contains(t, out.String(), `"results": []`)
+	require.Contains(t, out.String(), `"rules": []`)
+}
+
+func TestWriteFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.sarif")
+	report := &Report{}
+	report.Add(Finding{Rule: InvalidConfig, Level: Error, Message: "invalid"})
+	require.NoError(t, report.WriteFile(path))
+	bts, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Contains(t, string(bts), `"ruleId": "invalid-config"`)
+	require.NotContains(t, string(bts), `"locations"`)
+
+	require.Error(t, report.WriteFile(filepath.Join(path, "nope")))
+}
@@ -0,0 +1,182 @@
+// Package sharedlib lays out builds with -buildmode=c-shared as versioned
+// shared libraries, with SONAME-style symlinks and a pkg-config file, so they
+// can be used by C toolchains.
+package sharedlib
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+)
+
+const buildmodeShared = "-buildmode=c-shared"
+
+// File is a file of the shared library layout.
+type File struct {
+	// Source is the path of the file on disk.
+	// For symlinks, it is a symlink to Link.
+	Source string
+	// Destination is the path of the file in the archive or package.
+	Destination string
+	// Link is the target of the symlink, relative to it, or empty if the file
+	// is not a symlink.
+	Link string
+}
+
+// IsShared tells whether the given binary was built with -buildmode=c-shared.
+func IsShared(ctx *context.Context, binary *artifact.Artifact) bool {
+	id := binary.ExtraOr(artifact.ExtraID, "").(string)
+	for _, build := range ctx.Config.Builds {
+		if build.ID != id {
+			continue
+		}
+		for _, flag := range build.Flags {
+			if flag == buildmodeShared {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Files returns the files of the shared library of the given binary:
+// the library itself, named after the current version, its symlinks, its C
+// header, if any, and its pkg-config file.
+// Symlinks and the pkg-config file are written to dir.
+// root is where the lib and include folders are, e.g. /usr in packages, or
+// empty in archives.
+// The pkg-config prefix defaults to root, or to a path relative to the
+// pkg-config file if root is empty.
+func Files(ctx *context.Context, conf config.SharedLibrary, binary *artifact.Artifact, root, dir string) ([]File, error) {
+	t := tmpl.New(ctx).WithArtifact(binary, nil)
+	name, err := t.Apply(conf.Name)
+	if err != nil {
+		return nil, fmt.Errorf("shared library: failed to apply name template: %w", err)
+	}
+	description, err := t.Apply(conf.Description)
+	if err != nil {
+		return nil, fmt.Errorf("shared library: failed to apply description template: %w", err)
+	}
+	prefix, err := t.Apply(conf.Prefix)
+	if err != nil {
+		return nil, fmt.Errorf("shared library: failed to apply prefix template: %w", err)
+	}
+	var requires []string
+	for _, r := range conf.Requires {
+		v, err := t.Apply(r)
+		if err != nil {
+			return nil, fmt.Errorf("shared library: failed to apply requires template: %w", err)
+		}
+		requires = append(requires, v)
+	}
+
+	lib := binary.ExtraOr(artifact.ExtraBinary, binary.Name).(string)
+	link := strings.TrimPrefix(lib, "lib")
+	if name == "" {
+		name = link
+	}
+	if description == "" {
+		description = name
+	}
+	if prefix == "" {
+		prefix = root
+		if prefix == "" {
+			prefix = "${pcfiledir}/../.."
+		}
+	}
+	libdir := path.Join(root, "lib")
+	includedir := path.Join(root, "include")
+
+	names := libraryNames(ctx, lib, binary.Goos)
+	result := []File{{
+		Source:      binary.Path,
+		Destination: path.Join(libdir, names[0]),
+	}}
+	if len(names) > 1 {
+		// the library itself is linked in dir as well, so the symlinks there
+		// are not dangling.
+		abs, err := filepath.Abs(binary.Path)
+		if err != nil {
+			return nil, err
+		}
+		if err := os.Symlink(abs, filepath.Join(dir, names[0])); err != nil {
+			return nil, fmt.Errorf("shared library: failed to create symlink %s: %w", names[0], err)
+		}
+	}
+	for i := 1; i < len(names); i++ {
+		src := filepath.Join(dir, names[i])
+		if err := os.Symlink(names[i-1], src); err != nil {
+			return nil, fmt.Errorf("shared library: failed to create symlink %s: %w", names[i], err)
+		}
+		result = append(result, File{
+			Source:      src,
+			Destination: path.Join(libdir, names[i]),
+			Link:        names[i-1],
+		})
+	}
+
+	header := strings.TrimSuffix(binary.Path, filepath.Ext(binary.Path)) + ".h"
+	if _, err := os.Stat(header); err == nil {
+		result = append(result, File{
+			Source:      header,
+			Destination: path.Join(includedir, filepath.Base(header)),
+		})
+	}
+
+	pc := filepath.Join(dir, name+".pc")
+	if err := os.WriteFile(pc, []byte(pkgConfig(ctx, prefix, name, description, link, requires)), 0o644); err != nil { //nolint: gosec
+		return nil, fmt.Errorf("shared library: failed to write pkg-config file: %w", err)
+	}
+	return append(result, File{
+		Source:      pc,
+		Destination: path.Join(libdir, "pkgconfig", name+".pc"),
+	}), nil
+}
+
+// libraryNames returns the names of the library, the first being the actual
+// file, and each of the next ones a symlink to the previous one, e.g.
+// libfoo.so.1.2.3, libfoo.so.1 and libfoo.so.
+// Windows has no symlinks, so only the dll is returned.
+func libraryNames(ctx *context.Context, lib, goos string) []string {
+	major := fmt.Sprintf("%d", ctx.Semver.Major)
+	full := fmt.Sprintf("%d.%d.%d", ctx.Semver.Major, ctx.Semver.Minor, ctx.Semver.Patch)
+	switch goos {
+	case "windows":
+		return []string{lib + ".dll"}
+	case "darwin":
+		return []string{
+			lib + "." + full + ".dylib",
+			lib + "." + major + ".dylib",
+			lib + ".dylib",
+		}
+	default:
+		return []string{
+			lib + ".so." + full,
+			lib + ".so." + major,
+			lib + ".so",
+		}
+	}
+}
+
+func pkgConfig(ctx *context.Context, prefix, name, description, link string, requires []string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "prefix=%s\n", prefix)
+	b.WriteString("libdir=${prefix}/lib\n")
+	b.WriteString("includedir=${prefix}/include\n\n")
+	fmt.Fprintf(&b, "Name: %s\n", name)
+	fmt.Fprintf(&b, "Description: %s\n", description)
+	fmt.Fprintf(&b, "Version: %s\n", ctx.Version)
+	if len(requires) > 0 {
+		fmt.Fprintf(&b, "Requires: %s\n", strings.Join(requires, ", "))
+	}
+	fmt.Fprintf(&b, "Libs: -L${libdir} -l%s\n", link)
+	b.WriteString("Cflags: -I${includedir}\n")
+	return b.String()
+}
@@ -0,0 +1,192 @@
+package sharedlib
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsShared(t *testing.T) {
+	ctx := context.New(config.Project{
+		Builds: []config.Build{
+			{ID: "lib", Flags: []string{"-trimpath", "-buildmode=c-shared"}},
+			{ID: "cli"},
+		},
+	})
+	require.True(t, IsShared(ctx, &artifact.Artifact{Extra: map[string]interface{}{artifact.ExtraID: "lib"}}))
+	require.False(t, IsShared(ctx, &artifact.Artifact{Extra: map[string]interface{}{artifact.ExtraID: "cli"}}))
+	require.False(t, IsShared(ctx, &artifact.Artifact{}))
+}
+
+func TestFiles(t *testing.T) {
+	folder := t.TempDir()
+	binary := filepath.Join(folder, "libfoo")
+	require.NoError(t, os.WriteFile(binary, []byte("lib"), 0o755))
+	require.NoError(t, os.WriteFile(binary+".h", []byte("header"), 0o644))
+
+	ctx := newContext()
+	for name, tt := range map[string]struct {
+		goos  string
+		root  string
+		files []File
+	}{
+		"linux archive": {
+			goos: "linux",
+			files: []File{
+				{Source: binary, Destination: "lib/libfoo.so.1.2.3"},
+				{Destination: "lib/libfoo.so.1", Link: "libfoo.so.1.2.3"},
+				{Destination: "lib/libfoo.so", Link: "libfoo.so.1"},
+				{Source: binary + ".h", Destination: "include/libfoo.h"},
+				{Destination: "lib/pkgconfig/foo.pc"},
+			},
+		},
+		"linux package": {
+			goos: "linux",
+			root: "/usr",
+			files: []File{
+				{Source: binary, Destination: "/usr/lib/libfoo.so.1.2.3"},
+				{Destination: "/usr/lib/libfoo.so.1", Link: "libfoo.so.1.2.3"},
+				{Destination: "/usr/lib/libfoo.so", Link: "libfoo.so.1"},
+				{Source: binary + ".h", Destination: "/usr/include/libfoo.h"},
+				{Destination: "/usr/lib/pkgconfig/foo.pc"},
+			},
+		},
+		"darwin": {
+			goos: "darwin",
+			files: []File{
+				{Source: binary, Destination: "lib/libfoo.1.2.3.dylib"},
+				{Destination: "lib/libfoo.1.dylib", Link: "libfoo.1.2.3.dylib"},
+				{Destination: "lib/libfoo.dylib", Link: "libfoo.1.dylib"},
+				{Source: binary + ".h", Destination: "include/libfoo.h"},
+				{Destination: "lib/pkgconfig/foo.pc"},
+			},
+		},
+		"windows": {
+			goos: "windows",
+			files: []File{
+				{Source: binary, Destination: "lib/libfoo.dll"},
+				{Source: binary + ".h", Destination: "include/libfoo.h"},
+				{Destination: "lib/pkgconfig/foo.pc"},
+			},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			files, err := Files(ctx, config.SharedLibrary{}, &artifact.Artifact{
+				Name: "libfoo",
+				Path: binary,
+				Goos: tt.goos,
+				Extra: map[string]interface{}{
+					artifact.ExtraBinary: "libfoo",
+				},
+			}, tt.root, dir)
+			require.NoError(t, err)
+			require.Len(t, files, len(tt.files))
+			for i, f := range files {
+				require.Equal(t, tt.files[i].Destination, f.Destination)
+				require.Equal(t, tt.files[i].Link, f.Link)
+				if tt.files[i].Source != "" {
+					require.Equal(t, tt.files[i].Source, f.Source)
+				}
+				if f.Link != "" {
+					link, err := os.Readlink(f.Source)
+					require.NoError(t, err)
+					require.Equal(t, f.Link, link)
+				}
+			}
+		})
+	}
+}
+
+func TestPkgConfig(t *testing.T) {
+	binary := filepath.Join(t.TempDir(), "libfoo.so")
+	require.NoError(t, os.WriteFile(binary, []byte("lib"), 0o755))
+
+	ctx := newContext()
+	ctx.Env["DESC"] = "the foo library"
+	for name, tt := range map[string]struct {
+		conf     config.SharedLibrary
+		root     string
+		expected string
+	}{
+		"archive": {
+			expected: `prefix=${pcfiledir}/../..
+libdir=${prefix}/lib
+includedir=${prefix}/include
+
+Name: foo
+Description: foo
+Version: 1.2.3
+Libs: -L${libdir} -lfoo
+Cflags: -I${includedir}
+`,
+		},
+		"package": {
+			root: "/usr",
+			conf: config.SharedLibrary{
+				Name:        "foo-{{ .Major }}",
+				Description: "{{ .Env.DESC }}",
+				Requires:    []string{"zlib", "libssl >= 1.1"},
+			},
+			expected: `prefix=/usr
+libdir=${prefix}/lib
+includedir=${prefix}/include
+
+Name: foo-1
+Description: the foo library
+Version: 1.2.3
+Requires: zlib, libssl >= 1.1
+Libs: -L${libdir} -lfoo
+Cflags: -I${includedir}
+`,
+		},
+		"custom prefix": {
+			root: "/usr",
+			conf: config.SharedLibrary{Prefix: "/opt/foo"},
+			expected: `prefix=/opt/foo
+libdir=${prefix}/lib
+includedir=${prefix}/include
+
+Name: foo
+Description: foo
+Version: 1.2.3
+Libs: -L${libdir} -lfoo
+Cflags: -I${includedir}
+`,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			files, err := Files(ctx, tt.conf, &artifact.Artifact{
+				Name:  "libfoo.so",
+				Path:  binary,
+				Goos:  "linux",
+				Extra: map[string]interface{}{artifact.ExtraBinary: "libfoo"},
+			}, tt.root, t.TempDir())
+			require.NoError(t, err)
+			bts, err := os.ReadFile(files[len(files)-1].Source)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, string(bts))
+		})
+	}
+}
+
+func TestFilesInvalidTemplate(t *testing.T) {
+	_, err := Files(newContext(), config.SharedLibrary{
+		Name: "{{ .Nope }",
+	}, &artifact.Artifact{Goos: "linux"}, "", t.TempDir())
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "shared library: failed to apply name template")
+}
+
+func newContext() *context.Context {
+	ctx := context.New(config.Project{})
+	ctx.Version = "1.2.3"
+	ctx.Git.CurrentTag = "v1.2.3"
+	ctx.Semver = context.Semver{Major: 1, Minor: 2, Patch: 3}
+	return ctx
+}
@@ -0,0 +1,51 @@
+package shell
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/goreleaser/goreleaser/pkg/context"
+)
+
+// Container wraps the given command so it runs inside a container of the
+// given image, using the docker or podman CLI.
+// The current directory and the dist folder are mounted in the container at
+// the same paths, so paths to them keep working. dir is the working
+// directory inside the container, defaulting to the current directory.
+// Only the given env is set in the container.
+func Container(ctx *context.Context, use, image, dir string, env, command []string) ([]string, error) {
+	switch use {
+	case "":
+		use = "docker"
+	case "docker", "podman":
+	default:
+		return nil, fmt.Errorf("invalid container runtime %q, valid options are docker and podman", use)
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	if dir == "" {
+		dir = wd
+	} else if !filepath.IsAbs(dir) {
+		dir = filepath.Join(wd, dir)
+	}
+
+	args := []string{use, "run", "--rm", "-v", wd + ":" + wd}
+	if dist := ctx.Config.Dist; dist != "" {
+		if !filepath.IsAbs(dist) {
+			dist = filepath.Join(wd, dist)
+		}
+		if !strings.HasPrefix(dist, wd+string(filepath.Separator)) && dist != wd {
+			args = append(args, "-v", dist+":"+dist)
+		}
+	}
+	args = append(args, "-w", dir)
+	for _, e := range env {
+		args = append(args, "-e", e)
+	}
+	args = append(args, image)
+	return append(args, command...), nil
+}
@@ -0,0 +1,51 @@
+package shell_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/shell"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContainer(t *testing.T) {
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+
+	t.Run("defaults", func(t *testing.T) {
+		ctx := context.New(config.Project{Dist: "dist"})
+		cmd, err := shell.Container(ctx, "", "node:16", "", []string{"FOO=bar"}, []string{"npm", "run", "build-docs"})
+		require.NoError(t, err)
+		require.Equal(t, []string{
+			"docker", "run", "--rm",
+			"-v", wd + ":" + wd,
+			"-w", wd,
+			"-e", "FOO=bar",
+			"node:16",
+			"npm", "run", "build-docs",
+		}, cmd)
+	})
+
+	t.Run("podman with dist outside and dir", func(t *testing.T) {
+		dist := t.TempDir()
+		ctx := context.New(config.Project{Dist: dist})
+		cmd, err := shell.Container(ctx, "podman", "node:16", "docs", nil, []string{"npm", "run", "build-docs"})
+		require.NoError(t, err)
+		require.Equal(t, []string{
+			"podman", "run", "--rm",
+			"-v", wd + ":" + wd,
+			"-v", dist + ":" + dist,
+			"-w", filepath.Join(wd, "docs"),
+			"node:16",
+			"npm", "run", "build-docs",
+		}, cmd)
+	})
+
+	t.Run("invalid runtime", func(t *testing.T) {
+		_, err := shell.Container(context.New(config.Project{}), "nope", "node:16", "", nil, []string{"npm"})
+		require.EqualError(t, err, `invalid container runtime "nope", valid options are docker and podman`)
+	})
+}
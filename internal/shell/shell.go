@@ -34,9 +34,9 @@ func Run(ctx *context.Context, dir string, command, env []string) error {
 		cmd.Dir = dir
 	}
 
-	log.WithFields(fields).Debug("running")
+	log.FromContext(ctx).WithFields(fields).Debug("running")
 	if err := cmd.Run(); err != nil {
-		log.WithFields(fields).WithError(err).Debug("failed")
+		log.FromContext(ctx).WithFields(fields).WithError(err).Debug("failed")
 		return fmt.Errorf("%q: %w", b.String(), err)
 	}
 
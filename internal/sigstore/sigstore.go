@@ -0,0 +1,173 @@
+// Package sigstore implements sigstore keyless signing with sigstore-go: an
+// ephemeral key is certified by Fulcio for the identity of an OIDC token, each
+// signature is recorded in the Rekor transparency log, and the result is
+// verified against the trusted root of the instance, so it can be verified
+// with cosign.
+package sigstore
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/sigstore/sigstore-go/pkg/bundle"
+	"github.com/sigstore/sigstore-go/pkg/root"
+	"github.com/sigstore/sigstore-go/pkg/sign"
+	"github.com/sigstore/sigstore-go/pkg/verify"
+)
+
+const (
+	// DefaultFulcioURL is the URL of the public Fulcio instance.
+	DefaultFulcioURL = "https://fulcio.sigstore.dev"
+	// DefaultRekorURL is the URL of the public Rekor instance.
+	DefaultRekorURL = "https://rekor.sigstore.dev"
+
+	audience = "sigstore"
+)
+
+// Signer signs files with ephemeral keys certified by Fulcio.
+type Signer struct {
+	token    string
+	fulcio   sign.CertificateProvider
+	rekor    sign.Transparency
+	verifier *verify.Verifier
+}
+
+// NewSigner creates a signer for the identity of the given OIDC token.
+// If the token is empty, the ambient token of the CI is used, only GitHub
+// Actions is supported for now.
+// Signatures are verified against the trusted_root.json file at trustedRoot,
+// or, if it is empty, against the trusted root of the public instance, as
+// distributed by its TUF repository.
+func NewSigner(ctx *context.Context, fulcioURL, rekorURL, token, trustedRoot string) (*Signer, error) {
+	if token == "" {
+		t, err := ambientToken(ctx)
+		if err != nil {
+			return nil, err
+		}
+		token = t
+	}
+	material, err := loadTrustedRoot(trustedRoot)
+	if err != nil {
+		return nil, err
+	}
+	return newSigner(
+		token,
+		sign.NewFulcio(&sign.FulcioOptions{BaseURL: fulcioURL}),
+		sign.NewRekor(&sign.RekorOptions{BaseURL: rekorURL}),
+		material,
+	)
+}
+
+func newSigner(token string, fulcio sign.CertificateProvider, rekor sign.Transparency, material root.TrustedMaterial) (*Signer, error) {
+	// the certificate must be logged in a CT log, and the signature in
+	// Rekor, at a time the certificate was valid.
+	verifier, err := verify.NewVerifier(
+		material,
+		verify.WithSignedCertificateTimestamps(1),
+		verify.WithTransparencyLog(1),
+		verify.WithIntegratedTimestamps(1),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("sigstore: %w", err)
+	}
+	return &Signer{
+		token:    token,
+		fulcio:   fulcio,
+		rekor:    rekor,
+		verifier: verifier,
+	}, nil
+}
+
+func loadTrustedRoot(path string) (root.TrustedMaterial, error) {
+	if path != "" {
+		material, err := root.NewTrustedRootFromPath(path)
+		if err != nil {
+			return nil, fmt.Errorf("sigstore: invalid trusted root %s: %w", path, err)
+		}
+		return material, nil
+	}
+	material, err := root.FetchTrustedRoot()
+	if err != nil {
+		return nil, fmt.Errorf("sigstore: failed to get the trusted root: %w", err)
+	}
+	return material, nil
+}
+
+// Sign signs the given file and records the signature in Rekor.
+// It returns the base64 encoded signature, and the PEM encoded certificate,
+// like cosign sign-blob does.
+func (s *Signer) Sign(ctx *context.Context, path string) ([]byte, []byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("sigstore: %w", err)
+	}
+	keypair, err := sign.NewEphemeralKeypair(nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("sigstore: failed to generate key: %w", err)
+	}
+	pb, err := sign.Bundle(&sign.PlainData{Data: data}, keypair, sign.BundleOptions{
+		Context:                    ctx,
+		CertificateProvider:        s.fulcio,
+		CertificateProviderOptions: &sign.CertificateProviderOptions{IDToken: s.token},
+		TransparencyLogs:           []sign.Transparency{s.rekor},
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("sigstore: failed to sign %s: %w", path, err)
+	}
+
+	b, err := bundle.NewBundle(pb)
+	if err != nil {
+		return nil, nil, fmt.Errorf("sigstore: invalid bundle for %s: %w", path, err)
+	}
+	if _, err := s.verifier.Verify(b, verify.NewPolicy(
+		verify.WithArtifact(bytes.NewReader(data)),
+		verify.WithoutIdentitiesUnsafe(), // we requested the certificate ourselves.
+	)); err != nil {
+		return nil, nil, fmt.Errorf("sigstore: failed to verify the signature of %s: %w", path, err)
+	}
+	log.FromContext(ctx).WithField("file", path).Debug("signature verified against the trusted root")
+
+	sig := pb.GetMessageSignature().GetSignature()
+	cert := pem.EncodeToMemory(&pem.Block{
+		Type:  "CERTIFICATE",
+		Bytes: pb.GetVerificationMaterial().GetCertificate().GetRawBytes(),
+	})
+	return []byte(base64.StdEncoding.EncodeToString(sig)), cert, nil
+}
+
+// ambientToken gets an OIDC token from the CI the release is running on.
+func ambientToken(ctx *context.Context) (string, error) {
+	url := ctx.Env["ACTIONS_ID_TOKEN_REQUEST_URL"]
+	bearer := ctx.Env["ACTIONS_ID_TOKEN_REQUEST_TOKEN"]
+	if url == "" || bearer == "" {
+		return "", errors.New("sigstore: no identity token available, set identity_token or run on GitHub Actions with the id-token: write permission")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url+"&audience="+audience, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "bearer "+bearer)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("sigstore: failed to get identity token: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("sigstore: failed to get identity token: request failed with status %s", resp.Status)
+	}
+	var result struct {
+		Value string `json:"value"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("sigstore: failed to get identity token: %w", err)
+	}
+	return result.Value, nil
+}
@@ -0,0 +1,334 @@
+package sigstore
+
+import (
+	"bytes"
+	stdctx "context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/sigstore/rekor/pkg/generated/client/entries"
+	"github.com/sigstore/rekor/pkg/generated/models"
+	"github.com/sigstore/rekor/pkg/types"
+	"github.com/sigstore/rekor/pkg/util"
+	"github.com/sigstore/sigstore-go/pkg/root"
+	"github.com/sigstore/sigstore-go/pkg/sign"
+	"github.com/sigstore/sigstore-go/pkg/testing/ca"
+	"github.com/sigstore/sigstore/pkg/signature"
+	"github.com/stretchr/testify/require"
+)
+
+// instance is a fake sigstore instance: a Fulcio CA, a CT log and a Rekor log.
+type instance struct {
+	rootCert  *x509.Certificate
+	interCert *x509.Certificate
+	interKey  *ecdsa.PrivateKey
+	ctKey     *ecdsa.PrivateKey
+	rekorKey  *ecdsa.PrivateKey
+}
+
+func newInstance(tb testing.TB) *instance {
+	tb.Helper()
+	rootCert, rootKey, err := ca.GenerateRootCa()
+	require.NoError(tb, err)
+	interCert, interKey, err := ca.GenerateFulcioIntermediate(rootCert, rootKey)
+	require.NoError(tb, err)
+	ctKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(tb, err)
+	rekorKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(tb, err)
+	return &instance{
+		rootCert:  rootCert,
+		interCert: interCert,
+		interKey:  interKey,
+		ctKey:     ctKey,
+		rekorKey:  rekorKey,
+	}
+}
+
+func logID(tb testing.TB, pub crypto.PublicKey) []byte {
+	tb.Helper()
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	require.NoError(tb, err)
+	sum := sha256.Sum256(der)
+	return sum[:]
+}
+
+func (inst *instance) trustedRoot(tb testing.TB) root.TrustedMaterial {
+	tb.Helper()
+	transparencyLog := func(pub crypto.PublicKey) map[string]*root.TransparencyLog {
+		id := hex.EncodeToString(logID(tb, pub))
+		return map[string]*root.TransparencyLog{id: {
+			BaseURL:             "test",
+			ID:                  []byte(id),
+			ValidityPeriodStart: time.Now().Add(-time.Hour),
+			ValidityPeriodEnd:   time.Now().Add(time.Hour),
+			HashFunc:            crypto.SHA256,
+			PublicKey:           pub,
+			SignatureHashFunc:   crypto.SHA256,
+		}}
+	}
+	material, err := root.NewTrustedRoot(
+		root.TrustedRootMediaType01,
+		[]root.CertificateAuthority{&root.FulcioCertificateAuthority{
+			Root:                inst.rootCert,
+			Intermediates:       []*x509.Certificate{inst.interCert},
+			ValidityPeriodStart: time.Now().Add(-time.Hour),
+			ValidityPeriodEnd:   time.Now().Add(time.Hour),
+		}},
+		transparencyLog(inst.ctKey.Public()),
+		nil,
+		transparencyLog(inst.rekorKey.Public()),
+	)
+	require.NoError(tb, err)
+	return material
+}
+
+var oidSCTList = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 11129, 2, 4, 2}
+
+// fulcio issues certificates with an SCT embedded, as described in RFC 6962.
+type fulcio struct {
+	tb   testing.TB
+	inst *instance
+}
+
+func (f fulcio) GetCertificate(_ stdctx.Context, keypair sign.Keypair, opts *sign.CertificateProviderOptions) ([]byte, error) {
+	require.Equal(f.tb, "the-token", opts.IDToken)
+	tmpl := &x509.Certificate{
+		SerialNumber:   big.NewInt(2),
+		EmailAddresses: []string{"me@example.com"},
+		NotBefore:      time.Now().Add(-time.Minute),
+		NotAfter:       time.Now().Add(10 * time.Minute),
+		KeyUsage:       x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:    []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+	}
+	pre, err := x509.CreateCertificate(rand.Reader, tmpl, f.inst.interCert, keypair.GetPublicKey(), f.inst.interKey)
+	require.NoError(f.tb, err)
+	precert, err := x509.ParseCertificate(pre)
+	require.NoError(f.tb, err)
+
+	tbs := precert.RawTBSCertificate
+	issuerKeyHash := sha256.Sum256(f.inst.interCert.RawSubjectPublicKeyInfo)
+	timestamp := make([]byte, 8)
+	binary.BigEndian.PutUint64(timestamp, uint64(time.Now().UnixMilli()))
+	var signed bytes.Buffer
+	signed.Write([]byte{0, 0}) // v1, certificate timestamp.
+	signed.Write(timestamp)
+	signed.Write([]byte{0, 1}) // precert entry.
+	signed.Write(issuerKeyHash[:])
+	signed.Write([]byte{byte(len(tbs) >> 16), byte(len(tbs) >> 8), byte(len(tbs))})
+	signed.Write(tbs)
+	signed.Write([]byte{0, 0}) // no extensions.
+	digest := sha256.Sum256(signed.Bytes())
+	sig, err := ecdsa.SignASN1(rand.Reader, f.inst.ctKey, digest[:])
+	require.NoError(f.tb, err)
+
+	var sct bytes.Buffer
+	sct.WriteByte(0)
+	sct.Write(logID(f.tb, f.inst.ctKey.Public()))
+	sct.Write(timestamp)
+	sct.Write([]byte{0, 0}) // no extensions.
+	sct.Write([]byte{4, 3}) // sha256, ecdsa.
+	sct.Write([]byte{byte(len(sig) >> 8), byte(len(sig))})
+	sct.Write(sig)
+	list := []byte{byte((sct.Len() + 2) >> 8), byte(sct.Len() + 2), byte(sct.Len() >> 8), byte(sct.Len())}
+	list = append(list, sct.Bytes()...)
+	value, err := asn1.Marshal(list)
+	require.NoError(f.tb, err)
+
+	tmpl.ExtraExtensions = []pkix.Extension{{Id: oidSCTList, Value: value}}
+	return x509.CreateCertificate(rand.Reader, tmpl, f.inst.interCert, keypair.GetPublicKey(), f.inst.interKey)
+}
+
+// rekor creates entries signed by the given key, with an inclusion proof in a
+// tree of a single entry.
+type rekor struct {
+	tb      testing.TB
+	key     *ecdsa.PrivateKey
+	entries *int
+}
+
+func (r rekor) CreateLogEntry(params *entries.CreateLogEntryParams, _ ...entries.ClientOption) (*entries.CreateLogEntryCreated, error) {
+	*r.entries++
+	ctx := stdctx.Background()
+	impl, err := types.UnmarshalEntry(params.ProposedEntry)
+	require.NoError(r.tb, err)
+	body, err := types.CanonicalizeEntry(ctx, impl)
+	require.NoError(r.tb, err)
+
+	id := hex.EncodeToString(logID(r.tb, r.key.Public()))
+	integratedTime := time.Now().Unix()
+	logIndex := int64(1)
+	payload, err := json.Marshal(map[string]interface{}{
+		"body":           base64.StdEncoding.EncodeToString(body),
+		"integratedTime": integratedTime,
+		"logID":          id,
+		"logIndex":       logIndex,
+	})
+	require.NoError(r.tb, err)
+	digest := sha256.Sum256(payload)
+	set, err := ecdsa.SignASN1(rand.Reader, r.key, digest[:])
+	require.NoError(r.tb, err)
+
+	signer, err := signature.LoadECDSASignerVerifier(r.key, crypto.SHA256)
+	require.NoError(r.tb, err)
+	leaf := sha256.Sum256(append([]byte{0}, body...))
+	checkpoint, err := util.CreateAndSignCheckpoint(ctx, "rekor.localhost", 1, 1, leaf[:], signer)
+	require.NoError(r.tb, err)
+	rootHash := hex.EncodeToString(leaf[:])
+	treeSize := int64(1)
+	proofIndex := int64(0)
+	cp := string(checkpoint)
+
+	return &entries.CreateLogEntryCreated{
+		ETag: "abc",
+		Payload: map[string]models.LogEntryAnon{"abc": {
+			Body:           base64.StdEncoding.EncodeToString(body),
+			IntegratedTime: &integratedTime,
+			LogID:          &id,
+			LogIndex:       &logIndex,
+			Verification: &models.LogEntryAnonVerification{
+				SignedEntryTimestamp: set,
+				InclusionProof: &models.InclusionProof{
+					Checkpoint: &cp,
+					Hashes:     []string{},
+					LogIndex:   &proofIndex,
+					RootHash:   &rootHash,
+					TreeSize:   &treeSize,
+				},
+			},
+		}},
+	}, nil
+}
+
+func writeFile(tb testing.TB) string {
+	tb.Helper()
+	path := filepath.Join(tb.TempDir(), "foo.txt")
+	require.NoError(tb, os.WriteFile(path, []byte("foo"), 0o644))
+	return path
+}
+
+func TestSign(t *testing.T) {
+	inst := newInstance(t)
+	var n int
+	signer, err := newSigner(
+		"the-token",
+		fulcio{tb: t, inst: inst},
+		sign.NewRekor(&sign.RekorOptions{Client: rekor{tb: t, key: inst.rekorKey, entries: &n}}),
+		inst.trustedRoot(t),
+	)
+	require.NoError(t, err)
+
+	ctx := context.New(config.Project{})
+	sig, cert, err := signer.Sign(ctx, writeFile(t))
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+
+	block, _ := pem.Decode(cert)
+	require.NotNil(t, block)
+	c, err := x509.ParseCertificate(block.Bytes)
+	require.NoError(t, err)
+	require.Equal(t, []string{"me@example.com"}, c.EmailAddresses)
+
+	raw, err := base64.StdEncoding.DecodeString(string(sig))
+	require.NoError(t, err)
+	digest := sha256.Sum256([]byte("foo"))
+	require.True(t, ecdsa.VerifyASN1(c.PublicKey.(*ecdsa.PublicKey), digest[:], raw))
+}
+
+func TestSignUntrustedFulcio(t *testing.T) {
+	inst := newInstance(t)
+	var n int
+	signer, err := newSigner(
+		"the-token",
+		fulcio{tb: t, inst: newInstance(t)},
+		sign.NewRekor(&sign.RekorOptions{Client: rekor{tb: t, key: inst.rekorKey, entries: &n}}),
+		inst.trustedRoot(t),
+	)
+	require.NoError(t, err)
+
+	_, _, err = signer.Sign(context.New(config.Project{}), writeFile(t))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "sigstore: failed to verify the signature of")
+}
+
+func TestSignUntrustedRekor(t *testing.T) {
+	inst := newInstance(t)
+	other, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	var n int
+	signer, err := newSigner(
+		"the-token",
+		fulcio{tb: t, inst: inst},
+		sign.NewRekor(&sign.RekorOptions{Client: rekor{tb: t, key: other, entries: &n}}),
+		inst.trustedRoot(t),
+	)
+	require.NoError(t, err)
+
+	_, _, err = signer.Sign(context.New(config.Project{}), writeFile(t))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "sigstore: failed to verify the signature of")
+}
+
+func TestNewSignerInvalidTrustedRoot(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "trusted_root.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{}`), 0o644))
+	_, err := NewSigner(context.New(config.Project{}), DefaultFulcioURL, DefaultRekorURL, "the-token", path)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "sigstore: invalid trusted root "+path)
+}
+
+func TestNewSignerAmbientToken(t *testing.T) {
+	actions := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "bearer secret", r.Header.Get("Authorization"))
+		require.Equal(t, "sigstore", r.URL.Query().Get("audience"))
+		require.NoError(t, json.NewEncoder(w).Encode(map[string]string{"value": "the-token"}))
+	}))
+	t.Cleanup(actions.Close)
+
+	ctx := context.New(config.Project{})
+	ctx.Env["ACTIONS_ID_TOKEN_REQUEST_URL"] = actions.URL + "/token?api-version=2.0"
+	ctx.Env["ACTIONS_ID_TOKEN_REQUEST_TOKEN"] = "secret"
+	token, err := ambientToken(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "the-token", token)
+}
+
+func TestNewSignerAmbientTokenError(t *testing.T) {
+	actions := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "nope", http.StatusForbidden)
+	}))
+	t.Cleanup(actions.Close)
+
+	ctx := context.New(config.Project{})
+	ctx.Env["ACTIONS_ID_TOKEN_REQUEST_URL"] = actions.URL + "/token?api-version=2.0"
+	ctx.Env["ACTIONS_ID_TOKEN_REQUEST_TOKEN"] = "secret"
+	_, err := NewSigner(ctx, DefaultFulcioURL, DefaultRekorURL, "", "")
+	require.EqualError(t, err, "sigstore: failed to get identity token: request failed with status 403 Forbidden")
+}
+
+func TestNewSignerNoToken(t *testing.T) {
+	ctx := context.New(config.Project{})
+	ctx.Env = context.Env{}
+	_, err := NewSigner(ctx, DefaultFulcioURL, DefaultRekorURL, "", "")
+	require.EqualError(t, err, "sigstore: no identity token available, set identity_token or run on GitHub Actions with the id-token: write permission")
+}
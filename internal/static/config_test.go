@@ -12,3 +12,13 @@ func TestExampleConfig(t *testing.T) {
 	_, err := config.LoadReader(strings.NewReader(ExampleConfig))
 	require.NoError(t, err)
 }
+
+func TestTemplates(t *testing.T) {
+	require.Equal(t, []string{"cli", "daemon", "default", "k8s-operator", "library"}, TemplateNames())
+	for name, tmpl := range Templates {
+		t.Run(name, func(t *testing.T) {
+			_, err := config.LoadReader(strings.NewReader(tmpl))
+			require.NoError(t, err)
+		})
+	}
+}
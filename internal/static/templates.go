@@ -0,0 +1,245 @@
+package static
+
+import "sort"
+
+// Placeholders replaced in the templates by goreleaser init: the owner and
+// the name of the repository.
+const (
+	OwnerPlaceholder   = "__OWNER__"
+	ProjectPlaceholder = "__PROJECT__"
+)
+
+// Templates are the configs goreleaser init can generate, by name.
+var Templates = map[string]string{
+	"default":      ExampleConfig,
+	"cli":          CLIConfig,
+	"library":      LibraryConfig,
+	"daemon":       DaemonConfig,
+	"k8s-operator": K8sOperatorConfig,
+}
+
+// TemplateNames returns the sorted names of the templates.
+func TemplateNames() []string {
+	names := make([]string, 0, len(Templates))
+	for name := range Templates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// CLIConfig is the config of a command line tool, released as archives,
+// Linux packages and a Homebrew formula.
+const CLIConfig = `# This is a .goreleaser.yml file for a command line tool.
+# Make sure to check the documentation at https://goreleaser.com
+before:
+  hooks:
+    - go mod tidy
+builds:
+  - env:
+      - CGO_ENABLED=0
+    goos:
+      - linux
+      - windows
+      - darwin
+    goarch:
+      - amd64
+      - arm64
+    mod_timestamp: '{{ .CommitTimestamp }}'
+    flags:
+      - -trimpath
+archives:
+  - name_template: '{{ .ProjectName }}_{{ .Version }}_{{ .Os }}_{{ .Arch }}'
+    format_overrides:
+      - goos: windows
+        format: zip
+    files:
+      - README*
+      - LICENSE*
+checksum:
+  name_template: 'checksums.txt'
+nfpms:
+  - maintainer: __OWNER__
+    description: __PROJECT__ command line tool.
+    formats:
+      - deb
+      - rpm
+      - apk
+brews:
+  - tap:
+      owner: __OWNER__
+      name: homebrew-tap
+    folder: Formula
+    description: __PROJECT__ command line tool.
+    test: |
+      system "#{bin}/__PROJECT__ --help"
+# If your tool is a kubectl plugin, you can publish it to a krew index too:
+# krews:
+#   - index:
+#       owner: __OWNER__
+#       name: krew-index
+#     short_description: __PROJECT__ kubectl plugin.
+snapshot:
+  name_template: "{{ incpatch .Version }}-next"
+changelog:
+  sort: asc
+  filters:
+    exclude:
+      - '^docs:'
+      - '^test:'
+`
+
+// LibraryConfig is the config of a library, which only releases its source
+// code and changelog.
+const LibraryConfig = `# This is a .goreleaser.yml file for a library.
+# Make sure to check the documentation at https://goreleaser.com
+before:
+  hooks:
+    - go mod tidy
+builds:
+  # libraries have no binaries to release.
+  - skip: true
+source:
+  enabled: true
+  name_template: '{{ .ProjectName }}_{{ .Version }}_source'
+checksum:
+  name_template: 'checksums.txt'
+snapshot:
+  name_template: "{{ incpatch .Version }}-next"
+changelog:
+  sort: asc
+  use: git
+  groups:
+    - title: Features
+      regexp: '^.*?feat(\([[:word:]]+\))??!?:.+$'
+      order: 0
+    - title: Bug fixes
+      regexp: '^.*?fix(\([[:word:]]+\))??!?:.+$'
+      order: 1
+    - title: Others
+      order: 999
+  filters:
+    exclude:
+      - '^docs:'
+      - '^test:'
+`
+
+// DaemonConfig is the config of a long running service, released as Linux
+// packages running it with systemd, and as a docker image.
+const DaemonConfig = `# This is a .goreleaser.yml file for a daemon.
+# Make sure to check the documentation at https://goreleaser.com
+before:
+  hooks:
+    - go mod tidy
+builds:
+  - env:
+      - CGO_ENABLED=0
+    goos:
+      - linux
+    goarch:
+      - amd64
+      - arm64
+    mod_timestamp: '{{ .CommitTimestamp }}'
+    flags:
+      - -trimpath
+archives:
+  - name_template: '{{ .ProjectName }}_{{ .Version }}_{{ .Os }}_{{ .Arch }}'
+    files:
+      - README*
+      - LICENSE*
+      - contrib/systemd/*
+checksum:
+  name_template: 'checksums.txt'
+nfpms:
+  - maintainer: __OWNER__
+    description: __PROJECT__ daemon.
+    formats:
+      - deb
+      - rpm
+    contents:
+      # create this unit file in your repository.
+      - src: contrib/systemd/__PROJECT__.service
+        dst: /lib/systemd/system/__PROJECT__.service
+      - src: contrib/config.yaml
+        dst: /etc/__PROJECT__/config.yaml
+        type: config|noreplace
+    scripts:
+      # e.g. systemctl daemon-reload && systemctl enable __PROJECT__
+      postinstall: contrib/scripts/postinstall.sh
+      preremove: contrib/scripts/preremove.sh
+dockers:
+  - image_templates:
+      - 'ghcr.io/__OWNER__/__PROJECT__:{{ .Tag }}'
+      - 'ghcr.io/__OWNER__/__PROJECT__:latest'
+    use: buildx
+    platforms:
+      - linux/amd64
+      - linux/arm64
+    build_flag_templates:
+      - --label=org.opencontainers.image.title={{ .ProjectName }}
+      - --label=org.opencontainers.image.version={{ .Version }}
+      - --label=org.opencontainers.image.revision={{ .FullCommit }}
+snapshot:
+  name_template: "{{ incpatch .Version }}-next"
+changelog:
+  sort: asc
+  filters:
+    exclude:
+      - '^docs:'
+      - '^test:'
+`
+
+// K8sOperatorConfig is the config of a Kubernetes operator, released as a
+// multi-platform docker image, along with its manifests.
+const K8sOperatorConfig = `# This is a .goreleaser.yml file for a Kubernetes operator.
+# Make sure to check the documentation at https://goreleaser.com
+before:
+  hooks:
+    - go mod tidy
+    # e.g. regenerate the CRDs and RBAC manifests.
+    - go generate ./...
+builds:
+  - id: manager
+    binary: manager
+    env:
+      - CGO_ENABLED=0
+    goos:
+      - linux
+    goarch:
+      - amd64
+      - arm64
+    mod_timestamp: '{{ .CommitTimestamp }}'
+    flags:
+      - -trimpath
+archives:
+  - format: binary
+    name_template: '{{ .Binary }}_{{ .Os }}_{{ .Arch }}'
+dockers:
+  - image_templates:
+      - 'ghcr.io/__OWNER__/__PROJECT__:{{ .Tag }}'
+      - 'ghcr.io/__OWNER__/__PROJECT__:latest'
+    use: buildx
+    platforms:
+      - linux/amd64
+      - linux/arm64
+    provenance: mode=max
+    sbom: 'true'
+    build_flag_templates:
+      - --label=org.opencontainers.image.title={{ .ProjectName }}
+      - --label=org.opencontainers.image.version={{ .Version }}
+      - --label=org.opencontainers.image.revision={{ .FullCommit }}
+release:
+  # the manifests to install the operator, e.g. generated with kustomize.
+  extra_files:
+    - glob: ./deploy/*.yaml
+checksum:
+  name_template: 'checksums.txt'
+snapshot:
+  name_template: "{{ incpatch .Version }}-next"
+changelog:
+  sort: asc
+  filters:
+    exclude:
+      - '^docs:'
+      - '^test:'
+`
@@ -0,0 +1,30 @@
+package testlib
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// FakeGPG puts a gpg in the PATH that logs its calls and writes something to
+// its outputs.
+// It returns the path of the file the calls are logged to.
+func FakeGPG(tb testing.TB) string {
+	tb.Helper()
+	dir := tb.TempDir()
+	calls := filepath.Join(dir, "calls.log")
+	script := `#!/bin/sh
+echo "$@" >> ` + calls + `
+while [ $# -gt 0 ]; do
+	if [ "$1" = "--output" ]; then
+		echo signed > "$2"
+	fi
+	shift
+done
+`
+	require.NoError(tb, os.WriteFile(filepath.Join(dir, "gpg"), []byte(script), 0o755))
+	tb.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return calls
+}
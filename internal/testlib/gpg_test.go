@@ -0,0 +1,27 @@
+package testlib
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFakeGPG(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell scripts are not executable on windows")
+	}
+	calls := FakeGPG(t)
+	out := filepath.Join(t.TempDir(), "out.sig")
+	require.NoError(t, exec.Command("gpg", "--detach-sign", "--output", out, "file").Run())
+
+	bts, err := os.ReadFile(out)
+	require.NoError(t, err)
+	require.Equal(t, "signed\n", string(bts))
+	bts, err = os.ReadFile(calls)
+	require.NoError(t, err)
+	require.Equal(t, "--detach-sign --output "+out+" file\n", string(bts))
+}
@@ -0,0 +1,115 @@
+package tmpl
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+)
+
+// assetURL returns the public download URL of the given asset in the release
+// or, if a blob ID is given, in the bucket of that blob.
+func assetURL(ctx *context.Context, name string, blob ...string) (string, error) {
+	switch len(blob) {
+	case 0:
+		return releaseAssetURL(ctx, name)
+	case 1:
+		for _, b := range ctx.Config.Blobs {
+			if b.ID == blob[0] || (b.ID == "" && b.Bucket == blob[0]) {
+				return blobAssetURL(ctx, b, name)
+			}
+		}
+		return "", fmt.Errorf("assetURL: no blob with id %s", blob[0])
+	default:
+		return "", fmt.Errorf("assetURL: expected an asset name and an optional blob id, got %d arguments", len(blob)+1)
+	}
+}
+
+func releaseAssetURL(ctx *context.Context, name string) (string, error) {
+	t := New(ctx)
+	name = url.PathEscape(name)
+	tag := ctx.Git.CurrentTag
+	switch ctx.TokenType {
+	case context.TokenTypeGitLab:
+		download, err := t.Apply(ctx.Config.GitLabURLs.Download)
+		if err != nil {
+			return "", err
+		}
+		repo, err := t.Apply(ctx.Config.Release.GitLab.Name)
+		if err != nil {
+			return "", err
+		}
+		if owner := ctx.Config.Release.GitLab.Owner; owner != "" {
+			repo = owner + "/" + repo
+		}
+		return fmt.Sprintf("%s/%s/-/releases/%s/downloads/%s", download, repo, tag, name), nil
+	case context.TokenTypeGitea:
+		download, err := t.Apply(ctx.Config.GiteaURLs.Download)
+		if err != nil {
+			return "", err
+		}
+		repo := ctx.Config.Release.Gitea
+		return fmt.Sprintf("%s/%s/%s/releases/download/%s/%s", download, repo.Owner, repo.Name, tag, name), nil
+	case context.TokenTypeBitbucket:
+		download, err := t.Apply(ctx.Config.BitbucketURLs.Download)
+		if err != nil {
+			return "", err
+		}
+		repo := ctx.Config.Release.Bitbucket
+		return fmt.Sprintf("%s/%s/%s/downloads/%s", download, repo.Owner, repo.Name, name), nil
+	case context.TokenTypeAzureDevOps:
+		return "", fmt.Errorf("assetURL: Azure Artifacts universal packages have no download URLs")
+	default:
+		download, err := t.Apply(ctx.Config.GitHubURLs.Download)
+		if err != nil {
+			return "", err
+		}
+		repo := ctx.Config.Release.GitHub
+		return fmt.Sprintf("%s/%s/%s/releases/download/%s/%s", download, repo.Owner, repo.Name, tag, name), nil
+	}
+}
+
+func blobAssetURL(ctx *context.Context, blob config.Blob, name string) (string, error) {
+	t := New(ctx)
+	bucket, err := t.Apply(blob.Bucket)
+	if err != nil {
+		return "", err
+	}
+	folder, err := t.Apply(blob.Folder)
+	if err != nil {
+		return "", err
+	}
+	key := strings.Trim(folder, "/") + "/" + url.PathEscape(name)
+	key = strings.TrimPrefix(key, "/")
+
+	switch blob.Provider {
+	case "s3":
+		if blob.Endpoint != "" {
+			endpoint := strings.TrimSuffix(blob.Endpoint, "/")
+			if !strings.Contains(endpoint, "://") {
+				scheme := "https"
+				if blob.DisableSSL {
+					scheme = "http"
+				}
+				endpoint = scheme + "://" + endpoint
+			}
+			return fmt.Sprintf("%s/%s/%s", endpoint, bucket, key), nil
+		}
+		if blob.Region != "" {
+			return fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", bucket, blob.Region, key), nil
+		}
+		return fmt.Sprintf("https://%s.s3.amazonaws.com/%s", bucket, key), nil
+	case "gs":
+		return fmt.Sprintf("https://storage.googleapis.com/%s/%s", bucket, key), nil
+	case "azblob":
+		account := ctx.Env["AZURE_STORAGE_ACCOUNT"]
+		if account == "" {
+			return "", fmt.Errorf("assetURL: AZURE_STORAGE_ACCOUNT is required to resolve azblob urls")
+		}
+		return fmt.Sprintf("https://%s.blob.core.windows.net/%s/%s", account, bucket, key), nil
+	default:
+		return "", fmt.Errorf("assetURL: unsupported blob provider: %s", blob.Provider)
+	}
+}
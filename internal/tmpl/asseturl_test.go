@@ -0,0 +1,85 @@
+package tmpl
+
+import (
+	"testing"
+
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAssetURL(t *testing.T) {
+	newCtx := func(tokenType context.TokenType) *context.Context {
+		ctx := context.New(config.Project{
+			ProjectName:   "proj",
+			GitHubURLs:    config.GitHubURLs{Download: "https://github.com"},
+			GitLabURLs:    config.GitLabURLs{Download: "https://gitlab.com"},
+			GiteaURLs:     config.GiteaURLs{Download: "https://gitea.com"},
+			BitbucketURLs: config.BitbucketURLs{Download: "https://bitbucket.org"},
+			Release: config.Release{
+				GitHub:    config.Repo{Owner: "foo", Name: "bar"},
+				GitLab:    config.Repo{Owner: "foo", Name: "{{ .ProjectName }}"},
+				Gitea:     config.Repo{Owner: "foo", Name: "bar"},
+				Bitbucket: config.Repo{Owner: "foo", Name: "bar"},
+			},
+			Blobs: []config.Blob{
+				{ID: "s3", Provider: "s3", Bucket: "bucket", Region: "us-east-1", Folder: "{{ .ProjectName }}/{{ .Tag }}"},
+				{ID: "minio", Provider: "s3", Bucket: "bucket", Endpoint: "localhost:9000", DisableSSL: true, Folder: "{{ .Tag }}"},
+				{ID: "gs", Provider: "gs", Bucket: "bucket", Folder: "{{ .Tag }}"},
+				{ID: "azure", Provider: "azblob", Bucket: "container", Folder: "{{ .Tag }}"},
+				{Provider: "s3", Bucket: "nofolder"},
+			},
+		})
+		ctx.TokenType = tokenType
+		ctx.Git.CurrentTag = "v1.2.3"
+		ctx.Env["AZURE_STORAGE_ACCOUNT"] = "account"
+		return ctx
+	}
+
+	for expected, in := range map[string]struct {
+		tokenType context.TokenType
+		tmpl      string
+	}{
+		"https://github.com/foo/bar/releases/download/v1.2.3/app.tar.gz":     {context.TokenTypeGitHub, `{{ assetURL "app.tar.gz" }}`},
+		"https://gitlab.com/foo/proj/-/releases/v1.2.3/downloads/app.tar.gz": {context.TokenTypeGitLab, `{{ assetURL "app.tar.gz" }}`},
+		"https://gitea.com/foo/bar/releases/download/v1.2.3/app.tar.gz":      {context.TokenTypeGitea, `{{ assetURL "app.tar.gz" }}`},
+		"https://bitbucket.org/foo/bar/downloads/app.tar.gz":                 {context.TokenTypeBitbucket, `{{ assetURL "app.tar.gz" }}`},
+		"https://github.com/foo/bar/releases/download/v1.2.3/app%201.tar.gz": {context.TokenTypeGitHub, `{{ assetURL "app 1.tar.gz" }}`},
+		"https://bucket.s3.us-east-1.amazonaws.com/proj/v1.2.3/app.tar.gz":   {context.TokenTypeGitHub, `{{ assetURL "app.tar.gz" "s3" }}`},
+		"http://localhost:9000/bucket/v1.2.3/app.tar.gz":                     {context.TokenTypeGitHub, `{{ assetURL "app.tar.gz" "minio" }}`},
+		"https://storage.googleapis.com/bucket/v1.2.3/app.tar.gz":            {context.TokenTypeGitHub, `{{ assetURL "app.tar.gz" "gs" }}`},
+		"https://account.blob.core.windows.net/container/v1.2.3/app.tar.gz":  {context.TokenTypeGitHub, `{{ assetURL "app.tar.gz" "azure" }}`},
+		"https://nofolder.s3.amazonaws.com/app.tar.gz":                       {context.TokenTypeGitHub, `{{ assetURL "app.tar.gz" "nofolder" }}`},
+	} {
+		t.Run(expected, func(t *testing.T) {
+			result, err := New(newCtx(in.tokenType)).Apply(in.tmpl)
+			require.NoError(t, err)
+			require.Equal(t, expected, result)
+		})
+	}
+
+	t.Run("unknown blob", func(t *testing.T) {
+		_, err := New(newCtx(context.TokenTypeGitHub)).Apply(`{{ assetURL "app.tar.gz" "nope" }}`)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "assetURL: no blob with id nope")
+	})
+
+	t.Run("missing azure account", func(t *testing.T) {
+		ctx := newCtx(context.TokenTypeGitHub)
+		delete(ctx.Env, "AZURE_STORAGE_ACCOUNT")
+		_, err := New(ctx).Apply(`{{ assetURL "app.tar.gz" "azure" }}`)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "AZURE_STORAGE_ACCOUNT is required")
+	})
+
+	t.Run("azure devops", func(t *testing.T) {
+		_, err := New(newCtx(context.TokenTypeAzureDevOps)).Apply(`{{ assetURL "app.tar.gz" }}`)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "assetURL: Azure Artifacts universal packages have no download URLs")
+	})
+
+	t.Run("too many arguments", func(t *testing.T) {
+		_, err := New(newCtx(context.TokenTypeGitHub)).Apply(`{{ assetURL "app.tar.gz" "s3" "gs" }}`)
+		require.Error(t, err)
+	})
+}
@@ -12,13 +12,18 @@ import (
 
 	"github.com/Masterminds/semver/v3"
 	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/platforms"
 	"github.com/goreleaser/goreleaser/pkg/build"
+	"github.com/goreleaser/goreleaser/pkg/config"
 	"github.com/goreleaser/goreleaser/pkg/context"
 )
 
 // Template holds data that can be applied to a template string.
 type Template struct {
-	fields Fields
+	fields        Fields
+	platforms     []config.Platform
+	platformNames map[string]string
+	assetURL      func(name string, blob ...string) (string, error)
 }
 
 // Fields that will be available to the template engine.
@@ -41,6 +46,9 @@ const (
 	summary         = "Summary"
 	tagSubject      = "TagSubject"
 	tagContents     = "TagContents"
+	tagBody         = "TagBody"
+	tagTagger       = "TagTagger"
+	tagDate         = "TagDate"
 	releaseURL      = "ReleaseURL"
 	major           = "Major"
 	minor           = "Minor"
@@ -53,11 +61,17 @@ const (
 	modulePath      = "ModulePath"
 	releaseNotes    = "ReleaseNotes"
 
+	// previous release keys.
+	previousDownloads      = "PreviousReleaseDownloads"
+	previousAssetDownloads = "PreviousReleaseAssetDownloads"
+
 	// artifact-only keys.
 	osKey        = "Os"
 	arch         = "Arch"
 	arm          = "Arm"
 	mips         = "Mips"
+	platform     = "Platform"
+	platformName = "PlatformName"
 	binary       = "Binary"
 	artifactName = "ArtifactName"
 	artifactPath = "ArtifactPath"
@@ -75,6 +89,11 @@ func New(ctx *context.Context) *Template {
 	rawVersionV := fmt.Sprintf("%d.%d.%d", sv.Major, sv.Minor, sv.Patch)
 
 	return &Template{
+		platforms:     ctx.Config.Platforms,
+		platformNames: ctx.Config.PlatformNames,
+		assetURL: func(name string, blob ...string) (string, error) {
+			return assetURL(ctx, name, blob...)
+		},
 		fields: Fields{
 			projectName:     ctx.Config.ProjectName,
 			modulePath:      ctx.ModulePath,
@@ -92,6 +111,9 @@ func New(ctx *context.Context) *Template {
 			summary:         ctx.Git.Summary,
 			tagSubject:      ctx.Git.TagSubject,
 			tagContents:     ctx.Git.TagContents,
+			tagBody:         ctx.Git.TagBody,
+			tagTagger:       ctx.Git.TagTagger,
+			tagDate:         ctx.Git.TagDate.UTC().Format(time.RFC3339),
 			releaseURL:      ctx.ReleaseURL,
 			env:             ctx.Env,
 			date:            ctx.Date.UTC().Format(time.RFC3339),
@@ -102,6 +124,9 @@ func New(ctx *context.Context) *Template {
 			prerelease:      ctx.Semver.Prerelease,
 			isSnapshot:      ctx.Snapshot,
 			releaseNotes:    ctx.ReleaseNotes,
+
+			previousDownloads:      ctx.PreviousDownloads.Total(),
+			previousAssetDownloads: map[string]int(ctx.PreviousDownloads),
 		},
 	}
 }
@@ -142,6 +167,8 @@ func (t *Template) WithArtifact(a *artifact.Artifact, replacements map[string]st
 	t.fields[arch] = replace(replacements, a.Goarch)
 	t.fields[arm] = replace(replacements, a.Goarm)
 	t.fields[mips] = replace(replacements, a.Gomips)
+	t.fields[platform] = platforms.Of(t.platforms, a.Target())
+	t.fields[platformName] = platforms.Name(t.platformNames, a.Target())
 	t.fields[binary] = bin.(string)
 	t.fields[artifactName] = a.Name
 	t.fields[artifactPath] = a.Path
@@ -149,6 +176,8 @@ func (t *Template) WithArtifact(a *artifact.Artifact, replacements map[string]st
 }
 
 func (t *Template) WithBuildOptions(opts build.Options) *Template {
+	t.fields[platform] = platforms.Of(t.platforms, opts.Target)
+	t.fields[platformName] = platforms.Name(t.platformNames, opts.Target)
 	return t.WithExtraFields(buildOptsToFields(opts))
 }
 
@@ -185,6 +214,10 @@ func (t *Template) Apply(s string) (string, error) {
 			"incmajor":   incMajor,
 			"incminor":   incMinor,
 			"incpatch":   incPatch,
+			"platformname": func(parts ...string) string {
+				return platforms.Name(t.platformNames, joinTarget(parts))
+			},
+			"assetURL": t.assetURL,
 		}).
 		Parse(s)
 	if err != nil {
@@ -195,6 +228,18 @@ func (t *Template) Apply(s string) (string, error) {
 	return out.String(), err
 }
 
+// joinTarget joins the given goos, goarch and goarm or gomips into a target,
+// e.g. linux_arm_7, ignoring empty parts.
+func joinTarget(parts []string) string {
+	var result []string
+	for _, p := range parts {
+		if p != "" {
+			result = append(result, p)
+		}
+	}
+	return strings.Join(result, "_")
+}
+
 type ExpectedSingleEnvErr struct{}
 
 func (e ExpectedSingleEnvErr) Error() string {
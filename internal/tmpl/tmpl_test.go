@@ -5,8 +5,10 @@ import (
 	"path/filepath"
 	"testing"
 	"text/template"
+	"time"
 
 	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/pkg/build"
 	"github.com/goreleaser/goreleaser/pkg/config"
 	"github.com/goreleaser/goreleaser/pkg/context"
 	"github.com/stretchr/testify/require"
@@ -35,7 +37,14 @@ func TestWithArtifact(t *testing.T) {
 	ctx.Git.ShortCommit = "shortcommit"
 	ctx.Git.TagSubject = "awesome release"
 	ctx.Git.TagContents = "awesome release\n\nanother line"
+	ctx.Git.TagBody = "another line"
+	ctx.Git.TagTagger = "Foo <foo@example.com>"
+	ctx.Git.TagDate = time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)
 	ctx.ReleaseNotes = "test release notes"
+	ctx.PreviousDownloads = context.ReleaseDownloads{
+		"proj_linux_amd64.tar.gz": 42,
+		"proj_darwin_arm64.zip":   8,
+	}
 	for expect, tmpl := range map[string]string{
 		"bar":                              "{{.Env.FOO}}",
 		"Linux":                            "{{.Os}}",
@@ -62,6 +71,11 @@ func TestWithArtifact(t *testing.T) {
 		"v1.2.2":                           "{{ .PreviousTag }}",
 		"awesome release":                  "{{ .TagSubject }}",
 		"awesome release\n\nanother line":  "{{ .TagContents }}",
+		"another line":                     "{{ .TagBody }}",
+		"Foo <foo@example.com>":            "{{ .TagTagger }}",
+		"2022-01-02T03:04:05Z":             "{{ .TagDate }}",
+		"50":                               "{{ .PreviousReleaseDownloads }}",
+		"42":                               `{{ index .PreviousReleaseAssetDownloads "proj_linux_amd64.tar.gz" }}`,
 	} {
 		tmpl := tmpl
 		expect := expect
@@ -107,6 +121,51 @@ func TestWithArtifact(t *testing.T) {
 	})
 }
 
+func TestWithArtifactPlatform(t *testing.T) {
+	ctx := context.New(config.Project{
+		Platforms: []config.Platform{
+			{Name: "raspberry-pi", Targets: []string{"linux_arm64", "linux_arm_7"}},
+		},
+	})
+	for expected, a := range map[string]*artifact.Artifact{
+		"raspberry-pi": {Goos: "linux", Goarch: "arm", Goarm: "7"},
+		"":             {Goos: "linux", Goarch: "amd64"},
+	} {
+		result, err := New(ctx).WithArtifact(a, nil).Apply("{{ .Platform }}")
+		require.NoError(t, err)
+		require.Equal(t, expected, result)
+	}
+
+	result, err := New(ctx).WithBuildOptions(build.Options{Target: "linux_arm64"}).Apply("{{ .Platform }}")
+	require.NoError(t, err)
+	require.Equal(t, "raspberry-pi", result)
+}
+
+func TestPlatformName(t *testing.T) {
+	ctx := context.New(config.Project{
+		PlatformNames: map[string]string{
+			"linux_arm_7": "Raspberry Pi",
+		},
+	})
+	for expected, a := range map[string]*artifact.Artifact{
+		"Raspberry Pi":          {Goos: "linux", Goarch: "arm", Goarm: "7"},
+		"macOS (Apple Silicon)": {Goos: "darwin", Goarch: "arm64"},
+		"":                      {},
+	} {
+		result, err := New(ctx).WithArtifact(a, nil).Apply("{{ .PlatformName }}")
+		require.NoError(t, err)
+		require.Equal(t, expected, result)
+	}
+
+	result, err := New(ctx).WithBuildOptions(build.Options{Target: "windows_amd64"}).Apply("{{ .PlatformName }}")
+	require.NoError(t, err)
+	require.Equal(t, "Windows 64-bit", result)
+
+	result, err = New(ctx).Apply(`{{ platformname "linux" "arm" "7" }}, {{ platformname "linux" "amd64" "" }}, {{ platformname "darwin_all" }}`)
+	require.NoError(t, err)
+	require.Equal(t, "Raspberry Pi, Linux 64-bit, macOS (Universal)", result)
+}
+
 func TestEnv(t *testing.T) {
 	testCases := []struct {
 		desc string
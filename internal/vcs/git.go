@@ -0,0 +1,175 @@
+package vcs
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goreleaser/goreleaser/internal/git"
+)
+
+// Git is the git version control system.
+type Git struct{}
+
+func (Git) Name() string { return "git" }
+
+func (Git) Available() bool {
+	_, err := exec.LookPath("git")
+	return err == nil
+}
+
+func (Git) IsRepo() bool { return git.IsRepo() }
+
+func (Git) Branch() (string, error) {
+	return git.Clean(git.Run("rev-parse", "--abbrev-ref", "HEAD", "--quiet"))
+}
+
+func (Git) Commit() (Commit, error) {
+	short, err := git.Clean(git.Run("show", "--format='%h'", "HEAD", "--quiet"))
+	if err != nil {
+		return Commit{}, err
+	}
+	full, err := git.Clean(git.Run("show", "--format='%H'", "HEAD", "--quiet"))
+	if err != nil {
+		return Commit{}, err
+	}
+	ct, err := git.Clean(git.Run("show", "--format='%ct'", "HEAD", "--quiet"))
+	if err != nil {
+		return Commit{}, err
+	}
+	date, err := parseUnix(ct)
+	if err != nil {
+		return Commit{}, err
+	}
+	return Commit{Full: full, Short: short, Date: date}, nil
+}
+
+func (Git) FirstCommit() (string, error) {
+	return git.Clean(git.Run("rev-list", "--max-parents=0", "HEAD"))
+}
+
+func (Git) Summary() (string, error) {
+	return git.Clean(git.Run("describe", "--always", "--dirty", "--tags"))
+}
+
+func (Git) URL() (string, error) {
+	return git.Clean(git.Run("ls-remote", "--get-url"))
+}
+
+func (Git) Status() (string, error) {
+	return git.Run("status", "--porcelain")
+}
+
+func (Git) CurrentTag() (string, error) {
+	tag, err := git.Clean(git.Run("tag", "--points-at", "HEAD", "--sort", "-version:refname"))
+	if tag != "" || err != nil {
+		return tag, err
+	}
+	return git.Clean(git.Run("describe", "--tags", "--abbrev=0"))
+}
+
+func (Git) PreviousTag(current string) (string, error) {
+	return git.Clean(git.Run("describe", "--tags", "--abbrev=0", fmt.Sprintf("tags/%s^", current)))
+}
+
+func (Git) Tag(name string) (Tag, error) {
+	subject, err := git.Clean(git.Run("tag", "-l", "--format='%(contents:subject)'", name))
+	if err != nil {
+		return Tag{}, fmt.Errorf("couldn't get tag subject: %w", err)
+	}
+
+	out, err := git.Run("tag", "-l", "--format='%(contents)'", name)
+	if err != nil {
+		return Tag{}, fmt.Errorf("couldn't get tag contents: %w", err)
+	}
+	contents := strings.TrimSuffix(strings.ReplaceAll(out, "'", ""), "\n\n")
+
+	body, err := git.Run("tag", "-l", "--format=%(contents:body)", name)
+	if err != nil {
+		return Tag{}, fmt.Errorf("couldn't get tag body: %w", err)
+	}
+
+	// empty for lightweight tags
+	tagger, err := git.Run("tag", "-l", "--format=%(taggername) %(taggeremail)", name)
+	if err != nil {
+		return Tag{}, fmt.Errorf("couldn't get tag tagger: %w", err)
+	}
+
+	// the date of the commit for lightweight tags
+	ct, err := git.Clean(git.Run("tag", "-l", "--format=%(creatordate:unix)", name))
+	if err != nil {
+		return Tag{}, fmt.Errorf("couldn't get tag date: %w", err)
+	}
+	date, err := parseUnix(ct)
+	if err != nil {
+		return Tag{}, fmt.Errorf("couldn't get tag date: %w", err)
+	}
+
+	return Tag{
+		Subject:  subject,
+		Contents: contents,
+		Body:     strings.TrimSpace(body),
+		Tagger:   strings.TrimSpace(tagger),
+		Date:     date,
+	}, nil
+}
+
+func (Git) IsCurrent(tag string) bool {
+	_, err := git.Clean(git.Run("describe", "--exact-match", "--tags", "--match", tag))
+	return err == nil
+}
+
+func (Git) TagsBetween(prev, current string) ([]string, error) {
+	if !IsHash(prev) {
+		prev = "tags/" + prev
+	}
+	out, err := git.Run(
+		"tag",
+		"--merged", "tags/"+current,
+		"--no-merged", prev,
+		"--sort=-version:refname",
+	)
+	if err != nil {
+		return nil, err
+	}
+	return lines(out, current), nil
+}
+
+func (Git) Log(prev, current string) (string, error) {
+	args := []string{"log", "--pretty=oneline", "--abbrev-commit", "--no-decorate", "--no-color"}
+	if IsHash(prev) {
+		args = append(args, prev, current)
+	} else {
+		args = append(args, fmt.Sprintf("tags/%s..tags/%s", prev, current))
+	}
+	return git.Run(args...)
+}
+
+// parseUnix parses the given unix timestamp, returning the zero time if it is
+// empty.
+func parseUnix(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	i, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(i, 0).UTC(), nil
+}
+
+// lines returns the non empty lines of the given output, except the ones
+// equal to skip.
+func lines(out, skip string) []string {
+	var result []string
+	for _, line := range strings.Split(out, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || line == skip {
+			continue
+		}
+		result = append(result, line)
+	}
+	return result
+}
@@ -0,0 +1,247 @@
+package vcs
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/apex/log"
+)
+
+// Mercurial is the hg version control system.
+//
+// Mercurial tags are commits on the .hgtags file, so the tagging commit
+// counts as the tagged one in IsCurrent, and tags can't have their own
+// message nor tagger.
+type Mercurial struct{}
+
+func (Mercurial) Name() string { return "hg" }
+
+func (Mercurial) Available() bool {
+	_, err := exec.LookPath("hg")
+	return err == nil
+}
+
+func (Mercurial) IsRepo() bool {
+	_, err := hg("root")
+	return err == nil
+}
+
+func (Mercurial) Branch() (string, error) {
+	return hg("branch")
+}
+
+func (Mercurial) Commit() (Commit, error) {
+	out, err := hg("log", "-r", ".", "--template", "{node} {node|short} {date|hgdate}")
+	if err != nil {
+		return Commit{}, err
+	}
+	fields := strings.Fields(out)
+	if len(fields) < 3 {
+		return Commit{}, fmt.Errorf("unexpected hg log output: %q", out)
+	}
+	date, err := parseUnix(fields[2])
+	if err != nil {
+		return Commit{}, err
+	}
+	return Commit{Full: fields[0], Short: fields[1], Date: date}, nil
+}
+
+func (Mercurial) FirstCommit() (string, error) {
+	return hg("log", "-r", "0", "--template", "{node}")
+}
+
+func (m Mercurial) Summary() (string, error) {
+	out, err := hg("log", "-r", ".", "--template", "{latesttag} {latesttagdistance} {node|short}")
+	if err != nil {
+		return "", err
+	}
+	status, err := m.Status()
+	if err != nil {
+		return "", err
+	}
+	return summary(strings.Fields(out), status != ""), nil
+}
+
+// summary formats the output of hg log like git describe --always --dirty
+// --tags does.
+func summary(fields []string, dirty bool) string {
+	var result string
+	switch {
+	case len(fields) < 3:
+		result = strings.Join(fields, " ")
+	case fields[0] == "null":
+		result = fields[2]
+	case fields[1] == "0":
+		result = firstTag(fields[0], "")
+	default:
+		result = fmt.Sprintf("%s-%s-h%s", firstTag(fields[0], ""), fields[1], fields[2])
+	}
+	if dirty {
+		result += "-dirty"
+	}
+	return result
+}
+
+func (Mercurial) URL() (string, error) {
+	out, err := hg("paths", "default")
+	if err != nil {
+		// no default path configured
+		return "", nil
+	}
+	return out, nil
+}
+
+func (Mercurial) Status() (string, error) {
+	return hg("status")
+}
+
+func (Mercurial) CurrentTag() (string, error) {
+	out, err := hg("log", "-r", ".", "--template", "{latesttag}")
+	if err != nil {
+		return "", err
+	}
+	if out == "null" {
+		return "", errors.New("no tags found")
+	}
+	return firstTag(out, ""), nil
+}
+
+func (Mercurial) PreviousTag(current string) (string, error) {
+	out, err := hg(
+		"log",
+		"-r", fmt.Sprintf("last(tag() and ancestors(%[1]s) - %[1]s)", quote(current)),
+		"--template", "{tags}",
+	)
+	if err != nil {
+		return "", err
+	}
+	tag := firstTag(out, current)
+	if tag == "" {
+		return "", fmt.Errorf("no tags found before %s", current)
+	}
+	return tag, nil
+}
+
+func (Mercurial) Tag(name string) (Tag, error) {
+	desc, err := hg("log", "-r", quote(name), "--template", "{desc}")
+	if err != nil {
+		return Tag{}, fmt.Errorf("couldn't get tag contents: %w", err)
+	}
+	date, err := hg("log", "-r", quote(name), "--template", "{date|hgdate}")
+	if err != nil {
+		return Tag{}, fmt.Errorf("couldn't get tag date: %w", err)
+	}
+	var ct string
+	if fields := strings.Fields(date); len(fields) > 0 {
+		ct = fields[0]
+	}
+	t, err := parseUnix(ct)
+	if err != nil {
+		return Tag{}, fmt.Errorf("couldn't get tag date: %w", err)
+	}
+	subject, body := desc, ""
+	if i := strings.Index(desc, "\n"); i >= 0 {
+		subject, body = desc[:i], strings.TrimSpace(desc[i+1:])
+	}
+	return Tag{
+		Subject:  subject,
+		Contents: desc,
+		Body:     body,
+		Date:     t,
+	}, nil
+}
+
+func (Mercurial) IsCurrent(tag string) bool {
+	tagged, err := hg("log", "-r", quote(tag), "--template", "{node}")
+	if err != nil {
+		return false
+	}
+	current, err := hg("log", "-r", ".", "--template", "{node} {p1node} {files}")
+	if err != nil {
+		return false
+	}
+	fields := strings.Fields(current)
+	if len(fields) == 0 {
+		return false
+	}
+	if fields[0] == tagged {
+		return true
+	}
+	// hg tag commits the tag on top of the tagged revision
+	return len(fields) == 3 && fields[1] == tagged && fields[2] == ".hgtags"
+}
+
+func (Mercurial) TagsBetween(prev, current string) ([]string, error) {
+	out, err := hg(
+		"log",
+		"-r", fmt.Sprintf("reverse(only(%s, %s) and tag())", quote(current), quote(prev)),
+		"--template", "{join(tags, '\\n')}\n",
+	)
+	if err != nil {
+		return nil, err
+	}
+	var tags []string
+	for _, tag := range lines(out, current) {
+		if tag != "tip" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags, nil
+}
+
+func (Mercurial) Log(prev, current string) (string, error) {
+	revs := fmt.Sprintf("reverse(only(%s, %s))", quote(current), quote(prev))
+	if IsHash(prev) {
+		revs = fmt.Sprintf("reverse(::%s)", quote(current))
+	}
+	return hg("log", "-r", revs, "--template", "{node|short} {desc|firstline}\n")
+}
+
+// firstTag returns the first of the given space or colon separated tags,
+// ignoring tip and skip.
+func firstTag(tags, skip string) string {
+	for _, tag := range strings.FieldsFunc(tags, func(r rune) bool {
+		return r == ' ' || r == ':'
+	}) {
+		if tag != "tip" && tag != skip {
+			return tag
+		}
+	}
+	return ""
+}
+
+// quote quotes the given symbol to be used in a revset.
+func quote(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s) + "'"
+}
+
+// hg runs a hg command and returns its trimmed output or errors.
+func hg(args ...string) (string, error) {
+	/* #nosec */
+	cmd := exec.Command("hg", args...)
+
+	stdout := bytes.Buffer{}
+	stderr := bytes.Buffer{}
+
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	// disables user configuration that could change the output
+	cmd.Env = append(os.Environ(), "HGPLAIN=1")
+
+	log.WithField("args", args).Debug("running hg")
+	err := cmd.Run()
+
+	log.WithField("stdout", stdout.String()).
+		WithField("stderr", stderr.String()).
+		Debug("hg result")
+
+	if err != nil {
+		return "", errors.New(strings.TrimSpace(stderr.String()))
+	}
+
+	return strings.TrimSpace(stdout.String()), nil
+}
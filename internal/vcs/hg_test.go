@@ -0,0 +1,114 @@
+package vcs
+
+import (
+	"os"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/testlib"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHgSummary(t *testing.T) {
+	for name, tt := range map[string]struct {
+		fields []string
+		dirty  bool
+		expect string
+	}{
+		"tagged":        {[]string{"v1.0.0", "0", "abcdef123456"}, false, "v1.0.0"},
+		"tagged dirty":  {[]string{"v1.0.0", "0", "abcdef123456"}, true, "v1.0.0-dirty"},
+		"after tag":     {[]string{"v1.0.0", "2", "abcdef123456"}, false, "v1.0.0-2-habcdef123456"},
+		"multiple tags": {[]string{"v1.0.0:latest", "1", "abcdef123456"}, false, "v1.0.0-1-habcdef123456"},
+		"no tags":       {[]string{"null", "3", "abcdef123456"}, false, "abcdef123456"},
+		"no tags dirty": {[]string{"null", "3", "abcdef123456"}, true, "abcdef123456-dirty"},
+		"unexpected":    {[]string{"abcdef123456"}, false, "abcdef123456"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, tt.expect, summary(tt.fields, tt.dirty))
+		})
+	}
+}
+
+func TestHgFirstTag(t *testing.T) {
+	require.Equal(t, "v1.0.0", firstTag("tip v1.0.0 latest", ""))
+	require.Equal(t, "latest", firstTag("v1.0.0:latest", "v1.0.0"))
+	require.Empty(t, firstTag("tip", ""))
+	require.Empty(t, firstTag("", ""))
+}
+
+func TestHgQuote(t *testing.T) {
+	require.Equal(t, `'v1.0.0'`, quote("v1.0.0"))
+	require.Equal(t, `'it\'s\\here'`, quote(`it's\here`))
+}
+
+func TestHgRepo(t *testing.T) {
+	hg := Mercurial{}
+	if !hg.Available() {
+		t.Skip("hg not present in PATH")
+	}
+	testlib.Mktmp(t)
+	require.False(t, hg.IsRepo())
+	hgRun(t, "init")
+	require.True(t, hg.IsRepo())
+	require.Equal(t, "hg", Detect().Name())
+
+	require.NoError(t, os.WriteFile("foo", []byte("foo"), 0o644))
+	status, err := hg.Status()
+	require.NoError(t, err)
+	require.Contains(t, status, "foo")
+
+	hgRun(t, "add", "foo")
+	hgRun(t, "commit", "-m", "first\n\nwith a body")
+	first, err := hg.Commit()
+	require.NoError(t, err)
+	require.Len(t, first.Full, 40)
+	require.False(t, first.Date.IsZero())
+	hgRun(t, "tag", "v0.0.1")
+	require.True(t, hg.IsCurrent("v0.0.1"))
+
+	require.NoError(t, os.WriteFile("foo", []byte("bar"), 0o644))
+	hgRun(t, "commit", "-m", "second")
+	require.False(t, hg.IsCurrent("v0.0.1"))
+	hgRun(t, "tag", "v0.0.2")
+	require.True(t, hg.IsCurrent("v0.0.2"))
+
+	status, err = hg.Status()
+	require.NoError(t, err)
+	require.Empty(t, status)
+
+	branch, err := hg.Branch()
+	require.NoError(t, err)
+	require.Equal(t, "default", branch)
+
+	tag, err := hg.CurrentTag()
+	require.NoError(t, err)
+	require.Equal(t, "v0.0.2", tag)
+
+	previous, err := hg.PreviousTag("v0.0.2")
+	require.NoError(t, err)
+	require.Equal(t, "v0.0.1", previous)
+
+	details, err := hg.Tag("v0.0.1")
+	require.NoError(t, err)
+	require.Equal(t, "first", details.Subject)
+	require.Equal(t, "with a body", details.Body)
+	require.Equal(t, first.Date, details.Date)
+
+	firstCommit, err := hg.FirstCommit()
+	require.NoError(t, err)
+	require.Equal(t, first.Full, firstCommit)
+
+	log, err := hg.Log("v0.0.1", "v0.0.2")
+	require.NoError(t, err)
+	require.Contains(t, log, "second")
+	require.NotContains(t, log, "first")
+
+	log, err = hg.Log(firstCommit, "v0.0.2")
+	require.NoError(t, err)
+	require.Contains(t, log, "second")
+}
+
+func hgRun(tb testing.TB, args ...string) {
+	tb.Helper()
+	_, err := hg(append([]string{"--config", "ui.username=GoReleaser <test@goreleaser.github.com>"}, args...)...)
+	require.NoError(tb, err)
+}
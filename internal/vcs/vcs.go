@@ -0,0 +1,88 @@
+// Package vcs abstracts the version control systems goreleaser can release
+// from, namely git and Mercurial.
+package vcs
+
+import (
+	"regexp"
+	"time"
+)
+
+// VCS is a version control system of the current folder.
+type VCS interface {
+	// Name of the version control system, e.g. "git".
+	Name() string
+	// Available returns true if the version control system is installed.
+	Available() bool
+	// IsRepo returns true if the current folder is a repository.
+	IsRepo() bool
+
+	// Branch returns the current branch.
+	Branch() (string, error)
+	// Commit returns the revision the current folder is at.
+	Commit() (Commit, error)
+	// FirstCommit returns the full hash of the first revision.
+	FirstCommit() (string, error)
+	// Summary describes the current revision, e.g. v1.2.3-2-gabcdef.
+	Summary() (string, error)
+	// URL returns the URL of the default remote.
+	URL() (string, error)
+	// Status returns the changes of the current folder, which is dirty if
+	// it isn't empty.
+	Status() (string, error)
+
+	// CurrentTag returns the latest tag of the current revision.
+	CurrentTag() (string, error)
+	// PreviousTag returns the tag before the given one.
+	PreviousTag(current string) (string, error)
+	// Tag returns the details of the given tag.
+	Tag(name string) (Tag, error)
+	// IsCurrent returns true if the given tag was made against the current
+	// revision.
+	IsCurrent(tag string) bool
+	// TagsBetween returns the tags in the history of current but not in the
+	// one of prev, newest first, excluding current.
+	TagsBetween(prev, current string) ([]string, error)
+
+	// Log returns the revisions between prev and current, one per line with
+	// the short hash and the subject, newest first.
+	// prev is either a tag or the full hash of the first revision.
+	Log(prev, current string) (string, error)
+}
+
+// Commit is a revision.
+type Commit struct {
+	Full  string
+	Short string
+	Date  time.Time
+}
+
+// Tag holds the details of a tag.
+type Tag struct {
+	Subject  string
+	Contents string
+	Body     string
+	Tagger   string
+	Date     time.Time
+}
+
+// nolint: gochecknoglobals
+var all = []VCS{Git{}, Mercurial{}}
+
+// Detect returns the version control system of the current folder.
+// It defaults to git if the current folder is not a repository.
+func Detect() VCS {
+	for _, v := range all {
+		if v.Available() && v.IsRepo() {
+			return v
+		}
+	}
+	return Git{}
+}
+
+var validSHA1 = regexp.MustCompile(`^[a-fA-F0-9]{40}$`)
+
+// IsHash returns true if the given ref is the full hash of a revision
+// rather than a tag.
+func IsHash(ref string) bool {
+	return validSHA1.MatchString(ref)
+}
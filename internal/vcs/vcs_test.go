@@ -0,0 +1,50 @@
+package vcs
+
+import (
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/testlib"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectGit(t *testing.T) {
+	testlib.Mktmp(t)
+	testlib.GitInit(t)
+	require.Equal(t, "git", Detect().Name())
+}
+
+func TestDetectNotARepo(t *testing.T) {
+	testlib.Mktmp(t)
+	repo := Detect()
+	require.Equal(t, "git", repo.Name())
+	require.False(t, repo.IsRepo())
+}
+
+func TestIsHash(t *testing.T) {
+	require.True(t, IsHash("a4ba1f6ebd8bb3b9d1a6d8c95e0c0e1e2f3f4a5b"))
+	require.False(t, IsHash("v1.0.0"))
+	require.False(t, IsHash("a4ba1f6"))
+}
+
+func TestGitTagsBetween(t *testing.T) {
+	testlib.Mktmp(t)
+	testlib.GitInit(t)
+	testlib.GitCommit(t, "first")
+	testlib.GitTag(t, "v0.0.1")
+	testlib.GitCommit(t, "second")
+	testlib.GitTag(t, "v0.0.2")
+	testlib.GitCommit(t, "third")
+	testlib.GitTag(t, "v0.0.3")
+	testlib.GitCommit(t, "fourth")
+	testlib.GitTag(t, "v0.0.4")
+
+	tags, err := Git{}.TagsBetween("v0.0.1", "v0.0.4")
+	require.NoError(t, err)
+	require.Equal(t, []string{"v0.0.3", "v0.0.2"}, tags)
+
+	log, err := Git{}.Log("v0.0.2", "v0.0.4")
+	require.NoError(t, err)
+	require.Contains(t, log, "fourth")
+	require.Contains(t, log, "third")
+	require.NotContains(t, log, "second")
+}
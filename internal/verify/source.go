@@ -0,0 +1,189 @@
+package verify
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/context"
+)
+
+// Source is where the files of a release are read from.
+type Source interface {
+	// Fetch returns the path of a local copy of the release file with the
+	// given name.
+	// The error wraps os.ErrNotExist if the release has no such file.
+	Fetch(ctx *context.Context, name string) (string, error)
+	// List returns the names of the files of the release.
+	List() ([]string, error)
+	// Artifact returns the artifact the release file with the given name
+	// was created as, if the source knows it.
+	Artifact(name string) (*artifact.Artifact, bool)
+}
+
+// checkName returns an error if the given name is not the plain name of a
+// release file, e.g. one read from a tampered checksums file escaping the
+// folder files are looked up in.
+func checkName(name string) error {
+	if name == "" ||
+		name == "." ||
+		name == ".." ||
+		strings.ContainsAny(name, `/\`) ||
+		name != filepath.Base(name) {
+		return fmt.Errorf("invalid file name: %q", name)
+	}
+	return nil
+}
+
+// Dir is the source of a release built in the given dist folder.
+// Files are looked up in the artifacts.json file of the folder, and in the
+// folder itself.
+func Dir(dist string) (Source, error) {
+	src := dirSource{dist: dist, artifacts: map[string]*artifact.Artifact{}}
+	bts, err := os.ReadFile(filepath.Join(dist, "artifacts.json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return src, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var artifacts []struct {
+		Name  string          `json:"name"`
+		Path  string          `json:"path"`
+		Type  string          `json:"type"`
+		Extra artifact.Extras `json:"extra"`
+	}
+	if err := json.Unmarshal(bts, &artifacts); err != nil {
+		return nil, fmt.Errorf("failed to parse artifacts.json: %w", err)
+	}
+	// binaries and the archives wrapping them can share a name, the
+	// uploadable one is always added last.
+	for _, a := range artifacts {
+		if a.Name == "" || a.Path == "" {
+			continue
+		}
+		// artifacts of unknown types can still be fetched.
+		typ, _ := artifact.ParseType(a.Type)
+		src.artifacts[a.Name] = &artifact.Artifact{
+			Name:  a.Name,
+			Path:  a.Path,
+			Type:  typ,
+			Extra: a.Extra,
+		}
+	}
+	return src, nil
+}
+
+type dirSource struct {
+	dist      string
+	artifacts map[string]*artifact.Artifact
+}
+
+func (s dirSource) Fetch(_ *context.Context, name string) (string, error) {
+	if err := checkName(name); err != nil {
+		return "", err
+	}
+	// build folders are named like the binaries, e.g. foo_linux_amd64.
+	path := filepath.Join(s.dist, name)
+	if info, err := os.Stat(path); err == nil && !info.IsDir() {
+		return path, nil
+	}
+	if a, ok := s.artifacts[name]; ok {
+		if _, err := os.Stat(a.Path); err != nil {
+			return "", err
+		}
+		return a.Path, nil
+	}
+	return "", fmt.Errorf("%s: %w", name, os.ErrNotExist)
+}
+
+func (s dirSource) Artifact(name string) (*artifact.Artifact, bool) {
+	a, ok := s.artifacts[name]
+	return a, ok && a.Type != 0
+}
+
+func (s dirSource) List() ([]string, error) {
+	seen := map[string]bool{}
+	for name := range s.artifacts {
+		seen[name] = true
+	}
+	entries, err := os.ReadDir(s.dist)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		if !e.IsDir() {
+			seen[e.Name()] = true
+		}
+	}
+	result := make([]string, 0, len(seen))
+	for name := range seen {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+// URL is the source of a published release, downloading its files into dir.
+// urlTemplate is the download URL of the files, e.g.
+// https://github.com/foo/bar/releases/download/{{ .Tag }}/{{ .ArtifactName }}.
+func URL(urlTemplate, dir string) Source {
+	return urlSource{template: urlTemplate, dir: dir}
+}
+
+type urlSource struct {
+	template string
+	dir      string
+}
+
+func (s urlSource) Fetch(ctx *context.Context, name string) (string, error) {
+	if err := checkName(name); err != nil {
+		return "", err
+	}
+	url, err := tmpl.New(ctx).WithArtifact(&artifact.Artifact{Name: name}, nil).Apply(s.template)
+	if err != nil {
+		return "", fmt.Errorf("failed to apply template to download url: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to download %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return "", fmt.Errorf("%s: %w", url, os.ErrNotExist)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to download %s: %s", url, resp.Status)
+	}
+
+	path := filepath.Join(s.dir, name)
+	f, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	if _, err := io.Copy(f, resp.Body); err != nil {
+		return "", fmt.Errorf("failed to download %s: %w", url, err)
+	}
+	return path, f.Close()
+}
+
+func (s urlSource) Artifact(string) (*artifact.Artifact, bool) {
+	return nil, false
+}
+
+func (s urlSource) List() ([]string, error) {
+	return nil, errors.New("the files of a published release can't be listed, verify its dist folder instead")
+}
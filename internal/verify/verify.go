@@ -0,0 +1,247 @@
+// Package verify checks the integrity of a release: the checksums of its files
+// and the signatures and certificates created by the sign pipe.
+package verify
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/pipe"
+	"github.com/goreleaser/goreleaser/internal/pipe/checksums"
+	"github.com/goreleaser/goreleaser/internal/pipe/sign"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/context"
+)
+
+// Run verifies the release files of the given source.
+// key is the public key cosign signatures are verified with, if any.
+func Run(ctx *context.Context, src Source, key string) error {
+	sumFiles, files, failed, err := verifyChecksums(ctx, src)
+	if err != nil {
+		return err
+	}
+	signed, err := verifySignatures(ctx, src, sumFiles, files, key)
+	if err != nil {
+		return err
+	}
+	failed += signed
+	if failed > 0 {
+		return fmt.Errorf("%d verifications failed, check logs above for details", failed)
+	}
+	return nil
+}
+
+// verifyChecksums checks the files listed in the checksum files of the
+// release, returning the names of the checksum files, the names of all the
+// files, checksum files included, and how many failed.
+func verifyChecksums(ctx *context.Context, src Source) ([]string, []string, int, error) {
+	conf := ctx.Config.Checksum
+	if conf.Disable {
+		// the files to verify are the ones listed in the checksum files.
+		return nil, nil, 0, errors.New("checksums are disabled, there is nothing to verify")
+	}
+
+	var sumFiles []string
+	if conf.DisableCombined {
+		ext := checksums.Extension(conf.Algorithm, conf.Format)
+		names, err := src.List()
+		if err != nil {
+			return nil, nil, 0, err
+		}
+		for _, name := range names {
+			if strings.HasSuffix(name, ext) {
+				sumFiles = append(sumFiles, name)
+			}
+		}
+	} else {
+		name, err := tmpl.New(ctx).Apply(conf.NameTemplate)
+		if err != nil {
+			return nil, nil, 0, fmt.Errorf("failed to apply template to checksum name: %w", err)
+		}
+		sumFiles = append(sumFiles, name)
+	}
+	if len(sumFiles) == 0 {
+		return nil, nil, 0, errors.New("no checksum files found")
+	}
+
+	var failed int
+	files := append([]string{}, sumFiles...)
+	for _, sumFile := range sumFiles {
+		path, err := src.Fetch(ctx, sumFile)
+		if err != nil {
+			return nil, nil, 0, fmt.Errorf("failed to get checksum file: %w", err)
+		}
+		bts, err := os.ReadFile(path)
+		if err != nil {
+			return nil, nil, 0, err
+		}
+		sums, err := checksums.Parse(conf.Format, bts)
+		if err != nil {
+			return nil, nil, 0, fmt.Errorf("%s: %w", sumFile, err)
+		}
+
+		names := make([]string, 0, len(sums))
+		for name := range sums {
+			if err := checkName(name); err != nil {
+				return nil, nil, 0, fmt.Errorf("%s: %w", sumFile, err)
+			}
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			files = append(files, name)
+			if err := verifyChecksum(ctx, src, conf.Algorithm, name, sums[name]); err != nil {
+				log.FromContext(ctx).WithField("file", name).WithError(err).Error("invalid checksum")
+				failed++
+				continue
+			}
+			log.FromContext(ctx).WithField("file", name).Info("checksum ok")
+		}
+	}
+	return sumFiles, files, failed, nil
+}
+
+func verifyChecksum(ctx *context.Context, src Source, algorithm, name, expected string) error {
+	path, err := src.Fetch(ctx, name)
+	if err != nil {
+		return err
+	}
+	sum, err := artifact.Artifact{Path: path}.Checksum(algorithm)
+	if err != nil {
+		return err
+	}
+	if sum != expected {
+		return fmt.Errorf("expected %s, got %s", expected, sum)
+	}
+	return nil
+}
+
+// verifySignatures checks the signatures the sign configs created for the
+// given files, returning how many failed.
+// Each sign config only signs some kinds of artifacts: a missing signature is
+// a failure if the source knows the file is one of them, or if it is a
+// checksum file, and is skipped otherwise.
+func verifySignatures(ctx *context.Context, src Source, sumFiles, files []string, key string) (int, error) {
+	var failed int
+	for _, cfg := range ctx.Config.Signs {
+		filter, err := sign.Filter(ctx, cfg)
+		if errors.Is(err, pipe.ErrSkipSignEnabled) {
+			continue
+		}
+		if err != nil {
+			return 0, fmt.Errorf("sign %s: %w", cfg.ID, err)
+		}
+		for _, name := range files {
+			signature, cert, err := sign.Names(ctx, cfg, name)
+			if err != nil {
+				return 0, fmt.Errorf("sign %s: %w", cfg.ID, err)
+			}
+			if signature == "" {
+				continue
+			}
+			sigPath, err := src.Fetch(ctx, signature)
+			if errors.Is(err, os.ErrNotExist) {
+				if expectsSignature(ctx, src, filter, sumFiles, name) {
+					log.FromContext(ctx).WithField("file", name).WithField("signature", signature).Error("missing signature")
+					failed++
+				}
+				continue
+			}
+			if err != nil {
+				return 0, err
+			}
+			var certPath string
+			if cert != "" {
+				certPath, err = src.Fetch(ctx, cert)
+				if err != nil {
+					return 0, fmt.Errorf("failed to get certificate: %w", err)
+				}
+			}
+			path, err := src.Fetch(ctx, name)
+			if err != nil {
+				return 0, err
+			}
+
+			cmd := cfg.Cmd
+			switch {
+			case cfg.Sigstore.Enabled:
+				cmd = "cosign"
+			case cfg.GPG.Enabled:
+				cmd = "gpg"
+			}
+			args, err := verifyCommand(cmd, key, path, sigPath, certPath)
+			if err != nil {
+				log.FromContext(ctx).WithField("sign", cfg.ID).WithError(err).Warn("skipping signatures")
+				break
+			}
+			fields := log.Fields{"file": name, "signature": signature}
+			if cert != "" {
+				fields["certificate"] = cert
+			}
+			if err := run(ctx, args); err != nil {
+				log.FromContext(ctx).WithFields(fields).WithError(err).Error("invalid signature")
+				failed++
+				continue
+			}
+			log.FromContext(ctx).WithFields(fields).Info("signature ok")
+		}
+	}
+	return failed, nil
+}
+
+// expectsSignature returns true if the given filter of a sign config matches
+// the file with the given name.
+func expectsSignature(ctx *context.Context, src Source, filter artifact.Filter, sumFiles []string, name string) bool {
+	if a, ok := src.Artifact(name); ok {
+		return filter(a)
+	}
+	for _, sumFile := range sumFiles {
+		if sumFile == name {
+			return filter(&artifact.Artifact{Name: name, Type: artifact.Checksum})
+		}
+	}
+	log.FromContext(ctx).WithField("file", name).Debug("unknown artifact type, skipping missing signature")
+	return false
+}
+
+// verifyCommand returns the command verifying a signature created by the
+// given sign command.
+func verifyCommand(cmd, key, path, signature, cert string) ([]string, error) {
+	switch strings.TrimSuffix(filepath.Base(cmd), ".exe") {
+	case "gpg", "gpg2":
+		return []string{cmd, "--verify", signature, path}, nil
+	case "cosign":
+		args := []string{cmd, "verify-blob", "--signature", signature}
+		if cert != "" {
+			args = append(args, "--cert", cert)
+		}
+		if key != "" {
+			args = append(args, "--key", key)
+		}
+		return append(args, path), nil
+	default:
+		return nil, fmt.Errorf("signatures created by %s can't be verified, only gpg and cosign are supported", cmd)
+	}
+}
+
+func run(ctx *context.Context, args []string) error {
+	/* #nosec */
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Env = ctx.Env.Strings()
+	var b bytes.Buffer
+	cmd.Stdout = &b
+	cmd.Stderr = &b
+	log.FromContext(ctx).WithField("cmd", args).Debug("running")
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s failed: %w: %s", args[0], err, b.String())
+	}
+	return nil
+}
@@ -0,0 +1,248 @@
+package verify
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+func checksumOf(tb testing.TB, path string) string {
+	tb.Helper()
+	sum, err := artifact.Artifact{Path: path}.Checksum("sha256")
+	require.NoError(tb, err)
+	return sum
+}
+
+func setupDist(tb testing.TB) string {
+	tb.Helper()
+	dist := tb.TempDir()
+	require.NoError(tb, os.WriteFile(filepath.Join(dist, "foo.tar.gz"), []byte("foo"), 0o644))
+	require.NoError(tb, os.MkdirAll(filepath.Join(dist, "foo_linux_amd64"), 0o755))
+	bin := filepath.Join(dist, "foo_linux_amd64", "foo")
+	require.NoError(tb, os.WriteFile(bin, []byte("bin"), 0o644))
+	require.NoError(tb, os.WriteFile(filepath.Join(dist, "artifacts.json"), []byte(`[{"name":"foo_linux_amd64","path":"`+bin+`","type":"Binary"}]`), 0o644))
+	require.NoError(tb, os.WriteFile(
+		filepath.Join(dist, "checksums.txt"),
+		[]byte(checksumOf(tb, filepath.Join(dist, "foo.tar.gz"))+"  foo.tar.gz\n"+checksumOf(tb, bin)+"  foo_linux_amd64\n"),
+		0o644,
+	))
+	return dist
+}
+
+func newContext() *context.Context {
+	return context.New(config.Project{
+		Checksum: config.Checksum{
+			NameTemplate: "checksums.txt",
+			Algorithm:    "sha256",
+		},
+	})
+}
+
+func TestRunDir(t *testing.T) {
+	src, err := Dir(setupDist(t))
+	require.NoError(t, err)
+	require.NoError(t, Run(newContext(), src, ""))
+}
+
+func TestRunDirInvalidChecksum(t *testing.T) {
+	dist := setupDist(t)
+	require.NoError(t, os.WriteFile(filepath.Join(dist, "foo.tar.gz"), []byte("tampered"), 0o644))
+	src, err := Dir(dist)
+	require.NoError(t, err)
+	require.EqualError(t, Run(newContext(), src, ""), "1 verifications failed, check logs above for details")
+}
+
+func TestRunDirMissingFile(t *testing.T) {
+	dist := setupDist(t)
+	require.NoError(t, os.Remove(filepath.Join(dist, "foo.tar.gz")))
+	src, err := Dir(dist)
+	require.NoError(t, err)
+	require.EqualError(t, Run(newContext(), src, ""), "1 verifications failed, check logs above for details")
+}
+
+func TestRunDirMissingChecksums(t *testing.T) {
+	dist := setupDist(t)
+	require.NoError(t, os.Remove(filepath.Join(dist, "checksums.txt")))
+	src, err := Dir(dist)
+	require.NoError(t, err)
+	err = Run(newContext(), src, "")
+	require.Error(t, err)
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestRunDirSplit(t *testing.T) {
+	dist := setupDist(t)
+	require.NoError(t, os.Remove(filepath.Join(dist, "checksums.txt")))
+	require.NoError(t, os.WriteFile(
+		filepath.Join(dist, "foo.tar.gz.sha256"),
+		[]byte("SHA256 (foo.tar.gz) = "+checksumOf(t, filepath.Join(dist, "foo.tar.gz"))+"\n"),
+		0o644,
+	))
+	src, err := Dir(dist)
+	require.NoError(t, err)
+	ctx := newContext()
+	ctx.Config.Checksum.Split = true
+	ctx.Config.Checksum.DisableCombined = true
+	ctx.Config.Checksum.Format = "bsd"
+	require.NoError(t, Run(ctx, src, ""))
+}
+
+func TestRunDisabled(t *testing.T) {
+	src, err := Dir(t.TempDir())
+	require.NoError(t, err)
+	ctx := newContext()
+	ctx.Config.Checksum.Disable = true
+	require.EqualError(t, Run(ctx, src, ""), "checksums are disabled, there is nothing to verify")
+}
+
+func TestRunDirInvalidName(t *testing.T) {
+	dist := setupDist(t)
+	require.NoError(t, os.WriteFile(filepath.Join(dist, "checksums.txt"), []byte(checksumOf(t, filepath.Join(dist, "foo.tar.gz"))+"  ../foo.tar.gz\n"), 0o644))
+	src, err := Dir(dist)
+	require.NoError(t, err)
+	require.EqualError(t, Run(newContext(), src, ""), `checksums.txt: invalid file name: "../foo.tar.gz"`)
+}
+
+func TestRunMissingSignatures(t *testing.T) {
+	for name, tt := range map[string]struct {
+		artifacts string
+		ids       []string
+		err       string
+	}{
+		"checksum": {
+			artifacts: "checksum",
+			err:       "1 verifications failed, check logs above for details",
+		},
+		"binary": {
+			artifacts: "binary",
+			err:       "1 verifications failed, check logs above for details",
+		},
+		"all": {
+			artifacts: "all",
+			err:       "2 verifications failed, check logs above for details",
+		},
+		"other ids": {
+			artifacts: "binary",
+			ids:       []string{"bar"},
+		},
+		"unknown types": {
+			artifacts: "archive",
+		},
+		"none": {
+			artifacts: "none",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			src, err := Dir(setupDist(t))
+			require.NoError(t, err)
+			ctx := newContext()
+			ctx.Config.Signs = []config.Sign{{
+				ID:        "default",
+				Cmd:       "gpg",
+				Signature: "${artifact}.sig",
+				Artifacts: tt.artifacts,
+				IDs:       tt.ids,
+			}}
+			err = Run(ctx, src, "")
+			if tt.err == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.EqualError(t, err, tt.err)
+		})
+	}
+}
+
+func TestRunUnsupportedSignatures(t *testing.T) {
+	dist := setupDist(t)
+	require.NoError(t, os.WriteFile(filepath.Join(dist, "checksums.txt.sig"), []byte("sig"), 0o644))
+	src, err := Dir(dist)
+	require.NoError(t, err)
+	ctx := newContext()
+	ctx.Config.Signs = []config.Sign{{
+		ID:        "default",
+		Cmd:       "signer",
+		Signature: "${artifact}.sig",
+		Artifacts: "checksum",
+	}}
+	require.NoError(t, Run(ctx, src, ""))
+}
+
+func TestRunURL(t *testing.T) {
+	dist := setupDist(t)
+	var requested []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = append(requested, r.URL.Path)
+		if strings.HasSuffix(r.URL.Path, "/foo_linux_amd64") {
+			bts, err := os.ReadFile(filepath.Join(dist, "foo_linux_amd64", "foo"))
+			require.NoError(t, err)
+			_, _ = w.Write(bts)
+			return
+		}
+		http.ServeFile(w, r, filepath.Join(dist, filepath.Base(r.URL.Path)))
+	}))
+	t.Cleanup(srv.Close)
+
+	ctx := newContext()
+	ctx.Git.CurrentTag = "v1.0.0"
+	src := URL(srv.URL+"/download/{{ .Tag }}/{{ .ArtifactName }}", t.TempDir())
+	require.NoError(t, Run(ctx, src, ""))
+	require.Equal(t, []string{
+		"/download/v1.0.0/checksums.txt",
+		"/download/v1.0.0/foo.tar.gz",
+		"/download/v1.0.0/foo_linux_amd64",
+	}, requested)
+}
+
+func TestURLFetchNotFound(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	t.Cleanup(srv.Close)
+	_, err := URL(srv.URL+"/{{ .ArtifactName }}", t.TempDir()).Fetch(newContext(), "foo")
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestURLFetchInvalidName(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request: %s", r.URL.Path)
+	}))
+	t.Cleanup(srv.Close)
+	for _, name := range []string{"", ".", "..", "../foo", "foo/bar", `foo\bar`} {
+		t.Run(name, func(t *testing.T) {
+			_, err := URL(srv.URL+"/{{ .ArtifactName }}", t.TempDir()).Fetch(newContext(), name)
+			require.EqualError(t, err, fmt.Sprintf("invalid file name: %q", name))
+		})
+	}
+}
+
+func TestURLList(t *testing.T) {
+	_, err := URL("", t.TempDir()).List()
+	require.Error(t, err)
+}
+
+func TestVerifyCommand(t *testing.T) {
+	t.Run("gpg", func(t *testing.T) {
+		args, err := verifyCommand("gpg", "", "foo", "foo.sig", "")
+		require.NoError(t, err)
+		require.Equal(t, []string{"gpg", "--verify", "foo.sig", "foo"}, args)
+	})
+
+	t.Run("cosign", func(t *testing.T) {
+		args, err := verifyCommand("/usr/bin/cosign", "cosign.pub", "foo", "foo.sig", "foo.pem")
+		require.NoError(t, err)
+		require.Equal(t, []string{"/usr/bin/cosign", "verify-blob", "--signature", "foo.sig", "--cert", "foo.pem", "--key", "cosign.pub", "foo"}, args)
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		_, err := verifyCommand("signer", "", "foo", "foo.sig", "")
+		require.EqualError(t, err, "signatures created by signer can't be verified, only gpg and cosign are supported")
+	})
+}
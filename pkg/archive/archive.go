@@ -1,14 +1,19 @@
-// Package archive provides tar.gz and zip archiving
+// Package archive provides tar.gz, tar.xz, tar.zst, tar.lz4, zip and 7z archiving, as well as SquashFS images
 package archive
 
 import (
+	"io"
 	"os"
 	"strings"
 
 	"github.com/goreleaser/goreleaser/pkg/archive/gzip"
+	"github.com/goreleaser/goreleaser/pkg/archive/sevenzip"
+	"github.com/goreleaser/goreleaser/pkg/archive/squashfs"
 	"github.com/goreleaser/goreleaser/pkg/archive/tar"
 	"github.com/goreleaser/goreleaser/pkg/archive/targz"
+	"github.com/goreleaser/goreleaser/pkg/archive/tarlz4"
 	"github.com/goreleaser/goreleaser/pkg/archive/tarxz"
+	"github.com/goreleaser/goreleaser/pkg/archive/tarzst"
 	"github.com/goreleaser/goreleaser/pkg/archive/zip"
 	"github.com/goreleaser/goreleaser/pkg/config"
 )
@@ -19,22 +24,66 @@ type Archive interface {
 	Add(f config.File) error
 }
 
+// Options are used to customize archives on the formats that support them.
+type Options struct {
+	Compression config.ArchiveCompression
+	// Reproducible makes the tar based and zip formats not depend on the
+	// machine they are created on.
+	Reproducible bool
+	// Password protects zip and 7z archives, which are then created with
+	// the 7z tool.
+	// It is ignored by the other formats.
+	Password string
+	// PreserveHardlinks adds hard links as links in the tar based formats.
+	PreserveHardlinks bool
+}
+
 // New archive.
 func New(file *os.File) Archive {
-	if strings.HasSuffix(file.Name(), ".tar.gz") {
-		return targz.New(file)
+	return NewWithOptions(file, Options{})
+}
+
+// NewWithOptions creates a new archive with the given options.
+func NewWithOptions(file *os.File, opts Options) Archive {
+	return NewWriter(file, file.Name(), opts)
+}
+
+// NewWriter creates a new archive writing to the given writer, in the format
+// matching the extension of the given file name.
+func NewWriter(w io.Writer, name string, opts Options) Archive {
+	tarOpts := tar.Options{
+		Reproducible:      opts.Reproducible,
+		PreserveHardlinks: opts.PreserveHardlinks,
+	}
+	if strings.HasSuffix(name, ".tar.gz") {
+		return targz.NewWithOptions(w, tarOpts)
+	}
+	if strings.HasSuffix(name, ".gz") {
+		return gzip.New(w)
+	}
+	if strings.HasSuffix(name, ".tar.xz") {
+		return tarxz.NewWithOptions(w, tarOpts)
+	}
+	if strings.HasSuffix(name, ".tar.zst") {
+		return tarzst.NewWithOptions(w, opts.Compression, tarOpts)
+	}
+	if strings.HasSuffix(name, ".tar.lz4") {
+		return tarlz4.NewWithOptions(w, opts.Compression, tarOpts)
 	}
-	if strings.HasSuffix(file.Name(), ".gz") {
-		return gzip.New(file)
+	if strings.HasSuffix(name, ".zip") {
+		if opts.Password != "" {
+			return sevenzip.NewWithOptions(w, sevenzip.Options{Format: "zip", Password: opts.Password})
+		}
+		return zip.NewWithOptions(w, zip.Options{Reproducible: opts.Reproducible})
 	}
-	if strings.HasSuffix(file.Name(), ".tar.xz") {
-		return tarxz.New(file)
+	if strings.HasSuffix(name, ".7z") {
+		return sevenzip.NewWithOptions(w, sevenzip.Options{Password: opts.Password})
 	}
-	if strings.HasSuffix(file.Name(), ".zip") {
-		return zip.New(file)
+	if strings.HasSuffix(name, ".squashfs") {
+		return squashfs.New(w)
 	}
-	if strings.HasSuffix(file.Name(), ".tar") {
-		return tar.New(file)
+	if strings.HasSuffix(name, ".tar") {
+		return tar.NewWithOptions(w, tarOpts)
 	}
-	return targz.New(file)
+	return targz.NewWithOptions(w, tarOpts)
 }
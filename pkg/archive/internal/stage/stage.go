@@ -0,0 +1,136 @@
+// Package stage provides a temporary directory files can be copied into,
+// used by the archive formats which rely on external tools that can only
+// work on files already laid out on disk.
+package stage
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/goreleaser/goreleaser/pkg/config"
+)
+
+// Dir is a temporary staging directory.
+type Dir struct {
+	name    string
+	root    string
+	entries map[string]bool
+}
+
+// New staging directory for the given format name.
+// The directory is only created once the first file is added.
+func New(name string) *Dir {
+	return &Dir{
+		name:    name,
+		entries: map[string]bool{},
+	}
+}
+
+// Root is the path of the directory holding the staged files, or an empty
+// string if no files were added yet.
+func (d *Dir) Root() string {
+	if d.root == "" {
+		return ""
+	}
+	return filepath.Join(d.root, "files")
+}
+
+// Tmp is a path inside the staging directory, but outside of its root,
+// which can be used to write the final archive to.
+func (d *Dir) Tmp(name string) string {
+	return filepath.Join(d.root, name)
+}
+
+// Entries are the top level files and directories of the root, sorted.
+func (d *Dir) Entries() []string {
+	entries := make([]string, 0, len(d.entries))
+	for entry := range d.entries {
+		entries = append(entries, entry)
+	}
+	sort.Strings(entries)
+	return entries
+}
+
+// Remove the staging directory and all its contents.
+func (d *Dir) Remove() error {
+	if d.root == "" {
+		return nil
+	}
+	return os.RemoveAll(d.root)
+}
+
+// Add copies the given file into the staging directory, keeping its mode,
+// modification time and symlinks.
+func (d *Dir) Add(f config.File) error {
+	info, err := os.Lstat(f.Source) // #nosec
+	if err != nil {
+		return err
+	}
+	if d.root == "" {
+		root, err := os.MkdirTemp("", fmt.Sprintf("goreleaser-%s-", d.name))
+		if err != nil {
+			return err
+		}
+		d.root = root
+	}
+
+	dst := filepath.Join(d.Root(), filepath.FromSlash(f.Destination))
+	d.entries[strings.Split(filepath.ToSlash(filepath.Clean(f.Destination)), "/")[0]] = true
+	if info.IsDir() {
+		return os.MkdirAll(dst, 0o755)
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return err
+	}
+	if info.Mode()&os.ModeSymlink != 0 {
+		link, err := os.Readlink(f.Source) // #nosec
+		if err != nil {
+			return err
+		}
+		return os.Symlink(link, dst)
+	}
+
+	mode := info.Mode()
+	if f.Info.Mode != 0 {
+		mode = f.Info.Mode
+	}
+	if err := copyFile(f.Source, dst, mode); err != nil {
+		return err
+	}
+	if !f.Info.MTime.IsZero() {
+		return os.Chtimes(dst, f.Info.MTime, f.Info.MTime)
+	}
+	return os.Chtimes(dst, info.ModTime(), info.ModTime())
+}
+
+// CopyTo copies the file at the given path to the target writer.
+func CopyTo(target io.Writer, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(target, f)
+	return err
+}
+
+func copyFile(src, dst string, mode os.FileMode) error {
+	in, err := os.Open(src) // #nosec
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	if _, err := io.Copy(out, in); err != nil {
+		return err
+	}
+	return out.Close()
+}
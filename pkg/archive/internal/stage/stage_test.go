@@ -0,0 +1,61 @@
+package stage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStage(t *testing.T) {
+	dir := New("test")
+	require.Empty(t, dir.Root())
+	require.NoError(t, dir.Remove())
+
+	require.Error(t, dir.Add(config.File{
+		Source:      "../../testdata/nope.txt",
+		Destination: "nope.txt",
+	}))
+
+	mtime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	require.NoError(t, dir.Add(config.File{
+		Source:      "../../testdata/foo.txt",
+		Destination: "foo.txt",
+		Info: config.FileInfo{
+			Mode:  0o600,
+			MTime: mtime,
+		},
+	}))
+	require.NoError(t, dir.Add(config.File{
+		Source:      "../../testdata/sub1",
+		Destination: "sub1",
+	}))
+	require.NoError(t, dir.Add(config.File{
+		Source:      "../../testdata/sub1/sub2/subfoo.txt",
+		Destination: "sub1/sub2/subfoo.txt",
+	}))
+	require.NoError(t, dir.Add(config.File{
+		Source:      "../../testdata/link.txt",
+		Destination: "link.txt",
+	}))
+
+	require.NotEmpty(t, dir.Root())
+	require.Equal(t, []string{"foo.txt", "link.txt", "sub1"}, dir.Entries())
+
+	info, err := os.Stat(filepath.Join(dir.Root(), "foo.txt"))
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0o600), info.Mode())
+	require.True(t, mtime.Equal(info.ModTime()))
+
+	require.FileExists(t, filepath.Join(dir.Root(), "sub1", "sub2", "subfoo.txt"))
+
+	link, err := os.Readlink(filepath.Join(dir.Root(), "link.txt"))
+	require.NoError(t, err)
+	require.Equal(t, "regular.txt", link)
+
+	require.NoError(t, dir.Remove())
+	require.NoDirExists(t, dir.Root())
+}
@@ -0,0 +1,15 @@
+//go:build !windows
+// +build !windows
+
+package sevenzip
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// detach runs the command in a new session, without a controlling terminal,
+// so 7z reads the password from its standard input instead of the terminal.
+func detach(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+}
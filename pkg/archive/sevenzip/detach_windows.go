@@ -0,0 +1,7 @@
+package sevenzip
+
+import "os/exec"
+
+// detach does nothing, 7z reads the password from its standard input on
+// windows.
+func detach(cmd *exec.Cmd) {}
@@ -0,0 +1,112 @@
+// Package sevenzip implements the Archive interface providing 7z archiving
+// and compression.
+//
+// There is no pure Go 7z writer, so this relies on the 7z command line tool
+// being available in the $PATH.
+package sevenzip
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+
+	"github.com/goreleaser/goreleaser/pkg/archive/internal/stage"
+	"github.com/goreleaser/goreleaser/pkg/config"
+)
+
+// Cmd is the 7z command used to create the archives.
+const Cmd = "7z"
+
+// Options customizes the archives created by the 7z tool.
+type Options struct {
+	// Format of the archive, either 7z or zip.
+	// Defaults to 7z.
+	Format string
+	// Password encrypts the archive contents with AES-256.
+	// For 7z archives, the file names are encrypted too.
+	// It is written to the standard input of 7z, so it doesn't show up in
+	// the process list.
+	Password string
+}
+
+// Archive as 7z.
+type Archive struct {
+	target io.Writer
+	stage  *stage.Dir
+	opts   Options
+	closed bool
+}
+
+// New 7z archive.
+func New(target io.Writer) *Archive {
+	return NewWithOptions(target, Options{})
+}
+
+// NewWithOptions creates a new archive using the 7z tool with the given
+// options.
+func NewWithOptions(target io.Writer, opts Options) *Archive {
+	if opts.Format == "" {
+		opts.Format = "7z"
+	}
+	return &Archive{
+		target: target,
+		stage:  stage.New("7z"),
+		opts:   opts,
+	}
+}
+
+func (a *Archive) args(output string) []string {
+	args := []string{"a", "-t" + a.opts.Format, "-mx=9"}
+	if a.opts.Password != "" {
+		// without a value, 7z prompts for the password.
+		args = append(args, "-p")
+		if a.opts.Format == "zip" {
+			args = append(args, "-mem=AES256")
+		} else {
+			args = append(args, "-mhe=on")
+		}
+	}
+	// entries starting with a dash must not be parsed as switches.
+	args = append(args, output, "--")
+	return append(args, a.stage.Entries()...)
+}
+
+// Close creates the 7z archive with all the added files and writes it to
+// the target.
+func (a *Archive) Close() error {
+	if a.closed {
+		return nil
+	}
+	a.closed = true
+	if a.stage.Root() == "" {
+		return fmt.Errorf("7z: no files added to the archive")
+	}
+	defer a.stage.Remove() // nolint: errcheck
+
+	output := a.stage.Tmp("archive." + a.opts.Format)
+	var b bytes.Buffer
+	// #nosec
+	cmd := exec.Command(Cmd, a.args(output)...)
+	cmd.Dir = a.stage.Root()
+	if a.opts.Password != "" {
+		// 7z asks for the password, and then to confirm it.
+		cmd.Stdin = strings.NewReader(a.opts.Password + "\n" + a.opts.Password + "\n")
+		detach(cmd)
+	}
+	cmd.Stdout = &b
+	cmd.Stderr = &b
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("7z: failed to create archive: %w: %s", err, b.String())
+	}
+	return stage.CopyTo(a.target, output)
+}
+
+// Add file to the archive.
+func (a *Archive) Add(f config.File) error {
+	if a.closed {
+		return fmt.Errorf("7z: failed to add %s, archive is already closed", f.Destination)
+	}
+	return a.stage.Add(f)
+}
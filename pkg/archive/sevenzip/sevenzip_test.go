@@ -0,0 +1,150 @@
+package sevenzip
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/testlib"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSevenZipFile(t *testing.T) {
+	testlib.CheckPath(t, Cmd)
+
+	path := filepath.Join(t.TempDir(), "test.7z")
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	defer f.Close() // nolint: errcheck
+	archive := New(f)
+
+	require.Error(t, archive.Add(config.File{
+		Source:      "../testdata/nope.txt",
+		Destination: "nope.txt",
+	}))
+	require.NoError(t, archive.Add(config.File{
+		Source:      "../testdata/foo.txt",
+		Destination: "foo.txt",
+	}))
+	require.NoError(t, archive.Add(config.File{
+		Source:      "../testdata/sub1",
+		Destination: "sub1",
+	}))
+	require.NoError(t, archive.Add(config.File{
+		Source:      "../testdata/sub1/bar.txt",
+		Destination: "sub1/bar.txt",
+	}))
+	require.NoError(t, archive.Add(config.File{
+		Source:      "../testdata/sub1/sub2/subfoo.txt",
+		Destination: "sub1/sub2/subfoo.txt",
+	}))
+
+	require.NoError(t, archive.Close())
+	require.Error(t, archive.Add(config.File{
+		Source:      "../testdata/foo.txt",
+		Destination: "foo2.txt",
+	}))
+	require.NoError(t, f.Close())
+
+	out, err := exec.Command(Cmd, "l", "-slt", path).CombinedOutput()
+	require.NoError(t, err, string(out))
+	for _, name := range []string{
+		"foo.txt",
+		"sub1",
+		filepath.Join("sub1", "bar.txt"),
+		filepath.Join("sub1", "sub2", "subfoo.txt"),
+	} {
+		require.Contains(t, string(out), "Path = "+name+"\n")
+	}
+}
+
+func TestSevenZipEmpty(t *testing.T) {
+	f, err := os.Create(filepath.Join(t.TempDir(), "test.7z"))
+	require.NoError(t, err)
+	defer f.Close() // nolint: errcheck
+	require.EqualError(t, New(f).Close(), "7z: no files added to the archive")
+}
+
+func TestSevenZipArgs(t *testing.T) {
+	for name, tt := range map[string]struct {
+		opts     Options
+		expected []string
+	}{
+		"default": {
+			opts:     Options{},
+			expected: []string{"a", "-t7z", "-mx=9", "out", "--"},
+		},
+		"7z with password": {
+			opts:     Options{Password: "secret"},
+			expected: []string{"a", "-t7z", "-mx=9", "-p", "-mhe=on", "out", "--"},
+		},
+		"zip with password": {
+			opts:     Options{Format: "zip", Password: "secret"},
+			expected: []string{"a", "-tzip", "-mx=9", "-p", "-mem=AES256", "out", "--"},
+		},
+	} {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, tt.expected, NewWithOptions(nil, tt.opts).args("out"))
+		})
+	}
+}
+
+func TestSevenZipPassword(t *testing.T) {
+	testlib.CheckPath(t, Cmd)
+
+	for _, format := range []string{"7z", "zip"} {
+		format := format
+		t.Run(format, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "test."+format)
+			f, err := os.Create(path)
+			require.NoError(t, err)
+			defer f.Close() // nolint: errcheck
+			archive := NewWithOptions(f, Options{Format: format, Password: "secret"})
+			require.NoError(t, archive.Add(config.File{
+				Source:      "../testdata/foo.txt",
+				Destination: "foo.txt",
+			}))
+			require.NoError(t, archive.Close())
+			require.NoError(t, f.Close())
+
+			out, err := exec.Command(Cmd, "t", "-pwrong", path).CombinedOutput()
+			require.Error(t, err, string(out))
+			out, err = exec.Command(Cmd, "t", "-psecret", path).CombinedOutput()
+			require.NoError(t, err, string(out))
+		})
+	}
+}
+
+func TestSevenZipPasswordNotInArgs(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script as 7z")
+	}
+	bin := t.TempDir()
+	out := filepath.Join(t.TempDir(), "out")
+	script := "#!/bin/sh\necho \"$@\" > " + out + ".args\ncat > " + out + ".stdin\n" +
+		"touch archive.7z\n"
+	require.NoError(t, os.WriteFile(filepath.Join(bin, Cmd), []byte(script), 0o755))
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	f, err := os.Create(filepath.Join(t.TempDir(), "test.7z"))
+	require.NoError(t, err)
+	defer f.Close() // nolint: errcheck
+	archive := NewWithOptions(f, Options{Password: "secret"})
+	require.NoError(t, archive.Add(config.File{
+		Source:      "../testdata/foo.txt",
+		Destination: "-foo.txt",
+	}))
+	_ = archive.Close()
+
+	args, err := os.ReadFile(out + ".args")
+	require.NoError(t, err)
+	require.NotContains(t, string(args), "secret")
+	require.Contains(t, string(args), " -- -foo.txt")
+	stdin, err := os.ReadFile(out + ".stdin")
+	require.NoError(t, err)
+	require.Equal(t, "secret\nsecret\n", string(stdin))
+}
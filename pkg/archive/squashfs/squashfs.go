@@ -0,0 +1,66 @@
+// Package squashfs implements the Archive interface providing SquashFS
+// images, which can be mounted as read-only file systems.
+//
+// This relies on the mksquashfs command line tool (from squashfs-tools)
+// being available in the $PATH.
+package squashfs
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os/exec"
+
+	"github.com/goreleaser/goreleaser/pkg/archive/internal/stage"
+	"github.com/goreleaser/goreleaser/pkg/config"
+)
+
+// Cmd is the command used to create the images.
+const Cmd = "mksquashfs"
+
+// Archive as SquashFS.
+type Archive struct {
+	target io.Writer
+	stage  *stage.Dir
+	closed bool
+}
+
+// New SquashFS image.
+func New(target io.Writer) *Archive {
+	return &Archive{
+		target: target,
+		stage:  stage.New("squashfs"),
+	}
+}
+
+// Close creates the SquashFS image with all the added files and writes it to
+// the target.
+func (a *Archive) Close() error {
+	if a.closed {
+		return nil
+	}
+	a.closed = true
+	if a.stage.Root() == "" {
+		return fmt.Errorf("squashfs: no files added to the image")
+	}
+	defer a.stage.Remove() // nolint: errcheck
+
+	output := a.stage.Tmp("image.squashfs")
+	var b bytes.Buffer
+	// #nosec
+	cmd := exec.Command(Cmd, a.stage.Root(), output, "-noappend", "-all-root", "-no-progress")
+	cmd.Stdout = &b
+	cmd.Stderr = &b
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("squashfs: failed to create image: %w: %s", err, b.String())
+	}
+	return stage.CopyTo(a.target, output)
+}
+
+// Add file to the image.
+func (a *Archive) Add(f config.File) error {
+	if a.closed {
+		return fmt.Errorf("squashfs: failed to add %s, image is already closed", f.Destination)
+	}
+	return a.stage.Add(f)
+}
@@ -0,0 +1,69 @@
+package squashfs
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/testlib"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSquashFSFile(t *testing.T) {
+	testlib.CheckPath(t, Cmd)
+	testlib.CheckPath(t, "unsquashfs")
+
+	path := filepath.Join(t.TempDir(), "test.squashfs")
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	defer f.Close() // nolint: errcheck
+	archive := New(f)
+
+	require.Error(t, archive.Add(config.File{
+		Source:      "../testdata/nope.txt",
+		Destination: "nope.txt",
+	}))
+	require.NoError(t, archive.Add(config.File{
+		Source:      "../testdata/foo.txt",
+		Destination: "foo.txt",
+	}))
+	require.NoError(t, archive.Add(config.File{
+		Source:      "../testdata/sub1",
+		Destination: "sub1",
+	}))
+	require.NoError(t, archive.Add(config.File{
+		Source:      "../testdata/sub1/bar.txt",
+		Destination: "sub1/bar.txt",
+	}))
+	require.NoError(t, archive.Add(config.File{
+		Source:      "../testdata/sub1/sub2/subfoo.txt",
+		Destination: "sub1/sub2/subfoo.txt",
+	}))
+
+	require.NoError(t, archive.Close())
+	require.Error(t, archive.Add(config.File{
+		Source:      "../testdata/foo.txt",
+		Destination: "foo2.txt",
+	}))
+	require.NoError(t, f.Close())
+
+	out, err := exec.Command("unsquashfs", "-l", path).CombinedOutput()
+	require.NoError(t, err, string(out))
+	for _, name := range []string{
+		"squashfs-root/foo.txt",
+		"squashfs-root/sub1",
+		"squashfs-root/sub1/bar.txt",
+		"squashfs-root/sub1/sub2/subfoo.txt",
+	} {
+		require.Contains(t, string(out), name+"\n")
+	}
+}
+
+func TestSquashFSEmpty(t *testing.T) {
+	f, err := os.Create(filepath.Join(t.TempDir(), "test.squashfs"))
+	require.NoError(t, err)
+	defer f.Close() // nolint: errcheck
+	require.EqualError(t, New(f).Close(), "squashfs: no files added to the image")
+}
@@ -0,0 +1,24 @@
+//go:build !windows
+// +build !windows
+
+package tar
+
+import (
+	"os"
+	"syscall"
+)
+
+type fileID struct {
+	dev uint64
+	ino uint64
+}
+
+// hardlinkID identifies the file behind the given info, if it has other
+// hard links.
+func hardlinkID(info os.FileInfo) (fileID, bool) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok || stat.Nlink < 2 {
+		return fileID{}, false
+	}
+	return fileID{dev: uint64(stat.Dev), ino: uint64(stat.Ino)}, true // nolint: unconvert
+}
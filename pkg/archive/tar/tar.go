@@ -5,19 +5,42 @@ import (
 	"archive/tar"
 	"io"
 	"os"
+	"time"
 
+	"github.com/goreleaser/goreleaser/internal/gio"
 	"github.com/goreleaser/goreleaser/pkg/config"
 )
 
 // Archive as tar.
 type Archive struct {
-	tw *tar.Writer
+	tw    *tar.Writer
+	opts  Options
+	links map[fileID]string
+}
+
+// Options to customize tar archives.
+type Options struct {
+	// Reproducible makes the archive not depend on the machine it is created
+	// on, zeroing the owner and group of all files, and setting their mode
+	// to 0755 if they are executable and to 0644 otherwise, unless they are
+	// explicitly set.
+	Reproducible bool
+	// PreserveHardlinks adds files that are hard links to a file already in
+	// the archive as links to it, instead of copying their contents again.
+	PreserveHardlinks bool
 }
 
 // New tar archive.
 func New(target io.Writer) Archive {
+	return NewWithOptions(target, Options{})
+}
+
+// NewWithOptions creates a new tar archive with the given options.
+func NewWithOptions(target io.Writer, opts Options) Archive {
 	return Archive{
-		tw: tar.NewWriter(target),
+		tw:    tar.NewWriter(target),
+		opts:  opts,
+		links: map[fileID]string{},
 	}
 }
 
@@ -49,6 +72,25 @@ func (a Archive) Add(f config.File) error {
 		return err
 	}
 	header.Name = f.Destination
+	if a.opts.PreserveHardlinks && info.Mode().IsRegular() {
+		if id, ok := hardlinkID(info); ok {
+			if target, ok := a.links[id]; ok {
+				header.Typeflag = tar.TypeLink
+				header.Linkname = target
+				header.Size = 0
+			} else {
+				a.links[id] = f.Destination
+			}
+		}
+	}
+	if a.opts.Reproducible {
+		header.Uid, header.Gid = 0, 0
+		header.Uname, header.Gname = "", ""
+		header.AccessTime, header.ChangeTime = time.Time{}, time.Time{}
+		if info.Mode()&os.ModeSymlink == 0 {
+			header.Mode = int64(gio.NormalizeMode(info.Mode()))
+		}
+	}
 	if !f.Info.MTime.IsZero() {
 		header.ModTime = f.Info.MTime
 	}
@@ -66,7 +108,7 @@ func (a Archive) Add(f config.File) error {
 	if err = a.tw.WriteHeader(header); err != nil {
 		return err
 	}
-	if info.IsDir() || info.Mode()&os.ModeSymlink != 0 {
+	if header.Typeflag != tar.TypeReg {
 		return nil
 	}
 	_, err = io.Copy(a.tw, file)
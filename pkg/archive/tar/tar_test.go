@@ -2,10 +2,12 @@ package tar
 
 import (
 	"archive/tar"
+	"fmt"
 	"io"
 	"io/fs"
 	"os"
 	"path/filepath"
+	"runtime"
 	"testing"
 	"time"
 
@@ -163,3 +165,143 @@ func TestTarInvalidLink(t *testing.T) {
 		Destination: "badlink.txt",
 	}), "open ../testdata/badlink.txt: no such file or directory")
 }
+
+func TestTarReproducible(t *testing.T) {
+	f, err := os.Create(filepath.Join(t.TempDir(), "test.tar"))
+	require.NoError(t, err)
+	defer f.Close() // nolint: errcheck
+	archive := NewWithOptions(f, Options{Reproducible: true})
+	defer archive.Close() // nolint: errcheck
+
+	require.NoError(t, archive.Add(config.File{
+		Source:      "../testdata/foo.txt",
+		Destination: "foo.txt",
+	}))
+	require.NoError(t, archive.Add(config.File{
+		Source:      "../testdata/regular.txt",
+		Destination: "regular.txt",
+		Info: config.FileInfo{
+			Owner: "carlos",
+			Group: "users",
+		},
+	}))
+
+	require.NoError(t, archive.Close())
+	require.NoError(t, f.Close())
+
+	f, err = os.Open(f.Name())
+	require.NoError(t, err)
+	defer f.Close() // nolint: errcheck
+
+	r := tar.NewReader(f)
+	next, err := r.Next()
+	require.NoError(t, err)
+	require.Equal(t, "foo.txt", next.Name)
+	require.Equal(t, 0, next.Uid)
+	require.Equal(t, 0, next.Gid)
+	require.Empty(t, next.Uname)
+	require.Empty(t, next.Gname)
+
+	next, err = r.Next()
+	require.NoError(t, err)
+	require.Equal(t, "regular.txt", next.Name)
+	require.Equal(t, 0, next.Uid)
+	require.Equal(t, 0, next.Gid)
+	require.Equal(t, "carlos", next.Uname)
+	require.Equal(t, "users", next.Gname)
+}
+
+func TestTarReproducibleModes(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file modes are not supported on windows")
+	}
+	dir := t.TempDir()
+	bin := filepath.Join(dir, "bin")
+	file := filepath.Join(dir, "file")
+	require.NoError(t, os.WriteFile(bin, []byte("binary"), 0o700))
+	require.NoError(t, os.WriteFile(file, []byte("file"), 0o600))
+	require.NoError(t, os.Chmod(bin, 0o770))
+	require.NoError(t, os.Chmod(file, 0o660))
+
+	path := filepath.Join(dir, "test.tar")
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	defer f.Close() // nolint: errcheck
+	archive := NewWithOptions(f, Options{Reproducible: true})
+	require.NoError(t, archive.Add(config.File{Source: bin, Destination: "bin"}))
+	require.NoError(t, archive.Add(config.File{Source: file, Destination: "file"}))
+	require.NoError(t, archive.Add(config.File{
+		Source:      file,
+		Destination: "private",
+		Info:        config.FileInfo{Mode: 0o600},
+	}))
+	require.NoError(t, archive.Close())
+	require.NoError(t, f.Close())
+
+	f, err = os.Open(path)
+	require.NoError(t, err)
+	defer f.Close() // nolint: errcheck
+	r := tar.NewReader(f)
+	for _, expected := range []struct {
+		name string
+		mode int64
+	}{
+		{"bin", 0o755},
+		{"file", 0o644},
+		{"private", 0o600},
+	} {
+		next, err := r.Next()
+		require.NoError(t, err)
+		require.Equal(t, expected.name, next.Name)
+		require.Equal(t, expected.mode, next.Mode)
+	}
+}
+
+func TestTarHardlinks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hard links are not detected on windows")
+	}
+	folder := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(folder, "a"), []byte("content"), 0o644))
+	require.NoError(t, os.Link(filepath.Join(folder, "a"), filepath.Join(folder, "b")))
+
+	for _, preserve := range []bool{true, false} {
+		preserve := preserve
+		t.Run(fmt.Sprintf("preserve=%v", preserve), func(t *testing.T) {
+			f, err := os.Create(filepath.Join(t.TempDir(), "test.tar"))
+			require.NoError(t, err)
+			defer f.Close() // nolint: errcheck
+			archive := NewWithOptions(f, Options{PreserveHardlinks: preserve})
+			for _, name := range []string{"a", "b"} {
+				require.NoError(t, archive.Add(config.File{
+					Source:      filepath.Join(folder, name),
+					Destination: "bin/" + name,
+				}))
+			}
+			require.NoError(t, archive.Close())
+			require.NoError(t, f.Close())
+
+			f, err = os.Open(f.Name())
+			require.NoError(t, err)
+			defer f.Close() // nolint: errcheck
+			r := tar.NewReader(f)
+
+			next, err := r.Next()
+			require.NoError(t, err)
+			require.Equal(t, "bin/a", next.Name)
+			require.Equal(t, byte(tar.TypeReg), next.Typeflag)
+
+			next, err = r.Next()
+			require.NoError(t, err)
+			require.Equal(t, "bin/b", next.Name)
+			if preserve {
+				require.Equal(t, byte(tar.TypeLink), next.Typeflag)
+				require.Equal(t, "bin/a", next.Linkname)
+				require.Zero(t, next.Size)
+			} else {
+				require.Equal(t, byte(tar.TypeReg), next.Typeflag)
+				require.Equal(t, int64(7), next.Size)
+			}
+		})
+	}
+}
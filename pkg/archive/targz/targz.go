@@ -18,9 +18,14 @@ type Archive struct {
 
 // New tar.gz archive.
 func New(target io.Writer) Archive {
+	return NewWithOptions(target, tar.Options{})
+}
+
+// NewWithOptions creates a new tar.gz archive with the given tar options.
+func NewWithOptions(target io.Writer, opts tar.Options) Archive {
 	// the error will be nil since the compression level is valid
 	gw, _ := gzip.NewWriterLevel(target, gzip.BestCompression)
-	tw := tar.New(gw)
+	tw := tar.NewWithOptions(gw, opts)
 	return Archive{
 		gw: gw,
 		tw: &tw,
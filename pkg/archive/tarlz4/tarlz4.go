@@ -0,0 +1,67 @@
+// Package tarlz4 implements the Archive interface providing tar.lz4 archiving
+// and compression.
+package tarlz4
+
+import (
+	"io"
+
+	"github.com/goreleaser/goreleaser/pkg/archive/tar"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/pierrec/lz4/v4"
+)
+
+// Archive as tar.lz4.
+type Archive struct {
+	lw *lz4.Writer
+	tw *tar.Archive
+}
+
+// New tar.lz4 archive.
+func New(target io.Writer) Archive {
+	return NewWithCompression(target, config.ArchiveCompression{})
+}
+
+// NewWithCompression creates a new tar.lz4 archive with the given compression
+// level and concurrency.
+// Level ranges from 1 to 9 and defaults to lz4's fast mode.
+// Concurrency defaults to 1.
+func NewWithCompression(target io.Writer, compression config.ArchiveCompression) Archive {
+	return NewWithOptions(target, compression, tar.Options{})
+}
+
+// NewWithOptions creates a new tar.lz4 archive with the given compression
+// settings and tar options.
+func NewWithOptions(target io.Writer, compression config.ArchiveCompression, tarOpts tar.Options) Archive {
+	opts := []lz4.Option{}
+	if compression.Level > 0 {
+		level := compression.Level
+		if level > 9 {
+			level = 9
+		}
+		opts = append(opts, lz4.CompressionLevelOption(lz4.CompressionLevel(1<<(7+level))))
+	}
+	if compression.Concurrency > 0 {
+		opts = append(opts, lz4.ConcurrencyOption(compression.Concurrency))
+	}
+	lw := lz4.NewWriter(target)
+	// the error will be nil since the options are valid
+	_ = lw.Apply(opts...)
+	tw := tar.NewWithOptions(lw, tarOpts)
+	return Archive{
+		lw: lw,
+		tw: &tw,
+	}
+}
+
+// Close all closeables.
+func (a Archive) Close() error {
+	if err := a.tw.Close(); err != nil {
+		return err
+	}
+	return a.lw.Close()
+}
+
+// Add file to the archive.
+func (a Archive) Add(f config.File) error {
+	return a.tw.Add(f)
+}
@@ -18,8 +18,13 @@ type Archive struct {
 
 // New tar.xz archive.
 func New(target io.Writer) Archive {
+	return NewWithOptions(target, tar.Options{})
+}
+
+// NewWithOptions creates a new tar.xz archive with the given tar options.
+func NewWithOptions(target io.Writer, opts tar.Options) Archive {
 	xzw, _ := xz.WriterConfig{DictCap: 16 * 1024 * 1024}.NewWriter(target)
-	tw := tar.New(xzw)
+	tw := tar.NewWithOptions(xzw, opts)
 	return Archive{
 		xzw: xzw,
 		tw:  &tw,
@@ -0,0 +1,62 @@
+// Package tarzst implements the Archive interface providing tar.zst archiving
+// and compression.
+package tarzst
+
+import (
+	"io"
+
+	"github.com/goreleaser/goreleaser/pkg/archive/tar"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Archive as tar.zst.
+type Archive struct {
+	zw *zstd.Encoder
+	tw *tar.Archive
+}
+
+// New tar.zst archive.
+func New(target io.Writer) Archive {
+	return NewWithCompression(target, config.ArchiveCompression{})
+}
+
+// NewWithCompression creates a new tar.zst archive with the given compression
+// level and concurrency.
+// Level follows the zstd levels (1-22) and defaults to zstd's default level.
+// Concurrency defaults to GOMAXPROCS.
+func NewWithCompression(target io.Writer, compression config.ArchiveCompression) Archive {
+	return NewWithOptions(target, compression, tar.Options{})
+}
+
+// NewWithOptions creates a new tar.zst archive with the given compression
+// settings and tar options.
+func NewWithOptions(target io.Writer, compression config.ArchiveCompression, tarOpts tar.Options) Archive {
+	opts := []zstd.EOption{}
+	if compression.Level > 0 {
+		opts = append(opts, zstd.WithEncoderLevel(zstd.EncoderLevelFromZstd(compression.Level)))
+	}
+	if compression.Concurrency > 0 {
+		opts = append(opts, zstd.WithEncoderConcurrency(compression.Concurrency))
+	}
+	// the error will be nil since the options are valid
+	zw, _ := zstd.NewWriter(target, opts...)
+	tw := tar.NewWithOptions(zw, tarOpts)
+	return Archive{
+		zw: zw,
+		tw: &tw,
+	}
+}
+
+// Close all closeables.
+func (a Archive) Close() error {
+	if err := a.tw.Close(); err != nil {
+		return err
+	}
+	return a.zw.Close()
+}
+
+// Add file to the archive.
+func (a Archive) Add(f config.File) error {
+	return a.tw.Add(f)
+}
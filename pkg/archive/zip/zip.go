@@ -7,23 +7,41 @@ import (
 	"compress/flate"
 	"io"
 	"os"
+	"path/filepath"
 
+	"github.com/goreleaser/goreleaser/internal/gio"
 	"github.com/goreleaser/goreleaser/pkg/config"
 )
 
 // Archive zip struct.
 type Archive struct {
-	z *zip.Writer
+	z    *zip.Writer
+	opts Options
+}
+
+// Options to customize zip archives.
+type Options struct {
+	// Reproducible makes the archive not depend on the machine it is created
+	// on, setting the mode of all files to 0755 if they are executable and
+	// to 0644 otherwise, unless it is explicitly set.
+	// Zip archives don't record the owner of the files.
+	Reproducible bool
 }
 
 // New zip archive.
 func New(target io.Writer) Archive {
+	return NewWithOptions(target, Options{})
+}
+
+// NewWithOptions creates a new zip archive with the given options.
+func NewWithOptions(target io.Writer, opts Options) Archive {
 	compressor := zip.NewWriter(target)
 	compressor.RegisterCompressor(zip.Deflate, func(out io.Writer) (io.WriteCloser, error) {
 		return flate.NewWriter(out, flate.BestCompression)
 	})
 	return Archive{
-		z: compressor,
+		z:    compressor,
+		opts: opts,
 	}
 }
 
@@ -33,6 +51,7 @@ func (a Archive) Close() error {
 }
 
 // Add a file to the zip archive.
+// Symlinks are added as links, with their target as contents.
 func (a Archive) Add(f config.File) error {
 	file, err := os.Open(f.Source) // #nosec
 	if err != nil {
@@ -52,16 +71,27 @@ func (a Archive) Add(f config.File) error {
 	}
 	header.Name = f.Destination
 	header.Method = zip.Deflate
+	if a.opts.Reproducible && info.Mode()&os.ModeSymlink == 0 {
+		header.SetMode(gio.NormalizeMode(info.Mode()))
+	}
 	if !f.Info.MTime.IsZero() {
 		header.Modified = f.Info.MTime
 	}
 	if f.Info.Mode != 0 {
-		header.SetMode(f.Info.Mode)
+		header.SetMode(f.Info.Mode | info.Mode()&os.ModeSymlink)
 	}
 	w, err := a.z.CreateHeader(header)
 	if err != nil {
 		return err
 	}
+	if info.Mode()&os.ModeSymlink != 0 {
+		link, err := os.Readlink(f.Source) // #nosec
+		if err != nil {
+			return err
+		}
+		_, err = io.WriteString(w, filepath.ToSlash(link))
+		return err
+	}
 	_, err = io.Copy(w, file)
 	return err
 }
@@ -2,9 +2,12 @@ package zip
 
 import (
 	"archive/zip"
+	"bytes"
+	"io"
 	"io/fs"
 	"os"
 	"path/filepath"
+	"runtime"
 	"testing"
 	"time"
 
@@ -136,3 +139,88 @@ func TestZipFileInfo(t *testing.T) {
 		require.Equal(t, fs.FileMode(0o755), next.FileInfo().Mode())
 	}
 }
+
+func TestZipReproducible(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file modes are not supported on windows")
+	}
+	mtime := time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)
+	create := func(tb testing.TB, binMode, fileMode fs.FileMode) []byte {
+		tb.Helper()
+		dir := tb.TempDir()
+		bin := filepath.Join(dir, "bin")
+		file := filepath.Join(dir, "file")
+		require.NoError(tb, os.WriteFile(bin, []byte("binary"), binMode))
+		require.NoError(tb, os.WriteFile(file, []byte("file"), fileMode))
+		require.NoError(tb, os.Chmod(bin, binMode))
+		require.NoError(tb, os.Chmod(file, fileMode))
+
+		path := filepath.Join(dir, "test.zip")
+		f, err := os.Create(path)
+		require.NoError(tb, err)
+		defer f.Close() // nolint: errcheck
+		archive := NewWithOptions(f, Options{Reproducible: true})
+		require.NoError(tb, archive.Add(config.File{
+			Source:      bin,
+			Destination: "bin",
+			Info:        config.FileInfo{MTime: mtime},
+		}))
+		require.NoError(tb, archive.Add(config.File{
+			Source:      file,
+			Destination: "file",
+			Info:        config.FileInfo{MTime: mtime},
+		}))
+		require.NoError(tb, archive.Add(config.File{
+			Source:      file,
+			Destination: "private",
+			Info:        config.FileInfo{MTime: mtime, Mode: 0o600},
+		}))
+		require.NoError(tb, archive.Close())
+		bts, err := os.ReadFile(path)
+		require.NoError(tb, err)
+		return bts
+	}
+
+	bts := create(t, 0o700, 0o600)
+	require.Equal(t, bts, create(t, 0o775, 0o664))
+
+	r, err := zip.NewReader(bytes.NewReader(bts), int64(len(bts)))
+	require.NoError(t, err)
+	modes := map[string]fs.FileMode{}
+	for _, f := range r.File {
+		modes[f.Name] = f.FileInfo().Mode()
+	}
+	require.Equal(t, map[string]fs.FileMode{
+		"bin":     0o755,
+		"file":    0o644,
+		"private": 0o600,
+	}, modes)
+}
+
+func TestZipSymlink(t *testing.T) {
+	f, err := os.Create(filepath.Join(t.TempDir(), "test.zip"))
+	require.NoError(t, err)
+	defer f.Close() // nolint: errcheck
+	archive := New(f)
+	require.NoError(t, archive.Add(config.File{
+		Source:      "../testdata/link.txt",
+		Destination: "link.txt",
+		Info: config.FileInfo{
+			Mode: 0o755,
+		},
+	}))
+	require.NoError(t, archive.Close())
+	require.NoError(t, f.Close())
+
+	r, err := zip.OpenReader(f.Name())
+	require.NoError(t, err)
+	defer r.Close() // nolint: errcheck
+	require.Len(t, r.File, 1)
+	require.True(t, r.File[0].Mode()&os.ModeSymlink != 0)
+	rc, err := r.File[0].Open()
+	require.NoError(t, err)
+	defer rc.Close() // nolint: errcheck
+	bts, err := io.ReadAll(rc)
+	require.NoError(t, err)
+	require.Equal(t, "regular.txt", string(bts))
+}
@@ -38,6 +38,28 @@ type GiteaURLs struct {
 	SkipTLSVerify bool   `yaml:"skip_tls_verify,omitempty"`
 }
 
+// BitbucketURLs holds the URLs to be used when using bitbucket.
+type BitbucketURLs struct {
+	API           string `yaml:"api,omitempty"`
+	Download      string `yaml:"download,omitempty"`
+	SkipTLSVerify bool   `yaml:"skip_tls_verify,omitempty"`
+}
+
+// AzureDevOpsURLs holds the URLs to be used when using azure devops.
+type AzureDevOpsURLs struct {
+	API           string `yaml:"api,omitempty"`
+	SkipTLSVerify bool   `yaml:"skip_tls_verify,omitempty"`
+}
+
+// AzureArtifacts is the Azure Artifacts feed the release assets are published
+// to, as an universal package.
+type AzureArtifacts struct {
+	Feed        string `yaml:"feed,omitempty"`
+	Scope       string `yaml:"scope,omitempty"`
+	Package     string `yaml:"package,omitempty"`
+	Description string `yaml:"description,omitempty"`
+}
+
 // Repo represents any kind of repo (github, gitlab, etc).
 // to upload releases into.
 type Repo struct {
@@ -68,6 +90,7 @@ type RepoRef struct {
 type HomebrewDependency struct {
 	Name string `yaml:"name,omitempty"`
 	Type string `yaml:"type,omitempty"`
+	OS   string `yaml:"os,omitempty" jsonschema:"enum=mac,enum=linux"`
 }
 
 // type alias to prevent stack overflowing in the custom unmarshaler.
@@ -88,6 +111,7 @@ func (a *HomebrewDependency) UnmarshalYAML(unmarshal func(interface{}) error) er
 
 	a.Name = dep.Name
 	a.Type = dep.Type
+	a.OS = dep.OS
 
 	return nil
 }
@@ -130,6 +154,96 @@ type AUR struct {
 	PrivateKey            string       `yaml:"private_key,omitempty"`
 }
 
+// Nix contains the nix section.
+type Nix struct {
+	Name                  string       `yaml:"name,omitempty"`
+	Path                  string       `yaml:"path,omitempty"`
+	Repository            RepoRef      `yaml:"repository,omitempty"`
+	CommitAuthor          CommitAuthor `yaml:"commit_author,omitempty"`
+	CommitMessageTemplate string       `yaml:"commit_msg_template,omitempty"`
+	IDs                   []string     `yaml:"ids,omitempty"`
+	Goarm                 string       `yaml:"goarm,omitempty"`
+	SkipUpload            string       `yaml:"skip_upload,omitempty"`
+	URLTemplate           string       `yaml:"url_template,omitempty"`
+	Install               string       `yaml:"install,omitempty"`
+	PostInstall           string       `yaml:"post_install,omitempty"`
+	Description           string       `yaml:"description,omitempty"`
+	Homepage              string       `yaml:"homepage,omitempty"`
+	License               string       `yaml:"license,omitempty"`
+}
+
+// Termux contains the termux section.
+type Termux struct {
+	Name                  string       `yaml:"name,omitempty"`
+	Path                  string       `yaml:"path,omitempty"`
+	Repository            RepoRef      `yaml:"repository,omitempty"`
+	PullRequest           PullRequest  `yaml:"pull_request,omitempty"`
+	CommitAuthor          CommitAuthor `yaml:"commit_author,omitempty"`
+	CommitMessageTemplate string       `yaml:"commit_msg_template,omitempty"`
+	IDs                   []string     `yaml:"ids,omitempty"`
+	SkipUpload            string       `yaml:"skip_upload,omitempty"`
+	URLTemplate           string       `yaml:"url_template,omitempty"`
+	Description           string       `yaml:"description,omitempty"`
+	Homepage              string       `yaml:"homepage,omitempty"`
+	License               string       `yaml:"license,omitempty"`
+	Maintainer            string       `yaml:"maintainer,omitempty"`
+	Dependencies          []string     `yaml:"dependencies,omitempty"`
+}
+
+// PullRequest is the pull request opened with the files pushed to a
+// repository, e.g. a termux package.
+type PullRequest struct {
+	Enabled bool    `yaml:"enabled,omitempty"`
+	Base    RepoRef `yaml:"base,omitempty"`
+}
+
+// MacPorts contains the macports section.
+type MacPorts struct {
+	Name                  string       `yaml:"name,omitempty"`
+	Path                  string       `yaml:"path,omitempty"`
+	Repository            RepoRef      `yaml:"repository,omitempty"`
+	PullRequest           PullRequest  `yaml:"pull_request,omitempty"`
+	CommitAuthor          CommitAuthor `yaml:"commit_author,omitempty"`
+	CommitMessageTemplate string       `yaml:"commit_msg_template,omitempty"`
+	IDs                   []string     `yaml:"ids,omitempty"`
+	SkipUpload            string       `yaml:"skip_upload,omitempty"`
+	URLTemplate           string       `yaml:"url_template,omitempty"`
+	Categories            []string     `yaml:"categories,omitempty"`
+	Maintainers           []string     `yaml:"maintainers,omitempty"`
+	Description           string       `yaml:"description,omitempty"`
+	LongDescription       string       `yaml:"long_description,omitempty"`
+	Homepage              string       `yaml:"homepage,omitempty"`
+	License               string       `yaml:"license,omitempty"`
+	Dependencies          []string     `yaml:"dependencies,omitempty"`
+	Destroot              string       `yaml:"destroot,omitempty"`
+}
+
+// Winget contains the winget section.
+type Winget struct {
+	Name                  string       `yaml:"name,omitempty"`
+	PackageIdentifier     string       `yaml:"package_identifier,omitempty"`
+	Publisher             string       `yaml:"publisher,omitempty"`
+	PublisherURL          string       `yaml:"publisher_url,omitempty"`
+	Author                string       `yaml:"author,omitempty"`
+	Copyright             string       `yaml:"copyright,omitempty"`
+	License               string       `yaml:"license,omitempty"`
+	LicenseURL            string       `yaml:"license_url,omitempty"`
+	ShortDescription      string       `yaml:"short_description,omitempty"`
+	Description           string       `yaml:"description,omitempty"`
+	Homepage              string       `yaml:"homepage,omitempty"`
+	ReleaseNotes          string       `yaml:"release_notes,omitempty"`
+	ReleaseNotesURL       string       `yaml:"release_notes_url,omitempty"`
+	Tags                  []string     `yaml:"tags,omitempty"`
+	Path                  string       `yaml:"path,omitempty"`
+	Repository            RepoRef      `yaml:"repository,omitempty"`
+	PullRequest           PullRequest  `yaml:"pull_request,omitempty"`
+	CommitAuthor          CommitAuthor `yaml:"commit_author,omitempty"`
+	CommitMessageTemplate string       `yaml:"commit_msg_template,omitempty"`
+	IDs                   []string     `yaml:"ids,omitempty"`
+	SkipUpload            string       `yaml:"skip_upload,omitempty"`
+	URLTemplate           string       `yaml:"url_template,omitempty"`
+}
+
 // GoFish contains the gofish section.
 type GoFish struct {
 	Name                  string       `yaml:"name,omitempty"`
@@ -158,6 +272,8 @@ type Homebrew struct {
 	PostInstall           string               `yaml:"post_install,omitempty"`
 	Dependencies          []HomebrewDependency `yaml:"dependencies,omitempty"`
 	Test                  string               `yaml:"test,omitempty"`
+	Tests                 []HomebrewTest       `yaml:"tests,omitempty"`
+	Service               HomebrewService      `yaml:"service,omitempty"`
 	Conflicts             []string             `yaml:"conflicts,omitempty"`
 	Description           string               `yaml:"description,omitempty"`
 	Homepage              string               `yaml:"homepage,omitempty"`
@@ -169,6 +285,28 @@ type Homebrew struct {
 	CustomBlock           string               `yaml:"custom_block,omitempty"`
 	IDs                   []string             `yaml:"ids,omitempty"`
 	Goarm                 string               `yaml:"goarm,omitempty"`
+	Bottles               HomebrewBottles      `yaml:"bottles,omitempty"`
+}
+
+// HomebrewTest is a command run by the test block of a brew formula.
+type HomebrewTest struct {
+	Command string `yaml:"command,omitempty"`
+	Output  string `yaml:"output,omitempty"`
+}
+
+// HomebrewService configures the service block of a brew formula.
+type HomebrewService struct {
+	Run          []string `yaml:"run,omitempty"`
+	KeepAlive    bool     `yaml:"keep_alive,omitempty"`
+	LogPath      string   `yaml:"log_path,omitempty"`
+	ErrorLogPath string   `yaml:"error_log_path,omitempty"`
+}
+
+// HomebrewBottles configures the bottles built for a brew formula.
+type HomebrewBottles struct {
+	Enabled       bool     `yaml:"enabled,omitempty"`
+	RootURL       string   `yaml:"root_url,omitempty"`
+	MacOSVersions []string `yaml:"macos_versions,omitempty"`
 }
 
 // Krew contains the krew section.
@@ -185,31 +323,119 @@ type Krew struct {
 	URLTemplate           string       `yaml:"url_template,omitempty"`
 	Goarm                 string       `yaml:"goarm,omitempty"`
 	SkipUpload            string       `yaml:"skip_upload,omitempty"`
+	Files                 []KrewFile   `yaml:"files,omitempty"`
+	Plugins               []KrewPlugin `yaml:"plugins,omitempty"`
+}
+
+// KrewPlugin is one of the plugins shipped in the archives of a krew config,
+// each one getting its own manifest.
+type KrewPlugin struct {
+	Name             string     `yaml:"name,omitempty"`
+	Binary           string     `yaml:"binary,omitempty"`
+	ShortDescription string     `yaml:"short_description,omitempty"`
+	Description      string     `yaml:"description,omitempty"`
+	Homepage         string     `yaml:"homepage,omitempty"`
+	Caveats          string     `yaml:"caveats,omitempty"`
+	Files            []KrewFile `yaml:"files,omitempty"`
+}
+
+// KrewFile is a file of an archive installed with a krew plugin.
+type KrewFile struct {
+	From string `yaml:"from,omitempty"`
+	To   string `yaml:"to,omitempty"`
 }
 
 // Scoop contains the scoop.sh section.
 type Scoop struct {
-	Name                  string       `yaml:"name,omitempty"`
-	Bucket                RepoRef      `yaml:"bucket,omitempty"`
-	Folder                string       `yaml:"folder,omitempty"`
-	CommitAuthor          CommitAuthor `yaml:"commit_author,omitempty"`
-	CommitMessageTemplate string       `yaml:"commit_msg_template,omitempty"`
-	Homepage              string       `yaml:"homepage,omitempty"`
-	Description           string       `yaml:"description,omitempty"`
-	License               string       `yaml:"license,omitempty"`
-	URLTemplate           string       `yaml:"url_template,omitempty"`
-	Persist               []string     `yaml:"persist,omitempty"`
-	SkipUpload            string       `yaml:"skip_upload,omitempty"`
-	PreInstall            []string     `yaml:"pre_install,omitempty"`
-	PostInstall           []string     `yaml:"post_install,omitempty"`
+	Name                  string          `yaml:"name,omitempty"`
+	Bucket                RepoRef         `yaml:"bucket,omitempty"`
+	Folder                string          `yaml:"folder,omitempty"`
+	PullRequest           PullRequest     `yaml:"pull_request,omitempty"`
+	Buckets               []ScoopBucket   `yaml:"buckets,omitempty"`
+	CommitAuthor          CommitAuthor    `yaml:"commit_author,omitempty"`
+	CommitMessageTemplate string          `yaml:"commit_msg_template,omitempty"`
+	Homepage              string          `yaml:"homepage,omitempty"`
+	Description           string          `yaml:"description,omitempty"`
+	License               string          `yaml:"license,omitempty"`
+	URLTemplate           string          `yaml:"url_template,omitempty"`
+	Persist               []string        `yaml:"persist,omitempty"`
+	SkipUpload            string          `yaml:"skip_upload,omitempty"`
+	PreInstall            []string        `yaml:"pre_install,omitempty"`
+	PostInstall           []string        `yaml:"post_install,omitempty"`
+	Autoupdate            ScoopAutoupdate `yaml:"autoupdate,omitempty"`
+}
+
+// ScoopBucket is a bucket a scoop manifest is pushed to, besides the one set
+// in `bucket`.
+type ScoopBucket struct {
+	Repository  RepoRef     `yaml:"repository,omitempty"`
+	Folder      string      `yaml:"folder,omitempty"`
+	PullRequest PullRequest `yaml:"pull_request,omitempty"`
+}
+
+// ScoopAutoupdate configures the checkver and autoupdate sections of a scoop
+// manifest, so buckets can update it to new releases by themselves.
+type ScoopAutoupdate struct {
+	Enabled bool   `yaml:"enabled,omitempty"`
+	URL     string `yaml:"url,omitempty"`
+	Regex   string `yaml:"regex,omitempty"`
+}
+
+// Chocolatey contains the chocolatey section.
+type Chocolatey struct {
+	Name                     string                 `yaml:"name,omitempty"`
+	IDs                      []string               `yaml:"ids,omitempty"`
+	PackageSourceURL         string                 `yaml:"package_source_url,omitempty"`
+	Owners                   string                 `yaml:"owners,omitempty"`
+	Title                    string                 `yaml:"title,omitempty"`
+	Authors                  string                 `yaml:"authors,omitempty"`
+	ProjectURL               string                 `yaml:"project_url,omitempty"`
+	URLTemplate              string                 `yaml:"url_template,omitempty"`
+	IconURL                  string                 `yaml:"icon_url,omitempty"`
+	Copyright                string                 `yaml:"copyright,omitempty"`
+	LicenseURL               string                 `yaml:"license_url,omitempty"`
+	RequireLicenseAcceptance bool                   `yaml:"require_license_acceptance,omitempty"`
+	ProjectSourceURL         string                 `yaml:"project_source_url,omitempty"`
+	DocsURL                  string                 `yaml:"docs_url,omitempty"`
+	BugTrackerURL            string                 `yaml:"bug_tracker_url,omitempty"`
+	Tags                     string                 `yaml:"tags,omitempty"`
+	Summary                  string                 `yaml:"summary,omitempty"`
+	Description              string                 `yaml:"description,omitempty"`
+	ReleaseNotes             string                 `yaml:"release_notes,omitempty"`
+	Dependencies             []ChocolateyDependency `yaml:"dependencies,omitempty"`
+	SkipPublish              bool                   `yaml:"skip_publish,omitempty"`
+	APIKey                   string                 `yaml:"api_key,omitempty"`
+	SourceRepo               string                 `yaml:"source_repo,omitempty"`
+}
+
+// ChocolateyDependency is a package the chocolatey package depends on.
+type ChocolateyDependency struct {
+	ID      string `yaml:"id,omitempty"`
+	Version string `yaml:"version,omitempty"`
 }
 
 // CommitAuthor is the author of a Git commit.
 type CommitAuthor struct {
+	Name      string        `yaml:"name,omitempty"`
+	Email     string        `yaml:"email,omitempty"`
+	Committer Committer     `yaml:"committer,omitempty"`
+	Signing   CommitSigning `yaml:"signing,omitempty"`
+}
+
+// Committer is the committer of a Git commit, when it is not its author.
+type Committer struct {
 	Name  string `yaml:"name,omitempty"`
 	Email string `yaml:"email,omitempty"`
 }
 
+// CommitSigning holds the commit signing configuration.
+type CommitSigning struct {
+	Enabled bool   `yaml:"enabled,omitempty"`
+	Key     string `yaml:"key,omitempty"`
+	Program string `yaml:"program,omitempty"`
+	Format  string `yaml:"format,omitempty" jsonschema:"enum=openpgp,enum=x509,enum=ssh,default=openpgp"`
+}
+
 // BuildHooks define actions to run before and/or after something.
 type BuildHooks struct { // renamed on pro
 	Pre  string `yaml:"pre,omitempty"`
@@ -288,30 +514,58 @@ func (a FlagArray) JSONSchemaType() *jsonschema.Type {
 
 // Build contains the build configuration section.
 type Build struct {
-	ID              string          `yaml:"id,omitempty"`
-	Goos            []string        `yaml:"goos,omitempty"`
-	Goarch          []string        `yaml:"goarch,omitempty"`
-	Goarm           []string        `yaml:"goarm,omitempty"`
-	Gomips          []string        `yaml:"gomips,omitempty"`
-	Targets         []string        `yaml:"targets,omitempty"`
-	Ignore          []IgnoredBuild  `yaml:"ignore,omitempty"`
-	Dir             string          `yaml:"dir,omitempty"`
-	Main            string          `yaml:"main,omitempty"`
-	Ldflags         StringArray     `yaml:"ldflags,omitempty"`
-	Tags            FlagArray       `yaml:"tags,omitempty"`
-	Flags           FlagArray       `yaml:"flags,omitempty"`
-	Binary          string          `yaml:"binary,omitempty"`
-	Hooks           BuildHookConfig `yaml:"hooks,omitempty"`
-	Env             []string        `yaml:"env,omitempty"`
-	Builder         string          `yaml:"builder,omitempty"`
-	Asmflags        StringArray     `yaml:"asmflags,omitempty"`
-	Gcflags         StringArray     `yaml:"gcflags,omitempty"`
-	ModTimestamp    string          `yaml:"mod_timestamp,omitempty"`
-	Skip            bool            `yaml:"skip,omitempty"`
-	GoBinary        string          `yaml:"gobinary,omitempty"`
-	NoUniqueDistDir bool            `yaml:"no_unique_dist_dir,omitempty"`
-	UnproxiedMain   string          `yaml:"-"` // used by gomod.proxy
-	UnproxiedDir    string          `yaml:"-"` // used by gomod.proxy
+	ID                      string          `yaml:"id,omitempty"`
+	Goos                    []string        `yaml:"goos,omitempty"`
+	Goarch                  []string        `yaml:"goarch,omitempty"`
+	Goarm                   []string        `yaml:"goarm,omitempty"`
+	Gomips                  []string        `yaml:"gomips,omitempty"`
+	Targets                 []string        `yaml:"targets,omitempty"`
+	Ignore                  []IgnoredBuild  `yaml:"ignore,omitempty"`
+	Dir                     string          `yaml:"dir,omitempty"`
+	Main                    string          `yaml:"main,omitempty"`
+	Ldflags                 StringArray     `yaml:"ldflags,omitempty"`
+	Tags                    FlagArray       `yaml:"tags,omitempty"`
+	Flags                   FlagArray       `yaml:"flags,omitempty"`
+	Binary                  string          `yaml:"binary,omitempty"`
+	Hooks                   BuildHookConfig `yaml:"hooks,omitempty"`
+	Env                     []string        `yaml:"env,omitempty"`
+	Builder                 string          `yaml:"builder,omitempty"`
+	Asmflags                StringArray     `yaml:"asmflags,omitempty"`
+	Gcflags                 StringArray     `yaml:"gcflags,omitempty"`
+	ModTimestamp            string          `yaml:"mod_timestamp,omitempty"`
+	Skip                    bool            `yaml:"skip,omitempty"`
+	GoBinary                string          `yaml:"gobinary,omitempty"`
+	NoUniqueDistDir         bool            `yaml:"no_unique_dist_dir,omitempty"`
+	PruneUnsupportedTargets bool            `yaml:"prune_unsupported_targets,omitempty"`
+	Variants                []BuildVariant  `yaml:"variants,omitempty"`
+	UnproxiedMain           string          `yaml:"-"` // used by gomod.proxy
+	UnproxiedDir            string          `yaml:"-"` // used by gomod.proxy
+	Variant                 *BuildVariant   `yaml:"-"` // set on the builds created from variants
+}
+
+// BuildVariant is a variant of a build, e.g. with the race detector enabled,
+// built alongside it.
+type BuildVariant struct {
+	ID      string              `yaml:"id,omitempty"`
+	Binary  string              `yaml:"binary,omitempty"`
+	Env     []string            `yaml:"env,omitempty"`
+	Flags   FlagArray           `yaml:"flags,omitempty"`
+	Tags    FlagArray           `yaml:"tags,omitempty"`
+	Ldflags StringArray         `yaml:"ldflags,omitempty"`
+	Gcflags StringArray         `yaml:"gcflags,omitempty"`
+	Archive BuildVariantArchive `yaml:"archive,omitempty"`
+}
+
+// BuildVariantArchive configures the separate archives of a build variant.
+type BuildVariantArchive struct {
+	Enabled      bool   `yaml:"enabled,omitempty"`
+	NameTemplate string `yaml:"name_template,omitempty"`
+}
+
+// Platform is a custom alias for a list of build targets.
+type Platform struct {
+	Name    string   `yaml:"name,omitempty"`
+	Targets []string `yaml:"targets,omitempty"`
 }
 
 type BuildHookConfig struct {
@@ -358,9 +612,11 @@ func (bhc Hooks) JSONSchemaType() *jsonschema.Type {
 }
 
 type Hook struct {
-	Dir string   `yaml:"dir,omitempty"`
-	Cmd string   `yaml:"cmd,omitempty"`
-	Env []string `yaml:"env,omitempty"`
+	Dir   string   `yaml:"dir,omitempty"`
+	Cmd   string   `yaml:"cmd,omitempty"`
+	Env   []string `yaml:"env,omitempty"`
+	Image string   `yaml:"image,omitempty"`
+	Use   string   `yaml:"use,omitempty" jsonschema:"enum=docker,enum=podman,default=docker"`
 }
 
 // UnmarshalYAML is a custom unmarshaler that allows simplified declarations of commands as strings.
@@ -410,6 +666,24 @@ type File struct {
 	Info        FileInfo `yaml:"info,omitempty"`
 }
 
+// FileGenerate is a file rendered from a template.
+type FileGenerate struct {
+	ID       string      `yaml:"id,omitempty"`
+	Src      string      `yaml:"src,omitempty"`
+	Contents string      `yaml:"contents,omitempty"`
+	Dst      string      `yaml:"dst,omitempty"`
+	Repo     bool        `yaml:"repo,omitempty"`
+	Mode     os.FileMode `yaml:"mode,omitempty"`
+	Release  bool        `yaml:"release,omitempty"`
+}
+
+// Directory is a directory to publish as a whole, e.g. generated docs.
+type Directory struct {
+	ID           string `yaml:"id,omitempty"`
+	Path         string `yaml:"path,omitempty"`
+	NameTemplate string `yaml:"name_template,omitempty"`
+}
+
 // FileInfo is the file info of a file.
 type FileInfo struct {
 	Owner string      `yaml:"owner,omitempty"`
@@ -465,17 +739,116 @@ type UniversalBinary struct {
 	Hooks        BuildHookConfig `yaml:"hooks,omitempty"`
 }
 
+// Notarize configures the signing and notarization of binaries.
+type Notarize struct {
+	MacOS []MacOSSignNotarize `yaml:"macos,omitempty"`
+}
+
+// MacOSSignNotarize configures the codesigning and notarization of macOS
+// binaries.
+type MacOSSignNotarize struct {
+	ID       string        `yaml:"id,omitempty"`
+	IDs      []string      `yaml:"ids,omitempty"`
+	Sign     MacOSSign     `yaml:"sign,omitempty"`
+	Notarize MacOSNotarize `yaml:"notarize,omitempty"`
+	Gon      string        `yaml:"gon,omitempty"`
+}
+
+// MacOSSign configures how macOS binaries are codesigned.
+type MacOSSign struct {
+	Identity     string `yaml:"identity,omitempty"`
+	Certificate  string `yaml:"certificate,omitempty"`
+	Password     string `yaml:"password,omitempty"`
+	Entitlements string `yaml:"entitlements,omitempty"`
+}
+
+// MacOSNotarize configures the notarization of macOS binaries.
+type MacOSNotarize struct {
+	Enabled  bool          `yaml:"enabled,omitempty"`
+	IssuerID string        `yaml:"issuer_id,omitempty"`
+	KeyID    string        `yaml:"key_id,omitempty"`
+	Key      string        `yaml:"key,omitempty"`
+	Wait     bool          `yaml:"wait,omitempty"`
+	Timeout  time.Duration `yaml:"timeout,omitempty"`
+}
+
+// Authenticode configures the Authenticode signing of Windows binaries and
+// installers.
+type Authenticode struct {
+	ID           string   `yaml:"id,omitempty"`
+	IDs          []string `yaml:"ids,omitempty"`
+	Files        []string `yaml:"files,omitempty"`
+	Cmd          string   `yaml:"cmd,omitempty"`
+	Certificate  string   `yaml:"certificate,omitempty"`
+	Key          string   `yaml:"key,omitempty"`
+	Password     string   `yaml:"password,omitempty"`
+	PKCS11Engine string   `yaml:"pkcs11_engine,omitempty"`
+	PKCS11Module string   `yaml:"pkcs11_module,omitempty"`
+	Hash         string   `yaml:"hash,omitempty"`
+	Description  string   `yaml:"description,omitempty"`
+	URL          string   `yaml:"url,omitempty"`
+	TimestampURL string   `yaml:"timestamp_url,omitempty"`
+}
+
+// ArchiveCompression customizes the compression of archives.
+type ArchiveCompression struct {
+	Level       int `yaml:"level,omitempty"`
+	Concurrency int `yaml:"concurrency,omitempty"`
+}
+
+// ArchiveSplit configures the splitting of large archives into parts.
+type ArchiveSplit struct {
+	Size string `yaml:"size,omitempty"`
+}
+
+// ArchiveEncryption configures the encryption of archives.
+type ArchiveEncryption struct {
+	Password   string   `yaml:"password,omitempty"`
+	Recipients []string `yaml:"recipients,omitempty"`
+}
+
+// ArchiveWrapper configures a wrapper script that runs one of the binaries of
+// an archive.
+type ArchiveWrapper struct {
+	NameTemplate string   `yaml:"name_template,omitempty"`
+	Binary       string   `yaml:"binary,omitempty"`
+	Type         string   `yaml:"type,omitempty" jsonschema:"enum=sh,enum=bat"`
+	Args         []string `yaml:"args,omitempty"`
+	Env          []string `yaml:"env,omitempty"`
+}
+
 // Archive config used for the archive.
 type Archive struct {
-	ID                        string            `yaml:"id,omitempty"`
-	Builds                    []string          `yaml:"builds,omitempty"`
-	NameTemplate              string            `yaml:"name_template,omitempty"`
-	Replacements              map[string]string `yaml:"replacements,omitempty"`
-	Format                    string            `yaml:"format,omitempty"`
-	FormatOverrides           []FormatOverride  `yaml:"format_overrides,omitempty"`
-	WrapInDirectory           string            `yaml:"wrap_in_directory,omitempty"`
-	Files                     []File            `yaml:"files,omitempty"`
-	AllowDifferentBinaryCount bool              `yaml:"allow_different_binary_count,omitempty"`
+	ID                        string             `yaml:"id,omitempty"`
+	Builds                    []string           `yaml:"builds,omitempty"`
+	NameTemplate              string             `yaml:"name_template,omitempty"`
+	Replacements              map[string]string  `yaml:"replacements,omitempty"`
+	Format                    string             `yaml:"format,omitempty"`
+	FormatOverrides           []FormatOverride   `yaml:"format_overrides,omitempty"`
+	WrapInDirectory           string             `yaml:"wrap_in_directory,omitempty"`
+	Files                     []File             `yaml:"files,omitempty"`
+	BuildsInfo                FileInfo           `yaml:"builds_info,omitempty"`
+	NormalizeModes            bool               `yaml:"normalize_modes,omitempty"`
+	AllowDifferentBinaryCount bool               `yaml:"allow_different_binary_count,omitempty"`
+	Compression               ArchiveCompression `yaml:"compression,omitempty"`
+	Reproducible              bool               `yaml:"reproducible,omitempty"`
+	Split                     ArchiveSplit       `yaml:"split,omitempty"`
+	Encryption                ArchiveEncryption  `yaml:"encryption,omitempty"`
+	Symlinks                  string             `yaml:"symlinks,omitempty" jsonschema:"enum=preserve,enum=follow,enum=reject,default=preserve"`
+	PreserveHardlinks         bool               `yaml:"preserve_hardlinks,omitempty"`
+	BinaryNameTemplate        string             `yaml:"binary_name_template,omitempty"`
+	Wrappers                  []ArchiveWrapper   `yaml:"wrappers,omitempty"`
+	SharedLibrary             SharedLibrary      `yaml:"shared_library,omitempty"`
+}
+
+// SharedLibrary config used to ship builds with -buildmode=c-shared as
+// versioned shared libraries, with a pkg-config file.
+type SharedLibrary struct {
+	Enabled     bool     `yaml:"enabled,omitempty"`
+	Name        string   `yaml:"name,omitempty"`
+	Description string   `yaml:"description,omitempty"`
+	Requires    []string `yaml:"requires,omitempty"`
+	Prefix      string   `yaml:"prefix,omitempty"`
 }
 
 type ReleaseNotesMode string
@@ -489,18 +862,24 @@ const (
 
 // Release config used for the GitHub/GitLab release.
 type Release struct {
-	GitHub                 Repo        `yaml:"github,omitempty"`
-	GitLab                 Repo        `yaml:"gitlab,omitempty"`
-	Gitea                  Repo        `yaml:"gitea,omitempty"`
-	Draft                  bool        `yaml:"draft,omitempty"`
-	Disable                bool        `yaml:"disable,omitempty"`
-	Prerelease             string      `yaml:"prerelease,omitempty"`
-	NameTemplate           string      `yaml:"name_template,omitempty"`
-	IDs                    []string    `yaml:"ids,omitempty"`
-	ExtraFiles             []ExtraFile `yaml:"extra_files,omitempty"`
-	DiscussionCategoryName string      `yaml:"discussion_category_name,omitempty"`
-	Header                 string      `yaml:"header,omitempty"`
-	Footer                 string      `yaml:"footer,omitempty"`
+	GitHub                 Repo            `yaml:"github,omitempty"`
+	GitLab                 Repo            `yaml:"gitlab,omitempty"`
+	Gitea                  Repo            `yaml:"gitea,omitempty"`
+	Bitbucket              Repo            `yaml:"bitbucket,omitempty"`
+	AzureDevOps            Repo            `yaml:"azure_devops,omitempty"`
+	AzureArtifacts         AzureArtifacts  `yaml:"azure_artifacts,omitempty"`
+	Draft                  bool            `yaml:"draft,omitempty"`
+	Disable                bool            `yaml:"disable,omitempty"`
+	Prerelease             string          `yaml:"prerelease,omitempty"`
+	NameTemplate           string          `yaml:"name_template,omitempty"`
+	IDs                    []string        `yaml:"ids,omitempty"`
+	Include                ArtifactFilters `yaml:"include,omitempty"`
+	Exclude                ArtifactFilters `yaml:"exclude,omitempty"`
+	ExtraFiles             []ExtraFile     `yaml:"extra_files,omitempty"`
+	DiscussionCategoryName string          `yaml:"discussion_category_name,omitempty"`
+	Header                 string          `yaml:"header,omitempty"`
+	Footer                 string          `yaml:"footer,omitempty"`
+	Attestations           bool            `yaml:"attestations,omitempty"`
 
 	ReleaseNotesMode ReleaseNotesMode `yaml:"mode,omitempty" jsonschema:"enum=keep-existing,enum=append,enum=prepend,enum=replace,default=keep-existing"`
 }
@@ -524,18 +903,41 @@ type NFPM struct {
 	NFPMOverridables `yaml:",inline"`
 	Overrides        map[string]NFPMOverridables `yaml:"overrides,omitempty"`
 
-	ID          string   `yaml:"id,omitempty"`
-	Builds      []string `yaml:"builds,omitempty"`
-	Formats     []string `yaml:"formats,omitempty"`
-	Section     string   `yaml:"section,omitempty"`
-	Priority    string   `yaml:"priority,omitempty"`
-	Vendor      string   `yaml:"vendor,omitempty"`
-	Homepage    string   `yaml:"homepage,omitempty"`
-	Maintainer  string   `yaml:"maintainer,omitempty"`
-	Description string   `yaml:"description,omitempty"`
-	License     string   `yaml:"license,omitempty"`
-	Bindir      string   `yaml:"bindir,omitempty"`
-	Meta        bool     `yaml:"meta,omitempty"` // make package without binaries - only deps
+	ID             string        `yaml:"id,omitempty"`
+	Builds         []string      `yaml:"builds,omitempty"`
+	Formats        []string      `yaml:"formats,omitempty"`
+	Section        string        `yaml:"section,omitempty"`
+	Priority       string        `yaml:"priority,omitempty"`
+	Vendor         string        `yaml:"vendor,omitempty"`
+	Homepage       string        `yaml:"homepage,omitempty"`
+	Maintainer     string        `yaml:"maintainer,omitempty"`
+	Description    string        `yaml:"description,omitempty"`
+	License        string        `yaml:"license,omitempty"`
+	Bindir         string        `yaml:"bindir,omitempty"`
+	Meta           bool          `yaml:"meta,omitempty"` // make package without binaries - only deps
+	NormalizeModes bool          `yaml:"normalize_modes,omitempty"`
+	SharedLibrary  SharedLibrary `yaml:"shared_library,omitempty"`
+	Systemd        []NFPMSystemd `yaml:"systemd,omitempty"`
+	Changelog      NFPMChangelog `yaml:"changelog,omitempty"`
+}
+
+// NFPMChangelog embeds the release changelog in deb and rpm packages.
+type NFPMChangelog struct {
+	Enabled       bool     `yaml:"enabled,omitempty"`
+	Urgency       string   `yaml:"urgency,omitempty"`
+	Distributions []string `yaml:"distributions,omitempty"`
+}
+
+// NFPMSystemd is a systemd unit installed by the package, with the
+// maintainer scripts to enable and disable it.
+type NFPMSystemd struct {
+	Name        string `yaml:"name,omitempty"`
+	Template    string `yaml:"template,omitempty"`
+	Description string `yaml:"description,omitempty"`
+	ExecStart   string `yaml:"exec_start,omitempty"`
+	User        string `yaml:"user,omitempty"`
+	SkipEnable  bool   `yaml:"skip_enable,omitempty"`
+	Start       bool   `yaml:"start,omitempty"`
 }
 
 // NFPMScripts is used to specify maintainer scripts.
@@ -622,6 +1024,17 @@ type NFPMAPK struct {
 	Signature NFPMAPKSignature `yaml:"signature,omitempty"`
 }
 
+// NFPMIPK is custom config only available on ipk packages.
+type NFPMIPK struct {
+	Arch          string            `yaml:"arch,omitempty"`
+	ABIVersion    string            `yaml:"abi_version,omitempty"`
+	AutoInstalled bool              `yaml:"auto_installed,omitempty"`
+	Essential     bool              `yaml:"essential,omitempty"`
+	Predepends    []string          `yaml:"predepends,omitempty"`
+	Tags          []string          `yaml:"tags,omitempty"`
+	Fields        map[string]string `yaml:"fields,omitempty"`
+}
+
 // NFPMOverridables is used to specify per package format settings.
 type NFPMOverridables struct {
 	FileNameTemplate string            `yaml:"file_name_template,omitempty"`
@@ -642,17 +1055,22 @@ type NFPMOverridables struct {
 	RPM              NFPMRPM           `yaml:"rpm,omitempty"`
 	Deb              NFPMDeb           `yaml:"deb,omitempty"`
 	APK              NFPMAPK           `yaml:"apk,omitempty"`
+	IPK              NFPMIPK           `yaml:"ipk,omitempty"`
 }
 
 // SBOM config.
 type SBOM struct {
-	ID        string   `yaml:"id,omitempty"`
-	Cmd       string   `yaml:"cmd,omitempty"`
-	Env       []string `yaml:"env,omitempty"`
-	Args      []string `yaml:"args,omitempty"`
-	Documents []string `yaml:"documents,omitempty"`
-	Artifacts string   `yaml:"artifacts,omitempty"`
-	IDs       []string `yaml:"ids,omitempty"`
+	ID             string   `yaml:"id,omitempty"`
+	Cmd            string   `yaml:"cmd,omitempty"`
+	Env            []string `yaml:"env,omitempty"`
+	Args           []string `yaml:"args,omitempty"`
+	Documents      []string `yaml:"documents,omitempty"`
+	Artifacts      string   `yaml:"artifacts,omitempty"`
+	IDs            []string `yaml:"ids,omitempty"`
+	Builtin        bool     `yaml:"builtin,omitempty"`
+	Format         string   `yaml:"format,omitempty"`
+	Attach         string   `yaml:"attach,omitempty"`
+	MergedDocument string   `yaml:"merged_document,omitempty"`
 }
 
 // Sign config.
@@ -668,6 +1086,26 @@ type Sign struct {
 	Env         []string `yaml:"env,omitempty"`
 	Certificate string   `yaml:"certificate,omitempty"`
 	Output      bool     `yaml:"output,omitempty"`
+	Sigstore    Sigstore `yaml:"sigstore,omitempty"`
+	GPG         GPG      `yaml:"gpg,omitempty"`
+}
+
+// GPG configures in-process gpg signing, without the gpg binary.
+type GPG struct {
+	Enabled    bool   `yaml:"enabled,omitempty"`
+	Key        string `yaml:"key,omitempty"`
+	KeyFile    string `yaml:"key_file,omitempty"`
+	Passphrase string `yaml:"passphrase,omitempty"`
+	Armor      bool   `yaml:"armor,omitempty"`
+}
+
+// Sigstore configures sigstore keyless signing.
+type Sigstore struct {
+	Enabled       bool   `yaml:"enabled,omitempty"`
+	IdentityToken string `yaml:"identity_token,omitempty"`
+	FulcioURL     string `yaml:"fulcio_url,omitempty"`
+	RekorURL      string `yaml:"rekor_url,omitempty"`
+	TrustedRoot   string `yaml:"trusted_root,omitempty"`
 }
 
 // SnapcraftAppMetadata for the binaries that will be in the snap package.
@@ -717,46 +1155,264 @@ type SnapcraftExtraFiles struct {
 	Mode        uint32 `yaml:"mode,omitempty"`
 }
 
+// APTRepository config, publishing the deb packages to an APT repository.
+type APTRepository struct {
+	ID                    string         `yaml:"id,omitempty"`
+	IDs                   []string       `yaml:"ids,omitempty"`
+	Name                  string         `yaml:"name,omitempty"`
+	URL                   string         `yaml:"url,omitempty"`
+	Distribution          string         `yaml:"distribution,omitempty"`
+	Component             string         `yaml:"component,omitempty"`
+	Origin                string         `yaml:"origin,omitempty"`
+	Label                 string         `yaml:"label,omitempty"`
+	Description           string         `yaml:"description,omitempty"`
+	KeyID                 string         `yaml:"key_id,omitempty"`
+	SkipUpload            string         `yaml:"skip_upload,omitempty"`
+	Blob                  RepositoryBlob `yaml:"blob,omitempty"`
+	GitHubPages           RepoRef        `yaml:"github_pages,omitempty"`
+	Folder                string         `yaml:"folder,omitempty"`
+	CommitAuthor          CommitAuthor   `yaml:"commit_author,omitempty"`
+	CommitMessageTemplate string         `yaml:"commit_msg_template,omitempty"`
+}
+
+// YUMRepository config, publishing the rpm packages to a YUM/DNF repository.
+type YUMRepository struct {
+	ID          string         `yaml:"id,omitempty"`
+	IDs         []string       `yaml:"ids,omitempty"`
+	Name        string         `yaml:"name,omitempty"`
+	URL         string         `yaml:"url,omitempty"`
+	Description string         `yaml:"description,omitempty"`
+	KeyID       string         `yaml:"key_id,omitempty"`
+	SkipUpload  string         `yaml:"skip_upload,omitempty"`
+	Blob        RepositoryBlob `yaml:"blob,omitempty"`
+	Folder      string         `yaml:"folder,omitempty"`
+}
+
+// APKRepository config, publishing the apk packages to an Alpine repository.
+type APKRepository struct {
+	ID          string         `yaml:"id,omitempty"`
+	IDs         []string       `yaml:"ids,omitempty"`
+	Name        string         `yaml:"name,omitempty"`
+	URL         string         `yaml:"url,omitempty"`
+	Description string         `yaml:"description,omitempty"`
+	KeyFile     string         `yaml:"key_file,omitempty"`
+	KeyName     string         `yaml:"key_name,omitempty"`
+	SkipUpload  string         `yaml:"skip_upload,omitempty"`
+	Blob        RepositoryBlob `yaml:"blob,omitempty"`
+	Folder      string         `yaml:"folder,omitempty"`
+}
+
+// PacmanRepository config, packaging the linux binaries as pacman packages and
+// publishing them to an Arch Linux binary repository.
+type PacmanRepository struct {
+	ID          string         `yaml:"id,omitempty"`
+	Builds      []string       `yaml:"builds,omitempty"`
+	Name        string         `yaml:"name,omitempty"`
+	URL         string         `yaml:"url,omitempty"`
+	Description string         `yaml:"description,omitempty"`
+	Homepage    string         `yaml:"homepage,omitempty"`
+	License     string         `yaml:"license,omitempty"`
+	Packager    string         `yaml:"packager,omitempty"`
+	Rel         string         `yaml:"rel,omitempty"`
+	Provides    []string       `yaml:"provides,omitempty"`
+	Conflicts   []string       `yaml:"conflicts,omitempty"`
+	Depends     []string       `yaml:"depends,omitempty"`
+	OptDepends  []string       `yaml:"optdepends,omitempty"`
+	KeyID       string         `yaml:"key_id,omitempty"`
+	SkipUpload  string         `yaml:"skip_upload,omitempty"`
+	Blob        RepositoryBlob `yaml:"blob,omitempty"`
+	Folder      string         `yaml:"folder,omitempty"`
+}
+
+// RepositoryBlob is the bucket a package repository is published to.
+type RepositoryBlob struct {
+	Provider   string `yaml:"provider,omitempty"`
+	Bucket     string `yaml:"bucket,omitempty"`
+	Region     string `yaml:"region,omitempty"`
+	Endpoint   string `yaml:"endpoint,omitempty"`
+	DisableSSL bool   `yaml:"disable_ssl,omitempty"`
+}
+
+// AppImage config.
+type AppImage struct {
+	ID                string   `yaml:"id,omitempty"`
+	Builds            []string `yaml:"builds,omitempty"`
+	NameTemplate      string   `yaml:"name_template,omitempty"`
+	Name              string   `yaml:"name,omitempty"`
+	Binary            string   `yaml:"binary,omitempty"`
+	Comment           string   `yaml:"comment,omitempty"`
+	Icon              string   `yaml:"icon,omitempty"`
+	Categories        []string `yaml:"categories,omitempty"`
+	Terminal          bool     `yaml:"terminal,omitempty"`
+	UpdateInformation string   `yaml:"update_information,omitempty"`
+	Files             []File   `yaml:"files,omitempty"`
+}
+
+// MSI config.
+type MSI struct {
+	ID           string   `yaml:"id,omitempty"`
+	Builds       []string `yaml:"builds,omitempty"`
+	NameTemplate string   `yaml:"name_template,omitempty"`
+	Name         string   `yaml:"name,omitempty"`
+	WXS          string   `yaml:"wxs,omitempty"`
+	Manufacturer string   `yaml:"manufacturer,omitempty"`
+	Description  string   `yaml:"description,omitempty"`
+	UpgradeCode  string   `yaml:"upgrade_code,omitempty"`
+	License      string   `yaml:"license,omitempty"`
+	SkipPath     bool     `yaml:"skip_path,omitempty"`
+}
+
+// DMG config.
+type DMG struct {
+	ID                   string   `yaml:"id,omitempty"`
+	Builds               []string `yaml:"builds,omitempty"`
+	NameTemplate         string   `yaml:"name_template,omitempty"`
+	VolumeName           string   `yaml:"volume_name,omitempty"`
+	App                  string   `yaml:"app,omitempty"`
+	Background           string   `yaml:"background,omitempty"`
+	SkipApplicationsLink bool     `yaml:"skip_applications_link,omitempty"`
+	Files                []File   `yaml:"files,omitempty"`
+}
+
 // Snapshot config.
 type Snapshot struct {
 	NameTemplate string `yaml:"name_template,omitempty"`
 }
 
+// ArtifactFilters selects artifacts by their IDs, types, goos and formats.
+type ArtifactFilters struct {
+	IDs       []string `yaml:"ids,omitempty"`
+	Types     []string `yaml:"types,omitempty"`
+	Goos      []string `yaml:"goos,omitempty"`
+	Formats   []string `yaml:"formats,omitempty"`
+	Platforms []string `yaml:"platforms,omitempty"`
+}
+
 // Checksum config.
 type Checksum struct {
-	NameTemplate string      `yaml:"name_template,omitempty"`
-	Algorithm    string      `yaml:"algorithm,omitempty"`
-	IDs          []string    `yaml:"ids,omitempty"`
-	Disable      bool        `yaml:"disable,omitempty"`
-	ExtraFiles   []ExtraFile `yaml:"extra_files,omitempty"`
+	NameTemplate    string          `yaml:"name_template,omitempty"`
+	Algorithm       string          `yaml:"algorithm,omitempty"`
+	IDs             []string        `yaml:"ids,omitempty"`
+	Types           []string        `yaml:"types,omitempty"`
+	Exclude         ArtifactFilters `yaml:"exclude,omitempty"`
+	Disable         bool            `yaml:"disable,omitempty"`
+	ExtraFiles      []ExtraFile     `yaml:"extra_files,omitempty"`
+	Split           bool            `yaml:"split,omitempty"`
+	DisableCombined bool            `yaml:"disable_combined,omitempty"`
+	Format          string          `yaml:"format,omitempty" jsonschema:"enum=gnu,enum=bsd,enum=json,default=gnu"`
 }
 
 // Docker image config.
 type Docker struct {
-	ID                 string   `yaml:"id,omitempty"`
-	IDs                []string `yaml:"ids,omitempty"`
-	Goos               string   `yaml:"goos,omitempty"`
-	Goarch             string   `yaml:"goarch,omitempty"`
-	Goarm              string   `yaml:"goarm,omitempty"`
-	Dockerfile         string   `yaml:"dockerfile,omitempty"`
-	ImageTemplates     []string `yaml:"image_templates,omitempty"`
-	SkipPush           string   `yaml:"skip_push,omitempty"`
-	Files              []string `yaml:"extra_files,omitempty"`
-	BuildFlagTemplates []string `yaml:"build_flag_templates,omitempty"`
-	PushFlags          []string `yaml:"push_flags,omitempty"`
-	Buildx             bool     `yaml:"use_buildx,omitempty"` // deprecated: use Use instead
-	Use                string   `yaml:"use,omitempty"`
+	ID                 string            `yaml:"id,omitempty"`
+	IDs                []string          `yaml:"ids,omitempty"`
+	Goos               string            `yaml:"goos,omitempty"`
+	Goarch             string            `yaml:"goarch,omitempty"`
+	Goarm              string            `yaml:"goarm,omitempty"`
+	Dockerfile         string            `yaml:"dockerfile,omitempty"`
+	ImageTemplates     []string          `yaml:"image_templates,omitempty"`
+	SkipPush           string            `yaml:"skip_push,omitempty"`
+	Files              []string          `yaml:"extra_files,omitempty"`
+	BuildFlagTemplates []string          `yaml:"build_flag_templates,omitempty"`
+	PushFlags          []string          `yaml:"push_flags,omitempty"`
+	Secrets            []string          `yaml:"secrets,omitempty"`
+	SSH                []string          `yaml:"ssh,omitempty"`
+	Buildx             bool              `yaml:"use_buildx,omitempty"` // deprecated: use Use instead
+	Use                string            `yaml:"use,omitempty"`
+	Auth               RegistryAuth      `yaml:"auth,omitempty"`
+	Save               bool              `yaml:"save,omitempty"`
+	SaveFormat         string            `yaml:"save_format,omitempty"`
+	SaveUpload         bool              `yaml:"save_upload,omitempty"`
+	Platforms          []string          `yaml:"platforms,omitempty"`
+	Provenance         string            `yaml:"provenance,omitempty"`
+	SBOM               string            `yaml:"sbom,omitempty"`
+	PromoteFrom        string            `yaml:"promote_from,omitempty"`
+	Annotations        map[string]string `yaml:"annotations,omitempty"`
+	Retry              Retry             `yaml:"retry,omitempty"`
+	BaseImages         DockerBaseImages  `yaml:"base_images,omitempty"`
+}
+
+// Ko is the configuration of an image built with ko, without a Dockerfile or
+// a docker daemon.
+type Ko struct {
+	ID                  string      `yaml:"id,omitempty"`
+	Build               string      `yaml:"build,omitempty"`
+	Main                string      `yaml:"main,omitempty"`
+	WorkingDir          string      `yaml:"working_dir,omitempty"`
+	BaseImage           string      `yaml:"base_image,omitempty"`
+	Repository          string      `yaml:"repository,omitempty"`
+	Platforms           []string    `yaml:"platforms,omitempty"`
+	Tags                []string    `yaml:"tags,omitempty"`
+	SBOM                string      `yaml:"sbom,omitempty"`
+	Ldflags             StringArray `yaml:"ldflags,omitempty"`
+	Flags               FlagArray   `yaml:"flags,omitempty"`
+	Env                 []string    `yaml:"env,omitempty"`
+	Bare                bool        `yaml:"bare,omitempty"`
+	PreserveImportPaths bool        `yaml:"preserve_import_paths,omitempty"`
+	BaseImportPaths     bool        `yaml:"base_import_paths,omitempty"`
+}
+
+// DockerScan is the configuration of a vulnerability scan of docker images.
+type DockerScan struct {
+	ID       string   `yaml:"id,omitempty"`
+	IDs      []string `yaml:"ids,omitempty"`
+	Scanner  string   `yaml:"scanner,omitempty" jsonschema:"enum=trivy,enum=grype,default=trivy"`
+	Severity string   `yaml:"severity,omitempty" jsonschema:"enum=low,enum=medium,enum=high,enum=critical,default=high"`
+	Args     []string `yaml:"args,omitempty"`
+	Release  bool     `yaml:"release,omitempty"`
+}
+
+// RegistryAuth are the credentials used to log in to a container registry,
+// instead of the ones from the docker config of the user.
+type RegistryAuth struct {
+	Registry string `yaml:"registry,omitempty"`
+	Username string `yaml:"username,omitempty"`
+	Password string `yaml:"password,omitempty"`
+}
+
+// Registry are the credentials goreleaser logs in to a container registry
+// with before pushing images to it.
+type Registry struct {
+	Address  string `yaml:"address,omitempty"`
+	Username string `yaml:"username,omitempty"`
+	Password string `yaml:"password,omitempty"`
+	Token    string `yaml:"token,omitempty"`
 }
 
 // DockerManifest config.
 type DockerManifest struct {
-	ID             string   `yaml:"id,omitempty"`
-	NameTemplate   string   `yaml:"name_template,omitempty"`
-	SkipPush       string   `yaml:"skip_push,omitempty"`
-	ImageTemplates []string `yaml:"image_templates,omitempty"`
-	CreateFlags    []string `yaml:"create_flags,omitempty"`
-	PushFlags      []string `yaml:"push_flags,omitempty"`
-	Use            string   `yaml:"use,omitempty"`
+	ID             string            `yaml:"id,omitempty"`
+	NameTemplate   string            `yaml:"name_template,omitempty"`
+	SkipPush       string            `yaml:"skip_push,omitempty"`
+	ImageTemplates []string          `yaml:"image_templates,omitempty"`
+	CreateFlags    []string          `yaml:"create_flags,omitempty"`
+	PushFlags      []string          `yaml:"push_flags,omitempty"`
+	Use            string            `yaml:"use,omitempty"`
+	Auth           RegistryAuth      `yaml:"auth,omitempty"`
+	Annotations    map[string]string `yaml:"annotations,omitempty"`
+	Retry          Retry             `yaml:"retry,omitempty"`
+}
+
+// Retry configures how pushes to registries are retried when they fail with
+// transient errors.
+type Retry struct {
+	Attempts uint          `yaml:"attempts,omitempty"`
+	Delay    time.Duration `yaml:"delay,omitempty"`
+	MaxDelay time.Duration `yaml:"max_delay,omitempty"`
+}
+
+// DockerBaseImages configures how the base images of a Dockerfile are pinned
+// to their digests and verified before building.
+type DockerBaseImages struct {
+	Pin    bool         `yaml:"pin,omitempty"`
+	Verify CosignVerify `yaml:"verify,omitempty"`
+}
+
+// CosignVerify is the policy the signatures of an image are verified with.
+type CosignVerify struct {
+	Key                   string `yaml:"key,omitempty"`
+	CertificateIdentity   string `yaml:"certificate_identity,omitempty"`
+	CertificateOIDCIssuer string `yaml:"certificate_oidc_issuer,omitempty"`
 }
 
 // Filters config.
@@ -766,11 +1422,13 @@ type Filters struct {
 
 // Changelog Config.
 type Changelog struct {
-	Filters Filters          `yaml:"filters,omitempty"`
-	Sort    string           `yaml:"sort,omitempty"`
-	Skip    bool             `yaml:"skip,omitempty"` // TODO(caarlos0): rename to Disable to match other pipes
-	Use     string           `yaml:"use,omitempty" jsonschema:"enum=git,enum=github,enum=github-native,enum=gitlab,default=git"`
-	Groups  []ChangeLogGroup `yaml:"groups,omitempty"`
+	Filters       Filters          `yaml:"filters,omitempty"`
+	Sort          string           `yaml:"sort,omitempty"`
+	Skip          bool             `yaml:"skip,omitempty"` // TODO(caarlos0): rename to Disable to match other pipes
+	Use           string           `yaml:"use,omitempty" jsonschema:"enum=git,enum=github,enum=github-native,enum=gitlab,enum=bitbucket,enum=azure-devops,enum=tag,default=git"`
+	Groups        []ChangeLogGroup `yaml:"groups,omitempty"`
+	DownloadStats bool             `yaml:"download_stats,omitempty"`
+	DivideByTag   bool             `yaml:"divide_by_tag,omitempty"`
 }
 
 // ChangeLogGroup holds the grouping criteria for the changelog.
@@ -783,33 +1441,63 @@ type ChangeLogGroup struct {
 // EnvFiles holds paths to files that contains environment variables
 // values like the github token for example.
 type EnvFiles struct {
-	GitHubToken string `yaml:"github_token,omitempty"`
-	GitLabToken string `yaml:"gitlab_token,omitempty"`
-	GiteaToken  string `yaml:"gitea_token,omitempty"`
+	GitHubToken      string `yaml:"github_token,omitempty"`
+	GitLabToken      string `yaml:"gitlab_token,omitempty"`
+	GiteaToken       string `yaml:"gitea_token,omitempty"`
+	BitbucketToken   string `yaml:"bitbucket_token,omitempty"`
+	AzureDevOpsToken string `yaml:"azure_devops_token,omitempty"`
 }
 
 // Before config.
 type Before struct {
-	Hooks []string `yaml:"hooks,omitempty"`
+	Hooks Hooks `yaml:"hooks,omitempty"`
 }
 
 // Blob contains config for GO CDK blob.
 type Blob struct {
-	Bucket     string      `yaml:"bucket,omitempty"`
-	Provider   string      `yaml:"provider,omitempty"`
-	Region     string      `yaml:"region,omitempty"`
-	DisableSSL bool        `yaml:"disableSSL,omitempty"` // nolint:tagliatelle // TODO(caarlos0): rename to disable_ssl
-	Folder     string      `yaml:"folder,omitempty"`
-	KMSKey     string      `yaml:"kmskey,omitempty"`
-	IDs        []string    `yaml:"ids,omitempty"`
-	Endpoint   string      `yaml:"endpoint,omitempty"` // used for minio for example
-	ExtraFiles []ExtraFile `yaml:"extra_files,omitempty"`
+	ID          string          `yaml:"id,omitempty"`
+	Bucket      string          `yaml:"bucket,omitempty"`
+	Provider    string          `yaml:"provider,omitempty"`
+	Region      string          `yaml:"region,omitempty"`
+	DisableSSL  bool            `yaml:"disableSSL,omitempty"` // nolint:tagliatelle // TODO(caarlos0): rename to disable_ssl
+	Folder      string          `yaml:"folder,omitempty"`
+	KMSKey      string          `yaml:"kmskey,omitempty"`
+	IDs         []string        `yaml:"ids,omitempty"`
+	Include     ArtifactFilters `yaml:"include,omitempty"`
+	Exclude     ArtifactFilters `yaml:"exclude,omitempty"`
+	Endpoint    string          `yaml:"endpoint,omitempty"` // used for minio for example
+	ExtraFiles  []ExtraFile     `yaml:"extra_files,omitempty"`
+	Fallback    string          `yaml:"fallback,omitempty"`
+	Credentials BlobCredentials `yaml:"credentials,omitempty"`
+
+	ServerSideEncryption BlobSSE `yaml:"server_side_encryption,omitempty"`
+}
+
+// BlobSSE configures the server side encryption of the files uploaded to a
+// bucket.
+type BlobSSE struct {
+	Type     string `yaml:"type,omitempty" jsonschema:"enum=sse-s3,enum=sse-kms,enum=cmek"`
+	KMSKeyID string `yaml:"kms_key_id,omitempty"`
+	Verify   bool   `yaml:"verify,omitempty"`
+}
+
+// BlobCredentials overrides the credentials from the environment used to
+// access a bucket.
+type BlobCredentials struct {
+	AccessKeyID           string `yaml:"access_key_id,omitempty"`
+	SecretAccessKey       string `yaml:"secret_access_key,omitempty"`
+	SessionToken          string `yaml:"session_token,omitempty"`
+	GoogleCredentialsFile string `yaml:"google_credentials_file,omitempty"`
+	AzureAccount          string `yaml:"azure_account,omitempty"`
+	AzureKey              string `yaml:"azure_key,omitempty"`
 }
 
 // Upload configuration.
 type Upload struct {
 	Name               string            `yaml:"name,omitempty"`
 	IDs                []string          `yaml:"ids,omitempty"`
+	Include            ArtifactFilters   `yaml:"include,omitempty"`
+	Exclude            ArtifactFilters   `yaml:"exclude,omitempty"`
 	Target             string            `yaml:"target,omitempty"`
 	Username           string            `yaml:"username,omitempty"`
 	Mode               string            `yaml:"mode,omitempty"`
@@ -832,51 +1520,83 @@ type Publisher struct {
 	Cmd        string      `yaml:"cmd,omitempty"`
 	Env        []string    `yaml:"env,omitempty"`
 	ExtraFiles []ExtraFile `yaml:"extra_files,omitempty"`
+	Fallback   string      `yaml:"fallback,omitempty"`
 }
 
 // Source configuration.
 type Source struct {
-	NameTemplate   string `yaml:"name_template,omitempty"`
-	Format         string `yaml:"format,omitempty"`
-	Enabled        bool   `yaml:"enabled,omitempty"`
-	PrefixTemplate string `yaml:"prefix_template,omitempty"`
+	NameTemplate   string   `yaml:"name_template,omitempty"`
+	Format         string   `yaml:"format,omitempty"`
+	Enabled        bool     `yaml:"enabled,omitempty"`
+	PrefixTemplate string   `yaml:"prefix_template,omitempty"`
+	Excludes       []string `yaml:"excludes,omitempty"`
+	SkipChecksum   bool     `yaml:"skip_checksum,omitempty"`
+	SkipProvenance bool     `yaml:"skip_provenance,omitempty"`
+	Sign           bool     `yaml:"sign,omitempty"`
+	SBOM           bool     `yaml:"sbom,omitempty"`
 }
 
 // Project includes all project configuration.
 type Project struct {
-	ProjectName     string           `yaml:"project_name,omitempty"`
-	Env             []string         `yaml:"env,omitempty"`
-	Release         Release          `yaml:"release,omitempty"`
-	Milestones      []Milestone      `yaml:"milestones,omitempty"`
-	Brews           []Homebrew       `yaml:"brews,omitempty"`
-	Rigs            []GoFish         `yaml:"rigs,omitempty"`
-	AURs            []AUR            `yaml:"aurs,omitempty"`
-	Krews           []Krew           `yaml:"krews,omitempty"`
-	Scoop           Scoop            `yaml:"scoop,omitempty"`
-	Builds          []Build          `yaml:"builds,omitempty"`
-	Archives        []Archive        `yaml:"archives,omitempty"`
-	NFPMs           []NFPM           `yaml:"nfpms,omitempty"`
-	Snapcrafts      []Snapcraft      `yaml:"snapcrafts,omitempty"`
-	Snapshot        Snapshot         `yaml:"snapshot,omitempty"`
-	Checksum        Checksum         `yaml:"checksum,omitempty"`
-	Dockers         []Docker         `yaml:"dockers,omitempty"`
-	DockerManifests []DockerManifest `yaml:"docker_manifests,omitempty"`
-	Artifactories   []Upload         `yaml:"artifactories,omitempty"`
-	Uploads         []Upload         `yaml:"uploads,omitempty"`
-	Blobs           []Blob           `yaml:"blobs,omitempty"`
-	Publishers      []Publisher      `yaml:"publishers,omitempty"`
-	Changelog       Changelog        `yaml:"changelog,omitempty"`
-	Dist            string           `yaml:"dist,omitempty"`
-	Signs           []Sign           `yaml:"signs,omitempty"`
-	DockerSigns     []Sign           `yaml:"docker_signs,omitempty"`
-	EnvFiles        EnvFiles         `yaml:"env_files,omitempty"`
-	Before          Before           `yaml:"before,omitempty"`
-	Source          Source           `yaml:"source,omitempty"`
-	GoMod           GoMod            `yaml:"gomod,omitempty"`
-	Announce        Announce         `yaml:"announce,omitempty"`
-	SBOMs           []SBOM           `yaml:"sboms,omitempty"`
+	ProjectName        string             `yaml:"project_name,omitempty"`
+	Env                []string           `yaml:"env,omitempty"`
+	Release            Release            `yaml:"release,omitempty"`
+	Milestones         []Milestone        `yaml:"milestones,omitempty"`
+	Brews              []Homebrew         `yaml:"brews,omitempty"`
+	Rigs               []GoFish           `yaml:"rigs,omitempty"`
+	Nix                []Nix              `yaml:"nix,omitempty"`
+	Termux             []Termux           `yaml:"termux,omitempty"`
+	Winget             []Winget           `yaml:"winget,omitempty"`
+	MacPorts           []MacPorts         `yaml:"macports,omitempty"`
+	AURs               []AUR              `yaml:"aurs,omitempty"`
+	APTRepositories    []APTRepository    `yaml:"apt_repositories,omitempty"`
+	YUMRepositories    []YUMRepository    `yaml:"yum_repositories,omitempty"`
+	APKRepositories    []APKRepository    `yaml:"apk_repositories,omitempty"`
+	PacmanRepositories []PacmanRepository `yaml:"pacman_repositories,omitempty"`
+	Krews              []Krew             `yaml:"krews,omitempty"`
+	Scoop              Scoop              `yaml:"scoop,omitempty"`
+	Chocolateys        []Chocolatey       `yaml:"chocolateys,omitempty"`
+	Platforms          []Platform         `yaml:"platforms,omitempty"`
+	PlatformNames      map[string]string  `yaml:"platform_names,omitempty"`
+	Builds             []Build            `yaml:"builds,omitempty"`
+	Archives           []Archive          `yaml:"archives,omitempty"`
+	NFPMs              []NFPM             `yaml:"nfpms,omitempty"`
+	Snapcrafts         []Snapcraft        `yaml:"snapcrafts,omitempty"`
+	AppImages          []AppImage         `yaml:"appimages,omitempty"`
+	MSIs               []MSI              `yaml:"msis,omitempty"`
+	DMGs               []DMG              `yaml:"dmgs,omitempty"`
+	Snapshot           Snapshot           `yaml:"snapshot,omitempty"`
+	Checksum           Checksum           `yaml:"checksum,omitempty"`
+	Dockers            []Docker           `yaml:"dockers,omitempty"`
+	DockerManifests    []DockerManifest   `yaml:"docker_manifests,omitempty"`
+	Registries         []Registry         `yaml:"registries,omitempty"`
+	Kos                []Ko               `yaml:"kos,omitempty"`
+	Artifactories      []Upload           `yaml:"artifactories,omitempty"`
+	Uploads            []Upload           `yaml:"uploads,omitempty"`
+	Blobs              []Blob             `yaml:"blobs,omitempty"`
+	Publishers         []Publisher        `yaml:"publishers,omitempty"`
+	Changelog          Changelog          `yaml:"changelog,omitempty"`
+	Dist               string             `yaml:"dist,omitempty"`
+	Signs              []Sign             `yaml:"signs,omitempty"`
+	DockerSigns        []Sign             `yaml:"docker_signs,omitempty"`
+	DockerScans        []DockerScan       `yaml:"docker_scans,omitempty"`
+	EnvFiles           EnvFiles           `yaml:"env_files,omitempty"`
+	Before             Before             `yaml:"before,omitempty"`
+	Source             Source             `yaml:"source,omitempty"`
+	GoMod              GoMod              `yaml:"gomod,omitempty"`
+	Announce           Announce           `yaml:"announce,omitempty"`
+	SBOMs              []SBOM             `yaml:"sboms,omitempty"`
+	Metrics            Metrics            `yaml:"metrics,omitempty"`
+	Completions        Completions        `yaml:"completions,omitempty"`
+	Manpages           []string           `yaml:"manpages,omitempty"`
 
 	UniversalBinaries []UniversalBinary `yaml:"universal_binaries,omitempty"`
+	Notarize          Notarize          `yaml:"notarize,omitempty"`
+	Authenticode      []Authenticode    `yaml:"authenticode,omitempty"`
+	Provenance        Provenance        `yaml:"provenance,omitempty"`
+	Attestations      []Attestation     `yaml:"attestations,omitempty"`
+	FilesGenerate     []FileGenerate    `yaml:"files_generate,omitempty"`
+	Directories       []Directory       `yaml:"directories,omitempty"`
 
 	// this is a hack ¯\_(ツ)_/¯
 	SingleBuild Build `yaml:"build,omitempty"`
@@ -889,12 +1609,58 @@ type Project struct {
 
 	// should be set if using Gitea
 	GiteaURLs GiteaURLs `yaml:"gitea_urls,omitempty"`
+
+	// should be set if using a proxy in front of Bitbucket
+	BitbucketURLs BitbucketURLs `yaml:"bitbucket_urls,omitempty"`
+
+	// should be set if using a proxy in front of Azure DevOps
+	AzureDevOpsURLs AzureDevOpsURLs `yaml:"azure_devops_urls,omitempty"`
+}
+
+// Completions are the shell completion scripts of the project, added to
+// every archive, nfpm package and homebrew formula.
+type Completions struct {
+	Bash string `yaml:"bash,omitempty"`
+	Zsh  string `yaml:"zsh,omitempty"`
+	Fish string `yaml:"fish,omitempty"`
+}
+
+// Metrics config.
+type Metrics struct {
+	Pushgateway string            `yaml:"pushgateway,omitempty"`
+	Job         string            `yaml:"job,omitempty"`
+	Labels      map[string]string `yaml:"labels,omitempty"`
+}
+
+// Provenance configures the SLSA provenance attestation of the artifacts.
+type Provenance struct {
+	Enabled      bool     `yaml:"enabled,omitempty"`
+	NameTemplate string   `yaml:"name_template,omitempty"`
+	BuilderID    string   `yaml:"builder_id,omitempty"`
+	IDs          []string `yaml:"ids,omitempty"`
+}
+
+// Attestation configures the in-toto attestations of artifacts, with a
+// predicate from a file or the output of a command.
+type Attestation struct {
+	ID            string   `yaml:"id,omitempty"`
+	Artifacts     string   `yaml:"artifacts,omitempty" jsonschema:"enum=archive,enum=binary,enum=package,enum=source,enum=sbom,enum=all,enum=image,enum=manifest,default=archive"`
+	IDs           []string `yaml:"ids,omitempty"`
+	PredicateType string   `yaml:"predicate_type,omitempty"`
+	Predicate     string   `yaml:"predicate,omitempty"`
+	Cmd           string   `yaml:"cmd,omitempty"`
+	Args          []string `yaml:"args,omitempty"`
+	Env           []string `yaml:"env,omitempty"`
+	NameTemplate  string   `yaml:"name_template,omitempty"`
+	Key           string   `yaml:"key,omitempty"`
+	Referrers     bool     `yaml:"referrers,omitempty"`
 }
 
 type GoMod struct {
-	Proxy    bool     `yaml:"proxy,omitempty"`
-	Env      []string `yaml:"env,omitempty"`
-	GoBinary string   `yaml:"gobinary,omitempty"`
+	Proxy        bool     `yaml:"proxy,omitempty"`
+	Env          []string `yaml:"env,omitempty"`
+	GoBinary     string   `yaml:"gobinary,omitempty"`
+	VersionCheck string   `yaml:"version_check,omitempty" jsonschema:"enum=error,enum=warn,enum=skip,default=error"`
 }
 
 type Announce struct {
@@ -909,6 +1675,19 @@ type Announce struct {
 	LinkedIn   LinkedIn   `yaml:"linkedin,omitempty"`
 	Telegram   Telegram   `yaml:"telegram,omitempty"`
 	Webhook    Webhook    `yaml:"webhook,omitempty"`
+
+	GitHubDiscussions GitHubDiscussions `yaml:"github_discussions,omitempty"`
+}
+
+// GitHubDiscussions announces releases as GitHub discussions.
+type GitHubDiscussions struct {
+	Enabled             bool    `yaml:"enabled,omitempty"`
+	Repository          RepoRef `yaml:"repository,omitempty"`
+	Category            string  `yaml:"category,omitempty"`
+	TitleTemplate       string  `yaml:"title_template,omitempty"`
+	MessageTemplate     string  `yaml:"message_template,omitempty"`
+	PinnedDiscussion    int     `yaml:"pinned_discussion,omitempty"`
+	PinnedTitleTemplate string  `yaml:"pinned_title_template,omitempty"`
 }
 
 type Webhook struct {
@@ -53,3 +53,19 @@ func TestBuildHook_complex(t *testing.T) {
 		Env: []string{"TEST=value"},
 	}, actual.Pre[0])
 }
+
+func TestBuildHook_container(t *testing.T) {
+	var actual BuildHookConfig
+
+	err := yaml.UnmarshalStrict([]byte(`post:
+ - cmd: upx ./dist/bin
+   image: ghcr.io/upx/upx
+   use: podman
+`), &actual)
+	require.NoError(t, err)
+	require.Equal(t, Hook{
+		Cmd:   "upx ./dist/bin",
+		Image: "ghcr.io/upx/upx",
+		Use:   "podman",
+	}, actual.Post[0])
+}
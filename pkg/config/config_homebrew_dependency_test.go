@@ -55,6 +55,36 @@ brews:
 		}, prop.Brews[0].Dependencies)
 	})
 
+	t.Run("os", func(t *testing.T) {
+		conf := `
+brews:
+- name: foo
+  dependencies:
+  - foo
+  - name: bar
+    os: mac
+  - name: foobar
+    type: optional
+    os: linux
+`
+		buf := strings.NewReader(conf)
+		prop, err := LoadReader(buf)
+
+		require.NoError(t, err)
+		require.Equal(t, []HomebrewDependency{
+			{
+				Name: "foo",
+			}, {
+				Name: "bar",
+				OS:   "mac",
+			}, {
+				Name: "foobar",
+				Type: "optional",
+				OS:   "linux",
+			},
+		}, prop.Brews[0].Dependencies)
+	})
+
 	t.Run("mixed", func(t *testing.T) {
 		conf := `
 brews:
@@ -29,6 +29,75 @@ type GitInfo struct {
 	Summary     string
 	TagSubject  string
 	TagContents string
+	TagBody     string
+	TagTagger   string
+	TagDate     time.Time
+}
+
+// ReleaseDownloads holds the download count of each asset of a release,
+// keyed by the asset name.
+type ReleaseDownloads map[string]int
+
+// Total returns the sum of the downloads of all assets.
+func (d ReleaseDownloads) Total() int {
+	var total int
+	for _, count := range d {
+		total += count
+	}
+	return total
+}
+
+// PublishFallback records a publish target that failed, and the fallback
+// target used in its place.
+type PublishFallback struct {
+	Kind     string
+	Target   string
+	Fallback string
+	Err      string
+}
+
+// UnchangedFile records a file that was not committed to a repository,
+// because the repository already had the same content.
+type UnchangedFile struct {
+	Repo string
+	Path string
+}
+
+// Deprecation records a deprecated option used by the config.
+type Deprecation struct {
+	// Property is the deprecated option, e.g. docker.use_buildx.
+	Property string
+	// Path is the YAML path of the option in the config, e.g.
+	// dockers[0].use_buildx, if known.
+	Path string
+	// Replacement is the YAML snippet to use instead, if any.
+	Replacement string
+	// Message is the deprecation notice of deprecations without a property,
+	// e.g. the ones of nFPM.
+	Message string
+}
+
+// BaseImage records a base image of a docker image, and the digest it was
+// pinned to.
+type BaseImage struct {
+	Image  string
+	Digest string
+}
+
+// DockerPlatformBuild records how long the build of a platform of a
+// multi-platform docker image took, and how many attempts it needed.
+type DockerPlatformBuild struct {
+	Image    string
+	Platform string
+	Duration time.Duration
+	Attempts int
+}
+
+// LocalRegistry is a temporary registry the docker images and manifests are
+// pushed to, instead of their own registries, to test them locally.
+type LocalRegistry struct {
+	Enabled bool
+	Address string
 }
 
 // Env is the environment variables.
@@ -63,41 +132,52 @@ const (
 	TokenTypeGitLab TokenType = "gitlab"
 	// TokenTypeGitea defines gitea as type of the token.
 	TokenTypeGitea TokenType = "gitea"
+	// TokenTypeBitbucket defines bitbucket as type of the token.
+	TokenTypeBitbucket TokenType = "bitbucket"
+	// TokenTypeAzureDevOps defines azure devops as type of the token.
+	TokenTypeAzureDevOps TokenType = "azure_devops"
 )
 
 // Context carries along some data through the pipes.
 type Context struct {
 	ctx.Context
-	Config             config.Project
-	Env                Env
-	SkipTokenCheck     bool
-	Token              string
-	TokenType          TokenType
-	Git                GitInfo
-	Date               time.Time
-	Artifacts          artifact.Artifacts
-	ReleaseURL         string
-	ReleaseNotes       string
-	ReleaseNotesFile   string
-	ReleaseNotesTmpl   string
-	ReleaseHeaderFile  string
-	ReleaseHeaderTmpl  string
-	ReleaseFooterFile  string
-	ReleaseFooterTmpl  string
-	Version            string
-	ModulePath         string
-	Snapshot           bool
-	SkipPostBuildHooks bool
-	SkipPublish        bool
-	SkipAnnounce       bool
-	SkipSign           bool
-	SkipValidate       bool
-	SkipSBOMCataloging bool
-	RmDist             bool
-	PreRelease         bool
-	Deprecated         bool
-	Parallelism        int
-	Semver             Semver
+	Config               config.Project
+	Env                  Env
+	SkipTokenCheck       bool
+	Token                string
+	TokenType            TokenType
+	Git                  GitInfo
+	Date                 time.Time
+	Artifacts            artifact.Artifacts
+	ReleaseURL           string
+	ReleaseNotes         string
+	ReleaseNotesFile     string
+	ReleaseNotesTmpl     string
+	ReleaseHeaderFile    string
+	ReleaseHeaderTmpl    string
+	ReleaseFooterFile    string
+	ReleaseFooterTmpl    string
+	PreviousDownloads    ReleaseDownloads
+	PublishFallbacks     []PublishFallback
+	UnchangedFiles       []UnchangedFile
+	BaseImages           []BaseImage
+	DockerPlatformBuilds []DockerPlatformBuild
+	LocalRegistry        LocalRegistry
+	Version              string
+	ModulePath           string
+	Snapshot             bool
+	SkipPostBuildHooks   bool
+	SkipPublish          bool
+	SkipAnnounce         bool
+	SkipSign             bool
+	SkipValidate         bool
+	SkipSBOMCataloging   bool
+	RmDist               bool
+	PreRelease           bool
+	Deprecated           bool
+	Deprecations         []Deprecation
+	Parallelism          int
+	Semver               Semver
 }
 
 // Semver represents a semantic version.
@@ -132,6 +212,15 @@ func Wrap(ctx ctx.Context, config config.Project) *Context {
 	}
 }
 
+// Value returns the value of the given key in the wrapped context, or nil if
+// the context was built without one.
+func (c *Context) Value(key interface{}) interface{} {
+	if c.Context == nil {
+		return nil
+	}
+	return c.Context.Value(key)
+}
+
 // ToEnv converts a list of strings to an Env (aka a map[string]string).
 func ToEnv(env []string) Env {
 	r := Env{}
@@ -1,6 +1,7 @@
 package context
 
 import (
+	ctx "context"
 	"os"
 	"testing"
 	"time"
@@ -31,8 +32,20 @@ func TestNewWithTimeout(t *testing.T) {
 	require.EqualError(t, ctx.Err(), `context canceled`)
 }
 
+func TestValue(t *testing.T) {
+	type key struct{}
+	c := Wrap(ctx.WithValue(ctx.Background(), key{}, "foo"), config.Project{})
+	require.Equal(t, "foo", c.Value(key{}))
+	require.Nil(t, (&Context{}).Value(key{}))
+}
+
 func TestToEnv(t *testing.T) {
 	require.Equal(t, Env{"FOO": "BAR"}, ToEnv([]string{"=nope", "FOO=BAR"}))
 	require.Equal(t, Env{"FOO": "BAR"}, ToEnv([]string{"nope", "FOO=BAR"}))
 	require.Equal(t, Env{"FOO": "BAR", "nope": ""}, ToEnv([]string{"nope=", "FOO=BAR"}))
 }
+
+func TestReleaseDownloadsTotal(t *testing.T) {
+	require.Equal(t, 0, ReleaseDownloads(nil).Total())
+	require.Equal(t, 15, ReleaseDownloads{"a": 10, "b": 5}.Total())
+}
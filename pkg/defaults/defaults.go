@@ -4,24 +4,42 @@ package defaults
 
 import (
 	"fmt"
+	"github.com/goreleaser/goreleaser/internal/pipe/apk"
+	"github.com/goreleaser/goreleaser/internal/pipe/appimage"
+	"github.com/goreleaser/goreleaser/internal/pipe/apt"
 
 	"github.com/goreleaser/goreleaser/internal/pipe/archive"
 	"github.com/goreleaser/goreleaser/internal/pipe/artifactory"
+	"github.com/goreleaser/goreleaser/internal/pipe/attestation"
 	"github.com/goreleaser/goreleaser/internal/pipe/aur"
+	"github.com/goreleaser/goreleaser/internal/pipe/authenticode"
 	"github.com/goreleaser/goreleaser/internal/pipe/blob"
 	"github.com/goreleaser/goreleaser/internal/pipe/brew"
 	"github.com/goreleaser/goreleaser/internal/pipe/build"
 	"github.com/goreleaser/goreleaser/internal/pipe/checksums"
+	"github.com/goreleaser/goreleaser/internal/pipe/chocolatey"
+	"github.com/goreleaser/goreleaser/internal/pipe/directories"
 	"github.com/goreleaser/goreleaser/internal/pipe/discord"
+	"github.com/goreleaser/goreleaser/internal/pipe/discussions"
+	"github.com/goreleaser/goreleaser/internal/pipe/dmg"
 	"github.com/goreleaser/goreleaser/internal/pipe/docker"
+	"github.com/goreleaser/goreleaser/internal/pipe/dockerscan"
+	"github.com/goreleaser/goreleaser/internal/pipe/filesgenerate"
 	"github.com/goreleaser/goreleaser/internal/pipe/gofish"
 	"github.com/goreleaser/goreleaser/internal/pipe/gomod"
+	"github.com/goreleaser/goreleaser/internal/pipe/ko"
 	"github.com/goreleaser/goreleaser/internal/pipe/krew"
 	"github.com/goreleaser/goreleaser/internal/pipe/linkedin"
+	"github.com/goreleaser/goreleaser/internal/pipe/macports"
 	"github.com/goreleaser/goreleaser/internal/pipe/mattermost"
 	"github.com/goreleaser/goreleaser/internal/pipe/milestone"
+	"github.com/goreleaser/goreleaser/internal/pipe/msi"
 	"github.com/goreleaser/goreleaser/internal/pipe/nfpm"
+	"github.com/goreleaser/goreleaser/internal/pipe/nix"
+	"github.com/goreleaser/goreleaser/internal/pipe/notary"
+	"github.com/goreleaser/goreleaser/internal/pipe/pacman"
 	"github.com/goreleaser/goreleaser/internal/pipe/project"
+	"github.com/goreleaser/goreleaser/internal/pipe/provenance"
 	"github.com/goreleaser/goreleaser/internal/pipe/reddit"
 	"github.com/goreleaser/goreleaser/internal/pipe/release"
 	"github.com/goreleaser/goreleaser/internal/pipe/sbom"
@@ -34,9 +52,12 @@ import (
 	"github.com/goreleaser/goreleaser/internal/pipe/sourcearchive"
 	"github.com/goreleaser/goreleaser/internal/pipe/teams"
 	"github.com/goreleaser/goreleaser/internal/pipe/telegram"
+	"github.com/goreleaser/goreleaser/internal/pipe/termux"
 	"github.com/goreleaser/goreleaser/internal/pipe/twitter"
 	"github.com/goreleaser/goreleaser/internal/pipe/universalbinary"
 	"github.com/goreleaser/goreleaser/internal/pipe/webhook"
+	"github.com/goreleaser/goreleaser/internal/pipe/winget"
+	"github.com/goreleaser/goreleaser/internal/pipe/yum"
 	"github.com/goreleaser/goreleaser/pkg/context"
 )
 
@@ -58,24 +79,45 @@ var Defaulters = []Defaulter{
 	gomod.Pipe{},
 	build.Pipe{},
 	universalbinary.Pipe{},
+	notary.Pipe{},
+	authenticode.Pipe{},
+	filesgenerate.Pipe{},
+	directories.Pipe{},
 	sourcearchive.Pipe{},
 	archive.Pipe{},
 	nfpm.Pipe{},
 	snapcraft.Pipe{},
+	appimage.Pipe{},
+	msi.Pipe{},
+	dmg.Pipe{},
 	checksums.Pipe{},
+	provenance.Pipe{},
+	attestation.Pipe{},
 	sign.Pipe{},
 	sign.DockerPipe{},
 	sbom.Pipe{},
 	docker.Pipe{},
 	docker.ManifestPipe{},
+	dockerscan.Pipe{},
+	ko.Pipe{},
 	artifactory.Pipe{},
 	blob.Pipe{},
 	aur.Pipe{},
+	apt.Pipe{},
+	yum.Pipe{},
+	apk.Pipe{},
+	pacman.Pipe{},
 	brew.Pipe{},
 	krew.Pipe{},
 	gofish.Pipe{},
+	nix.Pipe{},
+	termux.Pipe{},
+	winget.Pipe{},
+	macports.Pipe{},
 	scoop.Pipe{},
+	chocolatey.Pipe{},
 	discord.Pipe{},
+	discussions.Pipe{},
 	reddit.Pipe{},
 	slack.Pipe{},
 	teams.Pipe{},
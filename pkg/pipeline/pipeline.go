@@ -0,0 +1,120 @@
+// Package pipeline allows programs to embed GoReleaser, running the same
+// pipelines as goreleaser release and goreleaser build do, without shelling
+// out to the CLI.
+//
+// The config can be loaded with the config package, or built in code:
+//
+//	cfg, err := config.Load(".goreleaser.yaml")
+//	if err != nil {
+//		return err
+//	}
+//	ctx, err := pipeline.Run(context.Background(), cfg, pipeline.ReleasePipes(), pipeline.Options{
+//		Snapshot: true,
+//	})
+//	if err != nil {
+//		return err
+//	}
+//	for _, a := range ctx.Artifacts.List() {
+//		fmt.Println(a.Name, a.Path)
+//	}
+package pipeline
+
+import (
+	stdctx "context"
+	"runtime"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/metrics"
+	"github.com/goreleaser/goreleaser/internal/pipeline"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+)
+
+// Piper is a step of a pipeline.
+type Piper = pipeline.Piper
+
+// ReleasePipes returns the pipeline goreleaser release runs.
+func ReleasePipes() []Piper {
+	return append([]Piper(nil), pipeline.Pipeline...)
+}
+
+// BuildPipes returns the pipeline goreleaser build runs.
+func BuildPipes() []Piper {
+	return append([]Piper(nil), pipeline.BuildCmdPipeline...)
+}
+
+// Skips are the parts of a pipeline to skip, like the CLI --skip-* flags do.
+type Skips struct {
+	Publish        bool
+	Announce       bool
+	Sign           bool
+	Validate       bool
+	SBOMCataloging bool
+	PostBuildHooks bool
+	TokenCheck     bool
+}
+
+// Options customize a run of a pipeline.
+type Options struct {
+	// Snapshot releases a snapshot, which implies skipping the publishing,
+	// announcing and validation.
+	Snapshot bool
+
+	// RmDist removes the dist folder before running.
+	RmDist bool
+
+	// Parallelism is the maximum number of tasks to run concurrently.
+	// Defaults to the number of CPUs.
+	Parallelism int
+
+	// Skip are the parts of the pipeline to skip.
+	Skip Skips
+
+	// FailOnDeprecated fails the run after the first pipe that finds
+	// deprecated options in the config, like the CLI --fail-on-deprecated
+	// flag does.
+	FailOnDeprecated bool
+
+	// LogHandler handles the logs of the run.
+	// Defaults to the handler of the logger of the given context, as set with
+	// log.NewContext, or of the global logger.
+	LogHandler log.Handler
+}
+
+// Run runs the given pipes for the given config, the same way the CLI does,
+// and returns the context they ran with, which has the resulting artifacts,
+// even if a pipe fails.
+// The run stops when the given context is done. Metrics are pushed at the
+// end of the run if the config has a Pushgateway.
+func Run(parent stdctx.Context, cfg config.Project, pipes []Piper, opts Options) (*context.Context, error) {
+	if opts.LogHandler != nil {
+		logger := &log.Logger{Handler: opts.LogHandler, Level: log.InfoLevel}
+		if l, ok := log.FromContext(parent).(*log.Logger); ok {
+			logger.Level = l.Level
+		}
+		parent = log.NewContext(parent, logger)
+	}
+	ctx := context.Wrap(parent, cfg)
+	setup(ctx, opts)
+
+	recorder := metrics.New()
+	err := pipeline.Run(ctx, pipes, recorder, opts.FailOnDeprecated)
+	recorder.Push(ctx, err)
+	return ctx, err
+}
+
+func setup(ctx *context.Context, opts Options) {
+	ctx.Parallelism = runtime.NumCPU()
+	if opts.Parallelism > 0 {
+		ctx.Parallelism = opts.Parallelism
+	}
+	ctx.Snapshot = opts.Snapshot
+	ctx.RmDist = opts.RmDist
+	ctx.SkipPublish = ctx.Snapshot || opts.Skip.Publish
+	ctx.SkipAnnounce = ctx.SkipPublish || opts.Skip.Announce
+	ctx.SkipValidate = ctx.Snapshot || opts.Skip.Validate
+	ctx.SkipSign = opts.Skip.Sign
+	ctx.SkipSBOMCataloging = opts.Skip.SBOMCataloging
+	ctx.SkipPostBuildHooks = opts.Skip.PostBuildHooks
+	ctx.SkipTokenCheck = opts.Skip.TokenCheck
+}
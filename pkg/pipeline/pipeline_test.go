@@ -0,0 +1,188 @@
+package pipeline
+
+import (
+	stdctx "context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/apex/log"
+	"github.com/apex/log/handlers/memory"
+	"github.com/goreleaser/goreleaser/internal/deprecate"
+	"github.com/goreleaser/goreleaser/internal/pipe"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+type fakePipe struct {
+	name       string
+	err        error
+	skip       bool
+	deprecated bool
+	ran        *[]string
+}
+
+func (p fakePipe) String() string                 { return p.name }
+func (p fakePipe) Skip(ctx *context.Context) bool { return p.skip }
+func (p fakePipe) Run(ctx *context.Context) error {
+	*p.ran = append(*p.ran, p.name)
+	log.FromContext(ctx).Info("running " + p.name)
+	if p.deprecated {
+		deprecate.Notice(ctx, "foo")
+	}
+	return p.err
+}
+
+func TestPipelines(t *testing.T) {
+	require.NotEmpty(t, ReleasePipes())
+	require.NotEmpty(t, BuildPipes())
+	require.Greater(t, len(ReleasePipes()), len(BuildPipes()))
+
+	pipes := ReleasePipes()
+	pipes[0] = fakePipe{name: "replaced"}
+	require.NotEqual(t, pipes[0], ReleasePipes()[0])
+}
+
+func TestRun(t *testing.T) {
+	var ran []string
+	handler := memory.New()
+	ctx, err := Run(stdctx.Background(), config.Project{ProjectName: "foo"}, []Piper{
+		fakePipe{name: "first", ran: &ran},
+		fakePipe{name: "skipped", skip: true, ran: &ran},
+		fakePipe{name: "skip error", err: pipe.Skip("nope"), ran: &ran},
+		fakePipe{name: "last", ran: &ran},
+	}, Options{
+		Snapshot:    true,
+		Parallelism: 2,
+		Skip:        Skips{Sign: true},
+		LogHandler:  handler,
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"first", "skip error", "last"}, ran)
+	require.Equal(t, "foo", ctx.Config.ProjectName)
+	require.Equal(t, 2, ctx.Parallelism)
+	require.True(t, ctx.Snapshot)
+	require.True(t, ctx.SkipPublish)
+	require.True(t, ctx.SkipAnnounce)
+	require.True(t, ctx.SkipValidate)
+	require.True(t, ctx.SkipSign)
+	require.False(t, ctx.SkipSBOMCataloging)
+
+	var messages []string
+	for _, e := range handler.Entries {
+		messages = append(messages, e.Message)
+	}
+	require.Contains(t, messages, "running first")
+	require.Contains(t, messages, "running last")
+	_, global := log.Log.(*log.Logger).Handler.(*memory.Handler)
+	require.False(t, global)
+}
+
+func TestRunConcurrentLogHandlers(t *testing.T) {
+	var wg sync.WaitGroup
+	handlers := []*memory.Handler{memory.New(), memory.New()}
+	for i, handler := range handlers {
+		i, handler := i, handler
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var ran []string
+			_, err := Run(stdctx.Background(), config.Project{}, []Piper{
+				fakePipe{name: fmt.Sprintf("pipe %d", i), ran: &ran},
+			}, Options{LogHandler: handler})
+			require.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	for i, handler := range handlers {
+		var messages []string
+		for _, e := range handler.Entries {
+			messages = append(messages, e.Message)
+		}
+		require.Contains(t, messages, fmt.Sprintf("running pipe %d", i))
+		require.NotContains(t, messages, fmt.Sprintf("running pipe %d", 1-i))
+	}
+}
+
+func TestRunError(t *testing.T) {
+	var ran []string
+	ctx, err := Run(stdctx.Background(), config.Project{}, []Piper{
+		fakePipe{name: "first", err: errors.New("failed"), ran: &ran},
+		fakePipe{name: "last", ran: &ran},
+	}, Options{})
+	require.EqualError(t, err, "failed")
+	require.NotNil(t, ctx)
+	require.Equal(t, []string{"first"}, ran)
+}
+
+func TestRunCanceled(t *testing.T) {
+	var ran []string
+	parent, cancel := stdctx.WithCancel(stdctx.Background())
+	cancel()
+	_, err := Run(parent, config.Project{}, []Piper{
+		fakePipe{name: "first", ran: &ran},
+	}, Options{})
+	require.ErrorIs(t, err, stdctx.Canceled)
+	require.Empty(t, ran)
+}
+
+func TestRunFailOnDeprecated(t *testing.T) {
+	var ran []string
+	pipes := []Piper{
+		fakePipe{name: "first", deprecated: true, ran: &ran},
+		fakePipe{name: "last", ran: &ran},
+	}
+	_, err := Run(stdctx.Background(), config.Project{}, pipes, Options{})
+	require.NoError(t, err)
+	require.Equal(t, []string{"first", "last"}, ran)
+
+	ran = nil
+	_, err = Run(stdctx.Background(), config.Project{}, pipes, Options{FailOnDeprecated: true})
+	require.ErrorIs(t, err, deprecate.ErrDeprecated)
+	require.Equal(t, []string{"first"}, ran)
+}
+
+func TestRunPushesMetrics(t *testing.T) {
+	var body string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		bts, _ := io.ReadAll(r.Body)
+		body = string(bts)
+	}))
+	t.Cleanup(srv.Close)
+
+	var ran []string
+	_, err := Run(stdctx.Background(), config.Project{
+		Metrics: config.Metrics{Pushgateway: srv.URL},
+	}, []Piper{
+		fakePipe{name: "first", err: errors.New("failed"), ran: &ran},
+	}, Options{})
+	require.EqualError(t, err, "failed")
+	require.Contains(t, body, "goreleaser_release_success 0\n")
+	require.Contains(t, body, `goreleaser_pipe_failed{pipe="first"} 1`)
+}
+
+func TestSetup(t *testing.T) {
+	ctx := context.New(config.Project{})
+	setup(ctx, Options{
+		Skip: Skips{
+			Publish:        true,
+			SBOMCataloging: true,
+			PostBuildHooks: true,
+			TokenCheck:     true,
+		},
+	})
+	require.False(t, ctx.Snapshot)
+	require.True(t, ctx.SkipPublish)
+	require.True(t, ctx.SkipAnnounce)
+	require.False(t, ctx.SkipValidate)
+	require.True(t, ctx.SkipSBOMCataloging)
+	require.True(t, ctx.SkipPostBuildHooks)
+	require.True(t, ctx.SkipTokenCheck)
+	require.Positive(t, ctx.Parallelism)
+}
@@ -0,0 +1,104 @@
+package testlib
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/pkg/config"
+)
+
+// BlobStore is a fake S3 compatible blob store, implementing enough of the
+// S3 API to upload and download objects, which keeps everything in memory.
+type BlobStore struct {
+	// URL is the endpoint of the blob store.
+	URL string
+
+	lock    sync.Mutex
+	objects map[string][]byte
+}
+
+// NewBlobStore starts a new BlobStore, which is closed when the test
+// finishes.
+func NewBlobStore(tb testing.TB) *BlobStore {
+	tb.Helper()
+	s := &BlobStore{objects: map[string][]byte{}}
+	srv := httptest.NewServer(http.HandlerFunc(s.serve))
+	tb.Cleanup(srv.Close)
+	s.URL = srv.URL
+	return s
+}
+
+// Configure configures the blob to upload to the BlobStore.
+// The bucket still needs to be set.
+func (s *BlobStore) Configure(blob *config.Blob) {
+	blob.Provider = "s3"
+	blob.Endpoint = s.URL
+	blob.Region = "us-east-1"
+	blob.DisableSSL = true
+	blob.Credentials = config.BlobCredentials{
+		AccessKeyID:     "testlib",
+		SecretAccessKey: "testlib",
+	}
+}
+
+// Object returns the contents of the given object.
+func (s *BlobStore) Object(bucket, key string) ([]byte, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	bts, ok := s.objects[bucket+"/"+key]
+	return bts, ok
+}
+
+// Keys returns the keys of the objects of the given bucket.
+func (s *BlobStore) Keys(bucket string) []string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	var keys []string
+	for k := range s.objects {
+		if key := strings.TrimPrefix(k, bucket+"/"); key != k {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (s *BlobStore) serve(w http.ResponseWriter, r *http.Request) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	// path style: /bucket/key
+	path := strings.TrimPrefix(r.URL.Path, "/")
+	switch r.Method {
+	case http.MethodPut:
+		bts, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.objects[path] = bts
+		w.Header().Set("ETag", `"`+sha(bts)+`"`)
+		w.WriteHeader(http.StatusOK)
+	case http.MethodGet, http.MethodHead:
+		bts, ok := s.objects[path]
+		if !ok {
+			w.Header().Set("Content-Type", "application/xml")
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = io.WriteString(w, `<?xml version="1.0" encoding="UTF-8"?><Error><Code>NoSuchKey</Code><Message>The specified key does not exist.</Message></Error>`)
+			return
+		}
+		if r.Method == http.MethodGet {
+			_, _ = w.Write(bts)
+		}
+	case http.MethodDelete:
+		delete(s.objects, path)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
@@ -0,0 +1,36 @@
+package testlib
+
+import (
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/pipe/blob"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlobStore(t *testing.T) {
+	store := NewBlobStore(t)
+	b := config.Blob{Bucket: "releases"}
+	store.Configure(&b)
+	ctx := NewContext(t, config.Project{
+		ProjectName: "foo",
+		Blobs:       []config.Blob{b},
+	})
+	AddArtifact(t, ctx, Archive, "foo_1.0.0_linux_amd64.tar.gz", []byte("archive"))
+	AddArtifact(t, ctx, Checksum, "checksums.txt", []byte("sums"))
+
+	require.NoError(t, blob.Pipe{}.Default(ctx))
+	require.NoError(t, blob.Pipe{}.Publish(ctx))
+
+	require.Equal(t, []string{
+		"foo/v1.0.0/checksums.txt",
+		"foo/v1.0.0/foo_1.0.0_linux_amd64.tar.gz",
+	}, store.Keys("releases"))
+	bts, ok := store.Object("releases", "foo/v1.0.0/checksums.txt")
+	require.True(t, ok)
+	require.Equal(t, "sums", string(bts))
+
+	_, ok = store.Object("releases", "nope")
+	require.False(t, ok)
+	require.Empty(t, store.Keys("other"))
+}
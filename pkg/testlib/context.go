@@ -0,0 +1,83 @@
+// Package testlib contains helpers to integration test code built on top of
+// GoReleaser, like external publishers, against fake forges, registries and
+// blob stores, without hitting real services.
+package testlib
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+)
+
+// Artifact is a file produced by the release, as added with AddArtifact.
+type Artifact = artifact.Artifact
+
+// ArtifactType is the type of an Artifact.
+type ArtifactType = artifact.Type
+
+// Types of the artifacts that can be added with AddArtifact.
+const (
+	Archive       ArtifactType = artifact.UploadableArchive
+	Binary        ArtifactType = artifact.UploadableBinary
+	File          ArtifactType = artifact.UploadableFile
+	LinuxPackage  ArtifactType = artifact.LinuxPackage
+	Checksum      ArtifactType = artifact.Checksum
+	Signature     ArtifactType = artifact.Signature
+	SourceArchive ArtifactType = artifact.UploadableSourceArchive
+)
+
+// NewContext returns a context to release the v1.0.0 tag of the given
+// project, with a temporary dist folder and the default settings of a
+// GitHub release.
+func NewContext(tb testing.TB, cfg config.Project) *context.Context {
+	tb.Helper()
+	if cfg.ProjectName == "" {
+		cfg.ProjectName = "test"
+	}
+	if cfg.Dist == "" {
+		cfg.Dist = tb.TempDir()
+	}
+	if cfg.Release.NameTemplate == "" {
+		cfg.Release.NameTemplate = "{{ .Tag }}"
+	}
+	ctx := context.New(cfg)
+	ctx.Git = context.GitInfo{
+		CurrentTag:  "v1.0.0",
+		Commit:      "a1b2c3d4e5f6a1b2c3d4e5f6a1b2c3d4e5f6a1b2",
+		ShortCommit: "a1b2c3d",
+		FullCommit:  "a1b2c3d4e5f6a1b2c3d4e5f6a1b2c3d4e5f6a1b2",
+		URL:         "https://github.com/goreleaser/test.git",
+	}
+	ctx.Version = "1.0.0"
+	ctx.Semver = context.Semver{Major: 1}
+	ctx.TokenType = context.TokenTypeGitHub
+	ctx.Token = "testlib"
+	return ctx
+}
+
+// AddArtifact writes the given content to a file with the given name in the
+// dist folder, and adds it to the context as an artifact of the given type.
+func AddArtifact(tb testing.TB, ctx *context.Context, typ ArtifactType, name string, content []byte) *Artifact {
+	tb.Helper()
+	path := filepath.Join(ctx.Config.Dist, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		tb.Fatal(err)
+	}
+	if err := os.WriteFile(path, content, 0o644); err != nil {
+		tb.Fatal(err)
+	}
+	a := &Artifact{
+		Name: name,
+		Path: path,
+		Type: typ,
+		Extra: map[string]interface{}{
+			artifact.ExtraID: "default",
+		},
+	}
+	ctx.Artifacts.Add(a)
+	return a
+}
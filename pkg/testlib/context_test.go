@@ -0,0 +1,25 @@
+package testlib
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewContext(t *testing.T) {
+	ctx := NewContext(t, config.Project{})
+	require.Equal(t, "test", ctx.Config.ProjectName)
+	require.DirExists(t, ctx.Config.Dist)
+	require.Equal(t, "v1.0.0", ctx.Git.CurrentTag)
+	require.Equal(t, "1.0.0", ctx.Version)
+}
+
+func TestAddArtifact(t *testing.T) {
+	ctx := NewContext(t, config.Project{})
+	a := AddArtifact(t, ctx, Binary, "bin/foo", []byte("binary"))
+	require.Equal(t, filepath.Join(ctx.Config.Dist, "bin", "foo"), a.Path)
+	require.FileExists(t, a.Path)
+	require.Equal(t, []*Artifact{a}, ctx.Artifacts.List())
+}
@@ -0,0 +1,339 @@
+package testlib
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/pkg/config"
+)
+
+// Release is a release created in a Forge.
+type Release struct {
+	ID         int64
+	Tag        string
+	Name       string
+	Body       string
+	Draft      bool
+	Prerelease bool
+	// Assets are the contents of the uploaded assets, by name.
+	Assets map[string][]byte
+}
+
+// Forge is a fake GitHub API, which keeps the releases, release assets and
+// repository files created by GoReleaser in memory.
+//
+// It also serves the release assets as GitHub does, so the download URLs of
+// the releases work.
+type Forge struct {
+	// URL is the base URL of the fake API.
+	URL string
+
+	lock     sync.Mutex
+	nextID   int64
+	releases map[string]*Release
+	assets   map[int64]assetRef
+	files    map[string][]byte
+}
+
+type assetRef struct {
+	release *Release
+	name    string
+}
+
+// NewForge starts a new Forge, which is closed when the test finishes.
+func NewForge(tb testing.TB) *Forge {
+	tb.Helper()
+	f := &Forge{
+		releases: map[string]*Release{},
+		assets:   map[int64]assetRef{},
+		files:    map[string][]byte{},
+	}
+	srv := httptest.NewServer(http.HandlerFunc(f.serve))
+	tb.Cleanup(srv.Close)
+	f.URL = srv.URL
+	return f
+}
+
+// Configure configures the project to release to the Forge.
+// The release repository still needs to be set, as well as a GitHub token
+// in the context.
+func (f *Forge) Configure(cfg *config.Project) {
+	cfg.GitHubURLs = config.GitHubURLs{
+		API:      f.URL + "/api/",
+		Upload:   f.URL + "/uploads/",
+		Download: f.URL,
+	}
+}
+
+// Release returns the release of the given repository and tag.
+func (f *Forge) Release(owner, name, tag string) (Release, bool) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	r, ok := f.releases[owner+"/"+name+"@"+tag]
+	if !ok {
+		return Release{}, false
+	}
+	result := *r
+	result.Assets = map[string][]byte{}
+	for k, v := range r.Assets {
+		result.Assets[k] = v
+	}
+	return result, true
+}
+
+// File returns the contents of the given file of a repository, as created
+// by the brew, scoop and other publishers.
+func (f *Forge) File(owner, name, path string) ([]byte, bool) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	bts, ok := f.files[owner+"/"+name+"/"+path]
+	return bts, ok
+}
+
+type releaseJSON struct {
+	ID         int64       `json:"id"`
+	TagName    string      `json:"tag_name"`
+	Name       string      `json:"name"`
+	Body       string      `json:"body"`
+	Draft      bool        `json:"draft"`
+	Prerelease bool        `json:"prerelease"`
+	HTMLURL    string      `json:"html_url"`
+	Assets     []assetJSON `json:"assets"`
+}
+
+type assetJSON struct {
+	ID            int64  `json:"id"`
+	Name          string `json:"name"`
+	Size          int    `json:"size"`
+	Digest        string `json:"digest"`
+	DownloadCount int    `json:"download_count"`
+}
+
+type contentJSON struct {
+	Type     string `json:"type"`
+	Path     string `json:"path"`
+	SHA      string `json:"sha"`
+	Encoding string `json:"encoding,omitempty"`
+	Content  string `json:"content,omitempty"`
+}
+
+type fileOptionsJSON struct {
+	Message string `json:"message"`
+	Content []byte `json:"content"`
+	SHA     string `json:"sha"`
+	Branch  string `json:"branch"`
+}
+
+func (f *Forge) serve(w http.ResponseWriter, r *http.Request) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	switch {
+	case len(parts) >= 4 && parts[0] == "api" && parts[1] == "repos":
+		f.serveAPI(w, r, parts[2]+"/"+parts[3], parts[4:])
+	case len(parts) == 7 && parts[0] == "uploads" && parts[1] == "repos" && parts[4] == "releases" && parts[6] == "assets" && r.Method == http.MethodPost:
+		f.upload(w, r, parts[2]+"/"+parts[3], parts[5])
+	case len(parts) >= 6 && parts[2] == "releases" && parts[3] == "download" && r.Method == http.MethodGet:
+		// tags can have slashes.
+		f.download(w, parts[0]+"/"+parts[1], strings.Join(parts[4:len(parts)-1], "/"), parts[len(parts)-1])
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (f *Forge) serveAPI(w http.ResponseWriter, r *http.Request, repo string, parts []string) {
+	switch {
+	case len(parts) == 0 && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, map[string]string{"default_branch": "main"})
+	case len(parts) >= 3 && parts[0] == "releases" && parts[1] == "tags" && r.Method == http.MethodGet:
+		release, ok := f.releases[repo+"@"+strings.Join(parts[2:], "/")]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		writeJSON(w, http.StatusOK, f.releaseJSON(repo, release))
+	case len(parts) == 1 && parts[0] == "releases" && r.Method == http.MethodPost:
+		var body releaseJSON
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		f.nextID++
+		release := &Release{ID: f.nextID, Assets: map[string][]byte{}}
+		updateRelease(release, body)
+		f.releases[repo+"@"+release.Tag] = release
+		writeJSON(w, http.StatusCreated, f.releaseJSON(repo, release))
+	case len(parts) == 2 && parts[0] == "releases" && r.Method == http.MethodPatch:
+		release := f.releaseByID(repo, parts[1])
+		if release == nil {
+			http.NotFound(w, r)
+			return
+		}
+		var body releaseJSON
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		delete(f.releases, repo+"@"+release.Tag)
+		updateRelease(release, body)
+		f.releases[repo+"@"+release.Tag] = release
+		writeJSON(w, http.StatusOK, f.releaseJSON(repo, release))
+	case len(parts) == 3 && parts[0] == "releases" && parts[2] == "assets" && r.Method == http.MethodGet:
+		release := f.releaseByID(repo, parts[1])
+		if release == nil {
+			http.NotFound(w, r)
+			return
+		}
+		writeJSON(w, http.StatusOK, f.releaseJSON(repo, release).Assets)
+	case len(parts) == 3 && parts[0] == "releases" && parts[1] == "assets" && r.Method == http.MethodDelete:
+		id, _ := strconv.ParseInt(parts[2], 10, 64)
+		ref, ok := f.assets[id]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		delete(ref.release.Assets, ref.name)
+		delete(f.assets, id)
+		w.WriteHeader(http.StatusNoContent)
+	case len(parts) > 1 && parts[0] == "contents" && r.Method == http.MethodGet:
+		path := strings.Join(parts[1:], "/")
+		bts, ok := f.files[repo+"/"+path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		writeJSON(w, http.StatusOK, contentJSON{
+			Type:     "file",
+			Path:     path,
+			SHA:      sha(bts),
+			Encoding: "base64",
+			Content:  base64.StdEncoding.EncodeToString(bts),
+		})
+	case len(parts) > 1 && parts[0] == "contents" && r.Method == http.MethodPut:
+		var body fileOptionsJSON
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		path := strings.Join(parts[1:], "/")
+		f.files[repo+"/"+path] = body.Content
+		writeJSON(w, http.StatusCreated, map[string]contentJSON{
+			"content": {Type: "file", Path: path, SHA: sha(body.Content)},
+		})
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (f *Forge) upload(w http.ResponseWriter, r *http.Request, repo, id string) {
+	release := f.releaseByID(repo, id)
+	if release == nil {
+		http.NotFound(w, r)
+		return
+	}
+	name := r.URL.Query().Get("name")
+	if _, ok := release.Assets[name]; ok {
+		writeJSON(w, http.StatusUnprocessableEntity, map[string]interface{}{
+			"message": "Validation Failed",
+			"errors":  []map[string]string{{"resource": "ReleaseAsset", "code": "already_exists", "field": "name"}},
+		})
+		return
+	}
+	bts, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	f.nextID++
+	release.Assets[name] = bts
+	f.assets[f.nextID] = assetRef{release: release, name: name}
+	writeJSON(w, http.StatusCreated, assetJSON{
+		ID:     f.nextID,
+		Name:   name,
+		Size:   len(bts),
+		Digest: "sha256:" + sha(bts),
+	})
+}
+
+func (f *Forge) download(w http.ResponseWriter, repo, tag, name string) {
+	release, ok := f.releases[repo+"@"+tag]
+	if !ok {
+		http.Error(w, "Not Found", http.StatusNotFound)
+		return
+	}
+	bts, ok := release.Assets[name]
+	if !ok {
+		http.Error(w, "Not Found", http.StatusNotFound)
+		return
+	}
+	_, _ = w.Write(bts)
+}
+
+func (f *Forge) releaseByID(repo, id string) *Release {
+	for key, release := range f.releases {
+		if strings.HasPrefix(key, repo+"@") && strconv.FormatInt(release.ID, 10) == id {
+			return release
+		}
+	}
+	return nil
+}
+
+func (f *Forge) releaseJSON(repo string, release *Release) releaseJSON {
+	result := releaseJSON{
+		ID:         release.ID,
+		TagName:    release.Tag,
+		Name:       release.Name,
+		Body:       release.Body,
+		Draft:      release.Draft,
+		Prerelease: release.Prerelease,
+		HTMLURL:    fmt.Sprintf("%s/%s/releases/tag/%s", f.URL, repo, release.Tag),
+		Assets:     []assetJSON{},
+	}
+	for id, ref := range f.assets {
+		if ref.release != release {
+			continue
+		}
+		bts := release.Assets[ref.name]
+		result.Assets = append(result.Assets, assetJSON{
+			ID:     id,
+			Name:   ref.name,
+			Size:   len(bts),
+			Digest: "sha256:" + sha(bts),
+		})
+	}
+	sort.Slice(result.Assets, func(i, j int) bool {
+		return result.Assets[i].ID < result.Assets[j].ID
+	})
+	return result
+}
+
+func updateRelease(release *Release, body releaseJSON) {
+	release.Tag = body.TagName
+	release.Name = body.Name
+	release.Body = body.Body
+	release.Draft = body.Draft
+	release.Prerelease = body.Prerelease
+}
+
+func sha(bts []byte) string {
+	sum := sha256.Sum256(bts)
+	return hex.EncodeToString(sum[:])
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,123 @@
+package testlib
+
+import (
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/client"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestForge(t *testing.T) {
+	forge := NewForge(t)
+	cfg := config.Project{
+		Release: config.Release{
+			GitHub: config.Repo{Owner: "foo", Name: "bar"},
+		},
+	}
+	forge.Configure(&cfg)
+	ctx := NewContext(t, cfg)
+
+	cli, err := client.New(ctx)
+	require.NoError(t, err)
+	id, err := cli.CreateRelease(ctx, "the changelog")
+	require.NoError(t, err)
+
+	a := AddArtifact(t, ctx, Archive, "bar_1.0.0_linux_amd64.tar.gz", []byte("archive"))
+	f, err := os.Open(a.Path)
+	require.NoError(t, err)
+	defer f.Close()
+	require.NoError(t, cli.Upload(ctx, id, a, f))
+
+	release, ok := forge.Release("foo", "bar", "v1.0.0")
+	require.True(t, ok)
+	require.Equal(t, "v1.0.0", release.Name)
+	require.Equal(t, "the changelog", release.Body)
+	require.Equal(t, map[string][]byte{"bar_1.0.0_linux_amd64.tar.gz": []byte("archive")}, release.Assets)
+
+	// releasing again updates the release.
+	ctx.Config.Release.ReleaseNotesMode = config.ReleaseNotesModeReplace
+	id2, err := cli.CreateRelease(ctx, "the new changelog")
+	require.NoError(t, err)
+	require.Equal(t, id, id2)
+	release, ok = forge.Release("foo", "bar", "v1.0.0")
+	require.True(t, ok)
+	require.Equal(t, "the new changelog", release.Body)
+
+	// the assets can be downloaded.
+	url, err := cli.ReleaseURLTemplate(ctx)
+	require.NoError(t, err)
+	url, err = tmpl.New(ctx).WithArtifact(a, nil).Apply(url)
+	require.NoError(t, err)
+	resp, err := http.Get(url)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	bts, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Equal(t, "archive", string(bts))
+
+	_, ok = forge.Release("foo", "bar", "v2.0.0")
+	require.False(t, ok)
+}
+
+func TestForgeFiles(t *testing.T) {
+	forge := NewForge(t)
+	var cfg config.Project
+	forge.Configure(&cfg)
+	ctx := NewContext(t, cfg)
+
+	cli, err := client.New(ctx)
+	require.NoError(t, err)
+	repo := client.Repo{Owner: "foo", Name: "homebrew-tap"}
+	author := config.CommitAuthor{Name: "bot", Email: "bot@example.com"}
+
+	require.NoError(t, cli.CreateFile(ctx, author, repo, []byte("v1"), "Formula/bar.rb", "bar v1"))
+	bts, ok := forge.File("foo", "homebrew-tap", "Formula/bar.rb")
+	require.True(t, ok)
+	require.Equal(t, "v1", string(bts))
+
+	require.NoError(t, cli.CreateFile(ctx, author, repo, []byte("v2"), "Formula/bar.rb", "bar v2"))
+	bts, ok = forge.File("foo", "homebrew-tap", "Formula/bar.rb")
+	require.True(t, ok)
+	require.Equal(t, "v2", string(bts))
+
+	_, ok = forge.File("foo", "homebrew-tap", "Formula/nope.rb")
+	require.False(t, ok)
+}
+
+func TestForgeDuplicatedAsset(t *testing.T) {
+	forge := NewForge(t)
+	cfg := config.Project{
+		Release: config.Release{
+			GitHub: config.Repo{Owner: "foo", Name: "bar"},
+		},
+	}
+	forge.Configure(&cfg)
+	ctx := NewContext(t, cfg)
+
+	cli, err := client.New(ctx)
+	require.NoError(t, err)
+	id, err := cli.CreateRelease(ctx, "")
+	require.NoError(t, err)
+
+	a := AddArtifact(t, ctx, Checksum, "checksums.txt", []byte("sums"))
+	upload := func() error {
+		f, err := os.Open(a.Path)
+		require.NoError(t, err)
+		return cli.Upload(ctx, id, a, f)
+	}
+	require.NoError(t, upload())
+	// identical assets are skipped.
+	require.NoError(t, upload())
+
+	require.NoError(t, os.WriteFile(a.Path, []byte("other sums"), 0o644))
+	err = upload()
+	require.Error(t, err)
+	require.True(t, strings.Contains(err.Error(), "already_exists"), err.Error())
+}
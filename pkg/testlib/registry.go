@@ -0,0 +1,256 @@
+package testlib
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// Registry is a fake container registry, implementing enough of the OCI
+// distribution API to push and pull images, which keeps everything in
+// memory.
+//
+// Docker allows plain HTTP registries on localhost, so images can be pushed
+// to it without any extra configuration.
+type Registry struct {
+	// Host is the host and port of the registry, to prefix the images with.
+	Host string
+
+	lock      sync.Mutex
+	blobs     map[string][]byte
+	uploads   map[string][]byte
+	manifests map[string]manifest
+	nextID    int
+}
+
+type manifest struct {
+	mediaType string
+	content   []byte
+}
+
+// NewRegistry starts a new Registry, which is closed when the test
+// finishes.
+func NewRegistry(tb testing.TB) *Registry {
+	tb.Helper()
+	r := &Registry{
+		blobs:     map[string][]byte{},
+		uploads:   map[string][]byte{},
+		manifests: map[string]manifest{},
+	}
+	srv := httptest.NewServer(http.HandlerFunc(r.serve))
+	tb.Cleanup(srv.Close)
+	u, err := url.Parse(srv.URL)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	r.Host = u.Host
+	return r
+}
+
+// Manifest returns the manifest of the given repository and reference,
+// either a tag or a digest.
+func (r *Registry) Manifest(repository, reference string) ([]byte, bool) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	m, ok := r.manifests[repository+":"+reference]
+	return m.content, ok
+}
+
+// Blob returns the blob with the given digest.
+func (r *Registry) Blob(digest string) ([]byte, bool) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	bts, ok := r.blobs[digest]
+	return bts, ok
+}
+
+// Tags returns the tags pushed to the given repository.
+func (r *Registry) Tags(repository string) []string {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	var tags []string
+	for key := range r.manifests {
+		tag := strings.TrimPrefix(key, repository+":")
+		if tag != key && !strings.HasPrefix(tag, "sha256:") {
+			tags = append(tags, tag)
+		}
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+func (r *Registry) serve(w http.ResponseWriter, req *http.Request) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	path := strings.TrimPrefix(req.URL.Path, "/v2/")
+	if path == "" || path == req.URL.Path {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	// repositories can have slashes, so the path is split at the last
+	// blobs, uploads or manifests element.
+	kind, at := "", -1
+	for _, k := range []string{"/blobs/uploads/", "/blobs/uploads", "/blobs/", "/manifests/"} {
+		if i := strings.LastIndex(path, k); i > at {
+			kind, at = k, i
+		}
+	}
+	if at < 0 {
+		registryError(w, http.StatusNotFound, "NAME_UNKNOWN", "unknown path")
+		return
+	}
+	repository, ref := path[:at], path[at+len(kind):]
+	switch kind {
+	case "/blobs/uploads/", "/blobs/uploads":
+		r.serveUpload(w, req, repository, ref)
+	case "/blobs/":
+		r.serveBlob(w, req, ref)
+	default:
+		r.serveManifest(w, req, repository, ref)
+	}
+}
+
+func (r *Registry) serveBlob(w http.ResponseWriter, req *http.Request, digest string) {
+	bts, ok := r.blobs[digest]
+	if !ok {
+		registryError(w, http.StatusNotFound, "BLOB_UNKNOWN", "blob unknown to registry")
+		return
+	}
+	w.Header().Set("Content-Length", strconv.Itoa(len(bts)))
+	w.Header().Set("Docker-Content-Digest", digest)
+	switch req.Method {
+	case http.MethodHead:
+		w.WriteHeader(http.StatusOK)
+	case http.MethodGet:
+		_, _ = w.Write(bts)
+	default:
+		registryError(w, http.StatusMethodNotAllowed, "UNSUPPORTED", "unsupported method")
+	}
+}
+
+func (r *Registry) serveUpload(w http.ResponseWriter, req *http.Request, repository, id string) {
+	switch {
+	case req.Method == http.MethodPost && id == "":
+		if mount := req.URL.Query().Get("mount"); mount != "" {
+			if _, ok := r.blobs[mount]; ok {
+				w.Header().Set("Location", "/v2/"+repository+"/blobs/"+mount)
+				w.Header().Set("Docker-Content-Digest", mount)
+				w.WriteHeader(http.StatusCreated)
+				return
+			}
+		}
+		r.nextID++
+		id = strconv.Itoa(r.nextID)
+		r.uploads[id] = nil
+		if digest := req.URL.Query().Get("digest"); digest != "" {
+			r.finishUpload(w, req, repository, id, digest)
+			return
+		}
+		r.uploadAccepted(w, repository, id)
+	case req.Method == http.MethodPatch:
+		if !r.appendUpload(w, req, id) {
+			return
+		}
+		r.uploadAccepted(w, repository, id)
+	case req.Method == http.MethodPut:
+		r.finishUpload(w, req, repository, id, req.URL.Query().Get("digest"))
+	default:
+		registryError(w, http.StatusMethodNotAllowed, "UNSUPPORTED", "unsupported method")
+	}
+}
+
+func (r *Registry) appendUpload(w http.ResponseWriter, req *http.Request, id string) bool {
+	data, ok := r.uploads[id]
+	if !ok {
+		registryError(w, http.StatusNotFound, "BLOB_UPLOAD_UNKNOWN", "blob upload unknown to registry")
+		return false
+	}
+	bts, err := io.ReadAll(req.Body)
+	if err != nil {
+		registryError(w, http.StatusBadRequest, "BLOB_UPLOAD_INVALID", err.Error())
+		return false
+	}
+	r.uploads[id] = append(data, bts...)
+	return true
+}
+
+func (r *Registry) finishUpload(w http.ResponseWriter, req *http.Request, repository, id, digest string) {
+	if !r.appendUpload(w, req, id) {
+		return
+	}
+	bts := r.uploads[id]
+	delete(r.uploads, id)
+	if actual := digestOf(bts); actual != digest {
+		registryError(w, http.StatusBadRequest, "DIGEST_INVALID", fmt.Sprintf("expected %s, got %s", digest, actual))
+		return
+	}
+	r.blobs[digest] = bts
+	w.Header().Set("Location", "/v2/"+repository+"/blobs/"+digest)
+	w.Header().Set("Docker-Content-Digest", digest)
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (r *Registry) uploadAccepted(w http.ResponseWriter, repository, id string) {
+	w.Header().Set("Location", "/v2/"+repository+"/blobs/uploads/"+id)
+	w.Header().Set("Docker-Upload-UUID", id)
+	end := len(r.uploads[id]) - 1
+	if end < 0 {
+		end = 0
+	}
+	w.Header().Set("Range", fmt.Sprintf("0-%d", end))
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (r *Registry) serveManifest(w http.ResponseWriter, req *http.Request, repository, reference string) {
+	switch req.Method {
+	case http.MethodPut:
+		bts, err := io.ReadAll(req.Body)
+		if err != nil {
+			registryError(w, http.StatusBadRequest, "MANIFEST_INVALID", err.Error())
+			return
+		}
+		digest := digestOf(bts)
+		m := manifest{mediaType: req.Header.Get("Content-Type"), content: bts}
+		r.manifests[repository+":"+reference] = m
+		r.manifests[repository+":"+digest] = m
+		w.Header().Set("Location", "/v2/"+repository+"/manifests/"+digest)
+		w.Header().Set("Docker-Content-Digest", digest)
+		w.WriteHeader(http.StatusCreated)
+	case http.MethodGet, http.MethodHead:
+		m, ok := r.manifests[repository+":"+reference]
+		if !ok {
+			registryError(w, http.StatusNotFound, "MANIFEST_UNKNOWN", "manifest unknown")
+			return
+		}
+		w.Header().Set("Content-Type", m.mediaType)
+		w.Header().Set("Content-Length", strconv.Itoa(len(m.content)))
+		w.Header().Set("Docker-Content-Digest", digestOf(m.content))
+		if req.Method == http.MethodGet {
+			_, _ = w.Write(m.content)
+		}
+	default:
+		registryError(w, http.StatusMethodNotAllowed, "UNSUPPORTED", "unsupported method")
+	}
+}
+
+func digestOf(bts []byte) string {
+	sum := sha256.Sum256(bts)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+func registryError(w http.ResponseWriter, status int, code, message string) {
+	writeJSON(w, status, map[string]interface{}{
+		"errors": []map[string]string{{"code": code, "message": message}},
+	})
+}
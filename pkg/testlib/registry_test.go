@@ -0,0 +1,78 @@
+package testlib
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry(t *testing.T) {
+	registry := NewRegistry(t)
+	base := "http://" + registry.Host + "/v2/"
+
+	do := func(method, url string, body []byte) *http.Response {
+		req, err := http.NewRequest(method, url, bytes.NewReader(body))
+		require.NoError(t, err)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = resp.Body.Close() })
+		return resp
+	}
+
+	require.Equal(t, http.StatusOK, do(http.MethodGet, base, nil).StatusCode)
+
+	// monolithic upload.
+	config := []byte(`{"architecture":"amd64"}`)
+	resp := do(http.MethodPost, base+"foo/bar/blobs/uploads/?digest="+digestOf(config), config)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	// chunked upload.
+	layer := []byte("layer contents")
+	resp = do(http.MethodPost, base+"foo/bar/blobs/uploads/", nil)
+	require.Equal(t, http.StatusAccepted, resp.StatusCode)
+	location := "http://" + registry.Host + resp.Header.Get("Location")
+	resp = do(http.MethodPatch, location, layer[:5])
+	require.Equal(t, http.StatusAccepted, resp.StatusCode)
+	location = "http://" + registry.Host + resp.Header.Get("Location")
+	resp = do(http.MethodPut, location+"?digest="+digestOf(layer), layer[5:])
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	resp = do(http.MethodHead, base+"foo/bar/blobs/"+digestOf(layer), nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	bts, ok := registry.Blob(digestOf(layer))
+	require.True(t, ok)
+	require.Equal(t, layer, bts)
+
+	// invalid digest.
+	resp = do(http.MethodPost, base+"foo/bar/blobs/uploads/?digest=sha256:nope", layer)
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	manifest := []byte(`{"schemaVersion":2}`)
+	req, err := http.NewRequest(http.MethodPut, base+"foo/bar/manifests/v1.0.0", bytes.NewReader(manifest))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/vnd.oci.image.manifest.v1+json")
+	resp, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	require.Equal(t, digestOf(manifest), resp.Header.Get("Docker-Content-Digest"))
+
+	resp = do(http.MethodGet, base+"foo/bar/manifests/v1.0.0", nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "application/vnd.oci.image.manifest.v1+json", resp.Header.Get("Content-Type"))
+	bts, err = io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Equal(t, manifest, bts)
+
+	bts, ok = registry.Manifest("foo/bar", digestOf(manifest))
+	require.True(t, ok)
+	require.Equal(t, manifest, bts)
+	require.Equal(t, []string{"v1.0.0"}, registry.Tags("foo/bar"))
+	require.Empty(t, registry.Tags("foo"))
+
+	resp = do(http.MethodGet, base+"foo/bar/manifests/latest", nil)
+	require.Equal(t, http.StatusNotFound, resp.StatusCode)
+}